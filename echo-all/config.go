@@ -0,0 +1,60 @@
+package main
+
+import (
+	"log"
+	"net"
+
+	"github.com/probitas-test/echo-servers/shared/config"
+	"github.com/probitas-test/echo-servers/shared/logging"
+	"github.com/probitas-test/echo-servers/shared/network"
+)
+
+// Variables of echo-all itself are prefixed with ECHO_ALL_ because every
+// server reads the process environment too
+type Config struct {
+	Host string
+	Port string
+
+	// YAML file selecting and configuring the servers
+	ConfigFile string
+
+	// Comma-separated servers to start when no config file is given ("all")
+	Servers string
+
+	// Delay before a server that exited is started again
+	RestartDelayMs int
+
+	// Address family of every listener (IP_FAMILY: dual, ipv4 or ipv6), of
+	// the servers as well
+	Network network.Config
+
+	// Structured logging (LOG_LEVEL, LOG_FORMAT), of the servers as well
+	Logging logging.Config
+
+	// Effective configuration, served at /config
+	src *config.Source
+}
+
+func LoadConfig() *Config {
+	// Environment, .env file and CONFIG_FILE
+	src := config.Load()
+
+	cfg := &Config{
+		Host:           src.String("HOST", "0.0.0.0"),
+		Port:           src.String("ECHO_ALL_PORT", "8080"),
+		ConfigFile:     src.String("ECHO_ALL_CONFIG", ""),
+		Servers:        src.String("ECHO_ALL_SERVERS", "all"),
+		RestartDelayMs: src.Int("ECHO_ALL_RESTART_DELAY_MS", 1000),
+		Network:        network.LoadConfig(src),
+		Logging:        logging.LoadConfig(src),
+	}
+	cfg.src = src
+	if err := src.Err(); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	return cfg
+}
+
+func (c *Config) Addr() string {
+	return net.JoinHostPort(c.Host, c.Port)
+}
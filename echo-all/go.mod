@@ -0,0 +1,153 @@
+module github.com/probitas-test/echo-servers/echo-all
+
+go 1.25.0
+
+require (
+	github.com/probitas-test/echo-servers/echo-amqp v0.0.0-00010101000000-000000000000
+	github.com/probitas-test/echo-servers/echo-coap v0.0.0-00010101000000-000000000000
+	github.com/probitas-test/echo-servers/echo-connectrpc v0.0.0-00010101000000-000000000000
+	github.com/probitas-test/echo-servers/echo-ftp v0.0.0-00010101000000-000000000000
+	github.com/probitas-test/echo-servers/echo-graphql v0.0.0-00010101000000-000000000000
+	github.com/probitas-test/echo-servers/echo-grpc v0.0.0-00010101000000-000000000000
+	github.com/probitas-test/echo-servers/echo-http v0.0.0-00010101000000-000000000000
+	github.com/probitas-test/echo-servers/echo-jsonrpc v0.0.0-00010101000000-000000000000
+	github.com/probitas-test/echo-servers/echo-kafka v0.0.0-00010101000000-000000000000
+	github.com/probitas-test/echo-servers/echo-mqtt v0.0.0-00010101000000-000000000000
+	github.com/probitas-test/echo-servers/echo-msgpack-rpc v0.0.0-00010101000000-000000000000
+	github.com/probitas-test/echo-servers/echo-nats v0.0.0-00010101000000-000000000000
+	github.com/probitas-test/echo-servers/echo-proxy v0.0.0-00010101000000-000000000000
+	github.com/probitas-test/echo-servers/echo-redis v0.0.0-00010101000000-000000000000
+	github.com/probitas-test/echo-servers/echo-socketio v0.0.0-00010101000000-000000000000
+	github.com/probitas-test/echo-servers/echo-sse v0.0.0-00010101000000-000000000000
+	github.com/probitas-test/echo-servers/echo-statsd v0.0.0-00010101000000-000000000000
+	github.com/probitas-test/echo-servers/echo-syslog v0.0.0-00010101000000-000000000000
+	github.com/probitas-test/echo-servers/echo-websocket v0.0.0-00010101000000-000000000000
+	github.com/probitas-test/echo-servers/shared v0.0.0-00010101000000-000000000000
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	buf.build/gen/go/bufbuild/protovalidate/protocolbuffers/go v1.36.10-20251209175733-2a1774d88802.1 // indirect
+	buf.build/go/protovalidate v1.0.1 // indirect
+	cel.dev/expr v0.24.0 // indirect
+	connectrpc.com/connect v1.18.1 // indirect
+	connectrpc.com/grpchealth v1.4.0 // indirect
+	connectrpc.com/grpcreflect v1.2.0 // indirect
+	connectrpc.com/otelconnect v0.7.2 // indirect
+	github.com/99designs/gqlgen v0.17.84 // indirect
+	github.com/agnivade/levenshtein v1.2.1 // indirect
+	github.com/andybalholm/brotli v1.1.1 // indirect
+	github.com/antithesishq/antithesis-sdk-go v0.7.2-default-no-op // indirect
+	github.com/antlr4-go/antlr/v4 v4.13.1 // indirect
+	github.com/beorn7/perks v1.0.1 // indirect
+	github.com/cenkalti/backoff/v5 v5.0.3 // indirect
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/cncf/xds/go v0.0.0-20251022180443-0feb69152e9f // indirect
+	github.com/dunglas/httpsfv v1.1.0 // indirect
+	github.com/envoyproxy/protoc-gen-validate v1.2.1 // indirect
+	github.com/go-chi/chi/v5 v5.2.3 // indirect
+	github.com/go-logr/logr v1.4.3 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/go-viper/mapstructure/v2 v2.4.0 // indirect
+	github.com/google/cel-go v0.26.1 // indirect
+	github.com/google/go-tpm v0.9.8 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/gorilla/websocket v1.5.3 // indirect
+	github.com/grpc-ecosystem/grpc-gateway/v2 v2.27.2 // indirect
+	github.com/hashicorp/golang-lru/v2 v2.0.7 // indirect
+	github.com/joho/godotenv v1.5.1 // indirect
+	github.com/klauspost/compress v1.19.2 // indirect
+	github.com/kr/fs v0.1.0 // indirect
+	github.com/minio/highwayhash v1.0.4 // indirect
+	github.com/mochi-mqtt/server/v2 v2.7.9 // indirect
+	github.com/munnerz/goautoneg v0.0.0-20191010083416-a7dc8b61c822 // indirect
+	github.com/nats-io/jwt/v2 v2.8.2 // indirect
+	github.com/nats-io/nats-server/v2 v2.14.5 // indirect
+	github.com/nats-io/nats.go v1.51.0 // indirect
+	github.com/nats-io/nkeys v0.4.16 // indirect
+	github.com/nats-io/nuid v1.0.1 // indirect
+	github.com/pion/dtls/v3 v3.1.10 // indirect
+	github.com/pion/logging v0.2.4 // indirect
+	github.com/pion/transport/v5 v5.0.0 // indirect
+	github.com/pkg/sftp v1.13.11 // indirect
+	github.com/probitas-test/echo-servers/proto v0.0.0-00010101000000-000000000000 // indirect
+	github.com/prometheus/client_golang v1.23.2 // indirect
+	github.com/prometheus/client_model v0.6.2 // indirect
+	github.com/prometheus/common v0.66.1 // indirect
+	github.com/prometheus/procfs v0.16.1 // indirect
+	github.com/quic-go/qpack v0.6.0 // indirect
+	github.com/quic-go/quic-go v0.59.0 // indirect
+	github.com/quic-go/webtransport-go v0.10.0 // indirect
+	github.com/rs/xid v1.6.0 // indirect
+	github.com/sosodev/duration v1.3.1 // indirect
+	github.com/spf13/afero v1.15.0 // indirect
+	github.com/stoewer/go-strcase v1.3.1 // indirect
+	github.com/twmb/franz-go v1.21.7 // indirect
+	github.com/twmb/franz-go/pkg/kmsg v1.13.1 // indirect
+	github.com/vektah/gqlparser/v2 v2.5.31 // indirect
+	github.com/vmihailenco/msgpack/v5 v5.4.1 // indirect
+	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
+	go.opentelemetry.io/auto/sdk v1.2.1 // indirect
+	go.opentelemetry.io/otel v1.38.0 // indirect
+	go.opentelemetry.io/otel/exporters/otlp/otlptrace v1.38.0 // indirect
+	go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc v1.38.0 // indirect
+	go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp v1.38.0 // indirect
+	go.opentelemetry.io/otel/metric v1.38.0 // indirect
+	go.opentelemetry.io/otel/sdk v1.38.0 // indirect
+	go.opentelemetry.io/otel/trace v1.38.0 // indirect
+	go.opentelemetry.io/proto/otlp v1.7.1 // indirect
+	go.yaml.in/yaml/v2 v2.4.2 // indirect
+	goftp.io/server/v2 v2.0.3 // indirect
+	golang.org/x/crypto v0.55.0 // indirect
+	golang.org/x/exp v0.0.0-20250813145105-42675adae3e6 // indirect
+	golang.org/x/net v0.57.0 // indirect
+	golang.org/x/sys v0.47.0 // indirect
+	golang.org/x/text v0.41.0 // indirect
+	golang.org/x/time v0.15.0 // indirect
+	google.golang.org/genproto/googleapis/api v0.0.0-20251022142026-3a174f9686a8 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20251124214823-79d6a2a48846 // indirect
+	google.golang.org/grpc v1.77.0 // indirect
+	google.golang.org/protobuf v1.36.10 // indirect
+)
+
+replace github.com/probitas-test/echo-servers/echo-amqp => ../echo-amqp
+
+replace github.com/probitas-test/echo-servers/echo-coap => ../echo-coap
+
+replace github.com/probitas-test/echo-servers/echo-connectrpc => ../echo-connectrpc
+
+replace github.com/probitas-test/echo-servers/echo-ftp => ../echo-ftp
+
+replace github.com/probitas-test/echo-servers/echo-graphql => ../echo-graphql
+
+replace github.com/probitas-test/echo-servers/echo-grpc => ../echo-grpc
+
+replace github.com/probitas-test/echo-servers/echo-http => ../echo-http
+
+replace github.com/probitas-test/echo-servers/echo-jsonrpc => ../echo-jsonrpc
+
+replace github.com/probitas-test/echo-servers/echo-kafka => ../echo-kafka
+
+replace github.com/probitas-test/echo-servers/echo-mqtt => ../echo-mqtt
+
+replace github.com/probitas-test/echo-servers/echo-msgpack-rpc => ../echo-msgpack-rpc
+
+replace github.com/probitas-test/echo-servers/echo-nats => ../echo-nats
+
+replace github.com/probitas-test/echo-servers/echo-proxy => ../echo-proxy
+
+replace github.com/probitas-test/echo-servers/echo-redis => ../echo-redis
+
+replace github.com/probitas-test/echo-servers/echo-socketio => ../echo-socketio
+
+replace github.com/probitas-test/echo-servers/echo-sse => ../echo-sse
+
+replace github.com/probitas-test/echo-servers/echo-statsd => ../echo-statsd
+
+replace github.com/probitas-test/echo-servers/echo-syslog => ../echo-syslog
+
+replace github.com/probitas-test/echo-servers/echo-websocket => ../echo-websocket
+
+replace github.com/probitas-test/echo-servers/proto => ../proto
+
+replace github.com/probitas-test/echo-servers/shared => ../shared
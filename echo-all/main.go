@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/probitas-test/echo-servers/echo-all/supervisor"
+	"github.com/probitas-test/echo-servers/shared/logging"
+	"github.com/probitas-test/echo-servers/shared/network"
+	"github.com/probitas-test/echo-servers/shared/version"
+)
+
+//go:embed docs/api.md
+var apiDocs string
+
+func main() {
+	cfg := LoadConfig()
+
+	// Structured logging; the log package writes through it as well
+	if err := logging.Setup(cfg.Logging); err != nil {
+		log.Fatalf("Invalid logging configuration: %v", err)
+	}
+
+	// IPv4-only, IPv6-only or dual-stack listeners
+	if err := network.Setup(cfg.Network); err != nil {
+		log.Fatalf("Invalid network configuration: %v", err)
+	}
+
+	var servers *supervisor.Config
+	var err error
+	if cfg.ConfigFile != "" {
+		servers, err = supervisor.LoadConfig(cfg.ConfigFile)
+	} else {
+		servers, err = supervisor.ConfigFromList(cfg.Servers)
+	}
+	if err != nil {
+		log.Fatalf("Failed to load servers: %v", err)
+	}
+	instances, err := servers.Instances()
+	if err != nil {
+		log.Fatalf("Invalid servers: %v", err)
+	}
+
+	sup := supervisor.New(instances, time.Duration(cfg.RestartDelayMs)*time.Millisecond)
+	sup.Start()
+
+	mux := http.NewServeMux()
+	supervisor.RegisterHandlers(mux, sup)
+
+	// Effective configuration (secrets redacted)
+	mux.Handle("GET /config", cfg.src)
+
+	// Address family of the client connection
+	mux.Handle("GET "+network.Path, network.Handler())
+
+	// Build information
+	mux.Handle("GET "+version.Path, version.Handler(version.New("echo-all", nil)))
+
+	// API documentation endpoint
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		_, _ = w.Write([]byte(apiDocs))
+	})
+
+	srv := &http.Server{
+		Addr:              cfg.Addr(),
+		Handler:           logging.HTTP(mux),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	// Graceful shutdown (servers are given 10s to stop)
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+		<-sigChan
+
+		log.Println("Shutting down server...")
+		stopCtx, stopCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer stopCancel()
+		if err := sup.Stop(stopCtx); err != nil {
+			slog.Error("Servers shutdown error", "error", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(ctx); err != nil {
+			slog.Error("Server shutdown error", "error", err)
+		}
+	}()
+
+	log.Printf("Starting HTTP server on %s", cfg.Addr())
+	if err := network.ListenAndServe(srv); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		_ = sup.Stop(ctx)
+		cancel()
+		log.Fatalf("Failed to serve: %v", err)
+	}
+	<-stopped
+
+	log.Println("Server stopped")
+}
@@ -0,0 +1,64 @@
+package supervisor
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// RegisterHandlers adds the status API to mux:
+//
+//	GET  /health                  200 when every server is running, else 503
+//	GET  /servers                 state of every server
+//	GET  /servers/{name}          state of a server
+//	POST /servers/{name}/restart  restart a server
+func RegisterHandlers(mux *http.ServeMux, s *Supervisor) {
+	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
+		var down []string
+		for _, st := range s.Status() {
+			if !st.Running {
+				down = append(down, st.Name)
+			}
+		}
+		if len(down) > 0 {
+			writeJSON(w, http.StatusServiceUnavailable, map[string]any{"status": "degraded", "down": down})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+
+	mux.HandleFunc("GET /servers", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{"servers": s.Status()})
+	})
+
+	mux.HandleFunc("GET /servers/{name}", func(w http.ResponseWriter, r *http.Request) {
+		st, err := s.Get(r.PathValue("name"))
+		if err != nil {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, st)
+	})
+
+	mux.HandleFunc("POST /servers/{name}/restart", func(w http.ResponseWriter, r *http.Request) {
+		if err := s.Restart(r.PathValue("name")); err != nil {
+			status := http.StatusConflict
+			if errors.Is(err, ErrNotFound) {
+				status = http.StatusNotFound
+			}
+			writeError(w, status, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusAccepted, map[string]string{"status": "restarting"})
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,147 @@
+package supervisor
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Config selects the servers to start
+type Config struct {
+	Servers map[string]ServerConfig `yaml:"servers"`
+}
+
+// ServerConfig configures one server instance. The instance name is the
+// map key; several instances of a server can run with different ports.
+type ServerConfig struct {
+	// Known server to start (defaults to the instance name)
+	Server string `yaml:"server"`
+
+	// Set to false to keep the instance in the file without starting it
+	Enabled *bool `yaml:"enabled"`
+
+	// Environment variables merged over the defaults of the server
+	Env map[string]string `yaml:"env"`
+}
+
+// Instance is a server to run in the echo-all process
+type Instance struct {
+	Name   string            `json:"name"`
+	Server string            `json:"server"`
+	Env    map[string]string `json:"env"`
+
+	run RunFunc
+}
+
+// ParseConfig parses a YAML config file. Unknown fields are rejected.
+func ParseConfig(data []byte) (*Config, error) {
+	var cfg Config
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	return &cfg, nil
+}
+
+// LoadConfig reads and parses a YAML config file
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseConfig(data)
+}
+
+// ConfigFromList returns a config starting the comma-separated known
+// servers with their defaults; "all" starts every known server
+func ConfigFromList(list string) (*Config, error) {
+	cfg := &Config{Servers: map[string]ServerConfig{}}
+	for name := range strings.SplitSeq(list, ",") {
+		name = strings.TrimSpace(name)
+		switch {
+		case name == "":
+		case name == "all":
+			for _, n := range Names() {
+				cfg.Servers[n] = ServerConfig{}
+			}
+		case Servers[name].Run == nil:
+			return nil, fmt.Errorf("unknown server %q (known: %s)", name, strings.Join(Names(), ", "))
+		default:
+			cfg.Servers[name] = ServerConfig{}
+		}
+	}
+	return cfg, nil
+}
+
+// Instances resolves the enabled servers, sorted by name. The environment
+// of an instance is the default environment of its server overridden by
+// the configured one. Two instances listening on the same port, or of a
+// single server, are an error.
+func (c *Config) Instances() ([]Instance, error) {
+	var instances []Instance
+	servers := map[string]string{}
+	for _, name := range slices.Sorted(maps.Keys(c.Servers)) {
+		sc := c.Servers[name]
+		if sc.Enabled != nil && !*sc.Enabled {
+			continue
+		}
+		if !namePattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid instance name %q (lowercase letters, digits, '-' and '_')", name)
+		}
+
+		server := sc.Server
+		if server == "" {
+			server = name
+		}
+		known, ok := Servers[server]
+		if !ok {
+			return nil, fmt.Errorf("%s: unknown server %q (known: %s)", name, server, strings.Join(Names(), ", "))
+		}
+		if other, ok := servers[server]; ok && known.Single {
+			return nil, fmt.Errorf("%s: %s already runs as %s (one instance per process)", name, server, other)
+		}
+		servers[server] = name
+
+		inst := Instance{Name: name, Server: server, Env: maps.Clone(known.Env), run: known.Run}
+		maps.Copy(inst.Env, sc.Env)
+		instances = append(instances, inst)
+	}
+	if len(instances) == 0 {
+		return nil, errors.New("no servers enabled")
+	}
+	if err := checkPorts(instances); err != nil {
+		return nil, err
+	}
+	return instances, nil
+}
+
+// checkPorts reports the first port assigned twice. Listen ports are the
+// variables ending in PORT (ADVERTISED_PORT only announces one).
+func checkPorts(instances []Instance) error {
+	used := map[string]string{}
+	for _, inst := range instances {
+		for _, key := range slices.Sorted(maps.Keys(inst.Env)) {
+			port := inst.Env[key]
+			if !strings.HasSuffix(key, "PORT") || strings.HasPrefix(key, "ADVERTISED_") || port == "" || port == "0" {
+				continue
+			}
+			owner := inst.Name + " (" + key + ")"
+			if other, ok := used[port]; ok {
+				return fmt.Errorf("port %s is used by %s and %s", port, other, owner)
+			}
+			used[port] = owner
+		}
+	}
+	return nil
+}
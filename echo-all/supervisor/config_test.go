@@ -0,0 +1,99 @@
+package supervisor
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseConfig(t *testing.T) {
+	cfg, err := ParseConfig([]byte(`
+servers:
+  http:
+    env:
+      HTTP3_ENABLED: "true"
+  grpc-alt:
+    server: grpc
+    env:
+      PORT: "50052"
+      METRICS_PORT: "29101"
+      ADMIN_PORT: "29201"
+  grpc:
+    enabled: false
+`))
+	if err != nil {
+		t.Fatalf("ParseConfig() error = %v", err)
+	}
+	instances, err := cfg.Instances()
+	if err != nil {
+		t.Fatalf("Instances() error = %v", err)
+	}
+
+	var names []string
+	for _, inst := range instances {
+		names = append(names, inst.Name)
+	}
+	if got := strings.Join(names, ","); got != "grpc-alt,http" {
+		t.Fatalf("instances = %s", got)
+	}
+
+	alt, http := instances[0], instances[1]
+	if alt.Server != "grpc" || alt.run == nil || alt.Env["PORT"] != "50052" || alt.Env["METRICS_PORT"] != "29101" {
+		t.Errorf("grpc-alt = %+v", alt)
+	}
+	if http.Server != "http" || http.run == nil || http.Env["PORT"] != "18080" || http.Env["HTTP3_ENABLED"] != "true" {
+		t.Errorf("http = %+v", http)
+	}
+	if Servers["http"].Env["HTTP3_ENABLED"] != "" {
+		t.Error("Instances() modified the default environment")
+	}
+}
+
+func TestParseConfigErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want string
+	}{
+		{"unknown field", "servers:\n  http:\n    port: 1\n", "field port not found"},
+		{"unknown server", "servers:\n  bogus: {}\n", `unknown server "bogus"`},
+		{"binary", "servers:\n  custom:\n    binary: /usr/local/bin/my-server\n", "field binary not found"},
+		{"second single instance", "servers:\n  http: {}\n  http-2:\n    server: http\n    env:\n      PORT: \"28080\"\n", "http-2: http already runs as http"},
+		{"invalid name", "servers:\n  Bad Name:\n    server: http\n", "invalid instance name"},
+		{"none enabled", "servers:\n  http:\n    enabled: false\n", "no servers enabled"},
+		{"empty", "", "no servers enabled"},
+		{"port conflict", "servers:\n  grpc: {}\n  grpc-2:\n    server: grpc\n    env:\n      METRICS_PORT: \"1\"\n      ADMIN_PORT: \"2\"\n", "port 50051 is used by grpc (PORT) and grpc-2 (PORT)"},
+		{"port conflict within server", "servers:\n  mqtt:\n    env:\n      PORT: \"18084\"\n", "port 18084 is used by mqtt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := ParseConfig([]byte(tt.yaml))
+			if err == nil {
+				_, err = cfg.Instances()
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigFromList(t *testing.T) {
+	cfg, err := ConfigFromList("http, grpc")
+	if err != nil || len(cfg.Servers) != 2 {
+		t.Fatalf("ConfigFromList() = %v, %v", cfg, err)
+	}
+
+	// The defaults of the known servers do not conflict
+	cfg, err = ConfigFromList("all")
+	if err != nil {
+		t.Fatalf("ConfigFromList(all) error = %v", err)
+	}
+	instances, err := cfg.Instances()
+	if err != nil || len(instances) != len(Servers) {
+		t.Errorf("Instances() = %d, %v; want %d", len(instances), err, len(Servers))
+	}
+
+	if _, err := ConfigFromList("http,bogus"); err == nil {
+		t.Error("ConfigFromList() with an unknown server succeeded")
+	}
+}
@@ -0,0 +1,83 @@
+package supervisor
+
+import (
+	"context"
+	"maps"
+	"slices"
+
+	amqp "github.com/probitas-test/echo-servers/echo-amqp/app"
+	coap "github.com/probitas-test/echo-servers/echo-coap/app"
+	connectrpc "github.com/probitas-test/echo-servers/echo-connectrpc/app"
+	ftp "github.com/probitas-test/echo-servers/echo-ftp/app"
+	graphql "github.com/probitas-test/echo-servers/echo-graphql/app"
+	grpc "github.com/probitas-test/echo-servers/echo-grpc/app"
+	http "github.com/probitas-test/echo-servers/echo-http/app"
+	jsonrpc "github.com/probitas-test/echo-servers/echo-jsonrpc/app"
+	kafka "github.com/probitas-test/echo-servers/echo-kafka/app"
+	mqtt "github.com/probitas-test/echo-servers/echo-mqtt/app"
+	msgpackrpc "github.com/probitas-test/echo-servers/echo-msgpack-rpc/app"
+	nats "github.com/probitas-test/echo-servers/echo-nats/app"
+	proxy "github.com/probitas-test/echo-servers/echo-proxy/app"
+	redis "github.com/probitas-test/echo-servers/echo-redis/app"
+	socketio "github.com/probitas-test/echo-servers/echo-socketio/app"
+	sse "github.com/probitas-test/echo-servers/echo-sse/app"
+	statsd "github.com/probitas-test/echo-servers/echo-statsd/app"
+	syslog "github.com/probitas-test/echo-servers/echo-syslog/app"
+	websocket "github.com/probitas-test/echo-servers/echo-websocket/app"
+	"github.com/probitas-test/echo-servers/shared/config"
+)
+
+// RunFunc runs a server with the settings of src until ctx is done
+type RunFunc func(ctx context.Context, src *config.Source) error
+
+// Server is an echo server run in the echo-all process and the environment
+// it is run with
+type Server struct {
+	Run RunFunc
+	Env map[string]string
+
+	// Single servers keep their state in package variables, so one instance
+	// at most runs in a process
+	Single bool
+}
+
+// Servers are the known echo servers by name. The default environment
+// assigns the host ports of the Docker Compose setup, so every server can
+// run in one process (and one container) without port conflicts.
+var Servers = map[string]Server{
+	"http":        {Run: run(http.LoadConfig, http.Run), Env: map[string]string{"PORT": "18080", "HTTP3_PORT": "18443", "HTTPS_PORT": "18444", "METRICS_PORT": "19100", "ADMIN_PORT": "19200"}, Single: true},
+	"grpc":        {Run: run(grpc.LoadConfig, grpc.Run), Env: map[string]string{"PORT": "50051", "METRICS_PORT": "19101", "ADMIN_PORT": "19201"}},
+	"graphql":     {Run: run(graphql.LoadConfig, graphql.Run), Env: map[string]string{"PORT": "14000", "METRICS_PORT": "19102", "ADMIN_PORT": "19202"}},
+	"connectrpc":  {Run: run(connectrpc.LoadConfig, connectrpc.Run), Env: map[string]string{"PORT": "18081", "METRICS_PORT": "19103", "ADMIN_PORT": "19203"}},
+	"websocket":   {Run: run(websocket.LoadConfig, websocket.Run), Env: map[string]string{"PORT": "18082", "ADMIN_PORT": "19204"}, Single: true},
+	"jsonrpc":     {Run: run(jsonrpc.LoadConfig, jsonrpc.Run), Env: map[string]string{"PORT": "18083", "ADMIN_PORT": "19205"}},
+	"mqtt":        {Run: run(mqtt.LoadConfig, mqtt.Run), Env: map[string]string{"PORT": "11883", "HTTP_PORT": "18084", "ADMIN_PORT": "19206"}},
+	"ftp":         {Run: run(ftp.LoadConfig, ftp.Run), Env: map[string]string{"FTP_PORT": "10021", "SFTP_PORT": "10022", "HTTP_PORT": "18085", "ADMIN_PORT": "19207"}},
+	"redis":       {Run: run(redis.LoadConfig, redis.Run), Env: map[string]string{"PORT": "16379", "HTTP_PORT": "18086", "ADMIN_PORT": "19208"}},
+	"amqp":        {Run: run(amqp.LoadConfig, amqp.Run), Env: map[string]string{"AMQP_PORT": "15672", "STOMP_PORT": "16613", "HTTP_PORT": "18087", "ADMIN_PORT": "19209"}},
+	"nats":        {Run: run(nats.LoadConfig, nats.Run), Env: map[string]string{"PORT": "14222", "HTTP_PORT": "18088", "ADMIN_PORT": "19210"}},
+	"kafka":       {Run: run(kafka.LoadConfig, kafka.Run), Env: map[string]string{"PORT": "19092", "HTTP_PORT": "18089", "ADMIN_PORT": "19211"}},
+	"coap":        {Run: run(coap.LoadConfig, coap.Run), Env: map[string]string{"PORT": "15683", "DTLS_PORT": "15684", "HTTP_PORT": "18090", "ADMIN_PORT": "19212"}},
+	"socketio":    {Run: run(socketio.LoadConfig, socketio.Run), Env: map[string]string{"PORT": "18091", "ADMIN_PORT": "19213"}},
+	"syslog":      {Run: run(syslog.LoadConfig, syslog.Run), Env: map[string]string{"PORT": "10514", "HTTP_PORT": "18092", "ADMIN_PORT": "19214"}},
+	"statsd":      {Run: run(statsd.LoadConfig, statsd.Run), Env: map[string]string{"PORT": "18125", "HTTP_PORT": "18093", "ADMIN_PORT": "19215"}},
+	"proxy":       {Run: run(proxy.LoadConfig, proxy.Run), Env: map[string]string{"PORT": "13128", "HTTP_PORT": "18094", "ADMIN_PORT": "19216", "UPSTREAM_URL": "http://127.0.0.1:18080"}},
+	"sse":         {Run: run(sse.LoadConfig, sse.Run), Env: map[string]string{"PORT": "18095", "ADMIN_PORT": "19217"}},
+	"msgpack-rpc": {Run: run(msgpackrpc.LoadConfig, msgpackrpc.Run), Env: map[string]string{"MSGPACK_PORT": "18800", "GOB_PORT": "11234", "HTTP_PORT": "18096", "ADMIN_PORT": "19218"}},
+}
+
+// run adapts the LoadConfig and Run of a server app package
+func run[C any](load func(*config.Source) (*C, error), serve func(context.Context, *C) error) RunFunc {
+	return func(ctx context.Context, src *config.Source) error {
+		cfg, err := load(src)
+		if err != nil {
+			return err
+		}
+		return serve(ctx, cfg)
+	}
+}
+
+// Names returns the names of the known servers in sorted order
+func Names() []string {
+	return slices.Sorted(maps.Keys(Servers))
+}
@@ -0,0 +1,243 @@
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/probitas-test/echo-servers/shared/config"
+	"github.com/probitas-test/echo-servers/shared/lifecycle"
+)
+
+// ErrNotFound is returned for an unknown instance name
+var ErrNotFound = errors.New("server not found")
+
+// Status is the state of a server instance
+type Status struct {
+	Instance
+	Running   bool       `json:"running"`
+	StartedAt *time.Time `json:"startedAt,omitempty"`
+	Restarts  int        `json:"restarts"`
+	LastExit  string     `json:"lastExit,omitempty"`
+}
+
+// process is a supervised instance; the fields are guarded by the
+// Supervisor mutex
+type process struct {
+	inst      Instance
+	stop      context.CancelFunc // ends the running instance
+	exited    chan struct{}
+	startedAt time.Time
+	restarts  int
+	lastExit  string
+	restart   bool // restart requested through Restart
+}
+
+// Supervisor runs server instances as goroutines of the process, each with
+// its own environment. An instance whose Run returns, or that crashes
+// (CRASH_AFTER_MS), is started again after the restart delay until Stop.
+type Supervisor struct {
+	restartDelay time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu    sync.Mutex
+	procs []*process
+}
+
+// New creates a supervisor for the instances
+func New(instances []Instance, restartDelay time.Duration) *Supervisor {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Supervisor{
+		restartDelay: restartDelay,
+		ctx:          ctx,
+		cancel:       cancel,
+	}
+	for _, inst := range instances {
+		s.procs = append(s.procs, &process{inst: inst})
+	}
+	return s
+}
+
+// Start starts every instance
+func (s *Supervisor) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, p := range s.procs {
+		s.start(p)
+		s.wg.Add(1)
+		go s.supervise(p)
+	}
+}
+
+// start runs p in a goroutine; s.mu must be held
+func (s *Supervisor) start(p *process) {
+	ctx, stop := context.WithCancel(s.ctx)
+	// A simulated crash ends the instance instead of the process
+	var crashed bool
+	ctx = lifecycle.WithExit(ctx, func(code int) {
+		s.mu.Lock()
+		crashed = true
+		p.lastExit = fmt.Sprintf("exit status %d", code)
+		s.mu.Unlock()
+		stop()
+	})
+
+	exited := make(chan struct{})
+	go func() {
+		defer stop()
+		err := p.inst.run(ctx, config.LoadEnv(p.inst.Env))
+		s.mu.Lock()
+		switch {
+		case crashed:
+		case err != nil:
+			p.lastExit = err.Error()
+		default:
+			p.lastExit = "stopped"
+		}
+		s.mu.Unlock()
+		close(exited)
+	}()
+
+	p.stop = stop
+	p.exited = exited
+	p.startedAt = time.Now()
+	log.Printf("Started %s (%s)", p.inst.Name, p.inst.Server)
+}
+
+// supervise restarts p whenever it exits until the supervisor is stopped
+func (s *Supervisor) supervise(p *process) {
+	defer s.wg.Done()
+	for {
+		s.mu.Lock()
+		exited := p.exited
+		s.mu.Unlock()
+
+		select {
+		case <-exited:
+		case <-s.ctx.Done():
+			<-exited
+			return
+		}
+
+		s.mu.Lock()
+		delay := s.restartDelay
+		if p.restart {
+			delay = 0
+			p.restart = false
+		} else if s.ctx.Err() == nil {
+			slog.Warn("Server exited, restarting", "instance", p.inst.Name, "exit", p.lastExit, "delay", delay.String())
+		}
+		s.mu.Unlock()
+
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		s.mu.Lock()
+		if s.ctx.Err() != nil {
+			// Stopped while waiting for the lock
+			s.mu.Unlock()
+			return
+		}
+		s.start(p)
+		p.restarts++
+		s.mu.Unlock()
+	}
+}
+
+// Restart stops an instance; it is started again right after it returns
+func (s *Supervisor) Restart(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, p := range s.procs {
+		if p.inst.Name != name {
+			continue
+		}
+		if !p.running() {
+			return fmt.Errorf("%s is not running", name)
+		}
+		log.Printf("Restarting %s", name)
+		p.restart = true
+		p.stop()
+		return nil
+	}
+	return ErrNotFound
+}
+
+// Stop stops every instance and waits for them to return. When ctx is
+// done first, ctx.Err() is returned without waiting for the remaining ones.
+func (s *Supervisor) Stop(ctx context.Context) error {
+	// No instance is started once the context is canceled
+	s.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Status returns the state of every instance in name order
+func (s *Supervisor) Status() []Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	statuses := make([]Status, 0, len(s.procs))
+	for _, p := range s.procs {
+		statuses = append(statuses, p.status())
+	}
+	return statuses
+}
+
+// Get returns the state of an instance
+func (s *Supervisor) Get(name string) (Status, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, p := range s.procs {
+		if p.inst.Name == name {
+			return p.status(), nil
+		}
+	}
+	return Status{}, ErrNotFound
+}
+
+// running reports whether the instance of p is running; the Supervisor
+// mutex must be held
+func (p *process) running() bool {
+	if p.exited == nil {
+		return false
+	}
+	select {
+	case <-p.exited:
+		return false
+	default:
+		return true
+	}
+}
+
+// status returns the state of p; the Supervisor mutex must be held
+func (p *process) status() Status {
+	st := Status{Restarts: p.restarts, LastExit: p.lastExit}
+	st.Instance = p.inst
+	st.Env = maps.Clone(p.inst.Env)
+	if p.running() {
+		st.Running = true
+		startedAt := p.startedAt
+		st.StartedAt = &startedAt
+	}
+	return st
+}
@@ -0,0 +1,150 @@
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/probitas-test/echo-servers/shared/admin"
+	"github.com/probitas-test/echo-servers/shared/config"
+	"github.com/probitas-test/echo-servers/shared/lifecycle"
+)
+
+// testServer records the PORT of every run and serves until ctx is done,
+// crashes after CRASH_AFTER_MS, or fails right away with FAIL
+type testServer struct {
+	mu    sync.Mutex
+	ports []string
+}
+
+func (ts *testServer) run(ctx context.Context, src *config.Source) error {
+	ts.mu.Lock()
+	ts.ports = append(ts.ports, src.String("PORT", ""))
+	ts.mu.Unlock()
+	if msg := src.String("FAIL", ""); msg != "" {
+		return errors.New(msg)
+	}
+	lifecycle.Start(ctx, lifecycle.LoadConfig(src), admin.New("test", src))
+	<-ctx.Done()
+	return nil
+}
+
+func (ts *testServer) runs() []string {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	return append([]string(nil), ts.ports...)
+}
+
+func (ts *testServer) instance(name string, env map[string]string) Instance {
+	return Instance{Name: name, Server: "test", Env: env, run: ts.run}
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestSupervisor(t *testing.T) {
+	a, b := &testServer{}, &testServer{}
+	s := New([]Instance{
+		a.instance("a", map[string]string{"PORT": "1001"}),
+		b.instance("b", map[string]string{"PORT": "1002"}),
+	}, time.Second)
+	s.Start()
+
+	// Every instance reads its own environment
+	waitFor(t, "runs", func() bool { return len(a.runs()) == 1 && len(b.runs()) == 1 })
+	if a.runs()[0] != "1001" || b.runs()[0] != "1002" {
+		t.Errorf("PORT = %v, %v", a.runs(), b.runs())
+	}
+	for _, st := range s.Status() {
+		if !st.Running || st.StartedAt == nil {
+			t.Errorf("status = %+v, want running", st)
+		}
+	}
+
+	// A requested restart starts the server again without the delay
+	if err := s.Restart("a"); err != nil {
+		t.Fatalf("Restart() error = %v", err)
+	}
+	waitFor(t, "restart", func() bool {
+		st, _ := s.Get("a")
+		return st.Running && st.Restarts == 1 && len(a.runs()) == 2
+	})
+	if st, _ := s.Get("a"); st.LastExit != "stopped" {
+		t.Errorf("LastExit = %q", st.LastExit)
+	}
+	if err := s.Restart("bogus"); err != ErrNotFound {
+		t.Errorf("Restart(bogus) error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.Stop(ctx); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	for _, st := range s.Status() {
+		if st.Running {
+			t.Errorf("%s still running after Stop()", st.Name)
+		}
+	}
+}
+
+func TestSupervisorRestartsFailedServer(t *testing.T) {
+	ts := &testServer{}
+	s := New([]Instance{ts.instance("fail", map[string]string{"FAIL": "failed to listen"})}, 20*time.Millisecond)
+	s.Start()
+	var st Status
+	waitFor(t, "restarts", func() bool {
+		st, _ = s.Get("fail")
+		return st.Restarts >= 2
+	})
+	if err := s.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+
+	if st.LastExit != "failed to listen" {
+		t.Errorf("LastExit = %q", st.LastExit)
+	}
+}
+
+func TestSupervisorRestartsCrashedServer(t *testing.T) {
+	// The crash ends the instance, not the test process
+	ts := &testServer{}
+	s := New([]Instance{ts.instance("crash", map[string]string{"CRASH_AFTER_MS": "10", "CRASH_EXIT_CODE": "3"})}, 20*time.Millisecond)
+	s.Start()
+	var st Status
+	waitFor(t, "restarts", func() bool {
+		st, _ = s.Get("crash")
+		return st.Restarts >= 2
+	})
+	if err := s.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+
+	if st.LastExit != "exit status 3" {
+		t.Errorf("LastExit = %q", st.LastExit)
+	}
+}
+
+func TestSupervisorStopTimeout(t *testing.T) {
+	stuck := Instance{Name: "stuck", Server: "test", run: func(ctx context.Context, src *config.Source) error {
+		select {}
+	}}
+	s := New([]Instance{stuck}, time.Second)
+	s.Start()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := s.Stop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Stop() error = %v", err)
+	}
+}
@@ -0,0 +1,40 @@
+// Package ack defines how the brokers answer published messages
+package ack
+
+import "fmt"
+
+// Header overrides the configured mode for a single message
+const Header = "x-echo-ack"
+
+// Mode selects how a published message is acknowledged to its publisher
+type Mode string
+
+const (
+	// Ack confirms the message (AMQP Basic.Ack, STOMP RECEIPT)
+	Ack Mode = "ack"
+
+	// Nack rejects the message (AMQP Basic.Nack, STOMP ERROR)
+	Nack Mode = "nack"
+
+	// None leaves the message unanswered
+	None Mode = "none"
+)
+
+// ParseMode parses "ack", "nack" or "none"
+func ParseMode(value string) (Mode, error) {
+	switch mode := Mode(value); mode {
+	case Ack, Nack, None:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid ack mode %q (must be ack, nack or none)", value)
+	}
+}
+
+// Resolve returns the mode requested by the header value of a message, or
+// fallback when the value is empty or invalid
+func Resolve(value string, fallback Mode) Mode {
+	if mode, err := ParseMode(value); err == nil {
+		return mode
+	}
+	return fallback
+}
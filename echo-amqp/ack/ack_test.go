@@ -0,0 +1,34 @@
+package ack
+
+import "testing"
+
+func TestParseMode(t *testing.T) {
+	for _, value := range []string{"ack", "nack", "none"} {
+		mode, err := ParseMode(value)
+		if err != nil || string(mode) != value {
+			t.Errorf("ParseMode(%q) = %q, %v", value, mode, err)
+		}
+	}
+	for _, value := range []string{"", "ACK", "reject"} {
+		if _, err := ParseMode(value); err == nil {
+			t.Errorf("ParseMode(%q) succeeded", value)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		value string
+		want  Mode
+	}{
+		{"", Ack},
+		{"nack", Nack},
+		{"none", None},
+		{"invalid", Ack},
+	}
+	for _, tt := range tests {
+		if got := Resolve(tt.value, Ack); got != tt.want {
+			t.Errorf("Resolve(%q, ack) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
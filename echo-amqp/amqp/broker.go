@@ -0,0 +1,239 @@
+package amqp
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Exchange types
+const (
+	exchangeDirect = "direct"
+	exchangeFanout = "fanout"
+	exchangeTopic  = "topic"
+)
+
+// message is a published message. Each queue holds its own copy; the
+// properties and body are shared and never modified.
+type message struct {
+	exchange    string
+	routingKey  string
+	properties  []byte
+	body        []byte
+	redelivered bool
+}
+
+// exchange routes messages to the queues bound to it
+type exchange struct {
+	name     string
+	kind     string
+	internal bool
+	bindings []binding
+}
+
+type binding struct {
+	queue *queue
+	key   string
+}
+
+// queue holds messages until a consumer or basic.get takes them
+type queue struct {
+	name       string
+	owner      *conn // exclusive queues only
+	autoDelete bool
+	deleted    bool
+
+	ready     []*message
+	consumers []*consumer
+	next      int
+}
+
+// consumer receives the messages of a queue on a channel
+type consumer struct {
+	tag       string
+	queue     *queue
+	ch        *channel
+	noAck     bool
+	exclusive bool
+}
+
+// delivery is a message delivered but not yet acknowledged
+type delivery struct {
+	queue *queue
+	msg   *message
+}
+
+// broker holds the exchanges and queues shared by all connections. A single
+// lock guards the broker, its queues and the channels of all connections;
+// frames are queued on the connection outboxes, so it is never held while
+// writing to the network.
+type broker struct {
+	mu        sync.Mutex
+	exchanges map[string]*exchange
+	queues    map[string]*queue
+}
+
+func newBroker() *broker {
+	b := &broker{
+		exchanges: make(map[string]*exchange),
+		queues:    make(map[string]*queue),
+	}
+	// The default exchange routes to the queue named by the routing key
+	for name, kind := range map[string]string{
+		"":           exchangeDirect,
+		"amq.direct": exchangeDirect,
+		"amq.fanout": exchangeFanout,
+		"amq.topic":  exchangeTopic,
+	} {
+		b.exchanges[name] = &exchange{name: name, kind: kind}
+	}
+	return b
+}
+
+// route returns the queues ex routes key to, each at most once
+func (b *broker) route(ex *exchange, key string) []*queue {
+	if ex.name == "" {
+		if q, ok := b.queues[key]; ok {
+			return []*queue{q}
+		}
+		return nil
+	}
+
+	var queues []*queue
+	for _, bnd := range ex.bindings {
+		var match bool
+		switch ex.kind {
+		case exchangeFanout:
+			match = true
+		case exchangeTopic:
+			match = topicMatch(strings.Split(bnd.key, "."), strings.Split(key, "."))
+		default:
+			match = bnd.key == key
+		}
+		if match && !slices.Contains(queues, bnd.queue) {
+			queues = append(queues, bnd.queue)
+		}
+	}
+	return queues
+}
+
+// topicMatch matches the words of a routing key against a binding pattern,
+// where "*" matches one word and "#" zero or more
+func topicMatch(pattern, words []string) bool {
+	if len(pattern) == 0 {
+		return len(words) == 0
+	}
+	switch pattern[0] {
+	case "#":
+		for i := 0; i <= len(words); i++ {
+			if topicMatch(pattern[1:], words[i:]) {
+				return true
+			}
+		}
+		return false
+	case "*":
+		return len(words) > 0 && topicMatch(pattern[1:], words[1:])
+	default:
+		return len(words) > 0 && pattern[0] == words[0] && topicMatch(pattern[1:], words[1:])
+	}
+}
+
+// enqueue appends a copy of msg to q and delivers what the consumers of q
+// can take
+func (b *broker) enqueue(q *queue, msg *message) {
+	m := *msg
+	m.redelivered = false
+	q.ready = append(q.ready, &m)
+	b.dispatch(q)
+}
+
+// dispatch delivers ready messages round-robin to the consumers with
+// capacity left
+func (b *broker) dispatch(q *queue) {
+	for len(q.ready) > 0 {
+		c := q.nextConsumer()
+		if c == nil {
+			return
+		}
+		msg := q.ready[0]
+		q.ready[0] = nil
+		q.ready = q.ready[1:]
+		c.ch.deliver(c, msg)
+	}
+}
+
+// dispatchAll dispatches every queue, after consumers got capacity back
+func (b *broker) dispatchAll() {
+	for _, q := range b.queues {
+		b.dispatch(q)
+	}
+}
+
+func (q *queue) nextConsumer() *consumer {
+	for i := range q.consumers {
+		c := q.consumers[(q.next+i)%len(q.consumers)]
+		if c.ch.hasCapacity() {
+			q.next = (q.next + i + 1) % len(q.consumers)
+			return c
+		}
+	}
+	return nil
+}
+
+// requeue puts unacknowledged deliveries back at the front of their queues
+// in delivery order, marked as redelivered
+func (b *broker) requeue(deliveries []*delivery) {
+	for _, d := range slices.Backward(deliveries) {
+		if d.queue.deleted {
+			continue
+		}
+		d.msg.redelivered = true
+		d.queue.ready = slices.Insert(d.queue.ready, 0, d.msg)
+	}
+	for _, d := range deliveries {
+		b.dispatch(d.queue)
+	}
+}
+
+// removeConsumer detaches c from its queue, deleting auto-delete queues
+// that lost their last consumer
+func (b *broker) removeConsumer(c *consumer) {
+	q := c.queue
+	q.consumers = slices.DeleteFunc(q.consumers, func(other *consumer) bool { return other == c })
+	q.next = 0
+	delete(c.ch.consumers, c.tag)
+	if q.autoDelete && len(q.consumers) == 0 {
+		b.deleteQueue(q)
+	}
+}
+
+// deleteQueue removes q with its bindings and cancels its consumers. It
+// returns the number of messages dropped.
+func (b *broker) deleteQueue(q *queue) int {
+	if q.deleted {
+		return 0
+	}
+	q.deleted = true
+	delete(b.queues, q.name)
+	for _, ex := range b.exchanges {
+		ex.bindings = slices.DeleteFunc(ex.bindings, func(bnd binding) bool { return bnd.queue == q })
+	}
+	for _, c := range q.consumers {
+		delete(c.ch.consumers, c.tag)
+		c.ch.cancelled(c)
+	}
+	q.consumers = nil
+	count := len(q.ready)
+	q.ready = nil
+	return count
+}
+
+// generateName returns prefix followed by a random suffix, as used for
+// server-named queues and consumer tags
+func generateName(prefix string) string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return prefix + base64.RawURLEncoding.EncodeToString(b)
+}
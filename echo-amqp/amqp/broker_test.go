@@ -0,0 +1,67 @@
+package amqp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTopicMatch(t *testing.T) {
+	tests := []struct {
+		pattern, key string
+		want         bool
+	}{
+		{"orders.created", "orders.created", true},
+		{"orders.*", "orders.created", true},
+		{"orders.*", "orders.created.eu", false},
+		{"orders.#", "orders", true},
+		{"orders.#", "orders.created.eu", true},
+		{"#.eu", "orders.created.eu", true},
+		{"#", "", true},
+		{"*.created", "orders.deleted", false},
+	}
+	for _, tt := range tests {
+		got := topicMatch(strings.Split(tt.pattern, "."), strings.Split(tt.key, "."))
+		if got != tt.want {
+			t.Errorf("topicMatch(%q, %q) = %v, want %v", tt.pattern, tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestRoute(t *testing.T) {
+	b := newBroker()
+	a := &queue{name: "a"}
+	c := &queue{name: "c"}
+	b.queues["a"] = a
+	b.queues["c"] = c
+	b.exchanges["amq.direct"].bindings = []binding{{a, "k"}, {c, "other"}}
+	b.exchanges["amq.fanout"].bindings = []binding{{a, ""}, {c, ""}, {a, "again"}}
+
+	tests := []struct {
+		exchange, key string
+		want          []*queue
+	}{
+		{"", "a", []*queue{a}},
+		{"", "missing", nil},
+		{"amq.direct", "k", []*queue{a}},
+		{"amq.fanout", "ignored", []*queue{a, c}},
+	}
+	for _, tt := range tests {
+		got := b.route(b.exchanges[tt.exchange], tt.key)
+		if len(got) != len(tt.want) {
+			t.Errorf("route(%q, %q) = %d queues, want %d", tt.exchange, tt.key, len(got), len(tt.want))
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("route(%q, %q)[%d] = %s, want %s", tt.exchange, tt.key, i, got[i].name, tt.want[i].name)
+			}
+		}
+	}
+}
+
+func TestGenerateName(t *testing.T) {
+	a, b := generateName("amq.gen-"), generateName("amq.gen-")
+	if !strings.HasPrefix(a, "amq.gen-") || a == b {
+		t.Errorf("generateName = %q, %q", a, b)
+	}
+}
@@ -0,0 +1,683 @@
+package amqp
+
+import (
+	"maps"
+	"slices"
+	"strings"
+)
+
+// channel is the state of an open channel, guarded by the broker lock
+type channel struct {
+	id   uint16
+	conn *conn
+
+	// closing is set once channel.close was sent; frames are ignored until
+	// the close-ok
+	closing bool
+
+	active    bool
+	prefetch  int
+	confirm   bool
+	lastQueue string
+
+	publishSeq  uint64
+	deliveryTag uint64
+	unacked     map[uint64]*delivery
+	consumers   map[string]*consumer
+
+	// publishing is the message whose content frames are expected
+	publishing *publishing
+}
+
+// publishing is a basic.publish waiting for its content
+type publishing struct {
+	msg       *message
+	exchange  *exchange
+	mandatory bool
+	header    bool
+	size      uint64
+}
+
+func (c *conn) channelMethod(id uint16, m methodID, d *decoder) error {
+	ch := c.channels[id]
+	if m == channelOpen {
+		if ch != nil {
+			return connectionError(replyChannelError, m, "second 'channel.open' seen")
+		}
+		if id > c.channelMax {
+			return connectionError(replyChannelError, m, "channel %d exceeds channel-max %d", id, c.channelMax)
+		}
+		c.channels[id] = &channel{
+			id:        id,
+			conn:      c,
+			active:    true,
+			unacked:   make(map[uint64]*delivery),
+			consumers: make(map[string]*consumer),
+		}
+		c.push(methodFrame(id, channelOpenOk, func(e *encoder) { e.longstr(nil) }))
+		return nil
+	}
+	if ch == nil {
+		return connectionError(replyChannelError, m, "expected 'channel.open' on channel %d", id)
+	}
+	if ch.closing {
+		switch m {
+		case channelClose:
+			c.push(methodFrame(id, channelCloseOk, nil))
+			delete(c.channels, id)
+		case channelCloseOk:
+			delete(c.channels, id)
+		}
+		return nil
+	}
+	if ch.publishing != nil {
+		return connectionError(replyUnexpectedFrame, m, "expected content header for class 60, got %s", m)
+	}
+
+	err := ch.method(m, d)
+	if d.err != nil {
+		return connectionError(replySyntaxError, m, "malformed %s", m)
+	}
+	return err
+}
+
+// closeChannel closes a channel after a soft error
+func (c *conn) closeChannel(id uint16, err *amqpError) {
+	b := c.server.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	ch := c.channels[id]
+	if ch == nil || ch.closing {
+		return
+	}
+	c.push(closeFrame(id, err))
+	ch.release()
+	ch.closing = true
+}
+
+// release cancels the consumers of ch and requeues its unacknowledged
+// messages
+func (ch *channel) release() {
+	b := ch.conn.server.broker
+	for _, c := range ch.consumers {
+		b.removeConsumer(c)
+	}
+	ch.publishing = nil
+	ch.requeue(slices.Collect(maps.Keys(ch.unacked)))
+}
+
+// requeue requeues the unacknowledged messages with the given tags
+func (ch *channel) requeue(tags []uint64) {
+	slices.Sort(tags)
+	deliveries := make([]*delivery, 0, len(tags))
+	for _, tag := range tags {
+		deliveries = append(deliveries, ch.unacked[tag])
+		delete(ch.unacked, tag)
+	}
+	ch.conn.server.broker.requeue(deliveries)
+}
+
+// hasCapacity reports whether a consumer on ch may receive another message
+func (ch *channel) hasCapacity() bool {
+	return ch.active && !ch.closing && (ch.prefetch == 0 || len(ch.unacked) < ch.prefetch)
+}
+
+// deliver sends msg to consumer c
+func (ch *channel) deliver(c *consumer, msg *message) {
+	ch.deliveryTag++
+	if !c.noAck {
+		ch.unacked[ch.deliveryTag] = &delivery{queue: c.queue, msg: msg}
+	}
+	ch.conn.push(methodFrame(ch.id, basicDeliver, func(e *encoder) {
+		e.shortstr(c.tag)
+		e.longlong(ch.deliveryTag)
+		e.bits(msg.redelivered)
+		e.shortstr(msg.exchange)
+		e.shortstr(msg.routingKey)
+	}))
+	ch.pushContent(msg)
+}
+
+// cancelled notifies the client that the server cancelled consumer c
+func (ch *channel) cancelled(c *consumer) {
+	if ch.conn.cancelNotify {
+		ch.conn.push(methodFrame(ch.id, basicCancel, func(e *encoder) {
+			e.shortstr(c.tag)
+			e.bits(true)
+		}))
+	}
+}
+
+// returnMessage returns an unroutable mandatory message to its publisher
+func (ch *channel) returnMessage(msg *message, code uint16, text string) {
+	ch.conn.push(methodFrame(ch.id, basicReturn, func(e *encoder) {
+		e.short(code)
+		e.shortstr(text)
+		e.shortstr(msg.exchange)
+		e.shortstr(msg.routingKey)
+	}))
+	ch.pushContent(msg)
+}
+
+// pushContent sends the content header and body frames of msg
+func (ch *channel) pushContent(msg *message) {
+	header := &encoder{}
+	header.short(classBasic)
+	header.short(0)
+	header.longlong(uint64(len(msg.body)))
+	header.buf = append(header.buf, msg.properties...)
+	frames := [][]byte{frame{typ: frameHeader, channel: ch.id, payload: header.buf}.encode()}
+
+	chunk := int(ch.conn.frameMax - frameOverhead)
+	for body := msg.body; len(body) > 0; {
+		n := min(len(body), chunk)
+		frames = append(frames, frame{typ: frameBody, channel: ch.id, payload: body[:n]}.encode())
+		body = body[n:]
+	}
+	ch.conn.push(frames...)
+}
+
+// content handles the header and body frames of a publish
+func (c *conn) content(f frame) error {
+	ch := c.channels[f.channel]
+	if ch == nil {
+		return connectionError(replyChannelError, methodID{}, "expected 'channel.open' on channel %d", f.channel)
+	}
+	if ch.closing {
+		return nil
+	}
+	p := ch.publishing
+	if p == nil {
+		return connectionError(replyUnexpectedFrame, methodID{}, "unexpected content frame on channel %d", f.channel)
+	}
+
+	if f.typ == frameHeader {
+		if p.header {
+			return connectionError(replyUnexpectedFrame, basicPublish, "expected content body, got content header")
+		}
+		d := &decoder{buf: f.payload}
+		class := d.short()
+		d.short()
+		p.size = d.longlong()
+		if d.err != nil || class != classBasic {
+			return connectionError(replyFrameError, basicPublish, "malformed content header")
+		}
+		if p.size > maxBodySize {
+			return channelError(replyPreconditionFailed, basicPublish,
+				"message size %d is larger than max size %d", p.size, maxBodySize)
+		}
+		p.header = true
+		p.msg.properties = d.buf
+		p.msg.body = make([]byte, 0, p.size)
+	} else {
+		if !p.header {
+			return connectionError(replyUnexpectedFrame, basicPublish, "expected content header, got content body")
+		}
+		p.msg.body = append(p.msg.body, f.payload...)
+		if uint64(len(p.msg.body)) > p.size {
+			return connectionError(replyFrameError, basicPublish, "content body exceeds the announced size %d", p.size)
+		}
+	}
+
+	if uint64(len(p.msg.body)) < p.size {
+		return nil
+	}
+	ch.publishing = nil
+	return c.server.broker.publish(ch, p.exchange, p.msg, p.mandatory)
+}
+
+// method handles a method on an open channel
+func (ch *channel) method(m methodID, d *decoder) error {
+	switch m {
+	case channelFlow:
+		ch.active = d.octet()&1 != 0
+		ch.conn.push(methodFrame(ch.id, channelFlowOk, func(e *encoder) { e.bits(ch.active) }))
+		if ch.active {
+			ch.conn.server.broker.dispatchAll()
+		}
+		return nil
+
+	case channelClose:
+		ch.release()
+		delete(ch.conn.channels, ch.id)
+		ch.conn.push(methodFrame(ch.id, channelCloseOk, nil))
+		return nil
+
+	case channelCloseOk:
+		return nil
+
+	case exchangeDeclare:
+		return ch.exchangeDeclare(d)
+	case exchangeDelete:
+		return ch.exchangeDelete(d)
+	case queueDeclare:
+		return ch.queueDeclare(d)
+	case queueBind, queueUnbind:
+		return ch.queueBind(m, d)
+	case queuePurge:
+		return ch.queuePurge(d)
+	case queueDelete:
+		return ch.queueDelete(d)
+	case basicQos:
+		return ch.basicQos(d)
+	case basicConsume:
+		return ch.basicConsume(d)
+	case basicCancel:
+		return ch.basicCancel(d)
+	case basicPublish:
+		return ch.basicPublish(d)
+	case basicGet:
+		return ch.basicGet(d)
+	case basicAck, basicReject, basicNack:
+		return ch.basicAck(m, d)
+	case basicRecover, basicRecoverAsync:
+		d.octet()
+		ch.requeue(slices.Collect(maps.Keys(ch.unacked)))
+		if m == basicRecover {
+			ch.conn.push(methodFrame(ch.id, basicRecoverOk, nil))
+		}
+		return nil
+
+	case confirmSelect:
+		noWait := d.octet()&1 != 0
+		ch.confirm = true
+		if !noWait {
+			ch.conn.push(methodFrame(ch.id, confirmSelectOk, nil))
+		}
+		return nil
+	}
+
+	if m.class == classTx {
+		return channelError(replyNotImplemented, m, "transactions are not supported")
+	}
+	return connectionError(replyCommandInvalid, m, "unexpected %s", m)
+}
+
+func (ch *channel) exchangeDeclare(d *decoder) error {
+	b := ch.conn.server.broker
+	d.short()
+	name := d.shortstr()
+	kind := d.shortstr()
+	flags := d.octet()
+	d.table()
+	passive, internal, noWait := flags&1 != 0, flags&8 != 0, flags&16 != 0
+	if d.err != nil {
+		return nil
+	}
+
+	ex, ok := b.exchanges[name]
+	switch {
+	case passive && !ok:
+		return channelError(replyNotFound, exchangeDeclare, "no exchange '%s' in vhost '/'", name)
+	case passive:
+	case name == "" || (!ok && strings.HasPrefix(name, "amq.")):
+		return channelError(replyAccessRefused, exchangeDeclare,
+			"exchange name '%s' contains reserved prefix 'amq.*'", name)
+	case kind != exchangeDirect && kind != exchangeFanout && kind != exchangeTopic:
+		return connectionError(replyCommandInvalid, exchangeDeclare, "invalid exchange type '%s'", kind)
+	case ok && ex.kind != kind:
+		return channelError(replyPreconditionFailed, exchangeDeclare,
+			"inequivalent arg 'type' for exchange '%s' in vhost '/': received '%s' but current is '%s'",
+			name, kind, ex.kind)
+	case !ok:
+		b.exchanges[name] = &exchange{name: name, kind: kind, internal: internal}
+	}
+	if !noWait {
+		ch.conn.push(methodFrame(ch.id, exchangeDeclareOk, nil))
+	}
+	return nil
+}
+
+func (ch *channel) exchangeDelete(d *decoder) error {
+	b := ch.conn.server.broker
+	d.short()
+	name := d.shortstr()
+	flags := d.octet()
+	ifUnused, noWait := flags&1 != 0, flags&2 != 0
+	if d.err != nil {
+		return nil
+	}
+
+	if name == "" || strings.HasPrefix(name, "amq.") {
+		return channelError(replyAccessRefused, exchangeDelete, "operation not permitted on exchange '%s'", name)
+	}
+	if ex, ok := b.exchanges[name]; ok {
+		if ifUnused && len(ex.bindings) > 0 {
+			return channelError(replyPreconditionFailed, exchangeDelete, "exchange '%s' in vhost '/' in use", name)
+		}
+		delete(b.exchanges, name)
+	}
+	if !noWait {
+		ch.conn.push(methodFrame(ch.id, exchangeDeleteOk, nil))
+	}
+	return nil
+}
+
+// findQueue returns the queue named name (the last declared queue of the
+// channel when empty) if it exists and is accessible by the connection
+func (ch *channel) findQueue(m methodID, name string) (*queue, *amqpError) {
+	if name == "" {
+		name = ch.lastQueue
+	}
+	q, ok := ch.conn.server.broker.queues[name]
+	if !ok {
+		return nil, channelError(replyNotFound, m, "no queue '%s' in vhost '/'", name)
+	}
+	if q.owner != nil && q.owner != ch.conn {
+		return nil, channelError(replyResourceLocked, m,
+			"cannot obtain exclusive access to locked queue '%s' in vhost '/'", name)
+	}
+	return q, nil
+}
+
+func (ch *channel) queueDeclare(d *decoder) error {
+	b := ch.conn.server.broker
+	d.short()
+	name := d.shortstr()
+	flags := d.octet()
+	d.table()
+	passive, exclusive, autoDelete, noWait := flags&1 != 0, flags&4 != 0, flags&8 != 0, flags&16 != 0
+	if d.err != nil {
+		return nil
+	}
+
+	var q *queue
+	if passive {
+		var err *amqpError
+		if q, err = ch.findQueue(queueDeclare, name); err != nil {
+			return err
+		}
+	} else {
+		generated := name == ""
+		if generated {
+			name = generateName("amq.gen-")
+		}
+		q = b.queues[name]
+		switch {
+		case q == nil && !generated && strings.HasPrefix(name, "amq."):
+			return channelError(replyAccessRefused, queueDeclare,
+				"queue name '%s' contains reserved prefix 'amq.*'", name)
+		case q == nil:
+			q = &queue{name: name, autoDelete: autoDelete}
+			if exclusive {
+				q.owner = ch.conn
+			}
+			b.queues[name] = q
+		case q.owner != nil && q.owner != ch.conn:
+			return channelError(replyResourceLocked, queueDeclare,
+				"cannot obtain exclusive access to locked queue '%s' in vhost '/'", name)
+		}
+	}
+
+	ch.lastQueue = q.name
+	if !noWait {
+		ch.conn.push(methodFrame(ch.id, queueDeclareOk, func(e *encoder) {
+			e.shortstr(q.name)
+			e.long(uint32(len(q.ready)))
+			e.long(uint32(len(q.consumers)))
+		}))
+	}
+	return nil
+}
+
+// queueBind handles queue.bind and queue.unbind
+func (ch *channel) queueBind(m methodID, d *decoder) error {
+	b := ch.conn.server.broker
+	d.short()
+	queueName := d.shortstr()
+	exchangeName := d.shortstr()
+	key := d.shortstr()
+	noWait := false
+	if m == queueBind {
+		noWait = d.octet()&1 != 0
+	}
+	d.table()
+	if d.err != nil {
+		return nil
+	}
+
+	q, err := ch.findQueue(m, queueName)
+	if err != nil {
+		return err
+	}
+	if exchangeName == "" {
+		return channelError(replyAccessRefused, m, "operation not permitted on the default exchange")
+	}
+	ex, ok := b.exchanges[exchangeName]
+	if !ok {
+		return channelError(replyNotFound, m, "no exchange '%s' in vhost '/'", exchangeName)
+	}
+
+	bnd := binding{queue: q, key: key}
+	if m == queueBind {
+		if !slices.Contains(ex.bindings, bnd) {
+			ex.bindings = append(ex.bindings, bnd)
+		}
+		if !noWait {
+			ch.conn.push(methodFrame(ch.id, queueBindOk, nil))
+		}
+		return nil
+	}
+	ex.bindings = slices.DeleteFunc(ex.bindings, func(other binding) bool { return other == bnd })
+	ch.conn.push(methodFrame(ch.id, queueUnbindOk, nil))
+	return nil
+}
+
+func (ch *channel) queuePurge(d *decoder) error {
+	d.short()
+	name := d.shortstr()
+	noWait := d.octet()&1 != 0
+	if d.err != nil {
+		return nil
+	}
+
+	q, err := ch.findQueue(queuePurge, name)
+	if err != nil {
+		return err
+	}
+	count := len(q.ready)
+	q.ready = nil
+	if !noWait {
+		ch.conn.push(methodFrame(ch.id, queuePurgeOk, func(e *encoder) { e.long(uint32(count)) }))
+	}
+	return nil
+}
+
+func (ch *channel) queueDelete(d *decoder) error {
+	d.short()
+	name := d.shortstr()
+	flags := d.octet()
+	ifUnused, ifEmpty, noWait := flags&1 != 0, flags&2 != 0, flags&4 != 0
+	if d.err != nil {
+		return nil
+	}
+
+	count := 0
+	q, err := ch.findQueue(queueDelete, name)
+	switch {
+	case err != nil && err.code == replyNotFound:
+		// Deleting a missing queue succeeds
+	case err != nil:
+		return err
+	case ifUnused && len(q.consumers) > 0:
+		return channelError(replyPreconditionFailed, queueDelete, "queue '%s' in vhost '/' in use", q.name)
+	case ifEmpty && len(q.ready) > 0:
+		return channelError(replyPreconditionFailed, queueDelete, "queue '%s' in vhost '/' is not empty", q.name)
+	default:
+		count = ch.conn.server.broker.deleteQueue(q)
+	}
+	if !noWait {
+		ch.conn.push(methodFrame(ch.id, queueDeleteOk, func(e *encoder) { e.long(uint32(count)) }))
+	}
+	return nil
+}
+
+func (ch *channel) basicQos(d *decoder) error {
+	d.long()
+	count := d.short()
+	d.octet()
+	if d.err != nil {
+		return nil
+	}
+	ch.prefetch = int(count)
+	ch.conn.push(methodFrame(ch.id, basicQosOk, nil))
+	ch.conn.server.broker.dispatchAll()
+	return nil
+}
+
+func (ch *channel) basicConsume(d *decoder) error {
+	d.short()
+	queueName := d.shortstr()
+	tag := d.shortstr()
+	flags := d.octet()
+	d.table()
+	noAck, exclusive, noWait := flags&2 != 0, flags&4 != 0, flags&8 != 0
+	if d.err != nil {
+		return nil
+	}
+
+	q, err := ch.findQueue(basicConsume, queueName)
+	if err != nil {
+		return err
+	}
+	if tag == "" {
+		tag = generateName("amq.ctag-")
+	}
+	if _, ok := ch.consumers[tag]; ok {
+		return connectionError(replyNotAllowed, basicConsume, "attempt to reuse consumer tag '%s'", tag)
+	}
+	if (exclusive && len(q.consumers) > 0) || (len(q.consumers) > 0 && q.consumers[0].exclusive) {
+		return channelError(replyAccessRefused, basicConsume,
+			"queue '%s' in vhost '/' in exclusive use", q.name)
+	}
+
+	c := &consumer{tag: tag, queue: q, ch: ch, noAck: noAck, exclusive: exclusive}
+	ch.consumers[tag] = c
+	q.consumers = append(q.consumers, c)
+	if !noWait {
+		ch.conn.push(methodFrame(ch.id, basicConsumeOk, func(e *encoder) { e.shortstr(tag) }))
+	}
+	ch.conn.server.broker.dispatch(q)
+	return nil
+}
+
+func (ch *channel) basicCancel(d *decoder) error {
+	tag := d.shortstr()
+	noWait := d.octet()&1 != 0
+	if d.err != nil {
+		return nil
+	}
+
+	if c, ok := ch.consumers[tag]; ok {
+		ch.conn.server.broker.removeConsumer(c)
+	}
+	if !noWait {
+		ch.conn.push(methodFrame(ch.id, basicCancelOk, func(e *encoder) { e.shortstr(tag) }))
+	}
+	return nil
+}
+
+func (ch *channel) basicPublish(d *decoder) error {
+	d.short()
+	exchangeName := d.shortstr()
+	key := d.shortstr()
+	flags := d.octet()
+	mandatory, immediate := flags&1 != 0, flags&2 != 0
+	if d.err != nil {
+		return nil
+	}
+
+	if immediate {
+		return connectionError(replyNotImplemented, basicPublish, "immediate=true")
+	}
+	ex, ok := ch.conn.server.broker.exchanges[exchangeName]
+	if !ok {
+		return channelError(replyNotFound, basicPublish, "no exchange '%s' in vhost '/'", exchangeName)
+	}
+	if ex.internal {
+		return channelError(replyAccessRefused, basicPublish, "cannot publish to internal exchange '%s'", exchangeName)
+	}
+	ch.publishing = &publishing{
+		msg:       &message{exchange: exchangeName, routingKey: key},
+		exchange:  ex,
+		mandatory: mandatory,
+	}
+	return nil
+}
+
+func (ch *channel) basicGet(d *decoder) error {
+	d.short()
+	name := d.shortstr()
+	noAck := d.octet()&1 != 0
+	if d.err != nil {
+		return nil
+	}
+
+	q, err := ch.findQueue(basicGet, name)
+	if err != nil {
+		return err
+	}
+	if len(q.ready) == 0 {
+		ch.conn.push(methodFrame(ch.id, basicGetEmpty, func(e *encoder) { e.shortstr("") }))
+		return nil
+	}
+
+	msg := q.ready[0]
+	q.ready[0] = nil
+	q.ready = q.ready[1:]
+	ch.deliveryTag++
+	if !noAck {
+		ch.unacked[ch.deliveryTag] = &delivery{queue: q, msg: msg}
+	}
+	ch.conn.push(methodFrame(ch.id, basicGetOk, func(e *encoder) {
+		e.longlong(ch.deliveryTag)
+		e.bits(msg.redelivered)
+		e.shortstr(msg.exchange)
+		e.shortstr(msg.routingKey)
+		e.long(uint32(len(q.ready)))
+	}))
+	ch.pushContent(msg)
+	return nil
+}
+
+// basicAck handles basic.ack, basic.reject and basic.nack from consumers
+func (ch *channel) basicAck(m methodID, d *decoder) error {
+	tag := d.longlong()
+	flags := d.octet()
+	if d.err != nil {
+		return nil
+	}
+	var multiple, requeue bool
+	switch m {
+	case basicAck:
+		multiple = flags&1 != 0
+	case basicReject:
+		requeue = flags&1 != 0
+	case basicNack:
+		multiple, requeue = flags&1 != 0, flags&2 != 0
+	}
+
+	var tags []uint64
+	if multiple {
+		for t := range ch.unacked {
+			if tag == 0 || t <= tag {
+				tags = append(tags, t)
+			}
+		}
+	} else if _, ok := ch.unacked[tag]; ok {
+		tags = []uint64{tag}
+	}
+	if len(tags) == 0 && (tag != 0 || !multiple) {
+		return channelError(replyPreconditionFailed, m, "unknown delivery tag %d", tag)
+	}
+
+	if requeue {
+		ch.requeue(tags)
+	} else {
+		for _, t := range tags {
+			delete(ch.unacked, t)
+		}
+	}
+	ch.conn.server.broker.dispatchAll()
+	return nil
+}
@@ -0,0 +1,344 @@
+package amqp
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/subtle"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/probitas-test/echo-servers/echo-amqp/outbox"
+)
+
+const (
+	// shutdownTimeout bounds how long queued frames are written after the
+	// connection was shut down
+	shutdownTimeout = time.Second
+
+	// Limits proposed in connection.tune
+	channelMax = 2047
+	frameMax   = 131072
+
+	// minFrameMax is the smallest frame-max a client may choose
+	minFrameMax = 4096
+
+	// maxBodySize is the largest message body accepted
+	maxBodySize = 128 << 20
+
+	// handshakeTimeout bounds the connection handshake
+	handshakeTimeout = 10 * time.Second
+
+	// Product properties announced in connection.start
+	product = "echo-amqp"
+	version = "1.0.0"
+)
+
+// conn is the state of a client connection. Its channels are guarded by the
+// broker lock.
+type conn struct {
+	server *Server
+	nc     net.Conn
+	out    *outbox.Outbox
+
+	frameMax     uint32
+	channelMax   uint16
+	heartbeat    time.Duration
+	cancelNotify bool
+
+	channels map[uint16]*channel
+
+	// closing is set once connection.close was sent; only close-ok and
+	// close are handled afterwards
+	closing bool
+
+	// done ends the read loop
+	done bool
+
+	shutdownOnce sync.Once
+	stop         chan struct{}
+}
+
+func newConn(s *Server, nc net.Conn) *conn {
+	return &conn{
+		server:     s,
+		nc:         nc,
+		out:        outbox.New(),
+		frameMax:   frameMax,
+		channelMax: channelMax,
+		channels:   make(map[uint16]*channel),
+		stop:       make(chan struct{}),
+	}
+}
+
+// push queues frames for the client
+func (c *conn) push(frames ...[]byte) {
+	c.out.Push(frames...)
+}
+
+// writeLoop writes queued frames and, once negotiated, heartbeats
+func (c *conn) writeLoop() {
+	c.out.Write(c.nc)
+}
+
+// shutdown writes the queued frames, then closes the connection
+func (c *conn) shutdown() {
+	c.shutdownOnce.Do(func() {
+		close(c.stop)
+		_ = c.nc.SetWriteDeadline(time.Now().Add(shutdownTimeout))
+		c.out.Close()
+	})
+}
+
+// sendHeartbeats sends a heartbeat frame every interval until shutdown
+func (c *conn) sendHeartbeats(interval time.Duration) {
+	heartbeat := frame{typ: frameHeartbeat}.encode()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.push(heartbeat)
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+// handshake negotiates the connection: protocol header, start, tune and
+// open. The connection is closed when it fails.
+func (c *conn) handshake(r *bufio.Reader) error {
+	_ = c.nc.SetReadDeadline(time.Now().Add(handshakeTimeout))
+
+	header := make([]byte, len(protocolHeader))
+	if _, err := io.ReadFull(r, header); err != nil {
+		return err
+	}
+	if string(header) != protocolHeader {
+		// Announce the supported protocol version, then close
+		c.push([]byte(protocolHeader))
+		return errors.New("unsupported protocol")
+	}
+
+	c.push(methodFrame(0, connectionStart, func(e *encoder) {
+		e.octet(0)
+		e.octet(9)
+		e.table(Table{
+			"product":  product,
+			"version":  version,
+			"platform": "Go",
+			"capabilities": Table{
+				"publisher_confirms":         true,
+				"basic.nack":                 true,
+				"consumer_cancel_notify":     true,
+				"exchange_exchange_bindings": false,
+				"connection.blocked":         false,
+				"per_consumer_qos":           false,
+			},
+		})
+		e.longstr([]byte("PLAIN"))
+		e.longstr([]byte("en_US"))
+	}))
+
+	d, err := c.readMethod(r, connectionStartOk)
+	if err != nil {
+		return err
+	}
+	clientProps := d.table()
+	mechanism := d.shortstr()
+	response := d.longstr()
+	d.shortstr()
+	if d.err != nil {
+		return d.err
+	}
+	if caps, ok := clientProps["capabilities"].(Table); ok {
+		c.cancelNotify, _ = caps["consumer_cancel_notify"].(bool)
+	}
+	if mechanism != "PLAIN" || !c.authenticate(response) {
+		c.push(closeFrame(0, connectionError(replyAccessRefused, connectionStartOk,
+			"Login was refused using authentication mechanism %s", mechanism)))
+		return errors.New("access refused")
+	}
+
+	heartbeat := uint16(c.server.cfg.Heartbeat / time.Second)
+	c.push(methodFrame(0, connectionTune, func(e *encoder) {
+		e.short(channelMax)
+		e.long(frameMax)
+		e.short(heartbeat)
+	}))
+
+	if d, err = c.readMethod(r, connectionTuneOk); err != nil {
+		return err
+	}
+	if n := d.short(); n > 0 && n < channelMax {
+		c.channelMax = n
+	}
+	if n := d.long(); n > 0 && n < frameMax {
+		c.frameMax = max(n, minFrameMax)
+	}
+	c.heartbeat = time.Duration(d.short()) * time.Second
+	if d.err != nil {
+		return d.err
+	}
+
+	if _, err = c.readMethod(r, connectionOpen); err != nil {
+		return err
+	}
+	c.push(methodFrame(0, connectionOpenOk, func(e *encoder) {
+		e.shortstr("")
+	}))
+
+	_ = c.nc.SetReadDeadline(time.Time{})
+	if c.heartbeat > 0 {
+		go c.sendHeartbeats(c.heartbeat / 2)
+	}
+	return nil
+}
+
+// authenticate checks a PLAIN response ("\x00user\x00password") when
+// credentials are configured
+func (c *conn) authenticate(response []byte) bool {
+	cfg := c.server.cfg
+	if cfg.Username == "" {
+		return true
+	}
+	parts := bytes.SplitN(response, []byte{0}, 3)
+	if len(parts) != 3 {
+		return false
+	}
+	username := subtle.ConstantTimeCompare(parts[1], []byte(cfg.Username))
+	password := subtle.ConstantTimeCompare(parts[2], []byte(cfg.Password))
+	return username&password == 1
+}
+
+// readMethod reads the next method frame on channel 0 during the handshake,
+// skipping heartbeats, and fails unless it is want
+func (c *conn) readMethod(r *bufio.Reader, want methodID) (*decoder, error) {
+	for {
+		f, err := readFrame(r, frameMax)
+		if err != nil {
+			return nil, err
+		}
+		if f.typ == frameHeartbeat {
+			continue
+		}
+		d := &decoder{buf: f.payload}
+		m := methodID{d.short(), d.short()}
+		if f.typ != frameMethod || f.channel != 0 || m != want {
+			return nil, errors.New("unexpected frame during handshake")
+		}
+		return d, nil
+	}
+}
+
+// serve handles frames until the connection is closed. Clients silent for
+// three heartbeat intervals are disconnected.
+func (c *conn) serve(r *bufio.Reader) {
+	for !c.done {
+		if c.heartbeat > 0 && !c.closing {
+			_ = c.nc.SetReadDeadline(time.Now().Add(3 * c.heartbeat))
+		}
+		f, err := readFrame(r, c.frameMax)
+		if err != nil {
+			if !c.closing && !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				var netErr net.Error
+				if !errors.As(err, &netErr) {
+					c.close(connectionError(replyFrameError, methodID{}, "%v", err))
+				}
+			}
+			return
+		}
+
+		if err := c.handleFrame(f); err != nil {
+			var amqpErr *amqpError
+			if !errors.As(err, &amqpErr) {
+				return
+			}
+			if amqpErr.hard {
+				c.close(amqpErr)
+			} else {
+				c.closeChannel(f.channel, amqpErr)
+			}
+		}
+	}
+}
+
+// close sends connection.close and waits briefly for the close-ok
+func (c *conn) close(err *amqpError) {
+	c.push(closeFrame(0, err))
+	c.closing = true
+	_ = c.nc.SetReadDeadline(time.Now().Add(shutdownTimeout))
+}
+
+func (c *conn) handleFrame(f frame) error {
+	switch f.typ {
+	case frameHeartbeat:
+		return nil
+
+	case frameMethod:
+		d := &decoder{buf: f.payload}
+		m := methodID{d.short(), d.short()}
+		if d.err != nil {
+			return connectionError(replySyntaxError, methodID{}, "malformed method frame")
+		}
+		if f.channel == 0 {
+			return c.connectionMethod(m)
+		}
+		if c.closing {
+			return nil
+		}
+		if m == basicPublish {
+			// Outside the broker lock, so only this connection waits
+			c.server.delay()
+		}
+		c.server.broker.mu.Lock()
+		defer c.server.broker.mu.Unlock()
+		return c.channelMethod(f.channel, m, d)
+
+	case frameHeader, frameBody:
+		if c.closing {
+			return nil
+		}
+		c.server.broker.mu.Lock()
+		defer c.server.broker.mu.Unlock()
+		return c.content(f)
+
+	default:
+		return connectionError(replyFrameError, methodID{}, "unknown frame type %d", f.typ)
+	}
+}
+
+func (c *conn) connectionMethod(m methodID) error {
+	switch m {
+	case connectionClose:
+		c.push(methodFrame(0, connectionCloseOk, nil))
+		c.done = true
+		return nil
+	case connectionCloseOk:
+		c.done = true
+		return nil
+	}
+	if c.closing {
+		return nil
+	}
+	return connectionError(replyCommandInvalid, m, "unexpected %s on channel 0", m)
+}
+
+// release requeues the unacknowledged messages of all channels, cancels
+// their consumers and deletes the exclusive queues of the connection
+func (c *conn) release() {
+	b := c.server.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, ch := range c.channels {
+		ch.release()
+	}
+	c.channels = nil
+	for _, q := range b.queues {
+		if q.owner == c {
+			b.deleteQueue(q)
+		}
+	}
+}
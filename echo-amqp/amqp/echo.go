@@ -0,0 +1,136 @@
+package amqp
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/probitas-test/echo-servers/echo-amqp/ack"
+	"github.com/probitas-test/echo-servers/shared/tracing"
+)
+
+// Basic property flags, from the highest bit down
+const (
+	flagContentType     = 1 << 15
+	flagContentEncoding = 1 << 14
+	flagHeaders         = 1 << 13
+	flagDeliveryMode    = 1 << 12
+	flagPriority        = 1 << 11
+	flagCorrelationID   = 1 << 10
+	flagReplyTo         = 1 << 9
+)
+
+// properties are the basic properties the broker reads from a message
+type properties struct {
+	headers Table
+	replyTo string
+}
+
+// parseProperties reads the headers and reply-to properties from the raw
+// property flags and list of a content header
+func parseProperties(raw []byte) (properties, error) {
+	var props properties
+	d := &decoder{buf: raw}
+	flags := d.short()
+	if flags&flagContentType != 0 {
+		d.shortstr()
+	}
+	if flags&flagContentEncoding != 0 {
+		d.shortstr()
+	}
+	if flags&flagHeaders != 0 {
+		props.headers = d.table()
+	}
+	if flags&flagDeliveryMode != 0 {
+		d.octet()
+	}
+	if flags&flagPriority != 0 {
+		d.octet()
+	}
+	if flags&flagCorrelationID != 0 {
+		d.shortstr()
+	}
+	if flags&flagReplyTo != 0 {
+		props.replyTo = d.shortstr()
+	}
+	return props, d.err
+}
+
+// publish routes a message published on ch and echoes it, then confirms it
+// per the ack mode when ch is in confirm mode. Messages rejected by the ack
+// mode are neither routed nor echoed. Each publish is traced in a span
+// parented to the traceparent header; the message itself, trace headers
+// included, is routed unchanged.
+func (b *broker) publish(ch *channel, ex *exchange, msg *message, mandatory bool) error {
+	props, err := parseProperties(msg.properties)
+	if err != nil {
+		return connectionError(replySyntaxError, basicPublish, "malformed message properties")
+	}
+	destination := ex.name
+	if destination == "" {
+		destination = "amq.default"
+	}
+	_, span := tracing.Start(context.Background(), tableCarrier(props.headers), "publish "+destination, trace.SpanKindConsumer,
+		attribute.String("messaging.system", "rabbitmq"),
+		attribute.String("messaging.destination.name", destination),
+		attribute.String("messaging.rabbitmq.destination.routing_key", msg.routingKey),
+	)
+	defer span.End()
+
+	mode := ch.conn.server.cfg.AckMode
+	if value, ok := props.headers[ack.Header].(string); ok {
+		mode = ack.Resolve(value, mode)
+	}
+
+	if mode != ack.Nack {
+		queues := b.route(ex, msg.routingKey)
+		for _, q := range queues {
+			b.enqueue(q, msg)
+		}
+		if len(queues) == 0 && mandatory {
+			ch.returnMessage(msg, replyNoRoute, "NO_ROUTE")
+		}
+		b.echo(msg, props.replyTo, ch.conn.server.cfg.EchoPrefix)
+	}
+
+	if ch.confirm {
+		ch.publishSeq++
+		switch mode {
+		case ack.Ack:
+			ch.conn.push(methodFrame(ch.id, basicAck, func(e *encoder) {
+				e.longlong(ch.publishSeq)
+				e.bits(false)
+			}))
+		case ack.Nack:
+			ch.conn.push(methodFrame(ch.id, basicNack, func(e *encoder) {
+				e.longlong(ch.publishSeq)
+				e.bits(false, false)
+			}))
+		}
+	}
+	return nil
+}
+
+// echo copies msg through the default exchange to the queue named by
+// replyTo or, without one, prefix + routing key. Messages already routed to
+// a prefixed key are not echoed again, and echoes to missing queues are
+// dropped.
+func (b *broker) echo(msg *message, replyTo, prefix string) {
+	target := replyTo
+	if target == "" {
+		if strings.HasPrefix(msg.routingKey, prefix) {
+			return
+		}
+		target = prefix + msg.routingKey
+	}
+	q, ok := b.queues[target]
+	if !ok {
+		return
+	}
+	echo := *msg
+	echo.exchange = ""
+	echo.routingKey = target
+	b.enqueue(q, &echo)
+}
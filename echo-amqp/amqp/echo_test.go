@@ -0,0 +1,34 @@
+package amqp
+
+import "testing"
+
+func TestParseProperties(t *testing.T) {
+	e := &encoder{}
+	e.short(flagContentType | flagHeaders | flagDeliveryMode | flagCorrelationID | flagReplyTo)
+	e.shortstr("text/plain")
+	e.table(Table{"x-echo-ack": "nack"})
+	e.octet(2)
+	e.shortstr("42")
+	e.shortstr("replies")
+
+	props, err := parseProperties(e.buf)
+	if err != nil {
+		t.Fatalf("parseProperties: %v", err)
+	}
+	if props.replyTo != "replies" {
+		t.Errorf("replyTo = %q, want replies", props.replyTo)
+	}
+	if props.headers["x-echo-ack"] != "nack" {
+		t.Errorf("headers = %v", props.headers)
+	}
+}
+
+func TestParsePropertiesEmpty(t *testing.T) {
+	props, err := parseProperties([]byte{0, 0})
+	if err != nil || props.replyTo != "" || props.headers != nil {
+		t.Errorf("parseProperties = %+v, %v", props, err)
+	}
+	if _, err := parseProperties([]byte{0x02, 0, 5}); err == nil {
+		t.Error("parseProperties accepted a truncated reply-to")
+	}
+}
@@ -0,0 +1,200 @@
+package amqp
+
+import "fmt"
+
+// Class ids
+const (
+	classConnection = 10
+	classChannel    = 20
+	classExchange   = 40
+	classQueue      = 50
+	classBasic      = 60
+	classConfirm    = 85
+	classTx         = 90
+)
+
+// methodID identifies a method by class and method id
+type methodID struct {
+	class, method uint16
+}
+
+func (m methodID) String() string {
+	if name, ok := methodNames[m]; ok {
+		return name
+	}
+	return fmt.Sprintf("method %d.%d", m.class, m.method)
+}
+
+// Methods used by the server
+var (
+	connectionStart   = methodID{classConnection, 10}
+	connectionStartOk = methodID{classConnection, 11}
+	connectionSecure  = methodID{classConnection, 20}
+	connectionTune    = methodID{classConnection, 30}
+	connectionTuneOk  = methodID{classConnection, 31}
+	connectionOpen    = methodID{classConnection, 40}
+	connectionOpenOk  = methodID{classConnection, 41}
+	connectionClose   = methodID{classConnection, 50}
+	connectionCloseOk = methodID{classConnection, 51}
+
+	channelOpen    = methodID{classChannel, 10}
+	channelOpenOk  = methodID{classChannel, 11}
+	channelFlow    = methodID{classChannel, 20}
+	channelFlowOk  = methodID{classChannel, 21}
+	channelClose   = methodID{classChannel, 40}
+	channelCloseOk = methodID{classChannel, 41}
+
+	exchangeDeclare   = methodID{classExchange, 10}
+	exchangeDeclareOk = methodID{classExchange, 11}
+	exchangeDelete    = methodID{classExchange, 20}
+	exchangeDeleteOk  = methodID{classExchange, 21}
+
+	queueDeclare   = methodID{classQueue, 10}
+	queueDeclareOk = methodID{classQueue, 11}
+	queueBind      = methodID{classQueue, 20}
+	queueBindOk    = methodID{classQueue, 21}
+	queuePurge     = methodID{classQueue, 30}
+	queuePurgeOk   = methodID{classQueue, 31}
+	queueDelete    = methodID{classQueue, 40}
+	queueDeleteOk  = methodID{classQueue, 41}
+	queueUnbind    = methodID{classQueue, 50}
+	queueUnbindOk  = methodID{classQueue, 51}
+
+	basicQos          = methodID{classBasic, 10}
+	basicQosOk        = methodID{classBasic, 11}
+	basicConsume      = methodID{classBasic, 20}
+	basicConsumeOk    = methodID{classBasic, 21}
+	basicCancel       = methodID{classBasic, 30}
+	basicCancelOk     = methodID{classBasic, 31}
+	basicPublish      = methodID{classBasic, 40}
+	basicReturn       = methodID{classBasic, 50}
+	basicDeliver      = methodID{classBasic, 60}
+	basicGet          = methodID{classBasic, 70}
+	basicGetOk        = methodID{classBasic, 71}
+	basicGetEmpty     = methodID{classBasic, 72}
+	basicAck          = methodID{classBasic, 80}
+	basicReject       = methodID{classBasic, 90}
+	basicRecoverAsync = methodID{classBasic, 100}
+	basicRecover      = methodID{classBasic, 110}
+	basicRecoverOk    = methodID{classBasic, 111}
+	basicNack         = methodID{classBasic, 120}
+
+	confirmSelect   = methodID{classConfirm, 10}
+	confirmSelectOk = methodID{classConfirm, 11}
+)
+
+var methodNames = map[methodID]string{
+	connectionStartOk: "connection.start-ok",
+	connectionSecure:  "connection.secure",
+	connectionTuneOk:  "connection.tune-ok",
+	connectionOpen:    "connection.open",
+	connectionClose:   "connection.close",
+	connectionCloseOk: "connection.close-ok",
+	channelOpen:       "channel.open",
+	channelFlow:       "channel.flow",
+	channelFlowOk:     "channel.flow-ok",
+	channelClose:      "channel.close",
+	channelCloseOk:    "channel.close-ok",
+	exchangeDeclare:   "exchange.declare",
+	exchangeDelete:    "exchange.delete",
+	queueDeclare:      "queue.declare",
+	queueBind:         "queue.bind",
+	queuePurge:        "queue.purge",
+	queueDelete:       "queue.delete",
+	queueUnbind:       "queue.unbind",
+	basicQos:          "basic.qos",
+	basicConsume:      "basic.consume",
+	basicCancel:       "basic.cancel",
+	basicPublish:      "basic.publish",
+	basicGet:          "basic.get",
+	basicAck:          "basic.ack",
+	basicReject:       "basic.reject",
+	basicRecoverAsync: "basic.recover-async",
+	basicRecover:      "basic.recover",
+	basicNack:         "basic.nack",
+	confirmSelect:     "confirm.select",
+}
+
+// Reply codes
+const (
+	replySuccess            = 200
+	replyContentTooLarge    = 311
+	replyNoRoute            = 312
+	replyConnectionForced   = 320
+	replyAccessRefused      = 403
+	replyNotFound           = 404
+	replyResourceLocked     = 405
+	replyPreconditionFailed = 406
+	replyFrameError         = 501
+	replySyntaxError        = 502
+	replyCommandInvalid     = 503
+	replyChannelError       = 504
+	replyUnexpectedFrame    = 505
+	replyNotAllowed         = 530
+	replyNotImplemented     = 540
+)
+
+// replyTexts prefixes reply texts like RabbitMQ does
+var replyTexts = map[uint16]string{
+	replyContentTooLarge:    "CONTENT_TOO_LARGE",
+	replyNoRoute:            "NO_ROUTE",
+	replyConnectionForced:   "CONNECTION_FORCED",
+	replyAccessRefused:      "ACCESS_REFUSED",
+	replyNotFound:           "NOT_FOUND",
+	replyResourceLocked:     "RESOURCE_LOCKED",
+	replyPreconditionFailed: "PRECONDITION_FAILED",
+	replyFrameError:         "FRAME_ERROR",
+	replySyntaxError:        "SYNTAX_ERROR",
+	replyCommandInvalid:     "COMMAND_INVALID",
+	replyChannelError:       "CHANNEL_ERROR",
+	replyUnexpectedFrame:    "UNEXPECTED_FRAME",
+	replyNotAllowed:         "NOT_ALLOWED",
+	replyNotImplemented:     "NOT_IMPLEMENTED",
+}
+
+// amqpError is an exception closing a channel or, when hard, the connection
+type amqpError struct {
+	code   uint16
+	text   string
+	method methodID
+	hard   bool
+}
+
+func (e *amqpError) Error() string {
+	return replyTexts[e.code] + " - " + e.text
+}
+
+// channelError returns a soft error closing the channel
+func channelError(code uint16, method methodID, format string, args ...any) *amqpError {
+	return &amqpError{code: code, text: fmt.Sprintf(format, args...), method: method}
+}
+
+// connectionError returns a hard error closing the connection
+func connectionError(code uint16, method methodID, format string, args ...any) *amqpError {
+	return &amqpError{code: code, text: fmt.Sprintf(format, args...), method: method, hard: true}
+}
+
+// methodFrame encodes a method frame with the arguments written by args
+func methodFrame(channel uint16, m methodID, args func(e *encoder)) []byte {
+	e := &encoder{}
+	e.short(m.class)
+	e.short(m.method)
+	if args != nil {
+		args(e)
+	}
+	return frame{typ: frameMethod, channel: channel, payload: e.buf}.encode()
+}
+
+// closeFrame encodes a connection.close (channel 0) or channel.close
+func closeFrame(channel uint16, err *amqpError) []byte {
+	m := channelClose
+	if channel == 0 {
+		m = connectionClose
+	}
+	return methodFrame(channel, m, func(e *encoder) {
+		e.short(err.code)
+		e.shortstr(err.Error())
+		e.short(err.method.class)
+		e.short(err.method.method)
+	})
+}
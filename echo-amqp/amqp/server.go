@@ -0,0 +1,132 @@
+package amqp
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/probitas-test/echo-servers/echo-amqp/ack"
+)
+
+// ErrServerClosed is returned by Serve after Close
+var ErrServerClosed = errors.New("server closed")
+
+// Config controls the broker
+type Config struct {
+	// EchoPrefix is prepended to the routing key to name the queue a
+	// message is echoed to when it has no reply-to property
+	EchoPrefix string
+
+	// AckMode answers publishes on channels in confirm mode
+	AckMode ack.Mode
+
+	// Heartbeat is the heartbeat interval proposed to clients (0 = disabled)
+	Heartbeat time.Duration
+
+	// Credentials required from clients (not checked when Username is empty)
+	Username string
+	Password string
+
+	// Delay, if set, is read on every publish and delays its delivery and
+	// echo
+	Delay func() time.Duration
+}
+
+// Server is an in-memory AMQP 0-9-1 broker echoing every published message
+// to a reply queue
+type Server struct {
+	cfg    Config
+	broker *broker
+
+	mu       sync.Mutex
+	listener net.Listener
+	conns    map[*conn]struct{}
+	closed   bool
+}
+
+// New creates a broker with the default exchanges
+func New(cfg Config) *Server {
+	return &Server{
+		cfg:    cfg,
+		broker: newBroker(),
+		conns:  make(map[*conn]struct{}),
+	}
+}
+
+// Serve accepts connections on l until Close is called
+func (s *Server) Serve(l net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return ErrServerClosed
+	}
+	s.listener = l
+	s.mu.Unlock()
+
+	for {
+		nc, err := l.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return ErrServerClosed
+			}
+			return err
+		}
+		go s.serveConn(nc)
+	}
+}
+
+// Close stops accepting connections and closes the open ones with
+// CONNECTION_FORCED
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	for c := range s.conns {
+		c.push(closeFrame(0, connectionError(replyConnectionForced, methodID{}, "broker shutdown")))
+		c.shutdown()
+	}
+	if s.listener != nil {
+		return s.listener.Close()
+	}
+	return nil
+}
+
+// delay waits for the configured delay of a message
+func (s *Server) delay() {
+	if s.cfg.Delay != nil {
+		if d := s.cfg.Delay(); d > 0 {
+			time.Sleep(d)
+		}
+	}
+}
+
+func (s *Server) serveConn(nc net.Conn) {
+	c := newConn(s, nc)
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		_ = nc.Close()
+		return
+	}
+	s.conns[c] = struct{}{}
+	s.mu.Unlock()
+	go c.writeLoop()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, c)
+		s.mu.Unlock()
+		c.release()
+		c.shutdown()
+	}()
+
+	r := bufio.NewReader(nc)
+	if err := c.handshake(r); err != nil {
+		return
+	}
+	c.serve(r)
+}
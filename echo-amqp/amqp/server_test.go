@@ -0,0 +1,397 @@
+package amqp
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	amqp091 "github.com/rabbitmq/amqp091-go"
+
+	"github.com/probitas-test/echo-servers/echo-amqp/ack"
+)
+
+func startServer(t *testing.T, cfg Config) string {
+	t.Helper()
+	if cfg.EchoPrefix == "" {
+		cfg.EchoPrefix = "echo."
+	}
+	if cfg.AckMode == "" {
+		cfg.AckMode = ack.Ack
+	}
+	s := New(cfg)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	go func() { _ = s.Serve(l) }()
+	t.Cleanup(func() { _ = s.Close() })
+	return "amqp://guest:guest@" + l.Addr().String() + "/"
+}
+
+func dial(t *testing.T, url string) (*amqp091.Connection, *amqp091.Channel) {
+	t.Helper()
+	conn, err := amqp091.Dial(url)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	ch, err := conn.Channel()
+	if err != nil {
+		t.Fatalf("Channel: %v", err)
+	}
+	return conn, ch
+}
+
+func declare(t *testing.T, ch *amqp091.Channel, name string) string {
+	t.Helper()
+	q, err := ch.QueueDeclare(name, false, false, false, false, nil)
+	if err != nil {
+		t.Fatalf("QueueDeclare(%q): %v", name, err)
+	}
+	return q.Name
+}
+
+func consume(t *testing.T, ch *amqp091.Channel, queue string, autoAck bool) <-chan amqp091.Delivery {
+	t.Helper()
+	deliveries, err := ch.Consume(queue, "", autoAck, false, false, false, nil)
+	if err != nil {
+		t.Fatalf("Consume(%q): %v", queue, err)
+	}
+	return deliveries
+}
+
+func receive(t *testing.T, deliveries <-chan amqp091.Delivery) amqp091.Delivery {
+	t.Helper()
+	select {
+	case d := <-deliveries:
+		return d
+	case <-time.After(2 * time.Second):
+		t.Fatal("no delivery")
+		return amqp091.Delivery{}
+	}
+}
+
+func publish(t *testing.T, ch *amqp091.Channel, exchange, key string, msg amqp091.Publishing) {
+	t.Helper()
+	if err := ch.Publish(exchange, key, false, false, msg); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+}
+
+func TestEcho(t *testing.T) {
+	_, ch := dial(t, startServer(t, Config{}))
+	declare(t, ch, "orders")
+	declare(t, ch, "echo.orders")
+	orders := consume(t, ch, "orders", true)
+	echoes := consume(t, ch, "echo.orders", true)
+
+	publish(t, ch, "", "orders", amqp091.Publishing{
+		Body:          []byte("hello"),
+		ContentType:   "text/plain",
+		CorrelationId: "42",
+		Headers:       amqp091.Table{"trace": "abc"},
+	})
+
+	if d := receive(t, orders); string(d.Body) != "hello" || d.RoutingKey != "orders" {
+		t.Errorf("delivery = %q to %q", d.Body, d.RoutingKey)
+	}
+	d := receive(t, echoes)
+	if string(d.Body) != "hello" || d.RoutingKey != "echo.orders" || d.Exchange != "" {
+		t.Errorf("echo = %q to %q/%q", d.Body, d.Exchange, d.RoutingKey)
+	}
+	if d.ContentType != "text/plain" || d.CorrelationId != "42" || d.Headers["trace"] != "abc" {
+		t.Errorf("echo properties = %q %q %v", d.ContentType, d.CorrelationId, d.Headers)
+	}
+
+	// Messages on echo queues are not echoed again
+	declare(t, ch, "echo.echo.orders")
+	publish(t, ch, "", "echo.orders", amqp091.Publishing{Body: []byte("again")})
+	receive(t, echoes)
+	if msg, ok, _ := ch.Get("echo.echo.orders", true); ok {
+		t.Errorf("echo of an echo: %q", msg.Body)
+	}
+}
+
+func TestDelay(t *testing.T) {
+	delay := func() time.Duration { return 200 * time.Millisecond }
+	_, ch := dial(t, startServer(t, Config{Delay: delay}))
+	replies := declare(t, ch, "")
+	deliveries := consume(t, ch, replies, true)
+
+	start := time.Now()
+	publish(t, ch, "", "orders", amqp091.Publishing{Body: []byte("ping"), ReplyTo: replies})
+	receive(t, deliveries)
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("echo after %v, want the 200ms delay", elapsed)
+	}
+}
+
+func TestEchoReplyTo(t *testing.T) {
+	_, ch := dial(t, startServer(t, Config{}))
+	replies := declare(t, ch, "")
+	deliveries := consume(t, ch, replies, true)
+
+	// Unroutable messages are echoed as well
+	publish(t, ch, "amq.topic", "orders.created", amqp091.Publishing{Body: []byte("ping"), ReplyTo: replies})
+
+	d := receive(t, deliveries)
+	if string(d.Body) != "ping" || d.RoutingKey != replies || d.ReplyTo != replies {
+		t.Errorf("echo = %q to %q", d.Body, d.RoutingKey)
+	}
+}
+
+func TestTopicRouting(t *testing.T) {
+	_, ch := dial(t, startServer(t, Config{}))
+	q := declare(t, ch, "")
+	if err := ch.QueueBind(q, "orders.#", "amq.topic", false, nil); err != nil {
+		t.Fatalf("QueueBind: %v", err)
+	}
+	deliveries := consume(t, ch, q, true)
+
+	publish(t, ch, "amq.topic", "payments.created", amqp091.Publishing{Body: []byte("skip")})
+	publish(t, ch, "amq.topic", "orders.created.eu", amqp091.Publishing{Body: []byte("match")})
+	if d := receive(t, deliveries); string(d.Body) != "match" || d.Exchange != "amq.topic" {
+		t.Errorf("delivery = %q from %q", d.Body, d.Exchange)
+	}
+}
+
+func TestConfirms(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     ack.Mode
+		header   string
+		want     bool
+		answered bool
+		routed   bool
+	}{
+		{"ack", ack.Ack, "", true, true, true},
+		{"nack", ack.Nack, "", false, true, false},
+		{"none", ack.None, "", false, false, true},
+		{"header nack", ack.Ack, "nack", false, true, false},
+		{"header ack", ack.Nack, "ack", true, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, ch := dial(t, startServer(t, Config{AckMode: tt.mode}))
+			declare(t, ch, "orders")
+			if err := ch.Confirm(false); err != nil {
+				t.Fatalf("Confirm: %v", err)
+			}
+			confirms := ch.NotifyPublish(make(chan amqp091.Confirmation, 1))
+
+			msg := amqp091.Publishing{Body: []byte("hello")}
+			if tt.header != "" {
+				msg.Headers = amqp091.Table{ack.Header: tt.header}
+			}
+			publish(t, ch, "", "orders", msg)
+
+			select {
+			case c := <-confirms:
+				if !tt.answered {
+					t.Fatalf("unexpected confirmation %+v", c)
+				}
+				if c.DeliveryTag != 1 || c.Ack != tt.want {
+					t.Errorf("confirmation = %+v, want ack %v", c, tt.want)
+				}
+			case <-time.After(200 * time.Millisecond):
+				if tt.answered {
+					t.Fatal("no confirmation")
+				}
+			}
+
+			_, routed, err := ch.Get("orders", true)
+			if err != nil || routed != tt.routed {
+				t.Errorf("Get = %v, %v; want routed %v", routed, err, tt.routed)
+			}
+		})
+	}
+}
+
+func TestMandatoryReturn(t *testing.T) {
+	_, ch := dial(t, startServer(t, Config{}))
+	returns := ch.NotifyReturn(make(chan amqp091.Return, 1))
+	if err := ch.Publish("", "missing", true, false, amqp091.Publishing{Body: []byte("lost")}); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	select {
+	case r := <-returns:
+		if r.ReplyCode != replyNoRoute || string(r.Body) != "lost" {
+			t.Errorf("return = %d %q", r.ReplyCode, r.Body)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no return")
+	}
+}
+
+func TestRequeue(t *testing.T) {
+	_, ch := dial(t, startServer(t, Config{}))
+	declare(t, ch, "jobs")
+	deliveries := consume(t, ch, "jobs", false)
+	publish(t, ch, "", "jobs", amqp091.Publishing{Body: []byte("job")})
+
+	d := receive(t, deliveries)
+	if d.Redelivered {
+		t.Error("first delivery is redelivered")
+	}
+	if err := d.Nack(false, true); err != nil {
+		t.Fatalf("Nack: %v", err)
+	}
+	d = receive(t, deliveries)
+	if !d.Redelivered || string(d.Body) != "job" {
+		t.Errorf("redelivery = %q, redelivered %v", d.Body, d.Redelivered)
+	}
+	if err := d.Ack(false); err != nil {
+		t.Fatalf("Ack: %v", err)
+	}
+}
+
+func TestRequeueOnClose(t *testing.T) {
+	conn, ch := dial(t, startServer(t, Config{}))
+	declare(t, ch, "jobs")
+	publish(t, ch, "", "jobs", amqp091.Publishing{Body: []byte("job")})
+	if _, ok, err := ch.Get("jobs", false); !ok || err != nil {
+		t.Fatalf("Get = %v, %v", ok, err)
+	}
+	if err := ch.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		t.Fatalf("Channel: %v", err)
+	}
+	msg, ok, err := ch.Get("jobs", true)
+	if !ok || err != nil || !msg.Redelivered {
+		t.Errorf("Get after close = %v, %v, redelivered %v", ok, err, msg.Redelivered)
+	}
+}
+
+func TestPrefetch(t *testing.T) {
+	_, ch := dial(t, startServer(t, Config{}))
+	declare(t, ch, "jobs")
+	if err := ch.Qos(1, 0, false); err != nil {
+		t.Fatalf("Qos: %v", err)
+	}
+	deliveries := consume(t, ch, "jobs", false)
+	publish(t, ch, "", "jobs", amqp091.Publishing{Body: []byte("1")})
+	publish(t, ch, "", "jobs", amqp091.Publishing{Body: []byte("2")})
+
+	first := receive(t, deliveries)
+	select {
+	case d := <-deliveries:
+		t.Fatalf("delivery %q beyond prefetch", d.Body)
+	case <-time.After(100 * time.Millisecond):
+	}
+	if err := first.Ack(false); err != nil {
+		t.Fatalf("Ack: %v", err)
+	}
+	if d := receive(t, deliveries); string(d.Body) != "2" {
+		t.Errorf("second delivery = %q", d.Body)
+	}
+}
+
+func TestChannelError(t *testing.T) {
+	conn, ch := dial(t, startServer(t, Config{}))
+	_, err := ch.QueueDeclarePassive("missing", false, false, false, false, nil)
+	var amqpErr *amqp091.Error
+	if !errors.As(err, &amqpErr) || amqpErr.Code != replyNotFound {
+		t.Fatalf("QueueDeclarePassive = %v, want 404", err)
+	}
+
+	// The connection survives channel errors
+	ch, err = conn.Channel()
+	if err != nil {
+		t.Fatalf("Channel: %v", err)
+	}
+	declare(t, ch, "orders")
+}
+
+func TestExclusiveQueue(t *testing.T) {
+	url := startServer(t, Config{})
+	conn, ch := dial(t, url)
+	if _, err := ch.QueueDeclare("private", false, false, true, false, nil); err != nil {
+		t.Fatalf("QueueDeclare: %v", err)
+	}
+
+	_, other := dial(t, url)
+	_, err := other.QueueDeclarePassive("private", false, false, true, false, nil)
+	var amqpErr *amqp091.Error
+	if !errors.As(err, &amqpErr) || amqpErr.Code != replyResourceLocked {
+		t.Fatalf("QueueDeclarePassive = %v, want 405", err)
+	}
+
+	// Exclusive queues are deleted with their connection
+	_ = conn.Close()
+	_, other = dial(t, url)
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, err := other.QueueDeclarePassive("private", false, false, true, false, nil); err != nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("exclusive queue survived its connection")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestLargeMessage(t *testing.T) {
+	_, ch := dial(t, startServer(t, Config{}))
+	declare(t, ch, "large")
+	body := make([]byte, 3*frameMax)
+	for i := range body {
+		body[i] = byte(i)
+	}
+	publish(t, ch, "", "large", amqp091.Publishing{Body: body})
+
+	msg, ok, err := ch.Get("large", true)
+	if !ok || err != nil || string(msg.Body) != string(body) {
+		t.Errorf("Get = %v, %v, %d bytes", ok, err, len(msg.Body))
+	}
+}
+
+func TestAuthentication(t *testing.T) {
+	url := startServer(t, Config{Username: "user", Password: "secret"})
+	if conn, err := amqp091.Dial(url); err == nil {
+		_ = conn.Close()
+		t.Fatal("Dial with wrong credentials succeeded")
+	}
+
+	conn, err := amqp091.Dial("amqp://user:secret@" + url[len("amqp://guest:guest@"):])
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	_ = conn.Close()
+}
+
+func TestClose(t *testing.T) {
+	s := New(Config{EchoPrefix: "echo.", AckMode: ack.Ack})
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	served := make(chan error, 1)
+	go func() { served <- s.Serve(l) }()
+
+	conn, err := amqp091.Dial("amqp://guest:guest@" + l.Addr().String() + "/")
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	closed := conn.NotifyClose(make(chan *amqp091.Error, 1))
+
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	select {
+	case amqpErr := <-closed:
+		if amqpErr == nil || amqpErr.Code != replyConnectionForced {
+			t.Errorf("close = %v, want 320", amqpErr)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("connection not closed")
+	}
+	if err := <-served; !errors.Is(err, ErrServerClosed) {
+		t.Errorf("Serve = %v, want ErrServerClosed", err)
+	}
+}
@@ -0,0 +1,20 @@
+package amqp
+
+// tableCarrier reads the trace context from the string headers of a
+// message. Messages are routed unchanged, so it never writes.
+type tableCarrier Table
+
+func (c tableCarrier) Get(key string) string {
+	v, _ := c[key].(string)
+	return v
+}
+
+func (c tableCarrier) Set(string, string) {}
+
+func (c tableCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
@@ -0,0 +1,285 @@
+package amqp
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// protocolHeader starts every AMQP 0-9-1 connection
+const protocolHeader = "AMQP\x00\x00\x09\x01"
+
+// Frame types
+const (
+	frameMethod    = 1
+	frameHeader    = 2
+	frameBody      = 3
+	frameHeartbeat = 8
+	frameEnd       = 0xCE
+)
+
+// frameOverhead is the size of a frame without its payload
+const frameOverhead = 8
+
+// errMalformed is returned when method arguments or tables are truncated
+// or of an unknown type
+var errMalformed = errors.New("malformed frame")
+
+// frame is an AMQP frame
+type frame struct {
+	typ     byte
+	channel uint16
+	payload []byte
+}
+
+// readFrame reads a frame of at most maxSize bytes (0 = unlimited)
+func readFrame(r *bufio.Reader, maxSize uint32) (frame, error) {
+	var head [7]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		return frame{}, err
+	}
+	f := frame{typ: head[0], channel: binary.BigEndian.Uint16(head[1:3])}
+	size := binary.BigEndian.Uint32(head[3:7])
+	if maxSize > 0 && size > maxSize-frameOverhead {
+		return frame{}, fmt.Errorf("frame of %d bytes exceeds frame-max %d", size+frameOverhead, maxSize)
+	}
+	f.payload = make([]byte, size+1)
+	if _, err := io.ReadFull(r, f.payload); err != nil {
+		return frame{}, err
+	}
+	if f.payload[size] != frameEnd {
+		return frame{}, errors.New("invalid frame end")
+	}
+	f.payload = f.payload[:size]
+	return f, nil
+}
+
+// encode returns the wire format of f
+func (f frame) encode() []byte {
+	buf := make([]byte, 7, len(f.payload)+frameOverhead)
+	buf[0] = f.typ
+	binary.BigEndian.PutUint16(buf[1:3], f.channel)
+	binary.BigEndian.PutUint32(buf[3:7], uint32(len(f.payload)))
+	buf = append(buf, f.payload...)
+	return append(buf, frameEnd)
+}
+
+// decoder reads method arguments. The first error is kept in err and later
+// reads return zero values.
+type decoder struct {
+	buf []byte
+	err error
+}
+
+func (d *decoder) take(n int) []byte {
+	if d.err != nil || n < 0 || len(d.buf) < n {
+		d.err = errMalformed
+		return nil
+	}
+	b := d.buf[:n]
+	d.buf = d.buf[n:]
+	return b
+}
+
+func (d *decoder) octet() byte {
+	if b := d.take(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (d *decoder) short() uint16 {
+	if b := d.take(2); b != nil {
+		return binary.BigEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (d *decoder) long() uint32 {
+	if b := d.take(4); b != nil {
+		return binary.BigEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (d *decoder) longlong() uint64 {
+	if b := d.take(8); b != nil {
+		return binary.BigEndian.Uint64(b)
+	}
+	return 0
+}
+
+func (d *decoder) shortstr() string {
+	return string(d.take(int(d.octet())))
+}
+
+func (d *decoder) longstr() []byte {
+	n := d.long()
+	if n > math.MaxInt32 {
+		d.err = errMalformed
+		return nil
+	}
+	return d.take(int(n))
+}
+
+// table decodes a field table. Strings become string, nested tables Table,
+// arrays []any and numbers their Go type.
+func (d *decoder) table() Table {
+	raw := d.longstr()
+	if d.err != nil {
+		return nil
+	}
+	t := Table{}
+	inner := &decoder{buf: raw}
+	for len(inner.buf) > 0 && inner.err == nil {
+		name := inner.shortstr()
+		t[name] = inner.field()
+	}
+	if inner.err != nil {
+		d.err = inner.err
+		return nil
+	}
+	return t
+}
+
+func (d *decoder) field() any {
+	switch typ := d.octet(); typ {
+	case 't':
+		return d.octet() != 0
+	case 'b':
+		return int8(d.octet())
+	case 'B':
+		return d.octet()
+	case 's':
+		return int16(d.short())
+	case 'u':
+		return d.short()
+	case 'I':
+		return int32(d.long())
+	case 'i':
+		return d.long()
+	case 'l':
+		return int64(d.longlong())
+	case 'f':
+		return math.Float32frombits(d.long())
+	case 'd':
+		return math.Float64frombits(d.longlong())
+	case 'D':
+		scale := d.octet()
+		return Decimal{Scale: scale, Value: int32(d.long())}
+	case 'S':
+		return string(d.longstr())
+	case 'x':
+		return d.longstr()
+	case 'A':
+		raw := d.longstr()
+		inner := &decoder{buf: raw}
+		var values []any
+		for len(inner.buf) > 0 && inner.err == nil {
+			values = append(values, inner.field())
+		}
+		if inner.err != nil {
+			d.err = inner.err
+		}
+		return values
+	case 'T':
+		return time.Unix(int64(d.longlong()), 0).UTC()
+	case 'F':
+		// Nested tables are length prefixed like the outer table
+		return d.table()
+	case 'V':
+		return nil
+	default:
+		d.err = errMalformed
+		return nil
+	}
+}
+
+// Table is an AMQP field table
+type Table map[string]any
+
+// Decimal is an AMQP decimal value: Value / 10^Scale
+type Decimal struct {
+	Scale uint8
+	Value int32
+}
+
+// encoder writes method arguments
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) octet(v byte) {
+	e.buf = append(e.buf, v)
+}
+
+func (e *encoder) short(v uint16) {
+	e.buf = binary.BigEndian.AppendUint16(e.buf, v)
+}
+
+func (e *encoder) long(v uint32) {
+	e.buf = binary.BigEndian.AppendUint32(e.buf, v)
+}
+
+func (e *encoder) longlong(v uint64) {
+	e.buf = binary.BigEndian.AppendUint64(e.buf, v)
+}
+
+// shortstr writes s, truncated to 255 bytes
+func (e *encoder) shortstr(s string) {
+	if len(s) > math.MaxUint8 {
+		s = s[:math.MaxUint8]
+	}
+	e.octet(byte(len(s)))
+	e.buf = append(e.buf, s...)
+}
+
+func (e *encoder) longstr(b []byte) {
+	e.long(uint32(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+// bits packs flags into one octet, first flag in the lowest bit
+func (e *encoder) bits(flags ...bool) {
+	var v byte
+	for i, flag := range flags {
+		if flag {
+			v |= 1 << i
+		}
+	}
+	e.octet(v)
+}
+
+// table encodes t. Only the value types the server sends are supported:
+// string, bool, int and nested Table.
+func (e *encoder) table(t Table) {
+	inner := &encoder{}
+	for name, value := range t {
+		inner.shortstr(name)
+		switch v := value.(type) {
+		case string:
+			inner.octet('S')
+			inner.longstr([]byte(v))
+		case bool:
+			inner.octet('t')
+			if v {
+				inner.octet(1)
+			} else {
+				inner.octet(0)
+			}
+		case int:
+			inner.octet('l')
+			inner.longlong(uint64(v))
+		case Table:
+			inner.octet('F')
+			inner.table(v)
+		default:
+			inner.octet('V')
+		}
+	}
+	e.longstr(inner.buf)
+}
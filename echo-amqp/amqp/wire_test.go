@@ -0,0 +1,104 @@
+package amqp
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestFrameRoundTrip(t *testing.T) {
+	want := frame{typ: frameBody, channel: 7, payload: []byte("hello")}
+	got, err := readFrame(bufio.NewReader(bytes.NewReader(want.encode())), frameMax)
+	if err != nil {
+		t.Fatalf("readFrame: %v", err)
+	}
+	if got.typ != want.typ || got.channel != want.channel || string(got.payload) != "hello" {
+		t.Errorf("readFrame = %+v, want %+v", got, want)
+	}
+}
+
+func TestReadFrameErrors(t *testing.T) {
+	badEnd := frame{typ: frameBody, channel: 1, payload: []byte("x")}.encode()
+	badEnd[len(badEnd)-1] = 0
+	if _, err := readFrame(bufio.NewReader(bytes.NewReader(badEnd)), frameMax); err == nil {
+		t.Error("readFrame accepted an invalid frame end")
+	}
+
+	large := frame{typ: frameBody, channel: 1, payload: make([]byte, minFrameMax)}.encode()
+	if _, err := readFrame(bufio.NewReader(bytes.NewReader(large)), minFrameMax); err == nil {
+		t.Error("readFrame accepted a frame above frame-max")
+	}
+}
+
+func TestTableRoundTrip(t *testing.T) {
+	e := &encoder{}
+	e.table(Table{
+		"string": "value",
+		"bool":   true,
+		"int":    42,
+		"nested": Table{"key": "nested"},
+	})
+
+	d := &decoder{buf: e.buf}
+	got := d.table()
+	if d.err != nil {
+		t.Fatalf("table: %v", d.err)
+	}
+	if got["string"] != "value" || got["bool"] != true || got["int"] != int64(42) {
+		t.Errorf("table = %v", got)
+	}
+	if nested, ok := got["nested"].(Table); !ok || nested["key"] != "nested" {
+		t.Errorf("nested = %v", got["nested"])
+	}
+	if len(d.buf) != 0 {
+		t.Errorf("%d bytes left", len(d.buf))
+	}
+}
+
+func TestDecodeFieldTypes(t *testing.T) {
+	field := func(typ byte, value ...byte) []byte {
+		return append([]byte{typ}, value...)
+	}
+	tests := []struct {
+		name string
+		raw  []byte
+		want any
+	}{
+		{"octet", field('B', 200), byte(200)},
+		{"signed octet", field('b', 0xff), int8(-1)},
+		{"short", field('s', 0xff, 0xfe), int16(-2)},
+		{"long", field('I', 0, 0, 1, 0), int32(256)},
+		{"decimal", field('D', 2, 0, 0, 1, 0), Decimal{Scale: 2, Value: 256}},
+		{"timestamp", field('T', 0, 0, 0, 0, 0, 0, 0, 60), time.Unix(60, 0).UTC()},
+		{"void", field('V'), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &decoder{buf: tt.raw}
+			if got := d.field(); got != tt.want || d.err != nil {
+				t.Errorf("field = %#v, %v; want %#v", got, d.err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecoderTruncated(t *testing.T) {
+	d := &decoder{buf: []byte{0, 5, 'a'}}
+	d.short()
+	d.shortstr()
+	if d.err != errMalformed {
+		t.Errorf("err = %v, want errMalformed", d.err)
+	}
+	if d.long() != 0 {
+		t.Error("read after error returned a value")
+	}
+}
+
+func TestBits(t *testing.T) {
+	e := &encoder{}
+	e.bits(true, false, true)
+	if e.buf[0] != 0b101 {
+		t.Errorf("bits = %08b, want 00000101", e.buf[0])
+	}
+}
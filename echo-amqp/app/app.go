@@ -0,0 +1,222 @@
+// Package app runs echo-amqp: LoadConfig reads its settings and Run serves
+// it until its context ends, for the echo-amqp binary and echo-all.
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/probitas-test/echo-servers/echo-amqp/ack"
+	"github.com/probitas-test/echo-servers/echo-amqp/amqp"
+	"github.com/probitas-test/echo-servers/echo-amqp/docs"
+	"github.com/probitas-test/echo-servers/echo-amqp/stomp"
+	"github.com/probitas-test/echo-servers/shared/admin"
+	"github.com/probitas-test/echo-servers/shared/bandwidth"
+	"github.com/probitas-test/echo-servers/shared/connlimit"
+	"github.com/probitas-test/echo-servers/shared/lifecycle"
+	"github.com/probitas-test/echo-servers/shared/logging"
+	"github.com/probitas-test/echo-servers/shared/network"
+	"github.com/probitas-test/echo-servers/shared/tracing"
+	"github.com/probitas-test/echo-servers/shared/version"
+)
+
+// Run serves echo-amqp with cfg until ctx is done, then shuts it down
+// gracefully. Logging and the address family are set up by the caller, as
+// they are shared by the servers of a process.
+func Run(ctx context.Context, cfg *Config) error {
+	// Started servers stop when Run returns early with an error
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Listeners serving in the background stop Run with their error
+	serveErr := make(chan error, 2)
+
+	// Simulated slow startup: no port opens before the startup delay
+	if err := cfg.Lifecycle.Validate(); err != nil {
+		return fmt.Errorf("invalid lifecycle configuration: %w", err)
+	}
+	log.Printf("Lifecycle: %s", cfg.Lifecycle)
+	lifecycle.Delay(ctx, cfg.Lifecycle)
+
+	// OpenTelemetry tracing and W3C trace context propagation
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		return fmt.Errorf("failed to set up tracing: %w", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Error("Tracing shutdown error", "error", err)
+		}
+	}()
+	log.Printf("OpenTelemetry tracing: %s", cfg.Tracing)
+
+	ackMode, err := ack.ParseMode(cfg.AckMode)
+	if err != nil {
+		return fmt.Errorf("invalid ACK_MODE: %w", err)
+	}
+	if cfg.Heartbeat < 0 || cfg.Heartbeat > math.MaxUint16 {
+		return fmt.Errorf("invalid HEARTBEAT %d (must be 0-%d)", cfg.Heartbeat, math.MaxUint16)
+	}
+	if cfg.EchoPrefix == "" {
+		return errors.New("ECHO_PREFIX must not be empty")
+	}
+	heartbeat := time.Duration(cfg.Heartbeat) * time.Second
+
+	// Admin API: health and publish delay
+	adm := admin.New("echo-amqp", cfg.src)
+
+	amqpServer := amqp.New(amqp.Config{
+		EchoPrefix: cfg.EchoPrefix,
+		AckMode:    ackMode,
+		Heartbeat:  heartbeat,
+		Username:   cfg.AuthUsername,
+		Password:   cfg.AuthPassword,
+		Delay:      adm.Delay,
+	})
+	stompServer := stomp.New(stomp.Config{
+		EchoPrefix: cfg.EchoPrefix,
+		AckMode:    ackMode,
+		Heartbeat:  heartbeat,
+		Username:   cfg.AuthUsername,
+		Password:   cfg.AuthPassword,
+		Delay:      adm.Delay,
+	})
+
+	// Bandwidth shaping of every AMQP and STOMP connection, changed at runtime
+	// on the admin port
+	shaper, err := bandwidth.New(cfg.Bandwidth)
+	if err != nil {
+		return err
+	}
+	log.Printf("Bandwidth: %s", cfg.Bandwidth)
+
+	// Cap on the open AMQP and STOMP connections, changed at runtime on the
+	// admin port; the next ones get a server busy error
+	connLimiter, err := connlimit.New(cfg.ConnLimit)
+	if err != nil {
+		return err
+	}
+	log.Printf("Connection limit: %s", cfg.ConnLimit)
+
+	amqpListener, err := network.Listen(cfg.AMQPAddr())
+	if err != nil {
+		return fmt.Errorf("failed to listen for AMQP: %w", err)
+	}
+	defer func() { _ = amqpListener.Close() }()
+	stompListener, err := network.Listen(cfg.STOMPAddr())
+	if err != nil {
+		return fmt.Errorf("failed to listen for STOMP: %w", err)
+	}
+	defer func() { _ = stompListener.Close() }()
+
+	mux := http.NewServeMux()
+
+	// Health check endpoint
+	mux.Handle("GET /health", adm.HealthHandler())
+
+	// Effective configuration (secrets redacted)
+	mux.Handle("GET /config", cfg.src)
+
+	// Address family of the client connection
+	mux.Handle("GET "+network.Path, network.Handler())
+
+	// Build information and enabled features
+	mux.Handle("GET "+version.Path, version.Handler(version.New("echo-amqp", version.Features{
+		"admin":   cfg.Admin.Enabled,
+		"debug":   cfg.Admin.Debug,
+		"tracing": cfg.Tracing.Enabled,
+	})))
+
+	// API documentation endpoint
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		_, _ = w.Write([]byte(docs.API))
+	})
+
+	// Admin API on a separate port, with the bandwidth and connection limit
+	// admin APIs
+	var adminServer *http.Server
+	if cfg.Admin.Enabled {
+		adm.Handle(bandwidth.Path, bandwidth.Handler(shaper))
+		adm.Handle(connlimit.Path, connlimit.Handler(connLimiter))
+		if cfg.Admin.Debug {
+			adm.Debug()
+		}
+		adminServer, err = adm.Serve(cfg.AdminAddr())
+		if err != nil {
+			return err
+		}
+		defer func() { _ = adminServer.Close() }()
+	}
+
+	// Readiness delay and simulated crash, counted from here
+	lifecycle.Start(ctx, cfg.Lifecycle, adm)
+
+	srv := &http.Server{
+		Addr:              cfg.HTTPAddr(),
+		Handler:           tracing.HTTP(nil)(logging.HTTP(mux)),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	// Graceful shutdown (AMQP and STOMP connections are closed)
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		<-ctx.Done()
+
+		log.Println("Shutting down server...")
+		if err := amqpServer.Close(); err != nil {
+			slog.Error("AMQP server shutdown error", "error", err)
+		}
+		if err := stompServer.Close(); err != nil {
+			slog.Error("STOMP server shutdown error", "error", err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := srv.Shutdown(ctx); err != nil {
+			slog.Error("Server shutdown error", "error", err)
+		}
+		if adminServer != nil {
+			_ = adminServer.Shutdown(ctx)
+		}
+	}()
+
+	go func() {
+		if err := amqpServer.Serve(shaper.Listen(connLimiter.Listen(amqpListener, nil))); err != nil && !errors.Is(err, amqp.ErrServerClosed) {
+			serveErr <- fmt.Errorf("failed to serve AMQP: %w", err)
+			cancel()
+		}
+	}()
+	go func() {
+		if err := stompServer.Serve(shaper.Listen(connLimiter.Listen(stompListener, stomp.Busy))); err != nil && !errors.Is(err, stomp.ErrServerClosed) {
+			serveErr <- fmt.Errorf("failed to serve STOMP: %w", err)
+			cancel()
+		}
+	}()
+
+	log.Printf("Echoing messages to %q with ack mode %s", cfg.EchoPrefix, ackMode)
+	log.Printf("Starting AMQP server on %s", cfg.AMQPAddr())
+	log.Printf("Starting STOMP server on %s", cfg.STOMPAddr())
+	log.Printf("Starting HTTP server on %s", cfg.HTTPAddr())
+	if err := network.ListenAndServe(srv); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve: %w", err)
+	}
+	<-stopped
+
+	log.Println("Server stopped")
+	select {
+	case err := <-serveErr:
+		return err
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,109 @@
+package app
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/probitas-test/echo-servers/shared/admin"
+	"github.com/probitas-test/echo-servers/shared/bandwidth"
+	"github.com/probitas-test/echo-servers/shared/config"
+	"github.com/probitas-test/echo-servers/shared/connlimit"
+	"github.com/probitas-test/echo-servers/shared/lifecycle"
+	"github.com/probitas-test/echo-servers/shared/logging"
+	"github.com/probitas-test/echo-servers/shared/network"
+	"github.com/probitas-test/echo-servers/shared/tracing"
+)
+
+type Config struct {
+	Host      string
+	AMQPPort  string
+	STOMPPort string
+
+	// Port of the HTTP server for health checks and API documentation
+	HTTPPort string
+
+	// Prefix of the queues and destinations messages are echoed to
+	EchoPrefix string
+
+	// How published messages are acknowledged: ack, nack or none
+	AckMode string
+
+	// Heartbeat interval offered to clients, in seconds (0 = disabled)
+	Heartbeat int
+
+	// Credentials required from clients (no authentication when unset)
+	AuthUsername string
+	AuthPassword string
+
+	// Admin API on a separate port (ADMIN_ENABLED, ADMIN_PORT)
+	Admin admin.Config
+
+	// Bandwidth shaping of accepted connections (BANDWIDTH_*), changed at
+	// runtime at /bandwidth on the admin port
+	Bandwidth bandwidth.Config
+
+	// Connection cap (CONN_LIMIT_*), changed at runtime at /connlimit on the
+	// admin port
+	ConnLimit connlimit.Config
+
+	// Address family of every listener (IP_FAMILY: dual, ipv4 or ipv6)
+	Network network.Config
+
+	// Simulated startup delay, readiness delay and crash (STARTUP_DELAY_MS, STARTUP_READY_DELAY_MS, CRASH_AFTER_MS, CRASH_EXIT_CODE)
+	Lifecycle lifecycle.Config
+
+	// Structured logging (LOG_LEVEL, LOG_FORMAT)
+	Logging logging.Config
+
+	// OpenTelemetry tracing (OTEL_* variables)
+	Tracing tracing.Config
+
+	// Effective configuration, served at /config
+	src *config.Source
+}
+
+// LoadConfig reads the settings of echo-amqp from src
+func LoadConfig(src *config.Source) (*Config, error) {
+	cfg := &Config{
+		Host:      src.String("HOST", "0.0.0.0"),
+		AMQPPort:  src.String("AMQP_PORT", "5672"),
+		STOMPPort: src.String("STOMP_PORT", "61613"),
+		HTTPPort:  src.String("HTTP_PORT", "8080"),
+
+		EchoPrefix: src.String("ECHO_PREFIX", "echo."),
+		AckMode:    src.String("ACK_MODE", "ack"),
+		Heartbeat:  src.Int("HEARTBEAT", 60),
+
+		AuthUsername: src.String("AUTH_USERNAME", ""),
+		AuthPassword: src.Secret("AUTH_PASSWORD", ""),
+
+		Admin:     admin.LoadConfig(src),
+		Tracing:   tracing.LoadConfig(src, "echo-amqp"),
+		Bandwidth: bandwidth.LoadConfig(src),
+		ConnLimit: connlimit.LoadConfig(src),
+		Network:   network.LoadConfig(src),
+		Lifecycle: lifecycle.LoadConfig(src),
+		Logging:   logging.LoadConfig(src),
+	}
+	cfg.src = src
+	if err := src.Err(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	return cfg, nil
+}
+
+func (c *Config) AMQPAddr() string {
+	return net.JoinHostPort(c.Host, c.AMQPPort)
+}
+
+func (c *Config) STOMPAddr() string {
+	return net.JoinHostPort(c.Host, c.STOMPPort)
+}
+
+func (c *Config) HTTPAddr() string {
+	return net.JoinHostPort(c.Host, c.HTTPPort)
+}
+
+func (c *Config) AdminAddr() string {
+	return net.JoinHostPort(c.Host, c.Admin.Port)
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/probitas-test/echo-servers/echo-amqp/app"
+	"github.com/probitas-test/echo-servers/shared/config"
+	"github.com/probitas-test/echo-servers/shared/logging"
+	"github.com/probitas-test/echo-servers/shared/network"
+)
+
+func main() {
+	// Environment, .env file and CONFIG_FILE
+	cfg, err := app.LoadConfig(config.Load())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Structured logging; the log package writes through it as well
+	if err := logging.Setup(cfg.Logging); err != nil {
+		log.Fatalf("Invalid logging configuration: %v", err)
+	}
+
+	// IPv4-only, IPv6-only or dual-stack listeners
+	if err := network.Setup(cfg.Network); err != nil {
+		log.Fatalf("Invalid network configuration: %v", err)
+	}
+
+	// Serve until SIGINT or SIGTERM, then shut down gracefully
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err = app.Run(ctx, cfg)
+	stop()
+	if err != nil {
+		log.Fatal(err)
+	}
+}
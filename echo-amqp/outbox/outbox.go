@@ -0,0 +1,77 @@
+// Package outbox queues encoded frames for a connection writer, so brokers
+// can send frames while holding their locks without blocking on the network
+package outbox
+
+import (
+	"bufio"
+	"net"
+	"sync"
+)
+
+// Outbox is a queue of encoded frames drained by Write
+type Outbox struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	frames [][]byte
+	closed bool
+}
+
+// New creates an empty outbox
+func New() *Outbox {
+	o := &Outbox{}
+	o.cond = sync.NewCond(&o.mu)
+	return o
+}
+
+// Push queues frames, dropping them once the outbox is closed
+func (o *Outbox) Push(frames ...[]byte) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.closed {
+		return
+	}
+	o.frames = append(o.frames, frames...)
+	o.cond.Signal()
+}
+
+// Close lets Write write the queued frames and return
+func (o *Outbox) Close() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.closed = true
+	o.cond.Signal()
+}
+
+// take waits for queued frames. It returns false once the outbox is closed
+// and drained.
+func (o *Outbox) take() ([][]byte, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for len(o.frames) == 0 && !o.closed {
+		o.cond.Wait()
+	}
+	frames := o.frames
+	o.frames = nil
+	return frames, len(frames) > 0
+}
+
+// Write writes the queued frames to nc until the outbox is closed or a
+// write fails, then closes nc
+func (o *Outbox) Write(nc net.Conn) {
+	defer func() { _ = nc.Close() }()
+	w := bufio.NewWriter(nc)
+	for {
+		frames, ok := o.take()
+		if !ok {
+			return
+		}
+		for _, f := range frames {
+			if _, err := w.Write(f); err != nil {
+				return
+			}
+		}
+		if err := w.Flush(); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package outbox
+
+import (
+	"io"
+	"net"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	server, client := net.Pipe()
+	o := New()
+	done := make(chan struct{})
+	go func() {
+		o.Write(server)
+		close(done)
+	}()
+
+	o.Push([]byte("one,"), []byte("two,"))
+	o.Push([]byte("three"))
+	o.Close()
+	o.Push([]byte("dropped"))
+
+	got, err := io.ReadAll(client)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if string(got) != "one,two,three" {
+		t.Errorf("written = %q", got)
+	}
+	<-done
+}
+
+func TestWriteStopsOnError(t *testing.T) {
+	server, client := net.Pipe()
+	_ = client.Close()
+	o := New()
+	done := make(chan struct{})
+	go func() {
+		o.Write(server)
+		close(done)
+	}()
+	o.Push([]byte("frame"))
+	<-done
+}
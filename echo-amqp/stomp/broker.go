@@ -0,0 +1,196 @@
+package stomp
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// topicPrefix marks destinations broadcast to every subscriber. All other
+// destinations are queues: each message goes to one subscriber and is kept
+// until one subscribes.
+const topicPrefix = "/topic/"
+
+// Subscription ack modes
+const (
+	ackAuto             = "auto"
+	ackClient           = "client"
+	ackClientIndividual = "client-individual"
+)
+
+// message is a message sent to a destination. Its headers are those of the
+// SEND frame without the transport headers.
+type message struct {
+	destination string
+	headers     []header
+	body        []byte
+	redelivered bool
+}
+
+// subscription delivers the messages of a destination to a session
+type subscription struct {
+	id          string
+	destination string
+	ack         string
+	prefetch    int
+	session     *session
+
+	// unacked holds the deliveries awaiting an ACK, in delivery order
+	unacked []*delivery
+}
+
+// delivery is a message delivered to a subscription
+type delivery struct {
+	id  string
+	sub *subscription
+	msg *message
+}
+
+// broker routes messages between the sessions. A single lock guards the
+// broker and the subscriptions of all sessions; frames are queued on the
+// session outboxes, so it is never held while writing to the network.
+type broker struct {
+	mu      sync.Mutex
+	subs    map[string][]*subscription
+	next    map[string]int
+	pending map[string][]*message
+	lastID  uint64
+}
+
+func newBroker() *broker {
+	return &broker{
+		subs:    make(map[string][]*subscription),
+		next:    make(map[string]int),
+		pending: make(map[string][]*message),
+	}
+}
+
+func isTopic(destination string) bool {
+	return strings.HasPrefix(destination, topicPrefix)
+}
+
+// send delivers msg to every subscriber of a topic, or to one subscriber of
+// a queue, keeping it until one has capacity
+func (b *broker) send(msg *message) {
+	if isTopic(msg.destination) {
+		for _, sub := range b.subs[msg.destination] {
+			b.deliver(sub, msg)
+		}
+		return
+	}
+	b.pending[msg.destination] = append(b.pending[msg.destination], msg)
+	b.dispatch(msg.destination)
+}
+
+// dispatch delivers the pending messages of a queue round-robin to the
+// subscriptions with capacity left
+func (b *broker) dispatch(destination string) {
+	for len(b.pending[destination]) > 0 {
+		sub := b.nextSubscription(destination)
+		if sub == nil {
+			return
+		}
+		msg := b.pending[destination][0]
+		b.pending[destination] = b.pending[destination][1:]
+		b.deliver(sub, msg)
+	}
+	delete(b.pending, destination)
+}
+
+func (b *broker) nextSubscription(destination string) *subscription {
+	subs := b.subs[destination]
+	for i := range subs {
+		sub := subs[(b.next[destination]+i)%len(subs)]
+		if sub.prefetch == 0 || len(sub.unacked) < sub.prefetch {
+			b.next[destination] = (b.next[destination] + i + 1) % len(subs)
+			return sub
+		}
+	}
+	return nil
+}
+
+// deliver sends msg to sub as a MESSAGE frame
+func (b *broker) deliver(sub *subscription, msg *message) {
+	b.lastID++
+	id := strconv.FormatUint(b.lastID, 10)
+	f := newFrame("MESSAGE",
+		"subscription", sub.id,
+		"message-id", id,
+		"destination", msg.destination,
+	)
+	if sub.ack != ackAuto {
+		sub.unacked = append(sub.unacked, &delivery{id: id, sub: sub, msg: msg})
+		if sub.session.version == "1.2" {
+			f.set("ack", id)
+		}
+	}
+	if msg.redelivered {
+		f.set("redelivered", "true")
+	}
+	for _, h := range msg.headers {
+		if _, ok := f.get(h.name); !ok {
+			f.headers = append(f.headers, h)
+		}
+	}
+	f.body = msg.body
+	sub.session.push(f)
+}
+
+// subscribe adds sub and delivers the pending messages of its destination
+func (b *broker) subscribe(sub *subscription) {
+	b.subs[sub.destination] = append(b.subs[sub.destination], sub)
+	b.dispatch(sub.destination)
+}
+
+// unsubscribe removes sub and requeues its unacknowledged messages
+func (b *broker) unsubscribe(sub *subscription) {
+	subs := slices.DeleteFunc(b.subs[sub.destination], func(other *subscription) bool { return other == sub })
+	if len(subs) == 0 {
+		delete(b.subs, sub.destination)
+		delete(b.next, sub.destination)
+	} else {
+		b.subs[sub.destination] = subs
+		b.next[sub.destination] = 0
+	}
+	unacked := sub.unacked
+	sub.unacked = nil
+	b.requeue(unacked)
+}
+
+// settle acknowledges the delivery with id. Subscriptions in client mode
+// settle all earlier deliveries too. Rejected queue messages are requeued
+// when requeue is set. It returns false for unknown ids.
+func (b *broker) settle(sub *subscription, id string, requeue bool) bool {
+	i := slices.IndexFunc(sub.unacked, func(d *delivery) bool { return d.id == id })
+	if i < 0 {
+		return false
+	}
+	start := i
+	if sub.ack == ackClient {
+		start = 0
+	}
+	settled := slices.Clone(sub.unacked[start : i+1])
+	sub.unacked = slices.Delete(sub.unacked, start, i+1)
+	if requeue {
+		b.requeue(settled)
+	}
+	b.dispatch(sub.destination)
+	return true
+}
+
+// requeue puts queue messages back at the front of their queues, marked as
+// redelivered. Topic messages are dropped.
+func (b *broker) requeue(deliveries []*delivery) {
+	for _, d := range slices.Backward(deliveries) {
+		if isTopic(d.msg.destination) {
+			continue
+		}
+		msg := *d.msg
+		msg.redelivered = true
+		b.pending[msg.destination] = slices.Insert(b.pending[msg.destination], 0, &msg)
+	}
+	for _, d := range deliveries {
+		b.dispatch(d.msg.destination)
+	}
+}
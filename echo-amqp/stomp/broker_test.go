@@ -0,0 +1,44 @@
+package stomp
+
+import "testing"
+
+func TestSettleClientMode(t *testing.T) {
+	b := newBroker()
+	sess := &session{version: "1.2"}
+	sub := &subscription{id: "s", destination: "/queue/a", ack: ackClient, session: sess}
+	for _, id := range []string{"1", "2", "3"} {
+		sub.unacked = append(sub.unacked, &delivery{id: id, sub: sub, msg: &message{destination: "/queue/a"}})
+	}
+
+	if !b.settle(sub, "2", false) {
+		t.Fatal("settle returned false")
+	}
+	if len(sub.unacked) != 1 || sub.unacked[0].id != "3" {
+		t.Errorf("unacked after cumulative ack = %d", len(sub.unacked))
+	}
+	if b.settle(sub, "1", false) {
+		t.Error("settle of an acknowledged id returned true")
+	}
+}
+
+func TestSettleRequeue(t *testing.T) {
+	b := newBroker()
+	sub := &subscription{id: "s", destination: "/queue/a", ack: ackClientIndividual}
+	for _, id := range []string{"1", "2"} {
+		sub.unacked = append(sub.unacked, &delivery{id: id, sub: sub, msg: &message{destination: "/queue/a", body: []byte(id)}})
+	}
+
+	b.settle(sub, "2", true)
+	pending := b.pending["/queue/a"]
+	if len(sub.unacked) != 1 || len(pending) != 1 || string(pending[0].body) != "2" || !pending[0].redelivered {
+		t.Errorf("unacked = %d, pending = %v", len(sub.unacked), pending)
+	}
+}
+
+func TestRequeueDropsTopics(t *testing.T) {
+	b := newBroker()
+	b.requeue([]*delivery{{msg: &message{destination: "/topic/a"}}})
+	if len(b.pending) != 0 {
+		t.Errorf("pending = %v", b.pending)
+	}
+}
@@ -0,0 +1,251 @@
+package stomp
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	// maxLineLength is the longest command or header line accepted
+	maxLineLength = 64 << 10
+
+	// maxHeaders is the largest number of headers of a frame
+	maxHeaders = 1000
+
+	// maxBodySize is the largest frame body accepted
+	maxBodySize = 16 << 20
+)
+
+// protocolError is a malformed frame, answered with an ERROR frame
+type protocolError string
+
+func (e protocolError) Error() string {
+	return string(e)
+}
+
+type header struct {
+	name, value string
+}
+
+// frame is a STOMP frame. Repeated headers are kept; the first occurrence
+// wins.
+type frame struct {
+	command string
+	headers []header
+	body    []byte
+}
+
+// newFrame creates a frame with the given header name/value pairs
+func newFrame(command string, headers ...string) *frame {
+	f := &frame{command: command}
+	for i := 0; i+1 < len(headers); i += 2 {
+		f.headers = append(f.headers, header{headers[i], headers[i+1]})
+	}
+	return f
+}
+
+// get returns the first value of the header name
+func (f *frame) get(name string) (string, bool) {
+	for _, h := range f.headers {
+		if h.name == name {
+			return h.value, true
+		}
+	}
+	return "", false
+}
+
+// value returns the first value of the header name, or ""
+func (f *frame) value(name string) string {
+	v, _ := f.get(name)
+	return v
+}
+
+// set replaces all values of the header name with value
+func (f *frame) set(name, value string) {
+	f.del(name)
+	f.headers = append(f.headers, header{name, value})
+}
+
+// del removes all values of the header name
+func (f *frame) del(name string) {
+	headers := f.headers[:0]
+	for _, h := range f.headers {
+		if h.name != name {
+			headers = append(headers, h)
+		}
+	}
+	f.headers = headers
+}
+
+// readFrame reads the next frame, skipping heart-beat EOLs. Header values
+// are unescaped when unescape is set (STOMP 1.1 and later).
+func readFrame(r *bufio.Reader, unescape bool) (*frame, error) {
+	var command string
+	for command == "" {
+		line, err := readLine(r)
+		if err != nil {
+			return nil, err
+		}
+		command = line
+	}
+
+	f := &frame{command: command}
+	// CONNECT and CONNECTED headers are never escaped
+	unescape = unescape && command != "CONNECT" && command != "CONNECTED"
+	for {
+		line, err := readLine(r)
+		if err != nil {
+			return nil, err
+		}
+		if line == "" {
+			break
+		}
+		if len(f.headers) == maxHeaders {
+			return nil, protocolError("too many headers")
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, protocolError(fmt.Sprintf("invalid header %q", line))
+		}
+		if unescape {
+			if name, err = unescapeValue(name); err != nil {
+				return nil, err
+			}
+			if value, err = unescapeValue(value); err != nil {
+				return nil, err
+			}
+		}
+		f.headers = append(f.headers, header{name, value})
+	}
+
+	if err := f.readBody(r); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// readBody reads content-length bytes or, without the header, up to the
+// terminating NUL
+func (f *frame) readBody(r *bufio.Reader) error {
+	if value, ok := f.get("content-length"); ok {
+		n, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return protocolError(fmt.Sprintf("invalid content-length %q", value))
+		}
+		if n > maxBodySize {
+			return protocolError(fmt.Sprintf("body of %d bytes exceeds %d bytes", n, maxBodySize))
+		}
+		f.body = make([]byte, n+1)
+		if _, err := io.ReadFull(r, f.body); err != nil {
+			return err
+		}
+		if f.body[n] != 0 {
+			return protocolError("frame body not terminated by NUL")
+		}
+		f.body = f.body[:n]
+		return nil
+	}
+
+	for {
+		chunk, err := r.ReadSlice(0)
+		f.body = append(f.body, chunk...)
+		if len(f.body) > maxBodySize+1 {
+			return protocolError(fmt.Sprintf("body exceeds %d bytes", maxBodySize))
+		}
+		if errors.Is(err, bufio.ErrBufferFull) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		f.body = f.body[:len(f.body)-1]
+		return nil
+	}
+}
+
+// readLine reads a line terminated by LF or CRLF
+func readLine(r *bufio.Reader) (string, error) {
+	var line []byte
+	for {
+		chunk, err := r.ReadSlice('\n')
+		line = append(line, chunk...)
+		if len(line) > maxLineLength {
+			return "", protocolError("line too long")
+		}
+		if errors.Is(err, bufio.ErrBufferFull) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		line = bytes.TrimSuffix(line[:len(line)-1], []byte("\r"))
+		return string(line), nil
+	}
+}
+
+var (
+	escaper   = strings.NewReplacer("\\", `\\`, "\r", `\r`, "\n", `\n`, ":", `\c`)
+	unescapes = map[byte]byte{'\\': '\\', 'r': '\r', 'n': '\n', 'c': ':'}
+)
+
+// unescapeValue decodes the escape sequences of a header name or value
+func unescapeValue(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			b.WriteByte(s[i])
+			continue
+		}
+		if i+1 == len(s) {
+			return "", protocolError("invalid escape sequence in header")
+		}
+		c, ok := unescapes[s[i+1]]
+		if !ok {
+			return "", protocolError(fmt.Sprintf(`undefined escape sequence \%c in header`, s[i+1]))
+		}
+		b.WriteByte(c)
+		i++
+	}
+	return b.String(), nil
+}
+
+// encode returns the wire format of f with a content-length header. Header
+// values are escaped when escape is set (STOMP 1.1 and later).
+func (f *frame) encode(escape bool) []byte {
+	var b bytes.Buffer
+	b.WriteString(f.command)
+	b.WriteByte('\n')
+	escape = escape && f.command != "CONNECTED"
+	for _, h := range f.headers {
+		if h.name == "content-length" {
+			continue
+		}
+		if escape {
+			b.WriteString(escaper.Replace(h.name))
+			b.WriteByte(':')
+			b.WriteString(escaper.Replace(h.value))
+		} else {
+			b.WriteString(h.name)
+			b.WriteByte(':')
+			b.WriteString(h.value)
+		}
+		b.WriteByte('\n')
+	}
+	if len(f.body) > 0 {
+		b.WriteString("content-length:")
+		b.WriteString(strconv.Itoa(len(f.body)))
+		b.WriteByte('\n')
+	}
+	b.WriteByte('\n')
+	b.Write(f.body)
+	b.WriteByte(0)
+	return b.Bytes()
+}
@@ -0,0 +1,88 @@
+package stomp
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+)
+
+func TestReadFrame(t *testing.T) {
+	raw := "\n\r\nSEND\r\ndestination:/queue/a\\cb\nkey:first\nkey:second\n\nhello\x00"
+	f, err := readFrame(bufio.NewReader(strings.NewReader(raw)), true)
+	if err != nil {
+		t.Fatalf("readFrame: %v", err)
+	}
+	if f.command != "SEND" || string(f.body) != "hello" {
+		t.Errorf("frame = %s %q", f.command, f.body)
+	}
+	if f.value("destination") != "/queue/a:b" {
+		t.Errorf("destination = %q", f.value("destination"))
+	}
+	if f.value("key") != "first" {
+		t.Errorf("key = %q, want the first value", f.value("key"))
+	}
+}
+
+func TestReadFrameContentLength(t *testing.T) {
+	raw := "SEND\ncontent-length:3\n\na\x00b\x00"
+	f, err := readFrame(bufio.NewReader(strings.NewReader(raw)), true)
+	if err != nil {
+		t.Fatalf("readFrame: %v", err)
+	}
+	if string(f.body) != "a\x00b" {
+		t.Errorf("body = %q", f.body)
+	}
+}
+
+func TestReadFrameConnectNotEscaped(t *testing.T) {
+	raw := "CONNECT\npasscode:a\\b\n\n\x00"
+	f, err := readFrame(bufio.NewReader(strings.NewReader(raw)), true)
+	if err != nil {
+		t.Fatalf("readFrame: %v", err)
+	}
+	if f.value("passcode") != `a\b` {
+		t.Errorf("passcode = %q", f.value("passcode"))
+	}
+}
+
+func TestReadFrameErrors(t *testing.T) {
+	tests := map[string]string{
+		"invalid header":         "SEND\nnocolon\n\n\x00",
+		"undefined escape":       "SEND\nkey:\\t\n\n\x00",
+		"invalid content-length": "SEND\ncontent-length:x\n\n\x00",
+		"missing NUL":            "SEND\ncontent-length:1\n\nab",
+		"line too long":          "SEND\nkey:" + strings.Repeat("a", maxLineLength) + "\n\n\x00",
+	}
+	for name, raw := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := readFrame(bufio.NewReader(strings.NewReader(raw)), true)
+			if _, ok := err.(protocolError); !ok {
+				t.Errorf("readFrame = %v, want protocolError", err)
+			}
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	f := newFrame("MESSAGE", "destination", "/queue/a:b", "content-length", "99")
+	f.body = []byte("hi")
+
+	if got := string(f.encode(true)); got != "MESSAGE\ndestination:/queue/a\\cb\ncontent-length:2\n\nhi\x00" {
+		t.Errorf("encode(true) = %q", got)
+	}
+	if got := string(f.encode(false)); got != "MESSAGE\ndestination:/queue/a:b\ncontent-length:2\n\nhi\x00" {
+		t.Errorf("encode(false) = %q", got)
+	}
+}
+
+func TestHeaders(t *testing.T) {
+	f := newFrame("SEND", "a", "1", "b", "2", "a", "3")
+	f.set("a", "4")
+	if f.value("a") != "4" || len(f.headers) != 2 {
+		t.Errorf("headers after set = %v", f.headers)
+	}
+	f.del("b")
+	if _, ok := f.get("b"); ok {
+		t.Error("header b not deleted")
+	}
+}
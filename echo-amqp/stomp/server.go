@@ -0,0 +1,140 @@
+package stomp
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/probitas-test/echo-servers/echo-amqp/ack"
+)
+
+// ErrServerClosed is returned by Serve after Close
+var ErrServerClosed = errors.New("server closed")
+
+// Config controls the broker
+type Config struct {
+	// EchoPrefix is inserted before the last path segment of the
+	// destination to name the destination a message is echoed to when it
+	// has no reply-to header
+	EchoPrefix string
+
+	// AckMode answers SEND frames: a RECEIPT when requested (ack), an ERROR
+	// (nack) or nothing (none)
+	AckMode ack.Mode
+
+	// Heartbeat is the interval the server offers to send heart-beats at
+	// (0 = none)
+	Heartbeat time.Duration
+
+	// Credentials required from clients (not checked when Username is empty)
+	Username string
+	Password string
+
+	// Delay, if set, is read on every SEND frame and delays its delivery and
+	// echo
+	Delay func() time.Duration
+}
+
+// Server is an in-memory STOMP 1.0-1.2 broker echoing every sent message to
+// a reply destination
+type Server struct {
+	cfg    Config
+	broker *broker
+
+	mu       sync.Mutex
+	listener net.Listener
+	sessions map[*session]struct{}
+	closed   bool
+}
+
+// New creates a broker without destinations
+func New(cfg Config) *Server {
+	return &Server{
+		cfg:      cfg,
+		broker:   newBroker(),
+		sessions: make(map[*session]struct{}),
+	}
+}
+
+// Serve accepts connections on l until Close is called
+func (s *Server) Serve(l net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return ErrServerClosed
+	}
+	s.listener = l
+	s.mu.Unlock()
+
+	for {
+		nc, err := l.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return ErrServerClosed
+			}
+			return err
+		}
+		go s.serveConn(nc)
+	}
+}
+
+// Close stops accepting connections and closes the open ones with an
+// ERROR frame
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	for sess := range s.sessions {
+		sess.push(newFrame("ERROR", "message", "Server shutting down"))
+		sess.shutdown()
+	}
+	if s.listener != nil {
+		return s.listener.Close()
+	}
+	return nil
+}
+
+// Busy answers the CONNECT frame of a connection rejected by the connection
+// limit with an ERROR frame
+func Busy(nc net.Conn) {
+	if _, err := readFrame(bufio.NewReader(nc), false); err != nil {
+		return
+	}
+	_, _ = nc.Write(newFrame("ERROR", "message", "Too many connections").encode(false))
+}
+
+// delay waits for the configured delay of a message
+func (s *Server) delay() {
+	if s.cfg.Delay != nil {
+		if d := s.cfg.Delay(); d > 0 {
+			time.Sleep(d)
+		}
+	}
+}
+
+func (s *Server) serveConn(nc net.Conn) {
+	sess := newSession(s, nc)
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		_ = nc.Close()
+		return
+	}
+	s.sessions[sess] = struct{}{}
+	s.mu.Unlock()
+	go sess.out.Write(nc)
+	defer func() {
+		s.mu.Lock()
+		delete(s.sessions, sess)
+		s.mu.Unlock()
+		sess.release()
+		sess.shutdown()
+	}()
+
+	sess.serve()
+}
@@ -0,0 +1,464 @@
+package stomp
+
+import (
+	"bufio"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/probitas-test/echo-servers/echo-amqp/ack"
+	"github.com/probitas-test/echo-servers/echo-amqp/outbox"
+)
+
+const (
+	// connectTimeout bounds the wait for the CONNECT frame
+	connectTimeout = 10 * time.Second
+
+	// shutdownTimeout bounds how long queued frames are written after the
+	// connection was shut down
+	shutdownTimeout = time.Second
+
+	// serverName is announced in the CONNECTED frame
+	serverName = "echo-amqp/1.0.0"
+)
+
+// versions lists the supported protocol versions, newest first
+var versions = []string{"1.2", "1.1", "1.0"}
+
+// Headers describing the transport of a SEND frame, not kept on messages
+var transportHeaders = []string{"destination", "receipt", "transaction", "content-length"}
+
+var lastSessionID atomic.Uint64
+
+// errDisconnect ends a session after DISCONNECT
+var errDisconnect = errors.New("disconnect")
+
+// session is the state of a client connection. Its subscriptions are
+// guarded by the broker lock.
+type session struct {
+	server *Server
+	nc     net.Conn
+	out    *outbox.Outbox
+	id     string
+
+	version string
+	escape  bool
+
+	subs         map[string]*subscription
+	transactions map[string][]*frame
+
+	shutdownOnce sync.Once
+	stop         chan struct{}
+}
+
+func newSession(s *Server, nc net.Conn) *session {
+	return &session{
+		server:       s,
+		nc:           nc,
+		out:          outbox.New(),
+		id:           "session-" + strconv.FormatUint(lastSessionID.Add(1), 10),
+		subs:         make(map[string]*subscription),
+		transactions: make(map[string][]*frame),
+		stop:         make(chan struct{}),
+	}
+}
+
+// push queues f for the client
+func (sess *session) push(f *frame) {
+	sess.out.Push(f.encode(sess.escape))
+}
+
+// shutdown writes the queued frames, then closes the connection
+func (sess *session) shutdown() {
+	sess.shutdownOnce.Do(func() {
+		close(sess.stop)
+		_ = sess.nc.SetWriteDeadline(time.Now().Add(shutdownTimeout))
+		sess.out.Close()
+	})
+}
+
+// release removes the subscriptions of the session, requeueing their
+// unacknowledged messages
+func (sess *session) release() {
+	b := sess.server.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, sub := range sess.subs {
+		b.unsubscribe(sub)
+	}
+	sess.subs = nil
+}
+
+// fail sends an ERROR frame; the connection is closed afterwards
+func (sess *session) fail(cause *frame, message, detail string) {
+	f := newFrame("ERROR", "message", message)
+	if cause != nil {
+		if receipt, ok := cause.get("receipt"); ok {
+			f.set("receipt-id", receipt)
+		}
+	}
+	if detail != "" {
+		f.set("content-type", "text/plain")
+		f.body = []byte(detail)
+	}
+	sess.push(f)
+}
+
+// receipt answers a frame requesting a receipt
+func (sess *session) receipt(f *frame) {
+	if receipt, ok := f.get("receipt"); ok {
+		sess.push(newFrame("RECEIPT", "receipt-id", receipt))
+	}
+}
+
+// sendHeartbeats sends an EOL every interval until shutdown
+func (sess *session) sendHeartbeats(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			sess.out.Push([]byte("\n"))
+		case <-sess.stop:
+			return
+		}
+	}
+}
+
+// serve handles the CONNECT frame, then the frames of the session until it
+// ends. Errors are answered with an ERROR frame.
+func (sess *session) serve() {
+	r := bufio.NewReader(sess.nc)
+	_ = sess.nc.SetReadDeadline(time.Now().Add(connectTimeout))
+	f, err := readFrame(r, false)
+	if err != nil {
+		sess.protocolError(err)
+		return
+	}
+	if !sess.connect(f) {
+		return
+	}
+	_ = sess.nc.SetReadDeadline(time.Time{})
+
+	for {
+		f, err := readFrame(r, sess.escape)
+		if err != nil {
+			sess.protocolError(err)
+			return
+		}
+		if err := sess.handle(f, false); err != nil {
+			return
+		}
+	}
+}
+
+// protocolError answers a malformed frame
+func (sess *session) protocolError(err error) {
+	var protoErr protocolError
+	if errors.As(err, &protoErr) {
+		sess.fail(nil, "Malformed frame", protoErr.Error())
+	}
+}
+
+// connect negotiates the protocol version, checks the credentials and
+// answers with CONNECTED
+func (sess *session) connect(f *frame) bool {
+	if f.command != "CONNECT" && f.command != "STOMP" {
+		sess.fail(f, "Expected CONNECT frame", fmt.Sprintf("received %s before CONNECT", f.command))
+		return false
+	}
+
+	accepted := []string{"1.0"}
+	if value, ok := f.get("accept-version"); ok {
+		accepted = strings.Split(value, ",")
+	}
+	for _, v := range versions {
+		if slices.Contains(accepted, v) {
+			sess.version = v
+			break
+		}
+	}
+	if sess.version == "" {
+		e := newFrame("ERROR", "version", "1.0,1.1,1.2", "message", "Supported protocol versions are 1.0,1.1,1.2")
+		sess.push(e)
+		return false
+	}
+
+	cfg := sess.server.cfg
+	if cfg.Username != "" {
+		login := subtle.ConstantTimeCompare([]byte(f.value("login")), []byte(cfg.Username))
+		passcode := subtle.ConstantTimeCompare([]byte(f.value("passcode")), []byte(cfg.Password))
+		if login&passcode != 1 {
+			sess.fail(f, "Access refused", "invalid login or passcode")
+			return false
+		}
+	}
+
+	connected := newFrame("CONNECTED",
+		"version", sess.version,
+		"session", sess.id,
+		"server", serverName,
+	)
+	if sess.version != "1.0" {
+		// The client's heart-beat header is "<cx>,<cy>": it sends every
+		// cx ms and wants to receive every cy ms
+		var cy int
+		if value, ok := f.get("heart-beat"); ok {
+			if _, y, ok := strings.Cut(value, ","); ok {
+				cy, _ = strconv.Atoi(strings.TrimSpace(y))
+			}
+		}
+		sx := int(cfg.Heartbeat / time.Millisecond)
+		connected.set("heart-beat", strconv.Itoa(sx)+",0")
+		if sx > 0 && cy > 0 {
+			go sess.sendHeartbeats(time.Duration(max(sx, cy)) * time.Millisecond)
+		}
+	}
+	sess.push(connected)
+	sess.escape = sess.version != "1.0"
+	return true
+}
+
+// handle executes a client frame. Frames of a committed transaction are
+// handled with committed set. Returning an error ends the session.
+func (sess *session) handle(f *frame, committed bool) error {
+	if tx, ok := f.get("transaction"); ok && !committed {
+		switch f.command {
+		case "SEND", "ACK", "NACK":
+			if _, ok := sess.transactions[tx]; !ok {
+				sess.fail(f, "Invalid transaction", fmt.Sprintf("transaction %q is not active", tx))
+				return errors.New("invalid transaction")
+			}
+			sess.transactions[tx] = append(sess.transactions[tx], f)
+			sess.receipt(f)
+			return nil
+		}
+	}
+
+	switch f.command {
+	case "SEND":
+		return sess.send(f)
+	case "SUBSCRIBE":
+		return sess.subscribe(f)
+	case "UNSUBSCRIBE":
+		return sess.unsubscribe(f)
+	case "ACK", "NACK":
+		return sess.settle(f)
+	case "BEGIN", "COMMIT", "ABORT":
+		return sess.transaction(f)
+	case "DISCONNECT":
+		sess.receipt(f)
+		return errDisconnect
+	default:
+		sess.fail(f, "Unknown command", fmt.Sprintf("command %q is not supported", f.command))
+		return errors.New("unknown command")
+	}
+}
+
+// require returns the value of a mandatory header, failing the session
+// when it is missing
+func (sess *session) require(f *frame, name string) (string, error) {
+	value, ok := f.get(name)
+	if !ok || value == "" {
+		sess.fail(f, "Missing header", fmt.Sprintf("%s frame requires a %s header", f.command, name))
+		return "", errors.New("missing header")
+	}
+	return value, nil
+}
+
+// send delivers a message, then echoes it. The ack mode, which the
+// x-echo-ack header overrides, decides whether the message is answered with
+// a RECEIPT (when requested), an ERROR or not at all; rejected messages are
+// neither delivered nor echoed.
+func (sess *session) send(f *frame) error {
+	destination, err := sess.require(f, "destination")
+	if err != nil {
+		return err
+	}
+	cfg := sess.server.cfg
+	mode := ack.Resolve(f.value(ack.Header), cfg.AckMode)
+	if mode == ack.Nack {
+		sess.fail(f, "Message rejected", fmt.Sprintf("message to %s rejected by ack mode nack", destination))
+		return errors.New("message rejected")
+	}
+
+	msg := &message{destination: destination, body: f.body}
+	for _, h := range f.headers {
+		if !slices.Contains(transportHeaders, h.name) {
+			msg.headers = append(msg.headers, h)
+		}
+	}
+
+	sess.server.delay()
+	b := sess.server.broker
+	b.mu.Lock()
+	b.send(msg)
+	if target := echoDestination(destination, f.value("reply-to"), cfg.EchoPrefix); target != "" {
+		echo := *msg
+		echo.destination = target
+		b.send(&echo)
+	}
+	b.mu.Unlock()
+
+	if mode == ack.Ack {
+		sess.receipt(f)
+	}
+	return nil
+}
+
+// echoDestination returns the destination a message sent to destination is
+// echoed to: replyTo, or destination with prefix inserted before its last
+// path segment ("/queue/orders" becomes "/queue/echo.orders"). It returns ""
+// for destinations that already carry the prefix.
+func echoDestination(destination, replyTo, prefix string) string {
+	if replyTo != "" {
+		return replyTo
+	}
+	dir, name := path.Split(destination)
+	if strings.HasPrefix(name, prefix) {
+		return ""
+	}
+	return dir + prefix + name
+}
+
+func (sess *session) subscribe(f *frame) error {
+	destination, err := sess.require(f, "destination")
+	if err != nil {
+		return err
+	}
+	id, ok := f.get("id")
+	if !ok {
+		if sess.version != "1.0" {
+			_, err := sess.require(f, "id")
+			return err
+		}
+		// STOMP 1.0 subscriptions may be identified by their destination
+		id = destination
+	}
+	mode := f.value("ack")
+	switch mode {
+	case "":
+		mode = ackAuto
+	case ackAuto, ackClient, ackClientIndividual:
+	default:
+		sess.fail(f, "Invalid ack mode", fmt.Sprintf("ack mode %q is not auto, client or client-individual", mode))
+		return errors.New("invalid ack mode")
+	}
+	prefetch, _ := strconv.Atoi(f.value("prefetch-count"))
+
+	b := sess.server.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := sess.subs[id]; ok {
+		sess.fail(f, "Duplicate subscription", fmt.Sprintf("subscription %q already exists", id))
+		return errors.New("duplicate subscription")
+	}
+	sub := &subscription{
+		id:          id,
+		destination: destination,
+		ack:         mode,
+		prefetch:    max(prefetch, 0),
+		session:     sess,
+	}
+	sess.subs[id] = sub
+	sess.receipt(f)
+	b.subscribe(sub)
+	return nil
+}
+
+func (sess *session) unsubscribe(f *frame) error {
+	id, ok := f.get("id")
+	if !ok && sess.version == "1.0" {
+		id, ok = f.get("destination")
+	}
+	if !ok {
+		_, err := sess.require(f, "id")
+		return err
+	}
+
+	b := sess.server.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if sub, ok := sess.subs[id]; ok {
+		delete(sess.subs, id)
+		b.unsubscribe(sub)
+	}
+	sess.receipt(f)
+	return nil
+}
+
+// settle handles ACK and NACK. NACKed queue messages are requeued unless
+// the frame has "requeue:false". Unknown ids are ignored, as they may have
+// been acknowledged cumulatively.
+func (sess *session) settle(f *frame) error {
+	idHeader := "id"
+	if sess.version != "1.2" {
+		idHeader = "message-id"
+	}
+	id, err := sess.require(f, idHeader)
+	if err != nil {
+		return err
+	}
+	requeue := f.command == "NACK" && f.value("requeue") != "false"
+
+	b := sess.server.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if subID, ok := f.get("subscription"); ok {
+		if sub, ok := sess.subs[subID]; ok {
+			b.settle(sub, id, requeue)
+		}
+	} else {
+		for _, sub := range sess.subs {
+			if b.settle(sub, id, requeue) {
+				break
+			}
+		}
+	}
+	sess.receipt(f)
+	return nil
+}
+
+// transaction handles BEGIN, COMMIT and ABORT. Frames sent in a transaction
+// are buffered and handled on COMMIT.
+func (sess *session) transaction(f *frame) error {
+	tx, err := sess.require(f, "transaction")
+	if err != nil {
+		return err
+	}
+	frames, active := sess.transactions[tx]
+	if f.command == "BEGIN" {
+		if active {
+			sess.fail(f, "Invalid transaction", fmt.Sprintf("transaction %q is already active", tx))
+			return errors.New("invalid transaction")
+		}
+		sess.transactions[tx] = nil
+		sess.receipt(f)
+		return nil
+	}
+
+	if !active {
+		sess.fail(f, "Invalid transaction", fmt.Sprintf("transaction %q is not active", tx))
+		return errors.New("invalid transaction")
+	}
+	delete(sess.transactions, tx)
+	if f.command == "COMMIT" {
+		for _, buffered := range frames {
+			// Receipts were sent when the frames were buffered
+			buffered.del("receipt")
+			if err := sess.handle(buffered, true); err != nil {
+				return err
+			}
+		}
+	}
+	sess.receipt(f)
+	return nil
+}
@@ -0,0 +1,383 @@
+package stomp
+
+import (
+	"bufio"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/probitas-test/echo-servers/echo-amqp/ack"
+)
+
+func startServer(t *testing.T, cfg Config) string {
+	t.Helper()
+	if cfg.EchoPrefix == "" {
+		cfg.EchoPrefix = "echo."
+	}
+	if cfg.AckMode == "" {
+		cfg.AckMode = ack.Ack
+	}
+	s := New(cfg)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	go func() { _ = s.Serve(l) }()
+	t.Cleanup(func() { _ = s.Close() })
+	return l.Addr().String()
+}
+
+type client struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func dial(t *testing.T, addr string) *client {
+	t.Helper()
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return &client{t: t, conn: conn, r: bufio.NewReader(conn)}
+}
+
+// connect dials and connects with STOMP 1.2
+func connect(t *testing.T, addr string, headers ...string) *client {
+	t.Helper()
+	c := dial(t, addr)
+	c.send(newFrame("CONNECT", append([]string{"accept-version", "1.2", "host", "/"}, headers...)...))
+	if f := c.read(); f.command != "CONNECTED" {
+		t.Fatalf("CONNECT answered with %s: %s", f.command, f.value("message"))
+	}
+	return c
+}
+
+func (c *client) send(f *frame) {
+	c.t.Helper()
+	if _, err := c.conn.Write(f.encode(f.command != "CONNECT")); err != nil {
+		c.t.Fatalf("write: %v", err)
+	}
+}
+
+func (c *client) read() *frame {
+	c.t.Helper()
+	_ = c.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	f, err := readFrame(c.r, true)
+	if err != nil {
+		c.t.Fatalf("read: %v", err)
+	}
+	return f
+}
+
+func (c *client) expect(command string) *frame {
+	c.t.Helper()
+	f := c.read()
+	if f.command != command {
+		c.t.Fatalf("received %s (%s), want %s", f.command, f.value("message"), command)
+	}
+	return f
+}
+
+func (c *client) subscribe(id, destination string, headers ...string) {
+	c.t.Helper()
+	c.send(newFrame("SUBSCRIBE", append([]string{"id", id, "destination", destination, "receipt", "sub-" + id}, headers...)...))
+	c.expect("RECEIPT")
+}
+
+func sendFrame(destination, body string, headers ...string) *frame {
+	f := newFrame("SEND", append([]string{"destination", destination}, headers...)...)
+	f.body = []byte(body)
+	return f
+}
+
+func TestEchoDestination(t *testing.T) {
+	tests := []struct {
+		destination, replyTo, want string
+	}{
+		{"/queue/orders", "", "/queue/echo.orders"},
+		{"/topic/a/b", "", "/topic/a/echo.b"},
+		{"orders", "", "echo.orders"},
+		{"/queue/orders", "/temp-queue/me", "/temp-queue/me"},
+		{"/queue/echo.orders", "", ""},
+	}
+	for _, tt := range tests {
+		if got := echoDestination(tt.destination, tt.replyTo, "echo."); got != tt.want {
+			t.Errorf("echoDestination(%q, %q) = %q, want %q", tt.destination, tt.replyTo, got, tt.want)
+		}
+	}
+}
+
+func TestConnect(t *testing.T) {
+	addr := startServer(t, Config{Heartbeat: 10 * time.Second})
+	c := dial(t, addr)
+	c.send(newFrame("STOMP", "accept-version", "1.0,1.1", "host", "/", "heart-beat", "0,0"))
+	f := c.expect("CONNECTED")
+	if f.value("version") != "1.1" || f.value("heart-beat") != "10000,0" || f.value("session") == "" {
+		t.Errorf("CONNECTED headers = %v", f.headers)
+	}
+
+	c = dial(t, addr)
+	c.send(newFrame("CONNECT", "accept-version", "2.0"))
+	if f := c.expect("ERROR"); f.value("version") != "1.0,1.1,1.2" {
+		t.Errorf("ERROR headers = %v", f.headers)
+	}
+
+	c = dial(t, addr)
+	c.send(sendFrame("/queue/a", "early"))
+	c.expect("ERROR")
+}
+
+func TestBusy(t *testing.T) {
+	nc, server := net.Pipe()
+	t.Cleanup(func() { _ = nc.Close() })
+	go func() {
+		Busy(server)
+		_ = server.Close()
+	}()
+	go func() {
+		_, _ = nc.Write(newFrame("CONNECT", "accept-version", "1.2", "host", "/").encode(false))
+	}()
+	c := &client{t: t, conn: nc, r: bufio.NewReader(nc)}
+	if f := c.expect("ERROR"); f.value("message") != "Too many connections" {
+		t.Errorf("ERROR headers = %v", f.headers)
+	}
+}
+
+func TestAuthentication(t *testing.T) {
+	addr := startServer(t, Config{Username: "user", Password: "secret"})
+	c := dial(t, addr)
+	c.send(newFrame("CONNECT", "accept-version", "1.2", "login", "user", "passcode", "wrong"))
+	if f := c.expect("ERROR"); f.value("message") != "Access refused" {
+		t.Errorf("message = %q", f.value("message"))
+	}
+	connect(t, addr, "login", "user", "passcode", "secret")
+}
+
+func TestEcho(t *testing.T) {
+	c := connect(t, startServer(t, Config{}))
+	c.subscribe("orders", "/queue/orders")
+	c.subscribe("echo", "/queue/echo.orders")
+
+	c.send(sendFrame("/queue/orders", "hello", "content-type", "text/plain", "trace", "abc", "receipt", "r1"))
+	received := map[string]*frame{}
+	for range 3 {
+		f := c.read()
+		received[f.command+f.value("subscription")] = f
+	}
+	if received["RECEIPT"] == nil || received["RECEIPT"].value("receipt-id") != "r1" {
+		t.Errorf("no RECEIPT for r1")
+	}
+	for _, sub := range []string{"orders", "echo"} {
+		f := received["MESSAGE"+sub]
+		if f == nil {
+			t.Fatalf("no MESSAGE on %s", sub)
+		}
+		if string(f.body) != "hello" || f.value("trace") != "abc" || f.value("content-type") != "text/plain" {
+			t.Errorf("MESSAGE on %s = %q, headers %v", sub, f.body, f.headers)
+		}
+		if _, ok := f.get("receipt"); ok {
+			t.Errorf("MESSAGE on %s carries the receipt header", sub)
+		}
+	}
+	if got := received["MESSAGEecho"].value("destination"); got != "/queue/echo.orders" {
+		t.Errorf("echo destination = %q", got)
+	}
+}
+
+func TestDelay(t *testing.T) {
+	delay := func() time.Duration { return 200 * time.Millisecond }
+	c := connect(t, startServer(t, Config{Delay: delay}))
+	c.subscribe("echo", "/queue/echo.orders")
+
+	start := time.Now()
+	c.send(sendFrame("/queue/orders", "hello"))
+	c.expect("MESSAGE")
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("echo after %v, want the 200ms delay", elapsed)
+	}
+}
+
+func TestEchoReplyTo(t *testing.T) {
+	c := connect(t, startServer(t, Config{}))
+	c.subscribe("replies", "/queue/replies")
+	c.send(sendFrame("/topic/requests", "ping", "reply-to", "/queue/replies"))
+
+	f := c.expect("MESSAGE")
+	if string(f.body) != "ping" || f.value("destination") != "/queue/replies" || f.value("reply-to") != "/queue/replies" {
+		t.Errorf("echo = %q, headers %v", f.body, f.headers)
+	}
+}
+
+func TestQueueBuffersUntilSubscribed(t *testing.T) {
+	addr := startServer(t, Config{})
+	producer := connect(t, addr)
+	producer.send(sendFrame("/queue/jobs", "job", "receipt", "r1"))
+	producer.expect("RECEIPT")
+
+	consumer := connect(t, addr)
+	consumer.subscribe("jobs", "/queue/jobs")
+	if f := consumer.expect("MESSAGE"); string(f.body) != "job" {
+		t.Errorf("body = %q", f.body)
+	}
+}
+
+func TestTopicBroadcast(t *testing.T) {
+	addr := startServer(t, Config{})
+	a := connect(t, addr)
+	b := connect(t, addr)
+	a.subscribe("a", "/topic/news")
+	b.subscribe("b", "/topic/news")
+
+	a.send(sendFrame("/topic/news", "extra"))
+	for _, c := range []*client{a, b} {
+		if f := c.expect("MESSAGE"); string(f.body) != "extra" {
+			t.Errorf("body = %q", f.body)
+		}
+	}
+}
+
+func TestAckModes(t *testing.T) {
+	tests := []struct {
+		name   string
+		mode   ack.Mode
+		header string
+		want   string
+	}{
+		{"ack", ack.Ack, "", "RECEIPT"},
+		{"nack", ack.Nack, "", "ERROR"},
+		{"none", ack.None, "", ""},
+		{"header nack", ack.Ack, "nack", "ERROR"},
+		{"header none", ack.Ack, "none", ""},
+		{"header ack", ack.Nack, "ack", "RECEIPT"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := connect(t, startServer(t, Config{AckMode: tt.mode}))
+			headers := []string{"receipt", "send"}
+			if tt.header != "" {
+				headers = append(headers, ack.Header, tt.header)
+			}
+			c.send(sendFrame("/queue/a", "hello", headers...))
+			// A later receipt shows whether the SEND was answered
+			c.send(newFrame("UNSUBSCRIBE", "id", "none", "receipt", "later"))
+
+			f := c.read()
+			switch tt.want {
+			case "":
+				if f.command != "RECEIPT" || f.value("receipt-id") != "later" {
+					t.Errorf("received %s %v, want no answer to SEND", f.command, f.headers)
+				}
+			default:
+				if f.command != tt.want || f.value("receipt-id") != "send" {
+					t.Errorf("received %s %v, want %s", f.command, f.headers, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestClientAck(t *testing.T) {
+	c := connect(t, startServer(t, Config{}))
+	c.subscribe("jobs", "/queue/jobs", "ack", "client-individual")
+	c.send(sendFrame("/queue/jobs", "job"))
+
+	f := c.expect("MESSAGE")
+	if f.value("ack") == "" || f.value("redelivered") != "" {
+		t.Fatalf("MESSAGE headers = %v", f.headers)
+	}
+	c.send(newFrame("NACK", "id", f.value("ack")))
+
+	f = c.expect("MESSAGE")
+	if string(f.body) != "job" || f.value("redelivered") != "true" {
+		t.Errorf("redelivery = %q, headers %v", f.body, f.headers)
+	}
+	c.send(newFrame("ACK", "id", f.value("ack"), "receipt", "acked"))
+	c.expect("RECEIPT")
+}
+
+func TestRequeueOnDisconnect(t *testing.T) {
+	addr := startServer(t, Config{})
+	c := connect(t, addr)
+	c.subscribe("jobs", "/queue/jobs", "ack", "client")
+	c.send(sendFrame("/queue/jobs", "job"))
+	c.expect("MESSAGE")
+	c.send(newFrame("DISCONNECT", "receipt", "bye"))
+	c.expect("RECEIPT")
+
+	other := connect(t, addr)
+	other.subscribe("jobs", "/queue/jobs")
+	if f := other.expect("MESSAGE"); f.value("redelivered") != "true" {
+		t.Errorf("MESSAGE headers = %v", f.headers)
+	}
+}
+
+func TestPrefetch(t *testing.T) {
+	c := connect(t, startServer(t, Config{}))
+	c.subscribe("jobs", "/queue/jobs", "ack", "client", "prefetch-count", "1")
+	c.send(sendFrame("/queue/jobs", "1"))
+	c.send(sendFrame("/queue/jobs", "2", "receipt", "sent"))
+
+	first := c.expect("MESSAGE")
+	c.expect("RECEIPT")
+	c.send(newFrame("ACK", "id", first.value("ack")))
+	if f := c.expect("MESSAGE"); string(f.body) != "2" {
+		t.Errorf("second MESSAGE = %q", f.body)
+	}
+}
+
+func TestTransaction(t *testing.T) {
+	c := connect(t, startServer(t, Config{}))
+	c.subscribe("a", "/queue/a")
+
+	c.send(newFrame("BEGIN", "transaction", "tx1"))
+	c.send(sendFrame("/queue/a", "aborted", "transaction", "tx1"))
+	c.send(newFrame("ABORT", "transaction", "tx1"))
+
+	c.send(newFrame("BEGIN", "transaction", "tx2"))
+	c.send(sendFrame("/queue/a", "committed", "transaction", "tx2"))
+	c.send(newFrame("COMMIT", "transaction", "tx2"))
+
+	if f := c.expect("MESSAGE"); string(f.body) != "committed" {
+		t.Errorf("body = %q", f.body)
+	}
+
+	c.send(newFrame("COMMIT", "transaction", "missing"))
+	c.expect("ERROR")
+}
+
+func TestSTOMP10(t *testing.T) {
+	c := dial(t, startServer(t, Config{}))
+	c.send(newFrame("CONNECT"))
+	if f := c.expect("CONNECTED"); f.value("version") != "1.0" {
+		t.Errorf("version = %q", f.value("version"))
+	}
+	c.send(newFrame("SUBSCRIBE", "destination", "/queue/a", "ack", "client"))
+	c.send(sendFrame("/queue/a", "old"))
+	f := c.expect("MESSAGE")
+	if _, ok := f.get("ack"); ok {
+		t.Error("STOMP 1.0 MESSAGE carries an ack header")
+	}
+	c.send(newFrame("ACK", "message-id", f.value("message-id"), "receipt", "acked"))
+	c.expect("RECEIPT")
+}
+
+func TestClose(t *testing.T) {
+	s := New(Config{EchoPrefix: "echo.", AckMode: ack.Ack})
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	served := make(chan error, 1)
+	go func() { served <- s.Serve(l) }()
+
+	c := connect(t, l.Addr().String())
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	c.expect("ERROR")
+	if err := <-served; err != ErrServerClosed {
+		t.Errorf("Serve = %v, want ErrServerClosed", err)
+	}
+}
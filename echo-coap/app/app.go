@@ -0,0 +1,199 @@
+// Package app runs echo-coap: LoadConfig reads its settings and Run serves
+// it until its context ends, for the echo-coap binary and echo-all.
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/probitas-test/echo-servers/echo-coap/coap"
+	"github.com/probitas-test/echo-servers/echo-coap/docs"
+	"github.com/probitas-test/echo-servers/shared/admin"
+	"github.com/probitas-test/echo-servers/shared/lifecycle"
+	"github.com/probitas-test/echo-servers/shared/logging"
+	"github.com/probitas-test/echo-servers/shared/network"
+	"github.com/probitas-test/echo-servers/shared/tracing"
+	"github.com/probitas-test/echo-servers/shared/version"
+)
+
+// Run serves echo-coap with cfg until ctx is done, then shuts it down
+// gracefully. Logging and the address family are set up by the caller, as
+// they are shared by the servers of a process.
+func Run(ctx context.Context, cfg *Config) error {
+	// Started servers stop when Run returns early with an error
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Listeners serving in the background stop Run with their error
+	serveErr := make(chan error, 2)
+
+	// Simulated slow startup: no port opens before the startup delay
+	if err := cfg.Lifecycle.Validate(); err != nil {
+		return fmt.Errorf("invalid lifecycle configuration: %w", err)
+	}
+	log.Printf("Lifecycle: %s", cfg.Lifecycle)
+	lifecycle.Delay(ctx, cfg.Lifecycle)
+
+	// OpenTelemetry tracing and W3C trace context propagation
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		return fmt.Errorf("failed to set up tracing: %w", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Error("Tracing shutdown error", "error", err)
+		}
+	}()
+	log.Printf("OpenTelemetry tracing: %s", cfg.Tracing)
+
+	if cfg.Port < 1 || cfg.Port > 65535 {
+		return fmt.Errorf("invalid PORT %d (must be 1-65535)", cfg.Port)
+	}
+	if cfg.DTLSEnabled && (cfg.DTLSPort < 1 || cfg.DTLSPort > 65535) {
+		return fmt.Errorf("invalid DTLS_PORT %d (must be 1-65535)", cfg.DTLSPort)
+	}
+	if !coap.ValidBlockSize(cfg.BlockSize) {
+		return fmt.Errorf("invalid BLOCK_SIZE %d (must be 16-1024, a power of 2)", cfg.BlockSize)
+	}
+	if cfg.ObserveInterval < 0 {
+		return fmt.Errorf("invalid OBSERVE_INTERVAL_MS %d (must be >= 0)", cfg.ObserveInterval.Milliseconds())
+	}
+
+	// Admin API: health and response delay
+	adm := admin.New("echo-coap", cfg.src)
+
+	server := coap.New(coap.Config{
+		BlockSize:       cfg.BlockSize,
+		ObserveInterval: cfg.ObserveInterval,
+		Delay:           adm.Delay,
+	})
+	pc, err := network.ListenPacket(cfg.Addr())
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	defer func() { _ = pc.Close() }()
+	var dtlsListener net.Listener
+	if cfg.DTLSEnabled {
+		dtlsListener, err = coap.ListenDTLS(network.UDP(), network.Addr(cfg.DTLSAddr()), coap.DTLSConfig{
+			PSKIdentity: cfg.DTLSPSKIdentity,
+			PSK:         []byte(cfg.DTLSPSK),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to listen for DTLS: %w", err)
+		}
+		defer func() { _ = dtlsListener.Close() }()
+	}
+
+	mux := http.NewServeMux()
+
+	// Health check endpoint
+	mux.Handle("GET /health", adm.HealthHandler())
+
+	// Effective configuration (secrets redacted)
+	mux.Handle("GET /config", cfg.src)
+
+	// Address family of the client connection
+	mux.Handle("GET "+network.Path, network.Handler())
+
+	// Build information and enabled features
+	mux.Handle("GET "+version.Path, version.Handler(version.New("echo-coap", version.Features{
+		"admin":   cfg.Admin.Enabled,
+		"debug":   cfg.Admin.Debug,
+		"dtls":    cfg.DTLSEnabled,
+		"tracing": cfg.Tracing.Enabled,
+	})))
+
+	// API documentation endpoint
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		_, _ = w.Write([]byte(docs.API))
+	})
+
+	// Admin API on a separate port
+	var adminServer *http.Server
+	if cfg.Admin.Enabled {
+		if cfg.Admin.Debug {
+			adm.Debug()
+		}
+		adminServer, err = adm.Serve(cfg.AdminAddr())
+		if err != nil {
+			return err
+		}
+		defer func() { _ = adminServer.Close() }()
+	}
+
+	// Readiness delay and simulated crash, counted from here
+	lifecycle.Start(ctx, cfg.Lifecycle, adm)
+
+	srv := &http.Server{
+		Addr:              cfg.HTTPAddr(),
+		Handler:           tracing.HTTP(nil)(logging.HTTP(mux)),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	// Graceful shutdown (DTLS sessions are closed)
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		<-ctx.Done()
+
+		log.Println("Shutting down server...")
+		if err := server.Close(); err != nil {
+			slog.Error("CoAP server shutdown error", "error", err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := srv.Shutdown(ctx); err != nil {
+			slog.Error("Server shutdown error", "error", err)
+		}
+		if adminServer != nil {
+			_ = adminServer.Shutdown(ctx)
+		}
+	}()
+
+	go func() {
+		if err := server.ServeUDP(pc); err != nil && !errors.Is(err, coap.ErrServerClosed) {
+			serveErr <- fmt.Errorf("failed to serve CoAP: %w", err)
+			cancel()
+		}
+	}()
+	if dtlsListener != nil {
+		go func() {
+			if err := server.ServeDTLS(dtlsListener); err != nil && !errors.Is(err, coap.ErrServerClosed) {
+				serveErr <- fmt.Errorf("failed to serve CoAP over DTLS: %w", err)
+				cancel()
+			}
+		}()
+	}
+
+	log.Printf("Starting CoAP server on %s (udp)", cfg.Addr())
+	if dtlsListener != nil {
+		if cfg.DTLSPSK != "" {
+			log.Printf("Starting CoAP server on %s (dtls, PSK identity %q)", cfg.DTLSAddr(), cfg.DTLSPSKIdentity)
+		} else {
+			log.Printf("Starting CoAP server on %s (dtls, self-signed certificate)", cfg.DTLSAddr())
+		}
+	}
+	log.Printf("Starting HTTP server on %s", cfg.HTTPAddr())
+	if err := network.ListenAndServe(srv); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve: %w", err)
+	}
+	<-stopped
+
+	log.Println("Server stopped")
+	select {
+	case err := <-serveErr:
+		return err
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,100 @@
+package app
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/probitas-test/echo-servers/shared/admin"
+	"github.com/probitas-test/echo-servers/shared/config"
+	"github.com/probitas-test/echo-servers/shared/lifecycle"
+	"github.com/probitas-test/echo-servers/shared/logging"
+	"github.com/probitas-test/echo-servers/shared/network"
+	"github.com/probitas-test/echo-servers/shared/tracing"
+)
+
+type Config struct {
+	Host string
+	Port int
+
+	// DTLS listener (DTLSPort) on top of plain UDP
+	DTLSEnabled bool
+	DTLSPort    int
+
+	// Pre-shared key of DTLS clients; a self-signed certificate is used
+	// when DTLSPSK is empty
+	DTLSPSKIdentity string
+	DTLSPSK         string
+
+	// Port of the HTTP server for health checks and API documentation
+	HTTPPort string
+
+	// Largest block of block-wise responses (16-1024, a power of 2)
+	BlockSize int
+
+	// Period of the /observe counter (0 = only PUT updates /observe)
+	ObserveInterval time.Duration
+
+	// Admin API on a separate port (ADMIN_ENABLED, ADMIN_PORT)
+	Admin admin.Config
+
+	// Address family of every listener (IP_FAMILY: dual, ipv4 or ipv6)
+	Network network.Config
+
+	// Simulated startup delay, readiness delay and crash (STARTUP_DELAY_MS, STARTUP_READY_DELAY_MS, CRASH_AFTER_MS, CRASH_EXIT_CODE)
+	Lifecycle lifecycle.Config
+
+	// Structured logging (LOG_LEVEL, LOG_FORMAT)
+	Logging logging.Config
+
+	// OpenTelemetry tracing (OTEL_* variables)
+	Tracing tracing.Config
+
+	// Effective configuration, served at /config
+	src *config.Source
+}
+
+// LoadConfig reads the settings of echo-coap from src
+func LoadConfig(src *config.Source) (*Config, error) {
+	cfg := &Config{
+		Host:     src.String("HOST", "0.0.0.0"),
+		Port:     src.Int("PORT", 5683),
+		HTTPPort: src.String("HTTP_PORT", "8080"),
+
+		DTLSEnabled:     src.Bool("DTLS_ENABLED", true),
+		DTLSPort:        src.Int("DTLS_PORT", 5684),
+		DTLSPSKIdentity: src.String("DTLS_PSK_IDENTITY", "echo"),
+		DTLSPSK:         src.Secret("DTLS_PSK", ""),
+
+		BlockSize:       src.Int("BLOCK_SIZE", 1024),
+		ObserveInterval: time.Duration(src.Int("OBSERVE_INTERVAL_MS", 1000)) * time.Millisecond,
+
+		Admin:     admin.LoadConfig(src),
+		Tracing:   tracing.LoadConfig(src, "echo-coap"),
+		Network:   network.LoadConfig(src),
+		Lifecycle: lifecycle.LoadConfig(src),
+		Logging:   logging.LoadConfig(src),
+	}
+	cfg.src = src
+	if err := src.Err(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	return cfg, nil
+}
+
+func (c *Config) Addr() string {
+	return net.JoinHostPort(c.Host, strconv.Itoa(c.Port))
+}
+
+func (c *Config) DTLSAddr() string {
+	return net.JoinHostPort(c.Host, strconv.Itoa(c.DTLSPort))
+}
+
+func (c *Config) HTTPAddr() string {
+	return net.JoinHostPort(c.Host, c.HTTPPort)
+}
+
+func (c *Config) AdminAddr() string {
+	return net.JoinHostPort(c.Host, c.Admin.Port)
+}
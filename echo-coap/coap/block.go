@@ -0,0 +1,50 @@
+package coap
+
+import "errors"
+
+// MaxBlockSize is the largest block size of block-wise transfers (SZX 6)
+const MaxBlockSize = 1024
+
+// Block is the value of a Block1 or Block2 option (RFC 7959)
+type Block struct {
+	Num  uint32
+	More bool
+	Size int
+}
+
+// parseBlock decodes a block option value
+func parseBlock(v []byte) (Block, error) {
+	if len(v) > 3 {
+		return Block{}, errors.New("block option too long")
+	}
+	n := decodeUint(v)
+	exp := n & 0x07
+	if exp == 7 {
+		return Block{}, errors.New("reserved block size exponent")
+	}
+	return Block{Num: n >> 4, More: n&0x08 != 0, Size: 1 << (exp + 4)}, nil
+}
+
+// encode returns the option value of the block
+func (b Block) encode() []byte {
+	v := b.Num<<4 | uint32(szx(b.Size))
+	if b.More {
+		v |= 0x08
+	}
+	return encodeUint(v)
+}
+
+// szx returns the size exponent of a block size
+func szx(size int) int {
+	e := 0
+	for size > 16 {
+		size >>= 1
+		e++
+	}
+	return e
+}
+
+// ValidBlockSize reports whether size is a block size (16-1024, a power of 2)
+func ValidBlockSize(size int) bool {
+	return size >= 16 && size <= MaxBlockSize && size&(size-1) == 0
+}
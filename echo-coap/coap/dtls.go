@@ -0,0 +1,59 @@
+package coap
+
+import (
+	"bytes"
+	"errors"
+	"net"
+
+	"github.com/pion/dtls/v3"
+	"github.com/pion/dtls/v3/pkg/crypto/selfsign"
+)
+
+// DTLSConfig selects how DTLS clients authenticate the server
+type DTLSConfig struct {
+	// PSKIdentity and PSK enable pre-shared key cipher suites; the server
+	// presents a self-signed certificate when PSK is empty
+	PSKIdentity string
+	PSK         []byte
+}
+
+var errUnknownPSKIdentity = errors.New("unknown PSK identity")
+
+// ListenDTLS listens for DTLS sessions on the address addr of the UDP
+// network ("udp", "udp4" or "udp6")
+func ListenDTLS(network, addr string, cfg DTLSConfig) (net.Listener, error) {
+	udpAddr, err := net.ResolveUDPAddr(network, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []dtls.ServerOption{
+		dtls.WithExtendedMasterSecret(dtls.RequestExtendedMasterSecret),
+	}
+	if len(cfg.PSK) > 0 {
+		opts = append(opts,
+			dtls.WithPSK(func(identity []byte) ([]byte, error) {
+				if cfg.PSKIdentity != "" && !bytes.Equal(identity, []byte(cfg.PSKIdentity)) {
+					return nil, errUnknownPSKIdentity
+				}
+				return cfg.PSK, nil
+			}),
+			dtls.WithPSKIdentityHint([]byte(cfg.PSKIdentity)),
+			// TLS_PSK_WITH_AES_128_CCM_8 is mandatory for CoAP (RFC 7252
+			// section 9.1.3.1)
+			dtls.WithCipherSuites(
+				dtls.TLS_PSK_WITH_AES_128_CCM_8,
+				dtls.TLS_PSK_WITH_AES_128_CCM,
+				dtls.TLS_PSK_WITH_AES_128_GCM_SHA256,
+				dtls.TLS_PSK_WITH_AES_128_CBC_SHA256,
+			),
+		)
+	} else {
+		cert, err := selfsign.GenerateSelfSigned()
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, dtls.WithCertificates(cert))
+	}
+	return dtls.ListenWithOptions(network, udpAddr, opts...)
+}
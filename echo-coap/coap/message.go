@@ -0,0 +1,346 @@
+package coap
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Type is the CoAP message type
+type Type uint8
+
+const (
+	Confirmable     Type = 0
+	NonConfirmable  Type = 1
+	Acknowledgement Type = 2
+	Reset           Type = 3
+)
+
+func (t Type) String() string {
+	switch t {
+	case Confirmable:
+		return "CON"
+	case NonConfirmable:
+		return "NON"
+	case Acknowledgement:
+		return "ACK"
+	default:
+		return "RST"
+	}
+}
+
+// Code is a CoAP request method or response code (class.detail)
+type Code uint8
+
+// Request methods
+const (
+	Empty  Code = 0x00
+	GET    Code = 0x01
+	POST   Code = 0x02
+	PUT    Code = 0x03
+	DELETE Code = 0x04
+	FETCH  Code = 0x05
+	PATCH  Code = 0x06
+	IPATCH Code = 0x07
+)
+
+// Response codes
+const (
+	Created                  Code = 0x41 // 2.01
+	Deleted                  Code = 0x42 // 2.02
+	Valid                    Code = 0x43 // 2.03
+	Changed                  Code = 0x44 // 2.04
+	Content                  Code = 0x45 // 2.05
+	Continue                 Code = 0x5f // 2.31
+	BadRequest               Code = 0x80 // 4.00
+	BadOption                Code = 0x82 // 4.02
+	NotFound                 Code = 0x84 // 4.04
+	MethodNotAllowed         Code = 0x85 // 4.05
+	RequestEntityIncomplete  Code = 0x88 // 4.08
+	RequestEntityTooLarge    Code = 0x8d // 4.13
+	UnsupportedContentFormat Code = 0x8f // 4.15
+	InternalServerError      Code = 0xa0 // 5.00
+)
+
+var methodNames = map[Code]string{
+	GET: "GET", POST: "POST", PUT: "PUT", DELETE: "DELETE",
+	FETCH: "FETCH", PATCH: "PATCH", IPATCH: "iPATCH",
+}
+
+// IsRequest reports whether c is a request method
+func (c Code) IsRequest() bool {
+	return c >= GET && c < 0x20
+}
+
+// String returns the method name or the class.detail notation
+func (c Code) String() string {
+	if name, ok := methodNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("%d.%02d", c>>5, c&0x1f)
+}
+
+// ParseCode parses a response code in class.detail notation ("4.04") or as
+// three digits ("404")
+func ParseCode(s string) (Code, error) {
+	class, detail, ok := strings.Cut(s, ".")
+	if !ok && len(s) == 3 {
+		class, detail = s[:1], s[1:]
+	}
+	if len(class) != 1 || len(detail) != 2 || class[0] < '2' || class[0] > '5' ||
+		detail[0] < '0' || detail[0] > '3' || detail[1] < '0' || detail[1] > '9' {
+		return 0, fmt.Errorf("invalid response code %q (must be 2.00-5.31)", s)
+	}
+	d := (detail[0]-'0')*10 + detail[1] - '0'
+	if d > 31 {
+		return 0, fmt.Errorf("invalid response code %q (must be 2.00-5.31)", s)
+	}
+	return Code((class[0]-'0')<<5 | d), nil
+}
+
+// Option numbers (RFC 7252, 7641, 7959, 8132)
+const (
+	OptionIfMatch       uint16 = 1
+	OptionURIHost       uint16 = 3
+	OptionETag          uint16 = 4
+	OptionIfNoneMatch   uint16 = 5
+	OptionObserve       uint16 = 6
+	OptionURIPort       uint16 = 7
+	OptionLocationPath  uint16 = 8
+	OptionURIPath       uint16 = 11
+	OptionContentFormat uint16 = 12
+	OptionMaxAge        uint16 = 14
+	OptionURIQuery      uint16 = 15
+	OptionAccept        uint16 = 17
+	OptionLocationQuery uint16 = 20
+	OptionBlock2        uint16 = 23
+	OptionBlock1        uint16 = 27
+	OptionSize2         uint16 = 28
+	OptionProxyURI      uint16 = 35
+	OptionProxyScheme   uint16 = 39
+	OptionSize1         uint16 = 60
+)
+
+// knownOptions are the options understood by the server. Unknown critical
+// (odd) options are rejected with 4.02 Bad Option.
+var knownOptions = map[uint16]bool{
+	OptionIfMatch: true, OptionURIHost: true, OptionETag: true, OptionIfNoneMatch: true,
+	OptionObserve: true, OptionURIPort: true, OptionLocationPath: true, OptionURIPath: true,
+	OptionContentFormat: true, OptionMaxAge: true, OptionURIQuery: true, OptionAccept: true,
+	OptionLocationQuery: true, OptionBlock2: true, OptionBlock1: true, OptionSize2: true,
+	OptionSize1: true,
+}
+
+// Content formats
+const (
+	FormatTextPlain   = 0
+	FormatLinkFormat  = 40
+	FormatOctetStream = 42
+	FormatJSON        = 50
+)
+
+// Option is a CoAP option
+type Option struct {
+	Number uint16
+	Value  []byte
+}
+
+// Message is a CoAP message
+type Message struct {
+	Type      Type
+	Code      Code
+	MessageID uint16
+	Token     []byte
+	Options   []Option
+	Payload   []byte
+}
+
+var errMessageFormat = errors.New("message format error")
+
+// Parse decodes a CoAP message from a datagram
+func Parse(b []byte) (*Message, error) {
+	if len(b) < 4 || b[0]>>6 != 1 {
+		return nil, errMessageFormat
+	}
+	tkl := int(b[0] & 0x0f)
+	if tkl > 8 || len(b) < 4+tkl {
+		return nil, errMessageFormat
+	}
+	m := &Message{
+		Type:      Type(b[0] >> 4 & 0x03),
+		Code:      Code(b[1]),
+		MessageID: binary.BigEndian.Uint16(b[2:]),
+		Token:     append([]byte(nil), b[4:4+tkl]...),
+	}
+	b = b[4+tkl:]
+
+	var number uint16
+	for len(b) > 0 {
+		if b[0] == 0xff {
+			if len(b) == 1 {
+				return nil, errMessageFormat
+			}
+			m.Payload = append([]byte(nil), b[1:]...)
+			break
+		}
+		delta, length := int(b[0]>>4), int(b[0]&0x0f)
+		b = b[1:]
+		var ok bool
+		if delta, b, ok = optionNibble(delta, b); !ok {
+			return nil, errMessageFormat
+		}
+		if length, b, ok = optionNibble(length, b); !ok || length > len(b) {
+			return nil, errMessageFormat
+		}
+		if int(number)+delta > 0xffff {
+			return nil, errMessageFormat
+		}
+		number += uint16(delta)
+		m.Options = append(m.Options, Option{Number: number, Value: append([]byte(nil), b[:length]...)})
+		b = b[length:]
+	}
+	return m, nil
+}
+
+// optionNibble decodes an extended option delta or length
+func optionNibble(n int, b []byte) (int, []byte, bool) {
+	switch n {
+	case 13:
+		if len(b) < 1 {
+			return 0, nil, false
+		}
+		return int(b[0]) + 13, b[1:], true
+	case 14:
+		if len(b) < 2 {
+			return 0, nil, false
+		}
+		return int(binary.BigEndian.Uint16(b)) + 269, b[2:], true
+	case 15:
+		return 0, nil, false
+	default:
+		return n, b, true
+	}
+}
+
+// Marshal encodes the message into a datagram
+func (m *Message) Marshal() []byte {
+	b := make([]byte, 4, 4+len(m.Token)+len(m.Payload)+16)
+	b[0] = 1<<6 | byte(m.Type)<<4 | byte(len(m.Token))
+	b[1] = byte(m.Code)
+	binary.BigEndian.PutUint16(b[2:], m.MessageID)
+	b = append(b, m.Token...)
+
+	options := append([]Option(nil), m.Options...)
+	sort.SliceStable(options, func(i, j int) bool { return options[i].Number < options[j].Number })
+	var number uint16
+	for _, o := range options {
+		delta, deltaExt := nibble(int(o.Number - number))
+		length, lengthExt := nibble(len(o.Value))
+		b = append(b, byte(delta<<4|length))
+		b = append(b, deltaExt...)
+		b = append(b, lengthExt...)
+		b = append(b, o.Value...)
+		number = o.Number
+	}
+
+	if len(m.Payload) > 0 {
+		b = append(b, 0xff)
+		b = append(b, m.Payload...)
+	}
+	return b
+}
+
+// nibble encodes an option delta or length and its extended bytes
+func nibble(n int) (int, []byte) {
+	switch {
+	case n < 13:
+		return n, nil
+	case n < 269:
+		return 13, []byte{byte(n - 13)}
+	default:
+		return 14, binary.BigEndian.AppendUint16(nil, uint16(n-269))
+	}
+}
+
+// clone returns a copy of the message whose options can be changed
+func (m *Message) clone() *Message {
+	c := *m
+	c.Options = append([]Option(nil), m.Options...)
+	return &c
+}
+
+// Option returns the value of the first option with the given number
+func (m *Message) Option(number uint16) ([]byte, bool) {
+	for _, o := range m.Options {
+		if o.Number == number {
+			return o.Value, true
+		}
+	}
+	return nil, false
+}
+
+// UintOption returns the value of a uint option, or -1 when it is absent
+func (m *Message) UintOption(number uint16) int {
+	v, ok := m.Option(number)
+	if !ok {
+		return -1
+	}
+	return int(decodeUint(v))
+}
+
+// Strings returns the values of all options with the given number
+func (m *Message) Strings(number uint16) []string {
+	var values []string
+	for _, o := range m.Options {
+		if o.Number == number {
+			values = append(values, string(o.Value))
+		}
+	}
+	return values
+}
+
+// Path returns the request path built from the Uri-Path options
+func (m *Message) Path() string {
+	return "/" + strings.Join(m.Strings(OptionURIPath), "/")
+}
+
+// SetOption replaces the options with the given number by a single one
+func (m *Message) SetOption(number uint16, value []byte) {
+	m.RemoveOption(number)
+	m.Options = append(m.Options, Option{Number: number, Value: value})
+}
+
+// SetUintOption replaces the options with the given number by a uint option
+func (m *Message) SetUintOption(number uint16, value uint32) {
+	m.SetOption(number, encodeUint(value))
+}
+
+// RemoveOption removes all options with the given number
+func (m *Message) RemoveOption(number uint16) {
+	options := m.Options[:0]
+	for _, o := range m.Options {
+		if o.Number != number {
+			options = append(options, o)
+		}
+	}
+	m.Options = options
+}
+
+// encodeUint encodes a uint option value in as few bytes as possible
+func encodeUint(v uint32) []byte {
+	b := binary.BigEndian.AppendUint32(nil, v)
+	for len(b) > 0 && b[0] == 0 {
+		b = b[1:]
+	}
+	return b
+}
+
+func decodeUint(b []byte) uint32 {
+	var v uint32
+	for _, c := range b {
+		v = v<<8 | uint32(c)
+	}
+	return v
+}
@@ -0,0 +1,98 @@
+package coap
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMarshalParse(t *testing.T) {
+	m := &Message{
+		Type:      Confirmable,
+		Code:      PUT,
+		MessageID: 0x1234,
+		Token:     []byte{1, 2, 3},
+		Payload:   []byte("hello"),
+	}
+	m.SetOption(OptionURIPath, []byte("echo"))
+	m.SetUintOption(OptionContentFormat, FormatJSON)
+	m.SetOption(OptionSize1, []byte{1})
+	m.SetOption(300, bytes.Repeat([]byte("x"), 300))
+
+	got, err := Parse(m.Marshal())
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	if got.Type != m.Type || got.Code != m.Code || got.MessageID != m.MessageID ||
+		!bytes.Equal(got.Token, m.Token) || !bytes.Equal(got.Payload, m.Payload) {
+		t.Errorf("unexpected message %+v", got)
+	}
+	if got.Path() != "/echo" {
+		t.Errorf("expected path /echo, got %s", got.Path())
+	}
+	if f := got.UintOption(OptionContentFormat); f != FormatJSON {
+		t.Errorf("expected content format %d, got %d", FormatJSON, f)
+	}
+	if v, ok := got.Option(300); !ok || len(v) != 300 {
+		t.Errorf("expected 300-byte option 300, got %d bytes", len(v))
+	}
+	if got.UintOption(OptionObserve) != -1 {
+		t.Error("expected no Observe option")
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := map[string][]byte{
+		"short":         {0x40, 0x01},
+		"version":       {0x80, 0x01, 0, 1},
+		"token length":  {0x49, 0x01, 0, 1},
+		"empty payload": {0x40, 0x01, 0, 1, 0xff},
+		"option length": {0x40, 0x01, 0, 1, 0xb5, 'e'},
+		"reserved":      {0x40, 0x01, 0, 1, 0xf0},
+	}
+	for name, b := range tests {
+		if _, err := Parse(b); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestParseCode(t *testing.T) {
+	tests := map[string]Code{
+		"2.05": Content,
+		"404":  NotFound,
+		"4.13": RequestEntityTooLarge,
+		"5.03": 0xa3,
+		"2.31": Continue,
+	}
+	for s, want := range tests {
+		got, err := ParseCode(s)
+		if err != nil || got != want {
+			t.Errorf("ParseCode(%q) = %v, %v; want %v", s, got, err, want)
+		}
+	}
+	for _, s := range []string{"", "1.00", "6.00", "4.4", "4.32", "4x04", "abc"} {
+		if _, err := ParseCode(s); err == nil {
+			t.Errorf("ParseCode(%q): expected an error", s)
+		}
+	}
+	if s := NotFound.String(); s != "4.04" {
+		t.Errorf("expected 4.04, got %s", s)
+	}
+}
+
+func TestBlock(t *testing.T) {
+	for _, b := range []Block{{0, true, 16}, {5, false, 1024}, {4095, true, 64}} {
+		got, err := parseBlock(b.encode())
+		if err != nil || got != b {
+			t.Errorf("expected %+v, got %+v (%v)", b, got, err)
+		}
+	}
+	if _, err := parseBlock([]byte{0x07}); err == nil {
+		t.Error("expected an error for SZX 7")
+	}
+	for size, want := range map[int]bool{16: true, 512: true, 1024: true, 8: false, 100: false, 2048: false} {
+		if ValidBlockSize(size) != want {
+			t.Errorf("ValidBlockSize(%d) != %v", size, want)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package coap
+
+import (
+	"strconv"
+	"sync"
+)
+
+// maxObserveSeq wraps the 24-bit Observe sequence number
+const maxObserveSeq = 1 << 24
+
+// observer is a client observing /observe
+type observer struct {
+	peer  *peer
+	token []byte
+
+	// lastMID is the message ID of the last notification, which the client
+	// may reject with a reset to cancel the observation
+	lastMID uint16
+}
+
+// observable is the state of /observe: a value replaced by a counter on
+// every tick or by PUT, and the clients notified of each change (RFC 7641)
+type observable struct {
+	mu        sync.Mutex
+	value     []byte
+	format    int
+	counter   int
+	seq       uint32
+	observers map[string]*observer
+}
+
+func newObservable() *observable {
+	return &observable{
+		value:     []byte("0"),
+		format:    FormatTextPlain,
+		observers: make(map[string]*observer),
+	}
+}
+
+func observerKey(peerKey string, token []byte) string {
+	return peerKey + "#" + string(token)
+}
+
+// get returns the current value, its content format and sequence number
+func (o *observable) get() ([]byte, int, uint32) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.value, o.format, o.seq
+}
+
+// register adds or refreshes an observation
+func (o *observable) register(p *peer, token []byte) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.observers[observerKey(p.key, token)] = &observer{peer: p, token: token}
+}
+
+// deregister cancels an observation
+func (o *observable) deregister(p *peer, token []byte) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	delete(o.observers, observerKey(p.key, token))
+}
+
+// removePeer cancels the observations of a client
+func (o *observable) removePeer(key string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for k, obs := range o.observers {
+		if obs.peer.key == key {
+			delete(o.observers, k)
+		}
+	}
+}
+
+// removeMessage cancels the observation whose last notification was reset
+func (o *observable) removeMessage(key string, mid uint16) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for k, obs := range o.observers {
+		if obs.peer.key == key && obs.lastMID == mid {
+			delete(o.observers, k)
+		}
+	}
+}
+
+// hasPeer reports whether a client observes the resource
+func (o *observable) hasPeer(key string) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for _, obs := range o.observers {
+		if obs.peer.key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// tick replaces the value by the next counter value
+func (o *observable) tick(s *Server) {
+	o.mu.Lock()
+	o.counter++
+	value := []byte(strconv.Itoa(o.counter))
+	o.mu.Unlock()
+	o.set(s, value, FormatTextPlain)
+}
+
+// set replaces the value and notifies the observers with non-confirmable
+// notifications. Notifications larger than a block carry the first block,
+// the client fetches the others with GET.
+func (o *observable) set(s *Server, value []byte, format int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.value = value
+	o.format = format
+	o.seq = (o.seq + 1) % maxObserveSeq
+
+	full := &Message{Code: Content, Payload: value}
+	full.SetUintOption(OptionContentFormat, uint32(format))
+	for k, obs := range o.observers {
+		msg := full.clone()
+		if len(value) > s.cfg.BlockSize {
+			obs.peer.storeDownload(exchangeKey(observeRequest()), full)
+			msg = sliceBlock(full, 0, s.cfg.BlockSize)
+		}
+		msg.Type = NonConfirmable
+		msg.MessageID = s.messageID()
+		msg.Token = obs.token
+		msg.SetUintOption(OptionObserve, o.seq)
+		obs.lastMID = msg.MessageID
+		if err := obs.peer.write(msg.Marshal()); err != nil {
+			delete(o.observers, k)
+		}
+	}
+}
+
+// observeRequest is the GET request fetching later blocks of notifications
+func observeRequest() *Message {
+	req := &Message{Code: GET}
+	req.SetOption(OptionURIPath, []byte("observe"))
+	return req
+}
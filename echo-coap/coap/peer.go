@@ -0,0 +1,159 @@
+package coap
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// peer is the state kept for a client: responses for retransmissions and
+// block-wise transfers in progress
+type peer struct {
+	key   string
+	write func([]byte) error
+
+	mu        sync.Mutex
+	responses map[uint16]cachedResponse
+	uploads   map[string]*upload
+	downloads map[string]*download
+}
+
+type cachedResponse struct {
+	data    []byte
+	expires time.Time
+}
+
+// upload is a request body received with Block1
+type upload struct {
+	body    []byte
+	expires time.Time
+}
+
+// download is a response sent with Block2
+type download struct {
+	resp    *Message
+	expires time.Time
+}
+
+// peer returns the state of the client identified by key, creating it with
+// the given writer
+func (s *Server) peer(key string, write func([]byte) error) *peer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.peers[key]
+	if !ok {
+		p = &peer{
+			key:       key,
+			write:     write,
+			responses: make(map[uint16]cachedResponse),
+			uploads:   make(map[string]*upload),
+			downloads: make(map[string]*download),
+		}
+		s.peers[key] = p
+	}
+	return p
+}
+
+// removeIdlePeers forgets UDP clients without state or observations
+func (s *Server) removeIdlePeers() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, p := range s.peers {
+		if strings.HasPrefix(key, "udp:") && p.expire() && !s.observe.hasPeer(key) {
+			delete(s.peers, key)
+		}
+	}
+}
+
+// expire drops expired state and reports whether none is left
+func (p *peer) expire() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	for id, r := range p.responses {
+		if now.After(r.expires) {
+			delete(p.responses, id)
+		}
+	}
+	for key, u := range p.uploads {
+		if now.After(u.expires) {
+			delete(p.uploads, key)
+		}
+	}
+	for key, d := range p.downloads {
+		if now.After(d.expires) {
+			delete(p.downloads, key)
+		}
+	}
+	return len(p.responses) == 0 && len(p.uploads) == 0 && len(p.downloads) == 0
+}
+
+// cachedResponse returns the response sent for a message ID
+func (p *peer) cachedResponse(id uint16) ([]byte, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	r, ok := p.responses[id]
+	if !ok || time.Now().After(r.expires) {
+		return nil, false
+	}
+	return r.data, true
+}
+
+func (p *peer) cacheResponse(id uint16, data []byte) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.responses[id] = cachedResponse{data: data, expires: time.Now().Add(exchangeLifetime)}
+}
+
+// upload adds a Block1 block to the body of a request. It returns the whole
+// body after the last block, or the response to send otherwise.
+func (p *peer) upload(key string, b Block, payload []byte) ([]byte, *Message) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	u, ok := p.uploads[key]
+	if b.Num == 0 {
+		u = &upload{}
+		p.uploads[key] = u
+	} else if !ok || len(u.body) != int(b.Num)*b.Size {
+		delete(p.uploads, key)
+		return nil, &Message{Code: RequestEntityIncomplete}
+	}
+	if len(u.body)+len(payload) > MaxBodySize {
+		delete(p.uploads, key)
+		resp := &Message{Code: RequestEntityTooLarge}
+		resp.SetUintOption(OptionSize1, MaxBodySize)
+		return nil, resp
+	}
+	if b.More && len(payload) != b.Size {
+		delete(p.uploads, key)
+		return nil, &Message{Code: BadRequest, Payload: []byte("block payload does not match its size")}
+	}
+	u.body = append(u.body, payload...)
+	u.expires = time.Now().Add(exchangeLifetime)
+
+	if b.More {
+		resp := &Message{Code: Continue}
+		resp.SetOption(OptionBlock1, b.encode())
+		return nil, resp
+	}
+	delete(p.uploads, key)
+	return u.body, nil
+}
+
+// download returns a copy of the response whose blocks are being fetched
+func (p *peer) download(key string) (*Message, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	d, ok := p.downloads[key]
+	if !ok || time.Now().After(d.expires) {
+		return nil, false
+	}
+	return d.resp.clone(), true
+}
+
+func (p *peer) storeDownload(key string, resp *Message) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.downloads[key] = &download{resp: resp, expires: time.Now().Add(exchangeLifetime)}
+}
@@ -0,0 +1,125 @@
+package coap
+
+import (
+	"strconv"
+	"strings"
+)
+
+// MaxLargeSize bounds the size of /large responses
+const MaxLargeSize = MaxBodySize
+
+// defaultLargeSize is the size of /large without a size query
+const defaultLargeSize = 4096
+
+// coreLinks describes the resources for /.well-known/core (RFC 6690)
+const coreLinks = `</echo>;title="Echo request payload";ct=0,` +
+	`</observe>;title="Observable counter";obs;ct=0,` +
+	`</large>;title="Large payload (?size=bytes)";ct=0,` +
+	`</status>;title="Response code (/status/4.04)"`
+
+// route answers a request with the matching resource
+func (s *Server) route(p *peer, req *Message) *Message {
+	path := req.Path()
+	switch {
+	case path == "/.well-known/core":
+		if req.Code != GET {
+			return &Message{Code: MethodNotAllowed}
+		}
+		return textResponse(Content, FormatLinkFormat, []byte(coreLinks))
+	case path == "/echo" || strings.HasPrefix(path, "/echo/"):
+		return echo(req)
+	case path == "/observe":
+		return s.observeResource(p, req)
+	case path == "/large":
+		return large(req)
+	case strings.HasPrefix(path, "/status/"):
+		code, err := ParseCode(strings.TrimPrefix(path, "/status/"))
+		if err != nil {
+			return &Message{Code: BadRequest, Payload: []byte(err.Error())}
+		}
+		return &Message{Code: code}
+	default:
+		return &Message{Code: NotFound}
+	}
+}
+
+func textResponse(code Code, format int, payload []byte) *Message {
+	resp := &Message{Code: code, Payload: payload}
+	resp.SetUintOption(OptionContentFormat, uint32(format))
+	return resp
+}
+
+// echo answers with the request payload and content format
+func echo(req *Message) *Message {
+	code := Content
+	switch req.Code {
+	case POST, PUT, PATCH, IPATCH:
+		code = Changed
+	case DELETE:
+		code = Deleted
+	}
+	resp := &Message{Code: code, Payload: req.Payload}
+	if format, ok := req.Option(OptionContentFormat); ok {
+		resp.SetOption(OptionContentFormat, format)
+	}
+	return resp
+}
+
+// observeResource serves /observe: GET returns (and with Observe, observes)
+// the value, PUT replaces it and notifies the observers
+func (s *Server) observeResource(p *peer, req *Message) *Message {
+	switch req.Code {
+	case GET:
+		switch req.UintOption(OptionObserve) {
+		case 0:
+			s.observe.register(p, req.Token)
+		case 1:
+			s.observe.deregister(p, req.Token)
+		}
+		value, format, seq := s.observe.get()
+		resp := textResponse(Content, format, value)
+		if req.UintOption(OptionObserve) == 0 {
+			resp.SetUintOption(OptionObserve, seq)
+		}
+		return resp
+	case PUT, POST:
+		format := FormatTextPlain
+		if f := req.UintOption(OptionContentFormat); f >= 0 {
+			format = f
+		}
+		s.observe.set(s, req.Payload, format)
+		return &Message{Code: Changed}
+	default:
+		return &Message{Code: MethodNotAllowed}
+	}
+}
+
+// large answers GET with a text payload of the requested size
+func large(req *Message) *Message {
+	if req.Code != GET {
+		return &Message{Code: MethodNotAllowed}
+	}
+	size := defaultLargeSize
+	for _, q := range req.Strings(OptionURIQuery) {
+		if v, ok := strings.CutPrefix(q, "size="); ok {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 || n > MaxLargeSize {
+				return &Message{Code: BadRequest, Payload: []byte("size must be 0-" + strconv.Itoa(MaxLargeSize))}
+			}
+			size = n
+		}
+	}
+	return textResponse(Content, FormatTextPlain, largePayload(size))
+}
+
+// largePayload returns size bytes of numbered lines, so that missing or
+// reordered blocks are easy to spot
+func largePayload(size int) []byte {
+	b := make([]byte, 0, size+64)
+	for line := 0; len(b) < size; line++ {
+		b = append(b, "line "...)
+		b = strconv.AppendInt(b, int64(line), 10)
+		b = append(b, '\n')
+	}
+	return b[:size]
+}
@@ -0,0 +1,376 @@
+package coap
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pion/dtls/v3"
+)
+
+// ErrServerClosed is returned by ServeUDP and ServeDTLS after Close
+var ErrServerClosed = errors.New("server closed")
+
+const (
+	// exchangeLifetime is how long message IDs are remembered to answer
+	// retransmissions (EXCHANGE_LIFETIME, RFC 7252 section 4.8.2)
+	exchangeLifetime = 247 * time.Second
+
+	// handshakeTimeout bounds DTLS handshakes
+	handshakeTimeout = 30 * time.Second
+
+	// maxDatagramSize is the largest datagram read
+	maxDatagramSize = 65535
+
+	// MaxBodySize bounds payloads assembled from Block1 transfers
+	MaxBodySize = 1 << 20
+)
+
+// Config controls block-wise transfers and the observable resource
+type Config struct {
+	// BlockSize is the largest block of responses split with Block2;
+	// clients may ask for smaller blocks
+	BlockSize int
+
+	// ObserveInterval is the period of the /observe counter (0 = only PUT
+	// updates /observe)
+	ObserveInterval time.Duration
+
+	// Delay, if set, is read on every request and delays its response.
+	// Requests are read one at a time, so delays add up.
+	Delay func() time.Duration
+}
+
+// Server serves echo resources over CoAP on UDP and DTLS. Requests of a
+// client are answered in order; confirmable requests get piggybacked
+// responses, and retransmissions get the response already sent.
+type Server struct {
+	cfg     Config
+	nextMID atomic.Uint32
+	observe *observable
+
+	mu        sync.Mutex
+	peers     map[string]*peer
+	udp       map[net.PacketConn]struct{}
+	listeners map[net.Listener]struct{}
+	conns     map[net.Conn]struct{}
+	closed    bool
+	done      chan struct{}
+}
+
+// New creates a server and starts the /observe counter
+func New(cfg Config) *Server {
+	if cfg.BlockSize == 0 {
+		cfg.BlockSize = MaxBlockSize
+	}
+	s := &Server{
+		cfg:       cfg,
+		observe:   newObservable(),
+		peers:     make(map[string]*peer),
+		udp:       make(map[net.PacketConn]struct{}),
+		listeners: make(map[net.Listener]struct{}),
+		conns:     make(map[net.Conn]struct{}),
+		done:      make(chan struct{}),
+	}
+	s.nextMID.Store(rand.Uint32())
+	go s.run()
+	return s
+}
+
+// ServeUDP answers CoAP datagrams received on pc until Close is called
+func (s *Server) ServeUDP(pc net.PacketConn) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return ErrServerClosed
+	}
+	s.udp[pc] = struct{}{}
+	s.mu.Unlock()
+
+	buf := make([]byte, maxDatagramSize)
+	for {
+		n, addr, err := pc.ReadFrom(buf)
+		if err != nil {
+			if s.isClosed() {
+				return ErrServerClosed
+			}
+			return err
+		}
+		p := s.peer("udp:"+addr.String(), func(b []byte) error {
+			_, err := pc.WriteTo(b, addr)
+			return err
+		})
+		s.handleDatagram(p, buf[:n])
+	}
+}
+
+// ServeDTLS answers CoAP over the DTLS sessions accepted on l until Close is
+// called
+func (s *Server) ServeDTLS(l net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return ErrServerClosed
+	}
+	s.listeners[l] = struct{}{}
+	s.mu.Unlock()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if s.isClosed() {
+				return ErrServerClosed
+			}
+			// A failed handshake setup only affects that client
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				continue
+			}
+			return err
+		}
+		go s.serveConn(conn)
+	}
+}
+
+// Close stops the transports and closes the DTLS sessions
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	close(s.done)
+
+	var errs []error
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+	for pc := range s.udp {
+		errs = append(errs, pc.Close())
+	}
+	for l := range s.listeners {
+		errs = append(errs, l.Close())
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Server) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		_ = conn.Close()
+		return
+	}
+	s.conns[conn] = struct{}{}
+	s.mu.Unlock()
+
+	key := "dtls:" + conn.RemoteAddr().String()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		delete(s.peers, key)
+		s.mu.Unlock()
+		s.observe.removePeer(key)
+		_ = conn.Close()
+	}()
+
+	if dc, ok := conn.(*dtls.Conn); ok {
+		ctx, cancel := context.WithTimeout(context.Background(), handshakeTimeout)
+		err := dc.HandshakeContext(ctx)
+		cancel()
+		if err != nil {
+			return
+		}
+	}
+
+	p := s.peer(key, func(b []byte) error {
+		_, err := conn.Write(b)
+		return err
+	})
+	buf := make([]byte, maxDatagramSize)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return
+		}
+		s.handleDatagram(p, buf[:n])
+	}
+}
+
+// run updates the /observe counter and forgets idle UDP clients
+func (s *Server) run() {
+	var tick <-chan time.Time
+	if s.cfg.ObserveInterval > 0 {
+		ticker := time.NewTicker(s.cfg.ObserveInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	cleanup := time.NewTicker(exchangeLifetime / 4)
+	defer cleanup.Stop()
+
+	for {
+		select {
+		case <-tick:
+			s.observe.tick(s)
+		case <-cleanup.C:
+			s.removeIdlePeers()
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// messageID returns the ID of a new message sent by the server
+func (s *Server) messageID() uint16 {
+	return uint16(s.nextMID.Add(1))
+}
+
+// handleDatagram answers a message received from p
+func (s *Server) handleDatagram(p *peer, b []byte) {
+	req, err := Parse(b)
+	if err != nil {
+		// Reject malformed confirmable messages (RFC 7252 section 4.2)
+		if len(b) >= 4 && b[0]>>6 == 1 && Type(b[0]>>4&0x03) == Confirmable {
+			rst := &Message{Type: Reset, MessageID: uint16(b[2])<<8 | uint16(b[3])}
+			_ = p.write(rst.Marshal())
+		}
+		return
+	}
+
+	switch {
+	case req.Type == Reset:
+		// The client rejected a notification
+		s.observe.removeMessage(p.key, req.MessageID)
+		return
+	case req.Type == Acknowledgement:
+		return
+	case !req.Code.IsRequest():
+		// Empty confirmable messages are pings, answered with a reset
+		if req.Type == Confirmable {
+			rst := &Message{Type: Reset, MessageID: req.MessageID}
+			_ = p.write(rst.Marshal())
+		}
+		return
+	}
+
+	if cached, ok := p.cachedResponse(req.MessageID); ok {
+		if req.Type == Confirmable {
+			_ = p.write(cached)
+		}
+		return
+	}
+
+	if s.cfg.Delay != nil {
+		if d := s.cfg.Delay(); d > 0 {
+			time.Sleep(d)
+		}
+	}
+	resp := s.serve(p, req)
+	resp.Token = req.Token
+	if req.Type == Confirmable {
+		resp.Type = Acknowledgement
+		resp.MessageID = req.MessageID
+	} else {
+		resp.Type = NonConfirmable
+		resp.MessageID = s.messageID()
+	}
+	out := resp.Marshal()
+	p.cacheResponse(req.MessageID, out)
+	_ = p.write(out)
+}
+
+// serve handles block-wise transfers around the resources
+func (s *Server) serve(p *peer, req *Message) *Message {
+	for _, o := range req.Options {
+		if o.Number&1 == 1 && !knownOptions[o.Number] {
+			return &Message{Code: BadOption}
+		}
+	}
+
+	var block1 *Block
+	if v, ok := req.Option(OptionBlock1); ok {
+		b, err := parseBlock(v)
+		if err != nil {
+			return &Message{Code: BadOption}
+		}
+		body, resp := p.upload(exchangeKey(req), b, req.Payload)
+		if resp != nil {
+			return resp
+		}
+		req.Payload = body
+		block1 = &b
+	}
+
+	blockSize := s.cfg.BlockSize
+	var block2 *Block
+	if v, ok := req.Option(OptionBlock2); ok {
+		b, err := parseBlock(v)
+		if err != nil {
+			return &Message{Code: BadOption}
+		}
+		blockSize = min(blockSize, b.Size)
+		block2 = &b
+	}
+
+	var resp *Message
+	if block2 != nil && block2.Num > 0 {
+		// Later blocks of a response are served from the first one
+		if body, ok := p.download(exchangeKey(req)); ok {
+			resp = body
+		}
+	}
+	if resp == nil {
+		resp = s.route(p, req)
+		if len(resp.Payload) > blockSize {
+			p.storeDownload(exchangeKey(req), resp)
+		}
+	}
+	if len(resp.Payload) > blockSize {
+		num := uint32(0)
+		if block2 != nil {
+			num = block2.Num
+		}
+		resp = sliceBlock(resp, num, blockSize)
+	}
+	if block1 != nil {
+		resp.SetOption(OptionBlock1, block1.encode())
+	}
+	return resp
+}
+
+// sliceBlock returns block num of a response
+func sliceBlock(resp *Message, num uint32, size int) *Message {
+	start := int(num) * size
+	if start >= len(resp.Payload) {
+		return &Message{Code: BadOption, Payload: []byte("block out of range")}
+	}
+	end := min(start+size, len(resp.Payload))
+
+	block := resp.clone()
+	block.Payload = resp.Payload[start:end]
+	block.SetOption(OptionBlock2, Block{Num: num, More: end < len(resp.Payload), Size: size}.encode())
+	if num == 0 {
+		block.SetUintOption(OptionSize2, uint32(len(resp.Payload)))
+	}
+	return block
+}
+
+// exchangeKey identifies the request a block belongs to
+func exchangeKey(req *Message) string {
+	key := req.Code.String() + " " + req.Path()
+	for _, q := range req.Strings(OptionURIQuery) {
+		key += "?" + q
+	}
+	return key
+}
@@ -0,0 +1,380 @@
+package coap
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pion/dtls/v3"
+)
+
+// startServer serves CoAP on a random local UDP port
+func startServer(t *testing.T, cfg Config) (*Server, string) {
+	t.Helper()
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	s := New(cfg)
+	go func() { _ = s.ServeUDP(pc) }()
+	t.Cleanup(func() { _ = s.Close() })
+	return s, pc.LocalAddr().String()
+}
+
+// client sends raw CoAP messages over a connection
+type client struct {
+	t    *testing.T
+	conn net.Conn
+	mid  uint16
+}
+
+func dial(t *testing.T, addr string) *client {
+	t.Helper()
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return &client{t: t, conn: conn, mid: 100}
+}
+
+func (c *client) send(m *Message) {
+	c.t.Helper()
+	if _, err := c.conn.Write(m.Marshal()); err != nil {
+		c.t.Fatalf("failed to send: %v", err)
+	}
+}
+
+func (c *client) receive() *Message {
+	c.t.Helper()
+	_ = c.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buf := make([]byte, maxDatagramSize)
+	n, err := c.conn.Read(buf)
+	if err != nil {
+		c.t.Fatalf("failed to receive: %v", err)
+	}
+	m, err := Parse(buf[:n])
+	if err != nil {
+		c.t.Fatalf("failed to parse: %v", err)
+	}
+	return m
+}
+
+// request sends a confirmable request and returns the response
+func (c *client) request(code Code, path string, payload []byte, options ...Option) *Message {
+	c.t.Helper()
+	c.mid++
+	req := &Message{Type: Confirmable, Code: code, MessageID: c.mid, Token: []byte("tk"), Payload: payload}
+	for _, segment := range splitPath(path) {
+		req.Options = append(req.Options, Option{Number: OptionURIPath, Value: []byte(segment)})
+	}
+	req.Options = append(req.Options, options...)
+	c.send(req)
+	resp := c.receive()
+	if resp.Type != Acknowledgement || resp.MessageID != c.mid || string(resp.Token) != "tk" {
+		c.t.Fatalf("unexpected response %v %d %q", resp.Type, resp.MessageID, resp.Token)
+	}
+	return resp
+}
+
+func splitPath(path string) []string {
+	return strings.Split(strings.TrimPrefix(path, "/"), "/")
+}
+
+func uintOption(number uint16, v uint32) Option {
+	return Option{Number: number, Value: encodeUint(v)}
+}
+
+func TestEcho(t *testing.T) {
+	_, addr := startServer(t, Config{})
+	c := dial(t, addr)
+
+	tests := []struct {
+		method Code
+		want   Code
+	}{
+		{GET, Content},
+		{FETCH, Content},
+		{POST, Changed},
+		{PUT, Changed},
+		{IPATCH, Changed},
+		{DELETE, Deleted},
+	}
+	for _, tt := range tests {
+		resp := c.request(tt.method, "/echo/sub", []byte("hello"), uintOption(OptionContentFormat, FormatJSON))
+		if resp.Code != tt.want || string(resp.Payload) != "hello" {
+			t.Errorf("%v: unexpected response %v %q", tt.method, resp.Code, resp.Payload)
+		}
+		if f := resp.UintOption(OptionContentFormat); f != FormatJSON {
+			t.Errorf("%v: expected content format %d, got %d", tt.method, FormatJSON, f)
+		}
+	}
+}
+
+func TestNonConfirmable(t *testing.T) {
+	_, addr := startServer(t, Config{})
+	c := dial(t, addr)
+
+	req := &Message{Type: NonConfirmable, Code: GET, MessageID: 7, Token: []byte{9}, Payload: []byte("x")}
+	req.SetOption(OptionURIPath, []byte("echo"))
+	c.send(req)
+	resp := c.receive()
+	if resp.Type != NonConfirmable || resp.Code != Content || !bytes.Equal(resp.Token, []byte{9}) {
+		t.Errorf("unexpected response %v %v %v", resp.Type, resp.Code, resp.Token)
+	}
+}
+
+func TestRetransmission(t *testing.T) {
+	s, addr := startServer(t, Config{})
+	c := dial(t, addr)
+
+	resp := c.request(PUT, "/observe", []byte("a"))
+	if resp.Code != Changed {
+		t.Fatalf("expected 2.04, got %v", resp.Code)
+	}
+	// The retransmitted request is answered without updating /observe twice
+	req := &Message{Type: Confirmable, Code: PUT, MessageID: c.mid, Token: []byte("tk"), Payload: []byte("b")}
+	req.SetOption(OptionURIPath, []byte("observe"))
+	c.send(req)
+	if resp := c.receive(); resp.MessageID != c.mid || resp.Code != Changed {
+		t.Errorf("unexpected response %d %v", resp.MessageID, resp.Code)
+	}
+	if value, _, seq := s.observe.get(); string(value) != "a" || seq != 1 {
+		t.Errorf("expected value a at 1, got %s at %d", value, seq)
+	}
+}
+
+func TestDelay(t *testing.T) {
+	delay := func() time.Duration { return 200 * time.Millisecond }
+	_, addr := startServer(t, Config{Delay: delay})
+	c := dial(t, addr)
+
+	start := time.Now()
+	c.request(GET, "/echo", []byte("x"))
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("response after %v, want the 200ms delay", elapsed)
+	}
+}
+
+func TestPing(t *testing.T) {
+	_, addr := startServer(t, Config{})
+	c := dial(t, addr)
+
+	c.send(&Message{Type: Confirmable, Code: Empty, MessageID: 42})
+	if resp := c.receive(); resp.Type != Reset || resp.MessageID != 42 {
+		t.Errorf("expected reset for 42, got %v %d", resp.Type, resp.MessageID)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	_, addr := startServer(t, Config{})
+	c := dial(t, addr)
+
+	tests := map[string]Code{
+		"/status/4.04": NotFound,
+		"/status/503":  0xa3,
+		"/status/2.01": Created,
+		"/status/9.99": BadRequest,
+		"/missing":     NotFound,
+	}
+	for path, want := range tests {
+		if resp := c.request(GET, path, nil); resp.Code != want {
+			t.Errorf("%s: expected %v, got %v", path, want, resp.Code)
+		}
+	}
+	if resp := c.request(POST, "/large", nil); resp.Code != MethodNotAllowed {
+		t.Errorf("expected 4.05, got %v", resp.Code)
+	}
+	if resp := c.request(GET, "/echo", nil, Option{Number: 9, Value: []byte{1}}); resp.Code != BadOption {
+		t.Errorf("expected 4.02 for an unknown critical option, got %v", resp.Code)
+	}
+}
+
+func TestWellKnownCore(t *testing.T) {
+	_, addr := startServer(t, Config{})
+	c := dial(t, addr)
+
+	resp := c.request(GET, "/.well-known/core", nil)
+	if resp.Code != Content || resp.UintOption(OptionContentFormat) != FormatLinkFormat {
+		t.Fatalf("unexpected response %v %d", resp.Code, resp.UintOption(OptionContentFormat))
+	}
+	if !bytes.Contains(resp.Payload, []byte("</observe>")) {
+		t.Errorf("expected /observe link, got %s", resp.Payload)
+	}
+}
+
+func TestBlock2(t *testing.T) {
+	_, addr := startServer(t, Config{BlockSize: 256})
+	c := dial(t, addr)
+	query := Option{Number: OptionURIQuery, Value: []byte("size=1000")}
+
+	var body []byte
+	for num := uint32(0); ; num++ {
+		block := Option{Number: OptionBlock2, Value: Block{Num: num, Size: 128}.encode()}
+		resp := c.request(GET, "/large", nil, query, block)
+		if resp.Code != Content {
+			t.Fatalf("block %d: unexpected code %v", num, resp.Code)
+		}
+		v, _ := resp.Option(OptionBlock2)
+		b, err := parseBlock(v)
+		if err != nil || b.Num != num || b.Size != 128 {
+			t.Fatalf("block %d: unexpected Block2 %+v (%v)", num, b, err)
+		}
+		if num == 0 && resp.UintOption(OptionSize2) != 1000 {
+			t.Errorf("expected Size2 1000, got %d", resp.UintOption(OptionSize2))
+		}
+		body = append(body, resp.Payload...)
+		if !b.More {
+			break
+		}
+	}
+	if !bytes.Equal(body, largePayload(1000)) {
+		t.Errorf("unexpected body (%d bytes)", len(body))
+	}
+
+	// Without Block2, responses are split with the configured block size
+	resp := c.request(GET, "/large", nil, query)
+	if len(resp.Payload) != 256 {
+		t.Errorf("expected a 256-byte block, got %d bytes", len(resp.Payload))
+	}
+	block := Option{Number: OptionBlock2, Value: Block{Num: 20, Size: 128}.encode()}
+	if resp := c.request(GET, "/large", nil, query, block); resp.Code != BadOption {
+		t.Errorf("expected 4.02 for a block out of range, got %v", resp.Code)
+	}
+}
+
+func TestBlock1(t *testing.T) {
+	_, addr := startServer(t, Config{})
+	c := dial(t, addr)
+	body := largePayload(300)
+
+	for num := 0; num*64 < len(body); num++ {
+		end := min((num+1)*64, len(body))
+		more := end < len(body)
+		block := Option{Number: OptionBlock1, Value: Block{Num: uint32(num), More: more, Size: 64}.encode()}
+		resp := c.request(POST, "/echo", body[num*64:end], block)
+		v, _ := resp.Option(OptionBlock1)
+		b, _ := parseBlock(v)
+		if b.Num != uint32(num) {
+			t.Errorf("block %d: unexpected Block1 %+v", num, b)
+		}
+		if more {
+			if resp.Code != Continue {
+				t.Fatalf("block %d: expected 2.31, got %v", num, resp.Code)
+			}
+			continue
+		}
+		if resp.Code != Changed || !bytes.Equal(resp.Payload, body) {
+			t.Errorf("unexpected response %v (%d bytes)", resp.Code, len(resp.Payload))
+		}
+	}
+
+	block := Option{Number: OptionBlock1, Value: Block{Num: 3, More: true, Size: 64}.encode()}
+	if resp := c.request(POST, "/echo", body[:64], block); resp.Code != RequestEntityIncomplete {
+		t.Errorf("expected 4.08 for a missing block, got %v", resp.Code)
+	}
+}
+
+func TestObserve(t *testing.T) {
+	_, addr := startServer(t, Config{ObserveInterval: 20 * time.Millisecond})
+	c := dial(t, addr)
+
+	resp := c.request(GET, "/observe", nil, uintOption(OptionObserve, 0))
+	if resp.Code != Content || resp.UintOption(OptionObserve) < 0 {
+		t.Fatalf("unexpected response %v with Observe %d", resp.Code, resp.UintOption(OptionObserve))
+	}
+	last := resp.UintOption(OptionObserve)
+	for range 3 {
+		n := c.receive()
+		if n.Type != NonConfirmable || n.Code != Content || string(n.Token) != "tk" {
+			t.Fatalf("unexpected notification %v %v %q", n.Type, n.Code, n.Token)
+		}
+		if seq := n.UintOption(OptionObserve); seq <= last {
+			t.Errorf("expected sequence after %d, got %d", last, seq)
+		}
+		if _, err := strconv.Atoi(string(n.Payload)); err != nil {
+			t.Errorf("expected a counter, got %q", n.Payload)
+		}
+		last = n.UintOption(OptionObserve)
+	}
+
+	// A reset cancels the observation
+	n := c.receive()
+	c.send(&Message{Type: Reset, MessageID: n.MessageID})
+	time.Sleep(100 * time.Millisecond)
+	_ = c.conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	buf := make([]byte, maxDatagramSize)
+	for {
+		if _, err := c.conn.Read(buf); err != nil {
+			break
+		}
+	}
+	_ = c.conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	if _, err := c.conn.Read(buf); err == nil {
+		t.Error("expected no notification after reset")
+	}
+}
+
+func TestObservePut(t *testing.T) {
+	_, addr := startServer(t, Config{BlockSize: 64})
+	observer := dial(t, addr)
+	writer := dial(t, addr)
+
+	observer.request(GET, "/observe", nil, uintOption(OptionObserve, 0))
+	value := largePayload(100)
+	if resp := writer.request(PUT, "/observe", value, uintOption(OptionContentFormat, FormatOctetStream)); resp.Code != Changed {
+		t.Fatalf("expected 2.04, got %v", resp.Code)
+	}
+
+	// Large notifications carry the first block; the rest is fetched with GET
+	n := observer.receive()
+	if n.UintOption(OptionContentFormat) != FormatOctetStream || len(n.Payload) != 64 {
+		t.Fatalf("unexpected notification format %d with %d bytes", n.UintOption(OptionContentFormat), len(n.Payload))
+	}
+	resp := observer.request(GET, "/observe", nil, Option{Number: OptionBlock2, Value: Block{Num: 1, Size: 64}.encode()})
+	if got := append(n.Payload, resp.Payload...); !bytes.Equal(got, value) {
+		t.Errorf("unexpected value %q", got)
+	}
+
+	observer.request(GET, "/observe", nil, uintOption(OptionObserve, 1))
+	writer.request(PUT, "/observe", []byte("x"))
+	_ = observer.conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	if _, err := observer.conn.Read(make([]byte, maxDatagramSize)); err == nil {
+		t.Error("expected no notification after deregistering")
+	}
+}
+
+func TestDTLS(t *testing.T) {
+	l, err := ListenDTLS("udp", "127.0.0.1:0", DTLSConfig{PSKIdentity: "echo", PSK: []byte("secret")})
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	s := New(Config{})
+	go func() { _ = s.ServeDTLS(l) }()
+	t.Cleanup(func() { _ = s.Close() })
+
+	raddr := l.Addr().(*net.UDPAddr)
+	conn, err := dtls.DialWithOptions("udp", raddr,
+		dtls.WithPSK(func([]byte) ([]byte, error) { return []byte("secret"), nil }),
+		dtls.WithPSKIdentityHint([]byte("echo")),
+		dtls.WithCipherSuites(dtls.TLS_PSK_WITH_AES_128_CCM_8),
+	)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := conn.HandshakeContext(ctx); err != nil {
+		t.Fatalf("failed to handshake: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	c := &client{t: t, conn: conn}
+	if resp := c.request(POST, "/echo", []byte("secure")); resp.Code != Changed || string(resp.Payload) != "secure" {
+		t.Errorf("unexpected response %v %q", resp.Code, resp.Payload)
+	}
+}
@@ -0,0 +1,408 @@
+// Package app runs echo-connectrpc: LoadConfig reads its settings and Run
+// serves it until its context ends, for the echo-connectrpc binary and echo-
+// all.
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"connectrpc.com/connect"
+	"connectrpc.com/grpchealth"
+	"connectrpc.com/grpcreflect"
+	"connectrpc.com/otelconnect"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+
+	"github.com/probitas-test/echo-servers/echo-connectrpc/docs"
+	"github.com/probitas-test/echo-servers/echo-connectrpc/server"
+	"github.com/probitas-test/echo-servers/proto/echo/v1/echov1connect"
+	"github.com/probitas-test/echo-servers/shared/admin"
+	"github.com/probitas-test/echo-servers/shared/bandwidth"
+	"github.com/probitas-test/echo-servers/shared/chaos"
+	"github.com/probitas-test/echo-servers/shared/clients"
+	"github.com/probitas-test/echo-servers/shared/clock"
+	"github.com/probitas-test/echo-servers/shared/connlimit"
+	"github.com/probitas-test/echo-servers/shared/credentials"
+	"github.com/probitas-test/echo-servers/shared/lifecycle"
+	"github.com/probitas-test/echo-servers/shared/logging"
+	"github.com/probitas-test/echo-servers/shared/metrics"
+	"github.com/probitas-test/echo-servers/shared/network"
+	"github.com/probitas-test/echo-servers/shared/ratelimit"
+	"github.com/probitas-test/echo-servers/shared/recording"
+	"github.com/probitas-test/echo-servers/shared/script"
+	"github.com/probitas-test/echo-servers/shared/tracing"
+	"github.com/probitas-test/echo-servers/shared/version"
+)
+
+// Run serves echo-connectrpc with cfg until ctx is done, then shuts it down
+// gracefully. Logging and the address family are set up by the caller, as
+// they are shared by the servers of a process.
+func Run(ctx context.Context, cfg *Config) error {
+	// Started servers stop when Run returns early with an error
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Simulated slow startup: no port opens before the startup delay
+	if err := cfg.Lifecycle.Validate(); err != nil {
+		return fmt.Errorf("invalid lifecycle configuration: %w", err)
+	}
+	log.Printf("Lifecycle: %s", cfg.Lifecycle)
+	lifecycle.Delay(ctx, cfg.Lifecycle)
+
+	// Validate that at least one protocol is enabled
+	if cfg.DisableConnectRPC && cfg.DisableGRPC && cfg.DisableGRPCWeb {
+		return errors.New("at least one protocol must be enabled (ConnectRPC, gRPC, or gRPC-Web)")
+	}
+
+	mux := http.NewServeMux()
+
+	// API documentation endpoint
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		_, _ = w.Write([]byte(docs.API))
+	})
+
+	// Connection diagnostics endpoint (HTTP/1.1 vs h2c upgrade vs prior knowledge)
+	mux.HandleFunc("/connection", server.ConnectionInfoHandler)
+
+	// Effective configuration (secrets redacted)
+	mux.Handle("/config", cfg.src)
+
+	// Build information and enabled features, also reported by the Version
+	// RPC
+	build := version.New("echo-connectrpc", version.Features{
+		"admin":            cfg.Admin.Enabled,
+		"chaos":            cfg.Chaos.Enabled,
+		"client_profiles":  cfg.ClientProfiles.File != "",
+		"connect":          !cfg.DisableConnectRPC,
+		"connect_get":      !cfg.DisableConnectRPC && cfg.ConnectGet.Enabled,
+		"debug":            cfg.Admin.Debug,
+		"grpc":             !cfg.DisableGRPC,
+		"grpc_web":         !cfg.DisableGRPCWeb,
+		"metrics":          cfg.MetricsEnabled,
+		"rate_limit":       cfg.RateLimit.Enabled,
+		"recording":        cfg.Recording.Enabled,
+		"reflection":       !cfg.DisableReflectionV1 || !cfg.DisableReflectionV1Alpha,
+		"script":           cfg.Script.File != "",
+		"tracing":          cfg.Tracing.Enabled,
+		"websocket_bridge": cfg.WebSocketBridgeEnabled,
+	})
+	// Address family of the client connection
+	mux.Handle(network.Path, network.Handler())
+
+	mux.Handle(version.Path, version.Handler(build))
+
+	// Prepare handler options for protocol control
+	var handlerOpts []connect.HandlerOption
+
+	// Prometheus metrics of every RPC, served on a separate port
+	var metricsServer *http.Server
+	if cfg.MetricsEnabled {
+		m := metrics.New("echo-connectrpc")
+		handlerOpts = append(handlerOpts, connect.WithInterceptors(server.NewMetricsInterceptor(m)))
+		var err error
+		metricsServer, err = m.Serve(cfg.MetricsAddr())
+		if err != nil {
+			return err
+		}
+		defer func() { _ = metricsServer.Close() }()
+	}
+
+	// Configure OpenTelemetry tracing
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		return fmt.Errorf("failed to set up tracing: %w", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Error("Tracing shutdown error", "error", err)
+		}
+	}()
+	if cfg.Tracing.Enabled {
+		otelInterceptor, err := otelconnect.NewInterceptor(otelconnect.WithTrustRemote())
+		if err != nil {
+			return fmt.Errorf("failed to create OpenTelemetry interceptor: %w", err)
+		}
+		handlerOpts = append(handlerOpts, connect.WithInterceptors(otelInterceptor, server.TraceResponseInterceptor{}))
+	}
+	log.Printf("OpenTelemetry tracing: %s", cfg.Tracing)
+
+	// Rate limiting, inside the metrics and tracing interceptors so denied
+	// RPCs are recorded, and before the injected faults
+	limiter, err := ratelimit.New(cfg.RateLimit)
+	if err != nil {
+		return err
+	}
+	handlerOpts = append(handlerOpts, connect.WithInterceptors(server.NewRateLimitInterceptor(limiter)))
+	mux.Handle(ratelimit.Path, ratelimit.Handler(limiter))
+	log.Printf("Rate limit: %s", cfg.RateLimit)
+
+	// Scripted scenarios, after the rate limit and before the injected
+	// faults
+	scenarios, err := script.New(cfg.Script)
+	if err != nil {
+		return err
+	}
+	handlerOpts = append(handlerOpts, connect.WithInterceptors(server.NewScriptInterceptor(scenarios)))
+	mux.Handle(script.Path, script.Handler(scenarios))
+	log.Printf("Script: %s", cfg.Script)
+
+	// Client behavior profiles, each with its own rate limit and faults,
+	// before the global faults
+	profiles, err := clients.New(cfg.ClientProfiles)
+	if err != nil {
+		return err
+	}
+	handlerOpts = append(handlerOpts, connect.WithInterceptors(server.NewClientProfileInterceptor(profiles)))
+	mux.Handle(clients.Path, clients.Handler(profiles))
+	log.Printf("Client profiles: %s", cfg.ClientProfiles)
+
+	// Fault injection, inside the metrics and tracing interceptors so
+	// injected errors and delays are recorded
+	faults, err := chaos.New(cfg.Chaos)
+	if err != nil {
+		return err
+	}
+	handlerOpts = append(handlerOpts, connect.WithInterceptors(server.NewChaosInterceptor(faults)))
+	mux.Handle(chaos.Path, chaos.Handler(faults))
+	log.Printf("Chaos: %s", cfg.Chaos)
+
+	// Admin API: health, RPC delay, rate limit counters (global and per
+	// client profile) and scenario progress
+	adm := admin.New("echo-connectrpc", cfg.src)
+	adm.Store("rate_limits", limiter.Clear)
+	adm.Store("script_progress", scenarios.Rewind)
+	adm.Store("client_rate_limits", profiles.Clear)
+
+	// Plain HTTP health checks for load balancers that cannot speak gRPC
+	// health, failing once shutdown starts
+	probes := server.NewProbes(adm)
+	mux.Handle("/healthz", probes)
+	mux.Handle("/readyz", probes)
+
+	// Determine which protocols to support
+	protocols := []string{}
+	if !cfg.DisableConnectRPC {
+		protocols = append(protocols, connect.ProtocolConnect)
+	}
+	if !cfg.DisableGRPC {
+		protocols = append(protocols, connect.ProtocolGRPC)
+	}
+	if !cfg.DisableGRPCWeb {
+		protocols = append(protocols, connect.ProtocolGRPCWeb)
+	}
+
+	// Log enabled protocols
+	log.Printf("Enabled protocols: %v", protocols)
+	if len(cfg.DisableConnectRPCMethods) > 0 {
+		log.Printf("Connect RPC disabled for methods: %v", cfg.DisableConnectRPCMethods)
+	}
+	if len(cfg.DisableGRPCMethods) > 0 {
+		log.Printf("gRPC disabled for methods: %v", cfg.DisableGRPCMethods)
+	}
+	if len(cfg.DisableGRPCWebMethods) > 0 {
+		log.Printf("gRPC-Web disabled for methods: %v", cfg.DisableGRPCWebMethods)
+	}
+
+	// Register echo service (authentication and the admin delay apply only
+	// to echo.v1.Echo so health checks and reflection stay reachable)
+	echoOpts := append(handlerOpts[:len(handlerOpts):len(handlerOpts)],
+		connect.WithInterceptors(server.NewAdminInterceptor(adm)))
+	// Test identities shared with the other servers, accepted besides the
+	// AUTH_* credentials and required on their own while the store is
+	creds, err := credentials.New(cfg.Credentials)
+	if err != nil {
+		return fmt.Errorf("invalid credentials: %w", err)
+	}
+	log.Printf("Credentials: %s", cfg.Credentials)
+	// Clock of JWT expiry and the JWKS cache, moved at runtime on the admin
+	// port to expire tokens without waiting
+	clk := clock.New(cfg.Clock)
+	log.Printf("Clock: %s", cfg.Clock)
+	creds.SetClock(clk)
+	authCfg := server.AuthConfig{
+		BearerTokens: cfg.AuthBearerTokens,
+		APIKeys:      cfg.AuthAPIKeys,
+		JWKSURL:      cfg.AuthJWKSURL,
+		Credentials:  creds,
+		Clock:        clk,
+	}
+	echoOpts = append(echoOpts[:len(echoOpts):len(echoOpts)], connect.WithInterceptors(server.NewAuthInterceptor(authCfg)))
+	if authCfg.Enabled() {
+		log.Printf("Authentication enabled (bearer tokens=%d, API keys=%d, JWKS=%q)",
+			len(cfg.AuthBearerTokens), len(cfg.AuthAPIKeys), cfg.AuthJWKSURL)
+	}
+
+	echoServer := server.NewEchoServer()
+	echoServer.SetBuild(build)
+	echoOpts = append(echoOpts[:len(echoOpts):len(echoOpts)], server.GetCacheHandlerOptions()...)
+	path, handler := echov1connect.NewEchoHandler(echoServer, echoOpts...)
+	// Connect GET of the side-effect-free RPCs, cacheable with ETags
+	log.Printf("Connect GET: %s", cfg.ConnectGet)
+	mux.Handle(path, protocolFilterMiddleware(cfg, server.GetCacheMiddleware(cfg.ConnectGet, handler)))
+
+	// Register health check service
+	checker := grpchealth.NewStaticChecker(
+		echov1connect.EchoName,
+	)
+	healthPath, healthHandler := grpchealth.NewHandler(checker, handlerOpts...)
+	mux.Handle(healthPath, protocolFilterMiddleware(cfg, healthHandler))
+	adm.OnHealth(func(healthy bool) {
+		status := grpchealth.StatusServing
+		if !healthy || probes.Draining() {
+			status = grpchealth.StatusNotServing
+		}
+		checker.SetStatus("", status)
+		checker.SetStatus(echov1connect.EchoName, status)
+	})
+
+	// Build list of services for reflection
+	reflectionServices := []string{
+		echov1connect.EchoName,
+		grpchealth.HealthV1ServiceName,
+	}
+
+	if !cfg.DisableReflectionV1 {
+		reflectionServices = append(reflectionServices, grpcreflect.ReflectV1ServiceName)
+	}
+	if !cfg.DisableReflectionV1Alpha {
+		reflectionServices = append(reflectionServices, grpcreflect.ReflectV1AlphaServiceName)
+	}
+
+	// Register reflection service
+	log.Printf("Reflection include dependencies: %v", cfg.ReflectionIncludeDeps)
+
+	if !cfg.DisableReflectionV1 {
+		v1Path, v1Handler := server.NewReflectionHandlerV1(reflectionServices, cfg.ReflectionIncludeDeps, handlerOpts...)
+		mux.Handle(v1Path, protocolFilterMiddleware(cfg, v1Handler))
+		log.Printf("Registered reflection v1")
+	} else {
+		log.Printf("Reflection v1 disabled")
+	}
+
+	if !cfg.DisableReflectionV1Alpha {
+		v1AlphaPath, v1AlphaHandler := server.NewReflectionHandlerV1Alpha(reflectionServices, cfg.ReflectionIncludeDeps, handlerOpts...)
+		mux.Handle(v1AlphaPath, protocolFilterMiddleware(cfg, v1AlphaHandler))
+		log.Printf("Registered reflection v1alpha")
+	} else {
+		log.Printf("Reflection v1alpha disabled")
+	}
+
+	// Register WebSocket bridge for browser clients without HTTP/2 bidi support
+	if cfg.WebSocketBridgeEnabled {
+		mux.Handle(server.WebSocketBridgePrefix, server.NewWebSocketBridge(mux))
+		log.Printf("WebSocket bridge enabled at %s{procedure}", server.WebSocketBridgePrefix)
+	}
+
+	// Traffic recording, toggled and downloaded through the admin API
+	recorder, err := recording.New("echo-connectrpc", cfg.Recording)
+	if err != nil {
+		return err
+	}
+	log.Printf("Recording: %s", cfg.Recording)
+
+	// Bandwidth shaping of every Connect, gRPC and gRPC-Web connection,
+	// changed at runtime on the admin port
+	shaper, err := bandwidth.New(cfg.Bandwidth)
+	if err != nil {
+		return err
+	}
+	log.Printf("Bandwidth: %s", cfg.Bandwidth)
+
+	// Cap on the open connections, changed at runtime on the admin port; the
+	// next ones get a server busy error
+	connLimiter, err := connlimit.New(cfg.ConnLimit)
+	if err != nil {
+		return err
+	}
+	log.Printf("Connection limit: %s", cfg.ConnLimit)
+
+	// Admin API on a separate port, with the fault injection, rate limit,
+	// script, client profile, credential store, bandwidth, connection limit
+	// and recording admin APIs
+	var adminServer *http.Server
+	if cfg.Admin.Enabled {
+		adm.Handle(chaos.Path, chaos.Handler(faults))
+		adm.Handle(ratelimit.Path, ratelimit.Handler(limiter))
+		adm.Handle(script.Path, script.Handler(scenarios))
+		adm.Handle(clients.Path, clients.Handler(profiles))
+		adm.Handle(credentials.Path, credentials.Handler(creds))
+		adm.Handle(clock.Path, clock.Handler(clk))
+		adm.Handle(bandwidth.Path, bandwidth.Handler(shaper))
+		adm.Handle(connlimit.Path, connlimit.Handler(connLimiter))
+		recordings := recording.Handler(recorder)
+		adm.Handle(recording.Path, recordings)
+		adm.Handle(recording.Path+"/", recordings)
+		if cfg.Admin.Debug {
+			adm.Debug()
+		}
+		adminServer, err = adm.Serve(cfg.AdminAddr())
+		if err != nil {
+			return err
+		}
+		defer func() { _ = adminServer.Close() }()
+	}
+
+	// Readiness delay and simulated crash, counted from here
+	lifecycle.Start(ctx, cfg.Lifecycle, adm)
+
+	// Create server with h2c support (HTTP/2 without TLS)
+	srv := &http.Server{
+		Addr:              cfg.Addr(),
+		Handler:           server.ConnectionModeMiddleware(h2c.NewHandler(server.TraceHeadersMiddleware(logging.HTTP(recorder.HTTP(chaos.Resettable(mux)))), &http2.Server{})),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	// Graceful shutdown: fail /healthz, /readyz and gRPC health for the
+	// drain delay, then let in-flight RPCs complete
+	log.Printf("Shutdown drain delay: %dms, timeout: %dms", cfg.ShutdownDrainDelayMs, cfg.ShutdownTimeoutMs)
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		<-ctx.Done()
+
+		log.Println("Shutting down server...")
+		probes.Drain()
+		checker.SetStatus("", grpchealth.StatusNotServing)
+		checker.SetStatus(echov1connect.EchoName, grpchealth.StatusNotServing)
+		time.Sleep(time.Duration(cfg.ShutdownDrainDelayMs) * time.Millisecond)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.ShutdownTimeoutMs)*time.Millisecond)
+		defer cancel()
+
+		if err := srv.Shutdown(ctx); err != nil {
+			slog.Error("Server shutdown error", "error", err)
+		}
+		if metricsServer != nil {
+			_ = metricsServer.Shutdown(ctx)
+		}
+		if adminServer != nil {
+			_ = adminServer.Shutdown(ctx)
+		}
+	}()
+
+	listener, err := network.Listen(cfg.Addr())
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	defer func() { _ = listener.Close() }()
+	log.Printf("Starting Connect RPC server on %s", cfg.Addr())
+	log.Printf("Protocol configuration: ConnectRPC=%v, gRPC=%v, gRPC-Web=%v",
+		!cfg.DisableConnectRPC, !cfg.DisableGRPC, !cfg.DisableGRPCWeb)
+
+	if err := srv.Serve(shaper.Listen(connLimiter.Listen(listener, connlimit.HTTP))); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve: %w", err)
+	}
+	<-stopped
+
+	log.Println("Server stopped")
+	return nil
+}
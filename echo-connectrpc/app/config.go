@@ -0,0 +1,178 @@
+package app
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/probitas-test/echo-servers/echo-connectrpc/server"
+	"github.com/probitas-test/echo-servers/shared/admin"
+	"github.com/probitas-test/echo-servers/shared/bandwidth"
+	"github.com/probitas-test/echo-servers/shared/chaos"
+	"github.com/probitas-test/echo-servers/shared/clients"
+	"github.com/probitas-test/echo-servers/shared/clock"
+	"github.com/probitas-test/echo-servers/shared/config"
+	"github.com/probitas-test/echo-servers/shared/connlimit"
+	"github.com/probitas-test/echo-servers/shared/credentials"
+	"github.com/probitas-test/echo-servers/shared/lifecycle"
+	"github.com/probitas-test/echo-servers/shared/logging"
+	"github.com/probitas-test/echo-servers/shared/network"
+	"github.com/probitas-test/echo-servers/shared/ratelimit"
+	"github.com/probitas-test/echo-servers/shared/recording"
+	"github.com/probitas-test/echo-servers/shared/script"
+	"github.com/probitas-test/echo-servers/shared/tracing"
+)
+
+type Config struct {
+	Host                     string
+	Port                     string
+	DisableConnectRPC        bool
+	DisableGRPC              bool
+	DisableGRPCWeb           bool
+	ReflectionIncludeDeps    bool
+	DisableReflectionV1      bool
+	DisableReflectionV1Alpha bool
+
+	// Per-method protocol control (procedure paths such as
+	// "echo.v1.Echo/ServerStream" or "echo.v1.Echo/*")
+	DisableConnectRPCMethods []string
+	DisableGRPCMethods       []string
+	DisableGRPCWebMethods    []string
+
+	// Authentication (disabled unless at least one credential source is set)
+	AuthBearerTokens []string
+	AuthAPIKeys      []string
+	AuthJWKSURL      string
+
+	// WebSocket bridge for streaming RPCs (connect-es style envelopes)
+	WebSocketBridgeEnabled bool
+
+	// Connect GET of the NO_SIDE_EFFECTS RPCs with ETag and Cache-Control
+	// (CONNECT_GET_ENABLED, CONNECT_GET_MAX_AGE_S)
+	ConnectGet server.GetCacheConfig
+
+	// Graceful shutdown: how long /healthz, /readyz and gRPC health report
+	// the drain before connections are closed, and how long in-flight RPCs
+	// may take to complete
+	ShutdownDrainDelayMs int
+	ShutdownTimeoutMs    int
+
+	// Prometheus metrics, served at /metrics on a separate port
+	MetricsEnabled bool
+	MetricsPort    string
+
+	// Admin API on a separate port (ADMIN_ENABLED, ADMIN_PORT)
+	Admin admin.Config
+
+	// Fault injection (CHAOS_*), changed at runtime at /chaos
+	Chaos chaos.Config
+
+	// Rate limiting (RATE_LIMIT_*), changed at runtime at /ratelimit
+	RateLimit ratelimit.Config
+
+	// Traffic recording (RECORDING_*), downloaded from the admin port
+	Recording recording.Config
+
+	// Scripted scenarios (SCRIPT_*), replaced at runtime at /script
+	Script script.Config
+
+	// Client behavior profiles (CLIENT_PROFILES_*), replaced at runtime
+	// at /clients
+	ClientProfiles clients.Config
+
+	// Bandwidth shaping of accepted connections (BANDWIDTH_*), changed at
+	// runtime at /bandwidth on the admin port
+	Bandwidth bandwidth.Config
+
+	// Connection cap (CONN_LIMIT_*), changed at runtime at /connlimit on the
+	// admin port
+	ConnLimit connlimit.Config
+
+	// Address family of every listener (IP_FAMILY: dual, ipv4 or ipv6)
+	Network network.Config
+
+	// Simulated startup delay, readiness delay and crash (STARTUP_DELAY_MS, STARTUP_READY_DELAY_MS, CRASH_AFTER_MS, CRASH_EXIT_CODE)
+	Lifecycle lifecycle.Config
+
+	// Test identities shared with the other servers (CREDENTIALS_USERS, CREDENTIALS_FILE, CREDENTIALS_REQUIRED)
+	Credentials credentials.Config
+
+	// Controllable clock of JWT expiry (CLOCK_OFFSET_MS), moved at runtime at /clock on the admin port
+	Clock clock.Config
+
+	// Structured logging (LOG_LEVEL, LOG_FORMAT)
+	Logging logging.Config
+
+	// OpenTelemetry tracing (OTEL_* variables)
+	Tracing tracing.Config
+
+	// Effective configuration, served at /config
+	src *config.Source
+}
+
+// LoadConfig reads the settings of echo-connectrpc from src
+func LoadConfig(src *config.Source) (*Config, error) {
+	cfg := &Config{
+		Host:                     src.String("HOST", "0.0.0.0"),
+		Port:                     src.String("PORT", "8080"),
+		DisableConnectRPC:        src.Bool("DISABLE_CONNECTRPC", false),
+		DisableGRPC:              src.Bool("DISABLE_GRPC", false),
+		DisableGRPCWeb:           src.Bool("DISABLE_GRPC_WEB", false),
+		ReflectionIncludeDeps:    src.Bool("REFLECTION_INCLUDE_DEPENDENCIES", false),
+		DisableReflectionV1:      src.Bool("DISABLE_REFLECTION_V1", false),
+		DisableReflectionV1Alpha: src.Bool("DISABLE_REFLECTION_V1ALPHA", false),
+
+		DisableConnectRPCMethods: src.List("DISABLE_CONNECTRPC_METHODS", ""),
+		DisableGRPCMethods:       src.List("DISABLE_GRPC_METHODS", ""),
+		DisableGRPCWebMethods:    src.List("DISABLE_GRPC_WEB_METHODS", ""),
+
+		AuthBearerTokens: src.SecretList("AUTH_BEARER_TOKENS", ""),
+		AuthAPIKeys:      src.SecretList("AUTH_API_KEYS", ""),
+		AuthJWKSURL:      src.String("AUTH_JWKS_URL", ""),
+
+		WebSocketBridgeEnabled: src.Bool("WEBSOCKET_BRIDGE_ENABLED", false),
+
+		ConnectGet: server.GetCacheConfig{
+			Enabled: src.Bool("CONNECT_GET_ENABLED", false),
+			MaxAge:  time.Duration(src.Int("CONNECT_GET_MAX_AGE_S", 0)) * time.Second,
+		},
+
+		ShutdownDrainDelayMs: src.Int("SHUTDOWN_DRAIN_DELAY_MS", 0),
+		ShutdownTimeoutMs:    src.Int("SHUTDOWN_TIMEOUT_MS", 5000),
+
+		MetricsEnabled: src.Bool("METRICS_ENABLED", true),
+		MetricsPort:    src.String("METRICS_PORT", "9090"),
+
+		Admin:          admin.LoadConfig(src),
+		Chaos:          chaos.LoadConfig(src),
+		RateLimit:      ratelimit.LoadConfig(src),
+		Tracing:        tracing.LoadConfig(src, "echo-connectrpc"),
+		Recording:      recording.LoadConfig(src),
+		Script:         script.LoadConfig(src),
+		ClientProfiles: clients.LoadConfig(src),
+		Bandwidth:      bandwidth.LoadConfig(src),
+		ConnLimit:      connlimit.LoadConfig(src),
+		Network:        network.LoadConfig(src),
+		Lifecycle:      lifecycle.LoadConfig(src),
+		Credentials:    credentials.LoadConfig(src),
+		Clock:          clock.LoadConfig(src),
+		Logging:        logging.LoadConfig(src),
+	}
+	cfg.src = src
+	if err := src.Err(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	return cfg, nil
+}
+
+func (c *Config) Addr() string {
+	return net.JoinHostPort(c.Host, c.Port)
+}
+
+func (c *Config) MetricsAddr() string {
+	return net.JoinHostPort(c.Host, c.MetricsPort)
+}
+
+func (c *Config) AdminAddr() string {
+	return net.JoinHostPort(c.Host, c.Admin.Port)
+}
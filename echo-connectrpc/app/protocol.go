@@ -0,0 +1,91 @@
+package app
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"connectrpc.com/connect"
+)
+
+// protocolFilterMiddleware filters requests based on the Connect protocol header.
+// Protocols can be disabled globally or for specific procedures. Rejected
+// requests receive a protocol-specific Unimplemented error (gRPC trailers,
+// gRPC-Web trailers, or a Connect error body/end-stream message), so clients
+// observe a spec-compliant failure instead of a plain-text HTTP error.
+func protocolFilterMiddleware(cfg *Config, next http.Handler) http.Handler {
+	errorWriter := connect.NewErrorWriter()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType := r.Header.Get("Content-Type")
+
+		// Determine protocol from content type and headers
+		// gRPC-Web has specific content type
+		isGRPCWeb := contains(contentType, "application/grpc-web")
+		// gRPC has application/grpc but not grpc-web
+		isGRPC := contains(contentType, "application/grpc") && !isGRPCWeb
+		// Connect RPC uses application/connect+, application/json, or application/proto
+		// (or GET for side-effect-free unary calls)
+		isConnectRPC := contains(contentType, "application/connect+") ||
+			contentType == "application/json" ||
+			contentType == "application/proto" ||
+			contains(contentType, "application/json;") ||
+			contains(contentType, "application/proto;") ||
+			r.Method == http.MethodGet
+
+		procedure := r.URL.Path
+
+		// If it's a recognized protocol, check if it's disabled
+		if isGRPC && (cfg.DisableGRPC || matchesProcedure(cfg.DisableGRPCMethods, procedure)) {
+			writeProtocolDisabled(w, r, errorWriter, "gRPC", procedure)
+			return
+		}
+		if isGRPCWeb && (cfg.DisableGRPCWeb || matchesProcedure(cfg.DisableGRPCWebMethods, procedure)) {
+			writeProtocolDisabled(w, r, errorWriter, "gRPC-Web", procedure)
+			return
+		}
+		if isConnectRPC && (cfg.DisableConnectRPC || matchesProcedure(cfg.DisableConnectRPCMethods, procedure)) {
+			writeProtocolDisabled(w, r, errorWriter, "Connect RPC", procedure)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func writeProtocolDisabled(w http.ResponseWriter, r *http.Request, errorWriter *connect.ErrorWriter, protocol, procedure string) {
+	err := connect.NewError(connect.CodeUnimplemented, fmt.Errorf("%s protocol is disabled for %s", protocol, procedure))
+	_ = errorWriter.Write(w, r, err)
+}
+
+// matchesProcedure reports whether procedure matches any of the patterns.
+// A pattern is either a full procedure ("echo.v1.Echo/Echo") or a service
+// wildcard ("echo.v1.Echo/*"). The leading slash is optional.
+func matchesProcedure(patterns []string, procedure string) bool {
+	procedure = strings.TrimPrefix(procedure, "/")
+	for _, pattern := range patterns {
+		pattern = strings.TrimPrefix(pattern, "/")
+		if service, ok := strings.CutSuffix(pattern, "/*"); ok {
+			if strings.HasPrefix(procedure, service+"/") {
+				return true
+			}
+			continue
+		}
+		if pattern == procedure {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(s, substr string) bool {
+	if len(s) < len(substr) {
+		return false
+	}
+	for i := 0; i <= len(s)-len(substr); i++ {
+		if s[i:i+len(substr)] == substr {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"connectrpc.com/connect"
+
+	"github.com/probitas-test/echo-servers/echo-connectrpc/server"
+	pb "github.com/probitas-test/echo-servers/proto/echo/v1"
+	"github.com/probitas-test/echo-servers/proto/echo/v1/echov1connect"
+)
+
+func setupProtocolTestServer(t *testing.T, cfg *Config) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	path, handler := echov1connect.NewEchoHandler(server.NewEchoServer())
+	mux.Handle(path, protocolFilterMiddleware(cfg, handler))
+
+	// gRPC requires HTTP/2
+	srv := httptest.NewUnstartedServer(mux)
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestProtocolFilter_DisabledProtocol(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *Config
+		opts []connect.ClientOption
+	}{
+		{name: "connect", cfg: &Config{DisableConnectRPC: true}},
+		{name: "grpc", cfg: &Config{DisableGRPC: true}, opts: []connect.ClientOption{connect.WithGRPC()}},
+		{name: "grpc-web", cfg: &Config{DisableGRPCWeb: true}, opts: []connect.ClientOption{connect.WithGRPCWeb()}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := setupProtocolTestServer(t, tt.cfg)
+			client := echov1connect.NewEchoClient(srv.Client(), srv.URL, tt.opts...)
+
+			_, err := client.Echo(context.Background(), connect.NewRequest(&pb.EchoRequest{Message: "hello"}))
+			if connect.CodeOf(err) != connect.CodeUnimplemented {
+				t.Fatalf("expected CodeUnimplemented, got %v", err)
+			}
+		})
+	}
+}
+
+func TestProtocolFilter_DisabledMethods(t *testing.T) {
+	cfg := &Config{
+		DisableGRPCWebMethods: []string{
+			"echo.v1.Echo/ServerStream",
+			"/echo.v1.Echo/ClientStream",
+		},
+	}
+	srv := setupProtocolTestServer(t, cfg)
+	ctx := context.Background()
+
+	grpcWeb := echov1connect.NewEchoClient(srv.Client(), srv.URL, connect.WithGRPCWeb())
+
+	// Unary methods remain available over gRPC-Web
+	if _, err := grpcWeb.Echo(ctx, connect.NewRequest(&pb.EchoRequest{Message: "hello"})); err != nil {
+		t.Fatalf("Echo over gRPC-Web failed: %v", err)
+	}
+
+	// Disabled streaming methods fail with a gRPC-Web error frame
+	stream, err := grpcWeb.ServerStream(ctx, connect.NewRequest(&pb.ServerStreamRequest{Message: "hello", Count: 1}))
+	if err != nil {
+		t.Fatalf("ServerStream failed to start: %v", err)
+	}
+	for stream.Receive() {
+		t.Fatal("expected no messages from disabled method")
+	}
+	if connect.CodeOf(stream.Err()) != connect.CodeUnimplemented {
+		t.Errorf("expected CodeUnimplemented, got %v", stream.Err())
+	}
+
+	// Other protocols are unaffected
+	connectClient := echov1connect.NewEchoClient(srv.Client(), srv.URL)
+	stream, err = connectClient.ServerStream(ctx, connect.NewRequest(&pb.ServerStreamRequest{Message: "hello", Count: 1}))
+	if err != nil {
+		t.Fatalf("ServerStream failed to start: %v", err)
+	}
+	count := 0
+	for stream.Receive() {
+		count++
+	}
+	if err := stream.Err(); err != nil {
+		t.Fatalf("ServerStream over Connect failed: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("expected 1 message, got %d", count)
+	}
+}
+
+func TestMatchesProcedure(t *testing.T) {
+	tests := []struct {
+		name      string
+		patterns  []string
+		procedure string
+		want      bool
+	}{
+		{name: "exact", patterns: []string{"echo.v1.Echo/Echo"}, procedure: "/echo.v1.Echo/Echo", want: true},
+		{name: "leading slash", patterns: []string{"/echo.v1.Echo/Echo"}, procedure: "/echo.v1.Echo/Echo", want: true},
+		{name: "other method", patterns: []string{"echo.v1.Echo/Echo"}, procedure: "/echo.v1.Echo/EchoError", want: false},
+		{name: "service wildcard", patterns: []string{"echo.v1.Echo/*"}, procedure: "/echo.v1.Echo/ServerStream", want: true},
+		{name: "wildcard other service", patterns: []string{"echo.v1.Echo/*"}, procedure: "/grpc.health.v1.Health/Check", want: false},
+		{name: "no patterns", patterns: nil, procedure: "/echo.v1.Echo/Echo", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchesProcedure(tt.patterns, tt.procedure); got != tt.want {
+				t.Errorf("matchesProcedure(%v, %q) = %v, want %v", tt.patterns, tt.procedure, got, tt.want)
+			}
+		})
+	}
+}
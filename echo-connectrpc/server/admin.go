@@ -0,0 +1,43 @@
+package server
+
+import (
+	"context"
+
+	"connectrpc.com/connect"
+
+	"github.com/probitas-test/echo-servers/shared/admin"
+)
+
+// AdminInterceptor delays every RPC by the delay set through the admin API.
+// It is installed on the echo service only, so health checks and
+// reflection answer without delay.
+type AdminInterceptor struct {
+	admin *admin.Admin
+}
+
+// NewAdminInterceptor creates an interceptor applying the delay of a.
+func NewAdminInterceptor(a *admin.Admin) *AdminInterceptor {
+	return &AdminInterceptor{admin: a}
+}
+
+func (i *AdminInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		if err := i.admin.Wait(ctx); err != nil {
+			return nil, contextError(err)
+		}
+		return next(ctx, req)
+	}
+}
+
+func (i *AdminInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+func (i *AdminInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		if err := i.admin.Wait(ctx); err != nil {
+			return contextError(err)
+		}
+		return next(ctx, conn)
+	}
+}
@@ -0,0 +1,46 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+
+	pb "github.com/probitas-test/echo-servers/proto/echo/v1"
+	"github.com/probitas-test/echo-servers/proto/echo/v1/echov1connect"
+	"github.com/probitas-test/echo-servers/shared/admin"
+)
+
+func TestAdminInterceptor(t *testing.T) {
+	a := admin.New("echo-connectrpc", nil)
+	a.SetDelay(100 * time.Millisecond)
+
+	mux := http.NewServeMux()
+	path, handler := echov1connect.NewEchoHandler(NewEchoServer(),
+		connect.WithInterceptors(NewAdminInterceptor(a)))
+	mux.Handle(path, handler)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client := echov1connect.NewEchoClient(server.Client(), server.URL)
+
+	start := time.Now()
+	if _, err := client.Echo(context.Background(), connect.NewRequest(&pb.EchoRequest{Message: "hello"})); err != nil {
+		t.Fatalf("Echo failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("Echo took %v, want the 100ms delay", elapsed)
+	}
+
+	// The deadline of the RPC ends the delay
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := client.Echo(ctx, connect.NewRequest(&pb.EchoRequest{Message: "hello"}))
+	var connectErr *connect.Error
+	if !errors.As(err, &connectErr) || connectErr.Code() != connect.CodeDeadlineExceeded {
+		t.Errorf("Echo with a short deadline = %v, want deadline_exceeded", err)
+	}
+}
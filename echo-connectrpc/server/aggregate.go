@@ -0,0 +1,94 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Request headers selecting how ClientStream aggregates the messages, and
+// the response header reporting the number of messages received
+const (
+	AggregateHeader          = "X-Aggregate"
+	AggregateSeparatorHeader = "X-Aggregate-Separator"
+	MessageCountHeader       = "X-Message-Count"
+)
+
+// Aggregation modes of ClientStream
+const (
+	AggregateConcat   = "concat"   // messages joined with the separator (default ", ")
+	AggregateCount    = "count"    // number of messages
+	AggregateFirst    = "first"    // first message
+	AggregateLast     = "last"     // last message
+	AggregateChecksum = "checksum" // hex SHA-256 of the concatenated messages
+	AggregateBytes    = "bytes"    // total size of the messages in bytes
+)
+
+// aggregator folds the messages of a client stream as they arrive
+type aggregator struct {
+	mode      string
+	separator string
+
+	count       int
+	bytes       int
+	first, last string
+	joined      strings.Builder
+	sum         hash.Hash
+}
+
+// newAggregator returns the aggregator selected by the request headers
+func newAggregator(header http.Header) (*aggregator, error) {
+	a := &aggregator{mode: strings.ToLower(header.Get(AggregateHeader)), separator: ", "}
+	if a.mode == "" {
+		a.mode = AggregateConcat
+	}
+	if values, ok := header[http.CanonicalHeaderKey(AggregateSeparatorHeader)]; ok && len(values) > 0 {
+		a.separator = values[0]
+	}
+	switch a.mode {
+	case AggregateConcat, AggregateCount, AggregateFirst, AggregateLast, AggregateBytes:
+	case AggregateChecksum:
+		a.sum = sha256.New()
+	default:
+		return nil, fmt.Errorf("unknown aggregation %q (concat, count, first, last, checksum or bytes)", a.mode)
+	}
+	return a, nil
+}
+
+func (a *aggregator) add(message string) {
+	if a.count == 0 {
+		a.first = message
+	} else if a.mode == AggregateConcat {
+		a.joined.WriteString(a.separator)
+	}
+	a.count++
+	a.bytes += len(message)
+	a.last = message
+	switch a.mode {
+	case AggregateConcat:
+		a.joined.WriteString(message)
+	case AggregateChecksum:
+		a.sum.Write([]byte(message))
+	}
+}
+
+// result returns the aggregate of the messages added
+func (a *aggregator) result() string {
+	switch a.mode {
+	case AggregateCount:
+		return strconv.Itoa(a.count)
+	case AggregateFirst:
+		return a.first
+	case AggregateLast:
+		return a.last
+	case AggregateChecksum:
+		return hex.EncodeToString(a.sum.Sum(nil))
+	case AggregateBytes:
+		return strconv.Itoa(a.bytes)
+	}
+	return a.joined.String()
+}
@@ -0,0 +1,168 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"connectrpc.com/connect"
+
+	"github.com/probitas-test/echo-servers/shared/clock"
+	"github.com/probitas-test/echo-servers/shared/credentials"
+	"github.com/probitas-test/echo-servers/shared/jwt"
+)
+
+const (
+	authRealm   = "echo-connectrpc"
+	apiKeyField = "X-Api-Key"
+)
+
+// AuthConfig configures the credentials accepted by AuthInterceptor.
+type AuthConfig struct {
+	// BearerTokens are accepted verbatim in "Authorization: Bearer <token>".
+	BearerTokens []string
+	// APIKeys are accepted in the "x-api-key" header.
+	APIKeys []string
+	// JWKSURL enables JWT bearer tokens verified against this key set
+	// (e.g. the echo-http mock OIDC provider's /.well-known/jwks.json).
+	JWKSURL string
+	// Credentials are the test identities shared with the other servers:
+	// their API keys, bearer tokens and Basic passwords are accepted as
+	// well. Without another source, credentials are required only while
+	// the store is Required.
+	Credentials *credentials.Store
+	// Clock is the time of JWT expiry and of the JWKS cache (nil: the real
+	// time).
+	Clock *clock.Clock
+}
+
+// Enabled reports whether any static credential source is configured.
+func (c AuthConfig) Enabled() bool {
+	return len(c.BearerTokens) > 0 || len(c.APIKeys) > 0 || c.JWKSURL != ""
+}
+
+// required reports whether RPCs need credentials.
+func (c AuthConfig) required() bool {
+	return c.Enabled() || (c.Credentials != nil && c.Credentials.Required())
+}
+
+// AuthInterceptor rejects RPCs without valid credentials with
+// CodeUnauthenticated and a WWW-Authenticate header (gRPC: trailer).
+type AuthInterceptor struct {
+	cfg  AuthConfig
+	jwks *jwt.KeySet
+}
+
+// NewAuthInterceptor creates an interceptor validating bearer tokens, API
+// keys, or JWTs according to cfg.
+func NewAuthInterceptor(cfg AuthConfig) *AuthInterceptor {
+	i := &AuthInterceptor{cfg: cfg}
+	if cfg.JWKSURL != "" {
+		i.jwks = jwt.NewKeySet(cfg.JWKSURL, cfg.Clock)
+		i.jwks.AllowUnsigned = true
+	}
+	return i
+}
+
+func (i *AuthInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		if err := i.authenticate(ctx, req.Header()); err != nil {
+			return nil, err
+		}
+		return next(ctx, req)
+	}
+}
+
+func (i *AuthInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+func (i *AuthInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		if err := i.authenticate(ctx, conn.RequestHeader()); err != nil {
+			return err
+		}
+		return next(ctx, conn)
+	}
+}
+
+func (i *AuthInterceptor) authenticate(ctx context.Context, header http.Header) error {
+	if !i.cfg.required() {
+		return nil
+	}
+
+	if apiKey := header.Get(apiKeyField); apiKey != "" {
+		if containsSecret(i.cfg.APIKeys, apiKey) {
+			return nil
+		}
+		if i.cfg.Credentials != nil {
+			if _, ok := i.cfg.Credentials.APIKey(apiKey); ok {
+				return nil
+			}
+		}
+		return newAuthError("invalid API key", `Bearer realm="`+authRealm+`", error="invalid_token"`)
+	}
+
+	authHeader := header.Get("Authorization")
+	if authHeader == "" {
+		return newAuthError("missing credentials", `Bearer realm="`+authRealm+`"`)
+	}
+
+	scheme, token, ok := strings.Cut(authHeader, " ")
+	if ok && strings.EqualFold(scheme, "Basic") && i.cfg.Credentials != nil {
+		if _, err := i.cfg.Credentials.Authenticate(authHeader, ""); err != nil {
+			return newAuthError("invalid username or password", `Bearer realm="`+authRealm+`", Basic realm="`+authRealm+`"`)
+		}
+		return nil
+	}
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return newAuthError("invalid authorization header", `Bearer realm="`+authRealm+`", error="invalid_request"`)
+	}
+
+	if containsSecret(i.cfg.BearerTokens, token) {
+		return nil
+	}
+	if i.cfg.Credentials != nil {
+		if _, ok := i.cfg.Credentials.Bearer(token); ok {
+			return nil
+		}
+	}
+
+	if i.jwks != nil && strings.Count(token, ".") == 2 {
+		if err := i.verifyJWT(ctx, token); err != nil {
+			return newAuthError(err.Error(), fmt.Sprintf(`Bearer realm="%s", error="invalid_token", error_description=%q`, authRealm, err.Error()))
+		}
+		return nil
+	}
+
+	return newAuthError("invalid bearer token", `Bearer realm="`+authRealm+`", error="invalid_token"`)
+}
+
+func newAuthError(message, challenge string) *connect.Error {
+	err := connect.NewError(connect.CodeUnauthenticated, errors.New(message))
+	err.Meta().Set("WWW-Authenticate", challenge)
+	return err
+}
+
+// containsSecret reports whether value is one of secrets using constant-time
+// comparison.
+func containsSecret(secrets []string, value string) bool {
+	found := false
+	for _, s := range secrets {
+		if subtle.ConstantTimeCompare([]byte(s), []byte(value)) == 1 {
+			found = true
+		}
+	}
+	return found
+}
+
+// verifyJWT verifies the token signature against the JWKS and checks the exp
+// and nbf claims. Unsigned tokens (alg "none") are accepted only when the
+// JWKS publishes no keys, matching the echo-http mock OIDC provider.
+func (i *AuthInterceptor) verifyJWT(ctx context.Context, token string) error {
+	_, err := i.jwks.Validate(ctx, token)
+	return err
+}
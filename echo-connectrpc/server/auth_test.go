@@ -0,0 +1,242 @@
+package server
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+
+	pb "github.com/probitas-test/echo-servers/proto/echo/v1"
+	"github.com/probitas-test/echo-servers/proto/echo/v1/echov1connect"
+	"github.com/probitas-test/echo-servers/shared/clock"
+	"github.com/probitas-test/echo-servers/shared/credentials"
+	"github.com/probitas-test/echo-servers/shared/jwt"
+)
+
+func setupAuthTestServer(t *testing.T, cfg AuthConfig) echov1connect.EchoClient {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	path, handler := echov1connect.NewEchoHandler(
+		NewEchoServer(),
+		connect.WithInterceptors(NewAuthInterceptor(cfg)),
+	)
+	mux.Handle(path, handler)
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return echov1connect.NewEchoClient(http.DefaultClient, server.URL)
+}
+
+func callEcho(client echov1connect.EchoClient, headers map[string]string) error {
+	req := connect.NewRequest(&pb.EchoRequest{Message: "hello"})
+	for k, v := range headers {
+		req.Header().Set(k, v)
+	}
+	_, err := client.Echo(context.Background(), req)
+	return err
+}
+
+func assertUnauthenticated(t *testing.T, err error) {
+	t.Helper()
+
+	var connectErr *connect.Error
+	if !errors.As(err, &connectErr) || connectErr.Code() != connect.CodeUnauthenticated {
+		t.Fatalf("expected CodeUnauthenticated, got %v", err)
+	}
+	if got := connectErr.Meta().Get("WWW-Authenticate"); !strings.HasPrefix(got, "Bearer") {
+		t.Errorf("expected WWW-Authenticate Bearer challenge, got %q", got)
+	}
+}
+
+func TestAuthInterceptor_StaticCredentials(t *testing.T) {
+	client := setupAuthTestServer(t, AuthConfig{
+		BearerTokens: []string{"secret-token"},
+		APIKeys:      []string{"secret-key"},
+	})
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		wantErr bool
+	}{
+		{name: "valid bearer token", headers: map[string]string{"Authorization": "Bearer secret-token"}},
+		{name: "valid api key", headers: map[string]string{"x-api-key": "secret-key"}},
+		{name: "missing credentials", wantErr: true},
+		{name: "invalid bearer token", headers: map[string]string{"Authorization": "Bearer wrong"}, wantErr: true},
+		{name: "invalid api key", headers: map[string]string{"x-api-key": "wrong"}, wantErr: true},
+		{name: "basic scheme", headers: map[string]string{"Authorization": "Basic dXNlcjpwYXNz"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := callEcho(client, tt.headers)
+			if tt.wantErr {
+				assertUnauthenticated(t, err)
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected success, got %v", err)
+			}
+		})
+	}
+}
+
+func TestAuthInterceptor_Credentials(t *testing.T) {
+	store, err := credentials.New(credentials.Config{Users: []string{"alice:secret"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := setupAuthTestServer(t, AuthConfig{Credentials: store})
+
+	if err := callEcho(client, nil); err != nil {
+		t.Fatalf("credentials not required: %v", err)
+	}
+	c := store.Credentials()
+	c.Required = true
+	if err := store.Set(c); err != nil {
+		t.Fatal(err)
+	}
+
+	alice := credentials.User{Username: "alice", Password: "secret"}
+	tests := []struct {
+		name    string
+		headers map[string]string
+		wantErr bool
+	}{
+		{name: "basic password", headers: map[string]string{"Authorization": "Basic " + base64.StdEncoding.EncodeToString([]byte("alice:secret"))}},
+		{name: "derived bearer token", headers: map[string]string{"Authorization": "Bearer " + alice.Token()}},
+		{name: "missing credentials", wantErr: true},
+		{name: "wrong password", headers: map[string]string{"Authorization": "Basic " + base64.StdEncoding.EncodeToString([]byte("alice:wrong"))}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := callEcho(client, tt.headers)
+			if tt.wantErr {
+				assertUnauthenticated(t, err)
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected success, got %v", err)
+			}
+		})
+	}
+}
+
+func TestAuthInterceptor_Streaming(t *testing.T) {
+	client := setupAuthTestServer(t, AuthConfig{BearerTokens: []string{"secret-token"}})
+
+	stream, err := client.ServerStream(context.Background(), connect.NewRequest(&pb.ServerStreamRequest{Message: "hello", Count: 1}))
+	if err != nil {
+		t.Fatalf("ServerStream failed to start: %v", err)
+	}
+	for stream.Receive() {
+		t.Fatal("expected no messages without credentials")
+	}
+	assertUnauthenticated(t, stream.Err())
+}
+
+func newJWKSServer(t *testing.T, keys []jwt.Key) string {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": keys})
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func encodeSegment(t *testing.T, v any) string {
+	t.Helper()
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]any) string {
+	t.Helper()
+
+	signingInput := encodeSegment(t, map[string]string{"alg": "RS256", "typ": "JWT", "kid": kid}) + "." + encodeSegment(t, claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestAuthInterceptor_JWKS(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	jwksURL := newJWKSServer(t, []jwt.Key{{
+		Kty: "RSA",
+		Kid: "test-key",
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}})
+	client := setupAuthTestServer(t, AuthConfig{JWKSURL: jwksURL})
+
+	valid := signRS256(t, key, "test-key", map[string]any{"sub": "testuser", "exp": time.Now().Add(time.Hour).Unix()})
+	expired := signRS256(t, key, "test-key", map[string]any{"sub": "testuser", "exp": time.Now().Add(-time.Hour).Unix()})
+	wrongKey := signRS256(t, otherKey, "test-key", map[string]any{"sub": "testuser"})
+	unsigned := encodeSegment(t, map[string]string{"alg": "none"}) + "." + encodeSegment(t, map[string]any{"sub": "testuser"}) + "."
+
+	if err := callEcho(client, map[string]string{"Authorization": "Bearer " + valid}); err != nil {
+		t.Fatalf("expected valid JWT to succeed, got %v", err)
+	}
+	for name, token := range map[string]string{"expired": expired, "wrong key": wrongKey, "unsigned": unsigned} {
+		t.Run(name, func(t *testing.T) {
+			assertUnauthenticated(t, callEcho(client, map[string]string{"Authorization": "Bearer " + token}))
+		})
+	}
+}
+
+func TestAuthInterceptor_JWKSClock(t *testing.T) {
+	// The empty JWKS of the mock OIDC provider accepts its unsigned tokens
+	clk := clock.New(clock.Config{})
+	client := setupAuthTestServer(t, AuthConfig{JWKSURL: newJWKSServer(t, []jwt.Key{}), Clock: clk})
+	token := encodeSegment(t, map[string]string{"alg": "none"}) + "." +
+		encodeSegment(t, map[string]any{"sub": "testuser", "exp": time.Now().Add(time.Hour).Unix()}) + "."
+
+	if err := callEcho(client, map[string]string{"Authorization": "Bearer " + token}); err != nil {
+		t.Fatalf("expected unexpired JWT to succeed, got %v", err)
+	}
+	clk.Advance(2 * time.Hour)
+	assertUnauthenticated(t, callEcho(client, map[string]string{"Authorization": "Bearer " + token}))
+}
+
+func TestAuthInterceptor_JWKSUnsignedMockOIDC(t *testing.T) {
+	// The echo-http mock OIDC provider issues alg=none tokens and an empty JWKS
+	client := setupAuthTestServer(t, AuthConfig{JWKSURL: newJWKSServer(t, []jwt.Key{})})
+
+	token := encodeSegment(t, map[string]string{"alg": "none", "typ": "JWT"}) + "." +
+		encodeSegment(t, map[string]any{"sub": "testuser", "exp": time.Now().Add(time.Hour).Unix()}) + "."
+
+	if err := callEcho(client, map[string]string{"Authorization": "Bearer " + token}); err != nil {
+		t.Fatalf("expected unsigned token to succeed with empty JWKS, got %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"connectrpc.com/connect"
+
+	"github.com/probitas-test/echo-servers/shared/chaos"
+)
+
+// ChaosInterceptor injects the faults of a chaos engine into every RPC,
+// matching rules against the procedure. Injected errors carry the Connect
+// code of their HTTP status and the X-Chaos: error header, resets close the
+// connection (HTTP/1, needing chaos.Resettable) or the stream (HTTP/2), and
+// the bandwidth delays every response message by its size.
+type ChaosInterceptor struct {
+	engine *chaos.Engine
+}
+
+// NewChaosInterceptor creates an interceptor injecting the faults of e.
+func NewChaosInterceptor(e *chaos.Engine) *ChaosInterceptor {
+	return &ChaosInterceptor{engine: e}
+}
+
+func (i *ChaosInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		d := i.engine.Decide(req.Spec().Procedure)
+		if err := inject(ctx, d); err != nil {
+			return nil, err
+		}
+		resp, err := next(ctx, req)
+		if err == nil {
+			if err := d.Throttle(ctx, messageSize(resp.Any())); err != nil {
+				return nil, contextError(err)
+			}
+		}
+		return resp, err
+	}
+}
+
+func (i *ChaosInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+func (i *ChaosInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		d := i.engine.Decide(conn.Spec().Procedure)
+		if err := inject(ctx, d); err != nil {
+			return err
+		}
+		if d.Bandwidth > 0 {
+			conn = &throttledConn{StreamingHandlerConn: conn, ctx: ctx, decision: d}
+		}
+		return next(ctx, conn)
+	}
+}
+
+// inject waits for the delay of d, then resets the connection or returns
+// the injected error
+func inject(ctx context.Context, d chaos.Decision) error {
+	if err := d.Wait(ctx); err != nil {
+		return contextError(err)
+	}
+	switch {
+	case d.Reset:
+		if !chaos.Reset(ctx) {
+			panic(http.ErrAbortHandler)
+		}
+		return connect.NewError(connect.CodeUnavailable, errors.New("chaos: connection reset"))
+	case d.Status != 0:
+		err := connect.NewError(connect.Code(chaos.Code(d.Status)), errors.New("chaos: injected error"))
+		err.Meta().Set(chaos.Header, "error")
+		return err
+	}
+	return nil
+}
+
+// contextError converts the error of a done context to its Connect error
+func contextError(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return connect.NewError(connect.CodeDeadlineExceeded, err)
+	}
+	return connect.NewError(connect.CodeCanceled, err)
+}
+
+// throttledConn delays every message sent by its size at the bandwidth of
+// a chaos decision
+type throttledConn struct {
+	connect.StreamingHandlerConn
+	ctx      context.Context
+	decision chaos.Decision
+}
+
+func (c *throttledConn) Send(msg any) error {
+	if err := c.decision.Throttle(c.ctx, messageSize(msg)); err != nil {
+		return err
+	}
+	return c.StreamingHandlerConn.Send(msg)
+}
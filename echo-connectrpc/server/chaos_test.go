@@ -0,0 +1,81 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+
+	pb "github.com/probitas-test/echo-servers/proto/echo/v1"
+	"github.com/probitas-test/echo-servers/proto/echo/v1/echov1connect"
+	"github.com/probitas-test/echo-servers/shared/chaos"
+)
+
+func TestChaosInterceptor(t *testing.T) {
+	engine, err := chaos.New(chaos.Config{Profile: chaos.DefaultProfile()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	profile := func(p chaos.Profile) chaos.Profile {
+		p.LatencyRate = 1
+		if p.ErrorStatus == 0 {
+			p.ErrorStatus = chaos.DefaultErrorStatus
+		}
+		return p
+	}
+	if err := engine.SetState(chaos.State{
+		Enabled: true,
+		Global:  chaos.DefaultProfile(),
+		Rules: []chaos.Rule{
+			{Match: echov1connect.EchoEchoProcedure, Profile: profile(chaos.Profile{ErrorRate: 1, ErrorStatus: 429})},
+			{Match: echov1connect.EchoEchoErrorProcedure, Profile: profile(chaos.Profile{ResetRate: 1})},
+			{Match: "*Stream", Profile: profile(chaos.Profile{LatencyMS: 100, Bandwidth: 1000})},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	mux := http.NewServeMux()
+	path, handler := echov1connect.NewEchoHandler(NewEchoServer(),
+		connect.WithInterceptors(NewChaosInterceptor(engine)))
+	mux.Handle(path, handler)
+	server := httptest.NewServer(chaos.Resettable(mux))
+	defer server.Close()
+	client := echov1connect.NewEchoClient(server.Client(), server.URL)
+	ctx := context.Background()
+
+	// Injected error with the Connect code of its HTTP status
+	_, err = client.Echo(ctx, connect.NewRequest(&pb.EchoRequest{Message: "hello"}))
+	var connectErr *connect.Error
+	if !errors.As(err, &connectErr) || connectErr.Code() != connect.CodeResourceExhausted ||
+		connectErr.Meta().Get(chaos.Header) != "error" {
+		t.Errorf("Echo error = %v, want injected resource_exhausted", err)
+	}
+
+	// Reset connection
+	_, err = client.EchoError(ctx, connect.NewRequest(&pb.EchoErrorRequest{Message: "hello"}))
+	if err == nil {
+		t.Error("EchoError on a reset connection succeeded")
+	}
+
+	// Delayed stream throttled by message size
+	start := time.Now()
+	stream, err := client.ServerStream(ctx, connect.NewRequest(&pb.ServerStreamRequest{Message: "hello", Count: 3}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	received := 0
+	for stream.Receive() {
+		received++
+	}
+	if err := stream.Err(); err != nil || received != 3 {
+		t.Fatalf("ServerStream received %d messages, error %v", received, err)
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("ServerStream took %v, want at least the 100ms delay", elapsed)
+	}
+}
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"connectrpc.com/connect"
+
+	"github.com/probitas-test/echo-servers/shared/clients"
+)
+
+// ClientProfileInterceptor applies the profile of the client of every RPC
+// but the health checks, identified by API key or client ID header or peer
+// address: its rate limit, then its faults, like the rate limit and chaos
+// interceptors. The profile applied is reported in the X-Client-Profile
+// header.
+type ClientProfileInterceptor struct {
+	engine *clients.Engine
+}
+
+// NewClientProfileInterceptor creates an interceptor applying the profiles
+// of e.
+func NewClientProfileInterceptor(e *clients.Engine) *ClientProfileInterceptor {
+	return &ClientProfileInterceptor{engine: e}
+}
+
+func (i *ClientProfileInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		procedure := req.Spec().Procedure
+		m, ok := i.match(req.Peer(), req.Header(), procedure)
+		if !ok {
+			return next(ctx, req)
+		}
+		d := m.Limiter.Take(req.Peer().Addr, req.Header().Get(m.Limiter.Config().KeyHeader), procedure)
+		header := d.Header()
+		header.Set(clients.Header, m.Profile)
+		if err := rateLimitError(d); err != nil {
+			return withHeader(nil, err, header)
+		}
+		defer d.Done()
+		faults := m.Faults.Decide(procedure)
+		if err := inject(ctx, faults); err != nil {
+			return withHeader(nil, err, header)
+		}
+		resp, err := next(ctx, req)
+		if err == nil {
+			if err := faults.Throttle(ctx, messageSize(resp.Any())); err != nil {
+				return withHeader(nil, contextError(err), header)
+			}
+		}
+		return withHeader(resp, err, header)
+	}
+}
+
+func (i *ClientProfileInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+func (i *ClientProfileInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		procedure := conn.Spec().Procedure
+		m, ok := i.match(conn.Peer(), conn.RequestHeader(), procedure)
+		if !ok {
+			return next(ctx, conn)
+		}
+		d := m.Limiter.Take(conn.Peer().Addr, conn.RequestHeader().Get(m.Limiter.Config().KeyHeader), procedure)
+		if err := rateLimitError(d); err != nil {
+			_, err = withHeader(nil, err, http.Header{clients.Header: {m.Profile}})
+			return err
+		}
+		defer d.Done()
+		conn.ResponseHeader().Set(clients.Header, m.Profile)
+		addHeader(conn.ResponseHeader(), d.Header())
+		faults := m.Faults.Decide(procedure)
+		if err := inject(ctx, faults); err != nil {
+			return err
+		}
+		if faults.Bandwidth > 0 {
+			conn = &throttledConn{StreamingHandlerConn: conn, ctx: ctx, decision: faults}
+		}
+		return next(ctx, conn)
+	}
+}
+
+func (i *ClientProfileInterceptor) match(peer connect.Peer, header http.Header, procedure string) (clients.Match, bool) {
+	if strings.HasPrefix(procedure, healthServicePrefix) {
+		return clients.Match{}, false
+	}
+	return i.engine.Match(peer.Addr, header.Get(i.engine.KeyHeader()), header.Get(i.engine.IDHeader()))
+}
+
+// withHeader adds header to the response or the error of an RPC
+func withHeader(resp connect.AnyResponse, err error, header http.Header) (connect.AnyResponse, error) {
+	if connectErr := new(connect.Error); errors.As(err, &connectErr) {
+		addHeader(connectErr.Meta(), header)
+	} else if err == nil {
+		addHeader(resp.Header(), header)
+	}
+	return resp, err
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"connectrpc.com/connect"
+
+	pb "github.com/probitas-test/echo-servers/proto/echo/v1"
+	"github.com/probitas-test/echo-servers/proto/echo/v1/echov1connect"
+	"github.com/probitas-test/echo-servers/shared/chaos"
+	"github.com/probitas-test/echo-servers/shared/clients"
+	"github.com/probitas-test/echo-servers/shared/ratelimit"
+)
+
+func TestClientProfileInterceptor(t *testing.T) {
+	engine, err := clients.New(clients.Config{KeyHeader: clients.DefaultKeyHeader, IDHeader: clients.DefaultIDHeader})
+	if err != nil {
+		t.Fatal(err)
+	}
+	limits := ratelimit.DefaultConfig()
+	limits.Enabled, limits.Limit, limits.WindowMS, limits.Scope = true, 1, 60000, ratelimit.ScopeGlobal
+	broken := chaos.DefaultProfile()
+	broken.ErrorRate, broken.ErrorStatus = 1, 503
+	err = engine.SetProfiles(clients.Profiles{Profiles: []clients.Profile{
+		{Name: "noisy", APIKeys: []string{"noisy-key"}, Faults: chaos.DefaultProfile(), RateLimit: &limits},
+		{Name: "broken", ClientIDs: []string{"broken"}, Faults: broken},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(echov1connect.NewEchoHandler(NewEchoServer(),
+		connect.WithInterceptors(NewClientProfileInterceptor(engine))))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client := echov1connect.NewEchoClient(server.Client(), server.URL)
+
+	echo := func(header, value string) (*connect.Response[pb.EchoResponse], error) {
+		req := connect.NewRequest(&pb.EchoRequest{Message: "hello"})
+		req.Header().Set(header, value)
+		return client.Echo(context.Background(), req)
+	}
+
+	resp, err := echo(clients.DefaultKeyHeader, "noisy-key")
+	if err != nil || resp.Header().Get(clients.Header) != "noisy" || resp.Header().Get(ratelimit.HeaderLimit) != "1" {
+		t.Fatalf("first noisy Echo = %v, want success with the noisy quota", err)
+	}
+	_, err = echo(clients.DefaultKeyHeader, "noisy-key")
+	var connectErr *connect.Error
+	if !errors.As(err, &connectErr) || connectErr.Code() != connect.CodeResourceExhausted ||
+		connectErr.Meta().Get(clients.Header) != "noisy" {
+		t.Errorf("second noisy Echo = %v, want resource_exhausted", err)
+	}
+	if _, err := echo(clients.DefaultIDHeader, "broken"); connect.CodeOf(err) != connect.CodeUnavailable {
+		t.Errorf("broken Echo = %v, want unavailable", err)
+	}
+	resp, err = echo(clients.DefaultIDHeader, "other")
+	if err != nil {
+		t.Errorf("other Echo = %v, want success", err)
+	} else if got := resp.Header().Get(clients.Header); got != "" {
+		t.Errorf("other Echo profile = %q, want none", got)
+	}
+}
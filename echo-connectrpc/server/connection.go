@@ -0,0 +1,87 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// Connection modes reported by the connection info endpoint.
+const (
+	ConnectionModeHTTP1             = "http/1.1"
+	ConnectionModeH2CUpgrade        = "h2c-upgrade"
+	ConnectionModeH2CPriorKnowledge = "h2c-prior-knowledge"
+	ConnectionModeH2                = "h2"
+)
+
+type connectionModeKey struct{}
+
+// ConnectionModeMiddleware records how the client established the connection.
+// It must wrap the h2c handler: h2c serves upgraded and prior-knowledge
+// connections with the context of the initiating request, so the mode
+// detected here is visible to every request on that connection.
+func ConnectionModeMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), connectionModeKey{}, detectConnectionMode(r))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func detectConnectionMode(r *http.Request) string {
+	switch {
+	// HTTP/2 connection preface (RFC 7540 Section 3.4)
+	case r.Method == "PRI" && r.URL.Path == "*" && r.Proto == "HTTP/2.0":
+		return ConnectionModeH2CPriorKnowledge
+	// Upgrade to h2c (RFC 7540 Section 3.2)
+	case hasToken(r.Header.Values("Upgrade"), "h2c") && hasToken(r.Header.Values("Connection"), "HTTP2-Settings"):
+		return ConnectionModeH2CUpgrade
+	case r.ProtoMajor == 2:
+		return ConnectionModeH2
+	default:
+		return ConnectionModeHTTP1
+	}
+}
+
+func hasToken(values []string, token string) bool {
+	for _, v := range values {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ConnectionMode returns the connection mode recorded by
+// ConnectionModeMiddleware, falling back to the request protocol version.
+func ConnectionMode(r *http.Request) string {
+	if mode, ok := r.Context().Value(connectionModeKey{}).(string); ok {
+		return mode
+	}
+	if r.ProtoMajor == 2 {
+		return ConnectionModeH2
+	}
+	return ConnectionModeHTTP1
+}
+
+// ConnectionInfoResponse describes the connection used by the request.
+type ConnectionInfoResponse struct {
+	Mode       string `json:"mode"`
+	Proto      string `json:"proto"`
+	TLS        bool   `json:"tls"`
+	RemoteAddr string `json:"remote_addr"`
+}
+
+// ConnectionInfoHandler reports whether the connection used HTTP/1.1, an h2c
+// upgrade, or HTTP/2 with prior knowledge.
+func ConnectionInfoHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(ConnectionInfoResponse{
+		Mode:       ConnectionMode(r),
+		Proto:      r.Proto,
+		TLS:        r.TLS != nil,
+		RemoteAddr: r.RemoteAddr,
+	})
+}
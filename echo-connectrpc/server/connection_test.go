@@ -0,0 +1,181 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+func setupConnectionInfoServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/connection", ConnectionInfoHandler)
+
+	server := httptest.NewServer(ConnectionModeMiddleware(h2c.NewHandler(mux, &http2.Server{})))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func getConnectionInfo(t *testing.T, client *http.Client, url string) ConnectionInfoResponse {
+	t.Helper()
+
+	resp, err := client.Get(url + "/connection")
+	if err != nil {
+		t.Fatalf("GET /connection failed: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var info ConnectionInfoResponse
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	return info
+}
+
+func TestConnectionInfo_HTTP1(t *testing.T) {
+	server := setupConnectionInfoServer(t)
+
+	info := getConnectionInfo(t, server.Client(), server.URL)
+
+	if info.Mode != ConnectionModeHTTP1 {
+		t.Errorf("expected mode %q, got %q", ConnectionModeHTTP1, info.Mode)
+	}
+	if info.Proto != "HTTP/1.1" {
+		t.Errorf("expected proto HTTP/1.1, got %q", info.Proto)
+	}
+}
+
+func TestConnectionInfo_H2CPriorKnowledge(t *testing.T) {
+	server := setupConnectionInfoServer(t)
+
+	protocols := new(http.Protocols)
+	protocols.SetUnencryptedHTTP2(true)
+	client := &http.Client{Transport: &http.Transport{Protocols: protocols}}
+
+	info := getConnectionInfo(t, client, server.URL)
+
+	if info.Mode != ConnectionModeH2CPriorKnowledge {
+		t.Errorf("expected mode %q, got %q", ConnectionModeH2CPriorKnowledge, info.Mode)
+	}
+	if info.Proto != "HTTP/2.0" {
+		t.Errorf("expected proto HTTP/2.0, got %q", info.Proto)
+	}
+}
+
+func TestConnectionInfo_H2CUpgrade(t *testing.T) {
+	server := setupConnectionInfoServer(t)
+
+	conn, err := net.Dial("tcp", server.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	// Send an HTTP/1.1 request asking to upgrade to h2c
+	_, err = fmt.Fprintf(conn, "GET /connection HTTP/1.1\r\n"+
+		"Host: %s\r\n"+
+		"Connection: Upgrade, HTTP2-Settings\r\n"+
+		"Upgrade: h2c\r\n"+
+		"HTTP2-Settings: \r\n\r\n", server.Listener.Addr())
+	if err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatalf("read upgrade response failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("expected 101, got %d", resp.StatusCode)
+	}
+
+	// Complete the HTTP/2 handshake; the response to the upgraded request
+	// arrives on stream 1.
+	if _, err := io.WriteString(conn, http2.ClientPreface); err != nil {
+		t.Fatalf("write preface failed: %v", err)
+	}
+	framer := http2.NewFramer(conn, br)
+	if err := framer.WriteSettings(); err != nil {
+		t.Fatalf("write settings failed: %v", err)
+	}
+
+	var body bytes.Buffer
+	for {
+		frame, err := framer.ReadFrame()
+		if err != nil {
+			t.Fatalf("read frame failed: %v", err)
+		}
+		data, ok := frame.(*http2.DataFrame)
+		if !ok || data.StreamID != 1 {
+			continue
+		}
+		body.Write(data.Data())
+		if data.StreamEnded() {
+			break
+		}
+	}
+
+	var info ConnectionInfoResponse
+	if err := json.NewDecoder(&body).Decode(&info); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if info.Mode != ConnectionModeH2CUpgrade {
+		t.Errorf("expected mode %q, got %q", ConnectionModeH2CUpgrade, info.Mode)
+	}
+}
+
+func TestConnectionMode_Detection(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    string
+	}{
+		{name: "plain request", want: ConnectionModeHTTP1},
+		{
+			name:    "h2c upgrade",
+			headers: map[string]string{"Upgrade": "h2c", "Connection": "Upgrade, HTTP2-Settings"},
+			want:    ConnectionModeH2CUpgrade,
+		},
+		{
+			name:    "websocket upgrade",
+			headers: map[string]string{"Upgrade": "websocket", "Connection": "Upgrade"},
+			want:    ConnectionModeHTTP1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/connection", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+
+			var got string
+			handler := ConnectionModeMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = ConnectionMode(r)
+			}))
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if got != tt.want {
+				t.Errorf("expected mode %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	// Without the middleware, the mode falls back to the protocol version
+	req := httptest.NewRequest(http.MethodGet, "/connection", nil)
+	if got := ConnectionMode(req); got != ConnectionModeHTTP1 {
+		t.Errorf("expected fallback mode %q, got %q", ConnectionModeHTTP1, got)
+	}
+}
@@ -3,17 +3,23 @@ package server
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
-	"strings"
+	"net"
+	"net/http"
+	"strconv"
 	"time"
 
 	"connectrpc.com/connect"
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/durationpb"
 
-	pb "github.com/probitas-test/echo-servers/echo-connectrpc/proto"
-	"github.com/probitas-test/echo-servers/echo-connectrpc/proto/protoconnect"
+	pb "github.com/probitas-test/echo-servers/proto/echo/v1"
+	"github.com/probitas-test/echo-servers/proto/echo/v1/echov1connect"
+	"github.com/probitas-test/echo-servers/shared/network"
+	"github.com/probitas-test/echo-servers/shared/version"
 )
 
 const (
@@ -22,13 +28,21 @@ const (
 )
 
 type EchoServer struct {
-	protoconnect.UnimplementedEchoHandler
+	echov1connect.UnimplementedEchoHandler
+
+	// build is reported by Version
+	build version.Info
 }
 
 func NewEchoServer() *EchoServer {
 	return &EchoServer{}
 }
 
+// SetBuild sets the build information reported by Version
+func (s *EchoServer) SetBuild(info version.Info) {
+	s.build = info
+}
+
 func (s *EchoServer) Echo(ctx context.Context, req *connect.Request[pb.EchoRequest]) (*connect.Response[pb.EchoResponse], error) {
 	resp := &pb.EchoResponse{
 		Message:  req.Msg.Message,
@@ -206,52 +220,101 @@ func (s *EchoServer) EchoErrorWithDetails(_ context.Context, req *connect.Reques
 
 	err := connect.NewError(code, fmt.Errorf("%s", message))
 
-	// Add rich error details
+	// Add rich error details; NewErrorDetail attaches an Any as is
 	for _, detail := range req.Msg.Details {
-		switch detail.Type {
-		case "bad_request":
-			br := &errdetails.BadRequest{}
-			for _, fv := range detail.FieldViolations {
-				br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
-					Field:       fv.Field,
-					Description: fv.Description,
-				})
-			}
-			if d, detailErr := connect.NewErrorDetail(br); detailErr == nil {
-				err.AddDetail(d)
-			}
-		case "retry_info":
-			ri := &errdetails.RetryInfo{
-				RetryDelay: durationpb.New(time.Duration(detail.RetryDelayMs) * time.Millisecond),
-			}
-			if d, detailErr := connect.NewErrorDetail(ri); detailErr == nil {
-				err.AddDetail(d)
-			}
-		case "debug_info":
-			di := &errdetails.DebugInfo{
-				StackEntries: detail.StackEntries,
-				Detail:       detail.DebugDetail,
-			}
-			if d, detailErr := connect.NewErrorDetail(di); detailErr == nil {
-				err.AddDetail(d)
-			}
-		case "quota_failure":
-			qf := &errdetails.QuotaFailure{}
-			for _, qv := range detail.QuotaViolations {
-				qf.Violations = append(qf.Violations, &errdetails.QuotaFailure_Violation{
-					Subject:     qv.Subject,
-					Description: qv.Description,
-				})
-			}
-			if d, detailErr := connect.NewErrorDetail(qf); detailErr == nil {
-				err.AddDetail(d)
-			}
+		msg := errorDetail(detail)
+		if msg == nil {
+			continue
+		}
+		if d, detailErr := connect.NewErrorDetail(msg); detailErr == nil {
+			err.AddDetail(d)
 		}
 	}
 
 	return nil, err
 }
 
+// errorDetail builds the google.rpc message of detail, nil for an unknown
+// type or an empty any
+func errorDetail(detail *pb.ErrorDetail) proto.Message {
+	switch detail.Type {
+	case "bad_request":
+		br := &errdetails.BadRequest{}
+		for _, fv := range detail.FieldViolations {
+			br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       fv.Field,
+				Description: fv.Description,
+			})
+		}
+		return br
+	case "retry_info":
+		return &errdetails.RetryInfo{
+			RetryDelay: durationpb.New(time.Duration(detail.RetryDelayMs) * time.Millisecond),
+		}
+	case "debug_info":
+		return &errdetails.DebugInfo{
+			StackEntries: detail.StackEntries,
+			Detail:       detail.DebugDetail,
+		}
+	case "quota_failure":
+		qf := &errdetails.QuotaFailure{}
+		for _, qv := range detail.QuotaViolations {
+			qf.Violations = append(qf.Violations, &errdetails.QuotaFailure_Violation{
+				Subject:     qv.Subject,
+				Description: qv.Description,
+			})
+		}
+		return qf
+	case "error_info":
+		return &errdetails.ErrorInfo{
+			Reason:   detail.Reason,
+			Domain:   detail.Domain,
+			Metadata: detail.Metadata,
+		}
+	case "localized_message":
+		return &errdetails.LocalizedMessage{
+			Locale:  detail.Locale,
+			Message: detail.LocalizedMessage,
+		}
+	case "help":
+		h := &errdetails.Help{}
+		for _, l := range detail.Links {
+			h.Links = append(h.Links, &errdetails.Help_Link{
+				Description: l.Description,
+				Url:         l.Url,
+			})
+		}
+		return h
+	case "precondition_failure":
+		pf := &errdetails.PreconditionFailure{}
+		for _, pv := range detail.PreconditionViolations {
+			pf.Violations = append(pf.Violations, &errdetails.PreconditionFailure_Violation{
+				Type:        pv.Type,
+				Subject:     pv.Subject,
+				Description: pv.Description,
+			})
+		}
+		return pf
+	case "resource_info":
+		return &errdetails.ResourceInfo{
+			ResourceType: detail.ResourceType,
+			ResourceName: detail.ResourceName,
+			Owner:        detail.Owner,
+			Description:  detail.Description,
+		}
+	case "request_info":
+		return &errdetails.RequestInfo{
+			RequestId:   detail.RequestId,
+			ServingData: detail.ServingData,
+		}
+	case "any":
+		if detail.Any != nil {
+			return detail.Any
+		}
+	}
+	return nil
+}
+
 func (s *EchoServer) ServerStream(ctx context.Context, req *connect.Request[pb.ServerStreamRequest], stream *connect.ServerStream[pb.EchoResponse]) error {
 	md := make(map[string]string)
 
@@ -307,23 +370,29 @@ func (s *EchoServer) ClientStream(ctx context.Context, stream *connect.ClientStr
 		}
 	}
 
-	var messages []string
+	// Aggregation selected by the X-Aggregate header, concatenation by
+	// default
+	agg, err := newAggregator(stream.RequestHeader())
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
 
 	for stream.Receive() {
-		req := stream.Msg()
-		messages = append(messages, req.Message)
+		agg.add(stream.Msg().Message)
 	}
 
 	if err := stream.Err(); err != nil {
 		return nil, err
 	}
 
-	resp := &pb.EchoResponse{
-		Message:  strings.Join(messages, ", "),
+	resp := connect.NewResponse(&pb.EchoResponse{
+		Message:  agg.result(),
 		Metadata: md,
-	}
+	})
+	resp.Header().Set(AggregateHeader, agg.mode)
+	resp.Header().Set(MessageCountHeader, strconv.Itoa(agg.count))
 
-	return connect.NewResponse(resp), nil
+	return resp, nil
 }
 
 func (s *EchoServer) BidirectionalStream(ctx context.Context, stream *connect.BidiStream[pb.EchoRequest, pb.EchoResponse]) error {
@@ -344,7 +413,7 @@ func (s *EchoServer) BidirectionalStream(ctx context.Context, stream *connect.Bi
 		}
 
 		req, err := stream.Receive()
-		if err == io.EOF {
+		if errors.Is(err, io.EOF) {
 			return nil
 		}
 		if err != nil {
@@ -361,3 +430,32 @@ func (s *EchoServer) BidirectionalStream(ctx context.Context, stream *connect.Bi
 		}
 	}
 }
+
+func (s *EchoServer) Version(_ context.Context, _ *connect.Request[pb.VersionRequest]) (*connect.Response[pb.VersionResponse], error) {
+	resp := &pb.VersionResponse{
+		Server:    s.build.Server,
+		Version:   s.build.Version,
+		Commit:    s.build.Commit,
+		Date:      s.build.Date,
+		GoVersion: s.build.GoVersion,
+		Platform:  s.build.Platform,
+		Features:  s.build.Features,
+	}
+	return connect.NewResponse(resp), nil
+}
+
+func (s *EchoServer) Network(ctx context.Context, req *connect.Request[pb.NetworkRequest]) (*connect.Response[pb.NetworkResponse], error) {
+	remote, err := net.ResolveTCPAddr("tcp", req.Peer().Addr)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+	local, _ := ctx.Value(http.LocalAddrContextKey).(net.Addr)
+	info := network.Describe(local, remote)
+	resp := &pb.NetworkResponse{
+		Family:        info.Family,
+		ClientAddress: info.ClientAddr,
+		ServerAddress: info.ServerAddr,
+		Listen:        info.Listen,
+	}
+	return connect.NewResponse(resp), nil
+}
@@ -2,6 +2,8 @@ package server
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -9,21 +11,23 @@ import (
 
 	"connectrpc.com/connect"
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/types/known/anypb"
 
-	pb "github.com/probitas-test/echo-servers/echo-connectrpc/proto"
-	"github.com/probitas-test/echo-servers/echo-connectrpc/proto/protoconnect"
+	pb "github.com/probitas-test/echo-servers/proto/echo/v1"
+	"github.com/probitas-test/echo-servers/proto/echo/v1/echov1connect"
+	"github.com/probitas-test/echo-servers/shared/version"
 )
 
-func setupTestServer(t *testing.T) (protoconnect.EchoClient, *httptest.Server) {
+func setupTestServer(t *testing.T) (echov1connect.EchoClient, *httptest.Server) {
 	t.Helper()
 
 	mux := http.NewServeMux()
 	echoServer := NewEchoServer()
-	path, handler := protoconnect.NewEchoHandler(echoServer)
+	path, handler := echov1connect.NewEchoHandler(echoServer)
 	mux.Handle(path, handler)
 
 	server := httptest.NewServer(mux)
-	client := protoconnect.NewEchoClient(http.DefaultClient, server.URL)
+	client := echov1connect.NewEchoClient(http.DefaultClient, server.URL)
 
 	return client, server
 }
@@ -230,6 +234,58 @@ func TestClientStream_AggregatesMessages(t *testing.T) {
 	}
 }
 
+func TestClientStream_AggregationModes(t *testing.T) {
+	client, server := setupTestServer(t)
+	defer server.Close()
+
+	sum := sha256.Sum256([]byte("onetwothree"))
+	tests := []struct {
+		mode, separator string
+		want            string
+	}{
+		{"", "", "one, two, three"},
+		{"concat", "|", "one|two|three"},
+		{"count", "", "3"},
+		{"first", "", "one"},
+		{"LAST", "", "three"},
+		{"checksum", "", hex.EncodeToString(sum[:])},
+		{"bytes", "", "11"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			stream := client.ClientStream(context.Background())
+			if tt.mode != "" {
+				stream.RequestHeader().Set(AggregateHeader, tt.mode)
+			}
+			if tt.separator != "" {
+				stream.RequestHeader().Set(AggregateSeparatorHeader, tt.separator)
+			}
+			for _, msg := range []string{"one", "two", "three"} {
+				if err := stream.Send(&pb.EchoRequest{Message: msg}); err != nil {
+					t.Fatalf("Send failed: %v", err)
+				}
+			}
+			resp, err := stream.CloseAndReceive()
+			if err != nil {
+				t.Fatalf("CloseAndReceive failed: %v", err)
+			}
+			if resp.Msg.Message != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, resp.Msg.Message)
+			}
+			if count := resp.Header().Get(MessageCountHeader); count != "3" {
+				t.Errorf("expected %s 3, got %q", MessageCountHeader, count)
+			}
+		})
+	}
+
+	stream := client.ClientStream(context.Background())
+	stream.RequestHeader().Set(AggregateHeader, "median")
+	_ = stream.Send(&pb.EchoRequest{Message: "one"})
+	if _, err := stream.CloseAndReceive(); connect.CodeOf(err) != connect.CodeInvalidArgument {
+		t.Errorf("expected InvalidArgument for an unknown aggregation, got %v", err)
+	}
+}
+
 func TestBidirectionalStream_EchoesEachMessage(t *testing.T) {
 	t.Skip("Bidirectional streaming requires HTTP/2, httptest.Server only supports HTTP/1.1")
 	// Note: This functionality is tested via integration tests with actual server
@@ -623,3 +679,96 @@ func TestEchoErrorWithDetails_QuotaFailure(t *testing.T) {
 		t.Errorf("expected subject %q, got %q", "user:123", quotaFailure.Violations[0].Subject)
 	}
 }
+
+func TestEchoErrorWithDetails_Catalogue(t *testing.T) {
+	client, server := setupTestServer(t)
+	defer server.Close()
+
+	custom, err := anypb.New(&pb.EchoResponse{Message: "custom"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.EchoErrorWithDetails(context.Background(), connect.NewRequest(&pb.EchoErrorWithDetailsRequest{
+		Code: int32(connect.CodeFailedPrecondition),
+		Details: []*pb.ErrorDetail{
+			{Type: "error_info", Reason: "STOCKOUT", Domain: "shop.example.com", Metadata: map[string]string{"sku": "A1"}},
+			{Type: "localized_message", Locale: "ja-JP", LocalizedMessage: "在庫切れです"},
+			{Type: "help", Links: []*pb.HelpLink{{Description: "Restock", Url: "https://example.com/restock"}}},
+			{Type: "precondition_failure", PreconditionViolations: []*pb.PreconditionViolation{{Type: "STOCK", Subject: "sku:A1", Description: "out of stock"}}},
+			{Type: "resource_info", ResourceType: "sku", ResourceName: "A1", Owner: "shop", Description: "not found"},
+			{Type: "request_info", RequestId: "req-1", ServingData: "replica-2"},
+			{Type: "any", Any: custom},
+			{Type: "any"},
+		},
+	}))
+
+	connectErr, ok := err.(*connect.Error)
+	if !ok || connectErr.Code() != connect.CodeFailedPrecondition {
+		t.Fatalf("expected FailedPrecondition, got %v", err)
+	}
+	var details []any
+	for _, d := range connectErr.Details() {
+		v, err := d.Value()
+		if err != nil {
+			t.Fatalf("failed to get detail value: %v", err)
+		}
+		details = append(details, v)
+	}
+
+	if len(details) != 7 {
+		t.Fatalf("expected 7 error details, got %d", len(details))
+	}
+	if d, ok := details[0].(*errdetails.ErrorInfo); !ok || d.Reason != "STOCKOUT" || d.Domain != "shop.example.com" || d.Metadata["sku"] != "A1" {
+		t.Errorf("ErrorInfo = %v", details[0])
+	}
+	if d, ok := details[1].(*errdetails.LocalizedMessage); !ok || d.Locale != "ja-JP" || d.Message != "在庫切れです" {
+		t.Errorf("LocalizedMessage = %v", details[1])
+	}
+	if d, ok := details[2].(*errdetails.Help); !ok || len(d.Links) != 1 || d.Links[0].Url != "https://example.com/restock" {
+		t.Errorf("Help = %v", details[2])
+	}
+	if d, ok := details[3].(*errdetails.PreconditionFailure); !ok || len(d.Violations) != 1 || d.Violations[0].Type != "STOCK" {
+		t.Errorf("PreconditionFailure = %v", details[3])
+	}
+	if d, ok := details[4].(*errdetails.ResourceInfo); !ok || d.ResourceName != "A1" || d.Owner != "shop" {
+		t.Errorf("ResourceInfo = %v", details[4])
+	}
+	if d, ok := details[5].(*errdetails.RequestInfo); !ok || d.RequestId != "req-1" {
+		t.Errorf("RequestInfo = %v", details[5])
+	}
+	// An Any is attached as is, not wrapped in another Any
+	if d, ok := details[6].(*pb.EchoResponse); !ok || d.Message != "custom" {
+		t.Errorf("Any = %v", details[6])
+	}
+}
+
+func TestVersion_ReturnsBuild(t *testing.T) {
+	s := NewEchoServer()
+	s.SetBuild(version.New("echo-connectrpc", version.Features{"grpc_web": true, "chaos": false}))
+
+	resp, err := s.Version(context.Background(), connect.NewRequest(&pb.VersionRequest{}))
+
+	if err != nil {
+		t.Fatalf("Version failed: %v", err)
+	}
+	if resp.Msg.Server != "echo-connectrpc" || resp.Msg.Version == "" || resp.Msg.GoVersion == "" {
+		t.Errorf("unexpected build: %v", resp.Msg)
+	}
+	if len(resp.Msg.Features) != 1 || resp.Msg.Features[0] != "grpc_web" {
+		t.Errorf("expected features [grpc_web], got %v", resp.Msg.Features)
+	}
+}
+
+func TestNetwork_ReportsFamily(t *testing.T) {
+	client, server := setupTestServer(t)
+	defer server.Close()
+
+	resp, err := client.Network(context.Background(), connect.NewRequest(&pb.NetworkRequest{}))
+
+	if err != nil {
+		t.Fatalf("Network failed: %v", err)
+	}
+	if resp.Msg.Family != "ipv4" || resp.Msg.ServerAddress != server.Listener.Addr().String() || resp.Msg.Listen != "dual" {
+		t.Errorf("unexpected network: %v", resp.Msg)
+	}
+}
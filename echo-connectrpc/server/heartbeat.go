@@ -0,0 +1,162 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"connectrpc.com/connect"
+
+	pb "github.com/probitas-test/echo-servers/proto/echo/v1"
+)
+
+// HeartbeatIntervalHeader sets the heartbeat interval of
+// BidirectionalStreamWithHeartbeat from the start of the stream
+const HeartbeatIntervalHeader = "X-Heartbeat-Interval-Ms"
+
+// defaultHeartbeatInterval is used without the header
+const defaultHeartbeatInterval = time.Second
+
+// Kinds of the BidirectionalStreamWithHeartbeat responses
+const (
+	KindEcho      = "echo"
+	KindHeartbeat = "heartbeat"
+)
+
+// BidirectionalStreamWithHeartbeat echoes each message, after its delay_ms,
+// while sending unsolicited heartbeats at the configured interval on the
+// same stream. Delayed echoes overtake each other and heartbeats, so
+// clients must match echoes by request_sequence rather than by order.
+func (s *EchoServer) BidirectionalStreamWithHeartbeat(ctx context.Context, stream *connect.BidiStream[pb.HeartbeatStreamRequest, pb.HeartbeatStreamResponse]) error {
+	interval := defaultHeartbeatInterval
+	if v := stream.RequestHeader().Get(HeartbeatIntervalHeader); v != "" {
+		ms, err := strconv.Atoi(v)
+		if err != nil || ms <= 0 {
+			return connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("%s must be a positive number of milliseconds, got %q", HeartbeatIntervalHeader, v))
+		}
+		interval = time.Duration(ms) * time.Millisecond
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	hs := &heartbeatStream{stream: stream, cancel: cancel}
+
+	intervals := make(chan time.Duration)
+	heartbeats := make(chan struct{})
+	go func() {
+		defer close(heartbeats)
+		hs.heartbeat(ctx, interval, intervals)
+	}()
+
+	var echoes sync.WaitGroup
+	err := hs.receive(ctx, &echoes, intervals)
+	if err == nil {
+		// Let the delayed echoes out before ending the stream
+		echoes.Wait()
+	}
+	cancel()
+	echoes.Wait()
+	<-heartbeats
+	if err != nil {
+		return err
+	}
+	return hs.failure()
+}
+
+// heartbeatStream serializes the sends of the echoes and heartbeats,
+// numbering them in the order they are written
+type heartbeatStream struct {
+	stream *connect.BidiStream[pb.HeartbeatStreamRequest, pb.HeartbeatStreamResponse]
+	cancel context.CancelFunc
+
+	mu       sync.Mutex
+	sequence int64
+	err      error
+}
+
+// receive starts the echo of each message until the client closes its side
+func (h *heartbeatStream) receive(ctx context.Context, echoes *sync.WaitGroup, intervals chan<- time.Duration) error {
+	var requests int64
+	for {
+		req, err := h.stream.Receive()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		requests++
+		if req.HeartbeatIntervalMs < 0 || req.DelayMs < 0 {
+			return connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("heartbeat_interval_ms and delay_ms must not be negative"))
+		}
+		if req.HeartbeatIntervalMs > 0 {
+			select {
+			case intervals <- time.Duration(req.HeartbeatIntervalMs) * time.Millisecond:
+			case <-ctx.Done():
+				return nil
+			}
+		}
+
+		echoes.Add(1)
+		go func(message string, requestSequence int64, delay time.Duration) {
+			defer echoes.Done()
+			if delay > 0 {
+				select {
+				case <-time.After(delay):
+				case <-ctx.Done():
+					return
+				}
+			}
+			h.send(&pb.HeartbeatStreamResponse{Kind: KindEcho, Message: message, RequestSequence: requestSequence})
+		}(req.Message, requests, time.Duration(req.DelayMs)*time.Millisecond)
+	}
+}
+
+// heartbeat sends heartbeats every interval, switching to the intervals
+// received, until ctx is done
+func (h *heartbeatStream) heartbeat(ctx context.Context, interval time.Duration, intervals <-chan time.Duration) {
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case interval = <-intervals:
+			timer.Reset(interval)
+		case <-timer.C:
+			if !h.send(&pb.HeartbeatStreamResponse{Kind: KindHeartbeat}) {
+				return
+			}
+			timer.Reset(interval)
+		}
+	}
+}
+
+// send numbers and writes resp, reporting false once a send failed
+func (h *heartbeatStream) send(resp *pb.HeartbeatStreamResponse) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.err != nil {
+		return false
+	}
+	h.sequence++
+	resp.Sequence = h.sequence
+	resp.TimestampMs = time.Now().UnixMilli()
+	if err := h.stream.Send(resp); err != nil {
+		h.err = err
+		h.cancel()
+		return false
+	}
+	return true
+}
+
+// failure returns the first send error
+func (h *heartbeatStream) failure() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.err
+}
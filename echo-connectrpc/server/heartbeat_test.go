@@ -0,0 +1,106 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"connectrpc.com/connect"
+
+	pb "github.com/probitas-test/echo-servers/proto/echo/v1"
+	"github.com/probitas-test/echo-servers/proto/echo/v1/echov1connect"
+)
+
+func setupHeartbeatServer(t *testing.T) echov1connect.EchoClient {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	path, handler := echov1connect.NewEchoHandler(NewEchoServer())
+	mux.Handle(path, handler)
+
+	// Bidirectional streaming requires HTTP/2
+	server := httptest.NewUnstartedServer(mux)
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	return echov1connect.NewEchoClient(server.Client(), server.URL)
+}
+
+func TestBidirectionalStreamWithHeartbeat_InterleavesHeartbeats(t *testing.T) {
+	client := setupHeartbeatServer(t)
+
+	stream := client.BidirectionalStreamWithHeartbeat(context.Background())
+	stream.RequestHeader().Set(HeartbeatIntervalHeader, "20")
+	for _, req := range []*pb.HeartbeatStreamRequest{
+		{Message: "slow", DelayMs: 200},
+		{Message: "fast"},
+	} {
+		if err := stream.Send(req); err != nil {
+			t.Fatalf("Send failed: %v", err)
+		}
+	}
+	if err := stream.CloseRequest(); err != nil {
+		t.Fatalf("CloseRequest failed: %v", err)
+	}
+
+	var echoes []*pb.HeartbeatStreamResponse
+	heartbeats := 0
+	for i := int64(1); ; i++ {
+		resp, err := stream.Receive()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Receive failed: %v", err)
+		}
+		if resp.Sequence != i {
+			t.Errorf("response %d has sequence %d", i, resp.Sequence)
+		}
+		switch resp.Kind {
+		case KindEcho:
+			echoes = append(echoes, resp)
+		case KindHeartbeat:
+			if len(echoes) == 1 {
+				heartbeats++
+			}
+		default:
+			t.Errorf("unexpected kind %q", resp.Kind)
+		}
+	}
+	if err := stream.CloseResponse(); err != nil {
+		t.Fatalf("CloseResponse failed: %v", err)
+	}
+
+	if len(echoes) != 2 {
+		t.Fatalf("expected 2 echoes, got %d", len(echoes))
+	}
+	if echoes[0].Message != "fast" || echoes[0].RequestSequence != 2 {
+		t.Errorf("expected the fast echo of request 2 first, got %q of request %d", echoes[0].Message, echoes[0].RequestSequence)
+	}
+	if echoes[1].Message != "slow" || echoes[1].RequestSequence != 1 {
+		t.Errorf("expected the slow echo of request 1 last, got %q of request %d", echoes[1].Message, echoes[1].RequestSequence)
+	}
+	if heartbeats == 0 {
+		t.Error("expected heartbeats between the echoes")
+	}
+}
+
+func TestBidirectionalStreamWithHeartbeat_RejectsInvalidInterval(t *testing.T) {
+	client := setupHeartbeatServer(t)
+
+	stream := client.BidirectionalStreamWithHeartbeat(context.Background())
+	stream.RequestHeader().Set(HeartbeatIntervalHeader, "0")
+	if err := stream.CloseRequest(); err != nil {
+		t.Fatalf("CloseRequest failed: %v", err)
+	}
+
+	_, err := stream.Receive()
+	if connect.CodeOf(err) != connect.CodeInvalidArgument {
+		t.Errorf("expected CodeInvalidArgument, got %v", err)
+	}
+	_ = stream.CloseResponse()
+}
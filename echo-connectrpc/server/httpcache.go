@@ -0,0 +1,161 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"connectrpc.com/connect"
+	"google.golang.org/protobuf/proto"
+)
+
+// conditionalHeaders are the request headers of RFC 9110 preconditions. The
+// middleware evaluates them itself, so the handler does not see them:
+// Echo and EchoRequestMetadata would otherwise echo If-None-Match and
+// change the body a revalidation is compared with.
+var conditionalHeaders = []string{"If-Match", "If-None-Match", "If-Modified-Since", "If-Unmodified-Since", "If-Range"}
+
+// GetCacheConfig controls Connect GET requests to the NO_SIDE_EFFECTS RPCs.
+type GetCacheConfig struct {
+	// Enabled accepts GET requests; otherwise they answer unimplemented
+	Enabled bool
+	// MaxAge is the freshness lifetime announced in Cache-Control; zero
+	// makes caches revalidate every response with If-None-Match
+	MaxAge time.Duration
+}
+
+// String describes the configuration for the startup log.
+func (c GetCacheConfig) String() string {
+	if !c.Enabled {
+		return "disabled"
+	}
+	return fmt.Sprintf("enabled (Cache-Control: %s)", c.cacheControl())
+}
+
+// cacheControl returns the Cache-Control of successful GET responses. They
+// are private: Echo and EchoRequestMetadata echo the request headers, which
+// are not part of the URL a shared cache would key them on.
+func (c GetCacheConfig) cacheControl() string {
+	if c.MaxAge <= 0 {
+		return "private, no-cache"
+	}
+	return fmt.Sprintf("private, max-age=%d", int(c.MaxAge.Seconds()))
+}
+
+// GetCacheHandlerOptions are the handler options of the RPCs served behind
+// GetCacheMiddleware: binary responses are marshaled deterministically, so
+// equal responses have equal ETags (protojson already sorts map keys).
+func GetCacheHandlerOptions() []connect.HandlerOption {
+	return []connect.HandlerOption{connect.WithCodec(deterministicProtoCodec{})}
+}
+
+// GetCacheMiddleware serves the Connect GET requests of next as an HTTP
+// cache expects. Successful responses carry an ETag hashing their body,
+// which holds everything the response depends on, the echoed headers
+// included, and a Cache-Control; a request whose If-None-Match matches the
+// ETag of its fresh response gets 304 Not Modified without a body. Errors
+// and other methods pass through unchanged. With GET disabled, GET
+// requests answer unimplemented before reaching next.
+func GetCacheMiddleware(cfg GetCacheConfig, next http.Handler) http.Handler {
+	errorWriter := connect.NewErrorWriter()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !cfg.Enabled {
+			err := connect.NewError(connect.CodeUnimplemented, errors.New("connect GET is disabled"))
+			_ = errorWriter.Write(w, r, err)
+			return
+		}
+
+		// The handler still runs so authentication, faults and errors apply
+		// to revalidations as well, without the preconditions
+		inner := r.Clone(r.Context())
+		for _, name := range conditionalHeaders {
+			inner.Header.Del(name)
+		}
+		rec := &bufferedResponse{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, inner)
+		if rec.status != http.StatusOK {
+			w.WriteHeader(rec.status)
+			_, _ = w.Write(rec.body.Bytes())
+			return
+		}
+
+		sum := sha256.Sum256(rec.body.Bytes())
+		etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Cache-Control", cfg.cacheControl())
+		if etagMatches(r.Header.Values("If-None-Match"), etag) {
+			w.Header().Del("Content-Length")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(rec.body.Bytes())
+	})
+}
+
+// bufferedResponse holds the status and body of a unary response until it
+// is known to succeed; headers go straight to the underlying writer, which
+// has not sent them yet.
+type bufferedResponse struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (b *bufferedResponse) WriteHeader(status int) {
+	if !b.wroteHeader {
+		b.status = status
+		b.wroteHeader = true
+	}
+}
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	b.wroteHeader = true
+	return b.body.Write(p)
+}
+
+// etagMatches reports whether an If-None-Match header lists etag or "*",
+// comparing weakly as RFC 9110 requires for If-None-Match.
+func etagMatches(values []string, etag string) bool {
+	for _, value := range values {
+		for _, candidate := range strings.Split(value, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == "*" || candidate == etag {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// deterministicProtoCodec is the binary protobuf codec of connect, with map
+// entries marshaled in key order.
+type deterministicProtoCodec struct{}
+
+func (deterministicProtoCodec) Name() string { return "proto" }
+
+func (deterministicProtoCodec) Marshal(message any) ([]byte, error) {
+	m, ok := message.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("%T is not a proto.Message", message)
+	}
+	return proto.MarshalOptions{Deterministic: true}.Marshal(m)
+}
+
+func (deterministicProtoCodec) Unmarshal(data []byte, message any) error {
+	m, ok := message.(proto.Message)
+	if !ok {
+		return fmt.Errorf("%T is not a proto.Message", message)
+	}
+	return proto.Unmarshal(data, m)
+}
@@ -0,0 +1,139 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+
+	pb "github.com/probitas-test/echo-servers/proto/echo/v1"
+	"github.com/probitas-test/echo-servers/proto/echo/v1/echov1connect"
+)
+
+func newGetCacheServer(t *testing.T, cfg GetCacheConfig) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	path, handler := echov1connect.NewEchoHandler(NewEchoServer(), GetCacheHandlerOptions()...)
+	mux.Handle(path, GetCacheMiddleware(cfg, handler))
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// getEcho calls Echo with a Connect GET request, sending ifNoneMatch unless
+// empty and the other headers of header
+func getEcho(t *testing.T, server *httptest.Server, message, ifNoneMatch string, header http.Header) *http.Response {
+	t.Helper()
+	query := url.Values{
+		"connect":  {"v1"},
+		"encoding": {"json"},
+		"message":  {`{"message":"` + message + `"}`},
+	}
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/echo.v1.Echo/Echo?"+query.Encode(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	if ifNoneMatch != "" {
+		req.Header.Set("If-None-Match", ifNoneMatch)
+	}
+	resp, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = resp.Body.Close() })
+	return resp
+}
+
+func TestGetCacheMiddleware(t *testing.T) {
+	server := newGetCacheServer(t, GetCacheConfig{Enabled: true, MaxAge: time.Minute})
+
+	// Generated clients issue GET for the NO_SIDE_EFFECTS RPCs
+	client := echov1connect.NewEchoClient(server.Client(), server.URL, connect.WithHTTPGet())
+	resp, err := client.Echo(context.Background(), connect.NewRequest(&pb.EchoRequest{Message: "hello"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Header().Get("ETag")) != 34 || resp.Header().Get("Cache-Control") != "private, max-age=60" {
+		t.Errorf("ETag = %q, Cache-Control = %q", resp.Header().Get("ETag"), resp.Header().Get("Cache-Control"))
+	}
+
+	// The ETag hashes the body: the same request has the same ETag, its
+	// If-None-Match aside, while another message or other echoed headers
+	// give another one
+	etag := getEcho(t, server, "hello", "", nil).Header.Get("ETag")
+	if got := getEcho(t, server, "hello", `"stale"`, nil).Header.Get("ETag"); got != etag {
+		t.Errorf("ETag of the same request = %q, want %q", got, etag)
+	}
+	if got := getEcho(t, server, "other", "", nil).Header.Get("ETag"); got == etag {
+		t.Errorf("ETag of another message = %q", got)
+	}
+	tenant := func(name string) http.Header { return http.Header{"X-Tenant": {name}} }
+	acme := getEcho(t, server, "hello", "", tenant("acme")).Header.Get("ETag")
+	if acme == etag || getEcho(t, server, "hello", "", tenant("other")).Header.Get("ETag") == acme {
+		t.Errorf("ETags with different echoed headers are equal: %q", acme)
+	}
+	if r := getEcho(t, server, "hello", acme, tenant("other")); r.StatusCode != http.StatusOK {
+		t.Errorf("If-None-Match of a response to other headers: status %d", r.StatusCode)
+	}
+
+	for _, ifNoneMatch := range []string{etag, `"stale", W/` + etag, "*"} {
+		r := getEcho(t, server, "hello", ifNoneMatch, nil)
+		if r.StatusCode != http.StatusNotModified || r.Header.Get("ETag") != etag {
+			t.Errorf("If-None-Match %s: status %d, ETag %q", ifNoneMatch, r.StatusCode, r.Header.Get("ETag"))
+		}
+	}
+	if r := getEcho(t, server, "hello", `"stale"`, nil); r.StatusCode != http.StatusOK {
+		t.Errorf("stale If-None-Match: status %d", r.StatusCode)
+	}
+
+	// Binary responses are deterministic despite their metadata maps
+	var metadataETag string
+	for range 5 {
+		req := connect.NewRequest(&pb.EchoRequestMetadataRequest{})
+		for _, name := range []string{"X-A", "X-B", "X-C", "X-D", "X-E", "X-F"} {
+			req.Header().Set(name, name)
+		}
+		resp, err := client.EchoRequestMetadata(context.Background(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := resp.Header().Get("ETag"); metadataETag != "" && got != metadataETag {
+			t.Fatalf("ETag of the same binary response = %q, want %q", got, metadataETag)
+		}
+		metadataETag = resp.Header().Get("ETag")
+	}
+
+	// Errors are not cacheable
+	_, err = client.EchoLargePayload(context.Background(), connect.NewRequest(&pb.EchoLargePayloadRequest{SizeBytes: MaxPayloadSize + 1}))
+	var connectErr *connect.Error
+	if !errors.As(err, &connectErr) || connectErr.Code() != connect.CodeInvalidArgument || connectErr.Meta().Get("ETag") != "" {
+		t.Errorf("oversized payload: %v", err)
+	}
+
+	// POST is untouched
+	resp, err = echov1connect.NewEchoClient(server.Client(), server.URL).Echo(context.Background(), connect.NewRequest(&pb.EchoRequest{Message: "hello"}))
+	if err != nil || resp.Header().Get("ETag") != "" {
+		t.Errorf("POST: ETag %q, %v", resp.Header().Get("ETag"), err)
+	}
+}
+
+func TestGetCacheMiddleware_Disabled(t *testing.T) {
+	server := newGetCacheServer(t, GetCacheConfig{})
+	client := echov1connect.NewEchoClient(server.Client(), server.URL, connect.WithHTTPGet())
+	_, err := client.Echo(context.Background(), connect.NewRequest(&pb.EchoRequest{Message: "hello"}))
+	if connect.CodeOf(err) != connect.CodeUnimplemented {
+		t.Errorf("GET while disabled: %v", err)
+	}
+
+	if got := (GetCacheConfig{Enabled: true}).cacheControl(); got != "private, no-cache" {
+		t.Errorf("Cache-Control without max age = %q", got)
+	}
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"connectrpc.com/connect"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/probitas-test/echo-servers/shared/metrics"
+)
+
+// MetricsInterceptor records every RPC in Prometheus metrics, labelled
+// with the protocol of the client (connect, grpc or grpcweb), the
+// procedure, and the Connect error code ("ok" on success).
+type MetricsInterceptor struct {
+	metrics *metrics.Metrics
+}
+
+// NewMetricsInterceptor creates an interceptor recording RPCs in m.
+func NewMetricsInterceptor(m *metrics.Metrics) *MetricsInterceptor {
+	return &MetricsInterceptor{metrics: m}
+}
+
+func (i *MetricsInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		protocol := req.Peer().Protocol
+		defer i.metrics.Start(protocol)()
+		start := time.Now()
+
+		resp, err := next(ctx, req)
+
+		var respSize int64
+		if err == nil {
+			respSize = messageSize(resp.Any())
+		}
+		i.metrics.Observe(metrics.Observation{
+			Protocol:     protocol,
+			Method:       req.Spec().Procedure,
+			Code:         codeOf(err),
+			Duration:     time.Since(start),
+			RequestSize:  messageSize(req.Any()),
+			ResponseSize: respSize,
+		})
+		return resp, err
+	}
+}
+
+func (i *MetricsInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+func (i *MetricsInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		protocol := conn.Peer().Protocol
+		defer i.metrics.Start(protocol)()
+		start := time.Now()
+
+		counted := &countingConn{StreamingHandlerConn: conn}
+		err := next(ctx, counted)
+
+		i.metrics.Observe(metrics.Observation{
+			Protocol:     protocol,
+			Method:       conn.Spec().Procedure,
+			Code:         codeOf(err),
+			Duration:     time.Since(start),
+			RequestSize:  counted.received,
+			ResponseSize: counted.sent,
+		})
+		return err
+	}
+}
+
+// countingConn sums the sizes of the messages of a stream
+type countingConn struct {
+	connect.StreamingHandlerConn
+	received int64
+	sent     int64
+}
+
+func (c *countingConn) Receive(msg any) error {
+	err := c.StreamingHandlerConn.Receive(msg)
+	if err == nil {
+		c.received += messageSize(msg)
+	}
+	return err
+}
+
+func (c *countingConn) Send(msg any) error {
+	err := c.StreamingHandlerConn.Send(msg)
+	if err == nil {
+		c.sent += messageSize(msg)
+	}
+	return err
+}
+
+// messageSize returns the encoded size of a protobuf message
+func messageSize(msg any) int64 {
+	if m, ok := msg.(proto.Message); ok {
+		return int64(proto.Size(m))
+	}
+	return 0
+}
+
+func codeOf(err error) string {
+	if err == nil {
+		return "ok"
+	}
+	return connect.CodeOf(err).String()
+}
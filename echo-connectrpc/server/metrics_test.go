@@ -0,0 +1,64 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"connectrpc.com/connect"
+
+	pb "github.com/probitas-test/echo-servers/proto/echo/v1"
+	"github.com/probitas-test/echo-servers/proto/echo/v1/echov1connect"
+	"github.com/probitas-test/echo-servers/shared/metrics"
+)
+
+func TestMetricsInterceptor(t *testing.T) {
+	m := metrics.New("echo-connectrpc")
+	mux := http.NewServeMux()
+	path, handler := echov1connect.NewEchoHandler(
+		NewEchoServer(),
+		connect.WithInterceptors(NewMetricsInterceptor(m)),
+	)
+	mux.Handle(path, handler)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	ctx := context.Background()
+	client := echov1connect.NewEchoClient(http.DefaultClient, server.URL)
+	if _, err := client.Echo(ctx, connect.NewRequest(&pb.EchoRequest{Message: "hello"})); err != nil {
+		t.Fatalf("Echo failed: %v", err)
+	}
+	if _, err := client.EchoError(ctx, connect.NewRequest(&pb.EchoErrorRequest{Code: int32(connect.CodeNotFound)})); err == nil {
+		t.Fatal("EchoError succeeded")
+	}
+
+	webClient := echov1connect.NewEchoClient(http.DefaultClient, server.URL, connect.WithGRPCWeb())
+	stream, err := webClient.ServerStream(ctx, connect.NewRequest(&pb.ServerStreamRequest{Message: "hi", Count: 3}))
+	if err != nil {
+		t.Fatalf("ServerStream failed to start: %v", err)
+	}
+	for stream.Receive() {
+	}
+	if err := stream.Err(); err != nil {
+		t.Fatalf("ServerStream failed: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest("GET", metrics.Path, nil))
+	for _, want := range []string{
+		`echo_requests_total{code="ok",method="/echo.v1.Echo/Echo",protocol="connect",server="echo-connectrpc"} 1`,
+		`echo_requests_total{code="not_found",method="/echo.v1.Echo/EchoError",protocol="connect",server="echo-connectrpc"} 1`,
+		`echo_requests_total{code="ok",method="/echo.v1.Echo/ServerStream",protocol="grpcweb",server="echo-connectrpc"} 1`,
+		`echo_request_size_bytes_sum{method="/echo.v1.Echo/Echo",protocol="connect",server="echo-connectrpc"} 7`,
+		`echo_requests_in_flight{protocol="grpcweb",server="echo-connectrpc"} 0`,
+	} {
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("metrics missing %q", want)
+		}
+	}
+	if strings.Contains(rec.Body.String(), `echo_response_size_bytes_sum{method="/echo.v1.Echo/ServerStream",protocol="grpcweb",server="echo-connectrpc"} 0`) {
+		t.Error("streamed messages not counted")
+	}
+}
@@ -0,0 +1,51 @@
+package server
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"github.com/probitas-test/echo-servers/shared/admin"
+)
+
+// Probes answers the plain HTTP health checks (/healthz, /readyz) of load
+// balancers that cannot speak gRPC health, following the health set through
+// the admin API and failing once the server drains.
+type Probes struct {
+	admin    *admin.Admin
+	draining atomic.Bool
+}
+
+// NewProbes creates probes reporting the health of a.
+func NewProbes(a *admin.Admin) *Probes {
+	return &Probes{admin: a}
+}
+
+// Drain makes the probes fail for the rest of the process lifetime.
+func (p *Probes) Drain() {
+	p.draining.Store(true)
+}
+
+// Draining reports whether Drain was called.
+func (p *Probes) Draining() bool {
+	return p.draining.Load()
+}
+
+// ServeHTTP answers {"status":"ok"}, or 503 with the reason the server is
+// not serving: "draining" once shutdown starts, "starting" before the
+// readiness delay ends and "unavailable" while set unhealthy.
+func (p *Probes) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	status := "ok"
+	switch {
+	case p.Draining():
+		status = "draining"
+	case !p.admin.Ready():
+		status = "starting"
+	case !p.admin.Healthy():
+		status = "unavailable"
+	}
+	if status != "ok" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_, _ = w.Write([]byte(`{"status":"` + status + `"}`))
+}
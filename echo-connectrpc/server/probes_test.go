@@ -0,0 +1,36 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/probitas-test/echo-servers/shared/admin"
+)
+
+func TestProbes(t *testing.T) {
+	a := admin.New("echo-connectrpc", nil)
+	p := NewProbes(a)
+
+	check := func(wantCode int, wantBody string) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		p.ServeHTTP(rec, httptest.NewRequest("GET", "/healthz", nil))
+		if rec.Code != wantCode || rec.Body.String() != wantBody {
+			t.Errorf("probe = %d %s, want %d %s", rec.Code, rec.Body, wantCode, wantBody)
+		}
+	}
+
+	check(http.StatusOK, `{"status":"ok"}`)
+
+	a.SetReady(false)
+	check(http.StatusServiceUnavailable, `{"status":"starting"}`)
+	a.SetReady(true)
+
+	a.SetHealthy(false)
+	check(http.StatusServiceUnavailable, `{"status":"unavailable"}`)
+	a.SetHealthy(true)
+
+	p.Drain()
+	check(http.StatusServiceUnavailable, `{"status":"draining"}`)
+}
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"connectrpc.com/connect"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/probitas-test/echo-servers/shared/ratelimit"
+)
+
+// healthServicePrefix is the procedure prefix of the health checks, which
+// are never rate limited
+const healthServicePrefix = "/grpc.health.v1.Health/"
+
+// RateLimitInterceptor throttles every RPC but the health checks with a
+// rate limiter, matching routes against the procedure and counting per peer
+// address or key header. The quota is reported in the RateLimit-* headers;
+// denied RPCs fail with resource_exhausted and a RetryInfo detail.
+type RateLimitInterceptor struct {
+	limiter *ratelimit.Limiter
+}
+
+// NewRateLimitInterceptor creates an interceptor throttling RPCs with l.
+func NewRateLimitInterceptor(l *ratelimit.Limiter) *RateLimitInterceptor {
+	return &RateLimitInterceptor{limiter: l}
+}
+
+func (i *RateLimitInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		procedure := req.Spec().Procedure
+		if strings.HasPrefix(procedure, healthServicePrefix) {
+			return next(ctx, req)
+		}
+		d := i.take(req.Peer(), req.Header(), procedure)
+		if err := rateLimitError(d); err != nil {
+			return nil, err
+		}
+		defer d.Done()
+		resp, err := next(ctx, req)
+		if connectErr := new(connect.Error); errors.As(err, &connectErr) {
+			addHeader(connectErr.Meta(), d.Header())
+		} else if err == nil {
+			addHeader(resp.Header(), d.Header())
+		}
+		return resp, err
+	}
+}
+
+func (i *RateLimitInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+func (i *RateLimitInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		procedure := conn.Spec().Procedure
+		if strings.HasPrefix(procedure, healthServicePrefix) {
+			return next(ctx, conn)
+		}
+		d := i.take(conn.Peer(), conn.RequestHeader(), procedure)
+		if err := rateLimitError(d); err != nil {
+			return err
+		}
+		defer d.Done()
+		addHeader(conn.ResponseHeader(), d.Header())
+		return next(ctx, conn)
+	}
+}
+
+func (i *RateLimitInterceptor) take(peer connect.Peer, header http.Header, procedure string) ratelimit.Decision {
+	return i.limiter.Take(peer.Addr, header.Get(i.limiter.Config().KeyHeader), procedure)
+}
+
+// rateLimitError returns the error of a denied RPC, carrying the quota
+// headers, or nil
+func rateLimitError(d ratelimit.Decision) error {
+	if d.Allowed {
+		return nil
+	}
+	err := connect.NewError(connect.CodeResourceExhausted, errors.New("rate limit exceeded"))
+	if detail, detailErr := connect.NewErrorDetail(&errdetails.RetryInfo{RetryDelay: durationpb.New(d.RetryAfter)}); detailErr == nil {
+		err.AddDetail(detail)
+	}
+	addHeader(err.Meta(), d.Header())
+	return err
+}
+
+func addHeader(dst, src http.Header) {
+	for name, values := range src {
+		dst[name] = values
+	}
+}
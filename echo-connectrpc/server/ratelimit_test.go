@@ -0,0 +1,65 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"connectrpc.com/connect"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+
+	pb "github.com/probitas-test/echo-servers/proto/echo/v1"
+	"github.com/probitas-test/echo-servers/proto/echo/v1/echov1connect"
+	"github.com/probitas-test/echo-servers/shared/ratelimit"
+)
+
+func TestRateLimitInterceptor(t *testing.T) {
+	cfg := ratelimit.DefaultConfig()
+	cfg.Enabled, cfg.Limit, cfg.WindowMS = true, 2, 60000
+	limiter, err := ratelimit.New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(echov1connect.NewEchoHandler(NewEchoServer(),
+		connect.WithInterceptors(NewRateLimitInterceptor(limiter))))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client := echov1connect.NewEchoClient(server.Client(), server.URL)
+	ctx := context.Background()
+
+	resp, err := client.Echo(ctx, connect.NewRequest(&pb.EchoRequest{Message: "hello"}))
+	if err != nil {
+		t.Fatalf("Echo failed: %v", err)
+	}
+	if got := resp.Header().Get(ratelimit.HeaderRemaining); got != "1" {
+		t.Errorf("%s = %q, want 1", ratelimit.HeaderRemaining, got)
+	}
+
+	stream, err := client.ServerStream(ctx, connect.NewRequest(&pb.ServerStreamRequest{Message: "hello", Count: 1}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for stream.Receive() {
+	}
+	if err := stream.Err(); err != nil || stream.ResponseHeader().Get(ratelimit.HeaderRemaining) != "0" {
+		t.Errorf("ServerStream error %v, header %v", err, stream.ResponseHeader())
+	}
+
+	_, err = client.Echo(ctx, connect.NewRequest(&pb.EchoRequest{Message: "hello"}))
+	var connectErr *connect.Error
+	if !errors.As(err, &connectErr) || connectErr.Code() != connect.CodeResourceExhausted ||
+		connectErr.Meta().Get(ratelimit.HeaderRetryAfter) == "" {
+		t.Fatalf("Echo over the limit = %v, want resource_exhausted with Retry-After", err)
+	}
+	details := connectErr.Details()
+	if len(details) != 1 {
+		t.Fatalf("details = %v, want a RetryInfo", details)
+	}
+	if info, err := details[0].Value(); err != nil || info.(*errdetails.RetryInfo).GetRetryDelay().GetSeconds() < 29 {
+		t.Errorf("RetryInfo = %v (%v), want about 30s", info, err)
+	}
+}
@@ -0,0 +1,240 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+
+	"connectrpc.com/connect"
+	_ "google.golang.org/grpc/health/grpc_health_v1" // registers grpc.health.v1 descriptors for reflection
+	reflectionv1 "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	_ "google.golang.org/grpc/reflection/grpc_reflection_v1alpha" // registers grpc.reflection.v1alpha descriptors for reflection
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+const (
+	reflectionV1Path      = "/grpc.reflection.v1.ServerReflection/"
+	reflectionV1AlphaPath = "/grpc.reflection.v1alpha.ServerReflection/"
+	reflectionMethodName  = "ServerReflectionInfo"
+)
+
+// NewReflectionHandlerV1 returns the path and handler for the v1 reflection
+// API. When includeDeps is false (default), the reflection response will omit
+// transitive dependencies, forcing clients to resolve imports themselves.
+// When true, the containing file is returned along with all of its transitive
+// imports.
+//
+// grpcreflect is not used here because it de-duplicates sent files by package
+// name, which drops imports that share a package with the requested file.
+func NewReflectionHandlerV1(services []string, includeDeps bool, options ...connect.HandlerOption) (string, http.Handler) {
+	return newReflectionHandler(reflectionV1Path, services, includeDeps, options)
+}
+
+// NewReflectionHandlerV1Alpha returns the path and handler for the v1alpha
+// reflection API, with the same dependency handling as NewReflectionHandlerV1.
+func NewReflectionHandlerV1Alpha(services []string, includeDeps bool, options ...connect.HandlerOption) (string, http.Handler) {
+	// v1 is binary-compatible with v1alpha, so only the path differs.
+	return newReflectionHandler(reflectionV1AlphaPath, services, includeDeps, options)
+}
+
+func newReflectionHandler(servicePath string, services []string, includeDeps bool, options []connect.HandlerOption) (string, http.Handler) {
+	svr := newReflectionServer(services, includeDeps)
+	return servicePath, connect.NewBidiStreamHandler(
+		servicePath+reflectionMethodName,
+		svr.ServerReflectionInfo,
+		options...,
+	)
+}
+
+type reflectionServer struct {
+	includeDeps bool
+	services    []string
+	desc        protodesc.Resolver
+	ext         extensionResolver
+}
+
+type extensionResolver interface {
+	protoregistry.ExtensionTypeResolver
+	RangeExtensionsByMessage(message protoreflect.FullName, f func(protoreflect.ExtensionType) bool)
+}
+
+func newReflectionServer(services []string, includeDeps bool) *reflectionServer {
+	return &reflectionServer{
+		includeDeps: includeDeps,
+		services:    services,
+		desc:        protoregistry.GlobalFiles,
+		ext:         protoregistry.GlobalTypes,
+	}
+}
+
+func (s *reflectionServer) ServerReflectionInfo(_ context.Context, stream *connect.BidiStream[reflectionv1.ServerReflectionRequest, reflectionv1.ServerReflectionResponse]) error {
+	sent := make(map[string]bool)
+
+	for {
+		in, err := stream.Receive()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		out := &reflectionv1.ServerReflectionResponse{
+			ValidHost:       in.Host,
+			OriginalRequest: in,
+		}
+
+		switch req := in.MessageRequest.(type) {
+		case *reflectionv1.ServerReflectionRequest_FileByFilename:
+			var b [][]byte
+			fd, err := s.desc.FindFileByPath(req.FileByFilename)
+			if err == nil {
+				b, err = s.fileDescWithDependencies(fd, sent)
+			}
+			s.writeFileDescriptorResponse(out, b, err)
+		case *reflectionv1.ServerReflectionRequest_FileContainingSymbol:
+			b, err := s.fileDescEncodingContainingSymbol(req.FileContainingSymbol, sent)
+			s.writeFileDescriptorResponse(out, b, err)
+		case *reflectionv1.ServerReflectionRequest_FileContainingExtension:
+			typeName := req.FileContainingExtension.ContainingType
+			extNum := req.FileContainingExtension.ExtensionNumber
+			b, err := s.fileDescEncodingContainingExtension(typeName, extNum, sent)
+			s.writeFileDescriptorResponse(out, b, err)
+		case *reflectionv1.ServerReflectionRequest_AllExtensionNumbersOfType:
+			extNums, err := s.allExtensionNumbersForTypeName(req.AllExtensionNumbersOfType)
+			if err != nil {
+				out.MessageResponse = newNotFoundResponse(err)
+			} else {
+				out.MessageResponse = &reflectionv1.ServerReflectionResponse_AllExtensionNumbersResponse{
+					AllExtensionNumbersResponse: &reflectionv1.ExtensionNumberResponse{
+						BaseTypeName:    req.AllExtensionNumbersOfType,
+						ExtensionNumber: extNums,
+					},
+				}
+			}
+		case *reflectionv1.ServerReflectionRequest_ListServices:
+			out.MessageResponse = &reflectionv1.ServerReflectionResponse_ListServicesResponse{
+				ListServicesResponse: &reflectionv1.ListServiceResponse{
+					Service: s.listServices(),
+				},
+			}
+		default:
+			return connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("invalid MessageRequest: %v", in.MessageRequest))
+		}
+
+		if err := stream.Send(out); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *reflectionServer) writeFileDescriptorResponse(out *reflectionv1.ServerReflectionResponse, b [][]byte, err error) {
+	if err != nil {
+		out.MessageResponse = newNotFoundResponse(err)
+		return
+	}
+
+	out.MessageResponse = &reflectionv1.ServerReflectionResponse_FileDescriptorResponse{
+		FileDescriptorResponse: &reflectionv1.FileDescriptorResponse{
+			FileDescriptorProto: b,
+		},
+	}
+}
+
+func (s *reflectionServer) fileDescWithDependencies(fd protoreflect.FileDescriptor, sent map[string]bool) ([][]byte, error) {
+	if fd.IsPlaceholder() {
+		return nil, protoregistry.NotFound
+	}
+
+	var result [][]byte
+	queue := []protoreflect.FileDescriptor{fd}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		if current.IsPlaceholder() {
+			continue
+		}
+
+		if sent[current.Path()] {
+			continue
+		}
+
+		sent[current.Path()] = true
+
+		fdProto := protodesc.ToFileDescriptorProto(current)
+		encoded, err := proto.Marshal(fdProto)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, encoded)
+
+		if s.includeDeps {
+			for i := 0; i < current.Imports().Len(); i++ {
+				queue = append(queue, current.Imports().Get(i))
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func (s *reflectionServer) fileDescEncodingContainingSymbol(name string, sent map[string]bool) ([][]byte, error) {
+	d, err := s.desc.FindDescriptorByName(protoreflect.FullName(name))
+	if err != nil {
+		return nil, err
+	}
+	return s.fileDescWithDependencies(d.ParentFile(), sent)
+}
+
+func (s *reflectionServer) fileDescEncodingContainingExtension(typeName string, extNum int32, sent map[string]bool) ([][]byte, error) {
+	xt, err := s.ext.FindExtensionByNumber(protoreflect.FullName(typeName), protoreflect.FieldNumber(extNum))
+	if err != nil {
+		return nil, err
+	}
+	return s.fileDescWithDependencies(xt.TypeDescriptor().ParentFile(), sent)
+}
+
+func (s *reflectionServer) allExtensionNumbersForTypeName(name string) ([]int32, error) {
+	var numbers []int32
+	s.ext.RangeExtensionsByMessage(protoreflect.FullName(name), func(xt protoreflect.ExtensionType) bool {
+		numbers = append(numbers, int32(xt.TypeDescriptor().Number()))
+		return true
+	})
+	sort.Slice(numbers, func(i, j int) bool {
+		return numbers[i] < numbers[j]
+	})
+	if len(numbers) == 0 {
+		if _, err := s.desc.FindDescriptorByName(protoreflect.FullName(name)); err != nil {
+			return nil, err
+		}
+	}
+	return numbers, nil
+}
+
+func (s *reflectionServer) listServices() []*reflectionv1.ServiceResponse {
+	resp := make([]*reflectionv1.ServiceResponse, 0, len(s.services))
+	for _, name := range s.services {
+		resp = append(resp, &reflectionv1.ServiceResponse{Name: name})
+	}
+	sort.Slice(resp, func(i, j int) bool {
+		return resp[i].Name < resp[j].Name
+	})
+	return resp
+}
+
+func newNotFoundResponse(err error) *reflectionv1.ServerReflectionResponse_ErrorResponse {
+	return &reflectionv1.ServerReflectionResponse_ErrorResponse{
+		ErrorResponse: &reflectionv1.ErrorResponse{
+			ErrorCode:    int32(connect.CodeNotFound),
+			ErrorMessage: err.Error(),
+		},
+	}
+}
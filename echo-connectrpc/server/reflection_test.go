@@ -0,0 +1,95 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"connectrpc.com/grpcreflect"
+
+	"github.com/probitas-test/echo-servers/proto/echo/v1/echov1connect"
+)
+
+func setupReflectionServer(t *testing.T, includeDeps bool) *grpcreflect.Client {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	services := []string{echov1connect.EchoName, grpcreflect.ReflectV1ServiceName}
+	path, handler := NewReflectionHandlerV1(services, includeDeps)
+	mux.Handle(path, handler)
+
+	// Bidirectional streaming requires HTTP/2
+	server := httptest.NewUnstartedServer(mux)
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	return grpcreflect.NewClient(server.Client(), server.URL)
+}
+
+func TestReflection_ExcludesDependencies(t *testing.T) {
+	client := setupReflectionServer(t, false)
+	stream := client.NewStream(context.Background())
+	defer stream.Close()
+
+	files, err := stream.FileContainingSymbol(echov1connect.EchoName)
+	if err != nil {
+		t.Fatalf("FileContainingSymbol failed: %v", err)
+	}
+
+	if len(files) != 1 {
+		t.Fatalf("expected 1 file descriptor, got %d", len(files))
+	}
+	if files[0].GetName() != "echo/v1/echo.proto" {
+		t.Errorf("expected echo/v1/echo.proto, got %q", files[0].GetName())
+	}
+
+	// Imports can still be resolved individually
+	deps, err := stream.FileByFilename("echo/v1/echo_unary.proto")
+	if err != nil {
+		t.Fatalf("FileByFilename failed: %v", err)
+	}
+	if len(deps) != 1 || deps[0].GetName() != "echo/v1/echo_unary.proto" {
+		t.Errorf("expected only echo/v1/echo_unary.proto, got %d files", len(deps))
+	}
+}
+
+func TestReflection_IncludesDependencies(t *testing.T) {
+	client := setupReflectionServer(t, true)
+	stream := client.NewStream(context.Background())
+	defer stream.Close()
+
+	files, err := stream.FileContainingSymbol(echov1connect.EchoName)
+	if err != nil {
+		t.Fatalf("FileContainingSymbol failed: %v", err)
+	}
+
+	names := make(map[string]bool)
+	for _, f := range files {
+		names[f.GetName()] = true
+	}
+	for _, want := range []string{"echo/v1/echo.proto", "echo/v1/echo_unary.proto", "echo/v1/echo_errors.proto"} {
+		if !names[want] {
+			t.Errorf("expected %s in response, got %v", want, names)
+		}
+	}
+}
+
+func TestReflection_ListServices(t *testing.T) {
+	client := setupReflectionServer(t, false)
+	stream := client.NewStream(context.Background())
+	defer stream.Close()
+
+	services, err := stream.ListServices()
+	if err != nil {
+		t.Fatalf("ListServices failed: %v", err)
+	}
+
+	if len(services) != 2 {
+		t.Fatalf("expected 2 services, got %d", len(services))
+	}
+	if string(services[0]) != echov1connect.EchoName {
+		t.Errorf("expected %s, got %s", echov1connect.EchoName, services[0])
+	}
+}
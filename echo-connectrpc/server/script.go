@@ -0,0 +1,91 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"connectrpc.com/connect"
+
+	"github.com/probitas-test/echo-servers/shared/chaos"
+	"github.com/probitas-test/echo-servers/shared/script"
+)
+
+// ScriptInterceptor plays the scenarios of a script engine on every RPC but
+// the health checks, matching them against the procedure and identifying
+// clients by header or peer address. The step applied is reported in the
+// X-Script-Step header; failing steps return the Connect code of their
+// HTTP status.
+type ScriptInterceptor struct {
+	engine *script.Engine
+}
+
+// NewScriptInterceptor creates an interceptor playing the script of e.
+func NewScriptInterceptor(e *script.Engine) *ScriptInterceptor {
+	return &ScriptInterceptor{engine: e}
+}
+
+func (i *ScriptInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		procedure := req.Spec().Procedure
+		if strings.HasPrefix(procedure, healthServicePrefix) {
+			return next(ctx, req)
+		}
+		a := i.next(req.Peer(), req.Header(), procedure)
+		if err := play(ctx, a); err != nil {
+			return nil, err
+		}
+		resp, err := next(ctx, req)
+		if a.Matched() {
+			if connectErr := new(connect.Error); errors.As(err, &connectErr) {
+				connectErr.Meta().Set(script.Header, a.Label())
+			} else if err == nil {
+				resp.Header().Set(script.Header, a.Label())
+			}
+		}
+		return resp, err
+	}
+}
+
+func (i *ScriptInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+func (i *ScriptInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		procedure := conn.Spec().Procedure
+		if strings.HasPrefix(procedure, healthServicePrefix) {
+			return next(ctx, conn)
+		}
+		a := i.next(conn.Peer(), conn.RequestHeader(), procedure)
+		if err := play(ctx, a); err != nil {
+			return err
+		}
+		if a.Matched() {
+			conn.ResponseHeader().Set(script.Header, a.Label())
+		}
+		return next(ctx, conn)
+	}
+}
+
+func (i *ScriptInterceptor) next(peer connect.Peer, header http.Header, procedure string) script.Action {
+	return i.engine.Next(script.Client(peer.Addr, header.Get(i.engine.ClientHeader())), procedure)
+}
+
+// play waits for the delay of a, then returns the error of a failing step
+func play(ctx context.Context, a script.Action) error {
+	if err := a.Wait(ctx); err != nil {
+		return contextError(err)
+	}
+	if a.Status == 0 {
+		return nil
+	}
+	msg := a.Body
+	if msg == "" {
+		msg = http.StatusText(a.Status)
+	}
+	err := connect.NewError(connect.Code(chaos.Code(a.Status)), errors.New(msg))
+	err.Meta().Set(script.Header, a.Label())
+	return err
+}
@@ -0,0 +1,59 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"connectrpc.com/connect"
+
+	pb "github.com/probitas-test/echo-servers/proto/echo/v1"
+	"github.com/probitas-test/echo-servers/proto/echo/v1/echov1connect"
+	"github.com/probitas-test/echo-servers/shared/script"
+)
+
+func TestScriptInterceptor(t *testing.T) {
+	engine, err := script.New(script.Config{ClientHeader: script.DefaultClientHeader})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = engine.SetScript(script.Script{Scenarios: []script.Scenario{{
+		Name:      "flaky",
+		Match:     []string{"/echo.v1.Echo/Echo"},
+		PerClient: true,
+		Steps:     []script.Step{{Times: 2, Status: 503, Body: "try again"}, {}},
+	}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(echov1connect.NewEchoHandler(NewEchoServer(),
+		connect.WithInterceptors(NewScriptInterceptor(engine))))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client := echov1connect.NewEchoClient(server.Client(), server.URL)
+
+	echo := func(clientID string) (*connect.Response[pb.EchoResponse], error) {
+		req := connect.NewRequest(&pb.EchoRequest{Message: "hello"})
+		req.Header().Set(script.DefaultClientHeader, clientID)
+		return client.Echo(context.Background(), req)
+	}
+	for range 2 {
+		_, err := echo("a")
+		var connectErr *connect.Error
+		if !errors.As(err, &connectErr) || connectErr.Code() != connect.CodeUnavailable ||
+			connectErr.Message() != "try again" || connectErr.Meta().Get(script.Header) != "flaky#1" {
+			t.Fatalf("Echo = %v, want unavailable from step 1", err)
+		}
+	}
+	resp, err := echo("a")
+	if err != nil || resp.Header().Get(script.Header) != "flaky#2" {
+		t.Fatalf("third Echo = %v, want success from step 2", err)
+	}
+	if _, err := echo("b"); connect.CodeOf(err) != connect.CodeUnavailable {
+		t.Errorf("first Echo of another client = %v, want unavailable", err)
+	}
+}
@@ -0,0 +1,89 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"connectrpc.com/connect"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/probitas-test/echo-servers/proto/echo/v1"
+)
+
+// EchoStructured echoes a message built from well-known types, oneofs,
+// maps and nested messages unchanged, with what the server made of it, so
+// clients check the round trip of their generated code
+func (s *EchoServer) EchoStructured(_ context.Context, req *connect.Request[pb.StructuredRequest]) (*connect.Response[pb.StructuredResponse], error) {
+	if err := checkStructured(req.Msg); err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+
+	resp := structuredResponse(req.Msg, time.Now())
+	for key, values := range req.Header() {
+		if len(values) > 0 {
+			resp.Metadata[key] = values[0]
+		}
+	}
+	return connect.NewResponse(resp), nil
+}
+
+// checkStructured rejects timestamps and durations out of their range
+func checkStructured(req *pb.StructuredRequest) error {
+	if req.CreatedAt != nil {
+		if err := req.CreatedAt.CheckValid(); err != nil {
+			return err
+		}
+	}
+	if req.Ttl != nil {
+		if err := req.Ttl.CheckValid(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// structuredResponse describes req as received at now, without metadata
+func structuredResponse(req *pb.StructuredRequest, now time.Time) *pb.StructuredResponse {
+	resp := &pb.StructuredResponse{
+		Request:    req,
+		ReceivedAt: timestamppb.New(now),
+		Metadata:   make(map[string]string),
+	}
+	if req.CreatedAt != nil {
+		createdAt := req.CreatedAt.AsTime()
+		resp.Age = durationpb.New(now.Sub(createdAt))
+		if req.Ttl != nil {
+			resp.ExpiresAt = timestamppb.New(createdAt.Add(req.Ttl.AsDuration()))
+		}
+	}
+
+	count := countItems(req.Items)
+	for _, item := range req.ItemsByKey {
+		count += countItems([]*pb.StructuredRequest_Item{item})
+	}
+	switch payload := req.Payload.(type) {
+	case *pb.StructuredRequest_Text:
+		resp.Payload = "text"
+	case *pb.StructuredRequest_Binary:
+		resp.Payload = "binary"
+	case *pb.StructuredRequest_Item_:
+		resp.Payload = "item"
+		count += countItems([]*pb.StructuredRequest_Item{payload.Item})
+	case *pb.StructuredRequest_Json:
+		resp.Payload = "json"
+	}
+	resp.ItemCount = int32(count)
+	return resp
+}
+
+// countItems counts items and their children, recursively
+func countItems(items []*pb.StructuredRequest_Item) int {
+	n := 0
+	for _, item := range items {
+		if item != nil {
+			n += 1 + countItems(item.Children)
+		}
+	}
+	return n
+}
@@ -0,0 +1,90 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	pb "github.com/probitas-test/echo-servers/proto/echo/v1"
+	"github.com/probitas-test/echo-servers/proto/echo/v1/echov1connect"
+)
+
+func TestEchoStructured_EchoesRequest(t *testing.T) {
+	_, server := setupTestServer(t)
+	defer server.Close()
+
+	attributes, err := structpb.NewStruct(map[string]any{"nested": map[string]any{"ok": true}, "n": 1.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	createdAt := time.Now().Add(-time.Minute)
+	msg := &pb.StructuredRequest{
+		Id:         "order-1",
+		CreatedAt:  timestamppb.New(createdAt),
+		Ttl:        durationpb.New(time.Hour),
+		Attributes: attributes,
+		Note:       wrapperspb.String("gift"),
+		Priority:   pb.StructuredRequest_PRIORITY_HIGH,
+		Labels:     map[string]string{"env": "test"},
+		ItemsByKey: map[string]*pb.StructuredRequest_Item{"spare": {Name: "spare"}},
+		Items: []*pb.StructuredRequest_Item{
+			{Name: "box", Quantity: 2, Children: []*pb.StructuredRequest_Item{{Name: "inner"}, {Name: "lid"}}},
+		},
+		Payload: &pb.StructuredRequest_Json{Json: structpb.NewListValue(&structpb.ListValue{
+			Values: []*structpb.Value{structpb.NewStringValue("a"), structpb.NewNullValue()},
+		})},
+	}
+
+	// Both codecs, since the JSON mapping of well-known types is the one
+	// generated clients get wrong
+	for name, opts := range map[string][]connect.ClientOption{
+		"proto": nil,
+		"json":  {connect.WithProtoJSON()},
+	} {
+		t.Run(name, func(t *testing.T) {
+			client := echov1connect.NewEchoClient(http.DefaultClient, server.URL, opts...)
+			req := connect.NewRequest(msg)
+			req.Header().Set("X-Test", "value")
+
+			resp, err := client.EchoStructured(context.Background(), req)
+			if err != nil {
+				t.Fatalf("EchoStructured failed: %v", err)
+			}
+			if !proto.Equal(resp.Msg.Request, msg) {
+				t.Errorf("request not echoed unchanged: %v", resp.Msg.Request)
+			}
+			if resp.Msg.Payload != "json" {
+				t.Errorf("expected payload json, got %q", resp.Msg.Payload)
+			}
+			if resp.Msg.ItemCount != 4 {
+				t.Errorf("expected 4 items, got %d", resp.Msg.ItemCount)
+			}
+			if !resp.Msg.ExpiresAt.AsTime().Equal(createdAt.Add(time.Hour)) {
+				t.Errorf("expected expires_at %v, got %v", createdAt.Add(time.Hour), resp.Msg.ExpiresAt.AsTime())
+			}
+			if resp.Msg.Metadata["X-Test"] != "value" {
+				t.Errorf("expected metadata X-Test, got %v", resp.Msg.Metadata)
+			}
+		})
+	}
+}
+
+func TestEchoStructured_RejectsInvalidDuration(t *testing.T) {
+	client, server := setupTestServer(t)
+	defer server.Close()
+
+	_, err := client.EchoStructured(context.Background(), connect.NewRequest(&pb.StructuredRequest{
+		Ttl: &durationpb.Duration{Seconds: 1, Nanos: -1},
+	}))
+	if connect.CodeOf(err) != connect.CodeInvalidArgument {
+		t.Errorf("expected CodeInvalidArgument, got %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"connectrpc.com/connect"
+	"go.opentelemetry.io/otel/propagation"
+
+	"github.com/probitas-test/echo-servers/shared/tracing"
+)
+
+// TraceHeaders lists the W3C Trace Context and Baggage headers that are
+// echoed back to the caller.
+var TraceHeaders = tracing.TraceHeaders
+
+// TraceHeadersMiddleware echoes the incoming traceparent, tracestate, and
+// baggage headers in the response headers, so clients can verify that trace
+// context actually reached the server regardless of protocol (Connect, gRPC,
+// or gRPC-Web).
+func TraceHeadersMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, key := range TraceHeaders {
+			for _, v := range r.Header.Values(key) {
+				w.Header().Add(key, v)
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// TraceResponseInterceptor adds the traceresponse header naming the server
+// span of the RPC. It must run inside the OpenTelemetry interceptor, which
+// starts that span.
+type TraceResponseInterceptor struct{}
+
+func (TraceResponseInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		resp, err := next(ctx, req)
+		var connectErr *connect.Error
+		switch {
+		case err == nil:
+			traceResponse(ctx, resp.Header())
+		case errors.As(err, &connectErr):
+			traceResponse(ctx, connectErr.Meta())
+		}
+		return resp, err
+	}
+}
+
+func (TraceResponseInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+func (TraceResponseInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		// Response headers are sent with the first message
+		traceResponse(ctx, conn.ResponseHeader())
+		return next(ctx, conn)
+	}
+}
+
+func traceResponse(ctx context.Context, header http.Header) {
+	tracing.Echo(ctx, propagation.MapCarrier{}, propagation.HeaderCarrier(header))
+}
@@ -0,0 +1,126 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"connectrpc.com/connect"
+	"connectrpc.com/otelconnect"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	pb "github.com/probitas-test/echo-servers/proto/echo/v1"
+	"github.com/probitas-test/echo-servers/proto/echo/v1/echov1connect"
+	"github.com/probitas-test/echo-servers/shared/tracing"
+)
+
+func TestTraceHeadersMiddleware_EchoesTraceContext(t *testing.T) {
+	mux := http.NewServeMux()
+	path, handler := echov1connect.NewEchoHandler(NewEchoServer())
+	mux.Handle(path, handler)
+
+	server := httptest.NewServer(TraceHeadersMiddleware(mux))
+	defer server.Close()
+	client := echov1connect.NewEchoClient(http.DefaultClient, server.URL)
+
+	traceparent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	req := connect.NewRequest(&pb.EchoRequest{Message: "hello"})
+	req.Header().Set("traceparent", traceparent)
+	req.Header().Set("tracestate", "vendor=value")
+	req.Header().Set("baggage", "userId=alice")
+
+	resp, err := client.Echo(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Echo failed: %v", err)
+	}
+
+	if got := resp.Header().Get("traceparent"); got != traceparent {
+		t.Errorf("expected traceparent %q, got %q", traceparent, got)
+	}
+	if got := resp.Header().Get("tracestate"); got != "vendor=value" {
+		t.Errorf("expected tracestate %q, got %q", "vendor=value", got)
+	}
+	if got := resp.Header().Get("baggage"); got != "userId=alice" {
+		t.Errorf("expected baggage %q, got %q", "userId=alice", got)
+	}
+}
+
+func TestTraceHeadersMiddleware_OmitsAbsentHeaders(t *testing.T) {
+	handler := TraceHeadersMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", nil))
+
+	for _, key := range TraceHeaders {
+		if got := rec.Header().Get(key); got != "" {
+			t.Errorf("expected no %s header, got %q", key, got)
+		}
+	}
+}
+
+func TestTraceResponseInterceptor(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	otel.SetTracerProvider(sdktrace.NewTracerProvider())
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+
+	otelInterceptor, err := otelconnect.NewInterceptor(otelconnect.WithTrustRemote())
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	path, handler := echov1connect.NewEchoHandler(NewEchoServer(),
+		connect.WithInterceptors(otelInterceptor, TraceResponseInterceptor{}))
+	mux.Handle(path, handler)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client := echov1connect.NewEchoClient(http.DefaultClient, server.URL, connect.WithGRPCWeb())
+
+	traceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+	traceparent := "00-" + traceID + "-00f067aa0ba902b7-01"
+	checkTraceResponse := func(name string, header http.Header) {
+		t.Helper()
+		got := header.Get(tracing.TraceResponse)
+		if !strings.HasPrefix(got, "00-"+traceID+"-") || strings.Contains(got, "00f067aa0ba902b7") {
+			t.Errorf("%s: traceresponse = %q, want a server span of trace %s", name, got, traceID)
+		}
+	}
+	ctx := context.Background()
+
+	req := connect.NewRequest(&pb.EchoRequest{Message: "hello"})
+	req.Header().Set("traceparent", traceparent)
+	resp, err := client.Echo(ctx, req)
+	if err != nil {
+		t.Fatalf("Echo failed: %v", err)
+	}
+	checkTraceResponse("Echo", resp.Header())
+
+	errReq := connect.NewRequest(&pb.EchoErrorRequest{Code: int32(connect.CodeNotFound)})
+	errReq.Header().Set("traceparent", traceparent)
+	_, err = client.EchoError(ctx, errReq)
+	var connectErr *connect.Error
+	if !errors.As(err, &connectErr) {
+		t.Fatalf("EchoError error = %v, want a connect error", err)
+	}
+	checkTraceResponse("EchoError", connectErr.Meta())
+
+	streamReq := connect.NewRequest(&pb.ServerStreamRequest{Message: "hi", Count: 2})
+	streamReq.Header().Set("traceparent", traceparent)
+	stream, err := client.ServerStream(ctx, streamReq)
+	if err != nil {
+		t.Fatalf("ServerStream failed: %v", err)
+	}
+	for stream.Receive() {
+	}
+	if err := stream.Err(); err != nil {
+		t.Fatalf("ServerStream failed: %v", err)
+	}
+	checkTraceResponse("ServerStream", stream.ResponseHeader())
+}
@@ -0,0 +1,248 @@
+package server
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/gorilla/websocket"
+)
+
+// WebSocketBridgePrefix is the path prefix for the WebSocket bridge. The
+// remainder of the path is the procedure, e.g.
+// /ws/echo.v1.Echo/BidirectionalStream.
+const WebSocketBridgePrefix = "/ws/"
+
+// WebSocket subprotocols select the Connect streaming codec.
+const (
+	WebSocketProtocolProto = "connect+proto"
+	WebSocketProtocolJSON  = "connect+json"
+)
+
+const (
+	envelopeHeaderSize    = 5
+	envelopeFlagEndStream = 0x02
+	maxEnvelopeSize       = 16 << 20
+)
+
+// NewWebSocketBridge exposes Connect streaming procedures of handler over
+// WebSocket, so browser clients without HTTP/2 bidi support can exercise
+// bidirectional streams.
+//
+// Each binary WebSocket message carries exactly one Connect streaming
+// envelope (1-byte flags, 4-byte big-endian length, payload). The client
+// half-closes by sending an end-stream envelope (flags 0x02); the server
+// finishes with its own end-stream envelope carrying the error and trailers
+// as JSON, then closes the socket.
+func NewWebSocketBridge(handler http.Handler) http.Handler {
+	upgrader := websocket.Upgrader{
+		Subprotocols: []string{WebSocketProtocolProto, WebSocketProtocolJSON},
+		// Echo server: accept browser clients from any origin
+		CheckOrigin: func(*http.Request) bool { return true },
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		procedure := "/" + strings.TrimPrefix(r.URL.Path, WebSocketBridgePrefix)
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			// Upgrade has already written an HTTP error response
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		conn.SetReadLimit(maxEnvelopeSize + envelopeHeaderSize)
+
+		bridgeWebSocket(conn, r, procedure, handler)
+	})
+}
+
+func bridgeWebSocket(conn *websocket.Conn, r *http.Request, procedure string, handler http.Handler) {
+	contentType := "application/connect+proto"
+	if conn.Subprotocol() == WebSocketProtocolJSON {
+		contentType = "application/connect+json"
+	}
+
+	reqBody, reqWriter := io.Pipe()
+	respBody, respWriter := io.Pipe()
+
+	req, err := http.NewRequestWithContext(r.Context(), http.MethodPost, procedure, reqBody)
+	if err != nil {
+		writeBridgeEndStream(conn, connect.CodeInternal, err.Error())
+		return
+	}
+	// Connect rejects bidi streams over HTTP/1.x; the WebSocket provides the
+	// full-duplex transport instead.
+	req.Proto, req.ProtoMajor, req.ProtoMinor = "HTTP/2.0", 2, 0
+	req.RemoteAddr = r.RemoteAddr
+	req.Host = r.Host
+	for key, values := range r.Header {
+		if isWebSocketHeader(key) {
+			continue
+		}
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	rw := &bridgeResponseWriter{header: make(http.Header), body: respWriter}
+
+	// Forward client envelopes to the request body
+	go func() {
+		_ = reqWriter.CloseWithError(readWebSocketEnvelopes(conn, reqWriter))
+	}()
+
+	// Serve the procedure in-process
+	go func() {
+		handler.ServeHTTP(rw, req)
+		_ = reqBody.Close()
+		_ = respWriter.Close()
+	}()
+
+	// Forward server envelopes to the WebSocket
+	br := bufio.NewReader(respBody)
+	for {
+		envelope, err := readEnvelope(br)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			_ = respBody.CloseWithError(err)
+			break
+		}
+		if err := conn.WriteMessage(websocket.BinaryMessage, envelope); err != nil {
+			_ = respBody.CloseWithError(err)
+			return
+		}
+	}
+
+	// Non-streaming failures (e.g. unknown procedure) never produce an
+	// end-stream envelope, so synthesize one from the HTTP status.
+	if rw.status != 0 && rw.status != http.StatusOK {
+		writeBridgeEndStream(conn, httpStatusToCode(rw.status), fmt.Sprintf("HTTP status %d", rw.status))
+	}
+
+	_ = conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+}
+
+// readWebSocketEnvelopes copies client envelopes into w until the client
+// sends an end-stream envelope or the socket closes.
+func readWebSocketEnvelopes(conn *websocket.Conn, w io.Writer) error {
+	for {
+		messageType, data, err := conn.ReadMessage()
+		if err != nil {
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				return nil
+			}
+			return err
+		}
+		if messageType != websocket.BinaryMessage {
+			return errors.New("websocket bridge requires binary messages")
+		}
+		if len(data) < envelopeHeaderSize || int(binary.BigEndian.Uint32(data[1:envelopeHeaderSize])) != len(data)-envelopeHeaderSize {
+			return errors.New("websocket message is not a single Connect envelope")
+		}
+		if data[0]&envelopeFlagEndStream != 0 {
+			return nil
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+	}
+}
+
+func readEnvelope(r io.Reader) ([]byte, error) {
+	envelope := make([]byte, envelopeHeaderSize)
+	if _, err := io.ReadFull(r, envelope); err != nil {
+		return nil, err
+	}
+	size := binary.BigEndian.Uint32(envelope[1:])
+	if size > maxEnvelopeSize {
+		return nil, fmt.Errorf("envelope size %d exceeds limit", size)
+	}
+	envelope = append(envelope, make([]byte, size)...)
+	if _, err := io.ReadFull(r, envelope[envelopeHeaderSize:]); err != nil {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return envelope, nil
+}
+
+func writeBridgeEndStream(conn *websocket.Conn, code connect.Code, message string) {
+	payload, _ := json.Marshal(map[string]any{
+		"error": map[string]string{
+			"code":    code.String(),
+			"message": message,
+		},
+	})
+	envelope := make([]byte, envelopeHeaderSize, envelopeHeaderSize+len(payload))
+	envelope[0] = envelopeFlagEndStream
+	binary.BigEndian.PutUint32(envelope[1:], uint32(len(payload)))
+	envelope = append(envelope, payload...)
+	if err := conn.WriteMessage(websocket.BinaryMessage, envelope); err != nil {
+		slog.Error("WebSocket bridge failed to write end-stream", "error", err)
+	}
+}
+
+// httpStatusToCode maps HTTP statuses to Connect codes as described in the
+// Connect protocol specification.
+func httpStatusToCode(status int) connect.Code {
+	switch status {
+	case http.StatusBadRequest:
+		return connect.CodeInternal
+	case http.StatusUnauthorized:
+		return connect.CodeUnauthenticated
+	case http.StatusForbidden:
+		return connect.CodePermissionDenied
+	case http.StatusNotFound:
+		return connect.CodeUnimplemented
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return connect.CodeUnavailable
+	default:
+		return connect.CodeUnknown
+	}
+}
+
+func isWebSocketHeader(key string) bool {
+	switch http.CanonicalHeaderKey(key) {
+	case "Connection", "Upgrade", "Content-Type", "Content-Length",
+		"Sec-Websocket-Key", "Sec-Websocket-Version", "Sec-Websocket-Extensions", "Sec-Websocket-Protocol":
+		return true
+	}
+	return false
+}
+
+// bridgeResponseWriter streams the handler's response body into a pipe.
+type bridgeResponseWriter struct {
+	header http.Header
+	body   *io.PipeWriter
+	status int
+}
+
+func (w *bridgeResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *bridgeResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *bridgeResponseWriter) Write(p []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	if w.status != http.StatusOK {
+		// Only Connect envelopes are forwarded to the client
+		return len(p), nil
+	}
+	return w.body.Write(p)
+}
+
+// Flush is a no-op: writes to the pipe are delivered synchronously.
+func (w *bridgeResponseWriter) Flush() {}
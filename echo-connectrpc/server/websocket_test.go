@@ -0,0 +1,152 @@
+package server
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/probitas-test/echo-servers/proto/echo/v1"
+	"github.com/probitas-test/echo-servers/proto/echo/v1/echov1connect"
+)
+
+func setupWebSocketBridge(t *testing.T) string {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	path, handler := echov1connect.NewEchoHandler(NewEchoServer())
+	mux.Handle(path, handler)
+	mux.Handle(WebSocketBridgePrefix, NewWebSocketBridge(mux))
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return "ws" + strings.TrimPrefix(server.URL, "http") + WebSocketBridgePrefix
+}
+
+func dialBridge(t *testing.T, url, subprotocol string, header http.Header) *websocket.Conn {
+	t.Helper()
+
+	dialer := websocket.Dialer{Subprotocols: []string{subprotocol}}
+	conn, _, err := dialer.Dial(url, header)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func makeEnvelope(flags byte, payload []byte) []byte {
+	envelope := make([]byte, envelopeHeaderSize, envelopeHeaderSize+len(payload))
+	envelope[0] = flags
+	binary.BigEndian.PutUint32(envelope[1:], uint32(len(payload)))
+	return append(envelope, payload...)
+}
+
+func readBridgeEnvelope(t *testing.T, conn *websocket.Conn) (byte, []byte) {
+	t.Helper()
+
+	messageType, data, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	if messageType != websocket.BinaryMessage || len(data) < envelopeHeaderSize {
+		t.Fatalf("expected binary envelope, got type %d with %d bytes", messageType, len(data))
+	}
+	return data[0], data[envelopeHeaderSize:]
+}
+
+type endStream struct {
+	Error *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func TestWebSocketBridge_BidirectionalStreamJSON(t *testing.T) {
+	url := setupWebSocketBridge(t)
+	conn := dialBridge(t, url+"echo.v1.Echo/BidirectionalStream", WebSocketProtocolJSON, http.Header{"X-Test": []string{"bridge"}})
+
+	if conn.Subprotocol() != WebSocketProtocolJSON {
+		t.Fatalf("expected subprotocol %q, got %q", WebSocketProtocolJSON, conn.Subprotocol())
+	}
+
+	for _, msg := range []string{"first", "second", "third"} {
+		payload, _ := protojson.Marshal(&pb.EchoRequest{Message: msg})
+		if err := conn.WriteMessage(websocket.BinaryMessage, makeEnvelope(0, payload)); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+
+		flags, data := readBridgeEnvelope(t, conn)
+		if flags != 0 {
+			t.Fatalf("expected data envelope, got flags %#x", flags)
+		}
+		var resp pb.EchoResponse
+		if err := protojson.Unmarshal(data, &resp); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+		if resp.Message != msg {
+			t.Errorf("expected message %q, got %q", msg, resp.Message)
+		}
+		if resp.Metadata["X-Test"] != "bridge" {
+			t.Errorf("expected upgrade headers to be forwarded, got %v", resp.Metadata)
+		}
+	}
+
+	// Half-close and expect a clean end-stream
+	if err := conn.WriteMessage(websocket.BinaryMessage, makeEnvelope(envelopeFlagEndStream, nil)); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	flags, data := readBridgeEnvelope(t, conn)
+	if flags&envelopeFlagEndStream == 0 {
+		t.Fatalf("expected end-stream envelope, got flags %#x", flags)
+	}
+	var end endStream
+	if err := json.Unmarshal(data, &end); err != nil {
+		t.Fatalf("failed to unmarshal end-stream: %v", err)
+	}
+	if end.Error != nil {
+		t.Errorf("expected no error, got %+v", end.Error)
+	}
+}
+
+func TestWebSocketBridge_BidirectionalStreamProto(t *testing.T) {
+	url := setupWebSocketBridge(t)
+	conn := dialBridge(t, url+"echo.v1.Echo/BidirectionalStream", WebSocketProtocolProto, nil)
+
+	payload, _ := proto.Marshal(&pb.EchoRequest{Message: "hello"})
+	if err := conn.WriteMessage(websocket.BinaryMessage, makeEnvelope(0, payload)); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	_, data := readBridgeEnvelope(t, conn)
+	var resp pb.EchoResponse
+	if err := proto.Unmarshal(data, &resp); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if resp.Message != "hello" {
+		t.Errorf("expected message %q, got %q", "hello", resp.Message)
+	}
+}
+
+func TestWebSocketBridge_UnknownProcedure(t *testing.T) {
+	url := setupWebSocketBridge(t)
+	conn := dialBridge(t, url+"echo.v1.Echo/DoesNotExist", WebSocketProtocolJSON, nil)
+
+	flags, data := readBridgeEnvelope(t, conn)
+	if flags&envelopeFlagEndStream == 0 {
+		t.Fatalf("expected end-stream envelope, got flags %#x", flags)
+	}
+	var end endStream
+	if err := json.Unmarshal(data, &end); err != nil {
+		t.Fatalf("failed to unmarshal end-stream: %v", err)
+	}
+	if end.Error == nil || end.Error.Code != "unimplemented" {
+		t.Errorf("expected unimplemented error, got %+v", end.Error)
+	}
+}
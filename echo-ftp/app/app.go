@@ -0,0 +1,286 @@
+// Package app runs echo-ftp: LoadConfig reads its settings and Run serves it
+// until its context ends, for the echo-ftp binary and echo-all.
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/probitas-test/echo-servers/echo-ftp/docs"
+	"github.com/probitas-test/echo-servers/echo-ftp/server"
+	"github.com/probitas-test/echo-servers/shared/admin"
+	"github.com/probitas-test/echo-servers/shared/bandwidth"
+	"github.com/probitas-test/echo-servers/shared/connlimit"
+	"github.com/probitas-test/echo-servers/shared/lifecycle"
+	"github.com/probitas-test/echo-servers/shared/logging"
+	"github.com/probitas-test/echo-servers/shared/network"
+	"github.com/probitas-test/echo-servers/shared/tracing"
+	"github.com/probitas-test/echo-servers/shared/version"
+)
+
+// Run serves echo-ftp with cfg until ctx is done, then shuts it down
+// gracefully. Logging and the address family are set up by the caller, as
+// they are shared by the servers of a process.
+func Run(ctx context.Context, cfg *Config) error {
+	// Started servers stop when Run returns early with an error
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Listeners serving in the background stop Run with their error
+	serveErr := make(chan error, 2)
+
+	// Simulated slow startup: no port opens before the startup delay
+	if err := cfg.Lifecycle.Validate(); err != nil {
+		return fmt.Errorf("invalid lifecycle configuration: %w", err)
+	}
+	log.Printf("Lifecycle: %s", cfg.Lifecycle)
+	lifecycle.Delay(ctx, cfg.Lifecycle)
+
+	// OpenTelemetry tracing and W3C trace context propagation
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		return fmt.Errorf("failed to set up tracing: %w", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Error("Tracing shutdown error", "error", err)
+		}
+	}()
+	log.Printf("OpenTelemetry tracing: %s", cfg.Tracing)
+
+	if cfg.FaultDropRate < 0 || cfg.FaultDropRate > 1 {
+		return fmt.Errorf("invalid FAULT_DROP_RATE %v (must be 0-1)", cfg.FaultDropRate)
+	}
+	if cfg.FaultDropAfterBytes < 0 {
+		return fmt.Errorf("invalid FAULT_DROP_AFTER_BYTES %d (must be >= 0)", cfg.FaultDropAfterBytes)
+	}
+	if cfg.FTPPublicIP != "" {
+		if ip := net.ParseIP(cfg.FTPPublicIP); ip == nil || ip.To4() == nil {
+			return fmt.Errorf("invalid FTP_PUBLIC_IP %q (must be an IPv4 address)", cfg.FTPPublicIP)
+		}
+	}
+	if err := validatePortRange(cfg.FTPPassivePorts); err != nil {
+		return fmt.Errorf("invalid FTP_PASSIVE_PORTS: %w", err)
+	}
+	hostKey, err := loadHostKey(cfg.SFTPHostKey)
+	if err != nil {
+		return fmt.Errorf("failed to load SFTP host key: %w", err)
+	}
+
+	// Admin API: health, transfer delay and files
+	adm := admin.New("echo-ftp", cfg.src)
+
+	credentials := server.Credentials{
+		Username:  cfg.AuthUsername,
+		Password:  cfg.AuthPassword,
+		Anonymous: cfg.AnonymousEnabled,
+	}
+	fault := server.Fault{
+		DropRate:       cfg.FaultDropRate,
+		DropAfterBytes: int64(cfg.FaultDropAfterBytes),
+		Delay:          adm.Delay,
+	}
+
+	// FTP and SFTP share one in-memory file system
+	fs := server.NewFS()
+	adm.Store("files", func() {
+		if err := server.ResetFS(fs); err != nil {
+			slog.Error("File system reset error", "error", err)
+		}
+	})
+	adm.State("files", func() any { return server.Files(fs) })
+	ftpServer, err := server.NewFTP(fs, server.FTPConfig{
+		PublicIP:       cfg.FTPPublicIP,
+		PassivePorts:   cfg.FTPPassivePorts,
+		ActiveEnabled:  cfg.FTPActiveEnabled,
+		PassiveEnabled: cfg.FTPPassiveEnabled,
+		Credentials:    credentials,
+		Fault:          fault,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create FTP server: %w", err)
+	}
+	sftpServer := server.NewSFTP(fs, server.SFTPConfig{
+		HostKey:     hostKey,
+		Credentials: credentials,
+		Fault:       fault,
+	})
+
+	// Bandwidth shaping of every FTP control and SFTP connection, changed at
+	// runtime on the admin port
+	shaper, err := bandwidth.New(cfg.Bandwidth)
+	if err != nil {
+		return err
+	}
+	log.Printf("Bandwidth: %s", cfg.Bandwidth)
+
+	// Cap on the open FTP and SFTP connections, changed at runtime on the
+	// admin port; the next ones get a server busy error
+	connLimiter, err := connlimit.New(cfg.ConnLimit)
+	if err != nil {
+		return err
+	}
+	log.Printf("Connection limit: %s", cfg.ConnLimit)
+
+	ftpListener, err := network.Listen(cfg.FTPAddr())
+	if err != nil {
+		return fmt.Errorf("failed to listen for FTP: %w", err)
+	}
+	defer func() { _ = ftpListener.Close() }()
+	sftpListener, err := network.Listen(cfg.SFTPAddr())
+	if err != nil {
+		return fmt.Errorf("failed to listen for SFTP: %w", err)
+	}
+	defer func() { _ = sftpListener.Close() }()
+
+	mux := http.NewServeMux()
+
+	// Health check endpoint
+	mux.Handle("GET /health", adm.HealthHandler())
+
+	// Effective configuration (secrets redacted)
+	mux.Handle("GET /config", cfg.src)
+
+	// Address family of the client connection
+	mux.Handle("GET "+network.Path, network.Handler())
+
+	// Build information and enabled features
+	mux.Handle("GET "+version.Path, version.Handler(version.New("echo-ftp", version.Features{
+		"active_mode":  cfg.FTPActiveEnabled,
+		"admin":        cfg.Admin.Enabled,
+		"anonymous":    cfg.AnonymousEnabled,
+		"debug":        cfg.Admin.Debug,
+		"passive_mode": cfg.FTPPassiveEnabled,
+		"tracing":      cfg.Tracing.Enabled,
+	})))
+
+	// API documentation endpoint
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		_, _ = w.Write([]byte(docs.API))
+	})
+
+	// Admin API on a separate port, with the bandwidth and connection limit
+	// admin APIs
+	var adminServer *http.Server
+	if cfg.Admin.Enabled {
+		adm.Handle(bandwidth.Path, bandwidth.Handler(shaper))
+		adm.Handle(connlimit.Path, connlimit.Handler(connLimiter))
+		if cfg.Admin.Debug {
+			adm.Debug()
+		}
+		adminServer, err = adm.Serve(cfg.AdminAddr())
+		if err != nil {
+			return err
+		}
+		defer func() { _ = adminServer.Close() }()
+	}
+
+	// Readiness delay and simulated crash, counted from here
+	lifecycle.Start(ctx, cfg.Lifecycle, adm)
+
+	srv := &http.Server{
+		Addr:              cfg.HTTPAddr(),
+		Handler:           tracing.HTTP(nil)(logging.HTTP(mux)),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	// Graceful shutdown (SFTP connections are closed; FTP sessions end with
+	// the process)
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		<-ctx.Done()
+
+		log.Println("Shutting down server...")
+		if err := ftpServer.Close(); err != nil {
+			slog.Error("FTP server shutdown error", "error", err)
+		}
+		if err := sftpServer.Close(); err != nil {
+			slog.Error("SFTP server shutdown error", "error", err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := srv.Shutdown(ctx); err != nil {
+			slog.Error("Server shutdown error", "error", err)
+		}
+		if adminServer != nil {
+			_ = adminServer.Shutdown(ctx)
+		}
+	}()
+
+	go func() {
+		if err := ftpServer.Serve(shaper.Listen(connLimiter.Listen(ftpListener, connlimit.Line("421 Too many users, try again later\r\n")))); err != nil && !errors.Is(err, server.ErrServerClosed) {
+			serveErr <- fmt.Errorf("failed to serve FTP: %w", err)
+			cancel()
+		}
+	}()
+	go func() {
+		if err := sftpServer.Serve(shaper.Listen(connLimiter.Listen(sftpListener, nil))); err != nil && !errors.Is(err, server.ErrServerClosed) {
+			serveErr <- fmt.Errorf("failed to serve SFTP: %w", err)
+			cancel()
+		}
+	}()
+
+	log.Printf("Credentials: username=%q, anonymous: %v", cfg.AuthUsername, cfg.AnonymousEnabled)
+	log.Printf("FTP active mode: %v, passive mode: %v (ports %s)", cfg.FTPActiveEnabled, cfg.FTPPassiveEnabled, cfg.FTPPassivePorts)
+	if cfg.FaultDropRate > 0 {
+		log.Printf("Dropping %v of transfers after %d bytes", cfg.FaultDropRate, cfg.FaultDropAfterBytes)
+	}
+	log.Printf("Starting FTP server on %s", cfg.FTPAddr())
+	log.Printf("Starting SFTP server on %s (host key %s)", cfg.SFTPAddr(), ssh.FingerprintSHA256(hostKey.PublicKey()))
+	log.Printf("Starting HTTP server on %s", cfg.HTTPAddr())
+	if err := network.ListenAndServe(srv); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve: %w", err)
+	}
+	<-stopped
+
+	log.Println("Server stopped")
+	select {
+	case err := <-serveErr:
+		return err
+	default:
+		return nil
+	}
+}
+
+// loadHostKey reads the PEM encoded SSH host key at path, or generates one
+// when path is empty
+func loadHostKey(path string) (ssh.Signer, error) {
+	if path == "" {
+		return server.GenerateHostKey()
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ssh.ParsePrivateKey(data)
+}
+
+// validatePortRange checks a passive port range such as "30000-30009"
+func validatePortRange(value string) error {
+	if value == "" {
+		return nil
+	}
+	first, last, ok := strings.Cut(value, "-")
+	start, err1 := strconv.Atoi(first)
+	end, err2 := strconv.Atoi(last)
+	if !ok || err1 != nil || err2 != nil || start < 1 || end > 65535 || start > end {
+		return errors.New("must be a range such as 30000-30009")
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package app
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/probitas-test/echo-servers/shared/admin"
+	"github.com/probitas-test/echo-servers/shared/bandwidth"
+	"github.com/probitas-test/echo-servers/shared/config"
+	"github.com/probitas-test/echo-servers/shared/connlimit"
+	"github.com/probitas-test/echo-servers/shared/lifecycle"
+	"github.com/probitas-test/echo-servers/shared/logging"
+	"github.com/probitas-test/echo-servers/shared/network"
+	"github.com/probitas-test/echo-servers/shared/tracing"
+)
+
+type Config struct {
+	Host     string
+	FTPPort  string
+	SFTPPort string
+
+	// Port of the HTTP server for health checks and API documentation
+	HTTPPort string
+
+	// Credentials accepted by FTP and SFTP
+	AuthUsername string
+	AuthPassword string
+
+	// Whether the users "anonymous" and "ftp" are accepted with any password
+	AnonymousEnabled bool
+
+	// IPv4 address announced for FTP passive mode (empty = the address the
+	// client connected to)
+	FTPPublicIP string
+
+	// Range of FTP passive mode ports such as "30000-30009"
+	FTPPassivePorts string
+
+	// Which FTP data connection modes are supported
+	FTPActiveEnabled  bool
+	FTPPassiveEnabled bool
+
+	// Path of the PEM encoded SSH host key (empty = generated at startup)
+	SFTPHostKey string
+
+	// Probability (0-1) that a file transfer is dropped
+	FaultDropRate float64
+
+	// Bytes a dropped transfer moves before its connection is closed
+	FaultDropAfterBytes int
+
+	// Admin API on a separate port (ADMIN_ENABLED, ADMIN_PORT)
+	Admin admin.Config
+
+	// Bandwidth shaping of accepted connections (BANDWIDTH_*), changed at
+	// runtime at /bandwidth on the admin port
+	Bandwidth bandwidth.Config
+
+	// Connection cap (CONN_LIMIT_*), changed at runtime at /connlimit on the
+	// admin port
+	ConnLimit connlimit.Config
+
+	// Address family of every listener (IP_FAMILY: dual, ipv4 or ipv6)
+	Network network.Config
+
+	// Simulated startup delay, readiness delay and crash (STARTUP_DELAY_MS, STARTUP_READY_DELAY_MS, CRASH_AFTER_MS, CRASH_EXIT_CODE)
+	Lifecycle lifecycle.Config
+
+	// Structured logging (LOG_LEVEL, LOG_FORMAT)
+	Logging logging.Config
+
+	// OpenTelemetry tracing (OTEL_* variables)
+	Tracing tracing.Config
+
+	// Effective configuration, served at /config
+	src *config.Source
+}
+
+// LoadConfig reads the settings of echo-ftp from src
+func LoadConfig(src *config.Source) (*Config, error) {
+	cfg := &Config{
+		Host:     src.String("HOST", "0.0.0.0"),
+		FTPPort:  src.String("FTP_PORT", "21"),
+		SFTPPort: src.String("SFTP_PORT", "22"),
+		HTTPPort: src.String("HTTP_PORT", "8080"),
+
+		AuthUsername:     src.String("AUTH_USERNAME", "user"),
+		AuthPassword:     src.Secret("AUTH_PASSWORD", "password"),
+		AnonymousEnabled: src.Bool("ANONYMOUS_ENABLED", false),
+
+		FTPPublicIP:       src.String("FTP_PUBLIC_IP", ""),
+		FTPPassivePorts:   src.String("FTP_PASSIVE_PORTS", "30000-30009"),
+		FTPActiveEnabled:  src.Bool("FTP_ACTIVE_ENABLED", true),
+		FTPPassiveEnabled: src.Bool("FTP_PASSIVE_ENABLED", true),
+
+		SFTPHostKey: src.String("SFTP_HOST_KEY", ""),
+
+		FaultDropRate:       src.Float("FAULT_DROP_RATE", 0),
+		FaultDropAfterBytes: src.Int("FAULT_DROP_AFTER_BYTES", 0),
+
+		Admin:     admin.LoadConfig(src),
+		Tracing:   tracing.LoadConfig(src, "echo-ftp"),
+		Bandwidth: bandwidth.LoadConfig(src),
+		ConnLimit: connlimit.LoadConfig(src),
+		Network:   network.LoadConfig(src),
+		Lifecycle: lifecycle.LoadConfig(src),
+		Logging:   logging.LoadConfig(src),
+	}
+	cfg.src = src
+	if err := src.Err(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	return cfg, nil
+}
+
+func (c *Config) FTPAddr() string {
+	return net.JoinHostPort(c.Host, c.FTPPort)
+}
+
+func (c *Config) SFTPAddr() string {
+	return net.JoinHostPort(c.Host, c.SFTPPort)
+}
+
+func (c *Config) HTTPAddr() string {
+	return net.JoinHostPort(c.Host, c.HTTPPort)
+}
+
+func (c *Config) AdminAddr() string {
+	return net.JoinHostPort(c.Host, c.Admin.Port)
+}
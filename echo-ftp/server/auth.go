@@ -0,0 +1,29 @@
+package server
+
+import "crypto/subtle"
+
+// anonymousUsers are the user names accepted with any password when
+// anonymous login is enabled
+var anonymousUsers = map[string]bool{
+	"anonymous": true,
+	"ftp":       true,
+}
+
+// Credentials are the logins accepted by the FTP and SFTP servers
+type Credentials struct {
+	Username string
+	Password string
+
+	// Anonymous accepts the users "anonymous" and "ftp" with any password
+	Anonymous bool
+}
+
+// Check reports whether user and password are accepted
+func (c Credentials) Check(user, password string) bool {
+	if c.Anonymous && anonymousUsers[user] {
+		return true
+	}
+	username := subtle.ConstantTimeCompare([]byte(user), []byte(c.Username))
+	pass := subtle.ConstantTimeCompare([]byte(password), []byte(c.Password))
+	return username&pass == 1
+}
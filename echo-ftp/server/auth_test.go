@@ -0,0 +1,29 @@
+package server
+
+import "testing"
+
+func TestCredentialsCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		creds    Credentials
+		user     string
+		password string
+		want     bool
+	}{
+		{name: "valid", creds: Credentials{Username: "user", Password: "password"}, user: "user", password: "password", want: true},
+		{name: "wrong password", creds: Credentials{Username: "user", Password: "password"}, user: "user", password: "wrong"},
+		{name: "wrong user", creds: Credentials{Username: "user", Password: "password"}, user: "other", password: "password"},
+		{name: "anonymous disabled", creds: Credentials{Username: "user", Password: "password"}, user: "anonymous", password: "guest"},
+		{name: "anonymous", creds: Credentials{Username: "user", Password: "password", Anonymous: true}, user: "anonymous", password: "guest", want: true},
+		{name: "ftp", creds: Credentials{Username: "user", Password: "password", Anonymous: true}, user: "ftp", want: true},
+		{name: "user with anonymous enabled", creds: Credentials{Username: "user", Password: "password", Anonymous: true}, user: "user", password: "wrong"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.creds.Check(tt.user, tt.password); got != tt.want {
+				t.Errorf("Check(%q, %q) = %v, want %v", tt.user, tt.password, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package server
+
+import (
+	"errors"
+	"io"
+	"math/rand/v2"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// ErrDropped is returned by transfers whose connection was dropped by the
+// fault injection
+var ErrDropped = errors.New("connection dropped by fault injection")
+
+// Fault configures delayed and dropped data connections. Each file transfer
+// (download or upload) is delayed by Delay and dropped with probability
+// DropRate: its connection is closed once DropAfterBytes bytes were
+// transferred.
+type Fault struct {
+	// Delay, if set, is read on every transfer and delays its start
+	Delay func() time.Duration
+
+	// DropRate is the probability (0-1) that a transfer is dropped
+	DropRate float64
+
+	// DropAfterBytes is the number of bytes a dropped transfer moves before
+	// its connection is closed
+	DropAfterBytes int64
+}
+
+// limit delays a new transfer and decides whether it is dropped. It returns
+// the number of bytes the transfer may move, or -1 when it is not dropped.
+func (f Fault) limit() int64 {
+	if f.Delay != nil {
+		if d := f.Delay(); d > 0 {
+			time.Sleep(d)
+		}
+	}
+	if f.DropRate <= 0 || rand.Float64() >= f.DropRate {
+		return -1
+	}
+	return max(f.DropAfterBytes, 0)
+}
+
+// dropReader reads from r until remaining bytes were read, then calls drop
+// and fails with ErrDropped
+type dropReader struct {
+	r         io.Reader
+	remaining int64
+	drop      func()
+}
+
+func (d *dropReader) Read(p []byte) (int, error) {
+	if d.remaining <= 0 {
+		d.drop()
+		return 0, ErrDropped
+	}
+	if int64(len(p)) > d.remaining {
+		p = p[:d.remaining]
+	}
+	n, err := d.r.Read(p)
+	d.remaining -= int64(n)
+	return n, err
+}
+
+// dropReaderAt serves reads below offset limit from the file, then calls drop
+// and fails with ErrDropped
+type dropReaderAt struct {
+	afero.File
+	limit int64
+	drop  func()
+}
+
+func (d *dropReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off >= d.limit {
+		d.drop()
+		return 0, ErrDropped
+	}
+	if off+int64(len(p)) > d.limit {
+		p = p[:d.limit-off]
+	}
+	return d.File.ReadAt(p, off)
+}
+
+// dropWriterAt applies writes below offset limit to the file, then calls drop
+// and fails with ErrDropped
+type dropWriterAt struct {
+	afero.File
+	limit int64
+	drop  func()
+}
+
+func (d *dropWriterAt) WriteAt(p []byte, off int64) (int, error) {
+	if off+int64(len(p)) <= d.limit {
+		return d.File.WriteAt(p, off)
+	}
+	n := 0
+	if off < d.limit {
+		n, _ = d.File.WriteAt(p[:d.limit-off], off)
+	}
+	d.drop()
+	return n, ErrDropped
+}
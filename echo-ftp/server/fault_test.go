@@ -0,0 +1,101 @@
+package server
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestFaultLimit(t *testing.T) {
+	if got := (Fault{}).limit(); got != -1 {
+		t.Errorf("expected no drop without rate, got %d", got)
+	}
+	if got := (Fault{DropRate: 1, DropAfterBytes: 10}).limit(); got != 10 {
+		t.Errorf("expected limit 10 with rate 1, got %d", got)
+	}
+	if got := (Fault{DropRate: 1, DropAfterBytes: -5}).limit(); got != 0 {
+		t.Errorf("expected negative limit to be 0, got %d", got)
+	}
+}
+
+func TestDropReader(t *testing.T) {
+	dropped := false
+	r := &dropReader{
+		r:         strings.NewReader("0123456789"),
+		remaining: 4,
+		drop:      func() { dropped = true },
+	}
+
+	data, err := io.ReadAll(r)
+	if !errors.Is(err, ErrDropped) {
+		t.Fatalf("expected ErrDropped, got %v", err)
+	}
+	if string(data) != "0123" {
+		t.Errorf("expected 4 bytes before the drop, got %q", data)
+	}
+	if !dropped {
+		t.Error("expected drop to be called")
+	}
+}
+
+func TestDropReaderAt(t *testing.T) {
+	fs := NewFS()
+	f, err := fs.Open(HelloFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+
+	drops := 0
+	r := &dropReaderAt{File: f, limit: 5, drop: func() { drops++ }}
+
+	buf := make([]byte, 8)
+	n, err := r.ReadAt(buf, 0)
+	if err != nil || string(buf[:n]) != "Hello" {
+		t.Errorf("expected %q below the limit, got %q (err=%v)", "Hello", buf[:n], err)
+	}
+	if drops != 0 {
+		t.Errorf("expected no drop below the limit, got %d", drops)
+	}
+
+	if _, err := r.ReadAt(buf, 5); !errors.Is(err, ErrDropped) {
+		t.Errorf("expected ErrDropped at the limit, got %v", err)
+	}
+	if drops != 1 {
+		t.Errorf("expected 1 drop, got %d", drops)
+	}
+}
+
+func TestDropWriterAt(t *testing.T) {
+	fs := NewFS()
+	f, err := fs.Create("/upload/data.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	drops := 0
+	w := &dropWriterAt{File: f, limit: 6, drop: func() { drops++ }}
+
+	if _, err := w.WriteAt([]byte("abcd"), 0); err != nil {
+		t.Fatalf("unexpected error below the limit: %v", err)
+	}
+	n, err := w.WriteAt([]byte("efgh"), 4)
+	if !errors.Is(err, ErrDropped) {
+		t.Fatalf("expected ErrDropped, got %v", err)
+	}
+	if n != 2 {
+		t.Errorf("expected 2 bytes written up to the limit, got %d", n)
+	}
+	if drops != 1 {
+		t.Errorf("expected 1 drop, got %d", drops)
+	}
+	_ = f.Close()
+
+	data, _ := afero.ReadFile(fs, "/upload/data.bin")
+	if string(data) != "abcdef" {
+		t.Errorf("expected %q stored, got %q", "abcdef", data)
+	}
+}
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"os"
+	"path"
+
+	"github.com/spf13/afero"
+)
+
+// HelloFile is the sample file every new file system contains
+const HelloFile = "/hello.txt"
+
+// UploadDir is the empty directory every new file system contains
+const UploadDir = "/upload"
+
+// helloContent is the content of HelloFile
+const helloContent = "Hello, World!\n"
+
+// NewFS creates the in-memory file system shared by the FTP and SFTP
+// servers, seeded with HelloFile and UploadDir. Nothing is persisted.
+func NewFS() afero.Fs {
+	fs := afero.NewMemMapFs()
+	seed(fs)
+	return fs
+}
+
+// ResetFS removes every file and directory of fs and seeds it again like
+// NewFS
+func ResetFS(fs afero.Fs) error {
+	entries, err := afero.ReadDir(fs, "/")
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if err := fs.RemoveAll(path.Join("/", e.Name())); err != nil {
+			return err
+		}
+	}
+	seed(fs)
+	return nil
+}
+
+// Files lists the paths of the files of fs, sorted
+func Files(fs afero.Fs) []string {
+	files := []string{}
+	_ = afero.Walk(fs, "/", func(name string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			files = append(files, name)
+		}
+		return nil
+	})
+	return files
+}
+
+func seed(fs afero.Fs) {
+	_ = afero.WriteFile(fs, HelloFile, []byte(helloContent), 0o644)
+	_ = fs.Mkdir(UploadDir, 0o755)
+}
+
+// checkParent ensures the parent directory of name exists, so uploads to
+// missing directories fail like on a disk
+func checkParent(fs afero.Fs, name string) error {
+	info, err := fs.Stat(path.Dir(name))
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	}
+	return nil
+}
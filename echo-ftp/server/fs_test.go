@@ -0,0 +1,68 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestNewFS(t *testing.T) {
+	fs := NewFS()
+
+	data, err := afero.ReadFile(fs, HelloFile)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", HelloFile, err)
+	}
+	if string(data) != "Hello, World!\n" {
+		t.Errorf("expected hello content, got %q", data)
+	}
+
+	info, err := fs.Stat(UploadDir)
+	if err != nil {
+		t.Fatalf("failed to stat %s: %v", UploadDir, err)
+	}
+	if !info.IsDir() {
+		t.Errorf("expected %s to be a directory", UploadDir)
+	}
+}
+
+func TestResetFS(t *testing.T) {
+	fs := NewFS()
+	_ = fs.MkdirAll("/upload/reports", 0o755)
+	_ = afero.WriteFile(fs, "/upload/reports/q1.csv", []byte("x"), 0o644)
+	_ = fs.Remove(HelloFile)
+
+	if err := ResetFS(fs); err != nil {
+		t.Fatalf("ResetFS: %v", err)
+	}
+	if files := Files(fs); len(files) != 1 || files[0] != HelloFile {
+		t.Errorf("files after reset = %v, want [%s]", files, HelloFile)
+	}
+	if info, err := fs.Stat(UploadDir); err != nil || !info.IsDir() {
+		t.Errorf("expected %s after reset: %v", UploadDir, err)
+	}
+}
+
+func TestCheckParent(t *testing.T) {
+	fs := NewFS()
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr bool
+	}{
+		{name: "root", path: "/new.txt"},
+		{name: "existing directory", path: "/upload/new.txt"},
+		{name: "missing directory", path: "/missing/new.txt", wantErr: true},
+		{name: "parent is a file", path: "/hello.txt/new.txt", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkParent(fs, tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkParent(%q) error = %v, wantErr %v", tt.path, err, tt.wantErr)
+			}
+		})
+	}
+}
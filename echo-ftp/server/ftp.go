@@ -0,0 +1,255 @@
+package server
+
+import (
+	"errors"
+	"io"
+	"maps"
+	"net"
+	"os"
+	"sync"
+
+	ftpserver "goftp.io/server/v2"
+
+	"github.com/spf13/afero"
+)
+
+// activeCommands open active mode data connections (the server connects to
+// the client)
+var activeCommands = []string{"PORT", "EPRT", "LPRT"}
+
+// passiveCommands open passive mode data connections (the client connects
+// to the server)
+var passiveCommands = []string{"PASV", "EPSV"}
+
+// ErrServerClosed is returned by Serve after Close
+var ErrServerClosed = errors.New("server closed")
+
+// errIsDir is returned for file operations on directories
+var errIsDir = errors.New("is a directory")
+
+// errNotDir is returned for directory operations on files
+var errNotDir = errors.New("not a directory")
+
+// FTPConfig configures the FTP server
+type FTPConfig struct {
+	// PublicIP is the IPv4 address announced for passive mode (empty = the
+	// address the client connected to)
+	PublicIP string
+
+	// PassivePorts is the range of passive mode ports such as
+	// "30000-30009" (empty = any free port)
+	PassivePorts string
+
+	// Which data connection modes are supported
+	ActiveEnabled  bool
+	PassiveEnabled bool
+
+	Credentials Credentials
+	Fault       Fault
+}
+
+// FTPServer serves FTP. It guards the listener of the wrapped server, whose
+// Shutdown races with Serve.
+type FTPServer struct {
+	server *ftpserver.Server
+
+	mu       sync.Mutex
+	listener net.Listener
+	closed   bool
+}
+
+// NewFTP creates an FTP server for fs
+func NewFTP(fs afero.Fs, cfg FTPConfig) (*FTPServer, error) {
+	commands := maps.Clone(ftpserver.DefaultCommands())
+	if !cfg.ActiveEnabled {
+		for _, name := range activeCommands {
+			delete(commands, name)
+		}
+	}
+	if !cfg.PassiveEnabled {
+		for _, name := range passiveCommands {
+			delete(commands, name)
+		}
+	}
+
+	server, err := ftpserver.NewServer(&ftpserver.Options{
+		Commands:       commands,
+		Driver:         &ftpDriver{fs: fs, fault: cfg.Fault},
+		Auth:           ftpAuth(cfg.Credentials),
+		Perm:           ftpserver.NewSimplePerm("echo", "echo"),
+		Name:           "echo-ftp",
+		PublicIP:       cfg.PublicIP,
+		PassivePorts:   cfg.PassivePorts,
+		WelcomeMessage: "echo-ftp ready",
+		Logger:         &ftpserver.DiscardLogger{},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &FTPServer{server: server}, nil
+}
+
+// Serve accepts FTP control connections on l until Close is called
+func (s *FTPServer) Serve(l net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return ErrServerClosed
+	}
+	s.listener = l
+	s.mu.Unlock()
+
+	err := s.server.Serve(l)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrServerClosed
+	}
+	return err
+}
+
+// Close stops accepting connections. Open sessions are not interrupted.
+func (s *FTPServer) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	if s.listener != nil {
+		return s.listener.Close()
+	}
+	return nil
+}
+
+// ftpAuth checks FTP logins against Credentials
+type ftpAuth Credentials
+
+func (a ftpAuth) CheckPasswd(ctx *ftpserver.Context, user, password string) (bool, error) {
+	return Credentials(a).Check(user, password), nil
+}
+
+// ftpDriver serves FTP commands from an afero file system
+type ftpDriver struct {
+	fs    afero.Fs
+	fault Fault
+}
+
+func (d *ftpDriver) Stat(ctx *ftpserver.Context, path string) (os.FileInfo, error) {
+	return d.fs.Stat(path)
+}
+
+func (d *ftpDriver) ListDir(ctx *ftpserver.Context, path string, callback func(os.FileInfo) error) error {
+	infos, err := afero.ReadDir(d.fs, path)
+	if err != nil {
+		return err
+	}
+	for _, info := range infos {
+		if err := callback(info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *ftpDriver) DeleteDir(ctx *ftpserver.Context, path string) error {
+	info, err := d.fs.Stat(path)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return errNotDir
+	}
+	return d.fs.Remove(path)
+}
+
+func (d *ftpDriver) DeleteFile(ctx *ftpserver.Context, path string) error {
+	info, err := d.fs.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return errIsDir
+	}
+	return d.fs.Remove(path)
+}
+
+func (d *ftpDriver) Rename(ctx *ftpserver.Context, fromPath, toPath string) error {
+	return d.fs.Rename(fromPath, toPath)
+}
+
+func (d *ftpDriver) MakeDir(ctx *ftpserver.Context, path string) error {
+	return d.fs.Mkdir(path, 0o755)
+}
+
+// GetFile opens path for RETR. A dropped download closes the data
+// connection, which the server answers with 551.
+func (d *ftpDriver) GetFile(ctx *ftpserver.Context, path string, offset int64) (int64, io.ReadCloser, error) {
+	f, err := d.fs.Open(path)
+	if err != nil {
+		return 0, nil, err
+	}
+	info, err := f.Stat()
+	if err == nil && info.IsDir() {
+		err = errIsDir
+	}
+	if err == nil {
+		_, err = f.Seek(offset, io.SeekStart)
+	}
+	if err != nil {
+		_ = f.Close()
+		return 0, nil, err
+	}
+
+	size := info.Size() - offset
+	if limit := d.fault.limit(); limit >= 0 {
+		return size, &readCloser{
+			Reader: &dropReader{r: f, remaining: limit, drop: func() {}},
+			Closer: f,
+		}, nil
+	}
+	return size, f, nil
+}
+
+// PutFile stores data, the data connection of STOR or APPE, at path. APPE
+// appends, STOR replaces the file or, after REST, writes from offset. A
+// dropped upload closes the data connection, which the server answers with
+// 450.
+func (d *ftpDriver) PutFile(ctx *ftpserver.Context, path string, data io.Reader, offset int64) (int64, error) {
+	if err := checkParent(d.fs, path); err != nil {
+		return 0, err
+	}
+	if info, err := d.fs.Stat(path); err == nil && info.IsDir() {
+		return 0, errIsDir
+	}
+	flag := os.O_WRONLY | os.O_CREATE
+	switch {
+	case ctx.Cmd == "APPE":
+		flag |= os.O_APPEND
+	case offset < 0:
+		flag |= os.O_TRUNC
+	}
+	f, err := d.fs.OpenFile(path, flag, 0o644)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = f.Close() }()
+	if ctx.Cmd != "APPE" && offset > 0 {
+		if _, err := f.Seek(offset, io.SeekStart); err != nil {
+			return 0, err
+		}
+	}
+
+	if limit := d.fault.limit(); limit >= 0 {
+		conn := data
+		data = &dropReader{r: conn, remaining: limit, drop: func() {
+			if c, ok := conn.(io.Closer); ok {
+				_ = c.Close()
+			}
+		}}
+	}
+	return io.Copy(f, data)
+}
+
+// readCloser combines a reader with the file it reads from
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,326 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jlaffaye/ftp"
+	"github.com/spf13/afero"
+)
+
+var testCredentials = Credentials{Username: "user", Password: "password"}
+
+// startFTP serves fs over FTP on a random local port and returns its
+// address
+func startFTP(t *testing.T, fs afero.Fs, cfg FTPConfig) string {
+	t.Helper()
+	srv, err := NewFTP(fs, cfg)
+	if err != nil {
+		t.Fatalf("failed to create FTP server: %v", err)
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	go func() { _ = srv.Serve(l) }()
+	t.Cleanup(func() { _ = srv.Close() })
+	return l.Addr().String()
+}
+
+// dialFTP connects to addr and logs in with user and password
+func dialFTP(t *testing.T, addr, user, password string) *ftp.ServerConn {
+	t.Helper()
+	c, err := ftp.Dial(addr, ftp.DialWithTimeout(5*time.Second))
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	t.Cleanup(func() { _ = c.Quit() })
+	if err := c.Login(user, password); err != nil {
+		t.Fatalf("failed to log in: %v", err)
+	}
+	return c
+}
+
+func defaultFTPConfig() FTPConfig {
+	return FTPConfig{
+		ActiveEnabled:  true,
+		PassiveEnabled: true,
+		Credentials:    testCredentials,
+	}
+}
+
+func TestFTPRetrieve(t *testing.T) {
+	addr := startFTP(t, NewFS(), defaultFTPConfig())
+	c := dialFTP(t, addr, "user", "password")
+
+	resp, err := c.Retr(HelloFile)
+	if err != nil {
+		t.Fatalf("failed to retrieve: %v", err)
+	}
+	data, err := io.ReadAll(resp)
+	_ = resp.Close()
+	if err != nil {
+		t.Fatalf("failed to read: %v", err)
+	}
+	if string(data) != "Hello, World!\n" {
+		t.Errorf("expected hello content, got %q", data)
+	}
+}
+
+func TestFTPStoreAndList(t *testing.T) {
+	fs := NewFS()
+	addr := startFTP(t, fs, defaultFTPConfig())
+	c := dialFTP(t, addr, "user", "password")
+
+	if err := c.Stor("/upload/a.txt", strings.NewReader("first")); err != nil {
+		t.Fatalf("failed to store: %v", err)
+	}
+	if err := c.Append("/upload/a.txt", strings.NewReader("+second")); err != nil {
+		t.Fatalf("failed to append: %v", err)
+	}
+	data, _ := afero.ReadFile(fs, "/upload/a.txt")
+	if string(data) != "first+second" {
+		t.Errorf("expected appended content, got %q", data)
+	}
+
+	entries, err := c.List("/upload")
+	if err != nil {
+		t.Fatalf("failed to list: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Name != "a.txt" || entries[0].Size != 12 {
+		t.Errorf("expected a.txt with 12 bytes, got %+v", entries)
+	}
+
+	if err := c.Stor("/missing/a.txt", strings.NewReader("x")); err == nil {
+		t.Error("expected store into a missing directory to fail")
+	}
+}
+
+func TestFTPFileCommands(t *testing.T) {
+	fs := NewFS()
+	addr := startFTP(t, fs, defaultFTPConfig())
+	c := dialFTP(t, addr, "user", "password")
+
+	if err := c.MakeDir("/upload/dir"); err != nil {
+		t.Fatalf("failed to make directory: %v", err)
+	}
+	if err := c.Rename(HelloFile, "/upload/dir/renamed.txt"); err != nil {
+		t.Fatalf("failed to rename: %v", err)
+	}
+	if err := c.RemoveDir("/upload/dir/renamed.txt"); err == nil {
+		t.Error("expected RMD of a file to fail")
+	}
+	if err := c.Delete("/upload/dir/renamed.txt"); err != nil {
+		t.Fatalf("failed to delete: %v", err)
+	}
+	if err := c.RemoveDir("/upload/dir"); err != nil {
+		t.Fatalf("failed to remove directory: %v", err)
+	}
+	if exists, _ := afero.Exists(fs, "/upload/dir"); exists {
+		t.Error("expected /upload/dir to be removed")
+	}
+}
+
+func TestFTPLogin(t *testing.T) {
+	tests := []struct {
+		name      string
+		anonymous bool
+		user      string
+		password  string
+		wantErr   bool
+	}{
+		{name: "valid", user: "user", password: "password"},
+		{name: "wrong password", user: "user", password: "wrong", wantErr: true},
+		{name: "anonymous disabled", user: "anonymous", password: "guest", wantErr: true},
+		{name: "anonymous", anonymous: true, user: "anonymous", password: "guest"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := defaultFTPConfig()
+			cfg.Credentials.Anonymous = tt.anonymous
+			addr := startFTP(t, NewFS(), cfg)
+
+			c, err := ftp.Dial(addr, ftp.DialWithTimeout(5*time.Second))
+			if err != nil {
+				t.Fatalf("failed to dial: %v", err)
+			}
+			defer func() { _ = c.Quit() }()
+			err = c.Login(tt.user, tt.password)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Login() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFTPPassiveDisabled(t *testing.T) {
+	cfg := defaultFTPConfig()
+	cfg.PassiveEnabled = false
+	addr := startFTP(t, NewFS(), cfg)
+	c := dialFTP(t, addr, "user", "password")
+
+	if _, err := c.Retr(HelloFile); err == nil {
+		t.Error("expected passive transfer to fail")
+	}
+}
+
+// controlConn is a raw FTP control connection for active mode, which the
+// client library does not support
+type controlConn struct {
+	*textproto.Conn
+}
+
+func dialControl(t *testing.T, addr string) *controlConn {
+	t.Helper()
+	conn, err := textproto.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	c := &controlConn{conn}
+	c.expect(t, 220)
+	c.cmd(t, 331, "USER user")
+	c.cmd(t, 230, "PASS password")
+	return c
+}
+
+func (c *controlConn) cmd(t *testing.T, code int, format string, args ...any) string {
+	t.Helper()
+	if err := c.PrintfLine(format, args...); err != nil {
+		t.Fatalf("failed to send %q: %v", format, err)
+	}
+	return c.expect(t, code)
+}
+
+func (c *controlConn) expect(t *testing.T, code int) string {
+	t.Helper()
+	_, message, err := c.ReadResponse(code)
+	if err != nil {
+		t.Fatalf("expected %d: %v", code, err)
+	}
+	return message
+}
+
+// portArg formats the address of l as a PORT argument
+func portArg(l net.Listener) string {
+	addr := l.Addr().(*net.TCPAddr)
+	ip := addr.IP.To4()
+	return fmt.Sprintf("%d,%d,%d,%d,%d,%d", ip[0], ip[1], ip[2], ip[3], addr.Port/256, addr.Port%256)
+}
+
+func TestFTPActiveMode(t *testing.T) {
+	addr := startFTP(t, NewFS(), defaultFTPConfig())
+	c := dialControl(t, addr)
+
+	l, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer func() { _ = l.Close() }()
+
+	c.cmd(t, 200, "PORT %s", portArg(l))
+	if err := c.PrintfLine("RETR %s", HelloFile); err != nil {
+		t.Fatalf("failed to send RETR: %v", err)
+	}
+	conn, err := l.Accept()
+	if err != nil {
+		t.Fatalf("failed to accept data connection: %v", err)
+	}
+	data, _ := io.ReadAll(conn)
+	_ = conn.Close()
+	c.expect(t, 150)
+	c.expect(t, 226)
+
+	if string(data) != "Hello, World!\n" {
+		t.Errorf("expected hello content, got %q", data)
+	}
+}
+
+func TestFTPActiveDisabled(t *testing.T) {
+	cfg := defaultFTPConfig()
+	cfg.ActiveEnabled = false
+	addr := startFTP(t, NewFS(), cfg)
+	c := dialControl(t, addr)
+
+	if err := c.PrintfLine("PORT 127,0,0,1,4,1"); err != nil {
+		t.Fatal(err)
+	}
+	code, _, _ := c.ReadResponse(200)
+	if code < 500 {
+		t.Errorf("expected PORT to be refused, got %d", code)
+	}
+}
+
+func TestFTPDroppedDownload(t *testing.T) {
+	cfg := defaultFTPConfig()
+	cfg.Fault = Fault{DropRate: 1, DropAfterBytes: 5}
+	addr := startFTP(t, NewFS(), cfg)
+	c := dialFTP(t, addr, "user", "password")
+
+	resp, err := c.Retr(HelloFile)
+	if err != nil {
+		t.Fatalf("failed to retrieve: %v", err)
+	}
+	data, _ := io.ReadAll(resp)
+	err = resp.Close()
+
+	if string(data) != "Hello" {
+		t.Errorf("expected 5 bytes before the drop, got %q", data)
+	}
+	var protoErr *textproto.Error
+	if !errors.As(err, &protoErr) || protoErr.Code != 551 {
+		t.Errorf("expected 551 after the drop, got %v", err)
+	}
+}
+
+func TestFTPDroppedUpload(t *testing.T) {
+	fs := NewFS()
+	cfg := defaultFTPConfig()
+	cfg.Fault = Fault{DropRate: 1, DropAfterBytes: 4}
+	addr := startFTP(t, fs, cfg)
+	c := dialFTP(t, addr, "user", "password")
+
+	payload := bytes.Repeat([]byte("x"), 1<<20)
+	err := c.Stor("/upload/big.bin", bytes.NewReader(payload))
+	if err == nil {
+		t.Fatal("expected dropped upload to fail")
+	}
+
+	data, _ := afero.ReadFile(fs, "/upload/big.bin")
+	if len(data) != 4 {
+		t.Errorf("expected 4 bytes stored before the drop, got %d", len(data))
+	}
+}
+
+func TestFTPShutdown(t *testing.T) {
+	srv, err := NewFTP(NewFS(), defaultFTPConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(l) }()
+
+	if err := srv.Close(); err != nil {
+		t.Fatalf("failed to close: %v", err)
+	}
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrServerClosed) {
+			t.Errorf("expected ErrServerClosed, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve did not return after Close")
+	}
+}
@@ -0,0 +1,322 @@
+package server
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/sftp"
+	"github.com/spf13/afero"
+	"golang.org/x/crypto/ssh"
+)
+
+// errAuthFailed rejects SSH logins with wrong credentials
+var errAuthFailed = errors.New("invalid username or password")
+
+// SFTPConfig configures the SFTP server
+type SFTPConfig struct {
+	// HostKey identifies the server to clients
+	HostKey ssh.Signer
+
+	Credentials Credentials
+	Fault       Fault
+}
+
+// SFTPServer serves the SFTP subsystem over SSH with password
+// authentication. Other SSH features (shells, commands, forwarding) are
+// refused.
+type SFTPServer struct {
+	fs    afero.Fs
+	fault Fault
+	ssh   *ssh.ServerConfig
+
+	mu       sync.Mutex
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+	closed   bool
+}
+
+// NewSFTP creates an SFTP server for fs
+func NewSFTP(fs afero.Fs, cfg SFTPConfig) *SFTPServer {
+	sshCfg := &ssh.ServerConfig{
+		PasswordCallback: func(meta ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if cfg.Credentials.Check(meta.User(), string(password)) {
+				return nil, nil
+			}
+			return nil, errAuthFailed
+		},
+		ServerVersion: "SSH-2.0-echo-ftp",
+	}
+	sshCfg.AddHostKey(cfg.HostKey)
+
+	return &SFTPServer{
+		fs:    fs,
+		fault: cfg.Fault,
+		ssh:   sshCfg,
+		conns: make(map[net.Conn]struct{}),
+	}
+}
+
+// GenerateHostKey creates a new ed25519 host key
+func GenerateHostKey() (ssh.Signer, error) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return ssh.NewSignerFromKey(key)
+}
+
+// Serve accepts SSH connections on l until Close is called
+func (s *SFTPServer) Serve(l net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return ErrServerClosed
+	}
+	s.listener = l
+	s.mu.Unlock()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return ErrServerClosed
+			}
+			return err
+		}
+		go s.serveConn(conn)
+	}
+}
+
+// Close stops accepting connections and closes the open ones
+func (s *SFTPServer) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+	if s.listener != nil {
+		return s.listener.Close()
+	}
+	return nil
+}
+
+func (s *SFTPServer) serveConn(conn net.Conn) {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		_ = conn.Close()
+		return
+	}
+	s.conns[conn] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		_ = conn.Close()
+	}()
+
+	// The handshake must not block forever on silent clients
+	_ = conn.SetDeadline(time.Now().Add(30 * time.Second))
+	_, chans, reqs, err := ssh.NewServerConn(conn, s.ssh)
+	if err != nil {
+		return
+	}
+	_ = conn.SetDeadline(time.Time{})
+	go ssh.DiscardRequests(reqs)
+
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "session" {
+			_ = newChannel.Reject(ssh.UnknownChannelType, "only session channels are supported")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			continue
+		}
+		go s.serveSession(conn, channel, requests)
+	}
+}
+
+// serveSession starts the SFTP subsystem when requested and refuses every
+// other session request. A dropped transfer closes conn, the whole SSH
+// connection.
+func (s *SFTPServer) serveSession(conn net.Conn, channel ssh.Channel, requests <-chan *ssh.Request) {
+	started := false
+	for req := range requests {
+		var payload struct{ Name string }
+		if started || req.Type != "subsystem" || ssh.Unmarshal(req.Payload, &payload) != nil || payload.Name != "sftp" {
+			_ = req.Reply(false, nil)
+			continue
+		}
+		_ = req.Reply(true, nil)
+		started = true
+
+		go func() {
+			h := &sftpHandler{fs: s.fs, fault: s.fault, drop: func() { _ = conn.Close() }}
+			server := sftp.NewRequestServer(channel, sftp.Handlers{
+				FileGet:  h,
+				FilePut:  h,
+				FileCmd:  h,
+				FileList: h,
+			})
+			_ = server.Serve()
+			_ = server.Close()
+		}()
+	}
+}
+
+// sftpHandler serves SFTP requests from an afero file system. drop closes
+// the connection when a transfer is dropped.
+type sftpHandler struct {
+	fs    afero.Fs
+	fault Fault
+	drop  func()
+}
+
+func (h *sftpHandler) Fileread(r *sftp.Request) (io.ReaderAt, error) {
+	f, err := h.fs.Open(r.Filepath)
+	if err != nil {
+		return nil, err
+	}
+	if info, err := f.Stat(); err != nil || info.IsDir() {
+		_ = f.Close()
+		return nil, errIsDir
+	}
+	if limit := h.fault.limit(); limit >= 0 {
+		return &dropReaderAt{File: f, limit: limit, drop: h.drop}, nil
+	}
+	return f, nil
+}
+
+func (h *sftpHandler) Filewrite(r *sftp.Request) (io.WriterAt, error) {
+	if err := checkParent(h.fs, r.Filepath); err != nil {
+		return nil, err
+	}
+	if info, err := h.fs.Stat(r.Filepath); err == nil && info.IsDir() {
+		return nil, errIsDir
+	}
+
+	pflags := r.Pflags()
+	flag := os.O_WRONLY | os.O_CREATE
+	if pflags.Trunc {
+		flag |= os.O_TRUNC
+	}
+	if pflags.Excl {
+		flag |= os.O_EXCL
+	}
+	f, err := h.fs.OpenFile(r.Filepath, flag, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if limit := h.fault.limit(); limit >= 0 {
+		return &dropWriterAt{File: f, limit: limit, drop: h.drop}, nil
+	}
+	return f, nil
+}
+
+func (h *sftpHandler) Filecmd(r *sftp.Request) error {
+	switch r.Method {
+	case "Setstat":
+		return h.setstat(r)
+	case "Rename":
+		if _, err := h.fs.Stat(r.Target); err == nil {
+			return os.ErrExist
+		}
+		return h.fs.Rename(r.Filepath, r.Target)
+	case "Rmdir":
+		info, err := h.fs.Stat(r.Filepath)
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return errNotDir
+		}
+		return h.fs.Remove(r.Filepath)
+	case "Remove":
+		info, err := h.fs.Stat(r.Filepath)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return errIsDir
+		}
+		return h.fs.Remove(r.Filepath)
+	case "Mkdir":
+		return h.fs.Mkdir(r.Filepath, 0o755)
+	}
+	return sftp.ErrSSHFxOpUnsupported
+}
+
+// setstat applies the size, permissions and times of a Setstat request;
+// owners are ignored
+func (h *sftpHandler) setstat(r *sftp.Request) error {
+	flags := r.AttrFlags()
+	attrs := r.Attributes()
+	if flags.Size {
+		f, err := h.fs.OpenFile(r.Filepath, os.O_WRONLY, 0)
+		if err != nil {
+			return err
+		}
+		err = f.Truncate(int64(attrs.Size))
+		_ = f.Close()
+		if err != nil {
+			return err
+		}
+	}
+	if flags.Permissions {
+		if err := h.fs.Chmod(r.Filepath, attrs.FileMode()); err != nil {
+			return err
+		}
+	}
+	if flags.Acmodtime {
+		atime := time.Unix(int64(attrs.Atime), 0)
+		mtime := time.Unix(int64(attrs.Mtime), 0)
+		if err := h.fs.Chtimes(r.Filepath, atime, mtime); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *sftpHandler) Filelist(r *sftp.Request) (sftp.ListerAt, error) {
+	switch r.Method {
+	case "List":
+		infos, err := afero.ReadDir(h.fs, r.Filepath)
+		if err != nil {
+			return nil, err
+		}
+		return listerAt(infos), nil
+	case "Stat":
+		info, err := h.fs.Stat(r.Filepath)
+		if err != nil {
+			return nil, err
+		}
+		return listerAt{info}, nil
+	}
+	return nil, sftp.ErrSSHFxOpUnsupported
+}
+
+// listerAt serves directory listings and stats
+type listerAt []os.FileInfo
+
+func (l listerAt) ListAt(infos []os.FileInfo, offset int64) (int, error) {
+	if offset >= int64(len(l)) {
+		return 0, io.EOF
+	}
+	n := copy(infos, l[offset:])
+	if n < len(infos) {
+		return n, io.EOF
+	}
+	return n, nil
+}
@@ -0,0 +1,224 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/pkg/sftp"
+	"github.com/spf13/afero"
+	"golang.org/x/crypto/ssh"
+)
+
+// startSFTP serves fs over SFTP on a random local port and returns its
+// address
+func startSFTP(t *testing.T, fs afero.Fs, cfg SFTPConfig) string {
+	t.Helper()
+	if cfg.HostKey == nil {
+		key, err := GenerateHostKey()
+		if err != nil {
+			t.Fatalf("failed to generate host key: %v", err)
+		}
+		cfg.HostKey = key
+	}
+	srv := NewSFTP(fs, cfg)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	go func() { _ = srv.Serve(l) }()
+	t.Cleanup(func() { _ = srv.Close() })
+	return l.Addr().String()
+}
+
+func dialSSH(addr, user, password string) (*ssh.Client, error) {
+	return ssh.Dial("tcp", addr, &ssh.ClientConfig{
+		User:            user,
+		Auth:            []ssh.AuthMethod{ssh.Password(password)},
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		Timeout:         5 * time.Second,
+	})
+}
+
+// dialSFTP connects to addr and opens the SFTP subsystem
+func dialSFTP(t *testing.T, addr string) *sftp.Client {
+	t.Helper()
+	conn, err := dialSSH(addr, "user", "password")
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	c, err := sftp.NewClient(conn)
+	if err != nil {
+		t.Fatalf("failed to start SFTP: %v", err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	return c
+}
+
+func TestSFTPRetrieve(t *testing.T) {
+	addr := startSFTP(t, NewFS(), SFTPConfig{Credentials: testCredentials})
+	c := dialSFTP(t, addr)
+
+	f, err := c.Open(HelloFile)
+	if err != nil {
+		t.Fatalf("failed to open: %v", err)
+	}
+	defer func() { _ = f.Close() }()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatalf("failed to read: %v", err)
+	}
+	if string(data) != "Hello, World!\n" {
+		t.Errorf("expected hello content, got %q", data)
+	}
+}
+
+func TestSFTPFileCommands(t *testing.T) {
+	fs := NewFS()
+	addr := startSFTP(t, fs, SFTPConfig{Credentials: testCredentials})
+	c := dialSFTP(t, addr)
+
+	f, err := c.Create("/upload/a.txt")
+	if err != nil {
+		t.Fatalf("failed to create: %v", err)
+	}
+	if _, err := f.Write([]byte("uploaded")); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+	_ = f.Close()
+	data, _ := afero.ReadFile(fs, "/upload/a.txt")
+	if string(data) != "uploaded" {
+		t.Errorf("expected uploaded content, got %q", data)
+	}
+
+	infos, err := c.ReadDir("/upload")
+	if err != nil {
+		t.Fatalf("failed to list: %v", err)
+	}
+	if len(infos) != 1 || infos[0].Name() != "a.txt" || infos[0].Size() != 8 {
+		t.Errorf("expected a.txt with 8 bytes, got %v", infos)
+	}
+
+	if err := c.Mkdir("/upload/dir"); err != nil {
+		t.Fatalf("failed to make directory: %v", err)
+	}
+	if err := c.Rename("/upload/a.txt", "/upload/dir/b.txt"); err != nil {
+		t.Fatalf("failed to rename: %v", err)
+	}
+	if err := c.Rename(HelloFile, "/upload/dir/b.txt"); err == nil {
+		t.Error("expected rename onto an existing file to fail")
+	}
+	if err := c.Truncate("/upload/dir/b.txt", 2); err != nil {
+		t.Fatalf("failed to truncate: %v", err)
+	}
+	if info, err := c.Stat("/upload/dir/b.txt"); err != nil || info.Size() != 2 {
+		t.Errorf("expected truncated size 2, got %v (err=%v)", info, err)
+	}
+	if err := c.Remove("/upload/dir/b.txt"); err != nil {
+		t.Fatalf("failed to remove: %v", err)
+	}
+	if err := c.RemoveDirectory("/upload/dir"); err != nil {
+		t.Fatalf("failed to remove directory: %v", err)
+	}
+
+	if _, err := c.Stat("/missing"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected not exist, got %v", err)
+	}
+	if _, err := c.Create("/missing/a.txt"); err == nil {
+		t.Error("expected create in a missing directory to fail")
+	}
+}
+
+func TestSFTPLogin(t *testing.T) {
+	addr := startSFTP(t, NewFS(), SFTPConfig{Credentials: testCredentials})
+
+	if _, err := dialSSH(addr, "user", "wrong"); err == nil {
+		t.Error("expected wrong password to be rejected")
+	}
+	if _, err := dialSSH(addr, "anonymous", "guest"); err == nil {
+		t.Error("expected anonymous login to be rejected")
+	}
+}
+
+func TestSFTPSharedFS(t *testing.T) {
+	fs := NewFS()
+	ftpAddr := startFTP(t, fs, defaultFTPConfig())
+	sftpAddr := startSFTP(t, fs, SFTPConfig{Credentials: testCredentials})
+
+	if err := dialFTP(t, ftpAddr, "user", "password").Stor("/upload/shared.txt", bytes.NewReader([]byte("shared"))); err != nil {
+		t.Fatalf("failed to store over FTP: %v", err)
+	}
+
+	f, err := dialSFTP(t, sftpAddr).Open("/upload/shared.txt")
+	if err != nil {
+		t.Fatalf("failed to open over SFTP: %v", err)
+	}
+	defer func() { _ = f.Close() }()
+	data, _ := io.ReadAll(f)
+	if string(data) != "shared" {
+		t.Errorf("expected shared content, got %q", data)
+	}
+}
+
+func TestSFTPDroppedDownload(t *testing.T) {
+	addr := startSFTP(t, NewFS(), SFTPConfig{
+		Credentials: testCredentials,
+		Fault:       Fault{DropRate: 1, DropAfterBytes: 5},
+	})
+	c := dialSFTP(t, addr)
+
+	f, err := c.Open(HelloFile)
+	if err != nil {
+		t.Fatalf("failed to open: %v", err)
+	}
+	if _, err := io.ReadAll(f); err == nil {
+		t.Error("expected dropped download to fail")
+	}
+
+	// The connection is closed, so the client is unusable
+	if _, err := c.Stat(HelloFile); err == nil {
+		t.Error("expected the connection to be closed")
+	}
+}
+
+func TestSFTPClose(t *testing.T) {
+	key, err := GenerateHostKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := NewSFTP(NewFS(), SFTPConfig{HostKey: key, Credentials: testCredentials})
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(l) }()
+
+	conn, err := dialSSH(l.Addr().String(), "user", "password")
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	if err := srv.Close(); err != nil {
+		t.Fatalf("failed to close: %v", err)
+	}
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrServerClosed) {
+			t.Errorf("expected ErrServerClosed, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve did not return after Close")
+	}
+
+	// Open connections are closed
+	if err := conn.Wait(); err == nil {
+		t.Error("expected the connection to be closed")
+	}
+}
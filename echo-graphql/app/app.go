@@ -0,0 +1,493 @@
+// Package app runs echo-graphql: LoadConfig reads its settings and Run
+// serves it until its context ends, for the echo-graphql binary and echo-
+// all.
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/apollotracing"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/99designs/gqlgen/graphql/playground"
+	"github.com/gorilla/websocket"
+
+	"github.com/probitas-test/echo-servers/echo-graphql/docs"
+	"github.com/probitas-test/echo-servers/echo-graphql/graph"
+	"github.com/probitas-test/echo-servers/echo-graphql/graph/model"
+	"github.com/probitas-test/echo-servers/shared/admin"
+	"github.com/probitas-test/echo-servers/shared/bandwidth"
+	"github.com/probitas-test/echo-servers/shared/chaos"
+	"github.com/probitas-test/echo-servers/shared/clock"
+	"github.com/probitas-test/echo-servers/shared/connlimit"
+	"github.com/probitas-test/echo-servers/shared/credentials"
+	"github.com/probitas-test/echo-servers/shared/jwt"
+	"github.com/probitas-test/echo-servers/shared/lifecycle"
+	"github.com/probitas-test/echo-servers/shared/logging"
+	"github.com/probitas-test/echo-servers/shared/metrics"
+	"github.com/probitas-test/echo-servers/shared/network"
+	"github.com/probitas-test/echo-servers/shared/recording"
+	"github.com/probitas-test/echo-servers/shared/tracing"
+	"github.com/probitas-test/echo-servers/shared/version"
+)
+
+// requestContextMiddleware injects the http.Request into context for header access
+func requestContextMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), model.RequestKey, r)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// Run serves echo-graphql with cfg until ctx is done, then shuts it down
+// gracefully. Logging and the address family are set up by the caller, as
+// they are shared by the servers of a process.
+func Run(ctx context.Context, cfg *Config) error {
+	// Started servers stop when Run returns early with an error
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Simulated slow startup: no port opens before the startup delay
+	if err := cfg.Lifecycle.Validate(); err != nil {
+		return fmt.Errorf("invalid lifecycle configuration: %w", err)
+	}
+	log.Printf("Lifecycle: %s", cfg.Lifecycle)
+	lifecycle.Delay(ctx, cfg.Lifecycle)
+
+	// Configure OpenTelemetry tracing
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		return fmt.Errorf("failed to set up tracing: %w", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Error("Tracing shutdown error", "error", err)
+		}
+	}()
+	log.Printf("OpenTelemetry tracing: %s", cfg.Tracing)
+
+	// Admin API: health, operation delay, introspection flag and the
+	// message, operation and APQ stores
+	adm := admin.New("echo-graphql", cfg.src)
+
+	// Clock of JWT expiry and the JWKS cache, moved at runtime on the admin
+	// port to expire tokens without waiting
+	clk := clock.New(cfg.Clock)
+	log.Printf("Clock: %s", cfg.Clock)
+
+	resolver := graph.NewResolver()
+	resolver.Clock = clk
+	resolver.MaxHugeSizeKb = cfg.EchoHugeMaxSizeKb
+	resolver.MaxDeepDepth = cfg.EchoDeepMaxDepth
+	if cfg.JWTJWKSURL != "" {
+		resolver.JWKS = jwt.NewKeySet(cfg.JWTJWKSURL, clk)
+	}
+	resolver.Build = version.New("echo-graphql", version.Features{
+		"admin":             cfg.Admin.Enabled,
+		"apollo_tracing":    cfg.ApolloTracingEnabled,
+		"apq":               cfg.APQEnabled,
+		"batching":          cfg.BatchingEnabled,
+		"chaos":             cfg.Chaos.Enabled,
+		"cors":              cfg.CORSEnabled,
+		"debug":             cfg.Admin.Debug,
+		"federation":        cfg.FederationEnabled,
+		"introspection":     cfg.IntrospectionEnabled,
+		"metrics":           cfg.MetricsEnabled,
+		"persisted_queries": cfg.PersistedQueriesOnly,
+		"playground":        cfg.PlaygroundEnabled,
+		"recording":         cfg.Recording.Enabled,
+		"sse":               cfg.SSEEnabled,
+		"tracing":           cfg.Tracing.Enabled,
+		"websocket":         cfg.WebSocketEnabled,
+	})
+	es := graph.NewExecutableSchema(graph.Config{
+		Resolvers:  resolver,
+		Directives: graph.NewDirectiveRoot(),
+		Complexity: graph.NewComplexityRoot(),
+	})
+	srv := handler.New(es)
+
+	// Graceful shutdown tracking of WebSocket connections and SSE
+	// subscriptions, which also backs /readyz
+	drainer := graph.NewDrainer()
+
+	// HTTP transports
+	srv.AddTransport(transport.Options{})
+	srv.AddTransport(transport.GET{})
+	// multipart/mixed incremental delivery (@defer), SSE subscriptions and
+	// batched requests must precede POST, which also accepts their
+	// application/json requests
+	srv.AddTransport(transport.MultipartMixed{})
+	if cfg.SSEEnabled {
+		// Server-Sent Events transport for subscriptions (graphql-sse
+		// distinct connections mode)
+		srv.AddTransport(transport.SSE{})
+	}
+	if cfg.BatchingEnabled {
+		// Array-batched POST bodies (Apollo batch format)
+		srv.AddTransport(graph.BatchPOST{MaxBatchSize: cfg.BatchMaxSize})
+	}
+	srv.AddTransport(transport.POST{})
+	srv.AddTransport(transport.MultipartForm{})
+
+	// WebSocket transport for subscriptions (graphql-ws and/or
+	// graphql-transport-ws subprotocols)
+	if cfg.WebSocketEnabled {
+		keepAlive := time.Duration(max(cfg.WebSocketKeepAliveMs, 0)) * time.Millisecond
+		srv.AddTransport(graph.WebsocketTransport{
+			Websocket: transport.Websocket{
+				Upgrader: websocket.Upgrader{
+					CheckOrigin: func(r *http.Request) bool {
+						return true
+					},
+					ReadBufferSize:  1024,
+					WriteBufferSize: 1024,
+				},
+				InitFunc: graph.NewInitFunc(time.Duration(cfg.WebSocketAckDelayMs) * time.Millisecond),
+				// "ka" messages (graphql-ws) and pings (graphql-transport-ws)
+				KeepAlivePingInterval: keepAlive,
+				PingPongInterval:      keepAlive,
+				MissingPongOk:         true,
+			},
+			DisableGraphQLWS:          !cfg.GraphQLWSEnabled,
+			DisableGraphQLTransportWS: !cfg.GraphQLTransportWSEnabled,
+			Drainer:                   drainer,
+		})
+	}
+
+	srv.Use(drainer)
+
+	// Operation capture (registered first to record requests as sent,
+	// before APQ resolves persisted queries)
+	if cfg.OperationLogSize > 0 {
+		resolver.Operations = graph.NewOperationLog(cfg.OperationLogSize)
+		srv.Use(resolver.Operations)
+	}
+
+	// Fault injection per operation (delays, errors, resets, bandwidth)
+	faults, err := chaos.New(cfg.Chaos)
+	if err != nil {
+		return err
+	}
+	srv.Use(graph.Chaos{Engine: faults})
+
+	// Persisted query allowlist (only registered operations may execute)
+	if cfg.PersistedQueriesOnly {
+		resolver.PersistedQueries = graph.NewPersistedQueries()
+		if cfg.PersistedQueriesManifest != "" {
+			n, err := resolver.PersistedQueries.LoadManifestFile(cfg.PersistedQueriesManifest)
+			if err != nil {
+				return fmt.Errorf("failed to load persisted query manifest: %w", err)
+			}
+			log.Printf("Loaded %d persisted queries from %s", n, cfg.PersistedQueriesManifest)
+		}
+		srv.Use(resolver.PersistedQueries)
+	}
+
+	// Resolver timings: OpenTelemetry spans and the Apollo tracing
+	// response extension
+	if cfg.Tracing.Enabled {
+		srv.Use(graph.Tracer{})
+	}
+	if cfg.ApolloTracingEnabled {
+		srv.Use(apollotracing.Tracer{})
+	}
+
+	// Introspection (optionally disabled or gated by a token header)
+	introspection := graph.IntrospectionPolicy{
+		Enabled: cfg.IntrospectionEnabled,
+		Switch: adm.Flag("introspection", cfg.IntrospectionEnabled,
+			"Allow introspection and /schema.graphql").Enabled,
+		Header: cfg.IntrospectionTokenHeader,
+		Token:  cfg.IntrospectionToken,
+	}
+	srv.Use(introspection)
+
+	// Apollo Federation subgraph fields (_service, _entities)
+	if !cfg.FederationEnabled {
+		srv.Use(graph.FederationGuard{})
+	}
+
+	// Query cost limits (always registered so queryCost can report the cost)
+	srv.Use(graph.NewComplexityLimit(cfg.ComplexityLimit))
+	srv.Use(graph.DepthLimit{Limit: cfg.DepthLimit})
+
+	// Automatic Persisted Queries (Apollo hash negotiation; replaced by the
+	// allowlist in persisted queries only mode)
+	if cfg.APQEnabled && !cfg.PersistedQueriesOnly {
+		resolver.APQ = graph.NewAPQCache(cfg.APQCacheSize)
+		srv.Use(extension.AutomaticPersistedQuery{Cache: resolver.APQ})
+	}
+
+	adm.Store("messages", func() { resolver.ClearMessages() })
+	adm.State("messages", func() any { return resolver.MessageCount() })
+	if resolver.Operations != nil {
+		adm.Store("operations", func() { resolver.Operations.Clear() })
+	}
+	if resolver.APQ != nil {
+		adm.Store("apq_stats", func() { resolver.APQ.Reset() })
+		adm.State("apq_stats", func() any { return resolver.APQ.Stats() })
+	}
+
+	mux := http.NewServeMux()
+
+	// Health check endpoint
+	mux.Handle("/health", adm.HealthHandler())
+
+	// Effective configuration (secrets redacted)
+	mux.Handle("/config", cfg.src)
+
+	// Address family of the client connection
+	mux.Handle(network.Path, network.Handler())
+
+	// Build information and enabled features, also reported by the version
+	// query
+	mux.Handle(version.Path, version.Handler(resolver.Build))
+
+	// Liveness and readiness probes (readiness fails during the readiness
+	// delay, while unhealthy and once shutdown starts)
+	mux.HandleFunc("/livez", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"ok"}`))
+	})
+	mux.Handle("/readyz", drainer.ReadyHandler(adm))
+
+	// Captured operations (GET lists newest first, DELETE clears)
+	mux.HandleFunc("/admin/operations", func(w http.ResponseWriter, r *http.Request) {
+		if resolver.Operations == nil {
+			http.Error(w, "operation log is disabled", http.StatusNotFound)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]any{"operations": resolver.Operations.Last(limit)})
+		case http.MethodDelete:
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]any{"cleared": resolver.Operations.Clear()})
+		default:
+			w.Header().Set("Allow", "GET, DELETE")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	// Persisted query allowlist (GET lists, POST registers a manifest,
+	// DELETE clears)
+	mux.HandleFunc("/admin/persisted-queries", func(w http.ResponseWriter, r *http.Request) {
+		if resolver.PersistedQueries == nil {
+			http.Error(w, "persisted queries only mode is disabled", http.StatusNotFound)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]any{"operations": resolver.PersistedQueries.List()})
+		case http.MethodPost:
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			queries, err := graph.ParseManifest(body)
+			if err == nil {
+				err = resolver.PersistedQueries.Register(queries...)
+			}
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]any{"registered": len(queries)})
+		case http.MethodDelete:
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]any{"cleared": resolver.PersistedQueries.Clear()})
+		default:
+			w.Header().Set("Allow", "GET, POST, DELETE")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	// Schema SDL for codegen tooling (same access rules as introspection)
+	mux.Handle("/schema.graphql", graph.NewSchemaHandler(es.Schema(), introspection))
+
+	// API documentation endpoint
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		_, _ = w.Write([]byte(docs.API))
+	})
+
+	// GraphQL playground
+	if cfg.PlaygroundEnabled {
+		mux.Handle("/playground", playground.Handler("GraphQL Playground", "/graphql"))
+	} else {
+		mux.HandleFunc("/playground", http.NotFound)
+	}
+
+	// Test identities shared with the other servers, required on the GraphQL
+	// endpoint while the store is; its admin API is served on the admin port
+	creds, err := credentials.New(cfg.Credentials)
+	if err != nil {
+		return fmt.Errorf("invalid credentials: %w", err)
+	}
+	log.Printf("Credentials: %s", cfg.Credentials)
+	creds.SetClock(clk)
+
+	// GraphQL endpoint (with request context middleware for header access and
+	// connection resets, delayed by the admin API), behind authentication
+	// and optional CSRF prevention and CORS
+	graphqlHandler := credentials.HTTP(creds, "echo-graphql")(adm.HTTP(chaos.Resettable(requestContextMiddleware(srv))))
+	if cfg.CSRFPreventionEnabled {
+		graphqlHandler = graph.CSRFPrevention{Headers: cfg.CSRFPreventionHeaders}.Handler(graphqlHandler)
+	}
+	if cfg.CORSEnabled {
+		graphqlHandler = graph.CORS{
+			AllowedOrigins:   cfg.CORSAllowedOrigins,
+			AllowedHeaders:   cfg.CORSAllowedHeaders,
+			AllowCredentials: cfg.CORSAllowCredentials,
+			MaxAge:           time.Duration(cfg.CORSMaxAgeSeconds) * time.Second,
+		}.Handler(graphqlHandler)
+	}
+	mux.Handle("/graphql", graphqlHandler)
+
+	// Fault injection admin API
+	mux.Handle(chaos.Path, chaos.Handler(faults))
+
+	// Traffic recording, toggled and downloaded through the admin API
+	recorder, err := recording.New("echo-graphql", cfg.Recording)
+	if err != nil {
+		return err
+	}
+	log.Printf("Recording: %s", cfg.Recording)
+
+	// Bandwidth shaping of every GraphQL connection, changed at runtime on the
+	// admin port
+	shaper, err := bandwidth.New(cfg.Bandwidth)
+	if err != nil {
+		return err
+	}
+	log.Printf("Bandwidth: %s", cfg.Bandwidth)
+
+	// Cap on the open connections, changed at runtime on the admin port; the
+	// next ones get a server busy error
+	connLimiter, err := connlimit.New(cfg.ConnLimit)
+	if err != nil {
+		return err
+	}
+	log.Printf("Connection limit: %s", cfg.ConnLimit)
+
+	// Admin API on a separate port, with the fault injection, credential
+	// store, bandwidth, connection limit and recording admin APIs
+	var adminServer *http.Server
+	if cfg.Admin.Enabled {
+		adm.Handle(credentials.Path, credentials.Handler(creds))
+		adm.Handle(clock.Path, clock.Handler(clk))
+		adm.Handle(bandwidth.Path, bandwidth.Handler(shaper))
+		adm.Handle(connlimit.Path, connlimit.Handler(connLimiter))
+		adm.Handle(chaos.Path, chaos.Handler(faults))
+		recordings := recording.Handler(recorder)
+		adm.Handle(recording.Path, recordings)
+		adm.Handle(recording.Path+"/", recordings)
+		if cfg.Admin.Debug {
+			adm.Debug()
+		}
+		adminServer, err = adm.Serve(cfg.AdminAddr())
+		if err != nil {
+			return err
+		}
+		defer func() { _ = adminServer.Close() }()
+	}
+
+	// Readiness delay and simulated crash, counted from here
+	lifecycle.Start(ctx, cfg.Lifecycle, adm)
+
+	log.Printf("Subscription transports: WebSocket=%v (graphql-ws=%v, graphql-transport-ws=%v), SSE=%v",
+		cfg.WebSocketEnabled, cfg.GraphQLWSEnabled, cfg.GraphQLTransportWSEnabled, cfg.SSEEnabled)
+	log.Printf("WebSocket keep-alive interval: %dms (0 = disabled), ack delay: %dms", cfg.WebSocketKeepAliveMs, cfg.WebSocketAckDelayMs)
+	log.Printf("Batching enabled: %v (max batch size: %d, 0 = unlimited)", cfg.BatchingEnabled, cfg.BatchMaxSize)
+	log.Printf("Introspection enabled: %v (token required: %v), playground enabled: %v",
+		cfg.IntrospectionEnabled, cfg.IntrospectionToken != "", cfg.PlaygroundEnabled)
+	log.Printf("CORS enabled: %v (origins: %v, credentials: %v), CSRF prevention enabled: %v",
+		cfg.CORSEnabled, cfg.CORSAllowedOrigins, cfg.CORSAllowCredentials, cfg.CSRFPreventionEnabled)
+	log.Printf("Federation subgraph mode: %v", cfg.FederationEnabled)
+	log.Printf("Operation log size: %d (0 = disabled)", cfg.OperationLogSize)
+	log.Printf("APQ enabled: %v (cache size: %d)", cfg.APQEnabled && !cfg.PersistedQueriesOnly, cfg.APQCacheSize)
+	log.Printf("Persisted queries only: %v", cfg.PersistedQueriesOnly)
+	log.Printf("Complexity limit: %d, depth limit: %d (0 = unlimited)", cfg.ComplexityLimit, cfg.DepthLimit)
+	log.Printf("JWT JWKS URL: %q (empty = bearer tokens are decoded without verification)", cfg.JWTJWKSURL)
+	log.Printf("Stress limits: echoHuge %dKiB, echoDeep depth %d (0 = unlimited)", cfg.EchoHugeMaxSizeKb, cfg.EchoDeepMaxDepth)
+	log.Printf("Apollo tracing enabled: %v", cfg.ApolloTracingEnabled)
+	log.Printf("Chaos: %s", cfg.Chaos)
+	log.Printf("Shutdown drain delay: %dms, timeout: %dms", cfg.ShutdownDrainDelayMs, cfg.ShutdownTimeoutMs)
+
+	// Server span per request, echoing the trace context in the response
+	handler := tracing.HTTP(nil)(logging.HTTP(recorder.HTTP(mux)))
+
+	// Prometheus metrics of every request, served on a separate port
+	var metricsServer *http.Server
+	if cfg.MetricsEnabled {
+		m := metrics.New("echo-graphql")
+		handler = m.HTTP("graphql", nil)(handler)
+		metricsServer, err = m.Serve(cfg.MetricsAddr())
+		if err != nil {
+			return err
+		}
+		defer func() { _ = metricsServer.Close() }()
+	}
+
+	server := &http.Server{
+		Addr:              cfg.Addr(),
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	// Graceful shutdown: fail readiness, end subscriptions with a going-away
+	// close, then let in-flight operations complete
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		<-ctx.Done()
+
+		log.Println("Shutting down server...")
+		drainer.Drain()
+		time.Sleep(time.Duration(cfg.ShutdownDrainDelayMs) * time.Millisecond)
+		log.Printf("Terminated %d subscription connections", drainer.Terminate())
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.ShutdownTimeoutMs)*time.Millisecond)
+		defer cancel()
+		if err := server.Shutdown(ctx); err != nil {
+			slog.Error("Server shutdown error", "error", err)
+		}
+		if metricsServer != nil {
+			_ = metricsServer.Shutdown(ctx)
+		}
+		if adminServer != nil {
+			_ = adminServer.Shutdown(ctx)
+		}
+	}()
+
+	listener, err := network.Listen(cfg.Addr())
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	log.Printf("Starting server on %s", cfg.Addr())
+	if err := server.Serve(shaper.Listen(connLimiter.Listen(listener, connlimit.HTTP))); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve: %w", err)
+	}
+	<-stopped
+
+	log.Println("Server stopped")
+	return nil
+}
@@ -0,0 +1,221 @@
+package app
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/probitas-test/echo-servers/shared/admin"
+	"github.com/probitas-test/echo-servers/shared/bandwidth"
+	"github.com/probitas-test/echo-servers/shared/chaos"
+	"github.com/probitas-test/echo-servers/shared/clock"
+	"github.com/probitas-test/echo-servers/shared/config"
+	"github.com/probitas-test/echo-servers/shared/connlimit"
+	"github.com/probitas-test/echo-servers/shared/credentials"
+	"github.com/probitas-test/echo-servers/shared/lifecycle"
+	"github.com/probitas-test/echo-servers/shared/logging"
+	"github.com/probitas-test/echo-servers/shared/network"
+	"github.com/probitas-test/echo-servers/shared/recording"
+	"github.com/probitas-test/echo-servers/shared/tracing"
+)
+
+type Config struct {
+	Host string
+	Port string
+
+	// Subscription transports
+	WebSocketEnabled bool
+	SSEEnabled       bool
+
+	// WebSocket subprotocols
+	GraphQLWSEnabled          bool
+	GraphQLTransportWSEnabled bool
+
+	// WebSocket keep-alive interval (0 disables keep-alives) and
+	// connection_ack delay in milliseconds
+	WebSocketKeepAliveMs int
+	WebSocketAckDelayMs  int
+
+	// Array-batched POST requests (0 = unlimited batch size)
+	BatchingEnabled bool
+	BatchMaxSize    int
+
+	// Number of executed operations kept for inspection (0 disables capture)
+	OperationLogSize int
+
+	// Stress query guards: largest echoHuge response in KiB and deepest
+	// echoDeep response (0 = unlimited)
+	EchoHugeMaxSizeKb int
+	EchoDeepMaxDepth  int
+
+	// JSON Web Key Set used to verify echoHeaders.bearer signatures
+	JWTJWKSURL string
+
+	// CORS for browser clients (origins and headers are comma-separated,
+	// "*" allows any) and Apollo-style CSRF prevention
+	CORSEnabled           bool
+	CORSAllowedOrigins    []string
+	CORSAllowedHeaders    []string
+	CORSAllowCredentials  bool
+	CORSMaxAgeSeconds     int
+	CSRFPreventionEnabled bool
+	CSRFPreventionHeaders []string
+
+	// Graceful shutdown: how long /readyz reports 503 before connections are
+	// drained, and how long in-flight operations may take to complete
+	ShutdownDrainDelayMs int
+	ShutdownTimeoutMs    int
+
+	// Automatic Persisted Queries
+	APQEnabled   bool
+	APQCacheSize int
+
+	// Persisted query allowlist mode, optionally seeded from a manifest file
+	PersistedQueriesOnly     bool
+	PersistedQueriesManifest string
+
+	// Introspection and playground access
+	IntrospectionEnabled     bool
+	IntrospectionToken       string
+	IntrospectionTokenHeader string
+	PlaygroundEnabled        bool
+
+	// Apollo Federation v2 subgraph mode
+	FederationEnabled bool
+
+	// Query cost limits (0 disables the limit)
+	ComplexityLimit int
+	DepthLimit      int
+
+	// Apollo tracing response extension
+	ApolloTracingEnabled bool
+
+	// Prometheus metrics, served at /metrics on a separate port
+	MetricsEnabled bool
+	MetricsPort    string
+
+	// Admin API on a separate port (ADMIN_ENABLED, ADMIN_PORT)
+	Admin admin.Config
+
+	// Fault injection (CHAOS_*), changed at runtime at /chaos
+	Chaos chaos.Config
+
+	// Traffic recording (RECORDING_*), downloaded from the admin port
+	Recording recording.Config
+
+	// Bandwidth shaping of accepted connections (BANDWIDTH_*), changed at
+	// runtime at /bandwidth on the admin port
+	Bandwidth bandwidth.Config
+
+	// Connection cap (CONN_LIMIT_*), changed at runtime at /connlimit on the
+	// admin port
+	ConnLimit connlimit.Config
+
+	// Address family of every listener (IP_FAMILY: dual, ipv4 or ipv6)
+	Network network.Config
+
+	// Simulated startup delay, readiness delay and crash (STARTUP_DELAY_MS, STARTUP_READY_DELAY_MS, CRASH_AFTER_MS, CRASH_EXIT_CODE)
+	Lifecycle lifecycle.Config
+
+	// Test identities shared with the other servers (CREDENTIALS_USERS, CREDENTIALS_FILE, CREDENTIALS_REQUIRED)
+	Credentials credentials.Config
+
+	// Controllable clock of JWT expiry (CLOCK_OFFSET_MS), moved at runtime at /clock on the admin port
+	Clock clock.Config
+
+	// Structured logging (LOG_LEVEL, LOG_FORMAT)
+	Logging logging.Config
+
+	// OpenTelemetry tracing (OTEL_* variables)
+	Tracing tracing.Config
+
+	// Effective configuration, served at /config
+	src *config.Source
+}
+
+// LoadConfig reads the settings of echo-graphql from src
+func LoadConfig(src *config.Source) (*Config, error) {
+	cfg := &Config{
+		Host: src.String("HOST", "0.0.0.0"),
+		Port: src.String("PORT", "8080"),
+
+		WebSocketEnabled: src.Bool("WEBSOCKET_ENABLED", true),
+		SSEEnabled:       src.Bool("SSE_ENABLED", true),
+
+		GraphQLWSEnabled:          src.Bool("GRAPHQL_WS_ENABLED", true),
+		GraphQLTransportWSEnabled: src.Bool("GRAPHQL_TRANSPORT_WS_ENABLED", true),
+
+		WebSocketKeepAliveMs: src.Int("WEBSOCKET_KEEPALIVE_INTERVAL_MS", 10000),
+		WebSocketAckDelayMs:  src.Int("WEBSOCKET_ACK_DELAY_MS", 0),
+
+		BatchingEnabled: src.Bool("BATCHING_ENABLED", true),
+		BatchMaxSize:    src.Int("BATCH_MAX_SIZE", 10),
+
+		OperationLogSize: src.Int("OPERATION_LOG_SIZE", 100),
+
+		EchoHugeMaxSizeKb: src.Int("ECHO_HUGE_MAX_SIZE_KB", 10240),
+		EchoDeepMaxDepth:  src.Int("ECHO_DEEP_MAX_DEPTH", 1000),
+
+		JWTJWKSURL: src.String("JWT_JWKS_URL", ""),
+
+		CORSEnabled:           src.Bool("CORS_ENABLED", true),
+		CORSAllowedOrigins:    src.List("CORS_ALLOWED_ORIGINS", "*"),
+		CORSAllowedHeaders:    src.List("CORS_ALLOWED_HEADERS", "*"),
+		CORSAllowCredentials:  src.Bool("CORS_ALLOW_CREDENTIALS", false),
+		CORSMaxAgeSeconds:     src.Int("CORS_MAX_AGE_SECONDS", 600),
+		CSRFPreventionEnabled: src.Bool("CSRF_PREVENTION_ENABLED", false),
+		CSRFPreventionHeaders: src.List("CSRF_PREVENTION_HEADERS", "X-Apollo-Operation-Name,Apollo-Require-Preflight"),
+
+		ShutdownDrainDelayMs: src.Int("SHUTDOWN_DRAIN_DELAY_MS", 0),
+		ShutdownTimeoutMs:    src.Int("SHUTDOWN_TIMEOUT_MS", 10000),
+
+		APQEnabled:   src.Bool("APQ_ENABLED", true),
+		APQCacheSize: src.Int("APQ_CACHE_SIZE", 1000),
+
+		PersistedQueriesOnly:     src.Bool("PERSISTED_QUERIES_ONLY", false),
+		PersistedQueriesManifest: src.String("PERSISTED_QUERIES_MANIFEST", ""),
+
+		IntrospectionEnabled:     src.Bool("INTROSPECTION_ENABLED", true),
+		IntrospectionToken:       src.Secret("INTROSPECTION_TOKEN", ""),
+		IntrospectionTokenHeader: src.String("INTROSPECTION_TOKEN_HEADER", "X-Introspection-Token"),
+		PlaygroundEnabled:        src.Bool("PLAYGROUND_ENABLED", true),
+
+		FederationEnabled: src.Bool("FEDERATION_ENABLED", false),
+
+		ComplexityLimit: src.Int("COMPLEXITY_LIMIT", 0),
+		DepthLimit:      src.Int("DEPTH_LIMIT", 0),
+
+		ApolloTracingEnabled: src.Bool("APOLLO_TRACING_ENABLED", false),
+
+		MetricsEnabled: src.Bool("METRICS_ENABLED", true),
+		MetricsPort:    src.String("METRICS_PORT", "9090"),
+
+		Admin:       admin.LoadConfig(src),
+		Chaos:       chaos.LoadConfig(src),
+		Tracing:     tracing.LoadConfig(src, "echo-graphql"),
+		Recording:   recording.LoadConfig(src),
+		Bandwidth:   bandwidth.LoadConfig(src),
+		ConnLimit:   connlimit.LoadConfig(src),
+		Network:     network.LoadConfig(src),
+		Lifecycle:   lifecycle.LoadConfig(src),
+		Credentials: credentials.LoadConfig(src),
+		Clock:       clock.LoadConfig(src),
+		Logging:     logging.LoadConfig(src),
+	}
+	cfg.src = src
+	if err := src.Err(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	return cfg, nil
+}
+
+func (c *Config) Addr() string {
+	return net.JoinHostPort(c.Host, c.Port)
+}
+
+func (c *Config) MetricsAddr() string {
+	return net.JoinHostPort(c.Host, c.MetricsPort)
+}
+
+func (c *Config) AdminAddr() string {
+	return net.JoinHostPort(c.Host, c.Admin.Port)
+}
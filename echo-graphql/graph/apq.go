@@ -0,0 +1,87 @@
+package graph
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler/lru"
+
+	"github.com/probitas-test/echo-servers/echo-graphql/graph/model"
+)
+
+// APQCache is an Automatic Persisted Query cache that counts lookups so
+// clients can verify their hash negotiation behavior.
+type APQCache struct {
+	cache         graphql.Cache[string]
+	hits          atomic.Int64
+	misses        atomic.Int64
+	registrations atomic.Int64
+}
+
+// NewAPQCache creates an APQ cache holding up to size queries in LRU order.
+// A non-positive size keeps every query in memory without eviction.
+func NewAPQCache(size int) *APQCache {
+	if size <= 0 {
+		return &APQCache{cache: &mapCache{queries: make(map[string]string)}}
+	}
+	return &APQCache{cache: lru.New[string](size)}
+}
+
+// Get looks up a query by its SHA-256 hash, counting a hit or miss
+func (c *APQCache) Get(ctx context.Context, hash string) (string, bool) {
+	query, ok := c.cache.Get(ctx, hash)
+	if ok {
+		c.hits.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
+	return query, ok
+}
+
+// Add registers a query sent alongside its hash
+func (c *APQCache) Add(ctx context.Context, hash string, query string) {
+	c.registrations.Add(1)
+	c.cache.Add(ctx, hash, query)
+}
+
+// Stats returns the current hit/miss counters
+func (c *APQCache) Stats() *model.APQStats {
+	return &model.APQStats{
+		Enabled:       true,
+		Hits:          int(c.hits.Load()),
+		Misses:        int(c.misses.Load()),
+		Registrations: int(c.registrations.Load()),
+	}
+}
+
+// Reset clears the counters and returns their previous values. Cached
+// queries are kept.
+func (c *APQCache) Reset() *model.APQStats {
+	return &model.APQStats{
+		Enabled:       true,
+		Hits:          int(c.hits.Swap(0)),
+		Misses:        int(c.misses.Swap(0)),
+		Registrations: int(c.registrations.Swap(0)),
+	}
+}
+
+// mapCache is an unbounded, concurrency-safe in-memory cache
+type mapCache struct {
+	mu      sync.RWMutex
+	queries map[string]string
+}
+
+func (m *mapCache) Get(_ context.Context, key string) (string, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	query, ok := m.queries[key]
+	return query, ok
+}
+
+func (m *mapCache) Add(_ context.Context, key string, value string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.queries[key] = value
+}
@@ -0,0 +1,100 @@
+package graph
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"sync"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// BatchPOST accepts array-batched POST bodies (the Apollo batch format) and
+// responds with an array of responses in request order. Operations of a batch
+// execute concurrently. It must be registered before transport.POST, which
+// also accepts application/json POST requests.
+type BatchPOST struct {
+	// MaxBatchSize is the maximum number of operations per batch (0 = unlimited)
+	MaxBatchSize int
+}
+
+var _ graphql.Transport = BatchPOST{}
+
+func (t BatchPOST) Supports(r *http.Request) bool {
+	if r.Method != http.MethodPost || r.Header.Get("Upgrade") != "" || r.Body == nil {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		return false
+	}
+
+	// Peek at the body and restore it for the transport that handles it
+	body, err := io.ReadAll(r.Body)
+	_ = r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return false
+	}
+	trimmed := bytes.TrimLeft(body, " \t\r\n")
+	return len(trimmed) > 0 && trimmed[0] == '['
+}
+
+func (t BatchPOST) Do(w http.ResponseWriter, r *http.Request, exec graphql.GraphExecutor) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	start := graphql.Now()
+	var batch []*graphql.RawParams
+	dec := json.NewDecoder(r.Body)
+	dec.UseNumber()
+	if err := dec.Decode(&batch); err != nil {
+		writeGraphQLError(w, http.StatusBadRequest, gqlerror.Errorf("json request body could not be decoded: %v", err))
+		return
+	}
+	readTime := graphql.TraceTiming{Start: start, End: graphql.Now()}
+
+	switch {
+	case len(batch) == 0:
+		writeGraphQLError(w, http.StatusBadRequest, gqlerror.Errorf("batch must contain at least one operation"))
+		return
+	case t.MaxBatchSize > 0 && len(batch) > t.MaxBatchSize:
+		writeGraphQLError(w, http.StatusBadRequest, gqlerror.Errorf("batch of %d operations exceeds the limit of %d", len(batch), t.MaxBatchSize))
+		return
+	}
+
+	responses := make([]*graphql.Response, len(batch))
+	var wg sync.WaitGroup
+	for i, params := range batch {
+		if params == nil {
+			responses[i] = exec.DispatchError(ctx, gqlerror.List{gqlerror.Errorf("batch entry %d is not an operation", i)})
+			continue
+		}
+		params.Headers = r.Header
+		params.ReadTime = readTime
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rc, opErr := exec.CreateOperationContext(ctx, params)
+			if opErr != nil {
+				responses[i] = exec.DispatchError(graphql.WithOperationContext(ctx, rc), opErr)
+				return
+			}
+			handler, opCtx := exec.DispatchOperation(ctx, rc)
+			responses[i] = handler(opCtx)
+		}()
+	}
+	wg.Wait()
+
+	_ = json.NewEncoder(w).Encode(responses)
+}
+
+// writeGraphQLError writes a request-level error response with status
+func writeGraphQLError(w http.ResponseWriter, status int, err *gqlerror.Error) {
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(&graphql.Response{Errors: gqlerror.List{err}})
+}
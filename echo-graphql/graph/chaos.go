@@ -0,0 +1,83 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+
+	"github.com/probitas-test/echo-servers/shared/chaos"
+)
+
+const (
+	errChaosError = "CHAOS_INJECTED_ERROR"
+	errChaosReset = "CHAOS_CONNECTION_RESET"
+)
+
+// Chaos injects the faults of a chaos engine into every operation,
+// matching rules against the operation name and its type and name, such
+// as "mutation CreateMessage" ("query" alone for anonymous queries).
+// Injected errors are GraphQL errors with the CHAOS_INJECTED_ERROR code
+// and the HTTP status in their extensions; resets close HTTP/1 connections
+// (the handler must be wrapped by chaos.Resettable) and fail the operation
+// otherwise; the bandwidth delays every response by its encoded size.
+type Chaos struct {
+	Engine *chaos.Engine
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationInterceptor
+} = Chaos{}
+
+func (Chaos) ExtensionName() string {
+	return "Chaos"
+}
+
+func (Chaos) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (c Chaos) InterceptOperation(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+	d := c.Engine.Decide(operationKeys(graphql.GetOperationContext(ctx))...)
+	if err := d.Wait(ctx); err != nil {
+		return graphql.OneShot(graphql.ErrorResponse(ctx, "%s", err))
+	}
+	switch {
+	case d.Reset:
+		chaos.Reset(ctx)
+		err := gqlerror.Errorf("chaos: connection reset")
+		errcode.Set(err, errChaosReset)
+		return graphql.OneShot(&graphql.Response{Errors: gqlerror.List{err}})
+	case d.Status != 0:
+		err := gqlerror.Errorf("chaos: injected error")
+		err.Extensions = map[string]any{"code": errChaosError, "status": d.Status}
+		return graphql.OneShot(&graphql.Response{Errors: gqlerror.List{err}})
+	}
+
+	responses := next(ctx)
+	if d.Bandwidth <= 0 {
+		return responses
+	}
+	return func(ctx context.Context) *graphql.Response {
+		resp := responses(ctx)
+		if resp != nil {
+			b, _ := json.Marshal(resp)
+			_ = d.Throttle(ctx, int64(len(b)))
+		}
+		return resp
+	}
+}
+
+// operationKeys returns the keys matched by chaos rules: the operation name
+// and the operation type followed by the name
+func operationKeys(opCtx *graphql.OperationContext) []string {
+	if opCtx.Operation == nil {
+		return []string{opCtx.OperationName}
+	}
+	op := opCtx.Operation
+	return []string{op.Name, strings.TrimSpace(string(op.Operation) + " " + op.Name)}
+}
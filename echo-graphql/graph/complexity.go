@@ -0,0 +1,55 @@
+package graph
+
+import (
+	"context"
+	"math"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+)
+
+// NewComplexityRoot returns complexity functions that scale list fields by
+// the number of items they return, so large lists cost more than one field.
+func NewComplexityRoot() ComplexityRoot {
+	var c ComplexityRoot
+	c.Query.EchoList = func(childComplexity int, message string, count int) int {
+		return 1 + max(count, 0)*childComplexity
+	}
+	c.Query.EchoConnection = func(childComplexity int, message string, count int, first *int, after *string, last *int, before *string) int {
+		size := max(count, 0)
+		if first != nil {
+			size = min(size, max(*first, 0))
+		}
+		if last != nil {
+			size = min(size, max(*last, 0))
+		}
+		return 1 + size*childComplexity
+	}
+	c.Query.EchoNested = func(childComplexity int, message string, depth int) int {
+		return 1 + max(depth, 1)*childComplexity
+	}
+	c.Query.EchoHuge = func(childComplexity int, sizeKb int) int {
+		return 1 + max(sizeKb, 0)
+	}
+	c.Query.EchoDeep = func(childComplexity int, depth int, arrays bool) int {
+		return 1 + max(depth, 0)
+	}
+	c.DeferredEcho.Items = func(childComplexity int, count int, delayMs int) int {
+		return 1 + max(count, 0)*childComplexity
+	}
+	return c
+}
+
+// NewComplexityLimit returns an extension rejecting operations whose
+// complexity exceeds limit. A non-positive limit only records the computed
+// complexity for the queryCost field.
+func NewComplexityLimit(limit int) *extension.ComplexityLimit {
+	if limit <= 0 {
+		return &extension.ComplexityLimit{
+			Func: func(context.Context, *graphql.OperationContext) int {
+				return math.MaxInt
+			},
+		}
+	}
+	return extension.FixedComplexityLimit(limit)
+}
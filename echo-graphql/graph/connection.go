@@ -0,0 +1,113 @@
+package graph
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+
+	"github.com/probitas-test/echo-servers/echo-graphql/graph/model"
+)
+
+const (
+	errBadUserInput = "BAD_USER_INPUT"
+	cursorPrefix    = "EchoListItem:"
+
+	// maxListCount caps the items of echoList, echoConnection and
+	// DeferredEcho.items
+	maxListCount = 10000
+)
+
+// listCount returns a requested item count, 0 when it is negative, and a
+// BAD_USER_INPUT error when it exceeds maxListCount
+func listCount(count int) (int, error) {
+	if count > maxListCount {
+		return 0, badUserInput(fmt.Sprintf("count must not exceed %d", maxListCount))
+	}
+	return max(count, 0), nil
+}
+
+// encodeCursor returns the opaque cursor of the item at index
+func encodeCursor(index int) string {
+	return base64.StdEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(index)))
+}
+
+// decodeCursor returns the item index encoded in cursor
+func decodeCursor(cursor string) (int, error) {
+	raw, err := base64.StdEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(raw), cursorPrefix) {
+		return 0, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	index, err := strconv.Atoi(strings.TrimPrefix(string(raw), cursorPrefix))
+	if err != nil || index < 0 {
+		return 0, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	return index, nil
+}
+
+// newConnection slices count items following the Relay cursor connections
+// algorithm: after/before narrow the range first, then first/last take from
+// its start/end.
+func newConnection(message string, count int, first *int, after *string, last *int, before *string) (*model.EchoConnection, error) {
+	count, err := listCount(count)
+	if err != nil {
+		return nil, err
+	}
+	start, end := 0, count
+
+	if after != nil {
+		index, err := decodeCursor(*after)
+		if err != nil {
+			return nil, badUserInput(err.Error())
+		}
+		start = min(index+1, end)
+	}
+	if before != nil {
+		index, err := decodeCursor(*before)
+		if err != nil {
+			return nil, badUserInput(err.Error())
+		}
+		end = max(min(index, end), start)
+	}
+	if first != nil {
+		if *first < 0 {
+			return nil, badUserInput("first must not be negative")
+		}
+		end = min(start+*first, end)
+	}
+	if last != nil {
+		if *last < 0 {
+			return nil, badUserInput("last must not be negative")
+		}
+		start = max(end-*last, start)
+	}
+
+	conn := &model.EchoConnection{
+		Edges: make([]*model.EchoEdge, 0, end-start),
+		PageInfo: &model.PageInfo{
+			HasPreviousPage: start > 0,
+			HasNextPage:     end < count,
+		},
+		TotalCount: count,
+	}
+	for i := start; i < end; i++ {
+		conn.Edges = append(conn.Edges, &model.EchoEdge{
+			Cursor: encodeCursor(i),
+			Node:   &model.EchoListItem{Index: i, Message: message},
+		})
+	}
+	if len(conn.Edges) > 0 {
+		conn.PageInfo.StartCursor = &conn.Edges[0].Cursor
+		conn.PageInfo.EndCursor = &conn.Edges[len(conn.Edges)-1].Cursor
+	}
+	return conn, nil
+}
+
+func badUserInput(message string) *gqlerror.Error {
+	err := gqlerror.Errorf("%s", message)
+	errcode.Set(err, errBadUserInput)
+	return err
+}
@@ -0,0 +1,143 @@
+package graph
+
+import (
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// CORS answers preflight requests and adds CORS headers for allowed origins.
+// "*" in AllowedOrigins allows any origin and "*" in AllowedHeaders allows any
+// requested header. With AllowCredentials the request origin is echoed
+// instead of "*", as browsers reject credentialed wildcard responses.
+type CORS struct {
+	AllowedOrigins   []string
+	AllowedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+func (c CORS) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Origin")
+
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+		if !c.originAllowed(origin) {
+			if preflight {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		allowOrigin := origin
+		if slices.Contains(c.AllowedOrigins, "*") && !c.AllowCredentials {
+			allowOrigin = "*"
+		}
+		w.Header().Set("Access-Control-Allow-Origin", allowOrigin)
+		if c.AllowCredentials {
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if !preflight {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Access-Control-Request-Method")
+		w.Header().Add("Vary", "Access-Control-Request-Headers")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+		if headers := c.allowHeaders(r.Header.Get("Access-Control-Request-Headers")); headers != "" {
+			w.Header().Set("Access-Control-Allow-Headers", headers)
+		}
+		if c.MaxAge > 0 {
+			w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(c.MaxAge.Seconds())))
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func (c CORS) originAllowed(origin string) bool {
+	for _, allowed := range c.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowHeaders returns the Access-Control-Allow-Headers value for the
+// requested headers: all of them for "*", otherwise the allowed ones
+func (c CORS) allowHeaders(requested string) string {
+	if slices.Contains(c.AllowedHeaders, "*") {
+		return requested
+	}
+	var allowed []string
+	for _, header := range strings.Split(requested, ",") {
+		header = strings.TrimSpace(header)
+		if header != "" && slices.ContainsFunc(c.AllowedHeaders, func(h string) bool { return strings.EqualFold(h, header) }) {
+			allowed = append(allowed, header)
+		}
+	}
+	return strings.Join(allowed, ", ")
+}
+
+// CSRFPrevention blocks requests a browser would send cross-origin without a
+// CORS preflight, following Apollo Server's CSRF prevention: a request must
+// either have a Content-Type other than application/x-www-form-urlencoded,
+// multipart/form-data and text/plain, or carry a non-empty value for one of
+// Headers. WebSocket upgrades and CORS preflights are not affected.
+type CSRFPrevention struct {
+	Headers []string
+}
+
+// simpleContentTypes are the content types of CORS-safelisted requests
+var simpleContentTypes = []string{"application/x-www-form-urlencoded", "multipart/form-data", "text/plain"}
+
+func (c CSRFPrevention) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodOptions || r.Header.Get("Upgrade") != "" || !c.preflightless(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		for _, header := range c.Headers {
+			if r.Header.Get(header) != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		err := gqlerror.Errorf("This operation has been blocked as a potential Cross-Site Request Forgery (CSRF). "+
+			"Please either specify a 'content-type' header (with a type that is not one of %s) "+
+			"or provide a non-empty value for one of the following headers: %s",
+			strings.Join(simpleContentTypes, ", "), strings.Join(c.Headers, ", "))
+		err.Extensions = map[string]any{"code": "BAD_REQUEST"}
+		writeGraphQLError(w, http.StatusBadRequest, err)
+	})
+}
+
+// preflightless reports whether a browser could send r cross-origin without
+// a preflight, judging by its Content-Type
+func (c CSRFPrevention) preflightless(r *http.Request) bool {
+	contentType := r.Header.Get("Content-Type")
+	if contentType == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return true
+	}
+	return slices.Contains(simpleContentTypes, strings.ToLower(mediaType))
+}
@@ -0,0 +1,95 @@
+package graph
+
+import (
+	"context"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+const (
+	depthLimitExtension = "DepthLimit"
+	errDepthLimit       = "DEPTH_LIMIT_EXCEEDED"
+)
+
+// DepthLimit rejects operations whose field nesting exceeds Limit. A
+// non-positive Limit only records the computed depth for the queryCost field.
+// Introspection fields (__schema, __type, __typename) are not counted.
+type DepthLimit struct {
+	Limit int
+}
+
+// DepthStats holds the computed depth of an operation
+type DepthStats struct {
+	Depth      int
+	DepthLimit int
+}
+
+var _ interface {
+	graphql.OperationContextMutator
+	graphql.HandlerExtension
+} = DepthLimit{}
+
+func (d DepthLimit) ExtensionName() string {
+	return depthLimitExtension
+}
+
+func (d DepthLimit) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (d DepthLimit) MutateOperationContext(ctx context.Context, opCtx *graphql.OperationContext) *gqlerror.Error {
+	op := opCtx.Doc.Operations.ForName(opCtx.OperationName)
+	if op == nil {
+		return nil
+	}
+	depth := selectionDepth(op.SelectionSet)
+
+	opCtx.Stats.SetExtension(depthLimitExtension, &DepthStats{
+		Depth:      depth,
+		DepthLimit: d.Limit,
+	})
+
+	if d.Limit > 0 && depth > d.Limit {
+		err := gqlerror.Errorf("operation has depth %d, which exceeds the limit of %d", depth, d.Limit)
+		errcode.Set(err, errDepthLimit)
+		return err
+	}
+	return nil
+}
+
+// GetDepthStats returns the depth computed for the current operation
+func GetDepthStats(ctx context.Context) *DepthStats {
+	if !graphql.HasOperationContext(ctx) {
+		return nil
+	}
+	s, _ := graphql.GetOperationContext(ctx).Stats.GetExtension(depthLimitExtension).(*DepthStats)
+	return s
+}
+
+// selectionDepth returns the deepest field nesting in set. Fragments do not
+// add a level of their own.
+func selectionDepth(set ast.SelectionSet) int {
+	depth := 0
+	for _, selection := range set {
+		var d int
+		switch sel := selection.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(sel.Name, "__") {
+				continue
+			}
+			d = 1 + selectionDepth(sel.SelectionSet)
+		case *ast.InlineFragment:
+			d = selectionDepth(sel.SelectionSet)
+		case *ast.FragmentSpread:
+			if sel.Definition != nil {
+				d = selectionDepth(sel.Definition.SelectionSet)
+			}
+		}
+		depth = max(depth, d)
+	}
+	return depth
+}
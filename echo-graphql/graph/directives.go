@@ -0,0 +1,36 @@
+package graph
+
+import (
+	"context"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+)
+
+// NewDirectiveRoot returns the implementations of the schema's executable directives
+func NewDirectiveRoot() DirectiveRoot {
+	return DirectiveRoot{
+		Delay:  delayDirective,
+		Stream: streamDirective,
+	}
+}
+
+// delayDirective waits ms milliseconds before resolving the field. The wait is
+// aborted when the request is canceled.
+func delayDirective(ctx context.Context, obj any, next graphql.Resolver, ms int) (any, error) {
+	if ms > 0 {
+		select {
+		case <-time.After(time.Duration(ms) * time.Millisecond):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return next(ctx)
+}
+
+// streamDirective accepts @stream but resolves the list eagerly. gqlgen has no
+// incremental list delivery, and the incremental delivery RFC allows servers to
+// ignore @stream and return the complete list in the initial payload.
+func streamDirective(ctx context.Context, obj any, next graphql.Resolver, ifArg *bool, label *string, initialCount *int) (any, error) {
+	return next(ctx)
+}
@@ -0,0 +1,35 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver
+// implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.84
+
+import (
+	"context"
+
+	"github.com/probitas-test/echo-servers/echo-graphql/graph/model"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// FindMessageByID resolves a Message entity reference for a federated gateway
+func (r *entityResolver) FindMessageByID(ctx context.Context, id string) (*model.Message, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	msg, ok := r.messages[id]
+	if !ok {
+		return nil, &gqlerror.Error{
+			Message: "message not found",
+			Extensions: map[string]interface{}{
+				"code": "NOT_FOUND",
+				"id":   id,
+			},
+		}
+	}
+	return msg, nil
+}
+
+func (r *Resolver) Entity() EntityResolver { return &entityResolver{r} }
+
+type entityResolver struct{ *Resolver }
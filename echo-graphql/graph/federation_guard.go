@@ -0,0 +1,69 @@
+package graph
+
+import (
+	"context"
+	"slices"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+const errFederationDisabled = "FEDERATION_DISABLED"
+
+// FederationGuard rejects operations selecting the Apollo Federation root
+// fields (_service, _entities). The fields are always part of the generated
+// schema; registering the guard keeps the server a plain (non-subgraph)
+// GraphQL server.
+type FederationGuard struct{}
+
+var _ interface {
+	graphql.OperationContextMutator
+	graphql.HandlerExtension
+} = FederationGuard{}
+
+func (FederationGuard) ExtensionName() string {
+	return "FederationGuard"
+}
+
+func (FederationGuard) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (FederationGuard) MutateOperationContext(ctx context.Context, opCtx *graphql.OperationContext) *gqlerror.Error {
+	op := opCtx.Doc.Operations.ForName(opCtx.OperationName)
+	if op == nil || op.Operation != ast.Query {
+		return nil
+	}
+	if name := selectedField(op.SelectionSet, "_service", "_entities"); name != "" {
+		err := gqlerror.Errorf("%s is unavailable: federation subgraph mode is disabled", name)
+		errcode.Set(err, errFederationDisabled)
+		return err
+	}
+	return nil
+}
+
+// selectedField returns the first of names selected at the top level of set
+// (looking through fragments), if any
+func selectedField(set ast.SelectionSet, names ...string) string {
+	for _, selection := range set {
+		switch sel := selection.(type) {
+		case *ast.Field:
+			if slices.Contains(names, sel.Name) {
+				return sel.Name
+			}
+		case *ast.InlineFragment:
+			if name := selectedField(sel.SelectionSet, names...); name != "" {
+				return name
+			}
+		case *ast.FragmentSpread:
+			if sel.Definition != nil {
+				if name := selectedField(sel.Definition.SelectionSet, names...); name != "" {
+					return name
+				}
+			}
+		}
+	}
+	return ""
+}
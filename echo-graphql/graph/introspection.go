@@ -0,0 +1,86 @@
+package graph
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+const (
+	errIntrospectionDisabled     = "INTROSPECTION_DISABLED"
+	errIntrospectionUnauthorized = "INTROSPECTION_UNAUTHORIZED"
+)
+
+// IntrospectionPolicy controls access to the introspection fields
+// (__schema, __type) and the _schema SDL field. It replaces extension.Introspection so clients can be
+// tested against locked-down servers: introspection can be disabled entirely
+// or require a token header. __typename is always available.
+type IntrospectionPolicy struct {
+	// Enabled allows introspection (subject to Token)
+	Enabled bool
+	// Switch, if set, replaces Enabled and is read on every request, for
+	// toggling introspection through the admin API
+	Switch func() bool
+	// Header carries the token when Token is set
+	Header string
+	// Token, if non-empty, must be sent in Header to introspect
+	Token string
+}
+
+var _ interface {
+	graphql.OperationContextMutator
+	graphql.HandlerExtension
+} = IntrospectionPolicy{}
+
+func (IntrospectionPolicy) ExtensionName() string {
+	return "IntrospectionPolicy"
+}
+
+func (IntrospectionPolicy) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (p IntrospectionPolicy) MutateOperationContext(ctx context.Context, opCtx *graphql.OperationContext) *gqlerror.Error {
+	if p.enabled() && p.authorized(opCtx.Headers) {
+		opCtx.DisableIntrospection = false
+		return nil
+	}
+
+	op := opCtx.Doc.Operations.ForName(opCtx.OperationName)
+	if op == nil {
+		return nil
+	}
+	name := selectedField(op.SelectionSet, "__schema", "__type", "_schema")
+	if name == "" {
+		return nil
+	}
+
+	var err *gqlerror.Error
+	if !p.enabled() {
+		err = gqlerror.Errorf("%s is unavailable: introspection is disabled", name)
+		errcode.Set(err, errIntrospectionDisabled)
+	} else {
+		err = gqlerror.Errorf("%s is unavailable: introspection requires a valid %s header", name, p.Header)
+		errcode.Set(err, errIntrospectionUnauthorized)
+	}
+	return err
+}
+
+func (p IntrospectionPolicy) enabled() bool {
+	if p.Switch != nil {
+		return p.Switch()
+	}
+	return p.Enabled
+}
+
+func (p IntrospectionPolicy) authorized(headers http.Header) bool {
+	if p.Token == "" {
+		return true
+	}
+	got := headers.Get(p.Header)
+	return subtle.ConstantTimeCompare([]byte(got), []byte(p.Token)) == 1
+}
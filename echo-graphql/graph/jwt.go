@@ -0,0 +1,97 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/probitas-test/echo-servers/echo-graphql/graph/model"
+	"github.com/probitas-test/echo-servers/shared/clock"
+	"github.com/probitas-test/echo-servers/shared/jwt"
+)
+
+// parseBearerToken decodes the JWT of a "Bearer" Authorization header
+// without verifying it, expired on clk. It returns nil if the header carries
+// no JWT.
+func parseBearerToken(authorization string, clk *clock.Clock) *model.BearerToken {
+	scheme, token, ok := strings.Cut(authorization, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return nil
+	}
+	token = strings.TrimSpace(token)
+
+	parsed, err := jwt.Parse(token)
+	if err != nil {
+		return nil
+	}
+	claims := parsed.Claims
+
+	bearer := &model.BearerToken{
+		Token:    token,
+		Header:   parsed.Header,
+		Claims:   claims,
+		Subject:  stringClaim(claims, "sub"),
+		Issuer:   stringClaim(claims, "iss"),
+		Audience: stringsClaim(claims, "aud"),
+		Scopes:   scopesClaim(claims),
+	}
+	if expiresAt, ok := parsed.Time("exp"); ok {
+		expires := model.DateTime(expiresAt.Format(time.RFC3339))
+		bearer.ExpiresAt = &expires
+		bearer.Expired = !clk.Now().Before(expiresAt)
+	}
+	return bearer
+}
+
+func stringClaim(claims map[string]any, name string) *string {
+	if s, ok := claims[name].(string); ok {
+		return &s
+	}
+	return nil
+}
+
+// stringsClaim returns a claim that may be a string or a list of strings
+// (such as aud)
+func stringsClaim(claims map[string]any, name string) []string {
+	switch v := claims[name].(type) {
+	case string:
+		return []string{v}
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return []string{}
+}
+
+// scopesClaim returns the space-delimited scope claim (RFC 9068), falling
+// back to the scp list used by some providers
+func scopesClaim(claims map[string]any) []string {
+	if scope, ok := claims["scope"].(string); ok {
+		return strings.Fields(scope)
+	}
+	return stringsClaim(claims, "scp")
+}
+
+// verifyBearerToken records whether the signature of bearer verifies against
+// jwks (nil when no key set is configured)
+func verifyBearerToken(ctx context.Context, bearer *model.BearerToken, jwks *jwt.KeySet) {
+	err := errors.New("no JWKS configured (set JWT_JWKS_URL)")
+	if jwks != nil {
+		var parsed *jwt.Token
+		if parsed, err = jwt.Parse(bearer.Token); err == nil {
+			err = jwks.Verify(ctx, parsed)
+		}
+	}
+	if err != nil {
+		msg := err.Error()
+		bearer.VerificationError = &msg
+		return
+	}
+	bearer.Verified = true
+}
@@ -2,7 +2,10 @@ package model
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"net/http"
+	"strconv"
 )
 
 type Message struct {
@@ -11,6 +14,9 @@ type Message struct {
 	CreatedAt string `json:"createdAt"`
 }
 
+// IsEntity marks Message as an Apollo Federation entity
+func (Message) IsEntity() {}
+
 type EchoResult struct {
 	Message *string `json:"message,omitempty"`
 	Error   *string `json:"error,omitempty"`
@@ -21,6 +27,27 @@ type Headers struct {
 	Request *http.Request `json:"-"`
 }
 
+// BearerToken is the decoded JWT of a Bearer Authorization header
+type BearerToken struct {
+	Token             string    `json:"token"`
+	Header            any       `json:"header"`
+	Claims            any       `json:"claims"`
+	Subject           *string   `json:"subject,omitempty"`
+	Issuer            *string   `json:"issuer,omitempty"`
+	Audience          []string  `json:"audience"`
+	Scopes            []string  `json:"scopes"`
+	ExpiresAt         *DateTime `json:"expiresAt,omitempty"`
+	Expired           bool      `json:"expired"`
+	Verified          bool      `json:"verified"`
+	VerificationError *string   `json:"verificationError,omitempty"`
+}
+
+// Claim returns a single claim by name (nil if absent)
+func (b *BearerToken) Claim(name string) any {
+	claims, _ := b.Claims.(map[string]any)
+	return claims[name]
+}
+
 // HeaderEntry represents a single header key-value pair
 type HeaderEntry struct {
 	Name  string `json:"name"`
@@ -39,6 +66,213 @@ type EchoListItem struct {
 	Message string `json:"message"`
 }
 
+// EchoConnection is a Relay-style connection over echo list items
+type EchoConnection struct {
+	Edges      []*EchoEdge `json:"edges"`
+	PageInfo   *PageInfo   `json:"pageInfo"`
+	TotalCount int         `json:"totalCount"`
+}
+
+// EchoEdge is an echo list item with its opaque cursor
+type EchoEdge struct {
+	Cursor string        `json:"cursor"`
+	Node   *EchoListItem `json:"node"`
+}
+
+// PageInfo describes the position of a connection page
+type PageInfo struct {
+	HasNextPage     bool    `json:"hasNextPage"`
+	HasPreviousPage bool    `json:"hasPreviousPage"`
+	StartCursor     *string `json:"startCursor,omitempty"`
+	EndCursor       *string `json:"endCursor,omitempty"`
+}
+
+// DeferredEcho represents an object with fields resolving at different speeds
+type DeferredEcho struct {
+	Message string `json:"message"`
+}
+
+// UploadedFile represents metadata echoed back for an uploaded file
+type UploadedFile struct {
+	Filename    string  `json:"filename"`
+	Size        int     `json:"size"`
+	ContentType *string `json:"contentType,omitempty"`
+	Sha256      string  `json:"sha256"`
+}
+
+// APQStats reports Automatic Persisted Query cache activity
+type APQStats struct {
+	Enabled       bool `json:"enabled"`
+	Hits          int  `json:"hits"`
+	Misses        int  `json:"misses"`
+	Registrations int  `json:"registrations"`
+}
+
+// QueryCost reports the computed complexity and depth of an operation
+type QueryCost struct {
+	Complexity      int  `json:"complexity"`
+	ComplexityLimit *int `json:"complexityLimit,omitempty"`
+	Depth           int  `json:"depth"`
+	DepthLimit      *int `json:"depthLimit,omitempty"`
+}
+
+// EchoPriority is an enum for input serialization tests
+type EchoPriority string
+
+const (
+	EchoPriorityLow    EchoPriority = "LOW"
+	EchoPriorityMedium EchoPriority = "MEDIUM"
+	EchoPriorityHigh   EchoPriority = "HIGH"
+)
+
+// IsValid reports whether p is a defined EchoPriority value
+func (p EchoPriority) IsValid() bool {
+	switch p {
+	case EchoPriorityLow, EchoPriorityMedium, EchoPriorityHigh:
+		return true
+	}
+	return false
+}
+
+// UnmarshalGQL validates an EchoPriority enum value
+func (p *EchoPriority) UnmarshalGQL(v any) error {
+	s, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+	*p = EchoPriority(s)
+	if !p.IsValid() {
+		return fmt.Errorf("%s is not a valid EchoPriority", s)
+	}
+	return nil
+}
+
+// MarshalGQL writes the enum value as a JSON string
+func (p EchoPriority) MarshalGQL(w io.Writer) {
+	_, _ = io.WriteString(w, strconv.Quote(string(p)))
+}
+
+// ShapeKind selects the concrete type returned by the shape queries
+type ShapeKind string
+
+const (
+	ShapeKindCircle ShapeKind = "CIRCLE"
+	ShapeKindSquare ShapeKind = "SQUARE"
+)
+
+// IsValid reports whether k is a defined ShapeKind value
+func (k ShapeKind) IsValid() bool {
+	switch k {
+	case ShapeKindCircle, ShapeKindSquare:
+		return true
+	}
+	return false
+}
+
+// UnmarshalGQL validates a ShapeKind enum value
+func (k *ShapeKind) UnmarshalGQL(v any) error {
+	s, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+	*k = ShapeKind(s)
+	if !k.IsValid() {
+		return fmt.Errorf("%s is not a valid ShapeKind", s)
+	}
+	return nil
+}
+
+// MarshalGQL writes the enum value as a JSON string
+func (k ShapeKind) MarshalGQL(w io.Writer) {
+	_, _ = io.WriteString(w, strconv.Quote(string(k)))
+}
+
+// Shape is implemented by the members of the Shape interface
+type Shape interface {
+	IsShape()
+}
+
+// ShapeResult is implemented by the members of the ShapeResult union
+type ShapeResult interface {
+	IsShapeResult()
+}
+
+// ShapeInput selects a shape returned by echoShapes
+type ShapeInput struct {
+	Kind ShapeKind `json:"kind"`
+	Size float64   `json:"size"`
+	ID   *string   `json:"id,omitempty"`
+}
+
+// Circle is a Shape sized by its radius
+type Circle struct {
+	ID     string    `json:"id"`
+	Kind   ShapeKind `json:"kind"`
+	Area   float64   `json:"area"`
+	Radius float64   `json:"radius"`
+}
+
+func (Circle) IsShape()       {}
+func (Circle) IsShapeResult() {}
+
+// Square is a Shape sized by its side length
+type Square struct {
+	ID   string    `json:"id"`
+	Kind ShapeKind `json:"kind"`
+	Area float64   `json:"area"`
+	Side float64   `json:"side"`
+}
+
+func (Square) IsShape()       {}
+func (Square) IsShapeResult() {}
+
+// EchoValue carries every supported input kind. It backs both the EchoInput
+// input type and the EchoOutput type so inputs are echoed back verbatim.
+type EchoValue struct {
+	String   *string       `json:"string,omitempty"`
+	Int      *int          `json:"int,omitempty"`
+	Float    *float64      `json:"float,omitempty"`
+	Boolean  *bool         `json:"boolean,omitempty"`
+	ID       *string       `json:"id,omitempty"`
+	Priority *EchoPriority `json:"priority,omitempty"`
+	Tags     []string      `json:"tags,omitempty"`
+	DateTime *DateTime     `json:"dateTime,omitempty"`
+	JSON     any           `json:"json,omitempty"`
+	BigInt   *BigInt       `json:"bigInt,omitempty"`
+	Bytes    Bytes         `json:"bytes,omitempty"`
+	Nested   *EchoValue    `json:"nested,omitempty"`
+	Children []*EchoValue  `json:"children,omitempty"`
+}
+
+// EchoErrorInput specifies an error returned by echoErrors
+type EchoErrorInput struct {
+	Message    string   `json:"message"`
+	Code       *string  `json:"code,omitempty"`
+	Extensions any      `json:"extensions,omitempty"`
+	Path       []string `json:"path,omitempty"`
+}
+
+// TopicEvent is an event published to a topic
+type TopicEvent struct {
+	ID          int      `json:"id"`
+	Topic       string   `json:"topic"`
+	Payload     any      `json:"payload"`
+	PublishedAt DateTime `json:"publishedAt"`
+}
+
+// OperationRecord is an executed operation captured by the operation log
+type OperationRecord struct {
+	ID            int      `json:"id"`
+	OperationName *string  `json:"operationName"`
+	OperationType *string  `json:"operationType"`
+	Query         string   `json:"query"`
+	Variables     any      `json:"variables"`
+	Extensions    any      `json:"extensions"`
+	StartedAt     DateTime `json:"startedAt"`
+	DurationMs    float64  `json:"durationMs"`
+	Errors        []string `json:"errors"`
+}
+
 // Key for storing http.Request in context
 type contextKey string
 
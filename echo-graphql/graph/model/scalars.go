@@ -0,0 +1,88 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"time"
+)
+
+// DateTime is an RFC 3339 timestamp. The original string is kept so values
+// are echoed back verbatim (offset and fractional seconds included).
+type DateTime string
+
+// UnmarshalGQL validates an RFC 3339 timestamp
+func (d *DateTime) UnmarshalGQL(v any) error {
+	s, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("DateTime must be an RFC 3339 string, got %T", v)
+	}
+	if _, err := time.Parse(time.RFC3339Nano, s); err != nil {
+		return fmt.Errorf("DateTime must be an RFC 3339 string: %w", err)
+	}
+	*d = DateTime(s)
+	return nil
+}
+
+// MarshalGQL writes the timestamp as a JSON string
+func (d DateTime) MarshalGQL(w io.Writer) {
+	_, _ = io.WriteString(w, strconv.Quote(string(d)))
+}
+
+// BigInt is an arbitrary-precision integer. It accepts a JSON number or a
+// decimal string and is always serialized as a decimal string, since JSON
+// numbers lose precision beyond 2^53 in most clients.
+type BigInt string
+
+// UnmarshalGQL parses an integer of any size
+func (b *BigInt) UnmarshalGQL(v any) error {
+	var s string
+	switch v := v.(type) {
+	case string:
+		s = v
+	case json.Number:
+		s = v.String()
+	case int:
+		s = strconv.Itoa(v)
+	case int64:
+		s = strconv.FormatInt(v, 10)
+	default:
+		return fmt.Errorf("BigInt must be an integer or decimal string, got %T", v)
+	}
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return fmt.Errorf("BigInt must be an integer, got %q", s)
+	}
+	*b = BigInt(n.String())
+	return nil
+}
+
+// MarshalGQL writes the integer as a JSON string
+func (b BigInt) MarshalGQL(w io.Writer) {
+	_, _ = io.WriteString(w, strconv.Quote(string(b)))
+}
+
+// Bytes is binary data encoded as standard base64 (RFC 4648 with padding)
+type Bytes []byte
+
+// UnmarshalGQL decodes a base64 string
+func (b *Bytes) UnmarshalGQL(v any) error {
+	s, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("Bytes must be a base64 string, got %T", v)
+	}
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return fmt.Errorf("Bytes must be a base64 string: %w", err)
+	}
+	*b = data
+	return nil
+}
+
+// MarshalGQL writes the data as a base64 JSON string
+func (b Bytes) MarshalGQL(w io.Writer) {
+	_, _ = io.WriteString(w, strconv.Quote(base64.StdEncoding.EncodeToString(b)))
+}
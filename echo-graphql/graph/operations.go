@@ -0,0 +1,152 @@
+package graph
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+
+	"github.com/probitas-test/echo-servers/echo-graphql/graph/model"
+)
+
+const operationLogExtension = "OperationLog"
+
+// OperationLog records executed operations in a ring buffer so tests can
+// assert on what a client actually sent. Register it before other extensions
+// (e.g. APQ) to capture the request parameters before they are rewritten.
+type OperationLog struct {
+	mu      sync.Mutex
+	records []*model.OperationRecord
+	next    int
+	nextID  int
+}
+
+// pendingOperation holds the raw request parameters until the first response
+type pendingOperation struct {
+	params   graphql.RawParams
+	recorded bool
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationParameterMutator
+	graphql.ResponseInterceptor
+} = &OperationLog{}
+
+// NewOperationLog creates a log keeping the last size operations
+func NewOperationLog(size int) *OperationLog {
+	return &OperationLog{
+		records: make([]*model.OperationRecord, 0, size),
+		nextID:  1,
+	}
+}
+
+func (l *OperationLog) ExtensionName() string {
+	return operationLogExtension
+}
+
+func (l *OperationLog) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+// MutateOperationParameters captures the request parameters as sent
+func (l *OperationLog) MutateOperationParameters(ctx context.Context, rawParams *graphql.RawParams) *gqlerror.Error {
+	graphql.GetOperationContext(ctx).Stats.SetExtension(operationLogExtension, &pendingOperation{params: *rawParams})
+	return nil
+}
+
+// InterceptResponse records the operation once its first response is ready.
+// Later responses of subscriptions and incremental delivery are not recorded.
+func (l *OperationLog) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	resp := next(ctx)
+	if resp == nil || !graphql.HasOperationContext(ctx) {
+		return resp
+	}
+
+	opCtx := graphql.GetOperationContext(ctx)
+	pending, _ := opCtx.Stats.GetExtension(operationLogExtension).(*pendingOperation)
+	if pending == nil || pending.recorded {
+		return resp
+	}
+	pending.recorded = true
+
+	start := opCtx.Stats.OperationStart
+	if start.IsZero() {
+		start = time.Now()
+	}
+	record := &model.OperationRecord{
+		Query:      pending.params.Query,
+		StartedAt:  model.DateTime(start.UTC().Format(time.RFC3339Nano)),
+		DurationMs: float64(time.Since(start).Microseconds()) / 1000,
+		Errors:     make([]string, 0, len(resp.Errors)),
+	}
+	if pending.params.OperationName != "" {
+		name := pending.params.OperationName
+		record.OperationName = &name
+	}
+	if pending.params.Variables != nil {
+		record.Variables = pending.params.Variables
+	}
+	if pending.params.Extensions != nil {
+		record.Extensions = pending.params.Extensions
+	}
+	if opCtx.Operation != nil {
+		operationType := string(opCtx.Operation.Operation)
+		record.OperationType = &operationType
+	}
+	for _, err := range resp.Errors {
+		record.Errors = append(record.Errors, err.Message)
+	}
+
+	l.add(record)
+	return resp
+}
+
+func (l *OperationLog) add(record *model.OperationRecord) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	record.ID = l.nextID
+	l.nextID++
+
+	if len(l.records) < cap(l.records) {
+		l.records = append(l.records, record)
+		return
+	}
+	if len(l.records) == 0 {
+		return
+	}
+	l.records[l.next] = record
+	l.next = (l.next + 1) % len(l.records)
+}
+
+// Last returns up to limit recorded operations, newest first. A non-positive
+// limit returns all of them.
+func (l *OperationLog) Last(limit int) []*model.OperationRecord {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	n := len(l.records)
+	if limit <= 0 || limit > n {
+		limit = n
+	}
+	result := make([]*model.OperationRecord, 0, limit)
+	for i := 0; i < limit; i++ {
+		// l.next is the oldest entry once the buffer is full
+		result = append(result, l.records[(l.next+n-1-i)%n])
+	}
+	return result
+}
+
+// Clear removes all recorded operations and returns how many were removed
+func (l *OperationLog) Clear() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	n := len(l.records)
+	l.records = l.records[:0]
+	l.next = 0
+	return n
+}
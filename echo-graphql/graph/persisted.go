@@ -0,0 +1,193 @@
+package graph
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+const errPersistedQueryNotFound = "PERSISTED_QUERY_NOT_FOUND"
+
+// PersistedQuery is an allowlisted operation, in the shape of an Apollo
+// persisted query manifest entry
+type PersistedQuery struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+	Type string `json:"type,omitempty"`
+	Body string `json:"body"`
+}
+
+// PersistedQueries is an operation allowlist. Registered as an extension, only
+// allowlisted operations may execute: requests either send the operation id
+// as extensions.persistedQuery.sha256Hash, or the full text of an allowlisted
+// operation. Anything else fails with PERSISTED_QUERY_NOT_FOUND.
+type PersistedQueries struct {
+	mu      sync.RWMutex
+	byID    map[string]*PersistedQuery
+	byQuery map[string]*PersistedQuery
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationParameterMutator
+} = &PersistedQueries{}
+
+// NewPersistedQueries creates an empty allowlist
+func NewPersistedQueries() *PersistedQueries {
+	return &PersistedQueries{
+		byID:    make(map[string]*PersistedQuery),
+		byQuery: make(map[string]*PersistedQuery),
+	}
+}
+
+func (p *PersistedQueries) ExtensionName() string {
+	return "PersistedQueries"
+}
+
+func (p *PersistedQueries) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+// MutateOperationParameters replaces a persisted query id with the
+// allowlisted operation and rejects operations that are not allowlisted
+func (p *PersistedQueries) MutateOperationParameters(ctx context.Context, rawParams *graphql.RawParams) *gqlerror.Error {
+	if id := persistedQueryID(rawParams.Extensions); id != "" {
+		query, ok := p.Get(id)
+		if !ok {
+			err := gqlerror.Errorf("PersistedQueryNotFound")
+			errcode.Set(err, errPersistedQueryNotFound)
+			return err
+		}
+		rawParams.Query = query.Body
+		return nil
+	}
+
+	p.mu.RLock()
+	_, ok := p.byQuery[rawParams.Query]
+	p.mu.RUnlock()
+	if !ok {
+		err := gqlerror.Errorf("operation is not in the persisted query allowlist")
+		errcode.Set(err, errPersistedQueryNotFound)
+		return err
+	}
+	return nil
+}
+
+// persistedQueryID returns extensions.persistedQuery.sha256Hash, if any
+func persistedQueryID(extensions map[string]any) string {
+	persisted, _ := extensions["persistedQuery"].(map[string]any)
+	id, _ := persisted["sha256Hash"].(string)
+	return id
+}
+
+// Register adds queries to the allowlist. Entries without an id are keyed
+// by the SHA-256 hash of their body.
+func (p *PersistedQueries) Register(queries ...PersistedQuery) error {
+	for _, query := range queries {
+		if strings.TrimSpace(query.Body) == "" {
+			return fmt.Errorf("persisted query %q has an empty body", query.ID)
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, query := range queries {
+		if query.ID == "" {
+			sum := sha256.Sum256([]byte(query.Body))
+			query.ID = hex.EncodeToString(sum[:])
+		}
+		if previous, ok := p.byID[query.ID]; ok {
+			delete(p.byQuery, previous.Body)
+		}
+		p.byID[query.ID] = &query
+		p.byQuery[query.Body] = &query
+	}
+	return nil
+}
+
+// Get looks up an allowlisted query by id
+func (p *PersistedQueries) Get(id string) (PersistedQuery, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	query, ok := p.byID[id]
+	if !ok {
+		return PersistedQuery{}, false
+	}
+	return *query, true
+}
+
+// List returns the allowlisted queries ordered by id
+func (p *PersistedQueries) List() []PersistedQuery {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	queries := make([]PersistedQuery, 0, len(p.byID))
+	for _, query := range p.byID {
+		queries = append(queries, *query)
+	}
+	slices.SortFunc(queries, func(a, b PersistedQuery) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	return queries
+}
+
+// Clear removes every allowlisted query and returns how many were removed
+func (p *PersistedQueries) Clear() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n := len(p.byID)
+	clear(p.byID)
+	clear(p.byQuery)
+	return n
+}
+
+// LoadManifestFile registers the queries of a manifest file (see ParseManifest)
+func (p *PersistedQueries) LoadManifestFile(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	queries, err := ParseManifest(data)
+	if err != nil {
+		return 0, err
+	}
+	return len(queries), p.Register(queries...)
+}
+
+// ParseManifest decodes an Apollo persisted query manifest
+// ({"operations": [{"id", "name", "type", "body"}]}) or a flat map of
+// operation ids to query text (as generated by Relay and GraphQL Codegen)
+func ParseManifest(data []byte) ([]PersistedQuery, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+
+	if operations, ok := raw["operations"]; ok {
+		var queries []PersistedQuery
+		if err := json.Unmarshal(operations, &queries); err != nil {
+			return nil, fmt.Errorf("invalid manifest operations: %w", err)
+		}
+		return queries, nil
+	}
+
+	queries := make([]PersistedQuery, 0, len(raw))
+	for id, body := range raw {
+		var query string
+		if err := json.Unmarshal(body, &query); err != nil {
+			return nil, errors.New("invalid manifest: expected an operations list or a map of ids to queries")
+		}
+		queries = append(queries, PersistedQuery{ID: id, Body: query})
+	}
+	return queries, nil
+}
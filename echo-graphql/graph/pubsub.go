@@ -0,0 +1,143 @@
+package graph
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/probitas-test/echo-servers/echo-graphql/graph/model"
+)
+
+const (
+	// topicBufferSize is the number of undelivered events kept per
+	// subscriber; events published to a full subscriber are dropped
+	topicBufferSize = 64
+
+	topicWildcardOne  = "*"
+	topicWildcardRest = "#"
+)
+
+// topicSubscriber is a subscribe subscription and its topic pattern
+type topicSubscriber struct {
+	pattern []string
+	ch      chan *model.TopicEvent
+}
+
+// pubsub fans published events out to subscribers with matching topic
+// patterns. The zero value is ready to use.
+type pubsub struct {
+	mu          sync.RWMutex
+	nextID      int
+	subscribers []*topicSubscriber
+}
+
+// subscribe registers a subscriber for topics matching pattern
+func (p *pubsub) subscribe(pattern string) (*topicSubscriber, error) {
+	segments, err := parseTopicPattern(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	sub := &topicSubscriber{pattern: segments, ch: make(chan *model.TopicEvent, topicBufferSize)}
+	p.subscribers = append(p.subscribers, sub)
+	return sub, nil
+}
+
+// unsubscribe removes a subscriber and closes its channel
+func (p *pubsub) unsubscribe(sub *topicSubscriber) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i, s := range p.subscribers {
+		if s == sub {
+			p.subscribers = append(p.subscribers[:i], p.subscribers[i+1:]...)
+			close(sub.ch)
+			return
+		}
+	}
+}
+
+// publish delivers payload to every matching subscriber and returns how
+// many subscribers received it
+func (p *pubsub) publish(topic string, payload any) (int, error) {
+	segments, err := parseTopic(topic)
+	if err != nil {
+		return 0, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.nextID++
+	event := &model.TopicEvent{
+		ID:          p.nextID,
+		Topic:       topic,
+		Payload:     payload,
+		PublishedAt: model.DateTime(time.Now().UTC().Format(time.RFC3339Nano)),
+	}
+
+	delivered := 0
+	for _, sub := range p.subscribers {
+		if !matchTopic(sub.pattern, segments) {
+			continue
+		}
+		select {
+		case sub.ch <- event:
+			delivered++
+		default:
+		}
+	}
+	return delivered, nil
+}
+
+// parseTopic splits a concrete topic into its segments
+func parseTopic(topic string) ([]string, error) {
+	segments, err := splitTopic(topic)
+	if err != nil {
+		return nil, err
+	}
+	for _, segment := range segments {
+		if segment == topicWildcardOne || segment == topicWildcardRest {
+			return nil, fmt.Errorf("invalid topic %q: wildcards are only allowed in subscriptions", topic)
+		}
+	}
+	return segments, nil
+}
+
+// parseTopicPattern splits a subscription pattern into its segments
+func parseTopicPattern(pattern string) ([]string, error) {
+	segments, err := splitTopic(pattern)
+	if err != nil {
+		return nil, err
+	}
+	for i, segment := range segments {
+		if segment == topicWildcardRest && i != len(segments)-1 {
+			return nil, fmt.Errorf("invalid topic pattern %q: %q must be the last segment", pattern, topicWildcardRest)
+		}
+	}
+	return segments, nil
+}
+
+func splitTopic(topic string) ([]string, error) {
+	segments := strings.Split(topic, ".")
+	for _, segment := range segments {
+		if segment == "" {
+			return nil, fmt.Errorf("invalid topic %q: segments must not be empty", topic)
+		}
+	}
+	return segments, nil
+}
+
+// matchTopic reports whether topic matches pattern
+func matchTopic(pattern, topic []string) bool {
+	for i, segment := range pattern {
+		if segment == topicWildcardRest {
+			return true
+		}
+		if i >= len(topic) || (segment != topicWildcardOne && segment != topic[i]) {
+			return false
+		}
+	}
+	return len(pattern) == len(topic)
+}
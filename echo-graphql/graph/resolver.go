@@ -3,10 +3,23 @@
 package graph
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
 	"strings"
 	"sync"
 
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+
 	"github.com/probitas-test/echo-servers/echo-graphql/graph/model"
+	"github.com/probitas-test/echo-servers/shared/clock"
+	"github.com/probitas-test/echo-servers/shared/jwt"
+	"github.com/probitas-test/echo-servers/shared/version"
 )
 
 // filteredSubscriber represents a subscriber with optional text filter
@@ -22,13 +35,41 @@ type Resolver struct {
 	nextID              int
 	messageChannels     []chan *model.Message
 	filteredSubscribers []filteredSubscriber
+	topics              pubsub
+
+	// APQ is the persisted query cache (nil when APQ is disabled)
+	APQ *APQCache
+
+	// Operations is the operation log (nil when capture is disabled)
+	Operations *OperationLog
+
+	// PersistedQueries is the operation allowlist (nil unless allowlist mode
+	// is enabled)
+	PersistedQueries *PersistedQueries
+
+	// Largest echoHuge size in KiB and deepest echoDeep depth (0 or less =
+	// unlimited)
+	MaxHugeSizeKb int
+	MaxDeepDepth  int
+
+	// JWKS verifies echoHeaders.bearer signatures (nil when no JWKS is
+	// configured)
+	JWKS *jwt.KeySet
+	// Clock is the time echoHeaders.bearer expiry is reported on (nil: the
+	// real time)
+	Clock *clock.Clock
+
+	// Build is reported by the version query
+	Build version.Info
 }
 
 // NewResolver creates a new resolver instance
 func NewResolver() *Resolver {
 	return &Resolver{
-		messages: make(map[string]*model.Message),
-		nextID:   1,
+		messages:      make(map[string]*model.Message),
+		nextID:        1,
+		MaxHugeSizeKb: DefaultMaxHugeSizeKb,
+		MaxDeepDepth:  DefaultMaxDeepDepth,
 	}
 }
 
@@ -100,3 +141,95 @@ func (r *Resolver) Broadcast(msg *model.Message) {
 		}
 	}
 }
+
+// ClearMessages removes every message and restarts the message IDs,
+// returning how many were removed
+func (r *Resolver) ClearMessages() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := len(r.messages)
+	clear(r.messages)
+	r.nextID = 1
+	return n
+}
+
+// MessageCount returns the number of stored messages
+func (r *Resolver) MessageCount() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.messages)
+}
+
+// describeUpload reads an uploaded file and returns its metadata and checksum
+func describeUpload(file graphql.Upload) (*model.UploadedFile, error) {
+	hash := sha256.New()
+	size, err := io.Copy(hash, file.File)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upload %q: %w", file.Filename, err)
+	}
+
+	uploaded := &model.UploadedFile{
+		Filename: file.Filename,
+		Size:     int(size),
+		Sha256:   hex.EncodeToString(hash.Sum(nil)),
+	}
+	if file.ContentType != "" {
+		contentType := file.ContentType
+		uploaded.ContentType = &contentType
+	}
+	return uploaded, nil
+}
+
+// newEchoError builds a GraphQL error with caller-specified extensions and
+// path. A nil path lets gqlgen fill in the current field path.
+func newEchoError(message string, code *string, extensions any, path []string) (*gqlerror.Error, error) {
+	err := &gqlerror.Error{Message: message}
+
+	if extensions != nil {
+		ext, ok := extensions.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("extensions must be an object, got %T", extensions)
+		}
+		err.Extensions = make(map[string]interface{}, len(ext)+1)
+		for k, v := range ext {
+			err.Extensions[k] = v
+		}
+	}
+	if code != nil {
+		if err.Extensions == nil {
+			err.Extensions = make(map[string]interface{}, 1)
+		}
+		err.Extensions["code"] = *code
+	}
+
+	if path != nil {
+		err.Path = ast.Path{}
+		for _, segment := range path {
+			if index, convErr := strconv.Atoi(segment); convErr == nil {
+				err.Path = append(err.Path, ast.PathIndex(index))
+			} else {
+				err.Path = append(err.Path, ast.PathName(segment))
+			}
+		}
+	}
+	return err, nil
+}
+
+// shape is a concrete type that is both a Shape and a ShapeResult member
+type shape interface {
+	model.Shape
+	model.ShapeResult
+}
+
+// newShape builds the concrete shape of kind. Without an explicit id, the id
+// is derived from kind and size so equal arguments yield the same cache key.
+func newShape(kind model.ShapeKind, size float64, id *string) shape {
+	shapeID := fmt.Sprintf("%s:%g", strings.ToLower(string(kind)), size)
+	if id != nil {
+		shapeID = *id
+	}
+	if kind == model.ShapeKindCircle {
+		return &model.Circle{ID: shapeID, Kind: kind, Area: math.Pi * size * size, Radius: size}
+	}
+	return &model.Square{ID: shapeID, Kind: kind, Area: size * size, Side: size}
+}
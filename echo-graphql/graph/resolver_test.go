@@ -1,24 +1,76 @@
 package graph_test
 
 import (
+	"bufio"
 	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/99designs/gqlgen/client"
 	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/apollotracing"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
 	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/gorilla/websocket"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 
 	"github.com/probitas-test/echo-servers/echo-graphql/graph"
+	"github.com/probitas-test/echo-servers/echo-graphql/graph/model"
+	"github.com/probitas-test/echo-servers/shared/admin"
+	"github.com/probitas-test/echo-servers/shared/chaos"
+	"github.com/probitas-test/echo-servers/shared/clock"
+	"github.com/probitas-test/echo-servers/shared/jwt"
+	"github.com/probitas-test/echo-servers/shared/lifecycle"
+	"github.com/probitas-test/echo-servers/shared/version"
 )
 
 func setupTestClient(t *testing.T) *client.Client {
+	t.Helper()
+	return setupTestClientWithLimits(t, 0, 0)
+}
+
+func setupTestClientWithLimits(t *testing.T, complexityLimit, depthLimit int) *client.Client {
 	t.Helper()
 	resolver := graph.NewResolver()
 	srv := handler.New(graph.NewExecutableSchema(graph.Config{
-		Resolvers: resolver,
+		Resolvers:  resolver,
+		Directives: graph.NewDirectiveRoot(),
+		Complexity: graph.NewComplexityRoot(),
 	}))
+	srv.AddTransport(transport.MultipartMixed{})
+	srv.AddTransport(transport.SSE{})
 	srv.AddTransport(transport.POST{})
+	srv.AddTransport(transport.MultipartForm{})
+	resolver.Operations = graph.NewOperationLog(10)
+	srv.Use(resolver.Operations)
+	srv.Use(extension.Introspection{})
+	resolver.APQ = graph.NewAPQCache(100)
+	srv.Use(extension.AutomaticPersistedQuery{Cache: resolver.APQ})
+	srv.Use(graph.NewComplexityLimit(complexityLimit))
+	srv.Use(graph.DepthLimit{Limit: depthLimit})
 	return client.New(srv)
 }
 
@@ -76,6 +128,82 @@ func TestEchoError_ReturnsGraphQLError(t *testing.T) {
 	}
 }
 
+type responseError struct {
+	Message    string         `json:"message"`
+	Path       []any          `json:"path"`
+	Extensions map[string]any `json:"extensions"`
+}
+
+func postForErrors(t *testing.T, c *client.Client, query string) []responseError {
+	t.Helper()
+
+	resp, err := c.RawPost(query)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	var errs []responseError
+	if err := json.Unmarshal(resp.Errors, &errs); err != nil {
+		t.Fatalf("failed to decode errors: %v", err)
+	}
+	return errs
+}
+
+func TestEchoErrorWithExtensions_ReturnsCustomExtensions(t *testing.T) {
+	c := setupTestClient(t)
+
+	errs := postForErrors(t, c, `query { echoErrorWithExtensions(message: "slow down", code: "RATE_LIMITED", extensions: {retryAfter: 30, code: "ignored"}) }`)
+
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %d", len(errs))
+	}
+	if errs[0].Message != "slow down" {
+		t.Errorf("expected message 'slow down', got %q", errs[0].Message)
+	}
+	if errs[0].Extensions["code"] != "RATE_LIMITED" {
+		t.Errorf("expected code argument to take precedence, got %v", errs[0].Extensions["code"])
+	}
+	if errs[0].Extensions["retryAfter"] != float64(30) {
+		t.Errorf("expected retryAfter 30, got %v", errs[0].Extensions["retryAfter"])
+	}
+}
+
+func TestEchoErrorWithExtensions_RejectsNonObjectExtensions(t *testing.T) {
+	c := setupTestClient(t)
+
+	errs := postForErrors(t, c, `query { echoErrorWithExtensions(message: "x", code: "X", extensions: [1, 2]) }`)
+	if len(errs) != 1 || !strings.Contains(errs[0].Message, "extensions must be an object") {
+		t.Errorf("expected extensions validation error, got %+v", errs)
+	}
+}
+
+func TestEchoErrors_ReturnsMultipleErrorsAtPaths(t *testing.T) {
+	c := setupTestClient(t)
+
+	errs := postForErrors(t, c, `query { echoErrors(errors: [
+		{message: "default path", code: "A"},
+		{message: "custom path", path: ["user", "friends", "1", "name"]},
+		{message: "no path", path: []}
+	]) }`)
+
+	if len(errs) != 3 {
+		t.Fatalf("expected 3 errors, got %d", len(errs))
+	}
+	byMessage := make(map[string]responseError)
+	for _, e := range errs {
+		byMessage[e.Message] = e
+	}
+
+	if got := byMessage["default path"]; len(got.Path) != 1 || got.Path[0] != "echoErrors" || got.Extensions["code"] != "A" {
+		t.Errorf("unexpected default path error: %+v", got)
+	}
+	if got := byMessage["custom path"].Path; len(got) != 4 || got[1] != "friends" || got[2] != float64(1) {
+		t.Errorf("expected path [user friends 1 name] with numeric index, got %v", got)
+	}
+	if got := byMessage["no path"].Path; got != nil {
+		t.Errorf("expected no path, got %v", got)
+	}
+}
+
 func TestEchoPartialError_ReturnsMixedResults(t *testing.T) {
 	c := setupTestClient(t)
 
@@ -288,6 +416,229 @@ func TestEchoList_EmptyList(t *testing.T) {
 	}
 }
 
+func TestEchoList_RejectsHugeCount(t *testing.T) {
+	c := setupTestClient(t)
+
+	var resp map[string]any
+	err := c.Post(`query { echoList(message: "item", count: 10001) { index } }`, &resp)
+	if err == nil || !strings.Contains(err.Error(), "BAD_USER_INPUT") {
+		t.Errorf("expected BAD_USER_INPUT error, got %v", err)
+	}
+}
+
+func TestEchoHuge_ReturnsRequestedSize(t *testing.T) {
+	c := setupTestClient(t)
+
+	var resp struct {
+		EchoHuge string
+	}
+	c.MustPost(`query { echoHuge(sizeKb: 3) }`, &resp)
+	if len(resp.EchoHuge) != 3*1024 {
+		t.Errorf("expected %d bytes, got %d", 3*1024, len(resp.EchoHuge))
+	}
+
+	for _, query := range []string{
+		`query { echoHuge(sizeKb: 0) }`,
+		fmt.Sprintf(`query { echoHuge(sizeKb: %d) }`, graph.DefaultMaxHugeSizeKb+1),
+	} {
+		err := c.Post(query, &resp)
+		if err == nil || !strings.Contains(err.Error(), "BAD_USER_INPUT") {
+			t.Errorf("%s: expected BAD_USER_INPUT, got %v", query, err)
+		}
+	}
+}
+
+func TestEchoDeep_ReturnsNestedValue(t *testing.T) {
+	c := setupTestClient(t)
+
+	var resp struct {
+		EchoDeep any
+	}
+	c.MustPost(`query { echoDeep(depth: 500) }`, &resp)
+	depth := 0
+	for value := resp.EchoDeep; value != nil; depth++ {
+		obj := value.(map[string]any)
+		if fmt.Sprint(obj["level"]) != fmt.Sprint(depth+1) {
+			t.Fatalf("expected level %d, got %v", depth+1, obj["level"])
+		}
+		value = obj["child"]
+	}
+	if depth != 500 {
+		t.Errorf("expected 500 nested objects, got %d", depth)
+	}
+
+	var arrays struct {
+		EchoDeep any
+	}
+	c.MustPost(`query { echoDeep(depth: 3, arrays: true) }`, &arrays)
+	if !reflect.DeepEqual(arrays.EchoDeep, []any{[]any{[]any{}}}) {
+		t.Errorf("expected 3 nested arrays, got %v", arrays.EchoDeep)
+	}
+
+	err := c.Post(fmt.Sprintf(`query { echoDeep(depth: %d) }`, graph.DefaultMaxDeepDepth+1), &resp)
+	if err == nil || !strings.Contains(err.Error(), "BAD_USER_INPUT") {
+		t.Errorf("expected BAD_USER_INPUT, got %v", err)
+	}
+}
+
+type echoConnectionResponse struct {
+	EchoConnection struct {
+		TotalCount int
+		Edges      []struct {
+			Cursor string
+			Node   struct{ Index int }
+		}
+		PageInfo struct {
+			HasNextPage     bool
+			HasPreviousPage bool
+			StartCursor     *string
+			EndCursor       *string
+		}
+	}
+}
+
+const echoConnectionQuery = `query ($first: Int, $after: String, $last: Int, $before: String) {
+	echoConnection(message: "item", count: 5, first: $first, after: $after, last: $last, before: $before) {
+		totalCount
+		edges { cursor node { index } }
+		pageInfo { hasNextPage hasPreviousPage startCursor endCursor }
+	}
+}`
+
+func TestEchoConnection_PaginatesForward(t *testing.T) {
+	c := setupTestClient(t)
+
+	var indexes []int
+	var after *string
+	for pages := 0; ; pages++ {
+		if pages > 5 {
+			t.Fatal("pagination did not terminate")
+		}
+		var resp echoConnectionResponse
+		c.MustPost(echoConnectionQuery, &resp, client.Var("first", 2), client.Var("after", after))
+
+		conn := resp.EchoConnection
+		if conn.TotalCount != 5 {
+			t.Errorf("expected totalCount 5, got %d", conn.TotalCount)
+		}
+		if conn.PageInfo.HasPreviousPage != (after != nil) {
+			t.Errorf("unexpected hasPreviousPage %v on page %d", conn.PageInfo.HasPreviousPage, pages)
+		}
+		for _, edge := range conn.Edges {
+			indexes = append(indexes, edge.Node.Index)
+		}
+		if !conn.PageInfo.HasNextPage {
+			break
+		}
+		after = conn.PageInfo.EndCursor
+	}
+
+	if !reflect.DeepEqual(indexes, []int{0, 1, 2, 3, 4}) {
+		t.Errorf("expected all items in order, got %v", indexes)
+	}
+}
+
+func TestEchoConnection_PaginatesBackward(t *testing.T) {
+	c := setupTestClient(t)
+
+	var first echoConnectionResponse
+	c.MustPost(echoConnectionQuery, &first, client.Var("last", 2))
+	if got := first.EchoConnection.Edges; len(got) != 2 || got[0].Node.Index != 3 || got[1].Node.Index != 4 {
+		t.Fatalf("expected items 3 and 4, got %+v", got)
+	}
+	if !first.EchoConnection.PageInfo.HasPreviousPage || first.EchoConnection.PageInfo.HasNextPage {
+		t.Errorf("unexpected pageInfo %+v", first.EchoConnection.PageInfo)
+	}
+
+	var prev echoConnectionResponse
+	c.MustPost(echoConnectionQuery, &prev, client.Var("last", 2), client.Var("before", first.EchoConnection.PageInfo.StartCursor))
+	if got := prev.EchoConnection.Edges; len(got) != 2 || got[0].Node.Index != 1 || got[1].Node.Index != 2 {
+		t.Fatalf("expected items 1 and 2, got %+v", got)
+	}
+	if *prev.EchoConnection.PageInfo.EndCursor != prev.EchoConnection.Edges[1].Cursor {
+		t.Errorf("expected endCursor to match the last edge")
+	}
+}
+
+func TestEchoConnection_RejectsInvalidCursor(t *testing.T) {
+	c := setupTestClient(t)
+
+	var resp map[string]any
+	err := c.Post(echoConnectionQuery, &resp, client.Var("after", "not-a-cursor"))
+	if err == nil || !strings.Contains(err.Error(), "BAD_USER_INPUT") {
+		t.Errorf("expected BAD_USER_INPUT error, got %v", err)
+	}
+}
+
+func TestEchoConnection_RejectsHugeCount(t *testing.T) {
+	c := setupTestClient(t)
+
+	var resp map[string]any
+	err := c.Post(`query { echoConnection(message: "item", count: 2147483647) { totalCount } }`, &resp)
+	if err == nil || !strings.Contains(err.Error(), "BAD_USER_INPUT") {
+		t.Errorf("expected BAD_USER_INPUT error, got %v", err)
+	}
+}
+
+func TestEchoShape_ResolvesInterfaceFragments(t *testing.T) {
+	c := setupTestClient(t)
+
+	var resp struct {
+		Circle struct {
+			Typename string `json:"__typename"`
+			ID       string
+			Area     float64
+			Radius   *float64
+			Side     *float64
+		}
+		Square struct {
+			Typename string `json:"__typename"`
+			ID       string
+			Area     float64
+			Radius   *float64
+			Side     *float64
+		}
+	}
+	c.MustPost(`query {
+		circle: echoShape(kind: CIRCLE, size: 2) { __typename id area ... on Circle { radius } ... on Square { side } }
+		square: echoShape(kind: SQUARE, size: 3, id: "s1") { __typename id area ... on Circle { radius } ... on Square { side } }
+	}`, &resp)
+
+	if resp.Circle.Typename != "Circle" || resp.Circle.ID != "circle:2" || resp.Circle.Radius == nil || *resp.Circle.Radius != 2 || resp.Circle.Side != nil {
+		t.Errorf("unexpected circle %+v", resp.Circle)
+	}
+	if resp.Square.Typename != "Square" || resp.Square.ID != "s1" || resp.Square.Area != 9 || resp.Square.Side == nil || resp.Square.Radius != nil {
+		t.Errorf("unexpected square %+v", resp.Square)
+	}
+}
+
+func TestEchoShapeUnions_ResolvesMixedUnionMembers(t *testing.T) {
+	c := setupTestClient(t)
+
+	var resp struct {
+		EchoShapeUnions []struct {
+			Typename string `json:"__typename"`
+			ID       string
+		}
+	}
+	c.MustPost(`query {
+		echoShapeUnions(shapes: [{kind: SQUARE}, {kind: CIRCLE, id: "same"}, {kind: SQUARE, id: "same"}]) {
+			__typename
+			... on Shape { id }
+		}
+	}`, &resp)
+
+	want := []string{"Square:square:1", "Circle:same", "Square:same"}
+	if len(resp.EchoShapeUnions) != len(want) {
+		t.Fatalf("expected %d results, got %+v", len(want), resp.EchoShapeUnions)
+	}
+	for i, result := range resp.EchoShapeUnions {
+		if got := result.Typename + ":" + result.ID; got != want[i] {
+			t.Errorf("result %d: expected %q, got %q", i, want[i], got)
+		}
+	}
+}
+
 func TestEchoNull_ReturnsNull(t *testing.T) {
 	c := setupTestClient(t)
 
@@ -354,130 +705,1352 @@ func TestEchoHeaders_ReturnsEmptyWhenNoRequest(t *testing.T) {
 	}
 }
 
-// Mutation Tests
+func signJWT(t *testing.T, header, claims map[string]any, sign func(signed []byte) []byte) string {
+	t.Helper()
+	encode := func(v any) string {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatalf("failed to encode JWT segment: %v", err)
+		}
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	signed := encode(header) + "." + encode(claims)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sign([]byte(signed)))
+}
 
-func TestCreateMessage_CreatesAndReturnsMessage(t *testing.T) {
-	c := setupTestClient(t)
+func TestEchoHeaders_BearerClaims(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate EC key: %v", err)
+	}
+	ecPoint, err := ecKey.PublicKey.Bytes()
+	if err != nil {
+		t.Fatalf("failed to encode EC key: %v", err)
+	}
+	b64 := base64.RawURLEncoding.EncodeToString
+	jwks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]any{
+			{"kty": "RSA", "kid": "rsa", "n": b64(rsaKey.N.Bytes()), "e": b64(big.NewInt(int64(rsaKey.E)).Bytes())},
+			{"kty": "EC", "kid": "ec", "crv": "P-256", "x": b64(ecPoint[1:33]), "y": b64(ecPoint[33:])},
+		}})
+	}))
+	t.Cleanup(jwks.Close)
 
-	var resp struct {
-		CreateMessage struct {
-			ID        string
-			Text      string
-			CreatedAt string
+	resolver := graph.NewResolver()
+	resolver.JWKS = jwt.NewKeySet(jwks.URL, nil)
+	srv := handler.New(graph.NewExecutableSchema(graph.Config{Resolvers: resolver}))
+	srv.AddTransport(transport.POST{})
+	c := client.New(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		srv.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), model.RequestKey, r)))
+	}))
+
+	claims := map[string]any{"sub": "alice", "iss": "https://issuer.example", "aud": "echo", "scope": "read write", "exp": 1, "tenant": "acme"}
+	rs256 := signJWT(t, map[string]any{"alg": "RS256", "kid": "rsa"}, claims, func(signed []byte) []byte {
+		digest := sha256.Sum256(signed)
+		sig, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:])
+		if err != nil {
+			t.Fatalf("failed to sign: %v", err)
+		}
+		return sig
+	})
+	es256 := signJWT(t, map[string]any{"alg": "ES256", "kid": "ec"}, claims, func(signed []byte) []byte {
+		digest := sha256.Sum256(signed)
+		r, s, err := ecdsa.Sign(rand.Reader, ecKey, digest[:])
+		if err != nil {
+			t.Fatalf("failed to sign: %v", err)
+		}
+		return append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	})
+	unsigned := signJWT(t, map[string]any{"alg": "none"}, claims, func([]byte) []byte { return nil })
+	tampered := rs256[:strings.LastIndex(rs256, ".")] + "." + b64([]byte("forged"))
+
+	for _, tc := range []struct {
+		name     string
+		token    string
+		verified bool
+		reason   string
+	}{
+		{name: "RS256", token: rs256, verified: true},
+		{name: "ES256", token: es256, verified: true},
+		{name: "unsigned", token: unsigned, reason: "unsigned"},
+		{name: "tampered", token: tampered, reason: "invalid signature"},
+	} {
+		var resp struct {
+			EchoHeaders struct {
+				Bearer struct {
+					Subject           string
+					Issuer            string
+					Audience          []string
+					Scopes            []string
+					ExpiresAt         string
+					Expired           bool
+					Tenant            string
+					Verified          bool
+					VerificationError *string
+				}
+			}
+		}
+		c.MustPost(`query { echoHeaders { bearer {
+			subject issuer audience scopes expiresAt expired tenant: claim(name: "tenant") verified verificationError
+		} } }`, &resp, client.AddHeader("Authorization", "Bearer "+tc.token))
+
+		bearer := resp.EchoHeaders.Bearer
+		if bearer.Subject != "alice" || bearer.Issuer != "https://issuer.example" || bearer.Tenant != "acme" ||
+			!reflect.DeepEqual(bearer.Audience, []string{"echo"}) || !reflect.DeepEqual(bearer.Scopes, []string{"read", "write"}) {
+			t.Errorf("%s: unexpected claims %+v", tc.name, bearer)
+		}
+		if bearer.ExpiresAt != "1970-01-01T00:00:01Z" || !bearer.Expired {
+			t.Errorf("%s: expected expired token at epoch+1s, got %q (expired=%v)", tc.name, bearer.ExpiresAt, bearer.Expired)
+		}
+		if bearer.Verified != tc.verified {
+			t.Errorf("%s: expected verified=%v, got %v (%v)", tc.name, tc.verified, bearer.Verified, bearer.VerificationError)
+		}
+		if tc.reason != "" && (bearer.VerificationError == nil || !strings.Contains(*bearer.VerificationError, tc.reason)) {
+			t.Errorf("%s: expected verification error containing %q, got %v", tc.name, tc.reason, bearer.VerificationError)
 		}
 	}
-	c.MustPost(`mutation { createMessage(text: "hello world") { id text createdAt } }`, &resp)
 
-	if resp.CreateMessage.ID == "" {
-		t.Error("expected non-empty ID")
-	}
-	if resp.CreateMessage.Text != "hello world" {
-		t.Errorf("expected text 'hello world', got %q", resp.CreateMessage.Text)
+	var resp struct {
+		EchoHeaders struct {
+			Bearer *struct{ Token string }
+		}
 	}
-	if resp.CreateMessage.CreatedAt == "" {
-		t.Error("expected non-empty createdAt")
+	c.MustPost(`query { echoHeaders { bearer { token } } }`, &resp, client.AddHeader("Authorization", "Basic dXNlcjpwYXNz"))
+	if resp.EchoHeaders.Bearer != nil {
+		t.Errorf("expected null bearer for Basic auth, got %+v", resp.EchoHeaders.Bearer)
 	}
 }
 
-func TestUpdateMessage_UpdatesExistingMessage(t *testing.T) {
-	c := setupTestClient(t)
+func TestEchoHeaders_BearerExpiredOnClock(t *testing.T) {
+	clk := clock.New(clock.Config{})
+	resolver := graph.NewResolver()
+	resolver.Clock = clk
+	srv := handler.New(graph.NewExecutableSchema(graph.Config{Resolvers: resolver}))
+	srv.AddTransport(transport.POST{})
+	c := client.New(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		srv.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), model.RequestKey, r)))
+	}))
+	token := signJWT(t, map[string]any{"alg": "none"}, map[string]any{"exp": time.Now().Add(time.Hour).Unix()}, func([]byte) []byte { return nil })
 
-	// Create a message first
-	var createResp struct {
-		CreateMessage struct {
-			ID   string
-			Text string
+	expired := func() bool {
+		var resp struct {
+			EchoHeaders struct{ Bearer struct{ Expired bool } }
 		}
+		c.MustPost(`query { echoHeaders { bearer { expired } } }`, &resp, client.AddHeader("Authorization", "Bearer "+token))
+		return resp.EchoHeaders.Bearer.Expired
 	}
-	c.MustPost(`mutation { createMessage(text: "original") { id text } }`, &createResp)
+	if expired() {
+		t.Error("expected unexpired token before exp")
+	}
+	clk.Advance(2 * time.Hour)
+	if !expired() {
+		t.Error("expected expired token once the clock passes exp")
+	}
+}
 
-	// Update the message
-	var updateResp struct {
-		UpdateMessage struct {
-			ID   string
-			Text string
-		}
+func TestDelayDirective_DelaysIndividualFields(t *testing.T) {
+	c := setupTestClient(t)
+
+	var resp struct {
+		Fast string
+		Slow string
 	}
-	query := `mutation { updateMessage(id: "` + createResp.CreateMessage.ID + `", text: "updated") { id text } }`
-	c.MustPost(query, &updateResp)
+	start := time.Now()
+	c.MustPost(`query { fast: echo(message: "fast") slow: echo(message: "slow") @delay(ms: 100) }`, &resp)
+	elapsed := time.Since(start)
 
-	if updateResp.UpdateMessage.ID != createResp.CreateMessage.ID {
-		t.Errorf("expected ID %q, got %q", createResp.CreateMessage.ID, updateResp.UpdateMessage.ID)
+	if resp.Fast != "fast" || resp.Slow != "slow" {
+		t.Errorf("unexpected response: %+v", resp)
 	}
-	if updateResp.UpdateMessage.Text != "updated" {
-		t.Errorf("expected text 'updated', got %q", updateResp.UpdateMessage.Text)
+	if elapsed < 100*time.Millisecond {
+		t.Errorf("expected at least 100ms delay, got %v", elapsed)
 	}
 }
 
-func TestUpdateMessage_ReturnsErrorForNonExistentID(t *testing.T) {
+func TestDelayDirective_RootFieldsResolveConcurrently(t *testing.T) {
 	c := setupTestClient(t)
 
 	var resp struct {
-		UpdateMessage *struct {
-			ID   string
-			Text string
-		}
+		A string
+		B string
 	}
-	err := c.Post(`mutation { updateMessage(id: "non-existent", text: "updated") { id text } }`, &resp)
+	start := time.Now()
+	c.MustPost(`query { a: echo(message: "a") @delay(ms: 150) b: echo(message: "b") @delay(ms: 150) }`, &resp)
+	elapsed := time.Since(start)
 
-	if err == nil {
-		t.Fatal("expected error, got nil")
+	if elapsed >= 300*time.Millisecond {
+		t.Errorf("expected sibling root field delays to overlap, took %v", elapsed)
 	}
 }
 
-func TestDeleteMessage_ReturnsTrueForExistingMessage(t *testing.T) {
+func TestEchoDeferred_DeliversSlowFieldIncrementally(t *testing.T) {
 	c := setupTestClient(t)
 
-	// Create a message first
-	var createResp struct {
-		CreateMessage struct {
-			ID string
+	query := `query { echoDeferred(message: "hi") { message ... @defer(label: "slow") { slow(delayMs: 10) } } }`
+	read := c.IncrementalHTTP(context.Background(), query)
+	defer func() { _ = read.Close() }()
+
+	var initial struct {
+		Data struct {
+			EchoDeferred struct {
+				Message string
+				Slow    *string
+			}
 		}
+		HasNext bool
 	}
-	c.MustPost(`mutation { createMessage(text: "to delete") { id } }`, &createResp)
-
-	// Delete the message
-	var deleteResp struct {
-		DeleteMessage bool
+	if err := read.Next(&initial); err != nil {
+		t.Fatalf("failed to read initial response: %v", err)
+	}
+	if initial.Data.EchoDeferred.Message != "hi" {
+		t.Errorf("expected message 'hi', got %q", initial.Data.EchoDeferred.Message)
+	}
+	if initial.Data.EchoDeferred.Slow != nil {
+		t.Errorf("expected slow field to be deferred, got %q", *initial.Data.EchoDeferred.Slow)
+	}
+	if !initial.HasNext {
+		t.Fatal("expected hasNext=true in initial response")
 	}
-	query := `mutation { deleteMessage(id: "` + createResp.CreateMessage.ID + `") }`
-	c.MustPost(query, &deleteResp)
 
-	if !deleteResp.DeleteMessage {
-		t.Error("expected deleteMessage to return true for existing message")
+	var next struct {
+		Incremental []struct {
+			Data struct {
+				Slow string
+			}
+			Label   string
+			Path    []any
+			HasNext bool
+		}
+		HasNext bool
+	}
+	if err := read.Next(&next); err != nil {
+		t.Fatalf("failed to read deferred response: %v", err)
+	}
+	if len(next.Incremental) != 1 {
+		t.Fatalf("expected 1 incremental payload, got %d", len(next.Incremental))
+	}
+	if next.Incremental[0].Label != "slow" || next.Incremental[0].Data.Slow != "hi" {
+		t.Errorf("expected deferred slow='hi' with label 'slow', got %+v", next.Incremental[0])
+	}
+	if next.HasNext {
+		t.Error("expected hasNext=false in final response")
 	}
 }
 
-func TestDeleteMessage_ReturnsFalseForNonExistentID(t *testing.T) {
+func TestEchoDeferred_StreamDeliversFullList(t *testing.T) {
 	c := setupTestClient(t)
 
 	var resp struct {
-		DeleteMessage bool
+		EchoDeferred struct {
+			Items []struct {
+				Index   int
+				Message string
+			}
+		}
 	}
-	c.MustPost(`mutation { deleteMessage(id: "non-existent") }`, &resp)
+	c.MustPost(`query { echoDeferred(message: "item") { items(count: 3, delayMs: 0) @stream(initialCount: 1) { index message } } }`, &resp)
 
-	if resp.DeleteMessage {
-		t.Error("expected deleteMessage to return false for non-existent ID")
+	if len(resp.EchoDeferred.Items) != 3 {
+		t.Fatalf("expected 3 items, got %d", len(resp.EchoDeferred.Items))
+	}
+	if resp.EchoDeferred.Items[2].Message != "item-2" {
+		t.Errorf("expected 'item-2', got %q", resp.EchoDeferred.Items[2].Message)
 	}
 }
 
-func TestBatchCreateMessages_CreatesMultipleMessages(t *testing.T) {
+func TestEchoDeferred_ItemsLimitsCount(t *testing.T) {
 	c := setupTestClient(t)
 
 	var resp struct {
-		BatchCreateMessages []struct {
-			ID        string
-			Text      string
-			CreatedAt string
+		EchoDeferred struct {
+			Items []struct {
+				Index int
+			}
 		}
 	}
-	c.MustPost(`mutation { batchCreateMessages(texts: ["first", "second", "third"]) { id text createdAt } }`, &resp)
+	c.MustPost(`query { echoDeferred(message: "item") { items(count: -1, delayMs: 0) { index } } }`, &resp)
+	if len(resp.EchoDeferred.Items) != 0 {
+		t.Errorf("negative count: expected 0 items, got %d", len(resp.EchoDeferred.Items))
+	}
 
-	if len(resp.BatchCreateMessages) != 3 {
-		t.Fatalf("expected 3 messages, got %d", len(resp.BatchCreateMessages))
+	var raw map[string]any
+	err := c.Post(`query { echoDeferred(message: "item") { items(count: 1000000, delayMs: 0) { index } } }`, &raw)
+	if err == nil || !strings.Contains(err.Error(), "BAD_USER_INPUT") {
+		t.Errorf("huge count: expected BAD_USER_INPUT error, got %v", err)
 	}
+}
 
-	expectedTexts := []string{"first", "second", "third"}
+func persistedQuery(query string) client.Option {
+	hash := sha256.Sum256([]byte(query))
+	return client.Extensions(map[string]any{
+		"persistedQuery": map[string]any{
+			"version":    1,
+			"sha256Hash": hex.EncodeToString(hash[:]),
+		},
+	})
+}
+
+func TestAPQ_HashNegotiation(t *testing.T) {
+	c := setupTestClient(t)
+	query := `query { echo(message: "apq") }`
+
+	// Hash only: not yet registered
+	err := c.Post("", &struct{}{}, persistedQuery(query))
+	if err == nil || !strings.Contains(err.Error(), "PersistedQueryNotFound") {
+		t.Fatalf("expected PersistedQueryNotFound, got %v", err)
+	}
+
+	// Hash with query: registers it
+	var resp struct {
+		Echo string
+	}
+	c.MustPost(query, &resp, persistedQuery(query))
+	if resp.Echo != "apq" {
+		t.Errorf("expected 'apq', got %q", resp.Echo)
+	}
+
+	// Hash only: served from the cache
+	resp.Echo = ""
+	c.MustPost("", &resp, persistedQuery(query))
+	if resp.Echo != "apq" {
+		t.Errorf("expected 'apq' from persisted query, got %q", resp.Echo)
+	}
+
+	var stats struct {
+		ApqStats struct {
+			Enabled       bool
+			Hits          int
+			Misses        int
+			Registrations int
+		}
+	}
+	c.MustPost(`query { apqStats { enabled hits misses registrations } }`, &stats)
+	if !stats.ApqStats.Enabled || stats.ApqStats.Hits != 1 || stats.ApqStats.Misses != 1 || stats.ApqStats.Registrations != 1 {
+		t.Errorf("expected enabled with 1 hit, 1 miss, 1 registration, got %+v", stats.ApqStats)
+	}
+}
+
+func TestAPQ_RejectsMismatchedHash(t *testing.T) {
+	c := setupTestClient(t)
+
+	err := c.Post(`query { echo(message: "a") }`, &struct{}{}, persistedQuery(`query { echo(message: "b") }`))
+	if err == nil || !strings.Contains(err.Error(), "provided APQ hash does not match query") {
+		t.Fatalf("expected hash mismatch error, got %v", err)
+	}
+}
+
+func TestAPQCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	cache := graph.NewAPQCache(1)
+	ctx := context.Background()
+
+	cache.Add(ctx, "a", "query A")
+	cache.Add(ctx, "b", "query B")
+
+	if _, ok := cache.Get(ctx, "a"); ok {
+		t.Error("expected 'a' to be evicted")
+	}
+	if query, ok := cache.Get(ctx, "b"); !ok || query != "query B" {
+		t.Errorf("expected 'b' to be cached, got %q (ok=%v)", query, ok)
+	}
+
+	stats := cache.Reset()
+	if stats.Hits != 1 || stats.Misses != 1 || stats.Registrations != 2 {
+		t.Errorf("unexpected stats before reset: %+v", stats)
+	}
+	if stats := cache.Stats(); stats.Hits != 0 || stats.Misses != 0 || stats.Registrations != 0 {
+		t.Errorf("expected counters to be reset, got %+v", stats)
+	}
+}
+
+func setupPersistedQueriesClient(t *testing.T, queries ...graph.PersistedQuery) *client.Client {
+	t.Helper()
+	allowlist := graph.NewPersistedQueries()
+	if err := allowlist.Register(queries...); err != nil {
+		t.Fatalf("failed to register persisted queries: %v", err)
+	}
+	srv := handler.New(graph.NewExecutableSchema(graph.Config{Resolvers: graph.NewResolver()}))
+	srv.AddTransport(transport.POST{})
+	srv.Use(allowlist)
+	return client.New(srv)
+}
+
+func TestPersistedQueries_AllowsOnlyRegisteredOperations(t *testing.T) {
+	allowed := `query { echo(message: "allowed") }`
+	c := setupPersistedQueriesClient(t,
+		graph.PersistedQuery{ID: "echo-allowed", Name: "EchoAllowed", Type: "query", Body: allowed},
+		graph.PersistedQuery{Body: `query { echo(message: "hashed") }`},
+	)
+
+	var resp struct {
+		Echo string
+	}
+	c.MustPost("", &resp, client.Extensions(map[string]any{
+		"persistedQuery": map[string]any{"version": 1, "sha256Hash": "echo-allowed"},
+	}))
+	if resp.Echo != "allowed" {
+		t.Errorf("expected 'allowed' from manifest id, got %q", resp.Echo)
+	}
+
+	// Entries without an id are keyed by the SHA-256 hash of their body
+	c.MustPost("", &resp, persistedQuery(`query { echo(message: "hashed") }`))
+	if resp.Echo != "hashed" {
+		t.Errorf("expected 'hashed' from body hash, got %q", resp.Echo)
+	}
+
+	// Full text of an allowlisted operation
+	c.MustPost(allowed, &resp)
+	if resp.Echo != "allowed" {
+		t.Errorf("expected 'allowed' from full text, got %q", resp.Echo)
+	}
+
+	for _, tc := range []struct {
+		name  string
+		query string
+		opts  []client.Option
+	}{
+		{name: "unknown id", opts: []client.Option{persistedQuery(`query { echo(message: "unknown") }`)}},
+		{name: "unregistered text", query: `query { echo(message: "unknown") }`},
+		{name: "registering via APQ", query: `query { echo(message: "unknown") }`, opts: []client.Option{persistedQuery(`query { echo(message: "unknown") }`)}},
+	} {
+		err := c.Post(tc.query, &resp, tc.opts...)
+		if err == nil || !strings.Contains(err.Error(), "PERSISTED_QUERY_NOT_FOUND") {
+			t.Errorf("%s: expected PERSISTED_QUERY_NOT_FOUND, got %v", tc.name, err)
+		}
+	}
+}
+
+func TestParseManifest_AcceptsApolloAndFlatFormats(t *testing.T) {
+	apollo := `{
+		"format": "apollo-persisted-query-manifest",
+		"version": 1,
+		"operations": [{"id": "abc", "name": "Echo", "type": "query", "body": "query Echo { echo(message: \"a\") }"}]
+	}`
+	queries, err := graph.ParseManifest([]byte(apollo))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []graph.PersistedQuery{{ID: "abc", Name: "Echo", Type: "query", Body: `query Echo { echo(message: "a") }`}}
+	if !reflect.DeepEqual(queries, want) {
+		t.Errorf("expected %+v, got %+v", want, queries)
+	}
+
+	queries, err = graph.ParseManifest([]byte(`{"abc": "query { echo(message: \"a\") }"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want = []graph.PersistedQuery{{ID: "abc", Body: `query { echo(message: "a") }`}}
+	if !reflect.DeepEqual(queries, want) {
+		t.Errorf("expected %+v, got %+v", want, queries)
+	}
+
+	if _, err := graph.ParseManifest([]byte(`{"abc": 1}`)); err == nil {
+		t.Error("expected an error for a malformed manifest")
+	}
+}
+
+func TestQueryCost_ReportsComplexityAndDepth(t *testing.T) {
+	c := setupTestClient(t)
+
+	var resp struct {
+		QueryCost struct {
+			Complexity      int
+			ComplexityLimit *int
+			Depth           int
+			DepthLimit      *int
+		}
+		EchoList []struct {
+			Index int
+		}
+	}
+	c.MustPost(`query { queryCost { complexity complexityLimit depth depthLimit } echoList(message: "x", count: 10) { index } }`, &resp)
+
+	// queryCost: 1 + 4 fields, echoList: 1 + 10 items * 1 field
+	if resp.QueryCost.Complexity != 16 {
+		t.Errorf("expected complexity 16, got %d", resp.QueryCost.Complexity)
+	}
+	if resp.QueryCost.Depth != 2 {
+		t.Errorf("expected depth 2, got %d", resp.QueryCost.Depth)
+	}
+	if resp.QueryCost.ComplexityLimit != nil || resp.QueryCost.DepthLimit != nil {
+		t.Errorf("expected null limits when unlimited, got %v / %v", resp.QueryCost.ComplexityLimit, resp.QueryCost.DepthLimit)
+	}
+}
+
+func TestVersion_ReturnsBuild(t *testing.T) {
+	resolver := graph.NewResolver()
+	resolver.Build = version.New("echo-graphql", version.Features{"apq": true, "federation": false})
+	srv := handler.New(graph.NewExecutableSchema(graph.Config{Resolvers: resolver}))
+	srv.AddTransport(transport.POST{})
+	c := client.New(srv)
+
+	var resp struct {
+		Version struct {
+			Server    string
+			Version   string
+			GoVersion string
+			Features  []string
+		}
+	}
+	c.MustPost(`query { version { server version goVersion features } }`, &resp)
+
+	if resp.Version.Server != "echo-graphql" || resp.Version.Version == "" || resp.Version.GoVersion == "" {
+		t.Errorf("unexpected build: %+v", resp.Version)
+	}
+	if len(resp.Version.Features) != 1 || resp.Version.Features[0] != "apq" {
+		t.Errorf("expected features [apq], got %v", resp.Version.Features)
+	}
+}
+
+func TestComplexityLimit_RejectsExpensiveQuery(t *testing.T) {
+	c := setupTestClientWithLimits(t, 50, 0)
+
+	var resp struct {
+		EchoList []struct {
+			Index int
+		}
+	}
+	c.MustPost(`query { echoList(message: "x", count: 10) { index } }`, &resp)
+
+	err := c.Post(`query { echoList(message: "x", count: 100) { index } }`, &resp)
+	if err == nil || !strings.Contains(err.Error(), "exceeds the limit of 50") {
+		t.Fatalf("expected complexity limit error, got %v", err)
+	}
+}
+
+func TestDepthLimit_RejectsDeepQuery(t *testing.T) {
+	c := setupTestClientWithLimits(t, 0, 3)
+
+	var resp struct {
+		QueryCost struct {
+			Depth      int
+			DepthLimit *int
+		}
+	}
+	c.MustPost(`query { queryCost { depth depthLimit } }`, &resp)
+	if resp.QueryCost.DepthLimit == nil || *resp.QueryCost.DepthLimit != 3 {
+		t.Errorf("expected depthLimit 3, got %v", resp.QueryCost.DepthLimit)
+	}
+
+	// Fragments do not add depth: echoNested > child > value is depth 3
+	var data map[string]any
+	c.MustPost(`query { echoNested(message: "x", depth: 2) { ...F } } fragment F on NestedEcho { child { value } }`, &data)
+
+	err := c.Post(`query { echoNested(message: "x", depth: 3) { child { child { value } } } }`, &data)
+	if err == nil || !strings.Contains(err.Error(), "operation has depth 4, which exceeds the limit of 3") {
+		t.Fatalf("expected depth limit error, got %v", err)
+	}
+
+	// Introspection fields are not counted
+	c.MustPost(`query { __schema { types { fields { type { name } } } } }`, &data)
+}
+
+func TestEchoInput_EchoesComplexInputVerbatim(t *testing.T) {
+	c := setupTestClient(t)
+
+	input := map[string]any{
+		"string":   "s",
+		"int":      42,
+		"float":    1.5,
+		"boolean":  true,
+		"id":       "7",
+		"priority": "HIGH",
+		"tags":     []string{"a", "b"},
+		"dateTime": "2024-01-02T03:04:05.123+09:00",
+		"json":     map[string]any{"a": []any{1, "x", nil}},
+		"bigInt":   "123456789012345678901234567890",
+		"bytes":    "aGVsbG8=",
+		"nested":   map[string]any{"string": "inner"},
+		"children": []map[string]any{{"int": 1}, {"priority": "LOW"}},
+	}
+
+	var resp struct {
+		EchoInput map[string]any
+	}
+	c.MustPost(`query ($in: EchoInput!) {
+		echoInput(input: $in) {
+			string int float boolean id priority tags dateTime json bigInt bytes
+			nested { string }
+			children { int priority }
+		}
+	}`, &resp, client.Var("in", input))
+
+	out := resp.EchoInput
+	checks := map[string]any{
+		"string":   "s",
+		"int":      float64(42),
+		"float":    1.5,
+		"boolean":  true,
+		"id":       "7",
+		"priority": "HIGH",
+		"dateTime": "2024-01-02T03:04:05.123+09:00",
+		"bigInt":   "123456789012345678901234567890",
+		"bytes":    "aGVsbG8=",
+	}
+	for field, want := range checks {
+		if out[field] != want {
+			t.Errorf("%s: expected %v, got %v", field, want, out[field])
+		}
+	}
+	if json, _ := out["json"].(map[string]any); json == nil || len(json["a"].([]any)) != 3 {
+		t.Errorf("json: expected object to be echoed, got %v", out["json"])
+	}
+	if nested, _ := out["nested"].(map[string]any); nested["string"] != "inner" {
+		t.Errorf("nested: expected 'inner', got %v", out["nested"])
+	}
+	if children, _ := out["children"].([]any); len(children) != 2 {
+		t.Errorf("children: expected 2 items, got %v", out["children"])
+	}
+}
+
+func TestEchoInput_RejectsInvalidScalars(t *testing.T) {
+	c := setupTestClient(t)
+
+	tests := []struct {
+		name    string
+		input   map[string]any
+		wantErr string
+	}{
+		{name: "invalid DateTime", input: map[string]any{"dateTime": "yesterday"}, wantErr: "DateTime must be an RFC 3339 string"},
+		{name: "fractional BigInt", input: map[string]any{"bigInt": 1.5}, wantErr: "BigInt must be an integer"},
+		{name: "invalid Bytes", input: map[string]any{"bytes": "not base64!"}, wantErr: "Bytes must be a base64 string"},
+		{name: "invalid enum", input: map[string]any{"priority": "URGENT"}, wantErr: "URGENT"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp map[string]any
+			err := c.Post(`query ($in: EchoInput!) { echoInput(input: $in) { string } }`, &resp, client.Var("in", tt.input))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestEchoInputs_EchoesList(t *testing.T) {
+	c := setupTestClient(t)
+
+	var resp struct {
+		EchoInputs []struct {
+			Int    int
+			BigInt string
+		}
+	}
+	c.MustPost(`query { echoInputs(inputs: [{int: 1, bigInt: 10}, {int: 2, bigInt: "-20"}]) { int bigInt } }`, &resp)
+
+	if len(resp.EchoInputs) != 2 || resp.EchoInputs[0].BigInt != "10" || resp.EchoInputs[1].BigInt != "-20" {
+		t.Errorf("unexpected echoInputs result: %+v", resp.EchoInputs)
+	}
+}
+
+func TestEchoVariables_ReturnsDecodedVariables(t *testing.T) {
+	c := setupTestClient(t)
+
+	var resp struct {
+		EchoInput struct {
+			String string
+		}
+		EchoVariables map[string]any
+	}
+	c.MustPost(`query ($a: String, $b: BigInt) { echoInput(input: {string: $a, bigInt: $b}) { string } echoVariables }`, &resp,
+		client.Var("a", "hello"), client.Var("b", "12345678901234567890"))
+
+	if resp.EchoVariables["a"] != "hello" {
+		t.Errorf("expected a='hello', got %v", resp.EchoVariables["a"])
+	}
+	if resp.EchoVariables["b"] != "12345678901234567890" {
+		t.Errorf("expected b='12345678901234567890', got %v", resp.EchoVariables["b"])
+	}
+}
+
+func TestLastOperations_RecordsOperationsAsSent(t *testing.T) {
+	c := setupTestClient(t)
+
+	var echo struct {
+		Echo string
+	}
+	c.MustPost(`query Hello($m: String!) { echo(message: $m) }`, &echo, client.Operation("Hello"), client.Var("m", "hi"))
+	_ = c.Post(`query { echoError(message: "boom") }`, &struct{ EchoError *string }{})
+
+	var resp struct {
+		LastOperations []struct {
+			ID            int
+			OperationName *string
+			OperationType *string
+			Query         string
+			Variables     map[string]any
+			DurationMs    float64
+			Errors        []string
+		}
+	}
+	c.MustPost(`query { lastOperations { id operationName operationType query variables durationMs errors } }`, &resp)
+
+	ops := resp.LastOperations
+	if len(ops) != 2 {
+		t.Fatalf("expected 2 recorded operations, got %d", len(ops))
+	}
+
+	// Newest first
+	if len(ops[0].Errors) != 1 || ops[0].Errors[0] != "boom" {
+		t.Errorf("expected newest operation to record error 'boom', got %v", ops[0].Errors)
+	}
+	hello := ops[1]
+	if hello.OperationName == nil || *hello.OperationName != "Hello" {
+		t.Errorf("expected operationName 'Hello', got %v", hello.OperationName)
+	}
+	if hello.OperationType == nil || *hello.OperationType != "query" {
+		t.Errorf("expected operationType 'query', got %v", hello.OperationType)
+	}
+	if hello.Query != `query Hello($m: String!) { echo(message: $m) }` {
+		t.Errorf("unexpected query text %q", hello.Query)
+	}
+	if hello.Variables["m"] != "hi" {
+		t.Errorf("expected variables {m: hi}, got %v", hello.Variables)
+	}
+	if ops[0].ID <= hello.ID {
+		t.Errorf("expected increasing ids, got %d then %d", hello.ID, ops[0].ID)
+	}
+
+	var cleared struct {
+		ClearOperations int
+	}
+	c.MustPost(`mutation { clearOperations }`, &cleared)
+	if cleared.ClearOperations != 3 {
+		t.Errorf("expected 3 cleared operations, got %d", cleared.ClearOperations)
+	}
+}
+
+func TestOperationLog_KeepsMostRecent(t *testing.T) {
+	srv := handler.New(graph.NewExecutableSchema(graph.Config{Resolvers: graph.NewResolver()}))
+	srv.AddTransport(transport.POST{})
+	log := graph.NewOperationLog(2)
+	srv.Use(log)
+	c := client.New(srv)
+
+	for _, msg := range []string{"one", "two", "three"} {
+		c.MustPost(`query ($m: String!) { echo(message: $m) }`, &struct{ Echo string }{}, client.Var("m", msg))
+	}
+
+	ops := log.Last(0)
+	if len(ops) != 2 {
+		t.Fatalf("expected 2 operations, got %d", len(ops))
+	}
+	for i, want := range []string{"three", "two"} {
+		if got := ops[i].Variables.(map[string]any)["m"]; got != want {
+			t.Errorf("operation %d: expected m=%q, got %v", i, want, got)
+		}
+	}
+	if got := log.Last(1); len(got) != 1 || got[0].ID != 3 {
+		t.Errorf("expected Last(1) to return operation 3, got %+v", got)
+	}
+}
+
+// Federation Tests
+
+func TestFederation_ServiceSDL(t *testing.T) {
+	c := setupTestClient(t)
+
+	var resp struct {
+		Service struct {
+			Sdl string
+		} `json:"_service"`
+	}
+	c.MustPost(`query { _service { sdl } }`, &resp)
+
+	if !strings.Contains(resp.Service.Sdl, `type Message @key(fields: "id")`) {
+		t.Errorf("expected SDL to declare Message entity, got:\n%s", resp.Service.Sdl)
+	}
+	if !strings.Contains(resp.Service.Sdl, "specs.apollo.dev/federation/v2") {
+		t.Errorf("expected SDL to link federation v2 spec")
+	}
+}
+
+func TestFederation_ResolvesMessageEntity(t *testing.T) {
+	c := setupTestClient(t)
+
+	var created struct {
+		CreateMessage struct {
+			ID string
+		}
+	}
+	c.MustPost(`mutation { createMessage(text: "federated") { id } }`, &created)
+
+	var resp struct {
+		Entities []struct {
+			ID   string
+			Text string
+		} `json:"_entities"`
+	}
+	c.MustPost(`query ($reps: [_Any!]!) { _entities(representations: $reps) { ... on Message { id text } } }`, &resp,
+		client.Var("reps", []map[string]any{{"__typename": "Message", "id": created.CreateMessage.ID}}))
+
+	if len(resp.Entities) != 1 || resp.Entities[0].Text != "federated" {
+		t.Errorf("expected resolved Message entity, got %+v", resp.Entities)
+	}
+
+	err := c.Post(`query ($reps: [_Any!]!) { _entities(representations: $reps) { ... on Message { id } } }`, &resp,
+		client.Var("reps", []map[string]any{{"__typename": "Message", "id": "missing"}}))
+	if err == nil || !strings.Contains(err.Error(), "message not found") {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
+
+func TestFederationGuard_RejectsFederationFields(t *testing.T) {
+	srv := handler.New(graph.NewExecutableSchema(graph.Config{Resolvers: graph.NewResolver()}))
+	srv.AddTransport(transport.POST{})
+	srv.Use(graph.FederationGuard{})
+	c := client.New(srv)
+
+	var resp map[string]any
+	err := c.Post(`query { _service { sdl } }`, &resp)
+	if err == nil || !strings.Contains(err.Error(), "FEDERATION_DISABLED") {
+		t.Fatalf("expected FEDERATION_DISABLED error, got %v", err)
+	}
+
+	c.MustPost(`query { echo(message: "ok") }`, &resp)
+}
+
+func setupIntrospectionClient(t *testing.T, policy graph.IntrospectionPolicy) *client.Client {
+	t.Helper()
+	srv := handler.New(graph.NewExecutableSchema(graph.Config{Resolvers: graph.NewResolver()}))
+	srv.AddTransport(transport.POST{})
+	srv.Use(policy)
+	return client.New(srv)
+}
+
+func TestIntrospectionPolicy_Disabled(t *testing.T) {
+	c := setupIntrospectionClient(t, graph.IntrospectionPolicy{Enabled: false})
+
+	var resp map[string]any
+	err := c.Post(`query { __schema { queryType { name } } }`, &resp)
+	if err == nil || !strings.Contains(err.Error(), "INTROSPECTION_DISABLED") {
+		t.Fatalf("expected INTROSPECTION_DISABLED error, got %v", err)
+	}
+
+	c.MustPost(`query { echo(message: "ok") __typename }`, &resp)
+	if resp["__typename"] != "Query" {
+		t.Errorf("expected __typename to remain available, got %v", resp)
+	}
+}
+
+func TestIntrospectionPolicy_RequiresToken(t *testing.T) {
+	c := setupIntrospectionClient(t, graph.IntrospectionPolicy{Enabled: true, Header: "X-Introspection-Token", Token: "secret"})
+
+	var resp map[string]any
+	for _, opts := range [][]client.Option{
+		nil,
+		{client.AddHeader("X-Introspection-Token", "wrong")},
+	} {
+		err := c.Post(`query { __type(name: "Message") { name } }`, &resp, opts...)
+		if err == nil || !strings.Contains(err.Error(), "INTROSPECTION_UNAUTHORIZED") {
+			t.Fatalf("expected INTROSPECTION_UNAUTHORIZED error, got %v", err)
+		}
+	}
+
+	c.MustPost(`query { __type(name: "Message") { name } }`, &resp, client.AddHeader("X-Introspection-Token", "secret"))
+	if typ, _ := resp["__type"].(map[string]any); typ["name"] != "Message" {
+		t.Errorf("expected Message type, got %v", resp)
+	}
+}
+
+func TestIntrospectionPolicy_SwitchOverridesEnabled(t *testing.T) {
+	var enabled atomic.Bool
+	c := setupIntrospectionClient(t, graph.IntrospectionPolicy{Enabled: true, Switch: enabled.Load})
+
+	var resp map[string]any
+	err := c.Post(`query { __schema { queryType { name } } }`, &resp)
+	if err == nil || !strings.Contains(err.Error(), "INTROSPECTION_DISABLED") {
+		t.Fatalf("expected INTROSPECTION_DISABLED error, got %v", err)
+	}
+
+	enabled.Store(true)
+	c.MustPost(`query { __schema { queryType { name } } }`, &resp)
+}
+
+func TestSchemaHandler_ServesSDLWithETag(t *testing.T) {
+	es := graph.NewExecutableSchema(graph.Config{Resolvers: graph.NewResolver()})
+	h := graph.NewSchemaHandler(es.Schema(), graph.IntrospectionPolicy{Enabled: true})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/schema.graphql", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	sdl := rec.Body.String()
+	if !strings.Contains(sdl, "type Query {") || !strings.Contains(sdl, "_schema: String!") {
+		t.Errorf("expected the echo schema SDL, got %.200s", sdl)
+	}
+	etag := rec.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("expected an ETag")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/schema.graphql", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("expected 304 for a matching If-None-Match, got %d", rec.Code)
+	}
+
+	var resp struct {
+		Schema string `json:"_schema"`
+	}
+	setupTestClient(t).MustPost(`query { _schema }`, &resp)
+	if resp.Schema != sdl {
+		t.Error("expected _schema to match GET /schema.graphql")
+	}
+}
+
+func TestSchemaHandler_FollowsIntrospectionPolicy(t *testing.T) {
+	es := graph.NewExecutableSchema(graph.Config{Resolvers: graph.NewResolver()})
+	for _, tc := range []struct {
+		name   string
+		policy graph.IntrospectionPolicy
+		token  string
+		status int
+	}{
+		{name: "disabled", policy: graph.IntrospectionPolicy{Enabled: false}, status: http.StatusNotFound},
+		{name: "missing token", policy: graph.IntrospectionPolicy{Enabled: true, Header: "X-Introspection-Token", Token: "secret"}, status: http.StatusUnauthorized},
+		{name: "valid token", policy: graph.IntrospectionPolicy{Enabled: true, Header: "X-Introspection-Token", Token: "secret"}, token: "secret", status: http.StatusOK},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/schema.graphql", nil)
+		if tc.token != "" {
+			req.Header.Set("X-Introspection-Token", tc.token)
+		}
+		rec := httptest.NewRecorder()
+		graph.NewSchemaHandler(es.Schema(), tc.policy).ServeHTTP(rec, req)
+		if rec.Code != tc.status {
+			t.Errorf("%s: expected %d, got %d", tc.name, tc.status, rec.Code)
+		}
+	}
+
+	c := setupIntrospectionClient(t, graph.IntrospectionPolicy{Enabled: false})
+	err := c.Post(`query { _schema }`, &struct{}{})
+	if err == nil || !strings.Contains(err.Error(), "INTROSPECTION_DISABLED") {
+		t.Errorf("expected INTROSPECTION_DISABLED for _schema, got %v", err)
+	}
+}
+
+// CORS and CSRF Tests
+
+func newEchoHandler() http.Handler {
+	srv := handler.New(graph.NewExecutableSchema(graph.Config{Resolvers: graph.NewResolver()}))
+	srv.AddTransport(transport.Options{})
+	srv.AddTransport(transport.GET{})
+	srv.AddTransport(transport.POST{})
+	return srv
+}
+
+func TestCORS_Preflight(t *testing.T) {
+	h := graph.CORS{
+		AllowedOrigins: []string{"https://app.example"},
+		AllowedHeaders: []string{"Content-Type", "Authorization"},
+		MaxAge:         10 * time.Minute,
+	}.Handler(newEchoHandler())
+
+	preflight := func(origin string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodOptions, "/graphql", nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", "POST")
+		req.Header.Set("Access-Control-Request-Headers", "content-type, x-secret")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := preflight("https://app.example")
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("expected 204, got %d", rec.Code)
+	}
+	for header, want := range map[string]string{
+		"Access-Control-Allow-Origin":  "https://app.example",
+		"Access-Control-Allow-Methods": "GET, POST, OPTIONS",
+		"Access-Control-Allow-Headers": "content-type",
+		"Access-Control-Max-Age":       "600",
+	} {
+		if got := rec.Header().Get(header); got != want {
+			t.Errorf("%s: expected %q, got %q", header, want, got)
+		}
+	}
+
+	rec = preflight("https://evil.example")
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("expected no Access-Control-Allow-Origin for a disallowed origin, got %q", got)
+	}
+}
+
+func TestCORS_SimpleRequest(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		cors        graph.CORS
+		allowOrigin string
+		credentials string
+	}{
+		{name: "wildcard", cors: graph.CORS{AllowedOrigins: []string{"*"}}, allowOrigin: "*"},
+		{name: "credentials", cors: graph.CORS{AllowedOrigins: []string{"*"}, AllowCredentials: true}, allowOrigin: "https://app.example", credentials: "true"},
+		{name: "disallowed", cors: graph.CORS{AllowedOrigins: []string{"https://other.example"}}},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query": "{ echo(message: \"hi\") }"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Origin", "https://app.example")
+		rec := httptest.NewRecorder()
+		tc.cors.Handler(newEchoHandler()).ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"echo":"hi"`) {
+			t.Errorf("%s: expected the operation to execute, got %d %s", tc.name, rec.Code, rec.Body)
+		}
+		if got := rec.Header().Get("Access-Control-Allow-Origin"); got != tc.allowOrigin {
+			t.Errorf("%s: expected Access-Control-Allow-Origin %q, got %q", tc.name, tc.allowOrigin, got)
+		}
+		if got := rec.Header().Get("Access-Control-Allow-Credentials"); got != tc.credentials {
+			t.Errorf("%s: expected Access-Control-Allow-Credentials %q, got %q", tc.name, tc.credentials, got)
+		}
+	}
+}
+
+func TestCSRFPrevention_RequiresPreflightedRequests(t *testing.T) {
+	h := graph.CSRFPrevention{Headers: []string{"X-Apollo-Operation-Name", "Apollo-Require-Preflight"}}.Handler(newEchoHandler())
+	body := `{"query": "{ echo(message: \"hi\") }"}`
+
+	for _, tc := range []struct {
+		name    string
+		method  string
+		headers map[string]string
+		blocked bool
+	}{
+		{name: "json POST", method: http.MethodPost, headers: map[string]string{"Content-Type": "application/json"}},
+		{name: "text/plain POST", method: http.MethodPost, headers: map[string]string{"Content-Type": "text/plain"}, blocked: true},
+		{name: "text/plain POST with header", method: http.MethodPost, headers: map[string]string{"Content-Type": "text/plain", "Apollo-Require-Preflight": "true"}},
+		{name: "GET", method: http.MethodGet, blocked: true},
+		{name: "GET with operation name", method: http.MethodGet, headers: map[string]string{"X-Apollo-Operation-Name": "Echo"}},
+	} {
+		var req *http.Request
+		if tc.method == http.MethodGet {
+			req = httptest.NewRequest(http.MethodGet, "/graphql?query="+url.QueryEscape(`{ echo(message: "hi") }`), nil)
+		} else {
+			req = httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body))
+		}
+		for name, value := range tc.headers {
+			req.Header.Set(name, value)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		blocked := rec.Code == http.StatusBadRequest && strings.Contains(rec.Body.String(), "Cross-Site Request Forgery")
+		if blocked != tc.blocked {
+			t.Errorf("%s: expected blocked=%v, got %d %s", tc.name, tc.blocked, rec.Code, rec.Body)
+		}
+	}
+}
+
+// Batching Tests
+
+func setupBatchServer(t *testing.T, maxBatchSize int) string {
+	t.Helper()
+	srv := handler.New(graph.NewExecutableSchema(graph.Config{Resolvers: graph.NewResolver()}))
+	srv.AddTransport(graph.BatchPOST{MaxBatchSize: maxBatchSize})
+	srv.AddTransport(transport.POST{})
+	server := httptest.NewServer(srv)
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func postJSON(t *testing.T, url, body string) (int, []byte) {
+	t.Helper()
+	resp, err := http.Post(url, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read response: %v", err)
+	}
+	return resp.StatusCode, data
+}
+
+func TestBatchPOST_ReturnsResponsesInOrder(t *testing.T) {
+	url := setupBatchServer(t, 0)
+
+	status, body := postJSON(t, url, `[
+		{"query": "{ echoWithDelay(message: \"first\", delayMs: 50) }"},
+		{"query": "query Second($m: String!) { echo(message: $m) }", "variables": {"m": "second"}},
+		{"query": "{ unknownField }"}
+	]`)
+	if status != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", status, body)
+	}
+
+	var responses []map[string]any
+	if err := json.Unmarshal(body, &responses); err != nil {
+		t.Fatalf("expected an array of responses: %v (%s)", err, body)
+	}
+	if len(responses) != 3 {
+		t.Fatalf("expected 3 responses, got %d", len(responses))
+	}
+	if data, _ := responses[0]["data"].(map[string]any); data["echoWithDelay"] != "first" {
+		t.Errorf("unexpected first response %v", responses[0])
+	}
+	if data, _ := responses[1]["data"].(map[string]any); data["echo"] != "second" {
+		t.Errorf("unexpected second response %v", responses[1])
+	}
+	if responses[2]["errors"] == nil {
+		t.Errorf("expected third response to carry a validation error, got %v", responses[2])
+	}
+
+	// Plain (non-array) bodies are still handled by the POST transport
+	status, body = postJSON(t, url, `{"query": "{ echo(message: \"single\") }"}`)
+	if status != http.StatusOK || !strings.Contains(string(body), `"echo":"single"`) {
+		t.Errorf("expected single response, got %d: %s", status, body)
+	}
+}
+
+func TestBatchPOST_RejectsInvalidBatchSize(t *testing.T) {
+	url := setupBatchServer(t, 2)
+
+	for _, body := range []string{
+		`[]`,
+		`[{"query": "{ echoNull }"}, {"query": "{ echoNull }"}, {"query": "{ echoNull }"}]`,
+	} {
+		status, resp := postJSON(t, url, body)
+		if status != http.StatusBadRequest {
+			t.Errorf("expected status 400 for %s, got %d: %s", body, status, resp)
+		}
+	}
+}
+
+// Tracing Tests
+
+func TestTracer_RecordsOperationAndResolverSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	srv := handler.New(graph.NewExecutableSchema(graph.Config{Resolvers: graph.NewResolver()}))
+	srv.AddTransport(transport.POST{})
+	srv.Use(graph.Tracer{TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))})
+	c := client.New(srv)
+
+	if _, err := c.RawPost(`query Hello { echo(message: "hi") fail: echoError(message: "boom") }`); err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	op, ok := spans["query Hello"]
+	if !ok {
+		t.Fatalf("expected operation span, got %v", spans)
+	}
+	if op.Status().Code != codes.Error {
+		t.Errorf("expected operation span to carry the error status, got %v", op.Status())
+	}
+
+	for name, wantErr := range map[string]bool{"Query.echo": false, "Query.echoError": true} {
+		span, ok := spans[name]
+		if !ok {
+			t.Fatalf("expected resolver span %q, got %v", name, spans)
+		}
+		if span.Parent().SpanID() != op.SpanContext().SpanID() {
+			t.Errorf("expected %q to be a child of the operation span", name)
+		}
+		if gotErr := span.Status().Code == codes.Error; gotErr != wantErr {
+			t.Errorf("expected %q error status %v, got %v", name, wantErr, span.Status())
+		}
+	}
+}
+
+func TestApolloTracing_ReportsResolverTimings(t *testing.T) {
+	srv := handler.New(graph.NewExecutableSchema(graph.Config{Resolvers: graph.NewResolver()}))
+	srv.AddTransport(transport.POST{})
+	srv.Use(apollotracing.Tracer{})
+	c := client.New(srv)
+
+	resp, err := c.RawPost(`query { echo(message: "hi") }`)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	tracing, ok := resp.Extensions["tracing"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected tracing extension, got %v", resp.Extensions)
+	}
+	if tracing["version"] != float64(1) {
+		t.Errorf("expected tracing version 1, got %v", tracing["version"])
+	}
+	execution, _ := tracing["execution"].(map[string]any)
+	resolvers, _ := execution["resolvers"].([]any)
+	if len(resolvers) != 1 {
+		t.Fatalf("expected 1 resolver timing, got %v", resolvers)
+	}
+	if path := resolvers[0].(map[string]any)["path"]; !reflect.DeepEqual(path, []any{"echo"}) {
+		t.Errorf("expected path [echo], got %v", path)
+	}
+}
+
+func TestChaos_InjectsFaultsPerOperation(t *testing.T) {
+	engine, err := chaos.New(chaos.Config{Profile: chaos.DefaultProfile()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.SetState(chaos.State{
+		Enabled: true,
+		Global:  chaos.DefaultProfile(),
+		Rules: []chaos.Rule{
+			{Match: "query Fail", Profile: chaos.Profile{LatencyRate: 1, ErrorRate: 1, ErrorStatus: 500}},
+			{Match: "Slow", Profile: chaos.Profile{LatencyMS: 50, LatencyRate: 1, ErrorStatus: 503}},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	srv := handler.New(graph.NewExecutableSchema(graph.Config{Resolvers: graph.NewResolver()}))
+	srv.AddTransport(transport.POST{})
+	srv.Use(graph.Chaos{Engine: engine})
+	c := client.New(srv)
+
+	errs := postForErrors(t, c, `query Fail { echo(message: "hi") }`)
+	if len(errs) != 1 || errs[0].Extensions["code"] != "CHAOS_INJECTED_ERROR" || errs[0].Extensions["status"] != float64(500) {
+		t.Errorf("expected an injected error, got %+v", errs)
+	}
+
+	start := time.Now()
+	var resp struct{ Echo string }
+	c.MustPost(`query Slow { echo(message: "hi") }`, &resp)
+	if resp.Echo != "hi" || time.Since(start) < 50*time.Millisecond {
+		t.Errorf("expected a delayed echo, got %q after %v", resp.Echo, time.Since(start))
+	}
+
+	// Other operations are not affected
+	c.MustPost(`query { echo(message: "hi") }`, &resp)
+}
+
+// Mutation Tests
+
+func TestCreateMessage_CreatesAndReturnsMessage(t *testing.T) {
+	c := setupTestClient(t)
+
+	var resp struct {
+		CreateMessage struct {
+			ID        string
+			Text      string
+			CreatedAt string
+		}
+	}
+	c.MustPost(`mutation { createMessage(text: "hello world") { id text createdAt } }`, &resp)
+
+	if resp.CreateMessage.ID == "" {
+		t.Error("expected non-empty ID")
+	}
+	if resp.CreateMessage.Text != "hello world" {
+		t.Errorf("expected text 'hello world', got %q", resp.CreateMessage.Text)
+	}
+	if resp.CreateMessage.CreatedAt == "" {
+		t.Error("expected non-empty createdAt")
+	}
+}
+
+func TestUpdateMessage_UpdatesExistingMessage(t *testing.T) {
+	c := setupTestClient(t)
+
+	// Create a message first
+	var createResp struct {
+		CreateMessage struct {
+			ID   string
+			Text string
+		}
+	}
+	c.MustPost(`mutation { createMessage(text: "original") { id text } }`, &createResp)
+
+	// Update the message
+	var updateResp struct {
+		UpdateMessage struct {
+			ID   string
+			Text string
+		}
+	}
+	query := `mutation { updateMessage(id: "` + createResp.CreateMessage.ID + `", text: "updated") { id text } }`
+	c.MustPost(query, &updateResp)
+
+	if updateResp.UpdateMessage.ID != createResp.CreateMessage.ID {
+		t.Errorf("expected ID %q, got %q", createResp.CreateMessage.ID, updateResp.UpdateMessage.ID)
+	}
+	if updateResp.UpdateMessage.Text != "updated" {
+		t.Errorf("expected text 'updated', got %q", updateResp.UpdateMessage.Text)
+	}
+}
+
+func TestUpdateMessage_ReturnsErrorForNonExistentID(t *testing.T) {
+	c := setupTestClient(t)
+
+	var resp struct {
+		UpdateMessage *struct {
+			ID   string
+			Text string
+		}
+	}
+	err := c.Post(`mutation { updateMessage(id: "non-existent", text: "updated") { id text } }`, &resp)
+
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func TestDeleteMessage_ReturnsTrueForExistingMessage(t *testing.T) {
+	c := setupTestClient(t)
+
+	// Create a message first
+	var createResp struct {
+		CreateMessage struct {
+			ID string
+		}
+	}
+	c.MustPost(`mutation { createMessage(text: "to delete") { id } }`, &createResp)
+
+	// Delete the message
+	var deleteResp struct {
+		DeleteMessage bool
+	}
+	query := `mutation { deleteMessage(id: "` + createResp.CreateMessage.ID + `") }`
+	c.MustPost(query, &deleteResp)
+
+	if !deleteResp.DeleteMessage {
+		t.Error("expected deleteMessage to return true for existing message")
+	}
+}
+
+func TestDeleteMessage_ReturnsFalseForNonExistentID(t *testing.T) {
+	c := setupTestClient(t)
+
+	var resp struct {
+		DeleteMessage bool
+	}
+	c.MustPost(`mutation { deleteMessage(id: "non-existent") }`, &resp)
+
+	if resp.DeleteMessage {
+		t.Error("expected deleteMessage to return false for non-existent ID")
+	}
+}
+
+func TestBatchCreateMessages_CreatesMultipleMessages(t *testing.T) {
+	c := setupTestClient(t)
+
+	var resp struct {
+		BatchCreateMessages []struct {
+			ID        string
+			Text      string
+			CreatedAt string
+		}
+	}
+	c.MustPost(`mutation { batchCreateMessages(texts: ["first", "second", "third"]) { id text createdAt } }`, &resp)
+
+	if len(resp.BatchCreateMessages) != 3 {
+		t.Fatalf("expected 3 messages, got %d", len(resp.BatchCreateMessages))
+	}
+
+	expectedTexts := []string{"first", "second", "third"}
 	for i, msg := range resp.BatchCreateMessages {
 		if msg.ID == "" {
 			t.Errorf("expected non-empty ID at index %d", i)
@@ -507,6 +2080,90 @@ func TestBatchCreateMessages_EmptyList(t *testing.T) {
 	}
 }
 
+func createTempFile(t *testing.T, name, content string) *os.File {
+	t.Helper()
+	f, err := os.Create(filepath.Join(t.TempDir(), name))
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	if _, err := f.WriteString(content); err != nil {
+		t.Fatalf("failed to write temp file: %v", err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		t.Fatalf("failed to seek temp file: %v", err)
+	}
+	t.Cleanup(func() { _ = f.Close() })
+	return f
+}
+
+func TestUploadFile_EchoesMetadata(t *testing.T) {
+	c := setupTestClient(t)
+	file := createTempFile(t, "hello.txt", "hello world")
+
+	var resp struct {
+		UploadFile struct {
+			Filename    string
+			Size        int
+			ContentType *string
+			Sha256      string
+		}
+	}
+	c.MustPost(
+		`mutation($file: Upload!) { uploadFile(file: $file) { filename size contentType sha256 } }`,
+		&resp,
+		client.Var("file", file),
+		client.WithFiles(),
+	)
+
+	if resp.UploadFile.Filename != "hello.txt" {
+		t.Errorf("expected filename 'hello.txt', got %q", resp.UploadFile.Filename)
+	}
+	if resp.UploadFile.Size != 11 {
+		t.Errorf("expected size 11, got %d", resp.UploadFile.Size)
+	}
+	if resp.UploadFile.ContentType == nil || *resp.UploadFile.ContentType == "" {
+		t.Error("expected contentType to be set")
+	}
+	// sha256("hello world")
+	expected := "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"
+	if resp.UploadFile.Sha256 != expected {
+		t.Errorf("expected sha256 %q, got %q", expected, resp.UploadFile.Sha256)
+	}
+}
+
+func TestUploadFiles_EchoesEachFile(t *testing.T) {
+	c := setupTestClient(t)
+	files := []*os.File{
+		createTempFile(t, "a.txt", "a"),
+		createTempFile(t, "b.txt", "bb"),
+	}
+
+	var resp struct {
+		UploadFiles []struct {
+			Filename string
+			Size     int
+		}
+	}
+	c.MustPost(
+		`mutation($files: [Upload!]!) { uploadFiles(files: $files) { filename size } }`,
+		&resp,
+		client.Var("files", files),
+		client.WithFiles(),
+	)
+
+	if len(resp.UploadFiles) != 2 {
+		t.Fatalf("expected 2 files, got %d", len(resp.UploadFiles))
+	}
+	for i, want := range []struct {
+		filename string
+		size     int
+	}{{"a.txt", 1}, {"b.txt", 2}} {
+		if resp.UploadFiles[i].Filename != want.filename || resp.UploadFiles[i].Size != want.size {
+			t.Errorf("expected %s (%d bytes) at index %d, got %+v", want.filename, want.size, i, resp.UploadFiles[i])
+		}
+	}
+}
+
 // Subscription Tests
 
 func TestCountdown_EmitsCorrectSequence(t *testing.T) {
@@ -541,6 +2198,33 @@ func TestCountdown_EmitsCorrectSequence(t *testing.T) {
 	}
 }
 
+func TestCountdown_OverSSE(t *testing.T) {
+	c := setupTestClient(t)
+
+	sse := c.SSE(context.Background(), `subscription { countdown(from: 1) }`)
+	defer func() { _ = sse.Close() }()
+
+	for _, want := range []float64{1, 0} {
+		var resp client.SSEResponse
+		if err := sse.Next(&resp); err != nil {
+			t.Fatalf("failed to read SSE event: %v", err)
+		}
+		data, _ := resp.Data.(map[string]any)
+		if data["countdown"] != want {
+			t.Errorf("expected %v, got %v", want, data["countdown"])
+		}
+	}
+
+	// The stream ends with a complete event
+	var resp client.SSEResponse
+	if err := sse.Next(&resp); err != nil {
+		t.Fatalf("expected complete event, got %v", err)
+	}
+	if resp.Data != nil {
+		t.Errorf("expected no more values, got %v", resp.Data)
+	}
+}
+
 func TestHeartbeat_EmitsTimestamps(t *testing.T) {
 	resolver := setupTestResolver(t)
 	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
@@ -657,3 +2341,335 @@ done:
 		t.Fatalf("expected 2 messages, got %d", len(received))
 	}
 }
+
+// WebSocket Transport Tests
+
+type wsMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+func TestPublish_FansOutToMatchingTopics(t *testing.T) {
+	resolver := setupTestResolver(t)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	subResolver := resolver.Subscription()
+	patterns := []string{"orders.eu.created", "orders.*.created", "orders.#", "#", "payments.#"}
+	channels := make(map[string]<-chan *model.TopicEvent, len(patterns))
+	for _, pattern := range patterns {
+		ch, err := subResolver.Subscribe(ctx, pattern)
+		if err != nil {
+			t.Fatalf("subscribe %q: unexpected error: %v", pattern, err)
+		}
+		channels[pattern] = ch
+	}
+
+	mutResolver := resolver.Mutation()
+	for topic, want := range map[string]int{"orders.eu.created": 4, "orders.us.shipped": 2, "orders": 2} {
+		delivered, err := mutResolver.Publish(ctx, topic, map[string]any{"topic": topic})
+		if err != nil {
+			t.Fatalf("publish %q: unexpected error: %v", topic, err)
+		}
+		if delivered != want {
+			t.Errorf("publish %q: expected %d subscribers, got %d", topic, want, delivered)
+		}
+	}
+
+	for pattern, want := range map[string]int{"orders.eu.created": 1, "orders.*.created": 1, "orders.#": 3, "#": 3, "payments.#": 0} {
+		if got := len(channels[pattern]); got != want {
+			t.Errorf("pattern %q: expected %d events, got %d", pattern, want, got)
+		}
+	}
+
+	event := <-channels["orders.*.created"]
+	if event.Topic != "orders.eu.created" || event.Payload.(map[string]any)["topic"] != "orders.eu.created" {
+		t.Errorf("unexpected event %+v", event)
+	}
+}
+
+func TestPublish_RejectsInvalidTopics(t *testing.T) {
+	resolver := setupTestResolver(t)
+	ctx := context.Background()
+
+	for _, topic := range []string{"", "orders..created", "orders.*", "orders.#"} {
+		if _, err := resolver.Mutation().Publish(ctx, topic, nil); err == nil {
+			t.Errorf("publish %q: expected an error", topic)
+		}
+	}
+	for _, pattern := range []string{"", "orders.", "#.created"} {
+		if _, err := resolver.Subscription().Subscribe(ctx, pattern); err == nil {
+			t.Errorf("subscribe %q: expected an error", pattern)
+		}
+	}
+}
+
+func setupWebsocketServer(t *testing.T, ws graph.WebsocketTransport) string {
+	t.Helper()
+	srv := handler.New(graph.NewExecutableSchema(graph.Config{Resolvers: graph.NewResolver()}))
+	srv.AddTransport(ws)
+	server := httptest.NewServer(srv)
+	t.Cleanup(server.Close)
+	return "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+func dialGraphQLWebsocket(t *testing.T, url string, subprotocols ...string) *websocket.Conn {
+	t.Helper()
+	dialer := websocket.Dialer{Subprotocols: subprotocols}
+	conn, _, err := dialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	return conn
+}
+
+// readWebsocketMessage returns the next message, skipping keep-alives
+func readWebsocketMessage(t *testing.T, conn *websocket.Conn) wsMessage {
+	t.Helper()
+	for {
+		var msg wsMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatalf("read failed: %v", err)
+		}
+		if msg.Type != "ka" && msg.Type != "ping" && msg.Type != "pong" {
+			return msg
+		}
+	}
+}
+
+func TestWebsocketTransport_SupportsBothProtocols(t *testing.T) {
+	url := setupWebsocketServer(t, graph.WebsocketTransport{
+		Websocket: transport.Websocket{InitFunc: graph.NewInitFunc(0)},
+	})
+
+	tests := []struct {
+		protocol  string
+		subscribe string
+		next      string
+	}{
+		{graph.ProtocolGraphQLWS, "start", "data"},
+		{graph.ProtocolGraphQLTransportWS, "subscribe", "next"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.protocol, func(t *testing.T) {
+			conn := dialGraphQLWebsocket(t, url, tt.protocol)
+			if conn.Subprotocol() != tt.protocol {
+				t.Fatalf("expected subprotocol %q, got %q", tt.protocol, conn.Subprotocol())
+			}
+
+			init := wsMessage{Type: "connection_init", Payload: json.RawMessage(`{"Authorization":"Bearer token"}`)}
+			if err := conn.WriteJSON(init); err != nil {
+				t.Fatalf("write failed: %v", err)
+			}
+			ack := readWebsocketMessage(t, conn)
+			if ack.Type != "connection_ack" {
+				t.Fatalf("expected connection_ack, got %+v", ack)
+			}
+			var payload map[string]any
+			if err := json.Unmarshal(ack.Payload, &payload); err != nil || payload["Authorization"] != "Bearer token" {
+				t.Errorf("expected init payload echoed in ack, got %s", ack.Payload)
+			}
+
+			start := wsMessage{ID: "1", Type: tt.subscribe, Payload: json.RawMessage(`{"query":"subscription { countdown(from: 1) }"}`)}
+			if err := conn.WriteJSON(start); err != nil {
+				t.Fatalf("write failed: %v", err)
+			}
+			for _, want := range []string{`{"data":{"countdown":1}}`, `{"data":{"countdown":0}}`} {
+				msg := readWebsocketMessage(t, conn)
+				if msg.Type != tt.next || msg.ID != "1" || string(msg.Payload) != want {
+					t.Errorf("expected %s %s, got %+v (%s)", tt.next, want, msg, msg.Payload)
+				}
+			}
+			if msg := readWebsocketMessage(t, conn); msg.Type != "complete" {
+				t.Errorf("expected complete, got %+v", msg)
+			}
+		})
+	}
+}
+
+func TestWebsocketTransport_RejectsDisabledProtocol(t *testing.T) {
+	url := setupWebsocketServer(t, graph.WebsocketTransport{DisableGraphQLWS: true})
+
+	// No subprotocol falls back to graphql-ws, which is disabled too
+	for _, subprotocols := range [][]string{{graph.ProtocolGraphQLWS}, nil} {
+		conn := dialGraphQLWebsocket(t, url, subprotocols...)
+		_, _, err := conn.ReadMessage()
+		if !websocket.IsCloseError(err, 4406) {
+			t.Errorf("expected close 4406 for %v, got %v", subprotocols, err)
+		}
+	}
+
+	conn := dialGraphQLWebsocket(t, url, graph.ProtocolGraphQLWS, graph.ProtocolGraphQLTransportWS)
+	if conn.Subprotocol() != graph.ProtocolGraphQLTransportWS {
+		t.Errorf("expected fallback to %q, got %q", graph.ProtocolGraphQLTransportWS, conn.Subprotocol())
+	}
+}
+
+func initGraphQLWebsocket(t *testing.T, conn *websocket.Conn, payload string) wsMessage {
+	t.Helper()
+	if err := conn.WriteJSON(wsMessage{Type: "connection_init", Payload: json.RawMessage(payload)}); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	ack := readWebsocketMessage(t, conn)
+	if ack.Type != "connection_ack" {
+		t.Fatalf("expected connection_ack, got %+v", ack)
+	}
+	return ack
+}
+
+func TestNewInitFunc_DelaysAck(t *testing.T) {
+	url := setupWebsocketServer(t, graph.WebsocketTransport{
+		Websocket: transport.Websocket{InitFunc: graph.NewInitFunc(0)},
+	})
+	conn := dialGraphQLWebsocket(t, url, graph.ProtocolGraphQLTransportWS)
+
+	start := time.Now()
+	initGraphQLWebsocket(t, conn, `{"ackDelayMs": 100}`)
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("expected ack after at least 100ms, got %v", elapsed)
+	}
+}
+
+func TestDisconnectAfter_ClosesSocketWithCode(t *testing.T) {
+	url := setupWebsocketServer(t, graph.WebsocketTransport{})
+
+	tests := []struct {
+		name   string
+		code   int
+		reason string
+	}{
+		{"close frame", 4321, "bye"},
+		{"abnormal closure", websocket.CloseAbnormalClosure, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := dialGraphQLWebsocket(t, url, graph.ProtocolGraphQLTransportWS)
+			initGraphQLWebsocket(t, conn, `{}`)
+
+			query := fmt.Sprintf(`{"query":"subscription { disconnectAfter(events: 2, code: %d, reason: \"%s\", intervalMs: 10) }"}`, tt.code, tt.reason)
+			if err := conn.WriteJSON(wsMessage{ID: "1", Type: "subscribe", Payload: json.RawMessage(query)}); err != nil {
+				t.Fatalf("write failed: %v", err)
+			}
+			for _, want := range []string{`{"data":{"disconnectAfter":1}}`, `{"data":{"disconnectAfter":2}}`} {
+				if msg := readWebsocketMessage(t, conn); msg.Type != "next" || string(msg.Payload) != want {
+					t.Errorf("expected next %s, got %+v (%s)", want, msg, msg.Payload)
+				}
+			}
+
+			var msg wsMessage
+			err := conn.ReadJSON(&msg)
+			var closeErr *websocket.CloseError
+			if !errors.As(err, &closeErr) || closeErr.Code != tt.code || (tt.reason != "" && closeErr.Text != tt.reason) {
+				t.Errorf("expected close %d %q, got %+v (%v)", tt.code, tt.reason, msg, err)
+			}
+		})
+	}
+}
+
+func TestDrainer_ReadyHandler(t *testing.T) {
+	drainer := graph.NewDrainer()
+	adm := admin.New("echo-graphql", nil)
+	readyz := drainer.ReadyHandler(adm)
+
+	check := func(wantCode int, wantBody string) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		readyz.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		if rec.Code != wantCode || rec.Body.String() != wantBody {
+			t.Errorf("readyz = %d %s, want %d %s", rec.Code, rec.Body, wantCode, wantBody)
+		}
+	}
+
+	check(http.StatusOK, `{"status":"ok"}`)
+
+	// STARTUP_READY_DELAY_MS
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	lifecycle.Start(ctx, lifecycle.Config{ReadyDelay: time.Hour}, adm)
+	check(http.StatusServiceUnavailable, `{"status":"starting"}`)
+	adm.SetReady(true)
+
+	adm.SetHealthy(false)
+	check(http.StatusServiceUnavailable, `{"status":"unavailable"}`)
+	adm.SetHealthy(true)
+
+	drainer.Drain()
+	check(http.StatusServiceUnavailable, `{"status":"shutting down"}`)
+}
+
+func TestDrainer_TerminatesSubscriptions(t *testing.T) {
+	drainer := graph.NewDrainer()
+	srv := handler.New(graph.NewExecutableSchema(graph.Config{Resolvers: graph.NewResolver()}))
+	srv.AddTransport(transport.SSE{})
+	srv.AddTransport(graph.WebsocketTransport{Drainer: drainer})
+	srv.Use(drainer)
+	server := httptest.NewServer(srv)
+	t.Cleanup(server.Close)
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	conn := dialGraphQLWebsocket(t, url, graph.ProtocolGraphQLTransportWS)
+	initGraphQLWebsocket(t, conn, `{}`)
+	subscribe := wsMessage{ID: "1", Type: "subscribe", Payload: json.RawMessage(`{"query":"subscription { heartbeat(intervalMs: 10) }"}`)}
+	if err := conn.WriteJSON(subscribe); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if msg := readWebsocketMessage(t, conn); msg.Type != "next" {
+		t.Fatalf("expected next, got %+v", msg)
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(`{"query":"subscription { heartbeat(intervalMs: 10) }"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	sse, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("SSE request failed: %v", err)
+	}
+	defer func() { _ = sse.Body.Close() }()
+	events := bufio.NewScanner(sse.Body)
+	for events.Scan() && events.Text() != "event: next" {
+	}
+
+	if !drainer.Ready() {
+		t.Fatal("expected ready before shutdown")
+	}
+	if n := drainer.Terminate(); n != 2 {
+		t.Errorf("expected 2 terminated connections, got %d", n)
+	}
+	if drainer.Ready() {
+		t.Error("expected not ready after shutdown")
+	}
+
+	// WebSocket clients get a going-away close
+	for {
+		var msg wsMessage
+		err := conn.ReadJSON(&msg)
+		if err == nil {
+			continue
+		}
+		if !websocket.IsCloseError(err, websocket.CloseGoingAway) {
+			t.Errorf("expected close 1001, got %v", err)
+		}
+		break
+	}
+
+	// SSE streams complete once buffered events are delivered
+	completed := false
+	for events.Scan() {
+		if events.Text() == "event: complete" {
+			completed = true
+			break
+		}
+	}
+	if !completed {
+		t.Errorf("expected complete event, got %v", events.Err())
+	}
+
+	// New WebSocket connections are refused while draining
+	_, rejected, err := websocket.DefaultDialer.Dial(url, nil)
+	if err == nil || rejected == nil || rejected.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 while draining, got %v", err)
+	}
+}
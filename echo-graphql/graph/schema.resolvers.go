@@ -8,15 +8,50 @@ package graph
 import (
 	"context"
 	"fmt"
+	"math"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
 	"github.com/probitas-test/echo-servers/echo-graphql/graph/model"
+	"github.com/probitas-test/echo-servers/shared/version"
 	"github.com/vektah/gqlparser/v2/gqlerror"
 )
 
+// Slow returns the message after a delay for @defer tests
+func (r *deferredEchoResolver) Slow(ctx context.Context, obj *model.DeferredEcho, delayMs int) (string, error) {
+	select {
+	case <-time.After(time.Duration(delayMs) * time.Millisecond):
+		return obj.Message, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// Items returns count list items after a delay for @stream tests
+func (r *deferredEchoResolver) Items(ctx context.Context, obj *model.DeferredEcho, count int, delayMs int) ([]*model.EchoListItem, error) {
+	count, err := listCount(count)
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case <-time.After(time.Duration(delayMs) * time.Millisecond):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	items := make([]*model.EchoListItem, count)
+	for i := 0; i < count; i++ {
+		items[i] = &model.EchoListItem{
+			Index:   i,
+			Message: fmt.Sprintf("%s-%d", obj.Message, i),
+		}
+	}
+	return items, nil
+}
+
 // Authorization returns the Authorization header value
 func (r *headersResolver) Authorization(ctx context.Context, obj *model.Headers) (*string, error) {
 	if obj.Request == nil {
@@ -70,6 +105,20 @@ func (r *headersResolver) All(ctx context.Context, obj *model.Headers) ([]*model
 	return entries, nil
 }
 
+// Bearer decodes the Bearer JWT of the Authorization header and verifies its
+// signature when a JWKS is configured
+func (r *headersResolver) Bearer(ctx context.Context, obj *model.Headers) (*model.BearerToken, error) {
+	if obj.Request == nil {
+		return nil, nil
+	}
+	bearer := parseBearerToken(obj.Request.Header.Get("Authorization"), r.Clock)
+	if bearer == nil {
+		return nil, nil
+	}
+	verifyBearerToken(ctx, bearer, r.JWKS)
+	return bearer, nil
+}
+
 // CreateMessage creates a new message
 func (r *mutationResolver) CreateMessage(ctx context.Context, text string) (*model.Message, error) {
 	r.mu.Lock()
@@ -143,6 +192,54 @@ func (r *mutationResolver) BatchCreateMessages(ctx context.Context, texts []stri
 	return messages, nil
 }
 
+// UploadFile echoes back the metadata and checksum of an uploaded file
+func (r *mutationResolver) UploadFile(ctx context.Context, file graphql.Upload) (*model.UploadedFile, error) {
+	return describeUpload(file)
+}
+
+// UploadFiles echoes back the metadata and checksum of each uploaded file
+func (r *mutationResolver) UploadFiles(ctx context.Context, files []*graphql.Upload) ([]*model.UploadedFile, error) {
+	result := make([]*model.UploadedFile, len(files))
+	for i, file := range files {
+		uploaded, err := describeUpload(*file)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = uploaded
+	}
+	return result, nil
+}
+
+// ClearOperations clears the operation log and returns the number of removed records
+func (r *mutationResolver) ClearOperations(ctx context.Context) (int, error) {
+	if r.Operations == nil {
+		return 0, nil
+	}
+	return r.Operations.Clear(), nil
+}
+
+// ResetApqStats resets the APQ counters and returns the values before the reset
+func (r *mutationResolver) ResetApqStats(ctx context.Context) (*model.APQStats, error) {
+	if r.APQ == nil {
+		return &model.APQStats{}, nil
+	}
+	return r.APQ.Reset(), nil
+}
+
+// Publish delivers a payload to the subscribers of matching topic patterns
+func (r *mutationResolver) Publish(ctx context.Context, topic string, payload any) (int, error) {
+	delivered, err := r.topics.publish(topic, payload)
+	if err != nil {
+		return 0, badUserInput(err.Error())
+	}
+	return delivered, nil
+}
+
+// Schema returns the schema in SDL
+func (r *queryResolver) Schema(ctx context.Context) (string, error) {
+	return echoSDL(), nil
+}
+
 // Echo echoes back the input message
 func (r *queryResolver) Echo(ctx context.Context, message string) (string, error) {
 	return message, nil
@@ -168,6 +265,27 @@ func (r *queryResolver) EchoError(ctx context.Context, message string) (string,
 	}
 }
 
+// EchoErrorWithExtensions always returns an error with the given code and extensions
+func (r *queryResolver) EchoErrorWithExtensions(ctx context.Context, message string, code string, extensions any) (*string, error) {
+	gqlErr, err := newEchoError(message, &code, extensions, nil)
+	if err != nil {
+		return nil, err
+	}
+	return nil, gqlErr
+}
+
+// EchoErrors returns one GraphQL error per entry, optionally at a custom path
+func (r *queryResolver) EchoErrors(ctx context.Context, errors []*model.EchoErrorInput) (*string, error) {
+	for _, spec := range errors {
+		gqlErr, err := newEchoError(spec.Message, spec.Code, spec.Extensions, spec.Path)
+		if err != nil {
+			return nil, err
+		}
+		graphql.AddError(ctx, gqlErr)
+	}
+	return nil, nil
+}
+
 // EchoPartialError returns partial data with errors for messages containing "error"
 func (r *queryResolver) EchoPartialError(ctx context.Context, messages []string) ([]*model.EchoResult, error) {
 	results := make([]*model.EchoResult, len(messages))
@@ -222,10 +340,21 @@ func (r *queryResolver) EchoNested(ctx context.Context, message string, depth in
 	return current, nil
 }
 
+// EchoHuge returns a large string generated server-side for response size benchmarks
+func (r *queryResolver) EchoHuge(ctx context.Context, sizeKb int) (string, error) {
+	return newHugeString(sizeKb, r.MaxHugeSizeKb)
+}
+
+// EchoDeep returns a deeply nested JSON value for parser nesting limit tests
+func (r *queryResolver) EchoDeep(ctx context.Context, depth int, arrays bool) (any, error) {
+	return newDeepValue(depth, arrays, r.MaxDeepDepth)
+}
+
 // EchoList returns a list of n items for pagination/list handling tests
 func (r *queryResolver) EchoList(ctx context.Context, message string, count int) ([]*model.EchoListItem, error) {
-	if count < 0 {
-		count = 0
+	count, err := listCount(count)
+	if err != nil {
+		return nil, err
 	}
 	items := make([]*model.EchoListItem, count)
 	for i := 0; i < count; i++ {
@@ -237,6 +366,39 @@ func (r *queryResolver) EchoList(ctx context.Context, message string, count int)
 	return items, nil
 }
 
+// EchoConnection returns a Relay-style page of count items for cursor pagination tests
+func (r *queryResolver) EchoConnection(ctx context.Context, message string, count int, first *int, after *string, last *int, before *string) (*model.EchoConnection, error) {
+	return newConnection(message, count, first, after, last, before)
+}
+
+// EchoShape returns a Shape interface value of the given kind for fragment matching tests
+func (r *queryResolver) EchoShape(ctx context.Context, kind model.ShapeKind, size float64, id *string) (model.Shape, error) {
+	return newShape(kind, size, id), nil
+}
+
+// EchoShapes returns one Shape interface value per input
+func (r *queryResolver) EchoShapes(ctx context.Context, shapes []*model.ShapeInput) ([]model.Shape, error) {
+	results := make([]model.Shape, len(shapes))
+	for i, input := range shapes {
+		results[i] = newShape(input.Kind, input.Size, input.ID)
+	}
+	return results, nil
+}
+
+// EchoShapeUnion returns a ShapeResult union value of the given kind for fragment matching tests
+func (r *queryResolver) EchoShapeUnion(ctx context.Context, kind model.ShapeKind, size float64, id *string) (model.ShapeResult, error) {
+	return newShape(kind, size, id), nil
+}
+
+// EchoShapeUnions returns one ShapeResult union value per input
+func (r *queryResolver) EchoShapeUnions(ctx context.Context, shapes []*model.ShapeInput) ([]model.ShapeResult, error) {
+	results := make([]model.ShapeResult, len(shapes))
+	for i, input := range shapes {
+		results[i] = newShape(input.Kind, input.Size, input.ID)
+	}
+	return results, nil
+}
+
 // EchoNull always returns null for null handling tests
 func (r *queryResolver) EchoNull(ctx context.Context) (*string, error) {
 	return nil, nil
@@ -250,9 +412,72 @@ func (r *queryResolver) EchoOptional(ctx context.Context, message string, return
 	return &message, nil
 }
 
+// EchoDeferred returns an object with fast and slow fields for incremental delivery tests
+func (r *queryResolver) EchoDeferred(ctx context.Context, message string) (*model.DeferredEcho, error) {
+	return &model.DeferredEcho{Message: message}, nil
+}
+
+// ApqStats returns the APQ cache hit/miss counters
+func (r *queryResolver) ApqStats(ctx context.Context) (*model.APQStats, error) {
+	if r.APQ == nil {
+		return &model.APQStats{}, nil
+	}
+	return r.APQ.Stats(), nil
+}
+
+// EchoInput echoes back a complex input object verbatim
+func (r *queryResolver) EchoInput(ctx context.Context, input model.EchoValue) (*model.EchoValue, error) {
+	return &input, nil
+}
+
+// EchoInputs echoes back a list of complex input objects verbatim
+func (r *queryResolver) EchoInputs(ctx context.Context, inputs []*model.EchoValue) ([]*model.EchoValue, error) {
+	return inputs, nil
+}
+
+// EchoVariables returns the operation variables exactly as decoded by the server
+func (r *queryResolver) EchoVariables(ctx context.Context) (any, error) {
+	return graphql.GetOperationContext(ctx).Variables, nil
+}
+
+// LastOperations returns recently executed operations, newest first
+func (r *queryResolver) LastOperations(ctx context.Context, limit *int) ([]*model.OperationRecord, error) {
+	if r.Operations == nil {
+		return []*model.OperationRecord{}, nil
+	}
+	n := 0
+	if limit != nil {
+		n = *limit
+	}
+	return r.Operations.Last(n), nil
+}
+
+// QueryCost reports the computed complexity and depth of the current operation
+func (r *queryResolver) QueryCost(ctx context.Context) (*model.QueryCost, error) {
+	cost := &model.QueryCost{}
+	if stats := extension.GetComplexityStats(ctx); stats != nil {
+		cost.Complexity = stats.Complexity
+		if stats.ComplexityLimit != math.MaxInt {
+			cost.ComplexityLimit = &stats.ComplexityLimit
+		}
+	}
+	if stats := GetDepthStats(ctx); stats != nil {
+		cost.Depth = stats.Depth
+		if stats.DepthLimit > 0 {
+			cost.DepthLimit = &stats.DepthLimit
+		}
+	}
+	return cost, nil
+}
+
+// Version returns the build information of the server
+func (r *queryResolver) Version(ctx context.Context) (*version.Info, error) {
+	return &r.Build, nil
+}
+
 // MessageCreated subscribes to message creation events
 func (r *subscriptionResolver) MessageCreated(ctx context.Context) (<-chan *model.Message, error) {
-	ch := r.Subscribe()
+	ch := r.Resolver.Subscribe()
 
 	go func() {
 		<-ctx.Done()
@@ -324,6 +549,59 @@ func (r *subscriptionResolver) Heartbeat(ctx context.Context, intervalMs int) (<
 	return ch, nil
 }
 
+// DisconnectAfter emits events and then force-closes the WebSocket connection
+func (r *subscriptionResolver) DisconnectAfter(ctx context.Context, events int, code int, reason string, intervalMs int) (<-chan int, error) {
+	if !isWebSocket(ctx) {
+		return nil, badUserInput("disconnectAfter requires a WebSocket connection")
+	}
+	if !validCloseCode(code) {
+		return nil, badUserInput(fmt.Sprintf("invalid close code %d", code))
+	}
+	ch := make(chan int)
+
+	go func() {
+		defer close(ch)
+		for i := 1; i <= events; i++ {
+			select {
+			case <-ctx.Done():
+				return
+			case ch <- i:
+			}
+			select {
+			case <-time.After(time.Duration(intervalMs) * time.Millisecond):
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		select {
+		case <-time.After(disconnectGrace):
+		case <-ctx.Done():
+			return
+		}
+		_ = CloseSocket(ctx, code, reason)
+	}()
+
+	return ch, nil
+}
+
+// Subscribe receives events published to topics matching a pattern
+func (r *subscriptionResolver) Subscribe(ctx context.Context, topic string) (<-chan *model.TopicEvent, error) {
+	sub, err := r.topics.subscribe(topic)
+	if err != nil {
+		return nil, badUserInput(err.Error())
+	}
+
+	go func() {
+		<-ctx.Done()
+		r.topics.unsubscribe(sub)
+	}()
+
+	return sub.ch, nil
+}
+
+func (r *Resolver) DeferredEcho() DeferredEchoResolver { return &deferredEchoResolver{r} }
+
 func (r *Resolver) Headers() HeadersResolver { return &headersResolver{r} }
 
 func (r *Resolver) Mutation() MutationResolver { return &mutationResolver{r} }
@@ -332,6 +610,7 @@ func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
 
 func (r *Resolver) Subscription() SubscriptionResolver { return &subscriptionResolver{r} }
 
+type deferredEchoResolver struct{ *Resolver }
 type headersResolver struct{ *Resolver }
 type mutationResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
@@ -0,0 +1,57 @@
+package graph
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/formatter"
+)
+
+// SDL returns schema printed as SDL, without built-in definitions
+func SDL(schema *ast.Schema) string {
+	var buf strings.Builder
+	formatter.NewFormatter(&buf).FormatSchema(schema)
+	return buf.String()
+}
+
+// echoSDL is the SDL of the echo schema, served by the _schema query
+var echoSDL = sync.OnceValue(func() string {
+	return SDL(parsedSchema)
+})
+
+// NewSchemaHandler serves the SDL of schema (GET /schema.graphql) with a
+// content-hash ETag, so codegen pipelines can pull the schema and revalidate
+// with If-None-Match. Access follows policy like the introspection fields:
+// 404 when introspection is disabled, 401 without a valid token.
+func NewSchemaHandler(schema *ast.Schema, policy IntrospectionPolicy) http.Handler {
+	sdl := []byte(SDL(schema))
+	sum := sha256.Sum256(sdl)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if !policy.enabled() {
+			http.Error(w, "schema is unavailable: introspection is disabled", http.StatusNotFound)
+			return
+		}
+		if !policy.authorized(r.Header) {
+			http.Error(w, "schema is unavailable: introspection requires a valid "+policy.Header+" header", http.StatusUnauthorized)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Cache-Control", "no-cache")
+		http.ServeContent(w, r, "schema.graphql", time.Time{}, bytes.NewReader(sdl))
+	})
+}
@@ -0,0 +1,156 @@
+package graph
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/gorilla/websocket"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+
+	"github.com/probitas-test/echo-servers/shared/admin"
+)
+
+// shutdownReason is the close reason sent to WebSocket clients on shutdown
+const shutdownReason = "server shutting down"
+
+// Drainer tracks the long-lived operations http.Server.Shutdown does not end
+// on its own, so a graceful shutdown can terminate them: WebSocket
+// connections (hijacked, invisible to the server) are closed with 1001
+// (going away) and SSE subscriptions are completed. Once draining starts the
+// server reports not ready and new subscriptions are refused.
+type Drainer struct {
+	mu       sync.Mutex
+	draining bool
+	sockets  map[*socketCloser]struct{}
+	streams  map[uint64]context.CancelFunc
+	nextID   uint64
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationInterceptor
+} = &Drainer{}
+
+// NewDrainer creates a Drainer for a server that is ready
+func NewDrainer() *Drainer {
+	return &Drainer{
+		sockets: make(map[*socketCloser]struct{}),
+		streams: make(map[uint64]context.CancelFunc),
+	}
+}
+
+// Ready reports whether the server accepts new work (false once draining)
+func (d *Drainer) Ready() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return !d.draining
+}
+
+// ReadyHandler answers the readiness probe: {"status":"ok"}, or 503 with
+// the reason the server is not ready: "shutting down" once draining starts,
+// "starting" before the readiness delay of a ends and "unavailable" while a
+// is set unhealthy.
+func (d *Drainer) ReadyHandler(a *admin.Admin) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		status := "ok"
+		switch {
+		case !d.Ready():
+			status = "shutting down"
+		case !a.Ready():
+			status = "starting"
+		case !a.Healthy():
+			status = "unavailable"
+		}
+		if status != "ok" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_, _ = w.Write([]byte(`{"status":"` + status + `"}`))
+	})
+}
+
+// Drain marks the server as draining: Ready turns false and new WebSocket
+// connections and SSE subscriptions are refused. Open ones keep running
+// until Terminate.
+func (d *Drainer) Drain() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.draining = true
+}
+
+// Terminate drains the server and ends every open WebSocket connection and
+// SSE subscription, returning how many were ended
+func (d *Drainer) Terminate() int {
+	d.mu.Lock()
+	d.draining = true
+	sockets := d.sockets
+	streams := d.streams
+	d.sockets = make(map[*socketCloser]struct{})
+	d.streams = make(map[uint64]context.CancelFunc)
+	d.mu.Unlock()
+
+	for closer := range sockets {
+		_ = closer.close(websocket.CloseGoingAway, shutdownReason)
+	}
+	for _, cancel := range streams {
+		cancel()
+	}
+	return len(sockets) + len(streams)
+}
+
+// track registers a WebSocket connection until the returned func is called.
+// It reports false while draining.
+func (d *Drainer) track(closer *socketCloser) (func(), bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.draining {
+		return nil, false
+	}
+	d.sockets[closer] = struct{}{}
+	return func() {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		delete(d.sockets, closer)
+	}, true
+}
+
+func (d *Drainer) ExtensionName() string {
+	return "Drainer"
+}
+
+func (d *Drainer) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptOperation makes subscriptions outside WebSocket connections (SSE)
+// cancellable by Terminate. Subscriptions over WebSocket end with their
+// connection.
+func (d *Drainer) InterceptOperation(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+	op := graphql.GetOperationContext(ctx).Operation
+	if op == nil || op.Operation != ast.Subscription || isWebSocket(ctx) {
+		return next(ctx)
+	}
+
+	d.mu.Lock()
+	if d.draining {
+		d.mu.Unlock()
+		err := gqlerror.Errorf("server is shutting down")
+		err.Extensions = map[string]any{"code": "SERVICE_UNAVAILABLE"}
+		return graphql.OneShot(&graphql.Response{Errors: gqlerror.List{err}})
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	id := d.nextID
+	d.nextID++
+	d.streams[id] = cancel
+	d.mu.Unlock()
+
+	context.AfterFunc(ctx, func() {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		delete(d.streams, id)
+	})
+	return next(ctx)
+}
@@ -0,0 +1,59 @@
+package graph
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// DefaultMaxHugeSizeKb is the default largest echoHuge response (10 MiB)
+	DefaultMaxHugeSizeKb = 10 * 1024
+	// DefaultMaxDeepDepth is the default deepest echoDeep response
+	DefaultMaxDeepDepth = 1000
+
+	hugeFill = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+)
+
+// newHugeString returns a string of exactly sizeKb KiB of repeating
+// alphanumerics
+func newHugeString(sizeKb, maxSizeKb int) (string, error) {
+	if err := checkStressLimit("sizeKb", sizeKb, maxSizeKb); err != nil {
+		return "", err
+	}
+	size := sizeKb * 1024
+	return strings.Repeat(hugeFill, size/len(hugeFill)+1)[:size], nil
+}
+
+// newDeepValue returns a JSON value nested depth levels deep: objects of the
+// form {"level": n, "child": ...}, or arrays of the form [[...]]. The
+// innermost object has a null child; the innermost array is empty.
+func newDeepValue(depth int, arrays bool, maxDepth int) (any, error) {
+	if err := checkStressLimit("depth", depth, maxDepth); err != nil {
+		return nil, err
+	}
+
+	var value any
+	if arrays {
+		value = []any{}
+		for range depth - 1 {
+			value = []any{value}
+		}
+		return value, nil
+	}
+	for level := depth; level >= 1; level-- {
+		value = map[string]any{"level": level, "child": value}
+	}
+	return value, nil
+}
+
+// checkStressLimit validates a stress query size argument against its
+// configured maximum (0 or less = unlimited)
+func checkStressLimit(name string, value, limit int) error {
+	switch {
+	case value < 1:
+		return badUserInput(fmt.Sprintf("%s must be at least 1, got %d", name, value))
+	case limit > 0 && value > limit:
+		return badUserInput(fmt.Sprintf("%s %d exceeds the limit of %d", name, value, limit))
+	}
+	return nil
+}
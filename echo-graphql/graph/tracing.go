@@ -0,0 +1,113 @@
+package graph
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/99designs/gqlgen/graphql"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/probitas-test/echo-servers/echo-graphql/graph/model"
+)
+
+const tracerName = "github.com/probitas-test/echo-servers/echo-graphql/graph"
+
+// Tracer records an OpenTelemetry span per operation response and a child
+// span per resolver, so client-observed latency can be correlated with
+// server-side resolver timings. Operation spans are children of the HTTP
+// server span or, without one, of the W3C trace context of the request.
+type Tracer struct {
+	// TracerProvider creates the spans; the global provider is used when nil
+	TracerProvider trace.TracerProvider
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.ResponseInterceptor
+	graphql.FieldInterceptor
+} = Tracer{}
+
+func (Tracer) ExtensionName() string {
+	return "OpenTelemetry"
+}
+
+func (Tracer) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (t Tracer) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	if !graphql.HasOperationContext(ctx) {
+		return next(ctx)
+	}
+	opCtx := graphql.GetOperationContext(ctx)
+
+	// Nested in the HTTP server span when the handler is traced
+	kind := trace.SpanKindInternal
+	if sc := trace.SpanContextFromContext(ctx); !sc.IsValid() || sc.IsRemote() {
+		kind = trace.SpanKindServer
+		if r, ok := ctx.Value(model.RequestKey).(*http.Request); ok && !sc.IsValid() {
+			ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(r.Header))
+		}
+	}
+
+	name := "graphql"
+	attrs := []attribute.KeyValue{attribute.String("graphql.document", opCtx.RawQuery)}
+	if op := opCtx.Operation; op != nil {
+		name = string(op.Operation)
+		attrs = append(attrs, attribute.String("graphql.operation.type", string(op.Operation)))
+		if op.Name != "" {
+			name += " " + op.Name
+			attrs = append(attrs, attribute.String("graphql.operation.name", op.Name))
+		}
+	}
+
+	ctx, span := t.tracer().Start(ctx, name,
+		trace.WithSpanKind(kind),
+		trace.WithAttributes(attrs...),
+	)
+	defer span.End()
+
+	resp := next(ctx)
+	if resp != nil && len(resp.Errors) > 0 {
+		span.SetStatus(codes.Error, resp.Errors.Error())
+	}
+	return resp
+}
+
+func (t Tracer) InterceptField(ctx context.Context, next graphql.Resolver) (any, error) {
+	fc := graphql.GetFieldContext(ctx)
+	if fc == nil || !fc.IsResolver {
+		return next(ctx)
+	}
+
+	ctx, span := t.tracer().Start(ctx, fc.Object+"."+fc.Field.Name,
+		trace.WithAttributes(
+			attribute.String("graphql.field.name", fc.Field.Name),
+			attribute.String("graphql.field.alias", fc.Field.Alias),
+			attribute.String("graphql.field.path", fc.Path().String()),
+			attribute.String("graphql.field.type", fc.Field.Definition.Type.String()),
+		),
+	)
+	defer span.End()
+
+	res, err := next(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	} else if errs := graphql.GetFieldErrors(ctx, fc); len(errs) > 0 {
+		span.SetStatus(codes.Error, errs.Error())
+	}
+	return res, err
+}
+
+func (t Tracer) tracer() trace.Tracer {
+	tp := t.TracerProvider
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	return tp.Tracer(tracerName)
+}
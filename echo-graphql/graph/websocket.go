@@ -0,0 +1,242 @@
+package graph
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// ProtocolGraphQLWS is the legacy subscriptions-transport-ws subprotocol
+	ProtocolGraphQLWS = "graphql-ws"
+	// ProtocolGraphQLTransportWS is the graphql-ws library subprotocol
+	ProtocolGraphQLTransportWS = "graphql-transport-ws"
+
+	// closeSubprotocolNotAcceptable is the close code used by the graphql-ws
+	// library when no supported subprotocol was offered
+	closeSubprotocolNotAcceptable = 4406
+
+	// disconnectGrace lets the last event reach the client before the socket
+	// is closed
+	disconnectGrace = 100 * time.Millisecond
+)
+
+// WebsocketTransport is a transport.Websocket whose subprotocols can be
+// disabled individually. gqlgen always negotiates both graphql-ws and
+// graphql-transport-ws; a connection offering only disabled subprotocols is
+// accepted and immediately closed with 4406 (Subprotocol not acceptable).
+// A connection offering no subprotocol at all is treated as graphql-ws.
+// With a Drainer, connections are tracked for graceful shutdown and refused
+// with 503 while draining.
+type WebsocketTransport struct {
+	transport.Websocket
+	DisableGraphQLWS          bool
+	DisableGraphQLTransportWS bool
+	Drainer                   *Drainer
+}
+
+var _ graphql.Transport = WebsocketTransport{}
+
+func (t WebsocketTransport) Do(w http.ResponseWriter, r *http.Request, exec graphql.GraphExecutor) {
+	offered := websocket.Subprotocols(r)
+	if len(offered) == 0 {
+		// gqlgen falls back to graphql-ws for clients without a subprotocol
+		if t.DisableGraphQLWS {
+			t.reject(w, r)
+			return
+		}
+		t.serve(w, r, exec)
+		return
+	}
+
+	var accepted []string
+	for _, protocol := range offered {
+		if !t.disabled(protocol) {
+			accepted = append(accepted, protocol)
+		}
+	}
+	if len(accepted) == 0 {
+		t.reject(w, r)
+		return
+	}
+
+	r.Header.Set("Sec-WebSocket-Protocol", strings.Join(accepted, ", "))
+	t.serve(w, r, exec)
+}
+
+// serve hands the connection to gqlgen, recording the hijacked network
+// connection so resolvers can force-close the socket (see CloseSocket)
+func (t WebsocketTransport) serve(w http.ResponseWriter, r *http.Request, exec graphql.GraphExecutor) {
+	closer := &socketCloser{}
+	if t.Drainer != nil {
+		untrack, ok := t.Drainer.track(closer)
+		if !ok {
+			http.Error(w, shutdownReason, http.StatusServiceUnavailable)
+			return
+		}
+		defer untrack()
+	}
+	r = r.WithContext(context.WithValue(r.Context(), socketCloserKey{}, closer))
+	t.Websocket.Do(hijackRecorder{ResponseWriter: w, closer: closer}, r, exec)
+}
+
+func (t WebsocketTransport) disabled(protocol string) bool {
+	var disabled []string
+	if t.DisableGraphQLWS {
+		disabled = append(disabled, ProtocolGraphQLWS)
+	}
+	if t.DisableGraphQLTransportWS {
+		disabled = append(disabled, ProtocolGraphQLTransportWS)
+	}
+	return slices.Contains(disabled, protocol)
+}
+
+func (t WebsocketTransport) reject(w http.ResponseWriter, r *http.Request) {
+	upgrader := t.Upgrader
+	upgrader.Subprotocols = nil
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer func() { _ = conn.Close() }()
+
+	msg := websocket.FormatCloseMessage(closeSubprotocolNotAcceptable, "Subprotocol not acceptable")
+	_ = conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(time.Second))
+}
+
+// NewInitFunc returns a WebsocketInitFunc that accepts every connection and
+// echoes the connection_init payload back in the connection_ack payload, so
+// clients can verify the connection params (e.g. auth tokens) they send.
+// The ack is delayed by ackDelay, or by the ackDelayMs connection param when
+// present.
+func NewInitFunc(ackDelay time.Duration) transport.WebsocketInitFunc {
+	return func(ctx context.Context, payload transport.InitPayload) (context.Context, *transport.InitPayload, error) {
+		delay := ackDelay
+		if ms, ok := payload["ackDelayMs"].(float64); ok {
+			delay = time.Duration(ms) * time.Millisecond
+		}
+		if delay > 0 {
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return ctx, nil, ctx.Err()
+			}
+		}
+
+		if len(payload) == 0 {
+			return ctx, nil, nil
+		}
+		return ctx, &payload, nil
+	}
+}
+
+// CloseSocket closes the WebSocket connection serving ctx with the given
+// close code and reason. Code 1006 (abnormal closure) drops the connection
+// without a close frame.
+func CloseSocket(ctx context.Context, code int, reason string) error {
+	closer, ok := ctx.Value(socketCloserKey{}).(*socketCloser)
+	if !ok {
+		return errors.New("not a WebSocket connection")
+	}
+	return closer.close(code, reason)
+}
+
+// isWebSocket reports whether ctx belongs to a WebSocket connection
+func isWebSocket(ctx context.Context) bool {
+	_, ok := ctx.Value(socketCloserKey{}).(*socketCloser)
+	return ok
+}
+
+// validCloseCode reports whether code may be used with CloseSocket
+func validCloseCode(code int) bool {
+	switch {
+	case code >= 1000 && code <= 1003,
+		code >= 1006 && code <= 1014,
+		code >= 3000 && code <= 4999:
+		return true
+	}
+	return false
+}
+
+type socketCloserKey struct{}
+
+// socketCloser writes a close frame on a hijacked WebSocket connection
+type socketCloser struct {
+	mu   sync.Mutex
+	conn *lockedConn
+}
+
+func (c *socketCloser) set(conn *lockedConn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.conn = conn
+}
+
+func (c *socketCloser) close(code int, reason string) error {
+	c.mu.Lock()
+	conn := c.conn
+	c.mu.Unlock()
+	if conn == nil {
+		return errors.New("connection not established")
+	}
+	defer func() { _ = conn.Close() }()
+
+	if code == websocket.CloseAbnormalClosure {
+		return nil
+	}
+
+	// Control frame payloads are limited to 125 bytes, 2 of which are the code
+	if len(reason) > 123 {
+		reason = reason[:123]
+	}
+	payload := websocket.FormatCloseMessage(code, reason)
+	frame := append([]byte{0x88, byte(len(payload))}, payload...) // FIN + close opcode, unmasked
+	if _, err := conn.Write(frame); err != nil {
+		return fmt.Errorf("failed to write close frame: %w", err)
+	}
+	return nil
+}
+
+// lockedConn serializes writes so a close frame written by socketCloser
+// never interleaves with frames written by gorilla/websocket
+type lockedConn struct {
+	net.Conn
+	mu sync.Mutex
+}
+
+func (c *lockedConn) Write(b []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.Conn.Write(b)
+}
+
+// hijackRecorder hands the hijacked connection to a socketCloser
+type hijackRecorder struct {
+	http.ResponseWriter
+	closer *socketCloser
+}
+
+func (w hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking")
+	}
+	conn, brw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+	locked := &lockedConn{Conn: conn}
+	w.closer.set(locked)
+	return locked, brw, nil
+}
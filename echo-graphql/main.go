@@ -2,80 +2,39 @@ package main
 
 import (
 	"context"
-	_ "embed"
 	"log"
-	"net/http"
-	"time"
-
-	"github.com/99designs/gqlgen/graphql/handler"
-	"github.com/99designs/gqlgen/graphql/handler/extension"
-	"github.com/99designs/gqlgen/graphql/handler/transport"
-	"github.com/99designs/gqlgen/graphql/playground"
-	"github.com/gorilla/websocket"
-
-	"github.com/probitas-test/echo-servers/echo-graphql/graph"
-	"github.com/probitas-test/echo-servers/echo-graphql/graph/model"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/probitas-test/echo-servers/echo-graphql/app"
+	"github.com/probitas-test/echo-servers/shared/config"
+	"github.com/probitas-test/echo-servers/shared/logging"
+	"github.com/probitas-test/echo-servers/shared/network"
 )
 
-//go:embed docs/api.md
-var apiDocs string
-
-// requestContextMiddleware injects the http.Request into context for header access
-func requestContextMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ctx := context.WithValue(r.Context(), model.RequestKey, r)
-		next.ServeHTTP(w, r.WithContext(ctx))
-	})
-}
-
 func main() {
-	cfg := LoadConfig()
-
-	resolver := graph.NewResolver()
-	srv := handler.New(graph.NewExecutableSchema(graph.Config{
-		Resolvers: resolver,
-	}))
-
-	// HTTP transports
-	srv.AddTransport(transport.Options{})
-	srv.AddTransport(transport.GET{})
-	srv.AddTransport(transport.POST{})
-
-	// WebSocket transport for subscriptions
-	srv.AddTransport(transport.Websocket{
-		Upgrader: websocket.Upgrader{
-			CheckOrigin: func(r *http.Request) bool {
-				return true
-			},
-			ReadBufferSize:  1024,
-			WriteBufferSize: 1024,
-		},
-		KeepAlivePingInterval: 10 * time.Second,
-	})
-
-	// Enable introspection
-	srv.Use(extension.Introspection{})
-
-	// Health check endpoint
-	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		_, _ = w.Write([]byte(`{"status":"ok"}`))
-	})
-
-	// API documentation endpoint
-	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
-		_, _ = w.Write([]byte(apiDocs))
-	})
+	// Environment, .env file and CONFIG_FILE
+	cfg, err := app.LoadConfig(config.Load())
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	// GraphQL playground
-	http.Handle("/playground", playground.Handler("GraphQL Playground", "/graphql"))
+	// Structured logging; the log package writes through it as well
+	if err := logging.Setup(cfg.Logging); err != nil {
+		log.Fatalf("Invalid logging configuration: %v", err)
+	}
 
-	// GraphQL endpoint (with request context middleware for header access)
-	http.Handle("/graphql", requestContextMiddleware(srv))
+	// IPv4-only, IPv6-only or dual-stack listeners
+	if err := network.Setup(cfg.Network); err != nil {
+		log.Fatalf("Invalid network configuration: %v", err)
+	}
 
-	log.Printf("Starting server on %s", cfg.Addr())
-	if err := http.ListenAndServe(cfg.Addr(), nil); err != nil {
-		log.Fatalf("Failed to serve: %v", err)
+	// Serve until SIGINT or SIGTERM, then shut down gracefully
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err = app.Run(ctx, cfg)
+	stop()
+	if err != nil {
+		log.Fatal(err)
 	}
 }
@@ -0,0 +1,319 @@
+// Package app runs echo-grpc: LoadConfig reads its settings and Run serves
+// it until its context ends, for the echo-grpc binary and echo-all.
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"log/slog"
+	"time"
+
+	"buf.build/go/protovalidate"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/probitas-test/echo-servers/echo-grpc/server"
+	pb "github.com/probitas-test/echo-servers/proto/echo/v1"
+	"github.com/probitas-test/echo-servers/shared/admin"
+	"github.com/probitas-test/echo-servers/shared/bandwidth"
+	"github.com/probitas-test/echo-servers/shared/chaos"
+	"github.com/probitas-test/echo-servers/shared/clients"
+	"github.com/probitas-test/echo-servers/shared/clock"
+	"github.com/probitas-test/echo-servers/shared/connlimit"
+	"github.com/probitas-test/echo-servers/shared/credentials"
+	"github.com/probitas-test/echo-servers/shared/lifecycle"
+	"github.com/probitas-test/echo-servers/shared/metrics"
+	"github.com/probitas-test/echo-servers/shared/mirror"
+	"github.com/probitas-test/echo-servers/shared/network"
+	"github.com/probitas-test/echo-servers/shared/ratelimit"
+	"github.com/probitas-test/echo-servers/shared/recording"
+	"github.com/probitas-test/echo-servers/shared/script"
+	"github.com/probitas-test/echo-servers/shared/tracing"
+	"github.com/probitas-test/echo-servers/shared/version"
+)
+
+// Run serves echo-grpc with cfg until ctx is done, then shuts it down
+// gracefully. Logging and the address family are set up by the caller, as
+// they are shared by the servers of a process.
+func Run(ctx context.Context, cfg *Config) error {
+	// Started servers stop when Run returns early with an error
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Simulated slow startup: no port opens before the startup delay
+	if err := cfg.Lifecycle.Validate(); err != nil {
+		return fmt.Errorf("invalid lifecycle configuration: %w", err)
+	}
+	log.Printf("Lifecycle: %s", cfg.Lifecycle)
+	lifecycle.Delay(ctx, cfg.Lifecycle)
+
+	// OpenTelemetry tracing and W3C trace context propagation
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		return fmt.Errorf("failed to set up tracing: %w", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Error("Tracing shutdown error", "error", err)
+		}
+	}()
+	log.Printf("OpenTelemetry tracing: %s", cfg.Tracing)
+
+	// Bandwidth shaping of every gRPC connection, changed at runtime on the
+	// admin port
+	shaper, err := bandwidth.New(cfg.Bandwidth)
+	if err != nil {
+		return err
+	}
+	log.Printf("Bandwidth: %s", cfg.Bandwidth)
+
+	// Cap on the open gRPC connections, changed at runtime on the admin port;
+	// the next ones get a server busy error
+	connLimiter, err := connlimit.New(cfg.ConnLimit)
+	if err != nil {
+		return err
+	}
+	log.Printf("Connection limit: %s", cfg.ConnLimit)
+
+	lis, err := network.Listen(cfg.Addr())
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	defer func() { _ = lis.Close() }()
+	// Connections by peer address, for the connection resets of the chaos
+	// interceptors
+	lis, conns := chaos.TrackConns(lis)
+
+	// HTTP/2 settings advertised by the server, and the ones of each client
+	// read from its connection preface, both reported by Http2Settings
+	if err := cfg.HTTP2.Validate(); err != nil {
+		return fmt.Errorf("invalid HTTP/2 configuration: %w", err)
+	}
+	log.Printf("HTTP/2: %s", cfg.HTTP2)
+	lis, clientSettings := server.TrackHTTP2Settings(lis)
+
+	// Server span per RPC, echoing the trace context in the response header
+	opts := server.TracingServerOptions()
+
+	// Request ID and RPC log
+	opts = append(opts, server.LoggingServerOptions()...)
+
+	// Traffic recording, toggled and downloaded through the admin API;
+	// outside the rate limit and fault injection so denied and failed RPCs
+	// are recorded
+	recorder, err := recording.New("echo-grpc", cfg.Recording)
+	if err != nil {
+		return err
+	}
+	log.Printf("Recording: %s", cfg.Recording)
+	opts = append(opts, server.RecordingServerOptions(recorder)...)
+
+	// Copies of the sampled unary RPCs sent to MIRROR_TARGET in the
+	// background, whatever the answer of this server; its admin API is
+	// served on the admin port
+	mirrors, err := mirror.New(cfg.Mirror)
+	if err != nil {
+		return err
+	}
+	log.Printf("Mirror: %s", cfg.Mirror)
+	opts = append(opts, server.MirrorServerOptions(mirrors)...)
+
+	// Fault injection, inside the metrics so injected errors and delays are
+	// recorded; its admin API is served on the admin port
+	faults, err := chaos.New(cfg.Chaos)
+	if err != nil {
+		return err
+	}
+	log.Printf("Chaos: %s", cfg.Chaos)
+
+	// Prometheus metrics of every RPC, served over HTTP on a separate port
+	if cfg.MetricsEnabled {
+		m := metrics.New("echo-grpc")
+		opts = append(opts, server.MetricsServerOptions(m)...)
+		metricsServer, err := m.Serve(cfg.MetricsAddr())
+		if err != nil {
+			return err
+		}
+		defer func() { _ = metricsServer.Close() }()
+	}
+
+	// Authentication with the test identities shared with the other
+	// servers, while they are required; inside the metrics so rejected RPCs
+	// are recorded, and its admin API is served on the admin port
+	creds, err := credentials.New(cfg.Credentials)
+	if err != nil {
+		return fmt.Errorf("invalid credentials: %w", err)
+	}
+	log.Printf("Credentials: %s", cfg.Credentials)
+	opts = append(opts, server.AuthServerOptions(creds)...)
+
+	// Server clock, moved at runtime on the admin port to skew the time
+	// reported by ServerTime and expire bearer JWTs; requests whose x-request-timestamp drifts too
+	// far from it are rejected next to the authentication
+	clk := clock.New(cfg.Clock)
+	log.Printf("Clock: %s, max drift %s", cfg.Clock, cfg.MaxClockDrift)
+	creds.SetClock(clk)
+	opts = append(opts, server.ClockServerOptions(clk, cfg.MaxClockDrift)...)
+
+	// Rate limiting, inside the metrics so denied RPCs are recorded and
+	// before the injected faults; its admin API is served on the admin port
+	limiter, err := ratelimit.New(cfg.RateLimit)
+	if err != nil {
+		return err
+	}
+	log.Printf("Rate limit: %s", cfg.RateLimit)
+	opts = append(opts, server.RateLimitServerOptions(limiter)...)
+
+	// Scripted scenarios, after the rate limit and before the injected
+	// faults; its admin API is served on the admin port
+	scenarios, err := script.New(cfg.Script)
+	if err != nil {
+		return err
+	}
+	log.Printf("Script: %s", cfg.Script)
+	opts = append(opts, server.ScriptServerOptions(scenarios)...)
+
+	// Client behavior profiles, each with its own rate limit and faults,
+	// before the global faults; its admin API is served on the admin port
+	profiles, err := clients.New(cfg.ClientProfiles)
+	if err != nil {
+		return err
+	}
+	log.Printf("Client profiles: %s", cfg.ClientProfiles)
+	opts = append(opts, server.ClientProfileServerOptions(profiles, conns)...)
+	opts = append(opts, server.ChaosServerOptions(faults, conns)...)
+
+	// Admin API: health, RPC delay, rate limit counters (global and per
+	// client profile) and scenario progress
+	adm := admin.New("echo-grpc", cfg.src)
+	adm.Store("rate_limits", limiter.Clear)
+	adm.Store("script_progress", scenarios.Rewind)
+	adm.Store("client_rate_limits", profiles.Clear)
+	opts = append(opts, server.AdminServerOptions(adm)...)
+
+	// Requests and responses checked against the buf.validate rules of the
+	// schema while strict validation is enabled; inside the faults so only
+	// the requests reaching the handlers are checked
+	if cfg.StrictValidation {
+		validator, err := protovalidate.New()
+		if err != nil {
+			return fmt.Errorf("failed to set up validation: %w", err)
+		}
+		opts = append(opts, server.ValidationServerOptions(validator)...)
+	}
+	log.Printf("Strict validation: %t", cfg.StrictValidation)
+
+	// ORCA per-call load reports of the load_report request field, in
+	// trailers; innermost so they follow the request to the handler
+	opts = append(opts, server.OrcaServerOptions()...)
+
+	opts = append(opts, cfg.HTTP2.ServerOptions()...)
+
+	s := grpc.NewServer(opts...)
+
+	// Register echo service
+	echoServer := server.NewEchoServer()
+	pb.RegisterEchoServer(s, echoServer)
+
+	// Build information and enabled features, reported by the Version RPC
+	// and at /version on the admin port
+	build := version.New("echo-grpc", version.Features{
+		"admin":           cfg.Admin.Enabled,
+		"chaos":           cfg.Chaos.Enabled,
+		"client_profiles": cfg.ClientProfiles.File != "",
+		"debug":           cfg.Admin.Debug,
+		"metrics":         cfg.MetricsEnabled,
+		"mirror":          cfg.Mirror.Target != "",
+		"mock":            cfg.MockDescriptorSet != "",
+		"rate_limit":      cfg.RateLimit.Enabled,
+		"recording":       cfg.Recording.Enabled,
+		"reflection":      !cfg.DisableReflectionV1 || !cfg.DisableReflectionV1Alpha,
+		"script":          cfg.Script.File != "",
+		"tracing":         cfg.Tracing.Enabled,
+		"validation":      cfg.StrictValidation,
+	})
+	echoServer.SetBuild(build)
+	echoServer.SetCredentials(creds)
+	echoServer.SetClock(clk, cfg.MaxClockDrift)
+
+	// CPU and memory burned by BurnResources, within safety caps
+	if err := cfg.Burn.Validate(); err != nil {
+		return fmt.Errorf("invalid burn configuration: %w", err)
+	}
+	log.Printf("Burn resources: %s", cfg.Burn)
+	echoServer.SetBurner(server.NewBurner(cfg.Burn))
+	echoServer.SetHTTP2(cfg.HTTP2, clientSettings)
+
+	// Register health service (grpc.health.v1)
+	healthServer := server.NewHealthServer()
+	healthpb.RegisterHealthServer(s, healthServer)
+	adm.OnHealth(healthServer.SetHealthy)
+
+	// Services of MOCK_DESCRIPTOR_SET answered with default messages,
+	// registered before reflection so it lists them
+	if cfg.MockDescriptorSet != "" {
+		mocked, err := server.RegisterMocks(s, cfg.MockDescriptorSet)
+		if err != nil {
+			return fmt.Errorf("invalid mock descriptor set: %w", err)
+		}
+		log.Printf("Mocked services: %v", mocked)
+	}
+
+	// Enable server reflection (v1 and v1alpha)
+	server.RegisterReflection(s, cfg.ReflectionIncludeDeps, cfg.DisableReflectionV1, cfg.DisableReflectionV1Alpha)
+
+	// Admin API over HTTP on a separate port, with the fault injection, rate
+	// limit, script, client profile, credential store, clock, mirror,
+	// bandwidth, connection limit and recording admin APIs and the build
+	// information
+	if cfg.Admin.Enabled {
+		adm.Handle(chaos.Path, chaos.Handler(faults))
+		adm.Handle(ratelimit.Path, ratelimit.Handler(limiter))
+		adm.Handle(script.Path, script.Handler(scenarios))
+		adm.Handle(clients.Path, clients.Handler(profiles))
+		adm.Handle(credentials.Path, credentials.Handler(creds))
+		adm.Handle(clock.Path, clock.Handler(clk))
+		adm.Handle(mirror.Path, mirror.Handler(mirrors))
+		adm.Handle(bandwidth.Path, bandwidth.Handler(shaper))
+		adm.Handle(connlimit.Path, connlimit.Handler(connLimiter))
+		adm.Handle(version.Path, version.Handler(build))
+		recordings := recording.Handler(recorder)
+		adm.Handle(recording.Path, recordings)
+		adm.Handle(recording.Path+"/", recordings)
+		if cfg.Admin.Debug {
+			adm.Debug()
+		}
+		adminServer, err := adm.Serve(cfg.AdminAddr())
+		if err != nil {
+			return err
+		}
+		defer func() { _ = adminServer.Close() }()
+	}
+
+	// Readiness delay and simulated crash, counted from here
+	lifecycle.Start(ctx, cfg.Lifecycle, adm)
+
+	// Graceful shutdown: the RPCs in flight finish, for 5 seconds at most
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		<-ctx.Done()
+
+		log.Println("Shutting down server...")
+		timer := time.AfterFunc(5*time.Second, s.Stop)
+		defer timer.Stop()
+		s.GracefulStop()
+	}()
+
+	log.Printf("Starting server on %s", cfg.Addr())
+	if err := s.Serve(shaper.Listen(connLimiter.Listen(lis, connlimit.HTTP))); err != nil {
+		return fmt.Errorf("failed to serve: %w", err)
+	}
+	<-stopped
+
+	log.Println("Server stopped")
+	return nil
+}
@@ -0,0 +1,175 @@
+package app
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/probitas-test/echo-servers/echo-grpc/server"
+	"github.com/probitas-test/echo-servers/shared/admin"
+	"github.com/probitas-test/echo-servers/shared/bandwidth"
+	"github.com/probitas-test/echo-servers/shared/chaos"
+	"github.com/probitas-test/echo-servers/shared/clients"
+	"github.com/probitas-test/echo-servers/shared/clock"
+	"github.com/probitas-test/echo-servers/shared/config"
+	"github.com/probitas-test/echo-servers/shared/connlimit"
+	"github.com/probitas-test/echo-servers/shared/credentials"
+	"github.com/probitas-test/echo-servers/shared/lifecycle"
+	"github.com/probitas-test/echo-servers/shared/logging"
+	"github.com/probitas-test/echo-servers/shared/mirror"
+	"github.com/probitas-test/echo-servers/shared/network"
+	"github.com/probitas-test/echo-servers/shared/ratelimit"
+	"github.com/probitas-test/echo-servers/shared/recording"
+	"github.com/probitas-test/echo-servers/shared/script"
+	"github.com/probitas-test/echo-servers/shared/tracing"
+)
+
+type Config struct {
+	Host                     string
+	Port                     string
+	ReflectionIncludeDeps    bool
+	DisableReflectionV1      bool
+	DisableReflectionV1Alpha bool
+
+	// HTTP/2 settings advertised to the clients (HTTP2_*), reported by the
+	// Http2Settings RPC
+	HTTP2 server.HTTP2Config
+
+	// FileDescriptorSet whose services are answered with default messages
+	// (MOCK_DESCRIPTOR_SET)
+	MockDescriptorSet string
+
+	// Requests and responses checked against the buf.validate rules of the
+	// schema (STRICT_VALIDATION)
+	StrictValidation bool
+
+	// Prometheus metrics, served at /metrics on a separate port
+	MetricsEnabled bool
+	MetricsPort    string
+
+	// Admin API on a separate port (ADMIN_ENABLED, ADMIN_PORT)
+	Admin admin.Config
+
+	// Fault injection (CHAOS_*), changed at runtime at /chaos on the
+	// admin port
+	Chaos chaos.Config
+
+	// Rate limiting (RATE_LIMIT_*), changed at runtime at /ratelimit on
+	// the admin port
+	RateLimit ratelimit.Config
+
+	// Traffic recording (RECORDING_*), downloaded from the admin port
+	Recording recording.Config
+
+	// Scripted scenarios (SCRIPT_*), replaced at runtime at /script
+	Script script.Config
+
+	// Client behavior profiles (CLIENT_PROFILES_*), replaced at runtime
+	// at /clients on the admin port
+	ClientProfiles clients.Config
+
+	// Bandwidth shaping of accepted connections (BANDWIDTH_*), changed at
+	// runtime at /bandwidth on the admin port
+	Bandwidth bandwidth.Config
+
+	// Connection cap (CONN_LIMIT_*), changed at runtime at /connlimit on the
+	// admin port
+	ConnLimit connlimit.Config
+
+	// Caps of the resources consumed by BurnResources (BURN_MAX_CPU_MS,
+	// BURN_MAX_MEMORY_MB, BURN_MAX_CONCURRENT)
+	Burn server.BurnConfig
+
+	// Server clock reported by ServerTime (CLOCK_OFFSET_MS), moved at runtime
+	// at /clock on the admin port
+	Clock clock.Config
+
+	// Largest drift of the x-request-timestamp metadata from the clock
+	// (CLOCK_MAX_DRIFT_MS, 0 = not checked)
+	MaxClockDrift time.Duration
+
+	// Address family of every listener (IP_FAMILY: dual, ipv4 or ipv6)
+	Network network.Config
+
+	// Simulated startup delay, readiness delay and crash (STARTUP_DELAY_MS, STARTUP_READY_DELAY_MS, CRASH_AFTER_MS, CRASH_EXIT_CODE)
+	Lifecycle lifecycle.Config
+
+	// Test identities shared with the other servers (CREDENTIALS_USERS, CREDENTIALS_FILE, CREDENTIALS_REQUIRED)
+	Credentials credentials.Config
+
+	// Copies of sampled unary RPCs sent to a secondary target (MIRROR_*), changed at runtime at /mirror on the admin port
+	Mirror mirror.Config
+
+	// Structured logging (LOG_LEVEL, LOG_FORMAT)
+	Logging logging.Config
+
+	// OpenTelemetry tracing (OTEL_* variables)
+	Tracing tracing.Config
+
+	// Effective configuration, served at /config
+	src *config.Source
+}
+
+// LoadConfig reads the settings of echo-grpc from src
+func LoadConfig(src *config.Source) (*Config, error) {
+	cfg := &Config{
+		Host:                     src.String("HOST", "0.0.0.0"),
+		Port:                     src.String("PORT", "50051"),
+		ReflectionIncludeDeps:    src.Bool("REFLECTION_INCLUDE_DEPENDENCIES", false),
+		DisableReflectionV1:      src.Bool("DISABLE_REFLECTION_V1", false),
+		DisableReflectionV1Alpha: src.Bool("DISABLE_REFLECTION_V1ALPHA", false),
+		MockDescriptorSet:        src.String("MOCK_DESCRIPTOR_SET", ""),
+		StrictValidation:         src.Bool("STRICT_VALIDATION", false),
+
+		HTTP2: server.HTTP2Config{
+			InitialWindowSize:     int32(src.Int("HTTP2_INITIAL_WINDOW_SIZE", 0)),
+			InitialConnWindowSize: int32(src.Int("HTTP2_INITIAL_CONN_WINDOW_SIZE", 0)),
+			MaxHeaderListSize:     uint32(src.Int("HTTP2_MAX_HEADER_LIST_SIZE", 0)),
+			HeaderTableSize:       uint32(src.Int("HTTP2_HEADER_TABLE_SIZE", 0)),
+			MaxConcurrentStreams:  uint32(src.Int("HTTP2_MAX_CONCURRENT_STREAMS", 0)),
+		},
+
+		Burn: server.BurnConfig{
+			MaxCPU:        time.Duration(src.Int("BURN_MAX_CPU_MS", 10000)) * time.Millisecond,
+			MaxMemory:     int64(src.Int("BURN_MAX_MEMORY_MB", 256)) << 20,
+			MaxConcurrent: src.Int("BURN_MAX_CONCURRENT", 4),
+		},
+
+		MetricsEnabled: src.Bool("METRICS_ENABLED", true),
+		MetricsPort:    src.String("METRICS_PORT", "9090"),
+
+		Admin:          admin.LoadConfig(src),
+		Chaos:          chaos.LoadConfig(src),
+		RateLimit:      ratelimit.LoadConfig(src),
+		Tracing:        tracing.LoadConfig(src, "echo-grpc"),
+		Recording:      recording.LoadConfig(src),
+		Script:         script.LoadConfig(src),
+		ClientProfiles: clients.LoadConfig(src),
+		Bandwidth:      bandwidth.LoadConfig(src),
+		ConnLimit:      connlimit.LoadConfig(src),
+		Network:        network.LoadConfig(src),
+		Clock:          clock.LoadConfig(src),
+		MaxClockDrift:  time.Duration(src.Int("CLOCK_MAX_DRIFT_MS", 0)) * time.Millisecond,
+		Lifecycle:      lifecycle.LoadConfig(src),
+		Credentials:    credentials.LoadConfig(src),
+		Mirror:         mirror.LoadConfig(src),
+		Logging:        logging.LoadConfig(src),
+	}
+	cfg.src = src
+	if err := src.Err(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	return cfg, nil
+}
+
+func (c *Config) Addr() string {
+	return net.JoinHostPort(c.Host, c.Port)
+}
+
+func (c *Config) MetricsAddr() string {
+	return net.JoinHostPort(c.Host, c.MetricsPort)
+}
+
+func (c *Config) AdminAddr() string {
+	return net.JoinHostPort(c.Host, c.Admin.Port)
+}
@@ -1,39 +1,40 @@
 package main
 
 import (
+	"context"
 	"log"
-	"net"
-
-	"google.golang.org/grpc"
-	healthpb "google.golang.org/grpc/health/grpc_health_v1"
-
-	pb "github.com/probitas-test/echo-servers/echo-grpc/proto"
-	"github.com/probitas-test/echo-servers/echo-grpc/server"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/probitas-test/echo-servers/echo-grpc/app"
+	"github.com/probitas-test/echo-servers/shared/config"
+	"github.com/probitas-test/echo-servers/shared/logging"
+	"github.com/probitas-test/echo-servers/shared/network"
 )
 
 func main() {
-	cfg := LoadConfig()
-
-	lis, err := net.Listen("tcp", cfg.Addr())
+	// Environment, .env file and CONFIG_FILE
+	cfg, err := app.LoadConfig(config.Load())
 	if err != nil {
-		log.Fatalf("Failed to listen: %v", err)
+		log.Fatal(err)
 	}
 
-	s := grpc.NewServer()
-
-	// Register echo service
-	echoServer := server.NewEchoServer()
-	pb.RegisterEchoServer(s, echoServer)
-
-	// Register health service (grpc.health.v1)
-	healthServer := server.NewHealthServer()
-	healthpb.RegisterHealthServer(s, healthServer)
+	// Structured logging; the log package writes through it as well
+	if err := logging.Setup(cfg.Logging); err != nil {
+		log.Fatalf("Invalid logging configuration: %v", err)
+	}
 
-	// Enable server reflection (v1 and v1alpha)
-	server.RegisterReflection(s, cfg.ReflectionIncludeDeps, cfg.DisableReflectionV1, cfg.DisableReflectionV1Alpha)
+	// IPv4-only, IPv6-only or dual-stack listeners
+	if err := network.Setup(cfg.Network); err != nil {
+		log.Fatalf("Invalid network configuration: %v", err)
+	}
 
-	log.Printf("Starting server on %s", cfg.Addr())
-	if err := s.Serve(lis); err != nil {
-		log.Fatalf("Failed to serve: %v", err)
+	// Serve until SIGINT or SIGTERM, then shut down gracefully
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err = app.Run(ctx, cfg)
+	stop()
+	if err != nil {
+		log.Fatal(err)
 	}
 }
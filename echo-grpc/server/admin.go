@@ -0,0 +1,41 @@
+package server
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/probitas-test/echo-servers/shared/admin"
+)
+
+// AdminServerOptions returns server options delaying every RPC but the
+// health checks by the delay set through the admin API.
+func AdminServerOptions(a *admin.Admin) []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if err := adminWait(ctx, a, info.FullMethod); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := adminWait(ss.Context(), a, info.FullMethod); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		}),
+	}
+}
+
+func adminWait(ctx context.Context, a *admin.Admin, method string) error {
+	if strings.HasPrefix(method, "/"+healthpb.Health_ServiceDesc.ServiceName+"/") {
+		return nil
+	}
+	if err := a.Wait(ctx); err != nil {
+		return status.FromContextError(err).Err()
+	}
+	return nil
+}
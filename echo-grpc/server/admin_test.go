@@ -0,0 +1,61 @@
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	pb "github.com/probitas-test/echo-servers/proto/echo/v1"
+	"github.com/probitas-test/echo-servers/shared/admin"
+)
+
+func TestAdminServerOptions(t *testing.T) {
+	a := admin.New("echo-grpc", nil)
+	a.SetDelay(100 * time.Millisecond)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer(AdminServerOptions(a)...)
+	pb.RegisterEchoServer(s, NewEchoServer())
+	healthServer := NewHealthServer()
+	healthpb.RegisterHealthServer(s, healthServer)
+	a.OnHealth(healthServer.SetHealthy)
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	ctx := context.Background()
+
+	start := time.Now()
+	if _, err := pb.NewEchoClient(conn).Echo(ctx, &pb.EchoRequest{Message: "hello"}); err != nil {
+		t.Fatalf("Echo failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("Echo took %v, want the 100ms delay", elapsed)
+	}
+
+	// Health checks are not delayed and follow the admin health
+	a.SetHealthy(false)
+	start = time.Now()
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if resp.Status != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("status = %v, want NOT_SERVING", resp.Status)
+	}
+	if elapsed := time.Since(start); elapsed >= 100*time.Millisecond {
+		t.Errorf("Check took %v, want no delay", elapsed)
+	}
+}
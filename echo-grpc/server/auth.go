@@ -0,0 +1,64 @@
+package server
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/probitas-test/echo-servers/shared/credentials"
+)
+
+// authRealm is the realm of the WWW-Authenticate challenge
+const authRealm = "echo-grpc"
+
+// AuthServerOptions returns server options rejecting the RPCs without
+// credentials of a user of the store with UNAUTHENTICATED while it is
+// required: Basic or Bearer authorization metadata, or an x-api-key. The
+// challenge is sent in the www-authenticate trailer metadata. Health
+// checks and reflection stay reachable.
+func AuthServerOptions(s *credentials.Store) []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if err := authenticate(ctx, s, info.FullMethod); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := authenticate(ss.Context(), s, info.FullMethod); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		}),
+	}
+}
+
+func authenticate(ctx context.Context, s *credentials.Store, method string) error {
+	if !s.Required() ||
+		strings.HasPrefix(method, "/"+healthpb.Health_ServiceDesc.ServiceName+"/") ||
+		strings.HasPrefix(method, "/grpc.reflection.") {
+		return nil
+	}
+	if _, err := s.Authenticate(incomingCredentials(ctx)); err != nil {
+		_ = grpc.SetTrailer(ctx, metadata.Pairs("www-authenticate", credentials.Challenge(authRealm)))
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
+	return nil
+}
+
+// incomingCredentials returns the authorization and x-api-key metadata of
+// the call, as sent by per-RPC credentials
+func incomingCredentials(ctx context.Context) (authorization, apiKey string) {
+	if values := metadata.ValueFromIncomingContext(ctx, "authorization"); len(values) > 0 {
+		authorization = values[0]
+	}
+	if values := metadata.ValueFromIncomingContext(ctx, credentials.APIKeyHeader); len(values) > 0 {
+		apiKey = values[0]
+	}
+	return authorization, apiKey
+}
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/probitas-test/echo-servers/proto/echo/v1"
+	"github.com/probitas-test/echo-servers/shared/credentials"
+)
+
+func TestAuthServerOptions(t *testing.T) {
+	store, err := credentials.New(credentials.Config{Users: []string{"alice:secret"}, Required: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := store.Credentials()
+	c.Users[0].APIKeys = []string{"alice-key"}
+	if err := store.Set(c); err != nil {
+		t.Fatal(err)
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer(AuthServerOptions(store)...)
+	pb.RegisterEchoServer(s, NewEchoServer())
+	healthpb.RegisterHealthServer(s, NewHealthServer())
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	client := pb.NewEchoClient(conn)
+
+	alice := credentials.User{Username: "alice", Password: "secret"}
+	tests := []struct {
+		key, value string
+		code       codes.Code
+	}{
+		{"authorization", "Basic " + base64.StdEncoding.EncodeToString([]byte("alice:secret")), codes.OK},
+		{"authorization", "Bearer " + alice.Token(), codes.OK},
+		{"x-api-key", "alice-key", codes.OK},
+		{"authorization", "Bearer wrong", codes.Unauthenticated},
+		{"x-other", "", codes.Unauthenticated},
+	}
+	for _, tt := range tests {
+		ctx := metadata.AppendToOutgoingContext(context.Background(), tt.key, tt.value)
+		var trailer metadata.MD
+		_, err := client.Echo(ctx, &pb.EchoRequest{Message: "hello"}, grpc.Trailer(&trailer))
+		if code := status.Code(err); code != tt.code {
+			t.Errorf("Echo with %s %q = %v, want %v", tt.key, tt.value, err, tt.code)
+		}
+		if tt.code == codes.Unauthenticated && len(trailer.Get("www-authenticate")) == 0 {
+			t.Errorf("Echo with %s %q: no www-authenticate trailer", tt.key, tt.value)
+		}
+	}
+
+	if _, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{}); err != nil {
+		t.Errorf("health check without credentials: %v", err)
+	}
+}
@@ -0,0 +1,194 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/probitas-test/echo-servers/proto/echo/v1"
+)
+
+const (
+	// maxBurnHold bounds how long BurnResources holds its memory
+	maxBurnHold = 60 * time.Second
+	// burnPageSize is the stride of the writes committing burned memory
+	burnPageSize = 4096
+)
+
+// BurnConfig caps the resources consumed by BurnResources
+type BurnConfig struct {
+	// MaxCPU is the CPU time of one call
+	MaxCPU time.Duration
+	// MaxMemory is the memory held by all the calls at once, in bytes
+	MaxMemory int64
+	// MaxConcurrent is the calls burning at once; 0 disables the RPC
+	MaxConcurrent int
+}
+
+// Validate reports the first negative cap
+func (c BurnConfig) Validate() error {
+	switch {
+	case c.MaxCPU < 0:
+		return fmt.Errorf("max CPU time %s is negative", c.MaxCPU)
+	case c.MaxMemory < 0:
+		return fmt.Errorf("max memory %d is negative", c.MaxMemory)
+	case c.MaxConcurrent < 0:
+		return fmt.Errorf("max concurrent burns %d is negative", c.MaxConcurrent)
+	}
+	return nil
+}
+
+// String describes the caps for the startup log
+func (c BurnConfig) String() string {
+	if c.MaxConcurrent == 0 {
+		return "disabled"
+	}
+	return fmt.Sprintf("cpu=%s memory=%dMB concurrent=%d", c.MaxCPU, c.MaxMemory>>20, c.MaxConcurrent)
+}
+
+// Burner tracks the BurnResources calls in flight against the caps
+type Burner struct {
+	cfg BurnConfig
+
+	mu       sync.Mutex
+	inFlight int
+	memory   int64
+}
+
+// NewBurner creates a burner enforcing the caps of cfg
+func NewBurner(cfg BurnConfig) *Burner {
+	return &Burner{cfg: cfg}
+}
+
+// acquire reserves a call holding memory bytes, returning the calls and
+// memory in flight with it
+func (b *Burner) acquire(memory int64) (int, int64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.inFlight >= b.cfg.MaxConcurrent {
+		return 0, 0, status.Errorf(codes.ResourceExhausted, "%d burns already in flight (max %d)", b.inFlight, b.cfg.MaxConcurrent)
+	}
+	if b.memory+memory > b.cfg.MaxMemory {
+		return 0, 0, status.Errorf(codes.ResourceExhausted, "%d bytes already held, %d more exceed the max %d", b.memory, memory, b.cfg.MaxMemory)
+	}
+	b.inFlight++
+	b.memory += memory
+	return b.inFlight, b.memory, nil
+}
+
+// release ends a call acquired with memory bytes
+func (b *Burner) release(memory int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.inFlight--
+	b.memory -= memory
+}
+
+// SetBurner sets the burner capping BurnResources
+func (s *EchoServer) SetBurner(b *Burner) {
+	s.burner = b
+}
+
+// BurnResources consumes CPU time on parallel goroutines and allocates and
+// touches memory held until hold_ms after the burn, so autoscalers and
+// client circuit breakers see a genuinely loaded backend. Requests above
+// the caps of one call are rejected with INVALID_ARGUMENT, calls beyond
+// the concurrent burns or memory held by all the calls with
+// RESOURCE_EXHAUSTED. A cancelled call stops burning and frees its memory.
+func (s *EchoServer) BurnResources(ctx context.Context, req *pb.BurnResourcesRequest) (*pb.BurnResourcesResponse, error) {
+	if s.burner == nil || s.burner.cfg.MaxConcurrent == 0 {
+		return nil, status.Error(codes.FailedPrecondition, "resource burning is disabled")
+	}
+	cfg := s.burner.cfg
+	cpu := time.Duration(req.CpuMs) * time.Millisecond
+	memory := int64(req.MemoryMb) << 20
+	hold := time.Duration(req.HoldMs) * time.Millisecond
+	parallelism := int(req.Parallelism)
+	if parallelism <= 0 {
+		parallelism = 1
+	}
+	switch {
+	case cpu < 0 || cpu > cfg.MaxCPU:
+		return nil, status.Errorf(codes.InvalidArgument, "cpu_ms %d is outside 0-%d", req.CpuMs, cfg.MaxCPU.Milliseconds())
+	case memory < 0 || memory > cfg.MaxMemory:
+		return nil, status.Errorf(codes.InvalidArgument, "memory_mb %d is outside 0-%d", req.MemoryMb, cfg.MaxMemory>>20)
+	case hold < 0 || hold > maxBurnHold:
+		return nil, status.Errorf(codes.InvalidArgument, "hold_ms %d is outside 0-%d", req.HoldMs, maxBurnHold.Milliseconds())
+	case parallelism > runtime.GOMAXPROCS(0):
+		return nil, status.Errorf(codes.InvalidArgument, "parallelism %d exceeds GOMAXPROCS %d", parallelism, runtime.GOMAXPROCS(0))
+	}
+
+	inFlight, memoryInUse, err := s.burner.acquire(memory)
+	if err != nil {
+		return nil, err
+	}
+	defer s.burner.release(memory)
+
+	start := time.Now()
+	buf := make([]byte, memory)
+	for i := 0; i < len(buf); i += burnPageSize {
+		buf[i] = byte(i)
+	}
+	burned := burnCPU(ctx, cpu, parallelism)
+	if ctx.Err() == nil && hold > 0 {
+		t := time.NewTimer(hold)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+		}
+	}
+	runtime.KeepAlive(buf)
+	if err := ctx.Err(); err != nil {
+		return nil, status.FromContextError(err).Err()
+	}
+
+	return &pb.BurnResourcesResponse{
+		CpuMs:            burned.Milliseconds(),
+		MemoryBytes:      memory,
+		Parallelism:      int32(parallelism),
+		ElapsedMs:        time.Since(start).Milliseconds(),
+		InFlight:         int32(inFlight),
+		MemoryInUseBytes: memoryInUse,
+	}, nil
+}
+
+// burnCPU spins parallelism goroutines for their share of total, until ctx
+// is done, and returns the time they spun
+func burnCPU(ctx context.Context, total time.Duration, parallelism int) time.Duration {
+	if total <= 0 {
+		return 0
+	}
+	share := total / time.Duration(parallelism)
+	spun := make([]time.Duration, parallelism)
+	var wg sync.WaitGroup
+	for i := range parallelism {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			x := uint64(i) + 1
+			for time.Since(start) < share && ctx.Err() == nil {
+				// xorshift rounds between clock reads
+				for range 10000 {
+					x ^= x << 13
+					x ^= x >> 7
+					x ^= x << 17
+				}
+			}
+			runtime.KeepAlive(x)
+			spun[i] = time.Since(start)
+		}()
+	}
+	wg.Wait()
+	var sum time.Duration
+	for _, d := range spun {
+		sum += d
+	}
+	return sum
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/probitas-test/echo-servers/proto/echo/v1"
+)
+
+func newBurnServer(cfg BurnConfig) *EchoServer {
+	s := NewEchoServer()
+	s.SetBurner(NewBurner(cfg))
+	return s
+}
+
+// waitInFlight waits until b has n calls in flight
+func waitInFlight(t *testing.T, b *Burner, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		b.mu.Lock()
+		inFlight := b.inFlight
+		b.mu.Unlock()
+		if inFlight == n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("%d burns never in flight", n)
+}
+
+func TestBurnResources(t *testing.T) {
+	s := newBurnServer(BurnConfig{MaxCPU: time.Second, MaxMemory: 8 << 20, MaxConcurrent: 2})
+
+	resp, err := s.BurnResources(context.Background(), &pb.BurnResourcesRequest{CpuMs: 20, MemoryMb: 1, HoldMs: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.CpuMs < 20 || resp.MemoryBytes != 1<<20 || resp.Parallelism != 1 || resp.ElapsedMs < 30 {
+		t.Errorf("response = %v", resp)
+	}
+	if resp.InFlight != 1 || resp.MemoryInUseBytes != 1<<20 {
+		t.Errorf("in flight = %d, memory in use = %d", resp.InFlight, resp.MemoryInUseBytes)
+	}
+
+	for _, req := range []*pb.BurnResourcesRequest{
+		{CpuMs: 2000},
+		{MemoryMb: 16},
+		{HoldMs: 120000},
+		{CpuMs: -1},
+		{Parallelism: 100000},
+	} {
+		if _, err := s.BurnResources(context.Background(), req); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("%v: %v", req, err)
+		}
+	}
+
+	if _, err := NewEchoServer().BurnResources(context.Background(), &pb.BurnResourcesRequest{}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("without burner: %v", err)
+	}
+	if _, err := newBurnServer(BurnConfig{}).BurnResources(context.Background(), &pb.BurnResourcesRequest{}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("disabled: %v", err)
+	}
+}
+
+func TestBurnResources_Caps(t *testing.T) {
+	s := newBurnServer(BurnConfig{MaxCPU: time.Second, MaxMemory: 2 << 20, MaxConcurrent: 2})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := s.BurnResources(ctx, &pb.BurnResourcesRequest{MemoryMb: 2, HoldMs: 60000})
+		done <- err
+	}()
+	waitInFlight(t, s.burner, 1)
+
+	// The memory held by all the calls is capped
+	if _, err := s.BurnResources(context.Background(), &pb.BurnResourcesRequest{MemoryMb: 1}); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("beyond the memory held: %v", err)
+	}
+	// and so are the calls in flight
+	go func() { _, _ = s.BurnResources(ctx, &pb.BurnResourcesRequest{HoldMs: 60000}) }()
+	waitInFlight(t, s.burner, 2)
+	if _, err := s.BurnResources(context.Background(), &pb.BurnResourcesRequest{}); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("beyond the calls in flight: %v", err)
+	}
+
+	// Cancelled calls release their memory
+	cancel()
+	if err := <-done; status.Code(err) != codes.Canceled {
+		t.Errorf("cancelled burn: %v", err)
+	}
+	waitInFlight(t, s.burner, 0)
+	if s.burner.memory != 0 {
+		t.Errorf("memory held after the calls = %d", s.burner.memory)
+	}
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/probitas-test/echo-servers/shared/chaos"
+)
+
+// ChaosServerOptions returns server options injecting the faults of e into
+// every RPC, matching rules against the full method. Injected errors carry
+// the gRPC code of their HTTP status and x-chaos: error header metadata,
+// resets close the connection of the RPC (tracked by conns), and the
+// bandwidth delays every response message by its size.
+func ChaosServerOptions(e *chaos.Engine, conns *chaos.Conns) []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(chaosUnaryInterceptor(e, conns)),
+		grpc.ChainStreamInterceptor(chaosStreamInterceptor(e, conns)),
+	}
+}
+
+func chaosUnaryInterceptor(e *chaos.Engine, conns *chaos.Conns) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		d := e.Decide(info.FullMethod)
+		if err := inject(ctx, d, conns); err != nil {
+			return nil, err
+		}
+		resp, err := handler(ctx, req)
+		if err == nil {
+			if err := d.Throttle(ctx, messageSize(resp)); err != nil {
+				return nil, status.FromContextError(err).Err()
+			}
+		}
+		return resp, err
+	}
+}
+
+func chaosStreamInterceptor(e *chaos.Engine, conns *chaos.Conns) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		d := e.Decide(info.FullMethod)
+		if err := inject(ss.Context(), d, conns); err != nil {
+			return err
+		}
+		if d.Bandwidth > 0 {
+			ss = &throttledStream{ServerStream: ss, decision: d}
+		}
+		return handler(srv, ss)
+	}
+}
+
+// inject waits for the delay of d, then resets the connection or returns
+// the injected error
+func inject(ctx context.Context, d chaos.Decision, conns *chaos.Conns) error {
+	if err := d.Wait(ctx); err != nil {
+		return status.FromContextError(err).Err()
+	}
+	switch {
+	case d.Reset:
+		if p, ok := peer.FromContext(ctx); ok {
+			conns.Reset(p.Addr)
+		}
+		return status.Error(codes.Unavailable, "chaos: connection reset")
+	case d.Status != 0:
+		_ = grpc.SetHeader(ctx, metadata.Pairs("x-chaos", "error"))
+		return status.Error(codes.Code(chaos.Code(d.Status)), "chaos: injected error")
+	}
+	return nil
+}
+
+// throttledStream delays every message sent by its size at the bandwidth
+// of a chaos decision
+type throttledStream struct {
+	grpc.ServerStream
+	decision chaos.Decision
+}
+
+func (s *throttledStream) SendMsg(m any) error {
+	if err := s.decision.Throttle(s.Context(), messageSize(m)); err != nil {
+		return status.FromContextError(err).Err()
+	}
+	return s.ServerStream.SendMsg(m)
+}
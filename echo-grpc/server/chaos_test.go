@@ -0,0 +1,69 @@
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/probitas-test/echo-servers/proto/echo/v1"
+	"github.com/probitas-test/echo-servers/shared/chaos"
+)
+
+func TestChaosServerOptions(t *testing.T) {
+	engine, err := chaos.New(chaos.Config{Profile: chaos.DefaultProfile()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.SetState(chaos.State{
+		Enabled: true,
+		Global:  chaos.DefaultProfile(),
+		Rules: []chaos.Rule{
+			{Match: "/echo.v1.Echo/Echo", Profile: chaos.Profile{LatencyRate: 1, ErrorRate: 1, ErrorStatus: 504}},
+			{Match: "/echo.v1.Echo/EchoError", Profile: chaos.Profile{LatencyRate: 1, ResetRate: 1, ErrorStatus: 503}},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lis, conns := chaos.TrackConns(lis)
+	s := grpc.NewServer(ChaosServerOptions(engine, conns)...)
+	pb.RegisterEchoServer(s, NewEchoServer())
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	client := pb.NewEchoClient(conn)
+	ctx := context.Background()
+
+	// Injected error with the gRPC code of its HTTP status
+	var header metadata.MD
+	_, err = client.Echo(ctx, &pb.EchoRequest{Message: "hello"}, grpc.Header(&header))
+	if status.Code(err) != codes.DeadlineExceeded || len(header.Get("x-chaos")) != 1 {
+		t.Errorf("Echo error = %v, header %v, want injected DeadlineExceeded", err, header)
+	}
+
+	// Reset connection
+	_, err = client.EchoError(ctx, &pb.EchoErrorRequest{Code: int32(codes.OK)})
+	if status.Code(err) != codes.Unavailable {
+		t.Errorf("EchoError on a reset connection = %v, want Unavailable", err)
+	}
+
+	// Other methods are not affected
+	if _, err := client.EchoWithDelay(ctx, &pb.EchoWithDelayRequest{Message: "hello"}); err != nil {
+		t.Errorf("EchoWithDelay failed: %v", err)
+	}
+}
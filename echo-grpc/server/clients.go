@@ -0,0 +1,91 @@
+package server
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/probitas-test/echo-servers/shared/chaos"
+	"github.com/probitas-test/echo-servers/shared/clients"
+)
+
+// ClientProfileServerOptions returns server options applying the profile
+// of the client of every RPC but the health checks, identified by API key
+// or client ID metadata or peer address: its rate limit, then its faults,
+// like the rate limit and chaos interceptors. The profile applied is
+// reported in the x-client-profile header metadata.
+func ClientProfileServerOptions(e *clients.Engine, conns *chaos.Conns) []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			m, ok := matchProfile(ctx, e, info.FullMethod)
+			if !ok {
+				return handler(ctx, req)
+			}
+			done, err := rateLimit(ctx, m.Limiter, info.FullMethod)
+			if err != nil {
+				return nil, err
+			}
+			defer done()
+			d := m.Faults.Decide(info.FullMethod)
+			if err := inject(ctx, d, conns); err != nil {
+				return nil, err
+			}
+			resp, err := handler(ctx, req)
+			if err == nil {
+				if err := d.Throttle(ctx, messageSize(resp)); err != nil {
+					return nil, status.FromContextError(err).Err()
+				}
+			}
+			return resp, err
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			ctx := ss.Context()
+			m, ok := matchProfile(ctx, e, info.FullMethod)
+			if !ok {
+				return handler(srv, ss)
+			}
+			done, err := rateLimit(ctx, m.Limiter, info.FullMethod)
+			if err != nil {
+				return err
+			}
+			defer done()
+			d := m.Faults.Decide(info.FullMethod)
+			if err := inject(ctx, d, conns); err != nil {
+				return err
+			}
+			if d.Bandwidth > 0 {
+				ss = &throttledStream{ServerStream: ss, decision: d}
+			}
+			return handler(srv, ss)
+		}),
+	}
+}
+
+// matchProfile returns the profile of the client of an RPC of method,
+// reporting it in the header metadata
+func matchProfile(ctx context.Context, e *clients.Engine, method string) (clients.Match, bool) {
+	if strings.HasPrefix(method, "/"+healthpb.Health_ServiceDesc.ServiceName+"/") {
+		return clients.Match{}, false
+	}
+	var addr, key, id string
+	if p, ok := peer.FromContext(ctx); ok {
+		addr = p.Addr.String()
+	}
+	if values := metadata.ValueFromIncomingContext(ctx, e.KeyHeader()); len(values) > 0 {
+		key = values[0]
+	}
+	if values := metadata.ValueFromIncomingContext(ctx, e.IDHeader()); len(values) > 0 {
+		id = values[0]
+	}
+
+	m, ok := e.Match(addr, key, id)
+	if ok {
+		_ = grpc.SetHeader(ctx, metadata.Pairs(strings.ToLower(clients.Header), m.Profile))
+	}
+	return m, ok
+}
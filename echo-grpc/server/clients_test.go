@@ -0,0 +1,76 @@
+package server
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/probitas-test/echo-servers/proto/echo/v1"
+	"github.com/probitas-test/echo-servers/shared/chaos"
+	"github.com/probitas-test/echo-servers/shared/clients"
+	"github.com/probitas-test/echo-servers/shared/ratelimit"
+)
+
+func TestClientProfileServerOptions(t *testing.T) {
+	engine, err := clients.New(clients.Config{KeyHeader: clients.DefaultKeyHeader, IDHeader: clients.DefaultIDHeader})
+	if err != nil {
+		t.Fatal(err)
+	}
+	limits := ratelimit.DefaultConfig()
+	limits.Enabled, limits.Limit, limits.WindowMS, limits.Scope = true, 1, 60000, ratelimit.ScopeGlobal
+	broken := chaos.DefaultProfile()
+	broken.ErrorRate, broken.ErrorStatus = 1, 503
+	err = engine.SetProfiles(clients.Profiles{Profiles: []clients.Profile{
+		{Name: "noisy", APIKeys: []string{"noisy-key"}, Faults: chaos.DefaultProfile(), RateLimit: &limits},
+		{Name: "broken", ClientIDs: []string{"broken"}, Faults: broken},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lis, conns := chaos.TrackConns(lis)
+	s := grpc.NewServer(ClientProfileServerOptions(engine, conns)...)
+	pb.RegisterEchoServer(s, NewEchoServer())
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	client := pb.NewEchoClient(conn)
+
+	tests := []struct {
+		key, value string
+		code       codes.Code
+		profile    string
+	}{
+		{"x-api-key", "noisy-key", codes.OK, "noisy"},
+		{"x-api-key", "noisy-key", codes.ResourceExhausted, "noisy"},
+		{"x-client-id", "broken", codes.Unavailable, "broken"},
+		{"x-client-id", "other", codes.OK, ""},
+	}
+	for i, tt := range tests {
+		ctx := metadata.AppendToOutgoingContext(context.Background(), tt.key, tt.value)
+		var header metadata.MD
+		_, err := client.Echo(ctx, &pb.EchoRequest{Message: "hello"}, grpc.Header(&header))
+		if code := status.Code(err); code != tt.code {
+			t.Errorf("Echo %d = %v, want %v", i, err, tt.code)
+		}
+		if got := strings.Join(header.Get("x-client-profile"), ","); got != tt.profile {
+			t.Errorf("Echo %d x-client-profile = %v, want %q", i, got, tt.profile)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/probitas-test/echo-servers/proto/echo/v1"
+	"github.com/probitas-test/echo-servers/shared/clock"
+)
+
+// RequestTimestampHeader is the metadata of the client time checked
+// against the server clock; ServerTimeTrailer carries the server time of
+// the RPCs rejected for drifting too far
+const (
+	RequestTimestampHeader = "x-request-timestamp"
+	ServerTimeTrailer      = "x-server-time"
+)
+
+// clockSkewReason is the ErrorInfo reason of the RPCs rejected for clock
+// drift
+const clockSkewReason = "CLOCK_SKEW"
+
+// SetClock sets the server clock reported by ServerTime and the largest
+// client drift it accepts
+func (s *EchoServer) SetClock(clk *clock.Clock, maxDrift time.Duration) {
+	s.clock = clk
+	s.maxDrift = maxDrift
+}
+
+// ServerTime reports the time of the server clock, moved from the real
+// time by its offset, and how far the client time of the request drifts
+// from it, so clients can measure and correct the clock skew
+func (s *EchoServer) ServerTime(_ context.Context, req *pb.ServerTimeRequest) (*pb.ServerTimeResponse, error) {
+	now := s.clock.Now()
+	resp := &pb.ServerTimeResponse{
+		ServerTime: timestamppb.New(now),
+		SkewMs:     s.clock.Offset().Milliseconds(),
+		MaxDriftMs: s.maxDrift.Milliseconds(),
+	}
+	if req.ClientTime != nil {
+		if err := req.ClientTime.CheckValid(); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid client_time: %v", err)
+		}
+		drift := req.ClientTime.AsTime().Sub(now)
+		resp.DriftMs = drift.Milliseconds()
+		resp.DriftExceeded = driftExceeded(drift, s.maxDrift)
+	}
+	return resp, nil
+}
+
+// ClockServerOptions returns server options rejecting the RPCs whose
+// x-request-timestamp metadata drifts more than maxDrift from the clock
+// with FAILED_PRECONDITION, as servers checking the freshness of signed
+// requests do. The error carries a google.rpc.ErrorInfo with the reason
+// CLOCK_SKEW, and the server time is sent in the x-server-time trailer.
+// Timestamps are RFC 3339, ISO 8601 basic (20060102T150405Z) or Unix
+// milliseconds. RPCs without the metadata, health checks, reflection and
+// ServerTime are not checked, nor is anything with a zero maxDrift.
+func ClockServerOptions(clk *clock.Clock, maxDrift time.Duration) []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if err := checkDrift(ctx, clk, maxDrift, info.FullMethod); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := checkDrift(ss.Context(), clk, maxDrift, info.FullMethod); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		}),
+	}
+}
+
+func checkDrift(ctx context.Context, clk *clock.Clock, maxDrift time.Duration, method string) error {
+	if maxDrift <= 0 ||
+		method == pb.Echo_ServerTime_FullMethodName ||
+		strings.HasPrefix(method, "/"+healthpb.Health_ServiceDesc.ServiceName+"/") ||
+		strings.HasPrefix(method, "/grpc.reflection.") {
+		return nil
+	}
+	values := metadata.ValueFromIncomingContext(ctx, RequestTimestampHeader)
+	if len(values) == 0 {
+		return nil
+	}
+	requestTime, err := parseRequestTimestamp(values[0])
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid %s: %v", RequestTimestampHeader, err)
+	}
+	now := clk.Now()
+	drift := requestTime.Sub(now)
+	if !driftExceeded(drift, maxDrift) {
+		return nil
+	}
+
+	serverTime := now.UTC().Format(time.RFC3339Nano)
+	_ = grpc.SetTrailer(ctx, metadata.Pairs(ServerTimeTrailer, serverTime))
+	st := status.Newf(codes.FailedPrecondition, "request time %s drifts %s from server time %s (max %s)",
+		requestTime.UTC().Format(time.RFC3339Nano), drift, serverTime, maxDrift)
+	withInfo, err := st.WithDetails(&errdetails.ErrorInfo{
+		Reason: clockSkewReason,
+		Domain: "echo-grpc",
+		Metadata: map[string]string{
+			"request_time": requestTime.UTC().Format(time.RFC3339Nano),
+			"server_time":  serverTime,
+			"drift_ms":     strconv.FormatInt(drift.Milliseconds(), 10),
+			"max_drift_ms": strconv.FormatInt(maxDrift.Milliseconds(), 10),
+		},
+	})
+	if err != nil {
+		return st.Err()
+	}
+	return withInfo.Err()
+}
+
+// driftExceeded reports whether drift, ahead or behind, is beyond maxDrift;
+// a zero maxDrift accepts any drift
+func driftExceeded(drift, maxDrift time.Duration) bool {
+	return maxDrift > 0 && (drift > maxDrift || drift < -maxDrift)
+}
+
+// parseRequestTimestamp parses an RFC 3339 or ISO 8601 basic timestamp, or
+// Unix milliseconds
+func parseRequestTimestamp(s string) (time.Time, error) {
+	if ms, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.UnixMilli(ms), nil
+	}
+	for _, layout := range []string{time.RFC3339Nano, "20060102T150405Z0700"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is not an RFC 3339 or ISO 8601 basic time or Unix milliseconds", s)
+}
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/probitas-test/echo-servers/proto/echo/v1"
+	"github.com/probitas-test/echo-servers/shared/clock"
+)
+
+func TestServerTime(t *testing.T) {
+	s := NewEchoServer()
+	s.SetClock(clock.New(clock.Config{Offset: time.Hour}), time.Minute)
+
+	resp, err := s.ServerTime(context.Background(), &pb.ServerTimeRequest{ClientTime: timestamppb.Now()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.SkewMs != time.Hour.Milliseconds() || resp.MaxDriftMs != time.Minute.Milliseconds() {
+		t.Errorf("skew_ms = %d, max_drift_ms = %d", resp.SkewMs, resp.MaxDriftMs)
+	}
+	if d := time.Until(resp.ServerTime.AsTime()); d < 59*time.Minute || d > 61*time.Minute {
+		t.Errorf("server_time %s is not an hour ahead", resp.ServerTime.AsTime())
+	}
+	if resp.DriftMs > -59*time.Minute.Milliseconds() || !resp.DriftExceeded {
+		t.Errorf("drift_ms = %d, drift_exceeded = %t", resp.DriftMs, resp.DriftExceeded)
+	}
+
+	// Without a client time, no drift
+	resp, err = s.ServerTime(context.Background(), &pb.ServerTimeRequest{})
+	if err != nil || resp.DriftMs != 0 || resp.DriftExceeded {
+		t.Errorf("without client_time: %v, %v", resp, err)
+	}
+}
+
+func TestClockServerOptions(t *testing.T) {
+	clk := clock.New(clock.Config{Offset: time.Hour})
+	echoServer := NewEchoServer()
+	echoServer.SetClock(clk, time.Minute)
+	s := grpc.NewServer(ClockServerOptions(clk, time.Minute)...)
+	pb.RegisterEchoServer(s, echoServer)
+	conn, err := grpc.NewClient(listen(t, s), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	client := pb.NewEchoClient(conn)
+
+	withTimestamp := func(v string) context.Context {
+		return metadata.AppendToOutgoingContext(context.Background(), RequestTimestampHeader, v)
+	}
+	serverNow := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		wantCode  codes.Code
+		wantDrift bool
+	}{
+		{name: "no timestamp", ctx: context.Background(), wantCode: codes.OK},
+		{name: "RFC 3339 on the server clock", ctx: withTimestamp(serverNow.Format(time.RFC3339)), wantCode: codes.OK},
+		{name: "ISO 8601 basic on the server clock", ctx: withTimestamp(serverNow.UTC().Format("20060102T150405Z")), wantCode: codes.OK},
+		{name: "Unix milliseconds on the server clock", ctx: withTimestamp(strconv.FormatInt(serverNow.UnixMilli(), 10)), wantCode: codes.OK},
+		{name: "real time", ctx: withTimestamp(time.Now().Format(time.RFC3339)), wantCode: codes.FailedPrecondition, wantDrift: true},
+		{name: "ahead of the server clock", ctx: withTimestamp(serverNow.Add(2 * time.Minute).Format(time.RFC3339)), wantCode: codes.FailedPrecondition, wantDrift: true},
+		{name: "invalid", ctx: withTimestamp("yesterday"), wantCode: codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var trailer metadata.MD
+			_, err := client.Echo(tt.ctx, &pb.EchoRequest{Message: "hello"}, grpc.Trailer(&trailer))
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("code = %v, want %v", err, tt.wantCode)
+			}
+			if !tt.wantDrift {
+				return
+			}
+			if values := trailer.Get(ServerTimeTrailer); len(values) != 1 {
+				t.Errorf("trailer %s = %v", ServerTimeTrailer, values)
+			}
+			var info *errdetails.ErrorInfo
+			for _, detail := range status.Convert(err).Details() {
+				if d, ok := detail.(*errdetails.ErrorInfo); ok {
+					info = d
+				}
+			}
+			if info == nil || info.Reason != clockSkewReason || info.Metadata["max_drift_ms"] != "60000" {
+				t.Errorf("error info = %v", info)
+			}
+		})
+	}
+
+	// ServerTime stays reachable to measure the skew
+	ctx := withTimestamp(time.Now().Format(time.RFC3339))
+	if _, err := client.ServerTime(ctx, &pb.ServerTimeRequest{}); err != nil {
+		t.Errorf("ServerTime with a drifting timestamp: %v", err)
+	}
+
+	// Moving the clock back to the real time accepts the real time
+	clk.SetOffset(0)
+	if _, err := client.Echo(ctx, &pb.EchoRequest{Message: "hello"}); err != nil {
+		t.Errorf("after resetting the offset: %v", err)
+	}
+}
@@ -3,6 +3,7 @@ package server
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"strings"
@@ -12,10 +13,17 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
 	"google.golang.org/protobuf/types/known/durationpb"
 
-	pb "github.com/probitas-test/echo-servers/echo-grpc/proto"
+	pb "github.com/probitas-test/echo-servers/proto/echo/v1"
+	"github.com/probitas-test/echo-servers/shared/clock"
+	"github.com/probitas-test/echo-servers/shared/credentials"
+	"github.com/probitas-test/echo-servers/shared/network"
+	"github.com/probitas-test/echo-servers/shared/version"
 )
 
 const (
@@ -25,12 +33,43 @@ const (
 
 type EchoServer struct {
 	pb.UnimplementedEchoServer
+
+	// build is reported by Version
+	build version.Info
+	// creds judges the credentials reported by Credentials
+	creds *credentials.Store
+	// http2 and clientSettings are reported by Http2Settings
+	http2          HTTP2Config
+	clientSettings *ClientSettings
+	// clock and maxDrift are reported by ServerTime
+	clock    *clock.Clock
+	maxDrift time.Duration
+	// burner caps the resources consumed by BurnResources
+	burner *Burner
 }
 
 func NewEchoServer() *EchoServer {
 	return &EchoServer{}
 }
 
+// SetBuild sets the build information reported by Version
+func (s *EchoServer) SetBuild(info version.Info) {
+	s.build = info
+}
+
+// SetHTTP2 sets the server HTTP/2 configuration and the client settings
+// reported by Http2Settings
+func (s *EchoServer) SetHTTP2(cfg HTTP2Config, clients *ClientSettings) {
+	s.http2 = cfg
+	s.clientSettings = clients
+}
+
+// SetCredentials sets the store judging the credentials reported by
+// Credentials
+func (s *EchoServer) SetCredentials(store *credentials.Store) {
+	s.creds = store
+}
+
 func (s *EchoServer) Echo(ctx context.Context, req *pb.EchoRequest) (*pb.EchoResponse, error) {
 	resp := &pb.EchoResponse{
 		Message:  req.Message,
@@ -197,48 +236,107 @@ func (s *EchoServer) EchoErrorWithDetails(_ context.Context, req *pb.EchoErrorWi
 		message = fmt.Sprintf("error with code %d", req.Code)
 	}
 
-	st := status.New(code, message)
+	p := status.New(code, message).Proto()
 
-	// Add rich error details
+	// Add rich error details; an Any is attached as is rather than wrapped
+	// in another Any
 	for _, detail := range req.Details {
-		var err error
-		switch detail.Type {
-		case "bad_request":
-			br := &errdetails.BadRequest{}
-			for _, fv := range detail.FieldViolations {
-				br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
-					Field:       fv.Field,
-					Description: fv.Description,
-				})
-			}
-			st, err = st.WithDetails(br)
-		case "retry_info":
-			ri := &errdetails.RetryInfo{
-				RetryDelay: durationpb.New(time.Duration(detail.RetryDelayMs) * time.Millisecond),
-			}
-			st, err = st.WithDetails(ri)
-		case "debug_info":
-			di := &errdetails.DebugInfo{
-				StackEntries: detail.StackEntries,
-				Detail:       detail.DebugDetail,
-			}
-			st, err = st.WithDetails(di)
-		case "quota_failure":
-			qf := &errdetails.QuotaFailure{}
-			for _, qv := range detail.QuotaViolations {
-				qf.Violations = append(qf.Violations, &errdetails.QuotaFailure_Violation{
-					Subject:     qv.Subject,
-					Description: qv.Description,
-				})
-			}
-			st, err = st.WithDetails(qf)
+		msg := errorDetail(detail)
+		if msg == nil {
+			continue
 		}
-		if err != nil {
-			return nil, status.Errorf(codes.Internal, "failed to attach error details: %v", err)
+		a, ok := msg.(*anypb.Any)
+		if !ok {
+			var err error
+			if a, err = anypb.New(msg); err != nil {
+				return nil, status.Errorf(codes.Internal, "failed to attach error details: %v", err)
+			}
 		}
+		p.Details = append(p.Details, a)
 	}
 
-	return nil, st.Err()
+	return nil, status.FromProto(p).Err()
+}
+
+// errorDetail builds the google.rpc message of detail, nil for an unknown
+// type or an empty any
+func errorDetail(detail *pb.ErrorDetail) proto.Message {
+	switch detail.Type {
+	case "bad_request":
+		br := &errdetails.BadRequest{}
+		for _, fv := range detail.FieldViolations {
+			br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       fv.Field,
+				Description: fv.Description,
+			})
+		}
+		return br
+	case "retry_info":
+		return &errdetails.RetryInfo{
+			RetryDelay: durationpb.New(time.Duration(detail.RetryDelayMs) * time.Millisecond),
+		}
+	case "debug_info":
+		return &errdetails.DebugInfo{
+			StackEntries: detail.StackEntries,
+			Detail:       detail.DebugDetail,
+		}
+	case "quota_failure":
+		qf := &errdetails.QuotaFailure{}
+		for _, qv := range detail.QuotaViolations {
+			qf.Violations = append(qf.Violations, &errdetails.QuotaFailure_Violation{
+				Subject:     qv.Subject,
+				Description: qv.Description,
+			})
+		}
+		return qf
+	case "error_info":
+		return &errdetails.ErrorInfo{
+			Reason:   detail.Reason,
+			Domain:   detail.Domain,
+			Metadata: detail.Metadata,
+		}
+	case "localized_message":
+		return &errdetails.LocalizedMessage{
+			Locale:  detail.Locale,
+			Message: detail.LocalizedMessage,
+		}
+	case "help":
+		h := &errdetails.Help{}
+		for _, l := range detail.Links {
+			h.Links = append(h.Links, &errdetails.Help_Link{
+				Description: l.Description,
+				Url:         l.Url,
+			})
+		}
+		return h
+	case "precondition_failure":
+		pf := &errdetails.PreconditionFailure{}
+		for _, pv := range detail.PreconditionViolations {
+			pf.Violations = append(pf.Violations, &errdetails.PreconditionFailure_Violation{
+				Type:        pv.Type,
+				Subject:     pv.Subject,
+				Description: pv.Description,
+			})
+		}
+		return pf
+	case "resource_info":
+		return &errdetails.ResourceInfo{
+			ResourceType: detail.ResourceType,
+			ResourceName: detail.ResourceName,
+			Owner:        detail.Owner,
+			Description:  detail.Description,
+		}
+	case "request_info":
+		return &errdetails.RequestInfo{
+			RequestId:   detail.RequestId,
+			ServingData: detail.ServingData,
+		}
+	case "any":
+		if detail.Any != nil {
+			return detail.Any
+		}
+	}
+	return nil
 }
 
 func (s *EchoServer) ServerStream(req *pb.ServerStreamRequest, stream grpc.ServerStreamingServer[pb.EchoResponse]) error {
@@ -288,6 +386,67 @@ func (s *EchoServer) ServerStream(req *pb.ServerStreamRequest, stream grpc.Serve
 	return nil
 }
 
+// ServerStreamResumable streams the messages numbered 1 to count that
+// follow last_sequence, so a client can resume a stream after a disconnect
+// by calling again with the last sequence it received. disconnect_after
+// fails the call with UNAVAILABLE after that many messages, simulating the
+// disconnect.
+func (s *EchoServer) ServerStreamResumable(req *pb.ServerStreamResumableRequest, stream grpc.ServerStreamingServer[pb.ResumableStreamResponse]) error {
+	ctx := stream.Context()
+	md := make(map[string]string)
+
+	if inMd, ok := metadata.FromIncomingContext(ctx); ok {
+		for k, v := range inMd {
+			if len(v) > 0 {
+				md[k] = v[0]
+			}
+		}
+	}
+
+	count := int64(req.Count)
+	if count <= 0 {
+		count = 1
+	}
+	switch {
+	case req.LastSequence < 0:
+		return status.Errorf(codes.InvalidArgument, "last_sequence %d is negative", req.LastSequence)
+	case req.LastSequence > count:
+		return status.Errorf(codes.OutOfRange, "last_sequence %d is beyond the last message %d", req.LastSequence, count)
+	case req.DisconnectAfter < 0:
+		return status.Errorf(codes.InvalidArgument, "disconnect_after %d is negative", req.DisconnectAfter)
+	}
+
+	interval := time.Duration(req.IntervalMs) * time.Millisecond
+	sent := int32(0)
+
+	for seq := req.LastSequence + 1; seq <= count; seq++ {
+		if req.DisconnectAfter > 0 && sent == req.DisconnectAfter {
+			return status.Errorf(codes.Unavailable, "stream disconnected after sequence %d", seq-1)
+		}
+
+		resp := &pb.ResumableStreamResponse{
+			Sequence: seq,
+			Message:  fmt.Sprintf("%s [%d/%d]", req.Message, seq, count),
+			Metadata: md,
+		}
+
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+		sent++
+
+		if seq < count && interval > 0 {
+			select {
+			case <-time.After(interval):
+			case <-ctx.Done():
+				return status.Error(codes.Canceled, "stream canceled")
+			}
+		}
+	}
+
+	return nil
+}
+
 func (s *EchoServer) ClientStream(stream grpc.ClientStreamingServer[pb.EchoRequest, pb.EchoResponse]) error {
 	ctx := stream.Context()
 	md := make(map[string]string)
@@ -358,3 +517,92 @@ func (s *EchoServer) BidirectionalStream(stream grpc.BidiStreamingServer[pb.Echo
 		}
 	}
 }
+
+func (s *EchoServer) Version(_ context.Context, _ *pb.VersionRequest) (*pb.VersionResponse, error) {
+	resp := &pb.VersionResponse{
+		Server:    s.build.Server,
+		Version:   s.build.Version,
+		Commit:    s.build.Commit,
+		Date:      s.build.Date,
+		GoVersion: s.build.GoVersion,
+		Platform:  s.build.Platform,
+		Features:  s.build.Features,
+	}
+	return resp, nil
+}
+
+func (s *EchoServer) Network(ctx context.Context, _ *pb.NetworkRequest) (*pb.NetworkResponse, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Internal, "no peer in context")
+	}
+	info := network.Describe(p.LocalAddr, p.Addr)
+	resp := &pb.NetworkResponse{
+		Family:        info.Family,
+		ClientAddress: info.ClientAddr,
+		ServerAddress: info.ServerAddr,
+		Listen:        info.Listen,
+	}
+	return resp, nil
+}
+
+// Credentials reports the per-RPC credentials of the call (authorization or
+// x-api-key metadata) and whether the store accepts them, so call
+// credential code paths can be tested without credentials being required
+func (s *EchoServer) Credentials(ctx context.Context, _ *pb.CredentialsRequest) (*pb.CredentialsResponse, error) {
+	if s.creds == nil {
+		return nil, status.Error(codes.FailedPrecondition, "no credential store")
+	}
+	id, err := s.creds.Identify(incomingCredentials(ctx))
+	resp := &pb.CredentialsResponse{
+		Type:             id.Type,
+		Authenticated:    err == nil,
+		Username:         id.User.Username,
+		Required:         s.creds.Required(),
+		SecurityProtocol: "insecure",
+	}
+	if err != nil {
+		resp.Error = err.Error()
+	}
+	if len(id.Claims) > 0 {
+		resp.Claims = make(map[string]string, len(id.Claims))
+		for name, value := range id.Claims {
+			if str, ok := value.(string); ok {
+				resp.Claims[name] = str
+				continue
+			}
+			b, _ := json.Marshal(value)
+			resp.Claims[name] = string(b)
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.AuthInfo != nil {
+		resp.SecurityProtocol = p.AuthInfo.AuthType()
+	}
+	return resp, nil
+}
+
+// Http2Settings reports the HTTP/2 settings advertised by the server and
+// the ones the client sent in the preface of the connection of the call
+func (s *EchoServer) Http2Settings(ctx context.Context, _ *pb.Http2SettingsRequest) (*pb.Http2SettingsResponse, error) {
+	resp := &pb.Http2SettingsResponse{
+		Server:        http2SettingsProto(s.http2.Settings()),
+		DynamicWindow: s.http2.DynamicWindow(),
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		if settings, ok := s.clientSettings.Get(p.Addr); ok {
+			resp.Client = http2SettingsProto(settings)
+		}
+	}
+	return resp, nil
+}
+
+func http2SettingsProto(s HTTP2Settings) *pb.Http2Settings {
+	return &pb.Http2Settings{
+		HeaderTableSize:      s.HeaderTableSize,
+		MaxConcurrentStreams: s.MaxConcurrentStreams,
+		InitialWindowSize:    s.InitialWindowSize,
+		MaxFrameSize:         s.MaxFrameSize,
+		MaxHeaderListSize:    s.MaxHeaderListSize,
+		ConnectionWindowSize: s.ConnWindowSize,
+	}
+}
@@ -2,6 +2,8 @@ package server
 
 import (
 	"context"
+	"encoding/base64"
+	"fmt"
 	"io"
 	"net"
 	"testing"
@@ -12,10 +14,15 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
 
-	pb "github.com/probitas-test/echo-servers/echo-grpc/proto"
+	pb "github.com/probitas-test/echo-servers/proto/echo/v1"
+	"github.com/probitas-test/echo-servers/shared/credentials"
+	"github.com/probitas-test/echo-servers/shared/version"
 )
 
 func setupTestServer(t *testing.T) (pb.EchoClient, func()) {
@@ -225,6 +232,73 @@ func TestServerStream_MessagesContainCorrectContent(t *testing.T) {
 	}
 }
 
+func TestServerStreamResumable_ResumesAfterDisconnect(t *testing.T) {
+	client, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	var received []int64
+	last := int64(0)
+	for calls := 1; ; calls++ {
+		if calls > 5 {
+			t.Fatalf("stream not complete after %d calls: %v", calls-1, received)
+		}
+		stream, err := client.ServerStreamResumable(context.Background(), &pb.ServerStreamResumableRequest{
+			Message:         "resume",
+			Count:           5,
+			LastSequence:    last,
+			DisconnectAfter: 2,
+		})
+		if err != nil {
+			t.Fatalf("ServerStreamResumable failed: %v", err)
+		}
+		for {
+			resp, err := stream.Recv()
+			if err == io.EOF {
+				if calls != 3 {
+					t.Errorf("expected the stream to complete on the third call, got %d", calls)
+				}
+				want := []int64{1, 2, 3, 4, 5}
+				if fmt.Sprint(received) != fmt.Sprint(want) {
+					t.Errorf("expected sequences %v, got %v", want, received)
+				}
+				return
+			}
+			if status.Code(err) == codes.Unavailable {
+				break
+			}
+			if err != nil {
+				t.Fatalf("Recv failed: %v", err)
+			}
+			received = append(received, resp.Sequence)
+			last = resp.Sequence
+		}
+	}
+}
+
+func TestServerStreamResumable_InvalidSequence(t *testing.T) {
+	client, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	for seq, code := range map[int64]codes.Code{-1: codes.InvalidArgument, 4: codes.OutOfRange} {
+		stream, err := client.ServerStreamResumable(context.Background(), &pb.ServerStreamResumableRequest{Count: 3, LastSequence: seq})
+		if err == nil {
+			_, err = stream.Recv()
+		}
+		if status.Code(err) != code {
+			t.Errorf("last_sequence %d: expected %v, got %v", seq, code, err)
+		}
+	}
+
+	// Resuming after the last message ends the stream
+	stream, err := client.ServerStreamResumable(context.Background(), &pb.ServerStreamResumableRequest{Count: 3, LastSequence: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); err != io.EOF {
+		t.Errorf("expected the stream to end, got %v", err)
+	}
+}
+
 func TestClientStream_AggregatesMessages(t *testing.T) {
 	client, cleanup := setupTestServer(t)
 	defer cleanup()
@@ -659,3 +733,152 @@ func TestEchoErrorWithDetails_QuotaFailure(t *testing.T) {
 		t.Errorf("expected subject %q, got %q", "user:123", qf.Violations[0].Subject)
 	}
 }
+
+func TestEchoErrorWithDetails_Catalogue(t *testing.T) {
+	client, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	custom, err := anypb.New(&pb.EchoResponse{Message: "custom"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.EchoErrorWithDetails(context.Background(), &pb.EchoErrorWithDetailsRequest{
+		Code: int32(codes.FailedPrecondition),
+		Details: []*pb.ErrorDetail{
+			{Type: "error_info", Reason: "STOCKOUT", Domain: "shop.example.com", Metadata: map[string]string{"sku": "A1"}},
+			{Type: "localized_message", Locale: "ja-JP", LocalizedMessage: "在庫切れです"},
+			{Type: "help", Links: []*pb.HelpLink{{Description: "Restock", Url: "https://example.com/restock"}}},
+			{Type: "precondition_failure", PreconditionViolations: []*pb.PreconditionViolation{{Type: "STOCK", Subject: "sku:A1", Description: "out of stock"}}},
+			{Type: "resource_info", ResourceType: "sku", ResourceName: "A1", Owner: "shop", Description: "not found"},
+			{Type: "request_info", RequestId: "req-1", ServingData: "replica-2"},
+			{Type: "any", Any: custom},
+			{Type: "any"},
+		},
+	})
+
+	st, ok := status.FromError(err)
+	if !ok || st.Code() != codes.FailedPrecondition {
+		t.Fatalf("expected FailedPrecondition, got %v", err)
+	}
+	details := st.Details()
+
+	if len(details) != 7 {
+		t.Fatalf("expected 7 error details, got %d", len(details))
+	}
+	if d, ok := details[0].(*errdetails.ErrorInfo); !ok || d.Reason != "STOCKOUT" || d.Domain != "shop.example.com" || d.Metadata["sku"] != "A1" {
+		t.Errorf("ErrorInfo = %v", details[0])
+	}
+	if d, ok := details[1].(*errdetails.LocalizedMessage); !ok || d.Locale != "ja-JP" || d.Message != "在庫切れです" {
+		t.Errorf("LocalizedMessage = %v", details[1])
+	}
+	if d, ok := details[2].(*errdetails.Help); !ok || len(d.Links) != 1 || d.Links[0].Url != "https://example.com/restock" {
+		t.Errorf("Help = %v", details[2])
+	}
+	if d, ok := details[3].(*errdetails.PreconditionFailure); !ok || len(d.Violations) != 1 || d.Violations[0].Type != "STOCK" {
+		t.Errorf("PreconditionFailure = %v", details[3])
+	}
+	if d, ok := details[4].(*errdetails.ResourceInfo); !ok || d.ResourceName != "A1" || d.Owner != "shop" {
+		t.Errorf("ResourceInfo = %v", details[4])
+	}
+	if d, ok := details[5].(*errdetails.RequestInfo); !ok || d.RequestId != "req-1" {
+		t.Errorf("RequestInfo = %v", details[5])
+	}
+	// An Any is attached as is, not wrapped in another Any
+	if d, ok := details[6].(*pb.EchoResponse); !ok || d.Message != "custom" {
+		t.Errorf("Any = %v", details[6])
+	}
+}
+
+func TestVersion_ReturnsBuild(t *testing.T) {
+	s := NewEchoServer()
+	s.SetBuild(version.New("echo-grpc", version.Features{"reflection": true, "chaos": false}))
+
+	resp, err := s.Version(context.Background(), &pb.VersionRequest{})
+
+	if err != nil {
+		t.Fatalf("Version failed: %v", err)
+	}
+	if resp.Server != "echo-grpc" || resp.Version == "" || resp.GoVersion == "" {
+		t.Errorf("unexpected build: %v", resp)
+	}
+	if len(resp.Features) != 1 || resp.Features[0] != "reflection" {
+		t.Errorf("expected features [reflection], got %v", resp.Features)
+	}
+}
+
+func TestNetwork_ReportsFamily(t *testing.T) {
+	s := NewEchoServer()
+	ctx := peer.NewContext(context.Background(), &peer.Peer{
+		Addr:      &net.TCPAddr{IP: net.ParseIP("::1"), Port: 40000},
+		LocalAddr: &net.TCPAddr{IP: net.ParseIP("::1"), Port: 50051},
+	})
+
+	resp, err := s.Network(ctx, &pb.NetworkRequest{})
+
+	if err != nil {
+		t.Fatalf("Network failed: %v", err)
+	}
+	if resp.Family != "ipv6" || resp.ClientAddress != "[::1]:40000" || resp.ServerAddress != "[::1]:50051" || resp.Listen != "dual" {
+		t.Errorf("unexpected network: %v", resp)
+	}
+}
+
+// callCredentials sends static per-RPC metadata, like the token sources of
+// gRPC clients, without requiring transport security
+type callCredentials map[string]string
+
+func (c callCredentials) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return c, nil
+}
+
+func (callCredentials) RequireTransportSecurity() bool { return false }
+
+func TestCredentials_ReportsCallCredentials(t *testing.T) {
+	store, err := credentials.New(credentials.Config{Users: []string{"bob:secret"}, JWTIssuer: "http://idp.test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer()
+	echo := NewEchoServer()
+	echo.SetCredentials(store)
+	pb.RegisterEchoServer(s, echo)
+	addr := listen(t, s)
+
+	enc := base64.RawURLEncoding.EncodeToString
+	jwt := enc([]byte(`{"alg":"none"}`)) + "." + enc([]byte(`{"iss":"http://idp.test","sub":"carol","exp":4102444800}`)) + "."
+	tests := []struct {
+		name  string
+		creds callCredentials
+		want  *pb.CredentialsResponse
+	}{
+		{"none", nil, &pb.CredentialsResponse{Type: "none", Error: "missing credentials"}},
+		{"static token", callCredentials{"authorization": "Bearer " + (credentials.User{Username: "bob", Password: "secret"}).Token()},
+			&pb.CredentialsResponse{Type: "bearer", Authenticated: true, Username: "bob"}},
+		{"invalid API key", callCredentials{"x-api-key": "other"}, &pb.CredentialsResponse{Type: "api_key", Error: "invalid credentials"}},
+		{"OAuth JWT", callCredentials{"authorization": "Bearer " + jwt},
+			&pb.CredentialsResponse{Type: "jwt", Authenticated: true, Username: "carol",
+				Claims: map[string]string{"iss": "http://idp.test", "sub": "carol", "exp": "4102444800"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+			if tt.creds != nil {
+				opts = append(opts, grpc.WithPerRPCCredentials(tt.creds))
+			}
+			conn, err := grpc.NewClient(addr, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() { _ = conn.Close() }()
+
+			resp, err := pb.NewEchoClient(conn).Credentials(context.Background(), &pb.CredentialsRequest{})
+			if err != nil {
+				t.Fatalf("Credentials failed: %v", err)
+			}
+			tt.want.SecurityProtocol = "insecure"
+			if !proto.Equal(resp, tt.want) {
+				t.Errorf("Credentials = %v, want %v", resp, tt.want)
+			}
+		})
+	}
+}
@@ -48,12 +48,27 @@ func (h *HealthServer) GetServingStatus(service string) healthpb.HealthCheckResp
 	return healthpb.HealthCheckResponse_SERVICE_UNKNOWN
 }
 
-// Shutdown sets all services to NOT_SERVING status.
-func (h *HealthServer) Shutdown() {
+// SetAllServingStatus updates the serving status of every service.
+func (h *HealthServer) SetAllServingStatus(status healthpb.HealthCheckResponse_ServingStatus) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 	for service := range h.services {
-		h.services[service] = healthpb.HealthCheckResponse_NOT_SERVING
-		h.Server.SetServingStatus(service, healthpb.HealthCheckResponse_NOT_SERVING)
+		h.services[service] = status
+		h.Server.SetServingStatus(service, status)
+	}
+}
+
+// SetHealthy sets every service to SERVING or NOT_SERVING, following the
+// health toggled through the admin API.
+func (h *HealthServer) SetHealthy(healthy bool) {
+	if healthy {
+		h.SetAllServingStatus(healthpb.HealthCheckResponse_SERVING)
+	} else {
+		h.SetAllServingStatus(healthpb.HealthCheckResponse_NOT_SERVING)
 	}
 }
+
+// Shutdown sets all services to NOT_SERVING status.
+func (h *HealthServer) Shutdown() {
+	h.SetAllServingStatus(healthpb.HealthCheckResponse_NOT_SERVING)
+}
@@ -0,0 +1,332 @@
+package server
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"net"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+)
+
+// HTTP/2 defaults (RFC 9113) and the fixed values of grpc-go
+const (
+	defaultHeaderTableSize = 4096
+	defaultWindowSize      = 65535
+	defaultMaxFrameSize    = 16384
+	// grpc-go always advertises and reads frames of up to 16KB
+	grpcMaxFrameSize = 16384
+	// grpc-go accepts headers of up to 16MB unless configured
+	grpcMaxHeaderListSize = 16 << 20
+)
+
+// HTTP/2 setting identifiers
+const (
+	settingHeaderTableSize      = 0x1
+	settingMaxConcurrentStreams = 0x3
+	settingInitialWindowSize    = 0x4
+	settingMaxFrameSize         = 0x5
+	settingMaxHeaderListSize    = 0x6
+)
+
+// HTTP2Config sets the HTTP/2 settings advertised by the server; zero
+// values keep the grpc-go defaults
+type HTTP2Config struct {
+	// InitialWindowSize is the stream flow control window, at least 65535;
+	// without it or InitialConnWindowSize grpc-go sizes the windows with
+	// BDP estimation
+	InitialWindowSize int32
+	// InitialConnWindowSize is the connection flow control window, at
+	// least 65535
+	InitialConnWindowSize int32
+	// MaxHeaderListSize bounds the headers accepted (default 16MB)
+	MaxHeaderListSize uint32
+	// HeaderTableSize is the HPACK dynamic table size (default 4096)
+	HeaderTableSize uint32
+	// MaxConcurrentStreams bounds the streams per connection (default
+	// unlimited)
+	MaxConcurrentStreams uint32
+}
+
+// Validate reports the first window below the HTTP/2 minimum, which
+// grpc-go would silently ignore
+func (c HTTP2Config) Validate() error {
+	switch {
+	case c.InitialWindowSize != 0 && c.InitialWindowSize < defaultWindowSize:
+		return fmt.Errorf("initial window size %d is below %d", c.InitialWindowSize, defaultWindowSize)
+	case c.InitialConnWindowSize != 0 && c.InitialConnWindowSize < defaultWindowSize:
+		return fmt.Errorf("initial connection window size %d is below %d", c.InitialConnWindowSize, defaultWindowSize)
+	}
+	return nil
+}
+
+// DynamicWindow reports whether grpc-go sizes the flow control windows
+// with BDP estimation, no window being configured
+func (c HTTP2Config) DynamicWindow() bool {
+	return c.InitialWindowSize == 0 && c.InitialConnWindowSize == 0
+}
+
+// ServerOptions returns the server options applying c
+func (c HTTP2Config) ServerOptions() []grpc.ServerOption {
+	var opts []grpc.ServerOption
+	if c.InitialWindowSize != 0 {
+		opts = append(opts, grpc.InitialWindowSize(c.InitialWindowSize))
+	}
+	if c.InitialConnWindowSize != 0 {
+		opts = append(opts, grpc.InitialConnWindowSize(c.InitialConnWindowSize))
+	}
+	if c.MaxHeaderListSize != 0 {
+		opts = append(opts, grpc.MaxHeaderListSize(c.MaxHeaderListSize))
+	}
+	if c.HeaderTableSize != 0 {
+		opts = append(opts, grpc.HeaderTableSize(c.HeaderTableSize))
+	}
+	if c.MaxConcurrentStreams != 0 {
+		opts = append(opts, grpc.MaxConcurrentStreams(c.MaxConcurrentStreams))
+	}
+	return opts
+}
+
+// Settings returns the settings the server advertises with c, defaults
+// included
+func (c HTTP2Config) Settings() HTTP2Settings {
+	s := HTTP2Settings{
+		HeaderTableSize:      c.HeaderTableSize,
+		MaxConcurrentStreams: c.MaxConcurrentStreams,
+		InitialWindowSize:    uint32(c.InitialWindowSize),
+		MaxFrameSize:         grpcMaxFrameSize,
+		MaxHeaderListSize:    c.MaxHeaderListSize,
+		ConnWindowSize:       uint32(c.InitialConnWindowSize),
+	}
+	if s.HeaderTableSize == 0 {
+		s.HeaderTableSize = defaultHeaderTableSize
+	}
+	if s.InitialWindowSize == 0 {
+		s.InitialWindowSize = defaultWindowSize
+	}
+	if s.MaxHeaderListSize == 0 {
+		s.MaxHeaderListSize = grpcMaxHeaderListSize
+	}
+	if s.ConnWindowSize == 0 {
+		s.ConnWindowSize = defaultWindowSize
+	}
+	return s
+}
+
+// String describes the settings for the startup log
+func (c HTTP2Config) String() string {
+	var parts []string
+	for _, p := range []struct {
+		name  string
+		value int64
+	}{
+		{"initial_window_size", int64(c.InitialWindowSize)},
+		{"initial_conn_window_size", int64(c.InitialConnWindowSize)},
+		{"max_header_list_size", int64(c.MaxHeaderListSize)},
+		{"header_table_size", int64(c.HeaderTableSize)},
+		{"max_concurrent_streams", int64(c.MaxConcurrentStreams)},
+	} {
+		if p.value != 0 {
+			parts = append(parts, fmt.Sprintf("%s=%d", p.name, p.value))
+		}
+	}
+	if len(parts) == 0 {
+		return "defaults"
+	}
+	return strings.Join(parts, " ")
+}
+
+// HTTP2Settings are the HTTP/2 settings of one side of a connection, with
+// their defaults when not sent; 0 is unlimited
+type HTTP2Settings struct {
+	HeaderTableSize      uint32
+	MaxConcurrentStreams uint32
+	InitialWindowSize    uint32
+	MaxFrameSize         uint32
+	MaxHeaderListSize    uint32
+	// ConnWindowSize is the connection flow control window, raised from
+	// 65535 by the WINDOW_UPDATE frames sent before the first request
+	ConnWindowSize uint32
+}
+
+// ClientSettings holds the HTTP/2 settings the clients sent in their
+// connection preface, by client address
+type ClientSettings struct {
+	mu    sync.Mutex
+	conns map[string]*settingsConn
+}
+
+// TrackHTTP2Settings returns l recording the HTTP/2 settings of its
+// accepted connections in the returned ClientSettings until they are
+// closed. Settings are read from the client preface of plaintext (h2c)
+// connections.
+func TrackHTTP2Settings(l net.Listener) (net.Listener, *ClientSettings) {
+	c := &ClientSettings{conns: make(map[string]*settingsConn)}
+	return &settingsListener{Listener: l, settings: c}, c
+}
+
+// Get returns the settings of the client connected from addr, if its
+// preface was read
+func (c *ClientSettings) Get(addr net.Addr) (HTTP2Settings, bool) {
+	if c == nil || addr == nil {
+		return HTTP2Settings{}, false
+	}
+	c.mu.Lock()
+	conn, ok := c.conns[addr.String()]
+	c.mu.Unlock()
+	if !ok {
+		return HTTP2Settings{}, false
+	}
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+	return conn.settings, conn.received
+}
+
+type settingsListener struct {
+	net.Listener
+	settings *ClientSettings
+}
+
+func (l *settingsListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	tracked := &settingsConn{
+		Conn: conn,
+		all:  l.settings,
+		settings: HTTP2Settings{
+			HeaderTableSize:   defaultHeaderTableSize,
+			InitialWindowSize: defaultWindowSize,
+			MaxFrameSize:      defaultMaxFrameSize,
+			ConnWindowSize:    defaultWindowSize,
+		},
+	}
+	l.settings.mu.Lock()
+	l.settings.conns[conn.RemoteAddr().String()] = tracked
+	l.settings.mu.Unlock()
+	return tracked, nil
+}
+
+// clientPreface starts every HTTP/2 connection
+var clientPreface = []byte("PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n")
+
+// maxPrefaceSize bounds the bytes buffered before the first request
+const maxPrefaceSize = 64 << 10
+
+// HTTP/2 frame types and flags read from the preface
+const (
+	frameHeaders      = 0x1
+	frameSettings     = 0x4
+	frameWindowUpdate = 0x8
+	flagAck           = 0x1
+)
+
+// settingsConn parses the frames read until the first request: the
+// client preface, its SETTINGS and connection WINDOW_UPDATE frames
+type settingsConn struct {
+	net.Conn
+	all  *ClientSettings
+	once sync.Once
+
+	mu       sync.Mutex
+	buf      []byte
+	done     bool
+	received bool
+	settings HTTP2Settings
+}
+
+// NetConn returns the wrapped connection
+func (c *settingsConn) NetConn() net.Conn {
+	return c.Conn
+}
+
+func (c *settingsConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	if n > 0 {
+		c.parse(p[:n])
+	}
+	return n, err
+}
+
+func (c *settingsConn) Close() error {
+	c.once.Do(func() {
+		c.all.mu.Lock()
+		if c.all.conns[c.RemoteAddr().String()] == c {
+			delete(c.all.conns, c.RemoteAddr().String())
+		}
+		c.all.mu.Unlock()
+	})
+	return c.Conn.Close()
+}
+
+func (c *settingsConn) parse(p []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.done {
+		return
+	}
+	c.buf = append(c.buf, p...)
+	if !c.received {
+		if len(c.buf) < len(clientPreface) {
+			if !bytes.HasPrefix(clientPreface, c.buf) {
+				c.stop()
+			}
+			return
+		}
+		if !bytes.HasPrefix(c.buf, clientPreface) {
+			c.stop()
+			return
+		}
+		c.received = true
+		c.buf = c.buf[len(clientPreface):]
+	}
+	for len(c.buf) >= 9 {
+		length := int(c.buf[0])<<16 | int(c.buf[1])<<8 | int(c.buf[2])
+		typ, flags := c.buf[3], c.buf[4]
+		stream := binary.BigEndian.Uint32(c.buf[5:9]) & math.MaxInt32
+		if len(c.buf) < 9+length {
+			break
+		}
+		payload := c.buf[9 : 9+length]
+		switch {
+		case typ == frameHeaders:
+			c.stop()
+			return
+		case typ == frameSettings && flags&flagAck == 0:
+			for i := 0; i+6 <= len(payload); i += 6 {
+				c.set(binary.BigEndian.Uint16(payload[i:]), binary.BigEndian.Uint32(payload[i+2:]))
+			}
+		case typ == frameWindowUpdate && stream == 0 && length == 4:
+			c.settings.ConnWindowSize += binary.BigEndian.Uint32(payload) & math.MaxInt32
+		}
+		c.buf = c.buf[9+length:]
+	}
+	if len(c.buf) > maxPrefaceSize {
+		c.stop()
+	}
+}
+
+// stop ends the parsing, releasing the buffer
+func (c *settingsConn) stop() {
+	c.done = true
+	c.buf = nil
+}
+
+func (c *settingsConn) set(id uint16, value uint32) {
+	switch id {
+	case settingHeaderTableSize:
+		c.settings.HeaderTableSize = value
+	case settingMaxConcurrentStreams:
+		c.settings.MaxConcurrentStreams = value
+	case settingInitialWindowSize:
+		c.settings.InitialWindowSize = value
+	case settingMaxFrameSize:
+		c.settings.MaxFrameSize = value
+	case settingMaxHeaderListSize:
+		c.settings.MaxHeaderListSize = value
+	}
+}
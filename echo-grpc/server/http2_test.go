@@ -0,0 +1,73 @@
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/probitas-test/echo-servers/proto/echo/v1"
+)
+
+func TestHttp2Settings(t *testing.T) {
+	cfg := HTTP2Config{InitialWindowSize: 1 << 20, MaxConcurrentStreams: 10, HeaderTableSize: 8192}
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lis, clients := TrackHTTP2Settings(lis)
+	s := grpc.NewServer(cfg.ServerOptions()...)
+	echo := NewEchoServer()
+	echo.SetHTTP2(cfg, clients)
+	pb.RegisterEchoServer(s, echo)
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient(lis.Addr().String(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithInitialWindowSize(256<<10),
+		grpc.WithInitialConnWindowSize(512<<10),
+		grpc.WithMaxHeaderListSize(32<<10),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	resp, err := pb.NewEchoClient(conn).Http2Settings(context.Background(), &pb.Http2SettingsRequest{})
+	if err != nil {
+		t.Fatalf("Http2Settings failed: %v", err)
+	}
+	want := &pb.Http2SettingsResponse{
+		Server: &pb.Http2Settings{
+			HeaderTableSize:      8192,
+			MaxConcurrentStreams: 10,
+			InitialWindowSize:    1 << 20,
+			MaxFrameSize:         16384,
+			MaxHeaderListSize:    16 << 20,
+			ConnectionWindowSize: 65535,
+		},
+		Client: &pb.Http2Settings{
+			HeaderTableSize:      4096,
+			InitialWindowSize:    256 << 10,
+			MaxFrameSize:         16384,
+			MaxHeaderListSize:    32 << 10,
+			ConnectionWindowSize: 512 << 10,
+		},
+	}
+	if !proto.Equal(resp, want) {
+		t.Errorf("Http2Settings = %v, want %v", resp, want)
+	}
+}
+
+func TestHTTP2ConfigValidate(t *testing.T) {
+	if err := (HTTP2Config{InitialWindowSize: 1024}).Validate(); err == nil {
+		t.Error("window below 65535 accepted")
+	}
+	if err := (HTTP2Config{}).Validate(); err != nil {
+		t.Errorf("defaults: %v", err)
+	}
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/probitas-test/echo-servers/shared/logging"
+)
+
+// requestIDKey is the metadata key of the request ID
+const requestIDKey = "x-request-id"
+
+// LoggingServerOptions returns server options keeping the x-request-id
+// metadata of every RPC, or generating one when it is absent or invalid,
+// echoing it in the response header metadata and logging the RPC once it
+// completed.
+func LoggingServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(loggingUnaryInterceptor),
+		grpc.ChainStreamInterceptor(loggingStreamInterceptor),
+	}
+}
+
+func loggingUnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	ctx = withRequestID(ctx)
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDKey, logging.RequestID(ctx)))
+
+	resp, err := handler(ctx, req)
+	logRPC(ctx, info.FullMethod, start, err)
+	return resp, err
+}
+
+func loggingStreamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	ctx := withRequestID(ss.Context())
+	_ = ss.SetHeader(metadata.Pairs(requestIDKey, logging.RequestID(ctx)))
+
+	err := handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	logRPC(ctx, info.FullMethod, start, err)
+	return err
+}
+
+func withRequestID(ctx context.Context) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var id string
+	if values := md.Get(requestIDKey); len(values) > 0 {
+		id = values[0]
+	}
+	if !logging.ValidRequestID(id) {
+		id = logging.NewRequestID()
+	}
+	return logging.WithRequestID(ctx, id)
+}
+
+// logRPC logs a completed RPC; server-side failures are logged at error
+// level
+func logRPC(ctx context.Context, method string, start time.Time, err error) {
+	code := status.Code(err)
+	level := slog.LevelInfo
+	switch code {
+	case codes.Unknown, codes.DeadlineExceeded, codes.Unimplemented, codes.Internal, codes.Unavailable, codes.DataLoss:
+		level = slog.LevelError
+	}
+	slog.LogAttrs(ctx, level, "rpc",
+		slog.String("method", method),
+		slog.String("code", code.String()),
+		slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+	)
+}
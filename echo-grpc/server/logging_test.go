@@ -0,0 +1,78 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+
+	pb "github.com/probitas-test/echo-servers/proto/echo/v1"
+	"github.com/probitas-test/echo-servers/shared/logging"
+)
+
+func TestLoggingServerOptions(t *testing.T) {
+	var buf bytes.Buffer
+	h, err := logging.NewHandler(&buf, logging.Config{Level: "info", Format: logging.FormatText})
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev := slog.Default()
+	slog.SetDefault(slog.New(h))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+
+	lis := bufconn.Listen(1024 * 1024)
+	s := grpc.NewServer(LoggingServerOptions()...)
+	pb.RegisterEchoServer(s, NewEchoServer())
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough://bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	client := pb.NewEchoClient(conn)
+
+	// Kept when sent by the client
+	var header metadata.MD
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-request-id", "client-id-1")
+	if _, err := client.Echo(ctx, &pb.EchoRequest{Message: "hello"}, grpc.Header(&header)); err != nil {
+		t.Fatalf("Echo failed: %v", err)
+	}
+	if got := header.Get("x-request-id"); len(got) != 1 || got[0] != "client-id-1" {
+		t.Errorf("x-request-id = %v, want the client's", got)
+	}
+
+	// Generated when absent
+	if _, err := client.EchoError(context.Background(), &pb.EchoErrorRequest{Code: int32(codes.Internal)}, grpc.Header(&header)); err == nil {
+		t.Fatal("EchoError succeeded")
+	}
+	generated := header.Get("x-request-id")
+	if len(generated) != 1 || len(generated[0]) != 32 {
+		t.Errorf("x-request-id = %v, want a generated one", generated)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"level=INFO msg=rpc method=/echo.v1.Echo/Echo code=OK",
+		"request_id=client-id-1",
+		"level=ERROR msg=rpc method=/echo.v1.Echo/EchoError code=Internal",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log missing %q:\n%s", want, out)
+		}
+	}
+}
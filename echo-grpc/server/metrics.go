@@ -0,0 +1,97 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/probitas-test/echo-servers/shared/metrics"
+)
+
+const metricsProtocol = "grpc"
+
+// MetricsServerOptions returns server options recording every RPC in
+// Prometheus metrics, labelled with the full method and the status code.
+func MetricsServerOptions(m *metrics.Metrics) []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(metricsUnaryInterceptor(m)),
+		grpc.ChainStreamInterceptor(metricsStreamInterceptor(m)),
+	}
+}
+
+func metricsUnaryInterceptor(m *metrics.Metrics) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		defer m.Start(metricsProtocol)()
+		start := time.Now()
+
+		resp, err := handler(ctx, req)
+
+		var respSize int64
+		if err == nil {
+			respSize = messageSize(resp)
+		}
+		m.Observe(metrics.Observation{
+			Protocol:     metricsProtocol,
+			Method:       info.FullMethod,
+			Code:         status.Code(err).String(),
+			Duration:     time.Since(start),
+			RequestSize:  messageSize(req),
+			ResponseSize: respSize,
+		})
+		return resp, err
+	}
+}
+
+func metricsStreamInterceptor(m *metrics.Metrics) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		defer m.Start(metricsProtocol)()
+		start := time.Now()
+
+		counted := &countingStream{ServerStream: ss}
+		err := handler(srv, counted)
+
+		m.Observe(metrics.Observation{
+			Protocol:     metricsProtocol,
+			Method:       info.FullMethod,
+			Code:         status.Code(err).String(),
+			Duration:     time.Since(start),
+			RequestSize:  counted.received,
+			ResponseSize: counted.sent,
+		})
+		return err
+	}
+}
+
+// countingStream sums the sizes of the messages of a stream
+type countingStream struct {
+	grpc.ServerStream
+	received int64
+	sent     int64
+}
+
+func (s *countingStream) RecvMsg(msg any) error {
+	err := s.ServerStream.RecvMsg(msg)
+	if err == nil {
+		s.received += messageSize(msg)
+	}
+	return err
+}
+
+func (s *countingStream) SendMsg(msg any) error {
+	err := s.ServerStream.SendMsg(msg)
+	if err == nil {
+		s.sent += messageSize(msg)
+	}
+	return err
+}
+
+// messageSize returns the encoded size of a protobuf message
+func messageSize(msg any) int64 {
+	if m, ok := msg.(proto.Message); ok {
+		return int64(proto.Size(m))
+	}
+	return 0
+}
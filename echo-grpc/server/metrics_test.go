@@ -0,0 +1,75 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	pb "github.com/probitas-test/echo-servers/proto/echo/v1"
+	"github.com/probitas-test/echo-servers/shared/metrics"
+)
+
+func TestMetricsServerOptions(t *testing.T) {
+	m := metrics.New("echo-grpc")
+	lis := bufconn.Listen(1024 * 1024)
+	s := grpc.NewServer(MetricsServerOptions(m)...)
+	pb.RegisterEchoServer(s, NewEchoServer())
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough://bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	client := pb.NewEchoClient(conn)
+
+	ctx := context.Background()
+	if _, err := client.Echo(ctx, &pb.EchoRequest{Message: "hello"}); err != nil {
+		t.Fatalf("Echo failed: %v", err)
+	}
+	if _, err := client.EchoError(ctx, &pb.EchoErrorRequest{Code: int32(codes.NotFound)}); err == nil {
+		t.Fatal("EchoError succeeded")
+	}
+	stream, err := client.ServerStream(ctx, &pb.ServerStreamRequest{Message: "hi", Count: 3})
+	if err != nil {
+		t.Fatalf("ServerStream failed: %v", err)
+	}
+	for {
+		if _, err := stream.Recv(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("Recv failed: %v", err)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest("GET", metrics.Path, nil))
+	for _, want := range []string{
+		`echo_requests_total{code="OK",method="/echo.v1.Echo/Echo",protocol="grpc",server="echo-grpc"} 1`,
+		`echo_requests_total{code="NotFound",method="/echo.v1.Echo/EchoError",protocol="grpc",server="echo-grpc"} 1`,
+		`echo_requests_total{code="OK",method="/echo.v1.Echo/ServerStream",protocol="grpc",server="echo-grpc"} 1`,
+		`echo_request_size_bytes_sum{method="/echo.v1.Echo/Echo",protocol="grpc",server="echo-grpc"} 7`,
+		`echo_requests_in_flight{protocol="grpc",server="echo-grpc"} 0`,
+	} {
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("metrics missing %q", want)
+		}
+	}
+	if strings.Contains(rec.Body.String(), `echo_response_size_bytes_sum{method="/echo.v1.Echo/ServerStream",protocol="grpc",server="echo-grpc"} 0`) {
+		t.Error("streamed messages not counted")
+	}
+}
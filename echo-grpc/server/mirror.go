@@ -0,0 +1,89 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"net/url"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	grpccredentials "google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/probitas-test/echo-servers/shared/mirror"
+)
+
+// mirrorHeader marks mirrored calls with the name of the server
+var mirrorHeader = strings.ToLower(mirror.Header)
+
+// MirrorServerOptions returns server options sending a copy of the sampled
+// unary RPCs to the target of m in the background, with the request
+// metadata and an x-mirrored-by entry. The response of the target is
+// discarded; streaming RPCs are not mirrored.
+func MirrorServerOptions(m *mirror.Mirror) []grpc.ServerOption {
+	conns := &mirrorConns{conns: map[string]*grpc.ClientConn{}}
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if m.Sample() {
+				md, _ := metadata.FromIncomingContext(ctx)
+				md = mirrorMetadata(md)
+				m.Go(func(ctx context.Context, target *url.URL) error {
+					conn, err := conns.get(target)
+					if err != nil {
+						return err
+					}
+					ctx = metadata.NewOutgoingContext(ctx, md)
+					// Any response message decodes into Empty as unknown
+					// fields, so the reply type is not needed
+					return conn.Invoke(ctx, info.FullMethod, req, &emptypb.Empty{})
+				})
+			}
+			return handler(ctx, req)
+		}),
+	}
+}
+
+// mirrorMetadata returns the metadata of a mirrored call: md without the
+// entries set by the transport, plus x-mirrored-by
+func mirrorMetadata(md metadata.MD) metadata.MD {
+	out := metadata.MD{}
+	for key, values := range md {
+		switch {
+		case strings.HasPrefix(key, ":"), strings.HasPrefix(key, "grpc-"),
+			key == "content-type", key == "user-agent", key == "te":
+			continue
+		}
+		out[key] = values
+	}
+	out.Set(mirrorHeader, "echo-grpc")
+	return out
+}
+
+// mirrorConns keeps one client connection per mirror target, as the target
+// can change at runtime
+type mirrorConns struct {
+	mu    sync.Mutex
+	conns map[string]*grpc.ClientConn
+}
+
+func (c *mirrorConns) get(target *url.URL) (*grpc.ClientConn, error) {
+	key := target.Scheme + "://" + target.Host
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if conn, ok := c.conns[key]; ok {
+		return conn, nil
+	}
+	creds := insecure.NewCredentials()
+	if target.Scheme == "https" {
+		creds = grpccredentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+	}
+	conn, err := grpc.NewClient(target.Host, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, err
+	}
+	c.conns[key] = conn
+	return conn, nil
+}
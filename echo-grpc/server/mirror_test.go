@@ -0,0 +1,82 @@
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+
+	pb "github.com/probitas-test/echo-servers/proto/echo/v1"
+	"github.com/probitas-test/echo-servers/shared/mirror"
+)
+
+// shadowServer records the calls it receives
+type shadowServer struct {
+	pb.UnimplementedEchoServer
+	calls chan metadata.MD
+	msgs  chan string
+}
+
+func (s *shadowServer) Echo(ctx context.Context, req *pb.EchoRequest) (*pb.EchoResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	s.calls <- md
+	s.msgs <- req.Message
+	return &pb.EchoResponse{Message: "shadow"}, nil
+}
+
+func listen(t *testing.T, s *grpc.Server) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+	return lis.Addr().String()
+}
+
+func TestMirrorServerOptions(t *testing.T) {
+	shadow := &shadowServer{calls: make(chan metadata.MD, 1), msgs: make(chan string, 1)}
+	target := grpc.NewServer()
+	pb.RegisterEchoServer(target, shadow)
+	shadowAddr := listen(t, target)
+
+	m, err := mirror.New(mirror.Config{Target: "http://" + shadowAddr, SampleRate: 1, TimeoutMS: 1000, MaxInFlight: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer(MirrorServerOptions(m)...)
+	pb.RegisterEchoServer(s, NewEchoServer())
+	addr := listen(t, s)
+
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-test", "1")
+	resp, err := pb.NewEchoClient(conn).Echo(ctx, &pb.EchoRequest{Message: "hello"})
+	if err != nil || resp.Message != "hello" {
+		t.Fatalf("Echo = %v, %v; want the answer of the server, not of the mirror", resp, err)
+	}
+
+	select {
+	case md := <-shadow.calls:
+		if got := md.Get("x-test"); len(got) != 1 || got[0] != "1" {
+			t.Errorf("mirrored metadata x-test = %v", got)
+		}
+		if got := md.Get("x-mirrored-by"); len(got) != 1 || got[0] != "echo-grpc" {
+			t.Errorf("mirrored metadata x-mirrored-by = %v", got)
+		}
+		if msg := <-shadow.msgs; msg != "hello" {
+			t.Errorf("mirrored message = %q", msg)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("call not mirrored")
+	}
+}
@@ -0,0 +1,220 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// maxMockDepth bounds the nesting of the default messages, which may be
+// recursive
+const maxMockDepth = 3
+
+// RegisterMocks registers the services described by the FileDescriptorSet
+// of path (protoc --descriptor_set_out --include_imports) on s, answering
+// every method with its default message. Services already registered on s
+// are skipped, and the files are added to the global registry so reflection
+// describes the mocked services. It returns the names of the registered
+// services.
+func RegisterMocks(s *grpc.Server, path string) ([]string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(b, &set); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	files, err := protodesc.NewFiles(&set)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	registered := s.GetServiceInfo()
+	var names []string
+	files.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		if _, err := protoregistry.GlobalFiles.FindFileByPath(fd.Path()); errors.Is(err, protoregistry.NotFound) {
+			if err := protoregistry.GlobalFiles.RegisterFile(fd); err != nil {
+				log.Printf("Mocked file %s is not described by reflection: %v", fd.Path(), err)
+			}
+		}
+		services := fd.Services()
+		for i := 0; i < services.Len(); i++ {
+			sd := services.Get(i)
+			if _, ok := registered[string(sd.FullName())]; ok {
+				continue
+			}
+			s.RegisterService(mockServiceDesc(sd), struct{}{})
+			names = append(names, string(sd.FullName()))
+		}
+		return true
+	})
+	return names, nil
+}
+
+// mockServiceDesc returns the description of a service answering the
+// methods of sd with their default message
+func mockServiceDesc(sd protoreflect.ServiceDescriptor) *grpc.ServiceDesc {
+	desc := &grpc.ServiceDesc{
+		ServiceName: string(sd.FullName()),
+		HandlerType: (*any)(nil),
+		Metadata:    sd.ParentFile().Path(),
+	}
+	methods := sd.Methods()
+	for i := 0; i < methods.Len(); i++ {
+		md := methods.Get(i)
+		if !md.IsStreamingClient() && !md.IsStreamingServer() {
+			desc.Methods = append(desc.Methods, grpc.MethodDesc{
+				MethodName: string(md.Name()),
+				Handler:    mockUnaryHandler(desc.ServiceName, md),
+			})
+			continue
+		}
+		desc.Streams = append(desc.Streams, grpc.StreamDesc{
+			StreamName:    string(md.Name()),
+			Handler:       mockStreamHandler(md),
+			ServerStreams: md.IsStreamingServer(),
+			ClientStreams: md.IsStreamingClient(),
+		})
+	}
+	return desc
+}
+
+// mockUnaryHandler answers md with its default message, through the
+// interceptors of the server
+func mockUnaryHandler(service string, md protoreflect.MethodDescriptor) func(any, context.Context, func(any) error, grpc.UnaryServerInterceptor) (any, error) {
+	fullMethod := "/" + service + "/" + string(md.Name())
+	return func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+		in := dynamicpb.NewMessage(md.Input())
+		if err := dec(in); err != nil {
+			return nil, err
+		}
+		handler := func(ctx context.Context, req any) (any, error) {
+			return DefaultMessage(md.Output()), nil
+		}
+		if interceptor == nil {
+			return handler(ctx, in)
+		}
+		return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: fullMethod}, handler)
+	}
+}
+
+// mockStreamHandler reads the requests of md and answers with one default
+// message, after every request for bidirectional streaming and after the
+// last one for client streaming
+func mockStreamHandler(md protoreflect.MethodDescriptor) grpc.StreamHandler {
+	return func(srv any, stream grpc.ServerStream) error {
+		for {
+			in := dynamicpb.NewMessage(md.Input())
+			err := stream.RecvMsg(in)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			if md.IsStreamingClient() && md.IsStreamingServer() {
+				if err := stream.SendMsg(DefaultMessage(md.Output())); err != nil {
+					return err
+				}
+			}
+			if !md.IsStreamingClient() {
+				break
+			}
+		}
+		if md.IsStreamingClient() && md.IsStreamingServer() {
+			return nil
+		}
+		return stream.SendMsg(DefaultMessage(md.Output()))
+	}
+}
+
+// DefaultMessage returns a message of md with every field set to a sample
+// value: strings to the field name, numbers to 1, booleans to true, enums
+// to their first non-zero value, one element per list and map, and nested
+// messages up to a few levels. Only the first field of a oneof is set.
+func DefaultMessage(md protoreflect.MessageDescriptor) *dynamicpb.Message {
+	m := dynamicpb.NewMessage(md)
+	fillMessage(m, 0)
+	return m
+}
+
+func fillMessage(m protoreflect.Message, depth int) {
+	fields := m.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if oneof := fd.ContainingOneof(); oneof != nil && !oneof.IsSynthetic() && oneof.Fields().Get(0) != fd {
+			continue
+		}
+		switch {
+		case fd.IsMap():
+			entries := m.Mutable(fd).Map()
+			if v, ok := sampleValue(entries.NewValue, fd.MapValue(), depth); ok {
+				entries.Set(scalarValue(fd.MapKey()).MapKey(), v)
+			}
+		case fd.IsList():
+			list := m.Mutable(fd).List()
+			if v, ok := sampleValue(list.NewElement, fd, depth); ok {
+				list.Append(v)
+			}
+		default:
+			if v, ok := sampleValue(func() protoreflect.Value { return m.NewField(fd) }, fd, depth); ok {
+				m.Set(fd, v)
+			}
+		}
+	}
+}
+
+// sampleValue returns the sample value of fd, a message created by
+// newValue and filled, or false beyond the nesting limit
+func sampleValue(newValue func() protoreflect.Value, fd protoreflect.FieldDescriptor, depth int) (protoreflect.Value, bool) {
+	if fd.Kind() != protoreflect.MessageKind && fd.Kind() != protoreflect.GroupKind {
+		return scalarValue(fd), true
+	}
+	if depth >= maxMockDepth {
+		return protoreflect.Value{}, false
+	}
+	v := newValue()
+	fillMessage(v.Message(), depth+1)
+	return v, true
+}
+
+// scalarValue returns the sample value of a scalar field
+func scalarValue(fd protoreflect.FieldDescriptor) protoreflect.Value {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return protoreflect.ValueOfBool(true)
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return protoreflect.ValueOfInt32(1)
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return protoreflect.ValueOfInt64(1)
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return protoreflect.ValueOfUint32(1)
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return protoreflect.ValueOfUint64(1)
+	case protoreflect.FloatKind:
+		return protoreflect.ValueOfFloat32(1)
+	case protoreflect.DoubleKind:
+		return protoreflect.ValueOfFloat64(1)
+	case protoreflect.BytesKind:
+		return protoreflect.ValueOfBytes([]byte(fd.Name()))
+	case protoreflect.EnumKind:
+		values := fd.Enum().Values()
+		if values.Len() > 1 {
+			return protoreflect.ValueOfEnum(values.Get(1).Number())
+		}
+		return protoreflect.ValueOfEnum(values.Get(0).Number())
+	}
+	return protoreflect.ValueOfString(string(fd.Name()))
+}
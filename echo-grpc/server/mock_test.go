@@ -0,0 +1,190 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	pb "github.com/probitas-test/echo-servers/proto/echo/v1"
+)
+
+// mockFile describes mock.v1.Pets, a service of every streaming kind
+var mockFile = &descriptorpb.FileDescriptorProto{
+	Name:    proto.String("mock/v1/pets.proto"),
+	Package: proto.String("mock.v1"),
+	Syntax:  proto.String("proto3"),
+	MessageType: []*descriptorpb.DescriptorProto{
+		{
+			Name: proto.String("GetPetRequest"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("id", 1, descriptorpb.FieldDescriptorProto_TYPE_INT64, ""),
+			},
+		},
+		{
+			Name: proto.String("Pet"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("id", 1, descriptorpb.FieldDescriptorProto_TYPE_INT64, ""),
+				field("name", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+				field("kind", 3, descriptorpb.FieldDescriptorProto_TYPE_ENUM, ".mock.v1.Kind"),
+				field("parent", 4, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".mock.v1.Pet"),
+				repeated(field("tags", 5, descriptorpb.FieldDescriptorProto_TYPE_STRING, "")),
+			},
+		},
+	},
+	EnumType: []*descriptorpb.EnumDescriptorProto{{
+		Name: proto.String("Kind"),
+		Value: []*descriptorpb.EnumValueDescriptorProto{
+			{Name: proto.String("KIND_UNSPECIFIED"), Number: proto.Int32(0)},
+			{Name: proto.String("KIND_DOG"), Number: proto.Int32(1)},
+		},
+	}},
+	Service: []*descriptorpb.ServiceDescriptorProto{{
+		Name: proto.String("Pets"),
+		Method: []*descriptorpb.MethodDescriptorProto{
+			{Name: proto.String("GetPet"), InputType: proto.String(".mock.v1.GetPetRequest"), OutputType: proto.String(".mock.v1.Pet")},
+			{Name: proto.String("ListPets"), InputType: proto.String(".mock.v1.GetPetRequest"), OutputType: proto.String(".mock.v1.Pet"), ServerStreaming: proto.Bool(true)},
+			{Name: proto.String("Chat"), InputType: proto.String(".mock.v1.GetPetRequest"), OutputType: proto.String(".mock.v1.Pet"), ClientStreaming: proto.Bool(true), ServerStreaming: proto.Bool(true)},
+		},
+	}},
+}
+
+func field(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type, typeName string) *descriptorpb.FieldDescriptorProto {
+	f := &descriptorpb.FieldDescriptorProto{
+		Name:     proto.String(name),
+		JsonName: proto.String(name),
+		Number:   proto.Int32(number),
+		Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		Type:     typ.Enum(),
+	}
+	if typeName != "" {
+		f.TypeName = proto.String(typeName)
+	}
+	return f
+}
+
+func repeated(f *descriptorpb.FieldDescriptorProto) *descriptorpb.FieldDescriptorProto {
+	f.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	return f
+}
+
+func TestRegisterMocks(t *testing.T) {
+	b, err := proto.Marshal(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{mockFile}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "pets.pb")
+	if err := os.WriteFile(path, b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	lis := bufconn.Listen(1024 * 1024)
+	s := grpc.NewServer()
+	pb.RegisterEchoServer(s, NewEchoServer())
+	mocked, err := RegisterMocks(s, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mocked) != 1 || mocked[0] != "mock.v1.Pets" {
+		t.Fatalf("mocked services = %v", mocked)
+	}
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough://bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	fd, err := protodesc.NewFile(mockFile, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := dynamicpb.NewMessage(fd.Messages().ByName("GetPetRequest"))
+	petDesc := fd.Messages().ByName("Pet")
+
+	pet := dynamicpb.NewMessage(petDesc)
+	if err := conn.Invoke(context.Background(), "/mock.v1.Pets/GetPet", request, pet); err != nil {
+		t.Fatalf("GetPet: %v", err)
+	}
+	if got := protojson.Format(pet); !proto.Equal(pet, DefaultMessage(petDesc)) {
+		t.Errorf("GetPet = %s, want the default message", got)
+	}
+	if got := pet.Get(petDesc.Fields().ByName("name")).String(); got != "name" {
+		t.Errorf("name = %q, want the field name", got)
+	}
+	if got := pet.Get(petDesc.Fields().ByName("kind")).Enum(); got != 1 {
+		t.Errorf("kind = %d, want the first non-zero value", got)
+	}
+
+	stream, err := conn.NewStream(context.Background(), &grpc.StreamDesc{ClientStreams: true, ServerStreams: true}, "/mock.v1.Pets/Chat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		if err := stream.SendMsg(request); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := stream.CloseSend(); err != nil {
+		t.Fatal(err)
+	}
+	replies := 0
+	for {
+		if err := stream.RecvMsg(dynamicpb.NewMessage(petDesc)); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("Chat: %v", err)
+		}
+		replies++
+	}
+	if replies != 2 {
+		t.Errorf("Chat replies = %d, want one per request", replies)
+	}
+
+	err = conn.Invoke(context.Background(), "/mock.v1.Pets/Missing", request, pet)
+	if code := status.Code(err); code != codes.Unimplemented {
+		t.Errorf("undescribed method = %v, want Unimplemented", err)
+	}
+
+	svr := newReflectionServer(s, false)
+	if _, ok := svr.services["mock.v1.Pets"]; !ok {
+		t.Error("mocked service not listed by reflection")
+	}
+	if _, err := svr.desc.FindDescriptorByName(protoreflect.FullName("mock.v1.Pets")); err != nil {
+		t.Errorf("mocked service not described by reflection: %v", err)
+	}
+}
+
+func TestRegisterMocks_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "invalid.pb")
+	if err := os.WriteFile(path, []byte("not a descriptor set"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RegisterMocks(grpc.NewServer(), path); err == nil {
+		t.Error("invalid descriptor set accepted")
+	}
+	if _, err := RegisterMocks(grpc.NewServer(), filepath.Join(t.TempDir(), "missing.pb")); err == nil {
+		t.Error("missing file accepted")
+	}
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/orca"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/probitas-test/echo-servers/proto/echo/v1"
+)
+
+// loadReporter is a request carrying the ORCA load report of its call
+type loadReporter interface {
+	GetLoadReport() *pb.LoadReport
+}
+
+// OrcaServerOptions returns server options sending the load_report field of
+// the request as an ORCA per-call load report, in the
+// endpoint-load-metrics-bin trailer, so custom load balancing policies and
+// backend metric consumers can be exercised. Streaming RPCs report the
+// load_report of their first request message. Calls without a load report
+// get no trailer; out of range values are rejected with INVALID_ARGUMENT.
+func OrcaServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		orca.CallMetricsServerOption(nil),
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if err := recordLoad(ctx, req); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			return handler(srv, &orcaStream{ServerStream: ss})
+		}),
+	}
+}
+
+// orcaStream records the load report of the first request message
+type orcaStream struct {
+	grpc.ServerStream
+	received bool
+}
+
+func (s *orcaStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if s.received {
+		return nil
+	}
+	s.received = true
+	return recordLoad(s.Context(), m)
+}
+
+// recordLoad records the load report of req, if any, for the trailer
+func recordLoad(ctx context.Context, req any) error {
+	r, ok := req.(loadReporter)
+	if !ok || r.GetLoadReport() == nil {
+		return nil
+	}
+	report := r.GetLoadReport()
+	if err := validateLoadReport(report); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	rec := orca.CallMetricsRecorderFromContext(ctx)
+	if rec == nil {
+		return nil
+	}
+	rec.SetCPUUtilization(report.CpuUtilization)
+	rec.SetMemoryUtilization(report.MemUtilization)
+	rec.SetApplicationUtilization(report.ApplicationUtilization)
+	rec.SetQPS(report.RpsFractional)
+	rec.SetEPS(report.Eps)
+	for name, value := range report.RequestCost {
+		rec.SetRequestCost(name, value)
+	}
+	for name, value := range report.Utilization {
+		rec.SetNamedUtilization(name, value)
+	}
+	for name, value := range report.NamedMetrics {
+		rec.SetNamedMetric(name, value)
+	}
+	return nil
+}
+
+// validateLoadReport reports the first value out of the ORCA ranges, which
+// the recorder would silently ignore
+func validateLoadReport(r *pb.LoadReport) error {
+	switch {
+	case r.CpuUtilization < 0:
+		return fmt.Errorf("load_report.cpu_utilization %g is negative", r.CpuUtilization)
+	case r.MemUtilization < 0 || r.MemUtilization > 1:
+		return fmt.Errorf("load_report.mem_utilization %g is not between 0 and 1", r.MemUtilization)
+	case r.ApplicationUtilization < 0:
+		return fmt.Errorf("load_report.application_utilization %g is negative", r.ApplicationUtilization)
+	case r.RpsFractional < 0:
+		return fmt.Errorf("load_report.rps_fractional %g is negative", r.RpsFractional)
+	case r.Eps < 0:
+		return fmt.Errorf("load_report.eps %g is negative", r.Eps)
+	}
+	for name, value := range r.Utilization {
+		if value < 0 || value > 1 {
+			return fmt.Errorf("load_report.utilization[%q] %g is not between 0 and 1", name, value)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	v3orcapb "github.com/cncf/xds/go/xds/data/orca/v3"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/probitas-test/echo-servers/proto/echo/v1"
+)
+
+const orcaTrailer = "endpoint-load-metrics-bin"
+
+func TestOrcaServerOptions(t *testing.T) {
+	s := grpc.NewServer(OrcaServerOptions()...)
+	pb.RegisterEchoServer(s, NewEchoServer())
+	conn, err := grpc.NewClient(listen(t, s), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	client := pb.NewEchoClient(conn)
+
+	var trailer metadata.MD
+	_, err = client.Echo(context.Background(), &pb.EchoRequest{
+		Message: "hello",
+		LoadReport: &pb.LoadReport{
+			CpuUtilization: 0.5,
+			MemUtilization: 0.25,
+			RpsFractional:  10,
+			RequestCost:    map[string]float64{"db": 3},
+			Utilization:    map[string]float64{"gpu": 0.75},
+		},
+	}, grpc.Trailer(&trailer))
+	if err != nil {
+		t.Fatalf("Echo failed: %v", err)
+	}
+	values := trailer.Get(orcaTrailer)
+	if len(values) != 1 {
+		t.Fatalf("trailer %s = %v", orcaTrailer, values)
+	}
+	var report v3orcapb.OrcaLoadReport
+	if err := proto.Unmarshal([]byte(values[0]), &report); err != nil {
+		t.Fatal(err)
+	}
+	want := &v3orcapb.OrcaLoadReport{
+		CpuUtilization: 0.5,
+		MemUtilization: 0.25,
+		RpsFractional:  10,
+		RequestCost:    map[string]float64{"db": 3},
+		Utilization:    map[string]float64{"gpu": 0.75},
+	}
+	if !proto.Equal(&report, want) {
+		t.Errorf("load report = %v, want %v", &report, want)
+	}
+
+	// No load report, no trailer
+	trailer = nil
+	if _, err := client.Echo(context.Background(), &pb.EchoRequest{Message: "hello"}, grpc.Trailer(&trailer)); err != nil {
+		t.Fatal(err)
+	}
+	if values := trailer.Get(orcaTrailer); len(values) != 0 {
+		t.Errorf("trailer without load report = %v", values)
+	}
+
+	_, err = client.Echo(context.Background(), &pb.EchoRequest{LoadReport: &pb.LoadReport{MemUtilization: 2}})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("out of range memory utilization: %v", err)
+	}
+}
+
+func TestOrcaServerOptions_Stream(t *testing.T) {
+	s := grpc.NewServer(OrcaServerOptions()...)
+	pb.RegisterEchoServer(s, NewEchoServer())
+	conn, err := grpc.NewClient(listen(t, s), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	stream, err := pb.NewEchoClient(conn).ServerStream(context.Background(), &pb.ServerStreamRequest{
+		Message:    "ping",
+		Count:      2,
+		LoadReport: &pb.LoadReport{NamedMetrics: map[string]float64{"queue": 7}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for {
+		if _, err := stream.Recv(); err != nil {
+			break
+		}
+	}
+	var report v3orcapb.OrcaLoadReport
+	values := stream.Trailer().Get(orcaTrailer)
+	if len(values) != 1 || proto.Unmarshal([]byte(values[0]), &report) != nil || report.NamedMetrics["queue"] != 7 {
+		t.Errorf("stream trailer %s = %v", orcaTrailer, values)
+	}
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/probitas-test/echo-servers/shared/ratelimit"
+)
+
+// RateLimitServerOptions returns server options throttling every RPC but
+// the health checks with l, matching routes against the full method and
+// counting per peer address or key metadata. The quota is reported in the
+// ratelimit-* header metadata; denied RPCs fail with ResourceExhausted and
+// a RetryInfo detail.
+func RateLimitServerOptions(l *ratelimit.Limiter) []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			done, err := rateLimit(ctx, l, info.FullMethod)
+			if err != nil {
+				return nil, err
+			}
+			defer done()
+			return handler(ctx, req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			done, err := rateLimit(ss.Context(), l, info.FullMethod)
+			if err != nil {
+				return err
+			}
+			defer done()
+			return handler(srv, ss)
+		}),
+	}
+}
+
+// rateLimit takes one RPC of method from l, returning the function to call
+// when it completes or the error of a denied RPC
+func rateLimit(ctx context.Context, l *ratelimit.Limiter, method string) (func(), error) {
+	if strings.HasPrefix(method, "/"+healthpb.Health_ServiceDesc.ServiceName+"/") {
+		return func() {}, nil
+	}
+	var addr, key string
+	if p, ok := peer.FromContext(ctx); ok {
+		addr = p.Addr.String()
+	}
+	if values := metadata.ValueFromIncomingContext(ctx, l.Config().KeyHeader); len(values) > 0 {
+		key = values[0]
+	}
+
+	d := l.Take(addr, key, method)
+	if h := d.Header(); len(h) > 0 {
+		md := metadata.MD{}
+		for name, values := range h {
+			md.Set(strings.ToLower(name), values...)
+		}
+		_ = grpc.SetHeader(ctx, md)
+	}
+	if !d.Allowed {
+		st := status.New(codes.ResourceExhausted, "rate limit exceeded")
+		if detailed, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(d.RetryAfter)}); err == nil {
+			st = detailed
+		}
+		return nil, st.Err()
+	}
+	return d.Done, nil
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/probitas-test/echo-servers/proto/echo/v1"
+	"github.com/probitas-test/echo-servers/shared/ratelimit"
+)
+
+func TestRateLimitServerOptions(t *testing.T) {
+	cfg := ratelimit.DefaultConfig()
+	cfg.Enabled, cfg.Limit, cfg.WindowMS, cfg.Scope = true, 1, 60000, ratelimit.ScopeKey
+	limiter, err := ratelimit.New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer(RateLimitServerOptions(limiter)...)
+	pb.RegisterEchoServer(s, NewEchoServer())
+	healthpb.RegisterHealthServer(s, NewHealthServer())
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	client := pb.NewEchoClient(conn)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "a")
+
+	var header metadata.MD
+	if _, err := client.Echo(ctx, &pb.EchoRequest{Message: "hello"}, grpc.Header(&header)); err != nil {
+		t.Fatalf("Echo failed: %v", err)
+	}
+	if got := header.Get("ratelimit-remaining"); len(got) != 1 || got[0] != "0" {
+		t.Errorf("ratelimit-remaining = %v, want 0", got)
+	}
+
+	_, err = client.Echo(ctx, &pb.EchoRequest{Message: "hello"}, grpc.Header(&header))
+	st := status.Convert(err)
+	if st.Code() != codes.ResourceExhausted || len(header.Get("retry-after")) != 1 {
+		t.Fatalf("Echo over the limit = %v, header %v, want ResourceExhausted", err, header)
+	}
+	if details := st.Details(); len(details) != 1 || details[0].(*errdetails.RetryInfo).GetRetryDelay().GetSeconds() < 59 {
+		t.Errorf("details = %v, want a RetryInfo of about 60s", details)
+	}
+
+	// Other keys and health checks are not limited
+	other := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "b")
+	if _, err := client.Echo(other, &pb.EchoRequest{Message: "hello"}); err != nil {
+		t.Errorf("Echo with another key failed: %v", err)
+	}
+	for range 2 {
+		if _, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{}); err != nil {
+			t.Errorf("health check failed: %v", err)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/probitas-test/echo-servers/shared/recording"
+)
+
+// RecordingServerOptions returns server options recording every RPC with r
+// while recording is enabled: the request metadata, the serialized
+// messages in both directions and the final status.
+func RecordingServerOptions(r *recording.Recorder) []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			c := startCall(ctx, r, info.FullMethod)
+			recordFrame(c, recording.Received, req)
+			resp, err := handler(ctx, req)
+			if err == nil {
+				recordFrame(c, recording.Sent, resp)
+			}
+			endCall(ctx, c, err)
+			return resp, err
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			c := startCall(ss.Context(), r, info.FullMethod)
+			if c == nil {
+				return handler(srv, ss)
+			}
+			err := handler(srv, &recordingStream{ServerStream: ss, call: c})
+			endCall(ss.Context(), c, err)
+			return err
+		}),
+	}
+}
+
+func startCall(ctx context.Context, r *recording.Recorder, method string) *recording.Call {
+	var addr string
+	if p, ok := peer.FromContext(ctx); ok {
+		addr = p.Addr.String()
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	return r.Start(method, addr, md)
+}
+
+func endCall(ctx context.Context, c *recording.Call, err error) {
+	st := status.Convert(err)
+	c.End(ctx, st.Code().String(), st.Message())
+}
+
+// recordFrame records the serialized message msg, if it is a protobuf one
+func recordFrame(c *recording.Call, dir recording.Direction, msg any) {
+	if c == nil {
+		return
+	}
+	if m, ok := msg.(proto.Message); ok {
+		if b, err := proto.Marshal(m); err == nil {
+			c.Frame(dir, b)
+		}
+	}
+}
+
+// recordingStream records the messages received and sent on a stream
+type recordingStream struct {
+	grpc.ServerStream
+	call *recording.Call
+}
+
+func (s *recordingStream) RecvMsg(m any) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		recordFrame(s.call, recording.Received, m)
+	}
+	return err
+}
+
+func (s *recordingStream) SendMsg(m any) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		recordFrame(s.call, recording.Sent, m)
+	}
+	return err
+}
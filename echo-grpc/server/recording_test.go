@@ -0,0 +1,87 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/probitas-test/echo-servers/proto/echo/v1"
+	"github.com/probitas-test/echo-servers/shared/recording"
+)
+
+func TestRecordingServerOptions(t *testing.T) {
+	dir := t.TempDir()
+	r, err := recording.New("echo-grpc", recording.Config{Enabled: true, Dir: dir, MaxBodyBytes: 1 << 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer(RecordingServerOptions(r)...)
+	pb.RegisterEchoServer(s, NewEchoServer())
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	client := pb.NewEchoClient(conn)
+
+	if _, err := client.Echo(context.Background(), &pb.EchoRequest{Message: "hello"}); err != nil {
+		t.Fatalf("Echo failed: %v", err)
+	}
+	stream, err := client.ServerStream(context.Background(), &pb.ServerStreamRequest{Message: "hi", Count: 2})
+	if err != nil {
+		t.Fatalf("ServerStream failed: %v", err)
+	}
+	for {
+		if _, err := stream.Recv(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("Recv failed: %v", err)
+		}
+	}
+	s.GracefulStop()
+
+	data, err := os.ReadFile(filepath.Join(dir, "echo-grpc.grpc.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rpcs []recording.RPC
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var rpc recording.RPC
+		if err := json.Unmarshal([]byte(line), &rpc); err != nil {
+			t.Fatal(err)
+		}
+		rpcs = append(rpcs, rpc)
+	}
+	if len(rpcs) != 2 {
+		t.Fatalf("%d RPCs recorded, want 2", len(rpcs))
+	}
+
+	echo := rpcs[0]
+	if echo.Method != "/echo.v1.Echo/Echo" || echo.Code != "OK" || len(echo.Frames) != 2 {
+		t.Fatalf("Echo recording = %+v", echo)
+	}
+	var req pb.EchoRequest
+	if err := proto.Unmarshal(echo.Frames[0].Data[5:], &req); err != nil || req.GetMessage() != "hello" {
+		t.Errorf("request frame = %v, %v", &req, err)
+	}
+	if stream := rpcs[1]; len(stream.Frames) != 3 || stream.Frames[2].Direction != recording.Sent {
+		t.Errorf("ServerStream recording = %+v, want 1 received and 2 sent frames", stream)
+	}
+}
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/probitas-test/echo-servers/shared/chaos"
+	"github.com/probitas-test/echo-servers/shared/script"
+)
+
+// ScriptServerOptions returns server options playing the scenarios of e on
+// every RPC but the health checks, matching them against the full method
+// and identifying clients by metadata or peer address. The step applied is
+// reported in the x-script-step header metadata; failing steps return the
+// gRPC code of their HTTP status.
+func ScriptServerOptions(e *script.Engine) []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if err := playScript(ctx, e, info.FullMethod); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := playScript(ss.Context(), e, info.FullMethod); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		}),
+	}
+}
+
+// playScript counts one RPC of method with e, waits for the delay of its
+// step and returns the error of a failing step
+func playScript(ctx context.Context, e *script.Engine, method string) error {
+	if strings.HasPrefix(method, "/"+healthpb.Health_ServiceDesc.ServiceName+"/") {
+		return nil
+	}
+	var addr, id string
+	if p, ok := peer.FromContext(ctx); ok {
+		addr = p.Addr.String()
+	}
+	if values := metadata.ValueFromIncomingContext(ctx, e.ClientHeader()); len(values) > 0 {
+		id = values[0]
+	}
+
+	a := e.Next(script.Client(addr, id), method)
+	if !a.Matched() {
+		return nil
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs(strings.ToLower(script.Header), a.Label()))
+	if err := a.Wait(ctx); err != nil {
+		return status.FromContextError(err).Err()
+	}
+	if a.Status == 0 {
+		return nil
+	}
+	msg := a.Body
+	if msg == "" {
+		msg = http.StatusText(a.Status)
+	}
+	return status.Error(codes.Code(chaos.Code(a.Status)), msg)
+}
@@ -0,0 +1,70 @@
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/probitas-test/echo-servers/proto/echo/v1"
+	"github.com/probitas-test/echo-servers/shared/script"
+)
+
+func TestScriptServerOptions(t *testing.T) {
+	engine, err := script.New(script.Config{ClientHeader: script.DefaultClientHeader})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = engine.SetScript(script.Script{Scenarios: []script.Scenario{{
+		Name:  "flaky",
+		Match: []string{"/echo.v1.Echo/*"},
+		Steps: []script.Step{{Status: 429}, {Status: 500, Body: "boom"}, {}},
+	}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer(ScriptServerOptions(engine)...)
+	pb.RegisterEchoServer(s, NewEchoServer())
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	client := pb.NewEchoClient(conn)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-client-id", "ci")
+
+	tests := []struct {
+		code codes.Code
+		msg  string
+		step string
+	}{
+		{codes.ResourceExhausted, "Too Many Requests", "flaky#1"},
+		{codes.Internal, "boom", "flaky#2"},
+		{codes.OK, "", "flaky#3"},
+		{codes.OK, "", "flaky#3"},
+	}
+	for i, tt := range tests {
+		var header metadata.MD
+		_, err := client.Echo(ctx, &pb.EchoRequest{Message: "hello"}, grpc.Header(&header))
+		st := status.Convert(err)
+		if st.Code() != tt.code || (tt.msg != "" && st.Message() != tt.msg) {
+			t.Errorf("Echo %d = %v, want %v %q", i, err, tt.code, tt.msg)
+		}
+		if got := header.Get("x-script-step"); len(got) != 1 || got[0] != tt.step {
+			t.Errorf("Echo %d x-script-step = %v, want %s", i, got, tt.step)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package server
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	pb "github.com/probitas-test/echo-servers/proto/echo/v1"
+)
+
+// EchoMap echoes maps with their keys in order, so clients check the
+// encoding of unusual keys and of entries with default values
+func (s *EchoServer) EchoMap(_ context.Context, req *pb.MapRequest) (*pb.MapResponse, error) {
+	resp := &pb.MapResponse{
+		Request:       req,
+		UnknownFields: unknownFields(req),
+	}
+	m := req.ProtoReflect()
+	fields := m.Descriptor().Fields()
+	for i := range fields.Len() {
+		fd := fields.Get(i)
+		if !fd.IsMap() {
+			continue
+		}
+		entries := m.Get(fd).Map()
+		keys := make([]protoreflect.MapKey, 0, entries.Len())
+		entries.Range(func(k protoreflect.MapKey, _ protoreflect.Value) bool {
+			keys = append(keys, k)
+			return true
+		})
+		slices.SortFunc(keys, compareMapKeys)
+		for _, k := range keys {
+			resp.Keys = append(resp.Keys, &pb.MapKey{
+				Field:        string(fd.Name()),
+				Key:          fmt.Sprint(k.Interface()),
+				DefaultValue: isDefault(entries.Get(k)),
+			})
+		}
+	}
+	return resp, nil
+}
+
+// EchoRepeated echoes repeated fields with their element counts, so
+// clients check the encoding of empty fields and default elements
+func (s *EchoServer) EchoRepeated(_ context.Context, req *pb.RepeatedRequest) (*pb.RepeatedResponse, error) {
+	resp := &pb.RepeatedResponse{
+		Request:       req,
+		UnknownFields: unknownFields(req),
+	}
+	m := req.ProtoReflect()
+	fields := m.Descriptor().Fields()
+	for i := range fields.Len() {
+		fd := fields.Get(i)
+		if !fd.IsList() {
+			continue
+		}
+		list := m.Get(fd).List()
+		field := &pb.RepeatedField{Field: string(fd.Name()), Count: int32(list.Len())}
+		for j := range list.Len() {
+			if isDefault(list.Get(j)) {
+				field.Defaults++
+			}
+		}
+		resp.Fields = append(resp.Fields, field)
+	}
+	return resp, nil
+}
+
+// EchoScalars echoes scalars with the fields present, so clients check
+// that proto3 optional fields keep their presence when set to defaults
+func (s *EchoServer) EchoScalars(_ context.Context, req *pb.ScalarsRequest) (*pb.ScalarsResponse, error) {
+	resp := &pb.ScalarsResponse{
+		Request:       req,
+		Present:       []string{},
+		UnknownFields: unknownFields(req),
+	}
+	m := req.ProtoReflect()
+	fields := m.Descriptor().Fields()
+	for i := range fields.Len() {
+		if fd := fields.Get(i); m.Has(fd) {
+			resp.Present = append(resp.Present, string(fd.Name()))
+		}
+	}
+	return resp, nil
+}
+
+// unknownFields returns the numbers of the fields of msg its schema does
+// not know, in the order received
+func unknownFields(msg proto.Message) []int32 {
+	numbers := []int32{}
+	b := msg.ProtoReflect().GetUnknown()
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			break
+		}
+		m := protowire.ConsumeFieldValue(num, typ, b[n:])
+		if m < 0 {
+			break
+		}
+		if !slices.Contains(numbers, int32(num)) {
+			numbers = append(numbers, int32(num))
+		}
+		b = b[n+m:]
+	}
+	return numbers
+}
+
+// isDefault reports whether v is a proto3 default value: zero, empty or a
+// message without fields
+func isDefault(v protoreflect.Value) bool {
+	switch x := v.Interface().(type) {
+	case bool:
+		return !x
+	case int32:
+		return x == 0
+	case int64:
+		return x == 0
+	case uint32:
+		return x == 0
+	case uint64:
+		return x == 0
+	case float32:
+		return x == 0
+	case float64:
+		return x == 0
+	case string:
+		return x == ""
+	case []byte:
+		return len(x) == 0
+	case protoreflect.EnumNumber:
+		return x == 0
+	case protoreflect.Message:
+		return proto.Size(x.Interface()) == 0
+	}
+	return false
+}
+
+// compareMapKeys orders map keys of the same kind: false before true,
+// integers by value and strings byte by byte
+func compareMapKeys(a, b protoreflect.MapKey) int {
+	switch av := a.Interface().(type) {
+	case bool:
+		bv := b.Bool()
+		if av == bv {
+			return 0
+		} else if !av {
+			return -1
+		}
+		return 1
+	case int32, int64:
+		return cmp.Compare(a.Int(), b.Int())
+	case uint32, uint64:
+		return cmp.Compare(a.Uint(), b.Uint())
+	default:
+		return cmp.Compare(a.String(), b.String())
+	}
+}
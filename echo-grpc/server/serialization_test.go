@@ -0,0 +1,142 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"slices"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/probitas-test/echo-servers/proto/echo/v1"
+)
+
+func TestEchoMap_ReportsKeysInOrder(t *testing.T) {
+	client, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	req := &pb.MapRequest{
+		StringKeys:    map[string]string{"": "empty", "é": "accent", "b": ""},
+		Int32Keys:     map[int32]string{math.MinInt32: "min", 0: "zero", -1: "minus one"},
+		Uint64Keys:    map[uint64]string{math.MaxUint64: "max"},
+		BoolKeys:      map[bool]string{true: "yes", false: "no"},
+		MessageValues: map[string]*pb.MapValue{"empty": {}, "set": {Number: 1}},
+	}
+	resp, err := client.EchoMap(context.Background(), req)
+	if err != nil {
+		t.Fatalf("EchoMap failed: %v", err)
+	}
+	if !proto.Equal(resp.Request, req) {
+		t.Errorf("request not echoed unchanged: %v", resp.Request)
+	}
+
+	var got []string
+	var defaults []string
+	for _, k := range resp.Keys {
+		got = append(got, k.Field+":"+k.Key)
+		if k.DefaultValue {
+			defaults = append(defaults, k.Field+":"+k.Key)
+		}
+	}
+	want := []string{
+		"string_keys:", "string_keys:b", "string_keys:é",
+		"int32_keys:-2147483648", "int32_keys:-1", "int32_keys:0",
+		"uint64_keys:18446744073709551615",
+		"bool_keys:false", "bool_keys:true",
+		"message_values:empty", "message_values:set",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("expected keys %v, got %v", want, got)
+	}
+	if wantDefaults := []string{"string_keys:b", "message_values:empty"}; !slices.Equal(defaults, wantDefaults) {
+		t.Errorf("expected default values %v, got %v", wantDefaults, defaults)
+	}
+}
+
+func TestEchoRepeated_CountsElements(t *testing.T) {
+	client, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	req := &pb.RepeatedRequest{
+		Packed:   []int32{0, 1, 2},
+		Expanded: []int32{3, 0},
+		Doubles:  []float64{math.NaN(), math.Inf(-1)},
+		Strings:  []string{""},
+		Enums:    []pb.StructuredRequest_Priority{99},
+		Messages: []*pb.MapValue{{}, {Text: "x"}},
+	}
+	resp, err := client.EchoRepeated(context.Background(), req)
+	if err != nil {
+		t.Fatalf("EchoRepeated failed: %v", err)
+	}
+	if !proto.Equal(resp.Request, req) {
+		t.Errorf("request not echoed unchanged: %v", resp.Request)
+	}
+
+	want := map[string][2]int32{
+		"packed":   {3, 1},
+		"expanded": {2, 1},
+		"doubles":  {2, 0},
+		"strings":  {1, 1},
+		"blobs":    {0, 0},
+		"enums":    {1, 0},
+		"messages": {2, 1},
+	}
+	if len(resp.Fields) != len(want) {
+		t.Fatalf("expected %d fields, got %v", len(want), resp.Fields)
+	}
+	for _, f := range resp.Fields {
+		if got := [2]int32{f.Count, f.Defaults}; got != want[f.Field] {
+			t.Errorf("%s: expected count and defaults %v, got %v", f.Field, want[f.Field], got)
+		}
+	}
+}
+
+func TestEchoScalars_ReportsPresence(t *testing.T) {
+	client, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	resp, err := client.EchoScalars(context.Background(), &pb.ScalarsRequest{
+		Int32Value:     0,
+		StringValue:    "set",
+		OptionalInt32:  proto.Int32(0),
+		OptionalString: proto.String(""),
+		OptionalEnum:   pb.StructuredRequest_PRIORITY_UNSPECIFIED.Enum(),
+	})
+	if err != nil {
+		t.Fatalf("EchoScalars failed: %v", err)
+	}
+	want := []string{"string_value", "optional_int32", "optional_string", "optional_enum"}
+	if !slices.Equal(resp.Present, want) {
+		t.Errorf("expected present %v, got %v", want, resp.Present)
+	}
+	if resp.Request.OptionalInt32 == nil || resp.Request.OptionalBool != nil {
+		t.Errorf("presence not echoed: %v", resp.Request)
+	}
+}
+
+func TestEchoScalars_PreservesUnknownFields(t *testing.T) {
+	client, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	// Fields of a newer schema: a varint and a string
+	unknown := protowire.AppendTag(nil, 100, protowire.VarintType)
+	unknown = protowire.AppendVarint(unknown, 42)
+	unknown = protowire.AppendTag(unknown, 101, protowire.BytesType)
+	unknown = protowire.AppendString(unknown, "future")
+	req := &pb.ScalarsRequest{Int64Value: 7}
+	req.ProtoReflect().SetUnknown(unknown)
+
+	resp, err := client.EchoScalars(context.Background(), req)
+	if err != nil {
+		t.Fatalf("EchoScalars failed: %v", err)
+	}
+	if !slices.Equal(resp.UnknownFields, []int32{100, 101}) {
+		t.Errorf("expected unknown fields [100 101], got %v", resp.UnknownFields)
+	}
+	if got := resp.Request.ProtoReflect().GetUnknown(); !bytes.Equal(got, unknown) {
+		t.Errorf("unknown fields not echoed: %x", got)
+	}
+}
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	pb "github.com/probitas-test/echo-servers/proto/echo/v1"
+)
+
+func TestEchoStructured_EchoesRequest(t *testing.T) {
+	client, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	attributes, err := structpb.NewStruct(map[string]any{"nested": map[string]any{"ok": true}, "n": 1.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	createdAt := time.Now().Add(-time.Minute)
+	req := &pb.StructuredRequest{
+		Id:         "order-1",
+		CreatedAt:  timestamppb.New(createdAt),
+		Ttl:        durationpb.New(time.Hour),
+		Attributes: attributes,
+		Note:       wrapperspb.String("gift"),
+		Priority:   pb.StructuredRequest_PRIORITY_HIGH,
+		Labels:     map[string]string{"env": "test"},
+		ItemsByKey: map[string]*pb.StructuredRequest_Item{"spare": {Name: "spare"}},
+		Items: []*pb.StructuredRequest_Item{
+			{Name: "box", Quantity: 2, Children: []*pb.StructuredRequest_Item{{Name: "inner"}, {Name: "lid"}}},
+		},
+		Payload: &pb.StructuredRequest_Item_{Item: &pb.StructuredRequest_Item{Name: "payload"}},
+	}
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-test", "value")
+	resp, err := client.EchoStructured(ctx, req)
+	if err != nil {
+		t.Fatalf("EchoStructured failed: %v", err)
+	}
+	if !proto.Equal(resp.Request, req) {
+		t.Errorf("request not echoed unchanged: %v", resp.Request)
+	}
+	if resp.Payload != "item" {
+		t.Errorf("expected payload item, got %q", resp.Payload)
+	}
+	if resp.ItemCount != 5 {
+		t.Errorf("expected 5 items, got %d", resp.ItemCount)
+	}
+	if !resp.ExpiresAt.AsTime().Equal(createdAt.Add(time.Hour)) {
+		t.Errorf("expected expires_at %v, got %v", createdAt.Add(time.Hour), resp.ExpiresAt.AsTime())
+	}
+	if age := resp.Age.AsDuration(); age < time.Minute || age > 2*time.Minute {
+		t.Errorf("expected age of about a minute, got %v", age)
+	}
+	if resp.Metadata["x-test"] != "value" {
+		t.Errorf("expected metadata x-test, got %v", resp.Metadata)
+	}
+}
+
+func TestEchoStructured_WithoutTimestamps(t *testing.T) {
+	client, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	resp, err := client.EchoStructured(context.Background(), &pb.StructuredRequest{
+		Ttl:     durationpb.New(time.Hour),
+		Payload: &pb.StructuredRequest_Text{Text: "hello"},
+	})
+	if err != nil {
+		t.Fatalf("EchoStructured failed: %v", err)
+	}
+	if resp.Age != nil || resp.ExpiresAt != nil {
+		t.Errorf("expected no age or expires_at without created_at, got %v and %v", resp.Age, resp.ExpiresAt)
+	}
+	if resp.Payload != "text" || resp.ItemCount != 0 {
+		t.Errorf("expected text payload and no items, got %q and %d", resp.Payload, resp.ItemCount)
+	}
+	if resp.ReceivedAt == nil {
+		t.Error("expected received_at")
+	}
+}
+
+func TestEchoStructured_RejectsInvalidTimestamp(t *testing.T) {
+	client, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	_, err := client.EchoStructured(context.Background(), &pb.StructuredRequest{
+		CreatedAt: &timestamppb.Timestamp{Seconds: 1, Nanos: -1},
+	})
+	if code := status.Code(err); code != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument, got %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/probitas-test/echo-servers/shared/tracing"
+)
+
+// TracingServerOptions returns server options starting a server span per
+// RPC, parented to the trace context of the request metadata, and echoing
+// the trace context in the response header metadata.
+func TracingServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(tracingUnaryInterceptor),
+		grpc.ChainStreamInterceptor(tracingStreamInterceptor),
+	}
+}
+
+func tracingUnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, span, header := startRPCSpan(ctx, info.FullMethod)
+	defer span.End()
+	_ = grpc.SetHeader(ctx, header)
+
+	resp, err := handler(ctx, req)
+	endRPCSpan(span, err)
+	return resp, err
+}
+
+func tracingStreamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, span, header := startRPCSpan(ss.Context(), info.FullMethod)
+	defer span.End()
+	_ = ss.SetHeader(header)
+
+	err := handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	endRPCSpan(span, err)
+	return err
+}
+
+func startRPCSpan(ctx context.Context, fullMethod string) (context.Context, trace.Span, metadata.MD) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	in := metadataCarrier(md)
+	ctx, span := tracing.Start(ctx, in, fullMethod[1:], trace.SpanKindServer,
+		attribute.String("rpc.system", "grpc"),
+		attribute.String("rpc.method", fullMethod),
+	)
+	header := metadata.MD{}
+	tracing.Echo(ctx, in, metadataCarrier(header))
+	return ctx, span, header
+}
+
+func endRPCSpan(span trace.Span, err error) {
+	code := status.Code(err)
+	span.SetAttributes(attribute.Int("rpc.grpc.status_code", int(code)))
+	if err != nil {
+		span.SetStatus(codes.Error, code.String())
+	}
+}
+
+// contextStream carries a context derived by an interceptor, such as the
+// context of the RPC span, to the handler
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+// metadataCarrier adapts gRPC metadata to the OpenTelemetry propagators
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	if v := metadata.MD(c).Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
@@ -0,0 +1,104 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+
+	pb "github.com/probitas-test/echo-servers/proto/echo/v1"
+	"github.com/probitas-test/echo-servers/shared/tracing"
+)
+
+func TestTracingServerOptions(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	rec := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+
+	lis := bufconn.Listen(1024 * 1024)
+	s := grpc.NewServer(TracingServerOptions()...)
+	pb.RegisterEchoServer(s, NewEchoServer())
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough://bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	client := pb.NewEchoClient(conn)
+
+	traceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+	traceparent := "00-" + traceID + "-00f067aa0ba902b7-01"
+	ctx := metadata.AppendToOutgoingContext(context.Background(),
+		"traceparent", traceparent,
+		"baggage", "user=alice",
+	)
+
+	var header metadata.MD
+	if _, err := client.Echo(ctx, &pb.EchoRequest{Message: "hello"}, grpc.Header(&header)); err != nil {
+		t.Fatalf("Echo failed: %v", err)
+	}
+	if got := header.Get("traceparent"); len(got) != 1 || got[0] != traceparent {
+		t.Errorf("traceparent = %v, want the request's", got)
+	}
+	if got := header.Get("baggage"); len(got) != 1 || got[0] != "user=alice" {
+		t.Errorf("baggage = %v", got)
+	}
+	if got := header.Get(tracing.TraceResponse); len(got) != 1 || !strings.HasPrefix(got[0], "00-"+traceID+"-") {
+		t.Errorf("traceresponse = %v, want a span of trace %s", got, traceID)
+	}
+
+	if _, err := client.EchoError(ctx, &pb.EchoErrorRequest{Code: int32(codes.NotFound)}, grpc.Header(&header)); err == nil {
+		t.Fatal("EchoError succeeded")
+	}
+	if len(header.Get(tracing.TraceResponse)) != 1 {
+		t.Error("traceresponse missing from the failed RPC")
+	}
+
+	stream, err := client.ServerStream(ctx, &pb.ServerStreamRequest{Message: "hi", Count: 2})
+	if err != nil {
+		t.Fatalf("ServerStream failed: %v", err)
+	}
+	for {
+		if _, err := stream.Recv(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("Recv failed: %v", err)
+		}
+	}
+	if header, _ := stream.Header(); len(header.Get(tracing.TraceResponse)) != 1 {
+		t.Error("traceresponse missing from the stream")
+	}
+
+	spans := rec.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("%d spans, want 3", len(spans))
+	}
+	for _, span := range spans {
+		if span.SpanContext().TraceID().String() != traceID {
+			t.Errorf("span %s not in the caller's trace", span.Name())
+		}
+	}
+	if spans[0].Name() != "echo.v1.Echo/Echo" || spans[1].Status().Code.String() != "Error" {
+		t.Errorf("spans = %s (%v), %s (%v)", spans[0].Name(), spans[0].Status(), spans[1].Name(), spans[1].Status())
+	}
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"buf.build/go/protovalidate"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// ValidationServerOptions returns server options checking the request and
+// response messages against the buf.validate rules of their schema. Invalid
+// requests are rejected with INVALID_ARGUMENT, carrying a
+// google.rpc.BadRequest with a field violation per broken rule and the
+// buf.validate.Violations of protovalidate; invalid responses fail the call
+// with INTERNAL. Streaming RPCs check every message they receive and send.
+func ValidationServerOptions(v protovalidate.Validator) []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if err := validateRequest(v, req); err != nil {
+				return nil, err
+			}
+			resp, err := handler(ctx, req)
+			if err != nil {
+				return nil, err
+			}
+			if err := validateResponse(v, resp); err != nil {
+				return nil, err
+			}
+			return resp, nil
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			return handler(srv, &validatingStream{ServerStream: ss, validator: v})
+		}),
+	}
+}
+
+// validatingStream checks the messages of a streaming RPC
+type validatingStream struct {
+	grpc.ServerStream
+	validator protovalidate.Validator
+}
+
+func (s *validatingStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return validateRequest(s.validator, m)
+}
+
+func (s *validatingStream) SendMsg(m any) error {
+	if err := validateResponse(s.validator, m); err != nil {
+		return err
+	}
+	return s.ServerStream.SendMsg(m)
+}
+
+// validateRequest returns the INVALID_ARGUMENT status of a request breaking
+// the rules of its schema
+func validateRequest(v protovalidate.Validator, req any) error {
+	msg, ok := req.(proto.Message)
+	if !ok {
+		return nil
+	}
+	err := v.Validate(msg)
+	if err == nil {
+		return nil
+	}
+	var verr *protovalidate.ValidationError
+	if !errors.As(err, &verr) {
+		return status.Errorf(codes.Internal, "request validation: %v", err)
+	}
+	return violationStatus(codes.InvalidArgument, "invalid request", verr)
+}
+
+// validateResponse returns the INTERNAL status of a response breaking the
+// rules of its schema
+func validateResponse(v protovalidate.Validator, resp any) error {
+	msg, ok := resp.(proto.Message)
+	if !ok {
+		return nil
+	}
+	err := v.Validate(msg)
+	if err == nil {
+		return nil
+	}
+	var verr *protovalidate.ValidationError
+	if !errors.As(err, &verr) {
+		return status.Errorf(codes.Internal, "response validation: %v", err)
+	}
+	return violationStatus(codes.Internal, "invalid response", verr)
+}
+
+// violationStatus returns a status of code listing the violations of verr,
+// as a BadRequest and as buf.validate.Violations
+func violationStatus(code codes.Code, message string, verr *protovalidate.ValidationError) error {
+	br := &errdetails.BadRequest{}
+	descriptions := make([]string, 0, len(verr.Violations))
+	for _, violation := range verr.Violations {
+		field := protovalidate.FieldPathString(violation.Proto.GetField())
+		br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       field,
+			Description: violation.Proto.GetMessage(),
+			Reason:      violation.Proto.GetRuleId(),
+		})
+		description := violation.Proto.GetMessage()
+		if field != "" {
+			description = field + ": " + description
+		}
+		descriptions = append(descriptions, description)
+	}
+	message += ": " + strings.Join(descriptions, "; ")
+	st, err := status.New(code, message).WithDetails(br, verr.ToProto())
+	if err != nil {
+		return status.Error(code, message)
+	}
+	return st.Err()
+}
@@ -0,0 +1,148 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"buf.build/gen/go/bufbuild/protovalidate/protocolbuffers/go/buf/validate"
+	"buf.build/go/protovalidate"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/probitas-test/echo-servers/proto/echo/v1"
+)
+
+func newValidatingClient(t *testing.T) pb.EchoClient {
+	t.Helper()
+	v, err := protovalidate.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer(ValidationServerOptions(v)...)
+	pb.RegisterEchoServer(s, NewEchoServer())
+	conn, err := grpc.NewClient(listen(t, s), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return pb.NewEchoClient(conn)
+}
+
+// violations returns the BadRequest and buf.validate.Violations details of err
+func violations(t *testing.T, err error) (*errdetails.BadRequest, *validate.Violations) {
+	t.Helper()
+	var br *errdetails.BadRequest
+	var vs *validate.Violations
+	for _, detail := range status.Convert(err).Details() {
+		switch d := detail.(type) {
+		case *errdetails.BadRequest:
+			br = d
+		case *validate.Violations:
+			vs = d
+		}
+	}
+	if br == nil || vs == nil {
+		t.Fatalf("details of %v: BadRequest %v, Violations %v", err, br, vs)
+	}
+	return br, vs
+}
+
+func TestValidationServerOptions(t *testing.T) {
+	client := newValidatingClient(t)
+	ctx := context.Background()
+
+	if _, err := client.Echo(ctx, &pb.EchoRequest{Message: "hello"}); err != nil {
+		t.Fatalf("valid request: %v", err)
+	}
+	// Codes are checked before the handler maps them to UNKNOWN
+	_, err := client.EchoError(ctx, &pb.EchoErrorRequest{Code: 42})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("out of range code: %v", err)
+	}
+	br, vs := violations(t, err)
+	if len(br.FieldViolations) != 1 || br.FieldViolations[0].Field != "code" || br.FieldViolations[0].Reason != "int32.gte_lte" {
+		t.Errorf("field violations = %v", br.FieldViolations)
+	}
+	if len(vs.GetViolations()) != 1 || vs.GetViolations()[0].GetRuleId() != "int32.gte_lte" {
+		t.Errorf("violations = %v", vs.GetViolations())
+	}
+
+	// Nested fields are reported by path
+	_, err = client.EchoErrorWithDetails(ctx, &pb.EchoErrorWithDetailsRequest{
+		Code:    3,
+		Details: []*pb.ErrorDetail{{Type: "bad_request"}, {Type: "unknown", RetryDelayMs: -1}},
+	})
+	br, _ = violations(t, err)
+	fields := map[string]bool{}
+	for _, v := range br.FieldViolations {
+		fields[v.Field] = true
+	}
+	if len(fields) != 2 || !fields["details[1].type"] || !fields["details[1].retry_delay_ms"] {
+		t.Errorf("field violations = %v", br.FieldViolations)
+	}
+
+	// Enum values outside the schema
+	_, err = client.EchoStructured(ctx, &pb.StructuredRequest{Priority: pb.StructuredRequest_Priority(7)})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("undefined enum value: %v", err)
+	}
+}
+
+func TestValidationServerOptions_Stream(t *testing.T) {
+	client := newValidatingClient(t)
+	ctx := context.Background()
+
+	stream, err := client.ServerStreamResumable(ctx, &pb.ServerStreamResumableRequest{Count: 2, LastSequence: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = stream.Recv()
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("last_sequence beyond count: %v", err)
+	}
+	br, vs := violations(t, err)
+	if len(br.FieldViolations) != 1 || br.FieldViolations[0].Field != "" || br.FieldViolations[0].Reason != "last_sequence_in_range" {
+		t.Errorf("field violations = %v", br.FieldViolations)
+	}
+	if len(vs.GetViolations()) != 1 || vs.GetViolations()[0].GetMessage() != "last_sequence must not exceed count" {
+		t.Errorf("violations = %v", vs.GetViolations())
+	}
+
+	// Every message of a client stream is checked
+	bidi, err := client.BidirectionalStream(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := bidi.Send(&pb.EchoRequest{Message: "ok"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bidi.Recv(); err != nil {
+		t.Fatalf("valid message: %v", err)
+	}
+	if err := bidi.Send(&pb.EchoRequest{LoadReport: &pb.LoadReport{MemUtilization: 2}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bidi.Recv(); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("invalid second message: %v", err)
+	}
+}
+
+func TestValidateResponse(t *testing.T) {
+	v, err := protovalidate.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := validateResponse(v, &pb.EchoLargePayloadResponse{Payload: []byte("XXX"), ActualSize: 3}); err != nil {
+		t.Errorf("valid response: %v", err)
+	}
+	err = validateResponse(v, &pb.EchoLargePayloadResponse{Payload: []byte("XXX"), ActualSize: 4})
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("invalid response: %v", err)
+	}
+	if br, _ := violations(t, err); br.FieldViolations[0].Reason != "actual_size" {
+		t.Errorf("field violations = %v", br.FieldViolations)
+	}
+}
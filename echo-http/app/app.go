@@ -0,0 +1,610 @@
+// Package app runs echo-http: LoadConfig reads its settings and Run serves
+// it until its context ends, for the echo-http binary and echo-all.
+package app
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/quic-go/webtransport-go"
+
+	"github.com/probitas-test/echo-servers/echo-http/docs"
+	"github.com/probitas-test/echo-servers/echo-http/handlers"
+	"github.com/probitas-test/echo-servers/shared/admin"
+	"github.com/probitas-test/echo-servers/shared/bandwidth"
+	"github.com/probitas-test/echo-servers/shared/certs"
+	"github.com/probitas-test/echo-servers/shared/chaos"
+	"github.com/probitas-test/echo-servers/shared/clients"
+	"github.com/probitas-test/echo-servers/shared/clock"
+	"github.com/probitas-test/echo-servers/shared/connlimit"
+	"github.com/probitas-test/echo-servers/shared/credentials"
+	"github.com/probitas-test/echo-servers/shared/lifecycle"
+	"github.com/probitas-test/echo-servers/shared/logging"
+	"github.com/probitas-test/echo-servers/shared/metrics"
+	"github.com/probitas-test/echo-servers/shared/mirror"
+	"github.com/probitas-test/echo-servers/shared/network"
+	"github.com/probitas-test/echo-servers/shared/ratelimit"
+	"github.com/probitas-test/echo-servers/shared/recording"
+	"github.com/probitas-test/echo-servers/shared/script"
+	"github.com/probitas-test/echo-servers/shared/tracing"
+	"github.com/probitas-test/echo-servers/shared/version"
+)
+
+// Run serves echo-http with cfg until ctx is done, then shuts it down
+// gracefully. Logging and the address family are set up by the caller, as
+// they are shared by the servers of a process.
+func Run(ctx context.Context, cfg *Config) error {
+	// Started servers stop when Run returns early with an error
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Listeners serving in the background stop Run with their error
+	serveErr := make(chan error, 2)
+
+	// Simulated slow startup: no port opens before the startup delay
+	if err := cfg.Lifecycle.Validate(); err != nil {
+		return fmt.Errorf("invalid lifecycle configuration: %w", err)
+	}
+	log.Printf("Lifecycle: %s", cfg.Lifecycle)
+	lifecycle.Delay(ctx, cfg.Lifecycle)
+
+	// OpenTelemetry tracing and W3C trace context propagation
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		return fmt.Errorf("failed to set up tracing: %w", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Error("Tracing shutdown error", "error", err)
+		}
+	}()
+	log.Printf("OpenTelemetry tracing: %s", cfg.Tracing)
+
+	// Admin API: health, response delay, OAuth2 flags and sessions, stored
+	// requests
+	adm := admin.New("echo-http", cfg.src)
+	requirePKCE := adm.Flag("auth_code_require_pkce", cfg.AuthCodeRequirePKCE,
+		"Require PKCE in the authorization code flow")
+	validateRedirectURI := adm.Flag("auth_code_validate_redirect_uri", cfg.AuthCodeValidateRedirectURI,
+		"Check redirect_uri against AUTH_CODE_ALLOWED_REDIRECT_URIS")
+	adm.Store("oauth2_sessions", func() { handlers.DefaultSessionStore.Clear() })
+	adm.State("oauth2_sessions", func() any { return handlers.DefaultSessionStore.Counts() })
+	adm.Store("stored_requests", func() { handlers.DefaultRequestStore.Clear() })
+	adm.State("stored_requests", func() any { return handlers.DefaultRequestStore.Len() })
+	adm.Store("origin_hits", func() { handlers.DefaultOriginHits.Clear() })
+	adm.State("origin_hits", func() any { return handlers.DefaultOriginHits.Counts() })
+	adm.Store("barriers", func() { handlers.DefaultBarriers.Clear() })
+	adm.State("barriers", func() any { return handlers.DefaultBarriers.Pending() })
+
+	// Local CA issuing the certificates of the HTTPS and HTTP/3 listeners
+	ca, err := certs.New(cfg.TLS)
+	if err != nil {
+		return fmt.Errorf("invalid TLS configuration: %w", err)
+	}
+
+	// Set API docs content for handler
+	handlers.SetAPIDocs(docs.API)
+
+	// Test identities shared with the other servers, checked by the Basic
+	// and Bearer auth endpoints and the password grant;
+	// AUTH_ALLOWED_USERNAME and AUTH_ALLOWED_PASSWORD add one more
+	var allowedUsers []credentials.User
+	if cfg.AuthAllowedUsername != "" && cfg.AuthAllowedPassword != "" {
+		allowedUsers = append(allowedUsers, credentials.User{Username: cfg.AuthAllowedUsername, Password: cfg.AuthAllowedPassword})
+	}
+	creds, err := credentials.New(cfg.Credentials, allowedUsers...)
+	if err != nil {
+		return fmt.Errorf("invalid credentials: %w", err)
+	}
+	log.Printf("Credentials: %s", cfg.Credentials)
+
+	// Clock of token, session and credentials JWT expiry and of the delay
+	// endpoints, moved at runtime to test expiry without waiting
+	clk := clock.New(cfg.Clock)
+	log.Printf("Clock: %s", cfg.Clock)
+	creds.SetClock(clk)
+
+	// Static key of /transform/aes
+	transformAESKey, err := hex.DecodeString(cfg.TransformAESKey)
+	if err != nil || (len(transformAESKey) != 16 && len(transformAESKey) != 24 && len(transformAESKey) != 32) {
+		return errors.New("TRANSFORM_AES_KEY must be 16, 24 or 32 bytes in hex")
+	}
+
+	// Set OAuth2/OIDC config for handlers
+	handlers.SetConfig(&handlers.Config{
+		AuthAllowedClientID:         cfg.AuthAllowedClientID,
+		AuthAllowedClientSecret:     cfg.AuthAllowedClientSecret,
+		AuthSupportedScopes:         cfg.AuthSupportedScopes,
+		AuthTokenExpiry:             cfg.AuthTokenExpiry,
+		AuthAllowedGrantTypes:       cfg.AuthAllowedGrantTypes,
+		AuthAllowedUsername:         cfg.AuthAllowedUsername,
+		AuthAllowedPassword:         cfg.AuthAllowedPassword,
+		Credentials:                 creds,
+		Clock:                       clk,
+		AuthCodeRequirePKCE:         requirePKCE.Enabled,
+		AuthCodeSessionTTL:          cfg.AuthCodeSessionTTL,
+		AuthCodeValidateRedirectURI: validateRedirectURI.Enabled,
+		AuthCodeAllowedRedirectURIs: cfg.AuthCodeAllowedRedirectURIs,
+		RedirectChainHosts:          cfg.RedirectChainHosts,
+		AnythingStoreTTL:            cfg.AnythingStoreTTL,
+		AnythingStoreMaxEntries:     cfg.AnythingStoreMaxEntries,
+		StickyInstances:             cfg.StickyInstances,
+		StickyCookie:                cfg.StickyCookie,
+		BombMaxSize:                 cfg.BombMaxSize,
+		TransformAESKey:             transformAESKey,
+	})
+
+	r := chi.NewRouter()
+
+	// Server span per request, echoing the trace context in the response
+	r.Use(tracing.HTTP(func(r *http.Request) string {
+		return chi.RouteContext(r.Context()).RoutePattern()
+	}))
+
+	// Traffic recording, toggled and downloaded through the admin API;
+	// outside the recoverer so panics are recorded as 500 responses
+	recorder, err := recording.New("echo-http", cfg.Recording)
+	if err != nil {
+		return err
+	}
+	log.Printf("Recording: %s", cfg.Recording)
+
+	// Request ID and request log; panics are logged as 500 responses
+	r.Use(logging.HTTP)
+	r.Use(recorder.HTTP)
+	r.Use(middleware.Recoverer)
+
+	// Connection numbering and reuse, reported on every response
+	keepAlive := handlers.NewKeepAlive(cfg.KeepAliveEnabled, cfg.KeepAliveMaxRequests)
+	r.Use(keepAlive.Middleware)
+	log.Printf("Keep-alive: enabled=%t max_requests=%d", cfg.KeepAliveEnabled, cfg.KeepAliveMaxRequests)
+
+	// Copies of the sampled requests sent to MIRROR_TARGET in the
+	// background, whatever the answer of this server
+	mirrors, err := mirror.New(cfg.Mirror)
+	if err != nil {
+		return err
+	}
+	r.Use(mirror.HTTP(mirrors, "echo-http"))
+	log.Printf("Mirror: %s", cfg.Mirror)
+
+	// Prometheus metrics of every request, served on a separate port
+	var metricsServer *http.Server
+	if cfg.MetricsEnabled {
+		m := metrics.New("echo-http")
+		r.Use(m.HTTP("http", routePattern))
+		metricsServer, err = m.Serve(cfg.MetricsAddr())
+		if err != nil {
+			return err
+		}
+		defer func() { _ = metricsServer.Close() }()
+	}
+
+	// Host allowlist, inside the metrics so rejected requests are recorded
+	allowedHosts, err := handlers.NewHostAllowlist(cfg.AllowedHosts)
+	if err != nil {
+		return err
+	}
+	r.Use(allowedHosts.Middleware)
+	log.Printf("Allowed hosts: %s", allowedHosts)
+
+	// API keys, inside the metrics so rejected requests are recorded, and
+	// before the rate limits
+	apiKeys, err := handlers.NewAPIKeys(cfg.APIKeys)
+	if err != nil {
+		return err
+	}
+	r.Use(apiKeys.Middleware)
+	log.Printf("API keys: %s", cfg.APIKeys)
+	adm.Store("api_key_rate_limits", apiKeys.Clear)
+
+	// Rate limiting, inside the metrics so throttled requests are recorded,
+	// and before the injected faults
+	limiter, err := ratelimit.New(cfg.RateLimit)
+	if err != nil {
+		return err
+	}
+	r.Use(ratelimit.HTTP(limiter))
+	log.Printf("Rate limit: %s", cfg.RateLimit)
+	adm.Store("rate_limits", limiter.Clear)
+
+	// Scripted scenarios, after the rate limit and before the injected
+	// faults
+	scenarios, err := script.New(cfg.Script)
+	if err != nil {
+		return err
+	}
+	r.Use(script.HTTP(scenarios))
+	log.Printf("Script: %s", cfg.Script)
+	adm.Store("script_progress", scenarios.Rewind)
+
+	// Client behavior profiles, each with its own rate limit and faults,
+	// before the global faults
+	profiles, err := clients.New(cfg.ClientProfiles)
+	if err != nil {
+		return err
+	}
+	r.Use(clients.HTTP(profiles))
+	log.Printf("Client profiles: %s", cfg.ClientProfiles)
+	adm.Store("client_rate_limits", profiles.Clear)
+
+	// Fault injection, inside the metrics so injected errors and delays
+	// are recorded
+	faults, err := chaos.New(cfg.Chaos)
+	if err != nil {
+		return err
+	}
+	r.Use(chaos.HTTP(faults))
+	log.Printf("Chaos: %s", cfg.Chaos)
+
+	// Response delay set through the admin API
+	r.Use(adm.HTTP)
+
+	// Example responses of the paths of OPENAPI_SPEC_FILE, ahead of the
+	// built-in endpoints
+	if cfg.OpenAPISpecFile != "" {
+		mock, err := handlers.LoadOpenAPI(cfg.OpenAPISpecFile)
+		if err != nil {
+			return fmt.Errorf("invalid OpenAPI specification: %w", err)
+		}
+		r.Use(mock.Middleware)
+		log.Printf("OpenAPI mock: %s %s", cfg.OpenAPISpecFile, mock)
+	}
+
+	// OPTIONS and TRACE on every path, and custom methods on /anything
+	r.Use(handlers.Methods(r))
+
+	// HEAD on every GET endpoint, with the headers and Content-Length of GET
+	r.Use(handlers.Head(r))
+
+	// Echo endpoints
+	r.Get("/get", handlers.EchoHandler)
+	r.Post("/post", handlers.EchoHandler)
+	r.Put("/put", handlers.EchoHandler)
+	r.Patch("/patch", handlers.EchoHandler)
+	r.Delete("/delete", handlers.EchoHandler)
+
+	// Anything endpoint - echoes any request
+	r.HandleFunc("/anything", handlers.AnythingHandler)
+	r.HandleFunc("/anything/stored/{id}", handlers.StoredRequestHandler)
+	r.HandleFunc("/anything/*", handlers.AnythingHandler)
+
+	// Utility endpoints
+	r.Get("/headers", handlers.HeadersHandler)
+	r.Get("/response-header", handlers.ResponseHeaderHandler)
+	r.Get("/weird-headers", handlers.WeirdHeadersHandler)
+	r.Get("/ip", handlers.IPHandler)
+	r.Get("/user-agent", handlers.UserAgentHandler)
+
+	// Status endpoint - support all HTTP methods
+	r.HandleFunc("/status/{code}", handlers.StatusHandler)
+
+	// Delay endpoint
+	r.Get("/delay/{seconds}", handlers.DelayHandler)
+
+	// Host header and SNI analysis
+	r.HandleFunc("/host", allowedHosts.Handler)
+
+	// Session affinity across simulated instances
+	r.HandleFunc("/sticky", handlers.StickyHandler)
+
+	// Response at a wall-clock instant
+	r.HandleFunc("/at", handlers.AtHandler)
+
+	// Requests held until enough of them arrived, then released together
+	r.HandleFunc("/barrier/{name}/{n}", handlers.BarrierHandler)
+
+	// Redirect endpoints
+	r.Get("/redirect/{n}", handlers.RedirectHandler)
+	r.Get("/redirect-to", handlers.RedirectToHandler)
+	r.Get("/absolute-redirect/{n}", handlers.AbsoluteRedirectHandler)
+	r.Get("/relative-redirect/{n}", handlers.RelativeRedirectHandler)
+	r.Get("/redirect-chain", handlers.RedirectChainHandler)
+	r.Get("/redirect-chain/{hop}", handlers.RedirectChainHandler)
+
+	// HEAD answered with a Content-Length that differs from GET
+	r.Get("/head-mismatch", handlers.HeadMismatchHandler)
+	r.Head("/head-mismatch", handlers.HeadMismatchHandler)
+
+	// OAuth2/OIDC endpoints (environment-based auth)
+	r.Get("/.well-known/oauth-authorization-server", handlers.OAuth2MetadataHandler)
+	r.Get("/.well-known/openid-configuration", handlers.OIDCDiscoveryRootHandler)
+	r.Get("/.well-known/jwks.json", handlers.OAuth2JWKSHandler)
+	r.Get("/oauth2/authorize", handlers.OAuth2AuthorizeHandler)
+	r.Post("/oauth2/authorize", handlers.OAuth2AuthorizeHandler)
+	r.Get("/oauth2/callback", handlers.OAuth2CallbackHandler)
+	r.Post("/oauth2/token", handlers.OAuth2TokenHandler)
+	r.Get("/oauth2/userinfo", handlers.OAuth2UserInfoHandler)
+	r.Get("/oauth2/demo", handlers.OAuth2DemoHandler)
+
+	// Basic Auth (environment-based)
+	r.Get("/basic-auth", handlers.BasicAuthEnvHandler)
+
+	// Bearer Token Auth (environment-based)
+	r.Get("/bearer-auth", handlers.BearerAuthEnvHandler)
+
+	// Cookie endpoints
+	r.Get("/cookies", handlers.CookiesHandler)
+	r.Get("/cookies/set", handlers.CookiesSetHandler)
+	r.Get("/cookies/delete", handlers.CookiesDeleteHandler)
+
+	// Binary data endpoints
+	r.Get("/bytes/{n}", handlers.BytesHandler)
+
+	// Streaming endpoints
+	r.Get("/stream/{n}", handlers.StreamHandler)
+	r.Get("/drip", handlers.DripHandler)
+
+	// Charset endpoints
+	r.Get("/charset", handlers.CharsetsHandler)
+	r.Get("/charset/{enc}", handlers.CharsetHandler)
+
+	// Body transformations
+	r.Post("/transform/{op}", handlers.TransformHandler)
+
+	// Compression endpoints
+	r.Get("/gzip", handlers.GzipHandler)
+	r.Get("/deflate", handlers.DeflateHandler)
+	r.Get("/brotli", handlers.BrotliHandler)
+
+	// Caching headers and revalidation of a changing resource
+	r.Get("/cache-control", handlers.CacheControlHandler)
+
+	// Decompression bombs and oversized responses, only when enabled
+	if cfg.BombEnabled {
+		r.Get("/bomb/content-length", handlers.BombContentLengthHandler)
+		r.Get("/bomb/{encoding}", handlers.BombHandler)
+		log.Printf("Bomb endpoints enabled: max_size=%d", cfg.BombMaxSize)
+	}
+
+	// echo-graphql schema, only when enabled
+	if cfg.GraphQLEnabled {
+		graphql, resolver := newGraphQLHandler()
+		r.Handle(cfg.GraphQLPath, graphql)
+		adm.Store("graphql_messages", func() { resolver.ClearMessages() })
+		adm.State("graphql_messages", func() any { return resolver.MessageCount() })
+		log.Printf("GraphQL endpoint enabled: path=%s", cfg.GraphQLPath)
+	}
+
+	// Health check endpoint
+	r.Get("/health", adm.HealthHandler().ServeHTTP)
+
+	// Effective configuration (secrets redacted)
+	r.Get("/config", cfg.src.ServeHTTP)
+
+	// Address family of the client connection
+	r.Get(network.Path, network.Handler().ServeHTTP)
+
+	// Connection number, reuse and keep-alive
+	r.Get("/connection", keepAlive.Handler)
+
+	// Build information and enabled features
+	build := version.New("echo-http", version.Features{
+		"admin":           cfg.Admin.Enabled,
+		"allowed_hosts":   len(cfg.AllowedHosts) > 0,
+		"api_keys":        len(cfg.APIKeys.Keys) > 0 || cfg.APIKeys.File != "",
+		"bomb":            cfg.BombEnabled,
+		"chaos":           cfg.Chaos.Enabled,
+		"client_profiles": cfg.ClientProfiles.File != "",
+		"debug":           cfg.Admin.Debug,
+		"graphql":         cfg.GraphQLEnabled,
+		"http3":           cfg.HTTP3Enabled,
+		"https":           cfg.HTTPSEnabled,
+		"metrics":         cfg.MetricsEnabled,
+		"mirror":          cfg.Mirror.Target != "",
+		"openapi_mock":    cfg.OpenAPISpecFile != "",
+		"rate_limit":      cfg.RateLimit.Enabled,
+		"recording":       cfg.Recording.Enabled,
+		"script":          cfg.Script.File != "",
+		"tracing":         cfg.Tracing.Enabled,
+		"virtual_hosts":   len(cfg.VirtualHosts) > 0,
+	})
+	r.Get(version.Path, version.Handler(build).ServeHTTP)
+
+	// OpenAPI description of the routes above
+	r.Get(handlers.OpenAPIDocumentPath, handlers.OpenAPIDocumentHandler(r, build.Version))
+
+	// CA certificate of the HTTPS and HTTP/3 listeners
+	r.Get(certs.CAPath, certs.CAHandler(ca).ServeHTTP)
+
+	// Fault injection admin API
+	r.Handle(chaos.Path, chaos.Handler(faults))
+
+	// Rate limit admin API
+	r.Handle(ratelimit.Path, ratelimit.Handler(limiter))
+
+	// Script admin API
+	r.Handle(script.Path, script.Handler(scenarios))
+
+	// Client profile admin API
+	r.Handle(clients.Path, clients.Handler(profiles))
+
+	// Clock admin API
+	r.Handle(clock.Path, clock.Handler(clk))
+
+	// Mirror admin API
+	r.Handle(mirror.Path, mirror.Handler(mirrors))
+
+	// API documentation endpoint
+	r.Get("/", handlers.APIDocsHandler)
+
+	// Every endpoint under BASE_PATH, or under the base path of the virtual
+	// host of the request
+	sites, err := handlers.NewSites(cfg.BasePath, cfg.VirtualHosts, cfg.VirtualHostsStrict)
+	if err != nil {
+		return err
+	}
+	site := sites.Handler(r)
+	log.Printf("Sites: %s", sites)
+
+	// Bandwidth shaping of every HTTP and HTTPS connection, changed at runtime
+	// on the admin port
+	shaper, err := bandwidth.New(cfg.Bandwidth)
+	if err != nil {
+		return err
+	}
+	log.Printf("Bandwidth: %s", cfg.Bandwidth)
+
+	// Cap on the open HTTP and HTTPS connections, changed at runtime on the
+	// admin port; the next ones get a server busy error
+	connLimiter, err := connlimit.New(cfg.ConnLimit)
+	if err != nil {
+		return err
+	}
+	log.Printf("Connection limit: %s", cfg.ConnLimit)
+
+	// Admin API on a separate port, with the fault injection, rate limit,
+	// script, client profile, credential store, clock, mirror, bandwidth,
+	// connection limit, API key, recording and certificate admin APIs
+	var adminServer *http.Server
+	if cfg.Admin.Enabled {
+		adm.Handle(chaos.Path, chaos.Handler(faults))
+		adm.Handle(ratelimit.Path, ratelimit.Handler(limiter))
+		adm.Handle(script.Path, script.Handler(scenarios))
+		adm.Handle(clients.Path, clients.Handler(profiles))
+		adm.Handle(credentials.Path, credentials.Handler(creds))
+		adm.Handle(clock.Path, clock.Handler(clk))
+		adm.Handle(mirror.Path, mirror.Handler(mirrors))
+		adm.Handle(bandwidth.Path, bandwidth.Handler(shaper))
+		adm.Handle(connlimit.Path, connlimit.Handler(connLimiter))
+		adm.Handle(handlers.APIKeysPath, apiKeys.Handler())
+		recordings := recording.Handler(recorder)
+		adm.Handle(recording.Path, recordings)
+		adm.Handle(recording.Path+"/", recordings)
+		adm.Handle(certs.Path, certs.Handler(ca))
+		adm.Handle(certs.CAPath, certs.CAHandler(ca))
+		if cfg.Admin.Debug {
+			adm.Debug()
+		}
+		adminServer, err = adm.Serve(cfg.AdminAddr())
+		if err != nil {
+			return err
+		}
+		defer func() { _ = adminServer.Close() }()
+	}
+
+	// Readiness delay and simulated crash, counted from here
+	lifecycle.Start(ctx, cfg.Lifecycle, adm)
+
+	tlsConfig, err := loadTLSConfig(cfg, ca)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+	if cfg.TLSCertFile == "" {
+		log.Printf("TLS certificates: %s", cfg.TLS)
+	}
+
+	var https *http.Server
+	if cfg.HTTPSEnabled {
+		httpsListener, err := network.Listen(cfg.HTTPSAddr())
+		if err != nil {
+			return fmt.Errorf("failed to listen for HTTPS: %w", err)
+		}
+		defer func() { _ = httpsListener.Close() }()
+		https = &http.Server{Addr: cfg.HTTPSAddr(), Handler: site, TLSConfig: tlsConfig}
+		keepAlive.Apply(https)
+		go func() {
+			if err := https.ServeTLS(shaper.Listen(connLimiter.Listen(httpsListener, nil)), "", ""); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				serveErr <- fmt.Errorf("failed to serve HTTPS: %w", err)
+				cancel()
+			}
+		}()
+		log.Printf("Starting HTTPS server on %s", cfg.HTTPSAddr())
+	}
+
+	var h3 *webtransport.Server
+	if cfg.HTTP3Enabled {
+		h3 = newHTTP3Server(cfg, tlsConfig, site)
+		h3Conn, err := network.ListenPacket(cfg.HTTP3Addr())
+		if err != nil {
+			return fmt.Errorf("failed to listen for HTTP/3: %w", err)
+		}
+		defer func() { _ = h3Conn.Close() }()
+		go func() {
+			if err := h3.Serve(h3Conn); err != nil && !errors.Is(err, context.Canceled) {
+				serveErr <- fmt.Errorf("failed to serve HTTP/3: %w", err)
+				cancel()
+			}
+		}()
+		hash, err := certificateHash(tlsConfig)
+		if err != nil {
+			return fmt.Errorf("failed to issue HTTP/3 certificate: %w", err)
+		}
+		log.Printf("HTTP/3 certificate SHA-256: %s", hash)
+		log.Printf("Starting HTTP/3 server on %s (udp)", cfg.HTTP3Addr())
+	}
+
+	listener, err := network.Listen(cfg.Addr())
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	defer func() { _ = listener.Close() }()
+	server := &http.Server{Handler: site}
+	keepAlive.Apply(server)
+
+	// Graceful shutdown of the HTTP and HTTPS servers; HTTP/3 and
+	// WebTransport connections are closed
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		<-ctx.Done()
+
+		log.Println("Shutting down server...")
+		if h3 != nil {
+			if err := h3.Close(); err != nil {
+				slog.Error("HTTP/3 server shutdown error", "error", err)
+			}
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if https != nil {
+			if err := https.Shutdown(ctx); err != nil {
+				slog.Error("HTTPS server shutdown error", "error", err)
+			}
+		}
+		if err := server.Shutdown(ctx); err != nil {
+			slog.Error("Server shutdown error", "error", err)
+		}
+		if metricsServer != nil {
+			_ = metricsServer.Shutdown(ctx)
+		}
+		if adminServer != nil {
+			_ = adminServer.Shutdown(ctx)
+		}
+	}()
+
+	log.Printf("Starting server on %s", cfg.Addr())
+	if err := server.Serve(shaper.Listen(connLimiter.Listen(listener, connlimit.HTTP))); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve: %w", err)
+	}
+	<-stopped
+
+	log.Println("Server stopped")
+	select {
+	case err := <-serveErr:
+		return err
+	default:
+		return nil
+	}
+}
+
+// routePattern returns the route that matched r, such as
+// "GET /status/{code}", keeping the metric labels bounded
+func routePattern(r *http.Request) string {
+	pattern := chi.RouteContext(r.Context()).RoutePattern()
+	if pattern == "" {
+		return metrics.Unmatched
+	}
+	return r.Method + " " + pattern
+}
@@ -0,0 +1,323 @@
+package app
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/probitas-test/echo-servers/echo-http/handlers"
+	"github.com/probitas-test/echo-servers/shared/admin"
+	"github.com/probitas-test/echo-servers/shared/bandwidth"
+	"github.com/probitas-test/echo-servers/shared/certs"
+	"github.com/probitas-test/echo-servers/shared/chaos"
+	"github.com/probitas-test/echo-servers/shared/clients"
+	"github.com/probitas-test/echo-servers/shared/clock"
+	"github.com/probitas-test/echo-servers/shared/config"
+	"github.com/probitas-test/echo-servers/shared/connlimit"
+	"github.com/probitas-test/echo-servers/shared/credentials"
+	"github.com/probitas-test/echo-servers/shared/lifecycle"
+	"github.com/probitas-test/echo-servers/shared/logging"
+	"github.com/probitas-test/echo-servers/shared/mirror"
+	"github.com/probitas-test/echo-servers/shared/network"
+	"github.com/probitas-test/echo-servers/shared/ratelimit"
+	"github.com/probitas-test/echo-servers/shared/recording"
+	"github.com/probitas-test/echo-servers/shared/script"
+	"github.com/probitas-test/echo-servers/shared/tracing"
+)
+
+type Config struct {
+	Host string
+	Port string
+
+	// HTTP/3 (QUIC) listener on UDP, serving the same routes plus the
+	// WebTransport echo endpoint
+	HTTP3Enabled bool
+	HTTP3Port    string
+
+	// HTTPS listener on TCP, serving the same routes over HTTP/1.1 and
+	// HTTP/2
+	HTTPSEnabled bool
+	HTTPSPort    string
+
+	// Certificate of the HTTPS and HTTP/3 listeners. Without certificate
+	// files, certificates are issued by a local CA generated at startup
+	// (TLS_* settings), served at /ca.pem.
+	TLSCertFile string
+	TLSKeyFile  string
+	TLS         certs.Config
+
+	// OAuth2 Configuration (shared across all flows)
+	AuthAllowedClientID     string
+	AuthAllowedClientSecret string
+	AuthSupportedScopes     []string
+	AuthTokenExpiry         int
+	AuthAllowedGrantTypes   []string
+
+	// Resource Owner Password Credentials / Basic Auth
+	AuthAllowedUsername string
+	AuthAllowedPassword string
+
+	// Authorization Code Flow Configuration
+	AuthCodeRequirePKCE         bool
+	AuthCodeSessionTTL          int
+	AuthCodeValidateRedirectURI bool
+	AuthCodeAllowedRedirectURIs string
+
+	// Logical echo sites: every endpoint under BasePath, and the sites of
+	// VirtualHosts ("host[=/base]", "*.domain" wildcards) chosen by Host
+	// header. Strict virtual hosts answer other hosts 421.
+	BasePath           string
+	VirtualHosts       []string
+	VirtualHostsStrict bool
+
+	// Requests stored by /anything?store=true, kept AnythingStoreTTL
+	// seconds unless ?store_ttl= and at most AnythingStoreMaxEntries
+	AnythingStoreTTL        int
+	AnythingStoreMaxEntries int
+
+	// API keys required by every endpoint while there are any (API_KEYS,
+	// API_KEYS_FILE), replaced at runtime at /apikeys on the admin port
+	APIKeys handlers.APIKeysConfig
+
+	// Host headers answered, others get 421 Misdirected Request (any host
+	// when empty)
+	AllowedHosts []string
+
+	// Instances simulated by /sticky and the name of its session cookie
+	StickyInstances int
+	StickyCookie    string
+
+	// Static AES key of /transform/aes (hex)
+	TransformAESKey string
+
+	// Decompression bombs and oversized Content-Length at /bomb, only
+	// served when BombEnabled, up to BombMaxSize bytes
+	BombEnabled bool
+	BombMaxSize int
+
+	// echo-graphql schema mounted at GraphQLPath when GraphQLEnabled
+	GraphQLEnabled bool
+	GraphQLPath    string
+
+	// Alias hosts of /redirect-chain, names of this server that clients
+	// treat as different hosts
+	RedirectChainHosts []string
+
+	// Prometheus metrics, served at /metrics on a separate port
+	MetricsEnabled bool
+	MetricsPort    string
+
+	// Admin API on a separate port (ADMIN_ENABLED, ADMIN_PORT)
+	Admin admin.Config
+
+	// Fault injection (CHAOS_*), changed at runtime at /chaos
+	Chaos chaos.Config
+
+	// Rate limiting (RATE_LIMIT_*), changed at runtime at /ratelimit
+	RateLimit ratelimit.Config
+
+	// Traffic recording (RECORDING_*), downloaded from the admin port
+	Recording recording.Config
+
+	// Scripted scenarios (SCRIPT_*), replaced at runtime at /script
+	Script script.Config
+
+	// Client behavior profiles (CLIENT_PROFILES_*), replaced at runtime
+	// at /clients
+	ClientProfiles clients.Config
+
+	// Bandwidth shaping of accepted connections (BANDWIDTH_*), changed at
+	// runtime at /bandwidth on the admin port
+	Bandwidth bandwidth.Config
+
+	// Connection cap (CONN_LIMIT_*), changed at runtime at /connlimit on the
+	// admin port
+	ConnLimit connlimit.Config
+
+	// Keep-alive of the HTTP and HTTPS connections, closed after
+	// KeepAliveMaxRequests requests unless 0
+	KeepAliveEnabled     bool
+	KeepAliveMaxRequests int
+
+	// Address family of every listener (IP_FAMILY: dual, ipv4 or ipv6)
+	Network network.Config
+
+	// Simulated startup delay, readiness delay and crash (STARTUP_DELAY_MS, STARTUP_READY_DELAY_MS, CRASH_AFTER_MS, CRASH_EXIT_CODE)
+	Lifecycle lifecycle.Config
+
+	// Test identities shared with the other servers (CREDENTIALS_USERS, CREDENTIALS_FILE, CREDENTIALS_REQUIRED)
+	Credentials credentials.Config
+
+	// OpenAPI 3 specification whose paths are answered with their example
+	// responses (OPENAPI_SPEC_FILE)
+	OpenAPISpecFile string
+
+	// Controllable clock of token expiry and delays (CLOCK_OFFSET_MS), moved at runtime at /clock
+	Clock clock.Config
+
+	// Copies of sampled requests sent to a secondary target (MIRROR_*), changed at runtime at /mirror
+	Mirror mirror.Config
+
+	// Structured logging (LOG_LEVEL, LOG_FORMAT)
+	Logging logging.Config
+
+	// OpenTelemetry tracing (OTEL_* variables)
+	Tracing tracing.Config
+
+	// Effective configuration, served at /config
+	src *config.Source
+}
+
+// LoadConfig reads the settings of echo-http from src
+func LoadConfig(src *config.Source) (*Config, error) {
+	cfg := &Config{
+		Host: src.String("HOST", "0.0.0.0"),
+		Port: src.String("PORT", "80"),
+
+		// HTTP/3 settings
+		HTTP3Enabled: src.Bool("HTTP3_ENABLED", false),
+		HTTP3Port:    src.String("HTTP3_PORT", "443"),
+
+		// HTTPS settings
+		HTTPSEnabled: src.Bool("HTTPS_ENABLED", false),
+		HTTPSPort:    src.String("HTTPS_PORT", "443"),
+
+		// Certificates of the HTTPS and HTTP/3 listeners
+		TLSCertFile: src.String("TLS_CERT_FILE", ""),
+		TLSKeyFile:  src.String("TLS_KEY_FILE", ""),
+		TLS:         certs.LoadConfig(src),
+
+		// OAuth2 settings (shared across all flows)
+		AuthAllowedClientID:     src.String("AUTH_ALLOWED_CLIENT_ID", ""),
+		AuthAllowedClientSecret: src.Secret("AUTH_ALLOWED_CLIENT_SECRET", ""),
+		AuthSupportedScopes:     parseScopes(src.String("AUTH_SUPPORTED_SCOPES", "openid,profile,email")),
+		AuthTokenExpiry:         src.Int("AUTH_TOKEN_EXPIRY", 3600),
+		AuthAllowedGrantTypes:   parseGrantTypes(src.String("AUTH_ALLOWED_GRANT_TYPES", "authorization_code,client_credentials,password,refresh_token")),
+
+		// Resource Owner Password Credentials / Basic Auth settings
+		AuthAllowedUsername: src.String("AUTH_ALLOWED_USERNAME", "testuser"),
+		AuthAllowedPassword: src.Secret("AUTH_ALLOWED_PASSWORD", "testpass"),
+
+		// Authorization Code Flow settings
+		AuthCodeRequirePKCE:         src.Bool("AUTH_CODE_REQUIRE_PKCE", false),
+		AuthCodeSessionTTL:          src.Int("AUTH_CODE_SESSION_TTL", 300),
+		AuthCodeValidateRedirectURI: src.Bool("AUTH_CODE_VALIDATE_REDIRECT_URI", false),
+		AuthCodeAllowedRedirectURIs: src.String("AUTH_CODE_ALLOWED_REDIRECT_URIS", ""),
+
+		// Base path and virtual hosts
+		BasePath:           src.String("BASE_PATH", ""),
+		VirtualHosts:       src.List("VIRTUAL_HOSTS", ""),
+		VirtualHostsStrict: src.Bool("VIRTUAL_HOSTS_STRICT", false),
+
+		// Stored requests
+		AnythingStoreTTL:        src.Int("ANYTHING_STORE_TTL", 300),
+		AnythingStoreMaxEntries: src.Int("ANYTHING_STORE_MAX_ENTRIES", 1000),
+
+		// API key protection
+		APIKeys: handlers.APIKeysConfig{
+			Keys:          src.SecretList("API_KEYS", ""),
+			File:          src.String("API_KEYS_FILE", ""),
+			Header:        src.String("API_KEYS_HEADER", credentials.APIKeyHeader),
+			SignatureSkew: time.Duration(src.Int("API_KEYS_SIGNATURE_SKEW_SECONDS", 300)) * time.Second,
+		},
+
+		// Host header validation
+		AllowedHosts: src.List("ALLOWED_HOSTS", ""),
+
+		// Session affinity
+		StickyInstances: src.Int("STICKY_INSTANCES", 3),
+		StickyCookie:    src.String("STICKY_COOKIE", "echo_sticky"),
+
+		// Body transformations
+		TransformAESKey: src.Secret("TRANSFORM_AES_KEY", handlers.DefaultTransformAESKey),
+
+		// Client safety testing
+		BombEnabled: src.Bool("BOMB_ENABLED", false),
+		BombMaxSize: src.Int("BOMB_MAX_SIZE", 10<<30),
+
+		// Embedded GraphQL endpoint
+		GraphQLEnabled: src.Bool("GRAPHQL_ENABLED", false),
+		GraphQLPath:    src.String("GRAPHQL_PATH", "/graphql"),
+
+		// Redirect chain settings
+		RedirectChainHosts: src.List("REDIRECT_CHAIN_HOSTS", "localhost,127.0.0.1"),
+
+		// Spec-driven mock
+		OpenAPISpecFile: src.String("OPENAPI_SPEC_FILE", ""),
+
+		// Connection reuse
+		KeepAliveEnabled:     src.Bool("KEEPALIVE_ENABLED", true),
+		KeepAliveMaxRequests: src.Int("KEEPALIVE_MAX_REQUESTS", 0),
+
+		MetricsEnabled: src.Bool("METRICS_ENABLED", true),
+		MetricsPort:    src.String("METRICS_PORT", "9090"),
+
+		Admin:          admin.LoadConfig(src),
+		Chaos:          chaos.LoadConfig(src),
+		RateLimit:      ratelimit.LoadConfig(src),
+		Tracing:        tracing.LoadConfig(src, "echo-http"),
+		Recording:      recording.LoadConfig(src),
+		Script:         script.LoadConfig(src),
+		ClientProfiles: clients.LoadConfig(src),
+		Bandwidth:      bandwidth.LoadConfig(src),
+		ConnLimit:      connlimit.LoadConfig(src),
+		Network:        network.LoadConfig(src),
+		Lifecycle:      lifecycle.LoadConfig(src),
+		Credentials:    credentials.LoadConfig(src),
+		Clock:          clock.LoadConfig(src),
+		Mirror:         mirror.LoadConfig(src),
+		Logging:        logging.LoadConfig(src),
+	}
+	cfg.src = src
+	if err := src.Err(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	return cfg, nil
+}
+
+func (c *Config) Addr() string {
+	return net.JoinHostPort(c.Host, c.Port)
+}
+
+func (c *Config) MetricsAddr() string {
+	return net.JoinHostPort(c.Host, c.MetricsPort)
+}
+
+func (c *Config) HTTP3Addr() string {
+	return net.JoinHostPort(c.Host, c.HTTP3Port)
+}
+
+func (c *Config) HTTPSAddr() string {
+	return net.JoinHostPort(c.Host, c.HTTPSPort)
+}
+
+// parseScopes parses comma-separated scopes into a slice of strings.
+// Empty values and surrounding whitespace are trimmed.
+func parseScopes(s string) []string {
+	scopes := strings.Split(s, ",")
+	result := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if trimmed := strings.TrimSpace(scope); trimmed != "" {
+			result = append(result, trimmed)
+		}
+	}
+	return result
+}
+
+// parseGrantTypes parses comma-separated grant types into a slice of strings.
+// Empty values and surrounding whitespace are trimmed.
+func parseGrantTypes(s string) []string {
+	grantTypes := strings.Split(s, ",")
+	result := make([]string, 0, len(grantTypes))
+	for _, grantType := range grantTypes {
+		if trimmed := strings.TrimSpace(grantType); trimmed != "" {
+			result = append(result, trimmed)
+		}
+	}
+	return result
+}
+
+func (c *Config) AdminAddr() string {
+	return net.JoinHostPort(c.Host, c.Admin.Port)
+}
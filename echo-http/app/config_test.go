@@ -0,0 +1,64 @@
+package app
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseScopes(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{
+			name:     "single scope",
+			input:    "openid",
+			expected: []string{"openid"},
+		},
+		{
+			name:     "multiple scopes",
+			input:    "openid,profile,email",
+			expected: []string{"openid", "profile", "email"},
+		},
+		{
+			name:     "scopes with spaces",
+			input:    " openid , profile , email ",
+			expected: []string{"openid", "profile", "email"},
+		},
+		{
+			name:     "empty string",
+			input:    "",
+			expected: []string{},
+		},
+		{
+			name:     "single scope with spaces",
+			input:    "  openid  ",
+			expected: []string{"openid"},
+		},
+		{
+			name:     "multiple scopes with extra commas",
+			input:    "openid,,profile,,email",
+			expected: []string{"openid", "profile", "email"},
+		},
+		{
+			name:     "trailing comma",
+			input:    "openid,profile,",
+			expected: []string{"openid", "profile"},
+		},
+		{
+			name:     "leading comma",
+			input:    ",openid,profile",
+			expected: []string{"openid", "profile"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := parseScopes(tt.input)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("parseScopes(%q) = %v, want %v", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package app
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/gorilla/websocket"
+
+	"github.com/probitas-test/echo-servers/echo-graphql/graph"
+	"github.com/probitas-test/echo-servers/echo-graphql/graph/model"
+)
+
+// newGraphQLHandler serves the echo-graphql schema with its default
+// transports (GET, POST, multipart, SSE and WebSocket) and introspection,
+// for single-container deployments. The optional echo-graphql features
+// (APQ, batching, persisted queries, limits) are left to the standalone
+// server.
+func newGraphQLHandler() (http.Handler, *graph.Resolver) {
+	resolver := graph.NewResolver()
+	srv := handler.New(graph.NewExecutableSchema(graph.Config{
+		Resolvers:  resolver,
+		Directives: graph.NewDirectiveRoot(),
+		Complexity: graph.NewComplexityRoot(),
+	}))
+
+	srv.AddTransport(transport.Options{})
+	srv.AddTransport(transport.GET{})
+	// multipart/mixed and SSE must precede POST, which also accepts their
+	// application/json requests
+	srv.AddTransport(transport.MultipartMixed{})
+	srv.AddTransport(transport.SSE{})
+	srv.AddTransport(transport.POST{})
+	srv.AddTransport(transport.MultipartForm{})
+	srv.AddTransport(transport.Websocket{
+		Upgrader: websocket.Upgrader{
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+	})
+
+	srv.Use(extension.Introspection{})
+	// Unlimited, registered so the queryCost field can report the cost
+	srv.Use(graph.NewComplexityLimit(0))
+	srv.Use(graph.DepthLimit{})
+
+	// The resolvers echoing headers read the request from the context
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), model.RequestKey, r)
+		srv.ServeHTTP(w, r.WithContext(ctx))
+	}), resolver
+}
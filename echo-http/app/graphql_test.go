@@ -0,0 +1,57 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGraphQLHandler(t *testing.T) {
+	graphql, _ := newGraphQLHandler()
+
+	tests := []struct {
+		name     string
+		method   string
+		target   string
+		body     string
+		wantBody string
+	}{
+		{
+			name:     "POST",
+			method:   http.MethodPost,
+			target:   "/graphql",
+			body:     `{"query":"{ echo(message: \"hello\") }"}`,
+			wantBody: `{"data":{"echo":"hello"}}`,
+		},
+		{
+			name:     "GET",
+			method:   http.MethodGet,
+			target:   "/graphql?query=%7B%20echo(message%3A%20%22hi%22)%20%7D",
+			wantBody: `{"data":{"echo":"hi"}}`,
+		},
+		{
+			name:     "request headers",
+			method:   http.MethodPost,
+			target:   "/graphql",
+			body:     `{"query":"{ echoHeaders { custom(name: \"X-Test\") } }"}`,
+			wantBody: `{"data":{"echoHeaders":{"custom":"value"}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Test", "value")
+			rec := httptest.NewRecorder()
+			graphql.ServeHTTP(rec, req)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+			}
+			if got := strings.TrimSpace(rec.Body.String()); got != tt.wantBody {
+				t.Errorf("expected %s, got %s", tt.wantBody, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package app
+
+import (
+	"crypto/tls"
+	"net/http"
+
+	"github.com/quic-go/quic-go/http3"
+	"github.com/quic-go/webtransport-go"
+
+	"github.com/probitas-test/echo-servers/echo-http/handlers"
+)
+
+// webTransportEchoPath is the WebTransport echo endpoint, served on HTTP/3
+// only
+const webTransportEchoPath = "/webtransport/echo"
+
+// newHTTP3Server serves handler over HTTP/3 with tlsConfig, plus the
+// WebTransport echo endpoint. The WebTransport session bypasses handler,
+// whose middleware would hide the HTTP/3 stream from the upgrade.
+func newHTTP3Server(cfg *Config, tlsConfig *tls.Config, handler http.Handler) *webtransport.Server {
+	server := &webtransport.Server{
+		H3: &http3.Server{
+			Addr:      cfg.HTTP3Addr(),
+			TLSConfig: http3.ConfigureTLSConfig(tlsConfig),
+		},
+		CheckOrigin: func(r *http.Request) bool { return true },
+	}
+	webTransport := handlers.WebTransportEchoHandler(server)
+	server.H3.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodConnect && r.URL.Path == webTransportEchoPath {
+			webTransport(w, r)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+	webtransport.ConfigureHTTP3Server(server.H3)
+	return server
+}
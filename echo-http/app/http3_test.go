@@ -0,0 +1,116 @@
+package app
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/http3"
+	"github.com/quic-go/webtransport-go"
+)
+
+// startHTTP3 serves a router with a /get route over HTTP/3 on a random port
+func startHTTP3(t *testing.T) string {
+	t.Helper()
+	r := chi.NewRouter()
+	r.Use(middleware.Logger)
+	r.Get("/get", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Proto))
+	})
+
+	cfg := &Config{Host: "127.0.0.1", HTTP3Port: "0"}
+	tlsConfig, err := loadTLSConfig(cfg, testCA(t))
+	if err != nil {
+		t.Fatalf("failed to load certificate: %v", err)
+	}
+	server := newHTTP3Server(cfg, tlsConfig, r)
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	go func() { _ = server.Serve(conn) }()
+	t.Cleanup(func() { _ = server.Close() })
+	return conn.LocalAddr().String()
+}
+
+func testContext(t *testing.T) context.Context {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+func TestHTTP3Routes(t *testing.T) {
+	addr := startHTTP3(t)
+	transport := &http3.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+	t.Cleanup(func() { _ = transport.Close() })
+
+	resp, err := (&http.Client{Transport: transport}).Get("https://" + addr + "/get")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(body) != "HTTP/3.0" {
+		t.Errorf("unexpected response %d %q", resp.StatusCode, body)
+	}
+}
+
+func TestWebTransportEcho(t *testing.T) {
+	addr := startHTTP3(t)
+	ctx := testContext(t)
+	dialer := &webtransport.Dialer{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true, NextProtos: []string{http3.NextProtoH3}},
+		QUICConfig:      &quic.Config{EnableDatagrams: true, EnableStreamResetPartialDelivery: true},
+	}
+	t.Cleanup(func() { _ = dialer.Close() })
+	resp, session, err := dialer.Dial(ctx, "https://"+addr+webTransportEchoPath, nil)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status %d", resp.StatusCode)
+	}
+	t.Cleanup(func() { _ = session.CloseWithError(0, "") })
+
+	// Bidirectional stream
+	stream, err := session.OpenStreamSync(ctx)
+	if err != nil {
+		t.Fatalf("failed to open stream: %v", err)
+	}
+	_, _ = stream.Write([]byte("hello"))
+	_ = stream.Close()
+	if data, err := io.ReadAll(stream); err != nil || string(data) != "hello" {
+		t.Errorf("unexpected stream echo %q (%v)", data, err)
+	}
+
+	// Unidirectional stream, echoed on a server stream
+	uni, err := session.OpenUniStreamSync(ctx)
+	if err != nil {
+		t.Fatalf("failed to open uni stream: %v", err)
+	}
+	_, _ = uni.Write([]byte("one way"))
+	_ = uni.Close()
+	reply, err := session.AcceptUniStream(ctx)
+	if err != nil {
+		t.Fatalf("no reply stream: %v", err)
+	}
+	if data, err := io.ReadAll(reply); err != nil || string(data) != "one way" {
+		t.Errorf("unexpected uni stream echo %q (%v)", data, err)
+	}
+
+	// Datagrams
+	if err := session.SendDatagram([]byte("dgram")); err != nil {
+		t.Fatalf("failed to send datagram: %v", err)
+	}
+	if data, err := session.ReceiveDatagram(ctx); err != nil || string(data) != "dgram" {
+		t.Errorf("unexpected datagram echo %q (%v)", data, err)
+	}
+}
@@ -0,0 +1,40 @@
+package app
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+
+	"github.com/probitas-test/echo-servers/shared/certs"
+)
+
+// loadTLSConfig returns the TLS configuration of the HTTPS and HTTP/3
+// listeners: the configured certificate files, or the certificates issued
+// by ca otherwise
+func loadTLSConfig(cfg *Config, ca *certs.Manager) (*tls.Config, error) {
+	if cfg.TLSCertFile != "" || cfg.TLSKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.TLSCertFile, cfg.TLSKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		return &tls.Config{Certificates: []tls.Certificate{cert}}, nil
+	}
+	return ca.TLSConfig(), nil
+}
+
+// certificateHash returns the SHA-256 hash of the server certificate
+// presented for the configured hosts, which browsers accept in
+// serverCertificateHashes instead of a trusted chain
+func certificateHash(tlsConfig *tls.Config) (string, error) {
+	cert := &tls.Certificate{}
+	if len(tlsConfig.Certificates) > 0 {
+		cert = &tlsConfig.Certificates[0]
+	} else {
+		var err error
+		if cert, err = tlsConfig.GetCertificate(&tls.ClientHelloInfo{}); err != nil {
+			return "", err
+		}
+	}
+	sum := sha256.Sum256(cert.Certificate[0])
+	return hex.EncodeToString(sum[:]), nil
+}
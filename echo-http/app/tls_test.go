@@ -0,0 +1,71 @@
+package app
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/probitas-test/echo-servers/shared/certs"
+)
+
+// testCA returns a local CA issuing certificates for localhost
+func testCA(t *testing.T) *certs.Manager {
+	t.Helper()
+	ca, err := certs.New(certs.Config{Hosts: []string{"localhost", "127.0.0.1"}, Mode: certs.ModeValid, Validity: time.Hour})
+	if err != nil {
+		t.Fatalf("failed to create CA: %v", err)
+	}
+	return ca
+}
+
+func TestLoadTLSConfig(t *testing.T) {
+	tlsConfig, err := loadTLSConfig(&Config{}, testCA(t))
+	if err != nil {
+		t.Fatalf("failed to issue certificate: %v", err)
+	}
+	if hash, err := certificateHash(tlsConfig); err != nil || len(hash) != 64 {
+		t.Errorf("unexpected certificate hash %q (%v)", hash, err)
+	}
+	if _, err := loadTLSConfig(&Config{TLSCertFile: "missing.pem", TLSKeyFile: "missing.key"}, nil); err == nil {
+		t.Error("expected an error for missing files")
+	}
+}
+
+func TestHTTPS(t *testing.T) {
+	ca := testCA(t)
+	tlsConfig, err := loadTLSConfig(&Config{}, ca)
+	if err != nil {
+		t.Fatalf("failed to issue certificate: %v", err)
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	server := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(r.Proto))
+		}),
+		TLSConfig: tlsConfig,
+	}
+	go func() { _ = server.ServeTLS(l, "", "") }()
+	defer server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(ca.CAPEM())
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig:   &tls.Config{RootCAs: roots},
+		ForceAttemptHTTP2: true,
+	}}
+	resp, err := client.Get("https://" + l.Addr().String())
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if body, _ := io.ReadAll(resp.Body); string(body) != "HTTP/2.0" {
+		t.Errorf("unexpected protocol %q", body)
+	}
+}
@@ -17,11 +17,15 @@ type AnythingResponse struct {
 	JSON    any               `json:"json,omitempty"`
 	Form    map[string]string `json:"form,omitempty"`
 	Files   map[string]string `json:"files,omitempty"`
+	// StoredID is the ID of the request stored with ?store=true, served at
+	// /anything/stored/{id}
+	StoredID string `json:"stored_id,omitempty"`
 }
 
 // AnythingHandler echoes any request information.
 // ANY /anything - Echo any request (method, headers, body, etc.)
 // ANY /anything/{path} - Echo any request with path
+// ANY /anything?store=true&store_id={id}&store_ttl={seconds} - Echo and store the request
 func AnythingHandler(w http.ResponseWriter, r *http.Request) {
 	response := AnythingResponse{
 		Method:  r.Method,
@@ -90,6 +94,10 @@ func AnythingHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if r.URL.Query().Get("store") == "true" && !storeAnything(w, r, &response) {
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(response)
 }
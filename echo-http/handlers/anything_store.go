@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// StoredIDHeader carries the ID of a request stored with ?store=true
+const StoredIDHeader = "X-Stored-Id"
+
+const (
+	defaultAnythingStoreTTL        = 300
+	defaultAnythingStoreMaxEntries = 1000
+	maxAnythingStoreTTL            = 86400
+)
+
+// storedIDPattern restricts the IDs chosen with ?store_id= to URL path
+// segments
+var storedIDPattern = regexp.MustCompile(`^[A-Za-z0-9._~-]{1,128}$`)
+
+// StoredRequest is a request echoed by /anything?store=true
+type StoredRequest struct {
+	ID        string           `json:"id"`
+	StoredAt  time.Time        `json:"stored_at"`
+	ExpiresAt time.Time        `json:"expires_at"`
+	Request   AnythingResponse `json:"request"`
+}
+
+// RequestStore keeps the requests stored by /anything until they expire,
+// evicting the oldest ones beyond maxEntries
+type RequestStore struct {
+	mu       sync.Mutex
+	requests map[string]*StoredRequest
+}
+
+// DefaultRequestStore is the store of /anything?store=true
+var DefaultRequestStore = NewRequestStore()
+
+// NewRequestStore creates an empty request store
+func NewRequestStore() *RequestStore {
+	return &RequestStore{requests: make(map[string]*StoredRequest)}
+}
+
+// Put stores request under id, replacing any request with the same ID
+func (s *RequestStore) Put(id string, request AnythingResponse, ttl time.Duration, maxEntries int) *StoredRequest {
+	now := currentClock().Now()
+	stored := &StoredRequest{ID: id, StoredAt: now, ExpiresAt: now.Add(ttl), Request: request}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.requests, id)
+	s.prune(now, maxEntries-1)
+	s.requests[id] = stored
+	return stored
+}
+
+// Get returns the request stored under id unless it expired
+func (s *RequestStore) Get(id string) (*StoredRequest, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, ok := s.requests[id]
+	if !ok || !currentClock().Now().Before(stored.ExpiresAt) {
+		return nil, false
+	}
+	return stored, true
+}
+
+// Len returns the number of stored requests, expired ones included until
+// the next Put
+func (s *RequestStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.requests)
+}
+
+// Clear removes every stored request and returns how many there were
+func (s *RequestStore) Clear() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := len(s.requests)
+	clear(s.requests)
+	return n
+}
+
+// prune removes the expired requests, then the oldest ones until at most
+// keep remain
+func (s *RequestStore) prune(now time.Time, keep int) {
+	for id, stored := range s.requests {
+		if !now.Before(stored.ExpiresAt) {
+			delete(s.requests, id)
+		}
+	}
+	for len(s.requests) > max(keep, 0) {
+		var oldest *StoredRequest
+		for _, stored := range s.requests {
+			if oldest == nil || stored.StoredAt.Before(oldest.StoredAt) {
+				oldest = stored
+			}
+		}
+		delete(s.requests, oldest.ID)
+	}
+}
+
+// storeAnything stores the echoed request of ?store=true, under
+// ?store_id= or a random ID, for ?store_ttl= seconds or the configured TTL
+func storeAnything(w http.ResponseWriter, r *http.Request, response *AnythingResponse) bool {
+	query := r.URL.Query()
+	ttl, maxEntries := defaultAnythingStoreTTL, defaultAnythingStoreMaxEntries
+	if globalConfig != nil {
+		ttl, maxEntries = globalConfig.AnythingStoreTTL, globalConfig.AnythingStoreMaxEntries
+	}
+	if s := query.Get("store_ttl"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 || n > maxAnythingStoreTTL {
+			http.Error(w, fmt.Sprintf("Invalid store_ttl (must be 1-%d)", maxAnythingStoreTTL), http.StatusBadRequest)
+			return false
+		}
+		ttl = n
+	}
+	id := query.Get("store_id")
+	if id == "" {
+		random, err := generateRandomString(16)
+		if err != nil {
+			http.Error(w, "Failed to generate ID", http.StatusInternalServerError)
+			return false
+		}
+		id = random
+	} else if !storedIDPattern.MatchString(id) {
+		http.Error(w, "Invalid store_id (must be 1-128 letters, digits, '.', '_', '~' or '-')", http.StatusBadRequest)
+		return false
+	}
+
+	response.StoredID = id
+	DefaultRequestStore.Put(id, *response, time.Duration(ttl)*time.Second, maxEntries)
+	w.Header().Set(StoredIDHeader, id)
+	return true
+}
+
+// StoredRequestHandler returns a request stored by /anything?store=true
+// until it expires; other methods are echoed as /anything.
+// GET /anything/stored/{id} - Get a stored request
+func StoredRequestHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		AnythingHandler(w, r)
+		return
+	}
+
+	stored, ok := DefaultRequestStore.Get(chi.URLParam(r, "id"))
+	if !ok {
+		http.Error(w, "Stored request not found or expired", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(stored)
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/probitas-test/echo-servers/shared/clock"
+)
+
+func newStoreRouter() *chi.Mux {
+	r := chi.NewRouter()
+	r.HandleFunc("/anything", AnythingHandler)
+	r.HandleFunc("/anything/stored/{id}", StoredRequestHandler)
+	r.HandleFunc("/anything/*", AnythingHandler)
+	return r
+}
+
+func TestStoredRequestHandler(t *testing.T) {
+	originalConfig := globalConfig
+	defer func() { globalConfig = originalConfig }()
+	clk := clock.New(clock.Config{})
+	globalConfig = &Config{Clock: clk, AnythingStoreTTL: 60, AnythingStoreMaxEntries: 10}
+	DefaultRequestStore.Clear()
+	r := newStoreRouter()
+
+	req := httptest.NewRequest(http.MethodPost, "/anything/hook?store=true", strings.NewReader(`{"event":"created"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	id := rec.Header().Get(StoredIDHeader)
+	var echoed AnythingResponse
+	if err := json.NewDecoder(rec.Body).Decode(&echoed); err != nil {
+		t.Fatal(err)
+	}
+	if id == "" || echoed.StoredID != id {
+		t.Fatalf("expected the stored ID in the header and body, got %q and %q", id, echoed.StoredID)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/anything/stored/"+id, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	var stored StoredRequest
+	if err := json.NewDecoder(rec.Body).Decode(&stored); err != nil {
+		t.Fatal(err)
+	}
+	if stored.ID != id || stored.Request.Method != http.MethodPost || stored.Request.Data != `{"event":"created"}` {
+		t.Errorf("unexpected stored request %+v", stored)
+	}
+	if got := stored.ExpiresAt.Sub(stored.StoredAt); got != time.Minute {
+		t.Errorf("expected a TTL of 1m, got %s", got)
+	}
+
+	clk.Advance(time.Minute)
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/anything/stored/"+id, nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 after the TTL, got %d", rec.Code)
+	}
+}
+
+func TestStoredRequestHandler_StoreParameters(t *testing.T) {
+	originalConfig := globalConfig
+	defer func() { globalConfig = originalConfig }()
+	globalConfig = &Config{AnythingStoreTTL: 60, AnythingStoreMaxEntries: 10}
+	DefaultRequestStore.Clear()
+	r := newStoreRouter()
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantID     string
+	}{
+		{name: "chosen ID", query: "store=true&store_id=order-42&store_ttl=5", wantStatus: http.StatusOK, wantID: "order-42"},
+		{name: "invalid ID", query: "store=true&store_id=a/b", wantStatus: http.StatusBadRequest},
+		{name: "invalid TTL", query: "store=true&store_ttl=0", wantStatus: http.StatusBadRequest},
+		{name: "not stored", query: "store=false&store_id=other", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/anything?"+tt.query, nil))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if got := rec.Header().Get(StoredIDHeader); got != tt.wantID {
+				t.Errorf("expected stored ID %q, got %q", tt.wantID, got)
+			}
+		})
+	}
+	if _, ok := DefaultRequestStore.Get("other"); ok {
+		t.Error("expected the request without store=true not to be stored")
+	}
+}
+
+func TestRequestStore_MaxEntries(t *testing.T) {
+	originalConfig := globalConfig
+	defer func() { globalConfig = originalConfig }()
+	clk := clock.New(clock.Config{})
+	globalConfig = &Config{Clock: clk}
+
+	store := NewRequestStore()
+	for _, id := range []string{"a", "b", "c"} {
+		store.Put(id, AnythingResponse{}, time.Minute, 2)
+		clk.Advance(time.Second)
+	}
+	if _, ok := store.Get("a"); ok {
+		t.Error("expected the oldest request to be evicted")
+	}
+	if _, ok := store.Get("c"); !ok || store.Len() != 2 {
+		t.Errorf("expected the 2 newest requests, got %d", store.Len())
+	}
+}
@@ -0,0 +1,414 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/probitas-test/echo-servers/shared/ratelimit"
+)
+
+// APIKeysPath is where the admin API of the API keys is served
+const APIKeysPath = "/apikeys"
+
+// Headers of the requests signed with the secret of their API key, and of
+// the responses naming the key
+const (
+	SignatureHeader          = "X-Signature"
+	SignatureTimestampHeader = "X-Signature-Timestamp"
+	APIKeyNameHeader         = "X-Api-Key-Name"
+)
+
+// Scopes of an API key: read allows GET, HEAD and OPTIONS, write the other
+// methods
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+	ScopeAll   = "*"
+)
+
+// maxSignedBodySize limits the body read to verify a signature
+const maxSignedBodySize = 10 << 20
+
+// errSignedBodyTooLarge is returned for a signed body over maxSignedBodySize
+var errSignedBodyTooLarge = fmt.Errorf("request body over %d bytes cannot be verified", maxSignedBodySize)
+
+// APIKeysConfig configures the API keys loaded at startup
+type APIKeysConfig struct {
+	// Keys are "key" or "key=scope+scope" entries
+	Keys []string
+	// File is a YAML file of APIKeySet, for secrets, validity and rate
+	// limits
+	File string
+	// Header carries the API key of a request
+	Header string
+	// SignatureSkew is how far the timestamp of a signed request may be
+	// from the server time
+	SignatureSkew time.Duration
+}
+
+// String describes the keys for the startup log
+func (c APIKeysConfig) String() string {
+	if len(c.Keys) == 0 && c.File == "" {
+		return "none"
+	}
+	s := fmt.Sprintf("keys=%d", len(c.Keys))
+	if c.File != "" {
+		s += " file=" + c.File
+	}
+	return fmt.Sprintf("%s header=%s signature_skew=%s", s, c.Header, c.SignatureSkew)
+}
+
+// APIKey is a key accepted in the API key header
+type APIKey struct {
+	Key  string `json:"key"`
+	Name string `json:"name,omitempty"`
+	// Scopes are read, write or *, every scope when empty
+	Scopes []string `json:"scopes,omitempty"`
+	// Secret requires the requests to be signed with HMAC-SHA256
+	Secret string `json:"secret,omitempty"`
+	// NotBefore and ExpiresAt bound the validity of the key, so rotations
+	// with overlapping keys can be rehearsed
+	NotBefore *time.Time `json:"not_before,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// RateLimit throttles the requests of the key when set, counting them
+	// together whatever its scope
+	RateLimit *ratelimit.Config `json:"rate_limit,omitempty"`
+}
+
+// APIKeySet is the list of accepted keys; every request needs one of them
+// unless the list is empty
+type APIKeySet struct {
+	Keys []APIKey `json:"keys"`
+}
+
+// apiKey is a key ready to check requests
+type apiKey struct {
+	APIKey
+	limiter *ratelimit.Limiter
+}
+
+func compileAPIKeys(set APIKeySet) (map[string]*apiKey, error) {
+	keys := make(map[string]*apiKey, len(set.Keys))
+	for i, k := range set.Keys {
+		switch {
+		case k.Key == "":
+			return nil, fmt.Errorf("key %d: key is required", i)
+		case keys[k.Key] != nil:
+			return nil, fmt.Errorf("key %d: duplicate key", i)
+		}
+		for _, scope := range k.Scopes {
+			if scope != ScopeRead && scope != ScopeWrite && scope != ScopeAll {
+				return nil, fmt.Errorf("key %d: invalid scope %q (want read, write or *)", i, scope)
+			}
+		}
+		c := &apiKey{APIKey: k}
+		if k.RateLimit != nil {
+			limits := *k.RateLimit
+			limits.Enabled = true
+			limits.Scope = ratelimit.ScopeGlobal
+			var err error
+			if c.limiter, err = ratelimit.New(limits); err != nil {
+				return nil, fmt.Errorf("key %d: invalid rate limit: %w", i, err)
+			}
+		}
+		keys[k.Key] = c
+	}
+	return keys, nil
+}
+
+// allows reports whether the scopes of k allow method
+func (k *apiKey) allows(method string) bool {
+	if len(k.Scopes) == 0 || slices.Contains(k.Scopes, ScopeAll) {
+		return true
+	}
+	return slices.Contains(k.Scopes, requiredScope(method))
+}
+
+// requiredScope returns the scope of the requests with method
+func requiredScope(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return ScopeRead
+	}
+	return ScopeWrite
+}
+
+// LoadAPIKeysFile reads the keys of the YAML file path, rejecting unknown
+// fields. Fields are named as in the admin API.
+func LoadAPIKeysFile(path string) (APIKeySet, error) {
+	var set APIKeySet
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return set, err
+	}
+	var v any
+	if err := yaml.Unmarshal(b, &v); err != nil {
+		return set, fmt.Errorf("%s: %w", path, err)
+	}
+	b, err = json.Marshal(v)
+	if err != nil {
+		return set, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := decodeStrict(b, &set); err != nil {
+		return set, fmt.Errorf("%s: %w", path, err)
+	}
+	return set, nil
+}
+
+// decodeStrict decodes the JSON value b into v, rejecting unknown fields
+// and trailing data
+func decodeStrict(b []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if dec.More() {
+		return errors.New("unexpected data after JSON value")
+	}
+	return nil
+}
+
+// APIKeys protects every endpoint with keys replaced at runtime
+type APIKeys struct {
+	header  string
+	skew    time.Duration
+	initial APIKeySet
+
+	mu   sync.RWMutex
+	set  APIKeySet
+	keys map[string]*apiKey
+}
+
+// NewAPIKeys creates the keys of c.Keys, then those of c.File, if any
+func NewAPIKeys(c APIKeysConfig) (*APIKeys, error) {
+	if c.Header == "" {
+		return nil, errors.New("API key header is required")
+	}
+	set := APIKeySet{Keys: []APIKey{}}
+	for _, entry := range c.Keys {
+		key, scopes, _ := strings.Cut(entry, "=")
+		k := APIKey{Key: key}
+		if scopes != "" {
+			k.Scopes = strings.Split(scopes, "+")
+		}
+		set.Keys = append(set.Keys, k)
+	}
+	if c.File != "" {
+		file, err := LoadAPIKeysFile(c.File)
+		if err != nil {
+			return nil, err
+		}
+		set.Keys = append(set.Keys, file.Keys...)
+	}
+	k := &APIKeys{header: c.Header, skew: c.SignatureSkew, initial: set}
+	if err := k.SetKeys(set); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// Keys returns the keys in effect
+func (k *APIKeys) Keys() APIKeySet {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return APIKeySet{Keys: slices.Clone(k.set.Keys)}
+}
+
+// SetKeys replaces the keys, forgetting the requests counted by their rate
+// limits
+func (k *APIKeys) SetKeys(set APIKeySet) error {
+	keys, err := compileAPIKeys(set)
+	if err != nil {
+		return err
+	}
+	if set.Keys == nil {
+		set.Keys = []APIKey{}
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.set = set
+	k.keys = keys
+	return nil
+}
+
+// Reset restores the startup keys
+func (k *APIKeys) Reset() {
+	_ = k.SetKeys(k.initial)
+}
+
+// Clear forgets the requests counted by the rate limits of every key
+func (k *APIKeys) Clear() {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	for _, key := range k.keys {
+		if key.limiter != nil {
+			key.limiter.Clear()
+		}
+	}
+}
+
+// Middleware requires one of the keys in every request but the health
+// checks at /health while there are keys. Requests without a valid key
+// get 401 Unauthorized, with a valid key lacking the scope of their method
+// 403 Forbidden, and beyond the rate limit of their key 429 Too Many
+// Requests.
+func (k *APIKeys) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		k.mu.RLock()
+		keys := k.keys
+		k.mu.RUnlock()
+		if len(keys) == 0 || r.URL.Path == "/health" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		presented := r.Header.Get(k.header)
+		if presented == "" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf("ApiKey header=%q", k.header))
+			http.Error(w, "Missing API key", http.StatusUnauthorized)
+			return
+		}
+		key, ok := keys[presented]
+		if !ok {
+			http.Error(w, "Invalid API key", http.StatusUnauthorized)
+			return
+		}
+		now := currentClock().Now()
+		if key.NotBefore != nil && now.Before(*key.NotBefore) {
+			http.Error(w, "API key not yet valid", http.StatusUnauthorized)
+			return
+		}
+		if key.ExpiresAt != nil && !now.Before(*key.ExpiresAt) {
+			http.Error(w, "Expired API key", http.StatusUnauthorized)
+			return
+		}
+		if key.Secret != "" {
+			if err := k.verifySignature(r, key.Secret, now); err != nil {
+				status := http.StatusUnauthorized
+				if errors.Is(err, errSignedBodyTooLarge) {
+					status = http.StatusRequestEntityTooLarge
+				}
+				msg := err.Error()
+				http.Error(w, strings.ToUpper(msg[:1])+msg[1:], status)
+				return
+			}
+		}
+		if key.Name != "" {
+			w.Header().Set(APIKeyNameHeader, key.Name)
+		}
+		if !key.allows(r.Method) {
+			http.Error(w, fmt.Sprintf("API key lacks the %s scope", requiredScope(r.Method)), http.StatusForbidden)
+			return
+		}
+		if key.limiter != nil {
+			ratelimit.HTTP(key.limiter)(next).ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// verifySignature checks the HMAC-SHA256 with secret of the string to sign
+// of r, its timestamp, method, request URI and hex SHA-256 of its body
+// separated by newlines
+func (k *APIKeys) verifySignature(r *http.Request, secret string, now time.Time) error {
+	signature := r.Header.Get(SignatureHeader)
+	timestamp := r.Header.Get(SignatureTimestampHeader)
+	if signature == "" || timestamp == "" {
+		return fmt.Errorf("missing %s or %s", SignatureHeader, SignatureTimestampHeader)
+	}
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid %s (must be Unix seconds)", SignatureTimestampHeader)
+	}
+	if skew := now.Sub(time.Unix(seconds, 0)).Abs(); skew > k.skew {
+		return fmt.Errorf("signature timestamp %s off the server time", skew)
+	}
+	got, err := hex.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("invalid %s (must be hex)", SignatureHeader)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxSignedBodySize+1))
+	if err != nil {
+		return fmt.Errorf("failed to read body: %w", err)
+	}
+	if len(body) > maxSignedBodySize {
+		return errSignedBodyTooLarge
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if !hmac.Equal(got, Signature(secret, timestamp, r.Method, r.RequestURI, body)) {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+// Signature returns the HMAC-SHA256 with secret of a request with method,
+// request URI and body signed at timestamp
+func Signature(secret, timestamp, method, requestURI string, body []byte) []byte {
+	sum := sha256.Sum256(body)
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = fmt.Fprintf(mac, "%s\n%s\n%s\n%s", timestamp, method, requestURI, hex.EncodeToString(sum[:]))
+	return mac.Sum(nil)
+}
+
+// Handler serves the admin API of k:
+//
+//	GET    /apikeys  current keys
+//	PUT    /apikeys  replace the keys
+//	DELETE /apikeys  restore the startup keys
+//
+// Changing the keys forgets the requests counted by their rate limits.
+func (k *APIKeys) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+		case http.MethodPut:
+			var set APIKeySet
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 1<<20))
+			if err == nil {
+				err = decodeStrict(body, &set)
+			}
+			if err == nil {
+				err = k.SetKeys(set)
+			}
+			if err != nil {
+				writeAdminJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+				return
+			}
+		case http.MethodDelete:
+			k.Reset()
+		default:
+			w.Header().Set("Allow", "GET, HEAD, PUT, DELETE")
+			writeAdminJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+		writeAdminJSON(w, http.StatusOK, k.Keys())
+	})
+}
+
+// writeAdminJSON writes v as indented JSON with status, like the shared
+// admin APIs
+func writeAdminJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
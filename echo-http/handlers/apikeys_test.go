@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/probitas-test/echo-servers/shared/clock"
+	"github.com/probitas-test/echo-servers/shared/ratelimit"
+)
+
+func newAPIKeysHandler(t *testing.T, keys *APIKeys) http.Handler {
+	t.Helper()
+	return keys.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func TestAPIKeys_Middleware(t *testing.T) {
+	originalConfig := globalConfig
+	defer func() { globalConfig = originalConfig }()
+	clk := clock.New(clock.Config{})
+	globalConfig = &Config{Clock: clk}
+	now := clk.Now()
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+
+	keys, err := NewAPIKeys(APIKeysConfig{Keys: []string{"admin", "reader=read"}, Header: "X-Api-Key"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	set := keys.Keys()
+	set.Keys = append(set.Keys,
+		APIKey{Key: "old", Name: "old-key", ExpiresAt: &past},
+		APIKey{Key: "next", NotBefore: &future},
+	)
+	if err := keys.SetKeys(set); err != nil {
+		t.Fatal(err)
+	}
+	handler := newAPIKeysHandler(t, keys)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		key        string
+		wantStatus int
+	}{
+		{name: "missing key", method: http.MethodGet, path: "/get", wantStatus: http.StatusUnauthorized},
+		{name: "unknown key", method: http.MethodGet, path: "/get", key: "nope", wantStatus: http.StatusUnauthorized},
+		{name: "expired key", method: http.MethodGet, path: "/get", key: "old", wantStatus: http.StatusUnauthorized},
+		{name: "key not yet valid", method: http.MethodGet, path: "/get", key: "next", wantStatus: http.StatusUnauthorized},
+		{name: "all scopes", method: http.MethodPost, path: "/post", key: "admin", wantStatus: http.StatusOK},
+		{name: "read scope", method: http.MethodGet, path: "/get", key: "reader", wantStatus: http.StatusOK},
+		{name: "missing write scope", method: http.MethodPost, path: "/post", key: "reader", wantStatus: http.StatusForbidden},
+		{name: "health check", method: http.MethodGet, path: "/health", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.key != "" {
+				req.Header.Set("X-Api-Key", tt.key)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+		})
+	}
+
+	// Without keys every request is allowed
+	if err := keys.SetKeys(APIKeySet{}); err != nil {
+		t.Fatal(err)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/get", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200 without keys, got %d", rec.Code)
+	}
+}
+
+func TestAPIKeys_Signature(t *testing.T) {
+	keys, err := NewAPIKeys(APIKeysConfig{Header: "X-Api-Key", SignatureSkew: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := keys.SetKeys(APIKeySet{Keys: []APIKey{{Key: "signed", Secret: "s3cret"}}}); err != nil {
+		t.Fatal(err)
+	}
+	handler := newAPIKeysHandler(t, keys)
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	stale := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+	sign := func(timestamp, body string) string {
+		return hex.EncodeToString(Signature("s3cret", timestamp, http.MethodPost, "/post?a=1", []byte(body)))
+	}
+
+	tests := []struct {
+		name       string
+		timestamp  string
+		signature  string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "valid signature", timestamp: now, signature: sign(now, `{"a":1}`), wantStatus: http.StatusOK},
+		{name: "missing signature", timestamp: now, wantStatus: http.StatusUnauthorized},
+		{name: "other body", timestamp: now, signature: sign(now, `{"a":2}`), wantStatus: http.StatusUnauthorized, wantBody: "Invalid signature\n"},
+		{name: "stale timestamp", timestamp: stale, signature: sign(stale, `{"a":1}`), wantStatus: http.StatusUnauthorized},
+		{name: "not hex", timestamp: now, signature: "zz", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/post?a=1", strings.NewReader(`{"a":1}`))
+			req.Header.Set("X-Api-Key", "signed")
+			req.Header.Set(SignatureTimestampHeader, tt.timestamp)
+			if tt.signature != "" {
+				req.Header.Set(SignatureHeader, tt.signature)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if tt.wantBody != "" && rec.Body.String() != tt.wantBody {
+				t.Errorf("expected body %q, got %q", tt.wantBody, rec.Body.String())
+			}
+		})
+	}
+
+	// The whole body is signed, so one over the limit is rejected, not cut
+	body := strings.Repeat("a", maxSignedBodySize+1)
+	req := httptest.NewRequest(http.MethodPost, "/post?a=1", strings.NewReader(body))
+	req.Header.Set("X-Api-Key", "signed")
+	req.Header.Set(SignatureTimestampHeader, now)
+	req.Header.Set(SignatureHeader, sign(now, body))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected status 413 for a body over the limit, got %d", rec.Code)
+	}
+}
+
+func TestAPIKeys_RateLimit(t *testing.T) {
+	keys, err := NewAPIKeys(APIKeysConfig{Header: "X-Api-Key"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	limits := ratelimit.DefaultConfig()
+	limits.Limit = 2
+	limits.WindowMS = 60000
+	if err := keys.SetKeys(APIKeySet{Keys: []APIKey{{Key: "limited", RateLimit: &limits}, {Key: "other"}}}); err != nil {
+		t.Fatal(err)
+	}
+	handler := newAPIKeysHandler(t, keys)
+
+	var codes []int
+	for _, key := range []string{"limited", "limited", "limited", "other"} {
+		req := httptest.NewRequest(http.MethodGet, "/get", nil)
+		req.Header.Set("X-Api-Key", key)
+		req.RemoteAddr = "192.0.2.1:1234"
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		codes = append(codes, rec.Code)
+	}
+	want := []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests, http.StatusOK}
+	for i := range want {
+		if codes[i] != want[i] {
+			t.Fatalf("expected statuses %v, got %v", want, codes)
+		}
+	}
+}
+
+func TestAPIKeys_Handler(t *testing.T) {
+	keys, err := NewAPIKeys(APIKeysConfig{Keys: []string{"initial"}, Header: "X-Api-Key"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	admin := keys.Handler()
+
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		wantStatus int
+		wantKeys   int
+	}{
+		{name: "replace", method: http.MethodPut, body: `{"keys":[{"key":"a","scopes":["read"]},{"key":"b"}]}`, wantStatus: http.StatusOK, wantKeys: 2},
+		{name: "invalid scope", method: http.MethodPut, body: `{"keys":[{"key":"a","scopes":["admin"]}]}`, wantStatus: http.StatusBadRequest, wantKeys: 2},
+		{name: "unknown field", method: http.MethodPut, body: `{"keys":[{"key":"a","role":"x"}]}`, wantStatus: http.StatusBadRequest, wantKeys: 2},
+		{name: "reset", method: http.MethodDelete, wantStatus: http.StatusOK, wantKeys: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			admin.ServeHTTP(rec, httptest.NewRequest(tt.method, APIKeysPath, strings.NewReader(tt.body)))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if got := len(keys.Keys().Keys); got != tt.wantKeys {
+				t.Errorf("expected %d keys, got %d", tt.wantKeys, got)
+			}
+		})
+	}
+}
+
+func TestLoadAPIKeysFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.yaml")
+	content := `keys:
+  - key: partner
+    name: partner
+    scopes: [read, write]
+    secret: s3cret
+    expires_at: 2030-01-01T00:00:00Z
+    rate_limit:
+      limit: 5
+`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	keys, err := NewAPIKeys(APIKeysConfig{File: path, Header: "X-Api-Key"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := keys.Keys().Keys
+	if len(got) != 1 || got[0].Secret != "s3cret" || got[0].ExpiresAt == nil || got[0].RateLimit.Limit != 5 {
+		t.Errorf("unexpected keys %+v", got)
+	}
+}
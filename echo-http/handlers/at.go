@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// maxAtWait bounds how far ahead /at may hold a request
+const maxAtWait = 300 * time.Second
+
+// AtHandler holds the request until a wall-clock instant of the clock,
+// for coordinating clients in time-synchronized scenarios. Instants in the
+// past are answered at once.
+// ANY /at?time={RFC3339} - Respond at time
+func AtHandler(w http.ResponseWriter, r *http.Request) {
+	at, err := time.Parse(time.RFC3339Nano, r.URL.Query().Get("time"))
+	if err != nil {
+		http.Error(w, "Invalid time (must be RFC 3339, such as 2026-01-01T00:00:00Z)", http.StatusBadRequest)
+		return
+	}
+
+	clk := currentClock()
+	received := clk.Now()
+	if at.Sub(received) > maxAtWait {
+		http.Error(w, fmt.Sprintf("Time too far ahead (max %s)", maxAtWait), http.StatusBadRequest)
+		return
+	}
+	if clk.Sleep(r.Context(), clk.Until(at)) != nil {
+		return
+	}
+	responded := clk.Now()
+
+	response := map[string]any{
+		"time":         at,
+		"received_at":  received,
+		"responded_at": responded,
+		"waited_ms":    responded.Sub(received).Milliseconds(),
+		"drift_ms":     float64(responded.Sub(at).Microseconds()) / 1000,
+		"method":       r.Method,
+		"url":          r.URL.RequestURI(),
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
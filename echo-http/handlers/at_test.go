@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/probitas-test/echo-servers/shared/clock"
+)
+
+func TestAtHandler(t *testing.T) {
+	originalConfig := globalConfig
+	defer func() { globalConfig = originalConfig }()
+	clk := clock.New(clock.Config{})
+	globalConfig = &Config{Clock: clk}
+
+	at := clk.Now().Add(time.Minute).Truncate(time.Second)
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		rec := httptest.NewRecorder()
+		AtHandler(rec, httptest.NewRequest(http.MethodGet, "/at?time="+at.Format(time.RFC3339), nil))
+		done <- rec
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("expected the request to be held until the time")
+	case <-time.After(20 * time.Millisecond):
+	}
+	clk.Set(at)
+
+	rec := <-done
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Time        time.Time `json:"time"`
+		RespondedAt time.Time `json:"responded_at"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Time.Equal(at) || resp.RespondedAt.Before(at) {
+		t.Errorf("expected a response at %v, got %+v", at, resp)
+	}
+}
+
+func TestAtHandler_Past(t *testing.T) {
+	rec := httptest.NewRecorder()
+	AtHandler(rec, httptest.NewRequest(http.MethodGet, "/at?time=2020-01-01T00:00:00Z", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", rec.Code)
+	}
+}
+
+func TestAtHandler_InvalidTime(t *testing.T) {
+	tests := []string{
+		"/at",
+		"/at?time=tomorrow",
+		"/at?time=" + time.Now().Add(time.Hour).UTC().Format(time.RFC3339),
+	}
+
+	for _, path := range tests {
+		rec := httptest.NewRecorder()
+		AtHandler(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", path, rec.Code)
+		}
+	}
+}
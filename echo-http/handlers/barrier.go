@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	maxBarrierSize           = 1000
+	defaultBarrierTimeout    = 30
+	maxBarrierTimeoutSeconds = 300
+)
+
+// barrierNamePattern restricts barrier names to URL path segments
+var barrierNamePattern = regexp.MustCompile(`^[A-Za-z0-9._~-]{1,128}$`)
+
+// barrier holds the requests waiting on a name until size of them arrived
+type barrier struct {
+	size    int
+	waiting int
+	// done is closed when the barrier is released or reset
+	done       chan struct{}
+	reset      bool
+	releasedAt time.Time
+}
+
+// BarrierState is a barrier waiting for requests
+type BarrierState struct {
+	Size    int `json:"size"`
+	Waiting int `json:"waiting"`
+}
+
+// Barriers holds the requests of /barrier until enough of them arrived on
+// the same name, then releases them together. A released name starts a
+// new barrier with the next request.
+type Barriers struct {
+	mu       sync.Mutex
+	barriers map[string]*barrier
+}
+
+// DefaultBarriers are the barriers of /barrier
+var DefaultBarriers = NewBarriers()
+
+// NewBarriers creates an empty set of barriers
+func NewBarriers() *Barriers {
+	return &Barriers{barriers: make(map[string]*barrier)}
+}
+
+// arrive adds a request to the barrier name of size, releasing it when the
+// request is the last one, and returns the barrier and the arrival order
+// of the request
+func (b *Barriers) arrive(name string, size int) (*barrier, int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	br, ok := b.barriers[name]
+	if !ok {
+		br = &barrier{size: size, done: make(chan struct{})}
+		b.barriers[name] = br
+	}
+	if br.size != size {
+		return nil, 0, fmt.Errorf("barrier %s is waiting for %d requests", name, br.size)
+	}
+	br.waiting++
+	arrival := br.waiting
+	if br.waiting == br.size {
+		br.releasedAt = currentClock().Now()
+		delete(b.barriers, name)
+		close(br.done)
+	}
+	return br, arrival, nil
+}
+
+// leave removes a request that stopped waiting from the barrier name,
+// returning false when the barrier was released or reset meanwhile
+func (b *Barriers) leave(name string, br *barrier) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	select {
+	case <-br.done:
+		return false
+	default:
+	}
+	br.waiting--
+	if br.waiting == 0 {
+		delete(b.barriers, name)
+	}
+	return true
+}
+
+// Pending returns the barriers waiting for requests by name
+func (b *Barriers) Pending() map[string]BarrierState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	pending := make(map[string]BarrierState, len(b.barriers))
+	for name, br := range b.barriers {
+		pending[name] = BarrierState{Size: br.size, Waiting: br.waiting}
+	}
+	return pending
+}
+
+// Clear resets the waiting barriers, failing their requests, and returns
+// how many there were
+func (b *Barriers) Clear() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n := len(b.barriers)
+	for name, br := range b.barriers {
+		br.reset = true
+		close(br.done)
+		delete(b.barriers, name)
+	}
+	return n
+}
+
+// BarrierHandler blocks requests until n of them arrived on the same
+// barrier name, then releases them all at once, for race condition and
+// thundering herd tests.
+// ANY /barrier/{name}/{n}?timeout={seconds} - Wait for n requests on name
+func BarrierHandler(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	if !barrierNamePattern.MatchString(name) {
+		http.Error(w, "Invalid barrier name (1-128 letters, digits, '.', '_', '~' or '-')", http.StatusBadRequest)
+		return
+	}
+	size, err := strconv.Atoi(chi.URLParam(r, "n"))
+	if err != nil || size < 1 || size > maxBarrierSize {
+		http.Error(w, fmt.Sprintf("Invalid barrier size (must be 1-%d)", maxBarrierSize), http.StatusBadRequest)
+		return
+	}
+	timeout := defaultBarrierTimeout
+	if s := r.URL.Query().Get("timeout"); s != "" {
+		timeout, err = strconv.Atoi(s)
+		if err != nil || timeout < 1 || timeout > maxBarrierTimeoutSeconds {
+			http.Error(w, fmt.Sprintf("Invalid timeout (must be 1-%d seconds)", maxBarrierTimeoutSeconds), http.StatusBadRequest)
+			return
+		}
+	}
+
+	clk := currentClock()
+	arrivedAt := clk.Now()
+	br, arrival, err := DefaultBarriers.arrive(name, size)
+	if err != nil {
+		http.Error(w, "Conflicting barrier size: "+err.Error(), http.StatusConflict)
+		return
+	}
+
+	// The timeout follows the clock, so advancing it expires the barrier
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	deadline := arrivedAt.Add(time.Duration(timeout) * time.Second)
+	expired := make(chan struct{})
+	go func() {
+		if clk.Sleep(ctx, clk.Until(deadline)) == nil {
+			close(expired)
+		}
+	}()
+
+	select {
+	case <-br.done:
+	case <-expired:
+		if DefaultBarriers.leave(name, br) {
+			http.Error(w, fmt.Sprintf("Barrier timed out after %ds waiting for %d requests", timeout, size), http.StatusRequestTimeout)
+			return
+		}
+	case <-r.Context().Done():
+		DefaultBarriers.leave(name, br)
+		return
+	}
+	if br.reset {
+		http.Error(w, "Barrier reset", http.StatusConflict)
+		return
+	}
+
+	response := map[string]any{
+		"name":        name,
+		"size":        size,
+		"arrival":     arrival,
+		"waited_ms":   br.releasedAt.Sub(arrivedAt).Milliseconds(),
+		"released_at": br.releasedAt,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
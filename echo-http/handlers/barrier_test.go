@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/probitas-test/echo-servers/shared/clock"
+)
+
+func newBarrierRouter() *chi.Mux {
+	r := chi.NewRouter()
+	r.HandleFunc("/barrier/{name}/{n}", BarrierHandler)
+	return r
+}
+
+func TestBarrierHandler(t *testing.T) {
+	defer DefaultBarriers.Clear()
+	router := newBarrierRouter()
+
+	const n = 5
+	recs := make([]*httptest.ResponseRecorder, n)
+	var wg sync.WaitGroup
+	for i := range n {
+		recs[i] = httptest.NewRecorder()
+		wg.Go(func() {
+			router.ServeHTTP(recs[i], httptest.NewRequest(http.MethodPost, "/barrier/race/5", nil))
+		})
+	}
+	wg.Wait()
+
+	arrivals := map[int]bool{}
+	var releasedAt time.Time
+	for _, rec := range recs {
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		var resp struct {
+			Size       int       `json:"size"`
+			Arrival    int       `json:"arrival"`
+			ReleasedAt time.Time `json:"released_at"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		if resp.Size != n {
+			t.Errorf("expected size %d, got %d", n, resp.Size)
+		}
+		if !releasedAt.IsZero() && !resp.ReleasedAt.Equal(releasedAt) {
+			t.Errorf("expected one release time, got %v and %v", releasedAt, resp.ReleasedAt)
+		}
+		releasedAt = resp.ReleasedAt
+		arrivals[resp.Arrival] = true
+	}
+	if len(arrivals) != n {
+		t.Errorf("expected %d distinct arrivals, got %v", n, arrivals)
+	}
+	if pending := DefaultBarriers.Pending(); len(pending) != 0 {
+		t.Errorf("expected the released barrier to be removed, got %v", pending)
+	}
+}
+
+func TestBarrierHandler_Timeout(t *testing.T) {
+	originalConfig := globalConfig
+	defer func() { globalConfig = originalConfig }()
+	clk := clock.New(clock.Config{})
+	globalConfig = &Config{Clock: clk}
+	defer DefaultBarriers.Clear()
+	router := newBarrierRouter()
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/barrier/slow/2?timeout=10", nil))
+		done <- rec
+	}()
+
+	// A second request with another size conflicts with the waiting one
+	for len(DefaultBarriers.Pending()) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/barrier/slow/3", nil))
+	if rec.Code != http.StatusConflict {
+		t.Errorf("expected status 409 for another size, got %d", rec.Code)
+	}
+
+	clk.Advance(11 * time.Second)
+	if rec := <-done; rec.Code != http.StatusRequestTimeout {
+		t.Errorf("expected status 408, got %d", rec.Code)
+	}
+	if pending := DefaultBarriers.Pending(); len(pending) != 0 {
+		t.Errorf("expected the timed out barrier to be removed, got %v", pending)
+	}
+}
+
+func TestBarrierHandler_Reset(t *testing.T) {
+	router := newBarrierRouter()
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/barrier/reset/2", nil))
+		done <- rec
+	}()
+	for len(DefaultBarriers.Pending()) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if n := DefaultBarriers.Clear(); n != 1 {
+		t.Errorf("expected 1 cleared barrier, got %d", n)
+	}
+	if rec := <-done; rec.Code != http.StatusConflict {
+		t.Errorf("expected status 409, got %d", rec.Code)
+	}
+}
+
+func TestBarrierHandler_InvalidParameters(t *testing.T) {
+	tests := []string{
+		"/barrier/race/0",
+		"/barrier/race/1001",
+		"/barrier/race/x",
+		"/barrier/race/2?timeout=0",
+		"/barrier/race/2?timeout=301",
+	}
+
+	for _, path := range tests {
+		rec := httptest.NewRecorder()
+		newBarrierRouter().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", path, rec.Code)
+		}
+	}
+}
@@ -5,8 +5,26 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+
+	"github.com/probitas-test/echo-servers/shared/credentials"
 )
 
+// newCredentialStore returns a store of the user alice:secret with the
+// token alice-token
+func newCredentialStore(t *testing.T) *credentials.Store {
+	t.Helper()
+	store, err := credentials.New(credentials.Config{Users: []string{"alice:secret"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := store.Credentials()
+	c.Users[0].Tokens = []string{"alice-token"}
+	if err := store.Set(c); err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
 func TestBasicAuthEnvHandler(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -69,6 +87,28 @@ func TestBasicAuthEnvHandler(t *testing.T) {
 			expectedCode: http.StatusUnauthorized,
 			expectJSON:   false,
 		},
+		{
+			name:         "credential store user",
+			config:       &Config{Credentials: newCredentialStore(t)},
+			username:     "alice",
+			password:     "secret",
+			setAuth:      true,
+			expectedCode: http.StatusOK,
+			expectJSON:   true,
+		},
+		{
+			name: "credential store replaces allowed username",
+			config: &Config{
+				AuthAllowedUsername: "testuser",
+				AuthAllowedPassword: "testpass",
+				Credentials:         newCredentialStore(t),
+			},
+			username:     "testuser",
+			password:     "testpass",
+			setAuth:      true,
+			expectedCode: http.StatusUnauthorized,
+			expectJSON:   false,
+		},
 	}
 
 	for _, tt := range tests {
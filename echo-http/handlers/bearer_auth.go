@@ -12,7 +12,8 @@ import (
 
 // BearerAuthEnvHandler validates Bearer token authentication against environment variables.
 // The expected token is SHA1(username:password) where username and password are from
-// AUTH_ALLOWED_USERNAME and AUTH_ALLOWED_PASSWORD configuration.
+// AUTH_ALLOWED_USERNAME and AUTH_ALLOWED_PASSWORD configuration. With the shared credential
+// store, the tokens of its users and SHA1(username:password) of each of them are accepted.
 // GET /bearer-auth - Returns 200 if token matches, 401 otherwise
 func BearerAuthEnvHandler(w http.ResponseWriter, r *http.Request) {
 	authHeader := r.Header.Get("Authorization")
@@ -36,18 +37,7 @@ func BearerAuthEnvHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Check if credentials are configured
-	if globalConfig == nil || globalConfig.AuthAllowedUsername == "" || globalConfig.AuthAllowedPassword == "" {
-		w.Header().Set("WWW-Authenticate", `Bearer`)
-		writeBearerAuthError(w, r)
-		return
-	}
-
-	// Compute expected token as SHA1(username:password)
-	expectedToken := computeBearerToken(globalConfig.AuthAllowedUsername, globalConfig.AuthAllowedPassword)
-
-	// Constant-time comparison to prevent timing attacks
-	if subtle.ConstantTimeCompare([]byte(token), []byte(expectedToken)) != 1 {
+	if !validateBearerToken(token) {
 		w.Header().Set("WWW-Authenticate", `Bearer`)
 		writeBearerAuthError(w, r)
 		return
@@ -62,6 +52,27 @@ func BearerAuthEnvHandler(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(response)
 }
 
+// validateBearerToken checks token against the credential store: the tokens of its users, or
+// SHA1(username:password) of each of them. Without a store, the token must be SHA1 of
+// AUTH_ALLOWED_USERNAME:AUTH_ALLOWED_PASSWORD.
+func validateBearerToken(token string) bool {
+	if globalConfig != nil && globalConfig.Credentials != nil {
+		_, ok := globalConfig.Credentials.Bearer(token)
+		return ok
+	}
+
+	// Check if credentials are configured
+	if globalConfig == nil || globalConfig.AuthAllowedUsername == "" || globalConfig.AuthAllowedPassword == "" {
+		return false
+	}
+
+	// Compute expected token as SHA1(username:password)
+	expectedToken := computeBearerToken(globalConfig.AuthAllowedUsername, globalConfig.AuthAllowedPassword)
+
+	// Constant-time comparison to prevent timing attacks
+	return subtle.ConstantTimeCompare([]byte(token), []byte(expectedToken)) == 1
+}
+
 // writeBearerAuthError writes a 401 response with helpful curl examples.
 func writeBearerAuthError(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
@@ -4,7 +4,10 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+
+	"github.com/probitas-test/echo-servers/shared/credentials"
 )
 
 func TestBearerAuthEnvHandler(t *testing.T) {
@@ -80,6 +83,21 @@ func TestBearerAuthEnvHandler(t *testing.T) {
 			expectedCode: http.StatusUnauthorized,
 			expectJSON:   false,
 		},
+		{
+			name:   "credential store token",
+			config: &Config{Credentials: newCredentialStore(t)},
+			// SHA1("alice:secret")
+			authHeader:   "Bearer " + credentials.User{Username: "alice", Password: "secret"}.Token(),
+			expectedCode: http.StatusOK,
+			expectJSON:   true,
+		},
+		{
+			name:         "credential store configured token",
+			config:       &Config{Credentials: newCredentialStore(t)},
+			authHeader:   "Bearer alice-token",
+			expectedCode: http.StatusOK,
+			expectJSON:   true,
+		},
 		{
 			name: "wrong auth type",
 			config: &Config{
@@ -130,8 +148,8 @@ func TestBearerAuthEnvHandler(t *testing.T) {
 				if !resp.Authenticated {
 					t.Error("expected authenticated=true")
 				}
-				if resp.Token != "1eac13f1578ef493b9ed5617a5f4a31b271eb667" {
-					t.Errorf("expected token to be SHA1 hash, got %s", resp.Token)
+				if want := strings.Fields(tt.authHeader)[1]; resp.Token != want {
+					t.Errorf("expected token %s, got %s", want, resp.Token)
 				}
 			}
 		})
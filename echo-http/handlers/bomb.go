@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	defaultBombSize    = 1 << 30  // 1GB
+	defaultBombMaxSize = 10 << 30 // 10GB
+
+	// bombUnitSize is the run of zeros compressed once and repeated by the
+	// gzip and deflate bombs
+	bombUnitSize = 16 << 20
+)
+
+var (
+	bombZeros     = make([]byte, bombUnitSize)
+	bombUnitOnce  sync.Once
+	bombUnitBytes []byte
+
+	// gzipBombHeader is a gzip header of deflate data without flags or
+	// modification time, compressed at maximum level on an unknown OS
+	gzipBombHeader = []byte{0x1f, 0x8b, 8, 0, 0, 0, 0, 0, 2, 255}
+)
+
+// bombUnit returns bombUnitSize zeros as non-final deflate blocks that
+// only refer to their own output, so repeating them extends the stream
+func bombUnit() []byte {
+	bombUnitOnce.Do(func() {
+		bombUnitBytes = deflateZeros(bombUnitSize, false)
+	})
+	return bombUnitBytes
+}
+
+// deflateZeros compresses n zeros with a fresh writer, ending the stream
+// when final and otherwise byte-aligned with a sync flush
+func deflateZeros(n int, final bool) []byte {
+	var buf bytes.Buffer
+	fw, _ := flate.NewWriter(&buf, flate.BestCompression)
+	_, _ = fw.Write(bombZeros[:n])
+	if final {
+		_ = fw.Close()
+	} else {
+		_ = fw.Flush()
+	}
+	return buf.Bytes()
+}
+
+// BombHandler streams size zeros compressed with encoding, a tiny response
+// that decompresses to gigabytes, to validate client protections against
+// decompression bombs.
+// GET /bomb/{encoding}?size={bytes} - encoding is gzip, deflate or br
+func BombHandler(w http.ResponseWriter, r *http.Request) {
+	size, ok := bombSize(w, r.URL.Query().Get("size"), defaultBombSize)
+	if !ok {
+		return
+	}
+
+	encoding := chi.URLParam(r, "encoding")
+	switch encoding {
+	case "gzip", "deflate", "br":
+	default:
+		http.Error(w, "Invalid encoding (must be gzip, deflate or br)", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Encoding", encoding)
+	w.Header().Set("X-Decompressed-Size", strconv.Itoa(size))
+	if encoding == "br" {
+		_ = writeBrotliBomb(w, size)
+		return
+	}
+
+	units := size / bombUnitSize
+	tail := deflateZeros(size%bombUnitSize, true)
+	length := units*len(bombUnit()) + len(tail)
+	if encoding == "gzip" {
+		length += len(gzipBombHeader) + 8
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(length))
+	if encoding == "gzip" {
+		_ = writeGzipBomb(w, units, tail, size)
+	} else {
+		_ = writeDeflateBomb(w, units, tail, nil)
+	}
+}
+
+// writeDeflateBomb writes units repeated zero units and the final tail as
+// a raw deflate stream, adding the zeros to crc when not nil
+func writeDeflateBomb(w http.ResponseWriter, units int, tail []byte, crc *uint32) error {
+	for range units {
+		if _, err := w.Write(bombUnit()); err != nil {
+			return err
+		}
+		if crc != nil {
+			*crc = crc32.Update(*crc, crc32.IEEETable, bombZeros)
+		}
+	}
+	_, err := w.Write(tail)
+	return err
+}
+
+// writeGzipBomb writes the deflate bomb of size zeros as a single gzip
+// member
+func writeGzipBomb(w http.ResponseWriter, units int, tail []byte, size int) error {
+	if _, err := w.Write(gzipBombHeader); err != nil {
+		return err
+	}
+	crc := uint32(0)
+	if err := writeDeflateBomb(w, units, tail, &crc); err != nil {
+		return err
+	}
+	crc = crc32.Update(crc, crc32.IEEETable, bombZeros[:size%bombUnitSize])
+	trailer := binary.LittleEndian.AppendUint32(nil, crc)
+	trailer = binary.LittleEndian.AppendUint32(trailer, uint32(size))
+	_, err := w.Write(trailer)
+	return err
+}
+
+// writeBrotliBomb compresses size zeros as they are sent, with the fastest
+// quality that still shrinks them over a thousandfold
+func writeBrotliBomb(w http.ResponseWriter, size int) error {
+	bw := brotli.NewWriterLevel(w, 1)
+	for ; size > 0; size -= min(size, bombUnitSize) {
+		if _, err := bw.Write(bombZeros[:min(size, bombUnitSize)]); err != nil {
+			return err
+		}
+	}
+	return bw.Close()
+}
+
+// BombContentLengthHandler declares a Content-Length far beyond the body it
+// sends, then closes the connection, to validate clients that preallocate
+// or wait for the declared length.
+// GET /bomb/content-length?declared={bytes}&size={bytes}
+func BombContentLengthHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	declared, ok := bombSize(w, query.Get("declared"), defaultBombSize)
+	if !ok {
+		return
+	}
+	size := 1024
+	if s := query.Get("size"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 || n > maxBytesSize || n > declared {
+			http.Error(w, fmt.Sprintf("Invalid size (must be 0-%d and at most declared)", maxBytesSize), http.StatusBadRequest)
+			return
+		}
+		size = n
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Length", strconv.Itoa(declared))
+	// The server closes the connection after the short body
+	_, _ = w.Write(bytes.Repeat([]byte{'a'}, size))
+}
+
+// bombSize parses a size of 1 to the configured maximum, defaultValue
+// when empty; it writes the error response when invalid
+func bombSize(w http.ResponseWriter, s string, defaultValue int) (int, bool) {
+	limit := defaultBombMaxSize
+	if globalConfig != nil && globalConfig.BombMaxSize > 0 {
+		limit = globalConfig.BombMaxSize
+	}
+	if s == "" {
+		return min(defaultValue, limit), true
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 || n > limit {
+		http.Error(w, fmt.Sprintf("Invalid size (must be 1-%d)", limit), http.StatusBadRequest)
+		return 0, false
+	}
+	return n, true
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/go-chi/chi/v5"
+)
+
+func newBombRouter() *chi.Mux {
+	r := chi.NewRouter()
+	r.Get("/bomb/content-length", BombContentLengthHandler)
+	r.Get("/bomb/{encoding}", BombHandler)
+	return r
+}
+
+// countZeros reads r to the end, failing on any byte other than zero
+func countZeros(t *testing.T, r io.Reader) int {
+	t.Helper()
+	buf := make([]byte, 1<<20)
+	total := 0
+	for {
+		n, err := r.Read(buf)
+		for _, b := range buf[:n] {
+			if b != 0 {
+				t.Fatalf("unexpected byte %d at offset %d", b, total)
+			}
+		}
+		total += n
+		if err == io.EOF {
+			return total
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestBombHandler(t *testing.T) {
+	size := 2*bombUnitSize + 12345
+
+	tests := []struct {
+		encoding string
+		decode   func(io.Reader) (io.Reader, error)
+	}{
+		{encoding: "gzip", decode: func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) }},
+		{encoding: "deflate", decode: func(r io.Reader) (io.Reader, error) { return flate.NewReader(r), nil }},
+		{encoding: "br", decode: func(r io.Reader) (io.Reader, error) { return brotli.NewReader(r), nil }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.encoding, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			newBombRouter().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/bomb/"+tt.encoding+"?size="+strconv.Itoa(size), nil))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d", rec.Code)
+			}
+			if got := rec.Header().Get("Content-Encoding"); got != tt.encoding {
+				t.Errorf("expected Content-Encoding %q, got %q", tt.encoding, got)
+			}
+			if cl := rec.Header().Get("Content-Length"); cl != "" && cl != strconv.Itoa(rec.Body.Len()) {
+				t.Errorf("expected Content-Length %d, got %s", rec.Body.Len(), cl)
+			}
+			if rec.Body.Len() > size/1000 {
+				t.Errorf("expected a ratio over 1000, got %d bytes for %d", rec.Body.Len(), size)
+			}
+
+			decoded, err := tt.decode(rec.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := countZeros(t, decoded); got != size {
+				t.Errorf("expected %d decompressed bytes, got %d", size, got)
+			}
+		})
+	}
+}
+
+func TestBombHandler_InvalidParameters(t *testing.T) {
+	originalConfig := globalConfig
+	defer func() { globalConfig = originalConfig }()
+	globalConfig = &Config{BombMaxSize: 1 << 20}
+
+	tests := []struct {
+		path       string
+		wantStatus int
+	}{
+		{path: "/bomb/zstd", wantStatus: http.StatusNotFound},
+		{path: "/bomb/gzip?size=0", wantStatus: http.StatusBadRequest},
+		{path: "/bomb/gzip?size=2097152", wantStatus: http.StatusBadRequest},
+		{path: "/bomb/content-length?declared=2097152", wantStatus: http.StatusBadRequest},
+		{path: "/bomb/content-length?declared=10&size=11", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		newBombRouter().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if rec.Code != tt.wantStatus {
+			t.Errorf("%s: expected status %d, got %d", tt.path, tt.wantStatus, rec.Code)
+		}
+	}
+}
+
+func TestBombContentLengthHandler(t *testing.T) {
+	server := httptest.NewServer(newBombRouter())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/bomb/content-length?declared=1000000&size=10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.ContentLength != 1000000 {
+		t.Errorf("expected Content-Length 1000000, got %d", resp.ContentLength)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != io.ErrUnexpectedEOF {
+		t.Errorf("expected an unexpected EOF, got %v", err)
+	}
+	if len(body) != 10 {
+		t.Errorf("expected 10 bytes before the connection closed, got %d", len(body))
+	}
+}
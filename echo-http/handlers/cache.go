@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultCacheControl       = "public, max-age=60"
+	defaultCachePeriod        = 60
+	maxCacheRevalidateDelayMS = 30000
+	maxCacheHeaderValueLength = 1024
+)
+
+// OriginHitsHeader counts the requests of a /cache-control URL that
+// reached the server, CacheGenerationHeader is the current generation of
+// its content
+const (
+	OriginHitsHeader      = "X-Origin-Hits"
+	CacheGenerationHeader = "X-Cache-Generation"
+)
+
+// OriginHits counts the requests of /cache-control reaching the server by
+// request URI, so tests tell cached responses from the ones of the origin
+type OriginHits struct {
+	mu   sync.Mutex
+	hits map[string]int
+}
+
+// DefaultOriginHits are the origin hits of /cache-control
+var DefaultOriginHits = NewOriginHits()
+
+// NewOriginHits creates an empty hit counter
+func NewOriginHits() *OriginHits {
+	return &OriginHits{hits: make(map[string]int)}
+}
+
+// add counts a hit of uri and returns its hits so far
+func (h *OriginHits) add(uri string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.hits[uri]++
+	return h.hits[uri]
+}
+
+// Counts returns the hits by request URI
+func (h *OriginHits) Counts() map[string]int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	counts := make(map[string]int, len(h.hits))
+	for uri, n := range h.hits {
+		counts[uri] = n
+	}
+	return counts
+}
+
+// Clear forgets the hits
+func (h *OriginHits) Clear() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	clear(h.hits)
+}
+
+// CacheControlHandler returns the caching headers of its query parameters
+// for a resource changing every period seconds, to exercise HTTP caches.
+// The resource has an ETag and Last-Modified of its current generation and
+// answers matching conditional requests 304 Not Modified; revalidations
+// (conditional requests) are delayed by revalidate_delay, so caches
+// serving stale content while revalidating answer faster than the origin.
+// GET /cache-control?cache_control={value}&age={s}&vary={headers}&expires={s}&surrogate_control={value}&period={s}&revalidate_delay={ms}
+func CacheControlHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	for name, values := range query {
+		for _, v := range values {
+			if len(v) > maxCacheHeaderValueLength || strings.ContainsAny(v, "\r\n") {
+				http.Error(w, fmt.Sprintf("Invalid %s (at most %d bytes on one line)", name, maxCacheHeaderValueLength), http.StatusBadRequest)
+				return
+			}
+		}
+	}
+
+	period := defaultCachePeriod
+	if s := query.Get("period"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 {
+			http.Error(w, "Invalid period (must be a positive number of seconds)", http.StatusBadRequest)
+			return
+		}
+		period = n
+	}
+	var age, expires, revalidateDelay int
+	for _, p := range []struct {
+		name string
+		dst  *int
+		min  int
+		max  int
+	}{
+		{name: "age", dst: &age, min: 0, max: math.MaxInt32},
+		{name: "expires", dst: &expires, min: math.MinInt32, max: math.MaxInt32},
+		{name: "revalidate_delay", dst: &revalidateDelay, min: 0, max: maxCacheRevalidateDelayMS},
+	} {
+		s := query.Get(p.name)
+		if s == "" {
+			continue
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil || n < p.min || n > p.max {
+			http.Error(w, fmt.Sprintf("Invalid %s (must be an integer from %d to %d)", p.name, p.min, p.max), http.StatusBadRequest)
+			return
+		}
+		*p.dst = n
+	}
+
+	clk := currentClock()
+	now := clk.Now()
+	generation := now.Unix() / int64(period)
+	generatedAt := time.Unix(generation*int64(period), 0).UTC()
+	etag := fmt.Sprintf(`"gen-%d"`, generation)
+
+	conditional := r.Header.Get("If-None-Match") != "" || r.Header.Get("If-Modified-Since") != ""
+	if conditional && revalidateDelay > 0 {
+		if clk.Sleep(r.Context(), time.Duration(revalidateDelay)*time.Millisecond) != nil {
+			return
+		}
+	}
+	hits := DefaultOriginHits.add(r.URL.RequestURI())
+
+	h := w.Header()
+	cacheControl := defaultCacheControl
+	if query.Has("cache_control") {
+		cacheControl = query.Get("cache_control")
+	}
+	if cacheControl != "" {
+		h.Set("Cache-Control", cacheControl)
+	}
+	if query.Has("age") {
+		h.Set("Age", strconv.Itoa(age))
+	}
+	if v := query.Get("vary"); v != "" {
+		h.Set("Vary", v)
+	}
+	if query.Has("expires") {
+		h.Set("Expires", now.Add(time.Duration(expires)*time.Second).UTC().Format(http.TimeFormat))
+	}
+	if v := query.Get("surrogate_control"); v != "" {
+		h.Set("Surrogate-Control", v)
+	}
+	h.Set("ETag", etag)
+	h.Set("Last-Modified", generatedAt.Format(http.TimeFormat))
+	h.Set("Date", now.UTC().Format(http.TimeFormat))
+	h.Set(OriginHitsHeader, strconv.Itoa(hits))
+	h.Set(CacheGenerationHeader, strconv.FormatInt(generation, 10))
+
+	if notModified(r, etag, generatedAt) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	response := map[string]any{
+		"generation":   generation,
+		"generated_at": generatedAt,
+		"origin_hits":  hits,
+		"url":          r.URL.RequestURI(),
+	}
+	h.Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// notModified reports whether the conditional headers of r match the
+// current generation: If-None-Match takes precedence over
+// If-Modified-Since (RFC 9110, section 13.2.2)
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for tag := range strings.SplitSeq(inm, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == "*" || tag == etag {
+				return true
+			}
+		}
+		return false
+	}
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" {
+		t, err := http.ParseTime(ims)
+		return err == nil && !lastModified.After(t)
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/probitas-test/echo-servers/shared/clock"
+)
+
+func serveCacheControl(path string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	rec := httptest.NewRecorder()
+	CacheControlHandler(rec, req)
+	return rec
+}
+
+func TestCacheControlHandler(t *testing.T) {
+	originalConfig := globalConfig
+	defer func() { globalConfig = originalConfig }()
+	clk := clock.New(clock.Config{})
+	clk.Set(time.Date(2026, 1, 1, 0, 0, 30, 0, time.UTC))
+	globalConfig = &Config{Clock: clk}
+	defer DefaultOriginHits.Clear()
+
+	rec := serveCacheControl("/cache-control?cache_control=public,+max-age=10,+stale-while-revalidate=30&age=5&vary=Accept-Encoding&expires=-60&surrogate_control=max-age=300", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	want := map[string]string{
+		"Cache-Control":       "public, max-age=10, stale-while-revalidate=30",
+		"Age":                 "5",
+		"Vary":                "Accept-Encoding",
+		"Expires":             "Wed, 31 Dec 2025 23:59:30 GMT",
+		"Surrogate-Control":   "max-age=300",
+		"Last-Modified":       "Thu, 01 Jan 2026 00:00:00 GMT",
+		OriginHitsHeader:      "1",
+		CacheGenerationHeader: strconv.FormatInt(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC).Unix()/60, 10),
+	}
+	for name, value := range want {
+		if got := rec.Header().Get(name); got != value {
+			t.Errorf("expected %s %q, got %q", name, value, got)
+		}
+	}
+	etag := rec.Header().Get("ETag")
+
+	// Revalidations of the current generation are not modified
+	rec = serveCacheControl("/cache-control", http.Header{"If-None-Match": {`W/` + etag}})
+	if rec.Code != http.StatusNotModified || rec.Header().Get("Cache-Control") != defaultCacheControl {
+		t.Errorf("expected status 304 with the default Cache-Control, got %d %q", rec.Code, rec.Header().Get("Cache-Control"))
+	}
+	rec = serveCacheControl("/cache-control", http.Header{"If-Modified-Since": {"Thu, 01 Jan 2026 00:00:10 GMT"}})
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("expected status 304 for If-Modified-Since, got %d", rec.Code)
+	}
+
+	// The next generation is modified
+	clk.Advance(time.Minute)
+	rec = serveCacheControl("/cache-control", http.Header{"If-None-Match": {etag}})
+	if rec.Code != http.StatusOK || rec.Header().Get("ETag") == etag {
+		t.Errorf("expected status 200 with a new ETag, got %d %s", rec.Code, rec.Header().Get("ETag"))
+	}
+	if got := DefaultOriginHits.Counts()["/cache-control"]; got != 3 {
+		t.Errorf("expected 3 origin hits, got %d", got)
+	}
+}
+
+func TestCacheControlHandler_RevalidateDelay(t *testing.T) {
+	defer DefaultOriginHits.Clear()
+
+	start := time.Now()
+	serveCacheControl("/cache-control?revalidate_delay=50", nil)
+	if elapsed := time.Since(start); elapsed > 40*time.Millisecond {
+		t.Errorf("expected unconditional requests without delay, took %v", elapsed)
+	}
+	start = time.Now()
+	serveCacheControl("/cache-control?revalidate_delay=50", http.Header{"If-None-Match": {`"gen-0"`}})
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("expected revalidations delayed by 50ms, took %v", elapsed)
+	}
+}
+
+func TestCacheControlHandler_InvalidParameters(t *testing.T) {
+	tests := []string{
+		"/cache-control?period=0",
+		"/cache-control?age=-1",
+		"/cache-control?expires=soon",
+		"/cache-control?revalidate_delay=30001",
+		"/cache-control?vary=a%0d%0aSet-Cookie:+x=1",
+	}
+
+	for _, path := range tests {
+		if rec := serveCacheControl(path, nil); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", path, rec.Code)
+		}
+	}
+}
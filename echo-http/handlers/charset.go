@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// charsetEncoding is an encoding of /charset/{enc} and the sample text it
+// can represent
+type charsetEncoding struct {
+	encoding encoding.Encoding
+	sample   string
+	// bom is the byte order mark added with ?bom=true; UTF-16 always has
+	// one
+	bom string
+}
+
+const (
+	sampleMixed    = "Hello, 世界! Grüße, Привет, 안녕하세요 🌍"
+	sampleLatin    = "Grüße aus Köln, café crème, ¿qué tal?"
+	sampleJapanese = "こんにちは、世界。日本語のテキスト"
+	sampleCyrillic = "Привет, мир! Съешь же ещё этих булок"
+)
+
+// charsets are the encodings of /charset/{enc}, by IANA name
+var charsets = map[string]charsetEncoding{
+	"utf-8":        {encoding.Nop, sampleMixed, "\xef\xbb\xbf"},
+	"utf-16":       {unicode.UTF16(unicode.BigEndian, unicode.UseBOM), sampleMixed, ""},
+	"utf-16be":     {unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM), sampleMixed, "\xfe\xff"},
+	"utf-16le":     {unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM), sampleMixed, "\xff\xfe"},
+	"iso-8859-1":   {charmap.ISO8859_1, sampleLatin, ""},
+	"iso-8859-15":  {charmap.ISO8859_15, sampleLatin + " 10 €", ""},
+	"windows-1252": {charmap.Windows1252, sampleLatin + " “10 €”", ""},
+	"windows-1251": {charmap.Windows1251, sampleCyrillic, ""},
+	"koi8-r":       {charmap.KOI8R, sampleCyrillic, ""},
+	"shift_jis":    {japanese.ShiftJIS, sampleJapanese, ""},
+	"euc-jp":       {japanese.EUCJP, sampleJapanese, ""},
+	"iso-2022-jp":  {japanese.ISO2022JP, sampleJapanese, ""},
+	"gbk":          {simplifiedchinese.GBK, "你好，世界！简体中文文本", ""},
+	"gb18030":      {simplifiedchinese.GB18030, "你好，世界！简体中文文本 🌍", ""},
+	"big5":         {traditionalchinese.Big5, "你好，世界！繁體中文文本", ""},
+	"euc-kr":       {korean.EUCKR, "안녕하세요, 세계! 한국어 텍스트", ""},
+}
+
+type CharsetsResponse struct {
+	Encodings map[string]string `json:"encodings"`
+}
+
+// CharsetsHandler lists the encodings of /charset/{enc} with their sample
+// text.
+// GET /charset - List the encodings
+func CharsetsHandler(w http.ResponseWriter, r *http.Request) {
+	response := CharsetsResponse{Encodings: make(map[string]string, len(charsets))}
+	for name, c := range charsets {
+		response.Encodings[name] = c.sample
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// CharsetHandler returns text encoded in enc, declaring the charset of
+// ?charset= (default enc, "none" to omit it) to test decoding with
+// matching and mismatched declarations.
+// GET /charset/{enc}?text={text}&charset={charset}&bom={true|false}
+func CharsetHandler(w http.ResponseWriter, r *http.Request) {
+	name := strings.ToLower(chi.URLParam(r, "enc"))
+	c, ok := charsets[name]
+	if !ok {
+		names := make([]string, 0, len(charsets))
+		for n := range charsets {
+			names = append(names, n)
+		}
+		slices.Sort(names)
+		http.Error(w, fmt.Sprintf("Unknown encoding %q (%s)", name, strings.Join(names, ", ")), http.StatusNotFound)
+		return
+	}
+
+	query := r.URL.Query()
+	text := c.sample
+	if t := query.Get("text"); t != "" {
+		text = t
+	}
+	body, err := c.encoding.NewEncoder().String(text)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Text cannot be encoded in %s: %v", name, err), http.StatusBadRequest)
+		return
+	}
+	if query.Get("bom") == "true" {
+		body = c.bom + body
+	}
+
+	declared := name
+	if d := query.Get("charset"); d != "" {
+		declared = d
+	}
+	contentType := "text/plain"
+	if declared != "none" {
+		contentType = mime.FormatMediaType("text/plain", map[string]string{"charset": declared})
+		if contentType == "" {
+			http.Error(w, "Invalid charset", http.StatusBadRequest)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Charset-Actual", name)
+	_, _ = w.Write([]byte(body))
+}
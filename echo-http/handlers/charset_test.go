@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func newCharsetRouter() *chi.Mux {
+	r := chi.NewRouter()
+	r.Get("/charset", CharsetsHandler)
+	r.Get("/charset/{enc}", CharsetHandler)
+	return r
+}
+
+func TestCharsetHandler_EncodesSamples(t *testing.T) {
+	r := newCharsetRouter()
+	for name, c := range charsets {
+		t.Run(name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/charset/"+name, nil))
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+			}
+			if got, want := rec.Header().Get("Content-Type"), "text/plain; charset="+name; got != want {
+				t.Errorf("expected Content-Type %q, got %q", want, got)
+			}
+			decoded, err := c.encoding.NewDecoder().Bytes(rec.Body.Bytes())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(decoded) != c.sample {
+				t.Errorf("expected %q after decoding, got %q", c.sample, decoded)
+			}
+		})
+	}
+}
+
+func TestCharsetHandler_Options(t *testing.T) {
+	tests := []struct {
+		name                string
+		path                string
+		expectedStatus      int
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			name:                "mismatched charset",
+			path:                "/charset/iso-8859-1?text=caf%C3%A9&charset=utf-8",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/plain; charset=utf-8",
+			expectedBody:        "caf\xe9",
+		},
+		{
+			name:                "no charset",
+			path:                "/charset/Shift_JIS?text=%E6%97%A5&charset=none",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/plain",
+			expectedBody:        "\x93\xfa",
+		},
+		{
+			name:                "UTF-16LE with BOM",
+			path:                "/charset/utf-16le?text=A&bom=true",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/plain; charset=utf-16le",
+			expectedBody:        "\xff\xfeA\x00",
+		},
+		{
+			name:           "text not representable returns 400",
+			path:           "/charset/iso-8859-1?text=%E6%97%A5",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown encoding returns 404",
+			path:           "/charset/ebcdic",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	r := newCharsetRouter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, rec.Code)
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+			if got := rec.Header().Get("Content-Type"); got != tt.expectedContentType {
+				t.Errorf("expected Content-Type %q, got %q", tt.expectedContentType, got)
+			}
+			if got := rec.Body.String(); got != tt.expectedBody {
+				t.Errorf("expected body %q, got %q", tt.expectedBody, got)
+			}
+		})
+	}
+}
@@ -1,5 +1,10 @@
 package handlers
 
+import (
+	"github.com/probitas-test/echo-servers/shared/clock"
+	"github.com/probitas-test/echo-servers/shared/credentials"
+)
+
 // globalConfig holds the global OAuth2/OIDC configuration.
 // It is used by authentication handlers.
 var globalConfig *Config
@@ -17,11 +22,43 @@ type Config struct {
 	AuthAllowedUsername string
 	AuthAllowedPassword string
 
-	// Authorization Code Flow Configuration
-	AuthCodeRequirePKCE         bool
+	// Credentials checks the passwords and bearer tokens instead of
+	// AuthAllowedUsername and AuthAllowedPassword when set; it holds the
+	// test identities shared with the other servers
+	Credentials *credentials.Store
+
+	// Clock of token and session expiry and of the delay endpoints, moved
+	// through the admin API; the real clock when nil
+	Clock *clock.Clock
+
+	// Authorization Code Flow Configuration; the switches are read on every
+	// request, as they can be flipped through the admin API
+	AuthCodeRequirePKCE         func() bool
 	AuthCodeSessionTTL          int
-	AuthCodeValidateRedirectURI bool
+	AuthCodeValidateRedirectURI func() bool
 	AuthCodeAllowedRedirectURIs string
+
+	// RedirectChainHosts are the alias hosts /redirect-chain redirects
+	// across, hosts or origins such as "https://127.0.0.1:8443"
+	RedirectChainHosts []string
+
+	// Lifetime in seconds and maximum number of the requests stored by
+	// /anything?store=true
+	AnythingStoreTTL        int
+	AnythingStoreMaxEntries int
+
+	// StickyInstances is the number of instances simulated by /sticky and
+	// StickyCookie the name of its session cookie
+	StickyInstances int
+	StickyCookie    string
+
+	// BombMaxSize caps the decompressed and declared sizes of the /bomb
+	// endpoints
+	BombMaxSize int
+
+	// TransformAESKey is the static AES key of /transform/aes, 16, 24 or 32
+	// bytes; DefaultTransformAESKey when empty
+	TransformAESKey []byte
 }
 
 // SetConfig sets the global configuration for handlers.
@@ -34,3 +71,12 @@ func SetConfig(cfg *Config) {
 func GetConfig() *Config {
 	return globalConfig
 }
+
+// currentClock returns the clock of the global configuration, nil (the
+// real clock) without one
+func currentClock() *clock.Clock {
+	if globalConfig == nil {
+		return nil
+	}
+	return globalConfig.Clock
+}
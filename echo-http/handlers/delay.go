@@ -23,7 +23,9 @@ func DelayHandler(w http.ResponseWriter, r *http.Request) {
 		seconds = maxDelaySeconds
 	}
 
-	time.Sleep(time.Duration(seconds) * time.Second)
+	if currentClock().Sleep(r.Context(), time.Duration(seconds)*time.Second) != nil {
+		return
+	}
 
 	response := map[string]any{
 		"delay":  seconds,
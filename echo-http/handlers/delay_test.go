@@ -8,6 +8,8 @@ import (
 	"time"
 
 	"github.com/go-chi/chi/v5"
+
+	"github.com/probitas-test/echo-servers/shared/clock"
 )
 
 func TestDelayHandler(t *testing.T) {
@@ -69,6 +71,32 @@ func TestDelayHandler(t *testing.T) {
 		}
 	})
 
+	t.Run("delay/30 ends when the clock is advanced", func(t *testing.T) {
+		clk := clock.New(clock.Config{})
+		originalConfig := globalConfig
+		globalConfig = &Config{Clock: clk}
+		defer func() { globalConfig = originalConfig }()
+
+		r := chi.NewRouter()
+		r.Get("/delay/{seconds}", DelayHandler)
+
+		req := httptest.NewRequest(http.MethodGet, "/delay/30", nil)
+		rec := httptest.NewRecorder()
+
+		time.AfterFunc(50*time.Millisecond, func() { clk.Advance(30 * time.Second) })
+		start := time.Now()
+		r.ServeHTTP(rec, req)
+		elapsed := time.Since(start)
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("expected status 200, got %d", rec.Code)
+		}
+
+		if elapsed > time.Second {
+			t.Errorf("expected the delay to end with the clock, took %v", elapsed)
+		}
+	})
+
 	t.Run("delay/-1 returns 400 (negative value)", func(t *testing.T) {
 		r := chi.NewRouter()
 		r.Get("/delay/{seconds}", DelayHandler)
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Head returns a middleware answering HEAD on the GET routes of routes
+// that do not handle HEAD themselves. The GET handler runs to completion
+// with its body discarded, so the response carries the status and headers
+// of the GET response, with the Content-Length of its body.
+func Head(routes chi.Routes) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rctx := chi.RouteContext(r.Context())
+			if r.Method != http.MethodHead || rctx == nil ||
+				routes.Match(chi.NewRouteContext(), http.MethodHead, r.URL.Path) ||
+				!routes.Match(chi.NewRouteContext(), http.MethodGet, r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			rctx.RouteMethod = http.MethodGet
+			hw := &headWriter{ResponseWriter: w}
+			next.ServeHTTP(hw, r)
+			hw.finish()
+		})
+	}
+}
+
+// headWriter counts and discards the body, holding the status until the
+// handler returns and the length of the body is known
+type headWriter struct {
+	http.ResponseWriter
+	code     int
+	n        int
+	hijacked bool
+}
+
+func (w *headWriter) WriteHeader(code int) {
+	if code < 200 {
+		// Informational responses are sent as they come
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if w.code == 0 {
+		w.code = code
+	}
+}
+
+func (w *headWriter) Write(p []byte) (int, error) {
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+	w.n += len(p)
+	return len(p), nil
+}
+
+// Flush keeps the headers until the length is known
+func (w *headWriter) Flush() {}
+
+// Unwrap gives http.ResponseController access to the underlying writer
+func (w *headWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Hijack hands over the connection to handlers writing raw responses,
+// which then answer HEAD themselves
+func (w *headWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, buf, err := http.NewResponseController(w.ResponseWriter).Hijack()
+	if err == nil {
+		w.hijacked = true
+	}
+	return conn, buf, err
+}
+
+// finish sends the status and headers, with the Content-Length of the
+// discarded body unless the handler set one
+func (w *headWriter) finish() {
+	if w.hijacked {
+		return
+	}
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+	if statusAllowsBody(w.code) && w.Header().Get("Content-Length") == "" {
+		w.Header().Del("Transfer-Encoding")
+		w.Header().Set("Content-Length", strconv.Itoa(w.n))
+	}
+	w.ResponseWriter.WriteHeader(w.code)
+}
+
+// defaultHeadMismatchSize is the body size of GET /head-mismatch without
+// ?size=
+const defaultHeadMismatchSize = 100
+
+// HeadMismatchHandler answers HEAD with a Content-Length different from
+// the body of GET, like a broken server.
+// GET /head-mismatch?size={n} - Body of n bytes with its Content-Length
+// HEAD /head-mismatch?size={n}&head_size={m|none} - Content-Length m (default 2n), or none
+func HeadMismatchHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	size := defaultHeadMismatchSize
+	if sizeStr := query.Get("size"); sizeStr != "" {
+		n, err := strconv.Atoi(sizeStr)
+		if err != nil || n < 0 || n > maxBytesSize {
+			http.Error(w, fmt.Sprintf("Invalid size (must be 0-%d)", maxBytesSize), http.StatusBadRequest)
+			return
+		}
+		size = n
+	}
+	headSize := strconv.Itoa(size * 2)
+	if headSizeStr := query.Get("head_size"); headSizeStr != "" {
+		if n, err := strconv.Atoi(headSizeStr); headSizeStr != "none" && (err != nil || n < 0) {
+			http.Error(w, "Invalid head_size (must be a size or none)", http.StatusBadRequest)
+			return
+		}
+		headSize = headSizeStr
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if r.Method == http.MethodHead {
+		if headSize != "none" {
+			w.Header().Set("Content-Length", headSize)
+		}
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(size))
+	_, _ = w.Write([]byte(strings.Repeat("x", size)))
+}
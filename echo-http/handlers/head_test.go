@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestHead_MatchesGet(t *testing.T) {
+	r := chi.NewRouter()
+	r.Use(Head(r))
+	r.Get("/headers", HeadersHandler)
+	r.Get("/bytes/{n}", BytesHandler)
+	r.Get("/stream/{n}", StreamHandler)
+	r.Get("/gzip", GzipHandler)
+	r.Post("/post", EchoHandler)
+	server := httptest.NewServer(r)
+	defer server.Close()
+	client := &http.Client{Transport: &http.Transport{DisableCompression: true}}
+
+	for _, path := range []string{"/headers", "/bytes/5000", "/stream/20", "/gzip"} {
+		t.Run(path, func(t *testing.T) {
+			get, err := client.Get(server.URL + path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, _ := io.ReadAll(get.Body)
+			_ = get.Body.Close()
+
+			head, err := client.Head(server.URL + path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_ = head.Body.Close()
+
+			if head.StatusCode != get.StatusCode {
+				t.Errorf("expected status %d, got %d", get.StatusCode, head.StatusCode)
+			}
+			if head.ContentLength != int64(len(body)) {
+				t.Errorf("expected Content-Length %d, got %d", len(body), head.ContentLength)
+			}
+			if got, want := head.Header.Get("Content-Type"), get.Header.Get("Content-Type"); got != want {
+				t.Errorf("expected Content-Type %q, got %q", want, got)
+			}
+		})
+	}
+
+	head, err := client.Head(server.URL + "/post")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = head.Body.Close()
+	if head.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("expected status 405 on a route without GET, got %d", head.StatusCode)
+	}
+}
+
+func TestHeadMismatchHandler(t *testing.T) {
+	tests := []struct {
+		name                  string
+		method                string
+		query                 string
+		expectedStatus        int
+		expectedContentLength string
+		expectedBodyLength    int
+	}{
+		{"GET returns the body", http.MethodGet, "?size=10", http.StatusOK, "10", 10},
+		{"HEAD doubles the length", http.MethodHead, "?size=10", http.StatusOK, "20", 0},
+		{"HEAD reports head_size", http.MethodHead, "?size=10&head_size=3", http.StatusOK, "3", 0},
+		{"HEAD omits the length", http.MethodHead, "?head_size=none", http.StatusOK, "", 0},
+		{"invalid size returns 400", http.MethodGet, "?size=-1", http.StatusBadRequest, "", -1},
+		{"invalid head_size returns 400", http.MethodHead, "?head_size=big", http.StatusBadRequest, "", -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/head-mismatch"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			HeadMismatchHandler(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, rec.Code)
+			}
+			if tt.expectedBodyLength < 0 {
+				return
+			}
+			if got := rec.Header().Get("Content-Length"); got != tt.expectedContentLength {
+				t.Errorf("expected Content-Length %q, got %q", tt.expectedContentLength, got)
+			}
+			if rec.Body.Len() != tt.expectedBodyLength {
+				t.Errorf("expected a body of %d bytes, got %d", tt.expectedBodyLength, rec.Body.Len())
+			}
+		})
+	}
+}
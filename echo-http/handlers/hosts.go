@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// hostPattern is an entry of the Host allowlist: a host name, IP address
+// or "*." wildcard, on any port unless port is set
+type hostPattern struct {
+	host string
+	port string
+}
+
+// HostAllowlist restricts the Host headers the server answers, as servers
+// guarding against DNS rebinding do; without patterns every host is
+// allowed
+type HostAllowlist struct {
+	patterns []hostPattern
+}
+
+// NewHostAllowlist returns the allowlist of patterns, "host", "host:port",
+// "*.domain" or IP addresses such as "[::1]:8080"
+func NewHostAllowlist(patterns []string) (*HostAllowlist, error) {
+	a := &HostAllowlist{}
+	for _, entry := range patterns {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		p := hostPattern{host: entry}
+		if host, port, err := net.SplitHostPort(entry); err == nil {
+			p = hostPattern{host: host, port: port}
+		}
+		p.host = normalizeHost(p.host)
+		if p.host == "" || strings.Trim(p.host, "*.") == "" {
+			return nil, fmt.Errorf("allowed host %q: missing host", entry)
+		}
+		if strings.Contains(p.host[1:], "*") || strings.HasPrefix(p.host, "*") && !strings.HasPrefix(p.host, "*.") {
+			return nil, fmt.Errorf("allowed host %q: only a leading *. wildcard is supported", entry)
+		}
+		a.patterns = append(a.patterns, p)
+	}
+	return a, nil
+}
+
+// String describes the allowlist for the startup log
+func (a *HostAllowlist) String() string {
+	if len(a.patterns) == 0 {
+		return "any"
+	}
+	parts := make([]string, len(a.patterns))
+	for i, p := range a.patterns {
+		parts[i] = p.host
+		if p.port != "" {
+			parts[i] = net.JoinHostPort(p.host, p.port)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// normalizeHost lowercases host and drops the brackets of IPv6 addresses
+// and the trailing dot of fully qualified names
+func normalizeHost(host string) string {
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
+
+// splitHost returns the host name and port of the Host header of r, the
+// port defaulting to the one of the scheme
+func splitHost(r *http.Request) (string, string) {
+	host, port, err := net.SplitHostPort(r.Host)
+	if err != nil {
+		host, port = r.Host, "80"
+		if r.TLS != nil {
+			port = "443"
+		}
+	}
+	return normalizeHost(host), port
+}
+
+// Allowed reports whether the Host header of r matches the allowlist
+func (a *HostAllowlist) Allowed(r *http.Request) bool {
+	if len(a.patterns) == 0 {
+		return true
+	}
+	host, port := splitHost(r)
+	for _, p := range a.patterns {
+		if p.port != "" && p.port != port {
+			continue
+		}
+		if suffix, ok := strings.CutPrefix(p.host, "*"); ok {
+			if strings.HasSuffix(host, suffix) {
+				return true
+			}
+		} else if host == p.host {
+			return true
+		}
+	}
+	return false
+}
+
+// Middleware answers requests for hosts outside the allowlist 421
+// Misdirected Request. Health checks are exempt.
+func (a *HostAllowlist) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/health" && !a.Allowed(r) {
+			http.Error(w, fmt.Sprintf("Host %q not allowed", r.Host), http.StatusMisdirectedRequest)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Handler reports how the request names its host: the Host header, the
+// TLS SNI, the host of an absolute-form request target and the forwarded
+// host, listing the SNI and forwarded host disagreeing with the Host
+// header.
+// ANY /host - Host header and SNI analysis
+func (a *HostAllowlist) Handler(w http.ResponseWriter, r *http.Request) {
+	host, port := splitHost(r)
+	mismatches := []string{}
+	response := map[string]any{
+		"host":       r.Host,
+		"hostname":   host,
+		"port":       port,
+		"ip_literal": net.ParseIP(host) != nil,
+		"tls":        r.TLS != nil,
+		"allowed":    a.Allowed(r),
+	}
+	if r.TLS != nil {
+		sni := r.TLS.ServerName
+		response["sni"] = sni
+		// Clients send no SNI for IP addresses
+		if normalizeHost(sni) != host && (sni != "" || net.ParseIP(host) == nil) {
+			mismatches = append(mismatches, "sni")
+		}
+	}
+	// net/http takes the host of absolute-form targets over the Host
+	// header, which it does not keep
+	if r.URL.Host != "" {
+		response["request_target_host"] = r.URL.Host
+	}
+	if forwarded := r.Header.Get("X-Forwarded-Host"); forwarded != "" {
+		response["x_forwarded_host"] = forwarded
+		if !strings.EqualFold(forwarded, r.Host) {
+			mismatches = append(mismatches, "x_forwarded_host")
+		}
+	}
+	response["mismatches"] = mismatches
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
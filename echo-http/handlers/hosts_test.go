@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestHostAllowlist_Middleware(t *testing.T) {
+	hosts, err := NewHostAllowlist([]string{"example.com", "*.example.org", "localhost:8080", "[::1]"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := hosts.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		host       string
+		path       string
+		wantStatus int
+	}{
+		{host: "example.com", path: "/get", wantStatus: http.StatusOK},
+		{host: "EXAMPLE.com.:8443", path: "/get", wantStatus: http.StatusOK},
+		{host: "api.example.org", path: "/get", wantStatus: http.StatusOK},
+		{host: "example.org", path: "/get", wantStatus: http.StatusMisdirectedRequest},
+		{host: "localhost:8080", path: "/get", wantStatus: http.StatusOK},
+		{host: "localhost", path: "/get", wantStatus: http.StatusMisdirectedRequest},
+		{host: "[::1]:80", path: "/get", wantStatus: http.StatusOK},
+		{host: "attacker.test", path: "/get", wantStatus: http.StatusMisdirectedRequest},
+		{host: "attacker.test", path: "/health", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		req.Host = tt.host
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tt.wantStatus {
+			t.Errorf("%s %s: expected status %d, got %d", tt.host, tt.path, tt.wantStatus, rec.Code)
+		}
+	}
+}
+
+func TestNewHostAllowlist_Invalid(t *testing.T) {
+	for _, entry := range []string{"*", "*.", "a*.example.com", "*example.com"} {
+		if _, err := NewHostAllowlist([]string{entry}); err == nil {
+			t.Errorf("expected an error for %q", entry)
+		}
+	}
+}
+
+func TestHostAllowlist_Handler(t *testing.T) {
+	hosts, err := NewHostAllowlist([]string{"example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		host           string
+		sni            string
+		tls            bool
+		forwarded      string
+		wantAllowed    bool
+		wantMismatches []string
+	}{
+		{name: "plain", host: "example.com", wantAllowed: true, wantMismatches: []string{}},
+		{name: "matching sni", host: "example.com:443", sni: "example.com", tls: true, wantAllowed: true, wantMismatches: []string{}},
+		{name: "other sni", host: "example.com", sni: "other.test", tls: true, wantAllowed: true, wantMismatches: []string{"sni"}},
+		{name: "missing sni", host: "example.com", tls: true, wantAllowed: true, wantMismatches: []string{"sni"}},
+		{name: "ip without sni", host: "127.0.0.1", tls: true, wantMismatches: []string{}},
+		{name: "forwarded host", host: "example.com", forwarded: "other.test", wantAllowed: true, wantMismatches: []string{"x_forwarded_host"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/host", nil)
+			req.Host = tt.host
+			if tt.tls {
+				req.TLS = &tls.ConnectionState{ServerName: tt.sni}
+			}
+			if tt.forwarded != "" {
+				req.Header.Set("X-Forwarded-Host", tt.forwarded)
+			}
+			rec := httptest.NewRecorder()
+			hosts.Handler(rec, req)
+
+			var resp struct {
+				Allowed    bool     `json:"allowed"`
+				Mismatches []string `json:"mismatches"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if resp.Allowed != tt.wantAllowed || !slices.Equal(resp.Mismatches, tt.wantMismatches) {
+				t.Errorf("expected allowed %v and mismatches %v, got %+v", tt.wantAllowed, tt.wantMismatches, resp)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+)
+
+// Response headers reporting the connection of the request
+const (
+	ConnectionIDHeader       = "X-Connection-Id"
+	ConnectionRequestsHeader = "X-Connection-Requests"
+	ConnectionReusedHeader   = "X-Connection-Reused"
+)
+
+// KeepAlive numbers the connections and their requests, and closes them
+// after a number of requests or when asked with ?connection=close
+type KeepAlive struct {
+	enabled     bool
+	maxRequests int64
+	conns       atomic.Uint64
+}
+
+type keepAliveConnKey struct{}
+
+// keepAliveConn counts the requests of one connection
+type keepAliveConn struct {
+	id       uint64
+	requests atomic.Int64
+}
+
+// NewKeepAlive returns the connection tracking of servers with keep-alive
+// enabled or not, closing connections after maxRequests requests unless 0
+func NewKeepAlive(enabled bool, maxRequests int) *KeepAlive {
+	return &KeepAlive{enabled: enabled, maxRequests: int64(maxRequests)}
+}
+
+// Apply sets the ConnContext of s numbering its connections, and disables
+// its keep-alive unless enabled
+func (k *KeepAlive) Apply(s *http.Server) {
+	s.ConnContext = k.ConnContext
+	s.SetKeepAlivesEnabled(k.enabled)
+}
+
+// ConnContext attaches a new connection number to the context of c
+func (k *KeepAlive) ConnContext(ctx context.Context, c net.Conn) context.Context {
+	return context.WithValue(ctx, keepAliveConnKey{}, &keepAliveConn{id: k.conns.Add(1)})
+}
+
+// Middleware reports the connection number and the position of the
+// request on it in the X-Connection-* response headers, and sends
+// Connection: close on the last request allowed or with ?connection=close.
+// HTTP/2 connections get a GOAWAY instead.
+func (k *KeepAlive) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := keepAliveRequest{close: r.URL.Query().Get("connection") == "close"}
+		if c, ok := r.Context().Value(keepAliveConnKey{}).(*keepAliveConn); ok {
+			req.id, req.n = c.id, c.requests.Add(1)
+			w.Header().Set(ConnectionIDHeader, strconv.FormatUint(req.id, 10))
+			w.Header().Set(ConnectionRequestsHeader, strconv.FormatInt(req.n, 10))
+			w.Header().Set(ConnectionReusedHeader, strconv.FormatBool(req.n > 1))
+			if k.maxRequests > 0 && req.n >= k.maxRequests {
+				req.close = true
+			}
+		}
+		if req.close {
+			w.Header().Set("Connection", "close")
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), keepAliveRequestKey{}, req)))
+	})
+}
+
+type keepAliveRequestKey struct{}
+
+// keepAliveRequest is the position of a request on its connection, 0 for
+// connections not numbered by ConnContext, and whether it is the last one
+type keepAliveRequest struct {
+	id    uint64
+	n     int64
+	close bool
+}
+
+type ConnectionResponse struct {
+	ID        uint64 `json:"id,omitempty"`
+	Requests  int64  `json:"requests,omitempty"`
+	Reused    bool   `json:"reused"`
+	KeepAlive bool   `json:"keep_alive"`
+	Proto     string `json:"proto"`
+	TLS       bool   `json:"tls"`
+	// MaxRequests is the number of requests served per connection, 0 for
+	// unlimited
+	MaxRequests int64  `json:"max_requests"`
+	RemoteAddr  string `json:"remote_addr"`
+}
+
+// Handler reports the connection of the request and whether it stays open
+// after the response.
+// GET /connection - Connection number, reuse and keep-alive
+func (k *KeepAlive) Handler(w http.ResponseWriter, r *http.Request) {
+	req, _ := r.Context().Value(keepAliveRequestKey{}).(keepAliveRequest)
+	response := ConnectionResponse{
+		ID:       req.id,
+		Requests: req.n,
+		Reused:   req.n > 1,
+		// HTTP/1.0 requests without Connection: keep-alive, and requests
+		// with Connection: close, end their connection
+		KeepAlive:   k.enabled && !r.Close && !req.close,
+		Proto:       r.Proto,
+		TLS:         r.TLS != nil,
+		MaxRequests: k.maxRequests,
+		RemoteAddr:  r.RemoteAddr,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
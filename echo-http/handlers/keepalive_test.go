@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newKeepAliveServer(t *testing.T, k *KeepAlive) *httptest.Server {
+	t.Helper()
+	server := httptest.NewUnstartedServer(k.Middleware(http.HandlerFunc(k.Handler)))
+	k.Apply(server.Config)
+	server.Start()
+	t.Cleanup(server.Close)
+	return server
+}
+
+func getConnection(t *testing.T, client *http.Client, url string) ConnectionResponse {
+	t.Helper()
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	var c ConnectionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&c); err != nil {
+		t.Fatal(err)
+	}
+	if got := resp.Header.Get(ConnectionReusedHeader); got != "" && (got == "true") != c.Reused {
+		t.Errorf("expected %s %t, got %q", ConnectionReusedHeader, c.Reused, got)
+	}
+	return c
+}
+
+func TestKeepAlive_MaxRequests(t *testing.T) {
+	server := newKeepAliveServer(t, NewKeepAlive(true, 2))
+	client := server.Client()
+
+	var got []ConnectionResponse
+	for range 3 {
+		got = append(got, getConnection(t, client, server.URL))
+	}
+
+	if got[0].ID != got[1].ID || got[1].Requests != 2 || !got[1].Reused {
+		t.Errorf("expected the second request to reuse the connection, got %+v then %+v", got[0], got[1])
+	}
+	if got[1].KeepAlive {
+		t.Error("expected the second request to close the connection")
+	}
+	if got[2].ID == got[1].ID || got[2].Requests != 1 || got[2].Reused {
+		t.Errorf("expected the third request on a new connection, got %+v", got[2])
+	}
+}
+
+func TestKeepAlive_ConnectionCloseQuery(t *testing.T) {
+	server := newKeepAliveServer(t, NewKeepAlive(true, 0))
+	client := server.Client()
+
+	first := getConnection(t, client, server.URL+"?connection=close")
+	if first.KeepAlive {
+		t.Error("expected ?connection=close to close the connection")
+	}
+	second := getConnection(t, client, server.URL)
+	if second.ID == first.ID || second.Reused {
+		t.Errorf("expected a new connection after ?connection=close, got %+v then %+v", first, second)
+	}
+	third := getConnection(t, client, server.URL)
+	if third.ID != second.ID || !third.Reused || !third.KeepAlive {
+		t.Errorf("expected the connection reused, got %+v then %+v", second, third)
+	}
+}
+
+func TestKeepAlive_Disabled(t *testing.T) {
+	server := newKeepAliveServer(t, NewKeepAlive(false, 0))
+	client := server.Client()
+
+	first := getConnection(t, client, server.URL)
+	second := getConnection(t, client, server.URL)
+	if first.KeepAlive || second.ID == first.ID || second.Reused {
+		t.Errorf("expected a new connection per request, got %+v then %+v", first, second)
+	}
+}
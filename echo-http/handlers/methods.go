@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// allowMethods are the methods looked up in the routes for the Allow
+// header, in the order listed
+var allowMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+}
+
+// Methods returns a middleware handling the methods the routes do not
+// declare:
+//   - OPTIONS gets the methods routes allows on the path in the Allow
+//     header, with 204 No Content unless the route answers OPTIONS itself
+//     (/anything, /status/{code})
+//   - TRACE echoes the request line and headers as message/http
+//   - methods unknown to the router, such as PROPFIND or PURGE, reach
+//     /anything and /anything/{path}
+//
+// Paths without routes are passed to next.
+func Methods(routes chi.Routes) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.Method == http.MethodOptions || r.Method == http.MethodTrace:
+				allow := allowed(routes, r.URL.Path)
+				if allow == nil {
+					next.ServeHTTP(w, r)
+					return
+				}
+				w.Header().Set("Allow", strings.Join(allow, ", "))
+				if r.Method == http.MethodTrace {
+					TraceHandler(w, r)
+					return
+				}
+				if routes.Match(chi.NewRouteContext(), http.MethodOptions, r.URL.Path) {
+					next.ServeHTTP(w, r)
+					return
+				}
+				w.WriteHeader(http.StatusNoContent)
+			case !knownMethod(r.Method) && isAnythingPath(r.URL.Path):
+				AnythingHandler(w, r)
+			default:
+				next.ServeHTTP(w, r)
+			}
+		})
+	}
+}
+
+// TraceHandler echoes the request as received, without its content.
+// TRACE /* - Echo the request line and headers as message/http
+func TraceHandler(w http.ResponseWriter, r *http.Request) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%s %s %s\r\n", r.Method, r.URL.RequestURI(), r.Proto)
+	fmt.Fprintf(&b, "Host: %s\r\n", r.Host)
+	_ = r.Header.Write(&b)
+	b.WriteString("\r\n")
+
+	w.Header().Set("Content-Type", "message/http")
+	_, _ = w.Write(b.Bytes())
+}
+
+// allowed returns the methods routes allows on path, OPTIONS and TRACE
+// included, or nil when no route matches
+func allowed(routes chi.Routes, path string) []string {
+	var allow []string
+	for _, method := range allowMethods {
+		// Head answers HEAD on every GET route
+		if routes.Match(chi.NewRouteContext(), method, path) ||
+			method == http.MethodHead && routes.Match(chi.NewRouteContext(), http.MethodGet, path) {
+			allow = append(allow, method)
+		}
+	}
+	if allow == nil {
+		return nil
+	}
+	return append(allow, http.MethodOptions, http.MethodTrace)
+}
+
+// knownMethod reports whether the router knows method
+func knownMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+func isAnythingPath(path string) bool {
+	return path == "/anything" || strings.HasPrefix(path, "/anything/")
+}
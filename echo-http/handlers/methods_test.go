@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func newMethodsRouter() *chi.Mux {
+	r := chi.NewRouter()
+	r.Use(Methods(r))
+	r.Get("/get", EchoHandler)
+	r.Post("/post", EchoHandler)
+	r.HandleFunc("/anything", AnythingHandler)
+	r.HandleFunc("/anything/*", AnythingHandler)
+	return r
+}
+
+func TestMethods_Options(t *testing.T) {
+	tests := []struct {
+		path       string
+		wantStatus int
+		wantAllow  string
+	}{
+		{"/get", http.StatusNoContent, "GET, HEAD, OPTIONS, TRACE"},
+		{"/post", http.StatusNoContent, "POST, OPTIONS, TRACE"},
+		{"/anything/a/b", http.StatusOK, "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS, TRACE"},
+		{"/missing", http.StatusNotFound, ""},
+	}
+	r := newMethodsRouter()
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodOptions, tt.path, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+			if got := w.Header().Get("Allow"); got != tt.wantAllow {
+				t.Errorf("expected Allow %q, got %q", tt.wantAllow, got)
+			}
+		})
+	}
+}
+
+func TestMethods_Trace(t *testing.T) {
+	r := newMethodsRouter()
+	req := httptest.NewRequest(http.MethodTrace, "/get?foo=bar", nil)
+	req.Header.Set("X-Test", "value")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "message/http" {
+		t.Errorf("expected Content-Type message/http, got %q", ct)
+	}
+	want := "TRACE /get?foo=bar HTTP/1.1\r\nHost: example.com\r\nX-Test: value\r\n\r\n"
+	if got := w.Body.String(); got != want {
+		t.Errorf("expected body %q, got %q", want, got)
+	}
+}
+
+func TestMethods_CustomMethodOnAnything(t *testing.T) {
+	r := newMethodsRouter()
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("PURGE", "/anything/cache", strings.NewReader("body")))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	var resp AnythingResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Method != "PURGE" || resp.Data != "body" {
+		t.Errorf("expected the PURGE request echoed, got method %q data %q", resp.Method, resp.Data)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("PURGE", "/get", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected status 405 outside /anything, got %d", w.Code)
+	}
+}
@@ -51,7 +51,7 @@ func handleOAuth2AuthorizeGET(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Validate redirect_uri if validation is enabled
-	if globalConfig != nil && globalConfig.AuthCodeValidateRedirectURI {
+	if globalConfig != nil && globalConfig.AuthCodeValidateRedirectURI != nil && globalConfig.AuthCodeValidateRedirectURI() {
 		var allowedPatterns []string
 		if globalConfig.AuthCodeAllowedRedirectURIs != "" {
 			// Split comma-separated patterns
@@ -95,7 +95,7 @@ func handleOAuth2AuthorizeGET(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Validate PKCE parameters
-	if globalConfig != nil && globalConfig.AuthCodeRequirePKCE && codeChallenge == "" {
+	if globalConfig != nil && globalConfig.AuthCodeRequirePKCE != nil && globalConfig.AuthCodeRequirePKCE() && codeChallenge == "" {
 		writeAuthorizationError(w, r, ErrorInvalidRequest, "code_challenge is required", state, redirectURI)
 		return
 	}
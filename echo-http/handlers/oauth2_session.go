@@ -85,7 +85,7 @@ func (s *SessionStore) CreateSession(state, redirectURI, scope, codeChallenge, c
 		CodeChallenge:       codeChallenge,
 		CodeChallengeMethod: codeChallengeMethod,
 		Nonce:               nonce,
-		CreatedAt:           time.Now(),
+		CreatedAt:           currentClock().Now(),
 	}
 
 	s.mu.Lock()
@@ -106,7 +106,7 @@ func (s *SessionStore) GetSession(sessionID string) (*Session, bool) {
 	}
 
 	// Check if session is expired
-	if time.Since(session.CreatedAt) > s.ttl {
+	if currentClock().Since(session.CreatedAt) > s.ttl {
 		return nil, false
 	}
 
@@ -135,7 +135,7 @@ func (s *SessionStore) CreateAuthCode(redirectURI, username, scope, codeChalleng
 		CodeChallenge:       codeChallenge,
 		CodeChallengeMethod: codeChallengeMethod,
 		Nonce:               nonce,
-		CreatedAt:           time.Now(),
+		CreatedAt:           currentClock().Now(),
 	}
 
 	s.mu.Lock()
@@ -156,7 +156,7 @@ func (s *SessionStore) GetAuthCode(code string) (*AuthCode, bool) {
 	}
 
 	// Check if auth code is expired
-	if time.Since(authCode.CreatedAt) > s.ttl {
+	if currentClock().Since(authCode.CreatedAt) > s.ttl {
 		return nil, false
 	}
 
@@ -183,7 +183,7 @@ func (s *SessionStore) CreateRefreshToken(username, clientID, scope, nonce strin
 		ClientID:  clientID,
 		Scope:     scope,
 		Nonce:     nonce,
-		CreatedAt: time.Now(),
+		CreatedAt: currentClock().Now(),
 	}
 
 	s.mu.Lock()
@@ -204,7 +204,7 @@ func (s *SessionStore) GetRefreshToken(token string) (*RefreshToken, bool) {
 	}
 
 	// Check if refresh token is expired
-	if time.Since(refreshToken.CreatedAt) > s.refreshTTL {
+	if currentClock().Since(refreshToken.CreatedAt) > s.refreshTTL {
 		return nil, false
 	}
 
@@ -218,6 +218,30 @@ func (s *SessionStore) DeleteRefreshToken(token string) {
 	s.mu.Unlock()
 }
 
+// Clear removes every session, authorization code and refresh token,
+// returning how many were removed
+func (s *SessionStore) Clear() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := len(s.sessions) + len(s.authCodes) + len(s.refreshTokens)
+	clear(s.sessions)
+	clear(s.authCodes)
+	clear(s.refreshTokens)
+	return n
+}
+
+// Counts returns the number of stored sessions, authorization codes and
+// refresh tokens, expired ones included until the next cleanup
+func (s *SessionStore) Counts() map[string]int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return map[string]int{
+		"sessions":       len(s.sessions),
+		"auth_codes":     len(s.authCodes),
+		"refresh_tokens": len(s.refreshTokens),
+	}
+}
+
 // cleanup periodically removes expired sessions and auth codes
 func (s *SessionStore) cleanup() {
 	ticker := time.NewTicker(1 * time.Minute)
@@ -225,7 +249,7 @@ func (s *SessionStore) cleanup() {
 
 	for range ticker.C {
 		s.mu.Lock()
-		now := time.Now()
+		now := currentClock().Now()
 
 		// Clean up expired sessions
 		for sessionID, session := range s.sessions {
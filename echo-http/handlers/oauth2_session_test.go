@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/probitas-test/echo-servers/shared/clock"
+)
+
+func TestSessionStoreClock(t *testing.T) {
+	clk := clock.New(clock.Config{})
+	originalConfig := globalConfig
+	globalConfig = &Config{Clock: clk}
+	defer func() { globalConfig = originalConfig }()
+
+	store := NewSessionStore(5 * time.Minute)
+	session, err := store.CreateSession("state", "http://localhost/callback", "openid", "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	refreshToken, err := store.CreateRefreshToken("alice", "client", "openid", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clk.Advance(4 * time.Minute)
+	if _, ok := store.GetSession(session.ID); !ok {
+		t.Error("session expired before its TTL")
+	}
+
+	clk.Advance(2 * time.Minute)
+	if _, ok := store.GetSession(session.ID); ok {
+		t.Error("session still valid after the clock passed its TTL")
+	}
+	if _, ok := store.GetRefreshToken(refreshToken.Token); !ok {
+		t.Error("refresh token expired before its TTL")
+	}
+
+	clk.Advance(24 * time.Hour)
+	if _, ok := store.GetRefreshToken(refreshToken.Token); ok {
+		t.Error("refresh token still valid after the clock passed its TTL")
+	}
+}
@@ -233,8 +233,8 @@ func generateOAuth2IDToken(issuer, clientID, username, nonce string, expiresIn i
 		"iss":   issuer,
 		"sub":   username,
 		"aud":   clientID,
-		"exp":   time.Now().Add(time.Duration(expiresIn) * time.Second).Unix(),
-		"iat":   time.Now().Unix(),
+		"exp":   currentClock().Now().Add(time.Duration(expiresIn) * time.Second).Unix(),
+		"iat":   currentClock().Now().Unix(),
 		"name":  username,
 		"email": fmt.Sprintf("%s@example.com", username),
 	}
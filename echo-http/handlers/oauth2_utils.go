@@ -54,7 +54,8 @@ func validateGrantType(grantType string, allowedTypes []string) error {
 	return nil
 }
 
-// validateBasicAuthCredentials validates username and password against configured values.
+// validateBasicAuthCredentials validates username and password against the credential store,
+// or the configured values without one.
 // Uses constant-time comparison to prevent timing attacks.
 // Returns error if credentials don't match or are not configured.
 func validateBasicAuthCredentials(username, password string) error {
@@ -62,6 +63,14 @@ func validateBasicAuthCredentials(username, password string) error {
 		return errors.New("username and password are required")
 	}
 
+	// Shared credential store
+	if globalConfig != nil && globalConfig.Credentials != nil {
+		if _, ok := globalConfig.Credentials.Basic(username, password); !ok {
+			return errors.New("invalid username or password")
+		}
+		return nil
+	}
+
 	// Check if credentials are configured
 	if globalConfig == nil || globalConfig.AuthAllowedUsername == "" || globalConfig.AuthAllowedPassword == "" {
 		return errors.New("authentication credentials not configured")
@@ -88,7 +97,8 @@ func isGrantTypeAllowed(grantType string, allowedTypes []string) bool {
 	return false
 }
 
-// buildBaseURL constructs the base URL from the request, respecting X-Forwarded-Proto.
+// buildBaseURL constructs the base URL from the request, respecting X-Forwarded-Proto
+// and the base path of the site.
 func buildBaseURL(r *http.Request) string {
 	scheme := "http"
 	if r.TLS != nil {
@@ -100,7 +110,7 @@ func buildBaseURL(r *http.Request) string {
 	}
 
 	host := r.Host
-	return fmt.Sprintf("%s://%s", scheme, host)
+	return fmt.Sprintf("%s://%s%s", scheme, host, BasePath(r))
 }
 
 // buildIssuerURL constructs the issuer URL based on the request.
@@ -0,0 +1,489 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"gopkg.in/yaml.v3"
+)
+
+// maxSchemaDepth bounds the nesting of the responses generated from
+// schemas, which may be recursive
+const maxSchemaDepth = 8
+
+// openAPIMethods are the operations of an OpenAPI path item
+var openAPIMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// OpenAPIMock serves the example responses of the operations of an OpenAPI
+// 3 specification, turning echo-http into a spec-driven mock
+type OpenAPIMock struct {
+	spec     map[string]any
+	basePath string
+	routes   []openAPIRoute
+}
+
+// openAPIRoute is a path of the specification, such as "/pets/{petId}"
+type openAPIRoute struct {
+	pattern    string
+	segments   []string
+	literals   int
+	operations map[string]map[string]any
+}
+
+// LoadOpenAPI reads the OpenAPI specification of the YAML or JSON file path
+func LoadOpenAPI(path string) (*OpenAPIMock, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	m, err := NewOpenAPIMock(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return m, nil
+}
+
+// NewOpenAPIMock parses a YAML or JSON OpenAPI specification
+func NewOpenAPIMock(data []byte) (*OpenAPIMock, error) {
+	var v any
+	if err := yaml.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	spec, ok := normalizeYAML(v).(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("specification is not an object")
+	}
+	paths, ok := spec["paths"].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("specification has no paths")
+	}
+
+	m := &OpenAPIMock{spec: spec}
+	if servers, ok := spec["servers"].([]any); ok && len(servers) > 0 {
+		if server, ok := servers[0].(map[string]any); ok {
+			if u, err := url.Parse(fmt.Sprint(server["url"])); err == nil {
+				m.basePath = strings.TrimSuffix(u.Path, "/")
+			}
+		}
+	}
+	for pattern, item := range paths {
+		item, ok := m.resolve(item).(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("path %s is not an object", pattern)
+		}
+		route := openAPIRoute{
+			pattern:    pattern,
+			segments:   strings.Split(strings.Trim(pattern, "/"), "/"),
+			operations: map[string]map[string]any{},
+		}
+		for _, s := range route.segments {
+			if !isPathParam(s) {
+				route.literals++
+			}
+		}
+		for _, method := range openAPIMethods {
+			if op, ok := item[method].(map[string]any); ok {
+				route.operations[strings.ToUpper(method)] = op
+			}
+		}
+		m.routes = append(m.routes, route)
+	}
+	// Literal segments win over parameters, as in "/pets/mine" and
+	// "/pets/{petId}"
+	slices.SortFunc(m.routes, func(a, b openAPIRoute) int {
+		if a.literals != b.literals {
+			return b.literals - a.literals
+		}
+		return strings.Compare(a.pattern, b.pattern)
+	})
+	return m, nil
+}
+
+// String describes the specification for the startup log
+func (m *OpenAPIMock) String() string {
+	operations := 0
+	for _, route := range m.routes {
+		operations += len(route.operations)
+	}
+	s := fmt.Sprintf("paths=%d operations=%d", len(m.routes), operations)
+	if m.basePath != "" {
+		s += " base_path=" + m.basePath
+	}
+	return s
+}
+
+// Middleware answers the requests of the paths of the specification with
+// their example responses, and passes the other ones to next. The
+// response is selected by the Prefer header: "code=404" picks the status
+// and "example=name" the named example.
+func (m *OpenAPIMock) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route, ok := m.match(r.URL.Path)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		// Route pattern of the metrics and traces
+		if rctx := chi.RouteContext(r.Context()); rctx != nil {
+			rctx.RoutePatterns = append(rctx.RoutePatterns, m.basePath+route.pattern)
+		}
+
+		op, ok := route.operations[r.Method]
+		if !ok && r.Method == http.MethodHead {
+			op, ok = route.operations[http.MethodGet]
+		}
+		if !ok {
+			var allow []string
+			for method := range route.operations {
+				allow = append(allow, method)
+			}
+			slices.Sort(allow)
+			w.Header().Set("Allow", strings.Join(allow, ", "))
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		m.respond(w, r, op)
+	})
+}
+
+// match returns the route of path
+func (m *OpenAPIMock) match(path string) (openAPIRoute, bool) {
+	if m.basePath != "" {
+		rest, ok := strings.CutPrefix(path, m.basePath)
+		if !ok || (rest != "" && !strings.HasPrefix(rest, "/")) {
+			return openAPIRoute{}, false
+		}
+		path = rest
+	}
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for _, route := range m.routes {
+		if len(route.segments) != len(segments) {
+			continue
+		}
+		matched := true
+		for i, s := range route.segments {
+			if isPathParam(s) {
+				matched = segments[i] != ""
+			} else {
+				matched = s == segments[i]
+			}
+			if !matched {
+				break
+			}
+		}
+		if matched {
+			return route, true
+		}
+	}
+	return openAPIRoute{}, false
+}
+
+// respond writes the example response of op selected by the request
+func (m *OpenAPIMock) respond(w http.ResponseWriter, r *http.Request, op map[string]any) {
+	prefer := parsePrefer(r.Header.Get("Prefer"))
+	responses, _ := m.resolve(op["responses"]).(map[string]any)
+	code, key := selectResponse(responses, prefer["code"])
+	response, _ := m.resolve(responses[key]).(map[string]any)
+
+	if headers, ok := response["headers"].(map[string]any); ok {
+		for name, header := range headers {
+			header, _ := m.resolve(header).(map[string]any)
+			value, ok := header["example"]
+			if !ok {
+				value = m.sample(header["schema"], 0)
+			}
+			if value != nil {
+				w.Header().Set(name, fmt.Sprint(value))
+			}
+		}
+	}
+
+	content, _ := response["content"].(map[string]any)
+	mediaType := selectMediaType(content, r.Header.Get("Accept"))
+	if mediaType == "" {
+		w.WriteHeader(code)
+		return
+	}
+	media, _ := m.resolve(content[mediaType]).(map[string]any)
+	body := m.example(media, prefer["example"])
+
+	var data []byte
+	if s, ok := body.(string); ok && !strings.Contains(mediaType, "json") {
+		data = []byte(s)
+	} else {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	if !strings.Contains(mediaType, "*") {
+		w.Header().Set("Content-Type", mediaType)
+	}
+	w.WriteHeader(code)
+	if r.Method != http.MethodHead {
+		_, _ = w.Write(data)
+	}
+}
+
+// example returns the example of a media type object: its example, the
+// named example or the first by name, or one generated from its schema
+func (m *OpenAPIMock) example(media map[string]any, name string) any {
+	if examples, ok := media["examples"].(map[string]any); ok && len(examples) > 0 {
+		if _, ok := examples[name]; !ok {
+			name = sortedKeys(examples)[0]
+		}
+		if example, ok := m.resolve(examples[name]).(map[string]any); ok {
+			return example["value"]
+		}
+	}
+	if example, ok := media["example"]; ok {
+		return example
+	}
+	return m.sample(media["schema"], 0)
+}
+
+// sample generates a value of schema: its example, default or first enum
+// value, or a value of its type with every property
+func (m *OpenAPIMock) sample(schema any, depth int) any {
+	s, ok := m.resolve(schema).(map[string]any)
+	if !ok || depth > maxSchemaDepth {
+		return nil
+	}
+	for _, key := range []string{"example", "default", "const"} {
+		if v, ok := s[key]; ok {
+			return v
+		}
+	}
+	if examples, ok := s["examples"].([]any); ok && len(examples) > 0 {
+		return examples[0]
+	}
+	if enum, ok := s["enum"].([]any); ok && len(enum) > 0 {
+		return enum[0]
+	}
+	if allOf, ok := s["allOf"].([]any); ok {
+		merged := map[string]any{}
+		for _, sub := range allOf {
+			v, ok := m.sample(sub, depth+1).(map[string]any)
+			if !ok {
+				return m.sample(sub, depth+1)
+			}
+			for k, value := range v {
+				merged[k] = value
+			}
+		}
+		return merged
+	}
+	for _, key := range []string{"oneOf", "anyOf"} {
+		if alternatives, ok := s[key].([]any); ok && len(alternatives) > 0 {
+			return m.sample(alternatives[0], depth+1)
+		}
+	}
+
+	switch schemaType(s) {
+	case "object":
+		obj := map[string]any{}
+		if properties, ok := s["properties"].(map[string]any); ok {
+			for name, property := range properties {
+				if v := m.sample(property, depth+1); v != nil {
+					obj[name] = v
+				}
+			}
+		}
+		return obj
+	case "array":
+		if v := m.sample(s["items"], depth+1); v != nil {
+			return []any{v}
+		}
+		return []any{}
+	case "string":
+		return sampleString(fmt.Sprint(s["format"]))
+	case "integer", "number":
+		if minimum, ok := s["minimum"]; ok {
+			return minimum
+		}
+		return 0
+	case "boolean":
+		return true
+	}
+	return nil
+}
+
+// resolve follows the local $ref of v, such as
+// "#/components/schemas/Pet"
+func (m *OpenAPIMock) resolve(v any) any {
+	for i := 0; ; i++ {
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return v
+		}
+		ref, ok := obj["$ref"].(string)
+		if !ok {
+			return v
+		}
+		pointer, ok := strings.CutPrefix(ref, "#/")
+		if !ok || i == maxSchemaDepth {
+			return nil
+		}
+		v = any(m.spec)
+		for _, token := range strings.Split(pointer, "/") {
+			token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+			obj, ok := v.(map[string]any)
+			if !ok {
+				return nil
+			}
+			v = obj[token]
+		}
+	}
+}
+
+// selectResponse returns the status code and key of the response: the
+// preferred code, the lowest 2xx or the default response
+func selectResponse(responses map[string]any, preferred string) (int, string) {
+	if preferred != "" {
+		code, err := strconv.Atoi(preferred)
+		if _, ok := responses[preferred]; ok && err == nil {
+			return code, preferred
+		}
+		if _, ok := responses["default"]; ok && err == nil {
+			return code, "default"
+		}
+	}
+	keys := sortedKeys(responses)
+	for _, key := range keys {
+		if code, err := strconv.Atoi(key); err == nil && code >= 200 && code < 300 {
+			return code, key
+		}
+	}
+	if _, ok := responses["default"]; ok {
+		return http.StatusOK, "default"
+	}
+	for _, key := range keys {
+		if code, err := strconv.Atoi(key); err == nil {
+			return code, key
+		}
+	}
+	return http.StatusOK, ""
+}
+
+// selectMediaType returns the media type of the response content: the
+// accepted one, application/json or the first one
+func selectMediaType(content map[string]any, accept string) string {
+	if len(content) == 0 {
+		return ""
+	}
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, _ := strings.Cut(part, ";")
+		if _, ok := content[strings.TrimSpace(mediaType)]; ok {
+			return strings.TrimSpace(mediaType)
+		}
+	}
+	if _, ok := content["application/json"]; ok {
+		return "application/json"
+	}
+	return sortedKeys(content)[0]
+}
+
+// parsePrefer returns the preferences of a Prefer header, such as
+// "code=404, example=notFound"
+func parsePrefer(header string) map[string]string {
+	prefer := map[string]string{}
+	for _, part := range strings.FieldsFunc(header, func(r rune) bool { return r == ',' || r == ';' }) {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		prefer[strings.ToLower(key)] = strings.Trim(value, `"`)
+	}
+	return prefer
+}
+
+// schemaType returns the type of a schema, the first non-null one of an
+// OpenAPI 3.1 type list, or the type implied by its keywords
+func schemaType(s map[string]any) string {
+	switch t := s["type"].(type) {
+	case string:
+		return t
+	case []any:
+		for _, v := range t {
+			if v != "null" {
+				return fmt.Sprint(v)
+			}
+		}
+	}
+	if _, ok := s["properties"]; ok {
+		return "object"
+	}
+	if _, ok := s["items"]; ok {
+		return "array"
+	}
+	return ""
+}
+
+// sampleString returns a string of the format
+func sampleString(format string) string {
+	switch format {
+	case "date-time":
+		return "1970-01-01T00:00:00Z"
+	case "date":
+		return "1970-01-01"
+	case "time":
+		return "00:00:00"
+	case "uuid":
+		return "00000000-0000-0000-0000-000000000000"
+	case "email":
+		return "user@example.com"
+	case "uri", "url":
+		return "https://example.com"
+	case "hostname":
+		return "example.com"
+	case "ipv4":
+		return "127.0.0.1"
+	case "ipv6":
+		return "::1"
+	case "byte":
+		return "c3RyaW5n"
+	}
+	return "string"
+}
+
+func isPathParam(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// normalizeYAML converts the mappings of a decoded YAML document to
+// map[string]any, so status codes such as 200 become "200" keys
+func normalizeYAML(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, value := range v {
+			v[k] = normalizeYAML(value)
+		}
+		return v
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for k, value := range v {
+			m[fmt.Sprint(k)] = normalizeYAML(value)
+		}
+		return m
+	case []any:
+		for i, value := range v {
+			v[i] = normalizeYAML(value)
+		}
+		return v
+	}
+	return v
+}
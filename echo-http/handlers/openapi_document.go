@@ -0,0 +1,489 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// OpenAPIDocumentPath serves the OpenAPI description of the endpoints
+const OpenAPIDocumentPath = "/openapi.json"
+
+// openAPIOperationMethods are the methods documented for routes accepting
+// any method; OPTIONS, TRACE and HEAD are answered on every path alike
+var openAPIOperationMethods = []string{
+	http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete,
+}
+
+// routeParamPattern matches the parameters of a chi route pattern, such as
+// "{code}" or "{id:[0-9]+}"
+var routeParamPattern = regexp.MustCompile(`\{([^}:]+)(:[^}]*)?\}`)
+
+// routeDoc documents the operations of a route
+type routeDoc struct {
+	tag     string
+	summary string
+	// params are the path parameters, named in the route, and the query
+	// parameters
+	params []paramDoc
+	// body is the media type of the request body, if any
+	body string
+	// content is the media type of the response (default application/json)
+	content string
+	// schema names the component schema of a JSON response
+	schema string
+	// methods restricts the documented methods of routes accepting any
+	// method
+	methods []string
+}
+
+// paramDoc documents a path or query parameter
+type paramDoc struct {
+	name        string
+	typ         string
+	description string
+	enum        []string
+}
+
+// param returns a string parameter
+func param(name, description string, enum ...string) paramDoc {
+	return paramDoc{name: name, typ: "string", description: description, enum: enum}
+}
+
+// intParam returns an integer parameter
+func intParam(name, description string) paramDoc {
+	return paramDoc{name: name, typ: "integer", description: description}
+}
+
+// numberParam returns a number parameter
+func numberParam(name, description string) paramDoc {
+	return paramDoc{name: name, typ: "number", description: description}
+}
+
+// boolParam returns a boolean parameter
+func boolParam(name, description string) paramDoc {
+	return paramDoc{name: name, typ: "boolean", description: description}
+}
+
+// adminMethods are the methods of the runtime configuration APIs
+var adminMethods = []string{http.MethodGet, http.MethodPut, http.MethodDelete}
+
+// routeDocs documents the routes by their OpenAPI path; routes missing here
+// are described by their path only
+var routeDocs = map[string]routeDoc{
+	"/": {tag: "Utility", summary: "API documentation", content: "text/markdown"},
+
+	// Echo endpoints
+	"/get":    {tag: "Echo", summary: "Echo a GET request", schema: "EchoResponse"},
+	"/post":   {tag: "Echo", summary: "Echo a POST request", body: "*/*", schema: "EchoResponse"},
+	"/put":    {tag: "Echo", summary: "Echo a PUT request", body: "*/*", schema: "EchoResponse"},
+	"/patch":  {tag: "Echo", summary: "Echo a PATCH request", body: "*/*", schema: "EchoResponse"},
+	"/delete": {tag: "Echo", summary: "Echo a DELETE request", schema: "EchoResponse"},
+	"/anything": {tag: "Echo", summary: "Echo any request", body: "*/*", schema: "AnythingResponse", params: []paramDoc{
+		boolParam("store", "Store the request for /anything/stored/{id}"),
+		param("store_id", "ID of the stored request (default random)"),
+		intParam("store_ttl", "Seconds the request is stored (1-86400)"),
+	}},
+	"/anything/{path}": {tag: "Echo", summary: "Echo any request with a path", body: "*/*", schema: "AnythingResponse", params: []paramDoc{
+		param("path", "Any path"),
+		boolParam("store", "Store the request for /anything/stored/{id}"),
+		param("store_id", "ID of the stored request (default random)"),
+		intParam("store_ttl", "Seconds the request is stored (1-86400)"),
+	}},
+	"/anything/stored/{id}": {tag: "Echo", summary: "Get a stored request", methods: []string{http.MethodGet}, params: []paramDoc{
+		param("id", "ID of the stored request"),
+	}},
+
+	// Utility endpoints
+	"/headers":         {tag: "Utility", summary: "Return the request headers"},
+	"/response-header": {tag: "Utility", summary: "Set the response headers of the query parameters"},
+	"/weird-headers": {tag: "Utility", summary: "Return malformed and unusual response headers", params: []paramDoc{
+		intParam("duplicate", "Repetitions of a duplicated header"),
+		intParam("long", "Size of a long header value in bytes"),
+		intParam("fold", "Lines of an obs-folded header"),
+		param("non_ascii", "Encoding of a non-ASCII header value", "utf8", "latin1", "none"),
+	}},
+	"/ip":         {tag: "Utility", summary: "Return the client IP address"},
+	"/user-agent": {tag: "Utility", summary: "Return the User-Agent header"},
+	"/status/{code}": {tag: "Utility", summary: "Return the status code", params: []paramDoc{
+		intParam("code", "HTTP status code"),
+		param("reason", "Reason phrase"),
+		param("body", "Response body"),
+		param("header", "Response header, such as \"Name: value\""),
+	}},
+	"/delay/{seconds}": {tag: "Utility", summary: "Respond after a delay", schema: "EchoResponse", params: []paramDoc{
+		numberParam("seconds", "Delay in seconds"),
+	}},
+	"/host":   {tag: "Utility", summary: "Host header and SNI analysis"},
+	"/sticky": {tag: "Utility", summary: "Return the simulated instance pinned by the session cookie"},
+	"/at": {tag: "Utility", summary: "Respond at a wall-clock instant", params: []paramDoc{
+		param("time", "RFC 3339 instant of the response, at most 300s ahead"),
+	}},
+	"/barrier/{name}/{n}": {tag: "Utility", summary: "Hold requests until n arrived on the barrier, then release them together", params: []paramDoc{
+		param("name", "Barrier name"),
+		intParam("n", "Number of requests released together (1-1000)"),
+		intParam("timeout", "Seconds to wait before failing with 408 (default 30, max 300)"),
+	}},
+	"/head-mismatch": {tag: "Utility", summary: "Answer HEAD with a Content-Length that differs from GET", params: []paramDoc{
+		intParam("size", "Body size in bytes"),
+		param("head_size", "Content-Length of HEAD (default twice size, or none)"),
+	}},
+	"/connection":       {tag: "Utility", summary: "Connection number, reuse and keep-alive"},
+	"/health":           {tag: "Utility", summary: "Health check"},
+	"/config":           {tag: "Utility", summary: "Effective configuration, secrets redacted"},
+	"/network":          {tag: "Utility", summary: "Address family of the client connection"},
+	"/version":          {tag: "Utility", summary: "Build information and enabled features"},
+	"/ca.pem":           {tag: "Utility", summary: "CA certificate of the HTTPS and HTTP/3 listeners", content: "application/x-pem-file"},
+	OpenAPIDocumentPath: {tag: "Utility", summary: "OpenAPI description of the endpoints"},
+
+	// Redirect endpoints
+	"/redirect/{n}": {tag: "Redirect", summary: "Redirect n times", params: []paramDoc{
+		intParam("n", "Number of redirects"),
+	}},
+	"/redirect-to": {tag: "Redirect", summary: "Redirect to a URL", params: []paramDoc{
+		param("url", "Redirect target"),
+		intParam("status_code", "Redirect status code (default 302)"),
+	}},
+	"/absolute-redirect/{n}": {tag: "Redirect", summary: "Redirect n times with absolute URLs", params: []paramDoc{
+		intParam("n", "Number of redirects"),
+	}},
+	"/relative-redirect/{n}": {tag: "Redirect", summary: "Redirect n times with relative URLs", params: []paramDoc{
+		intParam("n", "Number of redirects"),
+	}},
+	"/redirect-chain": {tag: "Redirect", summary: "Start a redirect chain across hosts", params: []paramDoc{
+		param("hops", "Hops of the chain, such as host[:cookie][:auth],..."),
+		intParam("status_code", "Redirect status code (default 302)"),
+	}},
+	"/redirect-chain/{hop}": {tag: "Redirect", summary: "Hop of a redirect chain", params: []paramDoc{
+		intParam("hop", "Index of the hop"),
+		param("hops", "Hops of the chain, such as host[:cookie][:auth],..."),
+		intParam("status_code", "Redirect status code (default 302)"),
+	}},
+
+	// Authentication endpoints
+	"/basic-auth":  {tag: "Authentication", summary: "Require Basic authentication"},
+	"/bearer-auth": {tag: "Authentication", summary: "Require a Bearer token"},
+
+	// OAuth2/OIDC endpoints
+	"/.well-known/oauth-authorization-server": {tag: "OAuth2", summary: "Authorization server metadata (RFC 8414)"},
+	"/.well-known/openid-configuration":       {tag: "OAuth2", summary: "OpenID Connect discovery"},
+	"/.well-known/jwks.json":                  {tag: "OAuth2", summary: "JSON Web Key Set"},
+	"/oauth2/authorize": {tag: "OAuth2", summary: "Display the login form or process the authentication", body: "application/x-www-form-urlencoded", content: "text/html", params: []paramDoc{
+		param("response_type", "Response type", "code"),
+		param("client_id", "Client ID"),
+		param("redirect_uri", "Redirect URI"),
+		param("scope", "Requested scopes"),
+		param("state", "Opaque client state"),
+		param("nonce", "OpenID Connect nonce"),
+		param("code_challenge", "PKCE code challenge"),
+		param("code_challenge_method", "PKCE code challenge method", "plain", "S256"),
+	}},
+	"/oauth2/callback": {tag: "OAuth2", summary: "Display the authorization code", content: "text/html", params: []paramDoc{
+		param("code", "Authorization code"),
+		param("state", "Opaque client state"),
+		param("error", "Authorization error"),
+	}},
+	"/oauth2/token":    {tag: "OAuth2", summary: "Issue tokens", body: "application/x-www-form-urlencoded"},
+	"/oauth2/userinfo": {tag: "OAuth2", summary: "Claims of the access token"},
+	"/oauth2/demo":     {tag: "OAuth2", summary: "Interactive authorization code flow", content: "text/html"},
+
+	// Cookie endpoints
+	"/cookies":        {tag: "Cookies", summary: "Return the request cookies"},
+	"/cookies/set":    {tag: "Cookies", summary: "Set the cookies of the query parameters and redirect to /cookies"},
+	"/cookies/delete": {tag: "Cookies", summary: "Delete the cookies of the query parameters and redirect to /cookies"},
+
+	// Data generation endpoints
+	"/bytes/{n}": {tag: "Data", summary: "Return n random bytes", content: "application/octet-stream", params: []paramDoc{
+		intParam("n", "Number of bytes"),
+	}},
+	"/stream/{n}": {tag: "Data", summary: "Stream n JSON lines", params: []paramDoc{
+		intParam("n", "Number of lines"),
+	}},
+	"/drip": {tag: "Data", summary: "Drip bytes over a duration", content: "application/octet-stream", params: []paramDoc{
+		numberParam("duration", "Duration in seconds"),
+		intParam("numbytes", "Number of bytes"),
+		numberParam("delay", "Delay before the first byte in seconds"),
+	}},
+
+	// Charset endpoints
+	"/charset": {tag: "Charset", summary: "List the encodings and their sample text"},
+	"/charset/{enc}": {tag: "Charset", summary: "Text in an encoding", content: "text/plain", params: []paramDoc{
+		param("enc", "Encoding of the body"),
+		param("text", "Text to encode (default the sample text)"),
+		param("charset", "charset parameter of the Content-Type (default enc)"),
+		boolParam("bom", "Prepend the byte order mark"),
+	}},
+
+	// Body transformations
+	"/transform/{op}": {tag: "Transform", summary: "Transform the request body", body: "*/*", content: "application/octet-stream", params: []paramDoc{
+		param("op", "Transformation", "hash", "base64", "gzip", "aes", "json-canonical"),
+		param("algorithm", "Digest algorithm of hash (default sha256)", "md5", "sha1", "sha256", "sha384", "sha512"),
+		boolParam("url", "URL-safe alphabet of base64"),
+		param("nonce", "AES-GCM nonce of aes (24 hex digits)"),
+		param("encoding", "Encoding of binary results", "raw", "hex", "base64"),
+	}},
+
+	// Compression endpoints
+	"/gzip":    {tag: "Compression", summary: "Return a gzip-compressed response"},
+	"/deflate": {tag: "Compression", summary: "Return a deflate-compressed response"},
+	"/brotli":  {tag: "Compression", summary: "Return a brotli-compressed response"},
+
+	// Caching
+	"/cache-control": {tag: "Caching", summary: "Caching headers of the query parameters for a resource changing every period", params: []paramDoc{
+		param("cache_control", "Cache-Control header (default public, max-age=60, empty for none)"),
+		intParam("age", "Age header in seconds"),
+		param("vary", "Vary header"),
+		intParam("expires", "Expires header in seconds from now, negative for the past"),
+		param("surrogate_control", "Surrogate-Control header"),
+		intParam("period", "Seconds between generations of the content (default 60)"),
+		intParam("revalidate_delay", "Delay of conditional requests in milliseconds (max 30000)"),
+	}},
+
+	// Client safety endpoints
+	"/bomb/{encoding}": {tag: "Client safety", summary: "Decompression bomb", content: "application/octet-stream", params: []paramDoc{
+		param("encoding", "Content-Encoding", "gzip", "deflate", "br"),
+		intParam("size", "Decompressed size in bytes"),
+	}},
+	"/bomb/content-length": {tag: "Client safety", summary: "Content-Length beyond the body", content: "application/octet-stream", params: []paramDoc{
+		intParam("declared", "Declared Content-Length"),
+		intParam("size", "Bytes actually sent"),
+	}},
+
+	// GraphQL
+	"/graphql": {tag: "GraphQL", summary: "echo-graphql queries, mutations and subscriptions", body: "application/json", methods: []string{http.MethodGet, http.MethodPost}},
+
+	// Runtime configuration
+	"/chaos":     {tag: "Runtime configuration", summary: "Fault injection", body: "application/json", methods: adminMethods},
+	"/ratelimit": {tag: "Runtime configuration", summary: "Rate limits", body: "application/json", methods: adminMethods},
+	"/script":    {tag: "Runtime configuration", summary: "Scripted scenarios", body: "application/json", methods: []string{http.MethodGet, http.MethodPut, http.MethodPost, http.MethodDelete}},
+	"/clients":   {tag: "Runtime configuration", summary: "Client profiles", body: "application/json", methods: adminMethods},
+	"/clock":     {tag: "Runtime configuration", summary: "Clock offset", body: "application/json", methods: adminMethods},
+	"/mirror":    {tag: "Runtime configuration", summary: "Request mirroring", body: "application/json", methods: adminMethods},
+}
+
+// openAPISchemas are the component schemas of the echo responses
+var openAPISchemas = map[string]any{
+	"EchoResponse": map[string]any{
+		"type":     "object",
+		"required": []string{"method", "url", "args", "headers"},
+		"properties": map[string]any{
+			"method":  map[string]any{"type": "string"},
+			"url":     map[string]any{"type": "string"},
+			"args":    map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "string"}},
+			"headers": map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "string"}},
+			"data":    map[string]any{"type": "string"},
+			"json":    map[string]any{},
+			"form":    map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "string"}},
+		},
+	},
+	"AnythingResponse": map[string]any{
+		"allOf": []any{
+			map[string]any{"$ref": "#/components/schemas/EchoResponse"},
+			map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"stored_id": map[string]any{"type": "string"},
+				},
+			},
+		},
+	},
+}
+
+// OpenAPIDocumentHandler serves an OpenAPI 3.1 description of the routes,
+// walked on every request so it lists exactly the endpoints enabled by the
+// configuration, under the base path of the request.
+// GET /openapi.json
+func OpenAPIDocumentHandler(routes chi.Routes, version string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		doc, err := NewOpenAPIDocument(routes, version, BasePath(r))
+		if err != nil {
+			http.Error(w, "Failed to describe the routes", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(doc)
+	}
+}
+
+// NewOpenAPIDocument describes the routes as an OpenAPI 3.1 document served
+// under basePath
+func NewOpenAPIDocument(routes chi.Routes, version, basePath string) (map[string]any, error) {
+	methods := map[string][]string{}
+	err := chi.Walk(routes, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		path := openAPIPath(route)
+		methods[path] = append(methods[path], method)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	paths := map[string]any{}
+	tags := []string{}
+	for path, routeMethods := range methods {
+		doc, ok := routeDocs[path]
+		if !ok {
+			doc = routeDoc{tag: "Other", summary: path}
+		}
+		if !slices.Contains(tags, doc.tag) {
+			tags = append(tags, doc.tag)
+		}
+		// Routes of every method register CONNECT as well
+		if slices.Contains(routeMethods, http.MethodConnect) {
+			routeMethods = openAPIOperationMethods
+			if doc.methods != nil {
+				routeMethods = doc.methods
+			}
+		}
+		item := map[string]any{}
+		for _, method := range routeMethods {
+			if !slices.Contains(openAPIOperationMethods, method) {
+				continue
+			}
+			item[strings.ToLower(method)] = doc.operation(method, path)
+		}
+		if params := doc.parameters(path); len(params) > 0 {
+			item["parameters"] = params
+		}
+		paths[path] = item
+	}
+	slices.Sort(tags)
+
+	server := basePath
+	if server == "" {
+		server = "/"
+	}
+	tagObjects := make([]any, len(tags))
+	for i, tag := range tags {
+		tagObjects[i] = map[string]any{"name": tag}
+	}
+	return map[string]any{
+		"openapi": "3.1.0",
+		"info": map[string]any{
+			"title":       "echo-http",
+			"description": "HTTP echo server for testing HTTP clients",
+			"version":     version,
+			"license":     map[string]any{"name": "MIT", "identifier": "MIT"},
+		},
+		"servers": []any{map[string]any{"url": server}},
+		"tags":    tagObjects,
+		"paths":   paths,
+		"components": map[string]any{
+			"schemas": openAPISchemas,
+		},
+	}, nil
+}
+
+// openAPIPath converts a chi route pattern to an OpenAPI path: the
+// parameters lose their regular expression and a trailing wildcard becomes
+// the {path} parameter
+func openAPIPath(route string) string {
+	if route != "/" {
+		route = strings.TrimSuffix(route, "/")
+	}
+	if prefix, ok := strings.CutSuffix(route, "/*"); ok {
+		route = prefix + "/{path}"
+	}
+	return routeParamPattern.ReplaceAllString(route, "{$1}")
+}
+
+// parameters returns the parameters of the path item: the path parameters
+// of path and the query parameters
+func (d routeDoc) parameters(path string) []any {
+	inPath := map[string]bool{}
+	for _, m := range routeParamPattern.FindAllStringSubmatch(path, -1) {
+		inPath[m[1]] = true
+	}
+	var params []any
+	documented := map[string]bool{}
+	for _, p := range d.params {
+		documented[p.name] = true
+		schema := map[string]any{"type": p.typ}
+		if len(p.enum) > 0 {
+			schema["enum"] = p.enum
+		}
+		param := map[string]any{
+			"name":        p.name,
+			"in":          "query",
+			"description": p.description,
+			"schema":      schema,
+		}
+		if inPath[p.name] {
+			param["in"] = "path"
+			param["required"] = true
+		}
+		params = append(params, param)
+	}
+	for _, m := range routeParamPattern.FindAllStringSubmatch(path, -1) {
+		if !documented[m[1]] {
+			params = append(params, map[string]any{
+				"name":     m[1],
+				"in":       "path",
+				"required": true,
+				"schema":   map[string]any{"type": "string"},
+			})
+		}
+	}
+	return params
+}
+
+// operation returns the operation of method on path
+func (d routeDoc) operation(method, path string) map[string]any {
+	content := d.content
+	if content == "" {
+		content = "application/json"
+	}
+	schema := map[string]any{}
+	if d.schema != "" {
+		schema = map[string]any{"$ref": "#/components/schemas/" + d.schema}
+	}
+	op := map[string]any{
+		"operationId": operationID(method, path),
+		"summary":     d.summary,
+		"tags":        []string{d.tag},
+		"responses": map[string]any{
+			"default": map[string]any{
+				"description": d.summary,
+				"content": map[string]any{
+					content: map[string]any{"schema": schema},
+				},
+			},
+		},
+	}
+	if d.body != "" && method != http.MethodGet && method != http.MethodDelete {
+		op["requestBody"] = map[string]any{
+			"content": map[string]any{
+				d.body: map[string]any{"schema": map[string]any{}},
+			},
+		}
+	}
+	return op
+}
+
+// operationID names the operation of method on path, such as
+// "getStatusCode" for GET /status/{code}
+func operationID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	upper := true
+	for _, c := range path {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+			if upper {
+				b.WriteString(strings.ToUpper(string(c)))
+			} else {
+				b.WriteRune(c)
+			}
+			upper = false
+		default:
+			upper = true
+		}
+	}
+	return b.String()
+}
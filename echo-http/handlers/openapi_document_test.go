@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestOpenAPIPath(t *testing.T) {
+	tests := []struct {
+		route string
+		want  string
+	}{
+		{route: "/", want: "/"},
+		{route: "/get", want: "/get"},
+		{route: "/status/{code}", want: "/status/{code}"},
+		{route: "/items/{id:[0-9]+}", want: "/items/{id}"},
+		{route: "/anything/*", want: "/anything/{path}"},
+	}
+
+	for _, tt := range tests {
+		if got := openAPIPath(tt.route); got != tt.want {
+			t.Errorf("openAPIPath(%q) = %q, want %q", tt.route, got, tt.want)
+		}
+	}
+}
+
+func TestOpenAPIDocumentHandler(t *testing.T) {
+	r := chi.NewRouter()
+	r.Get("/get", EchoHandler)
+	r.HandleFunc("/status/{code}", StatusHandler)
+	r.Post("/transform/{op}", TransformHandler)
+	r.Get("/custom", EchoHandler)
+	r.Get(OpenAPIDocumentPath, OpenAPIDocumentHandler(r, "v1.2.3"))
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, OpenAPIDocumentPath, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+
+	var doc struct {
+		OpenAPI string `json:"openapi"`
+		Info    struct {
+			Version string `json:"version"`
+		} `json:"info"`
+		Servers []struct {
+			URL string `json:"url"`
+		} `json:"servers"`
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.OpenAPI != "3.1.0" || doc.Info.Version != "v1.2.3" {
+		t.Errorf("unexpected openapi %q and version %q", doc.OpenAPI, doc.Info.Version)
+	}
+	if len(doc.Servers) != 1 || doc.Servers[0].URL != "/" {
+		t.Errorf("expected the server /, got %+v", doc.Servers)
+	}
+
+	wantOperations := map[string][]string{
+		"/get":            {"get"},
+		"/status/{code}":  {"get", "post", "put", "patch", "delete"},
+		"/transform/{op}": {"post"},
+		"/custom":         {"get"},
+		"/openapi.json":   {"get"},
+	}
+	if len(doc.Paths) != len(wantOperations) {
+		t.Errorf("expected %d paths, got %d", len(wantOperations), len(doc.Paths))
+	}
+	for path, methods := range wantOperations {
+		for _, method := range methods {
+			if _, ok := doc.Paths[path][method]; !ok {
+				t.Errorf("expected operation %s %s", method, path)
+			}
+		}
+	}
+
+	var params []struct {
+		Name     string `json:"name"`
+		In       string `json:"in"`
+		Required bool   `json:"required"`
+		Schema   struct {
+			Type string   `json:"type"`
+			Enum []string `json:"enum"`
+		} `json:"schema"`
+	}
+	if err := json.Unmarshal(doc.Paths["/transform/{op}"]["parameters"], &params); err != nil {
+		t.Fatal(err)
+	}
+	if len(params) == 0 || params[0].Name != "op" || params[0].In != "path" || !params[0].Required || len(params[0].Schema.Enum) != 5 {
+		t.Errorf("unexpected parameters %+v", params)
+	}
+}
+
+func TestOpenAPIDocumentHandler_BasePath(t *testing.T) {
+	r := chi.NewRouter()
+	r.Get(OpenAPIDocumentPath, OpenAPIDocumentHandler(r, "dev"))
+	sites, err := NewSites("/echo", nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	sites.Handler(r).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/echo"+OpenAPIDocumentPath, nil))
+	var doc struct {
+		Servers []struct {
+			URL string `json:"url"`
+		} `json:"servers"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Servers) != 1 || doc.Servers[0].URL != "/echo" {
+		t.Errorf("expected the server /echo, got %+v", doc.Servers)
+	}
+}
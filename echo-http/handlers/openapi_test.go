@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+const petstore = `
+openapi: 3.0.3
+servers:
+  - url: https://petstore.example.com/v1
+paths:
+  /pets:
+    get:
+      responses:
+        200:
+          description: Pets
+          headers:
+            X-Total-Count:
+              schema: {type: integer, minimum: 2}
+          content:
+            application/json:
+              schema:
+                type: array
+                items: {$ref: '#/components/schemas/Pet'}
+    post:
+      responses:
+        "201":
+          description: Created
+  /pets/{petId}:
+    get:
+      responses:
+        "200":
+          description: Pet
+          content:
+            application/json:
+              examples:
+                cat: {value: {id: 1, name: Tom}}
+                dog: {$ref: '#/components/examples/Dog'}
+        "404":
+          description: Not found
+          content:
+            application/json:
+              example: {message: not found}
+  /pets/mine:
+    get:
+      responses:
+        default:
+          description: Mine
+          content:
+            text/plain:
+              example: mine
+components:
+  schemas:
+    Pet:
+      type: object
+      properties:
+        id: {type: integer, format: int64}
+        name: {type: string, example: Rex}
+        tag: {type: string, enum: [dog, cat]}
+        born: {type: string, format: date}
+        owner: {$ref: '#/components/schemas/Owner'}
+    Owner:
+      allOf:
+        - type: object
+          properties:
+            email: {type: string, format: email}
+        - properties:
+            friends:
+              type: array
+              items: {$ref: '#/components/schemas/Owner'}
+  examples:
+    Dog:
+      value: {id: 2, name: Rex}
+`
+
+func TestOpenAPIMock(t *testing.T) {
+	mock, err := NewOpenAPIMock([]byte(petstore))
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	h := mock.Middleware(next)
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		prefer      string
+		status      int
+		contentType string
+		body        string
+	}{
+		{"example", "GET", "/v1/pets/7", "", 200, "application/json", `{"id":1,"name":"Tom"}`},
+		{"named example", "GET", "/v1/pets/7", "example=dog", 200, "application/json", `{"id":2,"name":"Rex"}`},
+		{"preferred code", "GET", "/v1/pets/7", "code=404", 404, "application/json", `{"message":"not found"}`},
+		{"literal segment", "GET", "/v1/pets/mine", "", 200, "text/plain", "mine"},
+		{"no content", "POST", "/v1/pets", "", 201, "", ""},
+		{"method not in the spec", "DELETE", "/v1/pets", "", 405, "text/plain; charset=utf-8", "Method not allowed\n"},
+		{"path not in the spec", "GET", "/v1/owners", "", 418, "", ""},
+		{"outside the base path", "GET", "/pets", "", 418, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.prefer != "" {
+				req.Header.Set("Prefer", tt.prefer)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if got := rec.Header().Get("Content-Type"); got != tt.contentType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.contentType)
+			}
+			if got := rec.Body.String(); got != tt.body {
+				t.Errorf("body = %q, want %q", got, tt.body)
+			}
+		})
+	}
+}
+
+func TestOpenAPIMock_Schema(t *testing.T) {
+	mock, err := NewOpenAPIMock([]byte(petstore))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := httptest.NewRecorder()
+	mock.Middleware(http.NotFoundHandler()).ServeHTTP(rec, httptest.NewRequest("GET", "/v1/pets", nil))
+
+	if got := rec.Header().Get("X-Total-Count"); got != "2" {
+		t.Errorf("X-Total-Count = %q, want 2", got)
+	}
+	var pets []map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &pets); err != nil {
+		t.Fatalf("body %s: %v", rec.Body, err)
+	}
+	if len(pets) != 1 {
+		t.Fatalf("pets = %v, want one generated pet", pets)
+	}
+	pet := pets[0]
+	for key, want := range map[string]any{"id": 0.0, "name": "Rex", "tag": "dog", "born": "1970-01-01"} {
+		if !reflect.DeepEqual(pet[key], want) {
+			t.Errorf("%s = %v, want %v", key, pet[key], want)
+		}
+	}
+	owner, _ := pet["owner"].(map[string]any)
+	if owner["email"] != "user@example.com" {
+		t.Errorf("owner = %v, want the merged allOf properties", owner)
+	}
+}
+
+func TestNewOpenAPIMock_Invalid(t *testing.T) {
+	for name, spec := range map[string]string{
+		"not an object": "- a",
+		"no paths":      "openapi: 3.0.3",
+		"invalid YAML":  "paths: [",
+	} {
+		if _, err := NewOpenAPIMock([]byte(spec)); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// defaultRedirectChainHosts are the alias hosts of /redirect-chain without
+// REDIRECT_CHAIN_HOSTS: two names of the same server that clients treat as
+// different hosts
+var defaultRedirectChainHosts = []string{"localhost", "127.0.0.1"}
+
+// chainHop is one hop of a redirect chain
+type chainHop struct {
+	host   int
+	cookie bool
+	auth   bool
+}
+
+type RedirectChainResponse struct {
+	Redirected    bool              `json:"redirected"`
+	Hops          int               `json:"hops"`
+	Host          string            `json:"host"`
+	Cookies       map[string]string `json:"cookies"`
+	Authorization string            `json:"authorization,omitempty"`
+}
+
+// RedirectChainHandler redirects through the hops of ?hops= across the
+// alias hosts of REDIRECT_CHAIN_HOSTS, setting cookies and requiring
+// authentication at the hops flagged, then reports what the last hop
+// received.
+// GET /redirect-chain?hops={host}[:cookie][:auth],...&status_code={code} - Start the chain
+// GET /redirect-chain/{hop}?hops=... - Hop of the chain
+func RedirectChainHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	hosts := redirectChainHosts()
+	hops, err := parseChainHops(query.Get("hops"), len(hosts))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	statusCode := http.StatusFound
+	if codeStr := query.Get("status_code"); codeStr != "" {
+		code, err := strconv.Atoi(codeStr)
+		if err != nil || !isRedirectCode(code) {
+			http.Error(w, "status_code must be a redirect code (301, 302, 303, 307, 308)", http.StatusBadRequest)
+			return
+		}
+		statusCode = code
+	}
+
+	// The entry request redirects to the first hop
+	next := 0
+	if hopStr := chi.URLParam(r, "hop"); hopStr != "" {
+		i, err := strconv.Atoi(hopStr)
+		if err != nil || i < 0 || i >= len(hops) {
+			http.Error(w, fmt.Sprintf("Invalid hop (must be 0-%d)", len(hops)-1), http.StatusBadRequest)
+			return
+		}
+		hop := hops[i]
+		w.Header().Set("X-Chain-Hop", strconv.Itoa(i))
+
+		if hop.auth && !chainAuthorized(r) {
+			w.Header().Set("WWW-Authenticate", `Basic realm="Restricted"`)
+			http.Error(w, fmt.Sprintf("Hop %d requires authentication", i), http.StatusUnauthorized)
+			return
+		}
+		if hop.cookie {
+			http.SetCookie(w, &http.Cookie{
+				Name:     fmt.Sprintf("hop%d", i),
+				Value:    hostOnly(r.Host),
+				Path:     "/",
+				HttpOnly: true,
+				SameSite: http.SameSiteLaxMode,
+			})
+		}
+
+		if i == len(hops)-1 {
+			writeRedirectChainResult(w, r, len(hops))
+			return
+		}
+		next = i + 1
+	}
+
+	location := chainOrigin(r, hosts[hops[next].host]) + "/redirect-chain/" + strconv.Itoa(next) + "?" + r.URL.RawQuery
+	http.Redirect(w, r, location, statusCode)
+}
+
+// writeRedirectChainResult reports the cookies and the authorization the
+// last hop received
+func writeRedirectChainResult(w http.ResponseWriter, r *http.Request, hops int) {
+	response := RedirectChainResponse{
+		Redirected:    true,
+		Hops:          hops,
+		Host:          r.Host,
+		Cookies:       make(map[string]string),
+		Authorization: r.Header.Get("Authorization"),
+	}
+	for _, cookie := range r.Cookies() {
+		response.Cookies[cookie.Name] = cookie.Value
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// parseChainHops parses hops such as "1,0:cookie,1:auth", each the index
+// of its alias host followed by its flags
+func parseChainHops(s string, hosts int) ([]chainHop, error) {
+	if s == "" {
+		return nil, fmt.Errorf("missing required parameter: hops")
+	}
+	parts := strings.Split(s, ",")
+	if len(parts) > maxRedirectCount {
+		return nil, fmt.Errorf("too many hops (must be 1-%d)", maxRedirectCount)
+	}
+	hops := make([]chainHop, 0, len(parts))
+	for _, part := range parts {
+		fields := strings.Split(strings.TrimSpace(part), ":")
+		host, err := strconv.Atoi(fields[0])
+		if err != nil || host < 0 || host >= hosts {
+			return nil, fmt.Errorf("invalid hop %q: host must be 0-%d", part, hosts-1)
+		}
+		hop := chainHop{host: host}
+		for _, flag := range fields[1:] {
+			switch flag {
+			case "cookie":
+				hop.cookie = true
+			case "auth":
+				hop.auth = true
+			default:
+				return nil, fmt.Errorf("invalid hop %q: unknown flag %q (cookie or auth)", part, flag)
+			}
+		}
+		hops = append(hops, hop)
+	}
+	return hops, nil
+}
+
+// chainAuthorized reports whether r carries the Basic credentials or the
+// bearer token accepted by /basic-auth and /bearer-auth
+func chainAuthorized(r *http.Request) bool {
+	if user, pass, ok := r.BasicAuth(); ok {
+		return validateBasicAuthCredentials(user, pass) == nil
+	}
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	return ok && strings.EqualFold(scheme, "Bearer") && token != "" && validateBearerToken(token)
+}
+
+// chainOrigin returns the origin of alias, a host or a URL such as
+// "https://127.0.0.1:8443", taking the scheme and the port of r when
+// missing
+func chainOrigin(r *http.Request, alias string) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if fwdProto := r.Header.Get("X-Forwarded-Proto"); fwdProto != "" {
+		scheme = fwdProto
+	}
+	if u, err := url.Parse(alias); err == nil && u.Scheme != "" && u.Host != "" {
+		scheme, alias = u.Scheme, u.Host
+	}
+	if _, _, err := net.SplitHostPort(alias); err != nil {
+		if _, port, err := net.SplitHostPort(r.Host); err == nil {
+			alias = net.JoinHostPort(strings.Trim(alias, "[]"), port)
+		}
+	}
+	return scheme + "://" + alias
+}
+
+// hostOnly returns host without its port
+func hostOnly(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		return h
+	}
+	return host
+}
+
+func redirectChainHosts() []string {
+	if globalConfig != nil && len(globalConfig.RedirectChainHosts) > 0 {
+		return globalConfig.RedirectChainHosts
+	}
+	return defaultRedirectChainHosts
+}
+
+func isRedirectCode(code int) bool {
+	switch code {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
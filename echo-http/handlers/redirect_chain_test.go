@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// newRedirectChainServer serves /redirect-chain with the default alias
+// hosts, localhost and 127.0.0.1
+func newRedirectChainServer(t *testing.T) (*httptest.Server, *http.Client) {
+	t.Helper()
+	originalConfig := globalConfig
+	globalConfig = &Config{AuthAllowedUsername: "testuser", AuthAllowedPassword: "testpass"}
+	t.Cleanup(func() { globalConfig = originalConfig })
+
+	r := chi.NewRouter()
+	r.Get("/redirect-chain", RedirectChainHandler)
+	r.Get("/redirect-chain/{hop}", RedirectChainHandler)
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return server, &http.Client{Jar: jar}
+}
+
+func TestRedirectChainHandler_ScopesCookiesByHost(t *testing.T) {
+	server, client := newRedirectChainServer(t)
+
+	resp, err := client.Get(server.URL + "/redirect-chain?hops=0:cookie,1:cookie,0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", resp.StatusCode)
+	}
+
+	var result RedirectChainResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Hops != 3 || !strings.HasPrefix(result.Host, "localhost:") {
+		t.Errorf("expected 3 hops ending on localhost, got %+v", result)
+	}
+	if result.Cookies["hop0"] != "localhost" {
+		t.Errorf("expected the cookie set by localhost, got %v", result.Cookies)
+	}
+	if _, ok := result.Cookies["hop1"]; ok {
+		t.Errorf("expected the cookie of 127.0.0.1 not sent to localhost, got %v", result.Cookies)
+	}
+}
+
+func TestRedirectChainHandler_RequiresAuthMidChain(t *testing.T) {
+	server, client := newRedirectChainServer(t)
+
+	tests := []struct {
+		name           string
+		hops           string
+		expectedStatus int
+	}{
+		{"same host keeps credentials", "1,1:auth", http.StatusOK},
+		{"other host strips credentials", "1,0:auth,1", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, server.URL+"/redirect-chain?hops="+tt.hops, nil)
+			req.SetBasicAuth("testuser", "testpass")
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_ = resp.Body.Close()
+			if resp.StatusCode != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestRedirectChainHandler_Location(t *testing.T) {
+	tests := []struct {
+		name             string
+		path             string
+		expectedStatus   int
+		expectedLocation string
+	}{
+		{
+			name:             "entry redirects to the first hop",
+			path:             "/redirect-chain?hops=1,0",
+			expectedStatus:   http.StatusFound,
+			expectedLocation: "http://127.0.0.1:8080/redirect-chain/0?hops=1,0",
+		},
+		{
+			name:             "hop redirects to the next host with the status code",
+			path:             "/redirect-chain/0?hops=1,0&status_code=307",
+			expectedStatus:   http.StatusTemporaryRedirect,
+			expectedLocation: "http://localhost:8080/redirect-chain/1?hops=1,0&status_code=307",
+		},
+		{
+			name:           "missing hops returns 400",
+			path:           "/redirect-chain",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown host returns 400",
+			path:           "/redirect-chain?hops=2",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown flag returns 400",
+			path:           "/redirect-chain?hops=0:secure",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "hop out of range returns 400",
+			path:           "/redirect-chain/2?hops=0,1",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "non-redirect status code returns 400",
+			path:           "/redirect-chain?hops=0&status_code=200",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	r := chi.NewRouter()
+	r.Get("/redirect-chain", RedirectChainHandler)
+	r.Get("/redirect-chain/{hop}", RedirectChainHandler)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Host = "localhost:8080"
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if tt.expectedLocation != "" && w.Header().Get("Location") != tt.expectedLocation {
+				t.Errorf("expected Location %q, got %q", tt.expectedLocation, w.Header().Get("Location"))
+			}
+		})
+	}
+}
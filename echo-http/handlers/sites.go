@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// SiteHeader names the virtual host that served the request
+const SiteHeader = "X-Echo-Site"
+
+// defaultSite names the site of the requests matching no virtual host
+const defaultSite = "default"
+
+// Site is a logical echo server: the endpoints under BasePath
+type Site struct {
+	Name     string
+	BasePath string
+}
+
+type siteKey struct{}
+
+// Sites routes the requests to the site of their Host header, each serving
+// the endpoints under its base path, so several logical echo servers share
+// one instance behind path-routing ingresses
+type Sites struct {
+	def    Site
+	hosts  []Site
+	strict bool
+}
+
+// NewSites returns the sites of virtualHosts, "host" or "host=/base"
+// entries where host may start with "*." to match subdomains, with the
+// default site under basePath. Unless strict, requests for other hosts are
+// served by the default site; strict sites answer them 421 Misdirected
+// Request.
+func NewSites(basePath string, virtualHosts []string, strict bool) (*Sites, error) {
+	def, err := cleanBasePath(basePath)
+	if err != nil {
+		return nil, err
+	}
+	s := &Sites{def: Site{Name: defaultSite, BasePath: def}, strict: strict}
+	for _, entry := range virtualHosts {
+		host, base, _ := strings.Cut(entry, "=")
+		host = strings.ToLower(strings.TrimSpace(host))
+		if host == "" || strings.Trim(host, "*.") == "" {
+			return nil, fmt.Errorf("virtual host %q: missing host", entry)
+		}
+		if strings.Contains(host[1:], "*") || strings.HasPrefix(host, "*") && !strings.HasPrefix(host, "*.") {
+			return nil, fmt.Errorf("virtual host %q: only a leading *. wildcard is supported", entry)
+		}
+		clean, err := cleanBasePath(strings.TrimSpace(base))
+		if err != nil {
+			return nil, fmt.Errorf("virtual host %q: %w", entry, err)
+		}
+		s.hosts = append(s.hosts, Site{Name: host, BasePath: clean})
+	}
+	return s, nil
+}
+
+// cleanBasePath returns base without its trailing slash, "" for the root
+func cleanBasePath(base string) (string, error) {
+	if base == "" || base == "/" {
+		return "", nil
+	}
+	if !strings.HasPrefix(base, "/") {
+		return "", fmt.Errorf("base path %q must start with /", base)
+	}
+	return strings.TrimRight(base, "/"), nil
+}
+
+// String describes the sites for the startup log
+func (s *Sites) String() string {
+	parts := []string{"base_path=" + siteBasePath(s.def)}
+	for _, h := range s.hosts {
+		parts = append(parts, h.Name+"="+siteBasePath(h))
+	}
+	if s.strict {
+		parts = append(parts, "strict")
+	}
+	return strings.Join(parts, " ")
+}
+
+func siteBasePath(site Site) string {
+	if site.BasePath == "" {
+		return "/"
+	}
+	return site.BasePath
+}
+
+// match returns the site of host, the first virtual host matching it
+func (s *Sites) match(host string) (Site, bool) {
+	host = strings.ToLower(hostOnly(host))
+	for _, h := range s.hosts {
+		if suffix, ok := strings.CutPrefix(h.Name, "*"); ok {
+			if strings.HasSuffix(host, suffix) {
+				return h, true
+			}
+		} else if host == h.Name {
+			return h, true
+		}
+	}
+	return s.def, !s.strict
+}
+
+// Handler serves the requests with next, their path relative to the base
+// path of their site. Paths outside the base path are not found, and
+// Location headers of absolute paths on the same host get the base path.
+func (s *Sites) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		site, ok := s.match(r.Host)
+		if !ok {
+			http.Error(w, fmt.Sprintf("Unknown host %q", hostOnly(r.Host)), http.StatusMisdirectedRequest)
+			return
+		}
+		if len(s.hosts) > 0 {
+			w.Header().Set(SiteHeader, site.Name)
+		}
+		if site.BasePath == "" {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), siteKey{}, site)))
+			return
+		}
+
+		rest, ok := strings.CutPrefix(r.URL.Path, site.BasePath)
+		if !ok || rest != "" && !strings.HasPrefix(rest, "/") {
+			http.NotFound(w, r)
+			return
+		}
+		if rest == "" {
+			rest = "/"
+		}
+		r2 := r.WithContext(context.WithValue(r.Context(), siteKey{}, site))
+		r2.URL = new(url.URL)
+		*r2.URL = *r.URL
+		r2.URL.Path = rest
+		r2.URL.RawPath = ""
+		if raw, ok := strings.CutPrefix(r.URL.RawPath, site.BasePath); ok {
+			r2.URL.RawPath = raw
+		}
+		next.ServeHTTP(&basePathWriter{ResponseWriter: w, site: site, host: r.Host}, r2)
+	})
+}
+
+// BasePath returns the base path of the site serving r, "" for the root
+func BasePath(r *http.Request) string {
+	site, _ := r.Context().Value(siteKey{}).(Site)
+	return site.BasePath
+}
+
+// basePathWriter adds the base path of the site to the Location header
+type basePathWriter struct {
+	http.ResponseWriter
+	site  Site
+	host  string
+	wrote bool
+}
+
+func (w *basePathWriter) WriteHeader(code int) {
+	w.rewriteLocation()
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *basePathWriter) Write(p []byte) (int, error) {
+	w.rewriteLocation()
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *basePathWriter) Flush() {
+	w.rewriteLocation()
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *basePathWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.rewriteLocation()
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+// Unwrap gives http.ResponseController access to the underlying writer
+func (w *basePathWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *basePathWriter) rewriteLocation() {
+	if w.wrote {
+		return
+	}
+	w.wrote = true
+	location := w.Header().Get("Location")
+	if location == "" {
+		return
+	}
+	u, err := url.Parse(location)
+	if err != nil || !strings.HasPrefix(u.Path, "/") || u.Host != "" && u.Host != w.host {
+		return
+	}
+	if u.Path == w.site.BasePath || strings.HasPrefix(u.Path, w.site.BasePath+"/") {
+		return
+	}
+	u.Path = w.site.BasePath + u.Path
+	u.RawPath = ""
+	w.Header().Set("Location", u.String())
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func newSitesRouter(t *testing.T, basePath string, virtualHosts []string, strict bool) http.Handler {
+	t.Helper()
+	sites, err := NewSites(basePath, virtualHosts, strict)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := chi.NewRouter()
+	r.Get("/get", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.URL.Path + " " + BasePath(r)))
+	})
+	r.Get("/redirect", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, r.URL.Query().Get("to"), http.StatusFound)
+	})
+	return sites.Handler(r)
+}
+
+func TestSites_BasePath(t *testing.T) {
+	handler := newSitesRouter(t, "/echo/", nil, false)
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "prefixed path", path: "/echo/get", wantStatus: http.StatusOK, wantBody: "/get /echo"},
+		{name: "unprefixed path", path: "/get", wantStatus: http.StatusNotFound},
+		{name: "partial segment", path: "/echoget", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if tt.wantBody != "" && rec.Body.String() != tt.wantBody {
+				t.Errorf("expected body %q, got %q", tt.wantBody, rec.Body.String())
+			}
+			if rec.Header().Get(SiteHeader) != "" {
+				t.Errorf("expected no %s header without virtual hosts", SiteHeader)
+			}
+		})
+	}
+}
+
+func TestSites_Location(t *testing.T) {
+	handler := newSitesRouter(t, "/echo", nil, false)
+
+	tests := []struct {
+		to   string
+		want string
+	}{
+		{to: "/get", want: "/echo/get"},
+		{to: "/echo/get", want: "/echo/get"},
+		{to: "http://example.com/get?a=1", want: "http://example.com/echo/get?a=1"},
+		{to: "http://other.test/get", want: "http://other.test/get"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.to, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/echo/redirect?to="+tt.to, nil)
+			req.Host = "example.com"
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if got := rec.Header().Get("Location"); got != tt.want {
+				t.Errorf("expected Location %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSites_VirtualHosts(t *testing.T) {
+	hosts := []string{"a.test=/a", "*.b.test=/b", "c.test"}
+
+	tests := []struct {
+		name       string
+		strict     bool
+		host       string
+		path       string
+		wantStatus int
+		wantSite   string
+		wantBody   string
+	}{
+		{name: "exact host", host: "a.test:8080", path: "/a/get", wantStatus: http.StatusOK, wantSite: "a.test", wantBody: "/get /a"},
+		{name: "wildcard host", host: "x.B.test", path: "/b/get", wantStatus: http.StatusOK, wantSite: "*.b.test", wantBody: "/get /b"},
+		{name: "host without base path", host: "c.test", path: "/get", wantStatus: http.StatusOK, wantSite: "c.test", wantBody: "/get "},
+		{name: "base path of another host", host: "a.test", path: "/b/get", wantStatus: http.StatusNotFound, wantSite: "a.test"},
+		{name: "unknown host", host: "d.test", path: "/echo/get", wantStatus: http.StatusOK, wantSite: "default", wantBody: "/get /echo"},
+		{name: "unknown host strict", strict: true, host: "d.test", path: "/echo/get", wantStatus: http.StatusMisdirectedRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := newSitesRouter(t, "/echo", hosts, tt.strict)
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Host = tt.host
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if got := rec.Header().Get(SiteHeader); got != tt.wantSite {
+				t.Errorf("expected site %q, got %q", tt.wantSite, got)
+			}
+			if tt.wantBody != "" && rec.Body.String() != tt.wantBody {
+				t.Errorf("expected body %q, got %q", tt.wantBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestNewSites_Invalid(t *testing.T) {
+	tests := []struct {
+		basePath string
+		hosts    []string
+	}{
+		{basePath: "echo"},
+		{hosts: []string{"=/a"}},
+		{hosts: []string{"a.*.test"}},
+		{hosts: []string{"*b.test"}},
+		{hosts: []string{"a.test=a"}},
+	}
+
+	for _, tt := range tests {
+		if _, err := NewSites(tt.basePath, tt.hosts, false); err == nil {
+			t.Errorf("NewSites(%q, %q): expected an error", tt.basePath, tt.hosts)
+		}
+	}
+}
@@ -1,12 +1,23 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+	"golang.org/x/net/http/httpguts"
 )
 
+// StatusHandler returns the status code of the path, including codes
+// without a standard meaning such as 299 or 599.
+// ANY /status/{code}?reason={phrase}&body={body}&header={Name: value} - Return the status code
+//
+// The reason phrase replaces the standard one on HTTP/1.x responses, which
+// are then written on the hijacked connection and close it; HTTP/2 and
+// HTTP/3 have no reason phrases.
 func StatusHandler(w http.ResponseWriter, r *http.Request) {
 	codeStr := chi.URLParam(r, "code")
 	code, err := strconv.Atoi(codeStr)
@@ -15,5 +26,91 @@ func StatusHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	query := r.URL.Query()
+	header := make(http.Header)
+	for _, h := range query["header"] {
+		name, value, ok := strings.Cut(h, ":")
+		name = strings.TrimSpace(name)
+		if !ok || !httpguts.ValidHeaderFieldName(name) {
+			http.Error(w, fmt.Sprintf("Invalid header %q (must be Name: value)", h), http.StatusBadRequest)
+			return
+		}
+		header.Add(name, strings.TrimSpace(value))
+	}
+	reason := query.Get("reason")
+	if strings.ContainsAny(reason, "\r\n") {
+		http.Error(w, "Invalid reason phrase", http.StatusBadRequest)
+		return
+	}
+	if reason != "" && code < 200 {
+		http.Error(w, "reason is not supported for 1xx status codes", http.StatusBadRequest)
+		return
+	}
+
+	body := query.Get("body")
+	if !statusAllowsBody(code) {
+		body = ""
+	}
+	for name, values := range header {
+		w.Header()[name] = values
+	}
+	if body != "" && w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	}
+
+	if reason != "" && r.ProtoMajor == 1 && writeStatusWithReason(w, r, code, reason, body) {
+		return
+	}
 	w.WriteHeader(code)
+	if body != "" {
+		_, _ = w.Write([]byte(body))
+	}
+}
+
+// writeStatusWithReason writes the response on the hijacked connection,
+// the only way to send a custom reason phrase, and closes it. It reports
+// false when the connection cannot be hijacked.
+func writeStatusWithReason(w http.ResponseWriter, r *http.Request, code int, reason, body string) bool {
+	return writeRawResponse(w, r, code, reason, nil, body)
+}
+
+// writeRawResponse writes an HTTP/1.1 response on the hijacked connection
+// of w, with the headers of w followed by the raw header lines, and closes
+// it. It reports false when the connection cannot be hijacked.
+func writeRawResponse(w http.ResponseWriter, r *http.Request, code int, reason string, lines []string, body string) bool {
+	conn, buf, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		return false
+	}
+	defer func() { _ = conn.Close() }()
+
+	// Header.Write strips CR and LF from values but not from names
+	header := w.Header().Clone()
+	for name := range header {
+		if !httpguts.ValidHeaderFieldName(name) {
+			delete(header, name)
+		}
+	}
+	header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	header.Set("Connection", "close")
+	if statusAllowsBody(code) {
+		header.Set("Content-Length", strconv.Itoa(len(body)))
+	}
+
+	_, _ = fmt.Fprintf(buf, "HTTP/1.1 %03d %s\r\n", code, reason)
+	_ = header.Write(buf)
+	for _, line := range lines {
+		_, _ = buf.WriteString(line + "\r\n")
+	}
+	_, _ = buf.WriteString("\r\n")
+	if r.Method != http.MethodHead {
+		_, _ = buf.WriteString(body)
+	}
+	_ = buf.Flush()
+	return true
+}
+
+// statusAllowsBody reports whether responses with code may have content
+func statusAllowsBody(code int) bool {
+	return code >= 200 && code != http.StatusNoContent && code != http.StatusNotModified
 }
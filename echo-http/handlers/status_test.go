@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -62,3 +63,72 @@ func TestStatusHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestStatusHandler_NonstandardResponse(t *testing.T) {
+	r := chi.NewRouter()
+	r.HandleFunc("/status/{code}", StatusHandler)
+
+	req := httptest.NewRequest(http.MethodGet, "/status/299?body=partial&header=X-Custom:%20one&header=X-Custom:two", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != 299 {
+		t.Errorf("expected status 299, got %d", rec.Code)
+	}
+	if rec.Body.String() != "partial" {
+		t.Errorf("expected body %q, got %q", "partial", rec.Body.String())
+	}
+	if got := rec.Header().Values("X-Custom"); len(got) != 2 || got[0] != "one" || got[1] != "two" {
+		t.Errorf("expected X-Custom [one two], got %v", got)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/status/200?header=invalid", nil)
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for a header without a colon, got %d", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/status/200?reason=OK&header=X%0d%0aSet-Cookie%3a%20injected:v", nil)
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for a header name with CRLF, got %d", rec.Code)
+	}
+	if rec.Header().Get("Set-Cookie") != "" {
+		t.Errorf("expected no Set-Cookie header, got %q", rec.Header().Get("Set-Cookie"))
+	}
+}
+
+func TestStatusHandler_ReasonPhrase(t *testing.T) {
+	r := chi.NewRouter()
+	r.HandleFunc("/status/{code}", StatusHandler)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/status/599?reason=Network%20Connect%20Timeout&body=timeout&header=Retry-After:%205")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.Status != "599 Network Connect Timeout" {
+		t.Errorf("expected status line %q, got %q", "599 Network Connect Timeout", resp.Status)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "timeout" {
+		t.Errorf("expected body %q, got %q", "timeout", body)
+	}
+	if got := resp.Header.Get("Retry-After"); got != "5" {
+		t.Errorf("expected Retry-After 5, got %q", got)
+	}
+
+	resp, err = http.Get(server.URL + "/status/100?reason=Custom")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status 400 for a 1xx reason phrase, got %d", resp.StatusCode)
+	}
+}
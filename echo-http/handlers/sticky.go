@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+const (
+	defaultStickyInstances = 3
+	defaultStickyCookie    = "echo_sticky"
+)
+
+// InstanceIDHeader carries the simulated instance of /sticky
+const InstanceIDHeader = "X-Instance-Id"
+
+// stickySessions counts the sessions of /sticky, spreading the new ones
+// across the instances in turn
+var stickySessions atomic.Uint64
+
+// stickyInstances returns the number of simulated instances of /sticky
+func stickyInstances() int {
+	if globalConfig != nil && globalConfig.StickyInstances > 0 {
+		return globalConfig.StickyInstances
+	}
+	return defaultStickyInstances
+}
+
+// stickyCookie returns the name of the session cookie of /sticky
+func stickyCookie() string {
+	if globalConfig != nil && globalConfig.StickyCookie != "" {
+		return globalConfig.StickyCookie
+	}
+	return defaultStickyCookie
+}
+
+// parseStickySession returns the instance of a session cookie value, such
+// as "echo-2.9f86d081884c7d65", or false when it names no current instance
+func parseStickySession(value string, instances int) (int, bool) {
+	name, _, ok := strings.Cut(value, ".")
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(name, "echo-"))
+	if err != nil || !strings.HasPrefix(name, "echo-") || n < 1 || n > instances {
+		return 0, false
+	}
+	return n, true
+}
+
+// StickyHandler simulates load-balanced instances with session affinity:
+// a request without a session cookie is assigned the next instance and
+// gets a cookie pinning it, later requests with the cookie stay on it. A
+// cookie naming an instance beyond STICKY_INSTANCES starts a new session,
+// like a load balancer whose backend went away.
+// ANY /sticky - Return the instance of the session
+func StickyHandler(w http.ResponseWriter, r *http.Request) {
+	instances := stickyInstances()
+	name := stickyCookie()
+
+	var instance int
+	var session string
+	newSession := true
+	if cookie, err := r.Cookie(name); err == nil {
+		if n, ok := parseStickySession(cookie.Value, instances); ok {
+			instance, session, newSession = n, cookie.Value, false
+		}
+	}
+	if newSession {
+		random, err := generateRandomString(8)
+		if err != nil {
+			http.Error(w, "Failed to create session", http.StatusInternalServerError)
+			return
+		}
+		instance = int((stickySessions.Add(1)-1)%uint64(instances)) + 1
+		session = fmt.Sprintf("echo-%d.%s", instance, random)
+		path := BasePath(r)
+		if path == "" {
+			path = "/"
+		}
+		http.SetCookie(w, &http.Cookie{
+			Name:     name,
+			Value:    session,
+			Path:     path,
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		})
+	}
+
+	response := map[string]any{
+		"instance":    fmt.Sprintf("echo-%d", instance),
+		"instances":   instances,
+		"session":     session,
+		"new_session": newSession,
+	}
+	w.Header().Set(InstanceIDHeader, fmt.Sprintf("echo-%d", instance))
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
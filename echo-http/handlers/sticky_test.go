@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type stickyResponse struct {
+	Instance   string `json:"instance"`
+	Session    string `json:"session"`
+	NewSession bool   `json:"new_session"`
+}
+
+func serveSticky(t *testing.T, cookie *http.Cookie) (stickyResponse, *httptest.ResponseRecorder) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/sticky", nil)
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	rec := httptest.NewRecorder()
+	StickyHandler(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	var resp stickyResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if got := rec.Header().Get(InstanceIDHeader); got != resp.Instance {
+		t.Errorf("expected %s %s, got %s", InstanceIDHeader, resp.Instance, got)
+	}
+	return resp, rec
+}
+
+func TestStickyHandler(t *testing.T) {
+	originalConfig := globalConfig
+	defer func() { globalConfig = originalConfig }()
+	globalConfig = &Config{StickyInstances: 3, StickyCookie: "lb"}
+
+	// New sessions are spread across the instances
+	instances := map[string]bool{}
+	var cookies []*http.Cookie
+	for range 3 {
+		resp, rec := serveSticky(t, nil)
+		if !resp.NewSession {
+			t.Error("expected a new session without a cookie")
+		}
+		instances[resp.Instance] = true
+		cookies = append(cookies, rec.Result().Cookies()...)
+	}
+	if len(instances) != 3 || len(cookies) != 3 {
+		t.Fatalf("expected 3 instances and cookies, got %v and %d cookies", instances, len(cookies))
+	}
+
+	// A session stays on its instance
+	first, _ := serveSticky(t, cookies[0])
+	again, rec := serveSticky(t, cookies[0])
+	if first.NewSession || again.Instance != first.Instance || first.Session != cookies[0].Value {
+		t.Errorf("expected the session %s to stick, got %+v and %+v", cookies[0].Value, first, again)
+	}
+	if len(rec.Result().Cookies()) != 0 {
+		t.Error("expected no new cookie for an existing session")
+	}
+
+	// Instances that went away start new sessions
+	for _, value := range []string{"echo-9.abcd", "garbage", "other-1.abcd"} {
+		if resp, _ := serveSticky(t, &http.Cookie{Name: "lb", Value: value}); !resp.NewSession {
+			t.Errorf("expected a new session for %q", value)
+		}
+	}
+}
@@ -116,7 +116,9 @@ func DripHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Initial delay
 	if delay > 0 {
-		time.Sleep(time.Duration(delay * float64(time.Second)))
+		if currentClock().Sleep(r.Context(), time.Duration(delay*float64(time.Second))) != nil {
+			return
+		}
 	}
 
 	w.Header().Set("Content-Type", "application/octet-stream")
@@ -131,8 +133,8 @@ func DripHandler(w http.ResponseWriter, r *http.Request) {
 	for range numBytes {
 		_, _ = w.Write([]byte("*"))
 		flusher.Flush()
-		if interval > 0 {
-			time.Sleep(interval)
+		if interval > 0 && currentClock().Sleep(r.Context(), interval) != nil {
+			return
 		}
 	}
 }
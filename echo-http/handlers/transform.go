@@ -0,0 +1,284 @@
+package handlers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// maxTransformBodySize limits the request body of /transform
+const maxTransformBodySize = 10 << 20 // 10MB
+
+// DefaultTransformAESKey is the static AES-256 key of /transform/aes
+// without TRANSFORM_AES_KEY, the bytes 0x00 to 0x1f
+const DefaultTransformAESKey = "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"
+
+// transformHashes are the algorithms of /transform/hash
+var transformHashes = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha384": sha512.New384,
+	"sha512": sha512.New,
+}
+
+// TransformHandler returns the request body transformed, a deterministic
+// target for clients verifying server-side transforms.
+// POST /transform/hash?algorithm={md5|sha1|sha256|sha384|sha512} - Digest of the body (hex)
+// POST /transform/base64?url={true|false} - Base64 of the body
+// POST /transform/gzip - Body compressed as a gzip file
+// POST /transform/aes?nonce={hex} - Body encrypted with AES-GCM and the static key
+// POST /transform/json-canonical - JSON body canonicalized after RFC 8785
+//
+// The binary results are raw unless ?encoding=hex or ?encoding=base64.
+func TransformHandler(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxTransformBodySize))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Body too large (max %d bytes)", maxTransformBodySize), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	query := r.URL.Query()
+	op := chi.URLParam(r, "op")
+	contentType := "application/octet-stream"
+	encoding := query.Get("encoding")
+	var result []byte
+	switch op {
+	case "hash":
+		algorithm := query.Get("algorithm")
+		if algorithm == "" {
+			algorithm = "sha256"
+		}
+		newHash, ok := transformHashes[algorithm]
+		if !ok {
+			http.Error(w, "Invalid algorithm (must be md5, sha1, sha256, sha384 or sha512)", http.StatusBadRequest)
+			return
+		}
+		h := newHash()
+		h.Write(body)
+		result = h.Sum(nil)
+		if encoding == "" {
+			encoding = "hex"
+		}
+		w.Header().Set("X-Transform-Algorithm", algorithm)
+	case "base64":
+		enc := base64.StdEncoding
+		if query.Get("url") == "true" {
+			enc = base64.URLEncoding
+		}
+		result = []byte(enc.EncodeToString(body))
+		contentType = "text/plain; charset=utf-8"
+		encoding = "raw"
+	case "gzip":
+		result = gzipTransform(body)
+		contentType = "application/gzip"
+	case "aes":
+		nonce := make([]byte, 12)
+		if s := query.Get("nonce"); s != "" {
+			if nonce, err = hex.DecodeString(s); err != nil || len(nonce) != 12 {
+				http.Error(w, "Invalid nonce (must be 24 hex digits)", http.StatusBadRequest)
+				return
+			}
+		}
+		if result, err = aesTransform(body, nonce); err != nil {
+			http.Error(w, "Failed to encrypt body", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("X-Transform-Nonce", hex.EncodeToString(nonce))
+	case "json-canonical":
+		if result, err = canonicalJSON(body); err != nil {
+			http.Error(w, fmt.Sprintf("Invalid JSON body: %v", err), http.StatusBadRequest)
+			return
+		}
+		contentType = "application/json"
+		encoding = "raw"
+	default:
+		http.Error(w, "Unknown transform (must be hash, base64, gzip, aes or json-canonical)", http.StatusNotFound)
+		return
+	}
+
+	switch encoding {
+	case "", "raw":
+	case "hex":
+		result = []byte(hex.EncodeToString(result))
+		contentType = "text/plain; charset=utf-8"
+	case "base64":
+		result = []byte(base64.StdEncoding.EncodeToString(result))
+		contentType = "text/plain; charset=utf-8"
+	default:
+		http.Error(w, "Invalid encoding (must be raw, hex or base64)", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Transform", op)
+	w.Header().Set("Content-Length", strconv.Itoa(len(result)))
+	_, _ = w.Write(result)
+}
+
+// gzipTransform compresses body at the default level without a name or
+// modification time, so equal bodies give equal files
+func gzipTransform(body []byte) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, _ = gz.Write(body)
+	_ = gz.Close()
+	return buf.Bytes()
+}
+
+// aesTransform encrypts body with AES-GCM under the static key and
+// nonce, returning the ciphertext followed by the tag
+func aesTransform(body, nonce []byte) ([]byte, error) {
+	key, _ := hex.DecodeString(DefaultTransformAESKey)
+	if globalConfig != nil && len(globalConfig.TransformAESKey) > 0 {
+		key = globalConfig.TransformAESKey
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nil, nonce, body, nil), nil
+}
+
+// canonicalJSON serializes the JSON value of body after the JSON
+// Canonicalization Scheme (RFC 8785): object members sorted by their UTF-16
+// code units, no whitespace, ECMAScript number and minimal string escaping
+func canonicalJSON(body []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, errors.New("unexpected data after JSON value")
+	}
+	var buf bytes.Buffer
+	if err := writeCanonicalJSON(&buf, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeCanonicalJSON(buf *bytes.Buffer, v any) error {
+	switch v := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil || math.IsInf(f, 0) {
+			return fmt.Errorf("number %s out of range", v)
+		}
+		buf.WriteString(canonicalNumber(f))
+	case string:
+		writeCanonicalString(buf, v)
+	case []any:
+		buf.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonicalJSON(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		slices.SortFunc(keys, compareUTF16)
+		buf.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeCanonicalString(buf, k)
+			buf.WriteByte(':')
+			if err := writeCanonicalJSON(buf, v[k]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	}
+	return nil
+}
+
+// canonicalNumber formats f like ECMAScript Number.prototype.toString
+func canonicalNumber(f float64) string {
+	if f == 0 {
+		return "0"
+	}
+	if abs := math.Abs(f); abs >= 1e-6 && abs < 1e21 {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	// Exponent without leading zeros, such as 1e-7 and 1e+21
+	s := strconv.FormatFloat(f, 'e', -1, 64)
+	mantissa, exponent, _ := strings.Cut(s, "e")
+	sign, digits := exponent[:1], strings.TrimLeft(exponent[1:], "0")
+	return mantissa + "e" + sign + digits
+}
+
+// writeCanonicalString writes s quoted, escaping only the quote, the
+// backslash and the control characters
+func writeCanonicalString(buf *bytes.Buffer, s string) {
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(buf, `\u%04x`, r)
+			} else {
+				buf.WriteRune(r)
+			}
+		}
+	}
+	buf.WriteByte('"')
+}
+
+// compareUTF16 orders strings by their UTF-16 code units, as RFC 8785 sorts
+// object members
+func compareUTF16(a, b string) int {
+	return slices.Compare(utf16.Encode([]rune(a)), utf16.Encode([]rune(b)))
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func serveTransform(path, body string) *httptest.ResponseRecorder {
+	r := chi.NewRouter()
+	r.Post("/transform/{op}", TransformHandler)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+	return rec
+}
+
+func TestTransformHandler(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		body     string
+		wantBody string
+	}{
+		{name: "sha256", path: "/transform/hash", body: "hello", wantBody: "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"},
+		{name: "md5", path: "/transform/hash?algorithm=md5", body: "hello", wantBody: "5d41402abc4b2a76b9719d911017c592"},
+		{name: "sha1 base64", path: "/transform/hash?algorithm=sha1&encoding=base64", body: "hello", wantBody: "qvTGHdzF6KLavt4PO0gs2a6pQ00="},
+		{name: "base64", path: "/transform/base64", body: "hi?>", wantBody: "aGk/Pg=="},
+		{name: "base64 url", path: "/transform/base64?url=true", body: "hi?>", wantBody: "aGk_Pg=="},
+		{
+			name:     "json canonical",
+			path:     "/transform/json-canonical",
+			body:     `{"b": [1.0, 1e21, 0.0000001, -0], "a": "€\n", "é": true, "😀": null, "ﬁ": 1}`,
+			wantBody: `{"a":"€\n","b":[1,1e+21,1e-7,0],"é":true,"😀":null,"ﬁ":1}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serveTransform(tt.path, tt.body)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+			}
+			if rec.Body.String() != tt.wantBody {
+				t.Errorf("expected %q, got %q", tt.wantBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestTransformHandler_Gzip(t *testing.T) {
+	first := serveTransform("/transform/gzip", "hello gzip")
+	second := serveTransform("/transform/gzip", "hello gzip")
+	if !bytes.Equal(first.Body.Bytes(), second.Body.Bytes()) {
+		t.Error("expected equal bodies to give equal gzip files")
+	}
+	gz, err := gzip.NewReader(first.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(gz)
+	if err != nil || string(got) != "hello gzip" {
+		t.Errorf("expected the body back, got %q (%v)", got, err)
+	}
+}
+
+func TestTransformHandler_AES(t *testing.T) {
+	nonce := "000102030405060708090a0b"
+	rec := serveTransform("/transform/aes?nonce="+nonce, "secret message")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("X-Transform-Nonce"); got != nonce {
+		t.Errorf("expected nonce %s, got %s", nonce, got)
+	}
+
+	key, _ := hex.DecodeString(DefaultTransformAESKey)
+	block, _ := aes.NewCipher(key)
+	gcm, _ := cipher.NewGCM(block)
+	n, _ := hex.DecodeString(nonce)
+	plain, err := gcm.Open(nil, n, rec.Body.Bytes(), nil)
+	if err != nil || string(plain) != "secret message" {
+		t.Errorf("expected the body back, got %q (%v)", plain, err)
+	}
+}
+
+func TestTransformHandler_Errors(t *testing.T) {
+	tests := []struct {
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{path: "/transform/rot13", wantStatus: http.StatusNotFound},
+		{path: "/transform/hash?algorithm=crc32", wantStatus: http.StatusBadRequest},
+		{path: "/transform/hash?encoding=base32", wantStatus: http.StatusBadRequest},
+		{path: "/transform/aes?nonce=00", wantStatus: http.StatusBadRequest},
+		{path: "/transform/json-canonical", body: `{"a":1} {}`, wantStatus: http.StatusBadRequest},
+		{path: "/transform/json-canonical", body: `1e400`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		if rec := serveTransform(tt.path, tt.body); rec.Code != tt.wantStatus {
+			t.Errorf("%s: expected status %d, got %d", tt.path, tt.wantStatus, rec.Code)
+		}
+	}
+}
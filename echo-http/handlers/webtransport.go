@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/quic-go/webtransport-go"
+)
+
+// maxUniStreamSize bounds the unidirectional streams echoed back, which are
+// read completely before the reply stream is opened
+const maxUniStreamSize = 1 << 20
+
+// WebTransportEchoHandler returns the handler of the WebTransport echo
+// session: bidirectional streams are echoed on the same stream,
+// unidirectional streams on a new server stream, and datagrams as
+// datagrams. It must be served by server, on HTTP/3 only.
+func WebTransportEchoHandler(server *webtransport.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		session, err := server.Upgrade(w, r)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "WebTransport upgrade failed", "error", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		go echoStreams(session)
+		go echoUniStreams(session)
+		go echoDatagrams(session)
+	}
+}
+
+func echoStreams(session *webtransport.Session) {
+	ctx := session.Context()
+	for {
+		stream, err := session.AcceptStream(ctx)
+		if err != nil {
+			return
+		}
+		go func() {
+			_, _ = io.Copy(stream, stream)
+			_ = stream.Close()
+		}()
+	}
+}
+
+func echoUniStreams(session *webtransport.Session) {
+	ctx := session.Context()
+	for {
+		stream, err := session.AcceptUniStream(ctx)
+		if err != nil {
+			return
+		}
+		go func() {
+			data, err := io.ReadAll(io.LimitReader(stream, maxUniStreamSize))
+			if err != nil {
+				return
+			}
+			reply, err := session.OpenUniStreamSync(ctx)
+			if err != nil {
+				return
+			}
+			_, _ = reply.Write(data)
+			_ = reply.Close()
+		}()
+	}
+}
+
+func echoDatagrams(session *webtransport.Session) {
+	ctx := session.Context()
+	for {
+		data, err := session.ReceiveDatagram(ctx)
+		if err != nil {
+			return
+		}
+		_ = session.SendDatagram(data)
+	}
+}
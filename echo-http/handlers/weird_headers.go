@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	maxWeirdHeaderDuplicates = 100
+	maxWeirdHeaderLength     = 1 << 20 // 1MB, the default limit of Go clients
+	maxWeirdHeaderFolds      = 100
+)
+
+// Values of the non-ASCII header of /weird-headers
+var nonASCIIValues = map[string]string{
+	"utf8":   "café ✓",
+	"latin1": "caf\xe9",
+}
+
+type WeirdHeader struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type WeirdHeadersResponse struct {
+	// Raw reports whether the headers were written as is on the HTTP/1.x
+	// connection; HTTP/2 and HTTP/3 cannot carry folded lines
+	Raw     bool          `json:"raw"`
+	Headers []WeirdHeader `json:"headers"`
+}
+
+// WeirdHeadersHandler emits headers that strain client parsers: duplicates,
+// very long values, obs-fold continuation lines and non-ASCII bytes.
+// GET /weird-headers?duplicate={n}&long={bytes}&fold={lines}&non_ascii={utf8|latin1|none}
+func WeirdHeadersHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	duplicate, err := weirdHeaderCount(query.Get("duplicate"), 2, maxWeirdHeaderDuplicates)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid duplicate (must be 0-%d)", maxWeirdHeaderDuplicates), http.StatusBadRequest)
+		return
+	}
+	long, err := weirdHeaderCount(query.Get("long"), 8192, maxWeirdHeaderLength)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid long (must be 0-%d)", maxWeirdHeaderLength), http.StatusBadRequest)
+		return
+	}
+	fold, err := weirdHeaderCount(query.Get("fold"), 2, maxWeirdHeaderFolds)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid fold (must be 0-%d)", maxWeirdHeaderFolds), http.StatusBadRequest)
+		return
+	}
+	nonASCII := query.Get("non_ascii")
+	if nonASCII == "" {
+		nonASCII = "utf8"
+	}
+	if _, ok := nonASCIIValues[nonASCII]; !ok && nonASCII != "none" {
+		http.Error(w, "Invalid non_ascii (must be utf8, latin1 or none)", http.StatusBadRequest)
+		return
+	}
+
+	var headers []WeirdHeader
+	for i := range duplicate {
+		headers = append(headers, WeirdHeader{Name: "X-Duplicate", Value: fmt.Sprintf("value-%d", i+1)})
+	}
+	if long > 0 {
+		headers = append(headers, WeirdHeader{Name: "X-Long", Value: strings.Repeat("a", long)})
+	}
+	if value, ok := nonASCIIValues[nonASCII]; ok {
+		headers = append(headers, WeirdHeader{Name: "X-Non-Ascii", Value: value})
+	}
+	foldable := len(headers)
+	if fold > 0 {
+		lines := []string{"line-0"}
+		for i := range fold {
+			lines = append(lines, fmt.Sprintf(" line-%d", i+1))
+		}
+		headers = append(headers, WeirdHeader{Name: "X-Folded", Value: strings.Join(lines, "\r\n")})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	response := WeirdHeadersResponse{Raw: r.ProtoMajor == 1, Headers: headers}
+	if response.Raw {
+		body, _ := json.Marshal(response)
+		lines := make([]string, len(headers))
+		for i, h := range headers {
+			lines[i] = h.Name + ": " + h.Value
+		}
+		if writeRawResponse(w, r, http.StatusOK, http.StatusText(http.StatusOK), lines, string(body)+"\n") {
+			return
+		}
+	}
+
+	// HTTP/2 and HTTP/3 carry the headers without the folded one
+	response.Raw = false
+	response.Headers = headers[:foldable]
+	for _, h := range response.Headers {
+		w.Header().Add(h.Name, h.Value)
+	}
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// weirdHeaderCount parses a count of 0 to limit, defaultValue when empty
+func weirdHeaderCount(s string, defaultValue, limit int) (int, error) {
+	if s == "" {
+		return defaultValue, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 || n > limit {
+		return 0, fmt.Errorf("invalid count %q", s)
+	}
+	return n, nil
+}
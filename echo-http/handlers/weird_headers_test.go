@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWeirdHeadersHandler_Raw(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(WeirdHeadersHandler))
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", server.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+	_, _ = io.WriteString(conn, "GET /weird-headers?duplicate=3&long=10&fold=1&non_ascii=latin1 HTTP/1.1\r\nHost: test\r\n\r\n")
+	raw, err := io.ReadAll(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	head, _, _ := strings.Cut(string(raw), "\r\n\r\n")
+	for _, want := range []string{
+		"\r\nX-Duplicate: value-1\r\nX-Duplicate: value-2\r\nX-Duplicate: value-3\r\n",
+		"\r\nX-Long: aaaaaaaaaa\r\n",
+		"\r\nX-Non-Ascii: caf\xe9\r\n",
+		"\r\nX-Folded: line-0\r\n line-1",
+	} {
+		if !strings.Contains(head+"\r\n", want) {
+			t.Errorf("expected %q in the response headers:\n%s", want, head)
+		}
+	}
+}
+
+func TestWeirdHeadersHandler_Fallback(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/weird-headers?long=0", nil)
+	rec := httptest.NewRecorder()
+	WeirdHeadersHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	var resp WeirdHeadersResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Raw || len(resp.Headers) != 3 {
+		t.Errorf("expected the 3 headers without the folded one, got %+v", resp)
+	}
+	if got := rec.Header().Values("X-Duplicate"); len(got) != 2 {
+		t.Errorf("expected 2 X-Duplicate headers, got %v", got)
+	}
+	if rec.Header().Get("X-Folded") != "" {
+		t.Error("expected no folded header without a raw connection")
+	}
+}
+
+func TestWeirdHeadersHandler_InvalidParameters(t *testing.T) {
+	for _, query := range []string{"duplicate=-1", "long=2000000", "fold=x", "non_ascii=ebcdic"} {
+		rec := httptest.NewRecorder()
+		WeirdHeadersHandler(rec, httptest.NewRequest(http.MethodGet, "/weird-headers?"+query, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", query, rec.Code)
+		}
+	}
+}
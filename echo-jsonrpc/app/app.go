@@ -0,0 +1,186 @@
+// Package app runs echo-jsonrpc: LoadConfig reads its settings and Run
+// serves it until its context ends, for the echo-jsonrpc binary and echo-
+// all.
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/probitas-test/echo-servers/echo-jsonrpc/docs"
+	"github.com/probitas-test/echo-servers/echo-jsonrpc/server"
+	"github.com/probitas-test/echo-servers/shared/admin"
+	"github.com/probitas-test/echo-servers/shared/bandwidth"
+	"github.com/probitas-test/echo-servers/shared/connlimit"
+	"github.com/probitas-test/echo-servers/shared/lifecycle"
+	"github.com/probitas-test/echo-servers/shared/logging"
+	"github.com/probitas-test/echo-servers/shared/network"
+	"github.com/probitas-test/echo-servers/shared/recording"
+	"github.com/probitas-test/echo-servers/shared/tracing"
+	"github.com/probitas-test/echo-servers/shared/version"
+)
+
+// Run serves echo-jsonrpc with cfg until ctx is done, then shuts it down
+// gracefully. Logging and the address family are set up by the caller, as
+// they are shared by the servers of a process.
+func Run(ctx context.Context, cfg *Config) error {
+	// Started servers stop when Run returns early with an error
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Simulated slow startup: no port opens before the startup delay
+	if err := cfg.Lifecycle.Validate(); err != nil {
+		return fmt.Errorf("invalid lifecycle configuration: %w", err)
+	}
+	log.Printf("Lifecycle: %s", cfg.Lifecycle)
+	lifecycle.Delay(ctx, cfg.Lifecycle)
+
+	// OpenTelemetry tracing and W3C trace context propagation
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		return fmt.Errorf("failed to set up tracing: %w", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Error("Tracing shutdown error", "error", err)
+		}
+	}()
+	log.Printf("OpenTelemetry tracing: %s", cfg.Tracing)
+
+	// Admin API: health, call delay and the recorded notifications
+	adm := admin.New("echo-jsonrpc", cfg.src)
+
+	rpc := server.New(server.Config{
+		BatchMaxSize:   cfg.BatchMaxSize,
+		MaxMessageSize: cfg.MaxMessageSize,
+		Wait:           adm.Wait,
+	})
+	adm.Store("notifications", func() { rpc.ClearNotifications() })
+	adm.State("notifications", func() any { return rpc.NotificationCount() })
+
+	mux := http.NewServeMux()
+
+	// JSON-RPC over HTTP POST
+	mux.Handle("POST /rpc", rpc)
+	mux.Handle("POST /{$}", rpc)
+
+	// JSON-RPC over WebSocket
+	mux.HandleFunc("GET /ws", rpc.ServeWebSocket)
+
+	// Health check endpoint
+	mux.Handle("GET /health", adm.HealthHandler())
+
+	// Effective configuration (secrets redacted)
+	mux.Handle("GET /config", cfg.src)
+
+	// Address family of the client connection
+	mux.Handle("GET "+network.Path, network.Handler())
+
+	// Build information and enabled features
+	mux.Handle("GET "+version.Path, version.Handler(version.New("echo-jsonrpc", version.Features{
+		"admin":     cfg.Admin.Enabled,
+		"debug":     cfg.Admin.Debug,
+		"recording": cfg.Recording.Enabled,
+		"tracing":   cfg.Tracing.Enabled,
+	})))
+
+	// API documentation endpoint
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		_, _ = w.Write([]byte(docs.API))
+	})
+
+	// Traffic recording, toggled and downloaded through the admin API
+	recorder, err := recording.New("echo-jsonrpc", cfg.Recording)
+	if err != nil {
+		return err
+	}
+	log.Printf("Recording: %s", cfg.Recording)
+
+	// Bandwidth shaping of every JSON-RPC connection, changed at runtime on
+	// the admin port
+	shaper, err := bandwidth.New(cfg.Bandwidth)
+	if err != nil {
+		return err
+	}
+	log.Printf("Bandwidth: %s", cfg.Bandwidth)
+
+	// Cap on the open connections, changed at runtime on the admin port; the
+	// next ones get a server busy error
+	connLimiter, err := connlimit.New(cfg.ConnLimit)
+	if err != nil {
+		return err
+	}
+	log.Printf("Connection limit: %s", cfg.ConnLimit)
+
+	// Admin API on a separate port, with the bandwidth and recording admin
+	// APIs
+	var adminServer *http.Server
+	if cfg.Admin.Enabled {
+		adm.Handle(bandwidth.Path, bandwidth.Handler(shaper))
+		adm.Handle(connlimit.Path, connlimit.Handler(connLimiter))
+		recordings := recording.Handler(recorder)
+		adm.Handle(recording.Path, recordings)
+		adm.Handle(recording.Path+"/", recordings)
+		if cfg.Admin.Debug {
+			adm.Debug()
+		}
+		adminServer, err = adm.Serve(cfg.AdminAddr())
+		if err != nil {
+			return err
+		}
+		defer func() { _ = adminServer.Close() }()
+	}
+
+	// Readiness delay and simulated crash, counted from here
+	lifecycle.Start(ctx, cfg.Lifecycle, adm)
+
+	srv := &http.Server{
+		Addr:              cfg.Addr(),
+		Handler:           tracing.HTTP(nil)(logging.HTTP(recorder.HTTP(mux))),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	// Graceful shutdown (WebSocket connections are hijacked, so they are
+	// closed with 1001 going away rather than waited for)
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		<-ctx.Done()
+
+		log.Println("Shutting down server...")
+		log.Printf("Closed %d WebSocket connections", rpc.CloseAll(websocket.CloseGoingAway, "server shutting down"))
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := srv.Shutdown(ctx); err != nil {
+			slog.Error("Server shutdown error", "error", err)
+		}
+		if adminServer != nil {
+			_ = adminServer.Shutdown(ctx)
+		}
+	}()
+
+	log.Printf("Batch max size: %d (0 = unlimited), max message size: %d bytes (0 = unlimited)", cfg.BatchMaxSize, cfg.MaxMessageSize)
+	listener, err := network.Listen(cfg.Addr())
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	log.Printf("Starting server on %s", cfg.Addr())
+	if err := srv.Serve(shaper.Listen(connLimiter.Listen(listener, connlimit.HTTP))); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve: %w", err)
+	}
+	<-stopped
+
+	log.Println("Server stopped")
+	return nil
+}
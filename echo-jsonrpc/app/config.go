@@ -0,0 +1,91 @@
+package app
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/probitas-test/echo-servers/shared/admin"
+	"github.com/probitas-test/echo-servers/shared/bandwidth"
+	"github.com/probitas-test/echo-servers/shared/config"
+	"github.com/probitas-test/echo-servers/shared/connlimit"
+	"github.com/probitas-test/echo-servers/shared/lifecycle"
+	"github.com/probitas-test/echo-servers/shared/logging"
+	"github.com/probitas-test/echo-servers/shared/network"
+	"github.com/probitas-test/echo-servers/shared/recording"
+	"github.com/probitas-test/echo-servers/shared/tracing"
+)
+
+type Config struct {
+	Host string
+	Port string
+
+	// Largest number of requests in a batch (larger batches are answered
+	// with a single Invalid Request error)
+	BatchMaxSize int
+
+	// Largest request body or WebSocket message in bytes
+	MaxMessageSize int
+
+	// Admin API on a separate port (ADMIN_ENABLED, ADMIN_PORT)
+	Admin admin.Config
+
+	// Traffic recording (RECORDING_*), downloaded from the admin port
+	Recording recording.Config
+
+	// Bandwidth shaping of accepted connections (BANDWIDTH_*), changed at
+	// runtime at /bandwidth on the admin port
+	Bandwidth bandwidth.Config
+
+	// Connection cap (CONN_LIMIT_*), changed at runtime at /connlimit on the
+	// admin port
+	ConnLimit connlimit.Config
+
+	// Address family of every listener (IP_FAMILY: dual, ipv4 or ipv6)
+	Network network.Config
+
+	// Simulated startup delay, readiness delay and crash (STARTUP_DELAY_MS, STARTUP_READY_DELAY_MS, CRASH_AFTER_MS, CRASH_EXIT_CODE)
+	Lifecycle lifecycle.Config
+
+	// Structured logging (LOG_LEVEL, LOG_FORMAT)
+	Logging logging.Config
+
+	// OpenTelemetry tracing (OTEL_* variables)
+	Tracing tracing.Config
+
+	// Effective configuration, served at /config
+	src *config.Source
+}
+
+// LoadConfig reads the settings of echo-jsonrpc from src
+func LoadConfig(src *config.Source) (*Config, error) {
+	cfg := &Config{
+		Host: src.String("HOST", "0.0.0.0"),
+		Port: src.String("PORT", "8080"),
+
+		BatchMaxSize: src.Int("BATCH_MAX_SIZE", 100),
+
+		MaxMessageSize: src.Int("MAX_MESSAGE_SIZE", 1024*1024),
+
+		Admin:     admin.LoadConfig(src),
+		Tracing:   tracing.LoadConfig(src, "echo-jsonrpc"),
+		Recording: recording.LoadConfig(src),
+		Bandwidth: bandwidth.LoadConfig(src),
+		ConnLimit: connlimit.LoadConfig(src),
+		Network:   network.LoadConfig(src),
+		Lifecycle: lifecycle.LoadConfig(src),
+		Logging:   logging.LoadConfig(src),
+	}
+	cfg.src = src
+	if err := src.Err(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	return cfg, nil
+}
+
+func (c *Config) Addr() string {
+	return net.JoinHostPort(c.Host, c.Port)
+}
+
+func (c *Config) AdminAddr() string {
+	return net.JoinHostPort(c.Host, c.Admin.Port)
+}
@@ -0,0 +1,34 @@
+package server
+
+import (
+	"errors"
+	"io"
+	"net/http"
+)
+
+// ServeHTTP handles a single or batch request sent as the body of a POST.
+// Requests consisting only of notifications are answered with 204 No Content.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body := io.Reader(r.Body)
+	if s.cfg.MaxMessageSize > 0 {
+		body = http.MaxBytesReader(w, r.Body, int64(s.cfg.MaxMessageSize))
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "Failed to read request body", http.StatusBadRequest)
+		return
+	}
+
+	resp := s.handle(r.Context(), data, "http", nil)
+	if resp == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(resp)
+}
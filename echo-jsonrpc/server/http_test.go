@@ -0,0 +1,64 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestServeHTTP(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "request",
+			body:           `{"jsonrpc":"2.0","method":"echo","params":{"message":"hi"},"id":1}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"jsonrpc":"2.0","result":{"message":"hi"},"id":1}`,
+		},
+		{
+			name:           "parse error",
+			body:           `{`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"jsonrpc":"2.0","error":{"code":-32700,"message":"Parse error"},"id":null}`,
+		},
+		{
+			name:           "notification",
+			body:           `{"jsonrpc":"2.0","method":"echo"}`,
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "too large",
+			body:           `{"jsonrpc":"2.0","method":"echo","params":["` + strings.Repeat("x", 100) + `"],"id":1}`,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	s := New(Config{MaxMessageSize: 100})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/rpc", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			s.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, rec.Code)
+			}
+			if tt.expectedBody == "" {
+				return
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("expected Content-Type application/json, got %q", ct)
+			}
+			body, _ := io.ReadAll(rec.Body)
+			if string(body) != tt.expectedBody {
+				t.Errorf("expected body %s, got %s", tt.expectedBody, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,318 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// Version is the only protocol version accepted in the "jsonrpc" member
+const Version = "2.0"
+
+// Standard JSON-RPC 2.0 error codes
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+
+	// CodeServerError is the start of the implementation-defined server error
+	// range (-32000 to -32099)
+	CodeServerError = -32000
+)
+
+// notificationHistorySize is the number of client notifications kept for the
+// "notifications" method
+const notificationHistorySize = 100
+
+// Request is a JSON-RPC 2.0 request. A request without an id is a
+// notification and is never answered.
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	// ID is nil when absent (a notification) and "null" when explicitly null
+	ID json.RawMessage `json:"id,omitempty"`
+}
+
+// Response is a JSON-RPC 2.0 response carrying either a result or an error
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+	ID      json.RawMessage `json:"id"`
+}
+
+// Error is a JSON-RPC 2.0 error object
+type Error struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// newError builds an error whose data is marshaled from data (omitted when nil)
+func newError(code int, message string, data any) *Error {
+	e := &Error{Code: code, Message: message}
+	if data != nil {
+		if raw, err := json.Marshal(data); err == nil {
+			e.Data = raw
+		}
+	}
+	return e
+}
+
+// codeMessages are the default messages of the standard error codes
+var codeMessages = map[int]string{
+	CodeParseError:     "Parse error",
+	CodeInvalidRequest: "Invalid Request",
+	CodeMethodNotFound: "Method not found",
+	CodeInvalidParams:  "Invalid params",
+	CodeInternalError:  "Internal error",
+	CodeServerError:    "Server error",
+}
+
+// Config holds the server configuration
+type Config struct {
+	// Largest number of requests in a batch (0 = unlimited)
+	BatchMaxSize int
+
+	// Largest request body or WebSocket message in bytes (0 = unlimited)
+	MaxMessageSize int
+
+	// Wait, if set, runs before every method call, such as the delay of
+	// the admin API; its error fails the call
+	Wait func(ctx context.Context) error
+}
+
+// Notification is a client notification recorded by the server
+type Notification struct {
+	Method     string          `json:"method"`
+	Params     json.RawMessage `json:"params,omitempty"`
+	Transport  string          `json:"transport"`
+	ReceivedAt time.Time       `json:"receivedAt"`
+}
+
+// notifier pushes server-to-client notifications. It is only available on
+// transports with a persistent connection.
+type notifier interface {
+	notify(method string, params any) error
+}
+
+// call is the context of a single method invocation
+type call struct {
+	params    json.RawMessage
+	transport string
+	notifier  notifier
+}
+
+type method func(ctx context.Context, c *call) (any, error)
+
+// Server dispatches JSON-RPC 2.0 requests to the echo methods. It serves
+// HTTP POST requests via ServeHTTP and WebSocket connections via
+// WebSocketHandler.
+type Server struct {
+	cfg     Config
+	methods map[string]method
+
+	mu            sync.Mutex
+	notifications []Notification
+	conns         map[*wsConn]struct{}
+}
+
+// New creates a server with the echo methods registered
+func New(cfg Config) *Server {
+	s := &Server{
+		cfg:   cfg,
+		conns: make(map[*wsConn]struct{}),
+	}
+	s.methods = map[string]method{
+		"echo":          s.echo,
+		"delay":         s.delay,
+		"error":         s.raiseError,
+		"notify":        s.notify,
+		"notifications": s.listNotifications,
+	}
+	return s
+}
+
+// handle processes a single or batch request and returns the encoded
+// response, or nil when nothing is to be answered (only notifications)
+func (s *Server) handle(ctx context.Context, data []byte, transport string, n notifier) []byte {
+	data = bytes.TrimSpace(data)
+	if !json.Valid(data) {
+		return encode(errorResponse(nil, newError(CodeParseError, codeMessages[CodeParseError], nil)))
+	}
+
+	if data[0] != '[' {
+		resp := s.handleOne(ctx, data, transport, n)
+		if resp == nil {
+			return nil
+		}
+		return encode(resp)
+	}
+
+	var batch []json.RawMessage
+	if err := json.Unmarshal(data, &batch); err != nil {
+		return encode(errorResponse(nil, newError(CodeParseError, codeMessages[CodeParseError], nil)))
+	}
+	if len(batch) == 0 {
+		return encode(errorResponse(nil, newError(CodeInvalidRequest, codeMessages[CodeInvalidRequest], "empty batch")))
+	}
+	if s.cfg.BatchMaxSize > 0 && len(batch) > s.cfg.BatchMaxSize {
+		return encode(errorResponse(nil, newError(CodeInvalidRequest, codeMessages[CodeInvalidRequest],
+			map[string]int{"batchSize": len(batch), "batchMaxSize": s.cfg.BatchMaxSize})))
+	}
+
+	// Requests of a batch run concurrently; responses keep the request order
+	responses := make([]*Response, len(batch))
+	var wg sync.WaitGroup
+	for i, item := range batch {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			responses[i] = s.handleOne(ctx, item, transport, n)
+		}()
+	}
+	wg.Wait()
+
+	answered := make([]*Response, 0, len(responses))
+	for _, resp := range responses {
+		if resp != nil {
+			answered = append(answered, resp)
+		}
+	}
+	if len(answered) == 0 {
+		return nil
+	}
+	return encode(answered)
+}
+
+// handleOne processes a single request and returns its response, or nil for
+// a notification
+func (s *Server) handleOne(ctx context.Context, data json.RawMessage, transport string, n notifier) *Response {
+	var req Request
+	if err := json.Unmarshal(data, &req); err != nil {
+		return errorResponse(nil, newError(CodeInvalidRequest, codeMessages[CodeInvalidRequest], err.Error()))
+	}
+	if req.ID != nil && !validID(req.ID) {
+		return errorResponse(nil, newError(CodeInvalidRequest, codeMessages[CodeInvalidRequest], "id must be a string, number or null"))
+	}
+	if req.JSONRPC != Version {
+		return errorResponse(req.ID, newError(CodeInvalidRequest, codeMessages[CodeInvalidRequest], `jsonrpc must be "2.0"`))
+	}
+	if req.Method == "" {
+		return errorResponse(req.ID, newError(CodeInvalidRequest, codeMessages[CodeInvalidRequest], "method is required"))
+	}
+	if req.Params != nil && !validParams(req.Params) {
+		return errorResponse(req.ID, newError(CodeInvalidRequest, codeMessages[CodeInvalidRequest], "params must be an object or array"))
+	}
+
+	if req.ID == nil {
+		s.record(req, transport)
+	}
+
+	result, err := s.invoke(ctx, &req, transport, n)
+	if req.ID == nil {
+		return nil
+	}
+	if err != nil {
+		return errorResponse(req.ID, err)
+	}
+	return &Response{JSONRPC: Version, Result: result, ID: req.ID}
+}
+
+// invoke calls the requested method and encodes its result
+func (s *Server) invoke(ctx context.Context, req *Request, transport string, n notifier) (json.RawMessage, *Error) {
+	m, ok := s.methods[req.Method]
+	if !ok {
+		return nil, newError(CodeMethodNotFound, codeMessages[CodeMethodNotFound], map[string]string{"method": req.Method})
+	}
+
+	if s.cfg.Wait != nil {
+		if err := s.cfg.Wait(ctx); err != nil {
+			return nil, newError(CodeInternalError, codeMessages[CodeInternalError], err.Error())
+		}
+	}
+
+	result, err := m(ctx, &call{params: req.Params, transport: transport, notifier: n})
+	if err != nil {
+		if rpcErr, ok := err.(*Error); ok {
+			return nil, rpcErr
+		}
+		return nil, newError(CodeInternalError, codeMessages[CodeInternalError], err.Error())
+	}
+
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return nil, newError(CodeInternalError, codeMessages[CodeInternalError], err.Error())
+	}
+	return raw, nil
+}
+
+// record keeps a client notification for the "notifications" method
+func (s *Server) record(req Request, transport string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.notifications = append(s.notifications, Notification{
+		Method:     req.Method,
+		Params:     req.Params,
+		Transport:  transport,
+		ReceivedAt: time.Now().UTC(),
+	})
+	if len(s.notifications) > notificationHistorySize {
+		s.notifications = s.notifications[len(s.notifications)-notificationHistorySize:]
+	}
+}
+
+// ClearNotifications forgets the recorded client notifications, returning
+// how many were removed
+func (s *Server) ClearNotifications() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := len(s.notifications)
+	s.notifications = nil
+	return n
+}
+
+// NotificationCount returns the number of recorded client notifications
+func (s *Server) NotificationCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.notifications)
+}
+
+func errorResponse(id json.RawMessage, err *Error) *Response {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	return &Response{JSONRPC: Version, Error: err, ID: id}
+}
+
+// validID reports whether id is a string, number or null
+func validID(id json.RawMessage) bool {
+	switch id[0] {
+	case '"', 'n', '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		return true
+	}
+	return false
+}
+
+// validParams reports whether params is a structured value (object or array)
+func validParams(params json.RawMessage) bool {
+	return params[0] == '{' || params[0] == '['
+}
+
+func encode(v any) []byte {
+	data, err := json.Marshal(v)
+	if err != nil {
+		data, _ = json.Marshal(errorResponse(nil, newError(CodeInternalError, codeMessages[CodeInternalError], err.Error())))
+	}
+	return data
+}
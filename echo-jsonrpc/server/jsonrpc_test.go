@@ -0,0 +1,167 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+// handleJSON sends data to the server over the "test" transport and decodes the
+// response into v. It fails the test if the server answered nothing.
+func handleJSON(t *testing.T, s *Server, data string, v any) {
+	t.Helper()
+	resp := s.handle(context.Background(), []byte(data), "test", nil)
+	if resp == nil {
+		t.Fatalf("expected a response to %s", data)
+	}
+	if err := json.Unmarshal(resp, v); err != nil {
+		t.Fatalf("failed to decode response %s: %v", resp, err)
+	}
+}
+
+func TestHandle_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		code int
+		id   string
+	}{
+		{name: "invalid JSON", data: `{"jsonrpc":"2.0","method":"echo"`, code: CodeParseError, id: "null"},
+		{name: "empty body", data: ``, code: CodeParseError, id: "null"},
+		{name: "not an object", data: `1`, code: CodeInvalidRequest, id: "null"},
+		{name: "wrong version", data: `{"jsonrpc":"1.0","method":"echo","id":1}`, code: CodeInvalidRequest, id: "1"},
+		{name: "missing method", data: `{"jsonrpc":"2.0","id":"a"}`, code: CodeInvalidRequest, id: `"a"`},
+		{name: "method not a string", data: `{"jsonrpc":"2.0","method":1,"id":1}`, code: CodeInvalidRequest, id: "null"},
+		{name: "object id", data: `{"jsonrpc":"2.0","method":"echo","id":{}}`, code: CodeInvalidRequest, id: "null"},
+		{name: "scalar params", data: `{"jsonrpc":"2.0","method":"echo","params":1,"id":2}`, code: CodeInvalidRequest, id: "2"},
+		{name: "unknown method", data: `{"jsonrpc":"2.0","method":"nope","id":3}`, code: CodeMethodNotFound, id: "3"},
+		{name: "empty batch", data: `[]`, code: CodeInvalidRequest, id: "null"},
+	}
+
+	s := New(Config{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp Response
+			handleJSON(t, s, tt.data, &resp)
+			if resp.JSONRPC != Version {
+				t.Errorf("expected jsonrpc %q, got %q", Version, resp.JSONRPC)
+			}
+			if resp.Error == nil || resp.Error.Code != tt.code {
+				t.Fatalf("expected error code %d, got %+v", tt.code, resp.Error)
+			}
+			if string(resp.ID) != tt.id {
+				t.Errorf("expected id %s, got %s", tt.id, resp.ID)
+			}
+		})
+	}
+}
+
+func TestHandle_NullID(t *testing.T) {
+	s := New(Config{})
+	var resp Response
+	handleJSON(t, s, `{"jsonrpc":"2.0","method":"echo","params":[1],"id":null}`, &resp)
+	if resp.Error != nil {
+		t.Fatalf("unexpected error: %+v", resp.Error)
+	}
+	if string(resp.ID) != "null" || string(resp.Result) != "[1]" {
+		t.Errorf("expected result [1] with id null, got %s with id %s", resp.Result, resp.ID)
+	}
+}
+
+func TestHandle_Notification(t *testing.T) {
+	s := New(Config{})
+	resp := s.handle(context.Background(), []byte(`{"jsonrpc":"2.0","method":"nope","params":{"a":1}}`), "test", nil)
+	if resp != nil {
+		t.Fatalf("expected no response to a notification, got %s", resp)
+	}
+	if len(s.notifications) != 1 || s.notifications[0].Method != "nope" || s.notifications[0].Transport != "test" {
+		t.Errorf("expected notification to be recorded, got %+v", s.notifications)
+	}
+}
+
+func TestHandle_NotificationHistory(t *testing.T) {
+	s := New(Config{})
+	for range notificationHistorySize + 5 {
+		s.handle(context.Background(), []byte(`{"jsonrpc":"2.0","method":"tick"}`), "test", nil)
+	}
+	if len(s.notifications) != notificationHistorySize {
+		t.Errorf("expected %d notifications, got %d", notificationHistorySize, len(s.notifications))
+	}
+}
+
+func TestHandle_Wait(t *testing.T) {
+	waited := 0
+	s := New(Config{Wait: func(ctx context.Context) error {
+		waited++
+		if waited > 1 {
+			return context.Canceled
+		}
+		return nil
+	}})
+	var resp Response
+	handleJSON(t, s, `{"jsonrpc":"2.0","method":"echo","params":[1],"id":1}`, &resp)
+	if resp.Error != nil || waited != 1 {
+		t.Fatalf("expected result after one wait, got %+v after %d waits", resp.Error, waited)
+	}
+	handleJSON(t, s, `{"jsonrpc":"2.0","method":"echo","params":[1],"id":2}`, &resp)
+	if resp.Error == nil || resp.Error.Code != CodeInternalError {
+		t.Errorf("expected internal error when the wait fails, got %+v", resp.Error)
+	}
+}
+
+func TestClearNotifications(t *testing.T) {
+	s := New(Config{})
+	s.handle(context.Background(), []byte(`{"jsonrpc":"2.0","method":"tick"}`), "test", nil)
+	if n := s.ClearNotifications(); n != 1 || s.NotificationCount() != 0 {
+		t.Errorf("expected 1 cleared notification and none left, got %d and %d", n, s.NotificationCount())
+	}
+}
+
+func TestHandle_Batch(t *testing.T) {
+	s := New(Config{})
+	// The slow first request must still be answered first
+	data := `[
+		{"jsonrpc":"2.0","method":"delay","params":{"ms":50,"value":"slow"},"id":1},
+		{"jsonrpc":"2.0","method":"echo","params":["fast"],"id":2},
+		{"jsonrpc":"2.0","method":"echo","params":["ignored"]},
+		1,
+		{"jsonrpc":"2.0","method":"nope","id":3}
+	]`
+	var resps []Response
+	handleJSON(t, s, data, &resps)
+	if len(resps) != 4 {
+		t.Fatalf("expected 4 responses, got %d", len(resps))
+	}
+	if string(resps[0].ID) != "1" || string(resps[0].Result) != `"slow"` {
+		t.Errorf("expected slow result first, got %+v", resps[0])
+	}
+	if string(resps[1].ID) != "2" || string(resps[1].Result) != `["fast"]` {
+		t.Errorf("expected fast result second, got %+v", resps[1])
+	}
+	if resps[2].Error == nil || resps[2].Error.Code != CodeInvalidRequest {
+		t.Errorf("expected invalid request for 1, got %+v", resps[2])
+	}
+	if resps[3].Error == nil || resps[3].Error.Code != CodeMethodNotFound {
+		t.Errorf("expected method not found, got %+v", resps[3])
+	}
+}
+
+func TestHandle_BatchOnlyNotifications(t *testing.T) {
+	s := New(Config{})
+	resp := s.handle(context.Background(), []byte(`[{"jsonrpc":"2.0","method":"echo"},{"jsonrpc":"2.0","method":"echo"}]`), "test", nil)
+	if resp != nil {
+		t.Errorf("expected no response, got %s", resp)
+	}
+}
+
+func TestHandle_BatchMaxSize(t *testing.T) {
+	s := New(Config{BatchMaxSize: 2})
+	var resp Response
+	handleJSON(t, s, `[{"jsonrpc":"2.0","method":"echo","id":1},{"jsonrpc":"2.0","method":"echo","id":2},{"jsonrpc":"2.0","method":"echo","id":3}]`, &resp)
+	if resp.Error == nil || resp.Error.Code != CodeInvalidRequest {
+		t.Fatalf("expected invalid request, got %+v", resp)
+	}
+	if string(resp.Error.Data) != `{"batchMaxSize":2,"batchSize":3}` {
+		t.Errorf("unexpected error data %s", resp.Error.Data)
+	}
+}
@@ -0,0 +1,183 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	// maxDelayMs is the longest delay accepted by "delay" and "notify"
+	maxDelayMs = 60000
+
+	// maxNotifyCount is the largest number of notifications "notify" sends
+	maxNotifyCount = 1000
+)
+
+// echo returns the params unchanged (null when absent)
+func (s *Server) echo(ctx context.Context, c *call) (any, error) {
+	if c.params == nil {
+		return nil, nil
+	}
+	return c.params, nil
+}
+
+// delayParams are the params of "delay", by name ({"ms": 100, "value": ...})
+// or by position ([100, ...])
+type delayParams struct {
+	Ms    int             `json:"ms"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// delay waits for ms milliseconds, then returns value (null when absent)
+func (s *Server) delay(ctx context.Context, c *call) (any, error) {
+	var p delayParams
+	if err := decodeParams(c.params, &p, "ms", "value"); err != nil {
+		return nil, err
+	}
+	if p.Ms < 0 || p.Ms > maxDelayMs {
+		return nil, invalidParams(fmt.Sprintf("ms must be 0-%d", maxDelayMs))
+	}
+
+	timer := time.NewTimer(time.Duration(p.Ms) * time.Millisecond)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+		return nil, newError(CodeServerError, "Request cancelled", nil)
+	}
+
+	if p.Value == nil {
+		return nil, nil
+	}
+	return p.Value, nil
+}
+
+// errorParams are the params of "error"
+type errorParams struct {
+	Code    *int            `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// raiseError answers with the requested error object. The code defaults to
+// -32000 and the message to the standard message of the code.
+func (s *Server) raiseError(ctx context.Context, c *call) (any, error) {
+	var p errorParams
+	if err := decodeParams(c.params, &p, "code", "message", "data"); err != nil {
+		return nil, err
+	}
+
+	code := CodeServerError
+	if p.Code != nil {
+		code = *p.Code
+	}
+	message := p.Message
+	if message == "" {
+		message = codeMessages[code]
+	}
+	if message == "" {
+		message = codeMessages[CodeServerError]
+	}
+	return nil, &Error{Code: code, Message: message, Data: p.Data}
+}
+
+// notifyParams are the params of "notify"
+type notifyParams struct {
+	Method     string          `json:"method"`
+	Params     json.RawMessage `json:"params,omitempty"`
+	Count      *int            `json:"count"`
+	IntervalMs int             `json:"intervalMs"`
+}
+
+// notify sends count server-to-client notifications, one every intervalMs,
+// then returns the number sent. It needs a persistent connection
+// (WebSocket).
+func (s *Server) notify(ctx context.Context, c *call) (any, error) {
+	if c.notifier == nil {
+		return nil, newError(CodeServerError, "Server notifications require the WebSocket transport",
+			map[string]string{"transport": c.transport})
+	}
+
+	var p notifyParams
+	if err := decodeParams(c.params, &p, "method", "params", "count", "intervalMs"); err != nil {
+		return nil, err
+	}
+	if p.Method == "" {
+		p.Method = "notify"
+	}
+	count := 1
+	if p.Count != nil {
+		count = *p.Count
+	}
+	if count < 1 || count > maxNotifyCount {
+		return nil, invalidParams(fmt.Sprintf("count must be 1-%d", maxNotifyCount))
+	}
+	if p.IntervalMs < 0 || p.IntervalMs > maxDelayMs {
+		return nil, invalidParams(fmt.Sprintf("intervalMs must be 0-%d", maxDelayMs))
+	}
+
+	for i := range count {
+		if i > 0 && p.IntervalMs > 0 {
+			select {
+			case <-time.After(time.Duration(p.IntervalMs) * time.Millisecond):
+			case <-ctx.Done():
+				return nil, newError(CodeServerError, "Request cancelled", nil)
+			}
+		}
+		var params any = map[string]int{"seq": i + 1, "count": count}
+		if p.Params != nil {
+			params = p.Params
+		}
+		if err := c.notifier.notify(p.Method, params); err != nil {
+			return nil, err
+		}
+	}
+	return map[string]int{"sent": count}, nil
+}
+
+// listNotifications returns the most recent client notifications, oldest
+// first
+func (s *Server) listNotifications(ctx context.Context, c *call) (any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	notifications := make([]Notification, len(s.notifications))
+	copy(notifications, s.notifications)
+	return map[string][]Notification{"notifications": notifications}, nil
+}
+
+// decodeParams decodes by-name (object) params into v, or by-position
+// (array) params by mapping each element to the field named in names
+func decodeParams(params json.RawMessage, v any, names ...string) error {
+	if params == nil {
+		return nil
+	}
+
+	if params[0] == '[' {
+		var positional []json.RawMessage
+		if err := json.Unmarshal(params, &positional); err != nil {
+			return invalidParams(err.Error())
+		}
+		if len(positional) > len(names) {
+			return invalidParams(fmt.Sprintf("at most %d positional params", len(names)))
+		}
+		named := make(map[string]json.RawMessage, len(positional))
+		for i, value := range positional {
+			named[names[i]] = value
+		}
+		var err error
+		if params, err = json.Marshal(named); err != nil {
+			return invalidParams(err.Error())
+		}
+	}
+
+	if err := json.Unmarshal(params, v); err != nil {
+		return invalidParams(err.Error())
+	}
+	return nil
+}
+
+func invalidParams(detail string) *Error {
+	return newError(CodeInvalidParams, codeMessages[CodeInvalidParams], detail)
+}
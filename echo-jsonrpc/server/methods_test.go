@@ -0,0 +1,170 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestEcho(t *testing.T) {
+	tests := []struct {
+		name     string
+		params   string
+		expected string
+	}{
+		{name: "by name", params: `,"params":{"a":[1,2],"b":null}`, expected: `{"a":[1,2],"b":null}`},
+		{name: "by position", params: `,"params":["x",1]`, expected: `["x",1]`},
+		{name: "no params", params: ``, expected: `null`},
+	}
+
+	s := New(Config{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp Response
+			handleJSON(t, s, `{"jsonrpc":"2.0","method":"echo"`+tt.params+`,"id":1}`, &resp)
+			if resp.Error != nil {
+				t.Fatalf("unexpected error: %+v", resp.Error)
+			}
+			if string(resp.Result) != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, resp.Result)
+			}
+		})
+	}
+}
+
+func TestDelay(t *testing.T) {
+	tests := []struct {
+		name     string
+		params   string
+		expected string
+		code     int
+	}{
+		{name: "by name", params: `{"ms":20,"value":{"ok":true}}`, expected: `{"ok":true}`},
+		{name: "by position", params: `[20,"done"]`, expected: `"done"`},
+		{name: "no value", params: `{"ms":20}`, expected: `null`},
+		{name: "negative", params: `{"ms":-1}`, code: CodeInvalidParams},
+		{name: "too long", params: `[60001]`, code: CodeInvalidParams},
+		{name: "too many positional", params: `[1,2,3]`, code: CodeInvalidParams},
+		{name: "wrong type", params: `{"ms":"soon"}`, code: CodeInvalidParams},
+	}
+
+	s := New(Config{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			var resp Response
+			handleJSON(t, s, `{"jsonrpc":"2.0","method":"delay","params":`+tt.params+`,"id":1}`, &resp)
+			if tt.code != 0 {
+				if resp.Error == nil || resp.Error.Code != tt.code {
+					t.Fatalf("expected error code %d, got %+v", tt.code, resp)
+				}
+				return
+			}
+			if resp.Error != nil {
+				t.Fatalf("unexpected error: %+v", resp.Error)
+			}
+			if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+				t.Errorf("expected at least 20ms delay, got %v", elapsed)
+			}
+			if string(resp.Result) != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, resp.Result)
+			}
+		})
+	}
+}
+
+func TestDelay_Cancelled(t *testing.T) {
+	s := New(Config{})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := s.delay(ctx, &call{params: []byte(`{"ms":60000}`)})
+	rpcErr, ok := err.(*Error)
+	if !ok || rpcErr.Code != CodeServerError {
+		t.Errorf("expected server error, got %v", err)
+	}
+}
+
+func TestRaiseError(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  string
+		code    int
+		message string
+		data    string
+	}{
+		{name: "defaults", params: ``, code: CodeServerError, message: "Server error"},
+		{name: "standard code", params: `,"params":{"code":-32602}`, code: CodeInvalidParams, message: "Invalid params"},
+		{name: "custom", params: `,"params":{"code":4001,"message":"User rejected","data":{"reason":"test"}}`, code: 4001, message: "User rejected", data: `{"reason":"test"}`},
+		{name: "custom code without message", params: `,"params":[42]`, code: 42, message: "Server error"},
+		{name: "by position", params: `,"params":[-32099,"Busy",[1]]`, code: -32099, message: "Busy", data: `[1]`},
+	}
+
+	s := New(Config{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp Response
+			handleJSON(t, s, `{"jsonrpc":"2.0","method":"error"`+tt.params+`,"id":1}`, &resp)
+			if resp.Error == nil {
+				t.Fatalf("expected error, got result %s", resp.Result)
+			}
+			if resp.Error.Code != tt.code || resp.Error.Message != tt.message || string(resp.Error.Data) != tt.data {
+				t.Errorf("expected %d %q %s, got %d %q %s", tt.code, tt.message, tt.data,
+					resp.Error.Code, resp.Error.Message, resp.Error.Data)
+			}
+		})
+	}
+}
+
+func TestNotify_RequiresWebSocket(t *testing.T) {
+	s := New(Config{})
+	var resp Response
+	handleJSON(t, s, `{"jsonrpc":"2.0","method":"notify","id":1}`, &resp)
+	if resp.Error == nil || resp.Error.Code != CodeServerError {
+		t.Errorf("expected server error, got %+v", resp)
+	}
+}
+
+type recordingNotifier struct {
+	methods []string
+}
+
+func (n *recordingNotifier) notify(method string, params any) error {
+	n.methods = append(n.methods, method)
+	return nil
+}
+
+func TestNotify(t *testing.T) {
+	s := New(Config{})
+	n := &recordingNotifier{}
+	result, err := s.notify(context.Background(), &call{params: []byte(`{"method":"tick","count":3}`), notifier: n})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sent := result.(map[string]int)["sent"]; sent != 3 {
+		t.Errorf("expected 3 sent, got %d", sent)
+	}
+	if len(n.methods) != 3 || n.methods[0] != "tick" {
+		t.Errorf("expected 3 tick notifications, got %v", n.methods)
+	}
+
+	_, err = s.notify(context.Background(), &call{params: []byte(`{"count":0}`), notifier: n})
+	if rpcErr, ok := err.(*Error); !ok || rpcErr.Code != CodeInvalidParams {
+		t.Errorf("expected invalid params, got %v", err)
+	}
+}
+
+func TestNotifications(t *testing.T) {
+	s := New(Config{})
+	s.handle(context.Background(), []byte(`{"jsonrpc":"2.0","method":"log","params":["first"]}`), "test", nil)
+
+	var resp struct {
+		Result struct {
+			Notifications []Notification `json:"notifications"`
+		} `json:"result"`
+	}
+	handleJSON(t, s, `{"jsonrpc":"2.0","method":"notifications","id":1}`, &resp)
+	got := resp.Result.Notifications
+	if len(got) != 1 || got[0].Method != "log" || string(got[0].Params) != `["first"]` {
+		t.Errorf("unexpected notifications %+v", got)
+	}
+}
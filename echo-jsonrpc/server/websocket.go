@@ -0,0 +1,107 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// writeTimeout bounds each write to a WebSocket connection
+const writeTimeout = 10 * time.Second
+
+// wsConn is a WebSocket connection. Messages are handled concurrently, so
+// writes are serialized by mu.
+type wsConn struct {
+	conn *websocket.Conn
+	mu   sync.Mutex
+}
+
+func (c *wsConn) write(data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_ = c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	return c.conn.WriteMessage(websocket.TextMessage, data)
+}
+
+// notify sends a server-to-client notification
+func (c *wsConn) notify(method string, params any) error {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	return c.write(encode(Request{JSONRPC: Version, Method: method, Params: raw}))
+}
+
+// ServeWebSocket upgrades the request to a WebSocket connection on which
+// every text or binary message is a single or batch request. Messages are
+// handled concurrently, so responses may arrive out of order; they are
+// matched to requests by id.
+func (s *Server) ServeWebSocket(w http.ResponseWriter, r *http.Request) {
+	upgrader := websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool {
+			return true
+		},
+	}
+	if offered := websocket.Subprotocols(r); len(offered) > 0 {
+		upgrader.Subprotocols = offered[:1]
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	if s.cfg.MaxMessageSize > 0 {
+		conn.SetReadLimit(int64(s.cfg.MaxMessageSize))
+	}
+
+	c := &wsConn{conn: conn}
+	s.mu.Lock()
+	s.conns[c] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, c)
+		s.mu.Unlock()
+	}()
+
+	// In-flight calls are cancelled once the connection closes
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			cancel()
+			_ = conn.Close()
+			wg.Wait()
+			return
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if resp := s.handle(ctx, data, "websocket", c); resp != nil {
+				_ = c.write(resp)
+			}
+		}()
+	}
+}
+
+// CloseAll sends a close frame with code and reason to every open WebSocket
+// connection, such as 1001 (going away) on shutdown. It returns the number of
+// connections.
+func (s *Server) CloseAll(code int, reason string) int {
+	s.mu.Lock()
+	open := make([]*wsConn, 0, len(s.conns))
+	for c := range s.conns {
+		open = append(open, c)
+	}
+	s.mu.Unlock()
+
+	for _, c := range open {
+		_ = c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(time.Second))
+	}
+	return len(open)
+}
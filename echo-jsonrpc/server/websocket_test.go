@@ -0,0 +1,105 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func dialWebSocket(t *testing.T, s *Server) *websocket.Conn {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(s.ServeWebSocket))
+	t.Cleanup(server.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	return conn
+}
+
+func TestServeWebSocket_OutOfOrder(t *testing.T) {
+	conn := dialWebSocket(t, New(Config{}))
+
+	requests := []string{
+		`{"jsonrpc":"2.0","method":"delay","params":[100,"slow"],"id":"slow"}`,
+		`{"jsonrpc":"2.0","method":"echo","params":["fast"],"id":"fast"}`,
+	}
+	for _, req := range requests {
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(req)); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+	}
+
+	var ids []string
+	for range requests {
+		var resp Response
+		if err := conn.ReadJSON(&resp); err != nil {
+			t.Fatalf("read failed: %v", err)
+		}
+		ids = append(ids, string(resp.ID))
+	}
+	if ids[0] != `"fast"` || ids[1] != `"slow"` {
+		t.Errorf("expected fast response before slow, got %v", ids)
+	}
+}
+
+func TestServeWebSocket_Notify(t *testing.T) {
+	conn := dialWebSocket(t, New(Config{}))
+
+	req := `{"jsonrpc":"2.0","method":"notify","params":{"method":"tick","count":2},"id":1}`
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(req)); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	for seq := 1; seq <= 2; seq++ {
+		var n Request
+		if err := conn.ReadJSON(&n); err != nil {
+			t.Fatalf("read failed: %v", err)
+		}
+		if n.Method != "tick" || n.ID != nil {
+			t.Fatalf("expected tick notification, got %+v", n)
+		}
+		var params map[string]int
+		_ = json.Unmarshal(n.Params, &params)
+		if params["seq"] != seq || params["count"] != 2 {
+			t.Errorf("expected seq %d of 2, got %v", seq, params)
+		}
+	}
+
+	var resp Response
+	if err := conn.ReadJSON(&resp); err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	if string(resp.Result) != `{"sent":2}` {
+		t.Errorf("expected {\"sent\":2}, got %s", resp.Result)
+	}
+}
+
+func TestCloseAll(t *testing.T) {
+	s := New(Config{})
+	conn := dialWebSocket(t, s)
+
+	// Wait for the connection to be registered
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","method":"echo","id":1}`)); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if _, _, err := conn.ReadMessage(); err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+
+	if n := s.CloseAll(websocket.CloseGoingAway, "server shutting down"); n != 1 {
+		t.Errorf("expected 1 connection, got %d", n)
+	}
+	_, _, err := conn.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.CloseGoingAway) {
+		t.Errorf("expected close 1001, got %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package app
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/probitas-test/echo-servers/shared/admin"
+	"github.com/probitas-test/echo-servers/shared/bandwidth"
+	"github.com/probitas-test/echo-servers/shared/config"
+	"github.com/probitas-test/echo-servers/shared/connlimit"
+	"github.com/probitas-test/echo-servers/shared/lifecycle"
+	"github.com/probitas-test/echo-servers/shared/logging"
+	"github.com/probitas-test/echo-servers/shared/network"
+	"github.com/probitas-test/echo-servers/shared/tracing"
+)
+
+type Config struct {
+	Host string
+	Port int
+
+	// Port of the HTTP server for health checks and API documentation
+	HTTPPort string
+
+	// Broker address returned in metadata, which clients connect to after
+	// bootstrapping (AdvertisedPort defaults to Port)
+	AdvertisedHost string
+	AdvertisedPort int
+
+	// Errors (code or name) injected into produce and fetch responses
+	ProduceError string
+	FetchError   string
+
+	// Probability (0-1) that a partition is answered with the injected error
+	ErrorRate float64
+
+	// Create unknown topics named in metadata requests
+	AutoCreateTopics bool
+
+	// Maximum size of each topic log, in bytes (0 = unlimited)
+	RetentionBytes int
+
+	// Admin API on a separate port (ADMIN_ENABLED, ADMIN_PORT)
+	Admin admin.Config
+
+	// Bandwidth shaping of accepted connections (BANDWIDTH_*), changed at
+	// runtime at /bandwidth on the admin port
+	Bandwidth bandwidth.Config
+
+	// Connection cap (CONN_LIMIT_*), changed at runtime at /connlimit on the
+	// admin port
+	ConnLimit connlimit.Config
+
+	// Address family of every listener (IP_FAMILY: dual, ipv4 or ipv6)
+	Network network.Config
+
+	// Simulated startup delay, readiness delay and crash (STARTUP_DELAY_MS, STARTUP_READY_DELAY_MS, CRASH_AFTER_MS, CRASH_EXIT_CODE)
+	Lifecycle lifecycle.Config
+
+	// Structured logging (LOG_LEVEL, LOG_FORMAT)
+	Logging logging.Config
+
+	// OpenTelemetry tracing (OTEL_* variables)
+	Tracing tracing.Config
+
+	// Effective configuration, served at /config
+	src *config.Source
+}
+
+// LoadConfig reads the settings of echo-kafka from src
+func LoadConfig(src *config.Source) (*Config, error) {
+	port := src.Int("PORT", 9092)
+	cfg := &Config{
+		Host:     src.String("HOST", "0.0.0.0"),
+		Port:     port,
+		HTTPPort: src.String("HTTP_PORT", "8080"),
+
+		AdvertisedHost: src.String("ADVERTISED_HOST", "localhost"),
+		AdvertisedPort: src.Int("ADVERTISED_PORT", port),
+
+		ProduceError:     src.String("PRODUCE_ERROR", "NOT_LEADER_OR_FOLLOWER"),
+		FetchError:       src.String("FETCH_ERROR", "NOT_LEADER_OR_FOLLOWER"),
+		ErrorRate:        src.Float("ERROR_RATE", 0),
+		AutoCreateTopics: src.Bool("AUTO_CREATE_TOPICS", true),
+		RetentionBytes:   src.Int("RETENTION_BYTES", 64<<20),
+
+		Admin:     admin.LoadConfig(src),
+		Tracing:   tracing.LoadConfig(src, "echo-kafka"),
+		Bandwidth: bandwidth.LoadConfig(src),
+		ConnLimit: connlimit.LoadConfig(src),
+		Network:   network.LoadConfig(src),
+		Lifecycle: lifecycle.LoadConfig(src),
+		Logging:   logging.LoadConfig(src),
+	}
+	cfg.src = src
+	if err := src.Err(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	return cfg, nil
+}
+
+func (c *Config) Addr() string {
+	return net.JoinHostPort(c.Host, strconv.Itoa(c.Port))
+}
+
+func (c *Config) HTTPAddr() string {
+	return net.JoinHostPort(c.Host, c.HTTPPort)
+}
+
+func (c *Config) AdminAddr() string {
+	return net.JoinHostPort(c.Host, c.Admin.Port)
+}
@@ -0,0 +1,71 @@
+package kafka
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/twmb/franz-go/pkg/kerr"
+)
+
+// ErrorTopicPrefix starts the names of topics whose produce and fetch
+// requests always fail, e.g. "error-6" or "error-NOT_LEADER_OR_FOLLOWER-orders"
+const ErrorTopicPrefix = "error-"
+
+// maxErrorCode bounds the codes searched when parsing error names
+const maxErrorCode = 200
+
+// errorAliases are current Kafka names of errors known to kerr by an older name
+var errorAliases = map[string]int16{
+	"NOT_LEADER_OR_FOLLOWER": kerr.NotLeaderForPartition.Code,
+}
+
+// ParseErrorCode parses a Kafka error code given by number (e.g. "6") or by
+// name (e.g. "NOT_LEADER_FOR_PARTITION"). Only known non-zero codes are valid.
+func ParseErrorCode(s string) (int16, error) {
+	if n, err := strconv.ParseInt(s, 10, 16); err == nil {
+		code := int16(n)
+		if code == 0 || (code != kerr.UnknownServerError.Code && kerr.ErrorForCode(code) == kerr.UnknownServerError) {
+			return 0, fmt.Errorf("unknown error code %d", code)
+		}
+		return code, nil
+	}
+
+	name := strings.ToUpper(s)
+	if code, ok := errorAliases[name]; ok {
+		return code, nil
+	}
+	for code := int16(-1); code <= maxErrorCode; code++ {
+		if err := kerr.TypedErrorForCode(code); err != nil && err.Code == code && err.Message == name {
+			return code, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown error %q", s)
+}
+
+// ErrorName returns the current Kafka name of an error code
+func ErrorName(code int16) string {
+	for name, c := range errorAliases {
+		if c == code {
+			return name
+		}
+	}
+	if err := kerr.TypedErrorForCode(code); err != nil && err.Code == code {
+		return err.Message
+	}
+	return "UNKNOWN"
+}
+
+// topicError returns the error code selected by the name of an error topic
+func topicError(topic string) (int16, bool) {
+	rest, ok := strings.CutPrefix(topic, ErrorTopicPrefix)
+	if !ok {
+		return 0, false
+	}
+	code, _, _ := strings.Cut(rest, "-")
+	n, err := ParseErrorCode(code)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
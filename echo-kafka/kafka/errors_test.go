@@ -0,0 +1,65 @@
+package kafka
+
+import "testing"
+
+func TestParseErrorCode(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int16
+		wantErr bool
+	}{
+		{"6", 6, false},
+		{"-1", -1, false},
+		{"NOT_LEADER_FOR_PARTITION", 6, false},
+		{"not_leader_or_follower", 6, false},
+		{"REQUEST_TIMED_OUT", 7, false},
+		{"0", 0, true},
+		{"30000", 0, true},
+		{"NO_SUCH_ERROR", 0, true},
+		{"", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseErrorCode(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestTopicError(t *testing.T) {
+	tests := []struct {
+		topic  string
+		want   int16
+		wantOK bool
+	}{
+		{"error-6", 6, true},
+		{"error-6-orders", 6, true},
+		{"error-NOT_LEADER_OR_FOLLOWER-orders", 6, true},
+		{"error-x", 0, false},
+		{"orders", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := topicError(tt.topic)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("topicError(%q) = %d, %v; expected %d, %v", tt.topic, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestErrorName(t *testing.T) {
+	tests := map[int16]string{
+		6:     "NOT_LEADER_OR_FOLLOWER",
+		7:     "REQUEST_TIMED_OUT",
+		30000: "UNKNOWN",
+	}
+	for code, want := range tests {
+		if got := ErrorName(code); got != want {
+			t.Errorf("ErrorName(%d) = %q, expected %q", code, got, want)
+		}
+	}
+}
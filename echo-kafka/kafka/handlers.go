@@ -0,0 +1,393 @@
+package kafka
+
+import (
+	"crypto/rand"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+// ClusterID is the cluster ID returned in metadata
+const ClusterID = "echo-kafka"
+
+// brokerID is the node ID of the only broker
+const brokerID = 0
+
+// maxTopicNameLength is the longest valid topic name
+const maxTopicNameLength = 249
+
+// apiVersions are the supported APIs and versions. Produce starts at v3,
+// the first version requiring record batches (magic 2).
+var apiVersions = []kmsg.ApiVersionsResponseApiKey{
+	{ApiKey: kmsg.Produce.Int16(), MinVersion: 3, MaxVersion: 9},
+	{ApiKey: kmsg.Fetch.Int16(), MinVersion: 4, MaxVersion: 12},
+	{ApiKey: kmsg.ListOffsets.Int16(), MinVersion: 1, MaxVersion: 6},
+	{ApiKey: kmsg.Metadata.Int16(), MinVersion: 0, MaxVersion: 12},
+	{ApiKey: kmsg.ApiVersions.Int16(), MinVersion: 0, MaxVersion: 3},
+	{ApiKey: kmsg.CreateTopics.Int16(), MinVersion: 0, MaxVersion: 7},
+	{ApiKey: kmsg.InitProducerID.Int16(), MinVersion: 0, MaxVersion: 4},
+}
+
+// supported reports whether a version of an API is served
+func supported(key, version int16) bool {
+	for _, api := range apiVersions {
+		if api.ApiKey == key {
+			return version >= api.MinVersion && version <= api.MaxVersion
+		}
+	}
+	return false
+}
+
+// topic is a single-partition topic
+type topic struct {
+	id  [16]byte
+	log *partitionLog
+}
+
+// handle answers a request, or returns nil when no response is expected
+func (s *Server) handle(req kmsg.Request) kmsg.Response {
+	switch req := req.(type) {
+	case *kmsg.ApiVersionsRequest:
+		return apiVersionsResponse(req.Version, 0)
+	case *kmsg.MetadataRequest:
+		return s.metadata(req)
+	case *kmsg.CreateTopicsRequest:
+		return s.createTopics(req)
+	case *kmsg.InitProducerIDRequest:
+		return s.initProducerID(req)
+	case *kmsg.ProduceRequest:
+		s.delay()
+		return s.produce(req)
+	case *kmsg.FetchRequest:
+		s.delay()
+		return s.fetch(req)
+	case *kmsg.ListOffsetsRequest:
+		return s.listOffsets(req)
+	default:
+		panic("unreachable: unsupported request " + kmsg.NameForKey(req.Key()))
+	}
+}
+
+func apiVersionsResponse(version, errorCode int16) *kmsg.ApiVersionsResponse {
+	resp := kmsg.NewPtrApiVersionsResponse()
+	resp.Version = version
+	resp.ErrorCode = errorCode
+	resp.ApiKeys = apiVersions
+	return resp
+}
+
+// validTopicName reports whether name is a valid Kafka topic name
+func validTopicName(name string) bool {
+	if name == "" || name == "." || name == ".." || len(name) > maxTopicNameLength {
+		return false
+	}
+	for _, c := range name {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '.', c == '_', c == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// createTopic adds an empty topic; s.mu must be held
+func (s *Server) createTopic(name string) *topic {
+	t := &topic{log: newPartitionLog(s.cfg.RetentionBytes)}
+	_, _ = rand.Read(t.id[:])
+	s.topics[name] = t
+	return t
+}
+
+func (s *Server) metadata(req *kmsg.MetadataRequest) kmsg.Response {
+	resp := req.ResponseKind().(*kmsg.MetadataResponse)
+	clusterID := ClusterID
+	resp.ClusterID = &clusterID
+	resp.ControllerID = brokerID
+	resp.Brokers = []kmsg.MetadataResponseBroker{{
+		NodeID: brokerID,
+		Host:   s.cfg.AdvertisedHost,
+		Port:   s.cfg.AdvertisedPort,
+	}}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// A null list (or an empty one before v1) requests all topics
+	if req.Topics == nil || (req.Version == 0 && len(req.Topics) == 0) {
+		names := make([]string, 0, len(s.topics))
+		for name := range s.topics {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			resp.Topics = append(resp.Topics, metadataTopic(name, s.topics[name]))
+		}
+		return resp
+	}
+
+	for _, rt := range req.Topics {
+		if rt.Topic == nil {
+			resp.Topics = append(resp.Topics, s.metadataTopicByID(rt.TopicID))
+			continue
+		}
+		name := *rt.Topic
+		t, ok := s.topics[name]
+		switch {
+		case ok:
+			resp.Topics = append(resp.Topics, metadataTopic(name, t))
+		case !validTopicName(name):
+			resp.Topics = append(resp.Topics, metadataTopicError(name, kerr.InvalidTopicException.Code))
+		case s.cfg.AutoCreateTopics:
+			resp.Topics = append(resp.Topics, metadataTopic(name, s.createTopic(name)))
+		default:
+			resp.Topics = append(resp.Topics, metadataTopicError(name, kerr.UnknownTopicOrPartition.Code))
+		}
+	}
+	return resp
+}
+
+// metadataTopicByID describes the topic with the given ID; s.mu must be held
+func (s *Server) metadataTopicByID(id [16]byte) kmsg.MetadataResponseTopic {
+	for name, t := range s.topics {
+		if t.id == id {
+			return metadataTopic(name, t)
+		}
+	}
+	mt := kmsg.NewMetadataResponseTopic()
+	mt.TopicID = id
+	mt.ErrorCode = kerr.UnknownTopicID.Code
+	return mt
+}
+
+func metadataTopic(name string, t *topic) kmsg.MetadataResponseTopic {
+	mt := kmsg.NewMetadataResponseTopic()
+	mt.Topic = &name
+	mt.TopicID = t.id
+	p := kmsg.NewMetadataResponseTopicPartition()
+	p.Partition = 0
+	p.Leader = brokerID
+	p.Replicas = []int32{brokerID}
+	p.ISR = []int32{brokerID}
+	mt.Partitions = []kmsg.MetadataResponseTopicPartition{p}
+	return mt
+}
+
+func metadataTopicError(name string, code int16) kmsg.MetadataResponseTopic {
+	mt := kmsg.NewMetadataResponseTopic()
+	mt.Topic = &name
+	mt.ErrorCode = code
+	return mt
+}
+
+func (s *Server) createTopics(req *kmsg.CreateTopicsRequest) kmsg.Response {
+	resp := req.ResponseKind().(*kmsg.CreateTopicsResponse)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, rt := range req.Topics {
+		ct := kmsg.NewCreateTopicsResponseTopic()
+		ct.Topic = rt.Topic
+		ct.NumPartitions = 1
+		ct.ReplicationFactor = 1
+
+		var msg string
+		switch _, exists := s.topics[rt.Topic]; {
+		case !validTopicName(rt.Topic):
+			ct.ErrorCode = kerr.InvalidTopicException.Code
+		case exists:
+			ct.ErrorCode = kerr.TopicAlreadyExists.Code
+		case rt.NumPartitions != -1 && rt.NumPartitions != 1, len(rt.ReplicaAssignment) > 1:
+			ct.ErrorCode = kerr.InvalidPartitions.Code
+			msg = "only single-partition topics are supported"
+		case rt.ReplicationFactor != -1 && rt.ReplicationFactor != 1:
+			ct.ErrorCode = kerr.InvalidReplicationFactor.Code
+			msg = "replication factor must be 1 (single broker)"
+		case !req.ValidateOnly:
+			ct.TopicID = s.createTopic(rt.Topic).id
+		}
+		if msg != "" {
+			ct.ErrorMessage = &msg
+		}
+		resp.Topics = append(resp.Topics, ct)
+	}
+	return resp
+}
+
+// initProducerID hands out producer IDs for idempotent producers.
+// Transactions are not supported.
+func (s *Server) initProducerID(req *kmsg.InitProducerIDRequest) kmsg.Response {
+	resp := req.ResponseKind().(*kmsg.InitProducerIDResponse)
+	if req.TransactionalID != nil {
+		resp.ErrorCode = kerr.CoordinatorNotAvailable.Code
+		return resp
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if req.ProducerID >= 0 && req.ProducerID < s.producerIDs {
+		// Epoch bump of a producer recovering from an error
+		resp.ProducerID = req.ProducerID
+		resp.ProducerEpoch = req.ProducerEpoch + 1
+		return resp
+	}
+	resp.ProducerID = s.producerIDs
+	s.producerIDs++
+	return resp
+}
+
+func (s *Server) produce(req *kmsg.ProduceRequest) kmsg.Response {
+	resp := req.ResponseKind().(*kmsg.ProduceResponse)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	appended := false
+	for _, rt := range req.Topics {
+		pt := kmsg.NewProduceResponseTopic()
+		pt.Topic = rt.Topic
+		for _, rp := range rt.Partitions {
+			pp := kmsg.NewProduceResponseTopicPartition()
+			pp.Partition = rp.Partition
+			pp.BaseOffset = -1
+
+			t, ok := s.topics[rt.Topic]
+			if !ok || rp.Partition != 0 {
+				pp.ErrorCode = kerr.UnknownTopicOrPartition.Code
+			} else if code := s.injectError(rt.Topic, s.cfg.ProduceError); code != 0 {
+				pp.ErrorCode = code
+			} else if batches, err := parseBatches(rp.Records); err != nil {
+				pp.ErrorCode = kerr.CorruptMessage.Code
+				if errors.Is(err, errUnsupportedMagic) {
+					pp.ErrorCode = kerr.UnsupportedForMessageFormat.Code
+				}
+				msg := err.Error()
+				pp.ErrorMessage = &msg
+			} else {
+				pp.BaseOffset = t.log.append(batches)
+				pp.LogStartOffset = t.log.start
+				appended = true
+			}
+			pt.Partitions = append(pt.Partitions, pp)
+		}
+		resp.Topics = append(resp.Topics, pt)
+	}
+	if appended {
+		// Wake up fetches waiting for records
+		close(s.appended)
+		s.appended = make(chan struct{})
+	}
+
+	if req.Acks == 0 {
+		return nil
+	}
+	return resp
+}
+
+// fetch answers once MinBytes of records are available, a partition has an
+// error or MaxWaitMillis elapsed, like a regular broker
+func (s *Server) fetch(req *kmsg.FetchRequest) kmsg.Response {
+	timer := time.NewTimer(time.Duration(req.MaxWaitMillis) * time.Millisecond)
+	defer timer.Stop()
+	for {
+		s.mu.Lock()
+		resp, size, failed := s.readFetch(req)
+		appended := s.appended
+		s.mu.Unlock()
+		if failed || size >= int(req.MinBytes) {
+			return resp
+		}
+
+		select {
+		case <-appended:
+		case <-timer.C:
+			return resp
+		case <-s.done:
+			return resp
+		}
+	}
+}
+
+// readFetch builds a fetch response from the logs and returns its size in
+// bytes of records and whether a partition has an error; s.mu must be held
+func (s *Server) readFetch(req *kmsg.FetchRequest) (*kmsg.FetchResponse, int, bool) {
+	resp := req.ResponseKind().(*kmsg.FetchResponse)
+	size := 0
+	failed := false
+	for _, rt := range req.Topics {
+		ft := kmsg.NewFetchResponseTopic()
+		ft.Topic = rt.Topic
+		for _, rp := range rt.Partitions {
+			fp := kmsg.NewFetchResponseTopicPartition()
+			fp.Partition = rp.Partition
+			fp.HighWatermark = -1
+
+			t, ok := s.topics[rt.Topic]
+			switch {
+			case !ok || rp.Partition != 0:
+				fp.ErrorCode = kerr.UnknownTopicOrPartition.Code
+			case rp.FetchOffset < t.log.start || rp.FetchOffset > t.log.end:
+				fp.ErrorCode = kerr.OffsetOutOfRange.Code
+			default:
+				fp.ErrorCode = s.injectError(rt.Topic, s.cfg.FetchError)
+			}
+			if ok {
+				fp.HighWatermark = t.log.end
+				fp.LastStableOffset = t.log.end
+				fp.LogStartOffset = t.log.start
+			}
+			if fp.ErrorCode != 0 {
+				failed = true
+			} else {
+				maxBytes := int(rp.PartitionMaxBytes)
+				if req.MaxBytes > 0 {
+					maxBytes = min(maxBytes, int(req.MaxBytes)-size)
+				}
+				fp.RecordBatches = t.log.read(rp.FetchOffset, maxBytes)
+				if size > 0 && len(fp.RecordBatches) > maxBytes {
+					// Only the first partition may exceed the limits
+					fp.RecordBatches = nil
+				}
+				size += len(fp.RecordBatches)
+			}
+			ft.Partitions = append(ft.Partitions, fp)
+		}
+		resp.Topics = append(resp.Topics, ft)
+	}
+	return resp, size, failed
+}
+
+func (s *Server) listOffsets(req *kmsg.ListOffsetsRequest) kmsg.Response {
+	resp := req.ResponseKind().(*kmsg.ListOffsetsResponse)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, rt := range req.Topics {
+		lt := kmsg.NewListOffsetsResponseTopic()
+		lt.Topic = rt.Topic
+		for _, rp := range rt.Partitions {
+			lp := kmsg.NewListOffsetsResponseTopicPartition()
+			lp.Partition = rp.Partition
+
+			t, ok := s.topics[rt.Topic]
+			switch {
+			case !ok || rp.Partition != 0:
+				lp.ErrorCode = kerr.UnknownTopicOrPartition.Code
+			case rp.Timestamp == -1: // latest
+				lp.Offset = t.log.end
+			case rp.Timestamp == -2: // earliest
+				lp.Offset = t.log.start
+			default:
+				lp.Offset, lp.Timestamp = t.log.offsetForTime(rp.Timestamp)
+			}
+			if lp.ErrorCode == 0 {
+				lp.LeaderEpoch = 0
+			}
+			lt.Partitions = append(lt.Partitions, lp)
+		}
+		resp.Topics = append(resp.Topics, lt)
+	}
+	return resp
+}
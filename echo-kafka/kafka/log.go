@@ -0,0 +1,139 @@
+package kafka
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"sort"
+)
+
+// Offsets of the record batch header fields (magic 2)
+const (
+	batchLengthOffset     = 8
+	batchMagicOffset      = 16
+	batchCRCOffset        = 17
+	batchAttributesOffset = 21
+	lastOffsetDeltaOffset = 23
+	maxTimestampOffset    = 35
+	batchHeaderSize       = 61
+)
+
+var crc32c = crc32.MakeTable(crc32.Castagnoli)
+
+var (
+	errCorruptBatch     = errors.New("corrupt record batch")
+	errUnsupportedMagic = errors.New("unsupported record batch magic (only v2 is supported)")
+)
+
+// batch is a record batch stored in a log
+type batch struct {
+	data         []byte
+	baseOffset   int64
+	lastOffset   int64
+	maxTimestamp int64
+}
+
+// parseBatches splits the records of a produce request into record batches.
+// The batches are copied, so they can be rewritten when appended.
+func parseBatches(records []byte) ([]batch, error) {
+	var batches []batch
+	for len(records) > 0 {
+		if len(records) < batchHeaderSize {
+			return nil, errCorruptBatch
+		}
+		size := int(int32(binary.BigEndian.Uint32(records[batchLengthOffset:]))) + batchLengthOffset + 4
+		if size < batchHeaderSize || size > len(records) {
+			return nil, errCorruptBatch
+		}
+		data := records[:size]
+		records = records[size:]
+
+		if data[batchMagicOffset] != 2 {
+			return nil, errUnsupportedMagic
+		}
+		if crc32.Checksum(data[batchAttributesOffset:], crc32c) != binary.BigEndian.Uint32(data[batchCRCOffset:]) {
+			return nil, errCorruptBatch
+		}
+		lastOffsetDelta := int64(int32(binary.BigEndian.Uint32(data[lastOffsetDeltaOffset:])))
+		if lastOffsetDelta < 0 {
+			return nil, errCorruptBatch
+		}
+		batches = append(batches, batch{
+			data:         append([]byte(nil), data...),
+			lastOffset:   lastOffsetDelta,
+			maxTimestamp: int64(binary.BigEndian.Uint64(data[maxTimestampOffset:])),
+		})
+	}
+	if len(batches) == 0 {
+		return nil, errCorruptBatch
+	}
+	return batches, nil
+}
+
+// partitionLog is the in-memory log of a single-partition topic. Batches are
+// kept as produced, with their base offset rewritten, and served back as is.
+type partitionLog struct {
+	batches []batch
+
+	// start is the log start offset, end the high watermark (next offset)
+	start, end int64
+
+	size           int
+	retentionBytes int
+}
+
+func newPartitionLog(retentionBytes int) *partitionLog {
+	return &partitionLog{retentionBytes: retentionBytes}
+}
+
+// append assigns offsets to the batches and adds them to the log. The oldest
+// batches are dropped once the log exceeds its retention, but the newest one
+// is always kept. It returns the offset of the first record.
+func (l *partitionLog) append(batches []batch) int64 {
+	base := l.end
+	for _, b := range batches {
+		b.baseOffset = l.end
+		b.lastOffset += l.end
+		binary.BigEndian.PutUint64(b.data, uint64(b.baseOffset))
+		l.batches = append(l.batches, b)
+		l.size += len(b.data)
+		l.end = b.lastOffset + 1
+	}
+	for l.retentionBytes > 0 && l.size > l.retentionBytes && len(l.batches) > 1 {
+		l.size -= len(l.batches[0].data)
+		l.batches[0] = batch{}
+		l.batches = l.batches[1:]
+		l.start = l.batches[0].baseOffset
+	}
+	return base
+}
+
+// read returns the batches holding offset and the following ones, up to
+// maxBytes. The first batch is returned even when it is larger than maxBytes,
+// so that consumers always make progress.
+func (l *partitionLog) read(offset int64, maxBytes int) []byte {
+	i := sort.Search(len(l.batches), func(i int) bool {
+		return l.batches[i].lastOffset >= offset
+	})
+	var out []byte
+	for ; i < len(l.batches); i++ {
+		data := l.batches[i].data
+		if len(out) > 0 && len(out)+len(data) > maxBytes {
+			break
+		}
+		out = append(out, data...)
+	}
+	return out
+}
+
+// offsetForTime returns the base offset and maximum timestamp of the first
+// batch holding a record with a timestamp of at least ts, or -1 and -1 when
+// there is none.
+func (l *partitionLog) offsetForTime(ts int64) (int64, int64) {
+	for _, b := range l.batches {
+		if b.maxTimestamp >= ts {
+			return b.baseOffset, b.maxTimestamp
+		}
+	}
+	return -1, -1
+}
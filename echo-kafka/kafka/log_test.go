@@ -0,0 +1,143 @@
+package kafka
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"testing"
+)
+
+// newBatch builds a record batch header for n records with the given maximum
+// timestamp, followed by payload in place of the records
+func newBatch(n int, maxTimestamp int64, payload string) []byte {
+	b := make([]byte, batchHeaderSize, batchHeaderSize+len(payload))
+	b = append(b, payload...)
+	binary.BigEndian.PutUint32(b[batchLengthOffset:], uint32(len(b)-batchLengthOffset-4))
+	b[batchMagicOffset] = 2
+	binary.BigEndian.PutUint32(b[lastOffsetDeltaOffset:], uint32(n-1))
+	binary.BigEndian.PutUint64(b[maxTimestampOffset:], uint64(maxTimestamp))
+	binary.BigEndian.PutUint32(b[batchCRCOffset:], crc32.Checksum(b[batchAttributesOffset:], crc32c))
+	return b
+}
+
+func mustParse(t *testing.T, records []byte) []batch {
+	t.Helper()
+	batches, err := parseBatches(records)
+	if err != nil {
+		t.Fatalf("failed to parse batches: %v", err)
+	}
+	return batches
+}
+
+func TestParseBatches(t *testing.T) {
+	records := append(newBatch(2, 100, "a"), newBatch(3, 200, "bc")...)
+	batches := mustParse(t, records)
+	if len(batches) != 2 {
+		t.Fatalf("expected 2 batches, got %d", len(batches))
+	}
+	if batches[0].lastOffset != 1 || batches[1].lastOffset != 2 {
+		t.Errorf("unexpected last offset deltas %d, %d", batches[0].lastOffset, batches[1].lastOffset)
+	}
+	if batches[1].maxTimestamp != 200 {
+		t.Errorf("expected max timestamp 200, got %d", batches[1].maxTimestamp)
+	}
+}
+
+func TestParseBatchesInvalid(t *testing.T) {
+	badCRC := newBatch(1, 0, "a")
+	badCRC[len(badCRC)-1] = 'b'
+	oldMagic := newBatch(1, 0, "a")
+	oldMagic[batchMagicOffset] = 1
+	truncated := newBatch(1, 0, "abc")
+
+	tests := []struct {
+		name    string
+		records []byte
+		want    error
+	}{
+		{"empty", nil, errCorruptBatch},
+		{"bad CRC", badCRC, errCorruptBatch},
+		{"magic 1", oldMagic, errUnsupportedMagic},
+		{"truncated", truncated[:len(truncated)-1], errCorruptBatch},
+		{"short header", truncated[:batchHeaderSize-1], errCorruptBatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseBatches(tt.records); !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestPartitionLogAppendRead(t *testing.T) {
+	l := newPartitionLog(0)
+	if base := l.append(mustParse(t, newBatch(2, 100, "a"))); base != 0 {
+		t.Errorf("expected base offset 0, got %d", base)
+	}
+	if base := l.append(mustParse(t, newBatch(3, 200, "b"))); base != 2 {
+		t.Errorf("expected base offset 2, got %d", base)
+	}
+	if l.end != 5 {
+		t.Errorf("expected high watermark 5, got %d", l.end)
+	}
+
+	// The batch holding offset 3 starts at 2
+	got := l.read(3, 1<<20)
+	if binary.BigEndian.Uint64(got) != 2 || !bytes.HasSuffix(got, []byte("b")) {
+		t.Errorf("expected second batch, got %q", got)
+	}
+	if got := l.read(0, 1<<20); len(got) != 2*(batchHeaderSize+1) {
+		t.Errorf("expected both batches, got %d bytes", len(got))
+	}
+	if got := l.read(0, 1); len(got) != batchHeaderSize+1 {
+		t.Errorf("expected only the first batch, got %d bytes", len(got))
+	}
+	if got := l.read(5, 1<<20); got != nil {
+		t.Errorf("expected no records at the high watermark, got %q", got)
+	}
+}
+
+func TestPartitionLogRetention(t *testing.T) {
+	size := batchHeaderSize + 1
+	l := newPartitionLog(2 * size)
+	for range 3 {
+		l.append(mustParse(t, newBatch(2, 0, "a")))
+	}
+	if l.start != 2 || l.end != 6 {
+		t.Errorf("expected offsets 2-6, got %d-%d", l.start, l.end)
+	}
+	if l.size != 2*size {
+		t.Errorf("expected size %d, got %d", 2*size, l.size)
+	}
+
+	// The newest batch is kept even when it exceeds the retention
+	l.append(mustParse(t, newBatch(1, 0, string(make([]byte, 3*size)))))
+	if len(l.batches) != 1 || l.start != 6 {
+		t.Errorf("expected only the newest batch from offset 6, got %d batches from %d", len(l.batches), l.start)
+	}
+}
+
+func TestPartitionLogOffsetForTime(t *testing.T) {
+	l := newPartitionLog(0)
+	l.append(mustParse(t, newBatch(2, 100, "a")))
+	l.append(mustParse(t, newBatch(2, 200, "b")))
+
+	tests := []struct {
+		ts         int64
+		wantOffset int64
+		wantTs     int64
+	}{
+		{0, 0, 100},
+		{150, 2, 200},
+		{200, 2, 200},
+		{201, -1, -1},
+	}
+	for _, tt := range tests {
+		offset, ts := l.offsetForTime(tt.ts)
+		if offset != tt.wantOffset || ts != tt.wantTs {
+			t.Errorf("offsetForTime(%d) = %d, %d; expected %d, %d", tt.ts, offset, ts, tt.wantOffset, tt.wantTs)
+		}
+	}
+}
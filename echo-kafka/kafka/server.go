@@ -0,0 +1,247 @@
+package kafka
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kbin"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+// ErrServerClosed is returned by Serve after Close
+var ErrServerClosed = errors.New("server closed")
+
+var errUnsupportedVersion = errors.New("unsupported version")
+
+// maxRequestSize bounds the size of a request (socket.request.max.bytes)
+const maxRequestSize = 100 << 20
+
+// Config controls the broker returned in metadata and the injected errors
+type Config struct {
+	// AdvertisedHost and AdvertisedPort are the address of the broker
+	// returned in metadata, which clients connect to after bootstrapping
+	AdvertisedHost string
+	AdvertisedPort int32
+
+	// ProduceError and FetchError are the error codes injected into
+	// produce and fetch responses
+	ProduceError int16
+	FetchError   int16
+
+	// ErrorRate is the probability (0-1) that a partition of a produce or
+	// fetch request is answered with ProduceError or FetchError
+	ErrorRate float64
+
+	// AutoCreateTopics creates unknown topics named in metadata requests,
+	// whether or not the client allows it
+	AutoCreateTopics bool
+
+	// RetentionBytes caps the size of each topic log (0 = unlimited)
+	RetentionBytes int
+
+	// Delay, if set, is read on every produce and fetch request and
+	// delays its handling
+	Delay func() time.Duration
+}
+
+// Server is a single Kafka broker serving single-partition topics from
+// in-memory logs. Produced record batches are stored as is and served back
+// on fetch. Requests of a connection are answered in order.
+type Server struct {
+	cfg Config
+
+	mu          sync.Mutex
+	topics      map[string]*topic
+	appended    chan struct{}
+	producerIDs int64
+	listener    net.Listener
+	conns       map[net.Conn]struct{}
+	closed      bool
+	done        chan struct{}
+}
+
+// New creates a broker without topics
+func New(cfg Config) *Server {
+	return &Server{
+		cfg:      cfg,
+		topics:   make(map[string]*topic),
+		appended: make(chan struct{}),
+		conns:    make(map[net.Conn]struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Serve accepts connections on l until Close is called
+func (s *Server) Serve(l net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return ErrServerClosed
+	}
+	s.listener = l
+	s.mu.Unlock()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return ErrServerClosed
+			}
+			return err
+		}
+		go s.serveConn(conn)
+	}
+}
+
+// Close stops accepting connections and closes the open ones
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.done)
+	}
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+	if s.listener != nil {
+		return s.listener.Close()
+	}
+	return nil
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		_ = conn.Close()
+		return
+	}
+	s.conns[conn] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		_ = conn.Close()
+	}()
+
+	r := bufio.NewReader(conn)
+	for {
+		body, err := readRequest(r)
+		if err != nil {
+			return
+		}
+		corrID, req, err := parseRequest(body)
+		var resp kmsg.Response
+		switch {
+		case errors.Is(err, errUnsupportedVersion) && req.Key() == kmsg.ApiVersions.Int16():
+			resp = apiVersionsResponse(0, kerr.UnsupportedVersion.Code)
+		case err != nil:
+			return
+		default:
+			resp = s.handle(req)
+		}
+		if resp == nil {
+			// Produce requests with acks=0 are not answered
+			continue
+		}
+		if _, err := conn.Write(appendResponse(nil, corrID, resp)); err != nil {
+			return
+		}
+	}
+}
+
+// readRequest reads a size-delimited request
+func readRequest(r io.Reader) ([]byte, error) {
+	var size [4]byte
+	if _, err := io.ReadFull(r, size[:]); err != nil {
+		return nil, err
+	}
+	n := int32(binary.BigEndian.Uint32(size[:]))
+	if n < 8 || n > maxRequestSize {
+		return nil, fmt.Errorf("invalid request size %d", n)
+	}
+	body := make([]byte, n)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// parseRequest decodes the request header and body. For unsupported versions
+// it returns the request without decoding it, as clients send ApiVersions
+// with their newest version and expect an UNSUPPORTED_VERSION reply listing
+// the supported ones.
+func parseRequest(body []byte) (int32, kmsg.Request, error) {
+	b := kbin.Reader{Src: body}
+	key := b.Int16()
+	version := b.Int16()
+	corrID := b.Int32()
+	b.NullableString() // client ID
+
+	req := kmsg.RequestForKey(key)
+	if req == nil {
+		return 0, nil, fmt.Errorf("unknown API key %d", key)
+	}
+	if !supported(key, version) {
+		return corrID, req, fmt.Errorf("%w: %s v%d", errUnsupportedVersion, kmsg.NameForKey(key), version)
+	}
+	req.SetVersion(version)
+	if req.IsFlexible() {
+		kmsg.SkipTags(&b)
+	}
+	if err := b.Complete(); err != nil {
+		return 0, nil, err
+	}
+	if err := req.ReadFrom(b.Src); err != nil {
+		return 0, nil, err
+	}
+	return corrID, req, nil
+}
+
+// appendResponse appends the size-delimited response to dst
+func appendResponse(dst []byte, corrID int32, resp kmsg.Response) []byte {
+	start := len(dst)
+	dst = append(dst, 0, 0, 0, 0)
+	dst = kbin.AppendInt32(dst, corrID)
+	// ApiVersions responses always use the v0 header
+	if resp.IsFlexible() && resp.Key() != kmsg.ApiVersions.Int16() {
+		dst = append(dst, 0)
+	}
+	dst = resp.AppendTo(dst)
+	binary.BigEndian.PutUint32(dst[start:], uint32(len(dst)-start-4))
+	return dst
+}
+
+// delay waits for the configured delay of a produce or fetch request
+func (s *Server) delay() {
+	if s.cfg.Delay != nil {
+		if d := s.cfg.Delay(); d > 0 {
+			time.Sleep(d)
+		}
+	}
+}
+
+// injectError returns the code of the error injected into a partition
+// response, or 0
+func (s *Server) injectError(topic string, code int16) int16 {
+	if code, ok := topicError(topic); ok {
+		return code
+	}
+	if code != 0 && s.cfg.ErrorRate > 0 && rand.Float64() < s.cfg.ErrorRate {
+		return code
+	}
+	return 0
+}
@@ -0,0 +1,263 @@
+package kafka
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+// startServer serves a broker advertising its random local port
+func startServer(t *testing.T, cfg Config) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	cfg.AutoCreateTopics = true
+	cfg.AdvertisedHost = "127.0.0.1"
+	cfg.AdvertisedPort = int32(l.Addr().(*net.TCPAddr).Port)
+	s := New(cfg)
+	go func() { _ = s.Serve(l) }()
+	t.Cleanup(func() { _ = s.Close() })
+	return l.Addr().String()
+}
+
+// newClient connects a client to addr
+func newClient(t *testing.T, addr string, opts ...kgo.Opt) *kgo.Client {
+	t.Helper()
+	cl, err := kgo.NewClient(append([]kgo.Opt{kgo.SeedBrokers(addr)}, opts...)...)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	t.Cleanup(cl.Close)
+	return cl
+}
+
+func testContext(t *testing.T) context.Context {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+func TestProduceFetch(t *testing.T) {
+	addr := startServer(t, Config{})
+	ctx := testContext(t)
+
+	producer := newClient(t, addr)
+	for i := range 3 {
+		r := &kgo.Record{Topic: "orders", Key: []byte("k"), Value: []byte("v" + strconv.Itoa(i))}
+		if err := producer.ProduceSync(ctx, r).FirstErr(); err != nil {
+			t.Fatalf("failed to produce: %v", err)
+		}
+		if r.Offset != int64(i) {
+			t.Errorf("expected offset %d, got %d", i, r.Offset)
+		}
+	}
+
+	consumer := newClient(t, addr,
+		kgo.ConsumeTopics("orders"),
+		kgo.ConsumeResetOffset(kgo.NewOffset().AtStart()),
+	)
+	var got []*kgo.Record
+	for len(got) < 3 {
+		fetches := consumer.PollFetches(ctx)
+		if err := fetches.Err(); err != nil {
+			t.Fatalf("failed to fetch: %v", err)
+		}
+		got = append(got, fetches.Records()...)
+	}
+	for i, r := range got {
+		if string(r.Value) != "v"+strconv.Itoa(i) || string(r.Key) != "k" || r.Offset != int64(i) {
+			t.Errorf("unexpected record %d: %s=%s at %d", i, r.Key, r.Value, r.Offset)
+		}
+	}
+}
+
+func TestFetchWaitsForRecords(t *testing.T) {
+	addr := startServer(t, Config{})
+	ctx := testContext(t)
+	producer := newClient(t, addr)
+	consumer := newClient(t, addr,
+		kgo.ConsumeTopics("late"),
+		kgo.ConsumeResetOffset(kgo.NewOffset().AtStart()),
+		kgo.FetchMaxWait(5*time.Second),
+	)
+
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		_ = producer.ProduceSync(ctx, &kgo.Record{Topic: "late", Value: []byte("hi")})
+	}()
+
+	start := time.Now()
+	fetches := consumer.PollFetches(ctx)
+	if err := fetches.Err(); err != nil {
+		t.Fatalf("failed to fetch: %v", err)
+	}
+	if records := fetches.Records(); len(records) != 1 || string(records[0].Value) != "hi" {
+		t.Fatalf("unexpected records %v", records)
+	}
+	if elapsed := time.Since(start); elapsed > 4*time.Second {
+		t.Errorf("fetch was not answered when records arrived (took %v)", elapsed)
+	}
+}
+
+func TestErrorTopic(t *testing.T) {
+	addr := startServer(t, Config{})
+	cl := newClient(t, addr)
+
+	r := &kgo.Record{Topic: "error-POLICY_VIOLATION", Value: []byte("x")}
+	err := cl.ProduceSync(testContext(t), r).FirstErr()
+	if !errors.Is(err, kerr.PolicyViolation) {
+		t.Errorf("expected POLICY_VIOLATION, got %v", err)
+	}
+}
+
+func TestErrorRate(t *testing.T) {
+	addr := startServer(t, Config{ProduceError: kerr.PolicyViolation.Code, ErrorRate: 1})
+	cl := newClient(t, addr)
+
+	err := cl.ProduceSync(testContext(t), &kgo.Record{Topic: "orders", Value: []byte("x")}).FirstErr()
+	if !errors.Is(err, kerr.PolicyViolation) {
+		t.Errorf("expected POLICY_VIOLATION, got %v", err)
+	}
+}
+
+func TestDelay(t *testing.T) {
+	delay := func() time.Duration { return 200 * time.Millisecond }
+	addr := startServer(t, Config{Delay: delay})
+	cl := newClient(t, addr)
+
+	start := time.Now()
+	if err := cl.ProduceSync(testContext(t), &kgo.Record{Topic: "orders", Value: []byte("x")}).FirstErr(); err != nil {
+		t.Fatalf("failed to produce: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("produce took %v, want the 200ms delay", elapsed)
+	}
+}
+
+func TestCreateTopics(t *testing.T) {
+	addr := startServer(t, Config{})
+	cl := newClient(t, addr)
+	ctx := testContext(t)
+
+	tests := []struct {
+		topic      string
+		partitions int32
+		want       int16
+	}{
+		{"created", 1, 0},
+		{"created", 1, kerr.TopicAlreadyExists.Code},
+		{"wide", 3, kerr.InvalidPartitions.Code},
+		{"bad/name", 1, kerr.InvalidTopicException.Code},
+	}
+	for _, tt := range tests {
+		req := kmsg.NewPtrCreateTopicsRequest()
+		rt := kmsg.NewCreateTopicsRequestTopic()
+		rt.Topic = tt.topic
+		rt.NumPartitions = tt.partitions
+		rt.ReplicationFactor = -1
+		req.Topics = append(req.Topics, rt)
+
+		resp, err := req.RequestWith(ctx, cl)
+		if err != nil {
+			t.Fatalf("failed to create topic: %v", err)
+		}
+		if got := resp.Topics[0].ErrorCode; got != tt.want {
+			t.Errorf("%s with %d partitions: expected error %d, got %d", tt.topic, tt.partitions, tt.want, got)
+		}
+	}
+}
+
+func TestListOffsets(t *testing.T) {
+	addr := startServer(t, Config{})
+	cl := newClient(t, addr)
+	ctx := testContext(t)
+
+	for _, ts := range []int64{1000, 2000} {
+		r := &kgo.Record{Topic: "orders", Value: []byte("x"), Timestamp: time.UnixMilli(ts)}
+		if err := cl.ProduceSync(ctx, r).FirstErr(); err != nil {
+			t.Fatalf("failed to produce: %v", err)
+		}
+	}
+
+	tests := []struct {
+		timestamp int64
+		want      int64
+	}{
+		{-2, 0},
+		{-1, 2},
+		{1500, 1},
+		{3000, -1},
+	}
+	for _, tt := range tests {
+		req := kmsg.NewPtrListOffsetsRequest()
+		rt := kmsg.NewListOffsetsRequestTopic()
+		rt.Topic = "orders"
+		rp := kmsg.NewListOffsetsRequestTopicPartition()
+		rp.Timestamp = tt.timestamp
+		rt.Partitions = append(rt.Partitions, rp)
+		req.Topics = append(req.Topics, rt)
+
+		resp, err := req.RequestWith(ctx, cl)
+		if err != nil {
+			t.Fatalf("failed to list offsets: %v", err)
+		}
+		p := resp.Topics[0].Partitions[0]
+		if p.ErrorCode != 0 || p.Offset != tt.want {
+			t.Errorf("timestamp %d: expected offset %d, got %d (error %d)", tt.timestamp, tt.want, p.Offset, p.ErrorCode)
+		}
+	}
+}
+
+func TestApiVersionsFallback(t *testing.T) {
+	addr := startServer(t, Config{})
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	// ApiVersions v99 with correlation ID 7 and a null client ID
+	req := []byte{0, 0, 0, 10, 0, 18, 0, 99, 0, 0, 0, 7, 0xff, 0xff}
+	if _, err := conn.Write(req); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+	body, err := readRequest(conn)
+	if err != nil {
+		t.Fatalf("failed to read response: %v", err)
+	}
+	if corrID := binary.BigEndian.Uint32(body); corrID != 7 {
+		t.Errorf("expected correlation ID 7, got %d", corrID)
+	}
+
+	// The reply is a v0 response listing the supported versions
+	resp := kmsg.ApiVersionsResponse{Version: 0}
+	if err := resp.ReadFrom(body[4:]); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.ErrorCode != kerr.UnsupportedVersion.Code || len(resp.ApiKeys) != len(apiVersions) {
+		t.Errorf("expected UNSUPPORTED_VERSION with %d APIs, got %d with %d", len(apiVersions), resp.ErrorCode, len(resp.ApiKeys))
+	}
+}
+
+func TestUnsupportedAPI(t *testing.T) {
+	addr := startServer(t, Config{})
+	cl := newClient(t, addr)
+
+	// Consumer groups are not served, so kgo refuses to send the request
+	req := kmsg.NewPtrFindCoordinatorRequest()
+	if _, err := req.RequestWith(testContext(t), cl); err == nil {
+		t.Error("expected FindCoordinator to be unsupported")
+	}
+}
@@ -0,0 +1,213 @@
+// Package app runs echo-mqtt: LoadConfig reads its settings and Run serves
+// it until its context ends, for the echo-mqtt binary and echo-all.
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"time"
+
+	mqtt "github.com/mochi-mqtt/server/v2"
+	"github.com/mochi-mqtt/server/v2/listeners"
+
+	"github.com/probitas-test/echo-servers/echo-mqtt/broker"
+	"github.com/probitas-test/echo-servers/echo-mqtt/docs"
+	"github.com/probitas-test/echo-servers/shared/admin"
+	"github.com/probitas-test/echo-servers/shared/bandwidth"
+	"github.com/probitas-test/echo-servers/shared/connlimit"
+	"github.com/probitas-test/echo-servers/shared/lifecycle"
+	"github.com/probitas-test/echo-servers/shared/logging"
+	"github.com/probitas-test/echo-servers/shared/network"
+	"github.com/probitas-test/echo-servers/shared/tracing"
+	"github.com/probitas-test/echo-servers/shared/version"
+)
+
+// Run serves echo-mqtt with cfg until ctx is done, then shuts it down
+// gracefully. Logging and the address family are set up by the caller, as
+// they are shared by the servers of a process.
+func Run(ctx context.Context, cfg *Config) error {
+	// Started servers stop when Run returns early with an error
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Simulated slow startup: no port opens before the startup delay
+	if err := cfg.Lifecycle.Validate(); err != nil {
+		return fmt.Errorf("invalid lifecycle configuration: %w", err)
+	}
+	log.Printf("Lifecycle: %s", cfg.Lifecycle)
+	lifecycle.Delay(ctx, cfg.Lifecycle)
+
+	// OpenTelemetry tracing and W3C trace context propagation
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		return fmt.Errorf("failed to set up tracing: %w", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Error("Tracing shutdown error", "error", err)
+		}
+	}()
+	log.Printf("OpenTelemetry tracing: %s", cfg.Tracing)
+
+	if cfg.MaxQoS < 0 || cfg.MaxQoS > 2 {
+		return fmt.Errorf("invalid MAX_QOS %d (must be 0-2)", cfg.MaxQoS)
+	}
+	if cfg.EchoTopicPrefix == "" {
+		return errors.New("ECHO_TOPIC_PREFIX must not be empty")
+	}
+	connectCfg := broker.ConnectConfig{
+		Username: cfg.AuthUsername,
+		Password: cfg.AuthPassword,
+	}
+	if cfg.ConnectRejectCode != "" {
+		code, err := broker.ParseCode(cfg.ConnectRejectCode)
+		if err != nil {
+			return fmt.Errorf("invalid CONNECT_REJECT_CODE: %w", err)
+		}
+		connectCfg.RejectCode = code
+	}
+
+	// Admin API: health and echo delay
+	adm := admin.New("echo-mqtt", cfg.src)
+
+	capabilities := mqtt.NewDefaultServerCapabilities()
+	capabilities.MaximumQos = byte(cfg.MaxQoS)
+	if !cfg.RetainAvailable {
+		capabilities.RetainAvailable = 0
+	}
+	server := mqtt.New(&mqtt.Options{
+		Capabilities: capabilities,
+	})
+
+	if err := server.AddHook(broker.NewConnectHook(connectCfg), nil); err != nil {
+		return fmt.Errorf("failed to add connect hook: %w", err)
+	}
+	echo := broker.NewEchoHook(server, cfg.EchoTopicPrefix)
+	echo.Delay = adm.Delay
+	if err := server.AddHook(echo, nil); err != nil {
+		return fmt.Errorf("failed to add echo hook: %w", err)
+	}
+
+	// Bandwidth shaping of every MQTT connection, changed at runtime on the
+	// admin port
+	shaper, err := bandwidth.New(cfg.Bandwidth)
+	if err != nil {
+		return err
+	}
+	log.Printf("Bandwidth: %s", cfg.Bandwidth)
+
+	// Cap on the open MQTT connections, changed at runtime on the admin port;
+	// the next ones get a server busy error
+	connLimiter, err := connlimit.New(cfg.ConnLimit)
+	if err != nil {
+		return err
+	}
+	log.Printf("Connection limit: %s", cfg.ConnLimit)
+
+	listener, err := network.Listen(cfg.Addr())
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	defer func() { _ = listener.Close() }()
+	if err := server.AddListener(listeners.NewNet("tcp", shaper.Listen(connLimiter.Listen(listener, broker.Busy)))); err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+
+	mux := http.NewServeMux()
+
+	// Health check endpoint
+	mux.Handle("GET /health", adm.HealthHandler())
+
+	// Effective configuration (secrets redacted)
+	mux.Handle("GET /config", cfg.src)
+
+	// Address family of the client connection
+	mux.Handle("GET "+network.Path, network.Handler())
+
+	// Build information and enabled features
+	mux.Handle("GET "+version.Path, version.Handler(version.New("echo-mqtt", version.Features{
+		"admin":   cfg.Admin.Enabled,
+		"debug":   cfg.Admin.Debug,
+		"retain":  cfg.RetainAvailable,
+		"tracing": cfg.Tracing.Enabled,
+	})))
+
+	// API documentation endpoint
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		_, _ = w.Write([]byte(docs.API))
+	})
+
+	// Admin API on a separate port, with the bandwidth and connection limit
+	// admin APIs
+	var adminServer *http.Server
+	if cfg.Admin.Enabled {
+		adm.Handle(bandwidth.Path, bandwidth.Handler(shaper))
+		adm.Handle(connlimit.Path, connlimit.Handler(connLimiter))
+		if cfg.Admin.Debug {
+			adm.Debug()
+		}
+		adminServer, err = adm.Serve(cfg.AdminAddr())
+		if err != nil {
+			return err
+		}
+		defer func() { _ = adminServer.Close() }()
+	}
+
+	// Readiness delay and simulated crash, counted from here
+	lifecycle.Start(ctx, cfg.Lifecycle, adm)
+
+	srv := &http.Server{
+		Addr:              cfg.HTTPAddr(),
+		Handler:           tracing.HTTP(nil)(logging.HTTP(mux)),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	// Graceful shutdown (MQTT clients are disconnected with reason code 0x8B
+	// server shutting down)
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		<-ctx.Done()
+
+		log.Println("Shutting down server...")
+		if err := server.Close(); err != nil {
+			slog.Error("MQTT server shutdown error", "error", err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := srv.Shutdown(ctx); err != nil {
+			slog.Error("Server shutdown error", "error", err)
+		}
+		if adminServer != nil {
+			_ = adminServer.Shutdown(ctx)
+		}
+	}()
+
+	log.Printf("Echo topic prefix: %q, max QoS: %d, retain available: %v", cfg.EchoTopicPrefix, cfg.MaxQoS, cfg.RetainAvailable)
+	if cfg.AuthUsername != "" {
+		log.Printf("Authentication enabled (username=%q)", cfg.AuthUsername)
+	}
+	if connectCfg.RejectCode != 0 {
+		log.Printf("Rejecting all connections with code 0x%02x", connectCfg.RejectCode)
+	}
+	log.Printf("Starting MQTT server on %s", cfg.Addr())
+	if err := server.Serve(); err != nil {
+		return fmt.Errorf("failed to serve MQTT: %w", err)
+	}
+	log.Printf("Starting HTTP server on %s", cfg.HTTPAddr())
+	if err := network.ListenAndServe(srv); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve: %w", err)
+	}
+	<-stopped
+
+	log.Println("Server stopped")
+	return nil
+}
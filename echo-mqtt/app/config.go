@@ -0,0 +1,110 @@
+package app
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/probitas-test/echo-servers/shared/admin"
+	"github.com/probitas-test/echo-servers/shared/bandwidth"
+	"github.com/probitas-test/echo-servers/shared/config"
+	"github.com/probitas-test/echo-servers/shared/connlimit"
+	"github.com/probitas-test/echo-servers/shared/lifecycle"
+	"github.com/probitas-test/echo-servers/shared/logging"
+	"github.com/probitas-test/echo-servers/shared/network"
+	"github.com/probitas-test/echo-servers/shared/tracing"
+)
+
+type Config struct {
+	Host string
+	Port string
+
+	// Port of the HTTP server for health checks and API documentation
+	HTTPPort string
+
+	// Prefix of the mirrored topics messages are echoed to
+	EchoTopicPrefix string
+
+	// Highest QoS granted to subscriptions and publishes (0-2)
+	MaxQoS int
+
+	// Whether retained messages are stored
+	RetainAvailable bool
+
+	// Credentials required from clients (not checked when AuthUsername is
+	// empty)
+	AuthUsername string
+	AuthPassword string
+
+	// CONNACK code every connection is rejected with (empty = accept)
+	ConnectRejectCode string
+
+	// Admin API on a separate port (ADMIN_ENABLED, ADMIN_PORT)
+	Admin admin.Config
+
+	// Bandwidth shaping of accepted connections (BANDWIDTH_*), changed at
+	// runtime at /bandwidth on the admin port
+	Bandwidth bandwidth.Config
+
+	// Connection cap (CONN_LIMIT_*), changed at runtime at /connlimit on the
+	// admin port
+	ConnLimit connlimit.Config
+
+	// Address family of every listener (IP_FAMILY: dual, ipv4 or ipv6)
+	Network network.Config
+
+	// Simulated startup delay, readiness delay and crash (STARTUP_DELAY_MS, STARTUP_READY_DELAY_MS, CRASH_AFTER_MS, CRASH_EXIT_CODE)
+	Lifecycle lifecycle.Config
+
+	// Structured logging (LOG_LEVEL, LOG_FORMAT)
+	Logging logging.Config
+
+	// OpenTelemetry tracing (OTEL_* variables)
+	Tracing tracing.Config
+
+	// Effective configuration, served at /config
+	src *config.Source
+}
+
+// LoadConfig reads the settings of echo-mqtt from src
+func LoadConfig(src *config.Source) (*Config, error) {
+	cfg := &Config{
+		Host:     src.String("HOST", "0.0.0.0"),
+		Port:     src.String("PORT", "1883"),
+		HTTPPort: src.String("HTTP_PORT", "8080"),
+
+		EchoTopicPrefix: src.String("ECHO_TOPIC_PREFIX", "echo/"),
+
+		MaxQoS:          src.Int("MAX_QOS", 2),
+		RetainAvailable: src.Bool("RETAIN_AVAILABLE", true),
+
+		AuthUsername: src.String("AUTH_USERNAME", ""),
+		AuthPassword: src.Secret("AUTH_PASSWORD", ""),
+
+		ConnectRejectCode: src.String("CONNECT_REJECT_CODE", ""),
+
+		Admin:     admin.LoadConfig(src),
+		Tracing:   tracing.LoadConfig(src, "echo-mqtt"),
+		Bandwidth: bandwidth.LoadConfig(src),
+		ConnLimit: connlimit.LoadConfig(src),
+		Network:   network.LoadConfig(src),
+		Lifecycle: lifecycle.LoadConfig(src),
+		Logging:   logging.LoadConfig(src),
+	}
+	cfg.src = src
+	if err := src.Err(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	return cfg, nil
+}
+
+func (c *Config) Addr() string {
+	return net.JoinHostPort(c.Host, c.Port)
+}
+
+func (c *Config) HTTPAddr() string {
+	return net.JoinHostPort(c.Host, c.HTTPPort)
+}
+
+func (c *Config) AdminAddr() string {
+	return net.JoinHostPort(c.Host, c.Admin.Port)
+}
@@ -0,0 +1,165 @@
+package broker
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	mqtt "github.com/mochi-mqtt/server/v2"
+	"github.com/mochi-mqtt/server/v2/packets"
+)
+
+// newTestBroker starts a broker with the echo hook (prefix "echo/") and a
+// connect hook using cfg
+func newTestBroker(t *testing.T, cfg ConnectConfig) *mqtt.Server {
+	t.Helper()
+	server := mqtt.New(&mqtt.Options{
+		Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	if err := server.AddHook(NewConnectHook(cfg), nil); err != nil {
+		t.Fatalf("failed to add connect hook: %v", err)
+	}
+	if err := server.AddHook(NewEchoHook(server, "echo/"), nil); err != nil {
+		t.Fatalf("failed to add echo hook: %v", err)
+	}
+	if err := server.Serve(); err != nil {
+		t.Fatalf("failed to serve: %v", err)
+	}
+	t.Cleanup(func() { _ = server.Close() })
+	return server
+}
+
+// testClient is a raw MQTT connection to a test broker
+type testClient struct {
+	t       *testing.T
+	conn    net.Conn
+	reader  *bufio.Reader
+	version byte
+}
+
+// dial connects a client over an in-memory pipe and sends CONNECT. The
+// CONNACK is left to be read.
+func dial(t *testing.T, server *mqtt.Server, version byte, clientID, username, password string) *testClient {
+	t.Helper()
+	clientConn, serverConn := net.Pipe()
+	go func() { _ = server.EstablishConnection("test", serverConn) }()
+	t.Cleanup(func() { _ = clientConn.Close() })
+	_ = clientConn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	c := &testClient{t: t, conn: clientConn, reader: bufio.NewReader(clientConn), version: version}
+	protocolName := "MQTT"
+	if version == 3 {
+		protocolName = "MQIsdp"
+	}
+	c.write(packets.Packet{
+		FixedHeader:     packets.FixedHeader{Type: packets.Connect},
+		ProtocolVersion: version,
+		Connect: packets.ConnectParams{
+			ProtocolName:     []byte(protocolName),
+			Clean:            true,
+			Keepalive:        30,
+			ClientIdentifier: clientID,
+			UsernameFlag:     username != "",
+			Username:         []byte(username),
+			PasswordFlag:     password != "",
+			Password:         []byte(password),
+		},
+	})
+	return c
+}
+
+// connect dials and expects a successful CONNACK
+func connect(t *testing.T, server *mqtt.Server, version byte, clientID string) *testClient {
+	t.Helper()
+	c := dial(t, server, version, clientID, "", "")
+	if ack := c.read(); ack.FixedHeader.Type != packets.Connack || ack.ReasonCode != 0 {
+		t.Fatalf("expected successful CONNACK, got type %d code 0x%02x", ack.FixedHeader.Type, ack.ReasonCode)
+	}
+	return c
+}
+
+func (c *testClient) write(pk packets.Packet) {
+	c.t.Helper()
+	pk.ProtocolVersion = c.version
+	pk.Mods.AllowResponseInfo = true
+	var buf bytes.Buffer
+	var err error
+	switch pk.FixedHeader.Type {
+	case packets.Connect:
+		err = pk.ConnectEncode(&buf)
+	case packets.Publish:
+		err = pk.PublishEncode(&buf)
+	case packets.Puback:
+		err = pk.PubackEncode(&buf)
+	case packets.Subscribe:
+		err = pk.SubscribeEncode(&buf)
+	default:
+		c.t.Fatalf("unsupported packet type %d", pk.FixedHeader.Type)
+	}
+	if err != nil {
+		c.t.Fatalf("failed to encode packet: %v", err)
+	}
+	if _, err := c.conn.Write(buf.Bytes()); err != nil {
+		c.t.Fatalf("failed to write packet: %v", err)
+	}
+}
+
+// read reads and decodes the next packet
+func (c *testClient) read() packets.Packet {
+	c.t.Helper()
+	pk, err := c.tryRead()
+	if err != nil {
+		c.t.Fatalf("failed to read packet: %v", err)
+	}
+	return pk
+}
+
+func (c *testClient) tryRead() (packets.Packet, error) {
+	header, err := c.reader.ReadByte()
+	if err != nil {
+		return packets.Packet{}, err
+	}
+	pk := packets.Packet{ProtocolVersion: c.version}
+	if err := pk.FixedHeader.Decode(header); err != nil {
+		return pk, err
+	}
+	length, _, err := packets.DecodeLength(c.reader)
+	if err != nil {
+		return pk, err
+	}
+	pk.FixedHeader.Remaining = length
+	body := make([]byte, length)
+	if _, err := io.ReadFull(c.reader, body); err != nil {
+		return pk, err
+	}
+
+	switch pk.FixedHeader.Type {
+	case packets.Connack:
+		err = pk.ConnackDecode(body)
+	case packets.Publish:
+		err = pk.PublishDecode(body)
+	case packets.Puback:
+		err = pk.PubackDecode(body)
+	case packets.Suback:
+		err = pk.SubackDecode(body)
+	case packets.Disconnect:
+		err = pk.DisconnectDecode(body)
+	}
+	return pk, err
+}
+
+func (c *testClient) subscribe(filter string, qos byte) {
+	c.t.Helper()
+	c.write(packets.Packet{
+		FixedHeader: packets.FixedHeader{Type: packets.Subscribe, Qos: 1},
+		PacketID:    1,
+		Filters:     packets.Subscriptions{{Filter: filter, Qos: qos}},
+	})
+	if ack := c.read(); ack.FixedHeader.Type != packets.Suback {
+		c.t.Fatalf("expected SUBACK, got type %d", ack.FixedHeader.Type)
+	}
+}
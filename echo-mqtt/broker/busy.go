@@ -0,0 +1,44 @@
+package broker
+
+import (
+	"bufio"
+	"io"
+	"net"
+)
+
+// Busy answers the CONNECT packet of a connection rejected by the
+// connection limit with a CONNACK refusing it: reason code 0x89 (server
+// busy) for MQTT 5.0 clients, return code 0x03 (server unavailable) for
+// MQTT 3.1.1 ones.
+func Busy(c net.Conn) {
+	br := bufio.NewReader(c)
+	if typ, err := br.ReadByte(); err != nil || typ>>4 != 1 {
+		return
+	}
+	// Remaining length, then the protocol name and level
+	for {
+		b, err := br.ReadByte()
+		if err != nil {
+			return
+		}
+		if b&0x80 == 0 {
+			break
+		}
+	}
+	var name [2]byte
+	if _, err := io.ReadFull(br, name[:]); err != nil {
+		return
+	}
+	if _, err := br.Discard(int(name[0])<<8 | int(name[1])); err != nil {
+		return
+	}
+	level, err := br.ReadByte()
+	if err != nil {
+		return
+	}
+	if level == 5 {
+		_, _ = c.Write([]byte{0x20, 0x03, 0x00, 0x89, 0x00})
+		return
+	}
+	_, _ = c.Write([]byte{0x20, 0x02, 0x00, 0x03})
+}
@@ -0,0 +1,39 @@
+package broker
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"testing"
+)
+
+func TestBusy(t *testing.T) {
+	tests := []struct {
+		name  string
+		level byte
+		want  []byte
+	}{
+		{"v3.1.1", 4, []byte{0x20, 0x02, 0x00, 0x03}},
+		{"v5", 5, []byte{0x20, 0x03, 0x00, 0x89, 0x00}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := net.Pipe()
+			defer func() { _ = client.Close() }()
+			go func() {
+				Busy(server)
+				_ = server.Close()
+			}()
+			// CONNECT up to the protocol level
+			connect := []byte{0x10, 0x0c, 0x00, 0x04, 'M', 'Q', 'T', 'T', tt.level, 0x02, 0x00, 0x3c, 0x00, 0x00}
+			go func() { _, _ = client.Write(connect) }()
+			got, err := io.ReadAll(client)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("CONNACK = %x, want %x", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package broker
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	mqtt "github.com/mochi-mqtt/server/v2"
+	"github.com/mochi-mqtt/server/v2/packets"
+)
+
+const (
+	// Client id prefixes selecting a connection mode
+	rejectPrefix = "reject-"
+	dropPrefix   = "drop-"
+	delayPrefix  = "delay-"
+
+	// maxConnackDelayMs is the longest CONNACK delay accepted from a client id
+	maxConnackDelayMs = 60000
+)
+
+// v3ReturnCodes maps MQTT 5.0 CONNACK reason codes to MQTT 3.1.1 return codes
+var v3ReturnCodes = map[byte]byte{
+	0x84: 0x01, // unsupported protocol version
+	0x85: 0x02, // client identifier not valid
+	0x88: 0x03, // server unavailable
+	0x89: 0x03, // server busy
+	0x86: 0x04, // bad user name or password
+	0x87: 0x05, // not authorized
+}
+
+// v5ReasonCodes maps MQTT 3.1.1 CONNACK return codes to MQTT 5.0 reason codes
+var v5ReasonCodes = map[byte]byte{
+	0x01: 0x84,
+	0x02: 0x85,
+	0x03: 0x88,
+	0x04: 0x86,
+	0x05: 0x87,
+}
+
+// ConnectConfig controls which connections are accepted
+type ConnectConfig struct {
+	// Credentials required from clients (not checked when Username is empty)
+	Username string
+	Password string
+
+	// RejectCode rejects every connection with this CONNACK reason or
+	// return code (0 = accept)
+	RejectCode byte
+}
+
+// ConnectHook accepts or rejects connections. Besides the configured
+// credentials and RejectCode, the client id selects a connection mode:
+//
+//   - reject-<code>: reject with CONNACK reason or return code <code>
+//     (decimal or 0x hex)
+//   - drop-...: close the connection without a CONNACK
+//   - delay-<ms>: wait <ms> milliseconds before the CONNACK
+type ConnectHook struct {
+	mqtt.HookBase
+
+	cfg ConnectConfig
+}
+
+// NewConnectHook creates a hook accepting connections per cfg
+func NewConnectHook(cfg ConnectConfig) *ConnectHook {
+	return &ConnectHook{cfg: cfg}
+}
+
+func (h *ConnectHook) ID() string {
+	return "connect"
+}
+
+func (h *ConnectHook) Provides(b byte) bool {
+	return b == mqtt.OnConnect || b == mqtt.OnConnectAuthenticate || b == mqtt.OnACLCheck
+}
+
+// OnConnect applies RejectCode and the connection mode of the client id.
+// Returning an error closes the connection.
+func (h *ConnectHook) OnConnect(cl *mqtt.Client, pk packets.Packet) error {
+	if h.cfg.RejectCode != 0 {
+		return reject(cl, h.cfg.RejectCode)
+	}
+
+	id := pk.Connect.ClientIdentifier
+	switch {
+	case strings.HasPrefix(id, rejectPrefix):
+		code, err := ParseCode(strings.TrimPrefix(id, rejectPrefix))
+		if err != nil {
+			return reject(cl, packets.ErrClientIdentifierNotValid.Code)
+		}
+		return reject(cl, code)
+
+	case strings.HasPrefix(id, dropPrefix):
+		return packets.ErrUnspecifiedError
+
+	case strings.HasPrefix(id, delayPrefix):
+		value, _, _ := strings.Cut(strings.TrimPrefix(id, delayPrefix), "-")
+		ms, err := strconv.Atoi(value)
+		if err != nil || ms < 0 || ms > maxConnackDelayMs {
+			return reject(cl, packets.ErrClientIdentifierNotValid.Code)
+		}
+		time.Sleep(time.Duration(ms) * time.Millisecond)
+	}
+	return nil
+}
+
+// OnConnectAuthenticate checks the credentials when they are configured
+func (h *ConnectHook) OnConnectAuthenticate(cl *mqtt.Client, pk packets.Packet) bool {
+	if h.cfg.Username == "" {
+		return true
+	}
+	username := subtle.ConstantTimeCompare(pk.Connect.Username, []byte(h.cfg.Username))
+	password := subtle.ConstantTimeCompare(pk.Connect.Password, []byte(h.cfg.Password))
+	return username&password == 1
+}
+
+// OnACLCheck allows every client to publish and subscribe to every topic
+func (h *ConnectHook) OnACLCheck(cl *mqtt.Client, topic string, write bool) bool {
+	return true
+}
+
+// reject sends a CONNACK with code, translated to the client's protocol
+// version, and returns the error closing the connection
+func reject(cl *mqtt.Client, code byte) error {
+	if cl.Properties.ProtocolVersion < 5 {
+		if v3, ok := v3ReturnCodes[code]; ok {
+			code = v3
+		} else if code >= 0x80 {
+			code = v3ReturnCodes[packets.ErrServerUnavailable.Code]
+		}
+	} else if v5, ok := v5ReasonCodes[code]; ok {
+		code = v5
+	}
+
+	reason := fmt.Sprintf("rejected with code 0x%02x", code)
+	_ = cl.WritePacket(packets.Packet{
+		FixedHeader: packets.FixedHeader{Type: packets.Connack},
+		ReasonCode:  code,
+		Properties:  packets.Properties{ReasonString: reason},
+	})
+	return packets.Code{Code: code, Reason: reason}
+}
+
+// ParseCode parses a CONNACK code given in decimal or 0x hex. It must be a
+// failure: an MQTT 3.1.1 return code (1-5) or MQTT 5.0 reason code
+// (0x80-0xff).
+func ParseCode(value string) (byte, error) {
+	value, _, _ = strings.Cut(value, "-")
+	code, err := strconv.ParseUint(value, 0, 8)
+	if err != nil || code == 0 || (code > 5 && code < 0x80) {
+		return 0, fmt.Errorf("invalid CONNACK code %q (must be 1-5 or 0x80-0xff)", value)
+	}
+	return byte(code), nil
+}
@@ -0,0 +1,96 @@
+package broker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mochi-mqtt/server/v2/packets"
+)
+
+func TestConnectHook_Reject(t *testing.T) {
+	tests := []struct {
+		name     string
+		version  byte
+		clientID string
+		cfg      ConnectConfig
+		expected byte
+	}{
+		{name: "v5 reason code", version: 5, clientID: "reject-0x87", expected: 0x87},
+		{name: "v5 decimal", version: 5, clientID: "reject-137-suffix", expected: 0x89},
+		{name: "v5 from v3 code", version: 5, clientID: "reject-4", expected: 0x86},
+		{name: "v3 return code", version: 4, clientID: "reject-2", expected: 0x02},
+		{name: "v3 from v5 code", version: 4, clientID: "reject-0x87", expected: 0x05},
+		{name: "v3 unmapped v5 code", version: 4, clientID: "reject-0x9f", expected: 0x03},
+		{name: "invalid code", version: 5, clientID: "reject-6", expected: 0x85},
+		{name: "invalid delay", version: 5, clientID: "delay-soon", expected: 0x85},
+		{name: "all rejected", version: 5, clientID: "anyone", cfg: ConnectConfig{RejectCode: 0x88}, expected: 0x88},
+		{name: "bad credentials", version: 5, clientID: "user", cfg: ConnectConfig{Username: "u", Password: "p"}, expected: 0x86},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTestBroker(t, tt.cfg)
+			c := dial(t, server, tt.version, tt.clientID, "", "")
+			ack := c.read()
+			if ack.FixedHeader.Type != packets.Connack || ack.ReasonCode != tt.expected {
+				t.Errorf("expected CONNACK 0x%02x, got type %d code 0x%02x", tt.expected, ack.FixedHeader.Type, ack.ReasonCode)
+			}
+		})
+	}
+}
+
+func TestConnectHook_Credentials(t *testing.T) {
+	server := newTestBroker(t, ConnectConfig{Username: "user", Password: "secret"})
+	c := dial(t, server, 4, "auth", "user", "secret")
+	if ack := c.read(); ack.ReasonCode != 0 {
+		t.Errorf("expected successful CONNACK, got 0x%02x", ack.ReasonCode)
+	}
+}
+
+func TestConnectHook_Drop(t *testing.T) {
+	server := newTestBroker(t, ConnectConfig{})
+	c := dial(t, server, 5, "drop-me", "", "")
+	if pk, err := c.tryRead(); err == nil {
+		t.Errorf("expected connection to be dropped, got packet type %d", pk.FixedHeader.Type)
+	}
+}
+
+func TestConnectHook_Delay(t *testing.T) {
+	server := newTestBroker(t, ConnectConfig{})
+	start := time.Now()
+	c := dial(t, server, 5, "delay-100-slow", "", "")
+	if ack := c.read(); ack.ReasonCode != 0 {
+		t.Fatalf("expected successful CONNACK, got 0x%02x", ack.ReasonCode)
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("expected CONNACK after at least 100ms, got %v", elapsed)
+	}
+}
+
+func TestParseCode(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected byte
+		wantErr  bool
+	}{
+		{value: "5", expected: 5},
+		{value: "0x80", expected: 0x80},
+		{value: "255", expected: 0xff},
+		{value: "0", wantErr: true},
+		{value: "6", wantErr: true},
+		{value: "256", wantErr: true},
+		{value: "abc", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			code, err := ParseCode(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if code != tt.expected {
+				t.Errorf("expected 0x%02x, got 0x%02x", tt.expected, code)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package broker
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"time"
+
+	mqtt "github.com/mochi-mqtt/server/v2"
+	"github.com/mochi-mqtt/server/v2/packets"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/probitas-test/echo-servers/shared/tracing"
+)
+
+// echoClientID is the client id echoed messages are published from
+const echoClientID = "echo"
+
+// EchoHook republishes every message to a mirrored topic: the topic with
+// Prefix prepended, or the MQTT 5.0 response topic when the publisher set
+// one. Messages on mirrored topics are not echoed again.
+type EchoHook struct {
+	mqtt.HookBase
+
+	// Delay, if set, is read on every message and delays its echo
+	Delay func() time.Duration
+
+	server *mqtt.Server
+	client *mqtt.Client
+	prefix string
+}
+
+// NewEchoHook creates a hook echoing messages published on server to
+// prefix + topic
+func NewEchoHook(server *mqtt.Server, prefix string) *EchoHook {
+	return &EchoHook{
+		server: server,
+		client: server.NewClient(nil, mqtt.LocalListener, echoClientID, true),
+		prefix: prefix,
+	}
+}
+
+func (h *EchoHook) ID() string {
+	return "echo"
+}
+
+func (h *EchoHook) Provides(b byte) bool {
+	return b == mqtt.OnPublished
+}
+
+// OnPublished echoes pk once it was delivered to the subscribers of its
+// topic. The echo keeps the payload, QoS (capped by MAX_QOS), retain flag and
+// MQTT 5.0 properties such as correlation data and user properties. Each
+// echo is traced in a span parented to the traceparent user property, named
+// in the traceresponse user property of the echo.
+func (h *EchoHook) OnPublished(cl *mqtt.Client, pk packets.Packet) {
+	if cl == h.client || strings.HasPrefix(pk.TopicName, h.prefix) {
+		return
+	}
+
+	if h.Delay != nil {
+		if d := h.Delay(); d > 0 {
+			time.Sleep(d)
+		}
+	}
+
+	topic := h.prefix + pk.TopicName
+	if pk.Properties.ResponseTopic != "" {
+		topic = pk.Properties.ResponseTopic
+	}
+
+	in := pk.Properties.User
+	ctx, span := tracing.Start(context.Background(), userPropertyCarrier{&in}, "process "+pk.TopicName, trace.SpanKindConsumer,
+		attribute.String("messaging.system", "mqtt"),
+		attribute.String("messaging.destination.name", pk.TopicName),
+		attribute.Int("messaging.message.body.size", len(pk.Payload)),
+	)
+	defer span.End()
+
+	echo := pk.Copy(false)
+	echo.TopicName = topic
+	echo.Payload = bytes.Clone(pk.Payload)
+	echo.Origin = ""
+	echo.Created = 0
+	echo.Expiry = 0
+	echo.Properties.TopicAlias = 0
+	echo.Properties.TopicAliasFlag = false
+	echo.Properties.SubscriptionIdentifier = nil
+	if echo.FixedHeader.Qos > 0 {
+		// Inline publishes are not acknowledged, but need a packet id to
+		// pass validation
+		echo.PacketID = uint16(echo.FixedHeader.Qos)
+	}
+
+	if pk.ProtocolVersion == 5 {
+		tracing.Echo(ctx, userPropertyCarrier{&in}, userPropertyCarrier{&echo.Properties.User})
+	}
+
+	if err := h.server.InjectPacket(h.client, echo); err != nil {
+		tracing.SetError(span, err)
+		h.Log.Warn("failed to echo message", "topic", pk.TopicName, "error", err)
+	}
+}
@@ -0,0 +1,172 @@
+package broker
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mochi-mqtt/server/v2/packets"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/probitas-test/echo-servers/shared/tracing"
+)
+
+// readPublish reads packets until a PUBLISH arrives
+func (c *testClient) readPublish() packets.Packet {
+	c.t.Helper()
+	for {
+		pk := c.read()
+		if pk.FixedHeader.Type == packets.Publish {
+			return pk
+		}
+	}
+}
+
+func TestEchoHook(t *testing.T) {
+	for _, version := range []byte{4, 5} {
+		t.Run(map[byte]string{4: "MQTT 3.1.1", 5: "MQTT 5.0"}[version], func(t *testing.T) {
+			server := newTestBroker(t, ConnectConfig{})
+			c := connect(t, server, version, "echo-test")
+			c.subscribe("echo/#", 1)
+
+			c.write(packets.Packet{
+				FixedHeader: packets.FixedHeader{Type: packets.Publish, Qos: 1},
+				PacketID:    7,
+				TopicName:   "sensors/temp",
+				Payload:     []byte("21.5"),
+			})
+
+			// The PUBACK and the echo may arrive in either order
+			var echo packets.Packet
+			acked := false
+			for echo.TopicName == "" || !acked {
+				pk := c.read()
+				switch pk.FixedHeader.Type {
+				case packets.Puback:
+					acked = pk.PacketID == 7
+				case packets.Publish:
+					echo = pk
+				}
+			}
+			if echo.TopicName != "echo/sensors/temp" || string(echo.Payload) != "21.5" {
+				t.Errorf("expected 21.5 on echo/sensors/temp, got %q on %s", echo.Payload, echo.TopicName)
+			}
+			if echo.FixedHeader.Qos != 1 {
+				t.Errorf("expected QoS 1, got %d", echo.FixedHeader.Qos)
+			}
+		})
+	}
+}
+
+func TestEchoHook_NoEchoOfMirroredTopics(t *testing.T) {
+	server := newTestBroker(t, ConnectConfig{})
+	c := connect(t, server, 4, "loop-test")
+	c.subscribe("#", 0)
+
+	c.write(packets.Packet{
+		FixedHeader: packets.FixedHeader{Type: packets.Publish},
+		TopicName:   "echo/a",
+		Payload:     []byte("x"),
+	})
+	c.write(packets.Packet{
+		FixedHeader: packets.FixedHeader{Type: packets.Publish},
+		TopicName:   "b",
+		Payload:     []byte("y"),
+	})
+
+	// Expect echo/a (own message), b (own message), echo/b (echo) and no
+	// echo/echo/a
+	var topics []string
+	for range 3 {
+		topics = append(topics, c.readPublish().TopicName)
+	}
+	expected := []string{"echo/a", "b", "echo/b"}
+	for i, topic := range expected {
+		if topics[i] != topic {
+			t.Fatalf("expected topics %v, got %v", expected, topics)
+		}
+	}
+}
+
+func TestEchoHook_ResponseTopic(t *testing.T) {
+	server := newTestBroker(t, ConnectConfig{})
+	c := connect(t, server, 5, "request-test")
+	c.subscribe("replies/me", 0)
+
+	c.write(packets.Packet{
+		FixedHeader: packets.FixedHeader{Type: packets.Publish},
+		TopicName:   "requests",
+		Payload:     []byte("ping"),
+		Properties: packets.Properties{
+			ResponseTopic:   "replies/me",
+			CorrelationData: []byte("42"),
+			User:            []packets.UserProperty{{Key: "k", Val: "v"}},
+		},
+	})
+
+	echo := c.readPublish()
+	if echo.TopicName != "replies/me" || string(echo.Payload) != "ping" {
+		t.Errorf("expected ping on replies/me, got %q on %s", echo.Payload, echo.TopicName)
+	}
+	if string(echo.Properties.CorrelationData) != "42" {
+		t.Errorf("expected correlation data 42, got %q", echo.Properties.CorrelationData)
+	}
+	if len(echo.Properties.User) != 1 || echo.Properties.User[0].Key != "k" {
+		t.Errorf("expected user property k=v, got %v", echo.Properties.User)
+	}
+}
+
+func TestEchoHook_Retained(t *testing.T) {
+	server := newTestBroker(t, ConnectConfig{})
+	publisher := connect(t, server, 4, "retain-publisher")
+	publisher.subscribe("echo/status", 0)
+	publisher.write(packets.Packet{
+		FixedHeader: packets.FixedHeader{Type: packets.Publish, Retain: true},
+		TopicName:   "status",
+		Payload:     []byte("online"),
+	})
+
+	// Once the echo was delivered, a later subscriber receives it retained
+	publisher.readPublish()
+	subscriber := connect(t, server, 4, "retain-subscriber")
+	subscriber.subscribe("echo/status", 0)
+	echo := subscriber.readPublish()
+	if !echo.FixedHeader.Retain || string(echo.Payload) != "online" {
+		t.Errorf("expected retained online, got retain=%v %q", echo.FixedHeader.Retain, echo.Payload)
+	}
+}
+
+func TestEchoHook_TraceContext(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	otel.SetTracerProvider(sdktrace.NewTracerProvider())
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+
+	server := newTestBroker(t, ConnectConfig{})
+	c := connect(t, server, 5, "trace-test")
+	c.subscribe("echo/#", 0)
+
+	traceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+	traceparent := "00-" + traceID + "-00f067aa0ba902b7-01"
+	c.write(packets.Packet{
+		FixedHeader: packets.FixedHeader{Type: packets.Publish},
+		TopicName:   "orders",
+		Payload:     []byte("1"),
+		Properties: packets.Properties{
+			User: []packets.UserProperty{{Key: "traceparent", Val: traceparent}},
+		},
+	})
+
+	echo := c.readPublish()
+	props := map[string]string{}
+	for _, p := range echo.Properties.User {
+		props[p.Key] = p.Val
+	}
+	if len(echo.Properties.User) != 2 || props["traceparent"] != traceparent {
+		t.Errorf("expected the traceparent once, got %v", echo.Properties.User)
+	}
+	if !strings.HasPrefix(props[tracing.TraceResponse], "00-"+traceID+"-") {
+		t.Errorf("expected a traceresponse of trace %s, got %q", traceID, props[tracing.TraceResponse])
+	}
+}
@@ -0,0 +1,40 @@
+package broker
+
+import (
+	"strings"
+
+	"github.com/mochi-mqtt/server/v2/packets"
+)
+
+// userPropertyCarrier adapts the MQTT 5.0 user properties of a packet to
+// the OpenTelemetry propagators. Lookups ignore case.
+type userPropertyCarrier struct {
+	props *[]packets.UserProperty
+}
+
+func (c userPropertyCarrier) Get(key string) string {
+	for _, p := range *c.props {
+		if strings.EqualFold(p.Key, key) {
+			return p.Val
+		}
+	}
+	return ""
+}
+
+func (c userPropertyCarrier) Set(key, value string) {
+	props := (*c.props)[:0]
+	for _, p := range *c.props {
+		if !strings.EqualFold(p.Key, key) {
+			props = append(props, p)
+		}
+	}
+	*c.props = append(props, packets.UserProperty{Key: key, Val: value})
+}
+
+func (c userPropertyCarrier) Keys() []string {
+	keys := make([]string, 0, len(*c.props))
+	for _, p := range *c.props {
+		keys = append(keys, p.Key)
+	}
+	return keys
+}
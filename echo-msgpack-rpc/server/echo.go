@@ -0,0 +1,150 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	// MaxPayloadSize is the maximum allowed payload size (10MB)
+	MaxPayloadSize = 10 * 1024 * 1024
+
+	// MaxStreamCount bounds the responses of ServerStream
+	MaxStreamCount = 1000
+)
+
+// Request and response types mirror the messages of the gRPC Echo service.
+// Field names are used by gob, msgpack tags by MessagePack-RPC.
+
+type EchoRequest struct {
+	Message string `msgpack:"message"`
+}
+
+type EchoWithDelayRequest struct {
+	Message string `msgpack:"message"`
+	DelayMs int32  `msgpack:"delayMs"`
+}
+
+type EchoErrorRequest struct {
+	Message string `msgpack:"message"`
+	Code    int32  `msgpack:"code"`
+	Details string `msgpack:"details"`
+}
+
+type EchoLargePayloadRequest struct {
+	SizeBytes int32  `msgpack:"sizeBytes"`
+	Pattern   string `msgpack:"pattern"`
+}
+
+type ServerStreamRequest struct {
+	Message    string `msgpack:"message"`
+	Count      int32  `msgpack:"count"`
+	IntervalMs int32  `msgpack:"intervalMs"`
+}
+
+type EchoResponse struct {
+	Message string `msgpack:"message"`
+}
+
+type EchoLargePayloadResponse struct {
+	Payload    []byte `msgpack:"payload"`
+	ActualSize int32  `msgpack:"actualSize"`
+}
+
+// Echo implements the echo methods shared by both protocols
+type Echo struct{}
+
+func (Echo) Echo(req *EchoRequest) *EchoResponse {
+	return &EchoResponse{Message: req.Message}
+}
+
+func (Echo) EchoWithDelay(ctx context.Context, req *EchoWithDelayRequest) (*EchoResponse, error) {
+	if req.DelayMs > 0 {
+		if err := sleep(ctx, time.Duration(req.DelayMs)*time.Millisecond); err != nil {
+			return nil, err
+		}
+	}
+	return &EchoResponse{Message: req.Message}, nil
+}
+
+func (Echo) EchoError(req *EchoErrorRequest) error {
+	code := Code(req.Code)
+	if req.Code < 0 || code > Unauthenticated {
+		code = Unknown
+	}
+
+	details := req.Details
+	if details == "" {
+		details = fmt.Sprintf("error with code %d: %s", req.Code, req.Message)
+	}
+
+	return &Error{Code: code, Message: details}
+}
+
+func (Echo) EchoLargePayload(req *EchoLargePayloadRequest) (*EchoLargePayloadResponse, error) {
+	size := int(req.SizeBytes)
+	if size <= 0 {
+		size = 1
+	}
+	if size > MaxPayloadSize {
+		return nil, Errorf(InvalidArgument, "requested size %d exceeds maximum %d bytes", size, MaxPayloadSize)
+	}
+
+	pattern := req.Pattern
+	if pattern == "" {
+		pattern = "X"
+	}
+
+	// Generate payload by repeating pattern
+	patternBytes := []byte(pattern)
+	payload := bytes.Repeat(patternBytes, (size/len(patternBytes))+1)
+	payload = payload[:size]
+
+	return &EchoLargePayloadResponse{
+		Payload:    payload,
+		ActualSize: int32(len(payload)),
+	}, nil
+}
+
+// ServerStream calls send count times, waiting IntervalMs between responses
+func (Echo) ServerStream(ctx context.Context, req *ServerStreamRequest, send func(*EchoResponse) error) error {
+	count := req.Count
+	if count <= 0 {
+		count = 1
+	}
+	if count > MaxStreamCount {
+		return Errorf(InvalidArgument, "count %d exceeds maximum %d", count, MaxStreamCount)
+	}
+
+	interval := time.Duration(req.IntervalMs) * time.Millisecond
+
+	for i := int32(0); i < count; i++ {
+		resp := &EchoResponse{
+			Message: fmt.Sprintf("%s [%d/%d]", req.Message, i+1, count),
+		}
+		if err := send(resp); err != nil {
+			return err
+		}
+
+		if i < count-1 && interval > 0 {
+			if err := sleep(ctx, interval); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// sleep waits d unless ctx is done first
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return Errorf(Canceled, "request canceled")
+	}
+}
@@ -0,0 +1,155 @@
+package server
+
+import (
+	"context"
+	"net"
+	"net/http/httptest"
+	"net/rpc"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEchoError(t *testing.T) {
+	tests := []struct {
+		req  EchoErrorRequest
+		code Code
+		msg  string
+	}{
+		{EchoErrorRequest{Message: "hi", Code: 5}, NotFound, "error with code 5: hi"},
+		{EchoErrorRequest{Code: 14, Details: "try later"}, Unavailable, "try later"},
+		{EchoErrorRequest{Code: 99}, Unknown, "error with code 99: "},
+		{EchoErrorRequest{Code: -1}, Unknown, "error with code -1: "},
+	}
+	for _, tt := range tests {
+		err := Echo{}.EchoError(&tt.req)
+		rpcErr, ok := err.(*Error)
+		if !ok || rpcErr.Code != tt.code || rpcErr.Message != tt.msg {
+			t.Errorf("EchoError(%+v) = %v, want %s %q", tt.req, err, tt.code, tt.msg)
+		}
+	}
+	if got := Errorf(NotFound, "gone").Error(); got != "rpc error: code = NotFound desc = gone" {
+		t.Errorf("Error() = %q", got)
+	}
+}
+
+func TestEchoLargePayload(t *testing.T) {
+	resp, err := Echo{}.EchoLargePayload(&EchoLargePayloadRequest{SizeBytes: 5, Pattern: "ab"})
+	if err != nil || string(resp.Payload) != "ababa" || resp.ActualSize != 5 {
+		t.Errorf("EchoLargePayload() = %+v, %v", resp, err)
+	}
+	if _, err := (Echo{}).EchoLargePayload(&EchoLargePayloadRequest{SizeBytes: MaxPayloadSize + 1}); err == nil {
+		t.Error("EchoLargePayload() over the maximum succeeded")
+	}
+}
+
+func TestEchoWithDelayCanceled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := Echo{}.EchoWithDelay(ctx, &EchoWithDelayRequest{DelayMs: 5000})
+	if rpcErr, ok := err.(*Error); !ok || rpcErr.Code != Canceled {
+		t.Errorf("EchoWithDelay() error = %v, want Canceled", err)
+	}
+}
+
+func startGob(t *testing.T, cfg Config) (*Server, string) {
+	t.Helper()
+	s := NewServer(cfg)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	go func() { _ = s.ServeGob(l) }()
+	t.Cleanup(func() { _ = s.Close() })
+	return s, l.Addr().String()
+}
+
+func TestGob(t *testing.T) {
+	_, addr := startGob(t, Config{})
+	client, err := rpc.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	var resp EchoResponse
+	if err := client.Call("Echo.Echo", &EchoRequest{Message: "hello"}, &resp); err != nil || resp.Message != "hello" {
+		t.Errorf("Echo.Echo = %+v, %v", resp, err)
+	}
+
+	start := time.Now()
+	if err := client.Call("Echo.EchoWithDelay", &EchoWithDelayRequest{Message: "late", DelayMs: 50}, &resp); err != nil || resp.Message != "late" {
+		t.Errorf("Echo.EchoWithDelay = %+v, %v", resp, err)
+	}
+	if time.Since(start) < 50*time.Millisecond {
+		t.Error("Echo.EchoWithDelay returned before the delay")
+	}
+
+	err = client.Call("Echo.EchoError", &EchoErrorRequest{Code: 7, Details: "denied"}, &resp)
+	if err == nil || err.Error() != "rpc error: code = PermissionDenied desc = denied" {
+		t.Errorf("Echo.EchoError error = %v", err)
+	}
+
+	var payload EchoLargePayloadResponse
+	if err := client.Call("Echo.EchoLargePayload", &EchoLargePayloadRequest{SizeBytes: 1024}, &payload); err != nil || payload.ActualSize != 1024 {
+		t.Errorf("Echo.EchoLargePayload = %d, %v", payload.ActualSize, err)
+	}
+}
+
+func TestGobDelay(t *testing.T) {
+	_, addr := startGob(t, Config{Delay: func() time.Duration { return 50 * time.Millisecond }})
+	client, err := rpc.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	start := time.Now()
+	var resp EchoResponse
+	if err := client.Call("Echo.Echo", &EchoRequest{Message: "hello"}, &resp); err != nil || resp.Message != "hello" {
+		t.Errorf("Echo.Echo = %+v, %v", resp, err)
+	}
+	if time.Since(start) < 50*time.Millisecond {
+		t.Error("Echo.Echo returned before the delay")
+	}
+}
+
+func TestGobHTTP(t *testing.T) {
+	s := NewServer(Config{})
+	srv := httptest.NewServer(s.GobHandler())
+	defer srv.Close()
+	defer func() { _ = s.Close() }()
+
+	client, err := rpc.DialHTTP("tcp", strings.TrimPrefix(srv.URL, "http://"))
+	if err != nil {
+		t.Fatalf("DialHTTP() error = %v", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	var resp EchoResponse
+	if err := client.Call("Echo.Echo", &EchoRequest{Message: "over http"}, &resp); err != nil || resp.Message != "over http" {
+		t.Errorf("Echo.Echo = %+v, %v", resp, err)
+	}
+}
+
+func TestGobClose(t *testing.T) {
+	s, addr := startGob(t, Config{})
+	client, err := rpc.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	call := client.Go("Echo.EchoWithDelay", &EchoWithDelayRequest{DelayMs: 10000}, &EchoResponse{}, nil)
+	time.Sleep(50 * time.Millisecond)
+	_ = s.Close()
+
+	select {
+	case <-call.Done:
+		if call.Error == nil {
+			t.Error("delayed call succeeded after Close()")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("delayed call still pending after Close()")
+	}
+}
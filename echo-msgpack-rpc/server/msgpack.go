@@ -0,0 +1,180 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"sync"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"github.com/vmihailenco/msgpack/v5/msgpcode"
+)
+
+// MessagePack-RPC message types
+const (
+	typeRequest      = 0
+	typeResponse     = 1
+	typeNotification = 2
+)
+
+// serveMsgpack reads MessagePack-RPC messages from conn. Requests are
+// handled concurrently, so responses may arrive out of order; notifications
+// are echoed back unchanged.
+func (s *Server) serveMsgpack(conn net.Conn) {
+	ctx, cancel := context.WithCancel(s.ctx)
+	var wg sync.WaitGroup
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	var mu sync.Mutex
+	enc := msgpack.NewEncoder(conn)
+	write := func(msg ...any) error {
+		mu.Lock()
+		defer mu.Unlock()
+		return enc.Encode(msg)
+	}
+
+	dec := msgpack.NewDecoder(bufio.NewReader(conn))
+	for {
+		var msg []msgpack.RawMessage
+		if err := dec.Decode(&msg); err != nil {
+			return
+		}
+		var typ int
+		if len(msg) == 0 || msgpack.Unmarshal(msg[0], &typ) != nil {
+			return
+		}
+
+		switch {
+		case typ == typeRequest && len(msg) == 4:
+			var msgid uint32
+			var method string
+			var params []msgpack.RawMessage
+			if msgpack.Unmarshal(msg[1], &msgid) != nil {
+				return
+			}
+			if err := unmarshalCall(msg[2], msg[3], &method, &params); err != nil {
+				_ = write(typeResponse, msgid, Errorf(InvalidArgument, "%v", err), nil)
+				continue
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				result, err := s.callMsgpack(ctx, method, params, func(resp *EchoResponse) error {
+					return write(typeNotification, method, []any{resp})
+				})
+				var rpcErr *Error
+				if err != nil && !errors.As(err, &rpcErr) {
+					rpcErr = Errorf(Internal, "%v", err)
+				}
+				if rpcErr != nil {
+					_ = write(typeResponse, msgid, rpcErr, nil)
+				} else {
+					_ = write(typeResponse, msgid, nil, result)
+				}
+			}()
+
+		case typ == typeNotification && len(msg) == 3:
+			var method string
+			var params []msgpack.RawMessage
+			if unmarshalCall(msg[1], msg[2], &method, &params) != nil {
+				continue
+			}
+			_ = write(typeNotification, method, params)
+
+		default:
+			// Not a MessagePack-RPC client
+			return
+		}
+	}
+}
+
+func unmarshalCall(rawMethod, rawParams msgpack.RawMessage, method *string, params *[]msgpack.RawMessage) error {
+	if err := msgpack.Unmarshal(rawMethod, method); err != nil {
+		return fmt.Errorf("invalid method: %w", err)
+	}
+	if err := msgpack.Unmarshal(rawParams, params); err != nil {
+		return fmt.Errorf("invalid params (must be an array): %w", err)
+	}
+	return nil
+}
+
+// callMsgpack calls a method; send delivers ServerStream responses as
+// notifications
+func (s *Server) callMsgpack(ctx context.Context, method string, params []msgpack.RawMessage, send func(*EchoResponse) error) (any, error) {
+	if err := s.delay(ctx); err != nil {
+		return nil, err
+	}
+	switch method {
+	case "Echo":
+		var req EchoRequest
+		if err := decodeParams(params, &req); err != nil {
+			return nil, err
+		}
+		return s.echo.Echo(&req), nil
+	case "EchoWithDelay":
+		var req EchoWithDelayRequest
+		if err := decodeParams(params, &req); err != nil {
+			return nil, err
+		}
+		return s.echo.EchoWithDelay(ctx, &req)
+	case "EchoError":
+		var req EchoErrorRequest
+		if err := decodeParams(params, &req); err != nil {
+			return nil, err
+		}
+		return nil, s.echo.EchoError(&req)
+	case "EchoLargePayload":
+		var req EchoLargePayloadRequest
+		if err := decodeParams(params, &req); err != nil {
+			return nil, err
+		}
+		return s.echo.EchoLargePayload(&req)
+	case "ServerStream":
+		var req ServerStreamRequest
+		if err := decodeParams(params, &req); err != nil {
+			return nil, err
+		}
+		return nil, s.echo.ServerStream(ctx, &req, send)
+	default:
+		return nil, Errorf(Unimplemented, "method %s not found", method)
+	}
+}
+
+// decodeParams decodes a single map parameter by field name, or positional
+// parameters in field order, into req (a pointer to a request struct)
+func decodeParams(params []msgpack.RawMessage, req any) error {
+	if len(params) == 1 && isMap(params[0]) {
+		dec := msgpack.NewDecoder(bytes.NewReader(params[0]))
+		dec.DisallowUnknownFields(true)
+		if err := dec.Decode(req); err != nil {
+			return Errorf(InvalidArgument, "invalid params: %v", err)
+		}
+		return nil
+	}
+
+	v := reflect.ValueOf(req).Elem()
+	if len(params) > v.NumField() {
+		return Errorf(InvalidArgument, "too many params: %d (at most %d)", len(params), v.NumField())
+	}
+	for i, p := range params {
+		if err := msgpack.Unmarshal(p, v.Field(i).Addr().Interface()); err != nil {
+			return Errorf(InvalidArgument, "invalid param %d (%s): %v", i, v.Type().Field(i).Tag.Get("msgpack"), err)
+		}
+	}
+	return nil
+}
+
+func isMap(raw msgpack.RawMessage) bool {
+	if len(raw) == 0 {
+		return false
+	}
+	c := raw[0]
+	return msgpcode.IsFixedMap(c) || c == msgpcode.Map16 || c == msgpcode.Map32
+}
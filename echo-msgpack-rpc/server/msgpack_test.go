@@ -0,0 +1,183 @@
+package server
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// msgpackClient is a minimal MessagePack-RPC client
+type msgpackClient struct {
+	t    *testing.T
+	conn net.Conn
+	enc  *msgpack.Encoder
+	dec  *msgpack.Decoder
+}
+
+func dialMsgpack(t *testing.T) *msgpackClient {
+	t.Helper()
+	s := NewServer(Config{})
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	go func() { _ = s.ServeMsgpack(l) }()
+	t.Cleanup(func() { _ = s.Close() })
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	_ = conn.SetDeadline(time.Now().Add(10 * time.Second))
+	return &msgpackClient{t: t, conn: conn, enc: msgpack.NewEncoder(conn), dec: msgpack.NewDecoder(conn)}
+}
+
+func (c *msgpackClient) send(msg ...any) {
+	c.t.Helper()
+	if err := c.enc.Encode(msg); err != nil {
+		c.t.Fatalf("send: %v", err)
+	}
+}
+
+func (c *msgpackClient) recv() []any {
+	c.t.Helper()
+	msg, err := c.dec.DecodeSlice()
+	if err != nil {
+		c.t.Fatalf("recv: %v", err)
+	}
+	return msg
+}
+
+// call sends a request and returns the error and result of its response
+func (c *msgpackClient) call(msgid uint32, method string, params ...any) (any, any) {
+	c.t.Helper()
+	if params == nil {
+		params = []any{}
+	}
+	c.send(typeRequest, msgid, method, params)
+	msg := c.recv()
+	if len(msg) != 4 || toInt(msg[0]) != typeResponse || toInt(msg[1]) != int64(msgid) {
+		c.t.Fatalf("response = %v", msg)
+	}
+	return msg[2], msg[3]
+}
+
+func toInt(v any) int64 {
+	switch n := v.(type) {
+	case int8:
+		return int64(n)
+	case int16:
+		return int64(n)
+	case int32:
+		return int64(n)
+	case int64:
+		return n
+	case uint8:
+		return int64(n)
+	case uint16:
+		return int64(n)
+	case uint32:
+		return int64(n)
+	case uint64:
+		return int64(n)
+	}
+	return -1
+}
+
+func errorCode(t *testing.T, rpcErr any) Code {
+	t.Helper()
+	m, ok := rpcErr.(map[string]any)
+	if !ok {
+		t.Fatalf("error = %v, want a map", rpcErr)
+	}
+	return Code(toInt(m["code"]))
+}
+
+func TestMsgpackEcho(t *testing.T) {
+	c := dialMsgpack(t)
+
+	// A map parameter and positional parameters
+	for _, params := range [][]any{{map[string]any{"message": "hello"}}, {"hello"}} {
+		rpcErr, result := c.call(1, "Echo", params...)
+		if rpcErr != nil {
+			t.Fatalf("Echo error = %v", rpcErr)
+		}
+		if m, _ := result.(map[string]any); m["message"] != "hello" {
+			t.Errorf("Echo(%v) = %v", params, result)
+		}
+	}
+
+	_, result := c.call(2, "EchoLargePayload", 4, "xy")
+	if m, _ := result.(map[string]any); string(m["payload"].([]byte)) != "xyxy" || toInt(m["actualSize"]) != 4 {
+		t.Errorf("EchoLargePayload = %v", result)
+	}
+
+	rpcErr, result := c.call(3, "EchoError", "boom", 13)
+	if result != nil || errorCode(t, rpcErr) != Internal {
+		t.Errorf("EchoError = %v, %v", rpcErr, result)
+	}
+	if m := rpcErr.(map[string]any); m["message"] != "error with code 13: boom" {
+		t.Errorf("EchoError message = %v", m["message"])
+	}
+
+	for method, params := range map[string][]any{
+		"Unknown":          nil,
+		"Echo":             {"a", "b"},
+		"EchoWithDelay":    {map[string]any{"bogus": 1}},
+		"EchoLargePayload": {"not a number"},
+	} {
+		rpcErr, _ := c.call(4, method, params...)
+		want := InvalidArgument
+		if method == "Unknown" {
+			want = Unimplemented
+		}
+		if got := errorCode(t, rpcErr); got != want {
+			t.Errorf("%s(%v) code = %s, want %s", method, params, got, want)
+		}
+	}
+}
+
+func TestMsgpackConcurrentRequests(t *testing.T) {
+	c := dialMsgpack(t)
+
+	// The delayed response arrives after the immediate one
+	c.send(typeRequest, 1, "EchoWithDelay", []any{"slow", 200})
+	c.send(typeRequest, 2, "Echo", []any{"fast"})
+	first, second := c.recv(), c.recv()
+	if toInt(first[1]) != 2 || toInt(second[1]) != 1 {
+		t.Errorf("responses = %v, %v; want msgid 2 first", first, second)
+	}
+}
+
+func TestMsgpackServerStream(t *testing.T) {
+	c := dialMsgpack(t)
+
+	c.send(typeRequest, 7, "ServerStream", []any{map[string]any{"message": "tick", "count": 3}})
+	for i, want := range []string{"tick [1/3]", "tick [2/3]", "tick [3/3]"} {
+		msg := c.recv()
+		if len(msg) != 3 || toInt(msg[0]) != typeNotification || msg[1] != "ServerStream" {
+			t.Fatalf("message %d = %v, want notification", i, msg)
+		}
+		if m, _ := msg[2].([]any)[0].(map[string]any); m["message"] != want {
+			t.Errorf("notification %d = %v, want %q", i, msg[2], want)
+		}
+	}
+	if msg := c.recv(); toInt(msg[0]) != typeResponse || toInt(msg[1]) != 7 || msg[2] != nil {
+		t.Errorf("response = %v", msg)
+	}
+}
+
+func TestMsgpackNotification(t *testing.T) {
+	c := dialMsgpack(t)
+
+	c.send(typeNotification, "event", []any{"payload", 1})
+	msg := c.recv()
+	if len(msg) != 3 || toInt(msg[0]) != typeNotification || msg[1] != "event" {
+		t.Fatalf("echoed notification = %v", msg)
+	}
+	if params := msg[2].([]any); len(params) != 2 || params[0] != "payload" {
+		t.Errorf("params = %v", params)
+	}
+}
@@ -0,0 +1,224 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/rpc"
+	"sync"
+	"time"
+)
+
+// ErrServerClosed is returned by ServeMsgpack and ServeGob after Close
+var ErrServerClosed = errors.New("server closed")
+
+// Config controls how methods are answered
+type Config struct {
+	// Delay, if set, is read on every call and delays its response
+	Delay func() time.Duration
+}
+
+// Server serves the echo methods over MessagePack-RPC and net/rpc (gob)
+type Server struct {
+	cfg  Config
+	echo Echo
+	rpc  *rpc.Server
+
+	// ctx is canceled by Close to abort delayed responses
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu        sync.Mutex
+	listeners map[net.Listener]struct{}
+	conns     map[net.Conn]struct{}
+	closed    bool
+}
+
+// NewServer creates a server
+func NewServer(cfg Config) *Server {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Server{
+		cfg:       cfg,
+		rpc:       rpc.NewServer(),
+		ctx:       ctx,
+		cancel:    cancel,
+		listeners: make(map[net.Listener]struct{}),
+		conns:     make(map[net.Conn]struct{}),
+	}
+	if err := s.rpc.RegisterName("Echo", &GobService{server: s}); err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// ServeMsgpack serves MessagePack-RPC on the connections accepted on l
+// until Close is called
+func (s *Server) ServeMsgpack(l net.Listener) error {
+	return s.serve(l, s.serveMsgpack)
+}
+
+// ServeGob serves net/rpc (gob) on the connections accepted on l until
+// Close is called
+func (s *Server) ServeGob(l net.Listener) error {
+	return s.serve(l, func(conn net.Conn) {
+		s.rpc.ServeConn(conn)
+	})
+}
+
+// GobHandler serves net/rpc over HTTP (rpc.DialHTTP): a CONNECT request
+// switches the connection to gob
+func (s *Server) GobHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodConnect {
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			_, _ = io.WriteString(w, "405 must CONNECT\n")
+			return
+		}
+		conn, _, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			return
+		}
+		// Same status line as rpc.Server.ServeHTTP, expected by rpc.DialHTTP
+		if _, err := io.WriteString(conn, "HTTP/1.0 200 Connected to Go RPC\n\n"); err != nil {
+			_ = conn.Close()
+			return
+		}
+		s.handle(conn, func(conn net.Conn) {
+			s.rpc.ServeConn(conn)
+		})
+	})
+}
+
+// Close stops the listeners, closes the connections and aborts delayed
+// responses
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	s.cancel()
+
+	var errs []error
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+	for l := range s.listeners {
+		errs = append(errs, l.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// track registers a listener or connection unless the server is closed
+func (s *Server) track(add func()) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return false
+	}
+	add()
+	return true
+}
+
+func (s *Server) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+func (s *Server) serve(l net.Listener, serveConn func(net.Conn)) error {
+	if !s.track(func() { s.listeners[l] = struct{}{} }) {
+		return ErrServerClosed
+	}
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if s.isClosed() {
+				return ErrServerClosed
+			}
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				time.Sleep(10 * time.Millisecond)
+				continue
+			}
+			return err
+		}
+		go s.handle(conn, serveConn)
+	}
+}
+
+// handle serves a tracked connection and closes it
+func (s *Server) handle(conn net.Conn, serveConn func(net.Conn)) {
+	if !s.track(func() { s.conns[conn] = struct{}{} }) {
+		_ = conn.Close()
+		return
+	}
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		_ = conn.Close()
+	}()
+	serveConn(conn)
+}
+
+// delay waits for the configured delay of a call
+func (s *Server) delay(ctx context.Context) error {
+	if s.cfg.Delay == nil {
+		return nil
+	}
+	if d := s.cfg.Delay(); d > 0 {
+		return sleep(ctx, d)
+	}
+	return nil
+}
+
+// GobService exposes the echo methods to net/rpc as the "Echo" service
+// (Echo.Echo, Echo.EchoWithDelay, ...)
+type GobService struct {
+	server *Server
+}
+
+func (g *GobService) Echo(req *EchoRequest, resp *EchoResponse) error {
+	if err := g.server.delay(g.server.ctx); err != nil {
+		return err
+	}
+	*resp = *g.server.echo.Echo(req)
+	return nil
+}
+
+func (g *GobService) EchoWithDelay(req *EchoWithDelayRequest, resp *EchoResponse) error {
+	if err := g.server.delay(g.server.ctx); err != nil {
+		return err
+	}
+	r, err := g.server.echo.EchoWithDelay(g.server.ctx, req)
+	if err != nil {
+		return err
+	}
+	*resp = *r
+	return nil
+}
+
+func (g *GobService) EchoError(req *EchoErrorRequest, _ *EchoResponse) error {
+	if err := g.server.delay(g.server.ctx); err != nil {
+		return err
+	}
+	return g.server.echo.EchoError(req)
+}
+
+func (g *GobService) EchoLargePayload(req *EchoLargePayloadRequest, resp *EchoLargePayloadResponse) error {
+	if err := g.server.delay(g.server.ctx); err != nil {
+		return err
+	}
+	r, err := g.server.echo.EchoLargePayload(req)
+	if err != nil {
+		return err
+	}
+	*resp = *r
+	return nil
+}
@@ -0,0 +1,202 @@
+// Package app runs echo-nats: LoadConfig reads its settings and Run serves
+// it until its context ends, for the echo-nats binary and echo-all.
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+
+	"github.com/probitas-test/echo-servers/echo-nats/broker"
+	"github.com/probitas-test/echo-servers/echo-nats/docs"
+	"github.com/probitas-test/echo-servers/shared/admin"
+	"github.com/probitas-test/echo-servers/shared/lifecycle"
+	"github.com/probitas-test/echo-servers/shared/logging"
+	"github.com/probitas-test/echo-servers/shared/network"
+	"github.com/probitas-test/echo-servers/shared/tracing"
+	"github.com/probitas-test/echo-servers/shared/version"
+)
+
+// Run serves echo-nats with cfg until ctx is done, then shuts it down
+// gracefully. Logging and the address family are set up by the caller, as
+// they are shared by the servers of a process.
+func Run(ctx context.Context, cfg *Config) error {
+	// Started servers stop when Run returns early with an error
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Simulated slow startup: no port opens before the startup delay
+	if err := cfg.Lifecycle.Validate(); err != nil {
+		return fmt.Errorf("invalid lifecycle configuration: %w", err)
+	}
+	log.Printf("Lifecycle: %s", cfg.Lifecycle)
+	lifecycle.Delay(ctx, cfg.Lifecycle)
+
+	// OpenTelemetry tracing and W3C trace context propagation
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		return fmt.Errorf("failed to set up tracing: %w", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Error("Tracing shutdown error", "error", err)
+		}
+	}()
+	log.Printf("OpenTelemetry tracing: %s", cfg.Tracing)
+
+	if cfg.Port < 1 || cfg.Port > 65535 {
+		return fmt.Errorf("invalid PORT %d (must be 1-65535)", cfg.Port)
+	}
+	if !server.IsValidLiteralSubject(cfg.EchoSubject) {
+		return fmt.Errorf("invalid ECHO_SUBJECT %q (must be a subject without wildcards)", cfg.EchoSubject)
+	}
+	if cfg.LatencyMs < 0 || time.Duration(cfg.LatencyMs)*time.Millisecond > broker.MaxDelay {
+		return fmt.Errorf("invalid LATENCY_MS %d (must be 0-%d)", cfg.LatencyMs, broker.MaxDelay.Milliseconds())
+	}
+	if !server.IsValidLiteralSubject(cfg.StreamSubject) {
+		return fmt.Errorf("invalid STREAM_SUBJECT %q (must be a subject without wildcards)", cfg.StreamSubject)
+	}
+	if cfg.StreamMaxMsgs < 1 {
+		return fmt.Errorf("invalid STREAM_MAX_MSGS %d (must be >= 1)", cfg.StreamMaxMsgs)
+	}
+
+	// Admin API: health and reply delay
+	adm := admin.New("echo-nats", cfg.src)
+
+	// The embedded server opens its own listener, dual-stack on an
+	// unspecified HOST whatever IP_FAMILY; an address as HOST restricts it
+	// to one family
+	ns, err := broker.StartServer(broker.ServerConfig{
+		Host:      cfg.Host,
+		Port:      cfg.Port,
+		Username:  cfg.AuthUsername,
+		Password:  cfg.AuthPassword,
+		JetStream: cfg.JetStreamEnabled,
+		StoreDir:  cfg.JetStreamStoreDir,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start NATS server: %w", err)
+	}
+	defer ns.Shutdown()
+	nc, err := broker.Connect(ns, cfg.AuthUsername, cfg.AuthPassword)
+	if err != nil {
+		return fmt.Errorf("failed to connect to NATS server: %w", err)
+	}
+	defer nc.Close()
+	latency := time.Duration(cfg.LatencyMs) * time.Millisecond
+	responder, err := broker.NewResponder(nc, cfg.EchoSubject, func() time.Duration {
+		return latency + adm.Delay()
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start echo responder: %w", err)
+	}
+	if cfg.JetStreamEnabled {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		err := broker.CreateStreams(ctx, nc, broker.StreamConfig{
+			Subject:     cfg.StreamSubject,
+			EchoSubject: cfg.EchoSubject,
+			MaxMsgs:     int64(cfg.StreamMaxMsgs),
+		})
+		cancel()
+		if err != nil {
+			return fmt.Errorf("failed to create JetStream streams: %w", err)
+		}
+	}
+
+	mux := http.NewServeMux()
+
+	// Health check endpoint
+	mux.Handle("GET /health", adm.HealthHandler())
+
+	// Effective configuration (secrets redacted)
+	mux.Handle("GET /config", cfg.src)
+
+	// Address family of the client connection
+	mux.Handle("GET "+network.Path, network.Handler())
+
+	// Build information and enabled features
+	mux.Handle("GET "+version.Path, version.Handler(version.New("echo-nats", version.Features{
+		"admin":     cfg.Admin.Enabled,
+		"debug":     cfg.Admin.Debug,
+		"jetstream": cfg.JetStreamEnabled,
+		"tracing":   cfg.Tracing.Enabled,
+	})))
+
+	// API documentation endpoint
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		_, _ = w.Write([]byte(docs.API))
+	})
+
+	// Admin API on a separate port
+	var adminServer *http.Server
+	if cfg.Admin.Enabled {
+		if cfg.Admin.Debug {
+			adm.Debug()
+		}
+		adminServer, err = adm.Serve(cfg.AdminAddr())
+		if err != nil {
+			return err
+		}
+		defer func() { _ = adminServer.Close() }()
+	}
+
+	// Readiness delay and simulated crash, counted from here
+	lifecycle.Start(ctx, cfg.Lifecycle, adm)
+
+	srv := &http.Server{
+		Addr:              cfg.HTTPAddr(),
+		Handler:           tracing.HTTP(nil)(logging.HTTP(mux)),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	// Graceful shutdown (NATS clients are disconnected)
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		<-ctx.Done()
+
+		log.Println("Shutting down server...")
+		if err := responder.Close(); err != nil {
+			slog.Error("Echo responder shutdown error", "error", err)
+		}
+		nc.Close()
+		ns.Shutdown()
+		ns.WaitForShutdown()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := srv.Shutdown(ctx); err != nil {
+			slog.Error("Server shutdown error", "error", err)
+		}
+		if adminServer != nil {
+			_ = adminServer.Shutdown(ctx)
+		}
+	}()
+
+	log.Printf("Echoing requests on %q", cfg.EchoSubject)
+	if cfg.LatencyMs > 0 {
+		log.Printf("Delaying replies by %dms", cfg.LatencyMs)
+	}
+	if cfg.JetStreamEnabled {
+		log.Printf("Echoing stream %s (%s.>) to %s (%s.%s.>)",
+			broker.EchoStream, cfg.StreamSubject, broker.RepliesStream, cfg.EchoSubject, cfg.StreamSubject)
+	}
+	log.Printf("Starting NATS server on %s", cfg.Addr())
+	log.Printf("Starting HTTP server on %s", cfg.HTTPAddr())
+	if err := network.ListenAndServe(srv); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve: %w", err)
+	}
+	<-stopped
+
+	log.Println("Server stopped")
+	return nil
+}
@@ -0,0 +1,101 @@
+package app
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/probitas-test/echo-servers/shared/admin"
+	"github.com/probitas-test/echo-servers/shared/config"
+	"github.com/probitas-test/echo-servers/shared/lifecycle"
+	"github.com/probitas-test/echo-servers/shared/logging"
+	"github.com/probitas-test/echo-servers/shared/network"
+	"github.com/probitas-test/echo-servers/shared/tracing"
+)
+
+type Config struct {
+	Host string
+	Port int
+
+	// Port of the HTTP server for health checks and API documentation
+	HTTPPort string
+
+	// Subject answered by the echo responder
+	EchoSubject string
+
+	// Delay before answering each request, in milliseconds
+	LatencyMs int
+
+	// JetStream echo streams
+	JetStreamEnabled  bool
+	JetStreamStoreDir string
+	StreamSubject     string
+	StreamMaxMsgs     int
+
+	// Credentials required from clients (no authentication when unset)
+	AuthUsername string
+	AuthPassword string
+
+	// Admin API on a separate port (ADMIN_ENABLED, ADMIN_PORT)
+	Admin admin.Config
+
+	// Address family of every listener (IP_FAMILY: dual, ipv4 or ipv6)
+	Network network.Config
+
+	// Simulated startup delay, readiness delay and crash (STARTUP_DELAY_MS, STARTUP_READY_DELAY_MS, CRASH_AFTER_MS, CRASH_EXIT_CODE)
+	Lifecycle lifecycle.Config
+
+	// Structured logging (LOG_LEVEL, LOG_FORMAT)
+	Logging logging.Config
+
+	// OpenTelemetry tracing (OTEL_* variables)
+	Tracing tracing.Config
+
+	// Effective configuration, served at /config
+	src *config.Source
+}
+
+// LoadConfig reads the settings of echo-nats from src
+func LoadConfig(src *config.Source) (*Config, error) {
+	cfg := &Config{
+		Host:     src.String("HOST", "0.0.0.0"),
+		Port:     src.Int("PORT", 4222),
+		HTTPPort: src.String("HTTP_PORT", "8080"),
+
+		EchoSubject: src.String("ECHO_SUBJECT", "echo"),
+		LatencyMs:   src.Int("LATENCY_MS", 0),
+
+		JetStreamEnabled:  src.Bool("JETSTREAM_ENABLED", true),
+		JetStreamStoreDir: src.String("JETSTREAM_STORE_DIR", filepath.Join(os.TempDir(), "echo-nats")),
+		StreamSubject:     src.String("STREAM_SUBJECT", "stream"),
+		StreamMaxMsgs:     src.Int("STREAM_MAX_MSGS", 10000),
+
+		AuthUsername: src.String("AUTH_USERNAME", ""),
+		AuthPassword: src.Secret("AUTH_PASSWORD", ""),
+
+		Admin:     admin.LoadConfig(src),
+		Tracing:   tracing.LoadConfig(src, "echo-nats"),
+		Network:   network.LoadConfig(src),
+		Lifecycle: lifecycle.LoadConfig(src),
+		Logging:   logging.LoadConfig(src),
+	}
+	cfg.src = src
+	if err := src.Err(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	return cfg, nil
+}
+
+func (c *Config) Addr() string {
+	return net.JoinHostPort(c.Host, strconv.Itoa(c.Port))
+}
+
+func (c *Config) HTTPAddr() string {
+	return net.JoinHostPort(c.Host, c.HTTPPort)
+}
+
+func (c *Config) AdminAddr() string {
+	return net.JoinHostPort(c.Host, c.Admin.Port)
+}
@@ -0,0 +1,30 @@
+package broker
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// logger forwards the warnings and errors of the embedded server to the
+// default slog logger
+type logger struct{}
+
+func (logger) Noticef(format string, v ...any) {}
+
+func (logger) Warnf(format string, v ...any) {
+	slog.Warn(fmt.Sprintf(format, v...), "component", "nats-server")
+}
+
+func (logger) Errorf(format string, v ...any) {
+	slog.Error(fmt.Sprintf(format, v...), "component", "nats-server")
+}
+
+// Fatalf logs without exiting; the server shuts itself down and
+// StartServer reports that it did not become ready
+func (logger) Fatalf(format string, v ...any) {
+	slog.Error(fmt.Sprintf(format, v...), "component", "nats-server", "fatal", true)
+}
+
+func (logger) Debugf(format string, v ...any) {}
+
+func (logger) Tracef(format string, v ...any) {}
@@ -0,0 +1,109 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/probitas-test/echo-servers/shared/tracing"
+)
+
+const (
+	// DelayHeader overrides the latency of a single request, in
+	// milliseconds
+	DelayHeader = "Echo-Delay-Ms"
+
+	// MaxDelay is the longest delay accepted from DelayHeader
+	MaxDelay = time.Minute
+
+	// queueGroup spreads requests over the responders of all connections
+	queueGroup = "echo"
+)
+
+// Responder answers every request on a subject with its payload and
+// headers, after an optional delay. Each request is traced in a server span
+// parented to its traceparent header, named in the traceresponse header of
+// the reply.
+type Responder struct {
+	nc      *nats.Conn
+	sub     *nats.Subscription
+	latency func() time.Duration
+}
+
+// NewResponder subscribes to subject on nc. Replies are delayed by latency,
+// read on every request, unless a request sets DelayHeader.
+func NewResponder(nc *nats.Conn, subject string, latency func() time.Duration) (*Responder, error) {
+	r := &Responder{nc: nc, latency: latency}
+	sub, err := nc.QueueSubscribe(subject, queueGroup, func(msg *nats.Msg) {
+		// Requests are answered concurrently, so delays do not add up
+		go r.respond(msg)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("subscribe to %q: %w", subject, err)
+	}
+	r.sub = sub
+	return r, nil
+}
+
+// Close stops answering requests
+func (r *Responder) Close() error {
+	return r.sub.Unsubscribe()
+}
+
+func (r *Responder) respond(msg *nats.Msg) {
+	if msg.Reply == "" {
+		// Plain publishes have nobody to answer
+		return
+	}
+
+	ctx, span := tracing.Start(context.Background(), headerCarrier(msg.Header), "process "+msg.Subject, trace.SpanKindServer,
+		attribute.String("messaging.system", "nats"),
+		attribute.String("messaging.destination.name", msg.Subject),
+		attribute.Int("messaging.message.body.size", len(msg.Data)),
+	)
+	defer span.End()
+
+	delay := r.latency()
+	if value := msg.Header.Get(DelayHeader); value != "" {
+		ms, err := strconv.Atoi(value)
+		if err != nil || ms < 0 || time.Duration(ms)*time.Millisecond > MaxDelay {
+			reply := errorReply(msg, "400", fmt.Sprintf("invalid %s %q (must be 0-%d)", DelayHeader, value, MaxDelay.Milliseconds()))
+			tracing.SetError(span, fmt.Errorf("invalid %s %q", DelayHeader, value))
+			r.reply(ctx, msg, reply)
+			return
+		}
+		delay = time.Duration(ms) * time.Millisecond
+	}
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+
+	reply := nats.NewMsg(msg.Reply)
+	reply.Data = msg.Data
+	for name, values := range msg.Header {
+		reply.Header[name] = values
+	}
+	r.reply(ctx, msg, reply)
+}
+
+func (r *Responder) reply(ctx context.Context, msg, reply *nats.Msg) {
+	tracing.Echo(ctx, headerCarrier(msg.Header), headerCarrier(reply.Header))
+	if len(reply.Header) == 0 {
+		// Clients without header support can still read the reply
+		reply.Header = nil
+	}
+	_ = r.nc.PublishMsg(reply)
+}
+
+// errorReply answers msg with the error headers of the NATS services API
+func errorReply(msg *nats.Msg, code, description string) *nats.Msg {
+	reply := nats.NewMsg(msg.Reply)
+	reply.Header.Set("Nats-Service-Error-Code", code)
+	reply.Header.Set("Nats-Service-Error", description)
+	return reply
+}
@@ -0,0 +1,135 @@
+package broker
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/probitas-test/echo-servers/shared/tracing"
+)
+
+func startResponder(t *testing.T, latency time.Duration) *nats.Conn {
+	t.Helper()
+	ns := startServer(t, ServerConfig{})
+	nc, err := Connect(ns, "", "")
+	if err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	t.Cleanup(nc.Close)
+	r, err := NewResponder(nc, "echo", func() time.Duration { return latency })
+	if err != nil {
+		t.Fatalf("NewResponder: %v", err)
+	}
+	t.Cleanup(func() { _ = r.Close() })
+	return dial(t, ns)
+}
+
+func TestRespond(t *testing.T) {
+	nc := startResponder(t, 0)
+
+	msg := nats.NewMsg("echo")
+	msg.Data = []byte("hello")
+	msg.Header.Set("Trace", "abc")
+	reply, err := nc.RequestMsg(msg, time.Second)
+	if err != nil {
+		t.Fatalf("RequestMsg: %v", err)
+	}
+	if string(reply.Data) != "hello" || reply.Header.Get("Trace") != "abc" {
+		t.Errorf("reply = %q, headers %v", reply.Data, reply.Header)
+	}
+
+	reply, err = nc.Request("echo", []byte("plain"), time.Second)
+	if err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+	if string(reply.Data) != "plain" || reply.Header != nil {
+		t.Errorf("reply = %q, headers %v", reply.Data, reply.Header)
+	}
+}
+
+func TestRespondLatency(t *testing.T) {
+	nc := startResponder(t, 200*time.Millisecond)
+
+	start := time.Now()
+	if _, err := nc.Request("echo", nil, time.Second); err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("reply after %s, want at least 200ms", elapsed)
+	}
+
+	// The header overrides the configured latency
+	msg := nats.NewMsg("echo")
+	msg.Header.Set(DelayHeader, "0")
+	start = time.Now()
+	if _, err := nc.RequestMsg(msg, time.Second); err != nil {
+		t.Fatalf("RequestMsg: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= 200*time.Millisecond {
+		t.Errorf("reply after %s with %s 0", elapsed, DelayHeader)
+	}
+}
+
+func TestRespondConcurrently(t *testing.T) {
+	nc := startResponder(t, 0)
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Go(func() {
+			msg := nats.NewMsg("echo")
+			msg.Header.Set(DelayHeader, "300")
+			if _, err := nc.RequestMsg(msg, 2*time.Second); err != nil {
+				t.Errorf("RequestMsg: %v", err)
+			}
+		})
+	}
+	wg.Wait()
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("5 delayed requests took %s, want them answered concurrently", elapsed)
+	}
+}
+
+func TestRespondInvalidDelay(t *testing.T) {
+	nc := startResponder(t, 0)
+
+	for _, value := range []string{"x", "-1", "60001"} {
+		msg := nats.NewMsg("echo")
+		msg.Header.Set(DelayHeader, value)
+		reply, err := nc.RequestMsg(msg, time.Second)
+		if err != nil {
+			t.Fatalf("RequestMsg: %v", err)
+		}
+		if reply.Header.Get("Nats-Service-Error-Code") != "400" || reply.Header.Get("Nats-Service-Error") == "" {
+			t.Errorf("%s %q: reply headers %v", DelayHeader, value, reply.Header)
+		}
+	}
+}
+
+func TestRespondTraceContext(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	otel.SetTracerProvider(sdktrace.NewTracerProvider())
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+	nc := startResponder(t, 0)
+
+	traceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+	msg := nats.NewMsg("echo")
+	msg.Header.Set("Traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	reply, err := nc.RequestMsg(msg, time.Second)
+	if err != nil {
+		t.Fatalf("RequestMsg: %v", err)
+	}
+	if got := reply.Header.Get(tracing.TraceResponse); !strings.HasPrefix(got, "00-"+traceID+"-") {
+		t.Errorf("traceresponse = %q, want a span of trace %s", got, traceID)
+	}
+	if len(reply.Header) != 2 {
+		t.Errorf("reply headers %v, want the traceparent once and traceresponse", reply.Header)
+	}
+}
@@ -0,0 +1,60 @@
+package broker
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+)
+
+// startTimeout bounds the wait for the embedded server to accept clients
+const startTimeout = 10 * time.Second
+
+// ServerConfig configures the embedded NATS server
+type ServerConfig struct {
+	Host string
+	Port int
+
+	// Credentials required from clients (not checked when Username is empty)
+	Username string
+	Password string
+
+	// JetStream enables JetStream, keeping its state under StoreDir
+	JetStream bool
+	StoreDir  string
+}
+
+// StartServer starts an embedded NATS server and waits until it accepts
+// clients
+func StartServer(cfg ServerConfig) (*server.Server, error) {
+	ns, err := server.NewServer(&server.Options{
+		ServerName: "echo-nats",
+		Host:       cfg.Host,
+		Port:       cfg.Port,
+		Username:   cfg.Username,
+		Password:   cfg.Password,
+		JetStream:  cfg.JetStream,
+		StoreDir:   cfg.StoreDir,
+		NoSigs:     true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	ns.SetLogger(logger{}, false, false)
+	ns.Start()
+	if !ns.ReadyForConnections(startTimeout) {
+		ns.Shutdown()
+		return nil, fmt.Errorf("server not ready after %s", startTimeout)
+	}
+	return ns, nil
+}
+
+// Connect opens an in-process client connection to ns
+func Connect(ns *server.Server, username, password string) (*nats.Conn, error) {
+	opts := []nats.Option{nats.InProcessServer(ns), nats.Name("echo")}
+	if username != "" {
+		opts = append(opts, nats.UserInfo(username, password))
+	}
+	return nats.Connect("", opts...)
+}
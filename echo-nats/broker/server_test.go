@@ -0,0 +1,51 @@
+package broker
+
+import (
+	"testing"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+)
+
+// startServer starts a server on a random port with JetStream enabled
+func startServer(t *testing.T, cfg ServerConfig) *server.Server {
+	t.Helper()
+	cfg.Host = "127.0.0.1"
+	cfg.Port = server.RANDOM_PORT
+	cfg.JetStream = true
+	cfg.StoreDir = t.TempDir()
+	ns, err := StartServer(cfg)
+	if err != nil {
+		t.Fatalf("StartServer: %v", err)
+	}
+	t.Cleanup(func() {
+		ns.Shutdown()
+		ns.WaitForShutdown()
+	})
+	return ns
+}
+
+func dial(t *testing.T, ns *server.Server, opts ...nats.Option) *nats.Conn {
+	t.Helper()
+	nc, err := nats.Connect(ns.ClientURL(), opts...)
+	if err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	t.Cleanup(nc.Close)
+	return nc
+}
+
+func TestStartServerAuth(t *testing.T) {
+	ns := startServer(t, ServerConfig{Username: "user", Password: "secret"})
+	if nc, err := nats.Connect(ns.ClientURL()); err == nil {
+		nc.Close()
+		t.Fatal("Connect without credentials succeeded")
+	}
+	dial(t, ns, nats.UserInfo("user", "secret"))
+
+	nc, err := Connect(ns, "user", "secret")
+	if err != nil {
+		t.Fatalf("Connect in-process: %v", err)
+	}
+	nc.Close()
+}
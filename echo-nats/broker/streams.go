@@ -0,0 +1,66 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+const (
+	// EchoStream stores the messages published to the stream subjects
+	EchoStream = "ECHO"
+
+	// RepliesStream stores the echoes of the messages of EchoStream
+	RepliesStream = "ECHO_REPLIES"
+)
+
+// StreamConfig configures the JetStream echo streams
+type StreamConfig struct {
+	// Subject is the prefix of the subjects stored in EchoStream
+	// ("orders" stores "orders.>")
+	Subject string
+
+	// EchoSubject prefixes the subjects echoes are republished to and
+	// stored in RepliesStream ("echo" republishes "orders.new" to
+	// "echo.orders.new")
+	EchoSubject string
+
+	// MaxMsgs limits the messages kept by each stream (oldest dropped)
+	MaxMsgs int64
+}
+
+// CreateStreams creates or updates the in-memory echo streams. Every message stored in EchoStream is republished by
+// the server to the echo subject and stored again in RepliesStream.
+func CreateStreams(ctx context.Context, nc *nats.Conn, cfg StreamConfig) error {
+	js, err := jetstream.New(nc)
+	if err != nil {
+		return err
+	}
+
+	subjects := cfg.Subject + ".>"
+	echoes := cfg.EchoSubject + "." + subjects
+	for _, stream := range []jetstream.StreamConfig{
+		{
+			Name:        EchoStream,
+			Description: "Messages echoed to " + echoes,
+			Subjects:    []string{subjects},
+			Storage:     jetstream.MemoryStorage,
+			MaxMsgs:     cfg.MaxMsgs,
+			RePublish:   &jetstream.RePublish{Source: subjects, Destination: echoes},
+		},
+		{
+			Name:        RepliesStream,
+			Description: "Echoes of the messages of " + EchoStream,
+			Subjects:    []string{echoes},
+			Storage:     jetstream.MemoryStorage,
+			MaxMsgs:     cfg.MaxMsgs,
+		},
+	} {
+		if _, err := js.CreateOrUpdateStream(ctx, stream); err != nil {
+			return fmt.Errorf("create stream %s: %w", stream.Name, err)
+		}
+	}
+	return nil
+}
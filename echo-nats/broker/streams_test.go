@@ -0,0 +1,57 @@
+package broker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+func TestCreateStreams(t *testing.T) {
+	ns := startServer(t, ServerConfig{})
+	nc, err := Connect(ns, "", "")
+	if err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	t.Cleanup(nc.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	cfg := StreamConfig{Subject: "stream", EchoSubject: "echo", MaxMsgs: 10}
+	if err := CreateStreams(ctx, nc, cfg); err != nil {
+		t.Fatalf("CreateStreams: %v", err)
+	}
+	// Creating the streams again updates them
+	if err := CreateStreams(ctx, nc, cfg); err != nil {
+		t.Fatalf("CreateStreams again: %v", err)
+	}
+
+	js, err := jetstream.New(dial(t, ns))
+	if err != nil {
+		t.Fatalf("jetstream.New: %v", err)
+	}
+	echoes, err := js.CreateOrUpdateConsumer(ctx, RepliesStream, jetstream.ConsumerConfig{})
+	if err != nil {
+		t.Fatalf("CreateOrUpdateConsumer: %v", err)
+	}
+
+	ack, err := js.Publish(ctx, "stream.orders", []byte("hello"))
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if ack.Stream != EchoStream || ack.Sequence != 1 {
+		t.Errorf("PubAck = %+v", ack)
+	}
+
+	msg, err := echoes.Next(jetstream.FetchMaxWait(2 * time.Second))
+	if err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	if msg.Subject() != "echo.stream.orders" || string(msg.Data()) != "hello" {
+		t.Errorf("echo = %q on %s", msg.Data(), msg.Subject())
+	}
+	if got := msg.Headers().Get("Nats-Subject"); got != "stream.orders" {
+		t.Errorf("Nats-Subject = %q", got)
+	}
+}
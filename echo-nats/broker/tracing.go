@@ -0,0 +1,41 @@
+package broker
+
+import (
+	"strings"
+
+	"github.com/nats-io/nats.go"
+)
+
+// headerCarrier adapts NATS headers, which are case-sensitive, to the
+// OpenTelemetry propagators. Lookups ignore case, so both traceparent and
+// Traceparent are extracted.
+type headerCarrier nats.Header
+
+func (c headerCarrier) Get(key string) string {
+	if v := nats.Header(c).Get(key); v != "" {
+		return v
+	}
+	for name, values := range c {
+		if strings.EqualFold(name, key) && len(values) > 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
+
+func (c headerCarrier) Set(key, value string) {
+	for name := range c {
+		if strings.EqualFold(name, key) {
+			delete(c, name)
+		}
+	}
+	nats.Header(c).Set(key, value)
+}
+
+func (c headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for name := range c {
+		keys = append(keys, name)
+	}
+	return keys
+}
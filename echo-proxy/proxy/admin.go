@@ -0,0 +1,77 @@
+package proxy
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+// RegisterHandlers adds the admin API to mux:
+//
+//	GET    /rules          list the rules
+//	POST   /rules          add a rule
+//	DELETE /rules          remove all rules
+//	DELETE /rules/{id}     remove a rule
+//	GET    /requests       requests handled by the proxy (?since=id)
+//	DELETE /requests       clear the request log
+func RegisterHandlers(mux *http.ServeMux, rules *Rules, log *Log) {
+	mux.HandleFunc("GET /rules", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{"rules": rules.List()})
+	})
+
+	mux.HandleFunc("POST /rules", func(w http.ResponseWriter, r *http.Request) {
+		var rule Rule
+		dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&rule); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid rule: "+err.Error())
+			return
+		}
+		added, err := rules.Add(rule)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusCreated, added)
+	})
+
+	mux.HandleFunc("DELETE /rules", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]int{"deleted": rules.Clear()})
+	})
+
+	mux.HandleFunc("DELETE /rules/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil || !rules.Delete(id) {
+			writeError(w, http.StatusNotFound, "rule not found")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	mux.HandleFunc("GET /requests", func(w http.ResponseWriter, r *http.Request) {
+		var since int64
+		if v := r.URL.Query().Get("since"); v != "" {
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || n < 0 {
+				writeError(w, http.StatusBadRequest, "invalid since (must be a request ID)")
+				return
+			}
+			since = n
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"requests": log.Since(since)})
+	})
+
+	mux.HandleFunc("DELETE /requests", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]int{"deleted": log.Clear()})
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,70 @@
+package proxy
+
+import (
+	"sync"
+	"time"
+)
+
+// Entry records a request handled by the proxy
+type Entry struct {
+	ID         int64     `json:"id"`
+	Time       time.Time `json:"time"`
+	Type       string    `json:"type"`
+	Method     string    `json:"method"`
+	URL        string    `json:"url"`
+	Host       string    `json:"host"`
+	RemoteAddr string    `json:"remoteAddr"`
+	Username   string    `json:"username,omitempty"`
+	Status     int       `json:"status"`
+	RuleID     int       `json:"ruleId,omitempty"`
+	DurationMs int64     `json:"durationMs"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Log keeps the latest entries in memory
+type Log struct {
+	mu      sync.Mutex
+	max     int
+	nextID  int64
+	entries []Entry
+}
+
+// NewLog creates a log that keeps up to max entries, dropping the oldest
+func NewLog(max int) *Log {
+	return &Log{max: max}
+}
+
+// Add stores an entry, assigning its ID
+func (l *Log) Add(e Entry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.nextID++
+	e.ID = l.nextID
+	if len(l.entries) >= l.max {
+		n := copy(l.entries, l.entries[len(l.entries)-l.max+1:])
+		l.entries = l.entries[:n]
+	}
+	l.entries = append(l.entries, e)
+}
+
+// Since returns the entries with an ID greater than id, oldest first
+func (l *Log) Since(id int64) []Entry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entries := []Entry{}
+	for _, e := range l.entries {
+		if e.ID > id {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// Clear removes all entries and returns how many there were
+func (l *Log) Clear() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	n := len(l.entries)
+	l.entries = nil
+	return n
+}
@@ -0,0 +1,315 @@
+package proxy
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/probitas-test/echo-servers/shared/tracing"
+)
+
+// via identifies the proxy in the Via header
+const via = "1.1 echo-proxy"
+
+// dialTimeout bounds connections to targets of CONNECT tunnels
+const dialTimeout = 10 * time.Second
+
+// Config controls the proxy
+type Config struct {
+	// Upstream is the target of reverse proxied (origin-form) requests;
+	// nil rejects them
+	Upstream *url.URL
+
+	// Username and Password require Basic proxy authentication for forward
+	// and CONNECT requests when Username is set
+	Username string
+	Password string
+}
+
+// Proxy is a forward proxy (absolute-form requests and CONNECT tunnels) and
+// a reverse proxy (origin-form requests) whose behavior is scripted by rules
+type Proxy struct {
+	cfg   Config
+	rules *Rules
+	log   *Log
+	rp    *httputil.ReverseProxy
+
+	mu      sync.Mutex
+	tunnels map[net.Conn]struct{}
+	closed  bool
+}
+
+// ruleKey carries the applied rule in the request context
+type ruleKey struct{}
+
+// New creates a proxy applying rules and recording requests in log
+func New(cfg Config, rules *Rules, log *Log) *Proxy {
+	p := &Proxy{cfg: cfg, rules: rules, log: log, tunnels: make(map[net.Conn]struct{})}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// The proxy never chains to the proxy of its own environment
+	transport.Proxy = nil
+	p.rp = &httputil.ReverseProxy{
+		Rewrite:        p.rewrite,
+		Transport:      transport,
+		ModifyResponse: p.modifyResponse,
+		ErrorHandler:   p.errorHandler,
+	}
+	return p
+}
+
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	typ, host := TypeReverse, r.Host
+	switch {
+	case r.Method == http.MethodConnect:
+		typ = TypeConnect
+	case r.URL.IsAbs():
+		typ, host = TypeForward, r.URL.Host
+	}
+
+	entry := Entry{
+		Time:       start.UTC(),
+		Type:       typ,
+		Method:     r.Method,
+		URL:        r.URL.String(),
+		Host:       host,
+		RemoteAddr: r.RemoteAddr,
+	}
+	if typ == TypeConnect {
+		entry.URL = r.Host
+	}
+	// Proxied requests carry the context of this span to the target
+	ctx, span := tracing.Start(r.Context(), propagation.HeaderCarrier(r.Header), r.Method, trace.SpanKindServer,
+		attribute.String("http.request.method", r.Method),
+		attribute.String("server.address", host),
+		attribute.String("proxy.type", typ),
+	)
+	r = r.WithContext(ctx)
+
+	rec := &statusRecorder{ResponseWriter: w}
+	defer func() {
+		if entry.Status == 0 {
+			entry.Status = rec.status
+		}
+		entry.DurationMs = time.Since(start).Milliseconds()
+		p.log.Add(entry)
+
+		span.SetAttributes(attribute.Int("http.response.status_code", entry.Status))
+		if entry.Error != "" {
+			span.SetStatus(codes.Error, entry.Error)
+		} else if entry.Status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(entry.Status))
+		}
+		span.End()
+	}()
+
+	if typ != TypeReverse && p.cfg.Username != "" {
+		username, ok := p.authenticate(r)
+		if !ok {
+			rec.Header().Set("Proxy-Authenticate", `Basic realm="echo-proxy"`)
+			http.Error(rec, "Proxy authentication required", http.StatusProxyAuthRequired)
+			return
+		}
+		entry.Username = username
+	}
+	if typ == TypeReverse && p.cfg.Upstream == nil {
+		http.Error(rec, "Not a proxy request (set UPSTREAM_URL to reverse proxy origin-form requests)", http.StatusBadRequest)
+		return
+	}
+
+	rule := p.rules.Apply(typ, r.Method, host, r.URL.Path)
+	if rule != nil {
+		entry.RuleID = rule.ID
+		if rule.LatencyMs > 0 {
+			select {
+			case <-time.After(time.Duration(rule.LatencyMs) * time.Millisecond):
+			case <-r.Context().Done():
+				entry.Error = "client canceled during latency"
+				return
+			}
+		}
+		if rule.Status != 0 {
+			// Scripted responses answer for the target
+			tracing.Echo(ctx, propagation.HeaderCarrier(r.Header), propagation.HeaderCarrier(rec.Header()))
+			rule.ResponseHeaders.apply(rec.Header())
+			rec.Header().Set("Via", via)
+			if rec.Header().Get("Content-Type") == "" {
+				rec.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			}
+			rec.WriteHeader(rule.Status)
+			_, _ = io.WriteString(rec, rule.Body)
+			return
+		}
+	}
+
+	if typ == TypeConnect {
+		entry.Status, entry.Error = p.tunnel(w, r)
+		return
+	}
+	p.rp.ServeHTTP(rec, r.WithContext(context.WithValue(r.Context(), ruleKey{}, rule)))
+}
+
+// authenticate checks the Basic Proxy-Authorization credentials
+func (p *Proxy) authenticate(r *http.Request) (string, bool) {
+	req := &http.Request{Header: http.Header{"Authorization": r.Header.Values("Proxy-Authorization")}}
+	username, password, ok := req.BasicAuth()
+	if !ok {
+		return "", false
+	}
+	userOK := subtle.ConstantTimeCompare([]byte(username), []byte(p.cfg.Username)) == 1
+	passOK := subtle.ConstantTimeCompare([]byte(password), []byte(p.cfg.Password)) == 1
+	return username, userOK && passOK
+}
+
+func (p *Proxy) rewrite(pr *httputil.ProxyRequest) {
+	if pr.In.URL.IsAbs() {
+		// Forward proxy: the request already names its target
+		u := *pr.In.URL
+		pr.Out.URL = &u
+		pr.Out.Host = ""
+	} else {
+		pr.SetURL(p.cfg.Upstream)
+		pr.SetXForwarded()
+	}
+	pr.Out.Header.Add("Via", via)
+	tracing.Inject(pr.In.Context(), propagation.HeaderCarrier(pr.Out.Header))
+	if rule, _ := pr.In.Context().Value(ruleKey{}).(*Rule); rule != nil {
+		rule.RequestHeaders.apply(pr.Out.Header)
+	}
+}
+
+func (p *Proxy) modifyResponse(resp *http.Response) error {
+	resp.Header.Add("Via", via)
+	if rule, _ := resp.Request.Context().Value(ruleKey{}).(*Rule); rule != nil {
+		rule.ResponseHeaders.apply(resp.Header)
+	}
+	return nil
+}
+
+func (p *Proxy) errorHandler(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+	slog.ErrorContext(r.Context(), "Proxy error", "url", r.URL.String(), "error", err)
+	w.Header().Set("Via", via)
+	http.Error(w, fmt.Sprintf("Bad gateway: %v", err), http.StatusBadGateway)
+}
+
+// tunnel answers CONNECT by relaying bytes between the client and the
+// target, and returns the status and error to log
+func (p *Proxy) tunnel(w http.ResponseWriter, r *http.Request) (int, string) {
+	target, err := net.DialTimeout("tcp", r.Host, dialTimeout)
+	if err != nil {
+		w.Header().Set("Via", via)
+		http.Error(w, fmt.Sprintf("Bad gateway: %v", err), http.StatusBadGateway)
+		return http.StatusBadGateway, err.Error()
+	}
+	client, buf, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		_ = target.Close()
+		http.Error(w, "CONNECT not supported", http.StatusInternalServerError)
+		return http.StatusInternalServerError, err.Error()
+	}
+	if !p.track(client, target) {
+		_ = client.Close()
+		_ = target.Close()
+		return http.StatusServiceUnavailable, "proxy closed"
+	}
+
+	_, _ = io.WriteString(client, "HTTP/1.1 200 Connection established\r\nVia: "+via+"\r\n\r\n")
+	go func() {
+		defer p.untrack(client, target)
+		done := make(chan struct{}, 2)
+		go func() {
+			// Bytes the client sent after the CONNECT request are buffered
+			_, _ = io.Copy(target, buf.Reader)
+			closeWrite(target)
+			done <- struct{}{}
+		}()
+		go func() {
+			_, _ = io.Copy(client, target)
+			closeWrite(client)
+			done <- struct{}{}
+		}()
+		<-done
+		<-done
+	}()
+	return http.StatusOK, ""
+}
+
+func closeWrite(conn net.Conn) {
+	if cw, ok := conn.(interface{ CloseWrite() error }); ok {
+		_ = cw.CloseWrite()
+	}
+}
+
+func (p *Proxy) track(conns ...net.Conn) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return false
+	}
+	for _, c := range conns {
+		p.tunnels[c] = struct{}{}
+	}
+	return true
+}
+
+func (p *Proxy) untrack(conns ...net.Conn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, c := range conns {
+		_ = c.Close()
+		delete(p.tunnels, c)
+	}
+}
+
+// Close closes the open CONNECT tunnels, which http.Server.Shutdown does
+// not track once hijacked
+func (p *Proxy) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	for c := range p.tunnels {
+		_ = c.Close()
+	}
+}
+
+// statusRecorder remembers the response status for the log
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 && status >= 200 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
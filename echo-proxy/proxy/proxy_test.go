@@ -0,0 +1,354 @@
+package proxy
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// echoUpstream answers with the request method, path and headers
+func echoUpstream(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Upstream", "yes")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"method":  r.Method,
+			"path":    r.URL.Path,
+			"host":    r.Host,
+			"headers": r.Header,
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+type echoed struct {
+	Method  string
+	Path    string
+	Host    string
+	Headers http.Header
+}
+
+// startProxy serves a proxy and returns it with its admin API
+func startProxy(t *testing.T, cfg Config) (*httptest.Server, *Rules, *Log, *httptest.Server) {
+	t.Helper()
+	rules, log := NewRules(), NewLog(100)
+	p := New(cfg, rules, log)
+	srv := httptest.NewServer(p)
+	mux := http.NewServeMux()
+	RegisterHandlers(mux, rules, log)
+	admin := httptest.NewServer(mux)
+	t.Cleanup(func() {
+		p.Close()
+		srv.Close()
+		admin.Close()
+	})
+	return srv, rules, log, admin
+}
+
+// waitEntries waits until the log holds n entries; entries are added once
+// the handler returns, which may be after the client read the response
+func waitEntries(t *testing.T, log *Log, n int) []Entry {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		entries := log.Since(0)
+		if len(entries) >= n || time.Now().After(deadline) {
+			return entries
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// proxyClient sends requests through the proxy
+func proxyClient(proxyURL string) *http.Client {
+	u, _ := url.Parse(proxyURL)
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyURL(u),
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+		Timeout: 10 * time.Second,
+	}
+}
+
+func TestForwardProxy(t *testing.T) {
+	upstream := echoUpstream(t)
+	srv, _, log, _ := startProxy(t, Config{})
+
+	resp, err := proxyClient(srv.URL).Get(upstream.URL + "/hello")
+	if err != nil {
+		t.Fatalf("GET through proxy: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	var got echoed
+	_ = json.NewDecoder(resp.Body).Decode(&got)
+
+	if got.Path != "/hello" || got.Headers.Get("Via") != via {
+		t.Errorf("upstream saw %+v, want path /hello with Via", got)
+	}
+	if resp.Header.Get("Via") != via || resp.Header.Get("X-Upstream") != "yes" {
+		t.Errorf("response headers = %v", resp.Header)
+	}
+
+	entries := waitEntries(t, log, 1)
+	if len(entries) != 1 || entries[0].Type != TypeForward || entries[0].Status != 200 || entries[0].URL != upstream.URL+"/hello" {
+		t.Errorf("log = %+v", entries)
+	}
+}
+
+func TestConnectTunnel(t *testing.T) {
+	upstream := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "secure hello")
+	}))
+	defer upstream.Close()
+	srv, _, log, _ := startProxy(t, Config{})
+
+	resp, err := proxyClient(srv.URL).Get(upstream.URL)
+	if err != nil {
+		t.Fatalf("GET through tunnel: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if string(body) != "secure hello" {
+		t.Errorf("body = %q", body)
+	}
+
+	entries := waitEntries(t, log, 1)
+	if len(entries) != 1 || entries[0].Type != TypeConnect || entries[0].Status != 200 {
+		t.Errorf("log = %+v", entries)
+	}
+}
+
+func TestProxyAuth(t *testing.T) {
+	upstream := echoUpstream(t)
+	srv, _, log, _ := startProxy(t, Config{Username: "user", Password: "pass"})
+
+	resp, err := proxyClient(srv.URL).Get(upstream.URL)
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusProxyAuthRequired || resp.Header.Get("Proxy-Authenticate") == "" {
+		t.Errorf("without credentials: %d %v, want 407 with Proxy-Authenticate", resp.StatusCode, resp.Header)
+	}
+
+	authURL := strings.Replace(srv.URL, "http://", "http://user:pass@", 1)
+	resp, err = proxyClient(authURL).Get(upstream.URL)
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	var got echoed
+	_ = json.NewDecoder(resp.Body).Decode(&got)
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("with credentials: %d, want 200", resp.StatusCode)
+	}
+	if got.Headers.Get("Proxy-Authorization") != "" {
+		t.Error("Proxy-Authorization was forwarded to the upstream")
+	}
+	if entries := waitEntries(t, log, 2); entries[1].Username != "user" {
+		t.Errorf("log = %+v, want username", entries)
+	}
+
+	// CONNECT is authenticated too
+	tlsUpstream := httptest.NewTLSServer(http.NotFoundHandler())
+	defer tlsUpstream.Close()
+	if _, err := proxyClient(srv.URL).Get(tlsUpstream.URL); err == nil {
+		t.Error("CONNECT without credentials succeeded")
+	}
+}
+
+func TestReverseProxy(t *testing.T) {
+	upstream := echoUpstream(t)
+	target, _ := url.Parse(upstream.URL)
+
+	srv, _, _, _ := startProxy(t, Config{})
+	resp, err := http.Get(srv.URL + "/path")
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("without upstream: %d, want 400", resp.StatusCode)
+	}
+
+	srv, _, log, _ := startProxy(t, Config{Upstream: target})
+	resp, err = http.Get(srv.URL + "/path")
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	var got echoed
+	_ = json.NewDecoder(resp.Body).Decode(&got)
+	_ = resp.Body.Close()
+	if got.Path != "/path" || got.Host != target.Host || got.Headers.Get("X-Forwarded-For") == "" {
+		t.Errorf("upstream saw %+v", got)
+	}
+	if entries := waitEntries(t, log, 1); len(entries) != 1 || entries[0].Type != TypeReverse {
+		t.Errorf("log = %+v", entries)
+	}
+}
+
+func TestScriptedRules(t *testing.T) {
+	upstream := echoUpstream(t)
+	srv, _, log, admin := startProxy(t, Config{})
+	client := proxyClient(srv.URL)
+
+	addRule := func(body string) {
+		t.Helper()
+		resp, err := http.Post(admin.URL+"/rules", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("POST /rules: %v", err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("POST /rules = %d", resp.StatusCode)
+		}
+	}
+
+	// A one-shot 502, then header rewrites with latency
+	addRule(`{"match":{"type":"forward","pathPrefix":"/flaky"},"status":502,"body":"upstream down","times":1}`)
+	addRule(`{"match":{"pathPrefix":"/flaky"},"latencyMs":100,
+		"requestHeaders":{"set":{"X-Injected":"1"},"remove":["User-Agent"]},
+		"responseHeaders":{"set":{"X-Rewritten":"1"},"remove":["X-Upstream"]}}`)
+
+	resp, err := client.Get(upstream.URL + "/flaky")
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusBadGateway || string(body) != "upstream down" {
+		t.Errorf("first request = %d %q, want scripted 502", resp.StatusCode, body)
+	}
+
+	start := time.Now()
+	resp, err = client.Get(upstream.URL + "/flaky")
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	var got echoed
+	_ = json.NewDecoder(resp.Body).Decode(&got)
+	_ = resp.Body.Close()
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("second request took %v, want >= 100ms latency", elapsed)
+	}
+	if got.Headers.Get("X-Injected") != "1" || got.Headers.Get("User-Agent") != "" {
+		t.Errorf("upstream headers = %v, want request rewrites", got.Headers)
+	}
+	if resp.Header.Get("X-Rewritten") != "1" || resp.Header.Get("X-Upstream") != "" {
+		t.Errorf("response headers = %v, want response rewrites", resp.Header)
+	}
+
+	entries := waitEntries(t, log, 2)
+	if len(entries) != 2 || entries[0].RuleID != 1 || entries[0].Status != 502 || entries[1].RuleID != 2 {
+		t.Errorf("log = %+v", entries)
+	}
+
+	// The one-shot rule is gone
+	resp, err = http.Get(admin.URL + "/rules")
+	if err != nil {
+		t.Fatalf("GET /rules: %v", err)
+	}
+	var list struct{ Rules []Rule }
+	_ = json.NewDecoder(resp.Body).Decode(&list)
+	_ = resp.Body.Close()
+	if len(list.Rules) != 1 || list.Rules[0].ID != 2 {
+		t.Errorf("rules = %+v, want only rule 2", list.Rules)
+	}
+}
+
+func TestConnectRefusedByRule(t *testing.T) {
+	upstream := httptest.NewTLSServer(http.NotFoundHandler())
+	defer upstream.Close()
+	srv, rules, log, _ := startProxy(t, Config{})
+	if _, err := rules.Add(Rule{Match: Match{Type: TypeConnect}, Status: http.StatusForbidden}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	if _, err := proxyClient(srv.URL).Get(upstream.URL); err == nil {
+		t.Error("CONNECT refused by a rule succeeded")
+	}
+	if entries := waitEntries(t, log, 1); len(entries) != 1 || entries[0].Status != http.StatusForbidden {
+		t.Errorf("log = %+v", entries)
+	}
+}
+
+func TestBadGateway(t *testing.T) {
+	srv, _, log, _ := startProxy(t, Config{})
+	resp, err := proxyClient(srv.URL).Get("http://127.0.0.1:1/unreachable")
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusBadGateway {
+		t.Errorf("status = %d, want 502", resp.StatusCode)
+	}
+	if entries := waitEntries(t, log, 1); len(entries) != 1 || entries[0].Status != http.StatusBadGateway {
+		t.Errorf("log = %+v", entries)
+	}
+}
+
+func TestAdminValidation(t *testing.T) {
+	_, _, _, admin := startProxy(t, Config{})
+	for _, body := range []string{`{"status":42}`, `{"unknown":true}`, `not json`} {
+		resp, err := http.Post(admin.URL+"/rules", "application/json", bytes.NewBufferString(body))
+		if err != nil {
+			t.Fatalf("POST /rules: %v", err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("POST /rules %s = %d, want 400", body, resp.StatusCode)
+		}
+	}
+
+	req, _ := http.NewRequest(http.MethodDelete, admin.URL+"/rules/99", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("DELETE: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("DELETE /rules/99 = %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestTraceContextPropagation(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	otel.SetTracerProvider(sdktrace.NewTracerProvider())
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+
+	upstream := echoUpstream(t)
+	target, _ := url.Parse(upstream.URL)
+	srv, _, _, _ := startProxy(t, Config{Upstream: target})
+
+	traceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+	traceparent := "00-" + traceID + "-00f067aa0ba902b7-01"
+	req, _ := http.NewRequest("GET", srv.URL+"/path", nil)
+	req.Header.Set("traceparent", traceparent)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	var got echoed
+	_ = json.NewDecoder(resp.Body).Decode(&got)
+	_ = resp.Body.Close()
+
+	// The target is a child of the proxy span, in the caller's trace
+	upstreamParent := got.Headers.Get("Traceparent")
+	if !strings.HasPrefix(upstreamParent, "00-"+traceID+"-") || upstreamParent == traceparent {
+		t.Errorf("upstream traceparent = %q, want the proxy span of trace %s", upstreamParent, traceID)
+	}
+}
@@ -0,0 +1,210 @@
+package proxy
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Request types
+const (
+	TypeForward = "forward"
+	TypeConnect = "connect"
+	TypeReverse = "reverse"
+)
+
+// MaxLatency bounds the latency of a rule
+const MaxLatency = 60 * time.Second
+
+// Match selects the requests a rule applies to; empty fields match
+// everything
+type Match struct {
+	// Type is forward, connect or reverse
+	Type string `json:"type,omitempty"`
+
+	// Method matches the request method (case-insensitive)
+	Method string `json:"method,omitempty"`
+
+	// Host matches the target host without port: "example.com" exactly,
+	// "*.example.com" any subdomain
+	Host string `json:"host,omitempty"`
+
+	// PathPrefix matches the start of the request path (not CONNECT)
+	PathPrefix string `json:"pathPrefix,omitempty"`
+}
+
+// HeaderRewrite sets and removes headers
+type HeaderRewrite struct {
+	Set    map[string]string `json:"set,omitempty"`
+	Remove []string          `json:"remove,omitempty"`
+}
+
+func (hr *HeaderRewrite) apply(h http.Header) {
+	if hr == nil {
+		return
+	}
+	for _, name := range hr.Remove {
+		h.Del(name)
+	}
+	for name, value := range hr.Set {
+		h.Set(name, value)
+	}
+}
+
+// Rule scripts the proxy behavior for matching requests
+type Rule struct {
+	ID    int   `json:"id"`
+	Match Match `json:"match"`
+
+	// LatencyMs delays the request before it is forwarded or answered
+	LatencyMs int `json:"latencyMs,omitempty"`
+
+	// Status answers the request directly with this status and Body
+	// instead of forwarding it (502 to simulate an unreachable upstream;
+	// for CONNECT, any status refuses the tunnel)
+	Status int    `json:"status,omitempty"`
+	Body   string `json:"body,omitempty"`
+
+	// RequestHeaders rewrites the forwarded request, ResponseHeaders the
+	// response (including responses built from Status)
+	RequestHeaders  *HeaderRewrite `json:"requestHeaders,omitempty"`
+	ResponseHeaders *HeaderRewrite `json:"responseHeaders,omitempty"`
+
+	// Times is how many requests the rule applies to before it is removed
+	// (0 = unlimited); Remaining counts down
+	Times     int `json:"times,omitempty"`
+	Remaining int `json:"remaining,omitempty"`
+}
+
+// Validate checks the rule fields
+func (r *Rule) Validate() error {
+	switch r.Match.Type {
+	case "", TypeForward, TypeConnect, TypeReverse:
+	default:
+		return fmt.Errorf("invalid match.type %q (must be forward, connect or reverse)", r.Match.Type)
+	}
+	if r.LatencyMs < 0 || time.Duration(r.LatencyMs)*time.Millisecond > MaxLatency {
+		return fmt.Errorf("invalid latencyMs %d (must be 0-%d)", r.LatencyMs, MaxLatency.Milliseconds())
+	}
+	if r.Status != 0 && (r.Status < 200 || r.Status > 599) {
+		return fmt.Errorf("invalid status %d (must be 200-599)", r.Status)
+	}
+	if r.Times < 0 {
+		return errors.New("invalid times (must be >= 0)")
+	}
+	return nil
+}
+
+func (r *Rule) matches(typ, method, host, path string) bool {
+	m := &r.Match
+	if m.Type != "" && m.Type != typ {
+		return false
+	}
+	if m.Method != "" && !strings.EqualFold(m.Method, method) {
+		return false
+	}
+	if m.Host != "" && !matchHost(m.Host, host) {
+		return false
+	}
+	if m.PathPrefix != "" && (typ == TypeConnect || !strings.HasPrefix(path, m.PathPrefix)) {
+		return false
+	}
+	return true
+}
+
+// matchHost matches a host (with or without port) against a pattern
+func matchHost(pattern, host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(strings.Trim(host, "[]"))
+	pattern = strings.ToLower(pattern)
+	if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+		return strings.HasSuffix(host, "."+suffix)
+	}
+	return host == pattern
+}
+
+// Rules is the ordered list of rules; the first matching rule applies
+type Rules struct {
+	mu     sync.Mutex
+	nextID int
+	rules  []*Rule
+}
+
+// NewRules creates an empty rule list
+func NewRules() *Rules {
+	return &Rules{}
+}
+
+// Add validates and appends a rule, assigning its ID
+func (rs *Rules) Add(r Rule) (Rule, error) {
+	if err := r.Validate(); err != nil {
+		return Rule{}, err
+	}
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	rs.nextID++
+	r.ID = rs.nextID
+	r.Remaining = r.Times
+	rs.rules = append(rs.rules, &r)
+	return r, nil
+}
+
+// List returns a copy of the rules
+func (rs *Rules) List() []Rule {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	list := make([]Rule, 0, len(rs.rules))
+	for _, r := range rs.rules {
+		list = append(list, *r)
+	}
+	return list
+}
+
+// Delete removes a rule and reports whether it existed
+func (rs *Rules) Delete(id int) bool {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	for i, r := range rs.rules {
+		if r.ID == id {
+			rs.rules = append(rs.rules[:i], rs.rules[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Clear removes all rules and returns how many there were
+func (rs *Rules) Clear() int {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	n := len(rs.rules)
+	rs.rules = nil
+	return n
+}
+
+// Apply returns a copy of the first rule matching the request, counting it
+// against the rule's Times
+func (rs *Rules) Apply(typ, method, host, path string) *Rule {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	for i, r := range rs.rules {
+		if !r.matches(typ, method, host, path) {
+			continue
+		}
+		if r.Times > 0 {
+			r.Remaining--
+			if r.Remaining == 0 {
+				rs.rules = append(rs.rules[:i], rs.rules[i+1:]...)
+			}
+		}
+		applied := *r
+		return &applied
+	}
+	return nil
+}
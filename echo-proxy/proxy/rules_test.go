@@ -0,0 +1,71 @@
+package proxy
+
+import "testing"
+
+func TestRuleMatches(t *testing.T) {
+	tests := []struct {
+		match Match
+		typ   string
+		host  string
+		path  string
+		want  bool
+	}{
+		{Match{}, TypeForward, "example.com", "/", true},
+		{Match{Type: TypeConnect}, TypeForward, "example.com", "/", false},
+		{Match{Host: "example.com"}, TypeConnect, "example.com:443", "", true},
+		{Match{Host: "Example.COM"}, TypeForward, "example.com", "/", true},
+		{Match{Host: "*.example.com"}, TypeForward, "api.example.com:8080", "/", true},
+		{Match{Host: "*.example.com"}, TypeForward, "example.com", "/", false},
+		{Match{Host: "::1"}, TypeForward, "[::1]:80", "/", true},
+		{Match{PathPrefix: "/api"}, TypeReverse, "upstream", "/api/users", true},
+		{Match{PathPrefix: "/api"}, TypeReverse, "upstream", "/other", false},
+		{Match{PathPrefix: "/api"}, TypeConnect, "example.com:443", "", false},
+		{Match{Method: "post"}, TypeForward, "example.com", "/", false},
+	}
+	for _, tt := range tests {
+		r := Rule{Match: tt.match}
+		if got := r.matches(tt.typ, "GET", tt.host, tt.path); got != tt.want {
+			t.Errorf("%+v matches(%s %s%s) = %v, want %v", tt.match, tt.typ, tt.host, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestRulesApply(t *testing.T) {
+	rules := NewRules()
+	once, err := rules.Add(Rule{Match: Match{Host: "example.com"}, Status: 502, Times: 2})
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	always, _ := rules.Add(Rule{LatencyMs: 10})
+
+	for i, want := range []int{once.ID, once.ID, always.ID} {
+		r := rules.Apply(TypeForward, "GET", "example.com", "/")
+		if r == nil || r.ID != want {
+			t.Fatalf("request %d applied %+v, want rule %d", i, r, want)
+		}
+	}
+	if list := rules.List(); len(list) != 1 || list[0].ID != always.ID {
+		t.Errorf("rules after Times = %+v, want only rule %d", list, always.ID)
+	}
+
+	if !rules.Delete(always.ID) || rules.Delete(always.ID) {
+		t.Error("Delete() should remove the rule once")
+	}
+	if r := rules.Apply(TypeForward, "GET", "example.com", "/"); r != nil {
+		t.Errorf("Apply() = %+v, want nil", r)
+	}
+}
+
+func TestRuleValidate(t *testing.T) {
+	for _, r := range []Rule{
+		{Match: Match{Type: "socks"}},
+		{LatencyMs: -1},
+		{LatencyMs: 61000},
+		{Status: 99},
+		{Times: -1},
+	} {
+		if err := r.Validate(); err == nil {
+			t.Errorf("Validate(%+v) succeeded, want error", r)
+		}
+	}
+}
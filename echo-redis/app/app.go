@@ -0,0 +1,207 @@
+// Package app runs echo-redis: LoadConfig reads its settings and Run serves
+// it until its context ends, for the echo-redis binary and echo-all.
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/probitas-test/echo-servers/echo-redis/docs"
+	"github.com/probitas-test/echo-servers/echo-redis/server"
+	"github.com/probitas-test/echo-servers/shared/admin"
+	"github.com/probitas-test/echo-servers/shared/bandwidth"
+	"github.com/probitas-test/echo-servers/shared/clock"
+	"github.com/probitas-test/echo-servers/shared/connlimit"
+	"github.com/probitas-test/echo-servers/shared/lifecycle"
+	"github.com/probitas-test/echo-servers/shared/logging"
+	"github.com/probitas-test/echo-servers/shared/network"
+	"github.com/probitas-test/echo-servers/shared/tracing"
+	"github.com/probitas-test/echo-servers/shared/version"
+)
+
+// Run serves echo-redis with cfg until ctx is done, then shuts it down
+// gracefully. Logging and the address family are set up by the caller, as
+// they are shared by the servers of a process.
+func Run(ctx context.Context, cfg *Config) error {
+	// Started servers stop when Run returns early with an error
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Listeners serving in the background stop Run with their error
+	serveErr := make(chan error, 1)
+
+	// Simulated slow startup: no port opens before the startup delay
+	if err := cfg.Lifecycle.Validate(); err != nil {
+		return fmt.Errorf("invalid lifecycle configuration: %w", err)
+	}
+	log.Printf("Lifecycle: %s", cfg.Lifecycle)
+	lifecycle.Delay(ctx, cfg.Lifecycle)
+
+	// OpenTelemetry tracing and W3C trace context propagation
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		return fmt.Errorf("failed to set up tracing: %w", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Error("Tracing shutdown error", "error", err)
+		}
+	}()
+	log.Printf("OpenTelemetry tracing: %s", cfg.Tracing)
+
+	if cfg.LatencyMs < 0 {
+		return fmt.Errorf("invalid LATENCY_MS %d (must be >= 0)", cfg.LatencyMs)
+	}
+	if cfg.ErrorRate < 0 || cfg.ErrorRate > 1 {
+		return fmt.Errorf("invalid ERROR_RATE %v (must be 0-1)", cfg.ErrorRate)
+	}
+	if cfg.ErrorReply == "" || strings.ContainsAny(cfg.ErrorReply, "\r\n") {
+		return errors.New("ERROR_REPLY must be a single non-empty line")
+	}
+
+	// Admin API: health, reply delay and key space
+	adm := admin.New("echo-redis", cfg.src)
+
+	// Clock of key expiry, moved at runtime to expire keys without waiting
+	clk := clock.New(cfg.Clock)
+	log.Printf("Clock: %s", cfg.Clock)
+
+	rds := server.New(server.Config{
+		Latency:    time.Duration(cfg.LatencyMs) * time.Millisecond,
+		Delay:      adm.Delay,
+		ErrorRate:  cfg.ErrorRate,
+		ErrorReply: cfg.ErrorReply,
+		Clock:      clk,
+	})
+	adm.Store("keyspace", rds.Store().Flush)
+	adm.State("keys", func() any { return rds.Store().Len() })
+
+	// Bandwidth shaping of every Redis connection, changed at runtime on the
+	// admin port
+	shaper, err := bandwidth.New(cfg.Bandwidth)
+	if err != nil {
+		return err
+	}
+	log.Printf("Bandwidth: %s", cfg.Bandwidth)
+
+	// Cap on the open Redis connections, changed at runtime on the admin port;
+	// the next ones get a server busy error
+	connLimiter, err := connlimit.New(cfg.ConnLimit)
+	if err != nil {
+		return err
+	}
+	log.Printf("Connection limit: %s", cfg.ConnLimit)
+
+	listener, err := network.Listen(cfg.Addr())
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	defer func() { _ = listener.Close() }()
+
+	mux := http.NewServeMux()
+
+	// Health check endpoint
+	mux.Handle("GET /health", adm.HealthHandler())
+
+	// Effective configuration (secrets redacted)
+	mux.Handle("GET /config", cfg.src)
+
+	// Address family of the client connection
+	mux.Handle("GET "+network.Path, network.Handler())
+
+	// Build information and enabled features
+	mux.Handle("GET "+version.Path, version.Handler(version.New("echo-redis", version.Features{
+		"admin":   cfg.Admin.Enabled,
+		"debug":   cfg.Admin.Debug,
+		"tracing": cfg.Tracing.Enabled,
+	})))
+
+	// API documentation endpoint
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		_, _ = w.Write([]byte(docs.API))
+	})
+
+	// Admin API on a separate port, with the bandwidth, connection limit and
+	// clock admin APIs
+	var adminServer *http.Server
+	if cfg.Admin.Enabled {
+		adm.Handle(bandwidth.Path, bandwidth.Handler(shaper))
+		adm.Handle(connlimit.Path, connlimit.Handler(connLimiter))
+		adm.Handle(clock.Path, clock.Handler(clk))
+		if cfg.Admin.Debug {
+			adm.Debug()
+		}
+		adminServer, err = adm.Serve(cfg.AdminAddr())
+		if err != nil {
+			return err
+		}
+		defer func() { _ = adminServer.Close() }()
+	}
+
+	// Readiness delay and simulated crash, counted from here
+	lifecycle.Start(ctx, cfg.Lifecycle, adm)
+
+	srv := &http.Server{
+		Addr:              cfg.HTTPAddr(),
+		Handler:           tracing.HTTP(nil)(logging.HTTP(mux)),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	// Graceful shutdown (Redis connections are closed)
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		<-ctx.Done()
+
+		log.Println("Shutting down server...")
+		if err := rds.Close(); err != nil {
+			slog.Error("Redis server shutdown error", "error", err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := srv.Shutdown(ctx); err != nil {
+			slog.Error("Server shutdown error", "error", err)
+		}
+		if adminServer != nil {
+			_ = adminServer.Shutdown(ctx)
+		}
+	}()
+
+	go func() {
+		if err := rds.Serve(shaper.Listen(connLimiter.Listen(listener, connlimit.Line("-ERR max number of clients reached\r\n")))); err != nil && !errors.Is(err, server.ErrServerClosed) {
+			serveErr <- fmt.Errorf("failed to serve Redis: %w", err)
+			cancel()
+		}
+	}()
+
+	if cfg.LatencyMs > 0 {
+		log.Printf("Delaying replies by %dms", cfg.LatencyMs)
+	}
+	if cfg.ErrorRate > 0 {
+		log.Printf("Answering %v of commands with %q", cfg.ErrorRate, cfg.ErrorReply)
+	}
+	log.Printf("Starting Redis server on %s", cfg.Addr())
+	log.Printf("Starting HTTP server on %s", cfg.HTTPAddr())
+	if err := network.ListenAndServe(srv); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve: %w", err)
+	}
+	<-stopped
+
+	log.Println("Server stopped")
+	select {
+	case err := <-serveErr:
+		return err
+	default:
+		return nil
+	}
+}
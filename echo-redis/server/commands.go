@@ -0,0 +1,331 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Version is the Redis version reported by HELLO
+const Version = "7.2.0"
+
+// maxSleepSeconds is the longest DEBUG SLEEP accepted
+const maxSleepSeconds = 60
+
+// command is a supported Redis command
+type command struct {
+	// arity is the number of arguments including the command name; a
+	// negative arity is a minimum
+	arity int
+
+	// handshake commands are exempt from Latency and ErrorRate, so clients
+	// can always connect
+	handshake bool
+
+	run func(s *Server, c *client, args [][]byte) any
+}
+
+// commands maps lower case command names to their implementation
+var commands = map[string]command{
+	"ping":     {arity: -1, run: (*Server).ping},
+	"echo":     {arity: 2, run: (*Server).echo},
+	"get":      {arity: 2, run: (*Server).get},
+	"set":      {arity: -3, run: (*Server).set},
+	"del":      {arity: -2, run: (*Server).del},
+	"exists":   {arity: -2, run: (*Server).exists},
+	"ttl":      {arity: 2, run: (*Server).ttl},
+	"pttl":     {arity: 2, run: (*Server).pttl},
+	"dbsize":   {arity: 1, run: (*Server).dbsize},
+	"flushdb":  {arity: -1, run: (*Server).flush},
+	"flushall": {arity: -1, run: (*Server).flush},
+	"debug":    {arity: -2, run: (*Server).debug},
+	"hello":    {arity: -1, handshake: true, run: (*Server).hello},
+	"auth":     {arity: -2, handshake: true, run: (*Server).auth},
+	"client":   {arity: -2, handshake: true, run: (*Server).clientCmd},
+	"select":   {arity: 2, handshake: true, run: (*Server).selectDB},
+	"command":  {arity: -1, handshake: true, run: (*Server).commandCmd},
+	"quit":     {arity: -1, handshake: true, run: (*Server).quit},
+}
+
+var (
+	replyOK         = simpleString("OK")
+	errSyntax       = errorReply("ERR syntax error")
+	errNotInteger   = errorReply("ERR value is not an integer or out of range")
+	errInvalidFloat = errorReply("ERR value is not a valid float")
+)
+
+// latency returns how long to delay the reply of a command
+func (s *Server) latency() time.Duration {
+	d := s.cfg.Latency
+	if s.cfg.Delay != nil {
+		d += s.cfg.Delay()
+	}
+	return d
+}
+
+// exec runs a command, applying Latency and ErrorRate to every command but
+// the handshake ones
+func (s *Server) exec(c *client, args [][]byte) any {
+	name := strings.ToLower(string(args[0]))
+	cmd, ok := commands[name]
+	if !ok {
+		return unknownCommand(args)
+	}
+	if (cmd.arity > 0 && len(args) != cmd.arity) || (cmd.arity < 0 && len(args) < -cmd.arity) {
+		return errorReply(fmt.Sprintf("ERR wrong number of arguments for '%s' command", name))
+	}
+
+	if !cmd.handshake {
+		if d := s.latency(); d > 0 {
+			time.Sleep(d)
+		}
+		if s.injectError() {
+			return errorReply(s.cfg.ErrorReply)
+		}
+	}
+	return cmd.run(s, c, args)
+}
+
+func unknownCommand(args [][]byte) errorReply {
+	var quoted []string
+	for _, arg := range args[1:] {
+		quoted = append(quoted, "'"+string(arg)+"'")
+	}
+	return errorReply(fmt.Sprintf("ERR unknown command '%s', with args beginning with: %s", args[0], strings.Join(quoted, " ")))
+}
+
+// PING [message]
+func (s *Server) ping(c *client, args [][]byte) any {
+	if len(args) > 2 {
+		return errorReply("ERR wrong number of arguments for 'ping' command")
+	}
+	if len(args) == 2 {
+		return args[1]
+	}
+	return simpleString("PONG")
+}
+
+// ECHO message
+func (s *Server) echo(c *client, args [][]byte) any {
+	return args[1]
+}
+
+// GET key
+func (s *Server) get(c *client, args [][]byte) any {
+	value, ok := s.store.Get(string(args[1]))
+	if !ok {
+		return nilReply{}
+	}
+	return value
+}
+
+// SET key value [NX | XX] [EX seconds | PX milliseconds]
+func (s *Server) set(c *client, args [][]byte) any {
+	var nx, xx bool
+	var ttl time.Duration
+	for i := 3; i < len(args); i++ {
+		switch option := strings.ToUpper(string(args[i])); option {
+		case "NX":
+			nx = true
+		case "XX":
+			xx = true
+		case "EX", "PX":
+			if ttl != 0 || i+1 >= len(args) {
+				return errSyntax
+			}
+			i++
+			n, err := strconv.ParseInt(string(args[i]), 10, 64)
+			if err != nil {
+				return errNotInteger
+			}
+			unit := time.Second
+			if option == "PX" {
+				unit = time.Millisecond
+			}
+			if n <= 0 || n > math.MaxInt64/int64(unit) {
+				return errorReply("ERR invalid expire time in 'set' command")
+			}
+			ttl = time.Duration(n) * unit
+		default:
+			return errSyntax
+		}
+	}
+	if nx && xx {
+		return errSyntax
+	}
+
+	if !s.store.Set(string(args[1]), args[2], ttl, nx, xx) {
+		return nilReply{}
+	}
+	return replyOK
+}
+
+// DEL key [key ...]
+func (s *Server) del(c *client, args [][]byte) any {
+	return s.store.Delete(keys(args[1:])...)
+}
+
+// EXISTS key [key ...]
+func (s *Server) exists(c *client, args [][]byte) any {
+	return s.store.Exists(keys(args[1:])...)
+}
+
+// TTL key
+func (s *Server) ttl(c *client, args [][]byte) any {
+	ttl, ok := s.store.TTL(string(args[1]))
+	return ttlReply(ttl, ok, time.Second)
+}
+
+// PTTL key
+func (s *Server) pttl(c *client, args [][]byte) any {
+	ttl, ok := s.store.TTL(string(args[1]))
+	return ttlReply(ttl, ok, time.Millisecond)
+}
+
+// ttlReply converts a TTL to the reply of TTL and PTTL: -2 for missing keys,
+// -1 for keys without expiry, otherwise the TTL rounded to unit
+func ttlReply(ttl time.Duration, ok bool, unit time.Duration) int64 {
+	switch {
+	case !ok:
+		return -2
+	case ttl < 0:
+		return -1
+	}
+	return int64((ttl + unit/2) / unit)
+}
+
+// DBSIZE
+func (s *Server) dbsize(c *client, args [][]byte) any {
+	return s.store.Len()
+}
+
+// FLUSHDB [ASYNC | SYNC], FLUSHALL [ASYNC | SYNC]
+func (s *Server) flush(c *client, args [][]byte) any {
+	if len(args) > 2 {
+		return errSyntax
+	}
+	if len(args) == 2 {
+		if mode := strings.ToUpper(string(args[1])); mode != "ASYNC" && mode != "SYNC" {
+			return errSyntax
+		}
+	}
+	s.store.Flush()
+	return replyOK
+}
+
+// DEBUG SLEEP seconds, DEBUG ERROR message
+func (s *Server) debug(c *client, args [][]byte) any {
+	subcommand := strings.ToUpper(string(args[1]))
+	switch {
+	case subcommand == "SLEEP" && len(args) == 3:
+		seconds, err := strconv.ParseFloat(string(args[2]), 64)
+		if err != nil || math.IsNaN(seconds) {
+			return errInvalidFloat
+		}
+		if seconds < 0 || seconds > maxSleepSeconds {
+			return errorReply(fmt.Sprintf("ERR sleep time must be between 0 and %d seconds", maxSleepSeconds))
+		}
+		time.Sleep(time.Duration(seconds * float64(time.Second)))
+		return replyOK
+
+	case subcommand == "ERROR" && len(args) == 3:
+		return errorReply(strings.NewReplacer("\r", " ", "\n", " ").Replace(string(args[2])))
+	}
+	return errorReply(fmt.Sprintf("ERR unknown subcommand '%s'. Try DEBUG SLEEP or DEBUG ERROR.", args[1]))
+}
+
+// HELLO [protover [AUTH username password] [SETNAME clientname]]. Only RESP2
+// is supported; HELLO 3 fails with NOPROTO, so clients fall back to RESP2.
+func (s *Server) hello(c *client, args [][]byte) any {
+	if len(args) > 1 {
+		version, err := strconv.Atoi(string(args[1]))
+		if err != nil {
+			return errorReply("ERR Protocol version is not an integer or out of range")
+		}
+		if version != 2 {
+			return errorReply("NOPROTO unsupported protocol version")
+		}
+	}
+	for i := 2; i < len(args); i++ {
+		switch option := strings.ToUpper(string(args[i])); {
+		case option == "AUTH" && i+2 < len(args):
+			i += 2
+		case option == "SETNAME" && i+1 < len(args):
+			i++
+			c.name = string(args[i])
+		default:
+			return errorReply(fmt.Sprintf("ERR Syntax error in HELLO option '%s'", args[i]))
+		}
+	}
+	return []any{
+		"server", "redis",
+		"version", Version,
+		"proto", 2,
+		"id", c.id,
+		"mode", "standalone",
+		"role", "master",
+		"modules", []any{},
+	}
+}
+
+// AUTH [username] password. Any credentials are accepted.
+func (s *Server) auth(c *client, args [][]byte) any {
+	if len(args) > 3 {
+		return errSyntax
+	}
+	return replyOK
+}
+
+// CLIENT ID | GETNAME | SETNAME name | SETINFO attr value
+func (s *Server) clientCmd(c *client, args [][]byte) any {
+	subcommand := strings.ToUpper(string(args[1]))
+	switch {
+	case subcommand == "ID" && len(args) == 2:
+		return c.id
+	case subcommand == "GETNAME" && len(args) == 2:
+		if c.name == "" {
+			return nilReply{}
+		}
+		return c.name
+	case subcommand == "SETNAME" && len(args) == 3:
+		c.name = string(args[2])
+		return replyOK
+	case subcommand == "SETINFO" && len(args) == 4:
+		return replyOK
+	}
+	return errorReply(fmt.Sprintf("ERR unknown subcommand or wrong number of arguments for '%s'. Try CLIENT ID, GETNAME, SETNAME or SETINFO.", args[1]))
+}
+
+// SELECT index. Only database 0 exists.
+func (s *Server) selectDB(c *client, args [][]byte) any {
+	index, err := strconv.Atoi(string(args[1]))
+	if err != nil {
+		return errNotInteger
+	}
+	if index != 0 {
+		return errorReply("ERR DB index is out of range")
+	}
+	return replyOK
+}
+
+// COMMAND [subcommand ...]. Command introspection is not supported, so an
+// empty list is returned.
+func (s *Server) commandCmd(c *client, args [][]byte) any {
+	return []any{}
+}
+
+// QUIT closes the connection after the reply
+func (s *Server) quit(c *client, args [][]byte) any {
+	c.quit = true
+	return replyOK
+}
+
+func keys(args [][]byte) []string {
+	keys := make([]string, len(args))
+	for i, arg := range args {
+		keys[i] = string(arg)
+	}
+	return keys
+}
@@ -0,0 +1,142 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+// run executes a command on s and returns the RESP encoded reply
+func run(s *Server, c *client, args ...string) string {
+	raw := make([][]byte, len(args))
+	for i, arg := range args {
+		raw[i] = []byte(arg)
+	}
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	writeReply(w, s.exec(c, raw))
+	_ = w.Flush()
+	return buf.String()
+}
+
+func TestCommands(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "ping", args: []string{"PING"}, want: "+PONG\r\n"},
+		{name: "ping message", args: []string{"ping", "hi"}, want: "$2\r\nhi\r\n"},
+		{name: "ping too many", args: []string{"PING", "a", "b"}, want: "-ERR wrong number of arguments for 'ping' command\r\n"},
+		{name: "echo", args: []string{"ECHO", "hello"}, want: "$5\r\nhello\r\n"},
+		{name: "echo arity", args: []string{"ECHO"}, want: "-ERR wrong number of arguments for 'echo' command\r\n"},
+		{name: "get missing", args: []string{"GET", "missing"}, want: "$-1\r\n"},
+		{name: "get", args: []string{"GET", "greeting"}, want: "$5\r\nhello\r\n"},
+		{name: "set", args: []string{"SET", "k", "v"}, want: "+OK\r\n"},
+		{name: "set nx existing", args: []string{"SET", "greeting", "v", "NX"}, want: "$-1\r\n"},
+		{name: "set ex", args: []string{"SET", "k", "v", "EX", "10"}, want: "+OK\r\n"},
+		{name: "set px", args: []string{"SET", "k", "v", "px", "100"}, want: "+OK\r\n"},
+		{name: "set invalid expire", args: []string{"SET", "k", "v", "EX", "0"}, want: "-ERR invalid expire time in 'set' command\r\n"},
+		{name: "set expire not integer", args: []string{"SET", "k", "v", "EX", "x"}, want: "-ERR value is not an integer or out of range\r\n"},
+		{name: "set nx xx", args: []string{"SET", "k", "v", "NX", "XX"}, want: "-ERR syntax error\r\n"},
+		{name: "set unknown option", args: []string{"SET", "k", "v", "KEEP"}, want: "-ERR syntax error\r\n"},
+		{name: "del", args: []string{"DEL", "greeting", "missing"}, want: ":1\r\n"},
+		{name: "exists", args: []string{"EXISTS", "greeting", "greeting", "missing"}, want: ":2\r\n"},
+		{name: "ttl without expiry", args: []string{"TTL", "greeting"}, want: ":-1\r\n"},
+		{name: "pttl missing", args: []string{"PTTL", "missing"}, want: ":-2\r\n"},
+		{name: "dbsize", args: []string{"DBSIZE"}, want: ":1\r\n"},
+		{name: "flushall", args: []string{"FLUSHALL", "ASYNC"}, want: "+OK\r\n"},
+		{name: "debug error", args: []string{"DEBUG", "ERROR", "LOADING Redis is loading"}, want: "-LOADING Redis is loading\r\n"},
+		{name: "debug unknown", args: []string{"DEBUG", "SEGFAULT"}, want: "-ERR unknown subcommand 'SEGFAULT'. Try DEBUG SLEEP or DEBUG ERROR.\r\n"},
+		{name: "debug sleep", args: []string{"DEBUG", "SLEEP", "0"}, want: "+OK\r\n"},
+		{name: "debug sleep too long", args: []string{"DEBUG", "SLEEP", "61"}, want: "-ERR sleep time must be between 0 and 60 seconds\r\n"},
+		{name: "hello 3", args: []string{"HELLO", "3"}, want: "-NOPROTO unsupported protocol version\r\n"},
+		{name: "auth", args: []string{"AUTH", "user", "password"}, want: "+OK\r\n"},
+		{name: "select 0", args: []string{"SELECT", "0"}, want: "+OK\r\n"},
+		{name: "select 1", args: []string{"SELECT", "1"}, want: "-ERR DB index is out of range\r\n"},
+		{name: "command", args: []string{"COMMAND", "DOCS"}, want: "*0\r\n"},
+		{name: "unknown", args: []string{"HGET", "h", "f"}, want: "-ERR unknown command 'HGET', with args beginning with: 'h' 'f'\r\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New(Config{})
+			s.Store().Set("greeting", []byte("hello"), 0, false, false)
+			if got := run(s, &client{id: 1}, tt.args...); got != tt.want {
+				t.Errorf("%v: expected %q, got %q", tt.args, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSetExpiry(t *testing.T) {
+	s := New(Config{})
+	c := &client{id: 1}
+
+	run(s, c, "SET", "k", "v", "PX", "2400")
+	if got := run(s, c, "TTL", "k"); got != ":2\r\n" {
+		t.Errorf("expected TTL rounded to 2 seconds, got %q", got)
+	}
+	if got := run(s, c, "SET", "k", "v", "XX"); got != "+OK\r\n" {
+		t.Errorf("expected XX on an existing key to succeed, got %q", got)
+	}
+	if got := run(s, c, "PTTL", "k"); got != ":-1\r\n" {
+		t.Errorf("expected SET without expiry to clear the TTL, got %q", got)
+	}
+}
+
+func TestClientCommands(t *testing.T) {
+	s := New(Config{})
+	c := &client{id: 7}
+
+	if got := run(s, c, "CLIENT", "ID"); got != ":7\r\n" {
+		t.Errorf("expected id 7, got %q", got)
+	}
+	if got := run(s, c, "CLIENT", "GETNAME"); got != "$-1\r\n" {
+		t.Errorf("expected no name, got %q", got)
+	}
+	run(s, c, "CLIENT", "SETNAME", "worker")
+	if got := run(s, c, "CLIENT", "GETNAME"); got != "$6\r\nworker\r\n" {
+		t.Errorf("expected name worker, got %q", got)
+	}
+	if got := run(s, c, "CLIENT", "SETINFO", "LIB-NAME", "go-redis"); got != "+OK\r\n" {
+		t.Errorf("expected SETINFO to succeed, got %q", got)
+	}
+
+	got := run(s, c, "HELLO", "2", "SETNAME", "hello-name")
+	if !strings.HasPrefix(got, "*14\r\n$6\r\nserver\r\n$5\r\nredis\r\n") || !strings.Contains(got, "$5\r\nproto\r\n:2\r\n$2\r\nid\r\n:7\r\n") {
+		t.Errorf("unexpected HELLO reply %q", got)
+	}
+	if c.name != "hello-name" {
+		t.Errorf("expected HELLO SETNAME to set the name, got %q", c.name)
+	}
+
+	run(s, c, "QUIT")
+	if !c.quit {
+		t.Error("expected QUIT to close the connection")
+	}
+}
+
+func TestInjectedFaults(t *testing.T) {
+	s := New(Config{Latency: 50 * time.Millisecond, ErrorRate: 1, ErrorReply: "LOADING Redis is loading the dataset in memory"})
+	c := &client{id: 1}
+
+	start := time.Now()
+	if got := run(s, c, "GET", "k"); got != "-LOADING Redis is loading the dataset in memory\r\n" {
+		t.Errorf("expected the injected error, got %q", got)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("expected the reply to be delayed by 50ms, took %v", elapsed)
+	}
+
+	// Handshake commands are exempt
+	start = time.Now()
+	if got := run(s, c, "SELECT", "0"); got != "+OK\r\n" {
+		t.Errorf("expected SELECT to succeed, got %q", got)
+	}
+	if elapsed := time.Since(start); elapsed >= 50*time.Millisecond {
+		t.Errorf("expected SELECT not to be delayed, took %v", elapsed)
+	}
+}
@@ -0,0 +1,170 @@
+package server
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	// maxArrayLength is the largest number of arguments in a command
+	maxArrayLength = 1024 * 1024
+
+	// maxBulkLength is the largest argument in bytes, as in Redis
+	maxBulkLength = 512 * 1024 * 1024
+
+	// maxInlineLength is the longest inline command line
+	maxInlineLength = 64 * 1024
+)
+
+// protocolError is a malformed request. The connection is answered with
+// "-ERR Protocol error: ..." and closed, as Redis does.
+type protocolError string
+
+func (e protocolError) Error() string {
+	return "Protocol error: " + string(e)
+}
+
+// simpleString is replied as a RESP simple string (+OK)
+type simpleString string
+
+// errorReply is replied as a RESP error (-ERR ...). It starts with the
+// error code such as ERR or WRONGTYPE.
+type errorReply string
+
+// nilReply is replied as a RESP null bulk string ($-1)
+type nilReply struct{}
+
+// readCommand reads a command: a RESP array of bulk strings, or an inline
+// command (space separated words on one line) as sent by telnet. Empty
+// commands are skipped.
+func readCommand(r *bufio.Reader) ([][]byte, error) {
+	for {
+		b, err := r.Peek(1)
+		if err != nil {
+			return nil, err
+		}
+		var args [][]byte
+		if b[0] == '*' {
+			args, err = readArray(r)
+		} else {
+			args, err = readInline(r)
+		}
+		if err != nil || len(args) > 0 {
+			return args, err
+		}
+	}
+}
+
+func readArray(r *bufio.Reader) ([][]byte, error) {
+	line, err := readLine(r, maxInlineLength)
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(line[1:])
+	if err != nil || n > maxArrayLength {
+		return nil, protocolError("invalid multibulk length")
+	}
+	if n <= 0 {
+		return nil, nil
+	}
+
+	args := make([][]byte, 0, n)
+	for range n {
+		line, err := readLine(r, maxInlineLength)
+		if err != nil {
+			return nil, err
+		}
+		if line == "" || line[0] != '$' {
+			return nil, protocolError(fmt.Sprintf("expected '$', got '%s'", firstChar(line)))
+		}
+		size, err := strconv.Atoi(line[1:])
+		if err != nil || size < 0 || size > maxBulkLength {
+			return nil, protocolError("invalid bulk length")
+		}
+		arg := make([]byte, size+2)
+		if _, err := io.ReadFull(r, arg); err != nil {
+			return nil, err
+		}
+		if arg[size] != '\r' || arg[size+1] != '\n' {
+			return nil, protocolError("invalid bulk terminator")
+		}
+		args = append(args, arg[:size])
+	}
+	return args, nil
+}
+
+func readInline(r *bufio.Reader) ([][]byte, error) {
+	line, err := readLine(r, maxInlineLength)
+	if err != nil {
+		return nil, err
+	}
+	var args [][]byte
+	for _, field := range strings.Fields(line) {
+		args = append(args, []byte(field))
+	}
+	return args, nil
+}
+
+// readLine reads a line terminated by \n (or \r\n) of at most limit bytes
+func readLine(r *bufio.Reader, limit int) (string, error) {
+	var line []byte
+	for {
+		chunk, err := r.ReadSlice('\n')
+		line = append(line, chunk...)
+		if len(line) > limit {
+			return "", protocolError("too big inline request")
+		}
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, bufio.ErrBufferFull) {
+			return "", err
+		}
+	}
+	line = line[:len(line)-1]
+	if n := len(line); n > 0 && line[n-1] == '\r' {
+		line = line[:n-1]
+	}
+	return string(line), nil
+}
+
+func firstChar(line string) string {
+	if line == "" {
+		return ""
+	}
+	return line[:1]
+}
+
+// writeReply writes v in RESP2: simpleString, errorReply, int, int64,
+// []byte or string (bulk strings), nilReply, and []any (arrays)
+func writeReply(w *bufio.Writer, v any) {
+	switch v := v.(type) {
+	case simpleString:
+		_, _ = w.WriteString("+" + string(v) + "\r\n")
+	case errorReply:
+		_, _ = w.WriteString("-" + string(v) + "\r\n")
+	case int:
+		_, _ = w.WriteString(":" + strconv.Itoa(v) + "\r\n")
+	case int64:
+		_, _ = w.WriteString(":" + strconv.FormatInt(v, 10) + "\r\n")
+	case []byte:
+		_, _ = w.WriteString("$" + strconv.Itoa(len(v)) + "\r\n")
+		_, _ = w.Write(v)
+		_, _ = w.WriteString("\r\n")
+	case string:
+		writeReply(w, []byte(v))
+	case nilReply, nil:
+		_, _ = w.WriteString("$-1\r\n")
+	case []any:
+		_, _ = w.WriteString("*" + strconv.Itoa(len(v)) + "\r\n")
+		for _, item := range v {
+			writeReply(w, item)
+		}
+	default:
+		panic(fmt.Sprintf("unsupported reply type %T", v))
+	}
+}
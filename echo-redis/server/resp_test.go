@@ -0,0 +1,99 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestReadCommand(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{name: "array", input: "*2\r\n$4\r\nECHO\r\n$5\r\nhello\r\n", want: []string{"ECHO", "hello"}},
+		{name: "binary safe", input: "*2\r\n$4\r\nECHO\r\n$4\r\na\r\nb\r\n", want: []string{"ECHO", "a\r\nb"}},
+		{name: "empty bulk", input: "*2\r\n$4\r\nECHO\r\n$0\r\n\r\n", want: []string{"ECHO", ""}},
+		{name: "inline", input: "SET  key value\r\n", want: []string{"SET", "key", "value"}},
+		{name: "inline with LF", input: "PING\n", want: []string{"PING"}},
+		{name: "skips empty lines", input: "\r\n\r\nPING\r\n", want: []string{"PING"}},
+		{name: "skips empty arrays", input: "*0\r\n*1\r\n$4\r\nPING\r\n", want: []string{"PING"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, err := readCommand(bufio.NewReader(strings.NewReader(tt.input)))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got := make([]string, len(args))
+			for i, arg := range args {
+				got[i] = string(arg)
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestReadCommandErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "invalid array length", input: "*x\r\n", want: "Protocol error: invalid multibulk length"},
+		{name: "missing bulk", input: "*1\r\n+PING\r\n", want: "Protocol error: expected '$', got '+'"},
+		{name: "invalid bulk length", input: "*1\r\n$-3\r\n", want: "Protocol error: invalid bulk length"},
+		{name: "invalid terminator", input: "*1\r\n$4\r\nPINGxx", want: "Protocol error: invalid bulk terminator"},
+		{name: "inline too long", input: strings.Repeat("a", maxInlineLength+1) + "\r\n", want: "Protocol error: too big inline request"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := readCommand(bufio.NewReader(strings.NewReader(tt.input)))
+			var protoErr protocolError
+			if !errors.As(err, &protoErr) || err.Error() != tt.want {
+				t.Errorf("expected %q, got %v", tt.want, err)
+			}
+		})
+	}
+
+	if _, err := readCommand(bufio.NewReader(strings.NewReader("*1\r\n$4\r\nPI"))); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("expected unexpected EOF for a truncated command, got %v", err)
+	}
+}
+
+func TestWriteReply(t *testing.T) {
+	tests := []struct {
+		name  string
+		reply any
+		want  string
+	}{
+		{name: "simple string", reply: simpleString("OK"), want: "+OK\r\n"},
+		{name: "error", reply: errorReply("ERR oops"), want: "-ERR oops\r\n"},
+		{name: "integer", reply: 42, want: ":42\r\n"},
+		{name: "int64", reply: int64(-2), want: ":-2\r\n"},
+		{name: "bulk", reply: []byte("a\r\nb"), want: "$4\r\na\r\nb\r\n"},
+		{name: "string", reply: "hi", want: "$2\r\nhi\r\n"},
+		{name: "nil", reply: nilReply{}, want: "$-1\r\n"},
+		{name: "array", reply: []any{"a", 1, []any{}}, want: "*3\r\n$1\r\na\r\n:1\r\n*0\r\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w := bufio.NewWriter(&buf)
+			writeReply(w, tt.reply)
+			_ = w.Flush()
+			if buf.String() != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, buf.String())
+			}
+		})
+	}
+}
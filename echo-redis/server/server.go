@@ -0,0 +1,162 @@
+package server
+
+import (
+	"bufio"
+	"errors"
+	"math/rand/v2"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/probitas-test/echo-servers/shared/clock"
+)
+
+// ErrServerClosed is returned by Serve after Close
+var ErrServerClosed = errors.New("server closed")
+
+// Config controls how commands are answered
+type Config struct {
+	// Latency delays the reply of every command but the handshake ones
+	// (HELLO, AUTH, CLIENT, SELECT, COMMAND, QUIT)
+	Latency time.Duration
+
+	// Delay, if set, is read on every command and adds to Latency, for
+	// delays changed at runtime
+	Delay func() time.Duration
+
+	// ErrorRate is the probability (0-1) that a command other than the
+	// handshake ones is answered with ErrorReply instead
+	ErrorRate float64
+
+	// ErrorReply is the injected error, starting with its code such as
+	// "ERR" or "LOADING"
+	ErrorReply string
+
+	// Clock times the key expiry, the real clock when nil
+	Clock *clock.Clock
+}
+
+// Server serves a subset of the Redis protocol (RESP2) from an in-memory
+// store. Commands of a connection are answered in order, so pipelined
+// commands are supported; replies are flushed once no further command is
+// buffered.
+type Server struct {
+	cfg    Config
+	store  *Store
+	nextID atomic.Int64
+
+	mu       sync.Mutex
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+	closed   bool
+}
+
+// client is the state of a connection
+type client struct {
+	id   int64
+	name string
+
+	// quit closes the connection after the current reply
+	quit bool
+}
+
+// New creates a server with an empty store
+func New(cfg Config) *Server {
+	return &Server{
+		cfg:   cfg,
+		store: NewStore(cfg.Clock),
+		conns: make(map[net.Conn]struct{}),
+	}
+}
+
+// Store returns the key space of the server
+func (s *Server) Store() *Store {
+	return s.store
+}
+
+// Serve accepts connections on l until Close is called
+func (s *Server) Serve(l net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return ErrServerClosed
+	}
+	s.listener = l
+	s.mu.Unlock()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return ErrServerClosed
+			}
+			return err
+		}
+		go s.serveConn(conn)
+	}
+}
+
+// Close stops accepting connections and closes the open ones
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+	if s.listener != nil {
+		return s.listener.Close()
+	}
+	return nil
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		_ = conn.Close()
+		return
+	}
+	s.conns[conn] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		_ = conn.Close()
+	}()
+
+	c := &client{id: s.nextID.Add(1)}
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			var protoErr protocolError
+			if errors.As(err, &protoErr) {
+				writeReply(w, errorReply("ERR "+protoErr.Error()))
+				_ = w.Flush()
+			}
+			return
+		}
+
+		writeReply(w, s.exec(c, args))
+		if r.Buffered() == 0 || c.quit {
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+		if c.quit {
+			return
+		}
+	}
+}
+
+// injectError decides whether a command is answered with ErrorReply
+func (s *Server) injectError() bool {
+	return s.cfg.ErrorRate > 0 && rand.Float64() < s.cfg.ErrorRate
+}
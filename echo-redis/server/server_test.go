@@ -0,0 +1,155 @@
+package server
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// startServer serves s on a random local port and returns its address
+func startServer(t *testing.T, s *Server) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	go func() { _ = s.Serve(l) }()
+	t.Cleanup(func() { _ = s.Close() })
+	return l.Addr().String()
+}
+
+// dial connects to addr and returns the connection and a reader of replies
+func dial(t *testing.T, addr string) (net.Conn, *bufio.Reader) {
+	t.Helper()
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+	return conn, bufio.NewReader(conn)
+}
+
+// expect reads len(want) bytes and compares them to want
+func expect(t *testing.T, r *bufio.Reader, want string) {
+	t.Helper()
+	got := make([]byte, len(want))
+	if _, err := io.ReadFull(r, got); err != nil {
+		t.Fatalf("failed to read %q: %v (got %q)", want, err, got)
+	}
+	if string(got) != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestServerPipelining(t *testing.T) {
+	addr := startServer(t, New(Config{}))
+	conn, r := dial(t, addr)
+
+	// Send all commands at once, replies arrive in order
+	_, _ = conn.Write([]byte("*3\r\n$3\r\nSET\r\n$1\r\nk\r\n$1\r\nv\r\n" +
+		"*2\r\n$3\r\nGET\r\n$1\r\nk\r\n" +
+		"PING\r\n" +
+		"*2\r\n$4\r\nECHO\r\n$3\r\nbye\r\n"))
+	expect(t, r, "+OK\r\n$1\r\nv\r\n+PONG\r\n$3\r\nbye\r\n")
+}
+
+func TestServerSharedStore(t *testing.T) {
+	addr := startServer(t, New(Config{}))
+	conn1, r1 := dial(t, addr)
+	conn2, r2 := dial(t, addr)
+
+	_, _ = conn1.Write([]byte("SET shared value\r\n"))
+	expect(t, r1, "+OK\r\n")
+	_, _ = conn2.Write([]byte("GET shared\r\n"))
+	expect(t, r2, "$5\r\nvalue\r\n")
+}
+
+func TestServerQuit(t *testing.T) {
+	addr := startServer(t, New(Config{}))
+	conn, r := dial(t, addr)
+
+	_, _ = conn.Write([]byte("QUIT\r\nPING\r\n"))
+	expect(t, r, "+OK\r\n")
+	if _, err := r.ReadByte(); !errors.Is(err, io.EOF) {
+		t.Errorf("expected the connection to be closed after QUIT, got %v", err)
+	}
+}
+
+func TestServerProtocolError(t *testing.T) {
+	addr := startServer(t, New(Config{}))
+	conn, r := dial(t, addr)
+
+	_, _ = conn.Write([]byte("PING\r\n*1\r\n+PING\r\n"))
+	expect(t, r, "+PONG\r\n-ERR Protocol error: expected '$', got '+'\r\n")
+	if _, err := r.ReadByte(); !errors.Is(err, io.EOF) {
+		t.Errorf("expected the connection to be closed after a protocol error, got %v", err)
+	}
+}
+
+func TestServerLatency(t *testing.T) {
+	addr := startServer(t, New(Config{Latency: 300 * time.Millisecond}))
+	conn, r := dial(t, addr)
+
+	// A client timing out before the reply
+	_ = conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	_, _ = conn.Write([]byte("PING\r\n"))
+	var netErr net.Error
+	if _, err := r.ReadByte(); !errors.As(err, &netErr) || !netErr.Timeout() {
+		t.Fatalf("expected a read timeout, got %v", err)
+	}
+}
+
+func TestServerDelay(t *testing.T) {
+	var delay atomic.Int64
+	addr := startServer(t, New(Config{Delay: func() time.Duration { return time.Duration(delay.Load()) }}))
+	conn, r := dial(t, addr)
+
+	_, _ = conn.Write([]byte("PING\r\n"))
+	expect(t, r, "+PONG\r\n")
+
+	// Changed between commands
+	delay.Store(int64(300 * time.Millisecond))
+	_ = conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	_, _ = conn.Write([]byte("PING\r\n"))
+	var netErr net.Error
+	if _, err := r.ReadByte(); !errors.As(err, &netErr) || !netErr.Timeout() {
+		t.Fatalf("expected a read timeout, got %v", err)
+	}
+}
+
+func TestServerClose(t *testing.T) {
+	s := New(Config{})
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- s.Serve(l) }()
+
+	conn, r := dial(t, l.Addr().String())
+	_, _ = conn.Write([]byte("PING\r\n"))
+	expect(t, r, "+PONG\r\n")
+
+	if err := s.Close(); err != nil {
+		t.Fatalf("failed to close: %v", err)
+	}
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrServerClosed) {
+			t.Errorf("expected ErrServerClosed, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve did not return after Close")
+	}
+
+	// Open connections are closed
+	if _, err := r.ReadString('\n'); err == nil || strings.Contains(err.Error(), "timeout") {
+		t.Errorf("expected the connection to be closed, got %v", err)
+	}
+}
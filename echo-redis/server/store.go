@@ -0,0 +1,136 @@
+package server
+
+import (
+	"sync"
+	"time"
+
+	"github.com/probitas-test/echo-servers/shared/clock"
+)
+
+// entry is a stored value with an optional expiry
+type entry struct {
+	value   []byte
+	expires time.Time
+}
+
+func (e entry) expired(now time.Time) bool {
+	return !e.expires.IsZero() && !now.Before(e.expires)
+}
+
+// Store is the in-memory key space shared by all connections. Expired keys
+// are removed when they are accessed.
+type Store struct {
+	clock *clock.Clock
+
+	mu   sync.Mutex
+	data map[string]entry
+}
+
+// NewStore creates an empty store whose keys expire on clk, the real clock
+// when nil
+func NewStore(clk *clock.Clock) *Store {
+	return &Store{clock: clk, data: make(map[string]entry)}
+}
+
+// Get returns the value of key
+func (s *Store) Get(key string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.lookup(key)
+	return e.value, ok
+}
+
+// Set stores value at key. A zero ttl keeps the value forever. With nx the
+// key is only set when it does not exist, with xx only when it exists. It
+// reports whether the key was set.
+func (s *Store) Set(key string, value []byte, ttl time.Duration, nx, xx bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, exists := s.lookup(key)
+	if (nx && exists) || (xx && !exists) {
+		return false
+	}
+	e := entry{value: value}
+	if ttl > 0 {
+		e.expires = s.clock.Now().Add(ttl)
+	}
+	s.data[key] = e
+	return true
+}
+
+// Delete removes keys and returns how many existed
+func (s *Store) Delete(keys ...string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, key := range keys {
+		if _, ok := s.lookup(key); ok {
+			delete(s.data, key)
+			n++
+		}
+	}
+	return n
+}
+
+// Exists returns how many of keys exist, counting repeated keys repeatedly
+func (s *Store) Exists(keys ...string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, key := range keys {
+		if _, ok := s.lookup(key); ok {
+			n++
+		}
+	}
+	return n
+}
+
+// TTL returns the remaining time to live of key, or -1 when it has no
+// expiry. ok is false when key does not exist.
+func (s *Store) TTL(key string) (ttl time.Duration, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.lookup(key)
+	switch {
+	case !ok:
+		return 0, false
+	case e.expires.IsZero():
+		return -1, true
+	}
+	return s.clock.Until(e.expires), true
+}
+
+// Len returns the number of keys
+func (s *Store) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.clock.Now()
+	n := 0
+	for _, e := range s.data {
+		if !e.expired(now) {
+			n++
+		}
+	}
+	return n
+}
+
+// Flush removes all keys
+func (s *Store) Flush() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clear(s.data)
+}
+
+// lookup returns the entry of key, removing it when it expired. s.mu must be
+// held.
+func (s *Store) lookup(key string) (entry, bool) {
+	e, ok := s.data[key]
+	if !ok {
+		return entry{}, false
+	}
+	if e.expired(s.clock.Now()) {
+		delete(s.data, key)
+		return entry{}, false
+	}
+	return e, true
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/probitas-test/echo-servers/shared/clock"
+)
+
+func TestStoreSetGet(t *testing.T) {
+	s := NewStore(nil)
+
+	if _, ok := s.Get("key"); ok {
+		t.Fatal("expected missing key")
+	}
+	if !s.Set("key", []byte("value"), 0, false, false) {
+		t.Fatal("expected key to be set")
+	}
+	if value, ok := s.Get("key"); !ok || string(value) != "value" {
+		t.Errorf("expected value, got %q (ok=%v)", value, ok)
+	}
+
+	if s.Set("key", []byte("other"), 0, true, false) {
+		t.Error("expected NX to skip an existing key")
+	}
+	if s.Set("missing", []byte("other"), 0, false, true) {
+		t.Error("expected XX to skip a missing key")
+	}
+	if !s.Set("key", []byte("other"), 0, false, true) {
+		t.Error("expected XX to set an existing key")
+	}
+	if value, _ := s.Get("key"); string(value) != "other" {
+		t.Errorf("expected other, got %q", value)
+	}
+}
+
+func TestStoreExpiry(t *testing.T) {
+	s := NewStore(nil)
+	s.Set("short", []byte("v"), 20*time.Millisecond, false, false)
+	s.Set("forever", []byte("v"), 0, false, false)
+
+	if ttl, ok := s.TTL("short"); !ok || ttl <= 0 {
+		t.Errorf("expected a positive TTL, got %v (ok=%v)", ttl, ok)
+	}
+	if ttl, ok := s.TTL("forever"); !ok || ttl != -1 {
+		t.Errorf("expected -1 without expiry, got %v (ok=%v)", ttl, ok)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if _, ok := s.Get("short"); ok {
+		t.Error("expected expired key to be gone")
+	}
+	if _, ok := s.TTL("short"); ok {
+		t.Error("expected no TTL for an expired key")
+	}
+	if n := s.Len(); n != 1 {
+		t.Errorf("expected 1 key, got %d", n)
+	}
+}
+
+func TestStoreExpiryClock(t *testing.T) {
+	clk := clock.New(clock.Config{})
+	s := NewStore(clk)
+	s.Set("session", []byte("v"), time.Hour, false, false)
+
+	clk.Advance(59 * time.Minute)
+	if ttl, ok := s.TTL("session"); !ok || ttl > time.Minute {
+		t.Errorf("expected about a minute left, got %v (ok=%v)", ttl, ok)
+	}
+	clk.Advance(time.Minute)
+	if _, ok := s.Get("session"); ok {
+		t.Error("expected the key to expire once the clock passed its TTL")
+	}
+}
+
+func TestStoreDeleteExists(t *testing.T) {
+	s := NewStore(nil)
+	s.Set("a", []byte("1"), 0, false, false)
+	s.Set("b", []byte("2"), 0, false, false)
+
+	if n := s.Exists("a", "a", "c"); n != 2 {
+		t.Errorf("expected 2 (repeated keys count twice), got %d", n)
+	}
+	if n := s.Delete("a", "c"); n != 1 {
+		t.Errorf("expected 1 deleted, got %d", n)
+	}
+	s.Flush()
+	if n := s.Len(); n != 0 {
+		t.Errorf("expected empty store after flush, got %d", n)
+	}
+}
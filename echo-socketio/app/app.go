@@ -0,0 +1,196 @@
+// Package app runs echo-socketio: LoadConfig reads its settings and Run
+// serves it until its context ends, for the echo-socketio binary and echo-
+// all.
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/probitas-test/echo-servers/echo-socketio/docs"
+	"github.com/probitas-test/echo-servers/echo-socketio/engineio"
+	"github.com/probitas-test/echo-servers/echo-socketio/socketio"
+	"github.com/probitas-test/echo-servers/shared/admin"
+	"github.com/probitas-test/echo-servers/shared/bandwidth"
+	"github.com/probitas-test/echo-servers/shared/connlimit"
+	"github.com/probitas-test/echo-servers/shared/lifecycle"
+	"github.com/probitas-test/echo-servers/shared/logging"
+	"github.com/probitas-test/echo-servers/shared/network"
+	"github.com/probitas-test/echo-servers/shared/tracing"
+	"github.com/probitas-test/echo-servers/shared/version"
+)
+
+// Run serves echo-socketio with cfg until ctx is done, then shuts it down
+// gracefully. Logging and the address family are set up by the caller, as
+// they are shared by the servers of a process.
+func Run(ctx context.Context, cfg *Config) error {
+	// Started servers stop when Run returns early with an error
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Simulated slow startup: no port opens before the startup delay
+	if err := cfg.Lifecycle.Validate(); err != nil {
+		return fmt.Errorf("invalid lifecycle configuration: %w", err)
+	}
+	log.Printf("Lifecycle: %s", cfg.Lifecycle)
+	lifecycle.Delay(ctx, cfg.Lifecycle)
+
+	// OpenTelemetry tracing and W3C trace context propagation
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		return fmt.Errorf("failed to set up tracing: %w", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Error("Tracing shutdown error", "error", err)
+		}
+	}()
+	log.Printf("OpenTelemetry tracing: %s", cfg.Tracing)
+
+	if !strings.HasPrefix(cfg.Path, "/") {
+		return fmt.Errorf("invalid SOCKETIO_PATH %q (must start with /)", cfg.Path)
+	}
+	if cfg.PingInterval <= 0 || cfg.PingTimeout <= 0 {
+		return fmt.Errorf("invalid PING_INTERVAL_MS / PING_TIMEOUT_MS %d / %d (must be > 0)",
+			cfg.PingInterval.Milliseconds(), cfg.PingTimeout.Milliseconds())
+	}
+	if cfg.MaxPayload <= 0 {
+		return fmt.Errorf("invalid MAX_PAYLOAD %d (must be > 0)", cfg.MaxPayload)
+	}
+
+	server := socketio.New(socketio.Config{
+		AuthToken: cfg.AuthToken,
+	}, engineio.Config{
+		PingInterval: cfg.PingInterval,
+		PingTimeout:  cfg.PingTimeout,
+		MaxPayload:   cfg.MaxPayload,
+	})
+
+	// Admin API: health, request delay and rooms
+	adm := admin.New("echo-socketio", cfg.src)
+	adm.State("rooms", func() any { return server.Rooms() })
+
+	mux := http.NewServeMux()
+
+	// Socket.IO endpoint (with and without trailing slash)
+	path := strings.TrimSuffix(cfg.Path, "/")
+	mux.Handle(path+"/", server)
+	if path != "" {
+		mux.Handle(path, server)
+	}
+
+	// Room listing
+	mux.HandleFunc("GET /rooms", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"rooms": server.Rooms()})
+	})
+
+	// Health check endpoint
+	mux.Handle("GET /health", adm.HealthHandler())
+
+	// Effective configuration (secrets redacted)
+	mux.Handle("GET /config", cfg.src)
+
+	// Address family of the client connection
+	mux.Handle("GET "+network.Path, network.Handler())
+
+	// Build information and enabled features
+	mux.Handle("GET "+version.Path, version.Handler(version.New("echo-socketio", version.Features{
+		"admin":   cfg.Admin.Enabled,
+		"debug":   cfg.Admin.Debug,
+		"tracing": cfg.Tracing.Enabled,
+	})))
+
+	// API documentation endpoint
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		_, _ = w.Write([]byte(docs.API))
+	})
+
+	// Bandwidth shaping of every Socket.IO connection, changed at runtime on
+	// the admin port
+	shaper, err := bandwidth.New(cfg.Bandwidth)
+	if err != nil {
+		return err
+	}
+	log.Printf("Bandwidth: %s", cfg.Bandwidth)
+
+	// Cap on the open connections, changed at runtime on the admin port; the
+	// next ones get a server busy error
+	connLimiter, err := connlimit.New(cfg.ConnLimit)
+	if err != nil {
+		return err
+	}
+	log.Printf("Connection limit: %s", cfg.ConnLimit)
+
+	// Admin API on a separate port, with the bandwidth and connection limit
+	// admin APIs
+	var adminServer *http.Server
+	if cfg.Admin.Enabled {
+		adm.Handle(bandwidth.Path, bandwidth.Handler(shaper))
+		adm.Handle(connlimit.Path, connlimit.Handler(connLimiter))
+		if cfg.Admin.Debug {
+			adm.Debug()
+		}
+		adminServer, err = adm.Serve(cfg.AdminAddr())
+		if err != nil {
+			return err
+		}
+		defer func() { _ = adminServer.Close() }()
+	}
+
+	// Readiness delay and simulated crash, counted from here
+	lifecycle.Start(ctx, cfg.Lifecycle, adm)
+
+	srv := &http.Server{
+		Addr:              cfg.Addr(),
+		Handler:           tracing.HTTP(nil)(logging.HTTP(adm.HTTP(mux))),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	// Graceful shutdown (sessions are closed so that pending polls return
+	// and WebSocket connections do not hold up the shutdown)
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		<-ctx.Done()
+
+		log.Println("Shutting down server...")
+		log.Printf("Closed %d Socket.IO sessions", server.Close())
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := srv.Shutdown(ctx); err != nil {
+			slog.Error("Server shutdown error", "error", err)
+		}
+		if adminServer != nil {
+			_ = adminServer.Shutdown(ctx)
+		}
+	}()
+
+	log.Printf("Socket.IO path: %s (ping interval %v, timeout %v)", path+"/", cfg.PingInterval, cfg.PingTimeout)
+	if cfg.AuthToken != "" {
+		log.Println("Namespace connections require an auth token")
+	}
+	listener, err := network.Listen(cfg.Addr())
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	log.Printf("Starting server on %s", cfg.Addr())
+	if err := srv.Serve(shaper.Listen(connLimiter.Listen(listener, connlimit.HTTP))); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve: %w", err)
+	}
+	<-stopped
+
+	log.Println("Server stopped")
+	return nil
+}
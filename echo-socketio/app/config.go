@@ -0,0 +1,99 @@
+package app
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/probitas-test/echo-servers/shared/admin"
+	"github.com/probitas-test/echo-servers/shared/bandwidth"
+	"github.com/probitas-test/echo-servers/shared/config"
+	"github.com/probitas-test/echo-servers/shared/connlimit"
+	"github.com/probitas-test/echo-servers/shared/lifecycle"
+	"github.com/probitas-test/echo-servers/shared/logging"
+	"github.com/probitas-test/echo-servers/shared/network"
+	"github.com/probitas-test/echo-servers/shared/tracing"
+)
+
+type Config struct {
+	Host string
+	Port string
+
+	// Path of the Engine.IO endpoint (the "path" option of clients)
+	Path string
+
+	// Heartbeat sent to clients in the handshake: the server pings every
+	// PingInterval and closes sessions not answering within PingTimeout
+	PingInterval time.Duration
+	PingTimeout  time.Duration
+
+	// Largest polling payload or WebSocket message accepted, in bytes
+	MaxPayload int
+
+	// Token required in the auth payload of namespace connections (empty =
+	// no authentication)
+	AuthToken string
+
+	// Admin API on a separate port (ADMIN_ENABLED, ADMIN_PORT)
+	Admin admin.Config
+
+	// Bandwidth shaping of accepted connections (BANDWIDTH_*), changed at
+	// runtime at /bandwidth on the admin port
+	Bandwidth bandwidth.Config
+
+	// Connection cap (CONN_LIMIT_*), changed at runtime at /connlimit on the
+	// admin port
+	ConnLimit connlimit.Config
+
+	// Address family of every listener (IP_FAMILY: dual, ipv4 or ipv6)
+	Network network.Config
+
+	// Simulated startup delay, readiness delay and crash (STARTUP_DELAY_MS, STARTUP_READY_DELAY_MS, CRASH_AFTER_MS, CRASH_EXIT_CODE)
+	Lifecycle lifecycle.Config
+
+	// Structured logging (LOG_LEVEL, LOG_FORMAT)
+	Logging logging.Config
+
+	// OpenTelemetry tracing (OTEL_* variables)
+	Tracing tracing.Config
+
+	// Effective configuration, served at /config
+	src *config.Source
+}
+
+// LoadConfig reads the settings of echo-socketio from src
+func LoadConfig(src *config.Source) (*Config, error) {
+	cfg := &Config{
+		Host: src.String("HOST", "0.0.0.0"),
+		Port: src.String("PORT", "8080"),
+
+		Path: src.String("SOCKETIO_PATH", "/socket.io/"),
+
+		PingInterval: time.Duration(src.Int("PING_INTERVAL_MS", 25000)) * time.Millisecond,
+		PingTimeout:  time.Duration(src.Int("PING_TIMEOUT_MS", 20000)) * time.Millisecond,
+		MaxPayload:   src.Int("MAX_PAYLOAD", 1000000),
+
+		AuthToken: src.Secret("AUTH_TOKEN", ""),
+
+		Admin:     admin.LoadConfig(src),
+		Tracing:   tracing.LoadConfig(src, "echo-socketio"),
+		Bandwidth: bandwidth.LoadConfig(src),
+		ConnLimit: connlimit.LoadConfig(src),
+		Network:   network.LoadConfig(src),
+		Lifecycle: lifecycle.LoadConfig(src),
+		Logging:   logging.LoadConfig(src),
+	}
+	cfg.src = src
+	if err := src.Err(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	return cfg, nil
+}
+
+func (c *Config) Addr() string {
+	return net.JoinHostPort(c.Host, c.Port)
+}
+
+func (c *Config) AdminAddr() string {
+	return net.JoinHostPort(c.Host, c.Admin.Port)
+}
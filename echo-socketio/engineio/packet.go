@@ -0,0 +1,85 @@
+package engineio
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+)
+
+// PacketType is the type of an Engine.IO packet
+type PacketType byte
+
+const (
+	Open    PacketType = '0'
+	Close   PacketType = '1'
+	Ping    PacketType = '2'
+	Pong    PacketType = '3'
+	Message PacketType = '4'
+	Upgrade PacketType = '5'
+	Noop    PacketType = '6'
+)
+
+// recordSeparator separates packets in a polling payload (Engine.IO v4)
+const recordSeparator = 0x1e
+
+// Packet is an Engine.IO packet. Binary packets are always messages.
+type Packet struct {
+	Type   PacketType
+	Data   []byte
+	Binary bool
+}
+
+var errInvalidPacket = errors.New("invalid packet")
+
+// encodeText encodes a packet for a polling payload, with binary data as
+// base64 after a "b"
+func (p Packet) encodeText() []byte {
+	if p.Binary {
+		b := make([]byte, 1+base64.StdEncoding.EncodedLen(len(p.Data)))
+		b[0] = 'b'
+		base64.StdEncoding.Encode(b[1:], p.Data)
+		return b
+	}
+	return append([]byte{byte(p.Type)}, p.Data...)
+}
+
+// decodeText decodes a packet of a polling payload or a WebSocket text frame
+func decodeText(b []byte) (Packet, error) {
+	if len(b) == 0 {
+		return Packet{}, errInvalidPacket
+	}
+	if b[0] == 'b' {
+		data, err := base64.StdEncoding.DecodeString(string(b[1:]))
+		if err != nil {
+			return Packet{}, errInvalidPacket
+		}
+		return Packet{Type: Message, Data: data, Binary: true}, nil
+	}
+	t := PacketType(b[0])
+	if t < Open || t > Noop {
+		return Packet{}, errInvalidPacket
+	}
+	return Packet{Type: t, Data: b[1:]}, nil
+}
+
+// encodePayload joins packets into a polling payload
+func encodePayload(packets []Packet) []byte {
+	encoded := make([][]byte, len(packets))
+	for i, p := range packets {
+		encoded[i] = p.encodeText()
+	}
+	return bytes.Join(encoded, []byte{recordSeparator})
+}
+
+// decodePayload splits a polling payload into packets
+func decodePayload(b []byte) ([]Packet, error) {
+	var packets []Packet
+	for _, part := range bytes.Split(b, []byte{recordSeparator}) {
+		p, err := decodeText(part)
+		if err != nil {
+			return nil, err
+		}
+		packets = append(packets, p)
+	}
+	return packets, nil
+}
@@ -0,0 +1,39 @@
+package engineio
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPayloadRoundTrip(t *testing.T) {
+	packets := []Packet{
+		{Type: Message, Data: []byte("hello")},
+		{Type: Message, Data: []byte{0, 1, 2}, Binary: true},
+		{Type: Ping},
+		{Type: Message, Data: []byte("€")},
+	}
+	payload := encodePayload(packets)
+	if !bytes.Equal(payload, []byte("4hello\x1ebAAEC\x1e2\x1e4€")) {
+		t.Fatalf("unexpected payload %q", payload)
+	}
+	got, err := decodePayload(payload)
+	if err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	if len(got) != len(packets) {
+		t.Fatalf("expected %d packets, got %d", len(packets), len(got))
+	}
+	for i, p := range packets {
+		if got[i].Type != p.Type || got[i].Binary != p.Binary || !bytes.Equal(got[i].Data, p.Data) {
+			t.Errorf("packet %d: expected %+v, got %+v", i, p, got[i])
+		}
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	for _, payload := range []string{"", "7", "4a\x1e", "b!!"} {
+		if _, err := decodePayload([]byte(payload)); err == nil {
+			t.Errorf("%q: expected an error", payload)
+		}
+	}
+}
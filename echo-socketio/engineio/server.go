@@ -0,0 +1,232 @@
+package engineio
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Config controls heartbeats and payload limits
+type Config struct {
+	// The server pings every PingInterval and closes sessions whose pong
+	// does not arrive within PingTimeout
+	PingInterval time.Duration
+	PingTimeout  time.Duration
+
+	// MaxPayload is the largest polling payload or WebSocket message
+	// accepted from clients, in bytes
+	MaxPayload int
+}
+
+// Error codes of failed requests (engine.io Server.errors)
+const (
+	errTransportUnknown    = 0
+	errUnknownSID          = 1
+	errBadHandshakeMethod  = 2
+	errBadRequest          = 3
+	errUnsupportedProtocol = 5
+)
+
+var errorMessages = map[int]string{
+	errTransportUnknown:    "Transport unknown",
+	errUnknownSID:          "Session ID unknown",
+	errBadHandshakeMethod:  "Bad handshake method",
+	errBadRequest:          "Bad request",
+	errUnsupportedProtocol: "Unsupported protocol version",
+}
+
+// Server serves Engine.IO v4 sessions over HTTP long-polling and WebSocket,
+// with upgrades from polling to WebSocket
+type Server struct {
+	cfg       Config
+	onConnect func(*Session)
+	upgrader  websocket.Upgrader
+
+	mu       sync.Mutex
+	sessions map[string]*Session
+}
+
+// NewServer creates a server calling onConnect for each new session, before
+// any packet of the session is handled
+func NewServer(cfg Config, onConnect func(*Session)) *Server {
+	return &Server{
+		cfg:       cfg,
+		onConnect: onConnect,
+		upgrader: websocket.Upgrader{
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+		sessions: make(map[string]*Session),
+	}
+}
+
+// ServeHTTP handles the Engine.IO requests of a session
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	setCORSHeaders(w, r)
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	q := r.URL.Query()
+	if q.Get("EIO") != "4" {
+		writeError(w, errUnsupportedProtocol)
+		return
+	}
+	transport := q.Get("transport")
+	if transport != "polling" && transport != "websocket" {
+		writeError(w, errTransportUnknown)
+		return
+	}
+
+	sid := q.Get("sid")
+	if sid == "" {
+		if r.Method != http.MethodGet {
+			writeError(w, errBadHandshakeMethod)
+			return
+		}
+		if transport == "websocket" {
+			s.handshakeWebSocket(w, r)
+		} else {
+			s.handshakePolling(w, r)
+		}
+		return
+	}
+
+	s.mu.Lock()
+	sess := s.sessions[sid]
+	s.mu.Unlock()
+	if sess == nil {
+		writeError(w, errUnknownSID)
+		return
+	}
+
+	switch {
+	case transport == "websocket":
+		if !websocket.IsWebSocketUpgrade(r) {
+			writeError(w, errBadRequest)
+			return
+		}
+		sess.upgrade(w, r)
+	case r.Method == http.MethodGet:
+		sess.poll(w, r)
+	case r.Method == http.MethodPost:
+		sess.receive(w, r)
+	default:
+		writeError(w, errBadRequest)
+	}
+}
+
+// Close closes every session and returns how many were open
+func (s *Server) Close() int {
+	s.mu.Lock()
+	sessions := make([]*Session, 0, len(s.sessions))
+	for _, sess := range s.sessions {
+		sessions = append(sessions, sess)
+	}
+	s.mu.Unlock()
+
+	for _, sess := range sessions {
+		sess.Close()
+	}
+	return len(sessions)
+}
+
+func (s *Server) handshakePolling(w http.ResponseWriter, r *http.Request) {
+	sess := s.newSession(r, nil)
+	s.onConnect(sess)
+	go sess.heartbeat()
+	writePayload(w, []Packet{sess.openPacket()})
+}
+
+func (s *Server) handshakeWebSocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	conn.SetReadLimit(int64(s.cfg.MaxPayload))
+
+	sess := s.newSession(r, conn)
+	if err := sess.Send(sess.openPacket()); err != nil {
+		return
+	}
+	s.onConnect(sess)
+	go sess.heartbeat()
+	sess.readWebSocket(conn)
+}
+
+// newSession registers a session, over WebSocket when ws is set and
+// polling otherwise
+func (s *Server) newSession(r *http.Request, ws *websocket.Conn) *Session {
+	sess := &Session{
+		ID:         newSessionID(),
+		RemoteAddr: r.RemoteAddr,
+		srv:        s,
+		upgradable: ws == nil,
+		ws:         ws,
+		wake:       make(chan struct{}, 1),
+		pong:       make(chan struct{}, 1),
+		done:       make(chan struct{}),
+	}
+	s.mu.Lock()
+	s.sessions[sess.ID] = sess
+	s.mu.Unlock()
+	return sess
+}
+
+func (s *Server) remove(sess *Session) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, sess.ID)
+}
+
+// handshake is the data of the open packet
+type handshake struct {
+	SID          string   `json:"sid"`
+	Upgrades     []string `json:"upgrades"`
+	PingInterval int64    `json:"pingInterval"`
+	PingTimeout  int64    `json:"pingTimeout"`
+	MaxPayload   int      `json:"maxPayload"`
+}
+
+func newSessionID() string {
+	b := make([]byte, 15)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// setCORSHeaders allows polling from any origin, with credentials
+func setCORSHeaders(w http.ResponseWriter, r *http.Request) {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		return
+	}
+	w.Header().Set("Access-Control-Allow-Origin", origin)
+	w.Header().Set("Access-Control-Allow-Credentials", "true")
+	w.Header().Add("Vary", "Origin")
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+		if headers := r.Header.Get("Access-Control-Request-Headers"); headers != "" {
+			w.Header().Set("Access-Control-Allow-Headers", headers)
+		}
+	}
+}
+
+func writeError(w http.ResponseWriter, code int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	_ = json.NewEncoder(w).Encode(map[string]any{"code": code, "message": errorMessages[code]})
+}
+
+func writePayload(w http.ResponseWriter, packets []Packet) {
+	payload := encodePayload(packets)
+	w.Header().Set("Content-Type", "text/plain; charset=UTF-8")
+	w.Header().Set("Content-Length", strconv.Itoa(len(payload)))
+	_, _ = w.Write(payload)
+}
@@ -0,0 +1,201 @@
+package engineio
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// startServer serves an echo server: every message is sent back
+func startServer(t *testing.T, cfg Config) string {
+	t.Helper()
+	if cfg.PingInterval == 0 {
+		cfg.PingInterval = time.Minute
+		cfg.PingTimeout = time.Minute
+	}
+	if cfg.MaxPayload == 0 {
+		cfg.MaxPayload = 1000
+	}
+	s := NewServer(cfg, func(sess *Session) {
+		sess.OnMessage(func(p Packet) { _ = sess.Send(p) })
+	})
+	server := httptest.NewServer(s)
+	t.Cleanup(func() {
+		s.Close()
+		server.Close()
+	})
+	return server.URL + "/engine.io/?EIO=4"
+}
+
+func request(t *testing.T, method, url, body string) (int, string) {
+	t.Helper()
+	req, _ := http.NewRequest(method, url, strings.NewReader(body))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s failed: %v", method, url, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	data, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(data)
+}
+
+// open performs a polling handshake
+func open(t *testing.T, url string) handshake {
+	t.Helper()
+	status, body := request(t, http.MethodGet, url+"&transport=polling", "")
+	if status != http.StatusOK || !strings.HasPrefix(body, "0") {
+		t.Fatalf("unexpected handshake %d %q", status, body)
+	}
+	var h handshake
+	if err := json.Unmarshal([]byte(body[1:]), &h); err != nil {
+		t.Fatalf("invalid handshake %q: %v", body, err)
+	}
+	return h
+}
+
+func TestPolling(t *testing.T) {
+	url := startServer(t, Config{})
+	h := open(t, url)
+	if h.SID == "" || len(h.Upgrades) != 1 || h.Upgrades[0] != "websocket" || h.MaxPayload != 1000 {
+		t.Fatalf("unexpected handshake %+v", h)
+	}
+	sessionURL := url + "&transport=polling&sid=" + h.SID
+
+	if status, body := request(t, http.MethodPost, sessionURL, "4hello\x1ebAQI="); status != http.StatusOK || body != "ok" {
+		t.Fatalf("unexpected POST response %d %q", status, body)
+	}
+	if status, body := request(t, http.MethodGet, sessionURL, ""); status != http.StatusOK || body != "4hello\x1ebAQI=" {
+		t.Errorf("unexpected poll %d %q", status, body)
+	}
+
+	// Closing the session releases the pending poll
+	done := make(chan string)
+	go func() {
+		_, body := request(t, http.MethodGet, sessionURL, "")
+		done <- body
+	}()
+	time.Sleep(50 * time.Millisecond)
+	request(t, http.MethodPost, sessionURL, "1")
+	select {
+	case body := <-done:
+		if body != "1" {
+			t.Errorf("expected close packet, got %q", body)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("poll not released")
+	}
+	if status, body := request(t, http.MethodGet, sessionURL, ""); status != http.StatusBadRequest || !strings.Contains(body, "Session ID unknown") {
+		t.Errorf("expected unknown session, got %d %q", status, body)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	url := startServer(t, Config{})
+	base := strings.TrimSuffix(url, "?EIO=4")
+	tests := []struct {
+		method string
+		url    string
+		body   string
+		code   int
+		status int
+	}{
+		{http.MethodGet, base + "?EIO=3&transport=polling", "", errUnsupportedProtocol, http.StatusBadRequest},
+		{http.MethodGet, url + "&transport=flash", "", errTransportUnknown, http.StatusBadRequest},
+		{http.MethodPost, url + "&transport=polling", "", errBadHandshakeMethod, http.StatusBadRequest},
+		{http.MethodGet, url + "&transport=polling&sid=nope", "", errUnknownSID, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		status, body := request(t, tt.method, tt.url, tt.body)
+		var e struct {
+			Code int `json:"code"`
+		}
+		_ = json.Unmarshal([]byte(body), &e)
+		if status != tt.status || e.Code != tt.code {
+			t.Errorf("%s %s: expected %d code %d, got %d %q", tt.method, tt.url, tt.status, tt.code, status, body)
+		}
+	}
+
+	// Payloads over MaxPayload close the session
+	h := open(t, url)
+	sessionURL := url + "&transport=polling&sid=" + h.SID
+	if status, _ := request(t, http.MethodPost, sessionURL, "4"+strings.Repeat("x", 1000)); status != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected 413, got %d", status)
+	}
+	if status, _ := request(t, http.MethodGet, sessionURL, ""); status != http.StatusBadRequest {
+		t.Errorf("expected closed session, got %d", status)
+	}
+}
+
+func TestUpgrade(t *testing.T) {
+	url := startServer(t, Config{})
+	h := open(t, url)
+	sessionURL := url + "&transport=polling&sid=" + h.SID
+
+	// A message queued before the upgrade is delivered over WebSocket
+	request(t, http.MethodPost, sessionURL, "4queued")
+
+	wsURL := "ws" + strings.TrimPrefix(url, "http") + "&transport=websocket&sid=" + h.SID
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	_ = conn.WriteMessage(websocket.TextMessage, []byte("2probe"))
+	if _, data, err := conn.ReadMessage(); err != nil || string(data) != "3probe" {
+		t.Fatalf("expected 3probe, got %q (%v)", data, err)
+	}
+	// The pending poll gets the queued message and the noop
+	if _, body := request(t, http.MethodGet, sessionURL, ""); body != "4queued\x1e6" {
+		t.Errorf("unexpected poll %q", body)
+	}
+	_ = conn.WriteMessage(websocket.TextMessage, []byte("5"))
+
+	_ = conn.WriteMessage(websocket.TextMessage, []byte("4over ws"))
+	if _, data, err := conn.ReadMessage(); err != nil || string(data) != "4over ws" {
+		t.Errorf("expected echo, got %q (%v)", data, err)
+	}
+	_ = conn.WriteMessage(websocket.BinaryMessage, []byte{1, 2})
+	if messageType, data, err := conn.ReadMessage(); err != nil || messageType != websocket.BinaryMessage || len(data) != 2 {
+		t.Errorf("expected binary echo, got %d %v (%v)", messageType, data, err)
+	}
+}
+
+func TestWebSocketHeartbeat(t *testing.T) {
+	url := startServer(t, Config{PingInterval: 50 * time.Millisecond, PingTimeout: 50 * time.Millisecond})
+	wsURL := "ws" + strings.TrimPrefix(url, "http") + "&transport=websocket"
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	_, data, err := conn.ReadMessage()
+	if err != nil || !strings.HasPrefix(string(data), "0") || !strings.Contains(string(data), `"upgrades":[]`) {
+		t.Fatalf("unexpected open packet %q (%v)", data, err)
+	}
+
+	// Answered pings keep the session open
+	for range 3 {
+		if _, data, err := conn.ReadMessage(); err != nil || string(data) != "2" {
+			t.Fatalf("expected ping, got %q (%v)", data, err)
+		}
+		_ = conn.WriteMessage(websocket.TextMessage, []byte("3"))
+	}
+
+	// A missing pong closes it
+	if _, data, err := conn.ReadMessage(); err != nil || string(data) != "2" {
+		t.Fatalf("expected ping, got %q (%v)", data, err)
+	}
+	if _, _, err := conn.ReadMessage(); err == nil {
+		t.Error("expected the connection to close")
+	}
+}
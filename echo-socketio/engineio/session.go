@@ -0,0 +1,377 @@
+package engineio
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// writeTimeout bounds WebSocket writes to slow clients
+const writeTimeout = 10 * time.Second
+
+// ErrSessionClosed is returned when sending to a closed session
+var ErrSessionClosed = errors.New("session closed")
+
+// Session is an Engine.IO session. Packets are queued for the next poll
+// until the session is upgraded to WebSocket, then written directly.
+type Session struct {
+	ID         string
+	RemoteAddr string
+
+	srv        *Server
+	upgradable bool
+
+	// Handlers set by the connect callback. Messages are delivered in order,
+	// from the goroutine reading the transport.
+	onMessage func(Packet)
+	onClose   func(reason string)
+
+	mu      sync.Mutex
+	queue   []Packet
+	polling bool
+	ws      *websocket.Conn
+	probe   *websocket.Conn
+	closed  bool
+
+	wake chan struct{}
+	pong chan struct{}
+	done chan struct{}
+}
+
+// OnMessage sets the handler of message packets
+func (s *Session) OnMessage(fn func(Packet)) {
+	s.onMessage = fn
+}
+
+// OnClose sets the handler called once when the session closes
+func (s *Session) OnClose(fn func(reason string)) {
+	s.onClose = fn
+}
+
+// Send sends message packets
+func (s *Session) Send(packets ...Packet) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrSessionClosed
+	}
+	if s.ws != nil {
+		for _, p := range packets {
+			if err := s.writeWebSocket(s.ws, p); err != nil {
+				go s.close("transport error", false)
+				return err
+			}
+		}
+		return nil
+	}
+	s.queue = append(s.queue, packets...)
+	s.notify()
+	return nil
+}
+
+// Close closes the session from the server side
+func (s *Session) Close() {
+	s.close("forced server close", true)
+}
+
+// Done is closed when the session closes
+func (s *Session) Done() <-chan struct{} {
+	return s.done
+}
+
+// close ends the session; with sendClose the client is sent a close packet
+// (on the pending poll or over WebSocket) so that it does not reconnect
+// as if the transport failed
+func (s *Session) close(reason string, sendClose bool) {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	close(s.done)
+	ws, probe := s.ws, s.probe
+	if ws != nil && sendClose {
+		_ = s.writeWebSocket(ws, Packet{Type: Close})
+	}
+	s.mu.Unlock()
+
+	if ws != nil {
+		_ = ws.Close()
+	}
+	if probe != nil {
+		_ = probe.Close()
+	}
+	s.srv.remove(s)
+	if s.onClose != nil {
+		s.onClose(reason)
+	}
+}
+
+// notify wakes the pending poll. s.mu must be held.
+func (s *Session) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Session) openPacket() Packet {
+	upgrades := []string{}
+	if s.upgradable {
+		upgrades = append(upgrades, "websocket")
+	}
+	data, _ := json.Marshal(handshake{
+		SID:          s.ID,
+		Upgrades:     upgrades,
+		PingInterval: s.srv.cfg.PingInterval.Milliseconds(),
+		PingTimeout:  s.srv.cfg.PingTimeout.Milliseconds(),
+		MaxPayload:   s.srv.cfg.MaxPayload,
+	})
+	return Packet{Type: Open, Data: data}
+}
+
+// heartbeat pings the client and closes the session when a pong is missing
+func (s *Session) heartbeat() {
+	timer := time.NewTimer(s.srv.cfg.PingInterval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+		case <-s.done:
+			return
+		}
+		select {
+		case <-s.pong:
+		default:
+		}
+		if err := s.Send(Packet{Type: Ping}); err != nil {
+			return
+		}
+
+		timer.Reset(s.srv.cfg.PingTimeout)
+		select {
+		case <-s.pong:
+			if !timer.Stop() {
+				<-timer.C
+			}
+			timer.Reset(s.srv.cfg.PingInterval)
+		case <-timer.C:
+			s.close("ping timeout", false)
+			return
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// handle dispatches a packet received from the client
+func (s *Session) handle(p Packet) {
+	switch p.Type {
+	case Pong:
+		select {
+		case s.pong <- struct{}{}:
+		default:
+		}
+	case Ping:
+		_ = s.Send(Packet{Type: Pong, Data: p.Data})
+	case Message:
+		if s.onMessage != nil {
+			s.onMessage(p)
+		}
+	case Close:
+		s.close("transport close", false)
+	}
+}
+
+// poll answers a long-polling GET with the queued packets, waiting until
+// there is at least one
+func (s *Session) poll(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	if s.polling {
+		// Overlapping polls are a protocol violation
+		s.mu.Unlock()
+		s.close("transport error", false)
+		writeError(w, errBadRequest)
+		return
+	}
+	s.polling = true
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.polling = false
+		s.mu.Unlock()
+	}()
+
+	for {
+		s.mu.Lock()
+		switch {
+		case s.ws != nil:
+			// Upgraded: release the poll the client left pending
+			s.mu.Unlock()
+			writePayload(w, []Packet{{Type: Noop}})
+			return
+		case len(s.queue) > 0:
+			packets := s.queue
+			s.queue = nil
+			s.mu.Unlock()
+			writePayload(w, packets)
+			return
+		case s.closed:
+			s.mu.Unlock()
+			writePayload(w, []Packet{{Type: Close}})
+			return
+		}
+		s.mu.Unlock()
+
+		select {
+		case <-s.wake:
+		case <-s.done:
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// receive handles the packets of a polling POST
+func (s *Session) receive(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, int64(s.srv.cfg.MaxPayload)))
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			s.close("transport error", false)
+			http.Error(w, "Payload too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		writeError(w, errBadRequest)
+		return
+	}
+	packets, err := decodePayload(body)
+	if err != nil {
+		s.close("parse error", false)
+		writeError(w, errBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	_, _ = w.Write([]byte("ok"))
+	for _, p := range packets {
+		s.handle(p)
+	}
+}
+
+// upgrade switches a polling session to WebSocket: the client probes the
+// connection ("2probe" / "3probe") and confirms with an upgrade packet
+func (s *Session) upgrade(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	if !s.upgradable || s.ws != nil || s.probe != nil || s.closed {
+		s.mu.Unlock()
+		writeError(w, errBadRequest)
+		return
+	}
+	s.mu.Unlock()
+
+	conn, err := s.srv.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	conn.SetReadLimit(int64(s.srv.cfg.MaxPayload))
+	s.mu.Lock()
+	if s.closed || s.probe != nil {
+		s.mu.Unlock()
+		_ = conn.Close()
+		return
+	}
+	s.probe = conn
+	s.mu.Unlock()
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			s.abortUpgrade(conn)
+			return
+		}
+		p, err := decodeText(data)
+		if err != nil {
+			s.abortUpgrade(conn)
+			return
+		}
+		switch {
+		case p.Type == Ping && string(p.Data) == "probe":
+			if err := s.writeWebSocket(conn, Packet{Type: Pong, Data: p.Data}); err != nil {
+				s.abortUpgrade(conn)
+				return
+			}
+			// Release the pending poll so that the client can pause polling
+			_ = s.Send(Packet{Type: Noop})
+		case p.Type == Upgrade:
+			s.mu.Lock()
+			if s.closed {
+				s.mu.Unlock()
+				return
+			}
+			s.probe = nil
+			s.ws = conn
+			queue := s.queue
+			s.queue = nil
+			for _, p := range queue {
+				if err := s.writeWebSocket(conn, p); err != nil {
+					break
+				}
+			}
+			s.notify()
+			s.mu.Unlock()
+			s.readWebSocket(conn)
+			return
+		default:
+			s.abortUpgrade(conn)
+			return
+		}
+	}
+}
+
+// abortUpgrade drops a failed probe; the session goes on polling
+func (s *Session) abortUpgrade(conn *websocket.Conn) {
+	s.mu.Lock()
+	if s.probe == conn {
+		s.probe = nil
+	}
+	s.mu.Unlock()
+	_ = conn.Close()
+}
+
+// readWebSocket handles the packets received over WebSocket until the
+// connection fails
+func (s *Session) readWebSocket(conn *websocket.Conn) {
+	for {
+		messageType, data, err := conn.ReadMessage()
+		if err != nil {
+			s.close("transport close", false)
+			return
+		}
+		if messageType == websocket.BinaryMessage {
+			s.handle(Packet{Type: Message, Data: data, Binary: true})
+			continue
+		}
+		p, err := decodeText(data)
+		if err != nil {
+			s.close("parse error", false)
+			return
+		}
+		s.handle(p)
+	}
+}
+
+// writeWebSocket writes a packet, binary messages as binary frames
+func (s *Session) writeWebSocket(conn *websocket.Conn, p Packet) error {
+	_ = conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if p.Binary {
+		return conn.WriteMessage(websocket.BinaryMessage, p.Data)
+	}
+	return conn.WriteMessage(websocket.TextMessage, p.encodeText())
+}
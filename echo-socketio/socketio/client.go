@@ -0,0 +1,131 @@
+package socketio
+
+import (
+	"sync"
+
+	"github.com/probitas-test/echo-servers/echo-socketio/engineio"
+)
+
+// client is the Socket.IO side of an Engine.IO session: its namespace
+// sockets and the binary packet being received
+type client struct {
+	srv     *Server
+	session *engineio.Session
+
+	mu      sync.Mutex
+	sockets map[string]*socket
+
+	// pending is a binary packet waiting for its attachments
+	pending   *Packet
+	remaining int
+}
+
+// send writes a packet followed by its attachments
+func (c *client) send(p *Packet) error {
+	packets := make([]engineio.Packet, 0, 1+len(p.Attachments))
+	packets = append(packets, engineio.Packet{Type: engineio.Message, Data: p.encode()})
+	for _, a := range p.Attachments {
+		packets = append(packets, engineio.Packet{Type: engineio.Message, Data: a, Binary: true})
+	}
+	return c.session.Send(packets...)
+}
+
+// onMessage decodes packets and their binary attachments. Malformed
+// packets close the session, as the reference server does.
+func (c *client) onMessage(m engineio.Packet) {
+	if c.pending != nil {
+		if !m.Binary {
+			c.session.Close()
+			return
+		}
+		c.pending.Attachments = append(c.pending.Attachments, m.Data)
+		c.remaining--
+		if c.remaining == 0 {
+			p := c.pending
+			c.pending = nil
+			c.dispatch(p)
+		}
+		return
+	}
+	if m.Binary {
+		c.session.Close()
+		return
+	}
+
+	p, attachments, err := decode(m.Data)
+	if err != nil {
+		c.session.Close()
+		return
+	}
+	if attachments > 0 {
+		c.pending, c.remaining = p, attachments
+		return
+	}
+	c.dispatch(p)
+}
+
+// dispatch handles a complete packet
+func (c *client) dispatch(p *Packet) {
+	if p.Type == Connect {
+		c.connect(p)
+		return
+	}
+
+	c.mu.Lock()
+	sock := c.sockets[p.Namespace]
+	c.mu.Unlock()
+	if sock == nil {
+		// Packets for namespaces that are not connected are ignored
+		return
+	}
+	if p.Type == Disconnect {
+		c.removeSocket(sock)
+		return
+	}
+	sock.handle(p)
+}
+
+// connect joins a namespace, answering with its socket ID or a connect error
+func (c *client) connect(p *Packet) {
+	if reason := c.srv.authorize(p.Namespace, p.Data); reason != nil {
+		_ = c.send(&Packet{Type: ConnectError, Namespace: p.Namespace, Data: jsonArgs(reason)[0]})
+		return
+	}
+
+	sock := &socket{
+		id:        newSocketID(),
+		namespace: p.Namespace,
+		client:    c,
+		rooms:     make(map[string]struct{}),
+		acks:      make(map[int]string),
+	}
+	c.mu.Lock()
+	old := c.sockets[p.Namespace]
+	c.sockets[p.Namespace] = sock
+	c.mu.Unlock()
+	if old != nil {
+		c.srv.leaveAll(old)
+	}
+	_ = c.send(&Packet{Type: Connect, Namespace: p.Namespace, Data: jsonArgs(map[string]string{"sid": sock.id})[0]})
+}
+
+// removeSocket disconnects a socket from its namespace
+func (c *client) removeSocket(sock *socket) {
+	c.mu.Lock()
+	if c.sockets[sock.namespace] == sock {
+		delete(c.sockets, sock.namespace)
+	}
+	c.mu.Unlock()
+	c.srv.leaveAll(sock)
+}
+
+// onClose disconnects every socket of the session
+func (c *client) onClose(string) {
+	c.mu.Lock()
+	sockets := c.sockets
+	c.sockets = make(map[string]*socket)
+	c.mu.Unlock()
+	for _, sock := range sockets {
+		c.srv.leaveAll(sock)
+	}
+}
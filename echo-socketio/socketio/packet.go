@@ -0,0 +1,172 @@
+package socketio
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strconv"
+)
+
+// PacketType is the type of a Socket.IO packet
+type PacketType int
+
+const (
+	Connect PacketType = iota
+	Disconnect
+	Event
+	Ack
+	ConnectError
+	BinaryEvent
+	BinaryAck
+)
+
+// Packet is a Socket.IO v5 packet. Binary packets carry their binary
+// arguments as attachments, referenced from Data by placeholders
+// ({"_placeholder":true,"num":n}); they are echoed without being replaced.
+type Packet struct {
+	Type        PacketType
+	Namespace   string
+	ID          *int
+	Data        json.RawMessage
+	Attachments [][]byte
+}
+
+var errInvalidPacket = errors.New("invalid packet")
+
+// encode returns the text part of the packet:
+// <type>[<attachments>-][<namespace>,][<id>][<data>]
+func (p *Packet) encode() []byte {
+	b := []byte{byte('0' + p.Type)}
+	if p.Type == BinaryEvent || p.Type == BinaryAck {
+		b = strconv.AppendInt(b, int64(len(p.Attachments)), 10)
+		b = append(b, '-')
+	}
+	if p.Namespace != "" && p.Namespace != "/" {
+		b = append(b, p.Namespace...)
+		b = append(b, ',')
+	}
+	if p.ID != nil {
+		b = strconv.AppendInt(b, int64(*p.ID), 10)
+	}
+	return append(b, p.Data...)
+}
+
+// decode parses the text part of a packet and returns it with the number
+// of binary attachments that follow
+func decode(b []byte) (*Packet, int, error) {
+	if len(b) == 0 || b[0] < '0' || b[0] > '6' {
+		return nil, 0, errInvalidPacket
+	}
+	p := &Packet{Type: PacketType(b[0] - '0'), Namespace: "/"}
+	b = b[1:]
+
+	attachments := 0
+	if p.Type == BinaryEvent || p.Type == BinaryAck {
+		i := bytes.IndexByte(b, '-')
+		if i < 1 {
+			return nil, 0, errInvalidPacket
+		}
+		n, err := strconv.Atoi(string(b[:i]))
+		if err != nil || n < 0 {
+			return nil, 0, errInvalidPacket
+		}
+		attachments = n
+		b = b[i+1:]
+	}
+
+	if len(b) > 0 && b[0] == '/' {
+		i := bytes.IndexByte(b, ',')
+		if i < 0 {
+			i = len(b)
+		}
+		p.Namespace = string(b[:i])
+		b = b[min(i+1, len(b)):]
+	}
+
+	i := 0
+	for i < len(b) && b[i] >= '0' && b[i] <= '9' {
+		i++
+	}
+	if i > 0 {
+		id, err := strconv.Atoi(string(b[:i]))
+		if err != nil {
+			return nil, 0, errInvalidPacket
+		}
+		p.ID = &id
+		b = b[i:]
+	}
+
+	if len(b) > 0 {
+		if !json.Valid(b) {
+			return nil, 0, errInvalidPacket
+		}
+		p.Data = append(json.RawMessage(nil), b...)
+	}
+	if !p.valid() {
+		return nil, 0, errInvalidPacket
+	}
+	return p, attachments, nil
+}
+
+// valid checks the data of the packet type (socket.io-parser isPayloadValid)
+func (p *Packet) valid() bool {
+	switch p.Type {
+	case Connect:
+		return len(p.Data) == 0 || p.Data[0] == '{'
+	case Disconnect:
+		return len(p.Data) == 0
+	case ConnectError:
+		return len(p.Data) > 0 && (p.Data[0] == '{' || p.Data[0] == '"')
+	case Event, BinaryEvent:
+		var args []json.RawMessage
+		if json.Unmarshal(p.Data, &args) != nil || len(args) == 0 {
+			return false
+		}
+		var name string
+		return json.Unmarshal(args[0], &name) == nil
+	default:
+		var args []json.RawMessage
+		return p.ID != nil && json.Unmarshal(p.Data, &args) == nil
+	}
+}
+
+// args returns the elements of the data array
+func (p *Packet) args() []json.RawMessage {
+	var args []json.RawMessage
+	_ = json.Unmarshal(p.Data, &args)
+	return args
+}
+
+// newEvent builds an event packet from its name and arguments, binary when
+// there are attachments
+func newEvent(namespace, name string, args []json.RawMessage, attachments [][]byte) *Packet {
+	nameJSON, _ := json.Marshal(name)
+	data, _ := json.Marshal(append([]json.RawMessage{nameJSON}, args...))
+	p := &Packet{Type: Event, Namespace: namespace, Data: data, Attachments: attachments}
+	if len(attachments) > 0 {
+		p.Type = BinaryEvent
+	}
+	return p
+}
+
+// newAck builds an acknowledgement of the packet with the given ID
+func newAck(namespace string, id int, args []json.RawMessage, attachments [][]byte) *Packet {
+	if args == nil {
+		args = []json.RawMessage{}
+	}
+	data, _ := json.Marshal(args)
+	p := &Packet{Type: Ack, Namespace: namespace, ID: &id, Data: data, Attachments: attachments}
+	if len(attachments) > 0 {
+		p.Type = BinaryAck
+	}
+	return p
+}
+
+// jsonArgs marshals values as event or ack arguments
+func jsonArgs(values ...any) []json.RawMessage {
+	args := make([]json.RawMessage, len(values))
+	for i, v := range values {
+		args[i], _ = json.Marshal(v)
+	}
+	return args
+}
@@ -0,0 +1,48 @@
+package socketio
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPacketRoundTrip(t *testing.T) {
+	id := 12
+	tests := []struct {
+		packet  Packet
+		encoded string
+	}{
+		{Packet{Type: Connect, Namespace: "/"}, "0"},
+		{Packet{Type: Connect, Namespace: "/admin", Data: []byte(`{"token":"x"}`)}, `0/admin,{"token":"x"}`},
+		{Packet{Type: Disconnect, Namespace: "/admin"}, "1/admin,"},
+		{Packet{Type: Event, Namespace: "/", Data: []byte(`["hello",1]`)}, `2["hello",1]`},
+		{Packet{Type: Event, Namespace: "/chat", ID: &id, Data: []byte(`["hello"]`)}, `2/chat,12["hello"]`},
+		{Packet{Type: Ack, Namespace: "/", ID: &id, Data: []byte(`[]`)}, `312[]`},
+		{Packet{Type: ConnectError, Namespace: "/", Data: []byte(`{"message":"no"}`)}, `4{"message":"no"}`},
+		{
+			Packet{Type: BinaryEvent, Namespace: "/", Data: []byte(`["up",{"_placeholder":true,"num":0}]`), Attachments: [][]byte{{1}}},
+			`51-["up",{"_placeholder":true,"num":0}]`,
+		},
+	}
+	for _, tt := range tests {
+		if got := string(tt.packet.encode()); got != tt.encoded {
+			t.Errorf("expected %s, got %s", tt.encoded, got)
+		}
+		p, attachments, err := decode([]byte(tt.encoded))
+		if err != nil {
+			t.Errorf("%s: failed to decode: %v", tt.encoded, err)
+			continue
+		}
+		if p.Type != tt.packet.Type || p.Namespace != tt.packet.Namespace || !bytes.Equal(p.Data, tt.packet.Data) ||
+			(p.ID == nil) != (tt.packet.ID == nil) || attachments != len(tt.packet.Attachments) {
+			t.Errorf("%s: unexpected packet %+v with %d attachments", tt.encoded, p, attachments)
+		}
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	for _, s := range []string{"", "7", "2", "2[]", "2[1]", "2{}", "3[]", "1{}", "0[]", "4", "5-[\"a\"]", "2[\"a\""} {
+		if _, _, err := decode([]byte(s)); err == nil {
+			t.Errorf("%q: expected an error", s)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package socketio
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/probitas-test/echo-servers/echo-socketio/engineio"
+)
+
+// forbiddenNamespace prefixes the namespaces that refuse connections
+const forbiddenNamespace = "/forbidden"
+
+// Config controls namespace authentication
+type Config struct {
+	// AuthToken, when set, must be sent as {"token": ...} in the auth
+	// payload of every namespace connection
+	AuthToken string
+}
+
+// Server serves Socket.IO v5 on top of Engine.IO: every namespace accepts
+// connections and echoes events, with rooms shared by the sockets of a
+// namespace
+type Server struct {
+	cfg    Config
+	engine *engineio.Server
+
+	mu    sync.Mutex
+	rooms map[roomKey]map[*socket]struct{}
+}
+
+type roomKey struct {
+	namespace string
+	room      string
+}
+
+// RoomInfo describes a room listed by Rooms
+type RoomInfo struct {
+	Namespace string   `json:"namespace"`
+	Name      string   `json:"name"`
+	Members   []string `json:"members"`
+}
+
+// New creates a server using the Engine.IO settings of engineCfg
+func New(cfg Config, engineCfg engineio.Config) *Server {
+	s := &Server{
+		cfg:   cfg,
+		rooms: make(map[roomKey]map[*socket]struct{}),
+	}
+	s.engine = engineio.NewServer(engineCfg, s.connect)
+	return s
+}
+
+// ServeHTTP handles Engine.IO requests
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.engine.ServeHTTP(w, r)
+}
+
+// Close closes every session and returns how many were open
+func (s *Server) Close() int {
+	return s.engine.Close()
+}
+
+// Rooms lists the rooms with members, sorted by namespace and name
+func (s *Server) Rooms() []RoomInfo {
+	s.mu.Lock()
+	list := make([]RoomInfo, 0, len(s.rooms))
+	for key, members := range s.rooms {
+		info := RoomInfo{Namespace: key.namespace, Name: key.room, Members: make([]string, 0, len(members))}
+		for m := range members {
+			info.Members = append(info.Members, m.id)
+		}
+		sort.Strings(info.Members)
+		list = append(list, info)
+	}
+	s.mu.Unlock()
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Namespace != list[j].Namespace {
+			return list[i].Namespace < list[j].Namespace
+		}
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// connect sets up a client for a new Engine.IO session
+func (s *Server) connect(sess *engineio.Session) {
+	c := &client{
+		srv:     s,
+		session: sess,
+		sockets: make(map[string]*socket),
+	}
+	sess.OnMessage(c.onMessage)
+	sess.OnClose(c.onClose)
+}
+
+// authorize returns the connect error of a namespace connection, or nil
+func (s *Server) authorize(namespace string, auth json.RawMessage) map[string]any {
+	if namespace == forbiddenNamespace || strings.HasPrefix(namespace, forbiddenNamespace+"/") {
+		return map[string]any{"message": "Forbidden namespace", "data": map[string]string{"namespace": namespace}}
+	}
+	if s.cfg.AuthToken != "" {
+		var payload struct {
+			Token string `json:"token"`
+		}
+		_ = json.Unmarshal(auth, &payload)
+		if payload.Token != s.cfg.AuthToken {
+			return map[string]any{"message": "Unauthorized"}
+		}
+	}
+	return nil
+}
+
+// join adds a socket to a room and returns the number of members
+func (s *Server) join(sock *socket, room string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := roomKey{sock.namespace, room}
+	if s.rooms[key] == nil {
+		s.rooms[key] = make(map[*socket]struct{})
+	}
+	s.rooms[key][sock] = struct{}{}
+	sock.rooms[room] = struct{}{}
+	return len(s.rooms[key])
+}
+
+// leave removes a socket from a room and returns the number of members left
+func (s *Server) leave(sock *socket, room string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.remove(sock, room)
+}
+
+// leaveAll removes a socket from its rooms
+func (s *Server) leaveAll(sock *socket) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for room := range sock.rooms {
+		s.remove(sock, room)
+	}
+}
+
+// remove deletes a socket from a room, deleting the room once empty.
+// s.mu must be held.
+func (s *Server) remove(sock *socket, room string) int {
+	key := roomKey{sock.namespace, room}
+	delete(s.rooms[key], sock)
+	delete(sock.rooms, room)
+	n := len(s.rooms[key])
+	if n == 0 {
+		delete(s.rooms, key)
+	}
+	return n
+}
+
+// broadcast sends a packet to the members of a room and returns how many
+// received it
+func (s *Server) broadcast(namespace, room string, p *Packet) int {
+	s.mu.Lock()
+	members := make([]*socket, 0, len(s.rooms[roomKey{namespace, room}]))
+	for m := range s.rooms[roomKey{namespace, room}] {
+		members = append(members, m)
+	}
+	s.mu.Unlock()
+
+	delivered := 0
+	for _, m := range members {
+		if m.client.send(p) == nil {
+			delivered++
+		}
+	}
+	return delivered
+}
+
+func newSocketID() string {
+	b := make([]byte, 15)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
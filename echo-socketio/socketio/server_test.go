@@ -0,0 +1,231 @@
+package socketio
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/probitas-test/echo-servers/echo-socketio/engineio"
+)
+
+// startServer serves Socket.IO and returns the WebSocket URL of Engine.IO
+func startServer(t *testing.T, cfg Config) (*Server, string) {
+	t.Helper()
+	s := New(cfg, engineio.Config{PingInterval: time.Minute, PingTimeout: time.Minute, MaxPayload: 1 << 20})
+	server := httptest.NewServer(s)
+	t.Cleanup(func() {
+		s.Close()
+		server.Close()
+	})
+	return s, "ws" + strings.TrimPrefix(server.URL, "http") + "/socket.io/?EIO=4&transport=websocket"
+}
+
+// dial opens an Engine.IO session over WebSocket
+func dial(t *testing.T, url string) *websocket.Conn {
+	t.Helper()
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, data, err := conn.ReadMessage(); err != nil || data[0] != '0' {
+		t.Fatalf("expected open packet, got %q (%v)", data, err)
+	}
+	return conn
+}
+
+func send(t *testing.T, conn *websocket.Conn, packet string) {
+	t.Helper()
+	if err := conn.WriteMessage(websocket.TextMessage, []byte("4"+packet)); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+}
+
+// receive reads the next Socket.IO packet, skipping pings
+func receive(t *testing.T, conn *websocket.Conn) string {
+	t.Helper()
+	for {
+		messageType, data, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("read failed: %v", err)
+		}
+		if messageType == websocket.BinaryMessage {
+			return "binary:" + string(data)
+		}
+		if data[0] == '4' {
+			return string(data[1:])
+		}
+	}
+}
+
+// connect joins a namespace and returns the socket ID
+func connect(t *testing.T, conn *websocket.Conn, namespace string) string {
+	t.Helper()
+	prefix := ""
+	if namespace != "/" {
+		prefix = namespace + ","
+	}
+	send(t, conn, "0"+prefix)
+	packet := receive(t, conn)
+	var data struct {
+		SID string `json:"sid"`
+	}
+	if !strings.HasPrefix(packet, "0"+prefix) || json.Unmarshal([]byte(strings.TrimPrefix(packet, "0"+prefix)), &data) != nil || data.SID == "" {
+		t.Fatalf("unexpected connect response %q", packet)
+	}
+	return data.SID
+}
+
+func TestEcho(t *testing.T) {
+	_, url := startServer(t, Config{})
+	conn := dial(t, url)
+	connect(t, conn, "/")
+	connect(t, conn, "/chat")
+
+	send(t, conn, `2["hello","world",{"n":1}]`)
+	if got := receive(t, conn); got != `2["hello","world",{"n":1}]` {
+		t.Errorf("unexpected echo %s", got)
+	}
+	send(t, conn, `2/chat,["hello"]`)
+	if got := receive(t, conn); got != `2/chat,["hello"]` {
+		t.Errorf("unexpected echo %s", got)
+	}
+
+	// With an ack ID, the arguments come back as the acknowledgement
+	send(t, conn, `2/chat,7["hello",1,2]`)
+	if got := receive(t, conn); got != `3/chat,7[1,2]` {
+		t.Errorf("unexpected ack %s", got)
+	}
+}
+
+func TestBinary(t *testing.T) {
+	_, url := startServer(t, Config{})
+	conn := dial(t, url)
+	connect(t, conn, "/")
+
+	send(t, conn, `51-["upload",{"_placeholder":true,"num":0}]`)
+	_ = conn.WriteMessage(websocket.BinaryMessage, []byte("abc"))
+	if got := receive(t, conn); got != `51-["upload",{"_placeholder":true,"num":0}]` {
+		t.Errorf("unexpected echo %s", got)
+	}
+	if got := receive(t, conn); got != "binary:abc" {
+		t.Errorf("unexpected attachment %s", got)
+	}
+
+	send(t, conn, `51-3["upload",{"_placeholder":true,"num":0}]`)
+	_ = conn.WriteMessage(websocket.BinaryMessage, []byte("xyz"))
+	if got := receive(t, conn); got != `61-3[{"_placeholder":true,"num":0}]` {
+		t.Errorf("unexpected ack %s", got)
+	}
+	if got := receive(t, conn); got != "binary:xyz" {
+		t.Errorf("unexpected attachment %s", got)
+	}
+}
+
+func TestRooms(t *testing.T) {
+	s, url := startServer(t, Config{})
+	alice := dial(t, url)
+	bob := dial(t, url)
+	aliceID := connect(t, alice, "/")
+	connect(t, bob, "/")
+
+	send(t, alice, `21["join","lobby"]`)
+	if got := receive(t, alice); got != `31[{"members":1,"room":"lobby"}]` {
+		t.Fatalf("unexpected join ack %s", got)
+	}
+	send(t, bob, `21["join","lobby"]`)
+	if got := receive(t, bob); got != `31[{"members":2,"room":"lobby"}]` {
+		t.Fatalf("unexpected join ack %s", got)
+	}
+	send(t, alice, `22["rooms"]`)
+	if got := receive(t, alice); got != `32[["lobby"]]` {
+		t.Errorf("unexpected rooms ack %s", got)
+	}
+	if rooms := s.Rooms(); len(rooms) != 1 || rooms[0].Name != "lobby" || len(rooms[0].Members) != 2 {
+		t.Errorf("unexpected rooms %+v", rooms)
+	}
+
+	send(t, bob, `23["broadcast","lobby","news",{"from":"bob"}]`)
+	for name, conn := range map[string]*websocket.Conn{"alice": alice, "bob": bob} {
+		if got := receive(t, conn); got != `2["news",{"from":"bob"}]` {
+			t.Errorf("%s: unexpected broadcast %s", name, got)
+		}
+	}
+	if got := receive(t, bob); got != `33[{"delivered":2,"room":"lobby"}]` {
+		t.Errorf("unexpected broadcast ack %s", got)
+	}
+
+	// Closed sessions leave their rooms
+	_ = bob.Close()
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if rooms := s.Rooms(); len(rooms) == 1 && len(rooms[0].Members) == 1 {
+			if rooms[0].Members[0] != aliceID {
+				t.Errorf("expected alice in lobby, got %v", rooms[0].Members)
+			}
+			return
+		}
+	}
+	t.Errorf("bob still in lobby: %+v", s.Rooms())
+}
+
+func TestRequestAck(t *testing.T) {
+	_, url := startServer(t, Config{})
+	conn := dial(t, url)
+	connect(t, conn, "/")
+
+	send(t, conn, `2["request-ack","question",42]`)
+	got := receive(t, conn)
+	if !strings.HasPrefix(got, "2") || !strings.HasSuffix(got, `["question",42]`) {
+		t.Fatalf("unexpected event %s", got)
+	}
+	id := strings.TrimSuffix(strings.TrimPrefix(got, "2"), `["question",42]`)
+	send(t, conn, "3"+id+`["answer"]`)
+	if got := receive(t, conn); got != `2["ack-received","question","answer"]` {
+		t.Errorf("unexpected ack report %s", got)
+	}
+}
+
+func TestForceDisconnect(t *testing.T) {
+	_, url := startServer(t, Config{})
+	conn := dial(t, url)
+	connect(t, conn, "/chat")
+
+	send(t, conn, `2/chat,1["force-disconnect"]`)
+	if got := receive(t, conn); got != `3/chat,1[]` {
+		t.Errorf("unexpected ack %s", got)
+	}
+	if got := receive(t, conn); got != "1/chat," {
+		t.Errorf("expected disconnect, got %s", got)
+	}
+	// Events of a disconnected namespace are ignored
+	send(t, conn, `2/chat,["hello"]`)
+	connect(t, conn, "/chat")
+
+	send(t, conn, `2/chat,["force-disconnect",{"transport":true}]`)
+	if _, data, err := conn.ReadMessage(); err != nil || string(data) != "1" {
+		t.Errorf("expected close packet, got %q (%v)", data, err)
+	}
+}
+
+func TestConnectErrors(t *testing.T) {
+	_, url := startServer(t, Config{AuthToken: "secret"})
+	conn := dial(t, url)
+
+	send(t, conn, `0{"token":"wrong"}`)
+	if got := receive(t, conn); got != `4{"message":"Unauthorized"}` {
+		t.Errorf("unexpected connect error %s", got)
+	}
+	send(t, conn, `0/forbidden/x,{"token":"secret"}`)
+	if got := receive(t, conn); got != `4/forbidden/x,{"data":{"namespace":"/forbidden/x"},"message":"Forbidden namespace"}` {
+		t.Errorf("unexpected connect error %s", got)
+	}
+	send(t, conn, `0{"token":"secret"}`)
+	if got := receive(t, conn); !strings.HasPrefix(got, `0{"sid":`) {
+		t.Errorf("unexpected connect response %s", got)
+	}
+}
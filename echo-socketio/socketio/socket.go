@@ -0,0 +1,150 @@
+package socketio
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+)
+
+// maxDisconnectDelay bounds the delay of force-disconnect
+const maxDisconnectDelay = 60 * time.Second
+
+// socket is a client connected to a namespace
+type socket struct {
+	id        string
+	namespace string
+	client    *client
+
+	// rooms is guarded by the server mutex
+	rooms map[string]struct{}
+
+	mu      sync.Mutex
+	nextAck int
+	// acks holds the events sent with request-ack by acknowledgement ID
+	acks map[int]string
+}
+
+// handle answers events and acknowledgements
+func (s *socket) handle(p *Packet) {
+	switch p.Type {
+	case Event, BinaryEvent:
+		args := p.args()
+		var name string
+		_ = json.Unmarshal(args[0], &name)
+		s.event(p, name, args[1:])
+	case Ack, BinaryAck:
+		s.mu.Lock()
+		event, ok := s.acks[*p.ID]
+		delete(s.acks, *p.ID)
+		s.mu.Unlock()
+		if ok {
+			// Report the acknowledgement of a request-ack event
+			args := append(jsonArgs(event), p.args()...)
+			_ = s.client.send(newEvent(s.namespace, "ack-received", args, p.Attachments))
+		}
+	}
+}
+
+// event runs the built-in events; any other event is echoed
+func (s *socket) event(p *Packet, name string, args []json.RawMessage) {
+	switch name {
+	case "join":
+		room, ok := stringArg(args, 0)
+		if !ok {
+			s.ack(p, jsonArgs(map[string]string{"error": "room must be a string"}), nil)
+			return
+		}
+		members := s.client.srv.join(s, room)
+		s.ack(p, jsonArgs(map[string]any{"room": room, "members": members}), nil)
+	case "leave":
+		room, ok := stringArg(args, 0)
+		if !ok {
+			s.ack(p, jsonArgs(map[string]string{"error": "room must be a string"}), nil)
+			return
+		}
+		members := s.client.srv.leave(s, room)
+		s.ack(p, jsonArgs(map[string]any{"room": room, "members": members}), nil)
+	case "rooms":
+		s.client.srv.mu.Lock()
+		rooms := make([]string, 0, len(s.rooms))
+		for room := range s.rooms {
+			rooms = append(rooms, room)
+		}
+		s.client.srv.mu.Unlock()
+		sort.Strings(rooms)
+		s.ack(p, jsonArgs(rooms), nil)
+	case "broadcast":
+		room, ok := stringArg(args, 0)
+		event, ok2 := stringArg(args, 1)
+		if !ok || !ok2 {
+			s.ack(p, jsonArgs(map[string]string{"error": "room and event must be strings"}), nil)
+			return
+		}
+		delivered := s.client.srv.broadcast(s.namespace, room, newEvent(s.namespace, event, args[2:], p.Attachments))
+		s.ack(p, jsonArgs(map[string]any{"room": room, "delivered": delivered}), nil)
+	case "request-ack":
+		event, ok := stringArg(args, 0)
+		if !ok {
+			s.ack(p, jsonArgs(map[string]string{"error": "event must be a string"}), nil)
+			return
+		}
+		s.ack(p, nil, nil)
+		s.mu.Lock()
+		id := s.nextAck
+		s.nextAck++
+		s.acks[id] = event
+		s.mu.Unlock()
+		req := newEvent(s.namespace, event, args[1:], p.Attachments)
+		req.ID = &id
+		_ = s.client.send(req)
+	case "force-disconnect":
+		var opts struct {
+			Transport bool `json:"transport"`
+			DelayMs   int  `json:"delayMs"`
+		}
+		if len(args) > 0 {
+			_ = json.Unmarshal(args[0], &opts)
+		}
+		s.ack(p, nil, nil)
+		delay := min(max(time.Duration(opts.DelayMs)*time.Millisecond, 0), maxDisconnectDelay)
+		time.AfterFunc(delay, func() { s.disconnect(opts.Transport) })
+	default:
+		if p.ID != nil {
+			s.ack(p, args, p.Attachments)
+			return
+		}
+		_ = s.client.send(&Packet{Type: p.Type, Namespace: s.namespace, Data: p.Data, Attachments: p.Attachments})
+	}
+}
+
+// ack acknowledges p when the client asked for it
+func (s *socket) ack(p *Packet, args []json.RawMessage, attachments [][]byte) {
+	if p.ID == nil {
+		return
+	}
+	_ = s.client.send(newAck(s.namespace, *p.ID, args, attachments))
+}
+
+// disconnect disconnects the socket from its namespace (the client does not
+// reconnect), or closes the whole Engine.IO session (the client reconnects)
+func (s *socket) disconnect(transport bool) {
+	if transport {
+		s.client.session.Close()
+		return
+	}
+	_ = s.client.send(&Packet{Type: Disconnect, Namespace: s.namespace})
+	s.client.removeSocket(s)
+}
+
+// stringArg returns argument i when it is a string
+func stringArg(args []json.RawMessage, i int) (string, bool) {
+	if i >= len(args) {
+		return "", false
+	}
+	var s string
+	if err := json.Unmarshal(args[i], &s); err != nil {
+		return "", false
+	}
+	return s, true
+}
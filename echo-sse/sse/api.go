@@ -0,0 +1,134 @@
+package sse
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// scenarioSummary describes a scenario in GET /scenarios
+type scenarioSummary struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Repeat      bool   `json:"repeat,omitempty"`
+	End         string `json:"end"`
+	Steps       int    `json:"steps"`
+	Events      int    `json:"events"`
+}
+
+func summarize(sc *Scenario) scenarioSummary {
+	s := scenarioSummary{
+		Name:        sc.Name,
+		Description: sc.Description,
+		Repeat:      sc.Repeat,
+		End:         sc.End,
+		Steps:       len(sc.Steps),
+	}
+	if s.End == "" {
+		s.End = EndClose
+	}
+	for i := range sc.Steps {
+		if sc.Steps[i].IsEvent() {
+			s.Events++
+		}
+	}
+	return s
+}
+
+// RegisterHandlers adds the event streams and the scenario API to mux:
+//
+//	GET    /events/{name}       stream a scenario (Last-Event-ID resumes)
+//	GET    /scenarios           list the scenarios
+//	PUT    /scenarios/{name}    add or replace a scenario (YAML or JSON body)
+//	DELETE /scenarios/{name}    remove a scenario
+//	GET    /connections         streams served to clients (?since=id)
+//	DELETE /connections         clear the connection log
+func RegisterHandlers(mux *http.ServeMux, s *Server) {
+	mux.HandleFunc("GET /events/{name}", func(w http.ResponseWriter, r *http.Request) {
+		sc, ok := s.Get(r.PathValue("name"))
+		if !ok {
+			writeError(w, http.StatusNotFound, "scenario not found")
+			return
+		}
+		s.Stream(w, r, sc)
+	})
+
+	mux.HandleFunc("GET /scenarios", func(w http.ResponseWriter, r *http.Request) {
+		list := s.List()
+		summaries := make([]scenarioSummary, 0, len(list))
+		for _, sc := range list {
+			summaries = append(summaries, summarize(sc))
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"scenarios": summaries})
+	})
+
+	mux.HandleFunc("PUT /scenarios/{name}", func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 1<<20))
+		if err != nil {
+			writeError(w, http.StatusRequestEntityTooLarge, "scenario too large")
+			return
+		}
+		var sc Scenario
+		dec := yaml.NewDecoder(bytes.NewReader(body))
+		dec.KnownFields(true)
+		if err := dec.Decode(&sc); err != nil && !errors.Is(err, io.EOF) {
+			writeError(w, http.StatusBadRequest, "invalid scenario: "+err.Error())
+			return
+		}
+		name := r.PathValue("name")
+		if sc.Name != "" && sc.Name != name {
+			writeError(w, http.StatusBadRequest, "scenario name does not match the path")
+			return
+		}
+		sc.Name = name
+		if err := sc.Validate(); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		status := http.StatusOK
+		if s.Set(sc) {
+			status = http.StatusCreated
+		}
+		writeJSON(w, status, summarize(&sc))
+	})
+
+	mux.HandleFunc("DELETE /scenarios/{name}", func(w http.ResponseWriter, r *http.Request) {
+		if !s.Delete(r.PathValue("name")) {
+			writeError(w, http.StatusNotFound, "scenario not found")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	mux.HandleFunc("GET /connections", func(w http.ResponseWriter, r *http.Request) {
+		var since int64
+		if v := r.URL.Query().Get("since"); v != "" {
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || n < 0 {
+				writeError(w, http.StatusBadRequest, "invalid since (must be a connection ID)")
+				return
+			}
+			since = n
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"connections": s.log.Since(since)})
+	})
+
+	mux.HandleFunc("DELETE /connections", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]int{"deleted": s.log.Clear()})
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
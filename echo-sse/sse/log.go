@@ -0,0 +1,67 @@
+package sse
+
+import (
+	"sync"
+	"time"
+)
+
+// Connection records a stream served to a client, added when it ends
+type Connection struct {
+	ID          int64     `json:"id"`
+	Time        time.Time `json:"time"`
+	Scenario    string    `json:"scenario"`
+	RemoteAddr  string    `json:"remoteAddr"`
+	LastEventID string    `json:"lastEventId,omitempty"`
+	Status      int       `json:"status"`
+	EventsSent  int       `json:"eventsSent"`
+	End         string    `json:"end"`
+	DurationMs  int64     `json:"durationMs"`
+}
+
+// Log keeps the latest connections in memory
+type Log struct {
+	mu     sync.Mutex
+	max    int
+	nextID int64
+	conns  []Connection
+}
+
+// NewLog creates a log that keeps up to max connections, dropping the oldest
+func NewLog(max int) *Log {
+	return &Log{max: max}
+}
+
+// Add stores a connection, assigning its ID
+func (l *Log) Add(c Connection) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.nextID++
+	c.ID = l.nextID
+	if len(l.conns) >= l.max {
+		n := copy(l.conns, l.conns[len(l.conns)-l.max+1:])
+		l.conns = l.conns[:n]
+	}
+	l.conns = append(l.conns, c)
+}
+
+// Since returns the connections with an ID greater than id, oldest first
+func (l *Log) Since(id int64) []Connection {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	conns := []Connection{}
+	for _, c := range l.conns {
+		if c.ID > id {
+			conns = append(conns, c)
+		}
+	}
+	return conns
+}
+
+// Clear removes all connections and returns how many there were
+func (l *Log) Clear() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	n := len(l.conns)
+	l.conns = nil
+	return n
+}
@@ -0,0 +1,187 @@
+package sse
+
+import (
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Scenario ends
+const (
+	EndClose = "close"
+	EndHold  = "hold"
+)
+
+// Disconnect modes
+const (
+	// DisconnectClose ends the response normally
+	DisconnectClose = "close"
+
+	// DisconnectAbort drops the connection without ending the response
+	DisconnectAbort = "abort"
+)
+
+// MaxDelay bounds the delay of a step
+const MaxDelay = 5 * time.Minute
+
+//go:embed scenarios.yaml
+var defaultScenarios []byte
+
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// Step is one scripted action of a scenario. It waits Delay, then writes
+// Comment, Retry and the event fields, or disconnects when Disconnect is
+// set.
+type Step struct {
+	Delay time.Duration `yaml:"delay"`
+
+	// Comment is sent as comment lines (": text"), one per line
+	Comment string `yaml:"comment"`
+
+	// Retry sets the client reconnection time in milliseconds
+	Retry int `yaml:"retry"`
+
+	// Event fields; a multi-line Data is sent as several data lines
+	ID    string `yaml:"id"`
+	Event string `yaml:"event"`
+	Data  string `yaml:"data"`
+
+	// Disconnect ends the stream mid-way: close or abort
+	Disconnect string `yaml:"disconnect"`
+}
+
+// IsEvent reports whether the step writes event fields
+func (s *Step) IsEvent() bool {
+	return s.ID != "" || s.Event != "" || s.Data != ""
+}
+
+// Scenario is a scripted event stream
+type Scenario struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+
+	// Repeat replays the steps until the client disconnects
+	Repeat bool `yaml:"repeat"`
+
+	// End is what happens after the last step: close (default) or hold the
+	// connection open until the client disconnects
+	End string `yaml:"end"`
+
+	Steps []Step `yaml:"steps"`
+}
+
+// Validate checks the scenario fields
+func (s *Scenario) Validate() error {
+	if !namePattern.MatchString(s.Name) {
+		return fmt.Errorf("invalid name %q (letters, digits, '_', '.' and '-')", s.Name)
+	}
+	switch s.End {
+	case "", EndClose, EndHold:
+	default:
+		return fmt.Errorf("scenario %s: invalid end %q (must be close or hold)", s.Name, s.End)
+	}
+	if len(s.Steps) == 0 {
+		return fmt.Errorf("scenario %s: no steps", s.Name)
+	}
+	var total time.Duration
+	for i := range s.Steps {
+		if err := s.Steps[i].validate(); err != nil {
+			return fmt.Errorf("scenario %s: step %d: %w", s.Name, i+1, err)
+		}
+		total += s.Steps[i].Delay
+	}
+	if s.Repeat && total == 0 {
+		return fmt.Errorf("scenario %s: repeat requires a step with a delay", s.Name)
+	}
+	return nil
+}
+
+func (s *Step) validate() error {
+	if s.Delay < 0 || s.Delay > MaxDelay {
+		return fmt.Errorf("invalid delay %s (must be 0-%s)", s.Delay, MaxDelay)
+	}
+	if s.Retry < 0 {
+		return errors.New("invalid retry (must be >= 0)")
+	}
+	if strings.ContainsAny(s.ID, "\r\n\x00") {
+		return errors.New("id must be a single line without NUL")
+	}
+	if strings.ContainsAny(s.Event, "\r\n") {
+		return errors.New("event must be a single line")
+	}
+	switch s.Disconnect {
+	case "":
+		return nil
+	case DisconnectClose, DisconnectAbort:
+	default:
+		return fmt.Errorf("invalid disconnect %q (must be close or abort)", s.Disconnect)
+	}
+	if s.Comment != "" || s.Retry != 0 || s.IsEvent() {
+		return errors.New("disconnect cannot be combined with other fields (except delay)")
+	}
+	return nil
+}
+
+// resumeIndex returns the index of the step after the event with the given
+// ID, or 0 when there is no such event
+func (s *Scenario) resumeIndex(lastEventID string) int {
+	if lastEventID == "" {
+		return 0
+	}
+	for i := range s.Steps {
+		if s.Steps[i].ID == lastEventID {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+type scenarioFile struct {
+	Scenarios []Scenario `yaml:"scenarios"`
+}
+
+// ParseScenarios parses a YAML document with a list of scenarios
+func ParseScenarios(data []byte) ([]Scenario, error) {
+	var f scenarioFile
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&f); err != nil {
+		return nil, fmt.Errorf("invalid scenarios: %w", err)
+	}
+	seen := make(map[string]bool)
+	for i := range f.Scenarios {
+		if err := f.Scenarios[i].Validate(); err != nil {
+			return nil, err
+		}
+		if seen[f.Scenarios[i].Name] {
+			return nil, fmt.Errorf("duplicate scenario %s", f.Scenarios[i].Name)
+		}
+		seen[f.Scenarios[i].Name] = true
+	}
+	return f.Scenarios, nil
+}
+
+// DefaultScenarios returns the built-in scenarios
+func DefaultScenarios() []Scenario {
+	scenarios, err := ParseScenarios(defaultScenarios)
+	if err != nil {
+		panic(err)
+	}
+	return scenarios
+}
+
+// LoadScenarios reads scenarios from a YAML file
+func LoadScenarios(path string) ([]Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseScenarios(data)
+}
@@ -0,0 +1,82 @@
+package sse
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDefaultScenarios(t *testing.T) {
+	names := make(map[string]bool)
+	for _, sc := range DefaultScenarios() {
+		names[sc.Name] = true
+	}
+	for _, name := range []string{"ticker", "multiline", "reconnect", "disconnect", "heartbeat", "silent"} {
+		if !names[name] {
+			t.Errorf("built-in scenario %s missing", name)
+		}
+	}
+}
+
+func TestParseScenarios(t *testing.T) {
+	scenarios, err := ParseScenarios([]byte(`
+scenarios:
+  - name: orders
+    steps:
+      - { id: "1", event: order, data: "a", delay: 250ms }
+      - disconnect: abort
+`))
+	if err != nil {
+		t.Fatalf("ParseScenarios() error = %v", err)
+	}
+	if len(scenarios) != 1 || len(scenarios[0].Steps) != 2 {
+		t.Fatalf("scenarios = %+v", scenarios)
+	}
+	if step := scenarios[0].Steps[0]; step.Delay != 250*time.Millisecond || step.Event != "order" {
+		t.Errorf("step = %+v", step)
+	}
+
+	for _, doc := range []string{
+		`scenarios: [{name: "bad name", steps: [{data: x}]}]`,
+		`scenarios: [{name: a, steps: []}]`,
+		`scenarios: [{name: a, steps: [{data: x, unknown: 1}]}]`,
+		`scenarios: [{name: a, steps: [{data: x}]}, {name: a, steps: [{data: y}]}]`,
+		`scenarios: [{name: a, end: never, steps: [{data: x}]}]`,
+		`scenarios: [{name: a, repeat: true, steps: [{data: x}]}]`,
+		`scenarios: [{name: a, steps: [{disconnect: close, data: x}]}]`,
+		`scenarios: [{name: a, steps: [{disconnect: later}]}]`,
+		`scenarios: [{name: a, steps: [{id: "a\nb"}]}]`,
+		`scenarios: [{name: a, steps: [{delay: 1h}]}]`,
+	} {
+		if _, err := ParseScenarios([]byte(doc)); err == nil {
+			t.Errorf("ParseScenarios(%s) succeeded, want error", doc)
+		}
+	}
+}
+
+func TestFormatStep(t *testing.T) {
+	tests := []struct {
+		step Step
+		want string
+	}{
+		{Step{Data: "hello"}, "data: hello\n\n"},
+		{Step{ID: "7", Event: "update", Data: "a\nb\r\nc"}, "event: update\nid: 7\ndata: a\ndata: b\ndata: c\n\n"},
+		{Step{Data: "ends with newline\n"}, "data: ends with newline\ndata: \n\n"},
+		{Step{Comment: "one\ntwo"}, ": one\n: two\n"},
+		{Step{Retry: 1500}, "retry: 1500\n"},
+		{Step{ID: "only-id"}, "id: only-id\n\n"},
+	}
+	for _, tt := range tests {
+		if got := string(formatStep(&tt.step)); got != tt.want {
+			t.Errorf("formatStep(%+v) = %q, want %q", tt.step, got, tt.want)
+		}
+	}
+}
+
+func TestResumeIndex(t *testing.T) {
+	sc := Scenario{Steps: []Step{{Retry: 100}, {ID: "1"}, {ID: "2"}, {Disconnect: DisconnectClose}}}
+	for id, want := range map[string]int{"": 0, "1": 2, "2": 3, "unknown": 0} {
+		if got := sc.resumeIndex(id); got != want {
+			t.Errorf("resumeIndex(%q) = %d, want %d", id, got, want)
+		}
+	}
+}
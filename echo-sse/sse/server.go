@@ -0,0 +1,231 @@
+package sse
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Connection ends
+const (
+	EndCompleted  = "completed"
+	EndDisconnect = "disconnect"
+	EndClient     = "client"
+	EndShutdown   = "shutdown"
+	EndNoContent  = "no-content"
+)
+
+// Server streams scenarios to clients
+type Server struct {
+	mu        sync.RWMutex
+	scenarios map[string]*Scenario
+
+	log *Log
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewServer creates a server with the given scenarios
+func NewServer(scenarios []Scenario, log *Log) *Server {
+	s := &Server{
+		scenarios: make(map[string]*Scenario),
+		log:       log,
+		done:      make(chan struct{}),
+	}
+	for _, sc := range scenarios {
+		s.Set(sc)
+	}
+	return s
+}
+
+// Set adds or replaces a scenario and reports whether it was added
+func (s *Server) Set(sc Scenario) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, exists := s.scenarios[sc.Name]
+	s.scenarios[sc.Name] = &sc
+	return !exists
+}
+
+// Get returns a scenario by name
+func (s *Server) Get(name string) (*Scenario, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	sc, ok := s.scenarios[name]
+	return sc, ok
+}
+
+// Delete removes a scenario and reports whether it existed
+func (s *Server) Delete(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.scenarios[name]
+	delete(s.scenarios, name)
+	return ok
+}
+
+// List returns the scenarios sorted by name
+func (s *Server) List() []*Scenario {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	list := make([]*Scenario, 0, len(s.scenarios))
+	for _, sc := range s.scenarios {
+		list = append(list, sc)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// Close ends the active streams
+func (s *Server) Close() {
+	s.closeOnce.Do(func() { close(s.done) })
+}
+
+// Stream replays a scenario as an event stream. A client reconnecting with
+// Last-Event-ID resumes after that event; scripted disconnects before the
+// first resumed event are skipped so that they do not loop, and a client
+// resuming after the last event gets 204 No Content to stop reconnecting.
+func (s *Server) Stream(w http.ResponseWriter, r *http.Request, sc *Scenario) {
+	start := time.Now()
+	conn := Connection{
+		Time:        start,
+		Scenario:    sc.Name,
+		RemoteAddr:  r.RemoteAddr,
+		LastEventID: lastEventID(r),
+		Status:      http.StatusOK,
+	}
+	defer func() {
+		conn.DurationMs = time.Since(start).Milliseconds()
+		s.log.Add(conn)
+	}()
+
+	idx := sc.resumeIndex(conn.LastEventID)
+	resumed := idx > 0
+	if idx == len(sc.Steps) && !sc.Repeat && sc.End != EndHold {
+		conn.Status, conn.End = http.StatusNoContent, EndNoContent
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		conn.End = EndClient
+		return
+	}
+
+	for {
+		if idx == len(sc.Steps) {
+			if !sc.Repeat {
+				break
+			}
+			idx = 0
+		}
+		step := &sc.Steps[idx]
+		idx++
+
+		if step.Delay > 0 {
+			timer := time.NewTimer(step.Delay)
+			select {
+			case <-timer.C:
+			case <-r.Context().Done():
+				timer.Stop()
+				conn.End = EndClient
+				return
+			case <-s.done:
+				timer.Stop()
+				conn.End = EndShutdown
+				return
+			}
+		}
+
+		switch step.Disconnect {
+		case "":
+		case DisconnectAbort:
+			if resumed && conn.EventsSent == 0 {
+				continue
+			}
+			conn.End = EndDisconnect
+			// Drop the connection without the terminating chunk
+			panic(http.ErrAbortHandler)
+		default:
+			if resumed && conn.EventsSent == 0 {
+				continue
+			}
+			conn.End = EndDisconnect
+			return
+		}
+
+		if _, err := w.Write(formatStep(step)); err != nil {
+			conn.End = EndClient
+			return
+		}
+		if err := rc.Flush(); err != nil {
+			conn.End = EndClient
+			return
+		}
+		if step.IsEvent() {
+			conn.EventsSent++
+		}
+	}
+
+	if sc.End == EndHold {
+		select {
+		case <-r.Context().Done():
+			conn.End = EndClient
+		case <-s.done:
+			conn.End = EndShutdown
+		}
+		return
+	}
+	conn.End = EndCompleted
+}
+
+// lastEventID returns the Last-Event-ID header, or the lastEventId query
+// parameter used by EventSource polyfills that cannot set headers
+func lastEventID(r *http.Request) string {
+	if id := r.Header.Get("Last-Event-ID"); id != "" {
+		return id
+	}
+	return r.URL.Query().Get("lastEventId")
+}
+
+// formatStep encodes a step in the event stream format
+func formatStep(step *Step) []byte {
+	var b strings.Builder
+	if step.Comment != "" {
+		for _, line := range splitLines(step.Comment) {
+			b.WriteString(": " + line + "\n")
+		}
+	}
+	if step.Retry > 0 {
+		fmt.Fprintf(&b, "retry: %d\n", step.Retry)
+	}
+	if step.IsEvent() {
+		if step.Event != "" {
+			b.WriteString("event: " + step.Event + "\n")
+		}
+		if step.ID != "" {
+			b.WriteString("id: " + step.ID + "\n")
+		}
+		if step.Data != "" {
+			for _, line := range splitLines(step.Data) {
+				b.WriteString("data: " + line + "\n")
+			}
+		}
+		b.WriteString("\n")
+	}
+	return []byte(b.String())
+}
+
+func splitLines(s string) []string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.Split(strings.ReplaceAll(s, "\r", "\n"), "\n")
+}
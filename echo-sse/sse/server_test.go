@@ -0,0 +1,234 @@
+package sse
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type event struct {
+	ID    string
+	Event string
+	Data  string
+}
+
+// readEvents parses an event stream until it ends, returning the dispatched
+// events and the comment lines
+func readEvents(t *testing.T, r io.Reader) ([]event, []string, error) {
+	t.Helper()
+	var events []event
+	var comments []string
+	var cur event
+	var data []string
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := sc.Text()
+		switch {
+		case line == "":
+			if data != nil {
+				cur.Data = strings.Join(data, "\n")
+				events = append(events, cur)
+			}
+			cur, data = event{}, nil
+		case strings.HasPrefix(line, ":"):
+			comments = append(comments, strings.TrimPrefix(line, ": "))
+		default:
+			field, value, _ := strings.Cut(line, ": ")
+			switch field {
+			case "id":
+				cur.ID = value
+			case "event":
+				cur.Event = value
+			case "data":
+				data = append(data, value)
+			}
+		}
+	}
+	return events, comments, sc.Err()
+}
+
+func startServer(t *testing.T, scenarios string) (*httptest.Server, *Server) {
+	t.Helper()
+	list, err := ParseScenarios([]byte(scenarios))
+	if err != nil {
+		t.Fatalf("ParseScenarios() error = %v", err)
+	}
+	s := NewServer(list, NewLog(100))
+	mux := http.NewServeMux()
+	RegisterHandlers(mux, s)
+	srv := httptest.NewServer(mux)
+	t.Cleanup(func() {
+		s.Close()
+		srv.Close()
+	})
+	return srv, s
+}
+
+func get(t *testing.T, url, lastEventID string) *http.Response {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	t.Cleanup(func() { _ = resp.Body.Close() })
+	return resp
+}
+
+const testScenarios = `
+scenarios:
+  - name: resume
+    steps:
+      - comment: hello
+      - retry: 500
+      - { id: "1", event: greeting, data: "line 1\nline 2" }
+      - { id: "2", data: two }
+      - disconnect: close
+      - { id: "3", data: three }
+  - name: abort
+    steps:
+      - { id: "1", data: one }
+      - disconnect: abort
+      - { id: "2", data: two }
+  - name: hold
+    end: hold
+    steps:
+      - data: waiting
+`
+
+func TestStreamResume(t *testing.T) {
+	srv, s := startServer(t, testScenarios)
+
+	resp := get(t, srv.URL+"/events/resume", "")
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	events, comments, err := readEvents(t, resp.Body)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	want := []event{{"1", "greeting", "line 1\nline 2"}, {"2", "", "two"}}
+	if len(events) != 2 || events[0] != want[0] || events[1] != want[1] {
+		t.Errorf("events = %+v, want %+v", events, want)
+	}
+	if len(comments) != 1 || comments[0] != "hello" {
+		t.Errorf("comments = %q", comments)
+	}
+
+	// Reconnecting after the disconnect skips it
+	events, _, _ = readEvents(t, get(t, srv.URL+"/events/resume", "2").Body)
+	if len(events) != 1 || events[0].ID != "3" {
+		t.Errorf("resumed events = %+v, want event 3", events)
+	}
+
+	// The query parameter is used by polyfills; the disconnect applies
+	// again once an event was sent
+	events, _, _ = readEvents(t, get(t, srv.URL+"/events/resume?lastEventId=1", "").Body)
+	if len(events) != 1 || events[0].ID != "2" {
+		t.Errorf("resumed events = %+v, want event 2", events)
+	}
+
+	// Nothing left: 204 stops EventSource reconnection
+	if resp := get(t, srv.URL+"/events/resume", "3"); resp.StatusCode != http.StatusNoContent {
+		t.Errorf("resume after last event = %d, want 204", resp.StatusCode)
+	}
+
+	conns := s.log.Since(0)
+	if len(conns) != 4 {
+		t.Fatalf("connections = %+v", conns)
+	}
+	if c := conns[0]; c.End != EndDisconnect || c.EventsSent != 2 || c.Status != http.StatusOK {
+		t.Errorf("first connection = %+v", c)
+	}
+	if c := conns[1]; c.LastEventID != "2" || c.End != EndCompleted || c.EventsSent != 1 {
+		t.Errorf("resumed connection = %+v", c)
+	}
+	if c := conns[3]; c.Status != http.StatusNoContent || c.End != EndNoContent {
+		t.Errorf("final connection = %+v", c)
+	}
+}
+
+func TestStreamAbort(t *testing.T) {
+	srv, _ := startServer(t, testScenarios)
+
+	events, _, err := readEvents(t, get(t, srv.URL+"/events/abort", "").Body)
+	if err == nil {
+		t.Error("aborted stream ended without an error")
+	}
+	if len(events) != 1 || events[0].ID != "1" {
+		t.Errorf("events = %+v, want event 1", events)
+	}
+}
+
+func TestStreamShutdown(t *testing.T) {
+	srv, s := startServer(t, testScenarios)
+
+	resp := get(t, srv.URL+"/events/hold", "")
+	done := make(chan []event)
+	go func() {
+		events, _, _ := readEvents(t, resp.Body)
+		done <- events
+	}()
+	time.Sleep(50 * time.Millisecond)
+	s.Close()
+
+	select {
+	case events := <-done:
+		if len(events) != 1 || events[0].Data != "waiting" {
+			t.Errorf("events = %+v", events)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("held stream did not end on Close()")
+	}
+}
+
+func TestScenarioAPI(t *testing.T) {
+	srv, _ := startServer(t, testScenarios)
+
+	put := func(name, body string) int {
+		req, _ := http.NewRequest(http.MethodPut, srv.URL+"/scenarios/"+name, strings.NewReader(body))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("PUT: %v", err)
+		}
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if status := put("custom", "steps:\n  - data: from yaml\n"); status != http.StatusCreated {
+		t.Errorf("PUT new scenario = %d, want 201", status)
+	}
+	if status := put("custom", `{"steps":[{"event":"json","data":"from json"}]}`); status != http.StatusOK {
+		t.Errorf("PUT existing scenario = %d, want 200", status)
+	}
+	for _, body := range []string{`steps: []`, `{"name":"other","steps":[{"data":"x"}]}`, `{"bogus":1}`} {
+		if status := put("custom", body); status != http.StatusBadRequest {
+			t.Errorf("PUT %s = %d, want 400", body, status)
+		}
+	}
+
+	events, _, _ := readEvents(t, get(t, srv.URL+"/events/custom", "").Body)
+	if len(events) != 1 || events[0].Event != "json" || events[0].Data != "from json" {
+		t.Errorf("events = %+v", events)
+	}
+
+	req, _ := http.NewRequest(http.MethodDelete, srv.URL+"/scenarios/custom", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("DELETE: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("DELETE = %d, want 204", resp.StatusCode)
+	}
+	if resp := get(t, srv.URL+"/events/custom", ""); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("deleted scenario = %d, want 404", resp.StatusCode)
+	}
+}
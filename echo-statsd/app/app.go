@@ -0,0 +1,215 @@
+// Package app runs echo-statsd: LoadConfig reads its settings and Run serves
+// it until its context ends, for the echo-statsd binary and echo-all.
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/probitas-test/echo-servers/echo-statsd/docs"
+	"github.com/probitas-test/echo-servers/echo-statsd/statsd"
+	"github.com/probitas-test/echo-servers/shared/admin"
+	"github.com/probitas-test/echo-servers/shared/bandwidth"
+	"github.com/probitas-test/echo-servers/shared/connlimit"
+	"github.com/probitas-test/echo-servers/shared/lifecycle"
+	"github.com/probitas-test/echo-servers/shared/logging"
+	"github.com/probitas-test/echo-servers/shared/network"
+	"github.com/probitas-test/echo-servers/shared/tracing"
+	"github.com/probitas-test/echo-servers/shared/version"
+)
+
+// Run serves echo-statsd with cfg until ctx is done, then shuts it down
+// gracefully. Logging and the address family are set up by the caller, as
+// they are shared by the servers of a process.
+func Run(ctx context.Context, cfg *Config) error {
+	// Started servers stop when Run returns early with an error
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Listeners serving in the background stop Run with their error
+	serveErr := make(chan error, 2)
+
+	// Simulated slow startup: no port opens before the startup delay
+	if err := cfg.Lifecycle.Validate(); err != nil {
+		return fmt.Errorf("invalid lifecycle configuration: %w", err)
+	}
+	log.Printf("Lifecycle: %s", cfg.Lifecycle)
+	lifecycle.Delay(ctx, cfg.Lifecycle)
+
+	// OpenTelemetry tracing and W3C trace context propagation
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		return fmt.Errorf("failed to set up tracing: %w", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Error("Tracing shutdown error", "error", err)
+		}
+	}()
+	log.Printf("OpenTelemetry tracing: %s", cfg.Tracing)
+
+	if cfg.Port < 1 || cfg.Port > 65535 {
+		return fmt.Errorf("invalid PORT %d (must be 1-65535)", cfg.Port)
+	}
+	if !cfg.UDPEnabled && !cfg.TCPEnabled {
+		return errors.New("UDP_ENABLED and TCP_ENABLED are both false")
+	}
+	if cfg.MaxRecords < 1 {
+		return fmt.Errorf("invalid MAX_RECORDS %d (must be >= 1)", cfg.MaxRecords)
+	}
+
+	store := statsd.NewStore(cfg.MaxRecords)
+	server := statsd.NewServer(store)
+
+	// Bandwidth shaping of every TCP connection, changed at runtime on the
+	// admin port
+	shaper, err := bandwidth.New(cfg.Bandwidth)
+	if err != nil {
+		return err
+	}
+	log.Printf("Bandwidth: %s", cfg.Bandwidth)
+
+	// Cap on the open TCP connections, changed at runtime on the admin port;
+	// the next ones get a server busy error
+	connLimiter, err := connlimit.New(cfg.ConnLimit)
+	if err != nil {
+		return err
+	}
+	log.Printf("Connection limit: %s", cfg.ConnLimit)
+
+	var pc net.PacketConn
+	var tcpListener net.Listener
+	if cfg.UDPEnabled {
+		if pc, err = network.ListenPacket(cfg.Addr()); err != nil {
+			return fmt.Errorf("failed to listen on UDP: %w", err)
+		}
+		defer func() { _ = pc.Close() }()
+	}
+	if cfg.TCPEnabled {
+		if tcpListener, err = network.Listen(cfg.Addr()); err != nil {
+			return fmt.Errorf("failed to listen on TCP: %w", err)
+		}
+		defer func() { _ = tcpListener.Close() }()
+	}
+
+	// Admin API: health, query delay and records
+	adm := admin.New("echo-statsd", cfg.src)
+	adm.Store("records", func() { store.Clear() })
+	adm.State("records", func() any { return len(store.Query(statsd.Filter{})) })
+
+	mux := http.NewServeMux()
+
+	// Query API
+	statsd.RegisterHandlers(mux, store)
+
+	// Health check endpoint
+	mux.Handle("GET /health", adm.HealthHandler())
+
+	// Effective configuration (secrets redacted)
+	mux.Handle("GET /config", cfg.src)
+
+	// Address family of the client connection
+	mux.Handle("GET "+network.Path, network.Handler())
+
+	// Build information and enabled features
+	mux.Handle("GET "+version.Path, version.Handler(version.New("echo-statsd", version.Features{
+		"admin":   cfg.Admin.Enabled,
+		"debug":   cfg.Admin.Debug,
+		"tcp":     cfg.TCPEnabled,
+		"tracing": cfg.Tracing.Enabled,
+		"udp":     cfg.UDPEnabled,
+	})))
+
+	// API documentation endpoint
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		_, _ = w.Write([]byte(docs.API))
+	})
+
+	// Admin API on a separate port, with the bandwidth and connection limit
+	// admin APIs
+	var adminServer *http.Server
+	if cfg.Admin.Enabled {
+		adm.Handle(bandwidth.Path, bandwidth.Handler(shaper))
+		adm.Handle(connlimit.Path, connlimit.Handler(connLimiter))
+		if cfg.Admin.Debug {
+			adm.Debug()
+		}
+		adminServer, err = adm.Serve(cfg.AdminAddr())
+		if err != nil {
+			return err
+		}
+		defer func() { _ = adminServer.Close() }()
+	}
+
+	// Readiness delay and simulated crash, counted from here
+	lifecycle.Start(ctx, cfg.Lifecycle, adm)
+
+	srv := &http.Server{
+		Addr:              cfg.HTTPAddr(),
+		Handler:           tracing.HTTP(nil)(logging.HTTP(adm.HTTP(mux))),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	// Graceful shutdown (TCP connections are closed)
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		<-ctx.Done()
+
+		log.Println("Shutting down server...")
+		if err := server.Close(); err != nil {
+			slog.Error("StatsD server shutdown error", "error", err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := srv.Shutdown(ctx); err != nil {
+			slog.Error("Server shutdown error", "error", err)
+		}
+		if adminServer != nil {
+			_ = adminServer.Shutdown(ctx)
+		}
+	}()
+
+	if pc != nil {
+		go func() {
+			if err := server.ServeUDP(pc); err != nil && !errors.Is(err, statsd.ErrServerClosed) {
+				serveErr <- fmt.Errorf("failed to serve StatsD over UDP: %w", err)
+				cancel()
+			}
+		}()
+		log.Printf("Starting StatsD server on %s (udp)", cfg.Addr())
+	}
+	if tcpListener != nil {
+		go func() {
+			if err := server.ServeTCP(shaper.Listen(connLimiter.Listen(tcpListener, nil))); err != nil && !errors.Is(err, statsd.ErrServerClosed) {
+				serveErr <- fmt.Errorf("failed to serve StatsD over TCP: %w", err)
+				cancel()
+			}
+		}()
+		log.Printf("Starting StatsD server on %s (tcp)", cfg.Addr())
+	}
+
+	log.Printf("Starting HTTP server on %s", cfg.HTTPAddr())
+	if err := network.ListenAndServe(srv); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve: %w", err)
+	}
+	<-stopped
+
+	log.Println("Server stopped")
+	select {
+	case err := <-serveErr:
+		return err
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,91 @@
+package statsd
+
+import (
+	"slices"
+	"strings"
+)
+
+// Aggregate summarizes the samples of a metric with the same name, type and
+// tags, the way a statsd server would flush them
+type Aggregate struct {
+	Name string   `json:"name"`
+	Type string   `json:"type"`
+	Tags []string `json:"tags,omitempty"`
+
+	// Samples is the number of values received
+	Samples int `json:"samples"`
+
+	// Value is the total of a counter (scaled by the sample rates), the
+	// current value of a gauge, the number of unique members of a set, or
+	// the sum of timer, histogram and distribution values
+	Value float64 `json:"value"`
+
+	// Min, Max and Avg summarize timers, histograms and distributions
+	Min *float64 `json:"min,omitempty"`
+	Max *float64 `json:"max,omitempty"`
+	Avg *float64 `json:"avg,omitempty"`
+
+	members map[string]struct{}
+}
+
+// Aggregates summarizes the metric records, in order of first appearance
+func Aggregates(records []Record) []*Aggregate {
+	aggregates := []*Aggregate{}
+	byKey := make(map[string]*Aggregate)
+	for i := range records {
+		m := records[i].Metric
+		if m == nil {
+			continue
+		}
+		tags := slices.Clone(m.Tags)
+		slices.Sort(tags)
+		key := m.Name + "|" + m.Type + "|" + strings.Join(tags, ",")
+		a, ok := byKey[key]
+		if !ok {
+			a = &Aggregate{Name: m.Name, Type: m.Type, Tags: tags}
+			byKey[key] = a
+			aggregates = append(aggregates, a)
+		}
+		a.add(m)
+	}
+	for _, a := range aggregates {
+		if a.Avg != nil {
+			avg := a.Value / float64(a.Samples)
+			a.Avg = &avg
+		}
+	}
+	return aggregates
+}
+
+func (a *Aggregate) add(m *Metric) {
+	a.Samples++
+	switch m.Type {
+	case TypeSet:
+		if a.members == nil {
+			a.members = make(map[string]struct{})
+		}
+		a.members[m.Value.(string)] = struct{}{}
+		a.Value = float64(len(a.members))
+	case TypeCounter:
+		v := m.Value.(float64)
+		if m.SampleRate > 0 {
+			v /= m.SampleRate
+		}
+		a.Value += v
+	case TypeGauge:
+		if m.Delta {
+			a.Value += m.Value.(float64)
+		} else {
+			a.Value = m.Value.(float64)
+		}
+	default:
+		v := m.Value.(float64)
+		a.Value += v
+		if a.Min == nil {
+			a.Min, a.Max, a.Avg = new(float64), new(float64), new(float64)
+			*a.Min, *a.Max = v, v
+		}
+		*a.Min = min(*a.Min, v)
+		*a.Max = max(*a.Max, v)
+	}
+}
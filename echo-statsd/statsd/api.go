@@ -0,0 +1,122 @@
+package statsd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// MaxWait bounds the wait query parameter
+const MaxWait = 30 * time.Second
+
+// RegisterHandlers adds the query API of the store to mux:
+//
+//	GET    /records              records matching the query parameters
+//	GET    /metrics              metric records
+//	GET    /metrics/aggregates   metrics summarized by name, type and tags
+//	GET    /events               event records
+//	GET    /service-checks       service check records
+//	DELETE /records              remove all records
+func RegisterHandlers(mux *http.ServeMux, store *Store) {
+	mux.HandleFunc("GET /records", recordsHandler(store, ""))
+	mux.HandleFunc("GET /metrics", recordsHandler(store, KindMetric))
+	mux.HandleFunc("GET /events", recordsHandler(store, KindEvent))
+	mux.HandleFunc("GET /service-checks", recordsHandler(store, KindServiceCheck))
+
+	mux.HandleFunc("GET /metrics/aggregates", func(w http.ResponseWriter, r *http.Request) {
+		f, _, err := parseQuery(r.URL.Query())
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		f.Kind, f.Limit = KindMetric, 0
+		writeJSON(w, http.StatusOK, map[string]any{"aggregates": Aggregates(store.Query(f))})
+	})
+
+	mux.HandleFunc("DELETE /records", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]int{"deleted": store.Clear()})
+	})
+}
+
+// recordsHandler serves the records of a kind (all kinds if empty)
+func recordsHandler(store *Store, kind string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		f, wait, err := parseQuery(r.URL.Query())
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		if kind != "" {
+			f.Kind = kind
+		}
+		var records []Record
+		if wait > 0 {
+			ctx, cancel := context.WithTimeout(r.Context(), wait)
+			defer cancel()
+			records = store.Wait(ctx, f)
+		} else {
+			records = store.Query(f)
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"records": records})
+	}
+}
+
+// parseQuery builds a filter and the wait duration from query parameters
+func parseQuery(q url.Values) (Filter, time.Duration, error) {
+	f := Filter{
+		Kind: q.Get("kind"),
+		Name: q.Get("name"),
+		Type: q.Get("type"),
+		Tags: q["tag"],
+	}
+
+	switch f.Kind {
+	case "", KindMetric, KindEvent, KindServiceCheck:
+	default:
+		return f, 0, fmt.Errorf("invalid kind %q (must be metric, event or service_check)", f.Kind)
+	}
+	if f.Type != "" && !metricTypes[f.Type] {
+		return f, 0, fmt.Errorf("invalid type %q (must be c, g, ms, h, s or d)", f.Type)
+	}
+	if v := q.Get("invalid"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return f, 0, fmt.Errorf("invalid must be true or false, got %q", v)
+		}
+		f.Invalid = &b
+	}
+	if v := q.Get("since"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			return f, 0, fmt.Errorf("invalid since %q (must be a record ID)", v)
+		}
+		f.SinceID = n
+	}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return f, 0, fmt.Errorf("invalid limit %q (must be >= 1)", v)
+		}
+		f.Limit = n
+	}
+
+	var wait time.Duration
+	if v := q.Get("wait"); v != "" {
+		seconds, err := strconv.ParseFloat(v, 64)
+		wait = time.Duration(seconds * float64(time.Second))
+		if err != nil || wait < 0 || wait > MaxWait {
+			return f, 0, fmt.Errorf("invalid wait %q (must be 0-%d seconds)", v, int(MaxWait.Seconds()))
+		}
+	}
+	return f, wait, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,271 @@
+package statsd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Record kinds
+const (
+	KindMetric       = "metric"
+	KindEvent        = "event"
+	KindServiceCheck = "service_check"
+)
+
+// Metric types
+const (
+	TypeCounter      = "c"
+	TypeGauge        = "g"
+	TypeTimer        = "ms"
+	TypeHistogram    = "h"
+	TypeSet          = "s"
+	TypeDistribution = "d"
+)
+
+var metricTypes = map[string]bool{
+	TypeCounter: true, TypeGauge: true, TypeTimer: true,
+	TypeHistogram: true, TypeSet: true, TypeDistribution: true,
+}
+
+// Metric is a statsd metric sample. Value is a number, except for sets
+// where it is the string member.
+type Metric struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Value any    `json:"value"`
+
+	// Delta marks gauges sent with an explicit sign ("+3", "-3"), which
+	// change the gauge instead of setting it
+	Delta bool `json:"delta,omitempty"`
+
+	// DogStatsD extensions
+	SampleRate  float64  `json:"sampleRate,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	ContainerID string   `json:"containerId,omitempty"`
+	Timestamp   int64    `json:"timestamp,omitempty"`
+}
+
+// Event is a DogStatsD event (_e)
+type Event struct {
+	Title          string   `json:"title"`
+	Text           string   `json:"text"`
+	Timestamp      int64    `json:"timestamp,omitempty"`
+	Hostname       string   `json:"hostname,omitempty"`
+	AggregationKey string   `json:"aggregationKey,omitempty"`
+	Priority       string   `json:"priority,omitempty"`
+	SourceType     string   `json:"sourceType,omitempty"`
+	AlertType      string   `json:"alertType,omitempty"`
+	Tags           []string `json:"tags,omitempty"`
+}
+
+// ServiceCheck is a DogStatsD service check (_sc)
+type ServiceCheck struct {
+	Name      string   `json:"name"`
+	Status    int      `json:"status"`
+	Timestamp int64    `json:"timestamp,omitempty"`
+	Hostname  string   `json:"hostname,omitempty"`
+	Tags      []string `json:"tags,omitempty"`
+	Message   string   `json:"message,omitempty"`
+}
+
+// Packet is a parsed statsd line: one of Metrics (several with the
+// multi-value syntax "name:1:2:3|d"), Event or ServiceCheck
+type Packet struct {
+	Metrics      []*Metric
+	Event        *Event
+	ServiceCheck *ServiceCheck
+}
+
+// Parse parses a statsd or DogStatsD line
+func Parse(line string) (*Packet, error) {
+	switch {
+	case strings.HasPrefix(line, "_e{"):
+		e, err := parseEvent(line)
+		if err != nil {
+			return nil, err
+		}
+		return &Packet{Event: e}, nil
+	case strings.HasPrefix(line, "_sc|"):
+		sc, err := parseServiceCheck(line)
+		if err != nil {
+			return nil, err
+		}
+		return &Packet{ServiceCheck: sc}, nil
+	default:
+		metrics, err := parseMetric(line)
+		if err != nil {
+			return nil, err
+		}
+		return &Packet{Metrics: metrics}, nil
+	}
+}
+
+// parseMetric parses "name:value[:value...]|type[|@rate][|#tags][|c:id][|T ts]"
+func parseMetric(line string) ([]*Metric, error) {
+	name, rest, ok := strings.Cut(line, ":")
+	if !ok || name == "" {
+		return nil, fmt.Errorf("missing metric name")
+	}
+	fields := strings.Split(rest, "|")
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("missing metric type")
+	}
+	typ := fields[1]
+	if !metricTypes[typ] {
+		return nil, fmt.Errorf("invalid metric type %q", typ)
+	}
+
+	tmpl := Metric{Name: name, Type: typ}
+	for _, field := range fields[2:] {
+		switch {
+		case strings.HasPrefix(field, "@"):
+			rate, err := strconv.ParseFloat(field[1:], 64)
+			if err != nil || rate <= 0 || rate > 1 {
+				return nil, fmt.Errorf("invalid sample rate %q", field)
+			}
+			tmpl.SampleRate = rate
+		case strings.HasPrefix(field, "#"):
+			tmpl.Tags = parseTags(field[1:])
+		case strings.HasPrefix(field, "c:"):
+			tmpl.ContainerID = field[2:]
+		case strings.HasPrefix(field, "T"):
+			ts, err := strconv.ParseInt(field[1:], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid timestamp %q", field)
+			}
+			tmpl.Timestamp = ts
+		default:
+			// Unknown extensions are ignored, as by the DogStatsD agent
+		}
+	}
+
+	values := []string{fields[0]}
+	if typ != TypeSet {
+		values = strings.Split(fields[0], ":")
+	}
+	metrics := make([]*Metric, 0, len(values))
+	for _, v := range values {
+		m := tmpl
+		if v == "" {
+			return nil, fmt.Errorf("missing metric value")
+		}
+		if typ == TypeSet {
+			m.Value = v
+		} else {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid metric value %q", v)
+			}
+			m.Value = f
+			m.Delta = typ == TypeGauge && (v[0] == '+' || v[0] == '-')
+		}
+		metrics = append(metrics, &m)
+	}
+	return metrics, nil
+}
+
+// parseEvent parses "_e{title.length,text.length}:title|text|d:ts|h:host|
+// k:key|p:priority|s:source|t:alert|#tags"
+func parseEvent(line string) (*Event, error) {
+	header, rest, ok := strings.Cut(line[len("_e{"):], "}:")
+	if !ok {
+		return nil, fmt.Errorf("invalid event header")
+	}
+	titleLen, textLen, ok := strings.Cut(header, ",")
+	if !ok {
+		return nil, fmt.Errorf("invalid event header")
+	}
+	tl, err1 := strconv.Atoi(titleLen)
+	xl, err2 := strconv.Atoi(textLen)
+	if err1 != nil || err2 != nil || tl < 1 || xl < 0 || len(rest) < tl+1+xl || rest[tl] != '|' {
+		return nil, fmt.Errorf("event lengths do not match title and text")
+	}
+	e := &Event{
+		Title: rest[:tl],
+		// Newlines are escaped as \n in the text
+		Text: strings.ReplaceAll(rest[tl+1:tl+1+xl], `\n`, "\n"),
+	}
+	rest = rest[tl+1+xl:]
+	if rest != "" && rest[0] != '|' {
+		return nil, fmt.Errorf("event lengths do not match title and text")
+	}
+
+	for _, field := range strings.Split(rest, "|")[1:] {
+		switch {
+		case strings.HasPrefix(field, "d:"):
+			ts, err := strconv.ParseInt(field[2:], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid timestamp %q", field)
+			}
+			e.Timestamp = ts
+		case strings.HasPrefix(field, "h:"):
+			e.Hostname = field[2:]
+		case strings.HasPrefix(field, "k:"):
+			e.AggregationKey = field[2:]
+		case strings.HasPrefix(field, "p:"):
+			e.Priority = field[2:]
+		case strings.HasPrefix(field, "s:"):
+			e.SourceType = field[2:]
+		case strings.HasPrefix(field, "t:"):
+			e.AlertType = field[2:]
+		case strings.HasPrefix(field, "#"):
+			e.Tags = parseTags(field[1:])
+		}
+	}
+	return e, nil
+}
+
+// parseServiceCheck parses "_sc|name|status|d:ts|h:host|#tags|m:message"
+func parseServiceCheck(line string) (*ServiceCheck, error) {
+	fields := strings.Split(line, "|")
+	if len(fields) < 3 || fields[1] == "" {
+		return nil, fmt.Errorf("missing service check name or status")
+	}
+	status, err := strconv.Atoi(fields[2])
+	if err != nil || status < 0 || status > 3 {
+		return nil, fmt.Errorf("invalid service check status %q (must be 0-3)", fields[2])
+	}
+	sc := &ServiceCheck{Name: fields[1], Status: status}
+	for i := 3; i < len(fields); i++ {
+		field := fields[i]
+		switch {
+		case strings.HasPrefix(field, "d:"):
+			ts, err := strconv.ParseInt(field[2:], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid timestamp %q", field)
+			}
+			sc.Timestamp = ts
+		case strings.HasPrefix(field, "h:"):
+			sc.Hostname = field[2:]
+		case strings.HasPrefix(field, "#"):
+			sc.Tags = parseTags(field[1:])
+		case strings.HasPrefix(field, "m:"):
+			// The message is the last field and may contain "|"
+			sc.Message = strings.Join(append([]string{field[2:]}, fields[i+1:]...), "|")
+			return sc, nil
+		}
+	}
+	return sc, nil
+}
+
+func parseTags(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+// hasTag reports whether tags contain want: "key:value" matches exactly,
+// "key" matches the bare tag or any value of the key
+func hasTag(tags []string, want string) bool {
+	for _, tag := range tags {
+		if tag == want {
+			return true
+		}
+		if !strings.Contains(want, ":") && strings.HasPrefix(tag, want+":") {
+			return true
+		}
+	}
+	return false
+}
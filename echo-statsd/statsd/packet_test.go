@@ -0,0 +1,103 @@
+package statsd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMetric(t *testing.T) {
+	tests := []struct {
+		line string
+		want []Metric
+	}{
+		{"page.views:1|c", []Metric{{Name: "page.views", Type: TypeCounter, Value: 1.0}}},
+		{"queue.size:-3|g", []Metric{{Name: "queue.size", Type: TypeGauge, Value: -3.0, Delta: true}}},
+		{"cpu:0.5|g", []Metric{{Name: "cpu", Type: TypeGauge, Value: 0.5}}},
+		{"users:alice|s", []Metric{{Name: "users", Type: TypeSet, Value: "alice"}}},
+		{
+			"req.latency:320|ms|@0.1|#env:prod,canary|c:abc123|T1700000000",
+			[]Metric{{
+				Name: "req.latency", Type: TypeTimer, Value: 320.0, SampleRate: 0.1,
+				Tags: []string{"env:prod", "canary"}, ContainerID: "abc123", Timestamp: 1700000000,
+			}},
+		},
+		{
+			"size:1:2.5|d|#a",
+			[]Metric{
+				{Name: "size", Type: TypeDistribution, Value: 1.0, Tags: []string{"a"}},
+				{Name: "size", Type: TypeDistribution, Value: 2.5, Tags: []string{"a"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		p, err := Parse(tt.line)
+		if err != nil {
+			t.Errorf("Parse(%q) error = %v", tt.line, err)
+			continue
+		}
+		var got []Metric
+		for _, m := range p.Metrics {
+			got = append(got, *m)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestParseEvent(t *testing.T) {
+	p, err := Parse(`_e{6,12}:Deploy|line1\nline2|d:1700000000|h:web1|k:deploy|p:low|s:ci|t:success|#env:prod`)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	want := &Event{
+		Title: "Deploy", Text: "line1\nline2", Timestamp: 1700000000, Hostname: "web1",
+		AggregationKey: "deploy", Priority: "low", SourceType: "ci", AlertType: "success",
+		Tags: []string{"env:prod"},
+	}
+	if !reflect.DeepEqual(p.Event, want) {
+		t.Errorf("Parse() = %+v, want %+v", p.Event, want)
+	}
+}
+
+func TestParseServiceCheck(t *testing.T) {
+	p, err := Parse("_sc|db.up|2|d:1700000000|h:db1|#env:prod|m:connection refused | retrying")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	want := &ServiceCheck{
+		Name: "db.up", Status: 2, Timestamp: 1700000000, Hostname: "db1",
+		Tags: []string{"env:prod"}, Message: "connection refused | retrying",
+	}
+	if !reflect.DeepEqual(p.ServiceCheck, want) {
+		t.Errorf("Parse() = %+v, want %+v", p.ServiceCheck, want)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, line := range []string{
+		"no-value",
+		"name:1",
+		"name:1|x",
+		"name:abc|c",
+		"name:1|c|@2",
+		"name:|g",
+		"_e{10,2}:short|hi",
+		"_e{a,b}:t|x",
+		"_sc|db|9",
+		"_sc||0",
+	} {
+		if _, err := Parse(line); err == nil {
+			t.Errorf("Parse(%q) succeeded, want error", line)
+		}
+	}
+}
+
+func TestHasTag(t *testing.T) {
+	tags := []string{"env:prod", "canary"}
+	for want, ok := range map[string]bool{"env:prod": true, "env": true, "canary": true, "env:dev": false, "can": false} {
+		if got := hasTag(tags, want); got != ok {
+			t.Errorf("hasTag(%q) = %v, want %v", want, got, ok)
+		}
+	}
+}
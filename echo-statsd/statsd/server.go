@@ -0,0 +1,176 @@
+package statsd
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrServerClosed is returned by ServeUDP and ServeTCP after Close
+var ErrServerClosed = errors.New("server closed")
+
+// MaxPacketSize bounds datagrams and TCP lines; longer TCP lines close the
+// connection and longer datagrams are truncated by the read buffer
+const MaxPacketSize = 64 * 1024
+
+// Server receives statsd lines over UDP and TCP into a store
+type Server struct {
+	store *Store
+
+	mu        sync.Mutex
+	udp       map[net.PacketConn]struct{}
+	listeners map[net.Listener]struct{}
+	conns     map[net.Conn]struct{}
+	closed    bool
+}
+
+// NewServer creates a server storing messages in store
+func NewServer(store *Store) *Server {
+	return &Server{
+		store:     store,
+		udp:       make(map[net.PacketConn]struct{}),
+		listeners: make(map[net.Listener]struct{}),
+		conns:     make(map[net.Conn]struct{}),
+	}
+}
+
+// ServeUDP stores the lines of the datagrams received on pc until Close is
+// called
+func (s *Server) ServeUDP(pc net.PacketConn) error {
+	if !s.track(func() { s.udp[pc] = struct{}{} }) {
+		return ErrServerClosed
+	}
+
+	buf := make([]byte, MaxPacketSize)
+	for {
+		n, addr, err := pc.ReadFrom(buf)
+		if err != nil {
+			if s.isClosed() {
+				return ErrServerClosed
+			}
+			return err
+		}
+		s.receive("udp", addr.String(), string(buf[:n]))
+	}
+}
+
+// ServeTCP stores the newline-terminated lines of the connections accepted
+// on l until Close is called
+func (s *Server) ServeTCP(l net.Listener) error {
+	if !s.track(func() { s.listeners[l] = struct{}{} }) {
+		return ErrServerClosed
+	}
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if s.isClosed() {
+				return ErrServerClosed
+			}
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				time.Sleep(10 * time.Millisecond)
+				continue
+			}
+			return err
+		}
+		go s.serveConn(conn)
+	}
+}
+
+// Close stops the listeners and closes the TCP connections
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+
+	var errs []error
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+	for pc := range s.udp {
+		errs = append(errs, pc.Close())
+	}
+	for l := range s.listeners {
+		errs = append(errs, l.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// track registers a transport unless the server is closed
+func (s *Server) track(add func()) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return false
+	}
+	add()
+	return true
+}
+
+func (s *Server) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	if !s.track(func() { s.conns[conn] = struct{}{} }) {
+		_ = conn.Close()
+		return
+	}
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		_ = conn.Close()
+	}()
+
+	remote := conn.RemoteAddr().String()
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 4096), MaxPacketSize)
+	for scanner.Scan() {
+		s.receive("tcp", remote, scanner.Text())
+	}
+}
+
+// receive parses and stores the lines of a packet
+func (s *Server) receive(transport, remote, packet string) {
+	now := time.Now().UTC()
+	for _, line := range strings.Split(packet, "\n") {
+		line = strings.TrimRight(line, "\r\x00")
+		if line == "" {
+			continue
+		}
+		r := Record{
+			ReceivedAt: now,
+			Transport:  transport,
+			RemoteAddr: remote,
+			Raw:        line,
+		}
+		p, err := Parse(line)
+		switch {
+		case err != nil:
+			r.Error = err.Error()
+			s.store.Add(r)
+		case p.Event != nil:
+			r.Kind, r.Event = KindEvent, p.Event
+			s.store.Add(r)
+		case p.ServiceCheck != nil:
+			r.Kind, r.ServiceCheck = KindServiceCheck, p.ServiceCheck
+			s.store.Add(r)
+		default:
+			// Multi-value lines are stored as one record per value
+			for _, m := range p.Metrics {
+				r.Kind, r.Metric = KindMetric, m
+				s.store.Add(r)
+			}
+		}
+	}
+}
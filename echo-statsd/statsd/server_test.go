@@ -0,0 +1,197 @@
+package statsd
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startServer serves statsd on random local UDP and TCP ports
+func startServer(t *testing.T) (*Store, string, string) {
+	t.Helper()
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen on UDP: %v", err)
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen on TCP: %v", err)
+	}
+	store := NewStore(100)
+	s := NewServer(store)
+	go func() { _ = s.ServeUDP(pc) }()
+	go func() { _ = s.ServeTCP(l) }()
+	t.Cleanup(func() { _ = s.Close() })
+	return store, pc.LocalAddr().String(), l.Addr().String()
+}
+
+// waitRecords waits until the store holds n records
+func waitRecords(t *testing.T, store *Store, n int) []Record {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		records := store.Query(Filter{})
+		if len(records) >= n {
+			return records
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("got %d records, want %d", len(records), n)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestServeUDP(t *testing.T) {
+	store, udpAddr, _ := startServer(t)
+	conn, err := net.Dial("udp", udpAddr)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	// Clients batch several lines in a datagram
+	_, _ = conn.Write([]byte("hits:1|c\nlatency:5:7|ms\n_sc|up|0\nbogus\n"))
+
+	records := waitRecords(t, store, 5)
+	kinds := []string{KindMetric, KindMetric, KindMetric, KindServiceCheck, ""}
+	for i, r := range records {
+		if r.Kind != kinds[i] || r.Transport != "udp" {
+			t.Errorf("records[%d] = %+v, want kind %q", i, r, kinds[i])
+		}
+	}
+	if records[4].Error == "" || records[4].Raw != "bogus" {
+		t.Errorf("records[4] = %+v, want parse error", records[4])
+	}
+}
+
+func TestServeTCP(t *testing.T) {
+	store, _, tcpAddr := startServer(t)
+	conn, err := net.Dial("tcp", tcpAddr)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	_, _ = conn.Write([]byte("hits:1|c\r\n_e{2,2}:up|ok\n"))
+	_ = conn.Close()
+
+	records := waitRecords(t, store, 2)
+	if records[0].Metric == nil || records[0].Metric.Name != "hits" || records[1].Event == nil || records[1].Transport != "tcp" {
+		t.Errorf("records = %+v", records)
+	}
+}
+
+func TestAggregates(t *testing.T) {
+	store := NewStore(100)
+	s := NewServer(store)
+	s.receive("udp", "test", strings.Join([]string{
+		"hits:1|c|#b,a",
+		"hits:1|c|@0.5|#a,b",
+		"hits:1|c",
+		"temp:20|g",
+		"temp:+5|g",
+		"users:alice|s",
+		"users:bob|s",
+		"users:alice|s",
+		"latency:10:30|ms",
+		"latency:20|ms",
+	}, "\n"))
+
+	got := make(map[string]*Aggregate)
+	for _, a := range Aggregates(store.Query(Filter{})) {
+		got[a.Name+"|"+strings.Join(a.Tags, ",")] = a
+	}
+	if len(got) != 5 {
+		t.Fatalf("got %d aggregates, want 5", len(got))
+	}
+	if a := got["hits|a,b"]; a.Samples != 2 || a.Value != 3 {
+		t.Errorf("tagged counter = %+v, want 2 samples, value 3", a)
+	}
+	if a := got["hits|"]; a.Value != 1 {
+		t.Errorf("counter = %+v, want value 1", a)
+	}
+	if a := got["temp|"]; a.Value != 25 {
+		t.Errorf("gauge = %+v, want 25", a)
+	}
+	if a := got["users|"]; a.Samples != 3 || a.Value != 2 {
+		t.Errorf("set = %+v, want 2 unique", a)
+	}
+	if a := got["latency|"]; a.Samples != 3 || a.Value != 60 || *a.Min != 10 || *a.Max != 30 || *a.Avg != 20 {
+		t.Errorf("timer = %+v, want sum 60, min 10, max 30, avg 20", a)
+	}
+}
+
+func TestQueryAPI(t *testing.T) {
+	store := NewStore(100)
+	s := NewServer(store)
+	s.receive("udp", "test", "hits:1|c|#env:prod\nhits:2|c|#env:dev\nsize:3|g\n_e{2,2}:up|ok|#env:prod\n_sc|db|1")
+	mux := http.NewServeMux()
+	RegisterHandlers(mux, store)
+
+	get := func(target string, v any) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET %s = %d: %s", target, rec.Code, rec.Body)
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+			t.Fatalf("invalid JSON: %v", err)
+		}
+	}
+	count := func(target string) int {
+		t.Helper()
+		var body struct{ Records []Record }
+		get(target, &body)
+		return len(body.Records)
+	}
+
+	tests := map[string]int{
+		"/records":                  5,
+		"/metrics":                  3,
+		"/metrics?name=hits":        2,
+		"/metrics?type=g":           1,
+		"/records?tag=env:prod":     2,
+		"/metrics?tag=env":          2,
+		"/events":                   1,
+		"/service-checks?name=db":   1,
+		"/records?since=4":          1,
+		"/metrics?limit=1":          1,
+		"/records?invalid=true":     0,
+		"/metrics?tag=env&tag=none": 0,
+	}
+	for target, want := range tests {
+		if got := count(target); got != want {
+			t.Errorf("GET %s returned %d records, want %d", target, got, want)
+		}
+	}
+
+	var body struct{ Aggregates []Aggregate }
+	get("/metrics/aggregates?name=hits", &body)
+	if len(body.Aggregates) != 2 || body.Aggregates[0].Value != 1 || body.Aggregates[1].Value != 2 {
+		t.Errorf("GET /metrics/aggregates = %+v", body.Aggregates)
+	}
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics?type=x", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid type = %d, want 400", rec.Code)
+	}
+
+	// wait returns as soon as a matching record arrives
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		s.receive("udp", "test", "late:1|c")
+	}()
+	if got := count("/metrics?name=late&wait=5"); got != 1 {
+		t.Errorf("wait returned %d records, want 1", got)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/records", nil))
+	if !strings.Contains(rec.Body.String(), `"deleted":6`) {
+		t.Errorf("DELETE /records = %s", rec.Body)
+	}
+}
@@ -0,0 +1,157 @@
+package statsd
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Record is a received metric sample, event or service check. Lines that
+// cannot be parsed are kept with Error set and only the raw text.
+type Record struct {
+	ID           int64         `json:"id"`
+	ReceivedAt   time.Time     `json:"receivedAt"`
+	Transport    string        `json:"transport"`
+	RemoteAddr   string        `json:"remoteAddr"`
+	Kind         string        `json:"kind,omitempty"`
+	Metric       *Metric       `json:"metric,omitempty"`
+	Event        *Event        `json:"event,omitempty"`
+	ServiceCheck *ServiceCheck `json:"serviceCheck,omitempty"`
+	Raw          string        `json:"raw"`
+	Error        string        `json:"error,omitempty"`
+}
+
+// Filter selects records; zero fields match everything
+type Filter struct {
+	// SinceID only matches records with a greater ID
+	SinceID int64
+
+	// Kind matches the record kind (metric, event, service_check)
+	Kind string
+
+	// Name matches the metric name, event title or service check name
+	Name string
+
+	// Type matches the metric type
+	Type string
+
+	// Tags must all be present ("key:value" exactly, "key" with any value)
+	Tags []string
+
+	// Invalid matches lines that could not be parsed (true) or only parsed
+	// ones (false)
+	Invalid *bool
+
+	// Limit caps the number of records returned (the latest ones win)
+	Limit int
+}
+
+// Match reports whether r is selected by f
+func (f *Filter) Match(r *Record) bool {
+	if r.ID <= f.SinceID {
+		return false
+	}
+	if f.Invalid != nil && *f.Invalid != (r.Error != "") {
+		return false
+	}
+	if f.Kind != "" && r.Kind != f.Kind {
+		return false
+	}
+
+	var name, typ string
+	var tags []string
+	switch {
+	case r.Metric != nil:
+		name, typ, tags = r.Metric.Name, r.Metric.Type, r.Metric.Tags
+	case r.Event != nil:
+		name, tags = r.Event.Title, r.Event.Tags
+	case r.ServiceCheck != nil:
+		name, tags = r.ServiceCheck.Name, r.ServiceCheck.Tags
+	}
+	if f.Name != "" && name != f.Name || f.Type != "" && typ != f.Type {
+		return false
+	}
+	for _, tag := range f.Tags {
+		if !hasTag(tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// Store keeps the latest records in memory
+type Store struct {
+	mu      sync.Mutex
+	max     int
+	nextID  int64
+	records []Record
+	changed chan struct{}
+}
+
+// NewStore creates a store that keeps up to max records, dropping the oldest
+func NewStore(max int) *Store {
+	return &Store{max: max, changed: make(chan struct{})}
+}
+
+// Add stores a record, assigning its ID
+func (s *Store) Add(r Record) Record {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	r.ID = s.nextID
+	if len(s.records) >= s.max {
+		n := copy(s.records, s.records[len(s.records)-s.max+1:])
+		s.records = s.records[:n]
+	}
+	s.records = append(s.records, r)
+	close(s.changed)
+	s.changed = make(chan struct{})
+	return r
+}
+
+// Query returns the records matching f, oldest first
+func (s *Store) Query(f Filter) []Record {
+	records, _ := s.query(f)
+	return records
+}
+
+func (s *Store) query(f Filter) ([]Record, <-chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	records := []Record{}
+	for i := range s.records {
+		if f.Match(&s.records[i]) {
+			records = append(records, s.records[i])
+		}
+	}
+	if f.Limit > 0 && len(records) > f.Limit {
+		records = records[len(records)-f.Limit:]
+	}
+	return records, s.changed
+}
+
+// Wait returns the records matching f, waiting until there is at least one
+// or ctx is done
+func (s *Store) Wait(ctx context.Context, f Filter) []Record {
+	for {
+		records, changed := s.query(f)
+		if len(records) > 0 {
+			return records
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return records
+		}
+	}
+}
+
+// Clear removes all records and returns how many there were. IDs keep
+// increasing, so SinceID stays valid.
+func (s *Store) Clear() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := len(s.records)
+	s.records = nil
+	return n
+}
@@ -0,0 +1,107 @@
+package syslog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// MaxWait bounds the wait query parameter
+const MaxWait = 30 * time.Second
+
+// RegisterHandlers adds the query API of the store to mux:
+//
+//	GET    /messages  records matching the query parameters
+//	DELETE /messages  remove all records
+func RegisterHandlers(mux *http.ServeMux, store *Store) {
+	mux.HandleFunc("GET /messages", func(w http.ResponseWriter, r *http.Request) {
+		f, wait, err := parseQuery(r.URL.Query())
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		var records []Record
+		if wait > 0 {
+			ctx, cancel := context.WithTimeout(r.Context(), wait)
+			defer cancel()
+			records = store.Wait(ctx, f)
+		} else {
+			records = store.Query(f)
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"messages": records})
+	})
+
+	mux.HandleFunc("DELETE /messages", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]int{"deleted": store.Clear()})
+	})
+}
+
+// parseQuery builds a filter and the wait duration from query parameters
+func parseQuery(q url.Values) (Filter, time.Duration, error) {
+	f := Filter{
+		Hostname: q.Get("hostname"),
+		AppName:  q.Get("app"),
+		ProcID:   q.Get("procid"),
+		MsgID:    q.Get("msgid"),
+		SDID:     q.Get("sd_id"),
+		Contains: q.Get("contains"),
+	}
+
+	if v := q.Get("facility"); v != "" {
+		n, err := ParseFacility(v)
+		if err != nil {
+			return f, 0, err
+		}
+		f.Facility = &n
+	}
+	for name, dst := range map[string]**int{"severity": &f.Severity, "max_severity": &f.MaxSeverity} {
+		if v := q.Get(name); v != "" {
+			n, err := ParseSeverity(v)
+			if err != nil {
+				return f, 0, err
+			}
+			*dst = &n
+		}
+	}
+	if v := q.Get("invalid"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return f, 0, fmt.Errorf("invalid must be true or false, got %q", v)
+		}
+		f.Invalid = &b
+	}
+	if v := q.Get("since"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			return f, 0, fmt.Errorf("invalid since %q (must be a record ID)", v)
+		}
+		f.SinceID = n
+	}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return f, 0, fmt.Errorf("invalid limit %q (must be >= 1)", v)
+		}
+		f.Limit = n
+	}
+
+	var wait time.Duration
+	if v := q.Get("wait"); v != "" {
+		seconds, err := strconv.ParseFloat(v, 64)
+		wait = time.Duration(seconds * float64(time.Second))
+		if err != nil || wait < 0 || wait > MaxWait {
+			return f, 0, fmt.Errorf("invalid wait %q (must be 0-%d seconds)", v, int(MaxWait.Seconds()))
+		}
+	}
+	return f, wait, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,288 @@
+package syslog
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Message formats
+const (
+	FormatRFC5424 = "rfc5424"
+	FormatRFC3164 = "rfc3164"
+)
+
+// nilValue is the NILVALUE of RFC 5424 headers and structured data
+const nilValue = "-"
+
+// bom is the UTF-8 byte order mark allowed at the start of RFC 5424 messages
+const bom = "\xef\xbb\xbf"
+
+var facilityNames = []string{
+	"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news",
+	"uucp", "cron", "authpriv", "ftp", "ntp", "security", "console", "solaris-cron",
+	"local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7",
+}
+
+var severityNames = []string{
+	"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug",
+}
+
+// FacilityName returns the keyword of a facility code ("local0")
+func FacilityName(facility int) string {
+	if facility < 0 || facility >= len(facilityNames) {
+		return strconv.Itoa(facility)
+	}
+	return facilityNames[facility]
+}
+
+// SeverityName returns the keyword of a severity code ("warning")
+func SeverityName(severity int) string {
+	if severity < 0 || severity >= len(severityNames) {
+		return strconv.Itoa(severity)
+	}
+	return severityNames[severity]
+}
+
+// ParseFacility parses a facility keyword or code
+func ParseFacility(s string) (int, error) {
+	return parseCode(s, facilityNames, "facility")
+}
+
+// ParseSeverity parses a severity keyword or code; "error", "warn" and
+// "emergency" are accepted as aliases
+func ParseSeverity(s string) (int, error) {
+	switch strings.ToLower(s) {
+	case "error":
+		return 3, nil
+	case "warn":
+		return 4, nil
+	case "emergency", "panic":
+		return 0, nil
+	}
+	return parseCode(s, severityNames, "severity")
+}
+
+func parseCode(s string, names []string, kind string) (int, error) {
+	for i, name := range names {
+		if strings.EqualFold(s, name) {
+			return i, nil
+		}
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 || n >= len(names) {
+		return 0, fmt.Errorf("invalid %s %q", kind, s)
+	}
+	return n, nil
+}
+
+// Message is a parsed syslog message
+type Message struct {
+	Format         string                       `json:"format"`
+	Facility       int                          `json:"facility"`
+	FacilityName   string                       `json:"facilityName"`
+	Severity       int                          `json:"severity"`
+	SeverityName   string                       `json:"severityName"`
+	Version        int                          `json:"version,omitempty"`
+	Timestamp      string                       `json:"timestamp,omitempty"`
+	Hostname       string                       `json:"hostname,omitempty"`
+	AppName        string                       `json:"appName,omitempty"`
+	ProcID         string                       `json:"procId,omitempty"`
+	MsgID          string                       `json:"msgId,omitempty"`
+	StructuredData map[string]map[string]string `json:"structuredData,omitempty"`
+	Message        string                       `json:"message"`
+}
+
+var errNoPriority = errors.New("missing <PRI> priority")
+
+// Parse parses an RFC 5424 message, or an RFC 3164 (BSD) message when the
+// priority is not followed by a version
+func Parse(raw string) (*Message, error) {
+	pri, rest, err := parsePriority(raw)
+	if err != nil {
+		return nil, err
+	}
+	m := &Message{
+		Facility:     pri / 8,
+		FacilityName: FacilityName(pri / 8),
+		Severity:     pri % 8,
+		SeverityName: SeverityName(pri % 8),
+	}
+
+	if version, after, ok := strings.Cut(rest, " "); ok && version != "" && isDigits(version) {
+		m.Format = FormatRFC5424
+		m.Version, _ = strconv.Atoi(version)
+		if err := parse5424(m, after); err != nil {
+			return nil, err
+		}
+		return m, nil
+	}
+	m.Format = FormatRFC3164
+	parse3164(m, rest)
+	return m, nil
+}
+
+func parsePriority(raw string) (int, string, error) {
+	if !strings.HasPrefix(raw, "<") {
+		return 0, "", errNoPriority
+	}
+	end := strings.IndexByte(raw, '>')
+	if end < 2 || end > 4 || !isDigits(raw[1:end]) {
+		return 0, "", errNoPriority
+	}
+	pri, _ := strconv.Atoi(raw[1:end])
+	if pri > 191 {
+		return 0, "", fmt.Errorf("invalid priority %d (must be 0-191)", pri)
+	}
+	return pri, raw[end+1:], nil
+}
+
+// parse5424 parses the header fields after the version, the structured data
+// and the message
+func parse5424(m *Message, s string) error {
+	fields := make([]string, 5)
+	for i := range fields {
+		field, rest, ok := strings.Cut(s, " ")
+		if !ok {
+			return fmt.Errorf("truncated RFC 5424 header")
+		}
+		if field == "" {
+			return fmt.Errorf("empty RFC 5424 header field")
+		}
+		fields[i], s = field, rest
+	}
+	if fields[0] != nilValue {
+		if _, err := time.Parse(time.RFC3339Nano, fields[0]); err != nil {
+			return fmt.Errorf("invalid timestamp %q", fields[0])
+		}
+		m.Timestamp = fields[0]
+	}
+	m.Hostname = nilToEmpty(fields[1])
+	m.AppName = nilToEmpty(fields[2])
+	m.ProcID = nilToEmpty(fields[3])
+	m.MsgID = nilToEmpty(fields[4])
+
+	sd, rest, err := parseStructuredData(s)
+	if err != nil {
+		return err
+	}
+	m.StructuredData = sd
+	if msg, ok := strings.CutPrefix(rest, " "); ok {
+		m.Message = strings.TrimPrefix(msg, bom)
+	} else if rest != "" {
+		return fmt.Errorf("missing space before message")
+	}
+	return nil
+}
+
+// parseStructuredData parses the SD-ELEMENTs at the start of s and returns
+// the rest
+func parseStructuredData(s string) (map[string]map[string]string, string, error) {
+	if rest, ok := strings.CutPrefix(s, nilValue); ok {
+		return nil, rest, nil
+	}
+	if !strings.HasPrefix(s, "[") {
+		return nil, "", fmt.Errorf("invalid structured data")
+	}
+	sd := make(map[string]map[string]string)
+	for strings.HasPrefix(s, "[") {
+		s = s[1:]
+		end := strings.IndexAny(s, " ]")
+		if end < 1 {
+			return nil, "", fmt.Errorf("invalid SD-ID")
+		}
+		params := make(map[string]string)
+		sd[s[:end]] = params
+		s = s[end:]
+		for strings.HasPrefix(s, " ") {
+			name, rest, ok := strings.Cut(s[1:], `="`)
+			if !ok || name == "" {
+				return nil, "", fmt.Errorf("invalid SD-PARAM")
+			}
+			value, rest, err := parseParamValue(rest)
+			if err != nil {
+				return nil, "", err
+			}
+			params[name] = value
+			s = rest
+		}
+		if !strings.HasPrefix(s, "]") {
+			return nil, "", fmt.Errorf("unterminated SD-ELEMENT")
+		}
+		s = s[1:]
+	}
+	return sd, s, nil
+}
+
+// parseParamValue reads a PARAM-VALUE up to its closing quote, unescaping
+// \" \\ and \]
+func parseParamValue(s string) (string, string, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\\':
+			if i+1 < len(s) && (s[i+1] == '"' || s[i+1] == '\\' || s[i+1] == ']') {
+				i++
+				b.WriteByte(s[i])
+			} else {
+				b.WriteByte(c)
+			}
+		case '"':
+			return b.String(), s[i+1:], nil
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", "", fmt.Errorf("unterminated PARAM-VALUE")
+}
+
+// parse3164 parses "Mmm dd hh:mm:ss HOSTNAME TAG[PID]: MSG" leniently: parts
+// that do not match are left in the message
+func parse3164(m *Message, s string) {
+	if len(s) >= 16 && s[15] == ' ' {
+		if _, err := time.Parse(time.Stamp, s[:15]); err == nil {
+			m.Timestamp = s[:15]
+			s = s[16:]
+			if host, rest, ok := strings.Cut(s, " "); ok && host != "" && !strings.HasSuffix(host, ":") {
+				m.Hostname = host
+				s = rest
+			}
+		}
+	}
+
+	// The tag ends at the first character that is not alphanumeric or
+	// one of the usual program name characters
+	end := strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./", r))
+	})
+	if end > 0 {
+		tag, rest := s[:end], s[end:]
+		if pid, after, ok := strings.Cut(rest, "]"); ok && strings.HasPrefix(pid, "[") {
+			m.ProcID = pid[1:]
+			rest = after
+		}
+		if msg, ok := strings.CutPrefix(rest, ":"); ok {
+			m.AppName = tag
+			s = strings.TrimPrefix(msg, " ")
+		}
+	}
+	m.Message = s
+}
+
+func nilToEmpty(s string) string {
+	if s == nilValue {
+		return ""
+	}
+	return s
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
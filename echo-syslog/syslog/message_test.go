@@ -0,0 +1,131 @@
+package syslog
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseRFC5424(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want Message
+	}{
+		{
+			name: "full header",
+			raw:  "<34>1 2003-10-11T22:14:15.003Z mymachine.example.com su - ID47 - 'su root' failed for lonvick on /dev/pts/8",
+			want: Message{
+				Format: FormatRFC5424, Facility: 4, FacilityName: "auth", Severity: 2, SeverityName: "crit",
+				Version: 1, Timestamp: "2003-10-11T22:14:15.003Z", Hostname: "mymachine.example.com",
+				AppName: "su", MsgID: "ID47", Message: "'su root' failed for lonvick on /dev/pts/8",
+			},
+		},
+		{
+			name: "structured data and BOM",
+			raw:  `<165>1 2003-10-11T22:14:15.003-07:00 host evntslog 1234 ID47 [exampleSDID@32473 iut="3" eventSource="App\"lication\]"][origin ip="192.0.2.1"] ` + bom + "An application event",
+			want: Message{
+				Format: FormatRFC5424, Facility: 20, FacilityName: "local4", Severity: 5, SeverityName: "notice",
+				Version: 1, Timestamp: "2003-10-11T22:14:15.003-07:00", Hostname: "host",
+				AppName: "evntslog", ProcID: "1234", MsgID: "ID47",
+				StructuredData: map[string]map[string]string{
+					"exampleSDID@32473": {"iut": "3", "eventSource": `App"lication]`},
+					"origin":            {"ip": "192.0.2.1"},
+				},
+				Message: "An application event",
+			},
+		},
+		{
+			name: "nil values without message",
+			raw:  "<0>1 - - - - - -",
+			want: Message{
+				Format: FormatRFC5424, FacilityName: "kern", SeverityName: "emerg", Version: 1,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := Parse(tt.raw)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if !reflect.DeepEqual(*m, tt.want) {
+				t.Errorf("Parse() = %+v, want %+v", *m, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRFC3164(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want Message
+	}{
+		{
+			name: "full header",
+			raw:  "<13>Oct 11 22:14:15 mymachine sshd[42]: Accepted publickey",
+			want: Message{
+				Format: FormatRFC3164, Facility: 1, FacilityName: "user", Severity: 5, SeverityName: "notice",
+				Timestamp: "Oct 11 22:14:15", Hostname: "mymachine", AppName: "sshd", ProcID: "42",
+				Message: "Accepted publickey",
+			},
+		},
+		{
+			name: "tag without header",
+			raw:  "<14>myapp: started",
+			want: Message{
+				Format: FormatRFC3164, Facility: 1, FacilityName: "user", Severity: 6, SeverityName: "info",
+				AppName: "myapp", Message: "started",
+			},
+		},
+		{
+			name: "free text",
+			raw:  "<14>something happened",
+			want: Message{
+				Format: FormatRFC3164, Facility: 1, FacilityName: "user", Severity: 6, SeverityName: "info",
+				Message: "something happened",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := Parse(tt.raw)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if !reflect.DeepEqual(*m, tt.want) {
+				t.Errorf("Parse() = %+v, want %+v", *m, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, raw := range []string{
+		"no priority",
+		"<192>1 - - - - - -",
+		"<34>1 2003-10-11 host app - - -",
+		"<34>1 - host app",
+		`<34>1 - host app - - [id a="b"`,
+		`<34>1 - host app - - [id a=b]`,
+		"<34>1 - host app - - -message",
+	} {
+		if _, err := Parse(raw); err == nil {
+			t.Errorf("Parse(%q) succeeded, want error", raw)
+		}
+	}
+}
+
+func TestParseSeverity(t *testing.T) {
+	for s, want := range map[string]int{"err": 3, "error": 3, "WARNING": 4, "warn": 4, "7": 7, "emerg": 0} {
+		if got, err := ParseSeverity(s); err != nil || got != want {
+			t.Errorf("ParseSeverity(%q) = %d, %v, want %d", s, got, err, want)
+		}
+	}
+	if _, err := ParseSeverity("8"); err == nil {
+		t.Error("ParseSeverity(8) succeeded, want error")
+	}
+	if got, err := ParseFacility("local7"); err != nil || got != 23 {
+		t.Errorf("ParseFacility(local7) = %d, %v, want 23", got, err)
+	}
+}
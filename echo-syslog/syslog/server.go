@@ -0,0 +1,215 @@
+package syslog
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrServerClosed is returned by ServeUDP and ServeTCP after Close
+var ErrServerClosed = errors.New("server closed")
+
+// MaxMessageSize bounds a single message; longer TCP frames close the
+// connection and longer datagrams are truncated by the read buffer
+const MaxMessageSize = 64 * 1024
+
+// Server receives syslog messages over UDP and TCP into a store
+type Server struct {
+	store *Store
+
+	mu        sync.Mutex
+	udp       map[net.PacketConn]struct{}
+	listeners map[net.Listener]struct{}
+	conns     map[net.Conn]struct{}
+	closed    bool
+}
+
+// NewServer creates a server storing messages in store
+func NewServer(store *Store) *Server {
+	return &Server{
+		store:     store,
+		udp:       make(map[net.PacketConn]struct{}),
+		listeners: make(map[net.Listener]struct{}),
+		conns:     make(map[net.Conn]struct{}),
+	}
+}
+
+// ServeUDP stores a message for every datagram received on pc until Close
+// is called (RFC 5426)
+func (s *Server) ServeUDP(pc net.PacketConn) error {
+	if !s.track(func() { s.udp[pc] = struct{}{} }) {
+		return ErrServerClosed
+	}
+
+	buf := make([]byte, MaxMessageSize)
+	for {
+		n, addr, err := pc.ReadFrom(buf)
+		if err != nil {
+			if s.isClosed() {
+				return ErrServerClosed
+			}
+			return err
+		}
+		s.receive("udp", addr.String(), string(buf[:n]))
+	}
+}
+
+// ServeTCP stores the messages of the connections accepted on l until Close
+// is called. Messages are framed by octet counting or terminated by a line
+// feed (RFC 6587).
+func (s *Server) ServeTCP(l net.Listener) error {
+	if !s.track(func() { s.listeners[l] = struct{}{} }) {
+		return ErrServerClosed
+	}
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if s.isClosed() {
+				return ErrServerClosed
+			}
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				time.Sleep(10 * time.Millisecond)
+				continue
+			}
+			return err
+		}
+		go s.serveConn(conn)
+	}
+}
+
+// Close stops the listeners and closes the TCP connections
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+
+	var errs []error
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+	for pc := range s.udp {
+		errs = append(errs, pc.Close())
+	}
+	for l := range s.listeners {
+		errs = append(errs, l.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// track registers a transport unless the server is closed
+func (s *Server) track(add func()) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return false
+	}
+	add()
+	return true
+}
+
+func (s *Server) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	if !s.track(func() { s.conns[conn] = struct{}{} }) {
+		_ = conn.Close()
+		return
+	}
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		_ = conn.Close()
+	}()
+
+	remote := conn.RemoteAddr().String()
+	r := bufio.NewReaderSize(conn, 4096)
+	for {
+		msg, err := readFrame(r)
+		if err != nil {
+			if msg != "" {
+				s.receive("tcp", remote, msg)
+			}
+			return
+		}
+		s.receive("tcp", remote, msg)
+	}
+}
+
+var errFrameTooLarge = errors.New("frame too large")
+
+// readFrame reads an octet-counted ("LEN SP MSG") or LF-terminated message.
+// At EOF, an unterminated message is returned with the error.
+func readFrame(r *bufio.Reader) (string, error) {
+	first, err := r.Peek(1)
+	if err != nil {
+		return "", err
+	}
+	if first[0] >= '1' && first[0] <= '9' {
+		prefix, err := r.ReadString(' ')
+		if err != nil {
+			return "", err
+		}
+		n, err := strconv.Atoi(strings.TrimSuffix(prefix, " "))
+		if err != nil {
+			return "", err
+		}
+		if n > MaxMessageSize {
+			return "", errFrameTooLarge
+		}
+		buf := make([]byte, n)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return "", err
+		}
+		return string(buf), nil
+	}
+
+	var line []byte
+	for {
+		chunk, err := r.ReadSlice('\n')
+		line = append(line, chunk...)
+		if len(line) > MaxMessageSize+1 {
+			return "", errFrameTooLarge
+		}
+		if err == nil {
+			return strings.TrimRight(string(line), "\r\n"), nil
+		}
+		if !errors.Is(err, bufio.ErrBufferFull) {
+			return strings.TrimRight(string(line), "\r\n\x00"), err
+		}
+	}
+}
+
+// receive parses and stores a message
+func (s *Server) receive(transport, remote, raw string) {
+	raw = strings.TrimRight(raw, "\r\n\x00")
+	if raw == "" {
+		return
+	}
+	r := Record{
+		ReceivedAt: time.Now().UTC(),
+		Transport:  transport,
+		RemoteAddr: remote,
+		Raw:        raw,
+	}
+	m, err := Parse(raw)
+	if err != nil {
+		r.Error = err.Error()
+	} else {
+		r.Message = m
+	}
+	s.store.Add(r)
+}
@@ -0,0 +1,191 @@
+package syslog
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startServer serves syslog on random local UDP and TCP ports
+func startServer(t *testing.T, maxRecords int) (*Store, string, string) {
+	t.Helper()
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen on UDP: %v", err)
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen on TCP: %v", err)
+	}
+	store := NewStore(maxRecords)
+	s := NewServer(store)
+	go func() { _ = s.ServeUDP(pc) }()
+	go func() { _ = s.ServeTCP(l) }()
+	t.Cleanup(func() { _ = s.Close() })
+	return store, pc.LocalAddr().String(), l.Addr().String()
+}
+
+// waitRecords waits until the store holds n records
+func waitRecords(t *testing.T, store *Store, n int) []Record {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		records := store.Query(Filter{})
+		if len(records) >= n {
+			return records
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("got %d records, want %d", len(records), n)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestServeUDP(t *testing.T) {
+	store, udpAddr, _ := startServer(t, 100)
+	conn, err := net.Dial("udp", udpAddr)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	_, _ = conn.Write([]byte("<14>1 - host app 1 - - hello\n"))
+	_, _ = conn.Write([]byte("garbage"))
+
+	records := waitRecords(t, store, 2)
+	if r := records[0]; r.Transport != "udp" || r.Message == nil || r.Message.Message != "hello" || r.Raw != "<14>1 - host app 1 - - hello" {
+		t.Errorf("records[0] = %+v", r)
+	}
+	if r := records[1]; r.Message != nil || r.Error == "" || r.Raw != "garbage" {
+		t.Errorf("records[1] = %+v, want parse error", r)
+	}
+}
+
+func TestServeTCPFraming(t *testing.T) {
+	store, _, tcpAddr := startServer(t, 100)
+	conn, err := net.Dial("tcp", tcpAddr)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+
+	_, _ = conn.Write([]byte("<14>1 - host app - - - first\r\n"))
+	_, _ = conn.Write([]byte("33 <14>1 - host app - - - multi\nline"))
+	_, _ = conn.Write([]byte("<14>1 - host app - - - last"))
+	_ = conn.Close()
+
+	records := waitRecords(t, store, 3)
+	want := []string{"first", "multi\nline", "last"}
+	for i, r := range records {
+		if r.Transport != "tcp" || r.Message == nil || r.Message.Message != want[i] {
+			t.Errorf("records[%d] = %+v, want message %q", i, r, want[i])
+		}
+	}
+}
+
+func TestStoreLimit(t *testing.T) {
+	store := NewStore(2)
+	for _, msg := range []string{"a", "b", "c"} {
+		store.Add(Record{Raw: msg})
+	}
+	records := store.Query(Filter{})
+	if len(records) != 2 || records[0].Raw != "b" || records[1].ID != 3 {
+		t.Errorf("records = %+v, want b and c", records)
+	}
+	if n := store.Clear(); n != 2 {
+		t.Errorf("Clear() = %d, want 2", n)
+	}
+	if r := store.Add(Record{Raw: "d"}); r.ID != 4 {
+		t.Errorf("ID after Clear = %d, want 4", r.ID)
+	}
+}
+
+func TestQueryAPI(t *testing.T) {
+	store := NewStore(100)
+	for _, raw := range []string{
+		"<11>1 - web api 1 - - disk failure",
+		"<14>1 - web api 1 - [req id=\"7\"] request served",
+		"<134>1 - db postgres 2 - - checkpoint",
+	} {
+		m, err := Parse(raw)
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", raw, err)
+		}
+		store.Add(Record{Message: m, Raw: raw})
+	}
+	mux := http.NewServeMux()
+	RegisterHandlers(mux, store)
+
+	query := func(q string) []Record {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/messages?"+q, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET /messages?%s = %d: %s", q, rec.Code, rec.Body)
+		}
+		var body struct{ Messages []Record }
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("invalid JSON: %v", err)
+		}
+		return body.Messages
+	}
+
+	tests := []struct {
+		query string
+		want  []int64
+	}{
+		{"", []int64{1, 2, 3}},
+		{"app=api", []int64{1, 2}},
+		{"severity=err", []int64{1}},
+		{"max_severity=warning", []int64{1}},
+		{"facility=local0", []int64{3}},
+		{"hostname=db", []int64{3}},
+		{"sd_id=req", []int64{2}},
+		{"contains=served", []int64{2}},
+		{"since=1&limit=1", []int64{3}},
+	}
+	for _, tt := range tests {
+		var ids []int64
+		for _, r := range query(tt.query) {
+			ids = append(ids, r.ID)
+		}
+		if len(ids) != len(tt.want) {
+			t.Errorf("GET /messages?%s = %v, want %v", tt.query, ids, tt.want)
+			continue
+		}
+		for i := range ids {
+			if ids[i] != tt.want[i] {
+				t.Errorf("GET /messages?%s = %v, want %v", tt.query, ids, tt.want)
+				break
+			}
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/messages?severity=loud", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid severity = %d, want 400", rec.Code)
+	}
+
+	// wait returns as soon as a matching record arrives
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		store.Add(Record{Raw: "late"})
+	}()
+	start := time.Now()
+	if got := query("since=3&wait=5"); len(got) != 1 || got[0].Raw != "late" {
+		t.Errorf("wait = %+v, want the late record", got)
+	}
+	if time.Since(start) > 4*time.Second {
+		t.Error("wait did not return when the record arrived")
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/messages", nil))
+	if !strings.Contains(rec.Body.String(), `"deleted":4`) {
+		t.Errorf("DELETE /messages = %s", rec.Body)
+	}
+}
@@ -0,0 +1,160 @@
+package syslog
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Record is a received message with its transport details. Messages that
+// cannot be parsed are kept with Error set and only the raw text.
+type Record struct {
+	ID         int64     `json:"id"`
+	ReceivedAt time.Time `json:"receivedAt"`
+	Transport  string    `json:"transport"`
+	RemoteAddr string    `json:"remoteAddr"`
+	*Message
+	Raw   string `json:"raw"`
+	Error string `json:"error,omitempty"`
+}
+
+// Filter selects records; zero fields match everything
+type Filter struct {
+	// SinceID only matches records with a greater ID
+	SinceID int64
+
+	// Facility and Severity match exactly when non-nil; MaxSeverity
+	// matches the given severity and the more severe ones
+	Facility    *int
+	Severity    *int
+	MaxSeverity *int
+
+	Hostname string
+	AppName  string
+	ProcID   string
+	MsgID    string
+
+	// SDID matches records with that structured data element
+	SDID string
+
+	// Contains matches a substring of the message
+	Contains string
+
+	// Invalid matches records that could not be parsed (true) or only
+	// parsed ones (false)
+	Invalid *bool
+
+	// Limit caps the number of records returned (the latest ones win)
+	Limit int
+}
+
+// Match reports whether r is selected by f
+func (f *Filter) Match(r *Record) bool {
+	if r.ID <= f.SinceID {
+		return false
+	}
+	if f.Invalid != nil && *f.Invalid != (r.Message == nil) {
+		return false
+	}
+	m := r.Message
+	if m == nil {
+		return f.Facility == nil && f.Severity == nil && f.MaxSeverity == nil &&
+			f.Hostname == "" && f.AppName == "" && f.ProcID == "" && f.MsgID == "" &&
+			f.SDID == "" && (f.Contains == "" || strings.Contains(r.Raw, f.Contains))
+	}
+	switch {
+	case f.Facility != nil && m.Facility != *f.Facility,
+		f.Severity != nil && m.Severity != *f.Severity,
+		f.MaxSeverity != nil && m.Severity > *f.MaxSeverity,
+		f.Hostname != "" && m.Hostname != f.Hostname,
+		f.AppName != "" && m.AppName != f.AppName,
+		f.ProcID != "" && m.ProcID != f.ProcID,
+		f.MsgID != "" && m.MsgID != f.MsgID,
+		f.Contains != "" && !strings.Contains(m.Message, f.Contains):
+		return false
+	}
+	if f.SDID != "" {
+		if _, ok := m.StructuredData[f.SDID]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Store keeps the latest records in memory
+type Store struct {
+	mu      sync.Mutex
+	max     int
+	nextID  int64
+	records []Record
+	changed chan struct{}
+}
+
+// NewStore creates a store that keeps up to max records, dropping the oldest
+func NewStore(max int) *Store {
+	return &Store{max: max, changed: make(chan struct{})}
+}
+
+// Add stores a record, assigning its ID
+func (s *Store) Add(r Record) Record {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	r.ID = s.nextID
+	if len(s.records) >= s.max {
+		n := copy(s.records, s.records[len(s.records)-s.max+1:])
+		s.records = s.records[:n]
+	}
+	s.records = append(s.records, r)
+	close(s.changed)
+	s.changed = make(chan struct{})
+	return r
+}
+
+// Query returns the records matching f, oldest first
+func (s *Store) Query(f Filter) []Record {
+	records, _ := s.query(f)
+	return records
+}
+
+func (s *Store) query(f Filter) ([]Record, <-chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	records := []Record{}
+	for i := range s.records {
+		if f.Match(&s.records[i]) {
+			records = append(records, s.records[i])
+		}
+	}
+	if f.Limit > 0 && len(records) > f.Limit {
+		records = records[len(records)-f.Limit:]
+	}
+	return records, s.changed
+}
+
+// Wait returns the records matching f, waiting until there is at least one
+// or ctx is done
+func (s *Store) Wait(ctx context.Context, f Filter) []Record {
+	for {
+		records, changed := s.query(f)
+		if len(records) > 0 {
+			return records
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return records
+		}
+	}
+}
+
+// Clear removes all records and returns how many there were. IDs keep
+// increasing, so SinceID stays valid.
+func (s *Store) Clear() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := len(s.records)
+	s.records = nil
+	return n
+}
@@ -0,0 +1,9 @@
+// Package docs holds the API reference of echo-websocket, served at /
+package docs
+
+import _ "embed"
+
+// API is the API reference (api.md)
+//
+//go:embed api.md
+var API string
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// maxBinarySize is the largest generated binary message (16 MiB)
+const maxBinarySize = 16 * 1024 * 1024
+
+// BinaryHandler sends count binary messages of size bytes, then closes the
+// connection normally. Byte i of each message is i % 256, so clients can
+// verify the payload.
+// GET /ws/binary?size={n}&count={n}&intervalMs={ms}
+func BinaryHandler(w http.ResponseWriter, r *http.Request) {
+	size, err := queryInt(r, "size", 1024, 0, maxBinarySize)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	count, err := queryInt(r, "count", 1, 1, 1000)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	intervalMs, err := queryInt(r, "intervalMs", 0, 0, maxIntervalMs)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s, ok := upgrade(w, r, upgradeOptions{})
+	if !ok {
+		return
+	}
+
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	for i := range count {
+		if i > 0 && !s.wait(time.Duration(intervalMs)*time.Millisecond) {
+			s.close()
+			return
+		}
+		if err := s.conn.WriteMessage(websocket.BinaryMessage, data); err != nil {
+			s.close()
+			return
+		}
+	}
+	s.closeWith(websocket.CloseNormalClosure, "complete")
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestBinaryHandler(t *testing.T) {
+	url := newTestServer(t, "/ws/binary", BinaryHandler)
+	conn := dial(t, websocket.DefaultDialer, url+"/ws/binary?size=300&count=3")
+
+	for i := range 3 {
+		messageType, data, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("message %d: read failed: %v", i, err)
+		}
+		if messageType != websocket.BinaryMessage || len(data) != 300 {
+			t.Fatalf("message %d: expected 300 binary bytes, got type %d with %d bytes", i, messageType, len(data))
+		}
+		for j, b := range data {
+			if b != byte(j) {
+				t.Fatalf("message %d: byte %d is %d, expected %d", i, j, b, byte(j))
+			}
+		}
+	}
+	expectClose(t, conn, websocket.CloseNormalClosure, "complete")
+}
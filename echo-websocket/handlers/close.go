@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// CloseHandler echoes messages until a scripted close: after afterMessages
+// echoed messages and/or afterMs milliseconds (whichever comes first), or
+// right after the handshake when neither is set.
+// GET /ws/close?code={code}&reason={text}&afterMs={ms}&afterMessages={n}
+func CloseHandler(w http.ResponseWriter, r *http.Request) {
+	code := 1000
+	if value := r.URL.Query().Get("code"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || !validCloseCode(n) {
+			http.Error(w, fmt.Sprintf("Invalid close code %q (must be 1000-1003, 1006-1014 or 3000-4999)", value), http.StatusBadRequest)
+			return
+		}
+		code = n
+	}
+	reason := r.URL.Query().Get("reason")
+	if len(reason) > 123 {
+		http.Error(w, "Invalid reason (must be at most 123 bytes)", http.StatusBadRequest)
+		return
+	}
+	afterMs, err := queryInt(r, "afterMs", 0, 0, maxIntervalMs)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	afterMessages, err := queryInt(r, "afterMessages", 0, 0, 10000)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s, ok := upgrade(w, r, upgradeOptions{})
+	if !ok {
+		return
+	}
+	if afterMs == 0 && afterMessages == 0 {
+		s.closeWith(code, reason)
+		return
+	}
+
+	var deadline <-chan time.Time
+	if afterMs > 0 {
+		timer := time.NewTimer(time.Duration(afterMs) * time.Millisecond)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+	echoed := 0
+	for {
+		select {
+		case msg, ok := <-s.messages:
+			if !ok {
+				s.close()
+				return
+			}
+			if err := s.conn.WriteMessage(msg.Type, msg.Data); err != nil {
+				s.close()
+				return
+			}
+			echoed++
+			if echoed == afterMessages {
+				s.closeWith(code, reason)
+				return
+			}
+		case <-deadline:
+			s.closeWith(code, reason)
+			return
+		}
+	}
+}
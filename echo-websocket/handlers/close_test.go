@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestCloseHandler(t *testing.T) {
+	url := newTestServer(t, "/ws/close", CloseHandler)
+
+	tests := []struct {
+		name     string
+		query    string
+		messages int
+		code     int
+		reason   string
+	}{
+		{name: "immediate default", query: "", code: 1000},
+		{name: "custom code and reason", query: "code=4000&reason=bye", code: 4000, reason: "bye"},
+		{name: "after messages", query: "code=1011&afterMessages=2", messages: 2, code: 1011},
+		{name: "after delay", query: "code=3001&afterMs=50", code: 3001},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := dial(t, websocket.DefaultDialer, url+"/ws/close?"+tt.query)
+			for range tt.messages {
+				if err := conn.WriteMessage(websocket.TextMessage, []byte("ping")); err != nil {
+					t.Fatalf("write failed: %v", err)
+				}
+				if _, data, err := conn.ReadMessage(); err != nil || string(data) != "ping" {
+					t.Fatalf("expected echo, got %q (%v)", data, err)
+				}
+			}
+			expectClose(t, conn, tt.code, tt.reason)
+		})
+	}
+}
+
+func TestCloseHandler_AbnormalClosure(t *testing.T) {
+	url := newTestServer(t, "/ws/close", CloseHandler)
+	conn := dial(t, websocket.DefaultDialer, url+"/ws/close?code=1006")
+
+	start := time.Now()
+	_, _, err := conn.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.CloseAbnormalClosure) {
+		t.Errorf("expected abnormal closure without close frame, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > closeTimeout {
+		t.Errorf("expected the connection to drop immediately, took %v", elapsed)
+	}
+}
+
+func TestCloseHandler_InvalidParameters(t *testing.T) {
+	url := newTestServer(t, "/ws/close", CloseHandler)
+
+	for _, query := range []string{"code=1005", "code=999", "code=abc", "afterMs=-1", "afterMessages=x"} {
+		_, resp, err := websocket.DefaultDialer.Dial(url+"/ws/close?"+query, nil)
+		if err == nil || resp == nil || resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %v", query, err)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/websocket"
+)
+
+// CompressionInfo describes the permessage-deflate negotiation of a
+// connection
+type CompressionInfo struct {
+	Type string `json:"type"`
+	// Offered is the Sec-WebSocket-Extensions header sent by the client
+	Offered string `json:"offered"`
+	// Negotiated tells whether permessage-deflate is in use (the server
+	// supports it without context takeover)
+	Negotiated bool `json:"negotiated"`
+	// Level is the flate level used for compressed writes
+	Level int `json:"level"`
+	// WriteCompression tells whether messages sent by the server are
+	// compressed
+	WriteCompression bool `json:"writeCompression"`
+}
+
+// CompressionHandler reports the permessage-deflate negotiation in a first
+// JSON text message, then echoes messages. level overrides the configured
+// flate level and compress=false sends uncompressed messages on a
+// compressed connection.
+// GET /ws/compression?level={-2..9}&compress={bool}
+func CompressionHandler(w http.ResponseWriter, r *http.Request) {
+	level, err := queryInt(r, "level", GetConfig().CompressionLevel, -2, 9)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	compress, err := queryBool(r, "compress", true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s, ok := upgrade(w, r, upgradeOptions{})
+	if !ok {
+		return
+	}
+	defer s.close()
+
+	info := CompressionInfo{
+		Type:       "compression",
+		Offered:    strings.Join(r.Header.Values("Sec-WebSocket-Extensions"), ", "),
+		Negotiated: GetConfig().CompressionEnabled && offersDeflate(r),
+		Level:      level,
+	}
+	if info.Negotiated {
+		_ = s.conn.SetCompressionLevel(level)
+		info.WriteCompression = compress
+	}
+	s.conn.EnableWriteCompression(info.WriteCompression)
+
+	data, _ := json.Marshal(info)
+	if err := s.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+		return
+	}
+	for msg := range s.messages {
+		if err := s.conn.WriteMessage(msg.Type, msg.Data); err != nil {
+			return
+		}
+	}
+}
+
+// offersDeflate reports whether the client offered permessage-deflate,
+// which the upgrader accepts whenever compression is enabled
+func offersDeflate(r *http.Request) bool {
+	for _, header := range r.Header.Values("Sec-WebSocket-Extensions") {
+		for _, extension := range strings.Split(header, ",") {
+			name, _, _ := strings.Cut(extension, ";")
+			if strings.EqualFold(strings.TrimSpace(name), "permessage-deflate") {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestCompressionHandler(t *testing.T) {
+	url := newTestServer(t, "/ws/compression", CompressionHandler)
+
+	tests := []struct {
+		name             string
+		enable           bool
+		query            string
+		negotiated       bool
+		writeCompression bool
+	}{
+		{name: "deflate offered", enable: true, negotiated: true, writeCompression: true},
+		{name: "deflate offered without write compression", enable: true, query: "?compress=false", negotiated: true},
+		{name: "not offered", enable: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dialer := &websocket.Dialer{EnableCompression: tt.enable}
+			conn := dial(t, dialer, url+"/ws/compression"+tt.query)
+
+			var info CompressionInfo
+			if err := conn.ReadJSON(&info); err != nil {
+				t.Fatalf("read failed: %v", err)
+			}
+			if info.Negotiated != tt.negotiated || info.WriteCompression != tt.writeCompression {
+				t.Errorf("expected negotiated=%v writeCompression=%v, got %+v", tt.negotiated, tt.writeCompression, info)
+			}
+			if tt.enable && !strings.Contains(info.Offered, "permessage-deflate") {
+				t.Errorf("expected offered extensions to be reported, got %q", info.Offered)
+			}
+
+			// Compressible messages survive the round trip
+			payload := strings.Repeat("compress me ", 100)
+			if err := conn.WriteMessage(websocket.TextMessage, []byte(payload)); err != nil {
+				t.Fatalf("write failed: %v", err)
+			}
+			if _, data, err := conn.ReadMessage(); err != nil || string(data) != payload {
+				t.Errorf("expected echo, got %d bytes (%v)", len(data), err)
+			}
+		})
+	}
+}
+
+func TestCompressionHandler_Disabled(t *testing.T) {
+	SetConfig(&Config{CompressionEnabled: false})
+	t.Cleanup(func() { SetConfig(DefaultConfig()) })
+
+	url := newTestServer(t, "/ws/compression", CompressionHandler)
+	conn := dial(t, &websocket.Dialer{EnableCompression: true}, url+"/ws/compression")
+
+	var info CompressionInfo
+	if err := conn.ReadJSON(&info); err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	if info.Negotiated {
+		t.Errorf("expected no compression when disabled, got %+v", info)
+	}
+}
@@ -0,0 +1,38 @@
+package handlers
+
+// globalConfig holds the global connection configuration.
+// It is used by all WebSocket handlers.
+var globalConfig = DefaultConfig()
+
+// Config holds the connection configuration for handlers.
+type Config struct {
+	// permessage-deflate negotiation and the flate level used for writes
+	CompressionEnabled bool
+	CompressionLevel   int
+
+	// Largest message accepted from clients in bytes (0 = unlimited)
+	MaxMessageSize int
+
+	// Send buffer of each room member in messages
+	RoomBufferSize int
+}
+
+// DefaultConfig returns the configuration used until SetConfig is called.
+func DefaultConfig() *Config {
+	return &Config{
+		CompressionEnabled: true,
+		CompressionLevel:   1,
+		MaxMessageSize:     1024 * 1024,
+		RoomBufferSize:     64,
+	}
+}
+
+// SetConfig sets the global configuration for handlers.
+func SetConfig(cfg *Config) {
+	globalConfig = cfg
+}
+
+// GetConfig returns the global configuration for handlers.
+func GetConfig() *Config {
+	return globalConfig
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// DripMessage is a message sent by DripHandler
+type DripMessage struct {
+	Seq    int    `json:"seq"`
+	Count  int    `json:"count"`
+	SentAt string `json:"sentAt"`
+	// Padding fills the message up to the requested size
+	Padding string `json:"padding,omitempty"`
+}
+
+// DripHandler sends count JSON text messages, one every intervalMs, then
+// closes the connection normally, for testing slow producers and read
+// timeouts.
+// GET /ws/drip?count={n}&intervalMs={ms}&padding={n}
+func DripHandler(w http.ResponseWriter, r *http.Request) {
+	count, err := queryInt(r, "count", 10, 1, 1000)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	intervalMs, err := queryInt(r, "intervalMs", 1000, 0, maxIntervalMs)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	padding, err := queryInt(r, "padding", 0, 0, maxBinarySize)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s, ok := upgrade(w, r, upgradeOptions{})
+	if !ok {
+		return
+	}
+
+	for seq := 1; seq <= count; seq++ {
+		if seq > 1 && !s.wait(time.Duration(intervalMs)*time.Millisecond) {
+			s.close()
+			return
+		}
+		data, _ := json.Marshal(DripMessage{
+			Seq:     seq,
+			Count:   count,
+			SentAt:  time.Now().UTC().Format(time.RFC3339Nano),
+			Padding: strings.Repeat("x", padding),
+		})
+		if err := s.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+			s.close()
+			return
+		}
+	}
+	s.closeWith(websocket.CloseNormalClosure, "complete")
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestDripHandler(t *testing.T) {
+	url := newTestServer(t, "/ws/drip", DripHandler)
+	conn := dial(t, websocket.DefaultDialer, url+"/ws/drip?count=3&intervalMs=50&padding=10")
+
+	start := time.Now()
+	for seq := 1; seq <= 3; seq++ {
+		var msg DripMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatalf("read failed: %v", err)
+		}
+		if msg.Seq != seq || msg.Count != 3 || len(msg.Padding) != 10 {
+			t.Errorf("expected message %d of 3 with 10 padding bytes, got %+v", seq, msg)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("expected messages spread over at least 100ms, got %v", elapsed)
+	}
+	expectClose(t, conn, websocket.CloseNormalClosure, "complete")
+}
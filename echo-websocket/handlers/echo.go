@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+)
+
+// EchoHandler echoes every message back with the same message type.
+// GET /ws/echo?delayMs={ms} - Echo text and binary messages, optionally delayed
+func EchoHandler(w http.ResponseWriter, r *http.Request) {
+	delayMs, err := queryInt(r, "delayMs", 0, 0, maxIntervalMs)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s, ok := upgrade(w, r, upgradeOptions{})
+	if !ok {
+		return
+	}
+	defer s.close()
+
+	for msg := range s.messages {
+		time.Sleep(time.Duration(delayMs) * time.Millisecond)
+		if err := s.conn.WriteMessage(msg.Type, msg.Data); err != nil {
+			return
+		}
+	}
+}
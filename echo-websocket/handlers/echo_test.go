@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestEchoHandler(t *testing.T) {
+	url := newTestServer(t, "/ws/echo", EchoHandler)
+	conn := dial(t, websocket.DefaultDialer, url+"/ws/echo")
+
+	messages := []struct {
+		messageType int
+		data        []byte
+	}{
+		{websocket.TextMessage, []byte("hello")},
+		{websocket.BinaryMessage, []byte{0x00, 0xff, 0x10}},
+		{websocket.TextMessage, []byte("")},
+	}
+	for _, msg := range messages {
+		if err := conn.WriteMessage(msg.messageType, msg.data); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+		messageType, data, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("read failed: %v", err)
+		}
+		if messageType != msg.messageType || !bytes.Equal(data, msg.data) {
+			t.Errorf("expected type %d %q, got type %d %q", msg.messageType, msg.data, messageType, data)
+		}
+	}
+}
+
+func TestEchoHandler_Delay(t *testing.T) {
+	url := newTestServer(t, "/ws/echo", EchoHandler)
+	conn := dial(t, websocket.DefaultDialer, url+"/ws/echo?delayMs=100")
+
+	start := time.Now()
+	if err := conn.WriteMessage(websocket.TextMessage, []byte("hello")); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if _, _, err := conn.ReadMessage(); err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("expected echo after at least 100ms, got %v", elapsed)
+	}
+}
+
+func TestEchoHandler_InvalidDelay(t *testing.T) {
+	url := newTestServer(t, "/ws/echo", EchoHandler)
+	_, resp, err := websocket.DefaultDialer.Dial(url+"/ws/echo?delayMs=-1", nil)
+	if err == nil || resp == nil || resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400, got %v", err)
+	}
+}
+
+func TestEchoHandler_MessageTooBig(t *testing.T) {
+	SetConfig(&Config{MaxMessageSize: 8})
+	t.Cleanup(func() { SetConfig(DefaultConfig()) })
+
+	url := newTestServer(t, "/ws/echo", EchoHandler)
+	conn := dial(t, websocket.DefaultDialer, url+"/ws/echo")
+	if err := conn.WriteMessage(websocket.TextMessage, []byte("more than eight bytes")); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	expectClose(t, conn, websocket.CloseMessageTooBig, "")
+}
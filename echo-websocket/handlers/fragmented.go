@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+)
+
+// FragmentedHandler echoes every message back split into a first frame and
+// continuation frames of fragmentSize payload bytes each, optionally pausing
+// intervalMs between frames (a slow drip of a single message). Write
+// compression is disabled so frame sizes are exact.
+// GET /ws/fragmented?fragmentSize={n}&intervalMs={ms}
+func FragmentedHandler(w http.ResponseWriter, r *http.Request) {
+	fragmentSize, err := queryInt(r, "fragmentSize", 16, 1, 64*1024)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	intervalMs, err := queryInt(r, "intervalMs", 0, 0, maxIntervalMs)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// The write buffer holds exactly one frame payload, so every full buffer
+	// is flushed as a fragment
+	s, ok := upgrade(w, r, upgradeOptions{WriteBufferSize: fragmentSize})
+	if !ok {
+		return
+	}
+	defer s.close()
+	s.conn.EnableWriteCompression(false)
+
+	interval := time.Duration(intervalMs) * time.Millisecond
+	for msg := range s.messages {
+		writer, err := s.conn.NextWriter(msg.Type)
+		if err != nil {
+			return
+		}
+		// A fragment is flushed when the next write finds the buffer full,
+		// so pausing after each write spaces the frames by interval
+		for data := msg.Data; len(data) > 0; {
+			n := min(fragmentSize, len(data))
+			if _, err := writer.Write(data[:n]); err != nil {
+				return
+			}
+			data = data[n:]
+			time.Sleep(interval)
+		}
+		if err := writer.Close(); err != nil {
+			return
+		}
+	}
+}
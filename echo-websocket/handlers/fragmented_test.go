@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+type frame struct {
+	fin     bool
+	opcode  byte
+	payload []byte
+}
+
+// dialRaw opens a WebSocket connection without a client library so tests
+// can observe individual frames
+func dialRaw(t *testing.T, url string) (net.Conn, *bufio.Reader) {
+	t.Helper()
+	u := strings.TrimPrefix(url, "ws://")
+	host, path, _ := strings.Cut(u, "/")
+	conn, err := net.Dial("tcp", host)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	handshake := "GET /" + path + " HTTP/1.1\r\n" +
+		"Host: " + host + "\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n" +
+		"Sec-WebSocket-Version: 13\r\n\r\n"
+	if _, err := conn.Write([]byte(handshake)); err != nil {
+		t.Fatalf("handshake failed: %v", err)
+	}
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil || resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("expected 101, got %v (%v)", resp, err)
+	}
+	return conn, br
+}
+
+// writeRawText writes a single masked text frame (zero mask key)
+func writeRawText(t *testing.T, conn net.Conn, text string) {
+	t.Helper()
+	data := append([]byte{0x81, 0x80 | byte(len(text)), 0, 0, 0, 0}, text...)
+	if _, err := conn.Write(data); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+}
+
+// readRawFrame reads an unmasked server frame with a payload under 64 KiB
+func readRawFrame(t *testing.T, br *bufio.Reader) frame {
+	t.Helper()
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(br, header); err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	length := int(header[1] & 0x7f)
+	if length == 126 {
+		ext := make([]byte, 2)
+		if _, err := io.ReadFull(br, ext); err != nil {
+			t.Fatalf("read failed: %v", err)
+		}
+		length = int(ext[0])<<8 | int(ext[1])
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(br, payload); err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	return frame{fin: header[0]&0x80 != 0, opcode: header[0] & 0x0f, payload: payload}
+}
+
+func TestFragmentedHandler(t *testing.T) {
+	url := newTestServer(t, "/ws/fragmented", FragmentedHandler)
+	conn, br := dialRaw(t, url+"/ws/fragmented?fragmentSize=4")
+
+	writeRawText(t, conn, "hello world")
+	expected := []frame{
+		{fin: false, opcode: websocket.TextMessage, payload: []byte("hell")},
+		{fin: false, opcode: 0, payload: []byte("o wo")},
+		{fin: true, opcode: 0, payload: []byte("rld")},
+	}
+	for i, want := range expected {
+		got := readRawFrame(t, br)
+		if got.fin != want.fin || got.opcode != want.opcode || string(got.payload) != string(want.payload) {
+			t.Errorf("frame %d: expected %+v, got %+v", i, want, got)
+		}
+	}
+}
+
+func TestFragmentedHandler_Interval(t *testing.T) {
+	url := newTestServer(t, "/ws/fragmented", FragmentedHandler)
+	conn := dial(t, websocket.DefaultDialer, url+"/ws/fragmented?fragmentSize=2&intervalMs=30")
+
+	start := time.Now()
+	if err := conn.WriteMessage(websocket.TextMessage, []byte("abcdef")); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if _, data, err := conn.ReadMessage(); err != nil || string(data) != "abcdef" {
+		t.Fatalf("expected reassembled echo, got %q (%v)", data, err)
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("expected 3 fragments spread over at least 90ms, got %v", elapsed)
+	}
+}
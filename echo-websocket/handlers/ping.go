@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// ControlEvent reports a ping or pong control frame received from the client
+type ControlEvent struct {
+	Type    string `json:"type"`
+	Payload string `json:"payload"`
+	// Replied tells whether a client ping was answered with a pong
+	Replied *bool `json:"replied,omitempty"`
+	// RTTMs is the round trip time of a server ping answered by this pong
+	RTTMs *float64 `json:"rttMs,omitempty"`
+}
+
+// PingHandler exercises control frames: it pings the client every
+// intervalMs (with payload, or a sequence number when empty), answers client
+// pings unless autoPong=false, reports each ping and pong it receives as a
+// JSON text message and echoes data messages.
+// GET /ws/ping?intervalMs={ms}&payload={text}&autoPong={bool}
+func PingHandler(w http.ResponseWriter, r *http.Request) {
+	intervalMs, err := queryInt(r, "intervalMs", 1000, 0, maxIntervalMs)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	autoPong, err := queryBool(r, "autoPong", true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	payload := r.URL.Query().Get("payload")
+	if len(payload) > 125 {
+		http.Error(w, "Invalid payload (must be at most 125 bytes)", http.StatusBadRequest)
+		return
+	}
+
+	// Control frame handlers run on the reading goroutine; events are
+	// written by the handler goroutine
+	events := make(chan ControlEvent, 16)
+	report := func(event ControlEvent) {
+		select {
+		case events <- event:
+		default:
+		}
+	}
+	var mu sync.Mutex
+	sent := make(map[string]time.Time)
+	configure := func(conn *websocket.Conn) {
+		conn.SetPingHandler(func(appData string) error {
+			if autoPong {
+				_ = conn.WriteControl(websocket.PongMessage, []byte(appData), time.Now().Add(closeTimeout))
+			}
+			report(ControlEvent{Type: "ping", Payload: appData, Replied: &autoPong})
+			return nil
+		})
+		conn.SetPongHandler(func(appData string) error {
+			event := ControlEvent{Type: "pong", Payload: appData}
+			mu.Lock()
+			if at, ok := sent[appData]; ok {
+				rtt := float64(time.Since(at).Microseconds()) / 1000
+				event.RTTMs = &rtt
+				delete(sent, appData)
+			}
+			mu.Unlock()
+			report(event)
+			return nil
+		})
+	}
+
+	s, ok := upgrade(w, r, upgradeOptions{Configure: configure})
+	if !ok {
+		return
+	}
+	defer s.close()
+
+	var ticks <-chan time.Time
+	if intervalMs > 0 {
+		ticker := time.NewTicker(time.Duration(intervalMs) * time.Millisecond)
+		defer ticker.Stop()
+		ticks = ticker.C
+	}
+	seq := 0
+	for {
+		select {
+		case msg, ok := <-s.messages:
+			if !ok {
+				return
+			}
+			if err := s.conn.WriteMessage(msg.Type, msg.Data); err != nil {
+				return
+			}
+		case event := <-events:
+			data, _ := json.Marshal(event)
+			if err := s.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+				return
+			}
+		case <-ticks:
+			seq++
+			appData := payload
+			if appData == "" {
+				appData = strconv.Itoa(seq)
+			}
+			mu.Lock()
+			sent[appData] = time.Now()
+			mu.Unlock()
+			if err := s.conn.WriteControl(websocket.PingMessage, []byte(appData), time.Now().Add(closeTimeout)); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestPingHandler_ServerPings(t *testing.T) {
+	url := newTestServer(t, "/ws/ping", PingHandler)
+	conn := dial(t, websocket.DefaultDialer, url+"/ws/ping?intervalMs=10&payload=probe")
+
+	pinged := make(chan string, 10)
+	conn.SetPingHandler(func(appData string) error {
+		pinged <- appData
+		return conn.WriteControl(websocket.PongMessage, []byte(appData), deadline())
+	})
+
+	// The pong answering the server ping is reported with its round trip
+	var event ControlEvent
+	if err := conn.ReadJSON(&event); err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	if event.Type != "pong" || event.Payload != "probe" || event.RTTMs == nil {
+		t.Errorf("expected pong report with rtt, got %+v", event)
+	}
+	if got := <-pinged; got != "probe" {
+		t.Errorf("expected ping payload %q, got %q", "probe", got)
+	}
+}
+
+func TestPingHandler_ClientPings(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		replied  bool
+		wantPong bool
+	}{
+		{name: "auto pong", query: "?intervalMs=0", replied: true, wantPong: true},
+		{name: "pong suppressed", query: "?intervalMs=0&autoPong=false", replied: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url := newTestServer(t, "/ws/ping", PingHandler)
+			conn := dial(t, websocket.DefaultDialer, url+"/ws/ping"+tt.query)
+
+			ponged := make(chan string, 1)
+			conn.SetPongHandler(func(appData string) error {
+				ponged <- appData
+				return nil
+			})
+			if err := conn.WriteControl(websocket.PingMessage, []byte("hi"), deadline()); err != nil {
+				t.Fatalf("ping failed: %v", err)
+			}
+
+			var event ControlEvent
+			if err := conn.ReadJSON(&event); err != nil {
+				t.Fatalf("read failed: %v", err)
+			}
+			if event.Type != "ping" || event.Payload != "hi" || event.Replied == nil || *event.Replied != tt.replied {
+				t.Errorf("expected ping report replied=%v, got %+v", tt.replied, event)
+			}
+
+			// A pong (if any) precedes the report
+			select {
+			case got := <-ponged:
+				if !tt.wantPong || got != "hi" {
+					t.Errorf("unexpected pong %q", got)
+				}
+			default:
+				if tt.wantPong {
+					t.Error("expected a pong")
+				}
+			}
+		})
+	}
+}
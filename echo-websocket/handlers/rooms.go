@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// RoomEvent announces a member joining or leaving a room
+type RoomEvent struct {
+	Type    string `json:"type"`
+	Room    string `json:"room"`
+	Member  string `json:"member"`
+	Members int    `json:"members"`
+}
+
+// RoomInfo describes a room listed by RoomsHandler
+type RoomInfo struct {
+	Name    string   `json:"name"`
+	Members []string `json:"members"`
+}
+
+// member is a connection in a room
+type member struct {
+	name   string
+	events bool
+	send   chan message
+	// dropped is closed when the member fell behind and was removed
+	dropped chan struct{}
+}
+
+// rooms holds the members of each room by name
+var rooms = struct {
+	sync.Mutex
+	m map[string]map[*member]struct{}
+}{m: make(map[string]map[*member]struct{})}
+
+// RoomHandler joins a broadcast room: every message a member sends is
+// delivered to all members with the same message type, including the sender
+// unless echoSelf=false. With events=true the member also receives join and
+// leave events as JSON text messages. Members whose send buffer fills up are
+// disconnected with 1008.
+// GET /ws/rooms/{room}?name={member}&echoSelf={bool}&events={bool}
+func RoomHandler(w http.ResponseWriter, r *http.Request) {
+	room := r.PathValue("room")
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		name = r.RemoteAddr
+	}
+	echoSelf, err := queryBool(r, "echoSelf", true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	events, err := queryBool(r, "events", false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s, ok := upgrade(w, r, upgradeOptions{})
+	if !ok {
+		return
+	}
+
+	m := &member{
+		name:    name,
+		events:  events,
+		send:    make(chan message, max(GetConfig().RoomBufferSize, 1)),
+		dropped: make(chan struct{}),
+	}
+	join(room, m)
+	defer leave(room, m)
+
+	for {
+		select {
+		case msg, ok := <-s.messages:
+			if !ok {
+				s.close()
+				return
+			}
+			broadcast(room, m, msg, echoSelf)
+		case msg := <-m.send:
+			if err := s.conn.WriteMessage(msg.Type, msg.Data); err != nil {
+				s.close()
+				return
+			}
+		case <-m.dropped:
+			s.closeWith(websocket.ClosePolicyViolation, "message buffer full")
+			return
+		}
+	}
+}
+
+// RoomsHandler lists the rooms with members.
+// GET /rooms
+func RoomsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"rooms": Rooms()})
+}
+
+// Rooms returns the rooms with members, sorted by name
+func Rooms() []RoomInfo {
+	rooms.Lock()
+	list := make([]RoomInfo, 0, len(rooms.m))
+	for name, members := range rooms.m {
+		info := RoomInfo{Name: name, Members: make([]string, 0, len(members))}
+		for m := range members {
+			info.Members = append(info.Members, m.name)
+		}
+		slices.Sort(info.Members)
+		list = append(list, info)
+	}
+	rooms.Unlock()
+	slices.SortFunc(list, func(a, b RoomInfo) int { return strings.Compare(a.Name, b.Name) })
+	return list
+}
+
+// join adds m to room and announces it
+func join(room string, m *member) {
+	rooms.Lock()
+	defer rooms.Unlock()
+	if rooms.m[room] == nil {
+		rooms.m[room] = make(map[*member]struct{})
+	}
+	rooms.m[room][m] = struct{}{}
+	announce(room, "join", m.name)
+}
+
+// leave removes m from room (unless it was already dropped) and announces it
+func leave(room string, m *member) {
+	rooms.Lock()
+	defer rooms.Unlock()
+	remove(room, m)
+	announce(room, "leave", m.name)
+}
+
+// broadcast delivers msg from sender to the members of room
+func broadcast(room string, sender *member, msg message, echoSelf bool) {
+	rooms.Lock()
+	defer rooms.Unlock()
+	for m := range rooms.m[room] {
+		if m != sender || echoSelf {
+			deliver(room, m, msg)
+		}
+	}
+}
+
+// announce sends a join or leave event to the members of room that asked
+// for events. rooms must be locked.
+func announce(room, eventType, name string) {
+	data, _ := json.Marshal(RoomEvent{Type: eventType, Room: room, Member: name, Members: len(rooms.m[room])})
+	for m := range rooms.m[room] {
+		if m.events {
+			deliver(room, m, message{Type: websocket.TextMessage, Data: data})
+		}
+	}
+}
+
+// deliver queues msg for m, dropping m from room when its buffer is full.
+// rooms must be locked.
+func deliver(room string, m *member, msg message) {
+	select {
+	case m.send <- msg:
+	default:
+		remove(room, m)
+		close(m.dropped)
+	}
+}
+
+// remove deletes m from room, deleting the room once empty. rooms must be
+// locked.
+func remove(room string, m *member) {
+	delete(rooms.m[room], m)
+	if len(rooms.m[room]) == 0 {
+		delete(rooms.m, room)
+	}
+}
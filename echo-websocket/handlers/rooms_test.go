@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestRoomHandler_Broadcast(t *testing.T) {
+	url := newTestServer(t, "/ws/rooms/{room}", RoomHandler)
+	alice := dial(t, websocket.DefaultDialer, url+"/ws/rooms/lobby?name=alice&events=true")
+
+	// alice sees her own join, then bob's
+	var event RoomEvent
+	if err := alice.ReadJSON(&event); err != nil || event.Type != "join" || event.Member != "alice" || event.Members != 1 {
+		t.Fatalf("expected alice join event, got %+v (%v)", event, err)
+	}
+	bob := dial(t, websocket.DefaultDialer, url+"/ws/rooms/lobby?name=bob&echoSelf=false")
+	if err := alice.ReadJSON(&event); err != nil || event.Type != "join" || event.Member != "bob" || event.Members != 2 {
+		t.Fatalf("expected bob join event, got %+v (%v)", event, err)
+	}
+
+	// Message types are preserved; bob does not get his own message back
+	if err := bob.WriteMessage(websocket.BinaryMessage, []byte{1, 2, 3}); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if messageType, data, err := alice.ReadMessage(); err != nil || messageType != websocket.BinaryMessage || len(data) != 3 {
+		t.Errorf("expected bob's binary message, got type %d %v (%v)", messageType, data, err)
+	}
+
+	if err := alice.WriteMessage(websocket.TextMessage, []byte("hi")); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	for name, conn := range map[string]*websocket.Conn{"alice": alice, "bob": bob} {
+		if _, data, err := conn.ReadMessage(); err != nil || string(data) != "hi" {
+			t.Errorf("%s: expected alice's message, got %q (%v)", name, data, err)
+		}
+	}
+
+	_ = bob.Close()
+	if err := alice.ReadJSON(&event); err != nil || event.Type != "leave" || event.Member != "bob" || event.Members != 1 {
+		t.Errorf("expected bob leave event, got %+v (%v)", event, err)
+	}
+}
+
+func TestRoomHandler_DropsSlowMembers(t *testing.T) {
+	SetConfig(&Config{RoomBufferSize: 1})
+	t.Cleanup(func() { SetConfig(DefaultConfig()) })
+
+	url := newTestServer(t, "/ws/rooms/{room}", RoomHandler)
+	conn := dial(t, websocket.DefaultDialer, url+"/ws/rooms/slow")
+
+	// The member keeps sending without reading its own echoes
+	for range 1000 {
+		if err := conn.WriteMessage(websocket.TextMessage, make([]byte, 1024)); err != nil {
+			break
+		}
+	}
+	expectClose(t, conn, websocket.ClosePolicyViolation, "message buffer full")
+}
+
+func TestRoomsHandler(t *testing.T) {
+	url := newTestServer(t, "/ws/rooms/{room}", RoomHandler)
+	dial(t, websocket.DefaultDialer, url+"/ws/rooms/list-b?name=carol")
+	dial(t, websocket.DefaultDialer, url+"/ws/rooms/list-a?name=erin")
+	dial(t, websocket.DefaultDialer, url+"/ws/rooms/list-a?name=dave")
+
+	// Joining happens after the handshake completes
+	var listed []RoomInfo
+	for deadline := time.Now().Add(time.Second); ; {
+		rec := httptest.NewRecorder()
+		RoomsHandler(rec, httptest.NewRequest(http.MethodGet, "/rooms", nil))
+		var body struct {
+			Rooms []RoomInfo `json:"rooms"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("invalid JSON: %v", err)
+		}
+		listed = listed[:0]
+		for _, room := range body.Rooms {
+			if strings.HasPrefix(room.Name, "list-") {
+				listed = append(listed, room)
+			}
+		}
+		if len(listed) == 2 && len(listed[0].Members) == 2 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if len(listed) != 2 || listed[0].Name != "list-a" || listed[1].Name != "list-b" {
+		t.Fatalf("expected rooms list-a and list-b in order, got %+v", listed)
+	}
+	if members := listed[0].Members; len(members) != 2 || members[0] != "dave" || members[1] != "erin" {
+		t.Errorf("expected sorted members dave and erin, got %v", members)
+	}
+}
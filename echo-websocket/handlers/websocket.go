@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// closeTimeout bounds how long a close handshake waits for the client's
+	// close frame before the connection is dropped
+	closeTimeout = time.Second
+
+	// maxIntervalMs is the longest delay accepted by interval parameters
+	maxIntervalMs = 60000
+)
+
+// message is a data message read from a connection
+type message struct {
+	Type int
+	Data []byte
+}
+
+// session is an upgraded connection whose data messages are read in the
+// background, so handlers can wait for messages and timers at once. Only the
+// handler goroutine writes data messages.
+type session struct {
+	conn *websocket.Conn
+	// messages is closed once reading fails (the client closed the
+	// connection or it broke)
+	messages <-chan message
+	done     chan struct{}
+}
+
+// sessions tracks open sessions so they can be closed on shutdown
+var sessions = struct {
+	sync.Mutex
+	m map[*session]struct{}
+}{m: make(map[*session]struct{})}
+
+// upgradeOptions adjusts a connection for a handler
+type upgradeOptions struct {
+	// WriteBufferSize > 0 sets the payload size of the frames large
+	// messages are split into
+	WriteBufferSize int
+	// Configure runs before reading starts, such as to install control
+	// frame handlers
+	Configure func(conn *websocket.Conn)
+}
+
+// upgrade upgrades the request to a WebSocket session with the configured
+// compression and message size limit, accepting the first subprotocol the
+// client offers. On failure the upgrader has already answered the request.
+func upgrade(w http.ResponseWriter, r *http.Request, opts upgradeOptions) (*session, bool) {
+	cfg := GetConfig()
+	upgrader := websocket.Upgrader{
+		ReadBufferSize:    1024,
+		WriteBufferSize:   opts.WriteBufferSize,
+		EnableCompression: cfg.CompressionEnabled,
+		CheckOrigin: func(r *http.Request) bool {
+			return true
+		},
+	}
+	if offered := websocket.Subprotocols(r); len(offered) > 0 {
+		upgrader.Subprotocols = offered[:1]
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return nil, false
+	}
+	if cfg.MaxMessageSize > 0 {
+		conn.SetReadLimit(int64(cfg.MaxMessageSize))
+	}
+	if cfg.CompressionEnabled {
+		_ = conn.SetCompressionLevel(cfg.CompressionLevel)
+	}
+	if opts.Configure != nil {
+		opts.Configure(conn)
+	}
+
+	messages := make(chan message)
+	s := &session{conn: conn, messages: messages, done: make(chan struct{})}
+	go func() {
+		defer close(messages)
+		for {
+			typ, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			select {
+			case messages <- message{Type: typ, Data: data}:
+			case <-s.done:
+				return
+			}
+		}
+	}()
+
+	sessions.Lock()
+	sessions.m[s] = struct{}{}
+	sessions.Unlock()
+	return s, true
+}
+
+// wait sleeps for d, discarding messages sent by the client meanwhile. It
+// returns false if the connection closed.
+func (s *session) wait(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	for {
+		select {
+		case _, ok := <-s.messages:
+			if !ok {
+				return false
+			}
+		case <-timer.C:
+			return true
+		}
+	}
+}
+
+// close drops the connection without a close handshake
+func (s *session) close() {
+	sessions.Lock()
+	delete(sessions.m, s)
+	sessions.Unlock()
+
+	select {
+	case <-s.done:
+	default:
+		close(s.done)
+	}
+	_ = s.conn.Close()
+}
+
+// closeWith sends a close frame and waits for the client's close frame (or
+// closeTimeout) before dropping the connection. Code 1006 (abnormal
+// closure) drops the connection without a close frame.
+func (s *session) closeWith(code int, reason string) {
+	defer s.close()
+	if code == websocket.CloseAbnormalClosure {
+		return
+	}
+	if err := writeClose(s.conn, code, reason); err != nil {
+		return
+	}
+
+	timeout := time.NewTimer(closeTimeout)
+	defer timeout.Stop()
+	for {
+		select {
+		case _, ok := <-s.messages:
+			if !ok {
+				return
+			}
+		case <-timeout.C:
+			return
+		}
+	}
+}
+
+// writeClose writes a close frame, truncating reason to fit the 125 byte
+// control frame limit
+func writeClose(conn *websocket.Conn, code int, reason string) error {
+	if len(reason) > 123 {
+		reason = reason[:123]
+	}
+	return conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(closeTimeout))
+}
+
+// CloseAll sends a close frame with code and reason to every open
+// connection, such as 1001 (going away) on shutdown. It returns the number of
+// connections.
+func CloseAll(code int, reason string) int {
+	sessions.Lock()
+	open := make([]*session, 0, len(sessions.m))
+	for s := range sessions.m {
+		open = append(open, s)
+	}
+	sessions.Unlock()
+
+	for _, s := range open {
+		_ = writeClose(s.conn, code, reason)
+	}
+	return len(open)
+}
+
+// validCloseCode reports whether code may be sent in a close frame. 1006 is
+// accepted and means dropping the connection without one.
+func validCloseCode(code int) bool {
+	switch {
+	case code >= 1000 && code <= 1003,
+		code >= 1006 && code <= 1014,
+		code >= 3000 && code <= 4999:
+		return true
+	}
+	return false
+}
+
+// queryInt parses an integer query parameter in [minValue, maxValue],
+// returning defaultValue when it is absent
+func queryInt(r *http.Request, name string, defaultValue, minValue, maxValue int) (int, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return defaultValue, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < minValue || n > maxValue {
+		return 0, fmt.Errorf("invalid %s (must be %d-%d)", name, minValue, maxValue)
+	}
+	return n, nil
+}
+
+// queryBool parses a boolean query parameter, returning defaultValue when it
+// is absent
+func queryBool(r *http.Request, name string, defaultValue bool) (bool, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return defaultValue, nil
+	}
+	b, err := strconv.ParseBool(strings.ToLower(value))
+	if err != nil {
+		return false, fmt.Errorf("invalid %s (must be true or false)", name)
+	}
+	return b, nil
+}
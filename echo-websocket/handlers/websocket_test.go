@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// newTestServer serves handler at pattern and returns its ws:// base URL
+func newTestServer(t *testing.T, pattern string, handler http.HandlerFunc) string {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc(pattern, handler)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+func dial(t *testing.T, dialer *websocket.Dialer, url string) *websocket.Conn {
+	t.Helper()
+	conn, resp, err := dialer.Dial(url, nil)
+	if err != nil {
+		status := 0
+		if resp != nil {
+			status = resp.StatusCode
+		}
+		t.Fatalf("dial %s failed (status %d): %v", url, status, err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	return conn
+}
+
+// expectClose reads until the connection closes and checks the close code
+func expectClose(t *testing.T, conn *websocket.Conn, code int, reason string) {
+	t.Helper()
+	for {
+		_, _, err := conn.ReadMessage()
+		if err == nil {
+			continue
+		}
+		closeErr, ok := err.(*websocket.CloseError)
+		if !ok || closeErr.Code != code || closeErr.Text != reason {
+			t.Errorf("expected close %d %q, got %v", code, reason, err)
+		}
+		return
+	}
+}
+
+func TestQueryInt(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected int
+		wantErr  bool
+	}{
+		{name: "absent uses default", query: "", expected: 5},
+		{name: "in range", query: "n=7", expected: 7},
+		{name: "minimum", query: "n=1", expected: 1},
+		{name: "below minimum", query: "n=0", wantErr: true},
+		{name: "above maximum", query: "n=11", wantErr: true},
+		{name: "non-numeric", query: "n=abc", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/?"+tt.query, nil)
+			n, err := queryInt(req, "n", 5, 1, 10)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if !tt.wantErr && n != tt.expected {
+				t.Errorf("expected %d, got %d", tt.expected, n)
+			}
+		})
+	}
+}
+
+func TestValidCloseCode(t *testing.T) {
+	for _, code := range []int{1000, 1003, 1006, 1011, 3000, 4999} {
+		if !validCloseCode(code) {
+			t.Errorf("expected %d to be valid", code)
+		}
+	}
+	for _, code := range []int{0, 999, 1004, 1005, 1015, 2000, 5000} {
+		if validCloseCode(code) {
+			t.Errorf("expected %d to be invalid", code)
+		}
+	}
+}
+
+func TestCloseAll(t *testing.T) {
+	url := newTestServer(t, "/ws/echo", EchoHandler)
+	conn := dial(t, websocket.DefaultDialer, url+"/ws/echo")
+
+	// Make sure the session is registered before closing
+	if err := conn.WriteMessage(websocket.TextMessage, []byte("hello")); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if _, _, err := conn.ReadMessage(); err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+
+	if n := CloseAll(websocket.CloseGoingAway, "server shutting down"); n < 1 {
+		t.Errorf("expected at least 1 closed connection, got %d", n)
+	}
+	expectClose(t, conn, websocket.CloseGoingAway, "server shutting down")
+}
+
+// deadline is the write deadline for test control frames
+func deadline() time.Time {
+	return time.Now().Add(time.Second)
+}
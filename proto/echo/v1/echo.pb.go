@@ -0,0 +1,183 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v6.32.1
+// source: echo/v1/echo.proto
+
+package echov1
+
+import (
+	reflect "reflect"
+	unsafe "unsafe"
+
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+var File_echo_v1_echo_proto protoreflect.FileDescriptor
+
+const file_echo_v1_echo_proto_rawDesc = "" +
+	"\n" +
+	"\x12echo/v1/echo.proto\x12\aecho.v1\x1a\x17echo/v1/echo_burn.proto\x1a\x18echo/v1/echo_clock.proto\x1a\x1eecho/v1/echo_credentials.proto\x1a\x1becho/v1/echo_deadline.proto\x1a\x18echo/v1/echo_http2.proto\x1a\x1becho/v1/echo_metadata.proto\x1a\x1aecho/v1/echo_network.proto\x1a\x1aecho/v1/echo_payload.proto\x1a\x1becho/v1/echo_response.proto\x1a echo/v1/echo_serialization.proto\x1a\x19echo/v1/echo_stream.proto\x1a\x1decho/v1/echo_structured.proto\x1a\x18echo/v1/echo_unary.proto\x1a\x1aecho/v1/echo_version.proto2\xd6\r\n" +
+	"\x04Echo\x128\n" +
+	"\x04Echo\x12\x14.echo.v1.EchoRequest\x1a\x15.echo.v1.EchoResponse\"\x03\x90\x02\x01\x12E\n" +
+	"\rEchoWithDelay\x12\x1d.echo.v1.EchoWithDelayRequest\x1a\x15.echo.v1.EchoResponse\x12=\n" +
+	"\tEchoError\x12\x19.echo.v1.EchoErrorRequest\x1a\x15.echo.v1.EchoResponse\x12e\n" +
+	"\x13EchoRequestMetadata\x12#.echo.v1.EchoRequestMetadataRequest\x1a$.echo.v1.EchoRequestMetadataResponse\"\x03\x90\x02\x01\x12K\n" +
+	"\x10EchoWithTrailers\x12 .echo.v1.EchoWithTrailersRequest\x1a\x15.echo.v1.EchoResponse\x12\\\n" +
+	"\x10EchoLargePayload\x12 .echo.v1.EchoLargePayloadRequest\x1a!.echo.v1.EchoLargePayloadResponse\"\x03\x90\x02\x01\x12K\n" +
+	"\fEchoDeadline\x12\x1c.echo.v1.EchoDeadlineRequest\x1a\x1d.echo.v1.EchoDeadlineResponse\x12S\n" +
+	"\x14EchoErrorWithDetails\x12$.echo.v1.EchoErrorWithDetailsRequest\x1a\x15.echo.v1.EchoResponse\x12E\n" +
+	"\fServerStream\x12\x1c.echo.v1.ServerStreamRequest\x1a\x15.echo.v1.EchoResponse0\x01\x12=\n" +
+	"\fClientStream\x12\x14.echo.v1.EchoRequest\x1a\x15.echo.v1.EchoResponse(\x01\x12F\n" +
+	"\x13BidirectionalStream\x12\x14.echo.v1.EchoRequest\x1a\x15.echo.v1.EchoResponse(\x010\x01\x12b\n" +
+	"\x15ServerStreamResumable\x12%.echo.v1.ServerStreamResumableRequest\x1a .echo.v1.ResumableStreamResponse0\x01\x12i\n" +
+	" BidirectionalStreamWithHeartbeat\x12\x1f.echo.v1.HeartbeatStreamRequest\x1a .echo.v1.HeartbeatStreamResponse(\x010\x01\x12N\n" +
+	"\x0eEchoStructured\x12\x1a.echo.v1.StructuredRequest\x1a\x1b.echo.v1.StructuredResponse\"\x03\x90\x02\x01\x124\n" +
+	"\aEchoMap\x12\x13.echo.v1.MapRequest\x1a\x14.echo.v1.MapResponse\x12C\n" +
+	"\fEchoRepeated\x12\x18.echo.v1.RepeatedRequest\x1a\x19.echo.v1.RepeatedResponse\x12@\n" +
+	"\vEchoScalars\x12\x17.echo.v1.ScalarsRequest\x1a\x18.echo.v1.ScalarsResponse\x12A\n" +
+	"\aVersion\x12\x17.echo.v1.VersionRequest\x1a\x18.echo.v1.VersionResponse\"\x03\x90\x02\x01\x12<\n" +
+	"\aNetwork\x12\x17.echo.v1.NetworkRequest\x1a\x18.echo.v1.NetworkResponse\x12H\n" +
+	"\vCredentials\x12\x1b.echo.v1.CredentialsRequest\x1a\x1c.echo.v1.CredentialsResponse\x12N\n" +
+	"\rHttp2Settings\x12\x1d.echo.v1.Http2SettingsRequest\x1a\x1e.echo.v1.Http2SettingsResponse\x12E\n" +
+	"\n" +
+	"ServerTime\x12\x1a.echo.v1.ServerTimeRequest\x1a\x1b.echo.v1.ServerTimeResponse\x12N\n" +
+	"\rBurnResources\x12\x1d.echo.v1.BurnResourcesRequest\x1a\x1e.echo.v1.BurnResourcesResponseB<Z:github.com/probitas-test/echo-servers/proto/echo/v1;echov1b\x06proto3"
+
+var file_echo_v1_echo_proto_goTypes = []any{
+	(*EchoRequest)(nil),                  // 0: echo.v1.EchoRequest
+	(*EchoWithDelayRequest)(nil),         // 1: echo.v1.EchoWithDelayRequest
+	(*EchoErrorRequest)(nil),             // 2: echo.v1.EchoErrorRequest
+	(*EchoRequestMetadataRequest)(nil),   // 3: echo.v1.EchoRequestMetadataRequest
+	(*EchoWithTrailersRequest)(nil),      // 4: echo.v1.EchoWithTrailersRequest
+	(*EchoLargePayloadRequest)(nil),      // 5: echo.v1.EchoLargePayloadRequest
+	(*EchoDeadlineRequest)(nil),          // 6: echo.v1.EchoDeadlineRequest
+	(*EchoErrorWithDetailsRequest)(nil),  // 7: echo.v1.EchoErrorWithDetailsRequest
+	(*ServerStreamRequest)(nil),          // 8: echo.v1.ServerStreamRequest
+	(*ServerStreamResumableRequest)(nil), // 9: echo.v1.ServerStreamResumableRequest
+	(*HeartbeatStreamRequest)(nil),       // 10: echo.v1.HeartbeatStreamRequest
+	(*StructuredRequest)(nil),            // 11: echo.v1.StructuredRequest
+	(*MapRequest)(nil),                   // 12: echo.v1.MapRequest
+	(*RepeatedRequest)(nil),              // 13: echo.v1.RepeatedRequest
+	(*ScalarsRequest)(nil),               // 14: echo.v1.ScalarsRequest
+	(*VersionRequest)(nil),               // 15: echo.v1.VersionRequest
+	(*NetworkRequest)(nil),               // 16: echo.v1.NetworkRequest
+	(*CredentialsRequest)(nil),           // 17: echo.v1.CredentialsRequest
+	(*Http2SettingsRequest)(nil),         // 18: echo.v1.Http2SettingsRequest
+	(*ServerTimeRequest)(nil),            // 19: echo.v1.ServerTimeRequest
+	(*BurnResourcesRequest)(nil),         // 20: echo.v1.BurnResourcesRequest
+	(*EchoResponse)(nil),                 // 21: echo.v1.EchoResponse
+	(*EchoRequestMetadataResponse)(nil),  // 22: echo.v1.EchoRequestMetadataResponse
+	(*EchoLargePayloadResponse)(nil),     // 23: echo.v1.EchoLargePayloadResponse
+	(*EchoDeadlineResponse)(nil),         // 24: echo.v1.EchoDeadlineResponse
+	(*ResumableStreamResponse)(nil),      // 25: echo.v1.ResumableStreamResponse
+	(*HeartbeatStreamResponse)(nil),      // 26: echo.v1.HeartbeatStreamResponse
+	(*StructuredResponse)(nil),           // 27: echo.v1.StructuredResponse
+	(*MapResponse)(nil),                  // 28: echo.v1.MapResponse
+	(*RepeatedResponse)(nil),             // 29: echo.v1.RepeatedResponse
+	(*ScalarsResponse)(nil),              // 30: echo.v1.ScalarsResponse
+	(*VersionResponse)(nil),              // 31: echo.v1.VersionResponse
+	(*NetworkResponse)(nil),              // 32: echo.v1.NetworkResponse
+	(*CredentialsResponse)(nil),          // 33: echo.v1.CredentialsResponse
+	(*Http2SettingsResponse)(nil),        // 34: echo.v1.Http2SettingsResponse
+	(*ServerTimeResponse)(nil),           // 35: echo.v1.ServerTimeResponse
+	(*BurnResourcesResponse)(nil),        // 36: echo.v1.BurnResourcesResponse
+}
+var file_echo_v1_echo_proto_depIdxs = []int32{
+	0,  // 0: echo.v1.Echo.Echo:input_type -> echo.v1.EchoRequest
+	1,  // 1: echo.v1.Echo.EchoWithDelay:input_type -> echo.v1.EchoWithDelayRequest
+	2,  // 2: echo.v1.Echo.EchoError:input_type -> echo.v1.EchoErrorRequest
+	3,  // 3: echo.v1.Echo.EchoRequestMetadata:input_type -> echo.v1.EchoRequestMetadataRequest
+	4,  // 4: echo.v1.Echo.EchoWithTrailers:input_type -> echo.v1.EchoWithTrailersRequest
+	5,  // 5: echo.v1.Echo.EchoLargePayload:input_type -> echo.v1.EchoLargePayloadRequest
+	6,  // 6: echo.v1.Echo.EchoDeadline:input_type -> echo.v1.EchoDeadlineRequest
+	7,  // 7: echo.v1.Echo.EchoErrorWithDetails:input_type -> echo.v1.EchoErrorWithDetailsRequest
+	8,  // 8: echo.v1.Echo.ServerStream:input_type -> echo.v1.ServerStreamRequest
+	0,  // 9: echo.v1.Echo.ClientStream:input_type -> echo.v1.EchoRequest
+	0,  // 10: echo.v1.Echo.BidirectionalStream:input_type -> echo.v1.EchoRequest
+	9,  // 11: echo.v1.Echo.ServerStreamResumable:input_type -> echo.v1.ServerStreamResumableRequest
+	10, // 12: echo.v1.Echo.BidirectionalStreamWithHeartbeat:input_type -> echo.v1.HeartbeatStreamRequest
+	11, // 13: echo.v1.Echo.EchoStructured:input_type -> echo.v1.StructuredRequest
+	12, // 14: echo.v1.Echo.EchoMap:input_type -> echo.v1.MapRequest
+	13, // 15: echo.v1.Echo.EchoRepeated:input_type -> echo.v1.RepeatedRequest
+	14, // 16: echo.v1.Echo.EchoScalars:input_type -> echo.v1.ScalarsRequest
+	15, // 17: echo.v1.Echo.Version:input_type -> echo.v1.VersionRequest
+	16, // 18: echo.v1.Echo.Network:input_type -> echo.v1.NetworkRequest
+	17, // 19: echo.v1.Echo.Credentials:input_type -> echo.v1.CredentialsRequest
+	18, // 20: echo.v1.Echo.Http2Settings:input_type -> echo.v1.Http2SettingsRequest
+	19, // 21: echo.v1.Echo.ServerTime:input_type -> echo.v1.ServerTimeRequest
+	20, // 22: echo.v1.Echo.BurnResources:input_type -> echo.v1.BurnResourcesRequest
+	21, // 23: echo.v1.Echo.Echo:output_type -> echo.v1.EchoResponse
+	21, // 24: echo.v1.Echo.EchoWithDelay:output_type -> echo.v1.EchoResponse
+	21, // 25: echo.v1.Echo.EchoError:output_type -> echo.v1.EchoResponse
+	22, // 26: echo.v1.Echo.EchoRequestMetadata:output_type -> echo.v1.EchoRequestMetadataResponse
+	21, // 27: echo.v1.Echo.EchoWithTrailers:output_type -> echo.v1.EchoResponse
+	23, // 28: echo.v1.Echo.EchoLargePayload:output_type -> echo.v1.EchoLargePayloadResponse
+	24, // 29: echo.v1.Echo.EchoDeadline:output_type -> echo.v1.EchoDeadlineResponse
+	21, // 30: echo.v1.Echo.EchoErrorWithDetails:output_type -> echo.v1.EchoResponse
+	21, // 31: echo.v1.Echo.ServerStream:output_type -> echo.v1.EchoResponse
+	21, // 32: echo.v1.Echo.ClientStream:output_type -> echo.v1.EchoResponse
+	21, // 33: echo.v1.Echo.BidirectionalStream:output_type -> echo.v1.EchoResponse
+	25, // 34: echo.v1.Echo.ServerStreamResumable:output_type -> echo.v1.ResumableStreamResponse
+	26, // 35: echo.v1.Echo.BidirectionalStreamWithHeartbeat:output_type -> echo.v1.HeartbeatStreamResponse
+	27, // 36: echo.v1.Echo.EchoStructured:output_type -> echo.v1.StructuredResponse
+	28, // 37: echo.v1.Echo.EchoMap:output_type -> echo.v1.MapResponse
+	29, // 38: echo.v1.Echo.EchoRepeated:output_type -> echo.v1.RepeatedResponse
+	30, // 39: echo.v1.Echo.EchoScalars:output_type -> echo.v1.ScalarsResponse
+	31, // 40: echo.v1.Echo.Version:output_type -> echo.v1.VersionResponse
+	32, // 41: echo.v1.Echo.Network:output_type -> echo.v1.NetworkResponse
+	33, // 42: echo.v1.Echo.Credentials:output_type -> echo.v1.CredentialsResponse
+	34, // 43: echo.v1.Echo.Http2Settings:output_type -> echo.v1.Http2SettingsResponse
+	35, // 44: echo.v1.Echo.ServerTime:output_type -> echo.v1.ServerTimeResponse
+	36, // 45: echo.v1.Echo.BurnResources:output_type -> echo.v1.BurnResourcesResponse
+	23, // [23:46] is the sub-list for method output_type
+	0,  // [0:23] is the sub-list for method input_type
+	0,  // [0:0] is the sub-list for extension type_name
+	0,  // [0:0] is the sub-list for extension extendee
+	0,  // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_echo_v1_echo_proto_init() }
+func file_echo_v1_echo_proto_init() {
+	if File_echo_v1_echo_proto != nil {
+		return
+	}
+	file_echo_v1_echo_burn_proto_init()
+	file_echo_v1_echo_clock_proto_init()
+	file_echo_v1_echo_credentials_proto_init()
+	file_echo_v1_echo_deadline_proto_init()
+	file_echo_v1_echo_http2_proto_init()
+	file_echo_v1_echo_metadata_proto_init()
+	file_echo_v1_echo_network_proto_init()
+	file_echo_v1_echo_payload_proto_init()
+	file_echo_v1_echo_response_proto_init()
+	file_echo_v1_echo_serialization_proto_init()
+	file_echo_v1_echo_stream_proto_init()
+	file_echo_v1_echo_structured_proto_init()
+	file_echo_v1_echo_unary_proto_init()
+	file_echo_v1_echo_version_proto_init()
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_echo_v1_echo_proto_rawDesc), len(file_echo_v1_echo_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   0,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_echo_v1_echo_proto_goTypes,
+		DependencyIndexes: file_echo_v1_echo_proto_depIdxs,
+	}.Build()
+	File_echo_v1_echo_proto = out.File
+	file_echo_v1_echo_proto_goTypes = nil
+	file_echo_v1_echo_proto_depIdxs = nil
+}
@@ -0,0 +1,246 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v6.32.1
+// source: echo/v1/echo_burn.proto
+
+package echov1
+
+import (
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+
+	_ "buf.build/gen/go/bufbuild/protovalidate/protocolbuffers/go/buf/validate"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// BurnResources - Consume CPU time and hold memory during the call, so
+// autoscaling and circuit breaking can be tested against a loaded backend
+type BurnResourcesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CpuMs         int32                  `protobuf:"varint,1,opt,name=cpu_ms,json=cpuMs,proto3" json:"cpu_ms,omitempty"`          // CPU time to consume, split over the goroutines (max BURN_MAX_CPU_MS)
+	MemoryMb      int32                  `protobuf:"varint,2,opt,name=memory_mb,json=memoryMb,proto3" json:"memory_mb,omitempty"` // Memory to allocate and touch (max BURN_MAX_MEMORY_MB across calls)
+	HoldMs        int32                  `protobuf:"varint,3,opt,name=hold_ms,json=holdMs,proto3" json:"hold_ms,omitempty"`       // How long to hold the memory after burning the CPU (max 60000)
+	Parallelism   int32                  `protobuf:"varint,4,opt,name=parallelism,proto3" json:"parallelism,omitempty"`           // Goroutines burning CPU (default 1, max GOMAXPROCS)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BurnResourcesRequest) Reset() {
+	*x = BurnResourcesRequest{}
+	mi := &file_echo_v1_echo_burn_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BurnResourcesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BurnResourcesRequest) ProtoMessage() {}
+
+func (x *BurnResourcesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_echo_v1_echo_burn_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BurnResourcesRequest.ProtoReflect.Descriptor instead.
+func (*BurnResourcesRequest) Descriptor() ([]byte, []int) {
+	return file_echo_v1_echo_burn_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *BurnResourcesRequest) GetCpuMs() int32 {
+	if x != nil {
+		return x.CpuMs
+	}
+	return 0
+}
+
+func (x *BurnResourcesRequest) GetMemoryMb() int32 {
+	if x != nil {
+		return x.MemoryMb
+	}
+	return 0
+}
+
+func (x *BurnResourcesRequest) GetHoldMs() int32 {
+	if x != nil {
+		return x.HoldMs
+	}
+	return 0
+}
+
+func (x *BurnResourcesRequest) GetParallelism() int32 {
+	if x != nil {
+		return x.Parallelism
+	}
+	return 0
+}
+
+type BurnResourcesResponse struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	CpuMs            int64                  `protobuf:"varint,1,opt,name=cpu_ms,json=cpuMs,proto3" json:"cpu_ms,omitempty"`                                      // CPU time consumed, summed over the goroutines
+	MemoryBytes      int64                  `protobuf:"varint,2,opt,name=memory_bytes,json=memoryBytes,proto3" json:"memory_bytes,omitempty"`                    // Memory allocated for the call
+	Parallelism      int32                  `protobuf:"varint,3,opt,name=parallelism,proto3" json:"parallelism,omitempty"`                                       // Goroutines that burned CPU
+	ElapsedMs        int64                  `protobuf:"varint,4,opt,name=elapsed_ms,json=elapsedMs,proto3" json:"elapsed_ms,omitempty"`                          // Duration of the call
+	InFlight         int32                  `protobuf:"varint,5,opt,name=in_flight,json=inFlight,proto3" json:"in_flight,omitempty"`                             // Burning calls, this one included
+	MemoryInUseBytes int64                  `protobuf:"varint,6,opt,name=memory_in_use_bytes,json=memoryInUseBytes,proto3" json:"memory_in_use_bytes,omitempty"` // Memory held by the burning calls, this one included
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *BurnResourcesResponse) Reset() {
+	*x = BurnResourcesResponse{}
+	mi := &file_echo_v1_echo_burn_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BurnResourcesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BurnResourcesResponse) ProtoMessage() {}
+
+func (x *BurnResourcesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_echo_v1_echo_burn_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BurnResourcesResponse.ProtoReflect.Descriptor instead.
+func (*BurnResourcesResponse) Descriptor() ([]byte, []int) {
+	return file_echo_v1_echo_burn_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *BurnResourcesResponse) GetCpuMs() int64 {
+	if x != nil {
+		return x.CpuMs
+	}
+	return 0
+}
+
+func (x *BurnResourcesResponse) GetMemoryBytes() int64 {
+	if x != nil {
+		return x.MemoryBytes
+	}
+	return 0
+}
+
+func (x *BurnResourcesResponse) GetParallelism() int32 {
+	if x != nil {
+		return x.Parallelism
+	}
+	return 0
+}
+
+func (x *BurnResourcesResponse) GetElapsedMs() int64 {
+	if x != nil {
+		return x.ElapsedMs
+	}
+	return 0
+}
+
+func (x *BurnResourcesResponse) GetInFlight() int32 {
+	if x != nil {
+		return x.InFlight
+	}
+	return 0
+}
+
+func (x *BurnResourcesResponse) GetMemoryInUseBytes() int64 {
+	if x != nil {
+		return x.MemoryInUseBytes
+	}
+	return 0
+}
+
+var File_echo_v1_echo_burn_proto protoreflect.FileDescriptor
+
+const file_echo_v1_echo_burn_proto_rawDesc = "" +
+	"\n" +
+	"\x17echo/v1/echo_burn.proto\x12\aecho.v1\x1a\x1bbuf/validate/validate.proto\"\xad\x01\n" +
+	"\x14BurnResourcesRequest\x12\x1e\n" +
+	"\x06cpu_ms\x18\x01 \x01(\x05B\a\xbaH\x04\x1a\x02(\x00R\x05cpuMs\x12$\n" +
+	"\tmemory_mb\x18\x02 \x01(\x05B\a\xbaH\x04\x1a\x02(\x00R\bmemoryMb\x12$\n" +
+	"\ahold_ms\x18\x03 \x01(\x05B\v\xbaH\b\x1a\x06\x18\xe0\xd4\x03(\x00R\x06holdMs\x12)\n" +
+	"\vparallelism\x18\x04 \x01(\x05B\a\xbaH\x04\x1a\x02(\x00R\vparallelism\"\xde\x01\n" +
+	"\x15BurnResourcesResponse\x12\x15\n" +
+	"\x06cpu_ms\x18\x01 \x01(\x03R\x05cpuMs\x12!\n" +
+	"\fmemory_bytes\x18\x02 \x01(\x03R\vmemoryBytes\x12 \n" +
+	"\vparallelism\x18\x03 \x01(\x05R\vparallelism\x12\x1d\n" +
+	"\n" +
+	"elapsed_ms\x18\x04 \x01(\x03R\telapsedMs\x12\x1b\n" +
+	"\tin_flight\x18\x05 \x01(\x05R\binFlight\x12-\n" +
+	"\x13memory_in_use_bytes\x18\x06 \x01(\x03R\x10memoryInUseBytesB<Z:github.com/probitas-test/echo-servers/proto/echo/v1;echov1b\x06proto3"
+
+var (
+	file_echo_v1_echo_burn_proto_rawDescOnce sync.Once
+	file_echo_v1_echo_burn_proto_rawDescData []byte
+)
+
+func file_echo_v1_echo_burn_proto_rawDescGZIP() []byte {
+	file_echo_v1_echo_burn_proto_rawDescOnce.Do(func() {
+		file_echo_v1_echo_burn_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_echo_v1_echo_burn_proto_rawDesc), len(file_echo_v1_echo_burn_proto_rawDesc)))
+	})
+	return file_echo_v1_echo_burn_proto_rawDescData
+}
+
+var file_echo_v1_echo_burn_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_echo_v1_echo_burn_proto_goTypes = []any{
+	(*BurnResourcesRequest)(nil),  // 0: echo.v1.BurnResourcesRequest
+	(*BurnResourcesResponse)(nil), // 1: echo.v1.BurnResourcesResponse
+}
+var file_echo_v1_echo_burn_proto_depIdxs = []int32{
+	0, // [0:0] is the sub-list for method output_type
+	0, // [0:0] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_echo_v1_echo_burn_proto_init() }
+func file_echo_v1_echo_burn_proto_init() {
+	if File_echo_v1_echo_burn_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_echo_v1_echo_burn_proto_rawDesc), len(file_echo_v1_echo_burn_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_echo_v1_echo_burn_proto_goTypes,
+		DependencyIndexes: file_echo_v1_echo_burn_proto_depIdxs,
+		MessageInfos:      file_echo_v1_echo_burn_proto_msgTypes,
+	}.Build()
+	File_echo_v1_echo_burn_proto = out.File
+	file_echo_v1_echo_burn_proto_goTypes = nil
+	file_echo_v1_echo_burn_proto_depIdxs = nil
+}
@@ -0,0 +1,215 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v6.32.1
+// source: echo/v1/echo_clock.proto
+
+package echov1
+
+import (
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// ServerTime - Time of the server clock, skewed by its offset, and the
+// drift of the client clock from it
+type ServerTimeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ClientTime    *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=client_time,json=clientTime,proto3" json:"client_time,omitempty"` // Optional: time of the client clock, to measure its drift
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServerTimeRequest) Reset() {
+	*x = ServerTimeRequest{}
+	mi := &file_echo_v1_echo_clock_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServerTimeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerTimeRequest) ProtoMessage() {}
+
+func (x *ServerTimeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_echo_v1_echo_clock_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerTimeRequest.ProtoReflect.Descriptor instead.
+func (*ServerTimeRequest) Descriptor() ([]byte, []int) {
+	return file_echo_v1_echo_clock_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ServerTimeRequest) GetClientTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ClientTime
+	}
+	return nil
+}
+
+type ServerTimeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ServerTime    *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=server_time,json=serverTime,proto3" json:"server_time,omitempty"`           // Time of the server clock
+	SkewMs        int64                  `protobuf:"varint,2,opt,name=skew_ms,json=skewMs,proto3" json:"skew_ms,omitempty"`                      // Offset of the server clock from the real time (CLOCK_OFFSET_MS)
+	DriftMs       int64                  `protobuf:"varint,3,opt,name=drift_ms,json=driftMs,proto3" json:"drift_ms,omitempty"`                   // client_time minus server_time; 0 without client_time
+	MaxDriftMs    int64                  `protobuf:"varint,4,opt,name=max_drift_ms,json=maxDriftMs,proto3" json:"max_drift_ms,omitempty"`        // Largest drift of x-request-timestamp accepted (0 = not checked)
+	DriftExceeded bool                   `protobuf:"varint,5,opt,name=drift_exceeded,json=driftExceeded,proto3" json:"drift_exceeded,omitempty"` // client_time drifts more than max_drift_ms
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServerTimeResponse) Reset() {
+	*x = ServerTimeResponse{}
+	mi := &file_echo_v1_echo_clock_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServerTimeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerTimeResponse) ProtoMessage() {}
+
+func (x *ServerTimeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_echo_v1_echo_clock_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerTimeResponse.ProtoReflect.Descriptor instead.
+func (*ServerTimeResponse) Descriptor() ([]byte, []int) {
+	return file_echo_v1_echo_clock_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ServerTimeResponse) GetServerTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ServerTime
+	}
+	return nil
+}
+
+func (x *ServerTimeResponse) GetSkewMs() int64 {
+	if x != nil {
+		return x.SkewMs
+	}
+	return 0
+}
+
+func (x *ServerTimeResponse) GetDriftMs() int64 {
+	if x != nil {
+		return x.DriftMs
+	}
+	return 0
+}
+
+func (x *ServerTimeResponse) GetMaxDriftMs() int64 {
+	if x != nil {
+		return x.MaxDriftMs
+	}
+	return 0
+}
+
+func (x *ServerTimeResponse) GetDriftExceeded() bool {
+	if x != nil {
+		return x.DriftExceeded
+	}
+	return false
+}
+
+var File_echo_v1_echo_clock_proto protoreflect.FileDescriptor
+
+const file_echo_v1_echo_clock_proto_rawDesc = "" +
+	"\n" +
+	"\x18echo/v1/echo_clock.proto\x12\aecho.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"P\n" +
+	"\x11ServerTimeRequest\x12;\n" +
+	"\vclient_time\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"clientTime\"\xce\x01\n" +
+	"\x12ServerTimeResponse\x12;\n" +
+	"\vserver_time\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"serverTime\x12\x17\n" +
+	"\askew_ms\x18\x02 \x01(\x03R\x06skewMs\x12\x19\n" +
+	"\bdrift_ms\x18\x03 \x01(\x03R\adriftMs\x12 \n" +
+	"\fmax_drift_ms\x18\x04 \x01(\x03R\n" +
+	"maxDriftMs\x12%\n" +
+	"\x0edrift_exceeded\x18\x05 \x01(\bR\rdriftExceededB<Z:github.com/probitas-test/echo-servers/proto/echo/v1;echov1b\x06proto3"
+
+var (
+	file_echo_v1_echo_clock_proto_rawDescOnce sync.Once
+	file_echo_v1_echo_clock_proto_rawDescData []byte
+)
+
+func file_echo_v1_echo_clock_proto_rawDescGZIP() []byte {
+	file_echo_v1_echo_clock_proto_rawDescOnce.Do(func() {
+		file_echo_v1_echo_clock_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_echo_v1_echo_clock_proto_rawDesc), len(file_echo_v1_echo_clock_proto_rawDesc)))
+	})
+	return file_echo_v1_echo_clock_proto_rawDescData
+}
+
+var file_echo_v1_echo_clock_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_echo_v1_echo_clock_proto_goTypes = []any{
+	(*ServerTimeRequest)(nil),     // 0: echo.v1.ServerTimeRequest
+	(*ServerTimeResponse)(nil),    // 1: echo.v1.ServerTimeResponse
+	(*timestamppb.Timestamp)(nil), // 2: google.protobuf.Timestamp
+}
+var file_echo_v1_echo_clock_proto_depIdxs = []int32{
+	2, // 0: echo.v1.ServerTimeRequest.client_time:type_name -> google.protobuf.Timestamp
+	2, // 1: echo.v1.ServerTimeResponse.server_time:type_name -> google.protobuf.Timestamp
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_echo_v1_echo_clock_proto_init() }
+func file_echo_v1_echo_clock_proto_init() {
+	if File_echo_v1_echo_clock_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_echo_v1_echo_clock_proto_rawDesc), len(file_echo_v1_echo_clock_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_echo_v1_echo_clock_proto_goTypes,
+		DependencyIndexes: file_echo_v1_echo_clock_proto_depIdxs,
+		MessageInfos:      file_echo_v1_echo_clock_proto_msgTypes,
+	}.Build()
+	File_echo_v1_echo_clock_proto = out.File
+	file_echo_v1_echo_clock_proto_goTypes = nil
+	file_echo_v1_echo_clock_proto_depIdxs = nil
+}
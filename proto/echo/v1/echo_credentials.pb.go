@@ -0,0 +1,222 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v6.32.1
+// source: echo/v1/echo_credentials.proto
+
+package echov1
+
+import (
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type CredentialsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CredentialsRequest) Reset() {
+	*x = CredentialsRequest{}
+	mi := &file_echo_v1_echo_credentials_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CredentialsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CredentialsRequest) ProtoMessage() {}
+
+func (x *CredentialsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_echo_v1_echo_credentials_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CredentialsRequest.ProtoReflect.Descriptor instead.
+func (*CredentialsRequest) Descriptor() ([]byte, []int) {
+	return file_echo_v1_echo_credentials_proto_rawDescGZIP(), []int{0}
+}
+
+// Per-RPC credentials received with the call and how the credential store
+// judged them, whether or not credentials are required
+type CredentialsResponse struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Type             string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`                                                                               // none, basic, bearer, jwt or api_key
+	Authenticated    bool                   `protobuf:"varint,2,opt,name=authenticated,proto3" json:"authenticated,omitempty"`                                                            // the credentials match a user or JWT issuer
+	Username         string                 `protobuf:"bytes,3,opt,name=username,proto3" json:"username,omitempty"`                                                                       // authenticated user; for a JWT, its subject
+	Claims           map[string]string      `protobuf:"bytes,4,rep,name=claims,proto3" json:"claims,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // JWT claims, non-strings as JSON
+	Error            string                 `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`                                                                             // why the credentials were rejected
+	Required         bool                   `protobuf:"varint,6,opt,name=required,proto3" json:"required,omitempty"`                                                                      // RPCs without valid credentials are rejected
+	SecurityProtocol string                 `protobuf:"bytes,7,opt,name=security_protocol,json=securityProtocol,proto3" json:"security_protocol,omitempty"`                               // transport security: insecure, tls or alts
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *CredentialsResponse) Reset() {
+	*x = CredentialsResponse{}
+	mi := &file_echo_v1_echo_credentials_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CredentialsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CredentialsResponse) ProtoMessage() {}
+
+func (x *CredentialsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_echo_v1_echo_credentials_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CredentialsResponse.ProtoReflect.Descriptor instead.
+func (*CredentialsResponse) Descriptor() ([]byte, []int) {
+	return file_echo_v1_echo_credentials_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CredentialsResponse) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *CredentialsResponse) GetAuthenticated() bool {
+	if x != nil {
+		return x.Authenticated
+	}
+	return false
+}
+
+func (x *CredentialsResponse) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *CredentialsResponse) GetClaims() map[string]string {
+	if x != nil {
+		return x.Claims
+	}
+	return nil
+}
+
+func (x *CredentialsResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *CredentialsResponse) GetRequired() bool {
+	if x != nil {
+		return x.Required
+	}
+	return false
+}
+
+func (x *CredentialsResponse) GetSecurityProtocol() string {
+	if x != nil {
+		return x.SecurityProtocol
+	}
+	return ""
+}
+
+var File_echo_v1_echo_credentials_proto protoreflect.FileDescriptor
+
+const file_echo_v1_echo_credentials_proto_rawDesc = "" +
+	"\n" +
+	"\x1eecho/v1/echo_credentials.proto\x12\aecho.v1\"\x14\n" +
+	"\x12CredentialsRequest\"\xc7\x02\n" +
+	"\x13CredentialsResponse\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12$\n" +
+	"\rauthenticated\x18\x02 \x01(\bR\rauthenticated\x12\x1a\n" +
+	"\busername\x18\x03 \x01(\tR\busername\x12@\n" +
+	"\x06claims\x18\x04 \x03(\v2(.echo.v1.CredentialsResponse.ClaimsEntryR\x06claims\x12\x14\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\x12\x1a\n" +
+	"\brequired\x18\x06 \x01(\bR\brequired\x12+\n" +
+	"\x11security_protocol\x18\a \x01(\tR\x10securityProtocol\x1a9\n" +
+	"\vClaimsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B<Z:github.com/probitas-test/echo-servers/proto/echo/v1;echov1b\x06proto3"
+
+var (
+	file_echo_v1_echo_credentials_proto_rawDescOnce sync.Once
+	file_echo_v1_echo_credentials_proto_rawDescData []byte
+)
+
+func file_echo_v1_echo_credentials_proto_rawDescGZIP() []byte {
+	file_echo_v1_echo_credentials_proto_rawDescOnce.Do(func() {
+		file_echo_v1_echo_credentials_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_echo_v1_echo_credentials_proto_rawDesc), len(file_echo_v1_echo_credentials_proto_rawDesc)))
+	})
+	return file_echo_v1_echo_credentials_proto_rawDescData
+}
+
+var file_echo_v1_echo_credentials_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_echo_v1_echo_credentials_proto_goTypes = []any{
+	(*CredentialsRequest)(nil),  // 0: echo.v1.CredentialsRequest
+	(*CredentialsResponse)(nil), // 1: echo.v1.CredentialsResponse
+	nil,                         // 2: echo.v1.CredentialsResponse.ClaimsEntry
+}
+var file_echo_v1_echo_credentials_proto_depIdxs = []int32{
+	2, // 0: echo.v1.CredentialsResponse.claims:type_name -> echo.v1.CredentialsResponse.ClaimsEntry
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_echo_v1_echo_credentials_proto_init() }
+func file_echo_v1_echo_credentials_proto_init() {
+	if File_echo_v1_echo_credentials_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_echo_v1_echo_credentials_proto_rawDesc), len(file_echo_v1_echo_credentials_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_echo_v1_echo_credentials_proto_goTypes,
+		DependencyIndexes: file_echo_v1_echo_credentials_proto_depIdxs,
+		MessageInfos:      file_echo_v1_echo_credentials_proto_msgTypes,
+	}.Build()
+	File_echo_v1_echo_credentials_proto = out.File
+	file_echo_v1_echo_credentials_proto_goTypes = nil
+	file_echo_v1_echo_credentials_proto_depIdxs = nil
+}
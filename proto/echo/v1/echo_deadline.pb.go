@@ -2,9 +2,9 @@
 // versions:
 // 	protoc-gen-go v1.36.11
 // 	protoc        v6.32.1
-// source: echo_deadline.proto
+// source: echo/v1/echo_deadline.proto
 
-package proto
+package echov1
 
 import (
 	reflect "reflect"
@@ -31,7 +31,7 @@ type EchoDeadlineRequest struct {
 
 func (x *EchoDeadlineRequest) Reset() {
 	*x = EchoDeadlineRequest{}
-	mi := &file_echo_deadline_proto_msgTypes[0]
+	mi := &file_echo_v1_echo_deadline_proto_msgTypes[0]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -43,7 +43,7 @@ func (x *EchoDeadlineRequest) String() string {
 func (*EchoDeadlineRequest) ProtoMessage() {}
 
 func (x *EchoDeadlineRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_echo_deadline_proto_msgTypes[0]
+	mi := &file_echo_v1_echo_deadline_proto_msgTypes[0]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -56,7 +56,7 @@ func (x *EchoDeadlineRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use EchoDeadlineRequest.ProtoReflect.Descriptor instead.
 func (*EchoDeadlineRequest) Descriptor() ([]byte, []int) {
-	return file_echo_deadline_proto_rawDescGZIP(), []int{0}
+	return file_echo_v1_echo_deadline_proto_rawDescGZIP(), []int{0}
 }
 
 func (x *EchoDeadlineRequest) GetMessage() string {
@@ -77,7 +77,7 @@ type EchoDeadlineResponse struct {
 
 func (x *EchoDeadlineResponse) Reset() {
 	*x = EchoDeadlineResponse{}
-	mi := &file_echo_deadline_proto_msgTypes[1]
+	mi := &file_echo_v1_echo_deadline_proto_msgTypes[1]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -89,7 +89,7 @@ func (x *EchoDeadlineResponse) String() string {
 func (*EchoDeadlineResponse) ProtoMessage() {}
 
 func (x *EchoDeadlineResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_echo_deadline_proto_msgTypes[1]
+	mi := &file_echo_v1_echo_deadline_proto_msgTypes[1]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -102,7 +102,7 @@ func (x *EchoDeadlineResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use EchoDeadlineResponse.ProtoReflect.Descriptor instead.
 func (*EchoDeadlineResponse) Descriptor() ([]byte, []int) {
-	return file_echo_deadline_proto_rawDescGZIP(), []int{1}
+	return file_echo_v1_echo_deadline_proto_rawDescGZIP(), []int{1}
 }
 
 func (x *EchoDeadlineResponse) GetMessage() string {
@@ -126,36 +126,36 @@ func (x *EchoDeadlineResponse) GetHasDeadline() bool {
 	return false
 }
 
-var File_echo_deadline_proto protoreflect.FileDescriptor
+var File_echo_v1_echo_deadline_proto protoreflect.FileDescriptor
 
-const file_echo_deadline_proto_rawDesc = "" +
+const file_echo_v1_echo_deadline_proto_rawDesc = "" +
 	"\n" +
-	"\x13echo_deadline.proto\x12\aecho.v1\"/\n" +
+	"\x1becho/v1/echo_deadline.proto\x12\aecho.v1\"/\n" +
 	"\x13EchoDeadlineRequest\x12\x18\n" +
 	"\amessage\x18\x01 \x01(\tR\amessage\"\x87\x01\n" +
 	"\x14EchoDeadlineResponse\x12\x18\n" +
 	"\amessage\x18\x01 \x01(\tR\amessage\x122\n" +
 	"\x15deadline_remaining_ms\x18\x02 \x01(\x03R\x13deadlineRemainingMs\x12!\n" +
-	"\fhas_deadline\x18\x03 \x01(\bR\vhasDeadlineB7Z5github.com/probitas-test/echo-servers/echo-grpc/protob\x06proto3"
+	"\fhas_deadline\x18\x03 \x01(\bR\vhasDeadlineB<Z:github.com/probitas-test/echo-servers/proto/echo/v1;echov1b\x06proto3"
 
 var (
-	file_echo_deadline_proto_rawDescOnce sync.Once
-	file_echo_deadline_proto_rawDescData []byte
+	file_echo_v1_echo_deadline_proto_rawDescOnce sync.Once
+	file_echo_v1_echo_deadline_proto_rawDescData []byte
 )
 
-func file_echo_deadline_proto_rawDescGZIP() []byte {
-	file_echo_deadline_proto_rawDescOnce.Do(func() {
-		file_echo_deadline_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_echo_deadline_proto_rawDesc), len(file_echo_deadline_proto_rawDesc)))
+func file_echo_v1_echo_deadline_proto_rawDescGZIP() []byte {
+	file_echo_v1_echo_deadline_proto_rawDescOnce.Do(func() {
+		file_echo_v1_echo_deadline_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_echo_v1_echo_deadline_proto_rawDesc), len(file_echo_v1_echo_deadline_proto_rawDesc)))
 	})
-	return file_echo_deadline_proto_rawDescData
+	return file_echo_v1_echo_deadline_proto_rawDescData
 }
 
-var file_echo_deadline_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
-var file_echo_deadline_proto_goTypes = []any{
+var file_echo_v1_echo_deadline_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_echo_v1_echo_deadline_proto_goTypes = []any{
 	(*EchoDeadlineRequest)(nil),  // 0: echo.v1.EchoDeadlineRequest
 	(*EchoDeadlineResponse)(nil), // 1: echo.v1.EchoDeadlineResponse
 }
-var file_echo_deadline_proto_depIdxs = []int32{
+var file_echo_v1_echo_deadline_proto_depIdxs = []int32{
 	0, // [0:0] is the sub-list for method output_type
 	0, // [0:0] is the sub-list for method input_type
 	0, // [0:0] is the sub-list for extension type_name
@@ -163,26 +163,26 @@ var file_echo_deadline_proto_depIdxs = []int32{
 	0, // [0:0] is the sub-list for field type_name
 }
 
-func init() { file_echo_deadline_proto_init() }
-func file_echo_deadline_proto_init() {
-	if File_echo_deadline_proto != nil {
+func init() { file_echo_v1_echo_deadline_proto_init() }
+func file_echo_v1_echo_deadline_proto_init() {
+	if File_echo_v1_echo_deadline_proto != nil {
 		return
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
-			RawDescriptor: unsafe.Slice(unsafe.StringData(file_echo_deadline_proto_rawDesc), len(file_echo_deadline_proto_rawDesc)),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_echo_v1_echo_deadline_proto_rawDesc), len(file_echo_v1_echo_deadline_proto_rawDesc)),
 			NumEnums:      0,
 			NumMessages:   2,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
-		GoTypes:           file_echo_deadline_proto_goTypes,
-		DependencyIndexes: file_echo_deadline_proto_depIdxs,
-		MessageInfos:      file_echo_deadline_proto_msgTypes,
+		GoTypes:           file_echo_v1_echo_deadline_proto_goTypes,
+		DependencyIndexes: file_echo_v1_echo_deadline_proto_depIdxs,
+		MessageInfos:      file_echo_v1_echo_deadline_proto_msgTypes,
 	}.Build()
-	File_echo_deadline_proto = out.File
-	file_echo_deadline_proto_goTypes = nil
-	file_echo_deadline_proto_depIdxs = nil
+	File_echo_v1_echo_deadline_proto = out.File
+	file_echo_v1_echo_deadline_proto_goTypes = nil
+	file_echo_v1_echo_deadline_proto_depIdxs = nil
 }
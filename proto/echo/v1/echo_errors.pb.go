@@ -0,0 +1,547 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v6.32.1
+// source: echo/v1/echo_errors.proto
+
+package echov1
+
+import (
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+
+	_ "buf.build/gen/go/bufbuild/protovalidate/protocolbuffers/go/buf/validate"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	anypb "google.golang.org/protobuf/types/known/anypb"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// ErrorDetail is a google.rpc error detail, its type naming the message and
+// the fields it uses
+type ErrorDetail struct {
+	state                  protoimpl.MessageState   `protogen:"open.v1"`
+	Type                   string                   `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	FieldViolations        []*FieldViolation        `protobuf:"bytes,2,rep,name=field_violations,json=fieldViolations,proto3" json:"field_violations,omitempty"`
+	RetryDelayMs           int64                    `protobuf:"varint,3,opt,name=retry_delay_ms,json=retryDelayMs,proto3" json:"retry_delay_ms,omitempty"`
+	StackEntries           []string                 `protobuf:"bytes,4,rep,name=stack_entries,json=stackEntries,proto3" json:"stack_entries,omitempty"`
+	DebugDetail            string                   `protobuf:"bytes,5,opt,name=debug_detail,json=debugDetail,proto3" json:"debug_detail,omitempty"`
+	QuotaViolations        []*QuotaViolation        `protobuf:"bytes,6,rep,name=quota_violations,json=quotaViolations,proto3" json:"quota_violations,omitempty"`
+	Reason                 string                   `protobuf:"bytes,7,opt,name=reason,proto3" json:"reason,omitempty"`                                                                               // error_info
+	Domain                 string                   `protobuf:"bytes,8,opt,name=domain,proto3" json:"domain,omitempty"`                                                                               // error_info
+	Metadata               map[string]string        `protobuf:"bytes,9,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // error_info
+	Locale                 string                   `protobuf:"bytes,10,opt,name=locale,proto3" json:"locale,omitempty"`                                                                              // localized_message: BCP 47 tag such as en-US
+	LocalizedMessage       string                   `protobuf:"bytes,11,opt,name=localized_message,json=localizedMessage,proto3" json:"localized_message,omitempty"`                                  // localized_message
+	Links                  []*HelpLink              `protobuf:"bytes,12,rep,name=links,proto3" json:"links,omitempty"`                                                                                // help
+	PreconditionViolations []*PreconditionViolation `protobuf:"bytes,13,rep,name=precondition_violations,json=preconditionViolations,proto3" json:"precondition_violations,omitempty"`                // precondition_failure
+	ResourceType           string                   `protobuf:"bytes,14,opt,name=resource_type,json=resourceType,proto3" json:"resource_type,omitempty"`                                              // resource_info
+	ResourceName           string                   `protobuf:"bytes,15,opt,name=resource_name,json=resourceName,proto3" json:"resource_name,omitempty"`                                              // resource_info
+	Owner                  string                   `protobuf:"bytes,16,opt,name=owner,proto3" json:"owner,omitempty"`                                                                                // resource_info
+	Description            string                   `protobuf:"bytes,17,opt,name=description,proto3" json:"description,omitempty"`                                                                    // resource_info
+	RequestId              string                   `protobuf:"bytes,18,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`                                                       // request_info
+	ServingData            string                   `protobuf:"bytes,19,opt,name=serving_data,json=servingData,proto3" json:"serving_data,omitempty"`                                                 // request_info
+	Any                    *anypb.Any               `protobuf:"bytes,20,opt,name=any,proto3" json:"any,omitempty"`                                                                                    // any: attached unchanged
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *ErrorDetail) Reset() {
+	*x = ErrorDetail{}
+	mi := &file_echo_v1_echo_errors_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ErrorDetail) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ErrorDetail) ProtoMessage() {}
+
+func (x *ErrorDetail) ProtoReflect() protoreflect.Message {
+	mi := &file_echo_v1_echo_errors_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ErrorDetail.ProtoReflect.Descriptor instead.
+func (*ErrorDetail) Descriptor() ([]byte, []int) {
+	return file_echo_v1_echo_errors_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ErrorDetail) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *ErrorDetail) GetFieldViolations() []*FieldViolation {
+	if x != nil {
+		return x.FieldViolations
+	}
+	return nil
+}
+
+func (x *ErrorDetail) GetRetryDelayMs() int64 {
+	if x != nil {
+		return x.RetryDelayMs
+	}
+	return 0
+}
+
+func (x *ErrorDetail) GetStackEntries() []string {
+	if x != nil {
+		return x.StackEntries
+	}
+	return nil
+}
+
+func (x *ErrorDetail) GetDebugDetail() string {
+	if x != nil {
+		return x.DebugDetail
+	}
+	return ""
+}
+
+func (x *ErrorDetail) GetQuotaViolations() []*QuotaViolation {
+	if x != nil {
+		return x.QuotaViolations
+	}
+	return nil
+}
+
+func (x *ErrorDetail) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *ErrorDetail) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+func (x *ErrorDetail) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *ErrorDetail) GetLocale() string {
+	if x != nil {
+		return x.Locale
+	}
+	return ""
+}
+
+func (x *ErrorDetail) GetLocalizedMessage() string {
+	if x != nil {
+		return x.LocalizedMessage
+	}
+	return ""
+}
+
+func (x *ErrorDetail) GetLinks() []*HelpLink {
+	if x != nil {
+		return x.Links
+	}
+	return nil
+}
+
+func (x *ErrorDetail) GetPreconditionViolations() []*PreconditionViolation {
+	if x != nil {
+		return x.PreconditionViolations
+	}
+	return nil
+}
+
+func (x *ErrorDetail) GetResourceType() string {
+	if x != nil {
+		return x.ResourceType
+	}
+	return ""
+}
+
+func (x *ErrorDetail) GetResourceName() string {
+	if x != nil {
+		return x.ResourceName
+	}
+	return ""
+}
+
+func (x *ErrorDetail) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *ErrorDetail) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *ErrorDetail) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *ErrorDetail) GetServingData() string {
+	if x != nil {
+		return x.ServingData
+	}
+	return ""
+}
+
+func (x *ErrorDetail) GetAny() *anypb.Any {
+	if x != nil {
+		return x.Any
+	}
+	return nil
+}
+
+type FieldViolation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Field         string                 `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FieldViolation) Reset() {
+	*x = FieldViolation{}
+	mi := &file_echo_v1_echo_errors_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FieldViolation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FieldViolation) ProtoMessage() {}
+
+func (x *FieldViolation) ProtoReflect() protoreflect.Message {
+	mi := &file_echo_v1_echo_errors_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FieldViolation.ProtoReflect.Descriptor instead.
+func (*FieldViolation) Descriptor() ([]byte, []int) {
+	return file_echo_v1_echo_errors_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *FieldViolation) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *FieldViolation) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+type QuotaViolation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Subject       string                 `protobuf:"bytes,1,opt,name=subject,proto3" json:"subject,omitempty"`
+	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QuotaViolation) Reset() {
+	*x = QuotaViolation{}
+	mi := &file_echo_v1_echo_errors_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QuotaViolation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QuotaViolation) ProtoMessage() {}
+
+func (x *QuotaViolation) ProtoReflect() protoreflect.Message {
+	mi := &file_echo_v1_echo_errors_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QuotaViolation.ProtoReflect.Descriptor instead.
+func (*QuotaViolation) Descriptor() ([]byte, []int) {
+	return file_echo_v1_echo_errors_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *QuotaViolation) GetSubject() string {
+	if x != nil {
+		return x.Subject
+	}
+	return ""
+}
+
+func (x *QuotaViolation) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+type HelpLink struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Description   string                 `protobuf:"bytes,1,opt,name=description,proto3" json:"description,omitempty"`
+	Url           string                 `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HelpLink) Reset() {
+	*x = HelpLink{}
+	mi := &file_echo_v1_echo_errors_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HelpLink) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HelpLink) ProtoMessage() {}
+
+func (x *HelpLink) ProtoReflect() protoreflect.Message {
+	mi := &file_echo_v1_echo_errors_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HelpLink.ProtoReflect.Descriptor instead.
+func (*HelpLink) Descriptor() ([]byte, []int) {
+	return file_echo_v1_echo_errors_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *HelpLink) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *HelpLink) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+type PreconditionViolation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Subject       string                 `protobuf:"bytes,2,opt,name=subject,proto3" json:"subject,omitempty"`
+	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PreconditionViolation) Reset() {
+	*x = PreconditionViolation{}
+	mi := &file_echo_v1_echo_errors_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PreconditionViolation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PreconditionViolation) ProtoMessage() {}
+
+func (x *PreconditionViolation) ProtoReflect() protoreflect.Message {
+	mi := &file_echo_v1_echo_errors_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PreconditionViolation.ProtoReflect.Descriptor instead.
+func (*PreconditionViolation) Descriptor() ([]byte, []int) {
+	return file_echo_v1_echo_errors_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *PreconditionViolation) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *PreconditionViolation) GetSubject() string {
+	if x != nil {
+		return x.Subject
+	}
+	return ""
+}
+
+func (x *PreconditionViolation) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+var File_echo_v1_echo_errors_proto protoreflect.FileDescriptor
+
+const file_echo_v1_echo_errors_proto_rawDesc = "" +
+	"\n" +
+	"\x19echo/v1/echo_errors.proto\x12\aecho.v1\x1a\x1bbuf/validate/validate.proto\x1a\x19google/protobuf/any.proto\"\x9c\b\n" +
+	"\vErrorDetail\x12\xad\x01\n" +
+	"\x04type\x18\x01 \x01(\tB\x98\x01\xbaH\x94\x01r\x91\x01R\vbad_requestR\n" +
+	"retry_infoR\n" +
+	"debug_infoR\rquota_failureR\n" +
+	"error_infoR\x11localized_messageR\x04helpR\x14precondition_failureR\rresource_infoR\frequest_infoR\x03anyR\x04type\x12B\n" +
+	"\x10field_violations\x18\x02 \x03(\v2\x17.echo.v1.FieldViolationR\x0ffieldViolations\x12-\n" +
+	"\x0eretry_delay_ms\x18\x03 \x01(\x03B\a\xbaH\x04\"\x02(\x00R\fretryDelayMs\x12#\n" +
+	"\rstack_entries\x18\x04 \x03(\tR\fstackEntries\x12!\n" +
+	"\fdebug_detail\x18\x05 \x01(\tR\vdebugDetail\x12B\n" +
+	"\x10quota_violations\x18\x06 \x03(\v2\x17.echo.v1.QuotaViolationR\x0fquotaViolations\x12\x16\n" +
+	"\x06reason\x18\a \x01(\tR\x06reason\x12\x16\n" +
+	"\x06domain\x18\b \x01(\tR\x06domain\x12>\n" +
+	"\bmetadata\x18\t \x03(\v2\".echo.v1.ErrorDetail.MetadataEntryR\bmetadata\x12\x16\n" +
+	"\x06locale\x18\n" +
+	" \x01(\tR\x06locale\x12+\n" +
+	"\x11localized_message\x18\v \x01(\tR\x10localizedMessage\x12'\n" +
+	"\x05links\x18\f \x03(\v2\x11.echo.v1.HelpLinkR\x05links\x12W\n" +
+	"\x17precondition_violations\x18\r \x03(\v2\x1e.echo.v1.PreconditionViolationR\x16preconditionViolations\x12#\n" +
+	"\rresource_type\x18\x0e \x01(\tR\fresourceType\x12#\n" +
+	"\rresource_name\x18\x0f \x01(\tR\fresourceName\x12\x14\n" +
+	"\x05owner\x18\x10 \x01(\tR\x05owner\x12 \n" +
+	"\vdescription\x18\x11 \x01(\tR\vdescription\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x12 \x01(\tR\trequestId\x12!\n" +
+	"\fserving_data\x18\x13 \x01(\tR\vservingData\x12&\n" +
+	"\x03any\x18\x14 \x01(\v2\x14.google.protobuf.AnyR\x03any\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"Q\n" +
+	"\x0eFieldViolation\x12\x1d\n" +
+	"\x05field\x18\x01 \x01(\tB\a\xbaH\x04r\x02\x10\x01R\x05field\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\"L\n" +
+	"\x0eQuotaViolation\x12\x18\n" +
+	"\asubject\x18\x01 \x01(\tR\asubject\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\">\n" +
+	"\bHelpLink\x12 \n" +
+	"\vdescription\x18\x01 \x01(\tR\vdescription\x12\x10\n" +
+	"\x03url\x18\x02 \x01(\tR\x03url\"g\n" +
+	"\x15PreconditionViolation\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x18\n" +
+	"\asubject\x18\x02 \x01(\tR\asubject\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescriptionB<Z:github.com/probitas-test/echo-servers/proto/echo/v1;echov1b\x06proto3"
+
+var (
+	file_echo_v1_echo_errors_proto_rawDescOnce sync.Once
+	file_echo_v1_echo_errors_proto_rawDescData []byte
+)
+
+func file_echo_v1_echo_errors_proto_rawDescGZIP() []byte {
+	file_echo_v1_echo_errors_proto_rawDescOnce.Do(func() {
+		file_echo_v1_echo_errors_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_echo_v1_echo_errors_proto_rawDesc), len(file_echo_v1_echo_errors_proto_rawDesc)))
+	})
+	return file_echo_v1_echo_errors_proto_rawDescData
+}
+
+var file_echo_v1_echo_errors_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_echo_v1_echo_errors_proto_goTypes = []any{
+	(*ErrorDetail)(nil),           // 0: echo.v1.ErrorDetail
+	(*FieldViolation)(nil),        // 1: echo.v1.FieldViolation
+	(*QuotaViolation)(nil),        // 2: echo.v1.QuotaViolation
+	(*HelpLink)(nil),              // 3: echo.v1.HelpLink
+	(*PreconditionViolation)(nil), // 4: echo.v1.PreconditionViolation
+	nil,                           // 5: echo.v1.ErrorDetail.MetadataEntry
+	(*anypb.Any)(nil),             // 6: google.protobuf.Any
+}
+var file_echo_v1_echo_errors_proto_depIdxs = []int32{
+	1, // 0: echo.v1.ErrorDetail.field_violations:type_name -> echo.v1.FieldViolation
+	2, // 1: echo.v1.ErrorDetail.quota_violations:type_name -> echo.v1.QuotaViolation
+	5, // 2: echo.v1.ErrorDetail.metadata:type_name -> echo.v1.ErrorDetail.MetadataEntry
+	3, // 3: echo.v1.ErrorDetail.links:type_name -> echo.v1.HelpLink
+	4, // 4: echo.v1.ErrorDetail.precondition_violations:type_name -> echo.v1.PreconditionViolation
+	6, // 5: echo.v1.ErrorDetail.any:type_name -> google.protobuf.Any
+	6, // [6:6] is the sub-list for method output_type
+	6, // [6:6] is the sub-list for method input_type
+	6, // [6:6] is the sub-list for extension type_name
+	6, // [6:6] is the sub-list for extension extendee
+	0, // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_echo_v1_echo_errors_proto_init() }
+func file_echo_v1_echo_errors_proto_init() {
+	if File_echo_v1_echo_errors_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_echo_v1_echo_errors_proto_rawDesc), len(file_echo_v1_echo_errors_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   6,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_echo_v1_echo_errors_proto_goTypes,
+		DependencyIndexes: file_echo_v1_echo_errors_proto_depIdxs,
+		MessageInfos:      file_echo_v1_echo_errors_proto_msgTypes,
+	}.Build()
+	File_echo_v1_echo_errors_proto = out.File
+	file_echo_v1_echo_errors_proto_goTypes = nil
+	file_echo_v1_echo_errors_proto_depIdxs = nil
+}
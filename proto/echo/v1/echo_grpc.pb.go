@@ -0,0 +1,988 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.0
+// - protoc             v6.32.1
+// source: echo/v1/echo.proto
+
+package echov1
+
+import (
+	context "context"
+
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Echo_Echo_FullMethodName                             = "/echo.v1.Echo/Echo"
+	Echo_EchoWithDelay_FullMethodName                    = "/echo.v1.Echo/EchoWithDelay"
+	Echo_EchoError_FullMethodName                        = "/echo.v1.Echo/EchoError"
+	Echo_EchoRequestMetadata_FullMethodName              = "/echo.v1.Echo/EchoRequestMetadata"
+	Echo_EchoWithTrailers_FullMethodName                 = "/echo.v1.Echo/EchoWithTrailers"
+	Echo_EchoLargePayload_FullMethodName                 = "/echo.v1.Echo/EchoLargePayload"
+	Echo_EchoDeadline_FullMethodName                     = "/echo.v1.Echo/EchoDeadline"
+	Echo_EchoErrorWithDetails_FullMethodName             = "/echo.v1.Echo/EchoErrorWithDetails"
+	Echo_ServerStream_FullMethodName                     = "/echo.v1.Echo/ServerStream"
+	Echo_ClientStream_FullMethodName                     = "/echo.v1.Echo/ClientStream"
+	Echo_BidirectionalStream_FullMethodName              = "/echo.v1.Echo/BidirectionalStream"
+	Echo_ServerStreamResumable_FullMethodName            = "/echo.v1.Echo/ServerStreamResumable"
+	Echo_BidirectionalStreamWithHeartbeat_FullMethodName = "/echo.v1.Echo/BidirectionalStreamWithHeartbeat"
+	Echo_EchoStructured_FullMethodName                   = "/echo.v1.Echo/EchoStructured"
+	Echo_EchoMap_FullMethodName                          = "/echo.v1.Echo/EchoMap"
+	Echo_EchoRepeated_FullMethodName                     = "/echo.v1.Echo/EchoRepeated"
+	Echo_EchoScalars_FullMethodName                      = "/echo.v1.Echo/EchoScalars"
+	Echo_Version_FullMethodName                          = "/echo.v1.Echo/Version"
+	Echo_Network_FullMethodName                          = "/echo.v1.Echo/Network"
+	Echo_Credentials_FullMethodName                      = "/echo.v1.Echo/Credentials"
+	Echo_Http2Settings_FullMethodName                    = "/echo.v1.Echo/Http2Settings"
+	Echo_ServerTime_FullMethodName                       = "/echo.v1.Echo/ServerTime"
+	Echo_BurnResources_FullMethodName                    = "/echo.v1.Echo/BurnResources"
+)
+
+// EchoClient is the client API for Echo service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Echo service with various RPC patterns, shared by echo-grpc and
+// echo-connectrpc. RPCs marked for one server answer UNIMPLEMENTED on the
+// other. RPCs whose response depends only on the request are
+// NO_SIDE_EFFECTS, so Connect clients may call them with HTTP GET.
+type EchoClient interface {
+	// Unary RPCs
+	Echo(ctx context.Context, in *EchoRequest, opts ...grpc.CallOption) (*EchoResponse, error)
+	EchoWithDelay(ctx context.Context, in *EchoWithDelayRequest, opts ...grpc.CallOption) (*EchoResponse, error)
+	EchoError(ctx context.Context, in *EchoErrorRequest, opts ...grpc.CallOption) (*EchoResponse, error)
+	// Metadata/Headers RPCs
+	EchoRequestMetadata(ctx context.Context, in *EchoRequestMetadataRequest, opts ...grpc.CallOption) (*EchoRequestMetadataResponse, error)
+	EchoWithTrailers(ctx context.Context, in *EchoWithTrailersRequest, opts ...grpc.CallOption) (*EchoResponse, error)
+	// Payload Testing RPCs
+	EchoLargePayload(ctx context.Context, in *EchoLargePayloadRequest, opts ...grpc.CallOption) (*EchoLargePayloadResponse, error)
+	// Deadline/Timeout RPCs
+	EchoDeadline(ctx context.Context, in *EchoDeadlineRequest, opts ...grpc.CallOption) (*EchoDeadlineResponse, error)
+	// Error Scenarios RPCs
+	EchoErrorWithDetails(ctx context.Context, in *EchoErrorWithDetailsRequest, opts ...grpc.CallOption) (*EchoResponse, error)
+	// Streaming RPCs
+	ServerStream(ctx context.Context, in *ServerStreamRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[EchoResponse], error)
+	ClientStream(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[EchoRequest, EchoResponse], error)
+	BidirectionalStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[EchoRequest, EchoResponse], error)
+	// Resumable server streaming RPC (echo-grpc)
+	ServerStreamResumable(ctx context.Context, in *ServerStreamResumableRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ResumableStreamResponse], error)
+	// Heartbeat streaming RPC (echo-connectrpc)
+	BidirectionalStreamWithHeartbeat(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[HeartbeatStreamRequest, HeartbeatStreamResponse], error)
+	// Structured message RPC
+	EchoStructured(ctx context.Context, in *StructuredRequest, opts ...grpc.CallOption) (*StructuredResponse, error)
+	// Serialization edge case RPCs (echo-grpc)
+	EchoMap(ctx context.Context, in *MapRequest, opts ...grpc.CallOption) (*MapResponse, error)
+	EchoRepeated(ctx context.Context, in *RepeatedRequest, opts ...grpc.CallOption) (*RepeatedResponse, error)
+	EchoScalars(ctx context.Context, in *ScalarsRequest, opts ...grpc.CallOption) (*ScalarsResponse, error)
+	// Build information RPC
+	Version(ctx context.Context, in *VersionRequest, opts ...grpc.CallOption) (*VersionResponse, error)
+	// Address family RPC
+	Network(ctx context.Context, in *NetworkRequest, opts ...grpc.CallOption) (*NetworkResponse, error)
+	// Call credentials RPC (echo-grpc)
+	Credentials(ctx context.Context, in *CredentialsRequest, opts ...grpc.CallOption) (*CredentialsResponse, error)
+	// HTTP/2 settings RPC (echo-grpc)
+	Http2Settings(ctx context.Context, in *Http2SettingsRequest, opts ...grpc.CallOption) (*Http2SettingsResponse, error)
+	// Server clock RPC (echo-grpc)
+	ServerTime(ctx context.Context, in *ServerTimeRequest, opts ...grpc.CallOption) (*ServerTimeResponse, error)
+	// Load test RPC (echo-grpc)
+	BurnResources(ctx context.Context, in *BurnResourcesRequest, opts ...grpc.CallOption) (*BurnResourcesResponse, error)
+}
+
+type echoClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewEchoClient(cc grpc.ClientConnInterface) EchoClient {
+	return &echoClient{cc}
+}
+
+func (c *echoClient) Echo(ctx context.Context, in *EchoRequest, opts ...grpc.CallOption) (*EchoResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EchoResponse)
+	err := c.cc.Invoke(ctx, Echo_Echo_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *echoClient) EchoWithDelay(ctx context.Context, in *EchoWithDelayRequest, opts ...grpc.CallOption) (*EchoResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EchoResponse)
+	err := c.cc.Invoke(ctx, Echo_EchoWithDelay_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *echoClient) EchoError(ctx context.Context, in *EchoErrorRequest, opts ...grpc.CallOption) (*EchoResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EchoResponse)
+	err := c.cc.Invoke(ctx, Echo_EchoError_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *echoClient) EchoRequestMetadata(ctx context.Context, in *EchoRequestMetadataRequest, opts ...grpc.CallOption) (*EchoRequestMetadataResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EchoRequestMetadataResponse)
+	err := c.cc.Invoke(ctx, Echo_EchoRequestMetadata_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *echoClient) EchoWithTrailers(ctx context.Context, in *EchoWithTrailersRequest, opts ...grpc.CallOption) (*EchoResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EchoResponse)
+	err := c.cc.Invoke(ctx, Echo_EchoWithTrailers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *echoClient) EchoLargePayload(ctx context.Context, in *EchoLargePayloadRequest, opts ...grpc.CallOption) (*EchoLargePayloadResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EchoLargePayloadResponse)
+	err := c.cc.Invoke(ctx, Echo_EchoLargePayload_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *echoClient) EchoDeadline(ctx context.Context, in *EchoDeadlineRequest, opts ...grpc.CallOption) (*EchoDeadlineResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EchoDeadlineResponse)
+	err := c.cc.Invoke(ctx, Echo_EchoDeadline_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *echoClient) EchoErrorWithDetails(ctx context.Context, in *EchoErrorWithDetailsRequest, opts ...grpc.CallOption) (*EchoResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EchoResponse)
+	err := c.cc.Invoke(ctx, Echo_EchoErrorWithDetails_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *echoClient) ServerStream(ctx context.Context, in *ServerStreamRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[EchoResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Echo_ServiceDesc.Streams[0], Echo_ServerStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ServerStreamRequest, EchoResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Echo_ServerStreamClient = grpc.ServerStreamingClient[EchoResponse]
+
+func (c *echoClient) ClientStream(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[EchoRequest, EchoResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Echo_ServiceDesc.Streams[1], Echo_ClientStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[EchoRequest, EchoResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Echo_ClientStreamClient = grpc.ClientStreamingClient[EchoRequest, EchoResponse]
+
+func (c *echoClient) BidirectionalStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[EchoRequest, EchoResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Echo_ServiceDesc.Streams[2], Echo_BidirectionalStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[EchoRequest, EchoResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Echo_BidirectionalStreamClient = grpc.BidiStreamingClient[EchoRequest, EchoResponse]
+
+func (c *echoClient) ServerStreamResumable(ctx context.Context, in *ServerStreamResumableRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ResumableStreamResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Echo_ServiceDesc.Streams[3], Echo_ServerStreamResumable_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ServerStreamResumableRequest, ResumableStreamResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Echo_ServerStreamResumableClient = grpc.ServerStreamingClient[ResumableStreamResponse]
+
+func (c *echoClient) BidirectionalStreamWithHeartbeat(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[HeartbeatStreamRequest, HeartbeatStreamResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Echo_ServiceDesc.Streams[4], Echo_BidirectionalStreamWithHeartbeat_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[HeartbeatStreamRequest, HeartbeatStreamResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Echo_BidirectionalStreamWithHeartbeatClient = grpc.BidiStreamingClient[HeartbeatStreamRequest, HeartbeatStreamResponse]
+
+func (c *echoClient) EchoStructured(ctx context.Context, in *StructuredRequest, opts ...grpc.CallOption) (*StructuredResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StructuredResponse)
+	err := c.cc.Invoke(ctx, Echo_EchoStructured_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *echoClient) EchoMap(ctx context.Context, in *MapRequest, opts ...grpc.CallOption) (*MapResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MapResponse)
+	err := c.cc.Invoke(ctx, Echo_EchoMap_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *echoClient) EchoRepeated(ctx context.Context, in *RepeatedRequest, opts ...grpc.CallOption) (*RepeatedResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RepeatedResponse)
+	err := c.cc.Invoke(ctx, Echo_EchoRepeated_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *echoClient) EchoScalars(ctx context.Context, in *ScalarsRequest, opts ...grpc.CallOption) (*ScalarsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ScalarsResponse)
+	err := c.cc.Invoke(ctx, Echo_EchoScalars_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *echoClient) Version(ctx context.Context, in *VersionRequest, opts ...grpc.CallOption) (*VersionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VersionResponse)
+	err := c.cc.Invoke(ctx, Echo_Version_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *echoClient) Network(ctx context.Context, in *NetworkRequest, opts ...grpc.CallOption) (*NetworkResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NetworkResponse)
+	err := c.cc.Invoke(ctx, Echo_Network_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *echoClient) Credentials(ctx context.Context, in *CredentialsRequest, opts ...grpc.CallOption) (*CredentialsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CredentialsResponse)
+	err := c.cc.Invoke(ctx, Echo_Credentials_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *echoClient) Http2Settings(ctx context.Context, in *Http2SettingsRequest, opts ...grpc.CallOption) (*Http2SettingsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Http2SettingsResponse)
+	err := c.cc.Invoke(ctx, Echo_Http2Settings_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *echoClient) ServerTime(ctx context.Context, in *ServerTimeRequest, opts ...grpc.CallOption) (*ServerTimeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ServerTimeResponse)
+	err := c.cc.Invoke(ctx, Echo_ServerTime_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *echoClient) BurnResources(ctx context.Context, in *BurnResourcesRequest, opts ...grpc.CallOption) (*BurnResourcesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BurnResourcesResponse)
+	err := c.cc.Invoke(ctx, Echo_BurnResources_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// EchoServer is the server API for Echo service.
+// All implementations must embed UnimplementedEchoServer
+// for forward compatibility.
+//
+// Echo service with various RPC patterns, shared by echo-grpc and
+// echo-connectrpc. RPCs marked for one server answer UNIMPLEMENTED on the
+// other. RPCs whose response depends only on the request are
+// NO_SIDE_EFFECTS, so Connect clients may call them with HTTP GET.
+type EchoServer interface {
+	// Unary RPCs
+	Echo(context.Context, *EchoRequest) (*EchoResponse, error)
+	EchoWithDelay(context.Context, *EchoWithDelayRequest) (*EchoResponse, error)
+	EchoError(context.Context, *EchoErrorRequest) (*EchoResponse, error)
+	// Metadata/Headers RPCs
+	EchoRequestMetadata(context.Context, *EchoRequestMetadataRequest) (*EchoRequestMetadataResponse, error)
+	EchoWithTrailers(context.Context, *EchoWithTrailersRequest) (*EchoResponse, error)
+	// Payload Testing RPCs
+	EchoLargePayload(context.Context, *EchoLargePayloadRequest) (*EchoLargePayloadResponse, error)
+	// Deadline/Timeout RPCs
+	EchoDeadline(context.Context, *EchoDeadlineRequest) (*EchoDeadlineResponse, error)
+	// Error Scenarios RPCs
+	EchoErrorWithDetails(context.Context, *EchoErrorWithDetailsRequest) (*EchoResponse, error)
+	// Streaming RPCs
+	ServerStream(*ServerStreamRequest, grpc.ServerStreamingServer[EchoResponse]) error
+	ClientStream(grpc.ClientStreamingServer[EchoRequest, EchoResponse]) error
+	BidirectionalStream(grpc.BidiStreamingServer[EchoRequest, EchoResponse]) error
+	// Resumable server streaming RPC (echo-grpc)
+	ServerStreamResumable(*ServerStreamResumableRequest, grpc.ServerStreamingServer[ResumableStreamResponse]) error
+	// Heartbeat streaming RPC (echo-connectrpc)
+	BidirectionalStreamWithHeartbeat(grpc.BidiStreamingServer[HeartbeatStreamRequest, HeartbeatStreamResponse]) error
+	// Structured message RPC
+	EchoStructured(context.Context, *StructuredRequest) (*StructuredResponse, error)
+	// Serialization edge case RPCs (echo-grpc)
+	EchoMap(context.Context, *MapRequest) (*MapResponse, error)
+	EchoRepeated(context.Context, *RepeatedRequest) (*RepeatedResponse, error)
+	EchoScalars(context.Context, *ScalarsRequest) (*ScalarsResponse, error)
+	// Build information RPC
+	Version(context.Context, *VersionRequest) (*VersionResponse, error)
+	// Address family RPC
+	Network(context.Context, *NetworkRequest) (*NetworkResponse, error)
+	// Call credentials RPC (echo-grpc)
+	Credentials(context.Context, *CredentialsRequest) (*CredentialsResponse, error)
+	// HTTP/2 settings RPC (echo-grpc)
+	Http2Settings(context.Context, *Http2SettingsRequest) (*Http2SettingsResponse, error)
+	// Server clock RPC (echo-grpc)
+	ServerTime(context.Context, *ServerTimeRequest) (*ServerTimeResponse, error)
+	// Load test RPC (echo-grpc)
+	BurnResources(context.Context, *BurnResourcesRequest) (*BurnResourcesResponse, error)
+	mustEmbedUnimplementedEchoServer()
+}
+
+// UnimplementedEchoServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedEchoServer struct{}
+
+func (UnimplementedEchoServer) Echo(context.Context, *EchoRequest) (*EchoResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Echo not implemented")
+}
+func (UnimplementedEchoServer) EchoWithDelay(context.Context, *EchoWithDelayRequest) (*EchoResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method EchoWithDelay not implemented")
+}
+func (UnimplementedEchoServer) EchoError(context.Context, *EchoErrorRequest) (*EchoResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method EchoError not implemented")
+}
+func (UnimplementedEchoServer) EchoRequestMetadata(context.Context, *EchoRequestMetadataRequest) (*EchoRequestMetadataResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method EchoRequestMetadata not implemented")
+}
+func (UnimplementedEchoServer) EchoWithTrailers(context.Context, *EchoWithTrailersRequest) (*EchoResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method EchoWithTrailers not implemented")
+}
+func (UnimplementedEchoServer) EchoLargePayload(context.Context, *EchoLargePayloadRequest) (*EchoLargePayloadResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method EchoLargePayload not implemented")
+}
+func (UnimplementedEchoServer) EchoDeadline(context.Context, *EchoDeadlineRequest) (*EchoDeadlineResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method EchoDeadline not implemented")
+}
+func (UnimplementedEchoServer) EchoErrorWithDetails(context.Context, *EchoErrorWithDetailsRequest) (*EchoResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method EchoErrorWithDetails not implemented")
+}
+func (UnimplementedEchoServer) ServerStream(*ServerStreamRequest, grpc.ServerStreamingServer[EchoResponse]) error {
+	return status.Error(codes.Unimplemented, "method ServerStream not implemented")
+}
+func (UnimplementedEchoServer) ClientStream(grpc.ClientStreamingServer[EchoRequest, EchoResponse]) error {
+	return status.Error(codes.Unimplemented, "method ClientStream not implemented")
+}
+func (UnimplementedEchoServer) BidirectionalStream(grpc.BidiStreamingServer[EchoRequest, EchoResponse]) error {
+	return status.Error(codes.Unimplemented, "method BidirectionalStream not implemented")
+}
+func (UnimplementedEchoServer) ServerStreamResumable(*ServerStreamResumableRequest, grpc.ServerStreamingServer[ResumableStreamResponse]) error {
+	return status.Error(codes.Unimplemented, "method ServerStreamResumable not implemented")
+}
+func (UnimplementedEchoServer) BidirectionalStreamWithHeartbeat(grpc.BidiStreamingServer[HeartbeatStreamRequest, HeartbeatStreamResponse]) error {
+	return status.Error(codes.Unimplemented, "method BidirectionalStreamWithHeartbeat not implemented")
+}
+func (UnimplementedEchoServer) EchoStructured(context.Context, *StructuredRequest) (*StructuredResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method EchoStructured not implemented")
+}
+func (UnimplementedEchoServer) EchoMap(context.Context, *MapRequest) (*MapResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method EchoMap not implemented")
+}
+func (UnimplementedEchoServer) EchoRepeated(context.Context, *RepeatedRequest) (*RepeatedResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method EchoRepeated not implemented")
+}
+func (UnimplementedEchoServer) EchoScalars(context.Context, *ScalarsRequest) (*ScalarsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method EchoScalars not implemented")
+}
+func (UnimplementedEchoServer) Version(context.Context, *VersionRequest) (*VersionResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Version not implemented")
+}
+func (UnimplementedEchoServer) Network(context.Context, *NetworkRequest) (*NetworkResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Network not implemented")
+}
+func (UnimplementedEchoServer) Credentials(context.Context, *CredentialsRequest) (*CredentialsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Credentials not implemented")
+}
+func (UnimplementedEchoServer) Http2Settings(context.Context, *Http2SettingsRequest) (*Http2SettingsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Http2Settings not implemented")
+}
+func (UnimplementedEchoServer) ServerTime(context.Context, *ServerTimeRequest) (*ServerTimeResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ServerTime not implemented")
+}
+func (UnimplementedEchoServer) BurnResources(context.Context, *BurnResourcesRequest) (*BurnResourcesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method BurnResources not implemented")
+}
+func (UnimplementedEchoServer) mustEmbedUnimplementedEchoServer() {}
+func (UnimplementedEchoServer) testEmbeddedByValue()              {}
+
+// UnsafeEchoServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to EchoServer will
+// result in compilation errors.
+type UnsafeEchoServer interface {
+	mustEmbedUnimplementedEchoServer()
+}
+
+func RegisterEchoServer(s grpc.ServiceRegistrar, srv EchoServer) {
+	// If the following call panics, it indicates UnimplementedEchoServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Echo_ServiceDesc, srv)
+}
+
+func _Echo_Echo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EchoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EchoServer).Echo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Echo_Echo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EchoServer).Echo(ctx, req.(*EchoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Echo_EchoWithDelay_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EchoWithDelayRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EchoServer).EchoWithDelay(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Echo_EchoWithDelay_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EchoServer).EchoWithDelay(ctx, req.(*EchoWithDelayRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Echo_EchoError_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EchoErrorRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EchoServer).EchoError(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Echo_EchoError_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EchoServer).EchoError(ctx, req.(*EchoErrorRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Echo_EchoRequestMetadata_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EchoRequestMetadataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EchoServer).EchoRequestMetadata(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Echo_EchoRequestMetadata_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EchoServer).EchoRequestMetadata(ctx, req.(*EchoRequestMetadataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Echo_EchoWithTrailers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EchoWithTrailersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EchoServer).EchoWithTrailers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Echo_EchoWithTrailers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EchoServer).EchoWithTrailers(ctx, req.(*EchoWithTrailersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Echo_EchoLargePayload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EchoLargePayloadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EchoServer).EchoLargePayload(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Echo_EchoLargePayload_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EchoServer).EchoLargePayload(ctx, req.(*EchoLargePayloadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Echo_EchoDeadline_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EchoDeadlineRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EchoServer).EchoDeadline(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Echo_EchoDeadline_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EchoServer).EchoDeadline(ctx, req.(*EchoDeadlineRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Echo_EchoErrorWithDetails_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EchoErrorWithDetailsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EchoServer).EchoErrorWithDetails(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Echo_EchoErrorWithDetails_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EchoServer).EchoErrorWithDetails(ctx, req.(*EchoErrorWithDetailsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Echo_ServerStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ServerStreamRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(EchoServer).ServerStream(m, &grpc.GenericServerStream[ServerStreamRequest, EchoResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Echo_ServerStreamServer = grpc.ServerStreamingServer[EchoResponse]
+
+func _Echo_ClientStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(EchoServer).ClientStream(&grpc.GenericServerStream[EchoRequest, EchoResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Echo_ClientStreamServer = grpc.ClientStreamingServer[EchoRequest, EchoResponse]
+
+func _Echo_BidirectionalStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(EchoServer).BidirectionalStream(&grpc.GenericServerStream[EchoRequest, EchoResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Echo_BidirectionalStreamServer = grpc.BidiStreamingServer[EchoRequest, EchoResponse]
+
+func _Echo_ServerStreamResumable_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ServerStreamResumableRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(EchoServer).ServerStreamResumable(m, &grpc.GenericServerStream[ServerStreamResumableRequest, ResumableStreamResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Echo_ServerStreamResumableServer = grpc.ServerStreamingServer[ResumableStreamResponse]
+
+func _Echo_BidirectionalStreamWithHeartbeat_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(EchoServer).BidirectionalStreamWithHeartbeat(&grpc.GenericServerStream[HeartbeatStreamRequest, HeartbeatStreamResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Echo_BidirectionalStreamWithHeartbeatServer = grpc.BidiStreamingServer[HeartbeatStreamRequest, HeartbeatStreamResponse]
+
+func _Echo_EchoStructured_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StructuredRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EchoServer).EchoStructured(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Echo_EchoStructured_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EchoServer).EchoStructured(ctx, req.(*StructuredRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Echo_EchoMap_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MapRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EchoServer).EchoMap(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Echo_EchoMap_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EchoServer).EchoMap(ctx, req.(*MapRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Echo_EchoRepeated_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RepeatedRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EchoServer).EchoRepeated(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Echo_EchoRepeated_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EchoServer).EchoRepeated(ctx, req.(*RepeatedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Echo_EchoScalars_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ScalarsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EchoServer).EchoScalars(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Echo_EchoScalars_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EchoServer).EchoScalars(ctx, req.(*ScalarsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Echo_Version_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VersionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EchoServer).Version(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Echo_Version_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EchoServer).Version(ctx, req.(*VersionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Echo_Network_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NetworkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EchoServer).Network(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Echo_Network_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EchoServer).Network(ctx, req.(*NetworkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Echo_Credentials_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CredentialsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EchoServer).Credentials(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Echo_Credentials_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EchoServer).Credentials(ctx, req.(*CredentialsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Echo_Http2Settings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Http2SettingsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EchoServer).Http2Settings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Echo_Http2Settings_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EchoServer).Http2Settings(ctx, req.(*Http2SettingsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Echo_ServerTime_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ServerTimeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EchoServer).ServerTime(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Echo_ServerTime_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EchoServer).ServerTime(ctx, req.(*ServerTimeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Echo_BurnResources_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BurnResourcesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EchoServer).BurnResources(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Echo_BurnResources_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EchoServer).BurnResources(ctx, req.(*BurnResourcesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Echo_ServiceDesc is the grpc.ServiceDesc for Echo service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Echo_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "echo.v1.Echo",
+	HandlerType: (*EchoServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Echo",
+			Handler:    _Echo_Echo_Handler,
+		},
+		{
+			MethodName: "EchoWithDelay",
+			Handler:    _Echo_EchoWithDelay_Handler,
+		},
+		{
+			MethodName: "EchoError",
+			Handler:    _Echo_EchoError_Handler,
+		},
+		{
+			MethodName: "EchoRequestMetadata",
+			Handler:    _Echo_EchoRequestMetadata_Handler,
+		},
+		{
+			MethodName: "EchoWithTrailers",
+			Handler:    _Echo_EchoWithTrailers_Handler,
+		},
+		{
+			MethodName: "EchoLargePayload",
+			Handler:    _Echo_EchoLargePayload_Handler,
+		},
+		{
+			MethodName: "EchoDeadline",
+			Handler:    _Echo_EchoDeadline_Handler,
+		},
+		{
+			MethodName: "EchoErrorWithDetails",
+			Handler:    _Echo_EchoErrorWithDetails_Handler,
+		},
+		{
+			MethodName: "EchoStructured",
+			Handler:    _Echo_EchoStructured_Handler,
+		},
+		{
+			MethodName: "EchoMap",
+			Handler:    _Echo_EchoMap_Handler,
+		},
+		{
+			MethodName: "EchoRepeated",
+			Handler:    _Echo_EchoRepeated_Handler,
+		},
+		{
+			MethodName: "EchoScalars",
+			Handler:    _Echo_EchoScalars_Handler,
+		},
+		{
+			MethodName: "Version",
+			Handler:    _Echo_Version_Handler,
+		},
+		{
+			MethodName: "Network",
+			Handler:    _Echo_Network_Handler,
+		},
+		{
+			MethodName: "Credentials",
+			Handler:    _Echo_Credentials_Handler,
+		},
+		{
+			MethodName: "Http2Settings",
+			Handler:    _Echo_Http2Settings_Handler,
+		},
+		{
+			MethodName: "ServerTime",
+			Handler:    _Echo_ServerTime_Handler,
+		},
+		{
+			MethodName: "BurnResources",
+			Handler:    _Echo_BurnResources_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ServerStream",
+			Handler:       _Echo_ServerStream_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ClientStream",
+			Handler:       _Echo_ClientStream_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "BidirectionalStream",
+			Handler:       _Echo_BidirectionalStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "ServerStreamResumable",
+			Handler:       _Echo_ServerStreamResumable_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "BidirectionalStreamWithHeartbeat",
+			Handler:       _Echo_BidirectionalStreamWithHeartbeat_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "echo/v1/echo.proto",
+}
@@ -0,0 +1,276 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v6.32.1
+// source: echo/v1/echo_http2.proto
+
+package echov1
+
+import (
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Http2SettingsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Http2SettingsRequest) Reset() {
+	*x = Http2SettingsRequest{}
+	mi := &file_echo_v1_echo_http2_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Http2SettingsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Http2SettingsRequest) ProtoMessage() {}
+
+func (x *Http2SettingsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_echo_v1_echo_http2_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Http2SettingsRequest.ProtoReflect.Descriptor instead.
+func (*Http2SettingsRequest) Descriptor() ([]byte, []int) {
+	return file_echo_v1_echo_http2_proto_rawDescGZIP(), []int{0}
+}
+
+// HTTP/2 settings of one side of the connection, with their defaults when
+// not sent; 0 is unlimited
+type Http2Settings struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	HeaderTableSize      uint32                 `protobuf:"varint,1,opt,name=header_table_size,json=headerTableSize,proto3" json:"header_table_size,omitempty"`
+	MaxConcurrentStreams uint32                 `protobuf:"varint,2,opt,name=max_concurrent_streams,json=maxConcurrentStreams,proto3" json:"max_concurrent_streams,omitempty"`
+	InitialWindowSize    uint32                 `protobuf:"varint,3,opt,name=initial_window_size,json=initialWindowSize,proto3" json:"initial_window_size,omitempty"`
+	MaxFrameSize         uint32                 `protobuf:"varint,4,opt,name=max_frame_size,json=maxFrameSize,proto3" json:"max_frame_size,omitempty"`
+	MaxHeaderListSize    uint32                 `protobuf:"varint,5,opt,name=max_header_list_size,json=maxHeaderListSize,proto3" json:"max_header_list_size,omitempty"`
+	ConnectionWindowSize uint32                 `protobuf:"varint,6,opt,name=connection_window_size,json=connectionWindowSize,proto3" json:"connection_window_size,omitempty"` // after the WINDOW_UPDATE frames of the preface
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *Http2Settings) Reset() {
+	*x = Http2Settings{}
+	mi := &file_echo_v1_echo_http2_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Http2Settings) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Http2Settings) ProtoMessage() {}
+
+func (x *Http2Settings) ProtoReflect() protoreflect.Message {
+	mi := &file_echo_v1_echo_http2_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Http2Settings.ProtoReflect.Descriptor instead.
+func (*Http2Settings) Descriptor() ([]byte, []int) {
+	return file_echo_v1_echo_http2_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Http2Settings) GetHeaderTableSize() uint32 {
+	if x != nil {
+		return x.HeaderTableSize
+	}
+	return 0
+}
+
+func (x *Http2Settings) GetMaxConcurrentStreams() uint32 {
+	if x != nil {
+		return x.MaxConcurrentStreams
+	}
+	return 0
+}
+
+func (x *Http2Settings) GetInitialWindowSize() uint32 {
+	if x != nil {
+		return x.InitialWindowSize
+	}
+	return 0
+}
+
+func (x *Http2Settings) GetMaxFrameSize() uint32 {
+	if x != nil {
+		return x.MaxFrameSize
+	}
+	return 0
+}
+
+func (x *Http2Settings) GetMaxHeaderListSize() uint32 {
+	if x != nil {
+		return x.MaxHeaderListSize
+	}
+	return 0
+}
+
+func (x *Http2Settings) GetConnectionWindowSize() uint32 {
+	if x != nil {
+		return x.ConnectionWindowSize
+	}
+	return 0
+}
+
+// HTTP/2 settings of the connection of the call
+type Http2SettingsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Server        *Http2Settings         `protobuf:"bytes,1,opt,name=server,proto3" json:"server,omitempty"`                                     // advertised by the server (HTTP2_*)
+	Client        *Http2Settings         `protobuf:"bytes,2,opt,name=client,proto3" json:"client,omitempty"`                                     // sent by the client in its connection preface
+	DynamicWindow bool                   `protobuf:"varint,3,opt,name=dynamic_window,json=dynamicWindow,proto3" json:"dynamic_window,omitempty"` // the server sizes its windows with BDP estimation
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Http2SettingsResponse) Reset() {
+	*x = Http2SettingsResponse{}
+	mi := &file_echo_v1_echo_http2_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Http2SettingsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Http2SettingsResponse) ProtoMessage() {}
+
+func (x *Http2SettingsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_echo_v1_echo_http2_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Http2SettingsResponse.ProtoReflect.Descriptor instead.
+func (*Http2SettingsResponse) Descriptor() ([]byte, []int) {
+	return file_echo_v1_echo_http2_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Http2SettingsResponse) GetServer() *Http2Settings {
+	if x != nil {
+		return x.Server
+	}
+	return nil
+}
+
+func (x *Http2SettingsResponse) GetClient() *Http2Settings {
+	if x != nil {
+		return x.Client
+	}
+	return nil
+}
+
+func (x *Http2SettingsResponse) GetDynamicWindow() bool {
+	if x != nil {
+		return x.DynamicWindow
+	}
+	return false
+}
+
+var File_echo_v1_echo_http2_proto protoreflect.FileDescriptor
+
+const file_echo_v1_echo_http2_proto_rawDesc = "" +
+	"\n" +
+	"\x18echo/v1/echo_http2.proto\x12\aecho.v1\"\x16\n" +
+	"\x14Http2SettingsRequest\"\xae\x02\n" +
+	"\rHttp2Settings\x12*\n" +
+	"\x11header_table_size\x18\x01 \x01(\rR\x0fheaderTableSize\x124\n" +
+	"\x16max_concurrent_streams\x18\x02 \x01(\rR\x14maxConcurrentStreams\x12.\n" +
+	"\x13initial_window_size\x18\x03 \x01(\rR\x11initialWindowSize\x12$\n" +
+	"\x0emax_frame_size\x18\x04 \x01(\rR\fmaxFrameSize\x12/\n" +
+	"\x14max_header_list_size\x18\x05 \x01(\rR\x11maxHeaderListSize\x124\n" +
+	"\x16connection_window_size\x18\x06 \x01(\rR\x14connectionWindowSize\"\x9e\x01\n" +
+	"\x15Http2SettingsResponse\x12.\n" +
+	"\x06server\x18\x01 \x01(\v2\x16.echo.v1.Http2SettingsR\x06server\x12.\n" +
+	"\x06client\x18\x02 \x01(\v2\x16.echo.v1.Http2SettingsR\x06client\x12%\n" +
+	"\x0edynamic_window\x18\x03 \x01(\bR\rdynamicWindowB<Z:github.com/probitas-test/echo-servers/proto/echo/v1;echov1b\x06proto3"
+
+var (
+	file_echo_v1_echo_http2_proto_rawDescOnce sync.Once
+	file_echo_v1_echo_http2_proto_rawDescData []byte
+)
+
+func file_echo_v1_echo_http2_proto_rawDescGZIP() []byte {
+	file_echo_v1_echo_http2_proto_rawDescOnce.Do(func() {
+		file_echo_v1_echo_http2_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_echo_v1_echo_http2_proto_rawDesc), len(file_echo_v1_echo_http2_proto_rawDesc)))
+	})
+	return file_echo_v1_echo_http2_proto_rawDescData
+}
+
+var file_echo_v1_echo_http2_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_echo_v1_echo_http2_proto_goTypes = []any{
+	(*Http2SettingsRequest)(nil),  // 0: echo.v1.Http2SettingsRequest
+	(*Http2Settings)(nil),         // 1: echo.v1.Http2Settings
+	(*Http2SettingsResponse)(nil), // 2: echo.v1.Http2SettingsResponse
+}
+var file_echo_v1_echo_http2_proto_depIdxs = []int32{
+	1, // 0: echo.v1.Http2SettingsResponse.server:type_name -> echo.v1.Http2Settings
+	1, // 1: echo.v1.Http2SettingsResponse.client:type_name -> echo.v1.Http2Settings
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_echo_v1_echo_http2_proto_init() }
+func file_echo_v1_echo_http2_proto_init() {
+	if File_echo_v1_echo_http2_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_echo_v1_echo_http2_proto_rawDesc), len(file_echo_v1_echo_http2_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_echo_v1_echo_http2_proto_goTypes,
+		DependencyIndexes: file_echo_v1_echo_http2_proto_depIdxs,
+		MessageInfos:      file_echo_v1_echo_http2_proto_msgTypes,
+	}.Build()
+	File_echo_v1_echo_http2_proto = out.File
+	file_echo_v1_echo_http2_proto_goTypes = nil
+	file_echo_v1_echo_http2_proto_depIdxs = nil
+}
@@ -2,15 +2,16 @@
 // versions:
 // 	protoc-gen-go v1.36.11
 // 	protoc        v6.32.1
-// source: echo_metadata.proto
+// source: echo/v1/echo_metadata.proto
 
-package proto
+package echov1
 
 import (
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
 
+	_ "buf.build/gen/go/bufbuild/protovalidate/protocolbuffers/go/buf/validate"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 )
@@ -31,7 +32,7 @@ type MetadataValues struct {
 
 func (x *MetadataValues) Reset() {
 	*x = MetadataValues{}
-	mi := &file_echo_metadata_proto_msgTypes[0]
+	mi := &file_echo_v1_echo_metadata_proto_msgTypes[0]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -43,7 +44,7 @@ func (x *MetadataValues) String() string {
 func (*MetadataValues) ProtoMessage() {}
 
 func (x *MetadataValues) ProtoReflect() protoreflect.Message {
-	mi := &file_echo_metadata_proto_msgTypes[0]
+	mi := &file_echo_v1_echo_metadata_proto_msgTypes[0]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -56,7 +57,7 @@ func (x *MetadataValues) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MetadataValues.ProtoReflect.Descriptor instead.
 func (*MetadataValues) Descriptor() ([]byte, []int) {
-	return file_echo_metadata_proto_rawDescGZIP(), []int{0}
+	return file_echo_v1_echo_metadata_proto_rawDescGZIP(), []int{0}
 }
 
 func (x *MetadataValues) GetValues() []string {
@@ -76,7 +77,7 @@ type EchoRequestMetadataRequest struct {
 
 func (x *EchoRequestMetadataRequest) Reset() {
 	*x = EchoRequestMetadataRequest{}
-	mi := &file_echo_metadata_proto_msgTypes[1]
+	mi := &file_echo_v1_echo_metadata_proto_msgTypes[1]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -88,7 +89,7 @@ func (x *EchoRequestMetadataRequest) String() string {
 func (*EchoRequestMetadataRequest) ProtoMessage() {}
 
 func (x *EchoRequestMetadataRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_echo_metadata_proto_msgTypes[1]
+	mi := &file_echo_v1_echo_metadata_proto_msgTypes[1]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -101,7 +102,7 @@ func (x *EchoRequestMetadataRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use EchoRequestMetadataRequest.ProtoReflect.Descriptor instead.
 func (*EchoRequestMetadataRequest) Descriptor() ([]byte, []int) {
-	return file_echo_metadata_proto_rawDescGZIP(), []int{1}
+	return file_echo_v1_echo_metadata_proto_rawDescGZIP(), []int{1}
 }
 
 func (x *EchoRequestMetadataRequest) GetKeys() []string {
@@ -121,7 +122,7 @@ type EchoRequestMetadataResponse struct {
 
 func (x *EchoRequestMetadataResponse) Reset() {
 	*x = EchoRequestMetadataResponse{}
-	mi := &file_echo_metadata_proto_msgTypes[2]
+	mi := &file_echo_v1_echo_metadata_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -133,7 +134,7 @@ func (x *EchoRequestMetadataResponse) String() string {
 func (*EchoRequestMetadataResponse) ProtoMessage() {}
 
 func (x *EchoRequestMetadataResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_echo_metadata_proto_msgTypes[2]
+	mi := &file_echo_v1_echo_metadata_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -146,7 +147,7 @@ func (x *EchoRequestMetadataResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use EchoRequestMetadataResponse.ProtoReflect.Descriptor instead.
 func (*EchoRequestMetadataResponse) Descriptor() ([]byte, []int) {
-	return file_echo_metadata_proto_rawDescGZIP(), []int{2}
+	return file_echo_v1_echo_metadata_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *EchoRequestMetadataResponse) GetMetadata() map[string]*MetadataValues {
@@ -158,16 +159,17 @@ func (x *EchoRequestMetadataResponse) GetMetadata() map[string]*MetadataValues {
 
 // EchoWithTrailers - Return response with trailing metadata
 type EchoWithTrailersRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
-	Trailers      map[string]string      `protobuf:"bytes,2,rep,name=trailers,proto3" json:"trailers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Trailers to send with response
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Message string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	// Trailers to send with response; keys are lowercase metadata keys
+	Trailers      map[string]string `protobuf:"bytes,2,rep,name=trailers,proto3" json:"trailers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *EchoWithTrailersRequest) Reset() {
 	*x = EchoWithTrailersRequest{}
-	mi := &file_echo_metadata_proto_msgTypes[3]
+	mi := &file_echo_v1_echo_metadata_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -179,7 +181,7 @@ func (x *EchoWithTrailersRequest) String() string {
 func (*EchoWithTrailersRequest) ProtoMessage() {}
 
 func (x *EchoWithTrailersRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_echo_metadata_proto_msgTypes[3]
+	mi := &file_echo_v1_echo_metadata_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -192,7 +194,7 @@ func (x *EchoWithTrailersRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use EchoWithTrailersRequest.ProtoReflect.Descriptor instead.
 func (*EchoWithTrailersRequest) Descriptor() ([]byte, []int) {
-	return file_echo_metadata_proto_rawDescGZIP(), []int{3}
+	return file_echo_v1_echo_metadata_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *EchoWithTrailersRequest) GetMessage() string {
@@ -209,41 +211,41 @@ func (x *EchoWithTrailersRequest) GetTrailers() map[string]string {
 	return nil
 }
 
-var File_echo_metadata_proto protoreflect.FileDescriptor
+var File_echo_v1_echo_metadata_proto protoreflect.FileDescriptor
 
-const file_echo_metadata_proto_rawDesc = "" +
+const file_echo_v1_echo_metadata_proto_rawDesc = "" +
 	"\n" +
-	"\x13echo_metadata.proto\x12\aecho.v1\"(\n" +
+	"\x1becho/v1/echo_metadata.proto\x12\aecho.v1\x1a\x1bbuf/validate/validate.proto\"(\n" +
 	"\x0eMetadataValues\x12\x16\n" +
-	"\x06values\x18\x01 \x03(\tR\x06values\"0\n" +
-	"\x1aEchoRequestMetadataRequest\x12\x12\n" +
-	"\x04keys\x18\x01 \x03(\tR\x04keys\"\xc3\x01\n" +
+	"\x06values\x18\x01 \x03(\tR\x06values\">\n" +
+	"\x1aEchoRequestMetadataRequest\x12 \n" +
+	"\x04keys\x18\x01 \x03(\tB\f\xbaH\t\x92\x01\x06\"\x04r\x02\x10\x01R\x04keys\"\xc3\x01\n" +
 	"\x1bEchoRequestMetadataResponse\x12N\n" +
 	"\bmetadata\x18\x01 \x03(\v22.echo.v1.EchoRequestMetadataResponse.MetadataEntryR\bmetadata\x1aT\n" +
 	"\rMetadataEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12-\n" +
-	"\x05value\x18\x02 \x01(\v2\x17.echo.v1.MetadataValuesR\x05value:\x028\x01\"\xbc\x01\n" +
+	"\x05value\x18\x02 \x01(\v2\x17.echo.v1.MetadataValuesR\x05value:\x028\x01\"\xd8\x01\n" +
 	"\x17EchoWithTrailersRequest\x12\x18\n" +
-	"\amessage\x18\x01 \x01(\tR\amessage\x12J\n" +
-	"\btrailers\x18\x02 \x03(\v2..echo.v1.EchoWithTrailersRequest.TrailersEntryR\btrailers\x1a;\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\x12f\n" +
+	"\btrailers\x18\x02 \x03(\v2..echo.v1.EchoWithTrailersRequest.TrailersEntryB\x1a\xbaH\x17\x9a\x01\x14\"\x12r\x102\x0e^[0-9a-z_.-]+$R\btrailers\x1a;\n" +
 	"\rTrailersEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B7Z5github.com/probitas-test/echo-servers/echo-grpc/protob\x06proto3"
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B<Z:github.com/probitas-test/echo-servers/proto/echo/v1;echov1b\x06proto3"
 
 var (
-	file_echo_metadata_proto_rawDescOnce sync.Once
-	file_echo_metadata_proto_rawDescData []byte
+	file_echo_v1_echo_metadata_proto_rawDescOnce sync.Once
+	file_echo_v1_echo_metadata_proto_rawDescData []byte
 )
 
-func file_echo_metadata_proto_rawDescGZIP() []byte {
-	file_echo_metadata_proto_rawDescOnce.Do(func() {
-		file_echo_metadata_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_echo_metadata_proto_rawDesc), len(file_echo_metadata_proto_rawDesc)))
+func file_echo_v1_echo_metadata_proto_rawDescGZIP() []byte {
+	file_echo_v1_echo_metadata_proto_rawDescOnce.Do(func() {
+		file_echo_v1_echo_metadata_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_echo_v1_echo_metadata_proto_rawDesc), len(file_echo_v1_echo_metadata_proto_rawDesc)))
 	})
-	return file_echo_metadata_proto_rawDescData
+	return file_echo_v1_echo_metadata_proto_rawDescData
 }
 
-var file_echo_metadata_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
-var file_echo_metadata_proto_goTypes = []any{
+var file_echo_v1_echo_metadata_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_echo_v1_echo_metadata_proto_goTypes = []any{
 	(*MetadataValues)(nil),              // 0: echo.v1.MetadataValues
 	(*EchoRequestMetadataRequest)(nil),  // 1: echo.v1.EchoRequestMetadataRequest
 	(*EchoRequestMetadataResponse)(nil), // 2: echo.v1.EchoRequestMetadataResponse
@@ -251,7 +253,7 @@ var file_echo_metadata_proto_goTypes = []any{
 	nil,                                 // 4: echo.v1.EchoRequestMetadataResponse.MetadataEntry
 	nil,                                 // 5: echo.v1.EchoWithTrailersRequest.TrailersEntry
 }
-var file_echo_metadata_proto_depIdxs = []int32{
+var file_echo_v1_echo_metadata_proto_depIdxs = []int32{
 	4, // 0: echo.v1.EchoRequestMetadataResponse.metadata:type_name -> echo.v1.EchoRequestMetadataResponse.MetadataEntry
 	5, // 1: echo.v1.EchoWithTrailersRequest.trailers:type_name -> echo.v1.EchoWithTrailersRequest.TrailersEntry
 	0, // 2: echo.v1.EchoRequestMetadataResponse.MetadataEntry.value:type_name -> echo.v1.MetadataValues
@@ -262,26 +264,26 @@ var file_echo_metadata_proto_depIdxs = []int32{
 	0, // [0:3] is the sub-list for field type_name
 }
 
-func init() { file_echo_metadata_proto_init() }
-func file_echo_metadata_proto_init() {
-	if File_echo_metadata_proto != nil {
+func init() { file_echo_v1_echo_metadata_proto_init() }
+func file_echo_v1_echo_metadata_proto_init() {
+	if File_echo_v1_echo_metadata_proto != nil {
 		return
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
-			RawDescriptor: unsafe.Slice(unsafe.StringData(file_echo_metadata_proto_rawDesc), len(file_echo_metadata_proto_rawDesc)),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_echo_v1_echo_metadata_proto_rawDesc), len(file_echo_v1_echo_metadata_proto_rawDesc)),
 			NumEnums:      0,
 			NumMessages:   6,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
-		GoTypes:           file_echo_metadata_proto_goTypes,
-		DependencyIndexes: file_echo_metadata_proto_depIdxs,
-		MessageInfos:      file_echo_metadata_proto_msgTypes,
+		GoTypes:           file_echo_v1_echo_metadata_proto_goTypes,
+		DependencyIndexes: file_echo_v1_echo_metadata_proto_depIdxs,
+		MessageInfos:      file_echo_v1_echo_metadata_proto_msgTypes,
 	}.Build()
-	File_echo_metadata_proto = out.File
-	file_echo_metadata_proto_goTypes = nil
-	file_echo_metadata_proto_depIdxs = nil
+	File_echo_v1_echo_metadata_proto = out.File
+	file_echo_v1_echo_metadata_proto_goTypes = nil
+	file_echo_v1_echo_metadata_proto_depIdxs = nil
 }
@@ -0,0 +1,189 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v6.32.1
+// source: echo/v1/echo_network.proto
+
+package echov1
+
+import (
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type NetworkRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NetworkRequest) Reset() {
+	*x = NetworkRequest{}
+	mi := &file_echo_v1_echo_network_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NetworkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NetworkRequest) ProtoMessage() {}
+
+func (x *NetworkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_echo_v1_echo_network_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NetworkRequest.ProtoReflect.Descriptor instead.
+func (*NetworkRequest) Descriptor() ([]byte, []int) {
+	return file_echo_v1_echo_network_proto_rawDescGZIP(), []int{0}
+}
+
+// Address family of the client connection, as served at /network
+type NetworkResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Family        string                 `protobuf:"bytes,1,opt,name=family,proto3" json:"family,omitempty"`                                    // ipv4 or ipv6
+	ClientAddress string                 `protobuf:"bytes,2,opt,name=client_address,json=clientAddress,proto3" json:"client_address,omitempty"` // address of the client, as seen by the server
+	ServerAddress string                 `protobuf:"bytes,3,opt,name=server_address,json=serverAddress,proto3" json:"server_address,omitempty"` // local address the client connected to
+	Listen        string                 `protobuf:"bytes,4,opt,name=listen,proto3" json:"listen,omitempty"`                                    // family the server listens on (IP_FAMILY)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NetworkResponse) Reset() {
+	*x = NetworkResponse{}
+	mi := &file_echo_v1_echo_network_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NetworkResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NetworkResponse) ProtoMessage() {}
+
+func (x *NetworkResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_echo_v1_echo_network_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NetworkResponse.ProtoReflect.Descriptor instead.
+func (*NetworkResponse) Descriptor() ([]byte, []int) {
+	return file_echo_v1_echo_network_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *NetworkResponse) GetFamily() string {
+	if x != nil {
+		return x.Family
+	}
+	return ""
+}
+
+func (x *NetworkResponse) GetClientAddress() string {
+	if x != nil {
+		return x.ClientAddress
+	}
+	return ""
+}
+
+func (x *NetworkResponse) GetServerAddress() string {
+	if x != nil {
+		return x.ServerAddress
+	}
+	return ""
+}
+
+func (x *NetworkResponse) GetListen() string {
+	if x != nil {
+		return x.Listen
+	}
+	return ""
+}
+
+var File_echo_v1_echo_network_proto protoreflect.FileDescriptor
+
+const file_echo_v1_echo_network_proto_rawDesc = "" +
+	"\n" +
+	"\x1aecho/v1/echo_network.proto\x12\aecho.v1\"\x10\n" +
+	"\x0eNetworkRequest\"\x8f\x01\n" +
+	"\x0fNetworkResponse\x12\x16\n" +
+	"\x06family\x18\x01 \x01(\tR\x06family\x12%\n" +
+	"\x0eclient_address\x18\x02 \x01(\tR\rclientAddress\x12%\n" +
+	"\x0eserver_address\x18\x03 \x01(\tR\rserverAddress\x12\x16\n" +
+	"\x06listen\x18\x04 \x01(\tR\x06listenB<Z:github.com/probitas-test/echo-servers/proto/echo/v1;echov1b\x06proto3"
+
+var (
+	file_echo_v1_echo_network_proto_rawDescOnce sync.Once
+	file_echo_v1_echo_network_proto_rawDescData []byte
+)
+
+func file_echo_v1_echo_network_proto_rawDescGZIP() []byte {
+	file_echo_v1_echo_network_proto_rawDescOnce.Do(func() {
+		file_echo_v1_echo_network_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_echo_v1_echo_network_proto_rawDesc), len(file_echo_v1_echo_network_proto_rawDesc)))
+	})
+	return file_echo_v1_echo_network_proto_rawDescData
+}
+
+var file_echo_v1_echo_network_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_echo_v1_echo_network_proto_goTypes = []any{
+	(*NetworkRequest)(nil),  // 0: echo.v1.NetworkRequest
+	(*NetworkResponse)(nil), // 1: echo.v1.NetworkResponse
+}
+var file_echo_v1_echo_network_proto_depIdxs = []int32{
+	0, // [0:0] is the sub-list for method output_type
+	0, // [0:0] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_echo_v1_echo_network_proto_init() }
+func file_echo_v1_echo_network_proto_init() {
+	if File_echo_v1_echo_network_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_echo_v1_echo_network_proto_rawDesc), len(file_echo_v1_echo_network_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_echo_v1_echo_network_proto_goTypes,
+		DependencyIndexes: file_echo_v1_echo_network_proto_depIdxs,
+		MessageInfos:      file_echo_v1_echo_network_proto_msgTypes,
+	}.Build()
+	File_echo_v1_echo_network_proto = out.File
+	file_echo_v1_echo_network_proto_goTypes = nil
+	file_echo_v1_echo_network_proto_depIdxs = nil
+}
@@ -0,0 +1,206 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v6.32.1
+// source: echo/v1/echo_orca.proto
+
+package echov1
+
+import (
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+
+	_ "buf.build/gen/go/bufbuild/protovalidate/protocolbuffers/go/buf/validate"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// ORCA per-call load report, sent in the endpoint-load-metrics-bin trailer
+// as an xds.data.orca.v3.OrcaLoadReport. Zero values are not reported.
+type LoadReport struct {
+	state                  protoimpl.MessageState `protogen:"open.v1"`
+	CpuUtilization         float64                `protobuf:"fixed64,1,opt,name=cpu_utilization,json=cpuUtilization,proto3" json:"cpu_utilization,omitempty"`                                                                  // 0 or more
+	MemUtilization         float64                `protobuf:"fixed64,2,opt,name=mem_utilization,json=memUtilization,proto3" json:"mem_utilization,omitempty"`                                                                  // 0-1
+	ApplicationUtilization float64                `protobuf:"fixed64,3,opt,name=application_utilization,json=applicationUtilization,proto3" json:"application_utilization,omitempty"`                                          // 0 or more
+	RpsFractional          float64                `protobuf:"fixed64,4,opt,name=rps_fractional,json=rpsFractional,proto3" json:"rps_fractional,omitempty"`                                                                     // queries per second
+	Eps                    float64                `protobuf:"fixed64,5,opt,name=eps,proto3" json:"eps,omitempty"`                                                                                                              // errors per second
+	RequestCost            map[string]float64     `protobuf:"bytes,6,rep,name=request_cost,json=requestCost,proto3" json:"request_cost,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"fixed64,2,opt,name=value"` // per-request costs
+	// Named utilizations, 0-1
+	Utilization   map[string]float64 `protobuf:"bytes,7,rep,name=utilization,proto3" json:"utilization,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"fixed64,2,opt,name=value"`
+	NamedMetrics  map[string]float64 `protobuf:"bytes,8,rep,name=named_metrics,json=namedMetrics,proto3" json:"named_metrics,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"fixed64,2,opt,name=value"` // other named metrics
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LoadReport) Reset() {
+	*x = LoadReport{}
+	mi := &file_echo_v1_echo_orca_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LoadReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoadReport) ProtoMessage() {}
+
+func (x *LoadReport) ProtoReflect() protoreflect.Message {
+	mi := &file_echo_v1_echo_orca_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LoadReport.ProtoReflect.Descriptor instead.
+func (*LoadReport) Descriptor() ([]byte, []int) {
+	return file_echo_v1_echo_orca_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *LoadReport) GetCpuUtilization() float64 {
+	if x != nil {
+		return x.CpuUtilization
+	}
+	return 0
+}
+
+func (x *LoadReport) GetMemUtilization() float64 {
+	if x != nil {
+		return x.MemUtilization
+	}
+	return 0
+}
+
+func (x *LoadReport) GetApplicationUtilization() float64 {
+	if x != nil {
+		return x.ApplicationUtilization
+	}
+	return 0
+}
+
+func (x *LoadReport) GetRpsFractional() float64 {
+	if x != nil {
+		return x.RpsFractional
+	}
+	return 0
+}
+
+func (x *LoadReport) GetEps() float64 {
+	if x != nil {
+		return x.Eps
+	}
+	return 0
+}
+
+func (x *LoadReport) GetRequestCost() map[string]float64 {
+	if x != nil {
+		return x.RequestCost
+	}
+	return nil
+}
+
+func (x *LoadReport) GetUtilization() map[string]float64 {
+	if x != nil {
+		return x.Utilization
+	}
+	return nil
+}
+
+func (x *LoadReport) GetNamedMetrics() map[string]float64 {
+	if x != nil {
+		return x.NamedMetrics
+	}
+	return nil
+}
+
+var File_echo_v1_echo_orca_proto protoreflect.FileDescriptor
+
+const file_echo_v1_echo_orca_proto_rawDesc = "" +
+	"\n" +
+	"\x17echo/v1/echo_orca.proto\x12\aecho.v1\x1a\x1bbuf/validate/validate.proto\"\xe5\x05\n" +
+	"\n" +
+	"LoadReport\x127\n" +
+	"\x0fcpu_utilization\x18\x01 \x01(\x01B\x0e\xbaH\v\x12\t)\x00\x00\x00\x00\x00\x00\x00\x00R\x0ecpuUtilization\x12@\n" +
+	"\x0fmem_utilization\x18\x02 \x01(\x01B\x17\xbaH\x14\x12\x12\x19\x00\x00\x00\x00\x00\x00\xf0?)\x00\x00\x00\x00\x00\x00\x00\x00R\x0ememUtilization\x12G\n" +
+	"\x17application_utilization\x18\x03 \x01(\x01B\x0e\xbaH\v\x12\t)\x00\x00\x00\x00\x00\x00\x00\x00R\x16applicationUtilization\x125\n" +
+	"\x0erps_fractional\x18\x04 \x01(\x01B\x0e\xbaH\v\x12\t)\x00\x00\x00\x00\x00\x00\x00\x00R\rrpsFractional\x12 \n" +
+	"\x03eps\x18\x05 \x01(\x01B\x0e\xbaH\v\x12\t)\x00\x00\x00\x00\x00\x00\x00\x00R\x03eps\x12G\n" +
+	"\frequest_cost\x18\x06 \x03(\v2$.echo.v1.LoadReport.RequestCostEntryR\vrequestCost\x12d\n" +
+	"\vutilization\x18\a \x03(\v2$.echo.v1.LoadReport.UtilizationEntryB\x1c\xbaH\x19\x9a\x01\x16*\x14\x12\x12\x19\x00\x00\x00\x00\x00\x00\xf0?)\x00\x00\x00\x00\x00\x00\x00\x00R\vutilization\x12J\n" +
+	"\rnamed_metrics\x18\b \x03(\v2%.echo.v1.LoadReport.NamedMetricsEntryR\fnamedMetrics\x1a>\n" +
+	"\x10RequestCostEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x01R\x05value:\x028\x01\x1a>\n" +
+	"\x10UtilizationEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x01R\x05value:\x028\x01\x1a?\n" +
+	"\x11NamedMetricsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x01R\x05value:\x028\x01B<Z:github.com/probitas-test/echo-servers/proto/echo/v1;echov1b\x06proto3"
+
+var (
+	file_echo_v1_echo_orca_proto_rawDescOnce sync.Once
+	file_echo_v1_echo_orca_proto_rawDescData []byte
+)
+
+func file_echo_v1_echo_orca_proto_rawDescGZIP() []byte {
+	file_echo_v1_echo_orca_proto_rawDescOnce.Do(func() {
+		file_echo_v1_echo_orca_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_echo_v1_echo_orca_proto_rawDesc), len(file_echo_v1_echo_orca_proto_rawDesc)))
+	})
+	return file_echo_v1_echo_orca_proto_rawDescData
+}
+
+var file_echo_v1_echo_orca_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_echo_v1_echo_orca_proto_goTypes = []any{
+	(*LoadReport)(nil), // 0: echo.v1.LoadReport
+	nil,                // 1: echo.v1.LoadReport.RequestCostEntry
+	nil,                // 2: echo.v1.LoadReport.UtilizationEntry
+	nil,                // 3: echo.v1.LoadReport.NamedMetricsEntry
+}
+var file_echo_v1_echo_orca_proto_depIdxs = []int32{
+	1, // 0: echo.v1.LoadReport.request_cost:type_name -> echo.v1.LoadReport.RequestCostEntry
+	2, // 1: echo.v1.LoadReport.utilization:type_name -> echo.v1.LoadReport.UtilizationEntry
+	3, // 2: echo.v1.LoadReport.named_metrics:type_name -> echo.v1.LoadReport.NamedMetricsEntry
+	3, // [3:3] is the sub-list for method output_type
+	3, // [3:3] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_echo_v1_echo_orca_proto_init() }
+func file_echo_v1_echo_orca_proto_init() {
+	if File_echo_v1_echo_orca_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_echo_v1_echo_orca_proto_rawDesc), len(file_echo_v1_echo_orca_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   4,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_echo_v1_echo_orca_proto_goTypes,
+		DependencyIndexes: file_echo_v1_echo_orca_proto_depIdxs,
+		MessageInfos:      file_echo_v1_echo_orca_proto_msgTypes,
+	}.Build()
+	File_echo_v1_echo_orca_proto = out.File
+	file_echo_v1_echo_orca_proto_goTypes = nil
+	file_echo_v1_echo_orca_proto_depIdxs = nil
+}
@@ -2,15 +2,16 @@
 // versions:
 // 	protoc-gen-go v1.36.11
 // 	protoc        v6.32.1
-// source: echo_payload.proto
+// source: echo/v1/echo_payload.proto
 
-package proto
+package echov1
 
 import (
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
 
+	_ "buf.build/gen/go/bufbuild/protovalidate/protocolbuffers/go/buf/validate"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 )
@@ -32,7 +33,7 @@ type EchoLargePayloadRequest struct {
 
 func (x *EchoLargePayloadRequest) Reset() {
 	*x = EchoLargePayloadRequest{}
-	mi := &file_echo_payload_proto_msgTypes[0]
+	mi := &file_echo_v1_echo_payload_proto_msgTypes[0]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -44,7 +45,7 @@ func (x *EchoLargePayloadRequest) String() string {
 func (*EchoLargePayloadRequest) ProtoMessage() {}
 
 func (x *EchoLargePayloadRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_echo_payload_proto_msgTypes[0]
+	mi := &file_echo_v1_echo_payload_proto_msgTypes[0]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -57,7 +58,7 @@ func (x *EchoLargePayloadRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use EchoLargePayloadRequest.ProtoReflect.Descriptor instead.
 func (*EchoLargePayloadRequest) Descriptor() ([]byte, []int) {
-	return file_echo_payload_proto_rawDescGZIP(), []int{0}
+	return file_echo_v1_echo_payload_proto_rawDescGZIP(), []int{0}
 }
 
 func (x *EchoLargePayloadRequest) GetSizeBytes() int32 {
@@ -84,7 +85,7 @@ type EchoLargePayloadResponse struct {
 
 func (x *EchoLargePayloadResponse) Reset() {
 	*x = EchoLargePayloadResponse{}
-	mi := &file_echo_payload_proto_msgTypes[1]
+	mi := &file_echo_v1_echo_payload_proto_msgTypes[1]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -96,7 +97,7 @@ func (x *EchoLargePayloadResponse) String() string {
 func (*EchoLargePayloadResponse) ProtoMessage() {}
 
 func (x *EchoLargePayloadResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_echo_payload_proto_msgTypes[1]
+	mi := &file_echo_v1_echo_payload_proto_msgTypes[1]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -109,7 +110,7 @@ func (x *EchoLargePayloadResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use EchoLargePayloadResponse.ProtoReflect.Descriptor instead.
 func (*EchoLargePayloadResponse) Descriptor() ([]byte, []int) {
-	return file_echo_payload_proto_rawDescGZIP(), []int{1}
+	return file_echo_v1_echo_payload_proto_rawDescGZIP(), []int{1}
 }
 
 func (x *EchoLargePayloadResponse) GetPayload() []byte {
@@ -126,38 +127,39 @@ func (x *EchoLargePayloadResponse) GetActualSize() int32 {
 	return 0
 }
 
-var File_echo_payload_proto protoreflect.FileDescriptor
+var File_echo_v1_echo_payload_proto protoreflect.FileDescriptor
 
-const file_echo_payload_proto_rawDesc = "" +
+const file_echo_v1_echo_payload_proto_rawDesc = "" +
 	"\n" +
-	"\x12echo_payload.proto\x12\aecho.v1\"R\n" +
-	"\x17EchoLargePayloadRequest\x12\x1d\n" +
+	"\x1aecho/v1/echo_payload.proto\x12\aecho.v1\x1a\x1bbuf/validate/validate.proto\"j\n" +
+	"\x17EchoLargePayloadRequest\x12+\n" +
 	"\n" +
-	"size_bytes\x18\x01 \x01(\x05R\tsizeBytes\x12\x18\n" +
-	"\apattern\x18\x02 \x01(\tR\apattern\"U\n" +
+	"size_bytes\x18\x01 \x01(\x05B\f\xbaH\t\x1a\a\x18\x80\x80\x80\x05(\x00R\tsizeBytes\x12\"\n" +
+	"\apattern\x18\x02 \x01(\tB\b\xbaH\x05r\x03(\x80\bR\apattern\"\xb9\x01\n" +
 	"\x18EchoLargePayloadResponse\x12\x18\n" +
 	"\apayload\x18\x01 \x01(\fR\apayload\x12\x1f\n" +
 	"\vactual_size\x18\x02 \x01(\x05R\n" +
-	"actualSizeB=Z;github.com/probitas-test/echo-servers/echo-connectrpc/protob\x06proto3"
+	"actualSize:b\xbaH_\x1a]\n" +
+	"\vactual_size\x12&actual_size must be the payload length\x1a&this.actual_size == size(this.payload)B<Z:github.com/probitas-test/echo-servers/proto/echo/v1;echov1b\x06proto3"
 
 var (
-	file_echo_payload_proto_rawDescOnce sync.Once
-	file_echo_payload_proto_rawDescData []byte
+	file_echo_v1_echo_payload_proto_rawDescOnce sync.Once
+	file_echo_v1_echo_payload_proto_rawDescData []byte
 )
 
-func file_echo_payload_proto_rawDescGZIP() []byte {
-	file_echo_payload_proto_rawDescOnce.Do(func() {
-		file_echo_payload_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_echo_payload_proto_rawDesc), len(file_echo_payload_proto_rawDesc)))
+func file_echo_v1_echo_payload_proto_rawDescGZIP() []byte {
+	file_echo_v1_echo_payload_proto_rawDescOnce.Do(func() {
+		file_echo_v1_echo_payload_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_echo_v1_echo_payload_proto_rawDesc), len(file_echo_v1_echo_payload_proto_rawDesc)))
 	})
-	return file_echo_payload_proto_rawDescData
+	return file_echo_v1_echo_payload_proto_rawDescData
 }
 
-var file_echo_payload_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
-var file_echo_payload_proto_goTypes = []any{
+var file_echo_v1_echo_payload_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_echo_v1_echo_payload_proto_goTypes = []any{
 	(*EchoLargePayloadRequest)(nil),  // 0: echo.v1.EchoLargePayloadRequest
 	(*EchoLargePayloadResponse)(nil), // 1: echo.v1.EchoLargePayloadResponse
 }
-var file_echo_payload_proto_depIdxs = []int32{
+var file_echo_v1_echo_payload_proto_depIdxs = []int32{
 	0, // [0:0] is the sub-list for method output_type
 	0, // [0:0] is the sub-list for method input_type
 	0, // [0:0] is the sub-list for extension type_name
@@ -165,26 +167,26 @@ var file_echo_payload_proto_depIdxs = []int32{
 	0, // [0:0] is the sub-list for field type_name
 }
 
-func init() { file_echo_payload_proto_init() }
-func file_echo_payload_proto_init() {
-	if File_echo_payload_proto != nil {
+func init() { file_echo_v1_echo_payload_proto_init() }
+func file_echo_v1_echo_payload_proto_init() {
+	if File_echo_v1_echo_payload_proto != nil {
 		return
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
-			RawDescriptor: unsafe.Slice(unsafe.StringData(file_echo_payload_proto_rawDesc), len(file_echo_payload_proto_rawDesc)),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_echo_v1_echo_payload_proto_rawDesc), len(file_echo_v1_echo_payload_proto_rawDesc)),
 			NumEnums:      0,
 			NumMessages:   2,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
-		GoTypes:           file_echo_payload_proto_goTypes,
-		DependencyIndexes: file_echo_payload_proto_depIdxs,
-		MessageInfos:      file_echo_payload_proto_msgTypes,
+		GoTypes:           file_echo_v1_echo_payload_proto_goTypes,
+		DependencyIndexes: file_echo_v1_echo_payload_proto_depIdxs,
+		MessageInfos:      file_echo_v1_echo_payload_proto_msgTypes,
 	}.Build()
-	File_echo_payload_proto = out.File
-	file_echo_payload_proto_goTypes = nil
-	file_echo_payload_proto_depIdxs = nil
+	File_echo_v1_echo_payload_proto = out.File
+	file_echo_v1_echo_payload_proto_goTypes = nil
+	file_echo_v1_echo_payload_proto_depIdxs = nil
 }
@@ -0,0 +1,990 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v6.32.1
+// source: echo/v1/echo_serialization.proto
+
+package echov1
+
+import (
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// EchoMap - Echo maps with unusual keys: empty or non-ASCII strings,
+// negative and extreme integers, booleans
+type MapRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	StringKeys    map[string]string      `protobuf:"bytes,1,rep,name=string_keys,json=stringKeys,proto3" json:"string_keys,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Int32Keys     map[int32]string       `protobuf:"bytes,2,rep,name=int32_keys,json=int32Keys,proto3" json:"int32_keys,omitempty" protobuf_key:"varint,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Int64Keys     map[int64]string       `protobuf:"bytes,3,rep,name=int64_keys,json=int64Keys,proto3" json:"int64_keys,omitempty" protobuf_key:"varint,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Uint64Keys    map[uint64]string      `protobuf:"bytes,4,rep,name=uint64_keys,json=uint64Keys,proto3" json:"uint64_keys,omitempty" protobuf_key:"varint,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Sint32Keys    map[int32]string       `protobuf:"bytes,5,rep,name=sint32_keys,json=sint32Keys,proto3" json:"sint32_keys,omitempty" protobuf_key:"zigzag32,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	BoolKeys      map[bool]string        `protobuf:"bytes,6,rep,name=bool_keys,json=boolKeys,proto3" json:"bool_keys,omitempty" protobuf_key:"varint,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	BytesValues   map[string][]byte      `protobuf:"bytes,7,rep,name=bytes_values,json=bytesValues,proto3" json:"bytes_values,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	MessageValues map[string]*MapValue   `protobuf:"bytes,8,rep,name=message_values,json=messageValues,proto3" json:"message_values,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MapRequest) Reset() {
+	*x = MapRequest{}
+	mi := &file_echo_v1_echo_serialization_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MapRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MapRequest) ProtoMessage() {}
+
+func (x *MapRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_echo_v1_echo_serialization_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MapRequest.ProtoReflect.Descriptor instead.
+func (*MapRequest) Descriptor() ([]byte, []int) {
+	return file_echo_v1_echo_serialization_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *MapRequest) GetStringKeys() map[string]string {
+	if x != nil {
+		return x.StringKeys
+	}
+	return nil
+}
+
+func (x *MapRequest) GetInt32Keys() map[int32]string {
+	if x != nil {
+		return x.Int32Keys
+	}
+	return nil
+}
+
+func (x *MapRequest) GetInt64Keys() map[int64]string {
+	if x != nil {
+		return x.Int64Keys
+	}
+	return nil
+}
+
+func (x *MapRequest) GetUint64Keys() map[uint64]string {
+	if x != nil {
+		return x.Uint64Keys
+	}
+	return nil
+}
+
+func (x *MapRequest) GetSint32Keys() map[int32]string {
+	if x != nil {
+		return x.Sint32Keys
+	}
+	return nil
+}
+
+func (x *MapRequest) GetBoolKeys() map[bool]string {
+	if x != nil {
+		return x.BoolKeys
+	}
+	return nil
+}
+
+func (x *MapRequest) GetBytesValues() map[string][]byte {
+	if x != nil {
+		return x.BytesValues
+	}
+	return nil
+}
+
+func (x *MapRequest) GetMessageValues() map[string]*MapValue {
+	if x != nil {
+		return x.MessageValues
+	}
+	return nil
+}
+
+type MapValue struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Text          string                 `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	Number        int32                  `protobuf:"varint,2,opt,name=number,proto3" json:"number,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MapValue) Reset() {
+	*x = MapValue{}
+	mi := &file_echo_v1_echo_serialization_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MapValue) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MapValue) ProtoMessage() {}
+
+func (x *MapValue) ProtoReflect() protoreflect.Message {
+	mi := &file_echo_v1_echo_serialization_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MapValue.ProtoReflect.Descriptor instead.
+func (*MapValue) Descriptor() ([]byte, []int) {
+	return file_echo_v1_echo_serialization_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *MapValue) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *MapValue) GetNumber() int32 {
+	if x != nil {
+		return x.Number
+	}
+	return 0
+}
+
+// Key of a map entry received
+type MapKey struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Field         string                 `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`                                    // map field
+	Key           string                 `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`                                        // integers in decimal, booleans as true or false
+	DefaultValue  bool                   `protobuf:"varint,3,opt,name=default_value,json=defaultValue,proto3" json:"default_value,omitempty"` // the value is empty, zero or a message without fields
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MapKey) Reset() {
+	*x = MapKey{}
+	mi := &file_echo_v1_echo_serialization_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MapKey) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MapKey) ProtoMessage() {}
+
+func (x *MapKey) ProtoReflect() protoreflect.Message {
+	mi := &file_echo_v1_echo_serialization_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MapKey.ProtoReflect.Descriptor instead.
+func (*MapKey) Descriptor() ([]byte, []int) {
+	return file_echo_v1_echo_serialization_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *MapKey) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *MapKey) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *MapKey) GetDefaultValue() bool {
+	if x != nil {
+		return x.DefaultValue
+	}
+	return false
+}
+
+type MapResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Request       *MapRequest            `protobuf:"bytes,1,opt,name=request,proto3" json:"request,omitempty"`                                          // the request, unknown fields included
+	Keys          []*MapKey              `protobuf:"bytes,2,rep,name=keys,proto3" json:"keys,omitempty"`                                                // by field number, then in key order
+	UnknownFields []int32                `protobuf:"varint,3,rep,packed,name=unknown_fields,json=unknownFields,proto3" json:"unknown_fields,omitempty"` // numbers of the unknown fields of the request
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MapResponse) Reset() {
+	*x = MapResponse{}
+	mi := &file_echo_v1_echo_serialization_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MapResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MapResponse) ProtoMessage() {}
+
+func (x *MapResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_echo_v1_echo_serialization_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MapResponse.ProtoReflect.Descriptor instead.
+func (*MapResponse) Descriptor() ([]byte, []int) {
+	return file_echo_v1_echo_serialization_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *MapResponse) GetRequest() *MapRequest {
+	if x != nil {
+		return x.Request
+	}
+	return nil
+}
+
+func (x *MapResponse) GetKeys() []*MapKey {
+	if x != nil {
+		return x.Keys
+	}
+	return nil
+}
+
+func (x *MapResponse) GetUnknownFields() []int32 {
+	if x != nil {
+		return x.UnknownFields
+	}
+	return nil
+}
+
+// EchoRepeated - Echo repeated fields, packed or expanded, empty or
+// holding default values
+type RepeatedRequest struct {
+	state         protoimpl.MessageState       `protogen:"open.v1"`
+	Packed        []int32                      `protobuf:"varint,1,rep,packed,name=packed,proto3" json:"packed,omitempty"`    // packed, the proto3 default
+	Expanded      []int32                      `protobuf:"varint,2,rep,name=expanded,proto3" json:"expanded,omitempty"`       // one tag per element
+	Doubles       []float64                    `protobuf:"fixed64,3,rep,packed,name=doubles,proto3" json:"doubles,omitempty"` // NaN and infinities included
+	Strings       []string                     `protobuf:"bytes,4,rep,name=strings,proto3" json:"strings,omitempty"`
+	Blobs         [][]byte                     `protobuf:"bytes,5,rep,name=blobs,proto3" json:"blobs,omitempty"`
+	Enums         []StructuredRequest_Priority `protobuf:"varint,6,rep,packed,name=enums,proto3,enum=echo.v1.StructuredRequest_Priority" json:"enums,omitempty"` // unknown values kept as numbers
+	Messages      []*MapValue                  `protobuf:"bytes,7,rep,name=messages,proto3" json:"messages,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RepeatedRequest) Reset() {
+	*x = RepeatedRequest{}
+	mi := &file_echo_v1_echo_serialization_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RepeatedRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RepeatedRequest) ProtoMessage() {}
+
+func (x *RepeatedRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_echo_v1_echo_serialization_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RepeatedRequest.ProtoReflect.Descriptor instead.
+func (*RepeatedRequest) Descriptor() ([]byte, []int) {
+	return file_echo_v1_echo_serialization_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *RepeatedRequest) GetPacked() []int32 {
+	if x != nil {
+		return x.Packed
+	}
+	return nil
+}
+
+func (x *RepeatedRequest) GetExpanded() []int32 {
+	if x != nil {
+		return x.Expanded
+	}
+	return nil
+}
+
+func (x *RepeatedRequest) GetDoubles() []float64 {
+	if x != nil {
+		return x.Doubles
+	}
+	return nil
+}
+
+func (x *RepeatedRequest) GetStrings() []string {
+	if x != nil {
+		return x.Strings
+	}
+	return nil
+}
+
+func (x *RepeatedRequest) GetBlobs() [][]byte {
+	if x != nil {
+		return x.Blobs
+	}
+	return nil
+}
+
+func (x *RepeatedRequest) GetEnums() []StructuredRequest_Priority {
+	if x != nil {
+		return x.Enums
+	}
+	return nil
+}
+
+func (x *RepeatedRequest) GetMessages() []*MapValue {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+// Repeated field of the request
+type RepeatedField struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Field         string                 `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	Count         int32                  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`       // 0 for a field empty, null or missing
+	Defaults      int32                  `protobuf:"varint,3,opt,name=defaults,proto3" json:"defaults,omitempty"` // elements with the default value
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RepeatedField) Reset() {
+	*x = RepeatedField{}
+	mi := &file_echo_v1_echo_serialization_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RepeatedField) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RepeatedField) ProtoMessage() {}
+
+func (x *RepeatedField) ProtoReflect() protoreflect.Message {
+	mi := &file_echo_v1_echo_serialization_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RepeatedField.ProtoReflect.Descriptor instead.
+func (*RepeatedField) Descriptor() ([]byte, []int) {
+	return file_echo_v1_echo_serialization_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *RepeatedField) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *RepeatedField) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *RepeatedField) GetDefaults() int32 {
+	if x != nil {
+		return x.Defaults
+	}
+	return 0
+}
+
+type RepeatedResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Request       *RepeatedRequest       `protobuf:"bytes,1,opt,name=request,proto3" json:"request,omitempty"`                                          // the request, unknown fields included
+	Fields        []*RepeatedField       `protobuf:"bytes,2,rep,name=fields,proto3" json:"fields,omitempty"`                                            // every repeated field, by field number
+	UnknownFields []int32                `protobuf:"varint,3,rep,packed,name=unknown_fields,json=unknownFields,proto3" json:"unknown_fields,omitempty"` // numbers of the unknown fields of the request
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RepeatedResponse) Reset() {
+	*x = RepeatedResponse{}
+	mi := &file_echo_v1_echo_serialization_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RepeatedResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RepeatedResponse) ProtoMessage() {}
+
+func (x *RepeatedResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_echo_v1_echo_serialization_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RepeatedResponse.ProtoReflect.Descriptor instead.
+func (*RepeatedResponse) Descriptor() ([]byte, []int) {
+	return file_echo_v1_echo_serialization_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *RepeatedResponse) GetRequest() *RepeatedRequest {
+	if x != nil {
+		return x.Request
+	}
+	return nil
+}
+
+func (x *RepeatedResponse) GetFields() []*RepeatedField {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+func (x *RepeatedResponse) GetUnknownFields() []int32 {
+	if x != nil {
+		return x.UnknownFields
+	}
+	return nil
+}
+
+// EchoScalars - Echo scalars with implicit presence next to proto3
+// optional ones, reporting which were received
+type ScalarsRequest struct {
+	state           protoimpl.MessageState      `protogen:"open.v1"`
+	Int32Value      int32                       `protobuf:"varint,1,opt,name=int32_value,json=int32Value,proto3" json:"int32_value,omitempty"`
+	Int64Value      int64                       `protobuf:"varint,2,opt,name=int64_value,json=int64Value,proto3" json:"int64_value,omitempty"`
+	Uint64Value     uint64                      `protobuf:"varint,3,opt,name=uint64_value,json=uint64Value,proto3" json:"uint64_value,omitempty"`
+	Sint32Value     int32                       `protobuf:"zigzag32,4,opt,name=sint32_value,json=sint32Value,proto3" json:"sint32_value,omitempty"`
+	Fixed64Value    uint64                      `protobuf:"fixed64,5,opt,name=fixed64_value,json=fixed64Value,proto3" json:"fixed64_value,omitempty"`
+	FloatValue      float32                     `protobuf:"fixed32,6,opt,name=float_value,json=floatValue,proto3" json:"float_value,omitempty"`
+	DoubleValue     float64                     `protobuf:"fixed64,7,opt,name=double_value,json=doubleValue,proto3" json:"double_value,omitempty"`
+	BoolValue       bool                        `protobuf:"varint,8,opt,name=bool_value,json=boolValue,proto3" json:"bool_value,omitempty"`
+	StringValue     string                      `protobuf:"bytes,9,opt,name=string_value,json=stringValue,proto3" json:"string_value,omitempty"`
+	BytesValue      []byte                      `protobuf:"bytes,10,opt,name=bytes_value,json=bytesValue,proto3" json:"bytes_value,omitempty"`
+	EnumValue       StructuredRequest_Priority  `protobuf:"varint,11,opt,name=enum_value,json=enumValue,proto3,enum=echo.v1.StructuredRequest_Priority" json:"enum_value,omitempty"`
+	OptionalInt32   *int32                      `protobuf:"varint,21,opt,name=optional_int32,json=optionalInt32,proto3,oneof" json:"optional_int32,omitempty"`
+	OptionalInt64   *int64                      `protobuf:"varint,22,opt,name=optional_int64,json=optionalInt64,proto3,oneof" json:"optional_int64,omitempty"`
+	OptionalUint64  *uint64                     `protobuf:"varint,23,opt,name=optional_uint64,json=optionalUint64,proto3,oneof" json:"optional_uint64,omitempty"`
+	OptionalSint32  *int32                      `protobuf:"zigzag32,24,opt,name=optional_sint32,json=optionalSint32,proto3,oneof" json:"optional_sint32,omitempty"`
+	OptionalFixed64 *uint64                     `protobuf:"fixed64,25,opt,name=optional_fixed64,json=optionalFixed64,proto3,oneof" json:"optional_fixed64,omitempty"`
+	OptionalFloat   *float32                    `protobuf:"fixed32,26,opt,name=optional_float,json=optionalFloat,proto3,oneof" json:"optional_float,omitempty"`
+	OptionalDouble  *float64                    `protobuf:"fixed64,27,opt,name=optional_double,json=optionalDouble,proto3,oneof" json:"optional_double,omitempty"`
+	OptionalBool    *bool                       `protobuf:"varint,28,opt,name=optional_bool,json=optionalBool,proto3,oneof" json:"optional_bool,omitempty"`
+	OptionalString  *string                     `protobuf:"bytes,29,opt,name=optional_string,json=optionalString,proto3,oneof" json:"optional_string,omitempty"`
+	OptionalBytes   []byte                      `protobuf:"bytes,30,opt,name=optional_bytes,json=optionalBytes,proto3,oneof" json:"optional_bytes,omitempty"`
+	OptionalEnum    *StructuredRequest_Priority `protobuf:"varint,31,opt,name=optional_enum,json=optionalEnum,proto3,enum=echo.v1.StructuredRequest_Priority,oneof" json:"optional_enum,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ScalarsRequest) Reset() {
+	*x = ScalarsRequest{}
+	mi := &file_echo_v1_echo_serialization_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScalarsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScalarsRequest) ProtoMessage() {}
+
+func (x *ScalarsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_echo_v1_echo_serialization_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScalarsRequest.ProtoReflect.Descriptor instead.
+func (*ScalarsRequest) Descriptor() ([]byte, []int) {
+	return file_echo_v1_echo_serialization_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ScalarsRequest) GetInt32Value() int32 {
+	if x != nil {
+		return x.Int32Value
+	}
+	return 0
+}
+
+func (x *ScalarsRequest) GetInt64Value() int64 {
+	if x != nil {
+		return x.Int64Value
+	}
+	return 0
+}
+
+func (x *ScalarsRequest) GetUint64Value() uint64 {
+	if x != nil {
+		return x.Uint64Value
+	}
+	return 0
+}
+
+func (x *ScalarsRequest) GetSint32Value() int32 {
+	if x != nil {
+		return x.Sint32Value
+	}
+	return 0
+}
+
+func (x *ScalarsRequest) GetFixed64Value() uint64 {
+	if x != nil {
+		return x.Fixed64Value
+	}
+	return 0
+}
+
+func (x *ScalarsRequest) GetFloatValue() float32 {
+	if x != nil {
+		return x.FloatValue
+	}
+	return 0
+}
+
+func (x *ScalarsRequest) GetDoubleValue() float64 {
+	if x != nil {
+		return x.DoubleValue
+	}
+	return 0
+}
+
+func (x *ScalarsRequest) GetBoolValue() bool {
+	if x != nil {
+		return x.BoolValue
+	}
+	return false
+}
+
+func (x *ScalarsRequest) GetStringValue() string {
+	if x != nil {
+		return x.StringValue
+	}
+	return ""
+}
+
+func (x *ScalarsRequest) GetBytesValue() []byte {
+	if x != nil {
+		return x.BytesValue
+	}
+	return nil
+}
+
+func (x *ScalarsRequest) GetEnumValue() StructuredRequest_Priority {
+	if x != nil {
+		return x.EnumValue
+	}
+	return StructuredRequest_PRIORITY_UNSPECIFIED
+}
+
+func (x *ScalarsRequest) GetOptionalInt32() int32 {
+	if x != nil && x.OptionalInt32 != nil {
+		return *x.OptionalInt32
+	}
+	return 0
+}
+
+func (x *ScalarsRequest) GetOptionalInt64() int64 {
+	if x != nil && x.OptionalInt64 != nil {
+		return *x.OptionalInt64
+	}
+	return 0
+}
+
+func (x *ScalarsRequest) GetOptionalUint64() uint64 {
+	if x != nil && x.OptionalUint64 != nil {
+		return *x.OptionalUint64
+	}
+	return 0
+}
+
+func (x *ScalarsRequest) GetOptionalSint32() int32 {
+	if x != nil && x.OptionalSint32 != nil {
+		return *x.OptionalSint32
+	}
+	return 0
+}
+
+func (x *ScalarsRequest) GetOptionalFixed64() uint64 {
+	if x != nil && x.OptionalFixed64 != nil {
+		return *x.OptionalFixed64
+	}
+	return 0
+}
+
+func (x *ScalarsRequest) GetOptionalFloat() float32 {
+	if x != nil && x.OptionalFloat != nil {
+		return *x.OptionalFloat
+	}
+	return 0
+}
+
+func (x *ScalarsRequest) GetOptionalDouble() float64 {
+	if x != nil && x.OptionalDouble != nil {
+		return *x.OptionalDouble
+	}
+	return 0
+}
+
+func (x *ScalarsRequest) GetOptionalBool() bool {
+	if x != nil && x.OptionalBool != nil {
+		return *x.OptionalBool
+	}
+	return false
+}
+
+func (x *ScalarsRequest) GetOptionalString() string {
+	if x != nil && x.OptionalString != nil {
+		return *x.OptionalString
+	}
+	return ""
+}
+
+func (x *ScalarsRequest) GetOptionalBytes() []byte {
+	if x != nil {
+		return x.OptionalBytes
+	}
+	return nil
+}
+
+func (x *ScalarsRequest) GetOptionalEnum() StructuredRequest_Priority {
+	if x != nil && x.OptionalEnum != nil {
+		return *x.OptionalEnum
+	}
+	return StructuredRequest_PRIORITY_UNSPECIFIED
+}
+
+type ScalarsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Request       *ScalarsRequest        `protobuf:"bytes,1,opt,name=request,proto3" json:"request,omitempty"`                                          // the request, unknown fields included
+	Present       []string               `protobuf:"bytes,2,rep,name=present,proto3" json:"present,omitempty"`                                          // fields set: implicit ones when not default, optional ones whenever sent
+	UnknownFields []int32                `protobuf:"varint,3,rep,packed,name=unknown_fields,json=unknownFields,proto3" json:"unknown_fields,omitempty"` // numbers of the unknown fields of the request
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScalarsResponse) Reset() {
+	*x = ScalarsResponse{}
+	mi := &file_echo_v1_echo_serialization_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScalarsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScalarsResponse) ProtoMessage() {}
+
+func (x *ScalarsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_echo_v1_echo_serialization_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScalarsResponse.ProtoReflect.Descriptor instead.
+func (*ScalarsResponse) Descriptor() ([]byte, []int) {
+	return file_echo_v1_echo_serialization_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ScalarsResponse) GetRequest() *ScalarsRequest {
+	if x != nil {
+		return x.Request
+	}
+	return nil
+}
+
+func (x *ScalarsResponse) GetPresent() []string {
+	if x != nil {
+		return x.Present
+	}
+	return nil
+}
+
+func (x *ScalarsResponse) GetUnknownFields() []int32 {
+	if x != nil {
+		return x.UnknownFields
+	}
+	return nil
+}
+
+var File_echo_v1_echo_serialization_proto protoreflect.FileDescriptor
+
+const file_echo_v1_echo_serialization_proto_rawDesc = "" +
+	"\n" +
+	" echo/v1/echo_serialization.proto\x12\aecho.v1\x1a\x1decho/v1/echo_structured.proto\"\xc7\b\n" +
+	"\n" +
+	"MapRequest\x12D\n" +
+	"\vstring_keys\x18\x01 \x03(\v2#.echo.v1.MapRequest.StringKeysEntryR\n" +
+	"stringKeys\x12A\n" +
+	"\n" +
+	"int32_keys\x18\x02 \x03(\v2\".echo.v1.MapRequest.Int32KeysEntryR\tint32Keys\x12A\n" +
+	"\n" +
+	"int64_keys\x18\x03 \x03(\v2\".echo.v1.MapRequest.Int64KeysEntryR\tint64Keys\x12D\n" +
+	"\vuint64_keys\x18\x04 \x03(\v2#.echo.v1.MapRequest.Uint64KeysEntryR\n" +
+	"uint64Keys\x12D\n" +
+	"\vsint32_keys\x18\x05 \x03(\v2#.echo.v1.MapRequest.Sint32KeysEntryR\n" +
+	"sint32Keys\x12>\n" +
+	"\tbool_keys\x18\x06 \x03(\v2!.echo.v1.MapRequest.BoolKeysEntryR\bboolKeys\x12G\n" +
+	"\fbytes_values\x18\a \x03(\v2$.echo.v1.MapRequest.BytesValuesEntryR\vbytesValues\x12M\n" +
+	"\x0emessage_values\x18\b \x03(\v2&.echo.v1.MapRequest.MessageValuesEntryR\rmessageValues\x1a=\n" +
+	"\x0fStringKeysEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a<\n" +
+	"\x0eInt32KeysEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\x05R\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a<\n" +
+	"\x0eInt64KeysEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\x03R\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a=\n" +
+	"\x0fUint64KeysEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\x04R\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a=\n" +
+	"\x0fSint32KeysEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\x11R\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a;\n" +
+	"\rBoolKeysEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\bR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a>\n" +
+	"\x10BytesValuesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\fR\x05value:\x028\x01\x1aS\n" +
+	"\x12MessageValuesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12'\n" +
+	"\x05value\x18\x02 \x01(\v2\x11.echo.v1.MapValueR\x05value:\x028\x01\"6\n" +
+	"\bMapValue\x12\x12\n" +
+	"\x04text\x18\x01 \x01(\tR\x04text\x12\x16\n" +
+	"\x06number\x18\x02 \x01(\x05R\x06number\"U\n" +
+	"\x06MapKey\x12\x14\n" +
+	"\x05field\x18\x01 \x01(\tR\x05field\x12\x10\n" +
+	"\x03key\x18\x02 \x01(\tR\x03key\x12#\n" +
+	"\rdefault_value\x18\x03 \x01(\bR\fdefaultValue\"\x88\x01\n" +
+	"\vMapResponse\x12-\n" +
+	"\arequest\x18\x01 \x01(\v2\x13.echo.v1.MapRequestR\arequest\x12#\n" +
+	"\x04keys\x18\x02 \x03(\v2\x0f.echo.v1.MapKeyR\x04keys\x12%\n" +
+	"\x0eunknown_fields\x18\x03 \x03(\x05R\runknownFields\"\xfd\x01\n" +
+	"\x0fRepeatedRequest\x12\x16\n" +
+	"\x06packed\x18\x01 \x03(\x05R\x06packed\x12\x1e\n" +
+	"\bexpanded\x18\x02 \x03(\x05B\x02\x10\x00R\bexpanded\x12\x18\n" +
+	"\adoubles\x18\x03 \x03(\x01R\adoubles\x12\x18\n" +
+	"\astrings\x18\x04 \x03(\tR\astrings\x12\x14\n" +
+	"\x05blobs\x18\x05 \x03(\fR\x05blobs\x129\n" +
+	"\x05enums\x18\x06 \x03(\x0e2#.echo.v1.StructuredRequest.PriorityR\x05enums\x12-\n" +
+	"\bmessages\x18\a \x03(\v2\x11.echo.v1.MapValueR\bmessages\"W\n" +
+	"\rRepeatedField\x12\x14\n" +
+	"\x05field\x18\x01 \x01(\tR\x05field\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x05R\x05count\x12\x1a\n" +
+	"\bdefaults\x18\x03 \x01(\x05R\bdefaults\"\x9d\x01\n" +
+	"\x10RepeatedResponse\x122\n" +
+	"\arequest\x18\x01 \x01(\v2\x18.echo.v1.RepeatedRequestR\arequest\x12.\n" +
+	"\x06fields\x18\x02 \x03(\v2\x16.echo.v1.RepeatedFieldR\x06fields\x12%\n" +
+	"\x0eunknown_fields\x18\x03 \x03(\x05R\runknownFields\"\x8e\t\n" +
+	"\x0eScalarsRequest\x12\x1f\n" +
+	"\vint32_value\x18\x01 \x01(\x05R\n" +
+	"int32Value\x12\x1f\n" +
+	"\vint64_value\x18\x02 \x01(\x03R\n" +
+	"int64Value\x12!\n" +
+	"\fuint64_value\x18\x03 \x01(\x04R\vuint64Value\x12!\n" +
+	"\fsint32_value\x18\x04 \x01(\x11R\vsint32Value\x12#\n" +
+	"\rfixed64_value\x18\x05 \x01(\x06R\ffixed64Value\x12\x1f\n" +
+	"\vfloat_value\x18\x06 \x01(\x02R\n" +
+	"floatValue\x12!\n" +
+	"\fdouble_value\x18\a \x01(\x01R\vdoubleValue\x12\x1d\n" +
+	"\n" +
+	"bool_value\x18\b \x01(\bR\tboolValue\x12!\n" +
+	"\fstring_value\x18\t \x01(\tR\vstringValue\x12\x1f\n" +
+	"\vbytes_value\x18\n" +
+	" \x01(\fR\n" +
+	"bytesValue\x12B\n" +
+	"\n" +
+	"enum_value\x18\v \x01(\x0e2#.echo.v1.StructuredRequest.PriorityR\tenumValue\x12*\n" +
+	"\x0eoptional_int32\x18\x15 \x01(\x05H\x00R\roptionalInt32\x88\x01\x01\x12*\n" +
+	"\x0eoptional_int64\x18\x16 \x01(\x03H\x01R\roptionalInt64\x88\x01\x01\x12,\n" +
+	"\x0foptional_uint64\x18\x17 \x01(\x04H\x02R\x0eoptionalUint64\x88\x01\x01\x12,\n" +
+	"\x0foptional_sint32\x18\x18 \x01(\x11H\x03R\x0eoptionalSint32\x88\x01\x01\x12.\n" +
+	"\x10optional_fixed64\x18\x19 \x01(\x06H\x04R\x0foptionalFixed64\x88\x01\x01\x12*\n" +
+	"\x0eoptional_float\x18\x1a \x01(\x02H\x05R\roptionalFloat\x88\x01\x01\x12,\n" +
+	"\x0foptional_double\x18\x1b \x01(\x01H\x06R\x0eoptionalDouble\x88\x01\x01\x12(\n" +
+	"\roptional_bool\x18\x1c \x01(\bH\aR\foptionalBool\x88\x01\x01\x12,\n" +
+	"\x0foptional_string\x18\x1d \x01(\tH\bR\x0eoptionalString\x88\x01\x01\x12*\n" +
+	"\x0eoptional_bytes\x18\x1e \x01(\fH\tR\roptionalBytes\x88\x01\x01\x12M\n" +
+	"\roptional_enum\x18\x1f \x01(\x0e2#.echo.v1.StructuredRequest.PriorityH\n" +
+	"R\foptionalEnum\x88\x01\x01B\x11\n" +
+	"\x0f_optional_int32B\x11\n" +
+	"\x0f_optional_int64B\x12\n" +
+	"\x10_optional_uint64B\x12\n" +
+	"\x10_optional_sint32B\x13\n" +
+	"\x11_optional_fixed64B\x11\n" +
+	"\x0f_optional_floatB\x12\n" +
+	"\x10_optional_doubleB\x10\n" +
+	"\x0e_optional_boolB\x12\n" +
+	"\x10_optional_stringB\x11\n" +
+	"\x0f_optional_bytesB\x10\n" +
+	"\x0e_optional_enum\"\x85\x01\n" +
+	"\x0fScalarsResponse\x121\n" +
+	"\arequest\x18\x01 \x01(\v2\x17.echo.v1.ScalarsRequestR\arequest\x12\x18\n" +
+	"\apresent\x18\x02 \x03(\tR\apresent\x12%\n" +
+	"\x0eunknown_fields\x18\x03 \x03(\x05R\runknownFieldsB<Z:github.com/probitas-test/echo-servers/proto/echo/v1;echov1b\x06proto3"
+
+var (
+	file_echo_v1_echo_serialization_proto_rawDescOnce sync.Once
+	file_echo_v1_echo_serialization_proto_rawDescData []byte
+)
+
+func file_echo_v1_echo_serialization_proto_rawDescGZIP() []byte {
+	file_echo_v1_echo_serialization_proto_rawDescOnce.Do(func() {
+		file_echo_v1_echo_serialization_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_echo_v1_echo_serialization_proto_rawDesc), len(file_echo_v1_echo_serialization_proto_rawDesc)))
+	})
+	return file_echo_v1_echo_serialization_proto_rawDescData
+}
+
+var file_echo_v1_echo_serialization_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
+var file_echo_v1_echo_serialization_proto_goTypes = []any{
+	(*MapRequest)(nil),              // 0: echo.v1.MapRequest
+	(*MapValue)(nil),                // 1: echo.v1.MapValue
+	(*MapKey)(nil),                  // 2: echo.v1.MapKey
+	(*MapResponse)(nil),             // 3: echo.v1.MapResponse
+	(*RepeatedRequest)(nil),         // 4: echo.v1.RepeatedRequest
+	(*RepeatedField)(nil),           // 5: echo.v1.RepeatedField
+	(*RepeatedResponse)(nil),        // 6: echo.v1.RepeatedResponse
+	(*ScalarsRequest)(nil),          // 7: echo.v1.ScalarsRequest
+	(*ScalarsResponse)(nil),         // 8: echo.v1.ScalarsResponse
+	nil,                             // 9: echo.v1.MapRequest.StringKeysEntry
+	nil,                             // 10: echo.v1.MapRequest.Int32KeysEntry
+	nil,                             // 11: echo.v1.MapRequest.Int64KeysEntry
+	nil,                             // 12: echo.v1.MapRequest.Uint64KeysEntry
+	nil,                             // 13: echo.v1.MapRequest.Sint32KeysEntry
+	nil,                             // 14: echo.v1.MapRequest.BoolKeysEntry
+	nil,                             // 15: echo.v1.MapRequest.BytesValuesEntry
+	nil,                             // 16: echo.v1.MapRequest.MessageValuesEntry
+	(StructuredRequest_Priority)(0), // 17: echo.v1.StructuredRequest.Priority
+}
+var file_echo_v1_echo_serialization_proto_depIdxs = []int32{
+	9,  // 0: echo.v1.MapRequest.string_keys:type_name -> echo.v1.MapRequest.StringKeysEntry
+	10, // 1: echo.v1.MapRequest.int32_keys:type_name -> echo.v1.MapRequest.Int32KeysEntry
+	11, // 2: echo.v1.MapRequest.int64_keys:type_name -> echo.v1.MapRequest.Int64KeysEntry
+	12, // 3: echo.v1.MapRequest.uint64_keys:type_name -> echo.v1.MapRequest.Uint64KeysEntry
+	13, // 4: echo.v1.MapRequest.sint32_keys:type_name -> echo.v1.MapRequest.Sint32KeysEntry
+	14, // 5: echo.v1.MapRequest.bool_keys:type_name -> echo.v1.MapRequest.BoolKeysEntry
+	15, // 6: echo.v1.MapRequest.bytes_values:type_name -> echo.v1.MapRequest.BytesValuesEntry
+	16, // 7: echo.v1.MapRequest.message_values:type_name -> echo.v1.MapRequest.MessageValuesEntry
+	0,  // 8: echo.v1.MapResponse.request:type_name -> echo.v1.MapRequest
+	2,  // 9: echo.v1.MapResponse.keys:type_name -> echo.v1.MapKey
+	17, // 10: echo.v1.RepeatedRequest.enums:type_name -> echo.v1.StructuredRequest.Priority
+	1,  // 11: echo.v1.RepeatedRequest.messages:type_name -> echo.v1.MapValue
+	4,  // 12: echo.v1.RepeatedResponse.request:type_name -> echo.v1.RepeatedRequest
+	5,  // 13: echo.v1.RepeatedResponse.fields:type_name -> echo.v1.RepeatedField
+	17, // 14: echo.v1.ScalarsRequest.enum_value:type_name -> echo.v1.StructuredRequest.Priority
+	17, // 15: echo.v1.ScalarsRequest.optional_enum:type_name -> echo.v1.StructuredRequest.Priority
+	7,  // 16: echo.v1.ScalarsResponse.request:type_name -> echo.v1.ScalarsRequest
+	1,  // 17: echo.v1.MapRequest.MessageValuesEntry.value:type_name -> echo.v1.MapValue
+	18, // [18:18] is the sub-list for method output_type
+	18, // [18:18] is the sub-list for method input_type
+	18, // [18:18] is the sub-list for extension type_name
+	18, // [18:18] is the sub-list for extension extendee
+	0,  // [0:18] is the sub-list for field type_name
+}
+
+func init() { file_echo_v1_echo_serialization_proto_init() }
+func file_echo_v1_echo_serialization_proto_init() {
+	if File_echo_v1_echo_serialization_proto != nil {
+		return
+	}
+	file_echo_v1_echo_structured_proto_init()
+	file_echo_v1_echo_serialization_proto_msgTypes[7].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_echo_v1_echo_serialization_proto_rawDesc), len(file_echo_v1_echo_serialization_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   17,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_echo_v1_echo_serialization_proto_goTypes,
+		DependencyIndexes: file_echo_v1_echo_serialization_proto_depIdxs,
+		MessageInfos:      file_echo_v1_echo_serialization_proto_msgTypes,
+	}.Build()
+	File_echo_v1_echo_serialization_proto = out.File
+	file_echo_v1_echo_serialization_proto_goTypes = nil
+	file_echo_v1_echo_serialization_proto_depIdxs = nil
+}
@@ -0,0 +1,465 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v6.32.1
+// source: echo/v1/echo_stream.proto
+
+package echov1
+
+import (
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+
+	_ "buf.build/gen/go/bufbuild/protovalidate/protocolbuffers/go/buf/validate"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ServerStreamRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	Count         int32                  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`                             // Number of responses to stream
+	IntervalMs    int32                  `protobuf:"varint,3,opt,name=interval_ms,json=intervalMs,proto3" json:"interval_ms,omitempty"` // Interval between responses
+	LoadReport    *LoadReport            `protobuf:"bytes,4,opt,name=load_report,json=loadReport,proto3" json:"load_report,omitempty"`  // ORCA load report of the call
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServerStreamRequest) Reset() {
+	*x = ServerStreamRequest{}
+	mi := &file_echo_v1_echo_stream_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServerStreamRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerStreamRequest) ProtoMessage() {}
+
+func (x *ServerStreamRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_echo_v1_echo_stream_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerStreamRequest.ProtoReflect.Descriptor instead.
+func (*ServerStreamRequest) Descriptor() ([]byte, []int) {
+	return file_echo_v1_echo_stream_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ServerStreamRequest) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ServerStreamRequest) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *ServerStreamRequest) GetIntervalMs() int32 {
+	if x != nil {
+		return x.IntervalMs
+	}
+	return 0
+}
+
+func (x *ServerStreamRequest) GetLoadReport() *LoadReport {
+	if x != nil {
+		return x.LoadReport
+	}
+	return nil
+}
+
+// ServerStreamResumable - Stream numbered messages, resuming after the last
+// one the client received
+type ServerStreamResumableRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Message         string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	Count           int32                  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`                                            // Number of messages of the whole stream
+	IntervalMs      int32                  `protobuf:"varint,3,opt,name=interval_ms,json=intervalMs,proto3" json:"interval_ms,omitempty"`                // Interval between messages
+	LastSequence    int64                  `protobuf:"varint,4,opt,name=last_sequence,json=lastSequence,proto3" json:"last_sequence,omitempty"`          // Last sequence received; 0 starts from the first
+	DisconnectAfter int32                  `protobuf:"varint,5,opt,name=disconnect_after,json=disconnectAfter,proto3" json:"disconnect_after,omitempty"` // Fail with UNAVAILABLE after sending this many messages (0 = never)
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ServerStreamResumableRequest) Reset() {
+	*x = ServerStreamResumableRequest{}
+	mi := &file_echo_v1_echo_stream_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServerStreamResumableRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerStreamResumableRequest) ProtoMessage() {}
+
+func (x *ServerStreamResumableRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_echo_v1_echo_stream_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerStreamResumableRequest.ProtoReflect.Descriptor instead.
+func (*ServerStreamResumableRequest) Descriptor() ([]byte, []int) {
+	return file_echo_v1_echo_stream_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ServerStreamResumableRequest) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ServerStreamResumableRequest) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *ServerStreamResumableRequest) GetIntervalMs() int32 {
+	if x != nil {
+		return x.IntervalMs
+	}
+	return 0
+}
+
+func (x *ServerStreamResumableRequest) GetLastSequence() int64 {
+	if x != nil {
+		return x.LastSequence
+	}
+	return 0
+}
+
+func (x *ServerStreamResumableRequest) GetDisconnectAfter() int32 {
+	if x != nil {
+		return x.DisconnectAfter
+	}
+	return 0
+}
+
+type ResumableStreamResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sequence      int64                  `protobuf:"varint,1,opt,name=sequence,proto3" json:"sequence,omitempty"` // 1 to count
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Metadata      map[string]string      `protobuf:"bytes,3,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResumableStreamResponse) Reset() {
+	*x = ResumableStreamResponse{}
+	mi := &file_echo_v1_echo_stream_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResumableStreamResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResumableStreamResponse) ProtoMessage() {}
+
+func (x *ResumableStreamResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_echo_v1_echo_stream_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResumableStreamResponse.ProtoReflect.Descriptor instead.
+func (*ResumableStreamResponse) Descriptor() ([]byte, []int) {
+	return file_echo_v1_echo_stream_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ResumableStreamResponse) GetSequence() int64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *ResumableStreamResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ResumableStreamResponse) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+// BidirectionalStreamWithHeartbeat - Echo messages while sending heartbeats
+// at an interval on the same stream
+type HeartbeatStreamRequest struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Message             string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	HeartbeatIntervalMs int32                  `protobuf:"varint,2,opt,name=heartbeat_interval_ms,json=heartbeatIntervalMs,proto3" json:"heartbeat_interval_ms,omitempty"` // Heartbeat interval from this message on (0 keeps the current one)
+	DelayMs             int32                  `protobuf:"varint,3,opt,name=delay_ms,json=delayMs,proto3" json:"delay_ms,omitempty"`                                       // Delay before echoing this message
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *HeartbeatStreamRequest) Reset() {
+	*x = HeartbeatStreamRequest{}
+	mi := &file_echo_v1_echo_stream_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeartbeatStreamRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeartbeatStreamRequest) ProtoMessage() {}
+
+func (x *HeartbeatStreamRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_echo_v1_echo_stream_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeartbeatStreamRequest.ProtoReflect.Descriptor instead.
+func (*HeartbeatStreamRequest) Descriptor() ([]byte, []int) {
+	return file_echo_v1_echo_stream_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *HeartbeatStreamRequest) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *HeartbeatStreamRequest) GetHeartbeatIntervalMs() int32 {
+	if x != nil {
+		return x.HeartbeatIntervalMs
+	}
+	return 0
+}
+
+func (x *HeartbeatStreamRequest) GetDelayMs() int32 {
+	if x != nil {
+		return x.DelayMs
+	}
+	return 0
+}
+
+type HeartbeatStreamResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Kind            string                 `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`                                               // "echo" or "heartbeat"
+	Sequence        int64                  `protobuf:"varint,2,opt,name=sequence,proto3" json:"sequence,omitempty"`                                      // Position of the response in the stream, from 1
+	Message         string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`                                         // Echoed message, empty for heartbeats
+	RequestSequence int64                  `protobuf:"varint,4,opt,name=request_sequence,json=requestSequence,proto3" json:"request_sequence,omitempty"` // Position of the echoed request, from 1; 0 for heartbeats
+	TimestampMs     int64                  `protobuf:"varint,5,opt,name=timestamp_ms,json=timestampMs,proto3" json:"timestamp_ms,omitempty"`             // Unix time the response was sent, in milliseconds
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *HeartbeatStreamResponse) Reset() {
+	*x = HeartbeatStreamResponse{}
+	mi := &file_echo_v1_echo_stream_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeartbeatStreamResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeartbeatStreamResponse) ProtoMessage() {}
+
+func (x *HeartbeatStreamResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_echo_v1_echo_stream_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeartbeatStreamResponse.ProtoReflect.Descriptor instead.
+func (*HeartbeatStreamResponse) Descriptor() ([]byte, []int) {
+	return file_echo_v1_echo_stream_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *HeartbeatStreamResponse) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *HeartbeatStreamResponse) GetSequence() int64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *HeartbeatStreamResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *HeartbeatStreamResponse) GetRequestSequence() int64 {
+	if x != nil {
+		return x.RequestSequence
+	}
+	return 0
+}
+
+func (x *HeartbeatStreamResponse) GetTimestampMs() int64 {
+	if x != nil {
+		return x.TimestampMs
+	}
+	return 0
+}
+
+var File_echo_v1_echo_stream_proto protoreflect.FileDescriptor
+
+const file_echo_v1_echo_stream_proto_rawDesc = "" +
+	"\n" +
+	"\x19echo/v1/echo_stream.proto\x12\aecho.v1\x1a\x1bbuf/validate/validate.proto\x1a\x17echo/v1/echo_orca.proto\"\xb5\x01\n" +
+	"\x13ServerStreamRequest\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\x12 \n" +
+	"\x05count\x18\x02 \x01(\x05B\n" +
+	"\xbaH\a\x1a\x05\x18\x90N(\x00R\x05count\x12,\n" +
+	"\vinterval_ms\x18\x03 \x01(\x05B\v\xbaH\b\x1a\x06\x18\xe0\xd4\x03(\x00R\n" +
+	"intervalMs\x124\n" +
+	"\vload_report\x18\x04 \x01(\v2\x13.echo.v1.LoadReportR\n" +
+	"loadReport\"\xe3\x02\n" +
+	"\x1cServerStreamResumableRequest\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\x12 \n" +
+	"\x05count\x18\x02 \x01(\x05B\n" +
+	"\xbaH\a\x1a\x05\x18\x90N(\x00R\x05count\x12,\n" +
+	"\vinterval_ms\x18\x03 \x01(\x05B\v\xbaH\b\x1a\x06\x18\xe0\xd4\x03(\x00R\n" +
+	"intervalMs\x12,\n" +
+	"\rlast_sequence\x18\x04 \x01(\x03B\a\xbaH\x04\"\x02(\x00R\flastSequence\x122\n" +
+	"\x10disconnect_after\x18\x05 \x01(\x05B\a\xbaH\x04\x1a\x02(\x00R\x0fdisconnectAfter:w\xbaHt\x1ar\n" +
+	"\x16last_sequence_in_range\x12#last_sequence must not exceed count\x1a3this.count <= 0 || this.last_sequence <= this.count\"\xe1\x01\n" +
+	"\x17ResumableStreamResponse\x12#\n" +
+	"\bsequence\x18\x01 \x01(\x03B\a\xbaH\x04\"\x02(\x01R\bsequence\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12J\n" +
+	"\bmetadata\x18\x03 \x03(\v2..echo.v1.ResumableStreamResponse.MetadataEntryR\bmetadata\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x81\x01\n" +
+	"\x16HeartbeatStreamRequest\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\x122\n" +
+	"\x15heartbeat_interval_ms\x18\x02 \x01(\x05R\x13heartbeatIntervalMs\x12\x19\n" +
+	"\bdelay_ms\x18\x03 \x01(\x05R\adelayMs\"\xb1\x01\n" +
+	"\x17HeartbeatStreamResponse\x12\x12\n" +
+	"\x04kind\x18\x01 \x01(\tR\x04kind\x12\x1a\n" +
+	"\bsequence\x18\x02 \x01(\x03R\bsequence\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\x12)\n" +
+	"\x10request_sequence\x18\x04 \x01(\x03R\x0frequestSequence\x12!\n" +
+	"\ftimestamp_ms\x18\x05 \x01(\x03R\vtimestampMsB<Z:github.com/probitas-test/echo-servers/proto/echo/v1;echov1b\x06proto3"
+
+var (
+	file_echo_v1_echo_stream_proto_rawDescOnce sync.Once
+	file_echo_v1_echo_stream_proto_rawDescData []byte
+)
+
+func file_echo_v1_echo_stream_proto_rawDescGZIP() []byte {
+	file_echo_v1_echo_stream_proto_rawDescOnce.Do(func() {
+		file_echo_v1_echo_stream_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_echo_v1_echo_stream_proto_rawDesc), len(file_echo_v1_echo_stream_proto_rawDesc)))
+	})
+	return file_echo_v1_echo_stream_proto_rawDescData
+}
+
+var file_echo_v1_echo_stream_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_echo_v1_echo_stream_proto_goTypes = []any{
+	(*ServerStreamRequest)(nil),          // 0: echo.v1.ServerStreamRequest
+	(*ServerStreamResumableRequest)(nil), // 1: echo.v1.ServerStreamResumableRequest
+	(*ResumableStreamResponse)(nil),      // 2: echo.v1.ResumableStreamResponse
+	(*HeartbeatStreamRequest)(nil),       // 3: echo.v1.HeartbeatStreamRequest
+	(*HeartbeatStreamResponse)(nil),      // 4: echo.v1.HeartbeatStreamResponse
+	nil,                                  // 5: echo.v1.ResumableStreamResponse.MetadataEntry
+	(*LoadReport)(nil),                   // 6: echo.v1.LoadReport
+}
+var file_echo_v1_echo_stream_proto_depIdxs = []int32{
+	6, // 0: echo.v1.ServerStreamRequest.load_report:type_name -> echo.v1.LoadReport
+	5, // 1: echo.v1.ResumableStreamResponse.metadata:type_name -> echo.v1.ResumableStreamResponse.MetadataEntry
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_echo_v1_echo_stream_proto_init() }
+func file_echo_v1_echo_stream_proto_init() {
+	if File_echo_v1_echo_stream_proto != nil {
+		return
+	}
+	file_echo_v1_echo_orca_proto_init()
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_echo_v1_echo_stream_proto_rawDesc), len(file_echo_v1_echo_stream_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   6,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_echo_v1_echo_stream_proto_goTypes,
+		DependencyIndexes: file_echo_v1_echo_stream_proto_depIdxs,
+		MessageInfos:      file_echo_v1_echo_stream_proto_msgTypes,
+	}.Build()
+	File_echo_v1_echo_stream_proto = out.File
+	file_echo_v1_echo_stream_proto_goTypes = nil
+	file_echo_v1_echo_stream_proto_depIdxs = nil
+}
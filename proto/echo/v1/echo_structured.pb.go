@@ -0,0 +1,585 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v6.32.1
+// source: echo/v1/echo_structured.proto
+
+package echov1
+
+import (
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+
+	_ "buf.build/gen/go/bufbuild/protovalidate/protocolbuffers/go/buf/validate"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	wrapperspb "google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type StructuredRequest_Priority int32
+
+const (
+	StructuredRequest_PRIORITY_UNSPECIFIED StructuredRequest_Priority = 0
+	StructuredRequest_PRIORITY_LOW         StructuredRequest_Priority = 1
+	StructuredRequest_PRIORITY_NORMAL      StructuredRequest_Priority = 2
+	StructuredRequest_PRIORITY_HIGH        StructuredRequest_Priority = 3
+)
+
+// Enum value maps for StructuredRequest_Priority.
+var (
+	StructuredRequest_Priority_name = map[int32]string{
+		0: "PRIORITY_UNSPECIFIED",
+		1: "PRIORITY_LOW",
+		2: "PRIORITY_NORMAL",
+		3: "PRIORITY_HIGH",
+	}
+	StructuredRequest_Priority_value = map[string]int32{
+		"PRIORITY_UNSPECIFIED": 0,
+		"PRIORITY_LOW":         1,
+		"PRIORITY_NORMAL":      2,
+		"PRIORITY_HIGH":        3,
+	}
+)
+
+func (x StructuredRequest_Priority) Enum() *StructuredRequest_Priority {
+	p := new(StructuredRequest_Priority)
+	*p = x
+	return p
+}
+
+func (x StructuredRequest_Priority) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (StructuredRequest_Priority) Descriptor() protoreflect.EnumDescriptor {
+	return file_echo_v1_echo_structured_proto_enumTypes[0].Descriptor()
+}
+
+func (StructuredRequest_Priority) Type() protoreflect.EnumType {
+	return &file_echo_v1_echo_structured_proto_enumTypes[0]
+}
+
+func (x StructuredRequest_Priority) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use StructuredRequest_Priority.Descriptor instead.
+func (StructuredRequest_Priority) EnumDescriptor() ([]byte, []int) {
+	return file_echo_v1_echo_structured_proto_rawDescGZIP(), []int{0, 0}
+}
+
+// EchoStructured - Echo a message using well-known types, oneofs, maps,
+// enums and repeated nested messages, to exercise generated clients
+type StructuredRequest struct {
+	state      protoimpl.MessageState             `protogen:"open.v1"`
+	Id         string                             `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	CreatedAt  *timestamppb.Timestamp             `protobuf:"bytes,2,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Ttl        *durationpb.Duration               `protobuf:"bytes,3,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	Attributes *structpb.Struct                   `protobuf:"bytes,4,opt,name=attributes,proto3" json:"attributes,omitempty"` // arbitrary JSON object
+	Note       *wrapperspb.StringValue            `protobuf:"bytes,5,opt,name=note,proto3" json:"note,omitempty"`             // null when unset
+	Priority   StructuredRequest_Priority         `protobuf:"varint,6,opt,name=priority,proto3,enum=echo.v1.StructuredRequest_Priority" json:"priority,omitempty"`
+	Labels     map[string]string                  `protobuf:"bytes,7,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	ItemsByKey map[string]*StructuredRequest_Item `protobuf:"bytes,8,rep,name=items_by_key,json=itemsByKey,proto3" json:"items_by_key,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Items      []*StructuredRequest_Item          `protobuf:"bytes,9,rep,name=items,proto3" json:"items,omitempty"`
+	// Types that are valid to be assigned to Payload:
+	//
+	//	*StructuredRequest_Text
+	//	*StructuredRequest_Binary
+	//	*StructuredRequest_Item_
+	//	*StructuredRequest_Json
+	Payload       isStructuredRequest_Payload `protobuf_oneof:"payload"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StructuredRequest) Reset() {
+	*x = StructuredRequest{}
+	mi := &file_echo_v1_echo_structured_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StructuredRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StructuredRequest) ProtoMessage() {}
+
+func (x *StructuredRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_echo_v1_echo_structured_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StructuredRequest.ProtoReflect.Descriptor instead.
+func (*StructuredRequest) Descriptor() ([]byte, []int) {
+	return file_echo_v1_echo_structured_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *StructuredRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *StructuredRequest) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *StructuredRequest) GetTtl() *durationpb.Duration {
+	if x != nil {
+		return x.Ttl
+	}
+	return nil
+}
+
+func (x *StructuredRequest) GetAttributes() *structpb.Struct {
+	if x != nil {
+		return x.Attributes
+	}
+	return nil
+}
+
+func (x *StructuredRequest) GetNote() *wrapperspb.StringValue {
+	if x != nil {
+		return x.Note
+	}
+	return nil
+}
+
+func (x *StructuredRequest) GetPriority() StructuredRequest_Priority {
+	if x != nil {
+		return x.Priority
+	}
+	return StructuredRequest_PRIORITY_UNSPECIFIED
+}
+
+func (x *StructuredRequest) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *StructuredRequest) GetItemsByKey() map[string]*StructuredRequest_Item {
+	if x != nil {
+		return x.ItemsByKey
+	}
+	return nil
+}
+
+func (x *StructuredRequest) GetItems() []*StructuredRequest_Item {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *StructuredRequest) GetPayload() isStructuredRequest_Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *StructuredRequest) GetText() string {
+	if x != nil {
+		if x, ok := x.Payload.(*StructuredRequest_Text); ok {
+			return x.Text
+		}
+	}
+	return ""
+}
+
+func (x *StructuredRequest) GetBinary() []byte {
+	if x != nil {
+		if x, ok := x.Payload.(*StructuredRequest_Binary); ok {
+			return x.Binary
+		}
+	}
+	return nil
+}
+
+func (x *StructuredRequest) GetItem() *StructuredRequest_Item {
+	if x != nil {
+		if x, ok := x.Payload.(*StructuredRequest_Item_); ok {
+			return x.Item
+		}
+	}
+	return nil
+}
+
+func (x *StructuredRequest) GetJson() *structpb.Value {
+	if x != nil {
+		if x, ok := x.Payload.(*StructuredRequest_Json); ok {
+			return x.Json
+		}
+	}
+	return nil
+}
+
+type isStructuredRequest_Payload interface {
+	isStructuredRequest_Payload()
+}
+
+type StructuredRequest_Text struct {
+	Text string `protobuf:"bytes,10,opt,name=text,proto3,oneof"`
+}
+
+type StructuredRequest_Binary struct {
+	Binary []byte `protobuf:"bytes,11,opt,name=binary,proto3,oneof"`
+}
+
+type StructuredRequest_Item_ struct {
+	Item *StructuredRequest_Item `protobuf:"bytes,12,opt,name=item,proto3,oneof"`
+}
+
+type StructuredRequest_Json struct {
+	Json *structpb.Value `protobuf:"bytes,13,opt,name=json,proto3,oneof"`
+}
+
+func (*StructuredRequest_Text) isStructuredRequest_Payload() {}
+
+func (*StructuredRequest_Binary) isStructuredRequest_Payload() {}
+
+func (*StructuredRequest_Item_) isStructuredRequest_Payload() {}
+
+func (*StructuredRequest_Json) isStructuredRequest_Payload() {}
+
+type StructuredResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Request       *StructuredRequest     `protobuf:"bytes,1,opt,name=request,proto3" json:"request,omitempty"`                                                                             // the request, unchanged
+	ReceivedAt    *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=received_at,json=receivedAt,proto3" json:"received_at,omitempty"`                                                     // when the server received the request
+	Age           *durationpb.Duration   `protobuf:"bytes,3,opt,name=age,proto3" json:"age,omitempty"`                                                                                     // received_at - created_at, unset without created_at
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`                                                        // created_at + ttl, unset without both
+	Payload       string                 `protobuf:"bytes,5,opt,name=payload,proto3" json:"payload,omitempty"`                                                                             // payload set: text, binary, item, json or empty
+	ItemCount     int32                  `protobuf:"varint,6,opt,name=item_count,json=itemCount,proto3" json:"item_count,omitempty"`                                                       // items, items_by_key and item, with their children
+	Metadata      map[string]string      `protobuf:"bytes,7,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // request metadata
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StructuredResponse) Reset() {
+	*x = StructuredResponse{}
+	mi := &file_echo_v1_echo_structured_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StructuredResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StructuredResponse) ProtoMessage() {}
+
+func (x *StructuredResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_echo_v1_echo_structured_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StructuredResponse.ProtoReflect.Descriptor instead.
+func (*StructuredResponse) Descriptor() ([]byte, []int) {
+	return file_echo_v1_echo_structured_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *StructuredResponse) GetRequest() *StructuredRequest {
+	if x != nil {
+		return x.Request
+	}
+	return nil
+}
+
+func (x *StructuredResponse) GetReceivedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ReceivedAt
+	}
+	return nil
+}
+
+func (x *StructuredResponse) GetAge() *durationpb.Duration {
+	if x != nil {
+		return x.Age
+	}
+	return nil
+}
+
+func (x *StructuredResponse) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *StructuredResponse) GetPayload() string {
+	if x != nil {
+		return x.Payload
+	}
+	return ""
+}
+
+func (x *StructuredResponse) GetItemCount() int32 {
+	if x != nil {
+		return x.ItemCount
+	}
+	return 0
+}
+
+func (x *StructuredResponse) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+// Item nests itself through children
+type StructuredRequest_Item struct {
+	state         protoimpl.MessageState    `protogen:"open.v1"`
+	Name          string                    `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Quantity      int64                     `protobuf:"varint,2,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	Tags          []string                  `protobuf:"bytes,3,rep,name=tags,proto3" json:"tags,omitempty"`
+	Attributes    map[string]string         `protobuf:"bytes,4,rep,name=attributes,proto3" json:"attributes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Children      []*StructuredRequest_Item `protobuf:"bytes,5,rep,name=children,proto3" json:"children,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StructuredRequest_Item) Reset() {
+	*x = StructuredRequest_Item{}
+	mi := &file_echo_v1_echo_structured_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StructuredRequest_Item) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StructuredRequest_Item) ProtoMessage() {}
+
+func (x *StructuredRequest_Item) ProtoReflect() protoreflect.Message {
+	mi := &file_echo_v1_echo_structured_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StructuredRequest_Item.ProtoReflect.Descriptor instead.
+func (*StructuredRequest_Item) Descriptor() ([]byte, []int) {
+	return file_echo_v1_echo_structured_proto_rawDescGZIP(), []int{0, 0}
+}
+
+func (x *StructuredRequest_Item) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *StructuredRequest_Item) GetQuantity() int64 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *StructuredRequest_Item) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *StructuredRequest_Item) GetAttributes() map[string]string {
+	if x != nil {
+		return x.Attributes
+	}
+	return nil
+}
+
+func (x *StructuredRequest_Item) GetChildren() []*StructuredRequest_Item {
+	if x != nil {
+		return x.Children
+	}
+	return nil
+}
+
+var File_echo_v1_echo_structured_proto protoreflect.FileDescriptor
+
+const file_echo_v1_echo_structured_proto_rawDesc = "" +
+	"\n" +
+	"\x1decho/v1/echo_structured.proto\x12\aecho.v1\x1a\x1bbuf/validate/validate.proto\x1a\x1egoogle/protobuf/duration.proto\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x1egoogle/protobuf/wrappers.proto\"\xe1\t\n" +
+	"\x11StructuredRequest\x12\x18\n" +
+	"\x02id\x18\x01 \x01(\tB\b\xbaH\x05r\x03\x18\x80\x01R\x02id\x129\n" +
+	"\n" +
+	"created_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x125\n" +
+	"\x03ttl\x18\x03 \x01(\v2\x19.google.protobuf.DurationB\b\xbaH\x05\xaa\x01\x022\x00R\x03ttl\x127\n" +
+	"\n" +
+	"attributes\x18\x04 \x01(\v2\x17.google.protobuf.StructR\n" +
+	"attributes\x120\n" +
+	"\x04note\x18\x05 \x01(\v2\x1c.google.protobuf.StringValueR\x04note\x12I\n" +
+	"\bpriority\x18\x06 \x01(\x0e2#.echo.v1.StructuredRequest.PriorityB\b\xbaH\x05\x82\x01\x02\x10\x01R\bpriority\x12>\n" +
+	"\x06labels\x18\a \x03(\v2&.echo.v1.StructuredRequest.LabelsEntryR\x06labels\x12L\n" +
+	"\fitems_by_key\x18\b \x03(\v2*.echo.v1.StructuredRequest.ItemsByKeyEntryR\n" +
+	"itemsByKey\x125\n" +
+	"\x05items\x18\t \x03(\v2\x1f.echo.v1.StructuredRequest.ItemR\x05items\x12\x14\n" +
+	"\x04text\x18\n" +
+	" \x01(\tH\x00R\x04text\x12\x18\n" +
+	"\x06binary\x18\v \x01(\fH\x00R\x06binary\x125\n" +
+	"\x04item\x18\f \x01(\v2\x1f.echo.v1.StructuredRequest.ItemH\x00R\x04item\x12,\n" +
+	"\x04json\x18\r \x01(\v2\x16.google.protobuf.ValueH\x00R\x04json\x1a\xa9\x02\n" +
+	"\x04Item\x12\x1b\n" +
+	"\x04name\x18\x01 \x01(\tB\a\xbaH\x04r\x02\x10\x01R\x04name\x12#\n" +
+	"\bquantity\x18\x02 \x01(\x03B\a\xbaH\x04\"\x02(\x00R\bquantity\x12\x12\n" +
+	"\x04tags\x18\x03 \x03(\tR\x04tags\x12O\n" +
+	"\n" +
+	"attributes\x18\x04 \x03(\v2/.echo.v1.StructuredRequest.Item.AttributesEntryR\n" +
+	"attributes\x12;\n" +
+	"\bchildren\x18\x05 \x03(\v2\x1f.echo.v1.StructuredRequest.ItemR\bchildren\x1a=\n" +
+	"\x0fAttributesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a^\n" +
+	"\x0fItemsByKeyEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x125\n" +
+	"\x05value\x18\x02 \x01(\v2\x1f.echo.v1.StructuredRequest.ItemR\x05value:\x028\x01\"^\n" +
+	"\bPriority\x12\x18\n" +
+	"\x14PRIORITY_UNSPECIFIED\x10\x00\x12\x10\n" +
+	"\fPRIORITY_LOW\x10\x01\x12\x13\n" +
+	"\x0fPRIORITY_NORMAL\x10\x02\x12\x11\n" +
+	"\rPRIORITY_HIGH\x10\x03B\t\n" +
+	"\apayload\"\xac\x03\n" +
+	"\x12StructuredResponse\x124\n" +
+	"\arequest\x18\x01 \x01(\v2\x1a.echo.v1.StructuredRequestR\arequest\x12;\n" +
+	"\vreceived_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"receivedAt\x12+\n" +
+	"\x03age\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\x03age\x129\n" +
+	"\n" +
+	"expires_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x12\x18\n" +
+	"\apayload\x18\x05 \x01(\tR\apayload\x12\x1d\n" +
+	"\n" +
+	"item_count\x18\x06 \x01(\x05R\titemCount\x12E\n" +
+	"\bmetadata\x18\a \x03(\v2).echo.v1.StructuredResponse.MetadataEntryR\bmetadata\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B<Z:github.com/probitas-test/echo-servers/proto/echo/v1;echov1b\x06proto3"
+
+var (
+	file_echo_v1_echo_structured_proto_rawDescOnce sync.Once
+	file_echo_v1_echo_structured_proto_rawDescData []byte
+)
+
+func file_echo_v1_echo_structured_proto_rawDescGZIP() []byte {
+	file_echo_v1_echo_structured_proto_rawDescOnce.Do(func() {
+		file_echo_v1_echo_structured_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_echo_v1_echo_structured_proto_rawDesc), len(file_echo_v1_echo_structured_proto_rawDesc)))
+	})
+	return file_echo_v1_echo_structured_proto_rawDescData
+}
+
+var file_echo_v1_echo_structured_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_echo_v1_echo_structured_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_echo_v1_echo_structured_proto_goTypes = []any{
+	(StructuredRequest_Priority)(0), // 0: echo.v1.StructuredRequest.Priority
+	(*StructuredRequest)(nil),       // 1: echo.v1.StructuredRequest
+	(*StructuredResponse)(nil),      // 2: echo.v1.StructuredResponse
+	(*StructuredRequest_Item)(nil),  // 3: echo.v1.StructuredRequest.Item
+	nil,                             // 4: echo.v1.StructuredRequest.LabelsEntry
+	nil,                             // 5: echo.v1.StructuredRequest.ItemsByKeyEntry
+	nil,                             // 6: echo.v1.StructuredRequest.Item.AttributesEntry
+	nil,                             // 7: echo.v1.StructuredResponse.MetadataEntry
+	(*timestamppb.Timestamp)(nil),   // 8: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),     // 9: google.protobuf.Duration
+	(*structpb.Struct)(nil),         // 10: google.protobuf.Struct
+	(*wrapperspb.StringValue)(nil),  // 11: google.protobuf.StringValue
+	(*structpb.Value)(nil),          // 12: google.protobuf.Value
+}
+var file_echo_v1_echo_structured_proto_depIdxs = []int32{
+	8,  // 0: echo.v1.StructuredRequest.created_at:type_name -> google.protobuf.Timestamp
+	9,  // 1: echo.v1.StructuredRequest.ttl:type_name -> google.protobuf.Duration
+	10, // 2: echo.v1.StructuredRequest.attributes:type_name -> google.protobuf.Struct
+	11, // 3: echo.v1.StructuredRequest.note:type_name -> google.protobuf.StringValue
+	0,  // 4: echo.v1.StructuredRequest.priority:type_name -> echo.v1.StructuredRequest.Priority
+	4,  // 5: echo.v1.StructuredRequest.labels:type_name -> echo.v1.StructuredRequest.LabelsEntry
+	5,  // 6: echo.v1.StructuredRequest.items_by_key:type_name -> echo.v1.StructuredRequest.ItemsByKeyEntry
+	3,  // 7: echo.v1.StructuredRequest.items:type_name -> echo.v1.StructuredRequest.Item
+	3,  // 8: echo.v1.StructuredRequest.item:type_name -> echo.v1.StructuredRequest.Item
+	12, // 9: echo.v1.StructuredRequest.json:type_name -> google.protobuf.Value
+	1,  // 10: echo.v1.StructuredResponse.request:type_name -> echo.v1.StructuredRequest
+	8,  // 11: echo.v1.StructuredResponse.received_at:type_name -> google.protobuf.Timestamp
+	9,  // 12: echo.v1.StructuredResponse.age:type_name -> google.protobuf.Duration
+	8,  // 13: echo.v1.StructuredResponse.expires_at:type_name -> google.protobuf.Timestamp
+	7,  // 14: echo.v1.StructuredResponse.metadata:type_name -> echo.v1.StructuredResponse.MetadataEntry
+	6,  // 15: echo.v1.StructuredRequest.Item.attributes:type_name -> echo.v1.StructuredRequest.Item.AttributesEntry
+	3,  // 16: echo.v1.StructuredRequest.Item.children:type_name -> echo.v1.StructuredRequest.Item
+	3,  // 17: echo.v1.StructuredRequest.ItemsByKeyEntry.value:type_name -> echo.v1.StructuredRequest.Item
+	18, // [18:18] is the sub-list for method output_type
+	18, // [18:18] is the sub-list for method input_type
+	18, // [18:18] is the sub-list for extension type_name
+	18, // [18:18] is the sub-list for extension extendee
+	0,  // [0:18] is the sub-list for field type_name
+}
+
+func init() { file_echo_v1_echo_structured_proto_init() }
+func file_echo_v1_echo_structured_proto_init() {
+	if File_echo_v1_echo_structured_proto != nil {
+		return
+	}
+	file_echo_v1_echo_structured_proto_msgTypes[0].OneofWrappers = []any{
+		(*StructuredRequest_Text)(nil),
+		(*StructuredRequest_Binary)(nil),
+		(*StructuredRequest_Item_)(nil),
+		(*StructuredRequest_Json)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_echo_v1_echo_structured_proto_rawDesc), len(file_echo_v1_echo_structured_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_echo_v1_echo_structured_proto_goTypes,
+		DependencyIndexes: file_echo_v1_echo_structured_proto_depIdxs,
+		EnumInfos:         file_echo_v1_echo_structured_proto_enumTypes,
+		MessageInfos:      file_echo_v1_echo_structured_proto_msgTypes,
+	}.Build()
+	File_echo_v1_echo_structured_proto = out.File
+	file_echo_v1_echo_structured_proto_goTypes = nil
+	file_echo_v1_echo_structured_proto_depIdxs = nil
+}
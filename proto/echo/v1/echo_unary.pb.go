@@ -2,15 +2,16 @@
 // versions:
 // 	protoc-gen-go v1.36.11
 // 	protoc        v6.32.1
-// source: echo_unary.proto
+// source: echo/v1/echo_unary.proto
 
-package proto
+package echov1
 
 import (
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
 
+	_ "buf.build/gen/go/bufbuild/protovalidate/protocolbuffers/go/buf/validate"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 )
@@ -25,13 +26,14 @@ const (
 type EchoRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	LoadReport    *LoadReport            `protobuf:"bytes,2,opt,name=load_report,json=loadReport,proto3" json:"load_report,omitempty"` // ORCA load report of the call
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *EchoRequest) Reset() {
 	*x = EchoRequest{}
-	mi := &file_echo_unary_proto_msgTypes[0]
+	mi := &file_echo_v1_echo_unary_proto_msgTypes[0]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -43,7 +45,7 @@ func (x *EchoRequest) String() string {
 func (*EchoRequest) ProtoMessage() {}
 
 func (x *EchoRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_echo_unary_proto_msgTypes[0]
+	mi := &file_echo_v1_echo_unary_proto_msgTypes[0]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -56,7 +58,7 @@ func (x *EchoRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use EchoRequest.ProtoReflect.Descriptor instead.
 func (*EchoRequest) Descriptor() ([]byte, []int) {
-	return file_echo_unary_proto_rawDescGZIP(), []int{0}
+	return file_echo_v1_echo_unary_proto_rawDescGZIP(), []int{0}
 }
 
 func (x *EchoRequest) GetMessage() string {
@@ -66,17 +68,25 @@ func (x *EchoRequest) GetMessage() string {
 	return ""
 }
 
+func (x *EchoRequest) GetLoadReport() *LoadReport {
+	if x != nil {
+		return x.LoadReport
+	}
+	return nil
+}
+
 type EchoWithDelayRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
 	DelayMs       int32                  `protobuf:"varint,2,opt,name=delay_ms,json=delayMs,proto3" json:"delay_ms,omitempty"`
+	LoadReport    *LoadReport            `protobuf:"bytes,3,opt,name=load_report,json=loadReport,proto3" json:"load_report,omitempty"` // ORCA load report of the call
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *EchoWithDelayRequest) Reset() {
 	*x = EchoWithDelayRequest{}
-	mi := &file_echo_unary_proto_msgTypes[1]
+	mi := &file_echo_v1_echo_unary_proto_msgTypes[1]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -88,7 +98,7 @@ func (x *EchoWithDelayRequest) String() string {
 func (*EchoWithDelayRequest) ProtoMessage() {}
 
 func (x *EchoWithDelayRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_echo_unary_proto_msgTypes[1]
+	mi := &file_echo_v1_echo_unary_proto_msgTypes[1]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -101,7 +111,7 @@ func (x *EchoWithDelayRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use EchoWithDelayRequest.ProtoReflect.Descriptor instead.
 func (*EchoWithDelayRequest) Descriptor() ([]byte, []int) {
-	return file_echo_unary_proto_rawDescGZIP(), []int{1}
+	return file_echo_v1_echo_unary_proto_rawDescGZIP(), []int{1}
 }
 
 func (x *EchoWithDelayRequest) GetMessage() string {
@@ -118,6 +128,13 @@ func (x *EchoWithDelayRequest) GetDelayMs() int32 {
 	return 0
 }
 
+func (x *EchoWithDelayRequest) GetLoadReport() *LoadReport {
+	if x != nil {
+		return x.LoadReport
+	}
+	return nil
+}
+
 type EchoErrorRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
@@ -129,7 +146,7 @@ type EchoErrorRequest struct {
 
 func (x *EchoErrorRequest) Reset() {
 	*x = EchoErrorRequest{}
-	mi := &file_echo_unary_proto_msgTypes[2]
+	mi := &file_echo_v1_echo_unary_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -141,7 +158,7 @@ func (x *EchoErrorRequest) String() string {
 func (*EchoErrorRequest) ProtoMessage() {}
 
 func (x *EchoErrorRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_echo_unary_proto_msgTypes[2]
+	mi := &file_echo_v1_echo_unary_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -154,7 +171,7 @@ func (x *EchoErrorRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use EchoErrorRequest.ProtoReflect.Descriptor instead.
 func (*EchoErrorRequest) Descriptor() ([]byte, []int) {
-	return file_echo_unary_proto_rawDescGZIP(), []int{2}
+	return file_echo_v1_echo_unary_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *EchoErrorRequest) GetMessage() string {
@@ -190,7 +207,7 @@ type EchoErrorWithDetailsRequest struct {
 
 func (x *EchoErrorWithDetailsRequest) Reset() {
 	*x = EchoErrorWithDetailsRequest{}
-	mi := &file_echo_unary_proto_msgTypes[3]
+	mi := &file_echo_v1_echo_unary_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -202,7 +219,7 @@ func (x *EchoErrorWithDetailsRequest) String() string {
 func (*EchoErrorWithDetailsRequest) ProtoMessage() {}
 
 func (x *EchoErrorWithDetailsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_echo_unary_proto_msgTypes[3]
+	mi := &file_echo_v1_echo_unary_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -215,7 +232,7 @@ func (x *EchoErrorWithDetailsRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use EchoErrorWithDetailsRequest.ProtoReflect.Descriptor instead.
 func (*EchoErrorWithDetailsRequest) Descriptor() ([]byte, []int) {
-	return file_echo_unary_proto_rawDescGZIP(), []int{3}
+	return file_echo_v1_echo_unary_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *EchoErrorWithDetailsRequest) GetCode() int32 {
@@ -239,75 +256,83 @@ func (x *EchoErrorWithDetailsRequest) GetDetails() []*ErrorDetail {
 	return nil
 }
 
-var File_echo_unary_proto protoreflect.FileDescriptor
+var File_echo_v1_echo_unary_proto protoreflect.FileDescriptor
 
-const file_echo_unary_proto_rawDesc = "" +
+const file_echo_v1_echo_unary_proto_rawDesc = "" +
 	"\n" +
-	"\x10echo_unary.proto\x12\aecho.v1\x1a\x11echo_errors.proto\"'\n" +
-	"\vEchoRequest\x12\x18\n" +
-	"\amessage\x18\x01 \x01(\tR\amessage\"K\n" +
+	"\x18echo/v1/echo_unary.proto\x12\aecho.v1\x1a\x1bbuf/validate/validate.proto\x1a\x19echo/v1/echo_errors.proto\x1a\x17echo/v1/echo_orca.proto\"h\n" +
+	"\vEchoRequest\x12#\n" +
+	"\amessage\x18\x01 \x01(\tB\t\xbaH\x06r\x04(\x80\x80@R\amessage\x124\n" +
+	"\vload_report\x18\x02 \x01(\v2\x13.echo.v1.LoadReportR\n" +
+	"loadReport\"\x8e\x01\n" +
 	"\x14EchoWithDelayRequest\x12\x18\n" +
-	"\amessage\x18\x01 \x01(\tR\amessage\x12\x19\n" +
-	"\bdelay_ms\x18\x02 \x01(\x05R\adelayMs\"Z\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\x12&\n" +
+	"\bdelay_ms\x18\x02 \x01(\x05B\v\xbaH\b\x1a\x06\x18\xe0\xa7\x12(\x00R\adelayMs\x124\n" +
+	"\vload_report\x18\x03 \x01(\v2\x13.echo.v1.LoadReportR\n" +
+	"loadReport\"e\n" +
 	"\x10EchoErrorRequest\x12\x18\n" +
-	"\amessage\x18\x01 \x01(\tR\amessage\x12\x12\n" +
-	"\x04code\x18\x02 \x01(\x05R\x04code\x12\x18\n" +
-	"\adetails\x18\x03 \x01(\tR\adetails\"{\n" +
-	"\x1bEchoErrorWithDetailsRequest\x12\x12\n" +
-	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\x12\x1d\n" +
+	"\x04code\x18\x02 \x01(\x05B\t\xbaH\x06\x1a\x04\x18\x10(\x00R\x04code\x12\x18\n" +
+	"\adetails\x18\x03 \x01(\tR\adetails\"\x86\x01\n" +
+	"\x1bEchoErrorWithDetailsRequest\x12\x1d\n" +
+	"\x04code\x18\x01 \x01(\x05B\t\xbaH\x06\x1a\x04\x18\x10(\x00R\x04code\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12.\n" +
-	"\adetails\x18\x03 \x03(\v2\x14.echo.v1.ErrorDetailR\adetailsB=Z;github.com/probitas-test/echo-servers/echo-connectrpc/protob\x06proto3"
+	"\adetails\x18\x03 \x03(\v2\x14.echo.v1.ErrorDetailR\adetailsB<Z:github.com/probitas-test/echo-servers/proto/echo/v1;echov1b\x06proto3"
 
 var (
-	file_echo_unary_proto_rawDescOnce sync.Once
-	file_echo_unary_proto_rawDescData []byte
+	file_echo_v1_echo_unary_proto_rawDescOnce sync.Once
+	file_echo_v1_echo_unary_proto_rawDescData []byte
 )
 
-func file_echo_unary_proto_rawDescGZIP() []byte {
-	file_echo_unary_proto_rawDescOnce.Do(func() {
-		file_echo_unary_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_echo_unary_proto_rawDesc), len(file_echo_unary_proto_rawDesc)))
+func file_echo_v1_echo_unary_proto_rawDescGZIP() []byte {
+	file_echo_v1_echo_unary_proto_rawDescOnce.Do(func() {
+		file_echo_v1_echo_unary_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_echo_v1_echo_unary_proto_rawDesc), len(file_echo_v1_echo_unary_proto_rawDesc)))
 	})
-	return file_echo_unary_proto_rawDescData
+	return file_echo_v1_echo_unary_proto_rawDescData
 }
 
-var file_echo_unary_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
-var file_echo_unary_proto_goTypes = []any{
+var file_echo_v1_echo_unary_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_echo_v1_echo_unary_proto_goTypes = []any{
 	(*EchoRequest)(nil),                 // 0: echo.v1.EchoRequest
 	(*EchoWithDelayRequest)(nil),        // 1: echo.v1.EchoWithDelayRequest
 	(*EchoErrorRequest)(nil),            // 2: echo.v1.EchoErrorRequest
 	(*EchoErrorWithDetailsRequest)(nil), // 3: echo.v1.EchoErrorWithDetailsRequest
-	(*ErrorDetail)(nil),                 // 4: echo.v1.ErrorDetail
-}
-var file_echo_unary_proto_depIdxs = []int32{
-	4, // 0: echo.v1.EchoErrorWithDetailsRequest.details:type_name -> echo.v1.ErrorDetail
-	1, // [1:1] is the sub-list for method output_type
-	1, // [1:1] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
-}
-
-func init() { file_echo_unary_proto_init() }
-func file_echo_unary_proto_init() {
-	if File_echo_unary_proto != nil {
+	(*LoadReport)(nil),                  // 4: echo.v1.LoadReport
+	(*ErrorDetail)(nil),                 // 5: echo.v1.ErrorDetail
+}
+var file_echo_v1_echo_unary_proto_depIdxs = []int32{
+	4, // 0: echo.v1.EchoRequest.load_report:type_name -> echo.v1.LoadReport
+	4, // 1: echo.v1.EchoWithDelayRequest.load_report:type_name -> echo.v1.LoadReport
+	5, // 2: echo.v1.EchoErrorWithDetailsRequest.details:type_name -> echo.v1.ErrorDetail
+	3, // [3:3] is the sub-list for method output_type
+	3, // [3:3] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_echo_v1_echo_unary_proto_init() }
+func file_echo_v1_echo_unary_proto_init() {
+	if File_echo_v1_echo_unary_proto != nil {
 		return
 	}
-	file_echo_errors_proto_init()
+	file_echo_v1_echo_errors_proto_init()
+	file_echo_v1_echo_orca_proto_init()
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
-			RawDescriptor: unsafe.Slice(unsafe.StringData(file_echo_unary_proto_rawDesc), len(file_echo_unary_proto_rawDesc)),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_echo_v1_echo_unary_proto_rawDesc), len(file_echo_v1_echo_unary_proto_rawDesc)),
 			NumEnums:      0,
 			NumMessages:   4,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
-		GoTypes:           file_echo_unary_proto_goTypes,
-		DependencyIndexes: file_echo_unary_proto_depIdxs,
-		MessageInfos:      file_echo_unary_proto_msgTypes,
+		GoTypes:           file_echo_v1_echo_unary_proto_goTypes,
+		DependencyIndexes: file_echo_v1_echo_unary_proto_depIdxs,
+		MessageInfos:      file_echo_v1_echo_unary_proto_msgTypes,
 	}.Build()
-	File_echo_unary_proto = out.File
-	file_echo_unary_proto_goTypes = nil
-	file_echo_unary_proto_depIdxs = nil
+	File_echo_v1_echo_unary_proto = out.File
+	file_echo_v1_echo_unary_proto_goTypes = nil
+	file_echo_v1_echo_unary_proto_depIdxs = nil
 }
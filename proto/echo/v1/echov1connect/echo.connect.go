@@ -0,0 +1,772 @@
+// Code generated by protoc-gen-connect-go. DO NOT EDIT.
+//
+// Source: echo/v1/echo.proto
+
+package echov1connect
+
+import (
+	context "context"
+	errors "errors"
+	http "net/http"
+	strings "strings"
+
+	connect "connectrpc.com/connect"
+	v1 "github.com/probitas-test/echo-servers/proto/echo/v1"
+)
+
+// This is a compile-time assertion to ensure that this generated file and the connect package are
+// compatible. If you get a compiler error that this constant is not defined, this code was
+// generated with a version of connect newer than the one compiled into your binary. You can fix the
+// problem by either regenerating this code with an older version of connect or updating the connect
+// version compiled into your binary.
+const _ = connect.IsAtLeastVersion1_13_0
+
+const (
+	// EchoName is the fully-qualified name of the Echo service.
+	EchoName = "echo.v1.Echo"
+)
+
+// These constants are the fully-qualified names of the RPCs defined in this package. They're
+// exposed at runtime as Spec.Procedure and as the final two segments of the HTTP route.
+//
+// Note that these are different from the fully-qualified method names used by
+// google.golang.org/protobuf/reflect/protoreflect. To convert from these constants to
+// reflection-formatted method names, remove the leading slash and convert the remaining slash to a
+// period.
+const (
+	// EchoEchoProcedure is the fully-qualified name of the Echo's Echo RPC.
+	EchoEchoProcedure = "/echo.v1.Echo/Echo"
+	// EchoEchoWithDelayProcedure is the fully-qualified name of the Echo's EchoWithDelay RPC.
+	EchoEchoWithDelayProcedure = "/echo.v1.Echo/EchoWithDelay"
+	// EchoEchoErrorProcedure is the fully-qualified name of the Echo's EchoError RPC.
+	EchoEchoErrorProcedure = "/echo.v1.Echo/EchoError"
+	// EchoEchoRequestMetadataProcedure is the fully-qualified name of the Echo's EchoRequestMetadata
+	// RPC.
+	EchoEchoRequestMetadataProcedure = "/echo.v1.Echo/EchoRequestMetadata"
+	// EchoEchoWithTrailersProcedure is the fully-qualified name of the Echo's EchoWithTrailers RPC.
+	EchoEchoWithTrailersProcedure = "/echo.v1.Echo/EchoWithTrailers"
+	// EchoEchoLargePayloadProcedure is the fully-qualified name of the Echo's EchoLargePayload RPC.
+	EchoEchoLargePayloadProcedure = "/echo.v1.Echo/EchoLargePayload"
+	// EchoEchoDeadlineProcedure is the fully-qualified name of the Echo's EchoDeadline RPC.
+	EchoEchoDeadlineProcedure = "/echo.v1.Echo/EchoDeadline"
+	// EchoEchoErrorWithDetailsProcedure is the fully-qualified name of the Echo's EchoErrorWithDetails
+	// RPC.
+	EchoEchoErrorWithDetailsProcedure = "/echo.v1.Echo/EchoErrorWithDetails"
+	// EchoServerStreamProcedure is the fully-qualified name of the Echo's ServerStream RPC.
+	EchoServerStreamProcedure = "/echo.v1.Echo/ServerStream"
+	// EchoClientStreamProcedure is the fully-qualified name of the Echo's ClientStream RPC.
+	EchoClientStreamProcedure = "/echo.v1.Echo/ClientStream"
+	// EchoBidirectionalStreamProcedure is the fully-qualified name of the Echo's BidirectionalStream
+	// RPC.
+	EchoBidirectionalStreamProcedure = "/echo.v1.Echo/BidirectionalStream"
+	// EchoServerStreamResumableProcedure is the fully-qualified name of the Echo's
+	// ServerStreamResumable RPC.
+	EchoServerStreamResumableProcedure = "/echo.v1.Echo/ServerStreamResumable"
+	// EchoBidirectionalStreamWithHeartbeatProcedure is the fully-qualified name of the Echo's
+	// BidirectionalStreamWithHeartbeat RPC.
+	EchoBidirectionalStreamWithHeartbeatProcedure = "/echo.v1.Echo/BidirectionalStreamWithHeartbeat"
+	// EchoEchoStructuredProcedure is the fully-qualified name of the Echo's EchoStructured RPC.
+	EchoEchoStructuredProcedure = "/echo.v1.Echo/EchoStructured"
+	// EchoEchoMapProcedure is the fully-qualified name of the Echo's EchoMap RPC.
+	EchoEchoMapProcedure = "/echo.v1.Echo/EchoMap"
+	// EchoEchoRepeatedProcedure is the fully-qualified name of the Echo's EchoRepeated RPC.
+	EchoEchoRepeatedProcedure = "/echo.v1.Echo/EchoRepeated"
+	// EchoEchoScalarsProcedure is the fully-qualified name of the Echo's EchoScalars RPC.
+	EchoEchoScalarsProcedure = "/echo.v1.Echo/EchoScalars"
+	// EchoVersionProcedure is the fully-qualified name of the Echo's Version RPC.
+	EchoVersionProcedure = "/echo.v1.Echo/Version"
+	// EchoNetworkProcedure is the fully-qualified name of the Echo's Network RPC.
+	EchoNetworkProcedure = "/echo.v1.Echo/Network"
+	// EchoCredentialsProcedure is the fully-qualified name of the Echo's Credentials RPC.
+	EchoCredentialsProcedure = "/echo.v1.Echo/Credentials"
+	// EchoHttp2SettingsProcedure is the fully-qualified name of the Echo's Http2Settings RPC.
+	EchoHttp2SettingsProcedure = "/echo.v1.Echo/Http2Settings"
+	// EchoServerTimeProcedure is the fully-qualified name of the Echo's ServerTime RPC.
+	EchoServerTimeProcedure = "/echo.v1.Echo/ServerTime"
+	// EchoBurnResourcesProcedure is the fully-qualified name of the Echo's BurnResources RPC.
+	EchoBurnResourcesProcedure = "/echo.v1.Echo/BurnResources"
+)
+
+// EchoClient is a client for the echo.v1.Echo service.
+type EchoClient interface {
+	// Unary RPCs
+	Echo(context.Context, *connect.Request[v1.EchoRequest]) (*connect.Response[v1.EchoResponse], error)
+	EchoWithDelay(context.Context, *connect.Request[v1.EchoWithDelayRequest]) (*connect.Response[v1.EchoResponse], error)
+	EchoError(context.Context, *connect.Request[v1.EchoErrorRequest]) (*connect.Response[v1.EchoResponse], error)
+	// Metadata/Headers RPCs
+	EchoRequestMetadata(context.Context, *connect.Request[v1.EchoRequestMetadataRequest]) (*connect.Response[v1.EchoRequestMetadataResponse], error)
+	EchoWithTrailers(context.Context, *connect.Request[v1.EchoWithTrailersRequest]) (*connect.Response[v1.EchoResponse], error)
+	// Payload Testing RPCs
+	EchoLargePayload(context.Context, *connect.Request[v1.EchoLargePayloadRequest]) (*connect.Response[v1.EchoLargePayloadResponse], error)
+	// Deadline/Timeout RPCs
+	EchoDeadline(context.Context, *connect.Request[v1.EchoDeadlineRequest]) (*connect.Response[v1.EchoDeadlineResponse], error)
+	// Error Scenarios RPCs
+	EchoErrorWithDetails(context.Context, *connect.Request[v1.EchoErrorWithDetailsRequest]) (*connect.Response[v1.EchoResponse], error)
+	// Streaming RPCs
+	ServerStream(context.Context, *connect.Request[v1.ServerStreamRequest]) (*connect.ServerStreamForClient[v1.EchoResponse], error)
+	ClientStream(context.Context) *connect.ClientStreamForClient[v1.EchoRequest, v1.EchoResponse]
+	BidirectionalStream(context.Context) *connect.BidiStreamForClient[v1.EchoRequest, v1.EchoResponse]
+	// Resumable server streaming RPC (echo-grpc)
+	ServerStreamResumable(context.Context, *connect.Request[v1.ServerStreamResumableRequest]) (*connect.ServerStreamForClient[v1.ResumableStreamResponse], error)
+	// Heartbeat streaming RPC (echo-connectrpc)
+	BidirectionalStreamWithHeartbeat(context.Context) *connect.BidiStreamForClient[v1.HeartbeatStreamRequest, v1.HeartbeatStreamResponse]
+	// Structured message RPC
+	EchoStructured(context.Context, *connect.Request[v1.StructuredRequest]) (*connect.Response[v1.StructuredResponse], error)
+	// Serialization edge case RPCs (echo-grpc)
+	EchoMap(context.Context, *connect.Request[v1.MapRequest]) (*connect.Response[v1.MapResponse], error)
+	EchoRepeated(context.Context, *connect.Request[v1.RepeatedRequest]) (*connect.Response[v1.RepeatedResponse], error)
+	EchoScalars(context.Context, *connect.Request[v1.ScalarsRequest]) (*connect.Response[v1.ScalarsResponse], error)
+	// Build information RPC
+	Version(context.Context, *connect.Request[v1.VersionRequest]) (*connect.Response[v1.VersionResponse], error)
+	// Address family RPC
+	Network(context.Context, *connect.Request[v1.NetworkRequest]) (*connect.Response[v1.NetworkResponse], error)
+	// Call credentials RPC (echo-grpc)
+	Credentials(context.Context, *connect.Request[v1.CredentialsRequest]) (*connect.Response[v1.CredentialsResponse], error)
+	// HTTP/2 settings RPC (echo-grpc)
+	Http2Settings(context.Context, *connect.Request[v1.Http2SettingsRequest]) (*connect.Response[v1.Http2SettingsResponse], error)
+	// Server clock RPC (echo-grpc)
+	ServerTime(context.Context, *connect.Request[v1.ServerTimeRequest]) (*connect.Response[v1.ServerTimeResponse], error)
+	// Load test RPC (echo-grpc)
+	BurnResources(context.Context, *connect.Request[v1.BurnResourcesRequest]) (*connect.Response[v1.BurnResourcesResponse], error)
+}
+
+// NewEchoClient constructs a client for the echo.v1.Echo service. By default, it uses the Connect
+// protocol with the binary Protobuf Codec, asks for gzipped responses, and sends uncompressed
+// requests. To use the gRPC or gRPC-Web protocols, supply the connect.WithGRPC() or
+// connect.WithGRPCWeb() options.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewEchoClient(httpClient connect.HTTPClient, baseURL string, opts ...connect.ClientOption) EchoClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	echoMethods := v1.File_echo_v1_echo_proto.Services().ByName("Echo").Methods()
+	return &echoClient{
+		echo: connect.NewClient[v1.EchoRequest, v1.EchoResponse](
+			httpClient,
+			baseURL+EchoEchoProcedure,
+			connect.WithSchema(echoMethods.ByName("Echo")),
+			connect.WithIdempotency(connect.IdempotencyNoSideEffects),
+			connect.WithClientOptions(opts...),
+		),
+		echoWithDelay: connect.NewClient[v1.EchoWithDelayRequest, v1.EchoResponse](
+			httpClient,
+			baseURL+EchoEchoWithDelayProcedure,
+			connect.WithSchema(echoMethods.ByName("EchoWithDelay")),
+			connect.WithClientOptions(opts...),
+		),
+		echoError: connect.NewClient[v1.EchoErrorRequest, v1.EchoResponse](
+			httpClient,
+			baseURL+EchoEchoErrorProcedure,
+			connect.WithSchema(echoMethods.ByName("EchoError")),
+			connect.WithClientOptions(opts...),
+		),
+		echoRequestMetadata: connect.NewClient[v1.EchoRequestMetadataRequest, v1.EchoRequestMetadataResponse](
+			httpClient,
+			baseURL+EchoEchoRequestMetadataProcedure,
+			connect.WithSchema(echoMethods.ByName("EchoRequestMetadata")),
+			connect.WithIdempotency(connect.IdempotencyNoSideEffects),
+			connect.WithClientOptions(opts...),
+		),
+		echoWithTrailers: connect.NewClient[v1.EchoWithTrailersRequest, v1.EchoResponse](
+			httpClient,
+			baseURL+EchoEchoWithTrailersProcedure,
+			connect.WithSchema(echoMethods.ByName("EchoWithTrailers")),
+			connect.WithClientOptions(opts...),
+		),
+		echoLargePayload: connect.NewClient[v1.EchoLargePayloadRequest, v1.EchoLargePayloadResponse](
+			httpClient,
+			baseURL+EchoEchoLargePayloadProcedure,
+			connect.WithSchema(echoMethods.ByName("EchoLargePayload")),
+			connect.WithIdempotency(connect.IdempotencyNoSideEffects),
+			connect.WithClientOptions(opts...),
+		),
+		echoDeadline: connect.NewClient[v1.EchoDeadlineRequest, v1.EchoDeadlineResponse](
+			httpClient,
+			baseURL+EchoEchoDeadlineProcedure,
+			connect.WithSchema(echoMethods.ByName("EchoDeadline")),
+			connect.WithClientOptions(opts...),
+		),
+		echoErrorWithDetails: connect.NewClient[v1.EchoErrorWithDetailsRequest, v1.EchoResponse](
+			httpClient,
+			baseURL+EchoEchoErrorWithDetailsProcedure,
+			connect.WithSchema(echoMethods.ByName("EchoErrorWithDetails")),
+			connect.WithClientOptions(opts...),
+		),
+		serverStream: connect.NewClient[v1.ServerStreamRequest, v1.EchoResponse](
+			httpClient,
+			baseURL+EchoServerStreamProcedure,
+			connect.WithSchema(echoMethods.ByName("ServerStream")),
+			connect.WithClientOptions(opts...),
+		),
+		clientStream: connect.NewClient[v1.EchoRequest, v1.EchoResponse](
+			httpClient,
+			baseURL+EchoClientStreamProcedure,
+			connect.WithSchema(echoMethods.ByName("ClientStream")),
+			connect.WithClientOptions(opts...),
+		),
+		bidirectionalStream: connect.NewClient[v1.EchoRequest, v1.EchoResponse](
+			httpClient,
+			baseURL+EchoBidirectionalStreamProcedure,
+			connect.WithSchema(echoMethods.ByName("BidirectionalStream")),
+			connect.WithClientOptions(opts...),
+		),
+		serverStreamResumable: connect.NewClient[v1.ServerStreamResumableRequest, v1.ResumableStreamResponse](
+			httpClient,
+			baseURL+EchoServerStreamResumableProcedure,
+			connect.WithSchema(echoMethods.ByName("ServerStreamResumable")),
+			connect.WithClientOptions(opts...),
+		),
+		bidirectionalStreamWithHeartbeat: connect.NewClient[v1.HeartbeatStreamRequest, v1.HeartbeatStreamResponse](
+			httpClient,
+			baseURL+EchoBidirectionalStreamWithHeartbeatProcedure,
+			connect.WithSchema(echoMethods.ByName("BidirectionalStreamWithHeartbeat")),
+			connect.WithClientOptions(opts...),
+		),
+		echoStructured: connect.NewClient[v1.StructuredRequest, v1.StructuredResponse](
+			httpClient,
+			baseURL+EchoEchoStructuredProcedure,
+			connect.WithSchema(echoMethods.ByName("EchoStructured")),
+			connect.WithIdempotency(connect.IdempotencyNoSideEffects),
+			connect.WithClientOptions(opts...),
+		),
+		echoMap: connect.NewClient[v1.MapRequest, v1.MapResponse](
+			httpClient,
+			baseURL+EchoEchoMapProcedure,
+			connect.WithSchema(echoMethods.ByName("EchoMap")),
+			connect.WithClientOptions(opts...),
+		),
+		echoRepeated: connect.NewClient[v1.RepeatedRequest, v1.RepeatedResponse](
+			httpClient,
+			baseURL+EchoEchoRepeatedProcedure,
+			connect.WithSchema(echoMethods.ByName("EchoRepeated")),
+			connect.WithClientOptions(opts...),
+		),
+		echoScalars: connect.NewClient[v1.ScalarsRequest, v1.ScalarsResponse](
+			httpClient,
+			baseURL+EchoEchoScalarsProcedure,
+			connect.WithSchema(echoMethods.ByName("EchoScalars")),
+			connect.WithClientOptions(opts...),
+		),
+		version: connect.NewClient[v1.VersionRequest, v1.VersionResponse](
+			httpClient,
+			baseURL+EchoVersionProcedure,
+			connect.WithSchema(echoMethods.ByName("Version")),
+			connect.WithIdempotency(connect.IdempotencyNoSideEffects),
+			connect.WithClientOptions(opts...),
+		),
+		network: connect.NewClient[v1.NetworkRequest, v1.NetworkResponse](
+			httpClient,
+			baseURL+EchoNetworkProcedure,
+			connect.WithSchema(echoMethods.ByName("Network")),
+			connect.WithClientOptions(opts...),
+		),
+		credentials: connect.NewClient[v1.CredentialsRequest, v1.CredentialsResponse](
+			httpClient,
+			baseURL+EchoCredentialsProcedure,
+			connect.WithSchema(echoMethods.ByName("Credentials")),
+			connect.WithClientOptions(opts...),
+		),
+		http2Settings: connect.NewClient[v1.Http2SettingsRequest, v1.Http2SettingsResponse](
+			httpClient,
+			baseURL+EchoHttp2SettingsProcedure,
+			connect.WithSchema(echoMethods.ByName("Http2Settings")),
+			connect.WithClientOptions(opts...),
+		),
+		serverTime: connect.NewClient[v1.ServerTimeRequest, v1.ServerTimeResponse](
+			httpClient,
+			baseURL+EchoServerTimeProcedure,
+			connect.WithSchema(echoMethods.ByName("ServerTime")),
+			connect.WithClientOptions(opts...),
+		),
+		burnResources: connect.NewClient[v1.BurnResourcesRequest, v1.BurnResourcesResponse](
+			httpClient,
+			baseURL+EchoBurnResourcesProcedure,
+			connect.WithSchema(echoMethods.ByName("BurnResources")),
+			connect.WithClientOptions(opts...),
+		),
+	}
+}
+
+// echoClient implements EchoClient.
+type echoClient struct {
+	echo                             *connect.Client[v1.EchoRequest, v1.EchoResponse]
+	echoWithDelay                    *connect.Client[v1.EchoWithDelayRequest, v1.EchoResponse]
+	echoError                        *connect.Client[v1.EchoErrorRequest, v1.EchoResponse]
+	echoRequestMetadata              *connect.Client[v1.EchoRequestMetadataRequest, v1.EchoRequestMetadataResponse]
+	echoWithTrailers                 *connect.Client[v1.EchoWithTrailersRequest, v1.EchoResponse]
+	echoLargePayload                 *connect.Client[v1.EchoLargePayloadRequest, v1.EchoLargePayloadResponse]
+	echoDeadline                     *connect.Client[v1.EchoDeadlineRequest, v1.EchoDeadlineResponse]
+	echoErrorWithDetails             *connect.Client[v1.EchoErrorWithDetailsRequest, v1.EchoResponse]
+	serverStream                     *connect.Client[v1.ServerStreamRequest, v1.EchoResponse]
+	clientStream                     *connect.Client[v1.EchoRequest, v1.EchoResponse]
+	bidirectionalStream              *connect.Client[v1.EchoRequest, v1.EchoResponse]
+	serverStreamResumable            *connect.Client[v1.ServerStreamResumableRequest, v1.ResumableStreamResponse]
+	bidirectionalStreamWithHeartbeat *connect.Client[v1.HeartbeatStreamRequest, v1.HeartbeatStreamResponse]
+	echoStructured                   *connect.Client[v1.StructuredRequest, v1.StructuredResponse]
+	echoMap                          *connect.Client[v1.MapRequest, v1.MapResponse]
+	echoRepeated                     *connect.Client[v1.RepeatedRequest, v1.RepeatedResponse]
+	echoScalars                      *connect.Client[v1.ScalarsRequest, v1.ScalarsResponse]
+	version                          *connect.Client[v1.VersionRequest, v1.VersionResponse]
+	network                          *connect.Client[v1.NetworkRequest, v1.NetworkResponse]
+	credentials                      *connect.Client[v1.CredentialsRequest, v1.CredentialsResponse]
+	http2Settings                    *connect.Client[v1.Http2SettingsRequest, v1.Http2SettingsResponse]
+	serverTime                       *connect.Client[v1.ServerTimeRequest, v1.ServerTimeResponse]
+	burnResources                    *connect.Client[v1.BurnResourcesRequest, v1.BurnResourcesResponse]
+}
+
+// Echo calls echo.v1.Echo.Echo.
+func (c *echoClient) Echo(ctx context.Context, req *connect.Request[v1.EchoRequest]) (*connect.Response[v1.EchoResponse], error) {
+	return c.echo.CallUnary(ctx, req)
+}
+
+// EchoWithDelay calls echo.v1.Echo.EchoWithDelay.
+func (c *echoClient) EchoWithDelay(ctx context.Context, req *connect.Request[v1.EchoWithDelayRequest]) (*connect.Response[v1.EchoResponse], error) {
+	return c.echoWithDelay.CallUnary(ctx, req)
+}
+
+// EchoError calls echo.v1.Echo.EchoError.
+func (c *echoClient) EchoError(ctx context.Context, req *connect.Request[v1.EchoErrorRequest]) (*connect.Response[v1.EchoResponse], error) {
+	return c.echoError.CallUnary(ctx, req)
+}
+
+// EchoRequestMetadata calls echo.v1.Echo.EchoRequestMetadata.
+func (c *echoClient) EchoRequestMetadata(ctx context.Context, req *connect.Request[v1.EchoRequestMetadataRequest]) (*connect.Response[v1.EchoRequestMetadataResponse], error) {
+	return c.echoRequestMetadata.CallUnary(ctx, req)
+}
+
+// EchoWithTrailers calls echo.v1.Echo.EchoWithTrailers.
+func (c *echoClient) EchoWithTrailers(ctx context.Context, req *connect.Request[v1.EchoWithTrailersRequest]) (*connect.Response[v1.EchoResponse], error) {
+	return c.echoWithTrailers.CallUnary(ctx, req)
+}
+
+// EchoLargePayload calls echo.v1.Echo.EchoLargePayload.
+func (c *echoClient) EchoLargePayload(ctx context.Context, req *connect.Request[v1.EchoLargePayloadRequest]) (*connect.Response[v1.EchoLargePayloadResponse], error) {
+	return c.echoLargePayload.CallUnary(ctx, req)
+}
+
+// EchoDeadline calls echo.v1.Echo.EchoDeadline.
+func (c *echoClient) EchoDeadline(ctx context.Context, req *connect.Request[v1.EchoDeadlineRequest]) (*connect.Response[v1.EchoDeadlineResponse], error) {
+	return c.echoDeadline.CallUnary(ctx, req)
+}
+
+// EchoErrorWithDetails calls echo.v1.Echo.EchoErrorWithDetails.
+func (c *echoClient) EchoErrorWithDetails(ctx context.Context, req *connect.Request[v1.EchoErrorWithDetailsRequest]) (*connect.Response[v1.EchoResponse], error) {
+	return c.echoErrorWithDetails.CallUnary(ctx, req)
+}
+
+// ServerStream calls echo.v1.Echo.ServerStream.
+func (c *echoClient) ServerStream(ctx context.Context, req *connect.Request[v1.ServerStreamRequest]) (*connect.ServerStreamForClient[v1.EchoResponse], error) {
+	return c.serverStream.CallServerStream(ctx, req)
+}
+
+// ClientStream calls echo.v1.Echo.ClientStream.
+func (c *echoClient) ClientStream(ctx context.Context) *connect.ClientStreamForClient[v1.EchoRequest, v1.EchoResponse] {
+	return c.clientStream.CallClientStream(ctx)
+}
+
+// BidirectionalStream calls echo.v1.Echo.BidirectionalStream.
+func (c *echoClient) BidirectionalStream(ctx context.Context) *connect.BidiStreamForClient[v1.EchoRequest, v1.EchoResponse] {
+	return c.bidirectionalStream.CallBidiStream(ctx)
+}
+
+// ServerStreamResumable calls echo.v1.Echo.ServerStreamResumable.
+func (c *echoClient) ServerStreamResumable(ctx context.Context, req *connect.Request[v1.ServerStreamResumableRequest]) (*connect.ServerStreamForClient[v1.ResumableStreamResponse], error) {
+	return c.serverStreamResumable.CallServerStream(ctx, req)
+}
+
+// BidirectionalStreamWithHeartbeat calls echo.v1.Echo.BidirectionalStreamWithHeartbeat.
+func (c *echoClient) BidirectionalStreamWithHeartbeat(ctx context.Context) *connect.BidiStreamForClient[v1.HeartbeatStreamRequest, v1.HeartbeatStreamResponse] {
+	return c.bidirectionalStreamWithHeartbeat.CallBidiStream(ctx)
+}
+
+// EchoStructured calls echo.v1.Echo.EchoStructured.
+func (c *echoClient) EchoStructured(ctx context.Context, req *connect.Request[v1.StructuredRequest]) (*connect.Response[v1.StructuredResponse], error) {
+	return c.echoStructured.CallUnary(ctx, req)
+}
+
+// EchoMap calls echo.v1.Echo.EchoMap.
+func (c *echoClient) EchoMap(ctx context.Context, req *connect.Request[v1.MapRequest]) (*connect.Response[v1.MapResponse], error) {
+	return c.echoMap.CallUnary(ctx, req)
+}
+
+// EchoRepeated calls echo.v1.Echo.EchoRepeated.
+func (c *echoClient) EchoRepeated(ctx context.Context, req *connect.Request[v1.RepeatedRequest]) (*connect.Response[v1.RepeatedResponse], error) {
+	return c.echoRepeated.CallUnary(ctx, req)
+}
+
+// EchoScalars calls echo.v1.Echo.EchoScalars.
+func (c *echoClient) EchoScalars(ctx context.Context, req *connect.Request[v1.ScalarsRequest]) (*connect.Response[v1.ScalarsResponse], error) {
+	return c.echoScalars.CallUnary(ctx, req)
+}
+
+// Version calls echo.v1.Echo.Version.
+func (c *echoClient) Version(ctx context.Context, req *connect.Request[v1.VersionRequest]) (*connect.Response[v1.VersionResponse], error) {
+	return c.version.CallUnary(ctx, req)
+}
+
+// Network calls echo.v1.Echo.Network.
+func (c *echoClient) Network(ctx context.Context, req *connect.Request[v1.NetworkRequest]) (*connect.Response[v1.NetworkResponse], error) {
+	return c.network.CallUnary(ctx, req)
+}
+
+// Credentials calls echo.v1.Echo.Credentials.
+func (c *echoClient) Credentials(ctx context.Context, req *connect.Request[v1.CredentialsRequest]) (*connect.Response[v1.CredentialsResponse], error) {
+	return c.credentials.CallUnary(ctx, req)
+}
+
+// Http2Settings calls echo.v1.Echo.Http2Settings.
+func (c *echoClient) Http2Settings(ctx context.Context, req *connect.Request[v1.Http2SettingsRequest]) (*connect.Response[v1.Http2SettingsResponse], error) {
+	return c.http2Settings.CallUnary(ctx, req)
+}
+
+// ServerTime calls echo.v1.Echo.ServerTime.
+func (c *echoClient) ServerTime(ctx context.Context, req *connect.Request[v1.ServerTimeRequest]) (*connect.Response[v1.ServerTimeResponse], error) {
+	return c.serverTime.CallUnary(ctx, req)
+}
+
+// BurnResources calls echo.v1.Echo.BurnResources.
+func (c *echoClient) BurnResources(ctx context.Context, req *connect.Request[v1.BurnResourcesRequest]) (*connect.Response[v1.BurnResourcesResponse], error) {
+	return c.burnResources.CallUnary(ctx, req)
+}
+
+// EchoHandler is an implementation of the echo.v1.Echo service.
+type EchoHandler interface {
+	// Unary RPCs
+	Echo(context.Context, *connect.Request[v1.EchoRequest]) (*connect.Response[v1.EchoResponse], error)
+	EchoWithDelay(context.Context, *connect.Request[v1.EchoWithDelayRequest]) (*connect.Response[v1.EchoResponse], error)
+	EchoError(context.Context, *connect.Request[v1.EchoErrorRequest]) (*connect.Response[v1.EchoResponse], error)
+	// Metadata/Headers RPCs
+	EchoRequestMetadata(context.Context, *connect.Request[v1.EchoRequestMetadataRequest]) (*connect.Response[v1.EchoRequestMetadataResponse], error)
+	EchoWithTrailers(context.Context, *connect.Request[v1.EchoWithTrailersRequest]) (*connect.Response[v1.EchoResponse], error)
+	// Payload Testing RPCs
+	EchoLargePayload(context.Context, *connect.Request[v1.EchoLargePayloadRequest]) (*connect.Response[v1.EchoLargePayloadResponse], error)
+	// Deadline/Timeout RPCs
+	EchoDeadline(context.Context, *connect.Request[v1.EchoDeadlineRequest]) (*connect.Response[v1.EchoDeadlineResponse], error)
+	// Error Scenarios RPCs
+	EchoErrorWithDetails(context.Context, *connect.Request[v1.EchoErrorWithDetailsRequest]) (*connect.Response[v1.EchoResponse], error)
+	// Streaming RPCs
+	ServerStream(context.Context, *connect.Request[v1.ServerStreamRequest], *connect.ServerStream[v1.EchoResponse]) error
+	ClientStream(context.Context, *connect.ClientStream[v1.EchoRequest]) (*connect.Response[v1.EchoResponse], error)
+	BidirectionalStream(context.Context, *connect.BidiStream[v1.EchoRequest, v1.EchoResponse]) error
+	// Resumable server streaming RPC (echo-grpc)
+	ServerStreamResumable(context.Context, *connect.Request[v1.ServerStreamResumableRequest], *connect.ServerStream[v1.ResumableStreamResponse]) error
+	// Heartbeat streaming RPC (echo-connectrpc)
+	BidirectionalStreamWithHeartbeat(context.Context, *connect.BidiStream[v1.HeartbeatStreamRequest, v1.HeartbeatStreamResponse]) error
+	// Structured message RPC
+	EchoStructured(context.Context, *connect.Request[v1.StructuredRequest]) (*connect.Response[v1.StructuredResponse], error)
+	// Serialization edge case RPCs (echo-grpc)
+	EchoMap(context.Context, *connect.Request[v1.MapRequest]) (*connect.Response[v1.MapResponse], error)
+	EchoRepeated(context.Context, *connect.Request[v1.RepeatedRequest]) (*connect.Response[v1.RepeatedResponse], error)
+	EchoScalars(context.Context, *connect.Request[v1.ScalarsRequest]) (*connect.Response[v1.ScalarsResponse], error)
+	// Build information RPC
+	Version(context.Context, *connect.Request[v1.VersionRequest]) (*connect.Response[v1.VersionResponse], error)
+	// Address family RPC
+	Network(context.Context, *connect.Request[v1.NetworkRequest]) (*connect.Response[v1.NetworkResponse], error)
+	// Call credentials RPC (echo-grpc)
+	Credentials(context.Context, *connect.Request[v1.CredentialsRequest]) (*connect.Response[v1.CredentialsResponse], error)
+	// HTTP/2 settings RPC (echo-grpc)
+	Http2Settings(context.Context, *connect.Request[v1.Http2SettingsRequest]) (*connect.Response[v1.Http2SettingsResponse], error)
+	// Server clock RPC (echo-grpc)
+	ServerTime(context.Context, *connect.Request[v1.ServerTimeRequest]) (*connect.Response[v1.ServerTimeResponse], error)
+	// Load test RPC (echo-grpc)
+	BurnResources(context.Context, *connect.Request[v1.BurnResourcesRequest]) (*connect.Response[v1.BurnResourcesResponse], error)
+}
+
+// NewEchoHandler builds an HTTP handler from the service implementation. It returns the path on
+// which to mount the handler and the handler itself.
+//
+// By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
+// and JSON codecs. They also support gzip compression.
+func NewEchoHandler(svc EchoHandler, opts ...connect.HandlerOption) (string, http.Handler) {
+	echoMethods := v1.File_echo_v1_echo_proto.Services().ByName("Echo").Methods()
+	echoEchoHandler := connect.NewUnaryHandler(
+		EchoEchoProcedure,
+		svc.Echo,
+		connect.WithSchema(echoMethods.ByName("Echo")),
+		connect.WithIdempotency(connect.IdempotencyNoSideEffects),
+		connect.WithHandlerOptions(opts...),
+	)
+	echoEchoWithDelayHandler := connect.NewUnaryHandler(
+		EchoEchoWithDelayProcedure,
+		svc.EchoWithDelay,
+		connect.WithSchema(echoMethods.ByName("EchoWithDelay")),
+		connect.WithHandlerOptions(opts...),
+	)
+	echoEchoErrorHandler := connect.NewUnaryHandler(
+		EchoEchoErrorProcedure,
+		svc.EchoError,
+		connect.WithSchema(echoMethods.ByName("EchoError")),
+		connect.WithHandlerOptions(opts...),
+	)
+	echoEchoRequestMetadataHandler := connect.NewUnaryHandler(
+		EchoEchoRequestMetadataProcedure,
+		svc.EchoRequestMetadata,
+		connect.WithSchema(echoMethods.ByName("EchoRequestMetadata")),
+		connect.WithIdempotency(connect.IdempotencyNoSideEffects),
+		connect.WithHandlerOptions(opts...),
+	)
+	echoEchoWithTrailersHandler := connect.NewUnaryHandler(
+		EchoEchoWithTrailersProcedure,
+		svc.EchoWithTrailers,
+		connect.WithSchema(echoMethods.ByName("EchoWithTrailers")),
+		connect.WithHandlerOptions(opts...),
+	)
+	echoEchoLargePayloadHandler := connect.NewUnaryHandler(
+		EchoEchoLargePayloadProcedure,
+		svc.EchoLargePayload,
+		connect.WithSchema(echoMethods.ByName("EchoLargePayload")),
+		connect.WithIdempotency(connect.IdempotencyNoSideEffects),
+		connect.WithHandlerOptions(opts...),
+	)
+	echoEchoDeadlineHandler := connect.NewUnaryHandler(
+		EchoEchoDeadlineProcedure,
+		svc.EchoDeadline,
+		connect.WithSchema(echoMethods.ByName("EchoDeadline")),
+		connect.WithHandlerOptions(opts...),
+	)
+	echoEchoErrorWithDetailsHandler := connect.NewUnaryHandler(
+		EchoEchoErrorWithDetailsProcedure,
+		svc.EchoErrorWithDetails,
+		connect.WithSchema(echoMethods.ByName("EchoErrorWithDetails")),
+		connect.WithHandlerOptions(opts...),
+	)
+	echoServerStreamHandler := connect.NewServerStreamHandler(
+		EchoServerStreamProcedure,
+		svc.ServerStream,
+		connect.WithSchema(echoMethods.ByName("ServerStream")),
+		connect.WithHandlerOptions(opts...),
+	)
+	echoClientStreamHandler := connect.NewClientStreamHandler(
+		EchoClientStreamProcedure,
+		svc.ClientStream,
+		connect.WithSchema(echoMethods.ByName("ClientStream")),
+		connect.WithHandlerOptions(opts...),
+	)
+	echoBidirectionalStreamHandler := connect.NewBidiStreamHandler(
+		EchoBidirectionalStreamProcedure,
+		svc.BidirectionalStream,
+		connect.WithSchema(echoMethods.ByName("BidirectionalStream")),
+		connect.WithHandlerOptions(opts...),
+	)
+	echoServerStreamResumableHandler := connect.NewServerStreamHandler(
+		EchoServerStreamResumableProcedure,
+		svc.ServerStreamResumable,
+		connect.WithSchema(echoMethods.ByName("ServerStreamResumable")),
+		connect.WithHandlerOptions(opts...),
+	)
+	echoBidirectionalStreamWithHeartbeatHandler := connect.NewBidiStreamHandler(
+		EchoBidirectionalStreamWithHeartbeatProcedure,
+		svc.BidirectionalStreamWithHeartbeat,
+		connect.WithSchema(echoMethods.ByName("BidirectionalStreamWithHeartbeat")),
+		connect.WithHandlerOptions(opts...),
+	)
+	echoEchoStructuredHandler := connect.NewUnaryHandler(
+		EchoEchoStructuredProcedure,
+		svc.EchoStructured,
+		connect.WithSchema(echoMethods.ByName("EchoStructured")),
+		connect.WithIdempotency(connect.IdempotencyNoSideEffects),
+		connect.WithHandlerOptions(opts...),
+	)
+	echoEchoMapHandler := connect.NewUnaryHandler(
+		EchoEchoMapProcedure,
+		svc.EchoMap,
+		connect.WithSchema(echoMethods.ByName("EchoMap")),
+		connect.WithHandlerOptions(opts...),
+	)
+	echoEchoRepeatedHandler := connect.NewUnaryHandler(
+		EchoEchoRepeatedProcedure,
+		svc.EchoRepeated,
+		connect.WithSchema(echoMethods.ByName("EchoRepeated")),
+		connect.WithHandlerOptions(opts...),
+	)
+	echoEchoScalarsHandler := connect.NewUnaryHandler(
+		EchoEchoScalarsProcedure,
+		svc.EchoScalars,
+		connect.WithSchema(echoMethods.ByName("EchoScalars")),
+		connect.WithHandlerOptions(opts...),
+	)
+	echoVersionHandler := connect.NewUnaryHandler(
+		EchoVersionProcedure,
+		svc.Version,
+		connect.WithSchema(echoMethods.ByName("Version")),
+		connect.WithIdempotency(connect.IdempotencyNoSideEffects),
+		connect.WithHandlerOptions(opts...),
+	)
+	echoNetworkHandler := connect.NewUnaryHandler(
+		EchoNetworkProcedure,
+		svc.Network,
+		connect.WithSchema(echoMethods.ByName("Network")),
+		connect.WithHandlerOptions(opts...),
+	)
+	echoCredentialsHandler := connect.NewUnaryHandler(
+		EchoCredentialsProcedure,
+		svc.Credentials,
+		connect.WithSchema(echoMethods.ByName("Credentials")),
+		connect.WithHandlerOptions(opts...),
+	)
+	echoHttp2SettingsHandler := connect.NewUnaryHandler(
+		EchoHttp2SettingsProcedure,
+		svc.Http2Settings,
+		connect.WithSchema(echoMethods.ByName("Http2Settings")),
+		connect.WithHandlerOptions(opts...),
+	)
+	echoServerTimeHandler := connect.NewUnaryHandler(
+		EchoServerTimeProcedure,
+		svc.ServerTime,
+		connect.WithSchema(echoMethods.ByName("ServerTime")),
+		connect.WithHandlerOptions(opts...),
+	)
+	echoBurnResourcesHandler := connect.NewUnaryHandler(
+		EchoBurnResourcesProcedure,
+		svc.BurnResources,
+		connect.WithSchema(echoMethods.ByName("BurnResources")),
+		connect.WithHandlerOptions(opts...),
+	)
+	return "/echo.v1.Echo/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case EchoEchoProcedure:
+			echoEchoHandler.ServeHTTP(w, r)
+		case EchoEchoWithDelayProcedure:
+			echoEchoWithDelayHandler.ServeHTTP(w, r)
+		case EchoEchoErrorProcedure:
+			echoEchoErrorHandler.ServeHTTP(w, r)
+		case EchoEchoRequestMetadataProcedure:
+			echoEchoRequestMetadataHandler.ServeHTTP(w, r)
+		case EchoEchoWithTrailersProcedure:
+			echoEchoWithTrailersHandler.ServeHTTP(w, r)
+		case EchoEchoLargePayloadProcedure:
+			echoEchoLargePayloadHandler.ServeHTTP(w, r)
+		case EchoEchoDeadlineProcedure:
+			echoEchoDeadlineHandler.ServeHTTP(w, r)
+		case EchoEchoErrorWithDetailsProcedure:
+			echoEchoErrorWithDetailsHandler.ServeHTTP(w, r)
+		case EchoServerStreamProcedure:
+			echoServerStreamHandler.ServeHTTP(w, r)
+		case EchoClientStreamProcedure:
+			echoClientStreamHandler.ServeHTTP(w, r)
+		case EchoBidirectionalStreamProcedure:
+			echoBidirectionalStreamHandler.ServeHTTP(w, r)
+		case EchoServerStreamResumableProcedure:
+			echoServerStreamResumableHandler.ServeHTTP(w, r)
+		case EchoBidirectionalStreamWithHeartbeatProcedure:
+			echoBidirectionalStreamWithHeartbeatHandler.ServeHTTP(w, r)
+		case EchoEchoStructuredProcedure:
+			echoEchoStructuredHandler.ServeHTTP(w, r)
+		case EchoEchoMapProcedure:
+			echoEchoMapHandler.ServeHTTP(w, r)
+		case EchoEchoRepeatedProcedure:
+			echoEchoRepeatedHandler.ServeHTTP(w, r)
+		case EchoEchoScalarsProcedure:
+			echoEchoScalarsHandler.ServeHTTP(w, r)
+		case EchoVersionProcedure:
+			echoVersionHandler.ServeHTTP(w, r)
+		case EchoNetworkProcedure:
+			echoNetworkHandler.ServeHTTP(w, r)
+		case EchoCredentialsProcedure:
+			echoCredentialsHandler.ServeHTTP(w, r)
+		case EchoHttp2SettingsProcedure:
+			echoHttp2SettingsHandler.ServeHTTP(w, r)
+		case EchoServerTimeProcedure:
+			echoServerTimeHandler.ServeHTTP(w, r)
+		case EchoBurnResourcesProcedure:
+			echoBurnResourcesHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedEchoHandler returns CodeUnimplemented from all methods.
+type UnimplementedEchoHandler struct{}
+
+func (UnimplementedEchoHandler) Echo(context.Context, *connect.Request[v1.EchoRequest]) (*connect.Response[v1.EchoResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("echo.v1.Echo.Echo is not implemented"))
+}
+
+func (UnimplementedEchoHandler) EchoWithDelay(context.Context, *connect.Request[v1.EchoWithDelayRequest]) (*connect.Response[v1.EchoResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("echo.v1.Echo.EchoWithDelay is not implemented"))
+}
+
+func (UnimplementedEchoHandler) EchoError(context.Context, *connect.Request[v1.EchoErrorRequest]) (*connect.Response[v1.EchoResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("echo.v1.Echo.EchoError is not implemented"))
+}
+
+func (UnimplementedEchoHandler) EchoRequestMetadata(context.Context, *connect.Request[v1.EchoRequestMetadataRequest]) (*connect.Response[v1.EchoRequestMetadataResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("echo.v1.Echo.EchoRequestMetadata is not implemented"))
+}
+
+func (UnimplementedEchoHandler) EchoWithTrailers(context.Context, *connect.Request[v1.EchoWithTrailersRequest]) (*connect.Response[v1.EchoResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("echo.v1.Echo.EchoWithTrailers is not implemented"))
+}
+
+func (UnimplementedEchoHandler) EchoLargePayload(context.Context, *connect.Request[v1.EchoLargePayloadRequest]) (*connect.Response[v1.EchoLargePayloadResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("echo.v1.Echo.EchoLargePayload is not implemented"))
+}
+
+func (UnimplementedEchoHandler) EchoDeadline(context.Context, *connect.Request[v1.EchoDeadlineRequest]) (*connect.Response[v1.EchoDeadlineResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("echo.v1.Echo.EchoDeadline is not implemented"))
+}
+
+func (UnimplementedEchoHandler) EchoErrorWithDetails(context.Context, *connect.Request[v1.EchoErrorWithDetailsRequest]) (*connect.Response[v1.EchoResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("echo.v1.Echo.EchoErrorWithDetails is not implemented"))
+}
+
+func (UnimplementedEchoHandler) ServerStream(context.Context, *connect.Request[v1.ServerStreamRequest], *connect.ServerStream[v1.EchoResponse]) error {
+	return connect.NewError(connect.CodeUnimplemented, errors.New("echo.v1.Echo.ServerStream is not implemented"))
+}
+
+func (UnimplementedEchoHandler) ClientStream(context.Context, *connect.ClientStream[v1.EchoRequest]) (*connect.Response[v1.EchoResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("echo.v1.Echo.ClientStream is not implemented"))
+}
+
+func (UnimplementedEchoHandler) BidirectionalStream(context.Context, *connect.BidiStream[v1.EchoRequest, v1.EchoResponse]) error {
+	return connect.NewError(connect.CodeUnimplemented, errors.New("echo.v1.Echo.BidirectionalStream is not implemented"))
+}
+
+func (UnimplementedEchoHandler) ServerStreamResumable(context.Context, *connect.Request[v1.ServerStreamResumableRequest], *connect.ServerStream[v1.ResumableStreamResponse]) error {
+	return connect.NewError(connect.CodeUnimplemented, errors.New("echo.v1.Echo.ServerStreamResumable is not implemented"))
+}
+
+func (UnimplementedEchoHandler) BidirectionalStreamWithHeartbeat(context.Context, *connect.BidiStream[v1.HeartbeatStreamRequest, v1.HeartbeatStreamResponse]) error {
+	return connect.NewError(connect.CodeUnimplemented, errors.New("echo.v1.Echo.BidirectionalStreamWithHeartbeat is not implemented"))
+}
+
+func (UnimplementedEchoHandler) EchoStructured(context.Context, *connect.Request[v1.StructuredRequest]) (*connect.Response[v1.StructuredResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("echo.v1.Echo.EchoStructured is not implemented"))
+}
+
+func (UnimplementedEchoHandler) EchoMap(context.Context, *connect.Request[v1.MapRequest]) (*connect.Response[v1.MapResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("echo.v1.Echo.EchoMap is not implemented"))
+}
+
+func (UnimplementedEchoHandler) EchoRepeated(context.Context, *connect.Request[v1.RepeatedRequest]) (*connect.Response[v1.RepeatedResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("echo.v1.Echo.EchoRepeated is not implemented"))
+}
+
+func (UnimplementedEchoHandler) EchoScalars(context.Context, *connect.Request[v1.ScalarsRequest]) (*connect.Response[v1.ScalarsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("echo.v1.Echo.EchoScalars is not implemented"))
+}
+
+func (UnimplementedEchoHandler) Version(context.Context, *connect.Request[v1.VersionRequest]) (*connect.Response[v1.VersionResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("echo.v1.Echo.Version is not implemented"))
+}
+
+func (UnimplementedEchoHandler) Network(context.Context, *connect.Request[v1.NetworkRequest]) (*connect.Response[v1.NetworkResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("echo.v1.Echo.Network is not implemented"))
+}
+
+func (UnimplementedEchoHandler) Credentials(context.Context, *connect.Request[v1.CredentialsRequest]) (*connect.Response[v1.CredentialsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("echo.v1.Echo.Credentials is not implemented"))
+}
+
+func (UnimplementedEchoHandler) Http2Settings(context.Context, *connect.Request[v1.Http2SettingsRequest]) (*connect.Response[v1.Http2SettingsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("echo.v1.Echo.Http2Settings is not implemented"))
+}
+
+func (UnimplementedEchoHandler) ServerTime(context.Context, *connect.Request[v1.ServerTimeRequest]) (*connect.Response[v1.ServerTimeResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("echo.v1.Echo.ServerTime is not implemented"))
+}
+
+func (UnimplementedEchoHandler) BurnResources(context.Context, *connect.Request[v1.BurnResourcesRequest]) (*connect.Response[v1.BurnResourcesResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("echo.v1.Echo.BurnResources is not implemented"))
+}
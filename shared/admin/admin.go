@@ -0,0 +1,287 @@
+// Package admin serves the admin API of a server on a separate port, so
+// test orchestrators can reconfigure it between test cases without
+// restarts: toggle its health, delay its responses, flip its feature
+// flags, reset its stores and dump its state.
+//
+// A server creates one Admin, registers its flags, stores and state with
+// it, and consults it where it answers health checks (HealthHandler,
+// OnHealth) and handles requests (HTTP, Wait).
+package admin
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/probitas-test/echo-servers/shared/config"
+)
+
+// Config enables the admin API and sets its port
+type Config struct {
+	Enabled bool
+	Port    string
+
+	// Debug serves pprof, expvar and goroutine dumps on the admin port
+	Debug bool
+}
+
+// LoadConfig reads the ADMIN_* settings from src
+func LoadConfig(src *config.Source) Config {
+	return Config{
+		Enabled: src.Bool("ADMIN_ENABLED", true),
+		Port:    src.String("ADMIN_PORT", "9091"),
+		Debug:   src.Bool("ADMIN_DEBUG_ENABLED", false),
+	}
+}
+
+// Admin holds the runtime state of one server changed through the admin
+// API
+type Admin struct {
+	server  string
+	src     *config.Source
+	healthy atomic.Bool
+	ready   atomic.Bool
+	delay   atomic.Int64
+
+	mu       sync.Mutex
+	onHealth []func(healthy bool)
+	flags    map[string]*Flag
+	stores   map[string]func()
+	dumps    map[string]func() any
+
+	// mux of the admin port
+	mux *http.ServeMux
+}
+
+// New creates the admin state of server, healthy and ready without delay.
+// src is dumped with the state, as served at /config.
+func New(server string, src *config.Source) *Admin {
+	a := &Admin{
+		server: server,
+		src:    src,
+		flags:  make(map[string]*Flag),
+		stores: make(map[string]func()),
+		dumps:  make(map[string]func() any),
+	}
+	a.healthy.Store(true)
+	a.ready.Store(true)
+	a.mux = http.NewServeMux()
+	a.routes()
+	return a
+}
+
+// Healthy reports whether the server reports itself healthy: set healthy
+// through the admin API and ready
+func (a *Admin) Healthy() bool {
+	return a.healthy.Load() && a.ready.Load()
+}
+
+// SetHealthy changes the health set through the admin API and notifies the
+// OnHealth functions
+func (a *Admin) SetHealthy(healthy bool) {
+	a.healthy.Store(healthy)
+	a.notifyHealth()
+}
+
+// Ready reports whether the server is past its readiness delay
+func (a *Admin) Ready() bool {
+	return a.ready.Load()
+}
+
+// SetReady changes the readiness of the server, which reports itself
+// unhealthy until ready, and notifies the OnHealth functions. Reset keeps
+// the readiness.
+func (a *Admin) SetReady(ready bool) {
+	a.ready.Store(ready)
+	a.notifyHealth()
+}
+
+func (a *Admin) notifyHealth() {
+	healthy := a.Healthy()
+	a.mu.Lock()
+	fns := slices.Clone(a.onHealth)
+	a.mu.Unlock()
+	for _, fn := range fns {
+		fn(healthy)
+	}
+}
+
+// OnHealth calls fn with every change of Healthy, for health services that
+// keep their own status such as gRPC health
+func (a *Admin) OnHealth(fn func(healthy bool)) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.onHealth = append(a.onHealth, fn)
+}
+
+// Delay returns the delay added to every response
+func (a *Admin) Delay() time.Duration {
+	return time.Duration(a.delay.Load())
+}
+
+// SetDelay changes the delay added to every response
+func (a *Admin) SetDelay(d time.Duration) {
+	a.delay.Store(int64(max(d, 0)))
+}
+
+// Wait waits for the delay, returning early with the error of ctx
+func (a *Admin) Wait(ctx context.Context) error {
+	d := a.Delay()
+	if d <= 0 {
+		return nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Flag is a boolean feature flag flipped through the admin API
+type Flag struct {
+	enabled     atomic.Bool
+	initial     bool
+	description string
+}
+
+// Enabled reports whether the flag is on
+func (f *Flag) Enabled() bool {
+	return f.enabled.Load()
+}
+
+// Set turns the flag on or off
+func (f *Flag) Set(enabled bool) {
+	f.enabled.Store(enabled)
+}
+
+// Flag registers the feature flag name, initially enabled (usually its
+// configured value), which the server reads on every use
+func (a *Admin) Flag(name string, enabled bool, description string) *Flag {
+	f := &Flag{initial: enabled, description: description}
+	f.enabled.Store(enabled)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, ok := a.flags[name]; ok {
+		panic(fmt.Sprintf("admin: flag %q registered twice", name))
+	}
+	a.flags[name] = f
+	return f
+}
+
+// Store registers the store name, emptied by reset
+func (a *Admin) Store(name string, reset func()) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, ok := a.stores[name]; ok {
+		panic(fmt.Sprintf("admin: store %q registered twice", name))
+	}
+	a.stores[name] = reset
+}
+
+// State registers the state section name, dumped by calling dump on every
+// state request. dump must return a JSON-encodable value.
+func (a *Admin) State(name string, dump func() any) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, ok := a.dumps[name]; ok {
+		panic(fmt.Sprintf("admin: state %q registered twice", name))
+	}
+	a.dumps[name] = dump
+}
+
+// ResetStore empties the store name, reporting whether it exists
+func (a *Admin) ResetStore(name string) bool {
+	a.mu.Lock()
+	reset, ok := a.stores[name]
+	a.mu.Unlock()
+	if ok {
+		reset()
+	}
+	return ok
+}
+
+// ResetStores empties every store
+func (a *Admin) ResetStores() {
+	a.mu.Lock()
+	stores := slices.Collect(maps.Values(a.stores))
+	a.mu.Unlock()
+	for _, reset := range stores {
+		reset()
+	}
+}
+
+// Reset restores the startup state: healthy, no delay, every flag at its
+// initial value and every store empty
+func (a *Admin) Reset() {
+	a.SetHealthy(true)
+	a.SetDelay(0)
+	a.mu.Lock()
+	flags := slices.Collect(maps.Values(a.flags))
+	a.mu.Unlock()
+	for _, f := range flags {
+		f.Set(f.initial)
+	}
+	a.ResetStores()
+}
+
+// FlagState is a feature flag in the admin API
+type FlagState struct {
+	Enabled     bool   `json:"enabled"`
+	Description string `json:"description,omitempty"`
+}
+
+// State is the state dump of the admin API
+type State struct {
+	Server  string                  `json:"server"`
+	Healthy bool                    `json:"healthy"`
+	Ready   bool                    `json:"ready"`
+	DelayMS int64                   `json:"delay_ms"`
+	Flags   map[string]FlagState    `json:"flags"`
+	Stores  []string                `json:"stores"`
+	Config  map[string]config.Value `json:"config,omitempty"`
+	State   map[string]any          `json:"state,omitempty"`
+}
+
+// Dump returns the current state, including the registered sections
+func (a *Admin) Dump() State {
+	a.mu.Lock()
+	flags := maps.Clone(a.flags)
+	stores := slices.Sorted(maps.Keys(a.stores))
+	dumps := maps.Clone(a.dumps)
+	a.mu.Unlock()
+
+	s := State{
+		Server:  a.server,
+		Healthy: a.Healthy(),
+		Ready:   a.Ready(),
+		DelayMS: a.Delay().Milliseconds(),
+		Flags:   flagStates(flags),
+		Stores:  stores,
+	}
+	if a.src != nil {
+		s.Config = a.src.Values()
+	}
+	if len(dumps) > 0 {
+		s.State = make(map[string]any, len(dumps))
+		for name, dump := range dumps {
+			s.State[name] = dump()
+		}
+	}
+	return s
+}
+
+func flagStates(flags map[string]*Flag) map[string]FlagState {
+	states := make(map[string]FlagState, len(flags))
+	for name, f := range flags {
+		states[name] = FlagState{Enabled: f.Enabled(), Description: f.description}
+	}
+	return states
+}
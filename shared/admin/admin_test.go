@@ -0,0 +1,153 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func request(t *testing.T, h http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+	return rec
+}
+
+func TestHandler(t *testing.T) {
+	a := New("echo-test", nil)
+	var health []bool
+	a.OnHealth(func(healthy bool) { health = append(health, healthy) })
+	verbose := a.Flag("verbose", false, "Log every message")
+	messages := []string{"hello"}
+	a.Store("messages", func() { messages = nil })
+	a.State("messages", func() any { return len(messages) })
+	h := a.Handler()
+
+	tests := []struct {
+		method, path, body string
+		want               int
+	}{
+		{"PUT", "/health", `{"healthy":false}`, http.StatusOK},
+		{"PUT", "/health", `{}`, http.StatusBadRequest},
+		{"PUT", "/delay", `{"delay_ms":250}`, http.StatusOK},
+		{"PUT", "/delay", `{"delay_ms":-1}`, http.StatusBadRequest},
+		{"PUT", "/flags/verbose", `{"enabled":true}`, http.StatusOK},
+		{"PUT", "/flags/unknown", `{"enabled":true}`, http.StatusNotFound},
+		{"PUT", "/flags/verbose", `{"enabled":true,"extra":1}`, http.StatusBadRequest},
+		{"POST", "/stores/unknown/reset", ``, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		if rec := request(t, h, tt.method, tt.path, tt.body); rec.Code != tt.want {
+			t.Errorf("%s %s %s = %d, want %d: %s", tt.method, tt.path, tt.body, rec.Code, tt.want, rec.Body)
+		}
+	}
+
+	var s State
+	if err := json.Unmarshal(request(t, h, "GET", "/state", "").Body.Bytes(), &s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Server != "echo-test" || s.Healthy || s.DelayMS != 250 || !s.Flags["verbose"].Enabled ||
+		len(s.Stores) != 1 || s.State["messages"] != float64(1) {
+		t.Errorf("state = %+v", s)
+	}
+	if !verbose.Enabled() || len(health) != 1 || health[0] {
+		t.Errorf("verbose = %v, health changes = %v", verbose.Enabled(), health)
+	}
+
+	if rec := request(t, h, "POST", "/stores/messages/reset", ""); rec.Code != http.StatusNoContent || messages != nil {
+		t.Errorf("store reset = %d, messages %v", rec.Code, messages)
+	}
+
+	messages = []string{"hello"}
+	request(t, h, "POST", "/reset", "")
+	if !a.Healthy() || a.Delay() != 0 || verbose.Enabled() || messages != nil {
+		t.Errorf("after reset: healthy %v, delay %v, verbose %v, messages %v",
+			a.Healthy(), a.Delay(), verbose.Enabled(), messages)
+	}
+}
+
+func TestHealthHandler(t *testing.T) {
+	a := New("echo-test", nil)
+	h := a.HealthHandler()
+	if rec := request(t, h, "GET", "/health", ""); rec.Code != http.StatusOK {
+		t.Errorf("healthy = %d", rec.Code)
+	}
+	a.SetHealthy(false)
+	rec := request(t, h, "GET", "/health", "")
+	if rec.Code != http.StatusServiceUnavailable || !strings.Contains(rec.Body.String(), "unavailable") {
+		t.Errorf("unhealthy = %d %s", rec.Code, rec.Body)
+	}
+}
+
+func TestReady(t *testing.T) {
+	a := New("echo-test", nil)
+	var health []bool
+	a.OnHealth(func(healthy bool) { health = append(health, healthy) })
+	a.SetReady(false)
+	h := a.HealthHandler()
+	if rec := request(t, h, "GET", "/health", ""); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("not ready = %d", rec.Code)
+	}
+	a.Reset()
+	if a.Healthy() || a.Ready() {
+		t.Error("reset made the server ready")
+	}
+	a.SetReady(true)
+	if rec := request(t, h, "GET", "/health", ""); rec.Code != http.StatusOK {
+		t.Errorf("ready = %d", rec.Code)
+	}
+	if want := []bool{false, false, true}; !slices.Equal(health, want) {
+		t.Errorf("health changes = %v, want %v", health, want)
+	}
+}
+
+func TestHTTP(t *testing.T) {
+	a := New("echo-test", nil)
+	a.SetDelay(50 * time.Millisecond)
+	h := a.HTTP(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	start := time.Now()
+	request(t, h, "GET", "/echo", "")
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("request took %v, want the 50ms delay", elapsed)
+	}
+	start = time.Now()
+	request(t, h, "GET", "/health", "")
+	if elapsed := time.Since(start); elapsed >= 50*time.Millisecond {
+		t.Errorf("health check took %v, want no delay", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := a.Wait(ctx); err != context.Canceled {
+		t.Errorf("Wait with a canceled context = %v", err)
+	}
+}
+
+func TestDebug(t *testing.T) {
+	a := New("echo-test", nil)
+	if rec := request(t, a.Handler(), "GET", "/debug/goroutines", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("goroutines before Debug = %d, want 404", rec.Code)
+	}
+	a.Debug()
+
+	tests := []struct {
+		path, want string
+	}{
+		{"/debug/pprof/", "goroutine"},
+		{"/debug/pprof/heap?debug=1", "heap profile"},
+		{"/debug/vars", `"memstats"`},
+		{"/debug/goroutines", "TestDebug"},
+	}
+	for _, tt := range tests {
+		rec := request(t, a.Handler(), "GET", tt.path, "")
+		if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), tt.want) {
+			t.Errorf("GET %s = %d, want %q in %.200s", tt.path, rec.Code, tt.want, rec.Body)
+		}
+	}
+}
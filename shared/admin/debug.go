@@ -0,0 +1,29 @@
+package admin
+
+import (
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+	runtimepprof "runtime/pprof"
+)
+
+// Debug serves the runtime diagnostics on the admin port as well: the
+// net/http/pprof profiles at /debug/pprof/, the expvar variables at
+// /debug/vars and the stacks of every goroutine at /debug/goroutines. It
+// must be called before Serve.
+func (a *Admin) Debug() {
+	a.mux.HandleFunc("/debug/pprof/", pprof.Index)
+	a.mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	a.mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	a.mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	a.mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	a.mux.Handle("GET /debug/vars", expvar.Handler())
+	a.mux.HandleFunc("GET /debug/goroutines", serveGoroutines)
+}
+
+// serveGoroutines dumps the stack of every goroutine in the format of an
+// unrecovered panic
+func serveGoroutines(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_ = runtimepprof.Lookup("goroutine").WriteTo(w, 2)
+}
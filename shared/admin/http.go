@@ -0,0 +1,156 @@
+package admin
+
+import (
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/probitas-test/echo-servers/shared/internal/jsonhttp"
+	"github.com/probitas-test/echo-servers/shared/network"
+)
+
+func (a *Admin) routes() {
+	a.mux.HandleFunc("GET /{$}", a.serveState)
+	a.mux.HandleFunc("GET /state", a.serveState)
+
+	a.mux.HandleFunc("GET /health", a.serveHealth)
+	a.mux.HandleFunc("PUT /health", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Healthy *bool `json:"healthy"`
+		}
+		if err := jsonhttp.Decode(r, &body); err != nil || body.Healthy == nil {
+			jsonhttp.Error(w, http.StatusBadRequest, `body must be {"healthy": true|false}`)
+			return
+		}
+		a.SetHealthy(*body.Healthy)
+		a.serveHealth(w, r)
+	})
+
+	a.mux.HandleFunc("GET /delay", a.serveDelay)
+	a.mux.HandleFunc("PUT /delay", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			DelayMS *int64 `json:"delay_ms"`
+		}
+		if err := jsonhttp.Decode(r, &body); err != nil || body.DelayMS == nil || *body.DelayMS < 0 {
+			jsonhttp.Error(w, http.StatusBadRequest, `body must be {"delay_ms": <milliseconds >= 0>}`)
+			return
+		}
+		a.SetDelay(time.Duration(*body.DelayMS) * time.Millisecond)
+		a.serveDelay(w, r)
+	})
+
+	a.mux.HandleFunc("GET /flags", a.serveFlags)
+	a.mux.HandleFunc("PUT /flags/{name}", func(w http.ResponseWriter, r *http.Request) {
+		a.mu.Lock()
+		f, ok := a.flags[r.PathValue("name")]
+		a.mu.Unlock()
+		if !ok {
+			jsonhttp.Error(w, http.StatusNotFound, "unknown flag "+r.PathValue("name"))
+			return
+		}
+		var body struct {
+			Enabled *bool `json:"enabled"`
+		}
+		if err := jsonhttp.Decode(r, &body); err != nil || body.Enabled == nil {
+			jsonhttp.Error(w, http.StatusBadRequest, `body must be {"enabled": true|false}`)
+			return
+		}
+		f.Set(*body.Enabled)
+		a.serveFlags(w, r)
+	})
+
+	a.mux.HandleFunc("POST /stores/reset", func(w http.ResponseWriter, r *http.Request) {
+		a.ResetStores()
+		w.WriteHeader(http.StatusNoContent)
+	})
+	a.mux.HandleFunc("POST /stores/{name}/reset", func(w http.ResponseWriter, r *http.Request) {
+		if !a.ResetStore(r.PathValue("name")) {
+			jsonhttp.Error(w, http.StatusNotFound, "unknown store "+r.PathValue("name"))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	a.mux.HandleFunc("POST /reset", func(w http.ResponseWriter, r *http.Request) {
+		a.Reset()
+		a.serveState(w, r)
+	})
+}
+
+func (a *Admin) serveState(w http.ResponseWriter, r *http.Request) {
+	jsonhttp.Write(w, http.StatusOK, a.Dump())
+}
+
+func (a *Admin) serveHealth(w http.ResponseWriter, r *http.Request) {
+	jsonhttp.Write(w, http.StatusOK, map[string]bool{"healthy": a.Healthy()})
+}
+
+func (a *Admin) serveDelay(w http.ResponseWriter, r *http.Request) {
+	jsonhttp.Write(w, http.StatusOK, map[string]int64{"delay_ms": a.Delay().Milliseconds()})
+}
+
+func (a *Admin) serveFlags(w http.ResponseWriter, r *http.Request) {
+	a.mu.Lock()
+	flags := flagStates(a.flags)
+	a.mu.Unlock()
+	jsonhttp.Write(w, http.StatusOK, flags)
+}
+
+// Handle serves h at pattern on the admin port as well, such as the chaos
+// admin API. It must be called before Serve.
+func (a *Admin) Handle(pattern string, h http.Handler) {
+	a.mux.Handle(pattern, h)
+}
+
+// Handler serves the admin API
+func (a *Admin) Handler() http.Handler {
+	return a.mux
+}
+
+// Serve serves the admin API on addr in the background, once it listens.
+// The returned server is shut down with the application server.
+func (a *Admin) Serve(addr string) (*http.Server, error) {
+	listener, err := network.Listen(addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for the admin API: %w", err)
+	}
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           a.mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := srv.Serve(listener); err != nil && err != http.ErrServerClosed {
+			slog.Error("Failed to serve admin API", "error", err)
+		}
+	}()
+	log.Printf("Serving admin API on %s", addr)
+	return srv, nil
+}
+
+// HealthHandler answers the health checks of the server: {"status":"ok"},
+// or 503 with {"status":"unavailable"} while unhealthy
+func (a *Admin) HealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if !a.Healthy() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"status":"unavailable"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"ok"}`))
+	})
+}
+
+// HTTP is middleware delaying every request by the delay before next
+// handles it, except the health checks at /health
+func (a *Admin) HTTP(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/health" && a.Wait(r.Context()) != nil {
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,273 @@
+// Package bandwidth shapes the connections a server accepts, limiting how
+// fast every connection uploads (the bytes the server reads) and downloads
+// (the bytes it writes) with a token bucket per direction, so clients can
+// be tested under constrained network conditions without tc rules on the
+// host.
+//
+// A Shaper is configured with the BANDWIDTH_* settings and changed at
+// runtime through its admin API (Handler). Servers wrap their listeners
+// with Listen; connections already open follow configuration changes.
+package bandwidth
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/probitas-test/echo-servers/shared/config"
+	"github.com/probitas-test/echo-servers/shared/internal/jsonhttp"
+)
+
+// DefaultBurst is the burst of the default configuration, in bytes
+const DefaultBurst = 16 * 1024
+
+// Config configures the bandwidth of every connection
+type Config struct {
+	Enabled bool `json:"enabled"`
+	// Upload limits the bytes per second read from a connection (0 =
+	// unlimited)
+	Upload int `json:"upload"`
+	// Download limits the bytes per second written to a connection (0 =
+	// unlimited)
+	Download int `json:"download"`
+	// Burst is the number of bytes a connection transfers at full speed in
+	// each direction after being idle
+	Burst int `json:"burst"`
+}
+
+// LoadConfig reads the BANDWIDTH_* settings from src
+func LoadConfig(src *config.Source) Config {
+	return Config{
+		Enabled:  src.Bool("BANDWIDTH_ENABLED", false),
+		Upload:   src.Int("BANDWIDTH_UPLOAD", 0),
+		Download: src.Int("BANDWIDTH_DOWNLOAD", 0),
+		Burst:    src.Int("BANDWIDTH_BURST", DefaultBurst),
+	}
+}
+
+// DefaultConfig returns the configuration used for the fields omitted in
+// the admin API
+func DefaultConfig() Config {
+	return Config{Burst: DefaultBurst}
+}
+
+// UnmarshalJSON fills the fields missing from b with their defaults and
+// rejects unknown fields
+func (c *Config) UnmarshalJSON(b []byte) error {
+	type plain Config
+	v := plain(DefaultConfig())
+	if err := jsonhttp.DecodeStrict(b, &v); err != nil {
+		return err
+	}
+	*c = Config(v)
+	return nil
+}
+
+// Validate reports the first invalid setting
+func (c Config) Validate() error {
+	switch {
+	case c.Upload < 0:
+		return errors.New("upload must not be negative")
+	case c.Download < 0:
+		return errors.New("download must not be negative")
+	case c.Burst < 1:
+		return errors.New("burst must be at least 1")
+	}
+	return nil
+}
+
+// String describes the limits for the startup log
+func (c Config) String() string {
+	if !c.Enabled {
+		return "disabled"
+	}
+	return fmt.Sprintf("upload=%s download=%s burst=%dB", rate(c.Upload), rate(c.Download), c.Burst)
+}
+
+func rate(bps int) string {
+	if bps == 0 {
+		return "unlimited"
+	}
+	return fmt.Sprintf("%dB/s", bps)
+}
+
+// Shaper limits the bandwidth of connections under a configuration
+// replaced at runtime
+type Shaper struct {
+	initial Config
+	cfg     atomic.Pointer[Config]
+	conns   atomic.Int64
+}
+
+// New creates a shaper with the startup configuration c
+func New(c Config) (*Shaper, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	s := &Shaper{initial: c}
+	s.cfg.Store(&c)
+	return s, nil
+}
+
+// Config returns the configuration in effect
+func (s *Shaper) Config() Config {
+	return *s.cfg.Load()
+}
+
+// SetConfig replaces the configuration, for open connections too
+func (s *Shaper) SetConfig(c Config) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	s.cfg.Store(&c)
+	return nil
+}
+
+// Reset restores the startup configuration
+func (s *Shaper) Reset() {
+	c := s.initial
+	s.cfg.Store(&c)
+}
+
+// Conns returns the number of open connections shaped by s
+func (s *Shaper) Conns() int {
+	return int(s.conns.Load())
+}
+
+// Listen returns l with its accepted connections shaped by s
+func (s *Shaper) Listen(l net.Listener) net.Listener {
+	return &listener{Listener: l, shaper: s}
+}
+
+// Conn returns c shaped by s, for servers accepting connections without a
+// net.Listener
+func (s *Shaper) Conn(c net.Conn) net.Conn {
+	s.conns.Add(1)
+	return &conn{Conn: c, shaper: s, closed: make(chan struct{})}
+}
+
+type listener struct {
+	net.Listener
+	shaper *Shaper
+}
+
+func (l *listener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return l.shaper.Conn(c), nil
+}
+
+// conn shapes the reads and writes of a connection with a bucket per
+// direction
+type conn struct {
+	net.Conn
+	shaper   *Shaper
+	up, down bucket
+	closed   chan struct{}
+	once     sync.Once
+}
+
+// Read reads at most a burst and then waits until the upload rate allows
+// it, so a client sending faster fills the TCP window and slows down
+func (c *conn) Read(p []byte) (int, error) {
+	cfg := c.shaper.Config()
+	if !cfg.Enabled || cfg.Upload == 0 {
+		return c.Conn.Read(p)
+	}
+	if len(p) > cfg.Burst {
+		p = p[:cfg.Burst]
+	}
+	n, err := c.Conn.Read(p)
+	if n > 0 {
+		if werr := c.wait(c.up.take(n, cfg.Upload, cfg.Burst)); werr != nil {
+			return n, werr
+		}
+	}
+	return n, err
+}
+
+// Write writes p a burst at a time, waiting before each one until the
+// download rate allows it
+func (c *conn) Write(p []byte) (int, error) {
+	var written int
+	for len(p) > 0 {
+		cfg := c.shaper.Config()
+		if !cfg.Enabled || cfg.Download == 0 {
+			n, err := c.Conn.Write(p)
+			return written + n, err
+		}
+		chunk := p[:min(len(p), cfg.Burst)]
+		if err := c.wait(c.down.take(len(chunk), cfg.Download, cfg.Burst)); err != nil {
+			return written, err
+		}
+		n, err := c.Conn.Write(chunk)
+		written += n
+		if err != nil {
+			return written, err
+		}
+		p = p[n:]
+	}
+	return written, nil
+}
+
+// NetConn returns the shaped connection, so chaos resets reach the TCP
+// connection
+func (c *conn) NetConn() net.Conn {
+	return c.Conn
+}
+
+func (c *conn) Close() error {
+	c.once.Do(func() {
+		close(c.closed)
+		c.shaper.conns.Add(-1)
+	})
+	return c.Conn.Close()
+}
+
+// wait sleeps for d, returning net.ErrClosed when the connection is closed
+// meanwhile
+func (c *conn) wait(d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-c.closed:
+		return net.ErrClosed
+	}
+}
+
+// bucket is the token bucket of one direction of a connection, in bytes.
+// It starts full and may go into debt, which the caller waits out.
+type bucket struct {
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// take removes n bytes from the bucket, refilled at rate bytes per second
+// up to burst, returning how long to wait until it is out of debt
+func (b *bucket) take(n, rate, burst int) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	if b.last.IsZero() {
+		b.tokens = float64(burst)
+	} else {
+		b.tokens = min(float64(burst), b.tokens+now.Sub(b.last).Seconds()*float64(rate))
+	}
+	b.last = now
+	b.tokens -= float64(n)
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / float64(rate) * float64(time.Second))
+}
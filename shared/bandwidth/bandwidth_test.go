@@ -0,0 +1,174 @@
+package bandwidth
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// dial returns both ends of a TCP connection accepted through the listener
+// of a shaper with the configuration c
+func dial(t *testing.T, c Config) (*Shaper, net.Conn, net.Conn) {
+	t.Helper()
+	s, err := New(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l = s.Listen(l)
+	t.Cleanup(func() { _ = l.Close() })
+
+	client, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+	server, err := l.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = server.Close() })
+	return s, client, server
+}
+
+// transfer writes n bytes to w and reads them from r, returning how long it
+// took
+func transfer(t *testing.T, w, r net.Conn, n int) time.Duration {
+	t.Helper()
+	start := time.Now()
+	errc := make(chan error, 1)
+	go func() {
+		_, err := w.Write(make([]byte, n))
+		errc <- err
+	}()
+	if _, err := io.ReadFull(r, make([]byte, n)); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+	return time.Since(start)
+}
+
+func TestValidate(t *testing.T) {
+	for _, c := range []Config{{Upload: -1, Burst: 1}, {Download: -1, Burst: 1}, {Burst: 0}} {
+		if _, err := New(c); err == nil {
+			t.Errorf("New(%+v) succeeded", c)
+		}
+	}
+}
+
+func TestShaping(t *testing.T) {
+	// A burst of 1000 bytes, then 4000 bytes at 40000 B/s take 100ms
+	tests := []struct {
+		name     string
+		cfg      Config
+		download bool
+		slow     bool
+	}{
+		{"download", Config{Enabled: true, Download: 40000, Burst: 1000}, true, true},
+		{"upload", Config{Enabled: true, Upload: 40000, Burst: 1000}, false, true},
+		{"upload unlimited", Config{Enabled: true, Download: 40000, Burst: 1000}, false, false},
+		{"disabled", Config{Download: 40000, Burst: 1000}, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, client, server := dial(t, tt.cfg)
+			var elapsed time.Duration
+			if tt.download {
+				elapsed = transfer(t, server, client, 5000)
+			} else {
+				elapsed = transfer(t, client, server, 5000)
+			}
+			if tt.slow && elapsed < 80*time.Millisecond {
+				t.Errorf("transfer took %v, want about 100ms", elapsed)
+			}
+			if !tt.slow && elapsed >= 80*time.Millisecond {
+				t.Errorf("transfer took %v, want no shaping", elapsed)
+			}
+		})
+	}
+}
+
+func TestSetConfig(t *testing.T) {
+	s, client, server := dial(t, Config{Burst: 1000})
+	if elapsed := transfer(t, server, client, 5000); elapsed >= 80*time.Millisecond {
+		t.Errorf("disabled transfer took %v", elapsed)
+	}
+	if err := s.SetConfig(Config{Enabled: true, Download: 40000, Burst: 1000}); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := transfer(t, server, client, 5000); elapsed < 80*time.Millisecond {
+		t.Errorf("transfer on the open connection took %v, want about 100ms", elapsed)
+	}
+	s.Reset()
+	if s.Config().Enabled {
+		t.Error("Reset kept the runtime configuration")
+	}
+}
+
+func TestClose(t *testing.T) {
+	s, _, server := dial(t, Config{Enabled: true, Download: 1, Burst: 1})
+	if s.Conns() != 1 {
+		t.Errorf("Conns = %d, want 1", s.Conns())
+	}
+	errc := make(chan error, 1)
+	go func() {
+		_, err := server.Write([]byte("abc"))
+		errc <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	_ = server.Close()
+	select {
+	case err := <-errc:
+		if !errors.Is(err, net.ErrClosed) {
+			t.Errorf("Write = %v, want net.ErrClosed", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Write still waiting after Close")
+	}
+	if s.Conns() != 0 {
+		t.Errorf("Conns = %d after Close, want 0", s.Conns())
+	}
+}
+
+func TestHandler(t *testing.T) {
+	s, err := New(DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := Handler(s)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("PUT", Path, strings.NewReader(`{"enabled":true,"download":1024}`)))
+	var st State
+	if err := json.Unmarshal(rec.Body.Bytes(), &st); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusOK || !st.Config.Enabled || st.Config.Download != 1024 || st.Config.Burst != DefaultBurst {
+		t.Errorf("PUT %s = %d %+v", Path, rec.Code, st)
+	}
+
+	for _, body := range []string{`{"upload":-1}`, `{"burst":0}`, `{"unknown":1}`} {
+		rec = httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("PUT", Path, strings.NewReader(body)))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("PUT %s %s = %d, want 400", Path, body, rec.Code)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("DELETE", Path, nil))
+	if rec.Code != http.StatusOK || s.Config().Enabled {
+		t.Errorf("DELETE %s = %d, config %+v", Path, rec.Code, s.Config())
+	}
+}
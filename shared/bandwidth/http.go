@@ -0,0 +1,50 @@
+package bandwidth
+
+import (
+	"net/http"
+
+	"github.com/probitas-test/echo-servers/shared/internal/jsonhttp"
+)
+
+// Path is where the admin API is served
+const Path = "/bandwidth"
+
+// Handler serves the admin API of s:
+//
+//	GET    /bandwidth  current configuration and number of open connections
+//	PUT    /bandwidth  replace the configuration (omitted fields get defaults)
+//	DELETE /bandwidth  restore the startup configuration
+//
+// Open connections follow the new configuration from their next read or
+// write.
+func Handler(s *Shaper) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+		case http.MethodPut:
+			var c Config
+			err := jsonhttp.Decode(r, &c)
+			if err == nil {
+				err = s.SetConfig(c)
+			}
+			if err != nil {
+				jsonhttp.Error(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		case http.MethodDelete:
+			s.Reset()
+		default:
+			w.Header().Set("Allow", "GET, HEAD, PUT, DELETE")
+			jsonhttp.Error(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		jsonhttp.Write(w, http.StatusOK, State{Config: s.Config(), Conns: s.Conns()})
+	})
+}
+
+// State is the configuration of a Shaper and the number of connections it
+// shapes, as served by the admin API
+type State struct {
+	Config Config `json:"config"`
+	Conns  int    `json:"connections"`
+}
@@ -0,0 +1,414 @@
+// Package certs issues the TLS certificates of a server from a local CA
+// generated at startup, so clients can be tested against trusted,
+// rotated and deliberately broken certificates without managing files.
+//
+// A Manager selects a leaf certificate by SNI, issuing it on the first
+// handshake naming the host. Clients trust the server by downloading the
+// CA certificate (CAHandler, /ca.pem). Leaf certificates are reissued with
+// new keys by Rotate (the admin API, Handler) and after the configured
+// rotation interval. Hosts can be configured to get a broken certificate:
+// expired, not yet valid, for the wrong host, self-signed or signed by an
+// unknown CA.
+package certs
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/probitas-test/echo-servers/shared/config"
+)
+
+// Mode is the kind of certificate issued for a host
+type Mode string
+
+// Modes of the issued certificates. Every mode but ModeValid fails the
+// verification of a client trusting the CA.
+const (
+	ModeValid       Mode = "valid"
+	ModeExpired     Mode = "expired"
+	ModeNotYetValid Mode = "not-yet-valid"
+	ModeWrongHost   Mode = "wrong-host"
+	ModeSelfSigned  Mode = "self-signed"
+	ModeUnknownCA   Mode = "unknown-ca"
+)
+
+var modes = []Mode{ModeValid, ModeExpired, ModeNotYetValid, ModeWrongHost, ModeSelfSigned, ModeUnknownCA}
+
+// WrongHost is the only name of the certificates issued in ModeWrongHost
+const WrongHost = "wrong-host.invalid"
+
+// Config configures the CA and the issued certificates
+type Config struct {
+	// Hosts are the names of the default certificate, presented for these
+	// hosts and to clients sending no SNI, such as clients connecting to
+	// an IP address
+	Hosts []string
+	// Mode is the mode of the certificates of every host not in HostModes
+	Mode      Mode
+	HostModes map[string]Mode
+	// Validity is the lifetime of the leaf certificates
+	Validity time.Duration
+	// RotateInterval, if set, is the age at which a leaf certificate is
+	// reissued on the next handshake
+	RotateInterval time.Duration
+	// CACertFile and CAKeyFile load the CA instead of generating one, so
+	// that clients keep trusting the server across restarts
+	CACertFile string
+	CAKeyFile  string
+}
+
+// LoadConfig reads the TLS_* settings from src
+func LoadConfig(src *config.Source) Config {
+	return Config{
+		Hosts:          src.List("TLS_HOSTS", "localhost,127.0.0.1,::1"),
+		Mode:           Mode(src.String("TLS_CERT_MODE", string(ModeValid))),
+		HostModes:      parseHostModes(src.List("TLS_CERT_HOST_MODES", "")),
+		Validity:       time.Duration(src.Int("TLS_CERT_VALIDITY_HOURS", 13*24)) * time.Hour,
+		RotateInterval: time.Duration(src.Int("TLS_CERT_ROTATE_INTERVAL_MS", 0)) * time.Millisecond,
+		CACertFile:     src.String("TLS_CA_CERT_FILE", ""),
+		CAKeyFile:      src.String("TLS_CA_KEY_FILE", ""),
+	}
+}
+
+// parseHostModes parses host=mode entries. Malformed entries get an empty
+// mode, rejected by New.
+func parseHostModes(entries []string) map[string]Mode {
+	hostModes := make(map[string]Mode, len(entries))
+	for _, entry := range entries {
+		host, mode, _ := strings.Cut(entry, "=")
+		hostModes[strings.ToLower(strings.TrimSpace(host))] = Mode(strings.TrimSpace(mode))
+	}
+	return hostModes
+}
+
+// Validate reports the first invalid setting
+func (c Config) Validate() error {
+	if len(c.Hosts) == 0 {
+		return errors.New("at least one host is required")
+	}
+	if !slices.Contains(modes, c.Mode) {
+		return fmt.Errorf("invalid certificate mode %q (want one of %s)", c.Mode, modeList())
+	}
+	for host, mode := range c.HostModes {
+		if !slices.Contains(modes, mode) {
+			return fmt.Errorf("invalid certificate mode %q for host %q (want one of %s)", mode, host, modeList())
+		}
+	}
+	if c.Validity <= 0 {
+		return errors.New("certificate validity must be positive")
+	}
+	if c.RotateInterval < 0 {
+		return errors.New("rotation interval must not be negative")
+	}
+	if (c.CACertFile == "") != (c.CAKeyFile == "") {
+		return errors.New("the CA certificate and key files must be set together")
+	}
+	return nil
+}
+
+func modeList() string {
+	names := make([]string, len(modes))
+	for i, mode := range modes {
+		names[i] = string(mode)
+	}
+	return strings.Join(names, ", ")
+}
+
+// String describes the configuration for the startup log
+func (c Config) String() string {
+	s := fmt.Sprintf("hosts=%s, mode=%s, validity=%s", strings.Join(c.Hosts, ","), c.Mode, c.Validity)
+	if len(c.HostModes) > 0 {
+		hostModes := make([]string, 0, len(c.HostModes))
+		for host, mode := range c.HostModes {
+			hostModes = append(hostModes, host+"="+string(mode))
+		}
+		slices.Sort(hostModes)
+		s += ", host_modes=" + strings.Join(hostModes, ",")
+	}
+	if c.RotateInterval > 0 {
+		s += fmt.Sprintf(", rotate=%s", c.RotateInterval)
+	}
+	return s
+}
+
+// authority signs certificates
+type authority struct {
+	cert *x509.Certificate
+	key  crypto.Signer
+}
+
+// issued is a leaf certificate issued for a host
+type issued struct {
+	cert   *tls.Certificate
+	mode   Mode
+	issued time.Time
+}
+
+// Manager issues the certificates of a server
+type Manager struct {
+	cfg Config
+	ca  authority
+	// unknown signs the certificates of ModeUnknownCA and is never
+	// published
+	unknown authority
+
+	mu        sync.Mutex
+	certs     map[string]*issued
+	rotations int
+}
+
+// New creates the CA, loaded from the configured files or generated, and
+// a manager issuing certificates from it
+func New(cfg Config) (*Manager, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	m := &Manager{cfg: cfg, certs: make(map[string]*issued)}
+	var err error
+	if cfg.CACertFile != "" {
+		m.ca, err = loadAuthority(cfg.CACertFile, cfg.CAKeyFile)
+	} else {
+		m.ca, err = newAuthority("Echo Servers Local CA")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if m.unknown, err = newAuthority("Echo Servers Unknown CA"); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func newAuthority(name string) (authority, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return authority{}, err
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serialNumber(),
+		Subject:               pkix.Name{CommonName: name, Organization: []string{"probitas-test"}},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.AddDate(10, 0, 0),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return authority{}, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return authority{}, err
+	}
+	return authority{cert: cert, key: key}, nil
+}
+
+func loadAuthority(certFile, keyFile string) (authority, error) {
+	pair, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return authority{}, fmt.Errorf("load CA: %w", err)
+	}
+	cert, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return authority{}, fmt.Errorf("load CA: %w", err)
+	}
+	if !cert.IsCA {
+		return authority{}, fmt.Errorf("load CA: %s is not a CA certificate", certFile)
+	}
+	key, ok := pair.PrivateKey.(crypto.Signer)
+	if !ok {
+		return authority{}, fmt.Errorf("load CA: unsupported key type %T", pair.PrivateKey)
+	}
+	return authority{cert: cert, key: key}, nil
+}
+
+func serialNumber() *big.Int {
+	serial, _ := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 127))
+	return serial
+}
+
+// TLSConfig returns a TLS configuration presenting the certificates of m
+func (m *Manager) TLSConfig() *tls.Config {
+	return &tls.Config{
+		GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return m.Certificate(hello.ServerName)
+		},
+	}
+}
+
+// CA returns the CA certificate
+func (m *Manager) CA() *x509.Certificate {
+	return m.ca.cert
+}
+
+// CAPEM returns the PEM encoding of the CA certificate
+func (m *Manager) CAPEM() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: m.ca.cert.Raw})
+}
+
+// Certificate returns the certificate of host, issuing it if it has not
+// been issued yet or is due for rotation. The configured hosts without a
+// mode of their own and clients without SNI (empty host) share the
+// certificate of the configured hosts.
+func (m *Manager) Certificate(host string) (*tls.Certificate, error) {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if _, ok := m.cfg.HostModes[host]; !ok && slices.Contains(m.cfg.Hosts, host) {
+		host = ""
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if c, ok := m.certs[host]; ok &&
+		(m.cfg.RotateInterval <= 0 || time.Since(c.issued) < m.cfg.RotateInterval) {
+		return c.cert, nil
+	}
+	c, err := m.issue(host)
+	if err != nil {
+		return nil, err
+	}
+	m.certs[host] = c
+	return c.cert, nil
+}
+
+// Rotate discards the issued certificates, which are reissued with new
+// keys on the next handshake
+func (m *Manager) Rotate() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	clear(m.certs)
+	m.rotations++
+}
+
+// mode returns the mode of the certificate of host
+func (m *Manager) mode(host string) Mode {
+	if mode, ok := m.cfg.HostModes[host]; ok {
+		return mode
+	}
+	return m.cfg.Mode
+}
+
+// issue creates the certificate of host in its mode
+func (m *Manager) issue(host string) (*issued, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	names := m.cfg.Hosts
+	if host != "" {
+		names = []string{host}
+	}
+	mode := m.mode(host)
+	now := time.Now()
+	notBefore, notAfter := now.Add(-time.Hour), now.Add(m.cfg.Validity)
+	signer := m.ca
+	switch mode {
+	case ModeExpired:
+		notBefore, notAfter = now.Add(-m.cfg.Validity-time.Hour), now.Add(-time.Hour)
+	case ModeNotYetValid:
+		notBefore, notAfter = now.Add(24*time.Hour), now.Add(24*time.Hour+m.cfg.Validity)
+	case ModeWrongHost:
+		names = []string{WrongHost}
+	case ModeUnknownCA:
+		signer = m.unknown
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: serialNumber(),
+		Subject:      pkix.Name{CommonName: names[0]},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	for _, name := range names {
+		if ip := net.ParseIP(name); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, name)
+		}
+	}
+	parent, parentKey := signer.cert, signer.key
+	if mode == ModeSelfSigned {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		return nil, err
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	cert := &tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+	return &issued{cert: cert, mode: mode, issued: now}, nil
+}
+
+// Info describes a certificate
+type Info struct {
+	// Host is the SNI the certificate was issued for, empty for the
+	// certificate of the configured hosts
+	Host      string    `json:"host"`
+	Mode      Mode      `json:"mode,omitempty"`
+	Subject   string    `json:"subject"`
+	Names     []string  `json:"names,omitempty"`
+	Serial    string    `json:"serial"`
+	NotBefore time.Time `json:"not_before"`
+	NotAfter  time.Time `json:"not_after"`
+	// SHA256 is the hash of the DER certificate, as pinned by clients
+	SHA256 string `json:"sha256"`
+}
+
+func info(cert *x509.Certificate) Info {
+	sum := sha256.Sum256(cert.Raw)
+	names := slices.Clone(cert.DNSNames)
+	for _, ip := range cert.IPAddresses {
+		names = append(names, ip.String())
+	}
+	return Info{
+		Subject:   cert.Subject.CommonName,
+		Names:     names,
+		Serial:    cert.SerialNumber.Text(16),
+		NotBefore: cert.NotBefore,
+		NotAfter:  cert.NotAfter,
+		SHA256:    hex.EncodeToString(sum[:]),
+	}
+}
+
+// State is the CA and the issued certificates
+type State struct {
+	CA        Info   `json:"ca"`
+	Rotations int    `json:"rotations"`
+	Certs     []Info `json:"certs"`
+}
+
+// State returns the CA and the issued certificates, sorted by host
+func (m *Manager) State() State {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := State{CA: info(m.ca.cert), Rotations: m.rotations, Certs: make([]Info, 0, len(m.certs))}
+	for host, c := range m.certs {
+		i := info(c.cert.Leaf)
+		i.Host, i.Mode = host, c.mode
+		s.Certs = append(s.Certs, i)
+	}
+	slices.SortFunc(s.Certs, func(a, b Info) int { return strings.Compare(a.Host, b.Host) })
+	return s
+}
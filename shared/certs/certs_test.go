@@ -0,0 +1,179 @@
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newManager(t *testing.T, cfg Config) *Manager {
+	t.Helper()
+	if cfg.Hosts == nil {
+		cfg.Hosts = []string{"localhost", "127.0.0.1"}
+	}
+	if cfg.Mode == "" {
+		cfg.Mode = ModeValid
+	}
+	if cfg.Validity == 0 {
+		cfg.Validity = 24 * time.Hour
+	}
+	m, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+// handshake connects to a TLS listener of m, trusting its CA, and returns
+// the verification error
+func handshake(t *testing.T, m *Manager, serverName string) error {
+	t.Helper()
+	l, err := tls.Listen("tcp", "127.0.0.1:0", m.TLSConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		conn, err := l.Accept()
+		if err == nil {
+			_ = conn.(*tls.Conn).Handshake()
+			_ = conn.Close()
+		}
+	}()
+
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(m.CAPEM()) {
+		t.Fatal("invalid CA PEM")
+	}
+	conn, err := tls.Dial("tcp", l.Addr().String(), &tls.Config{RootCAs: roots, ServerName: serverName})
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+func TestModes(t *testing.T) {
+	m := newManager(t, Config{HostModes: map[string]Mode{
+		"expired.localhost":       ModeExpired,
+		"not-yet-valid.localhost": ModeNotYetValid,
+		"wrong-host.localhost":    ModeWrongHost,
+		"self-signed.localhost":   ModeSelfSigned,
+		"unknown-ca.localhost":    ModeUnknownCA,
+	}})
+
+	var (
+		invalid  x509.CertificateInvalidError
+		hostname x509.HostnameError
+		unknown  x509.UnknownAuthorityError
+	)
+	tests := []struct {
+		serverName string
+		target     any
+	}{
+		{"localhost", nil},
+		{"127.0.0.1", nil},
+		{"api.localhost", nil},
+		{"expired.localhost", &invalid},
+		{"not-yet-valid.localhost", &invalid},
+		{"wrong-host.localhost", &hostname},
+		{"self-signed.localhost", &unknown},
+		{"unknown-ca.localhost", &unknown},
+	}
+	for _, tt := range tests {
+		err := handshake(t, m, tt.serverName)
+		switch {
+		case tt.target == nil && err != nil:
+			t.Errorf("%s: %v", tt.serverName, err)
+		case tt.target != nil && (err == nil || !errors.As(err, tt.target)):
+			t.Errorf("%s: error %v, want %T", tt.serverName, err, tt.target)
+		}
+	}
+}
+
+func TestRotate(t *testing.T) {
+	m := newManager(t, Config{})
+	first, err := m.Certificate("localhost")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := m.Certificate("LOCALHOST."); again != first {
+		t.Error("certificate reissued without rotation")
+	}
+	if other, _ := m.Certificate("api.localhost"); other == first {
+		t.Error("certificate of the configured hosts presented for another host")
+	}
+	m.Rotate()
+	rotated, _ := m.Certificate("localhost")
+	if rotated == first || rotated.Leaf.SerialNumber.Cmp(first.Leaf.SerialNumber) == 0 {
+		t.Error("certificate not reissued after Rotate")
+	}
+
+	m = newManager(t, Config{RotateInterval: time.Millisecond})
+	first, _ = m.Certificate("")
+	time.Sleep(5 * time.Millisecond)
+	if again, _ := m.Certificate(""); again == first {
+		t.Error("certificate not reissued after the rotation interval")
+	}
+	if names := first.Leaf.DNSNames; len(names) != 1 || names[0] != "localhost" || len(first.Leaf.IPAddresses) != 1 {
+		t.Errorf("certificate without SNI for %v %v, want the configured hosts", names, first.Leaf.IPAddresses)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	m := newManager(t, Config{})
+	if err := handshake(t, m, "localhost"); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	Handler(m).ServeHTTP(rec, httptest.NewRequest("POST", Path, nil))
+	var s State
+	if err := json.Unmarshal(rec.Body.Bytes(), &s); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusOK || s.Rotations != 1 || len(s.Certs) != 0 || s.CA.Subject != "Echo Servers Local CA" {
+		t.Errorf("POST %s = %d %+v", Path, rec.Code, s)
+	}
+
+	rec = httptest.NewRecorder()
+	Handler(m).ServeHTTP(rec, httptest.NewRequest("DELETE", Path, nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("DELETE %s = %d, want 405", Path, rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	CAHandler(m).ServeHTTP(rec, httptest.NewRequest("GET", CAPath, nil))
+	if !strings.HasPrefix(rec.Body.String(), "-----BEGIN CERTIFICATE-----") {
+		t.Errorf("GET %s = %q", CAPath, rec.Body)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := Config{Hosts: []string{"localhost"}, Mode: ModeValid, Validity: time.Hour}
+	tests := []struct {
+		name   string
+		modify func(*Config)
+	}{
+		{"no hosts", func(c *Config) { c.Hosts = nil }},
+		{"mode", func(c *Config) { c.Mode = "broken" }},
+		{"host mode", func(c *Config) { c.HostModes = parseHostModes([]string{"expired.localhost"}) }},
+		{"validity", func(c *Config) { c.Validity = 0 }},
+		{"CA key only", func(c *Config) { c.CAKeyFile = "ca-key.pem" }},
+	}
+	if err := valid.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		c := valid
+		tt.modify(&c)
+		if err := c.Validate(); err == nil {
+			t.Errorf("%s: no error", tt.name)
+		}
+	}
+}
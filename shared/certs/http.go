@@ -0,0 +1,42 @@
+package certs
+
+import (
+	"net/http"
+
+	"github.com/probitas-test/echo-servers/shared/internal/jsonhttp"
+)
+
+// Path is where the admin API is served
+const Path = "/certs"
+
+// CAPath is where the CA certificate is served
+const CAPath = "/ca.pem"
+
+// Handler serves the admin API of m:
+//
+//	GET  /certs  the CA and the issued certificates
+//	POST /certs  rotate the issued certificates
+func Handler(m *Manager) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+		case http.MethodPost:
+			m.Rotate()
+		default:
+			w.Header().Set("Allow", "GET, HEAD, POST")
+			jsonhttp.Error(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		jsonhttp.Write(w, http.StatusOK, m.State())
+	})
+}
+
+// CAHandler serves the PEM-encoded CA certificate of m, for clients to
+// trust the server
+func CAHandler(m *Manager) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-pem-file")
+		w.Header().Set("Content-Disposition", `attachment; filename="ca.pem"`)
+		_, _ = w.Write(m.CAPEM())
+	})
+}
@@ -0,0 +1,346 @@
+// Package chaos injects faults into the requests a server handles:
+// probabilistic latency, error responses, connection resets and bandwidth
+// limits, so clients can be tested against a misbehaving server with the
+// same settings on every protocol.
+//
+// An Engine holds a global profile and per-route rules, configured with the
+// CHAOS_* settings and replaced at runtime through the admin API (Handler).
+// echo-http applies it with the HTTP middleware, RPC servers with their own
+// interceptors calling Decide.
+package chaos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/probitas-test/echo-servers/shared/config"
+	"github.com/probitas-test/echo-servers/shared/internal/jsonhttp"
+)
+
+// Defaults of the profile fields omitted in the configuration or the admin
+// API
+const (
+	DefaultLatencyRate = 1
+	DefaultErrorStatus = 503
+)
+
+// Config configures the faults in effect at startup
+type Config struct {
+	Enabled bool
+	Profile Profile
+	// Routes limits the profile to the matching routes (all when empty)
+	Routes []string
+}
+
+// LoadConfig reads the CHAOS_* settings from src
+func LoadConfig(src *config.Source) Config {
+	return Config{
+		Enabled: src.Bool("CHAOS_ENABLED", false),
+		Profile: Profile{
+			LatencyMS:   src.Int("CHAOS_LATENCY_MS", 0),
+			JitterMS:    src.Int("CHAOS_JITTER_MS", 0),
+			LatencyRate: src.Float("CHAOS_LATENCY_RATE", DefaultLatencyRate),
+			ErrorRate:   src.Float("CHAOS_ERROR_RATE", 0),
+			ErrorStatus: src.Int("CHAOS_ERROR_STATUS", DefaultErrorStatus),
+			ResetRate:   src.Float("CHAOS_RESET_RATE", 0),
+			Bandwidth:   src.Int("CHAOS_BANDWIDTH", 0),
+		},
+		Routes: src.List("CHAOS_ROUTES", ""),
+	}
+}
+
+// String describes the startup faults for the startup log
+func (c Config) String() string {
+	if !c.Enabled {
+		return "disabled"
+	}
+	routes := "all"
+	if len(c.Routes) > 0 {
+		routes = strings.Join(c.Routes, ",")
+	}
+	return fmt.Sprintf("%s, routes=%s", c.Profile, routes)
+}
+
+// Profile describes the faults injected into the requests it applies to.
+// Rates are probabilities from 0 to 1.
+type Profile struct {
+	// LatencyMS plus up to JitterMS of random delay are added before
+	// handling a request, with probability LatencyRate
+	LatencyMS   int     `json:"latency_ms"`
+	JitterMS    int     `json:"jitter_ms"`
+	LatencyRate float64 `json:"latency_rate"`
+	// ErrorRate of the requests fail with ErrorStatus (an HTTP status,
+	// mapped to a gRPC code by RPC servers)
+	ErrorRate   float64 `json:"error_rate"`
+	ErrorStatus int     `json:"error_status"`
+	// ResetRate of the requests have their connection reset
+	ResetRate float64 `json:"reset_rate"`
+	// Bandwidth limits responses to bytes per second (0 = unlimited)
+	Bandwidth int `json:"bandwidth"`
+}
+
+// DefaultProfile returns a profile injecting no faults
+func DefaultProfile() Profile {
+	return Profile{LatencyRate: DefaultLatencyRate, ErrorStatus: DefaultErrorStatus}
+}
+
+// UnmarshalJSON fills the fields missing from b with their defaults and
+// rejects unknown fields
+func (p *Profile) UnmarshalJSON(b []byte) error {
+	type plain Profile
+	v := plain(DefaultProfile())
+	if err := jsonhttp.DecodeStrict(b, &v); err != nil {
+		return err
+	}
+	*p = Profile(v)
+	return nil
+}
+
+func (p Profile) String() string {
+	return fmt.Sprintf("latency=%dms+%dms@%g, errors=%d@%g, resets=%g, bandwidth=%dB/s",
+		p.LatencyMS, p.JitterMS, p.LatencyRate, p.ErrorStatus, p.ErrorRate, p.ResetRate, p.Bandwidth)
+}
+
+// Validate checks that durations and bandwidth are not negative, rates are
+// probabilities and ErrorStatus is a 4xx or 5xx status
+func (p Profile) Validate() error {
+	switch {
+	case p.LatencyMS < 0 || p.JitterMS < 0:
+		return errors.New("latency and jitter must not be negative")
+	case p.Bandwidth < 0:
+		return errors.New("bandwidth must not be negative")
+	case p.ErrorStatus < 400 || p.ErrorStatus > 599:
+		return fmt.Errorf("error status %d is not a 4xx or 5xx status", p.ErrorStatus)
+	}
+	for _, rate := range []float64{p.LatencyRate, p.ErrorRate, p.ResetRate} {
+		if rate < 0 || rate > 1 {
+			return fmt.Errorf("rate %g is not between 0 and 1", rate)
+		}
+	}
+	return nil
+}
+
+// Rule applies a profile to the routes matching Match, a pattern where "*"
+// matches any run of characters: an HTTP path ("/status/*"), method and path
+// ("POST /anything*"), RPC procedure ("/echo.v1.Echo/*") or GraphQL
+// operation ("mutation *")
+type Rule struct {
+	Match   string  `json:"match"`
+	Profile Profile `json:"profile"`
+}
+
+// State is the fault configuration of an Engine, as served and replaced by
+// the admin API. The first rule matching a request wins; requests matching
+// no rule get the global profile.
+type State struct {
+	Enabled bool    `json:"enabled"`
+	Global  Profile `json:"global"`
+	Rules   []Rule  `json:"rules"`
+}
+
+// Validate checks every profile of s
+func (s State) Validate() error {
+	if err := s.Global.Validate(); err != nil {
+		return fmt.Errorf("global: %w", err)
+	}
+	for _, r := range s.Rules {
+		if r.Match == "" {
+			return errors.New("rule without match pattern")
+		}
+		if err := r.Profile.Validate(); err != nil {
+			return fmt.Errorf("rule %q: %w", r.Match, err)
+		}
+	}
+	return nil
+}
+
+// Engine decides the faults of each request
+type Engine struct {
+	mu      sync.RWMutex
+	state   State
+	initial State
+	// random returns a number in [0, 1), replaced in tests
+	random func() float64
+}
+
+// New creates an engine in the state of c. With routes, the profile of c
+// only applies to them.
+func New(c Config) (*Engine, error) {
+	s := State{Enabled: c.Enabled, Global: c.Profile, Rules: []Rule{}}
+	if len(c.Routes) > 0 {
+		s.Global = DefaultProfile()
+		for _, route := range c.Routes {
+			s.Rules = append(s.Rules, Rule{Match: route, Profile: c.Profile})
+		}
+	}
+	if err := s.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid chaos configuration: %w", err)
+	}
+	return &Engine{state: s, initial: s, random: rand.Float64}, nil
+}
+
+// State returns the current state of e
+func (e *Engine) State() State {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	s := e.state
+	s.Rules = append([]Rule{}, s.Rules...)
+	return s
+}
+
+// SetState replaces the state of e
+func (e *Engine) SetState(s State) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+	if s.Rules == nil {
+		s.Rules = []Rule{}
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.state = s
+	return nil
+}
+
+// Reset restores the startup state of e
+func (e *Engine) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.state = e.initial
+}
+
+// profile returns the profile of the first rule matching one of keys,
+// or the global profile
+func (e *Engine) profile(keys []string) (Profile, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if !e.state.Enabled {
+		return Profile{}, false
+	}
+	for _, r := range e.state.Rules {
+		for _, key := range keys {
+			if Match(r.Match, key) {
+				return r.Profile, true
+			}
+		}
+	}
+	return e.state.Global, true
+}
+
+// Decision is the faults drawn for one request
+type Decision struct {
+	// Delay to wait before handling the request
+	Delay time.Duration
+	// Reset the connection instead of responding
+	Reset bool
+	// Status fails the request with this HTTP status when not zero
+	Status int
+	// Bandwidth limits the response to bytes per second when not zero
+	Bandwidth int
+}
+
+// Decide draws the faults of a request identified by keys, such as its path
+// and its method and path
+func (e *Engine) Decide(keys ...string) Decision {
+	p, ok := e.profile(keys)
+	if !ok {
+		return Decision{}
+	}
+	d := Decision{Bandwidth: p.Bandwidth}
+	if (p.LatencyMS > 0 || p.JitterMS > 0) && e.random() < p.LatencyRate {
+		d.Delay = time.Duration(p.LatencyMS)*time.Millisecond +
+			time.Duration(e.random()*float64(p.JitterMS)*float64(time.Millisecond))
+	}
+	switch {
+	case p.ResetRate > 0 && e.random() < p.ResetRate:
+		d.Reset = true
+	case p.ErrorRate > 0 && e.random() < p.ErrorRate:
+		d.Status = p.ErrorStatus
+	}
+	return d
+}
+
+// Wait waits for the delay of d, or until ctx is done
+func (d Decision) Wait(ctx context.Context) error {
+	return sleep(ctx, d.Delay)
+}
+
+// Throttle waits as long as sending n bytes takes at the bandwidth of d, or
+// until ctx is done
+func (d Decision) Throttle(ctx context.Context, n int64) error {
+	if d.Bandwidth <= 0 || n <= 0 {
+		return nil
+	}
+	return sleep(ctx, time.Duration(n)*time.Second/time.Duration(d.Bandwidth))
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Code returns the gRPC status code (the Connect codes share its values)
+// of an injected error with the HTTP status
+func Code(status int) int {
+	switch status {
+	case 400:
+		return 3 // InvalidArgument
+	case 401:
+		return 16 // Unauthenticated
+	case 403:
+		return 7 // PermissionDenied
+	case 404:
+		return 5 // NotFound
+	case 408, 504:
+		return 4 // DeadlineExceeded
+	case 409:
+		return 10 // Aborted
+	case 429:
+		return 8 // ResourceExhausted
+	case 499:
+		return 1 // Canceled
+	case 500:
+		return 13 // Internal
+	case 501:
+		return 12 // Unimplemented
+	case 502, 503:
+		return 14 // Unavailable
+	}
+	return 2 // Unknown
+}
+
+// Match reports whether key matches pattern, in which "*" matches any run
+// of characters, "/" included
+func Match(pattern, key string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == key
+	}
+	if !strings.HasPrefix(key, parts[0]) {
+		return false
+	}
+	key = key[len(parts[0]):]
+	last := parts[len(parts)-1]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(key, part)
+		if i < 0 {
+			return false
+		}
+		key = key[i+len(part):]
+	}
+	return len(key) >= len(last) && strings.HasSuffix(key, last)
+}
@@ -0,0 +1,239 @@
+package chaos
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// newEngine returns an engine in state s whose random draws are always r
+func newEngine(t *testing.T, s State, r float64) *Engine {
+	t.Helper()
+	e, err := New(Config{Profile: DefaultProfile()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.SetState(s); err != nil {
+		t.Fatal(err)
+	}
+	e.random = func() float64 { return r }
+	return e
+}
+
+func TestMatch(t *testing.T) {
+	for _, tt := range []struct {
+		pattern, key string
+		want         bool
+	}{
+		{"/get", "/get", true},
+		{"/get", "/get/1", false},
+		{"*", "/status/500", true},
+		{"/status/*", "/status/500", true},
+		{"/status/*", "/status", false},
+		{"GET /anything*", "GET /anything/a/b", true},
+		{"/echo.v1.Echo/*", "/echo.v1.Echo/ServerStream", true},
+		{"/echo.v1.Echo/*", "/grpc.health.v1.Health/Check", false},
+		{"*Stream", "/echo.v1.Echo/BidiStream", true},
+		{"a*b*c", "abbc", true},
+		{"a*bc*c", "abc", false},
+	} {
+		if got := Match(tt.pattern, tt.key); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.pattern, tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestDecide(t *testing.T) {
+	s := State{
+		Enabled: true,
+		Global:  Profile{LatencyMS: 100, JitterMS: 50, LatencyRate: 1, ErrorStatus: 503, Bandwidth: 1024},
+		Rules: []Rule{
+			{Match: "/status/*", Profile: Profile{LatencyRate: 1, ErrorRate: 0.5, ErrorStatus: 500}},
+			{Match: "/reset", Profile: Profile{LatencyRate: 1, ErrorRate: 1, ErrorStatus: 503, ResetRate: 0.5}},
+		},
+	}
+
+	// Draws below the rates inject the faults
+	e := newEngine(t, s, 0.4)
+	if d := e.Decide("/get"); d.Delay != 120*time.Millisecond || d.Bandwidth != 1024 || d.Status != 0 || d.Reset {
+		t.Errorf("global decision = %+v", d)
+	}
+	if d := e.Decide("/status/200", "GET /status/200"); d.Status != 500 || d.Delay != 0 || d.Bandwidth != 0 {
+		t.Errorf("rule decision = %+v", d)
+	}
+	if d := e.Decide("/reset"); !d.Reset || d.Status != 0 {
+		t.Errorf("reset decision = %+v, want a reset instead of an error", d)
+	}
+
+	// Draws above the rates do not
+	e = newEngine(t, s, 0.6)
+	if d := e.Decide("/status/200"); d.Status != 0 {
+		t.Errorf("decision = %+v, want no error", d)
+	}
+	if d := e.Decide("/reset"); d.Reset || d.Status != 503 {
+		t.Errorf("decision = %+v, want an error", d)
+	}
+
+	// A disabled engine injects nothing
+	s.Enabled = false
+	e = newEngine(t, s, 0)
+	if d := e.Decide("/reset"); d != (Decision{}) {
+		t.Errorf("disabled decision = %+v", d)
+	}
+}
+
+func TestNew(t *testing.T) {
+	p := Profile{LatencyMS: 10, LatencyRate: 1, ErrorStatus: 503}
+	e, err := New(Config{Enabled: true, Profile: p, Routes: []string{"/a", "/b/*"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := e.State()
+	if s.Global != DefaultProfile() || len(s.Rules) != 2 || s.Rules[1] != (Rule{Match: "/b/*", Profile: p}) {
+		t.Errorf("state = %+v", s)
+	}
+
+	if _, err := New(Config{Profile: Profile{ErrorStatus: 200}}); err == nil {
+		t.Error("New accepted error status 200")
+	}
+	if _, err := New(Config{Profile: Profile{ErrorStatus: 503, ErrorRate: 1.5}}); err == nil {
+		t.Error("New accepted error rate 1.5")
+	}
+}
+
+func TestHandler(t *testing.T) {
+	e, err := New(Config{Profile: DefaultProfile()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := Handler(e)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("PUT", Path, strings.NewReader(
+		`{"enabled":true,"global":{"latency_ms":5},"rules":[{"match":"/x","profile":{"error_rate":1}}]}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("PUT = %d %s", rec.Code, rec.Body)
+	}
+	s := e.State()
+	want := Rule{Match: "/x", Profile: Profile{LatencyRate: 1, ErrorRate: 1, ErrorStatus: 503}}
+	if !s.Enabled || s.Global.LatencyMS != 5 || s.Global.LatencyRate != 1 || len(s.Rules) != 1 || s.Rules[0] != want {
+		t.Errorf("state = %+v", s)
+	}
+
+	for _, body := range []string{
+		`{"enabled":true,"global":{"latency":5}}`,
+		`{"rules":[{"profile":{}}]}`,
+		`{"global":{"error_status":302}}`,
+		`{} {}`,
+	} {
+		rec = httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("PUT", Path, strings.NewReader(body)))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("PUT %s = %d, want 400", body, rec.Code)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("DELETE", Path, nil))
+	if rec.Code != http.StatusOK || e.State().Enabled {
+		t.Errorf("DELETE = %d, state %+v", rec.Code, e.State())
+	}
+}
+
+func TestHTTP(t *testing.T) {
+	e := newEngine(t, State{
+		Enabled: true,
+		Global:  DefaultProfile(),
+		Rules: []Rule{
+			{Match: "GET /fail", Profile: Profile{LatencyRate: 1, ErrorRate: 1, ErrorStatus: 429}},
+			{Match: "/reset", Profile: Profile{LatencyRate: 1, ResetRate: 1, ErrorStatus: 503}},
+			{Match: "/slow", Profile: Profile{LatencyRate: 1, ErrorStatus: 503, Bandwidth: 1000}},
+		},
+	}, 0)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(strings.Repeat("x", 200)))
+	})
+	srv := httptest.NewServer(HTTP(e)(mux))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/fail")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get(Header) != "error" {
+		t.Errorf("injected error = %d %q", resp.StatusCode, resp.Header.Get(Header))
+	}
+
+	if _, err := http.Get(srv.URL + "/reset"); err == nil {
+		t.Error("reset request succeeded")
+	}
+
+	// 200 bytes at 1000 B/s take about 200ms
+	start := time.Now()
+	resp, err = http.Get(srv.URL + "/slow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if len(body) != 200 || time.Since(start) < 150*time.Millisecond {
+		t.Errorf("throttled response: %d bytes in %v", len(body), time.Since(start))
+	}
+}
+
+func TestReset(t *testing.T) {
+	srv := httptest.NewServer(Resettable(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !Reset(r.Context()) {
+			t.Error("Reset = false")
+		}
+		_, _ = w.Write([]byte("discarded"))
+	})))
+	defer srv.Close()
+
+	if _, err := http.Get(srv.URL); err == nil {
+		t.Error("request on a reset connection succeeded")
+	}
+	if Reset(context.Background()) {
+		t.Error("Reset without Resettable = true")
+	}
+}
+
+func TestConnsReset(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, conns := TrackConns(l)
+	defer l.Close()
+
+	client, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	server, err := l.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !conns.Reset(client.LocalAddr()) {
+		t.Fatal("Reset = false for an open connection")
+	}
+	_ = client.SetReadDeadline(time.Now().Add(time.Second))
+	if _, err := client.Read(make([]byte, 1)); !errors.Is(err, syscall.ECONNRESET) {
+		t.Errorf("read after reset: %v, want ECONNRESET", err)
+	}
+	_ = server.Close()
+	if conns.Reset(client.LocalAddr()) {
+		t.Error("Reset = true for a closed connection")
+	}
+}
@@ -0,0 +1,86 @@
+package chaos
+
+import (
+	"net"
+	"sync"
+)
+
+// Conns tracks the open connections of a listener by remote address, so
+// servers whose handlers have no access to the connection of a request,
+// such as gRPC, can reset it
+type Conns struct {
+	mu    sync.Mutex
+	conns map[string]*trackedConn
+}
+
+// TrackConns returns l recording its accepted connections in the returned
+// Conns until they are closed
+func TrackConns(l net.Listener) (net.Listener, *Conns) {
+	c := &Conns{conns: make(map[string]*trackedConn)}
+	return &trackingListener{Listener: l, conns: c}, c
+}
+
+// Reset closes the connection from addr with a TCP RST, reporting whether
+// it was open
+func (c *Conns) Reset(addr net.Addr) bool {
+	if addr == nil {
+		return false
+	}
+	c.mu.Lock()
+	conn, ok := c.conns[addr.String()]
+	c.mu.Unlock()
+	if !ok {
+		return false
+	}
+	abort(conn.Conn)
+	_ = conn.Close()
+	return true
+}
+
+// abort makes conn send a TCP RST when closed, unwrapping connections with
+// a NetConn method such as TLS or bandwidth shaped ones
+func abort(conn net.Conn) {
+	for {
+		switch c := conn.(type) {
+		case *net.TCPConn:
+			_ = c.SetLinger(0)
+			return
+		case interface{ NetConn() net.Conn }:
+			conn = c.NetConn()
+		default:
+			return
+		}
+	}
+}
+
+type trackingListener struct {
+	net.Listener
+	conns *Conns
+}
+
+func (l *trackingListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	tracked := &trackedConn{Conn: conn, conns: l.conns}
+	l.conns.mu.Lock()
+	l.conns.conns[conn.RemoteAddr().String()] = tracked
+	l.conns.mu.Unlock()
+	return tracked, nil
+}
+
+type trackedConn struct {
+	net.Conn
+	conns *Conns
+	once  sync.Once
+}
+
+func (c *trackedConn) Close() error {
+	c.once.Do(func() {
+		c.conns.mu.Lock()
+		delete(c.conns.conns, c.RemoteAddr().String())
+		c.conns.mu.Unlock()
+	})
+	return c.Conn.Close()
+}
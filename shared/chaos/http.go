@@ -0,0 +1,201 @@
+package chaos
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/probitas-test/echo-servers/shared/internal/jsonhttp"
+)
+
+// Path is where the admin API is served
+const Path = "/chaos"
+
+// Header marks responses failed by an injected error
+const Header = "X-Chaos"
+
+// HTTP is middleware injecting the faults of e into every request but
+// those of the admin API. Rules match the path or the method and path of a
+// request. Resets close HTTP/1 connections with a TCP RST and reset HTTP/2
+// and HTTP/3 streams.
+func HTTP(e *Engine) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == Path {
+				next.ServeHTTP(w, r)
+				return
+			}
+			d := e.Decide(r.URL.Path, r.Method+" "+r.URL.Path)
+			if d.Wait(r.Context()) != nil {
+				return
+			}
+			switch {
+			case d.Reset:
+				if !reset(w) {
+					panic(http.ErrAbortHandler)
+				}
+			case d.Status != 0:
+				w.Header().Set(Header, "error")
+				http.Error(w, "chaos: injected error", d.Status)
+			case d.Bandwidth > 0:
+				next.ServeHTTP(&throttledWriter{ResponseWriter: w, ctx: r.Context(), decision: d}, r)
+			default:
+				next.ServeHTTP(w, r)
+			}
+		})
+	}
+}
+
+type resetKey struct{}
+
+// Resettable is middleware letting RPC layers served over HTTP, which have
+// no access to the response writer, reset the connection of a request with
+// Reset
+func Resettable(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw := &resettableWriter{ResponseWriter: w}
+		ctx := context.WithValue(r.Context(), resetKey{}, rw)
+		next.ServeHTTP(rw, r.WithContext(ctx))
+	})
+}
+
+// Reset resets the HTTP/1 connection of the request of ctx with a TCP RST;
+// the response written afterwards is discarded. It reports false when the
+// request is not served by Resettable or its connection cannot be taken
+// over (HTTP/2 and HTTP/3); handlers may then panic with
+// http.ErrAbortHandler to reset the stream.
+func Reset(ctx context.Context) bool {
+	rw, ok := ctx.Value(resetKey{}).(*resettableWriter)
+	if !ok {
+		return false
+	}
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	if !reset(rw.ResponseWriter) {
+		return false
+	}
+	rw.reset = true
+	return true
+}
+
+// reset takes over the connection of w and closes it, discarding unsent
+// data with an RST
+func reset(w http.ResponseWriter) bool {
+	conn, _, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		return false
+	}
+	abort(conn)
+	_ = conn.Close()
+	return true
+}
+
+// resettableWriter discards the response once its connection was reset
+type resettableWriter struct {
+	http.ResponseWriter
+	mu    sync.Mutex
+	reset bool
+}
+
+func (w *resettableWriter) WriteHeader(code int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.reset {
+		w.ResponseWriter.WriteHeader(code)
+	}
+}
+
+func (w *resettableWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.reset {
+		return 0, http.ErrHijacked
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *resettableWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.reset {
+		_ = http.NewResponseController(w.ResponseWriter).Flush()
+	}
+}
+
+func (w *resettableWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+func (w *resettableWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// throttledWriter writes and flushes the response in slices of a tenth of
+// the bandwidth, waiting as long as sending each slice takes
+type throttledWriter struct {
+	http.ResponseWriter
+	ctx      context.Context
+	decision Decision
+}
+
+func (w *throttledWriter) Write(p []byte) (int, error) {
+	slice := max(w.decision.Bandwidth/10, 1)
+	written := 0
+	for len(p) > 0 {
+		n, err := w.ResponseWriter.Write(p[:min(slice, len(p))])
+		written += n
+		if err != nil {
+			return written, err
+		}
+		_ = http.NewResponseController(w.ResponseWriter).Flush()
+		if err := w.decision.Throttle(w.ctx, int64(n)); err != nil {
+			return written, err
+		}
+		p = p[n:]
+	}
+	return written, nil
+}
+
+func (w *throttledWriter) Flush() {
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *throttledWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+func (w *throttledWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Handler serves the admin API of e:
+//
+//	GET    /chaos  current state
+//	PUT    /chaos  replace the state (omitted profile fields get defaults)
+//	DELETE /chaos  restore the startup state
+func Handler(e *Engine) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+		case http.MethodPut:
+			s := State{Global: DefaultProfile()}
+			err := jsonhttp.Decode(r, &s)
+			if err == nil {
+				err = e.SetState(s)
+			}
+			if err != nil {
+				jsonhttp.Error(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		case http.MethodDelete:
+			e.Reset()
+		default:
+			w.Header().Set("Allow", "GET, HEAD, PUT, DELETE")
+			jsonhttp.Error(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		jsonhttp.Write(w, http.StatusOK, e.State())
+	})
+}
@@ -0,0 +1,298 @@
+// Package clients applies behavior profiles to the requests of identified
+// clients, so multi-tenant stories such as one noisy or slow tenant can be
+// simulated: every profile injects its own faults and enforces its own rate
+// limit on the clients it matches by API key, client ID or source CIDR.
+//
+// Profiles are loaded from the YAML file of CLIENT_PROFILES_FILE and
+// replaced at runtime through the admin API (Handler). echo-http applies
+// them with the HTTP middleware, RPC servers with their own interceptors
+// calling Match and applying the chaos engine and rate limiter of the
+// matched profile.
+package clients
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"slices"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/probitas-test/echo-servers/shared/chaos"
+	"github.com/probitas-test/echo-servers/shared/config"
+	"github.com/probitas-test/echo-servers/shared/internal/jsonhttp"
+	"github.com/probitas-test/echo-servers/shared/ratelimit"
+)
+
+// Default headers identifying the client of a request
+const (
+	DefaultKeyHeader = ratelimit.DefaultKeyHeader
+	DefaultIDHeader  = "X-Client-ID"
+)
+
+// Config configures the profiles loaded at startup
+type Config struct {
+	File string
+	// KeyHeader and IDHeader carry the API key and the client ID matched
+	// against the profiles
+	KeyHeader string
+	IDHeader  string
+}
+
+// LoadConfig reads the CLIENT_PROFILES_* settings from src
+func LoadConfig(src *config.Source) Config {
+	return Config{
+		File:      src.String("CLIENT_PROFILES_FILE", ""),
+		KeyHeader: src.String("CLIENT_PROFILES_KEY_HEADER", DefaultKeyHeader),
+		IDHeader:  src.String("CLIENT_PROFILES_ID_HEADER", DefaultIDHeader),
+	}
+}
+
+// String describes the profiles for the startup log
+func (c Config) String() string {
+	if c.File == "" {
+		return "none"
+	}
+	return fmt.Sprintf("%s (keys by %s, IDs by %s)", c.File, c.KeyHeader, c.IDHeader)
+}
+
+// Profile is the behavior of the clients presenting one of APIKeys or
+// ClientIDs, or connecting from one of CIDRs
+type Profile struct {
+	Name      string   `json:"name"`
+	APIKeys   []string `json:"api_keys,omitempty"`
+	ClientIDs []string `json:"client_ids,omitempty"`
+	CIDRs     []string `json:"cidrs,omitempty"`
+	// Faults are injected into every request of the clients
+	Faults chaos.Profile `json:"faults"`
+	// RateLimit throttles the requests of the clients when set; its scope
+	// counts them together (global), per IP or per key header
+	RateLimit *ratelimit.Config `json:"rate_limit,omitempty"`
+}
+
+// UnmarshalJSON fills the faults missing from b with their defaults,
+// enables the rate limit when present and rejects unknown fields
+func (p *Profile) UnmarshalJSON(b []byte) error {
+	type plain Profile
+	v := plain{Faults: chaos.DefaultProfile()}
+	if err := jsonhttp.DecodeStrict(b, &v); err != nil {
+		return err
+	}
+	if v.RateLimit != nil {
+		v.RateLimit.Enabled = true
+	}
+	*p = Profile(v)
+	return nil
+}
+
+// Profiles is the list of profiles; the first matching a request applies
+type Profiles struct {
+	Profiles []Profile `json:"profiles"`
+}
+
+// Validate reports the first invalid profile
+func (ps Profiles) Validate() error {
+	_, err := compile(ps)
+	return err
+}
+
+// LoadFile reads the profiles of the YAML file path, rejecting unknown
+// fields. Fields are named as in the admin API.
+func LoadFile(path string) (Profiles, error) {
+	var ps Profiles
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return ps, err
+	}
+	// Decoded through JSON, so the YAML file gets the defaults and field
+	// names of the admin API
+	var v any
+	if err := yaml.Unmarshal(b, &v); err != nil {
+		return ps, fmt.Errorf("%s: %w", path, err)
+	}
+	b, err = json.Marshal(v)
+	if err != nil {
+		return ps, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := jsonhttp.DecodeStrict(b, &ps); err != nil {
+		return ps, fmt.Errorf("%s: %w", path, err)
+	}
+	return ps, ps.Validate()
+}
+
+// Match is the behavior applied to the requests of a matched client
+type Match struct {
+	// Profile names the matched profile
+	Profile string
+	// Faults injects the faults of the profile
+	Faults *chaos.Engine
+	// Limiter enforces the rate limit of the profile, allowing every
+	// request without one
+	Limiter *ratelimit.Limiter
+}
+
+// compiled is a profile ready to match requests
+type compiled struct {
+	profile  Profile
+	prefixes []netip.Prefix
+	match    Match
+}
+
+func compile(ps Profiles) ([]*compiled, error) {
+	names := map[string]bool{}
+	var cs []*compiled
+	for i, p := range ps.Profiles {
+		switch {
+		case p.Name == "":
+			return nil, fmt.Errorf("profile %d: name is required", i)
+		case names[p.Name]:
+			return nil, fmt.Errorf("profile %q: duplicate name", p.Name)
+		case len(p.APIKeys) == 0 && len(p.ClientIDs) == 0 && len(p.CIDRs) == 0:
+			return nil, fmt.Errorf("profile %q: at least one API key, client ID or CIDR is required", p.Name)
+		}
+		names[p.Name] = true
+		c := &compiled{profile: p, match: Match{Profile: p.Name}}
+		for _, cidr := range p.CIDRs {
+			prefix, err := netip.ParsePrefix(cidr)
+			if err != nil {
+				return nil, fmt.Errorf("profile %q: %w", p.Name, err)
+			}
+			c.prefixes = append(c.prefixes, prefix.Masked())
+		}
+		var err error
+		if c.match.Faults, err = chaos.New(chaos.Config{Enabled: true, Profile: p.Faults}); err != nil {
+			return nil, fmt.Errorf("profile %q: %w", p.Name, err)
+		}
+		limits := ratelimit.DefaultConfig()
+		if p.RateLimit != nil {
+			limits = *p.RateLimit
+		}
+		if c.match.Limiter, err = ratelimit.New(limits); err != nil {
+			return nil, fmt.Errorf("profile %q: invalid rate limit: %w", p.Name, err)
+		}
+		cs = append(cs, c)
+	}
+	return cs, nil
+}
+
+// matches reports whether a client with the address addr (invalid when
+// unknown), API key and client ID is one of the clients of c
+func (c *compiled) matches(addr netip.Addr, key, id string) bool {
+	if key != "" && slices.Contains(c.profile.APIKeys, key) {
+		return true
+	}
+	if id != "" && slices.Contains(c.profile.ClientIDs, id) {
+		return true
+	}
+	if addr.IsValid() {
+		for _, prefix := range c.prefixes {
+			if prefix.Contains(addr) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Engine matches clients with profiles replaced at runtime
+type Engine struct {
+	keyHeader string
+	idHeader  string
+	initial   Profiles
+
+	mu       sync.RWMutex
+	profiles Profiles
+	compiled []*compiled
+}
+
+// New creates an engine applying the profiles of c.File, if any
+func New(c Config) (*Engine, error) {
+	if c.KeyHeader == "" || c.IDHeader == "" {
+		return nil, errors.New("client profile key and ID headers are required")
+	}
+	ps := Profiles{Profiles: []Profile{}}
+	if c.File != "" {
+		var err error
+		if ps, err = LoadFile(c.File); err != nil {
+			return nil, err
+		}
+	}
+	e := &Engine{keyHeader: c.KeyHeader, idHeader: c.IDHeader, initial: ps}
+	if err := e.SetProfiles(ps); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// KeyHeader returns the header carrying the API key of a request
+func (e *Engine) KeyHeader() string {
+	return e.keyHeader
+}
+
+// IDHeader returns the header carrying the client ID of a request
+func (e *Engine) IDHeader() string {
+	return e.idHeader
+}
+
+// Profiles returns the profiles in effect
+func (e *Engine) Profiles() Profiles {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return Profiles{Profiles: slices.Clone(e.profiles.Profiles)}
+}
+
+// SetProfiles replaces the profiles, forgetting the clients counted by
+// their rate limits
+func (e *Engine) SetProfiles(ps Profiles) error {
+	cs, err := compile(ps)
+	if err != nil {
+		return err
+	}
+	if ps.Profiles == nil {
+		ps.Profiles = []Profile{}
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.profiles = ps
+	e.compiled = cs
+	return nil
+}
+
+// Reset restores the startup profiles
+func (e *Engine) Reset() {
+	_ = e.SetProfiles(e.initial)
+}
+
+// Clear forgets the clients counted by the rate limits of every profile
+func (e *Engine) Clear() {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	for _, c := range e.compiled {
+		c.match.Limiter.Clear()
+	}
+}
+
+// Match returns the behavior of the first profile matching the client at
+// remoteAddr presenting key and id, which may be empty
+func (e *Engine) Match(remoteAddr, key, id string) (Match, bool) {
+	host := remoteAddr
+	if h, _, err := net.SplitHostPort(remoteAddr); err == nil {
+		host = h
+	}
+	// Invalid when the address is unknown, matching no CIDR
+	addr, _ := netip.ParseAddr(host)
+	addr = addr.Unmap()
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	for _, c := range e.compiled {
+		if c.matches(addr, key, id) {
+			return c.match, true
+		}
+	}
+	return Match{}, false
+}
@@ -0,0 +1,160 @@
+package clients
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const tenants = `
+profiles:
+  - name: noisy
+    api_keys: [noisy-key]
+    client_ids: [noisy]
+    rate_limit:
+      limit: 2
+      window_ms: 60000
+      scope: global
+  - name: slow
+    cidrs: [10.0.0.0/8, "2001:db8::/32"]
+    faults:
+      latency_ms: 1
+  - name: broken
+    client_ids: [broken]
+    faults:
+      error_rate: 1
+      error_status: 503
+`
+
+func newEngine(t *testing.T, yaml string) *Engine {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "clients.yaml")
+	if err := os.WriteFile(path, []byte(yaml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	e, err := New(Config{File: path, KeyHeader: DefaultKeyHeader, IDHeader: DefaultIDHeader})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+func TestMatch(t *testing.T) {
+	e := newEngine(t, tenants)
+
+	tests := []struct {
+		remoteAddr, key, id string
+		want                string
+	}{
+		{"192.0.2.1:1234", "noisy-key", "", "noisy"},
+		{"192.0.2.1:1234", "", "noisy", "noisy"},
+		{"10.1.2.3:1234", "", "", "slow"},
+		{"[::ffff:10.1.2.3]:1234", "", "", "slow"},
+		{"[2001:db8::1]:1234", "", "", "slow"},
+		{"10.1.2.3:1234", "", "broken", "slow"},
+		{"192.0.2.1:1234", "other", "broken", "broken"},
+		{"192.0.2.1:1234", "other", "other", ""},
+		{"pipe", "", "", ""},
+	}
+	for _, tt := range tests {
+		m, ok := e.Match(tt.remoteAddr, tt.key, tt.id)
+		if m.Profile != tt.want || ok != (tt.want != "") {
+			t.Errorf("Match(%q, %q, %q) = %q, %v, want %q", tt.remoteAddr, tt.key, tt.id, m.Profile, ok, tt.want)
+		}
+	}
+}
+
+func TestLoadFileRejectsInvalidProfiles(t *testing.T) {
+	for name, yaml := range map[string]string{
+		"no identity":   "profiles: [{name: a}]",
+		"duplicate":     "profiles: [{name: a, client_ids: [a]}, {name: a, client_ids: [b]}]",
+		"bad cidr":      "profiles: [{name: a, cidrs: [10.0.0.0/33]}]",
+		"bad faults":    "profiles: [{name: a, client_ids: [a], faults: {error_status: 200}}]",
+		"bad limit":     "profiles: [{name: a, client_ids: [a], rate_limit: {limit: 0}}]",
+		"unknown field": "profiles: [{name: a, client_ids: [a], latency_ms: 1}]",
+	} {
+		path := filepath.Join(t.TempDir(), "clients.yaml")
+		if err := os.WriteFile(path, []byte(yaml), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadFile(path); err == nil {
+			t.Errorf("%s: LoadFile succeeded", name)
+		}
+	}
+}
+
+func TestHTTP(t *testing.T) {
+	e := newEngine(t, tenants)
+	h := HTTP(e)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	serve := func(id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/get", nil)
+		req.Header.Set(DefaultIDHeader, id)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// The noisy tenant exhausts its own quota, leaving the others alone
+	var codes []string
+	for range 3 {
+		rec := serve("noisy")
+		if got := rec.Header().Get(Header); got != "noisy" {
+			t.Errorf("%s = %q, want noisy", Header, got)
+		}
+		codes = append(codes, http.StatusText(rec.Code))
+	}
+	if got := strings.Join(codes, ","); got != "OK,OK,Too Many Requests" {
+		t.Errorf("noisy tenant got %s", got)
+	}
+	if rec := serve("other"); rec.Code != http.StatusOK || rec.Header().Get(Header) != "" {
+		t.Errorf("other client got %d with profile %q", rec.Code, rec.Header().Get(Header))
+	}
+	if rec := serve("broken"); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("broken tenant got %d, want 503", rec.Code)
+	}
+
+	// Clearing the rate limits restores the quota
+	e.Clear()
+	if rec := serve("noisy"); rec.Code != http.StatusOK {
+		t.Errorf("noisy tenant got %d after Clear, want 200", rec.Code)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	e := newEngine(t, tenants)
+	h := Handler(e)
+	do := func(method, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(method, Path, strings.NewReader(body)))
+		return rec
+	}
+
+	rec := do(http.MethodPut, `{"profiles": [{"name": "vip", "api_keys": ["vip-key"]}]}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("PUT: %d %s", rec.Code, rec.Body)
+	}
+	if m, ok := e.Match("192.0.2.1:1234", "vip-key", ""); !ok || m.Profile != "vip" {
+		t.Errorf("vip-key matched %q, %v after PUT", m.Profile, ok)
+	}
+	if ps := e.Profiles(); ps.Profiles[0].Faults.LatencyRate != 1 || ps.Profiles[0].Faults.ErrorStatus != 503 {
+		t.Errorf("faults = %+v, want the defaults", ps.Profiles[0].Faults)
+	}
+	if rec := do(http.MethodPut, `{"profiles": [{"name": "vip"}]}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("PUT without identity: %d, want 400", rec.Code)
+	}
+
+	if rec := do(http.MethodDelete, ""); rec.Code != http.StatusOK {
+		t.Fatalf("DELETE: %d", rec.Code)
+	}
+	if _, ok := e.Match("192.0.2.1:1234", "vip-key", ""); ok {
+		t.Error("vip-key still matched after DELETE")
+	}
+	if len(e.Profiles().Profiles) != 3 {
+		t.Errorf("%d profiles after DELETE, want 3", len(e.Profiles().Profiles))
+	}
+}
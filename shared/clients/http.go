@@ -0,0 +1,68 @@
+package clients
+
+import (
+	"net/http"
+
+	"github.com/probitas-test/echo-servers/shared/chaos"
+	"github.com/probitas-test/echo-servers/shared/internal/jsonhttp"
+	"github.com/probitas-test/echo-servers/shared/ratelimit"
+)
+
+// Path is where the admin API is served
+const Path = "/clients"
+
+// Header names the profile applied to a response
+const Header = "X-Client-Profile"
+
+// HTTP is middleware applying the profile of the client of every request
+// but the health checks at /health and those of the admin API: its rate
+// limit first, then its faults, like the rate limit and chaos middleware.
+func HTTP(e *Engine) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == Path || r.URL.Path == "/health" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			m, ok := e.Match(r.RemoteAddr, r.Header.Get(e.KeyHeader()), r.Header.Get(e.IDHeader()))
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+			w.Header().Set(Header, m.Profile)
+			ratelimit.HTTP(m.Limiter)(chaos.HTTP(m.Faults)(next)).ServeHTTP(w, r)
+		})
+	}
+}
+
+// Handler serves the admin API of e:
+//
+//	GET    /clients  current profiles
+//	PUT    /clients  replace the profiles (omitted faults get defaults)
+//	DELETE /clients  restore the startup profiles
+//
+// Changing the profiles forgets the clients counted by their rate limits.
+func Handler(e *Engine) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+		case http.MethodPut:
+			var ps Profiles
+			err := jsonhttp.Decode(r, &ps)
+			if err == nil {
+				err = e.SetProfiles(ps)
+			}
+			if err != nil {
+				jsonhttp.Error(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		case http.MethodDelete:
+			e.Reset()
+		default:
+			w.Header().Set("Allow", "GET, HEAD, PUT, DELETE")
+			jsonhttp.Error(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		jsonhttp.Write(w, http.StatusOK, e.Profiles())
+	})
+}
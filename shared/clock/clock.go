@@ -0,0 +1,191 @@
+// Package clock is a controllable clock for time-dependent behavior, so
+// expiration-based client logic (token refresh, key expiry, timeouts) can
+// be tested in milliseconds instead of real time. The clock runs at real
+// speed from an offset that is moved forward or back at runtime through
+// its admin API (Handler); sleepers wake up as soon as the clock passes
+// their deadline.
+//
+// A nil *Clock is the real clock, so handlers work without one.
+package clock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/probitas-test/echo-servers/shared/config"
+)
+
+// Config configures the clock at startup
+type Config struct {
+	// Offset is added to the real time
+	Offset time.Duration
+}
+
+// LoadConfig reads the CLOCK_OFFSET_MS setting from src
+func LoadConfig(src *config.Source) Config {
+	return Config{
+		Offset: time.Duration(src.Int("CLOCK_OFFSET_MS", 0)) * time.Millisecond,
+	}
+}
+
+// String describes the clock for the startup log
+func (c Config) String() string {
+	if c.Offset == 0 {
+		return "real time"
+	}
+	return "offset " + c.Offset.String()
+}
+
+// Clock is the real time moved by an offset changed at runtime
+type Clock struct {
+	initial time.Duration
+
+	mu     sync.Mutex
+	offset time.Duration
+	// changed is closed and replaced whenever the offset changes
+	changed chan struct{}
+}
+
+// New creates a clock at the offset of c
+func New(c Config) *Clock {
+	return &Clock{initial: c.Offset, offset: c.Offset, changed: make(chan struct{})}
+}
+
+// Now returns the time of the clock
+func (c *Clock) Now() time.Time {
+	if c == nil {
+		return time.Now()
+	}
+	return time.Now().Add(c.Offset())
+}
+
+// Since returns the time elapsed on the clock since t
+func (c *Clock) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+// Until returns the time left on the clock until t
+func (c *Clock) Until(t time.Time) time.Duration {
+	return t.Sub(c.Now())
+}
+
+// Offset returns the offset of the clock from the real time
+func (c *Clock) Offset() time.Duration {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.offset
+}
+
+// SetOffset moves the clock to the offset d from the real time
+func (c *Clock) SetOffset(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.offset = d
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+// Set moves the clock to t
+func (c *Clock) Set(t time.Time) {
+	c.SetOffset(time.Until(t))
+}
+
+// Advance moves the clock forward by d, or back with a negative d
+func (c *Clock) Advance(d time.Duration) {
+	c.SetOffset(c.Offset() + d)
+}
+
+// Reset restores the startup offset
+func (c *Clock) Reset() {
+	c.SetOffset(c.initial)
+}
+
+// Sleep waits until the clock passes d from now, returning early when the
+// clock is advanced past it, or ctx.Err() when ctx is done first
+func (c *Clock) Sleep(ctx context.Context, d time.Duration) error {
+	if c == nil {
+		t := time.NewTimer(d)
+		defer t.Stop()
+		select {
+		case <-t.C:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	deadline := c.Now().Add(d)
+	for {
+		c.mu.Lock()
+		left := deadline.Sub(time.Now().Add(c.offset))
+		changed := c.changed
+		c.mu.Unlock()
+		if left <= 0 {
+			return nil
+		}
+		t := time.NewTimer(left)
+		select {
+		case <-t.C:
+		case <-changed:
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		}
+		t.Stop()
+	}
+}
+
+// Update is the change of the clock requested through the admin API;
+// exactly one field is set
+type Update struct {
+	// Now moves the clock to a time
+	Now *time.Time `json:"now,omitempty"`
+	// OffsetMs moves the clock to an offset from the real time
+	OffsetMs *int64 `json:"offset_ms,omitempty"`
+	// AdvanceMs moves the clock forward, or back when negative
+	AdvanceMs *int64 `json:"advance_ms,omitempty"`
+}
+
+// Apply applies u to c
+func (u Update) Apply(c *Clock) error {
+	set := 0
+	for _, ok := range []bool{u.Now != nil, u.OffsetMs != nil, u.AdvanceMs != nil} {
+		if ok {
+			set++
+		}
+	}
+	if set != 1 {
+		return errors.New("exactly one of now, offset_ms and advance_ms is required")
+	}
+	switch {
+	case u.Now != nil:
+		c.Set(*u.Now)
+	case u.OffsetMs != nil:
+		c.SetOffset(time.Duration(*u.OffsetMs) * time.Millisecond)
+	default:
+		c.Advance(time.Duration(*u.AdvanceMs) * time.Millisecond)
+	}
+	return nil
+}
+
+// State is the time of a Clock, as served by the admin API
+type State struct {
+	Now      time.Time `json:"now"`
+	OffsetMs int64     `json:"offset_ms"`
+}
+
+// State returns the time and offset of c
+func (c *Clock) State() State {
+	offset := c.Offset()
+	return State{Now: time.Now().Add(offset), OffsetMs: offset.Milliseconds()}
+}
+
+// String describes the clock for logs
+func (c *Clock) String() string {
+	return fmt.Sprintf("now=%s offset=%s", c.Now().Format(time.RFC3339), c.Offset())
+}
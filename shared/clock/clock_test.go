@@ -0,0 +1,101 @@
+package clock
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAdvance(t *testing.T) {
+	c := New(Config{Offset: time.Hour})
+	start := c.Now()
+	if d := time.Until(start); d < 59*time.Minute {
+		t.Fatalf("startup offset ignored: %s", d)
+	}
+	c.Advance(24 * time.Hour)
+	if d := c.Since(start); d < 24*time.Hour || d > 24*time.Hour+time.Minute {
+		t.Errorf("Since after Advance = %s", d)
+	}
+	c.Reset()
+	if c.Offset() != time.Hour {
+		t.Errorf("Offset after Reset = %s", c.Offset())
+	}
+}
+
+func TestNil(t *testing.T) {
+	var c *Clock
+	if d := time.Since(c.Now()); d < 0 || d > time.Second {
+		t.Errorf("nil clock is %s off the real time", d)
+	}
+	if err := c.Sleep(context.Background(), time.Millisecond); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSleep(t *testing.T) {
+	c := New(Config{})
+	done := make(chan error, 1)
+	go func() { done <- c.Sleep(context.Background(), time.Hour) }()
+
+	time.Sleep(10 * time.Millisecond)
+	c.Advance(30 * time.Minute)
+	select {
+	case <-done:
+		t.Fatal("woke up before the deadline")
+	case <-time.After(10 * time.Millisecond):
+	}
+	c.Advance(30 * time.Minute)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Error(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("still sleeping after the clock passed the deadline")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := c.Sleep(ctx, time.Hour); err != context.Canceled {
+		t.Errorf("Sleep with a canceled context = %v", err)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	c := New(Config{})
+	h := Handler(c)
+
+	tests := []struct {
+		body   string
+		status int
+		offset time.Duration
+	}{
+		{`{"offset_ms": 60000}`, http.StatusOK, time.Minute},
+		{`{"advance_ms": -30000}`, http.StatusOK, 30 * time.Second},
+		{`{}`, http.StatusBadRequest, 30 * time.Second},
+		{`{"offset_ms": 1, "advance_ms": 1}`, http.StatusBadRequest, 30 * time.Second},
+		{`{"speed": 2}`, http.StatusBadRequest, 30 * time.Second},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("PUT", Path, strings.NewReader(tt.body)))
+		if rec.Code != tt.status || c.Offset() != tt.offset {
+			t.Errorf("PUT %s = %d, offset %s, want %d, %s", tt.body, rec.Code, c.Offset(), tt.status, tt.offset)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("PUT", Path, strings.NewReader(`{"now": "2100-01-01T00:00:00Z"}`)))
+	if rec.Code != http.StatusOK || c.Now().Year() != 2100 {
+		t.Errorf("PUT now = %d, clock at %s", rec.Code, c.Now())
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("DELETE", Path, nil))
+	if rec.Code != http.StatusOK || c.Offset() != 0 {
+		t.Errorf("DELETE = %d, offset %s", rec.Code, c.Offset())
+	}
+}
@@ -0,0 +1,40 @@
+package clock
+
+import (
+	"net/http"
+
+	"github.com/probitas-test/echo-servers/shared/internal/jsonhttp"
+)
+
+// Path is where the admin API is served
+const Path = "/clock"
+
+// Handler serves the admin API of c:
+//
+//	GET    /clock  current time and offset
+//	PUT    /clock  move the clock: {"now": "..."}, {"offset_ms": n} or {"advance_ms": n}
+//	DELETE /clock  restore the startup offset
+func Handler(c *Clock) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+		case http.MethodPut:
+			var u Update
+			err := jsonhttp.Decode(r, &u)
+			if err == nil {
+				err = u.Apply(c)
+			}
+			if err != nil {
+				jsonhttp.Error(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		case http.MethodDelete:
+			c.Reset()
+		default:
+			w.Header().Set("Allow", "GET, HEAD, PUT, DELETE")
+			jsonhttp.Error(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		jsonhttp.Write(w, http.StatusOK, c.State())
+	})
+}
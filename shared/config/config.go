@@ -0,0 +1,293 @@
+// Package config loads server configuration from environment variables, a
+// .env file and an optional YAML or JSON config file, and serves the
+// effective configuration for debugging.
+//
+// A value is taken from the environment when set (and not empty), then from
+// the config file named by CONFIG_FILE, then from the default. Keys of the
+// config file are the environment variable names, case-insensitive; nested
+// maps are joined with "_" and lists with ",":
+//
+//	port: 8080
+//	auth:
+//	  bearer_tokens: [a, b]   # AUTH_BEARER_TOKENS=a,b
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// FileEnv is the environment variable naming the config file
+const FileEnv = "CONFIG_FILE"
+
+// Where an effective value comes from
+const (
+	SourceEnv     = "env"
+	SourceFile    = "file"
+	SourceDefault = "default"
+)
+
+// redacted replaces the value of non-empty secrets in the effective
+// configuration
+const redacted = "[REDACTED]"
+
+// Value is an effective configuration value
+type Value struct {
+	Value  any    `json:"value"`
+	Source string `json:"source"`
+}
+
+// Source reads configuration values and records the effective ones. Invalid
+// values are reported by Err, and the value falls back to the default.
+type Source struct {
+	// env overrides the process environment (LoadEnv)
+	env        map[string]string
+	file       string
+	fileValues map[string]string
+
+	mu     sync.Mutex
+	values map[string]Value
+	errs   []error
+}
+
+// Load loads the .env file (if it exists) and the config file named by
+// CONFIG_FILE. A config file that cannot be read is reported by Err.
+func Load() *Source {
+	_ = godotenv.Load()
+	return newSource(nil, os.Getenv(FileEnv))
+}
+
+// LoadEnv is Load for one of the servers sharing a process (echo-all):
+// env overrides the process environment, and the config file is the one
+// named by CONFIG_FILE in env, if any. The .env file is loaded by the Load
+// of the process.
+func LoadEnv(env map[string]string) *Source {
+	return newSource(env, env[FileEnv])
+}
+
+func newSource(env map[string]string, path string) *Source {
+	s := &Source{env: env, fileValues: map[string]string{}, values: map[string]Value{}}
+	if path != "" {
+		s.file = path
+		if err := s.readFile(path); err != nil {
+			s.errs = append(s.errs, err)
+		}
+	}
+	return s
+}
+
+func (s *Source) readFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config file: %w", err)
+	}
+	// YAML is a superset of JSON, so one parser reads both
+	var doc map[string]any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+	flatten("", doc, s.fileValues)
+	return nil
+}
+
+// flatten stores the scalars of m by upper-case key, joining nested keys
+// with "_" and list items with ","
+func flatten(prefix string, m map[string]any, out map[string]string) {
+	for k, v := range m {
+		key := strings.ToUpper(prefix + k)
+		switch v := v.(type) {
+		case map[string]any:
+			flatten(key+"_", v, out)
+		case []any:
+			items := make([]string, 0, len(v))
+			for _, item := range v {
+				items = append(items, fmt.Sprint(item))
+			}
+			out[key] = strings.Join(items, ",")
+		case nil:
+			out[key] = ""
+		default:
+			out[key] = fmt.Sprint(v)
+		}
+	}
+}
+
+// lookup returns the raw value of key and where it comes from; an empty
+// value means the default applies
+func (s *Source) lookup(key string) (string, string) {
+	if v := s.env[key]; v != "" {
+		return v, SourceEnv
+	}
+	if v := os.Getenv(key); v != "" {
+		return v, SourceEnv
+	}
+	if v := s.fileValues[key]; v != "" {
+		return v, SourceFile
+	}
+	return "", SourceDefault
+}
+
+func (s *Source) record(key string, value any, source string, secret bool) {
+	if secret && source != SourceDefault {
+		value = redacted
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] = Value{Value: value, Source: source}
+}
+
+func (s *Source) invalid(key, kind, raw string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errs = append(s.errs, fmt.Errorf("%s: invalid %s %q", key, kind, raw))
+}
+
+// String returns the value of key, or defaultValue when it is not set
+func (s *Source) String(key, defaultValue string) string {
+	return s.str(key, defaultValue, false)
+}
+
+// Secret is String for passwords, tokens and keys, which are redacted in
+// the effective configuration
+func (s *Source) Secret(key, defaultValue string) string {
+	return s.str(key, defaultValue, true)
+}
+
+func (s *Source) str(key, defaultValue string, secret bool) string {
+	raw, source := s.lookup(key)
+	if source == SourceDefault {
+		raw = defaultValue
+	}
+	s.record(key, raw, source, secret && raw != "")
+	return raw
+}
+
+// Bool returns the value of key as a boolean (1, true, yes, on or 0, false,
+// no, off; case-insensitive), or defaultValue when it is not set
+func (s *Source) Bool(key string, defaultValue bool) bool {
+	raw, source := s.lookup(key)
+	value := defaultValue
+	if source != SourceDefault {
+		switch strings.ToLower(raw) {
+		case "1", "true", "yes", "on":
+			value = true
+		case "0", "false", "no", "off":
+			value = false
+		default:
+			s.invalid(key, "boolean", raw)
+			source = SourceDefault
+		}
+	}
+	s.record(key, value, source, false)
+	return value
+}
+
+// Int returns the value of key as an integer, or defaultValue when it is
+// not set
+func (s *Source) Int(key string, defaultValue int) int {
+	raw, source := s.lookup(key)
+	value := defaultValue
+	if source != SourceDefault {
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			s.invalid(key, "integer", raw)
+			source = SourceDefault
+		} else {
+			value = n
+		}
+	}
+	s.record(key, value, source, false)
+	return value
+}
+
+// Float returns the value of key as a floating-point number, or
+// defaultValue when it is not set
+func (s *Source) Float(key string, defaultValue float64) float64 {
+	raw, source := s.lookup(key)
+	value := defaultValue
+	if source != SourceDefault {
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			s.invalid(key, "number", raw)
+			source = SourceDefault
+		} else {
+			value = f
+		}
+	}
+	s.record(key, value, source, false)
+	return value
+}
+
+// List returns the comma-separated items of key (or of defaultValue when it
+// is not set). Empty items and surrounding whitespace are trimmed.
+func (s *Source) List(key, defaultValue string) []string {
+	return s.list(key, defaultValue, false)
+}
+
+// SecretList is List for tokens and keys, which are redacted in the
+// effective configuration
+func (s *Source) SecretList(key, defaultValue string) []string {
+	return s.list(key, defaultValue, true)
+}
+
+func (s *Source) list(key, defaultValue string, secret bool) []string {
+	raw, source := s.lookup(key)
+	if source == SourceDefault {
+		raw = defaultValue
+	}
+	var items []string
+	for item := range strings.SplitSeq(raw, ",") {
+		if trimmed := strings.TrimSpace(item); trimmed != "" {
+			items = append(items, trimmed)
+		}
+	}
+	var value any = items
+	if items == nil {
+		value = []string{}
+	}
+	s.record(key, value, source, secret && len(items) > 0)
+	return items
+}
+
+// Err reports invalid values, an unreadable config file, and config file
+// keys that were not read (typically typos). Call it after reading every
+// value.
+func (s *Source) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	errs := slices.Clone(s.errs)
+	for _, key := range slices.Sorted(maps.Keys(s.fileValues)) {
+		if _, ok := s.values[key]; !ok {
+			errs = append(errs, fmt.Errorf("config file %s: unknown key %s", s.file, key))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Values returns the effective configuration by key, with secrets redacted
+func (s *Source) Values() map[string]Value {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return maps.Clone(s.values)
+}
+
+// ServeHTTP serves the effective configuration as JSON
+func (s *Source) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body := struct {
+		File   string           `json:"file,omitempty"`
+		Values map[string]Value `json:"values"`
+	}{s.file, s.Values()}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,193 @@
+package config
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeConfigFile(t *testing.T, name, content string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(FileEnv, path)
+}
+
+func TestPrecedence(t *testing.T) {
+	writeConfigFile(t, "config.yaml", `
+port: 9000
+host: 127.0.0.1
+auth:
+  bearer_tokens: [a, b]
+`)
+	t.Setenv("PORT", "9100")
+
+	s := Load()
+	if got := s.String("PORT", "8080"); got != "9100" {
+		t.Errorf("PORT = %q, want the environment value", got)
+	}
+	if got := s.String("HOST", "0.0.0.0"); got != "127.0.0.1" {
+		t.Errorf("HOST = %q, want the file value", got)
+	}
+	if got := s.Int("LOG_SIZE", 100); got != 100 {
+		t.Errorf("LOG_SIZE = %d, want the default", got)
+	}
+	if got := s.List("AUTH_BEARER_TOKENS", ""); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("AUTH_BEARER_TOKENS = %v", got)
+	}
+	if err := s.Err(); err != nil {
+		t.Errorf("Err() = %v", err)
+	}
+
+	values := s.Values()
+	for key, source := range map[string]string{"PORT": SourceEnv, "HOST": SourceFile, "LOG_SIZE": SourceDefault} {
+		if values[key].Source != source {
+			t.Errorf("%s source = %q, want %q", key, values[key].Source, source)
+		}
+	}
+}
+
+func TestLoadEnv(t *testing.T) {
+	// The process config file is not the one of the server
+	writeConfigFile(t, "process.yaml", "host: 127.0.0.1\n")
+	path := filepath.Join(t.TempDir(), "server.yaml")
+	if err := os.WriteFile(path, []byte("log_size: 50\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PORT", "9100")
+	t.Setenv("TLS_ENABLED", "true")
+
+	s := LoadEnv(map[string]string{"PORT": "9200", FileEnv: path})
+	if got := s.String("PORT", "8080"); got != "9200" {
+		t.Errorf("PORT = %q, want the server environment", got)
+	}
+	if !s.Bool("TLS_ENABLED", false) {
+		t.Error("TLS_ENABLED of the process environment ignored")
+	}
+	if got := s.String("HOST", "0.0.0.0"); got != "0.0.0.0" {
+		t.Errorf("HOST = %q, want the default over the process config file", got)
+	}
+	if got := s.Int("LOG_SIZE", 100); got != 50 {
+		t.Errorf("LOG_SIZE = %d, want the server config file", got)
+	}
+}
+
+func TestJSONFile(t *testing.T) {
+	writeConfigFile(t, "config.json", `{"PORT": 9000, "TLS_ENABLED": true, "RATE": 0.5}`)
+
+	s := Load()
+	if s.Int("PORT", 0) != 9000 || !s.Bool("TLS_ENABLED", false) || s.Float("RATE", 0) != 0.5 {
+		t.Errorf("values = %v", s.Values())
+	}
+	if err := s.Err(); err != nil {
+		t.Errorf("Err() = %v", err)
+	}
+}
+
+func TestParse(t *testing.T) {
+	t.Setenv(FileEnv, "")
+	for _, v := range []string{"1", "true", "TRUE", "yes", "On"} {
+		t.Setenv("FLAG", v)
+		if !Load().Bool("FLAG", false) {
+			t.Errorf("Bool(%q) = false", v)
+		}
+	}
+	for _, v := range []string{"0", "false", "No", "off"} {
+		t.Setenv("FLAG", v)
+		if Load().Bool("FLAG", true) {
+			t.Errorf("Bool(%q) = true", v)
+		}
+	}
+
+	t.Setenv("LIST", " a ,, b,")
+	if got := Load().List("LIST", ""); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("List() = %v", got)
+	}
+	if got := Load().List("UNSET_LIST", ""); got != nil {
+		t.Errorf("List() of an unset key = %v, want nil", got)
+	}
+	if got := Load().List("UNSET_LIST", "x,y"); !reflect.DeepEqual(got, []string{"x", "y"}) {
+		t.Errorf("List() default = %v", got)
+	}
+}
+
+func TestErr(t *testing.T) {
+	writeConfigFile(t, "config.yaml", "port: 8080\nprot: 1\n")
+	t.Setenv("SIZE", "big")
+	t.Setenv("ENABLED", "maybe")
+	t.Setenv("RATE", "half")
+
+	s := Load()
+	_ = s.String("PORT", "")
+	if got := s.Int("SIZE", 10); got != 10 {
+		t.Errorf("invalid Int() = %d, want the default", got)
+	}
+	if got := s.Bool("ENABLED", true); !got {
+		t.Error("invalid Bool() = false, want the default")
+	}
+	_ = s.Float("RATE", 0)
+
+	err := s.Err()
+	if err == nil {
+		t.Fatal("Err() = nil")
+	}
+	for _, want := range []string{`SIZE: invalid integer "big"`, `ENABLED: invalid boolean "maybe"`, `RATE: invalid number "half"`, "unknown key PROT"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Err() = %v, want %q", err, want)
+		}
+	}
+}
+
+func TestErrFile(t *testing.T) {
+	t.Setenv(FileEnv, filepath.Join(t.TempDir(), "missing.yaml"))
+	if err := Load().Err(); err == nil {
+		t.Error("Err() with a missing config file = nil")
+	}
+
+	writeConfigFile(t, "config.yaml", "port: [unclosed\n")
+	if err := Load().Err(); err == nil {
+		t.Error("Err() with an invalid config file = nil")
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	writeConfigFile(t, "config.yaml", "password: hunter2\n")
+	t.Setenv("TOKENS", "t1,t2")
+
+	s := Load()
+	_ = s.Secret("PASSWORD", "")
+	_ = s.Secret("API_KEY", "")
+	_ = s.SecretList("TOKENS", "")
+	_ = s.String("NAME", "echo")
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest("GET", "/config", nil))
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if strings.Contains(rec.Body.String(), "hunter2") || strings.Contains(rec.Body.String(), "t1") {
+		t.Errorf("secret in response: %s", rec.Body.String())
+	}
+
+	var body struct {
+		File   string           `json:"file"`
+		Values map[string]Value `json:"values"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.File != os.Getenv(FileEnv) {
+		t.Errorf("file = %q", body.File)
+	}
+	for key, want := range map[string]any{"PASSWORD": redacted, "TOKENS": redacted, "API_KEY": "", "NAME": "echo"} {
+		if got := body.Values[key].Value; got != want {
+			t.Errorf("%s = %v, want %v", key, got, want)
+		}
+	}
+}
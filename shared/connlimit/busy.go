@@ -0,0 +1,74 @@
+package connlimit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+)
+
+// Message is the reason given to rejected clients
+const Message = "too many connections"
+
+// http2Preface starts the connections of HTTP/2 clients with prior
+// knowledge (h2c, gRPC)
+const http2Preface = "PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n"
+
+// HTTP answers cleartext HTTP/1 clients with 503 Service Unavailable and
+// HTTP/2 clients with prior knowledge (gRPC, h2c) with a GOAWAY frame
+// carrying ENHANCE_YOUR_CALM, which gRPC clients report as UNAVAILABLE.
+func HTTP(c net.Conn) {
+	br := bufio.NewReader(c)
+	if p, err := br.Peek(3); err == nil && string(p) == http2Preface[:3] {
+		_, _ = io.CopyN(io.Discard, br, int64(len(http2Preface)))
+		_, _ = c.Write(goAway())
+		return
+	}
+	// Read the request head so the client reads the response instead of a
+	// failed write
+	if req, err := http.ReadRequest(br); err == nil {
+		_ = req.Body.Close()
+	}
+	body := fmt.Sprintf("{\"error\":%q}\n", Message)
+	_, _ = fmt.Fprintf(c, "HTTP/1.1 503 Service Unavailable\r\n"+
+		"Content-Type: application/json\r\n"+
+		"Content-Length: %d\r\n"+
+		"Retry-After: 1\r\n"+
+		"Connection: close\r\n\r\n%s", len(body), body)
+}
+
+// goAway returns an empty SETTINGS frame, which must start the server side
+// of an HTTP/2 connection, followed by a GOAWAY frame refusing every stream
+func goAway() []byte {
+	const (
+		frameSettings    = 0x4
+		frameGoAway      = 0x7
+		enhanceYourCalm  = 0xb
+		frameHeaderBytes = 9
+	)
+	var b bytes.Buffer
+	frame := func(typ byte, payload []byte) {
+		h := make([]byte, frameHeaderBytes)
+		h[0], h[1], h[2] = byte(len(payload)>>16), byte(len(payload)>>8), byte(len(payload))
+		h[3] = typ
+		b.Write(h) // flags and stream 0
+		b.Write(payload)
+	}
+	frame(frameSettings, nil)
+	payload := binary.BigEndian.AppendUint32(nil, 0) // last stream ID
+	payload = binary.BigEndian.AppendUint32(payload, enhanceYourCalm)
+	frame(frameGoAway, append(payload, Message...))
+	return b.Bytes()
+}
+
+// Line answers with msg as soon as the connection is accepted, for
+// protocols where the server speaks first or answers every command with a
+// line, such as FTP (421) or Redis (-ERR).
+func Line(msg string) Busy {
+	return func(c net.Conn) {
+		_, _ = io.WriteString(c, msg)
+	}
+}
@@ -0,0 +1,213 @@
+// Package connlimit caps the number of connections a server keeps open at
+// once, so client connection pools and queueing can be tested against a
+// saturated server. Connections accepted beyond the cap get the "server
+// busy" error of the protocol (Busy) and are closed; they are never handed
+// to the server.
+//
+// A Limiter is configured with the CONN_LIMIT_* settings and changed at
+// runtime through its admin API (Handler). Servers wrap their listeners
+// with Listen.
+package connlimit
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/probitas-test/echo-servers/shared/config"
+	"github.com/probitas-test/echo-servers/shared/internal/jsonhttp"
+)
+
+// DefaultMax is the cap of the default configuration
+const DefaultMax = 100
+
+// rejectTimeout bounds the time spent answering a rejected connection
+const rejectTimeout = 2 * time.Second
+
+// Config configures the connection cap
+type Config struct {
+	Enabled bool `json:"enabled"`
+	// Max is the number of connections kept open at once; the next ones are
+	// rejected until one is closed
+	Max int `json:"max"`
+}
+
+// LoadConfig reads the CONN_LIMIT_* settings from src
+func LoadConfig(src *config.Source) Config {
+	return Config{
+		Enabled: src.Bool("CONN_LIMIT_ENABLED", false),
+		Max:     src.Int("CONN_LIMIT_MAX", DefaultMax),
+	}
+}
+
+// DefaultConfig returns the configuration used for the fields omitted in
+// the admin API
+func DefaultConfig() Config {
+	return Config{Max: DefaultMax}
+}
+
+// UnmarshalJSON fills the fields missing from b with their defaults and
+// rejects unknown fields
+func (c *Config) UnmarshalJSON(b []byte) error {
+	type plain Config
+	v := plain(DefaultConfig())
+	if err := jsonhttp.DecodeStrict(b, &v); err != nil {
+		return err
+	}
+	*c = Config(v)
+	return nil
+}
+
+// Validate reports the first invalid setting
+func (c Config) Validate() error {
+	if c.Max < 0 {
+		return errors.New("max must not be negative")
+	}
+	return nil
+}
+
+// String describes the cap for the startup log
+func (c Config) String() string {
+	if !c.Enabled {
+		return "disabled"
+	}
+	return fmt.Sprintf("max=%d", c.Max)
+}
+
+// Busy answers a connection rejected by a Limiter with the "server busy"
+// error of a protocol before it is closed. The connection has a deadline.
+type Busy func(c net.Conn)
+
+// Limiter caps the connections accepted by its listeners under a
+// configuration replaced at runtime. The cap is shared by every listener of
+// the limiter.
+type Limiter struct {
+	initial  Config
+	cfg      atomic.Pointer[Config]
+	conns    atomic.Int64
+	rejected atomic.Int64
+}
+
+// New creates a limiter with the startup configuration c
+func New(c Config) (*Limiter, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	l := &Limiter{initial: c}
+	l.cfg.Store(&c)
+	return l, nil
+}
+
+// Config returns the configuration in effect
+func (l *Limiter) Config() Config {
+	return *l.cfg.Load()
+}
+
+// SetConfig replaces the configuration. Lowering the cap closes no
+// connection; new ones are rejected until enough are closed.
+func (l *Limiter) SetConfig(c Config) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	l.cfg.Store(&c)
+	return nil
+}
+
+// Reset restores the startup configuration
+func (l *Limiter) Reset() {
+	c := l.initial
+	l.cfg.Store(&c)
+}
+
+// Conns returns the number of open connections accepted by l
+func (l *Limiter) Conns() int {
+	return int(l.conns.Load())
+}
+
+// Rejected returns the number of connections rejected since startup
+func (l *Limiter) Rejected() int {
+	return int(l.rejected.Load())
+}
+
+// Listen returns ln with the connections beyond the cap answered by busy
+// and closed. A nil busy closes them without a word, for protocols without
+// a busy error or listeners serving TLS.
+func (l *Limiter) Listen(ln net.Listener, busy Busy) net.Listener {
+	return &listener{Listener: ln, limiter: l, busy: busy}
+}
+
+// acquire counts a new connection, reporting false when it is beyond the
+// cap
+func (l *Limiter) acquire() bool {
+	n := l.conns.Add(1)
+	cfg := l.Config()
+	if cfg.Enabled && n > int64(cfg.Max) {
+		l.conns.Add(-1)
+		l.rejected.Add(1)
+		return false
+	}
+	return true
+}
+
+type listener struct {
+	net.Listener
+	limiter *Limiter
+	busy    Busy
+}
+
+// Accept returns the next connection within the cap, rejecting the others
+// in the background
+func (l *listener) Accept() (net.Conn, error) {
+	for {
+		c, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+		if l.limiter.acquire() {
+			return &conn{Conn: c, limiter: l.limiter}, nil
+		}
+		go reject(c, l.busy)
+	}
+}
+
+// reject answers c with busy and closes it once the client has read the
+// answer, so the close does not reset the connection under unread request
+// bytes
+func reject(c net.Conn, busy Busy) {
+	defer func() { _ = c.Close() }()
+	_ = c.SetDeadline(time.Now().Add(rejectTimeout))
+	if busy == nil {
+		return
+	}
+	busy(c)
+	if cw, ok := c.(interface{ CloseWrite() error }); ok {
+		_ = cw.CloseWrite()
+	}
+	buf := make([]byte, 512)
+	for {
+		if _, err := c.Read(buf); err != nil {
+			return
+		}
+	}
+}
+
+// conn releases its place under the cap when closed
+type conn struct {
+	net.Conn
+	limiter *Limiter
+	once    sync.Once
+}
+
+// NetConn returns the limited connection, so chaos resets reach the TCP
+// connection
+func (c *conn) NetConn() net.Conn {
+	return c.Conn
+}
+
+func (c *conn) Close() error {
+	c.once.Do(func() { c.limiter.conns.Add(-1) })
+	return c.Conn.Close()
+}
@@ -0,0 +1,167 @@
+package connlimit
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// serve accepts connections on a listener limited by l until the test ends,
+// closing them when their client does, and returns its address
+func serve(t *testing.T, l *Limiter, busy Busy) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln = l.Listen(ln, busy)
+	t.Cleanup(func() { _ = ln.Close() })
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				_, _ = io.Copy(io.Discard, c)
+				_ = c.Close()
+			}()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func dial(t *testing.T, addr string) net.Conn {
+	t.Helper()
+	c, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	_ = c.SetDeadline(time.Now().Add(2 * time.Second))
+	return c
+}
+
+// waitConns waits until l counts n open connections
+func waitConns(t *testing.T, l *Limiter, n int) {
+	t.Helper()
+	for deadline := time.Now().Add(time.Second); l.Conns() != n; {
+		if time.Now().After(deadline) {
+			t.Fatalf("Conns = %d, want %d", l.Conns(), n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestLimit(t *testing.T) {
+	l, err := New(Config{Enabled: true, Max: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := serve(t, l, Line("-ERR max number of clients reached\r\n"))
+	first := dial(t, addr)
+	dial(t, addr)
+	waitConns(t, l, 2)
+
+	line, err := bufio.NewReader(dial(t, addr)).ReadString('\n')
+	if err != nil || line != "-ERR max number of clients reached\r\n" {
+		t.Errorf("rejected connection read %q, %v", line, err)
+	}
+	if l.Rejected() != 1 {
+		t.Errorf("Rejected = %d, want 1", l.Rejected())
+	}
+
+	_ = first.Close()
+	waitConns(t, l, 1)
+	dial(t, addr)
+	waitConns(t, l, 2)
+}
+
+func TestDisabled(t *testing.T) {
+	l, err := New(Config{Max: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := serve(t, l, nil)
+	for range 3 {
+		dial(t, addr)
+	}
+	waitConns(t, l, 3)
+
+	// Enabling the cap keeps the open connections
+	if err := l.SetConfig(Config{Enabled: true, Max: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dial(t, addr).Read(make([]byte, 1)); err != io.EOF {
+		t.Errorf("rejected connection read %v, want EOF", err)
+	}
+	waitConns(t, l, 3)
+}
+
+func TestHTTP(t *testing.T) {
+	l, err := New(Config{Enabled: true, Max: 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := serve(t, l, HTTP)
+
+	c := dial(t, addr)
+	_, _ = io.WriteString(c, "GET / HTTP/1.1\r\nHost: echo\r\n\r\n")
+	resp, err := http.ReadResponse(bufio.NewReader(c), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusServiceUnavailable || resp.Header.Get("Retry-After") == "" || !strings.Contains(string(body), Message) {
+		t.Errorf("HTTP/1 = %d %q", resp.StatusCode, body)
+	}
+
+	c = dial(t, addr)
+	_, _ = io.WriteString(c, http2Preface)
+	b, err := io.ReadAll(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// SETTINGS (9 bytes), then a GOAWAY frame header
+	if len(b) < 18 || b[3] != 0x4 || b[12] != 0x7 || !strings.HasSuffix(string(b), Message) {
+		t.Errorf("HTTP/2 = %x", b)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	l, err := New(DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := Handler(l)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("PUT", Path, strings.NewReader(`{"enabled":true,"max":5}`)))
+	var st State
+	if err := json.Unmarshal(rec.Body.Bytes(), &st); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusOK || !st.Config.Enabled || st.Config.Max != 5 {
+		t.Errorf("PUT %s = %d %+v", Path, rec.Code, st)
+	}
+
+	for _, body := range []string{`{"max":-1}`, `{"unknown":1}`} {
+		rec = httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("PUT", Path, strings.NewReader(body)))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("PUT %s %s = %d, want 400", Path, body, rec.Code)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("DELETE", Path, nil))
+	if rec.Code != http.StatusOK || l.Config().Enabled {
+		t.Errorf("DELETE %s = %d, config %+v", Path, rec.Code, l.Config())
+	}
+}
@@ -0,0 +1,48 @@
+package connlimit
+
+import (
+	"net/http"
+
+	"github.com/probitas-test/echo-servers/shared/internal/jsonhttp"
+)
+
+// Path is where the admin API is served
+const Path = "/connlimit"
+
+// Handler serves the admin API of l:
+//
+//	GET    /connlimit  current configuration, open and rejected connections
+//	PUT    /connlimit  replace the configuration (omitted fields get defaults)
+//	DELETE /connlimit  restore the startup configuration
+func Handler(l *Limiter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+		case http.MethodPut:
+			var c Config
+			err := jsonhttp.Decode(r, &c)
+			if err == nil {
+				err = l.SetConfig(c)
+			}
+			if err != nil {
+				jsonhttp.Error(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		case http.MethodDelete:
+			l.Reset()
+		default:
+			w.Header().Set("Allow", "GET, HEAD, PUT, DELETE")
+			jsonhttp.Error(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		jsonhttp.Write(w, http.StatusOK, State{Config: l.Config(), Conns: l.Conns(), Rejected: l.Rejected()})
+	})
+}
+
+// State is the configuration of a Limiter with its open and rejected
+// connections, as served by the admin API
+type State struct {
+	Config   Config `json:"config"`
+	Conns    int    `json:"connections"`
+	Rejected int    `json:"rejected"`
+}
@@ -0,0 +1,391 @@
+// Package credentials is the store of test identities shared by the
+// servers, so one user authenticates the same way against every protocol:
+// its password over Basic authentication (and the OAuth2 password grant of
+// echo-http), its bearer tokens, and its API keys.
+//
+// Users are loaded from CREDENTIALS_USERS and the YAML file of
+// CREDENTIALS_FILE, and replaced at runtime through the admin API
+// (Handler). echo-http checks them on its authentication endpoints; RPC
+// and GraphQL servers reject the requests without credentials of a user
+// while the store is Required.
+package credentials
+
+import (
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/probitas-test/echo-servers/shared/clock"
+	"github.com/probitas-test/echo-servers/shared/config"
+	"github.com/probitas-test/echo-servers/shared/internal/jsonhttp"
+)
+
+// APIKeyHeader carries the API key of a request (x-api-key metadata over
+// gRPC)
+const APIKeyHeader = "X-Api-Key"
+
+// Errors of Authenticate
+var (
+	ErrMissing = errors.New("missing credentials")
+	ErrInvalid = errors.New("invalid credentials")
+	ErrExpired = errors.New("expired token")
+)
+
+// Credential types of an Identity
+const (
+	TypeNone   = "none"
+	TypeBasic  = "basic"
+	TypeBearer = "bearer"
+	TypeJWT    = "jwt"
+	TypeAPIKey = "api_key"
+)
+
+// AnyIssuer as the JWT issuer accepts the JWTs of every issuer
+const AnyIssuer = "*"
+
+// Config configures the users loaded at startup
+type Config struct {
+	// Users are "username:password" entries
+	Users []string
+	// File is a YAML file of Credentials, for tokens and API keys
+	File string
+	// Required makes RPC and GraphQL servers reject the requests without
+	// credentials
+	Required bool
+	// JWTIssuer accepts the unexpired bearer JWTs of this issuer, such as
+	// the ID tokens of the echo-http OAuth2 server
+	JWTIssuer string
+}
+
+// LoadConfig reads the CREDENTIALS_* settings from src
+func LoadConfig(src *config.Source) Config {
+	return Config{
+		Users:     src.SecretList("CREDENTIALS_USERS", ""),
+		File:      src.String("CREDENTIALS_FILE", ""),
+		Required:  src.Bool("CREDENTIALS_REQUIRED", false),
+		JWTIssuer: src.String("CREDENTIALS_JWT_ISSUER", ""),
+	}
+}
+
+// String describes the users for the startup log
+func (c Config) String() string {
+	var users []string
+	for _, u := range c.Users {
+		name, _, _ := strings.Cut(u, ":")
+		users = append(users, name)
+	}
+	s := fmt.Sprintf("users=%v", users)
+	if c.File != "" {
+		s += " file=" + c.File
+	}
+	if c.JWTIssuer != "" {
+		s += " jwt_issuer=" + c.JWTIssuer
+	}
+	return fmt.Sprintf("%s required=%v", s, c.Required)
+}
+
+// User is a test identity
+type User struct {
+	Username string `json:"username"`
+	// Password authenticates the user over Basic authentication; it also
+	// derives a bearer token (Token)
+	Password string   `json:"password,omitempty"`
+	Tokens   []string `json:"tokens,omitempty"`
+	APIKeys  []string `json:"api_keys,omitempty"`
+}
+
+// Token returns the bearer token derived from the password of u, the hex
+// SHA1 of "username:password", or "" without a password
+func (u User) Token() string {
+	if u.Password == "" {
+		return ""
+	}
+	sum := sha1.Sum([]byte(u.Username + ":" + u.Password))
+	return hex.EncodeToString(sum[:])
+}
+
+// Credentials is the content of the store
+type Credentials struct {
+	Required  bool   `json:"required"`
+	JWTIssuer string `json:"jwt_issuer,omitempty"`
+	Users     []User `json:"users"`
+}
+
+// Validate reports the first invalid user
+func (c Credentials) Validate() error {
+	names := map[string]bool{}
+	for i, u := range c.Users {
+		switch {
+		case u.Username == "":
+			return fmt.Errorf("user %d: username is required", i)
+		case names[u.Username]:
+			return fmt.Errorf("user %q: duplicate username", u.Username)
+		case u.Password == "" && len(u.Tokens) == 0 && len(u.APIKeys) == 0:
+			return fmt.Errorf("user %q: a password, token or API key is required", u.Username)
+		}
+		names[u.Username] = true
+	}
+	return nil
+}
+
+// LoadFile reads the Credentials of the YAML file path, rejecting unknown
+// fields. Fields are named as in the admin API.
+func LoadFile(path string) (Credentials, error) {
+	var c Credentials
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return c, err
+	}
+	var v any
+	if err := yaml.Unmarshal(b, &v); err != nil {
+		return c, fmt.Errorf("%s: %w", path, err)
+	}
+	b, err = json.Marshal(v)
+	if err != nil {
+		return c, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := jsonhttp.DecodeStrict(b, &c); err != nil {
+		return c, fmt.Errorf("%s: %w", path, err)
+	}
+	return c, c.Validate()
+}
+
+// Store authenticates requests against users replaced at runtime
+type Store struct {
+	initial Credentials
+	// clock is the time of JWT expiry, the real time when nil
+	clock *clock.Clock
+
+	mu    sync.RWMutex
+	creds Credentials
+}
+
+// New creates a store of the users of c and its file, if any, then extra
+// users such as the ones of server-specific settings
+func New(c Config, extra ...User) (*Store, error) {
+	creds := Credentials{Required: c.Required, JWTIssuer: c.JWTIssuer, Users: []User{}}
+	for _, entry := range c.Users {
+		name, password, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, fmt.Errorf("CREDENTIALS_USERS: %q is not username:password", name)
+		}
+		creds.Users = append(creds.Users, User{Username: name, Password: password})
+	}
+	if c.File != "" {
+		file, err := LoadFile(c.File)
+		if err != nil {
+			return nil, err
+		}
+		creds.Users = append(creds.Users, file.Users...)
+		creds.Required = creds.Required || file.Required
+		if file.JWTIssuer != "" {
+			creds.JWTIssuer = file.JWTIssuer
+		}
+	}
+	creds.Users = append(creds.Users, extra...)
+	s := &Store{initial: creds}
+	if err := s.Set(creds); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// SetClock sets the clock the expiry of bearer JWTs is checked on, so
+// advancing it expires them
+func (s *Store) SetClock(clk *clock.Clock) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clock = clk
+}
+
+// Credentials returns the content of the store
+func (s *Store) Credentials() Credentials {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return Credentials{Required: s.creds.Required, JWTIssuer: s.creds.JWTIssuer, Users: slices.Clone(s.creds.Users)}
+}
+
+// Set replaces the content of the store
+func (s *Store) Set(c Credentials) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	if c.Users == nil {
+		c.Users = []User{}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.creds = c
+	return nil
+}
+
+// Reset restores the startup users
+func (s *Store) Reset() {
+	_ = s.Set(s.initial)
+}
+
+// Required reports whether RPC and GraphQL servers require credentials
+func (s *Store) Required() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.creds.Required
+}
+
+// find returns the first user for which match is true
+func (s *Store) find(match func(User) bool) (User, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, u := range s.creds.Users {
+		if match(u) {
+			return u, true
+		}
+	}
+	return User{}, false
+}
+
+// Basic returns the user with username and password
+func (s *Store) Basic(username, password string) (User, bool) {
+	return s.find(func(u User) bool {
+		return u.Password != "" && equal(u.Username, username) && equal(u.Password, password)
+	})
+}
+
+// Bearer returns the user with the bearer token, one of its Tokens or the
+// token derived from its password
+func (s *Store) Bearer(token string) (User, bool) {
+	return s.find(func(u User) bool {
+		return token != "" && (contains(u.Tokens, token) || equal(u.Token(), token))
+	})
+}
+
+// APIKey returns the user with the API key
+func (s *Store) APIKey(key string) (User, bool) {
+	return s.find(func(u User) bool {
+		return key != "" && contains(u.APIKeys, key)
+	})
+}
+
+// JWT returns the claims of a bearer JWT of the JWT issuer, unexpired on
+// the clock of the store.
+// Signatures are not verified, like the unsigned ID tokens of the mock
+// OAuth2 server.
+func (s *Store) JWT(token string) (map[string]any, error) {
+	s.mu.RLock()
+	issuer, clk := s.creds.JWTIssuer, s.clock
+	s.mu.RUnlock()
+	claims, ok := parseJWT(token)
+	if !ok || issuer == "" || (issuer != AnyIssuer && claims["iss"] != issuer) {
+		return nil, ErrInvalid
+	}
+	if exp, ok := claims["exp"].(float64); ok && clk.Now().Unix() >= int64(exp) {
+		return claims, ErrExpired
+	}
+	return claims, nil
+}
+
+// Identity describes the credentials of a request
+type Identity struct {
+	// Type is the credential type, TypeNone without credentials
+	Type string `json:"type"`
+	// User is the authenticated user; for a JWT, its subject
+	User User `json:"user"`
+	// Claims are the claims of a JWT
+	Claims map[string]any `json:"claims,omitempty"`
+}
+
+// Identify returns the identity of a request with the Authorization header
+// authorization (Basic or Bearer) and the API key header apiKey, either of
+// which may be empty. The identity has the type of the credentials even
+// when they are rejected.
+func (s *Store) Identify(authorization, apiKey string) (Identity, error) {
+	if apiKey != "" {
+		if u, ok := s.APIKey(apiKey); ok {
+			return Identity{Type: TypeAPIKey, User: u}, nil
+		}
+		return Identity{Type: TypeAPIKey}, ErrInvalid
+	}
+	if authorization == "" {
+		return Identity{Type: TypeNone}, ErrMissing
+	}
+	scheme, value, _ := strings.Cut(authorization, " ")
+	value = strings.TrimSpace(value)
+	switch {
+	case strings.EqualFold(scheme, "Bearer"):
+		if u, ok := s.Bearer(value); ok {
+			return Identity{Type: TypeBearer, User: u}, nil
+		}
+		if _, ok := parseJWT(value); !ok {
+			return Identity{Type: TypeBearer}, ErrInvalid
+		}
+		claims, err := s.JWT(value)
+		id := Identity{Type: TypeJWT, Claims: claims}
+		if sub, ok := claims["sub"].(string); ok && err == nil {
+			id.User = User{Username: sub}
+		}
+		return id, err
+	case strings.EqualFold(scheme, "Basic"):
+		b, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return Identity{Type: TypeBasic}, ErrInvalid
+		}
+		username, password, _ := strings.Cut(string(b), ":")
+		if u, ok := s.Basic(username, password); ok {
+			return Identity{Type: TypeBasic, User: u}, nil
+		}
+		return Identity{Type: TypeBasic}, ErrInvalid
+	}
+	return Identity{Type: strings.ToLower(scheme)}, ErrInvalid
+}
+
+// Authenticate returns the user of a request with the Authorization header
+// authorization (Basic or Bearer) and the API key header apiKey, either of
+// which may be empty
+func (s *Store) Authenticate(authorization, apiKey string) (User, error) {
+	id, err := s.Identify(authorization, apiKey)
+	return id.User, err
+}
+
+// parseJWT returns the claims of token if it is a JWT
+func parseJWT(token string) (map[string]any, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, false
+	}
+	var header map[string]any
+	b, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil || json.Unmarshal(b, &header) != nil || header["alg"] == nil {
+		return nil, false
+	}
+	var claims map[string]any
+	b, err = base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || json.Unmarshal(b, &claims) != nil {
+		return nil, false
+	}
+	return claims, true
+}
+
+// equal compares secrets in constant time
+func equal(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// contains reports whether value is one of secrets, in constant time
+func contains(secrets []string, value string) bool {
+	found := false
+	for _, s := range secrets {
+		if equal(s, value) {
+			found = true
+		}
+	}
+	return found
+}
@@ -0,0 +1,214 @@
+package credentials
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/probitas-test/echo-servers/shared/clock"
+)
+
+const users = `
+required: true
+users:
+  - username: alice
+    tokens: [alice-token]
+    api_keys: [alice-key]
+`
+
+func newStore(t *testing.T) *Store {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "credentials.yaml")
+	if err := os.WriteFile(path, []byte(users), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := New(Config{Users: []string{"bob:secret"}, File: path}, User{Username: "testuser", Password: "testpass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func basic(username, password string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+}
+
+func TestAuthenticate(t *testing.T) {
+	s := newStore(t)
+	bob := User{Username: "bob", Password: "secret"}
+
+	tests := []struct {
+		authorization, apiKey string
+		want                  string
+		err                   error
+	}{
+		{basic("bob", "secret"), "", "bob", nil},
+		{basic("testuser", "testpass"), "", "testuser", nil},
+		{"Bearer " + bob.Token(), "", "bob", nil},
+		{"bearer alice-token", "", "alice", nil},
+		{"", "alice-key", "alice", nil},
+		{basic("bob", "wrong"), "", "", ErrInvalid},
+		{basic("alice", ""), "", "", ErrInvalid},
+		{"Bearer other", "", "", ErrInvalid},
+		{"Bearer alice-token", "other", "", ErrInvalid},
+		{"", "", "", ErrMissing},
+	}
+	for _, tt := range tests {
+		u, err := s.Authenticate(tt.authorization, tt.apiKey)
+		if u.Username != tt.want || err != tt.err {
+			t.Errorf("Authenticate(%q, %q) = %q, %v, want %q, %v", tt.authorization, tt.apiKey, u.Username, err, tt.want, tt.err)
+		}
+	}
+	if !s.Required() {
+		t.Error("required from the file was ignored")
+	}
+}
+
+func jwt(claims string) string {
+	enc := base64.RawURLEncoding.EncodeToString
+	return enc([]byte(`{"alg":"none","typ":"JWT"}`)) + "." + enc([]byte(claims)) + "."
+}
+
+func TestIdentify(t *testing.T) {
+	s := newStore(t)
+	valid := jwt(`{"iss":"http://idp.test","sub":"carol","exp":4102444800}`)
+	expired := jwt(`{"iss":"http://idp.test","sub":"carol","exp":946684800}`)
+	other := jwt(`{"iss":"http://other.test","sub":"carol"}`)
+
+	if id, err := s.Identify("Bearer "+valid, ""); id.Type != TypeJWT || err != ErrInvalid {
+		t.Errorf("JWT without issuer = %+v, %v, want rejected", id, err)
+	}
+	c := s.Credentials()
+	c.JWTIssuer = "http://idp.test"
+	if err := s.Set(c); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		authorization, apiKey string
+		typ, user             string
+		err                   error
+	}{
+		{"Bearer " + valid, "", TypeJWT, "carol", nil},
+		{"Bearer " + expired, "", TypeJWT, "", ErrExpired},
+		{"Bearer " + other, "", TypeJWT, "", ErrInvalid},
+		{"Bearer alice-token", "", TypeBearer, "alice", nil},
+		{"Bearer other", "", TypeBearer, "", ErrInvalid},
+		{basic("bob", "secret"), "", TypeBasic, "bob", nil},
+		{"", "alice-key", TypeAPIKey, "alice", nil},
+		{"Digest x", "", "digest", "", ErrInvalid},
+		{"", "", TypeNone, "", ErrMissing},
+	}
+	for _, tt := range tests {
+		id, err := s.Identify(tt.authorization, tt.apiKey)
+		if id.Type != tt.typ || id.User.Username != tt.user || err != tt.err {
+			t.Errorf("Identify(%q, %q) = %+v, %v, want %s %q, %v", tt.authorization, tt.apiKey, id, err, tt.typ, tt.user, tt.err)
+		}
+	}
+	if id, _ := s.Identify("Bearer "+valid, ""); id.Claims["iss"] != "http://idp.test" {
+		t.Errorf("claims = %v", id.Claims)
+	}
+
+	c.JWTIssuer = AnyIssuer
+	if err := s.Set(c); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Identify("Bearer "+other, ""); err != nil {
+		t.Errorf("JWT of any issuer: %v", err)
+	}
+}
+
+func TestJWTExpiresOnTheClock(t *testing.T) {
+	s, err := New(Config{JWTIssuer: "http://idp.test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	clk := clock.New(clock.Config{})
+	s.SetClock(clk)
+	token := jwt(fmt.Sprintf(`{"iss":"http://idp.test","sub":"carol","exp":%d}`, time.Now().Add(time.Hour).Unix()))
+
+	if _, err := s.JWT(token); err != nil {
+		t.Fatalf("unexpired JWT: %v", err)
+	}
+	clk.Advance(2 * time.Hour)
+	if _, err := s.JWT(token); err != ErrExpired {
+		t.Errorf("JWT past exp on the clock: %v, want %v", err, ErrExpired)
+	}
+}
+
+func TestNewRejectsInvalidUsers(t *testing.T) {
+	for name, c := range map[string]Config{
+		"no password": {Users: []string{"bob"}},
+		"duplicate":   {Users: []string{"bob:a", "bob:b"}},
+		"no username": {Users: []string{":secret"}},
+	} {
+		if _, err := New(c); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+}
+
+func TestHTTP(t *testing.T) {
+	s := newStore(t)
+	h := HTTP(s, "echo-test")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u, _ := FromContext(r.Context())
+		_, _ = w.Write([]byte(u.Username))
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != http.StatusUnauthorized || !strings.Contains(rec.Header().Get("WWW-Authenticate"), `Basic realm="echo-test"`) {
+		t.Errorf("without credentials = %d %q", rec.Code, rec.Header().Get("WWW-Authenticate"))
+	}
+
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(APIKeyHeader, "alice-key")
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || rec.Body.String() != "alice" {
+		t.Errorf("with an API key = %d %q", rec.Code, rec.Body)
+	}
+
+	c := s.Credentials()
+	c.Required = false
+	if err := s.Set(c); err != nil {
+		t.Fatal(err)
+	}
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("not required = %d", rec.Code)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	s := newStore(t)
+	h := Handler(s)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("PUT", Path, strings.NewReader(`{"users": [{"username": "carol", "password": "pw"}]}`)))
+	if rec.Code != http.StatusOK || s.Required() {
+		t.Fatalf("PUT = %d %s", rec.Code, rec.Body)
+	}
+	if _, err := s.Authenticate(basic("bob", "secret"), ""); err != ErrInvalid {
+		t.Errorf("replaced user still accepted: %v", err)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("PUT", Path, strings.NewReader(`{"users": [{"username": "dave"}]}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("PUT without secret = %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("DELETE", Path, nil))
+	if _, err := s.Authenticate(basic("bob", "secret"), ""); rec.Code != http.StatusOK || err != nil {
+		t.Errorf("DELETE = %d, bob: %v", rec.Code, err)
+	}
+}
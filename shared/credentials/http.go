@@ -0,0 +1,78 @@
+package credentials
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/probitas-test/echo-servers/shared/internal/jsonhttp"
+)
+
+// Path is where the admin API is served
+const Path = "/credentials"
+
+type contextKey struct{}
+
+// WithUser returns ctx carrying the authenticated user u
+func WithUser(ctx context.Context, u User) context.Context {
+	return context.WithValue(ctx, contextKey{}, u)
+}
+
+// FromContext returns the user authenticated by HTTP or an interceptor
+func FromContext(ctx context.Context) (User, bool) {
+	u, ok := ctx.Value(contextKey{}).(User)
+	return u, ok
+}
+
+// Challenge returns the WWW-Authenticate challenge of a rejected request
+func Challenge(realm string) string {
+	return `Basic realm="` + realm + `", Bearer realm="` + realm + `"`
+}
+
+// HTTP is middleware rejecting the requests without credentials of a user
+// of s with 401 while s is Required; the user of accepted requests is in
+// their context (FromContext)
+func HTTP(s *Store, realm string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			u, err := s.Authenticate(r.Header.Get("Authorization"), r.Header.Get(APIKeyHeader))
+			if err == nil {
+				r = r.WithContext(WithUser(r.Context(), u))
+			} else if s.Required() {
+				w.Header().Set("WWW-Authenticate", Challenge(realm))
+				jsonhttp.Error(w, http.StatusUnauthorized, err.Error())
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Handler serves the admin API of s:
+//
+//	GET    /credentials  current users and whether they are required
+//	PUT    /credentials  replace them
+//	DELETE /credentials  restore the startup users
+func Handler(s *Store) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+		case http.MethodPut:
+			var c Credentials
+			err := jsonhttp.Decode(r, &c)
+			if err == nil {
+				err = s.Set(c)
+			}
+			if err != nil {
+				jsonhttp.Error(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		case http.MethodDelete:
+			s.Reset()
+		default:
+			w.Header().Set("Allow", "GET, HEAD, PUT, DELETE")
+			jsonhttp.Error(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		jsonhttp.Write(w, http.StatusOK, s.Credentials())
+	})
+}
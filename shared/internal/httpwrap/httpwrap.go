@@ -0,0 +1,102 @@
+// Package httpwrap wraps request bodies and response writers to observe
+// what a handler reads and writes, for the metrics and tracing middleware.
+package httpwrap
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+)
+
+// Body counts the bytes read from a request body
+type Body struct {
+	io.ReadCloser
+	N int64
+}
+
+// WrapBody replaces the body of r with a counting Body
+func WrapBody(r *http.Request) *Body {
+	b := &Body{ReadCloser: r.Body}
+	if r.Body != nil && r.Body != http.NoBody {
+		r.Body = b
+	}
+	return b
+}
+
+func (b *Body) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.N += int64(n)
+	return n, err
+}
+
+// ResponseWriter records the status code and counts the bytes written. It
+// keeps streaming (Flush) and WebSocket upgrades (Hijack) working.
+type ResponseWriter struct {
+	http.ResponseWriter
+	N int64
+
+	status   int
+	hijacked bool
+}
+
+// WrapResponseWriter wraps w; use an existing ResponseWriter when w is one
+func WrapResponseWriter(w http.ResponseWriter) *ResponseWriter {
+	if rw, ok := w.(*ResponseWriter); ok {
+		return rw
+	}
+	return &ResponseWriter{ResponseWriter: w}
+}
+
+func (w *ResponseWriter) WriteHeader(code int) {
+	// Informational responses (such as 103 Early Hints) precede the final one
+	if w.status == 0 && code >= 200 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *ResponseWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(p)
+	w.N += int64(n)
+	return n, err
+}
+
+func (w *ResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *ResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	conn, rw, err := h.Hijack()
+	if err == nil {
+		w.hijacked = true
+	}
+	return conn, rw, err
+}
+
+// Unwrap gives http.ResponseController access to the underlying writer
+func (w *ResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Status returns the status of the response; hijacked connections count
+// as protocol switches
+func (w *ResponseWriter) Status() int {
+	switch {
+	case w.status != 0:
+		return w.status
+	case w.hijacked:
+		return http.StatusSwitchingProtocols
+	default:
+		return http.StatusOK
+	}
+}
@@ -0,0 +1,56 @@
+// Package jsonhttp reads and writes the JSON bodies of the admin APIs.
+package jsonhttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// MaxBodySize limits the request body of the admin APIs
+const MaxBodySize = 1 << 20
+
+// ReadBody reads the body of r, up to MaxBodySize
+func ReadBody(r *http.Request) ([]byte, error) {
+	var buf bytes.Buffer
+	_, err := buf.ReadFrom(http.MaxBytesReader(nil, r.Body, MaxBodySize))
+	return buf.Bytes(), err
+}
+
+// Decode reads the body of r into v with DecodeStrict
+func Decode(r *http.Request, v any) error {
+	body, err := ReadBody(r)
+	if err != nil {
+		return err
+	}
+	return DecodeStrict(body, v)
+}
+
+// DecodeStrict decodes the JSON value b into v, rejecting unknown fields
+// and trailing data
+func DecodeStrict(b []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if dec.More() {
+		return errors.New("unexpected data after JSON value")
+	}
+	return nil
+}
+
+// Write writes v as indented JSON with status
+func Write(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
+
+// Error writes {"error": msg} with status
+func Error(w http.ResponseWriter, status int, msg string) {
+	Write(w, status, map[string]string{"error": msg})
+}
@@ -0,0 +1,103 @@
+// Package jwt decodes JSON Web Tokens and verifies their signatures against
+// a JSON Web Key Set fetched from a URL, such as the jwks_uri of an OIDC
+// provider. RSA (RS*, PS*) and EC (ES*) keys are supported.
+//
+// Expiry (exp, nbf) and the key set cache follow a clock.Clock, so moving
+// the clock through its admin API expires tokens and key sets; a nil clock
+// is the real time.
+package jwt
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Errors of CheckTime
+var (
+	ErrExpired     = errors.New("token expired")
+	ErrNotYetValid = errors.New("token not yet valid")
+)
+
+// Token is a decoded, unverified JWT
+type Token struct {
+	// Raw is the compact serialization (header.payload.signature)
+	Raw string
+	// Header and Claims hold the decoded JSON, numbers as json.Number
+	Header map[string]any
+	Claims map[string]any
+
+	signed    []byte
+	signature []byte
+}
+
+// Parse decodes a compact JWT without verifying it
+func Parse(token string) (*Token, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+	t := &Token{Raw: token, signed: []byte(parts[0] + "." + parts[1])}
+	if err := decodeSegment(parts[0], &t.Header); err != nil {
+		return nil, fmt.Errorf("malformed token header: %w", err)
+	}
+	if err := decodeSegment(parts[1], &t.Claims); err != nil {
+		return nil, fmt.Errorf("malformed token claims: %w", err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[2], "="))
+	if err != nil {
+		return nil, fmt.Errorf("malformed token signature: %w", err)
+	}
+	t.signature = signature
+	return t, nil
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(segment, "="))
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+// Alg returns the signing algorithm of the header
+func (t *Token) Alg() string {
+	alg, _ := t.Header["alg"].(string)
+	return alg
+}
+
+// Kid returns the key id of the header
+func (t *Token) Kid() string {
+	kid, _ := t.Header["kid"].(string)
+	return kid
+}
+
+// Time returns the NumericDate claim name, such as exp, nbf or iat
+func (t *Token) Time(name string) (time.Time, bool) {
+	n, ok := t.Claims[name].(json.Number)
+	if !ok {
+		return time.Time{}, false
+	}
+	seconds, err := n.Float64()
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(0, int64(seconds*float64(time.Second))).UTC(), true
+}
+
+// CheckTime returns ErrExpired from exp on and ErrNotYetValid before nbf
+func (t *Token) CheckTime(now time.Time) error {
+	if exp, ok := t.Time("exp"); ok && !now.Before(exp) {
+		return ErrExpired
+	}
+	if nbf, ok := t.Time("nbf"); ok && now.Before(nbf) {
+		return ErrNotYetValid
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/probitas-test/echo-servers/shared/clock"
+)
+
+var b64 = base64.RawURLEncoding.EncodeToString
+
+// sign returns a JWT of header and claims signed by sign
+func sign(t *testing.T, header, claims map[string]any, sign func(signed []byte) []byte) string {
+	t.Helper()
+	encode := func(v any) string {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b64(data)
+	}
+	signed := encode(header) + "." + encode(claims)
+	return signed + "." + b64(sign([]byte(signed)))
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]any) string {
+	return sign(t, map[string]any{"alg": "RS256", "kid": kid}, claims, func(signed []byte) []byte {
+		digest := sha256.Sum256(signed)
+		sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sig
+	})
+}
+
+// serveKeys serves a key set, counting the fetches
+func serveKeys(t *testing.T, keys *[]Key) (string, *atomic.Int32) {
+	t.Helper()
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": *keys})
+	}))
+	t.Cleanup(server.Close)
+	return server.URL, &fetches
+}
+
+func rsaKey(kid string, key *rsa.PrivateKey) Key {
+	return Key{Kty: "RSA", Kid: kid, N: b64(key.N.Bytes()), E: b64(big.NewInt(int64(key.E)).Bytes())}
+}
+
+func TestParse(t *testing.T) {
+	token := sign(t, map[string]any{"alg": "none", "kid": "k"}, map[string]any{"sub": "alice", "exp": 1.5}, func([]byte) []byte { return nil })
+	tok, err := Parse(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tok.Alg() != "none" || tok.Kid() != "k" || tok.Claims["sub"] != "alice" {
+		t.Errorf("token = %+v", tok)
+	}
+	if exp, ok := tok.Time("exp"); !ok || !exp.Equal(time.Unix(1, 5e8)) {
+		t.Errorf("exp = %s", exp)
+	}
+	if err := tok.CheckTime(time.Unix(1, 0)); err != nil {
+		t.Errorf("before exp: %v", err)
+	}
+	if err := tok.CheckTime(time.Unix(2, 0)); !errors.Is(err, ErrExpired) {
+		t.Errorf("after exp: %v", err)
+	}
+
+	for _, malformed := range []string{"", "a.b", "!.e30.", "e30.!.", "e30.e30.!"} {
+		if _, err := Parse(malformed); err == nil {
+			t.Errorf("%q parsed", malformed)
+		}
+	}
+}
+
+func TestKeySet(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	point, err := ecKey.PublicKey.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := []Key{rsaKey("rsa", key), {Kty: "EC", Kid: "ec", Crv: "P-256", X: b64(point[1:33]), Y: b64(point[33:])}}
+	url, fetches := serveKeys(t, &keys)
+	set := NewKeySet(url, nil)
+	ctx := context.Background()
+
+	valid := signRS256(t, key, "rsa", map[string]any{"exp": time.Now().Add(time.Hour).Unix()})
+	if _, err := set.Validate(ctx, valid); err != nil {
+		t.Fatalf("valid RS256: %v", err)
+	}
+	es256 := sign(t, map[string]any{"alg": "ES256", "kid": "ec"}, map[string]any{}, func(signed []byte) []byte {
+		digest := sha256.Sum256(signed)
+		r, s, err := ecdsa.Sign(rand.Reader, ecKey, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	})
+	if _, err := set.Validate(ctx, es256); err != nil {
+		t.Errorf("valid ES256: %v", err)
+	}
+	if fetches.Load() != 1 {
+		t.Errorf("key set fetched %d times", fetches.Load())
+	}
+
+	for name, tt := range map[string]struct {
+		token  string
+		reason string
+	}{
+		"expired":   {signRS256(t, key, "rsa", map[string]any{"exp": time.Now().Add(-time.Hour).Unix()}), "token expired"},
+		"wrong key": {signRS256(t, other, "rsa", nil), "invalid signature"},
+		"unknown":   {signRS256(t, key, "missing", nil), `no key with kid "missing"`},
+		"unsigned":  {sign(t, map[string]any{"alg": "none"}, nil, func([]byte) []byte { return nil }), "unsigned"},
+	} {
+		if _, err := set.Validate(ctx, tt.token); err == nil || !strings.Contains(err.Error(), tt.reason) {
+			t.Errorf("%s: %v, want %q", name, err, tt.reason)
+		}
+	}
+
+	// An unknown kid refetches the key set, picking up rotated keys
+	keys = append(keys, rsaKey("rotated", other))
+	if _, err := set.Validate(ctx, signRS256(t, other, "rotated", nil)); err != nil {
+		t.Errorf("rotated key: %v", err)
+	}
+}
+
+func TestKeySet_AllowUnsigned(t *testing.T) {
+	keys := []Key{}
+	url, _ := serveKeys(t, &keys)
+	set := NewKeySet(url, nil)
+	set.AllowUnsigned = true
+	unsigned := sign(t, map[string]any{"alg": "none"}, map[string]any{"sub": "alice"}, func([]byte) []byte { return nil })
+
+	if _, err := set.Validate(context.Background(), unsigned); err != nil {
+		t.Errorf("unsigned with an empty key set: %v", err)
+	}
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys = append(keys, rsaKey("rsa", key))
+	set.fetchedAt = time.Time{}
+	if _, err := set.Validate(context.Background(), unsigned); err == nil {
+		t.Error("unsigned accepted with keys published")
+	}
+}
+
+func TestKeySet_Clock(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := []Key{rsaKey("rsa", key)}
+	url, fetches := serveKeys(t, &keys)
+	clk := clock.New(clock.Config{})
+	set := NewKeySet(url, clk)
+	token := signRS256(t, key, "rsa", map[string]any{"exp": time.Now().Add(time.Hour).Unix()})
+
+	if _, err := set.Validate(context.Background(), token); err != nil {
+		t.Fatalf("unexpired: %v", err)
+	}
+	// Advancing the clock past exp expires the token and the cached key set
+	clk.Advance(2 * time.Hour)
+	if _, err := set.Validate(context.Background(), token); !errors.Is(err, ErrExpired) {
+		t.Errorf("past exp on the clock: %v, want %v", err, ErrExpired)
+	}
+	if fetches.Load() != 2 {
+		t.Errorf("key set fetched %d times, want a refetch past CacheTTL", fetches.Load())
+	}
+}
@@ -0,0 +1,252 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/probitas-test/echo-servers/shared/clock"
+)
+
+// CacheTTL is how long a fetched key set is used before it is fetched
+// again. Unknown key ids trigger an immediate refetch.
+const CacheTTL = 5 * time.Minute
+
+// Key is a JSON Web Key
+type Key struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// KeySet verifies JWT signatures against the key set at URL
+type KeySet struct {
+	URL    string
+	Client *http.Client
+	// AllowUnsigned accepts unsigned tokens (alg none) while the key set
+	// has no keys, as the echo-http mock OIDC provider issues them
+	AllowUnsigned bool
+
+	clock *clock.Clock
+
+	mu        sync.Mutex
+	keys      []Key
+	fetchedAt time.Time
+}
+
+// NewKeySet creates a verifier for the key set at url, cached on clk
+func NewKeySet(url string, clk *clock.Clock) *KeySet {
+	return &KeySet{URL: url, Client: &http.Client{Timeout: 5 * time.Second}, clock: clk}
+}
+
+// Validate parses token, verifies its signature and checks exp and nbf on
+// the clock of the key set
+func (s *KeySet) Validate(ctx context.Context, token string) (*Token, error) {
+	t, err := Parse(token)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Verify(ctx, t); err != nil {
+		return t, err
+	}
+	return t, t.CheckTime(s.clock.Now())
+}
+
+// Verify checks the signature of t against the keys of its kid, or every
+// key when either has none
+func (s *KeySet) Verify(ctx context.Context, t *Token) error {
+	alg := t.Alg()
+	if alg == "" || alg == "none" {
+		if s.AllowUnsigned && len(t.signature) == 0 {
+			keys, err := s.candidates(ctx, "")
+			if err == nil && len(keys) == 0 {
+				return nil
+			}
+		}
+		return errors.New("token is unsigned (alg none)")
+	}
+
+	keys, err := s.candidates(ctx, t.Kid())
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		if t.Kid() == "" {
+			return errors.New("the key set has no keys")
+		}
+		return fmt.Errorf("no key with kid %q in the key set", t.Kid())
+	}
+	err = errors.New("invalid signature")
+	for _, key := range keys {
+		if key.Alg != "" && key.Alg != alg {
+			err = fmt.Errorf("key %q is for %s, token uses %s", key.Kid, key.Alg, alg)
+			continue
+		}
+		if err = verifySignature(alg, key, t.signed, t.signature); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// candidates returns the keys matching kid, fetching the key set when the
+// cache is stale or has none
+func (s *KeySet) candidates(ctx context.Context, kid string) ([]Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	match := func() []Key {
+		var keys []Key
+		for _, key := range s.keys {
+			if kid == "" || key.Kid == "" || key.Kid == kid {
+				keys = append(keys, key)
+			}
+		}
+		return keys
+	}
+
+	fresh := !s.fetchedAt.IsZero() && s.clock.Since(s.fetchedAt) < CacheTTL
+	if keys := match(); fresh && (len(keys) > 0 || kid == "") {
+		return keys, nil
+	}
+	if err := s.fetch(ctx); err != nil {
+		return nil, err
+	}
+	return match(), nil
+}
+
+func (s *KeySet) fetch(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch JWKS: %s", resp.Status)
+	}
+
+	var set struct {
+		Keys []Key `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return fmt.Errorf("invalid JWKS: %w", err)
+	}
+	s.keys = set.Keys
+	s.fetchedAt = s.clock.Now()
+	return nil
+}
+
+func verifySignature(alg string, key Key, signed, signature []byte) error {
+	if len(alg) != 5 {
+		return fmt.Errorf("unsupported algorithm %s", alg)
+	}
+	var hash crypto.Hash
+	switch alg[2:] {
+	case "256":
+		hash = crypto.SHA256
+	case "384":
+		hash = crypto.SHA384
+	case "512":
+		hash = crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported algorithm %s", alg)
+	}
+	h := hash.New()
+	h.Write(signed)
+	digest := h.Sum(nil)
+
+	switch alg[:2] {
+	case "RS", "PS":
+		pub, err := key.rsaPublicKey()
+		if err != nil {
+			return err
+		}
+		if alg[:2] == "PS" {
+			err = rsa.VerifyPSS(pub, hash, digest, signature, nil)
+		} else {
+			err = rsa.VerifyPKCS1v15(pub, hash, digest, signature)
+		}
+		if err != nil {
+			return errors.New("invalid signature")
+		}
+		return nil
+	case "ES":
+		pub, err := key.ecdsaPublicKey()
+		if err != nil {
+			return err
+		}
+		if len(signature) == 0 || len(signature)%2 != 0 {
+			return errors.New("invalid signature")
+		}
+		size := len(signature) / 2
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(pub, digest, r, s) {
+			return errors.New("invalid signature")
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported algorithm %s", alg)
+}
+
+func (k Key) rsaPublicKey() (*rsa.PublicKey, error) {
+	if k.Kty != "RSA" {
+		return nil, fmt.Errorf("key %q is not an RSA key", k.Kid)
+	}
+	n, errN := base64.RawURLEncoding.DecodeString(k.N)
+	e, errE := base64.RawURLEncoding.DecodeString(k.E)
+	if errN != nil || errE != nil || len(e) > 4 {
+		return nil, fmt.Errorf("key %q has an invalid modulus or exponent", k.Kid)
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+}
+
+func (k Key) ecdsaPublicKey() (*ecdsa.PublicKey, error) {
+	if k.Kty != "EC" {
+		return nil, fmt.Errorf("key %q is not an EC key", k.Kid)
+	}
+	var curve elliptic.Curve
+	switch k.Crv {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("key %q uses unsupported curve %q", k.Kid, k.Crv)
+	}
+	x, errX := base64.RawURLEncoding.DecodeString(k.X)
+	y, errY := base64.RawURLEncoding.DecodeString(k.Y)
+	if errX != nil || errY != nil {
+		return nil, fmt.Errorf("key %q has invalid coordinates", k.Kid)
+	}
+	size := (curve.Params().BitSize + 7) / 8
+	if len(x) > size || len(y) > size {
+		return nil, fmt.Errorf("key %q has invalid coordinates", k.Kid)
+	}
+	point := make([]byte, 1+2*size)
+	point[0] = 4 // uncompressed
+	copy(point[1+size-len(x):1+size], x)
+	copy(point[1+2*size-len(y):], y)
+	return ecdsa.ParseUncompressedPublicKey(curve, point)
+}
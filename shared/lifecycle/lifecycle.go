@@ -0,0 +1,128 @@
+// Package lifecycle simulates slow and unreliable server startups, so
+// orchestration (readiness probes, restart policies) and client startup
+// retries can be tested: a delay before any port opens, a readiness delay
+// during which the server is up but reports itself unhealthy, and a crash
+// after a while.
+//
+// Servers call Delay before opening their listeners, then Start once the
+// admin state and its health services are set up.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/probitas-test/echo-servers/shared/admin"
+	"github.com/probitas-test/echo-servers/shared/config"
+)
+
+// Config configures the simulated startup
+type Config struct {
+	// StartupDelay is waited before any port opens
+	StartupDelay time.Duration
+	// ReadyDelay is the time after startup the server reports itself
+	// unhealthy (health checks, gRPC health) before turning ready
+	ReadyDelay time.Duration
+	// CrashAfter is the time after startup the server exits with
+	// CrashExitCode; zero never crashes
+	CrashAfter    time.Duration
+	CrashExitCode int
+}
+
+// LoadConfig reads the STARTUP_*_MS and CRASH_* settings from src
+func LoadConfig(src *config.Source) Config {
+	return Config{
+		StartupDelay:  time.Duration(src.Int("STARTUP_DELAY_MS", 0)) * time.Millisecond,
+		ReadyDelay:    time.Duration(src.Int("STARTUP_READY_DELAY_MS", 0)) * time.Millisecond,
+		CrashAfter:    time.Duration(src.Int("CRASH_AFTER_MS", 0)) * time.Millisecond,
+		CrashExitCode: src.Int("CRASH_EXIT_CODE", 1),
+	}
+}
+
+// Validate reports the first invalid setting
+func (c Config) Validate() error {
+	switch {
+	case c.StartupDelay < 0:
+		return errors.New("STARTUP_DELAY_MS must not be negative")
+	case c.ReadyDelay < 0:
+		return errors.New("STARTUP_READY_DELAY_MS must not be negative")
+	case c.CrashAfter < 0:
+		return errors.New("CRASH_AFTER_MS must not be negative")
+	case c.CrashExitCode < 0 || c.CrashExitCode > 255:
+		return errors.New("CRASH_EXIT_CODE must be 0-255")
+	}
+	return nil
+}
+
+// String describes the simulation for the startup log
+func (c Config) String() string {
+	var parts []string
+	if c.StartupDelay > 0 {
+		parts = append(parts, "startup delay "+c.StartupDelay.String())
+	}
+	if c.ReadyDelay > 0 {
+		parts = append(parts, "ready after "+c.ReadyDelay.String())
+	}
+	if c.CrashAfter > 0 {
+		parts = append(parts, fmt.Sprintf("crash after %s (exit code %d)", c.CrashAfter, c.CrashExitCode))
+	}
+	if len(parts) == 0 {
+		return "disabled"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Delay waits the startup delay of c, or until ctx is done
+func Delay(ctx context.Context, c Config) {
+	if c.StartupDelay <= 0 {
+		return
+	}
+	log.Printf("Delaying startup by %s", c.StartupDelay)
+	timer := time.NewTimer(c.StartupDelay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
+
+// exit ends the process on a crash; it is replaced by the tests
+var exit = os.Exit
+
+type exitKey struct{}
+
+// WithExit returns a context whose servers crash by calling exit instead of
+// ending the process, for servers sharing a process (echo-all)
+func WithExit(ctx context.Context, exit func(code int)) context.Context {
+	return context.WithValue(ctx, exitKey{}, exit)
+}
+
+// Start marks adm not ready for the readiness delay of c and schedules the
+// crash, through the exit function of ctx (WithExit) or os.Exit. Both are
+// canceled when ctx is done.
+func Start(ctx context.Context, c Config, adm *admin.Admin) {
+	crash := exit
+	if f, ok := ctx.Value(exitKey{}).(func(int)); ok {
+		crash = f
+	}
+	if c.ReadyDelay > 0 {
+		adm.SetReady(false)
+		ready := time.AfterFunc(c.ReadyDelay, func() {
+			log.Printf("Ready after %s", c.ReadyDelay)
+			adm.SetReady(true)
+		})
+		context.AfterFunc(ctx, func() { ready.Stop() })
+	}
+	if c.CrashAfter > 0 {
+		timer := time.AfterFunc(c.CrashAfter, func() {
+			log.Printf("Crashing after %s with exit code %d", c.CrashAfter, c.CrashExitCode)
+			crash(c.CrashExitCode)
+		})
+		context.AfterFunc(ctx, func() { timer.Stop() })
+	}
+}
@@ -0,0 +1,72 @@
+package lifecycle
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/probitas-test/echo-servers/shared/admin"
+)
+
+func TestValidate(t *testing.T) {
+	if err := (Config{CrashExitCode: 1}).Validate(); err != nil {
+		t.Errorf("default config: %v", err)
+	}
+	if err := (Config{ReadyDelay: -time.Second}).Validate(); err == nil {
+		t.Error("negative ready delay accepted")
+	}
+	if err := (Config{CrashExitCode: 256}).Validate(); err == nil {
+		t.Error("exit code 256 accepted")
+	}
+}
+
+func TestStart(t *testing.T) {
+	codes := make(chan int, 1)
+	exit = func(code int) { codes <- code }
+	t.Cleanup(func() { exit = os.Exit })
+
+	adm := admin.New("echo-test", nil)
+	Start(context.Background(), Config{ReadyDelay: 50 * time.Millisecond, CrashAfter: 100 * time.Millisecond, CrashExitCode: 3}, adm)
+	if adm.Healthy() {
+		t.Error("healthy during the readiness delay")
+	}
+	select {
+	case code := <-codes:
+		if code != 3 {
+			t.Errorf("exit code = %d, want 3", code)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no crash")
+	}
+	if !adm.Healthy() {
+		t.Error("not healthy after the readiness delay")
+	}
+}
+
+func TestStart_WithExit(t *testing.T) {
+	exit = func(code int) { t.Errorf("process exit %d", code) }
+	t.Cleanup(func() { exit = os.Exit })
+
+	codes := make(chan int, 1)
+	ctx := WithExit(context.Background(), func(code int) { codes <- code })
+	Start(ctx, Config{CrashAfter: 10 * time.Millisecond, CrashExitCode: 4}, admin.New("echo-test", nil))
+	select {
+	case code := <-codes:
+		if code != 4 {
+			t.Errorf("exit code = %d, want 4", code)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no crash")
+	}
+
+	// A server that has stopped does not crash
+	ctx, cancel := context.WithCancel(ctx)
+	Start(ctx, Config{CrashAfter: 20 * time.Millisecond, CrashExitCode: 5}, admin.New("echo-test", nil))
+	cancel()
+	select {
+	case code := <-codes:
+		t.Errorf("crashed with %d after the context ended", code)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,47 @@
+package logging
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/probitas-test/echo-servers/shared/internal/httpwrap"
+)
+
+// Header carries the request ID of HTTP requests and responses
+const Header = "X-Request-Id"
+
+// HTTP is middleware keeping the X-Request-Id of a request, or generating
+// one when it is absent or invalid, echoing it in the response and logging
+// the request once it completed. Server errors are logged at error level.
+func HTTP(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		id := r.Header.Get(Header)
+		if !ValidRequestID(id) {
+			id = NewRequestID()
+		}
+		w.Header().Set(Header, id)
+
+		ctx := WithRequestID(r.Context(), id)
+		rw := httpwrap.WrapResponseWriter(w)
+		body := httpwrap.WrapBody(r)
+		next.ServeHTTP(rw, r.WithContext(ctx))
+
+		status := rw.Status()
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		slog.LogAttrs(ctx, level, "request",
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.String("proto", r.Proto),
+			slog.String("remote_addr", r.RemoteAddr),
+			slog.Int("status", status),
+			slog.Int64("request_bytes", body.N),
+			slog.Int64("response_bytes", rw.N),
+			slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+		)
+	})
+}
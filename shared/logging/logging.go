@@ -0,0 +1,134 @@
+// Package logging sets up structured logging with log/slog and carries a
+// request ID through each request, so the log lines of a request can be
+// correlated with each other, with the client and with its trace.
+//
+// Setup installs the default slog logger, which the standard log package
+// writes through as well. Records logged with a context carry the request
+// ID and, when the context has a span, the trace and span IDs.
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/probitas-test/echo-servers/shared/config"
+)
+
+// Log formats (the values of LOG_FORMAT)
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// Config configures the default logger
+type Config struct {
+	Level  string
+	Format string
+}
+
+// LoadConfig reads LOG_LEVEL and LOG_FORMAT from src
+func LoadConfig(src *config.Source) Config {
+	return Config{
+		Level:  src.String("LOG_LEVEL", "info"),
+		Format: src.String("LOG_FORMAT", FormatJSON),
+	}
+}
+
+// Setup installs the default slog logger writing to stderr
+func Setup(c Config) error {
+	h, err := NewHandler(os.Stderr, c)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(slog.New(h))
+	return nil
+}
+
+// NewHandler returns a handler writing records of at least the configured
+// level to w, adding the request and trace IDs of the record context
+func NewHandler(w io.Writer, c Config) (slog.Handler, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.Level)); err != nil {
+		return nil, fmt.Errorf("unsupported log level %q (must be debug, info, warn or error)", c.Level)
+	}
+	opts := &slog.HandlerOptions{Level: level}
+
+	var h slog.Handler
+	switch strings.ToLower(c.Format) {
+	case FormatJSON:
+		h = slog.NewJSONHandler(w, opts)
+	case FormatText:
+		h = slog.NewTextHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("unsupported log format %q (must be %q or %q)", c.Format, FormatJSON, FormatText)
+	}
+	return contextHandler{h}, nil
+}
+
+// contextHandler adds the request and trace IDs of the context to records
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(slog.String("trace_id", sc.TraceID().String()), slog.String("span_id", sc.SpanID().String()))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+type requestIDKey struct{}
+
+// WithRequestID returns a context carrying the request ID id
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID of ctx, or "" without one
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// NewRequestID returns a random request ID (32 hex digits)
+func NewRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// maxRequestIDLength bounds request IDs accepted from clients
+const maxRequestIDLength = 128
+
+// ValidRequestID reports whether a request ID sent by a client is kept:
+// 1 to 128 printable ASCII characters
+func ValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
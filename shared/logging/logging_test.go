@@ -0,0 +1,167 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/probitas-test/echo-servers/shared/config"
+)
+
+// useBuffer installs a default JSON logger writing to the returned buffer
+// for the duration of the test
+func useBuffer(t *testing.T, level string) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	h, err := NewHandler(&buf, Config{Level: level, Format: FormatJSON})
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev := slog.Default()
+	slog.SetDefault(slog.New(h))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+	return &buf
+}
+
+func decode(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var rec map[string]any
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("invalid JSON log line %q: %v", line, err)
+		}
+		records = append(records, rec)
+	}
+	return records
+}
+
+func TestHTTP(t *testing.T) {
+	buf := useBuffer(t, "info")
+	var handlerID string
+	h := HTTP(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handlerID = RequestID(r.Context())
+		slog.InfoContext(r.Context(), "handled")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte("busy"))
+	}))
+
+	// Generated when absent
+	resp := httptest.NewRecorder()
+	h.ServeHTTP(resp, httptest.NewRequest("GET", "/items/1", nil))
+	id := resp.Header().Get(Header)
+	if len(id) != 32 || handlerID != id {
+		t.Errorf("request ID = %q, handler saw %q", id, handlerID)
+	}
+
+	records := decode(t, buf)
+	if len(records) != 2 {
+		t.Fatalf("%d log records, want 2: %s", len(records), buf)
+	}
+	if records[0]["msg"] != "handled" || records[0]["request_id"] != id {
+		t.Errorf("handler record = %v", records[0])
+	}
+	access := records[1]
+	if access["msg"] != "request" || access["level"] != "ERROR" || access["request_id"] != id {
+		t.Errorf("access record = %v", access)
+	}
+	if access["status"] != float64(503) || access["path"] != "/items/1" || access["response_bytes"] != float64(4) {
+		t.Errorf("access record = %v", access)
+	}
+
+	// Kept when sent by the client
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(Header, "client-id-1")
+	resp = httptest.NewRecorder()
+	h.ServeHTTP(resp, req)
+	if got := resp.Header().Get(Header); got != "client-id-1" {
+		t.Errorf("request ID = %q, want the client's", got)
+	}
+
+	// Replaced when invalid
+	req.Header.Set(Header, "has space")
+	resp = httptest.NewRecorder()
+	h.ServeHTTP(resp, req)
+	if got := resp.Header().Get(Header); got == "has space" || got == "" {
+		t.Errorf("request ID = %q, want a generated one", got)
+	}
+}
+
+func TestHandlerTraceIDs(t *testing.T) {
+	buf := useBuffer(t, "debug")
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+	}))
+
+	slog.DebugContext(ctx, "traced")
+	slog.With("component", "test").InfoContext(WithRequestID(ctx, "abc"), "with attrs")
+
+	records := decode(t, buf)
+	if len(records) != 2 {
+		t.Fatalf("%d log records, want 2: %s", len(records), buf)
+	}
+	if records[0]["trace_id"] != traceID.String() || records[0]["span_id"] != spanID.String() {
+		t.Errorf("record = %v", records[0])
+	}
+	if records[1]["component"] != "test" || records[1]["request_id"] != "abc" {
+		t.Errorf("record = %v", records[1])
+	}
+}
+
+func TestLevel(t *testing.T) {
+	buf := useBuffer(t, "warn")
+	slog.Info("dropped")
+	slog.Warn("kept")
+	if records := decode(t, buf); len(records) != 1 || records[0]["msg"] != "kept" {
+		t.Errorf("records = %v", records)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Setenv(config.FileEnv, "")
+	t.Setenv("LOG_LEVEL", "DEBUG")
+	t.Setenv("LOG_FORMAT", "text")
+
+	c := LoadConfig(config.Load())
+	var buf bytes.Buffer
+	h, err := NewHandler(&buf, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slog.New(h).Debug("hello", "k", "v")
+	if !strings.Contains(buf.String(), "level=DEBUG msg=hello k=v") {
+		t.Errorf("text output = %q", buf.String())
+	}
+}
+
+func TestNewHandlerErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		want string
+	}{
+		{"level", Config{Level: "loud", Format: FormatJSON}, "unsupported log level"},
+		{"format", Config{Level: "info", Format: "xml"}, "unsupported log format"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewHandler(&bytes.Buffer{}, tt.cfg)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("NewHandler() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
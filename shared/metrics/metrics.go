@@ -0,0 +1,176 @@
+// Package metrics collects Prometheus metrics of the requests a server
+// handles and serves them at /metrics on a separate port, so load tests
+// get the same server-side measurements from every echo server.
+//
+// Every metric has the labels protocol (http, grpc, connect, grpcweb,
+// graphql) and method (route pattern or RPC procedure); the request
+// counter also has the status code.
+package metrics
+
+import (
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/probitas-test/echo-servers/shared/internal/httpwrap"
+	"github.com/probitas-test/echo-servers/shared/network"
+)
+
+// Path is where the metrics are served
+const Path = "/metrics"
+
+// Unmatched is the method label of requests that match no route
+const Unmatched = "unmatched"
+
+// Metrics holds the request metrics of one server
+type Metrics struct {
+	registry     *prometheus.Registry
+	requests     *prometheus.CounterVec
+	duration     *prometheus.HistogramVec
+	inFlight     *prometheus.GaugeVec
+	requestSize  *prometheus.HistogramVec
+	responseSize *prometheus.HistogramVec
+}
+
+// New creates the metrics of server (the "server" label of every metric),
+// together with the Go runtime and process collectors
+func New(server string) *Metrics {
+	labels := prometheus.Labels{"server": server}
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:        "echo_requests_total",
+			Help:        "Requests handled, by protocol, method and status code.",
+			ConstLabels: labels,
+		}, []string{"protocol", "method", "code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:        "echo_request_duration_seconds",
+			Help:        "Time from receiving a request to completing its response.",
+			ConstLabels: labels,
+			// 1ms to ~33s, covering the delay and streaming endpoints
+			Buckets: prometheus.ExponentialBuckets(0.001, 2, 16),
+		}, []string{"protocol", "method"}),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name:        "echo_requests_in_flight",
+			Help:        "Requests being handled.",
+			ConstLabels: labels,
+		}, []string{"protocol"}),
+		requestSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:        "echo_request_size_bytes",
+			Help:        "Request payload size (body or sum of messages).",
+			ConstLabels: labels,
+			// 64B to 16MiB
+			Buckets: prometheus.ExponentialBuckets(64, 4, 10),
+		}, []string{"protocol", "method"}),
+		responseSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:        "echo_response_size_bytes",
+			Help:        "Response payload size (body or sum of messages).",
+			ConstLabels: labels,
+			Buckets:     prometheus.ExponentialBuckets(64, 4, 10),
+		}, []string{"protocol", "method"}),
+	}
+	m.registry.MustRegister(
+		m.requests, m.duration, m.inFlight, m.requestSize, m.responseSize,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// Observation is a completed request
+type Observation struct {
+	Protocol     string
+	Method       string
+	Code         string
+	Duration     time.Duration
+	RequestSize  int64
+	ResponseSize int64
+}
+
+// Observe records a completed request
+func (m *Metrics) Observe(o Observation) {
+	m.requests.WithLabelValues(o.Protocol, o.Method, o.Code).Inc()
+	m.duration.WithLabelValues(o.Protocol, o.Method).Observe(o.Duration.Seconds())
+	m.requestSize.WithLabelValues(o.Protocol, o.Method).Observe(float64(o.RequestSize))
+	m.responseSize.WithLabelValues(o.Protocol, o.Method).Observe(float64(o.ResponseSize))
+}
+
+// Start counts a request of protocol as in flight until the returned
+// function is called
+func (m *Metrics) Start(protocol string) func() {
+	g := m.inFlight.WithLabelValues(protocol)
+	g.Inc()
+	return g.Dec
+}
+
+// Handler serves the metrics in the Prometheus text format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// Serve serves the metrics at /metrics on addr in the background, once it
+// listens. The returned server is shut down with the application server.
+func (m *Metrics) Serve(addr string) (*http.Server, error) {
+	mux := http.NewServeMux()
+	mux.Handle("GET "+Path, m.Handler())
+	listener, err := network.Listen(addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for the metrics: %w", err)
+	}
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := srv.Serve(listener); err != nil && err != http.ErrServerClosed {
+			slog.Error("Failed to serve metrics", "error", err)
+		}
+	}()
+	log.Printf("Serving metrics on %s%s", addr, Path)
+	return srv, nil
+}
+
+// HTTP returns middleware recording the requests of next with protocol.
+// route returns the method label after next has handled the request,
+// e.g. the matched route pattern; the default is Pattern.
+func (m *Metrics) HTTP(protocol string, route func(*http.Request) string) func(http.Handler) http.Handler {
+	if route == nil {
+		route = Pattern
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer m.Start(protocol)()
+			start := time.Now()
+
+			body := httpwrap.WrapBody(r)
+			rw := httpwrap.WrapResponseWriter(w)
+			next.ServeHTTP(rw, r)
+
+			m.Observe(Observation{
+				Protocol:     protocol,
+				Method:       route(r),
+				Code:         strconv.Itoa(rw.Status()),
+				Duration:     time.Since(start),
+				RequestSize:  body.N,
+				ResponseSize: rw.N,
+			})
+		})
+	}
+}
+
+// Pattern returns the http.ServeMux pattern that matched r (such as
+// "GET /health"), or Unmatched
+func Pattern(r *http.Request) string {
+	if r.Pattern == "" {
+		return Unmatched
+	}
+	return r.Pattern
+}
@@ -0,0 +1,118 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestHTTP(t *testing.T) {
+	m := New("echo-test")
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /echo/{id}", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write(body)
+		_, _ = w.Write(body)
+	})
+	h := m.HTTP("http", nil)(mux)
+
+	for range 2 {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("POST", "/echo/1", strings.NewReader("hello")))
+		if rec.Code != http.StatusCreated || rec.Body.String() != "hellohello" {
+			t.Fatalf("response = %d %q", rec.Code, rec.Body.String())
+		}
+	}
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/missing", nil))
+
+	if got := testutil.ToFloat64(m.requests.WithLabelValues("http", "POST /echo/{id}", "201")); got != 2 {
+		t.Errorf("requests = %v, want 2", got)
+	}
+	if got := testutil.ToFloat64(m.requests.WithLabelValues("http", Unmatched, "404")); got != 1 {
+		t.Errorf("unmatched requests = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(m.inFlight.WithLabelValues("http")); got != 0 {
+		t.Errorf("in flight = %v, want 0", got)
+	}
+
+	// Payload sizes are summed per method
+	out := scrape(t, m)
+	for _, want := range []string{
+		`echo_request_size_bytes_sum{method="POST /echo/{id}",protocol="http",server="echo-test"} 10`,
+		`echo_response_size_bytes_sum{method="POST /echo/{id}",protocol="http",server="echo-test"} 20`,
+		`echo_request_duration_seconds_count{method="POST /echo/{id}",protocol="http",server="echo-test"} 2`,
+		"go_goroutines",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("metrics missing %q", want)
+		}
+	}
+}
+
+func TestHTTPInFlight(t *testing.T) {
+	m := New("echo-test")
+	started, release := make(chan struct{}), make(chan struct{})
+	h := m.HTTP("graphql", func(*http.Request) string { return "/graphql" })(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	}))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/graphql", nil))
+	}()
+	<-started
+	if got := testutil.ToFloat64(m.inFlight.WithLabelValues("graphql")); got != 1 {
+		t.Errorf("in flight = %v, want 1", got)
+	}
+	close(release)
+	<-done
+
+	// A handler that writes nothing responds with 200
+	if got := testutil.ToFloat64(m.requests.WithLabelValues("graphql", "/graphql", "200")); got != 1 {
+		t.Errorf("requests = %v, want 1", got)
+	}
+}
+
+func TestObserve(t *testing.T) {
+	m := New("echo-test")
+	done := m.Start("grpc")
+	m.Observe(Observation{
+		Protocol:     "grpc",
+		Method:       "/echo.v1.Echo/Echo",
+		Code:         "OK",
+		Duration:     5 * time.Millisecond,
+		RequestSize:  100,
+		ResponseSize: 300,
+	})
+	done()
+
+	out := scrape(t, m)
+	for _, want := range []string{
+		`echo_requests_total{code="OK",method="/echo.v1.Echo/Echo",protocol="grpc",server="echo-test"} 1`,
+		`echo_request_duration_seconds_bucket{method="/echo.v1.Echo/Echo",protocol="grpc",server="echo-test",le="0.008"} 1`,
+		`echo_response_size_bytes_sum{method="/echo.v1.Echo/Echo",protocol="grpc",server="echo-test"} 300`,
+		`echo_requests_in_flight{protocol="grpc",server="echo-test"} 0`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("metrics missing %q", want)
+		}
+	}
+}
+
+func scrape(t *testing.T, m *Metrics) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest("GET", Path, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /metrics = %d", rec.Code)
+	}
+	return rec.Body.String()
+}
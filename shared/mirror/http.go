@@ -0,0 +1,123 @@
+package mirror
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/probitas-test/echo-servers/shared/internal/jsonhttp"
+)
+
+// Path is where the admin API is served
+const Path = "/mirror"
+
+// Header marks mirrored requests with the name of the mirroring server
+const Header = "X-Mirrored-By"
+
+// MaxBodySize is the largest request body mirrored; requests with larger
+// bodies are not mirrored
+const MaxBodySize = 1 << 20
+
+// hopHeaders are not copied to mirrored requests
+var hopHeaders = []string{
+	"Connection", "Keep-Alive", "Proxy-Authenticate", "Proxy-Authorization",
+	"Proxy-Connection", "Te", "Trailer", "Transfer-Encoding", "Upgrade",
+}
+
+// client sends the mirrored HTTP requests
+var client = &http.Client{
+	// Redirects are the target's answer, not followed
+	CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+}
+
+// HTTP is middleware sending a copy of the sampled requests to the target
+// of m, with the same method, path, query, headers and body, marked by the
+// X-Mirrored-By header set to name. Upgrade requests (WebSocket) and
+// bodies over MaxBodySize are not mirrored.
+func HTTP(m *Mirror, name string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Upgrade") != "" || !m.Sample() {
+				next.ServeHTTP(w, r)
+				return
+			}
+			body, err := io.ReadAll(io.LimitReader(r.Body, MaxBodySize+1))
+			// The handler reads the whole body, the buffered part first
+			r.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+			if err != nil || len(body) > MaxBodySize {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			method, path, query := r.Method, r.URL.Path, r.URL.RawQuery
+			header := r.Header.Clone()
+			host := r.Host
+			m.Go(func(ctx context.Context, target *url.URL) error {
+				u := *target
+				u.Path = strings.TrimSuffix(target.Path, "/") + path
+				u.RawPath = ""
+				u.RawQuery = query
+				req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+				if err != nil {
+					return err
+				}
+				req.Header = header
+				for _, h := range hopHeaders {
+					req.Header.Del(h)
+				}
+				req.Header.Set(Header, name)
+				req.Header.Set("X-Forwarded-Host", host)
+				resp, err := client.Do(req)
+				if err != nil {
+					return err
+				}
+				_, _ = io.Copy(io.Discard, resp.Body)
+				return resp.Body.Close()
+			})
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Handler serves the admin API of m:
+//
+//	GET    /mirror  current configuration and counters
+//	PUT    /mirror  replace the configuration (omitted fields get defaults)
+//	DELETE /mirror  restore the startup configuration, clear the counters
+func Handler(m *Mirror) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+		case http.MethodPut:
+			var c Config
+			err := jsonhttp.Decode(r, &c)
+			if err == nil {
+				err = m.SetConfig(c)
+			}
+			if err != nil {
+				jsonhttp.Error(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		case http.MethodDelete:
+			m.Reset()
+		default:
+			w.Header().Set("Allow", "GET, HEAD, PUT, DELETE")
+			jsonhttp.Error(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		jsonhttp.Write(w, http.StatusOK, State{Config: m.Config(), Stats: m.Stats()})
+	})
+}
+
+// State is the configuration of a Mirror with its counters, as served by
+// the admin API
+type State struct {
+	Config Config `json:"config"`
+	Stats  Stats  `json:"stats"`
+}
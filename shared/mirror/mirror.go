@@ -0,0 +1,228 @@
+// Package mirror copies a sample of the incoming traffic to a secondary
+// target, fire-and-forget, so shadow-traffic tooling and diff-testing
+// consumers can be fed by the echo servers. Mirrored requests are sent in
+// the background with a timeout; their responses and failures never
+// affect the original request.
+//
+// A Mirror is configured with the MIRROR_* settings and changed at runtime
+// through its admin API (Handler). HTTP servers use the HTTP middleware;
+// RPC servers call Sample, then Go with a function resending the call.
+package mirror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/probitas-test/echo-servers/shared/config"
+	"github.com/probitas-test/echo-servers/shared/internal/jsonhttp"
+)
+
+// Defaults of the fields omitted in the configuration or the admin API
+const (
+	DefaultSampleRate  = 1
+	DefaultTimeoutMS   = 5000
+	DefaultMaxInFlight = 100
+)
+
+// Config configures the mirroring
+type Config struct {
+	// Target is the base URL of the secondary target, such as
+	// "http://shadow:8080"; empty disables mirroring. RPC servers dial its
+	// host, with TLS for https.
+	Target string `json:"target"`
+	// SampleRate is the probability (0-1) that a request is mirrored
+	SampleRate float64 `json:"sample_rate"`
+	// TimeoutMS bounds each mirrored request
+	TimeoutMS int `json:"timeout_ms"`
+	// MaxInFlight caps the mirrored requests in progress; the next ones
+	// are dropped
+	MaxInFlight int `json:"max_in_flight"`
+}
+
+// LoadConfig reads the MIRROR_* settings from src
+func LoadConfig(src *config.Source) Config {
+	return Config{
+		Target:      src.String("MIRROR_TARGET", ""),
+		SampleRate:  src.Float("MIRROR_SAMPLE_RATE", DefaultSampleRate),
+		TimeoutMS:   src.Int("MIRROR_TIMEOUT_MS", DefaultTimeoutMS),
+		MaxInFlight: src.Int("MIRROR_MAX_IN_FLIGHT", DefaultMaxInFlight),
+	}
+}
+
+// DefaultConfig returns the configuration used for the fields omitted in
+// the admin API
+func DefaultConfig() Config {
+	return Config{SampleRate: DefaultSampleRate, TimeoutMS: DefaultTimeoutMS, MaxInFlight: DefaultMaxInFlight}
+}
+
+// UnmarshalJSON fills the fields missing from b with their defaults and
+// rejects unknown fields
+func (c *Config) UnmarshalJSON(b []byte) error {
+	type plain Config
+	v := plain(DefaultConfig())
+	if err := jsonhttp.DecodeStrict(b, &v); err != nil {
+		return err
+	}
+	*c = Config(v)
+	return nil
+}
+
+// Validate reports the first invalid setting
+func (c Config) Validate() error {
+	if c.Target != "" {
+		if _, err := c.URL(); err != nil {
+			return err
+		}
+	}
+	switch {
+	case c.SampleRate < 0 || c.SampleRate > 1:
+		return fmt.Errorf("sample rate %g is not between 0 and 1", c.SampleRate)
+	case c.TimeoutMS <= 0:
+		return errors.New("timeout must be positive")
+	case c.MaxInFlight <= 0:
+		return errors.New("max in flight must be positive")
+	}
+	return nil
+}
+
+// URL returns the parsed Target
+func (c Config) URL() (*url.URL, error) {
+	u, err := url.Parse(c.Target)
+	if err != nil {
+		return nil, fmt.Errorf("target: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("target %q is not an http or https URL", c.Target)
+	}
+	return u, nil
+}
+
+// String describes the mirroring for the startup log
+func (c Config) String() string {
+	if c.Target == "" {
+		return "disabled"
+	}
+	return fmt.Sprintf("target=%s sample_rate=%g timeout=%dms max_in_flight=%d", c.Target, c.SampleRate, c.TimeoutMS, c.MaxInFlight)
+}
+
+// Stats counts the mirrored requests
+type Stats struct {
+	// Sent requests got a response, whatever its status
+	Sent int64 `json:"sent"`
+	// Failed requests got no response (connection error, timeout)
+	Failed int64 `json:"failed"`
+	// Dropped requests were sampled beyond MaxInFlight
+	Dropped   int64  `json:"dropped"`
+	InFlight  int64  `json:"in_flight"`
+	LastError string `json:"last_error,omitempty"`
+}
+
+// Mirror sends sampled requests to the target of a configuration changed
+// at runtime
+type Mirror struct {
+	initial Config
+
+	mu     sync.RWMutex
+	cfg    Config
+	target *url.URL
+
+	inFlight, sent, failed, dropped atomic.Int64
+	lastError                       atomic.Value
+
+	// random returns a number in [0, 1), replaced in tests
+	random func() float64
+}
+
+// New creates a mirror of c
+func New(c Config) (*Mirror, error) {
+	m := &Mirror{initial: c, random: rand.Float64}
+	if err := m.SetConfig(c); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Config returns the current configuration
+func (m *Mirror) Config() Config {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.cfg
+}
+
+// SetConfig replaces the configuration
+func (m *Mirror) SetConfig(c Config) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	var target *url.URL
+	if c.Target != "" {
+		target, _ = c.URL()
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cfg = c
+	m.target = target
+	return nil
+}
+
+// Reset restores the startup configuration and clears the counters
+func (m *Mirror) Reset() {
+	_ = m.SetConfig(m.initial)
+	m.sent.Store(0)
+	m.failed.Store(0)
+	m.dropped.Store(0)
+	m.lastError.Store("")
+}
+
+// Stats returns the counters of the mirrored requests
+func (m *Mirror) Stats() Stats {
+	last, _ := m.lastError.Load().(string)
+	return Stats{
+		Sent:      m.sent.Load(),
+		Failed:    m.failed.Load(),
+		Dropped:   m.dropped.Load(),
+		InFlight:  m.inFlight.Load(),
+		LastError: last,
+	}
+}
+
+// Sample reports whether the current request is to be mirrored
+func (m *Mirror) Sample() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.target != nil && m.random() < m.cfg.SampleRate
+}
+
+// Go calls send in the background with the target and a context bounded by
+// the timeout, unless MaxInFlight requests are in progress or mirroring
+// was disabled since Sample
+func (m *Mirror) Go(send func(ctx context.Context, target *url.URL) error) {
+	m.mu.RLock()
+	cfg, target := m.cfg, m.target
+	m.mu.RUnlock()
+	if target == nil {
+		return
+	}
+	if m.inFlight.Add(1) > int64(cfg.MaxInFlight) {
+		m.inFlight.Add(-1)
+		m.dropped.Add(1)
+		return
+	}
+	go func() {
+		defer m.inFlight.Add(-1)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.TimeoutMS)*time.Millisecond)
+		defer cancel()
+		if err := send(ctx, target); err != nil {
+			m.failed.Add(1)
+			m.lastError.Store(err.Error())
+			return
+		}
+		m.sent.Add(1)
+	}()
+}
@@ -0,0 +1,147 @@
+package mirror
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+type mirrored struct {
+	method, uri, body, by, host string
+}
+
+func newTarget(t *testing.T) (*httptest.Server, chan mirrored) {
+	t.Helper()
+	got := make(chan mirrored, 10)
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got <- mirrored{r.Method, r.URL.RequestURI(), string(body), r.Header.Get(Header), r.Header.Get("X-Forwarded-Host")}
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	t.Cleanup(target.Close)
+	return target, got
+}
+
+func TestHTTP(t *testing.T) {
+	target, got := newTarget(t)
+	m, err := New(Config{Target: target.URL + "/shadow/", SampleRate: 1, TimeoutMS: 1000, MaxInFlight: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := HTTP(m, "echo-test")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_, _ = w.Write(body)
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("POST", "http://echo.test/anything?a=1", strings.NewReader("hello")))
+	if rec.Body.String() != "hello" {
+		t.Errorf("original request body = %q", rec.Body)
+	}
+
+	select {
+	case req := <-got:
+		want := mirrored{"POST", "/shadow/anything?a=1", "hello", "echo-test", "echo.test"}
+		if req != want {
+			t.Errorf("mirrored request = %+v, want %+v", req, want)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("request not mirrored")
+	}
+	waitStats(t, m, func(s Stats) bool { return s.Sent == 1 && s.InFlight == 0 })
+
+	// Not sampled
+	c := m.Config()
+	c.SampleRate = 0
+	if err := m.SetConfig(c); err != nil {
+		t.Fatal(err)
+	}
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/get", nil))
+	select {
+	case req := <-got:
+		t.Errorf("unsampled request mirrored: %+v", req)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestGo(t *testing.T) {
+	m, err := New(Config{Target: "http://shadow.test", SampleRate: 1, TimeoutMS: 1000, MaxInFlight: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	release := make(chan struct{})
+	m.Go(func(ctx context.Context, target *url.URL) error {
+		<-release
+		return errors.New("connection refused")
+	})
+	m.Go(func(ctx context.Context, target *url.URL) error { return nil })
+	close(release)
+	waitStats(t, m, func(s Stats) bool { return s.Failed == 1 && s.InFlight == 0 })
+	if s := m.Stats(); s.Dropped != 1 || s.Sent != 0 || s.LastError != "connection refused" {
+		t.Errorf("stats = %+v, want one dropped and one failed request", s)
+	}
+
+	m.Reset()
+	if s := m.Stats(); s.Failed != 0 || s.Dropped != 0 || s.LastError != "" {
+		t.Errorf("stats after Reset = %+v", s)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for name, c := range map[string]Config{
+		"not a URL":    {Target: "shadow:8080", SampleRate: 1, TimeoutMS: 1, MaxInFlight: 1},
+		"sample rate":  {Target: "http://shadow", SampleRate: 2, TimeoutMS: 1, MaxInFlight: 1},
+		"timeout":      {Target: "http://shadow", SampleRate: 1, MaxInFlight: 1},
+		"max inflight": {Target: "http://shadow", SampleRate: 1, TimeoutMS: 1},
+	} {
+		if err := c.Validate(); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+	if err := DefaultConfig().Validate(); err != nil {
+		t.Errorf("default configuration: %v", err)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	m, err := New(DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := Handler(m)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("PUT", Path, strings.NewReader(`{"target": "http://shadow:8080", "sample_rate": 0.5}`)))
+	if c := m.Config(); rec.Code != http.StatusOK || c.Target != "http://shadow:8080" || c.TimeoutMS != DefaultTimeoutMS {
+		t.Errorf("PUT = %d, config %+v", rec.Code, c)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("PUT", Path, strings.NewReader(`{"target": "shadow"}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("PUT invalid target = %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("DELETE", Path, nil))
+	if rec.Code != http.StatusOK || m.Config().Target != "" {
+		t.Errorf("DELETE = %d, config %+v", rec.Code, m.Config())
+	}
+}
+
+func waitStats(t *testing.T, m *Mirror, done func(Stats) bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !done(m.Stats()) {
+		if time.Now().After(deadline) {
+			t.Fatalf("stats = %+v", m.Stats())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
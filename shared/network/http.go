@@ -0,0 +1,48 @@
+package network
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/probitas-test/echo-servers/shared/internal/jsonhttp"
+)
+
+// Path is where Handler is served
+const Path = "/network"
+
+// Info describes the connection of a client
+type Info struct {
+	// Family is the family of the connection, "ipv4" or "ipv6"
+	Family string `json:"family"`
+	// ClientAddr is the address of the client, as seen by the server
+	ClientAddr string `json:"client_address"`
+	// ServerAddr is the local address the client connected to
+	ServerAddr string `json:"server_address,omitempty"`
+	// Listen is the family the server listens on (IP_FAMILY)
+	Listen string `json:"listen"`
+}
+
+// Describe returns the Info of a connection from remote to local; local
+// may be nil
+func Describe(local, remote net.Addr) Info {
+	info := Info{Family: Of(remote), ClientAddr: remote.String(), Listen: Family()}
+	if local != nil {
+		info.ServerAddr = local.String()
+	}
+	return info
+}
+
+// Handler reports the Info of the connection of each request. Forwarding
+// headers are ignored: the family is the one of the connection to the
+// server.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		remote, err := net.ResolveTCPAddr("tcp", r.RemoteAddr)
+		if err != nil {
+			jsonhttp.Error(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		local, _ := r.Context().Value(http.LocalAddrContextKey).(net.Addr)
+		jsonhttp.Write(w, http.StatusOK, Describe(local, remote))
+	})
+}
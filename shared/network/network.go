@@ -0,0 +1,151 @@
+// Package network selects the IP versions every listener of a server
+// accepts (IP_FAMILY): IPv4 only, IPv6 only, or both on one dual-stack
+// socket. Handler reports the family of the client connection, so
+// dual-stack clients (happy eyeballs) can check which one they used.
+//
+// Servers call Setup once at startup, then listen with Listen,
+// ListenPacket and ListenAndServe instead of the net and net/http
+// functions.
+package network
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/probitas-test/echo-servers/shared/config"
+)
+
+// Families (the values of IP_FAMILY)
+const (
+	Dual = "dual"
+	IPv4 = "ipv4"
+	IPv6 = "ipv6"
+)
+
+// Config configures the family of the listeners
+type Config struct {
+	Family string
+}
+
+// LoadConfig reads IP_FAMILY from src
+func LoadConfig(src *config.Source) Config {
+	return Config{Family: src.String("IP_FAMILY", Dual)}
+}
+
+// family is the family installed by Setup
+var family atomic.Value
+
+// Setup installs the family of c for the listeners created afterwards
+func Setup(c Config) error {
+	switch c.Family {
+	case Dual, IPv4, IPv6:
+	default:
+		return fmt.Errorf("unsupported IP family %q (must be dual, ipv4 or ipv6)", c.Family)
+	}
+	family.Store(c.Family)
+	return nil
+}
+
+// Family returns the family installed by Setup, dual by default
+func Family() string {
+	if f, ok := family.Load().(string); ok {
+		return f
+	}
+	return Dual
+}
+
+// TCP returns the TCP network of the family: "tcp" (dual-stack), "tcp4" or
+// "tcp6"
+func TCP() string {
+	return "tcp" + suffix()
+}
+
+// UDP returns the UDP network of the family: "udp", "udp4" or "udp6"
+func UDP() string {
+	return "udp" + suffix()
+}
+
+func suffix() string {
+	switch Family() {
+	case IPv4:
+		return "4"
+	case IPv6:
+		return "6"
+	}
+	return ""
+}
+
+// Listen listens for TCP connections of the family on addr. An unspecified
+// host (0.0.0.0 or ::) listens on every address of the family.
+func Listen(addr string) (net.Listener, error) {
+	return net.Listen(TCP(), Addr(addr))
+}
+
+// ListenPacket listens for UDP datagrams of the family on addr, like Listen
+func ListenPacket(addr string) (net.PacketConn, error) {
+	return net.ListenPacket(UDP(), Addr(addr))
+}
+
+// Addr returns addr with its host replaced by Host
+func Addr(addr string) string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return net.JoinHostPort(Host(host), port)
+}
+
+// Host returns an unspecified host replaced by the unspecified address of
+// the family, so the default HOST (0.0.0.0) also listens on IPv6 only
+func Host(host string) string {
+	if ip := net.ParseIP(host); ip == nil || !ip.IsUnspecified() {
+		return host
+	}
+	switch Family() {
+	case IPv4:
+		return net.IPv4zero.String()
+	case IPv6:
+		return net.IPv6unspecified.String()
+	}
+	return host
+}
+
+// ListenAndServe serves srv on srv.Addr like http.Server.ListenAndServe,
+// with a listener of the family
+func ListenAndServe(srv *http.Server) error {
+	l, err := Listen(srv.Addr)
+	if err != nil {
+		return err
+	}
+	return srv.Serve(l)
+}
+
+// Of returns the family of the IP address of addr, "ipv4" or "ipv6".
+// IPv4-mapped IPv6 addresses, as accepted by dual-stack sockets from IPv4
+// clients, are IPv4.
+func Of(addr net.Addr) string {
+	var ip net.IP
+	switch a := addr.(type) {
+	case *net.TCPAddr:
+		ip = a.IP
+	case *net.UDPAddr:
+		ip = a.IP
+	case *net.IPAddr:
+		ip = a.IP
+	default:
+		host, _, err := net.SplitHostPort(addr.String())
+		if err != nil {
+			host = addr.String()
+		}
+		ip = net.ParseIP(host)
+	}
+	if ip == nil {
+		return ""
+	}
+	if ip.To4() != nil {
+		return IPv4
+	}
+	return IPv6
+}
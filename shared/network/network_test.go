@@ -0,0 +1,135 @@
+package network
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// setFamily installs family f for the duration of the test
+func setFamily(t *testing.T, f string) {
+	t.Helper()
+	prev := Family()
+	if err := Setup(Config{Family: f}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = Setup(Config{Family: prev}) })
+}
+
+// hasIPv6 reports whether the host has an IPv6 loopback address
+func hasIPv6() bool {
+	l, err := net.Listen("tcp6", "[::1]:0")
+	if err != nil {
+		return false
+	}
+	_ = l.Close()
+	return true
+}
+
+func TestSetup(t *testing.T) {
+	if err := Setup(Config{Family: "ipv5"}); err == nil {
+		t.Error("Setup accepted ipv5")
+	}
+	setFamily(t, IPv6)
+	if TCP() != "tcp6" || UDP() != "udp6" {
+		t.Errorf("networks = %s, %s", TCP(), UDP())
+	}
+}
+
+func TestListen(t *testing.T) {
+	if !hasIPv6() {
+		t.Skip("no IPv6 loopback")
+	}
+	tests := []struct {
+		family     string
+		ipv4, ipv6 bool
+	}{
+		{Dual, true, true},
+		{IPv4, true, false},
+		{IPv6, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.family, func(t *testing.T) {
+			setFamily(t, tt.family)
+			l, err := Listen("0.0.0.0:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() { _ = l.Close() }()
+			go func() {
+				for {
+					c, err := l.Accept()
+					if err != nil {
+						return
+					}
+					_ = c.Close()
+				}
+			}()
+			_, port, _ := net.SplitHostPort(l.Addr().String())
+			for _, c := range []struct {
+				host string
+				want bool
+			}{{"127.0.0.1", tt.ipv4}, {"::1", tt.ipv6}} {
+				conn, err := net.Dial("tcp", net.JoinHostPort(c.host, port))
+				if err == nil {
+					_ = conn.Close()
+				}
+				if (err == nil) != c.want {
+					t.Errorf("dial %s: %v, want accepted = %v", c.host, err, c.want)
+				}
+			}
+		})
+	}
+}
+
+func TestOf(t *testing.T) {
+	tests := map[string]string{
+		"192.0.2.1:80":          IPv4,
+		"[::ffff:192.0.2.1]:80": IPv4,
+		"[2001:db8::1]:80":      IPv6,
+		"[::1]:80":              IPv6,
+	}
+	for addr, want := range tests {
+		a, err := net.ResolveTCPAddr("tcp", addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := Of(a); got != want {
+			t.Errorf("Of(%s) = %q, want %q", addr, got, want)
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	hosts := []string{"127.0.0.1"}
+	if hasIPv6() {
+		hosts = append(hosts, "::1")
+	}
+	for _, host := range hosts {
+		l, err := net.Listen("tcp", net.JoinHostPort(host, "0"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		srv := httptest.NewUnstartedServer(Handler())
+		srv.Listener = l
+		srv.Start()
+
+		resp, err := http.Get(srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var info Info
+		err = json.NewDecoder(resp.Body).Decode(&info)
+		_ = resp.Body.Close()
+		srv.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := Of(l.Addr())
+		if info.Family != want || info.ServerAddr != l.Addr().String() || info.Listen != Dual {
+			t.Errorf("GET %s over %s = %+v, want family %s", Path, host, info, want)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package ratelimit
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/probitas-test/echo-servers/shared/internal/jsonhttp"
+)
+
+// Path is where the admin API is served
+const Path = "/ratelimit"
+
+// Headers reporting the quota of a client, after the IETF RateLimit header
+// fields draft, plus Retry-After on denied requests
+const (
+	HeaderLimit      = "RateLimit-Limit"
+	HeaderRemaining  = "RateLimit-Remaining"
+	HeaderReset      = "RateLimit-Reset"
+	HeaderRetryAfter = "Retry-After"
+)
+
+// Header returns the headers reporting d, none for requests that are not
+// limited. Times are in whole seconds, rounded up.
+func (d Decision) Header() http.Header {
+	h := http.Header{}
+	if !d.Limited {
+		return h
+	}
+	h.Set(HeaderLimit, strconv.Itoa(d.Limit))
+	h.Set(HeaderRemaining, strconv.Itoa(d.Remaining))
+	h.Set(HeaderReset, strconv.Itoa(ceilSeconds(d.Reset)))
+	if !d.Allowed {
+		h.Set(HeaderRetryAfter, strconv.Itoa(max(ceilSeconds(d.RetryAfter), 1)))
+	}
+	return h
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// HTTP is middleware throttling every request with l but the health checks
+// at /health and those of the admin API. Rules match the path or the
+// method and path of a request. Denied requests get 429 Too Many Requests.
+func HTTP(l *Limiter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == Path || r.URL.Path == "/health" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			d := l.Take(r.RemoteAddr, r.Header.Get(l.Config().KeyHeader), r.URL.Path, r.Method+" "+r.URL.Path)
+			for name, values := range d.Header() {
+				w.Header()[name] = values
+			}
+			if !d.Allowed {
+				http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+				return
+			}
+			defer d.Done()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Handler serves the admin API of l:
+//
+//	GET    /ratelimit  current configuration and number of clients counted
+//	PUT    /ratelimit  replace the configuration (omitted fields get defaults)
+//	DELETE /ratelimit  restore the startup configuration
+//
+// Changing the configuration forgets every client.
+func Handler(l *Limiter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+		case http.MethodPut:
+			var c Config
+			err := jsonhttp.Decode(r, &c)
+			if err == nil {
+				err = l.SetConfig(c)
+			}
+			if err != nil {
+				jsonhttp.Error(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		case http.MethodDelete:
+			l.Reset()
+		default:
+			w.Header().Set("Allow", "GET, HEAD, PUT, DELETE")
+			jsonhttp.Error(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		jsonhttp.Write(w, http.StatusOK, State{Config: l.Config(), Clients: l.Clients()})
+	})
+}
+
+// State is the configuration of a Limiter and the number of clients it
+// counts, as served by the admin API
+type State struct {
+	Config  Config `json:"config"`
+	Clients int    `json:"clients"`
+}
@@ -0,0 +1,357 @@
+// Package ratelimit throttles the requests a server handles with a token
+// bucket, a sliding window or a concurrency limit, scoped per client IP,
+// per API key or globally, so client throttling behavior can be tested
+// with the same settings on every protocol.
+//
+// A Limiter is configured with the RATE_LIMIT_* settings and replaced at
+// runtime through its admin API (Handler). echo-http applies it with the
+// HTTP middleware, RPC servers with their own interceptors calling Take and
+// reporting the Decision in their headers or metadata.
+package ratelimit
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/probitas-test/echo-servers/shared/chaos"
+	"github.com/probitas-test/echo-servers/shared/config"
+	"github.com/probitas-test/echo-servers/shared/internal/jsonhttp"
+)
+
+// Strategy is the algorithm counting the requests of a client
+type Strategy string
+
+// Strategies of a limiter
+const (
+	// TokenBucket allows bursts of Limit requests, refilled at Limit
+	// requests per Window
+	TokenBucket Strategy = "token-bucket"
+	// SlidingWindow allows Limit requests in any Window
+	SlidingWindow Strategy = "sliding-window"
+	// Concurrency allows Limit requests in flight
+	Concurrency Strategy = "concurrency"
+)
+
+var strategies = []Strategy{TokenBucket, SlidingWindow, Concurrency}
+
+// Scope is what the requests are counted per
+type Scope string
+
+// Scopes of a limiter
+const (
+	// ScopeIP counts the requests of every client IP address
+	ScopeIP Scope = "ip"
+	// ScopeKey counts the requests of every value of the key header,
+	// falling back to the client IP address without it
+	ScopeKey Scope = "key"
+	// ScopeGlobal counts every request together
+	ScopeGlobal Scope = "global"
+)
+
+var scopes = []Scope{ScopeIP, ScopeKey, ScopeGlobal}
+
+// DefaultKeyHeader is the header identifying the client in ScopeKey
+const DefaultKeyHeader = "X-API-Key"
+
+// Config configures the limits
+type Config struct {
+	Enabled  bool     `json:"enabled"`
+	Strategy Strategy `json:"strategy"`
+	Limit    int      `json:"limit"`
+	// WindowMS is the window of SlidingWindow and the refill period of
+	// TokenBucket
+	WindowMS  int    `json:"window_ms"`
+	Scope     Scope  `json:"scope"`
+	KeyHeader string `json:"key_header"`
+	// Routes limits the throttling to the matching routes (all when
+	// empty), with the patterns of chaos rules
+	Routes []string `json:"routes,omitempty"`
+}
+
+// LoadConfig reads the RATE_LIMIT_* settings from src
+func LoadConfig(src *config.Source) Config {
+	return Config{
+		Enabled:   src.Bool("RATE_LIMIT_ENABLED", false),
+		Strategy:  Strategy(src.String("RATE_LIMIT_STRATEGY", string(TokenBucket))),
+		Limit:     src.Int("RATE_LIMIT_REQUESTS", 10),
+		WindowMS:  src.Int("RATE_LIMIT_WINDOW_MS", 1000),
+		Scope:     Scope(src.String("RATE_LIMIT_SCOPE", string(ScopeIP))),
+		KeyHeader: src.String("RATE_LIMIT_KEY_HEADER", DefaultKeyHeader),
+		Routes:    src.List("RATE_LIMIT_ROUTES", ""),
+	}
+}
+
+// DefaultConfig returns the configuration used for the fields omitted in
+// the admin API
+func DefaultConfig() Config {
+	return Config{
+		Strategy:  TokenBucket,
+		Limit:     10,
+		WindowMS:  1000,
+		Scope:     ScopeIP,
+		KeyHeader: DefaultKeyHeader,
+	}
+}
+
+// UnmarshalJSON fills the fields missing from b with their defaults and
+// rejects unknown fields
+func (c *Config) UnmarshalJSON(b []byte) error {
+	type plain Config
+	v := plain(DefaultConfig())
+	if err := jsonhttp.DecodeStrict(b, &v); err != nil {
+		return err
+	}
+	*c = Config(v)
+	return nil
+}
+
+// Validate reports the first invalid setting
+func (c Config) Validate() error {
+	if !slices.Contains(strategies, c.Strategy) {
+		return fmt.Errorf("invalid strategy %q (want token-bucket, sliding-window or concurrency)", c.Strategy)
+	}
+	if !slices.Contains(scopes, c.Scope) {
+		return fmt.Errorf("invalid scope %q (want ip, key or global)", c.Scope)
+	}
+	if c.Limit < 1 {
+		return errors.New("limit must be at least 1")
+	}
+	if c.WindowMS < 1 && c.Strategy != Concurrency {
+		return errors.New("window_ms must be at least 1")
+	}
+	if c.Scope == ScopeKey && c.KeyHeader == "" {
+		return errors.New("key_header is required with the key scope")
+	}
+	return nil
+}
+
+// String describes the limits for the startup log
+func (c Config) String() string {
+	if !c.Enabled {
+		return "disabled"
+	}
+	routes := "all"
+	if len(c.Routes) > 0 {
+		routes = strings.Join(c.Routes, ",")
+	}
+	if c.Strategy == Concurrency {
+		return fmt.Sprintf("%s limit=%d scope=%s, routes=%s", c.Strategy, c.Limit, c.Scope, routes)
+	}
+	return fmt.Sprintf("%s limit=%d window=%dms scope=%s, routes=%s", c.Strategy, c.Limit, c.WindowMS, c.Scope, routes)
+}
+
+func (c Config) window() time.Duration {
+	return time.Duration(c.WindowMS) * time.Millisecond
+}
+
+// counter counts the requests of one client
+type counter struct {
+	// tokens left at refilled (TokenBucket)
+	tokens   float64
+	refilled time.Time
+	// times of the requests in the window (SlidingWindow)
+	times []time.Time
+	// requests in flight (Concurrency)
+	inFlight int
+}
+
+// Limiter throttles requests under a configuration replaced at runtime
+type Limiter struct {
+	initial Config
+
+	mu        sync.Mutex
+	cfg       Config
+	counters  map[string]*counter
+	lastSweep time.Time
+}
+
+// New creates a limiter with the startup configuration c
+func New(c Config) (*Limiter, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return &Limiter{initial: c, cfg: c, counters: make(map[string]*counter)}, nil
+}
+
+// Config returns the configuration in effect
+func (l *Limiter) Config() Config {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.cfg
+}
+
+// SetConfig replaces the configuration and forgets every client
+func (l *Limiter) SetConfig(c Config) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.cfg = c
+	clear(l.counters)
+	return nil
+}
+
+// Reset restores the startup configuration and forgets every client
+func (l *Limiter) Reset() {
+	_ = l.SetConfig(l.initial)
+}
+
+// Clear forgets every client, keeping the configuration
+func (l *Limiter) Clear() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	clear(l.counters)
+}
+
+// Clients returns the number of clients being counted
+func (l *Limiter) Clients() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.counters)
+}
+
+// Decision is the outcome of Take for one request
+type Decision struct {
+	// Limited reports whether the request is subject to the limits
+	Limited bool
+	// Allowed reports whether the request may proceed
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is the time until the quota is fully available again
+	Reset time.Duration
+	// RetryAfter is the time until a denied request may be retried
+	RetryAfter time.Duration
+
+	release func()
+}
+
+// Done releases the slot of an allowed request of the concurrency strategy.
+// It is a no-op for the other strategies and denied requests.
+func (d Decision) Done() {
+	if d.release != nil {
+		d.release()
+	}
+}
+
+// Take counts one request from the client at remoteAddr, presenting key
+// as the value of the key header, and decides whether it may proceed.
+// routes are the keys of the request matched against the configured
+// routes, like those of chaos rules.
+func (l *Limiter) Take(remoteAddr, key string, routes ...string) Decision {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	cfg := l.cfg
+	if !cfg.Enabled || !matches(cfg.Routes, routes) {
+		return Decision{Allowed: true}
+	}
+
+	now := time.Now()
+	l.sweep(now)
+	id := client(cfg, remoteAddr, key)
+	c, ok := l.counters[id]
+	if !ok {
+		c = &counter{tokens: float64(cfg.Limit), refilled: now}
+		l.counters[id] = c
+	}
+
+	d := Decision{Limited: true, Limit: cfg.Limit}
+	switch cfg.Strategy {
+	case TokenBucket:
+		rate := float64(cfg.Limit) / cfg.window().Seconds()
+		c.tokens = math.Min(float64(cfg.Limit), c.tokens+now.Sub(c.refilled).Seconds()*rate)
+		c.refilled = now
+		if c.tokens >= 1 {
+			c.tokens--
+			d.Allowed = true
+		} else {
+			d.RetryAfter = seconds((1 - c.tokens) / rate)
+		}
+		d.Remaining = int(c.tokens)
+		d.Reset = seconds((float64(cfg.Limit) - c.tokens) / rate)
+	case SlidingWindow:
+		c.times = slices.DeleteFunc(c.times, func(t time.Time) bool { return now.Sub(t) >= cfg.window() })
+		if len(c.times) < cfg.Limit {
+			c.times = append(c.times, now)
+			d.Allowed = true
+		} else {
+			d.RetryAfter = c.times[0].Add(cfg.window()).Sub(now)
+		}
+		d.Remaining = cfg.Limit - len(c.times)
+		if len(c.times) > 0 {
+			d.Reset = c.times[len(c.times)-1].Add(cfg.window()).Sub(now)
+		}
+	case Concurrency:
+		if c.inFlight < cfg.Limit {
+			c.inFlight++
+			d.Allowed = true
+			d.release = sync.OnceFunc(func() {
+				l.mu.Lock()
+				defer l.mu.Unlock()
+				c.inFlight--
+			})
+		} else {
+			// No way to know when a request completes: retry in a second
+			d.RetryAfter = time.Second
+		}
+		d.Remaining = cfg.Limit - c.inFlight
+	}
+	return d
+}
+
+// seconds converts a number of seconds to a duration
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// client returns the identity the requests are counted per
+func client(cfg Config, remoteAddr, key string) string {
+	switch {
+	case cfg.Scope == ScopeGlobal:
+		return ""
+	case cfg.Scope == ScopeKey && key != "":
+		return "key:" + key
+	}
+	if host, _, err := net.SplitHostPort(remoteAddr); err == nil {
+		remoteAddr = host
+	}
+	return "ip:" + remoteAddr
+}
+
+func matches(patterns, routes []string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		for _, route := range routes {
+			if chaos.Match(pattern, route) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sweep forgets the idle clients once per window, so counting per IP or
+// key does not grow without bound
+func (l *Limiter) sweep(now time.Time) {
+	window := max(l.cfg.window(), time.Second)
+	if now.Sub(l.lastSweep) < window {
+		return
+	}
+	l.lastSweep = now
+	for id, c := range l.counters {
+		idle := c.inFlight == 0 && now.Sub(c.refilled) >= window &&
+			(len(c.times) == 0 || now.Sub(c.times[len(c.times)-1]) >= window)
+		if idle {
+			delete(l.counters, id)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package ratelimit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newLimiter(t *testing.T, c Config) *Limiter {
+	t.Helper()
+	l, err := New(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+// take takes n requests from the client at addr and returns how many were
+// allowed
+func take(l *Limiter, addr, key string, n int) int {
+	allowed := 0
+	for range n {
+		if l.Take(addr, key, "/get").Allowed {
+			allowed++
+		}
+	}
+	return allowed
+}
+
+func TestStrategies(t *testing.T) {
+	for _, strategy := range []Strategy{TokenBucket, SlidingWindow} {
+		c := DefaultConfig()
+		c.Enabled, c.Strategy, c.Limit, c.WindowMS = true, strategy, 3, 50
+		l := newLimiter(t, c)
+
+		if allowed := take(l, "10.0.0.1:1234", "", 5); allowed != 3 {
+			t.Errorf("%s: %d of 5 requests allowed, want 3", strategy, allowed)
+		}
+		d := l.Take("10.0.0.1:5678", "", "/get")
+		if d.Allowed || !d.Limited || d.Remaining != 0 || d.RetryAfter <= 0 || d.RetryAfter > 50*time.Millisecond {
+			t.Errorf("%s: denied decision %+v", strategy, d)
+		}
+		if allowed := take(l, "10.0.0.2:1234", "", 1); allowed != 1 {
+			t.Errorf("%s: request of another IP denied", strategy)
+		}
+		time.Sleep(60 * time.Millisecond)
+		if allowed := take(l, "10.0.0.1:1234", "", 3); allowed != 3 {
+			t.Errorf("%s: %d of 3 requests allowed after the window, want 3", strategy, allowed)
+		}
+	}
+}
+
+func TestConcurrency(t *testing.T) {
+	c := DefaultConfig()
+	c.Enabled, c.Strategy, c.Limit = true, Concurrency, 2
+	l := newLimiter(t, c)
+
+	first, second := l.Take("10.0.0.1:1", "", "/get"), l.Take("10.0.0.1:2", "", "/get")
+	if !first.Allowed || !second.Allowed || second.Remaining != 0 {
+		t.Fatalf("decisions %+v %+v", first, second)
+	}
+	if d := l.Take("10.0.0.1:3", "", "/get"); d.Allowed || d.RetryAfter != time.Second {
+		t.Errorf("third concurrent request %+v", d)
+	}
+	first.Done()
+	first.Done()
+	if d := l.Take("10.0.0.1:3", "", "/get"); !d.Allowed || d.Remaining != 0 {
+		t.Errorf("request after Done %+v", d)
+	}
+}
+
+func TestScopes(t *testing.T) {
+	c := DefaultConfig()
+	c.Enabled, c.Limit, c.Scope = true, 1, ScopeKey
+	l := newLimiter(t, c)
+	if take(l, "10.0.0.1:1", "a", 2) != 1 || take(l, "10.0.0.1:1", "b", 1) != 1 || take(l, "10.0.0.1:1", "", 2) != 1 {
+		t.Error("requests not counted per key, falling back to the IP")
+	}
+
+	c.Scope = ScopeGlobal
+	l = newLimiter(t, c)
+	if take(l, "10.0.0.1:1", "", 1) != 1 || take(l, "10.0.0.2:1", "", 1) != 0 {
+		t.Error("requests not counted globally")
+	}
+
+	c.Scope, c.Routes = ScopeIP, []string{"/status/*"}
+	l = newLimiter(t, c)
+	if d := l.Take("10.0.0.1:1", "", "/get"); !d.Allowed || d.Limited {
+		t.Errorf("unmatched route limited: %+v", d)
+	}
+	if first, second := l.Take("10.0.0.1:1", "", "/status/500"), l.Take("10.0.0.1:1", "", "/status/500"); !first.Allowed || second.Allowed {
+		t.Errorf("matched route not limited: %+v %+v", first, second)
+	}
+}
+
+func TestHTTP(t *testing.T) {
+	c := DefaultConfig()
+	c.Enabled, c.Limit, c.WindowMS = true, 1, 60000
+	l := newLimiter(t, c)
+	h := HTTP(l)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/get", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get(HeaderLimit) != "1" || rec.Header().Get(HeaderRemaining) != "0" ||
+		rec.Header().Get(HeaderReset) != "60" {
+		t.Errorf("allowed request = %d %v", rec.Code, rec.Header())
+	}
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/get", nil))
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get(HeaderRetryAfter) != "60" {
+		t.Errorf("denied request = %d %v", rec.Code, rec.Header())
+	}
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/health", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get(HeaderLimit) != "" {
+		t.Errorf("health check = %d %v", rec.Code, rec.Header())
+	}
+}
+
+func TestHandler(t *testing.T) {
+	l := newLimiter(t, DefaultConfig())
+	h := Handler(l)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("PUT", Path, strings.NewReader(`{"enabled":true,"strategy":"sliding-window","limit":5}`)))
+	var s State
+	if err := json.Unmarshal(rec.Body.Bytes(), &s); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusOK || !s.Config.Enabled || s.Config.Strategy != SlidingWindow || s.Config.Limit != 5 ||
+		s.Config.WindowMS != 1000 || s.Config.Scope != ScopeIP {
+		t.Errorf("PUT %s = %d %+v", Path, rec.Code, s)
+	}
+
+	for _, body := range []string{`{"strategy":"leaky-bucket"}`, `{"limit":0}`, `{"scope":"key","key_header":""}`, `{"unknown":1}`} {
+		rec = httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("PUT", Path, strings.NewReader(body)))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("PUT %s %s = %d, want 400", Path, body, rec.Code)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("DELETE", Path, nil))
+	if rec.Code != http.StatusOK || l.Config().Enabled {
+		t.Errorf("DELETE %s = %d, config %+v", Path, rec.Code, l.Config())
+	}
+}
@@ -0,0 +1,287 @@
+package recording
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"sort"
+	"time"
+	"unicode/utf8"
+
+	"github.com/probitas-test/echo-servers/shared/internal/httpwrap"
+)
+
+// HAR 1.2 (http://www.softwareishard.com/blog/har-12-spec/) types of the
+// recorded entries. Header sizes, which the server cannot observe, are -1.
+
+// HAR is a HAR document
+type HAR struct {
+	Log Log `json:"log"`
+}
+
+// Log is the log of a HAR document
+type Log struct {
+	Version string            `json:"version"`
+	Creator Creator           `json:"creator"`
+	Entries []json.RawMessage `json:"entries"`
+}
+
+// Creator names the application that recorded a HAR document
+type Creator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// Entry is one recorded HTTP exchange
+type Entry struct {
+	StartedDateTime time.Time `json:"startedDateTime"`
+	// Time is the time to handle the request, in milliseconds
+	Time     float64  `json:"time"`
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+	Cache    struct{} `json:"cache"`
+	Timings  Timings  `json:"timings"`
+	// ClientAddress is the remote address of the client (not in HAR 1.2)
+	ClientAddress string `json:"_clientAddress,omitempty"`
+}
+
+// NameValue is a header or query parameter
+type NameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Request is the request of an Entry
+type Request struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	HTTPVersion string      `json:"httpVersion"`
+	Cookies     []NameValue `json:"cookies"`
+	Headers     []NameValue `json:"headers"`
+	QueryString []NameValue `json:"queryString"`
+	PostData    *PostData   `json:"postData,omitempty"`
+	HeadersSize int         `json:"headersSize"`
+	BodySize    int64       `json:"bodySize"`
+}
+
+// PostData is the body of a Request. Binary bodies are base64-encoded,
+// like response contents.
+type PostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+	Encoding string `json:"encoding,omitempty"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+// Response is the response of an Entry
+type Response struct {
+	Status      int         `json:"status"`
+	StatusText  string      `json:"statusText"`
+	HTTPVersion string      `json:"httpVersion"`
+	Cookies     []NameValue `json:"cookies"`
+	Headers     []NameValue `json:"headers"`
+	Content     Content     `json:"content"`
+	RedirectURL string      `json:"redirectURL"`
+	HeadersSize int         `json:"headersSize"`
+	BodySize    int64       `json:"bodySize"`
+}
+
+// Content is the body of a Response
+type Content struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+// Timings splits the time of an Entry; the server only observes the wait
+type Timings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// HTTP is middleware recording every request and response of next as a HAR
+// entry while recording is enabled. Bodies are recorded as far as they are
+// read and written, up to MaxBodyBytes.
+func (r *Recorder) HTTP(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !r.Enabled() {
+			next.ServeHTTP(w, req)
+			return
+		}
+		start := time.Now()
+		reqBody := &captureBody{ReadCloser: req.Body, buf: limitedBuffer{max: r.cfg.MaxBodyBytes}}
+		if req.Body != nil && req.Body != http.NoBody {
+			req.Body = reqBody
+		}
+		rw := &captureWriter{ResponseWriter: httpwrap.WrapResponseWriter(w), buf: limitedBuffer{max: r.cfg.MaxBodyBytes}}
+		// Captured before the handler runs, as handlers may modify it
+		reqHeader := req.Header.Clone()
+
+		defer func() {
+			elapsed := float64(time.Since(start).Microseconds()) / 1000
+			entry := Entry{
+				StartedDateTime: start,
+				Time:            elapsed,
+				Request:         harRequest(req, reqHeader, reqBody),
+				Response:        harResponse(req, rw),
+				Timings:         Timings{Wait: elapsed},
+				ClientAddress:   req.RemoteAddr,
+			}
+			if err := r.append(kindHAR, entry); err != nil {
+				slog.ErrorContext(req.Context(), "failed to record request", "error", err)
+			}
+		}()
+		next.ServeHTTP(rw, req)
+	})
+}
+
+func harRequest(req *http.Request, header http.Header, body *captureBody) Request {
+	scheme := "http"
+	if req.TLS != nil {
+		scheme = "https"
+	}
+	hr := Request{
+		Method:      req.Method,
+		URL:         scheme + "://" + req.Host + req.URL.RequestURI(),
+		HTTPVersion: req.Proto,
+		Cookies:     []NameValue{},
+		Headers:     nameValues(header),
+		QueryString: nameValues(req.URL.Query()),
+		HeadersSize: -1,
+		BodySize:    body.n,
+	}
+	for _, c := range req.Cookies() {
+		hr.Cookies = append(hr.Cookies, NameValue{Name: c.Name, Value: c.Value})
+	}
+	if body.n > 0 {
+		text, encoding := encode(body.buf.Bytes())
+		hr.PostData = &PostData{
+			MimeType: header.Get("Content-Type"),
+			Text:     text,
+			Encoding: encoding,
+			Comment:  body.buf.comment(),
+		}
+	}
+	return hr
+}
+
+func harResponse(req *http.Request, rw *captureWriter) Response {
+	status := rw.Status()
+	header := rw.Header()
+	text, encoding := encode(rw.buf.Bytes())
+	hr := Response{
+		Status:      status,
+		StatusText:  http.StatusText(status),
+		HTTPVersion: req.Proto,
+		Cookies:     []NameValue{},
+		Headers:     nameValues(header),
+		Content: Content{
+			Size:     rw.N,
+			MimeType: header.Get("Content-Type"),
+			Text:     text,
+			Encoding: encoding,
+			Comment:  rw.buf.comment(),
+		},
+		RedirectURL: header.Get("Location"),
+		HeadersSize: -1,
+		BodySize:    rw.N,
+	}
+	for _, c := range (&http.Response{Header: header}).Cookies() {
+		hr.Cookies = append(hr.Cookies, NameValue{Name: c.Name, Value: c.Value})
+	}
+	return hr
+}
+
+// nameValues flattens h in name order
+func nameValues(h map[string][]string) []NameValue {
+	names := make([]string, 0, len(h))
+	for name := range h {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	nv := []NameValue{}
+	for _, name := range names {
+		for _, value := range h[name] {
+			nv = append(nv, NameValue{Name: name, Value: value})
+		}
+	}
+	return nv
+}
+
+// encode returns b as text, base64-encoded unless it is valid UTF-8
+func encode(b []byte) (text, encoding string) {
+	if utf8.Valid(b) {
+		return string(b), ""
+	}
+	return base64.StdEncoding.EncodeToString(b), "base64"
+}
+
+// limitedBuffer keeps the first max bytes written to it
+type limitedBuffer struct {
+	bytes.Buffer
+	max       int
+	truncated bool
+}
+
+func (b *limitedBuffer) keep(p []byte) {
+	if room := b.max - b.Len(); room < len(p) {
+		p = p[:max(room, 0)]
+		b.truncated = true
+	}
+	b.Write(p)
+}
+
+func (b *limitedBuffer) comment() string {
+	if b.truncated {
+		return "truncated"
+	}
+	return ""
+}
+
+// captureBody records what the handler reads from a request body
+type captureBody struct {
+	io.ReadCloser
+	buf limitedBuffer
+	n   int64
+}
+
+func (b *captureBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.buf.keep(p[:n])
+	b.n += int64(n)
+	return n, err
+}
+
+// captureWriter records what the handler writes to a response, keeping
+// streaming and WebSocket upgrades working
+type captureWriter struct {
+	*httpwrap.ResponseWriter
+	buf limitedBuffer
+}
+
+func (w *captureWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	w.buf.keep(p[:n])
+	return n, err
+}
+
+func (w *captureWriter) Flush() {
+	w.ResponseWriter.Flush()
+}
+
+func (w *captureWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return w.ResponseWriter.Hijack()
+}
+
+// Unwrap gives http.ResponseController access to the underlying writer
+func (w *captureWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
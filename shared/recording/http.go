@@ -0,0 +1,147 @@
+package recording
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/probitas-test/echo-servers/shared/internal/jsonhttp"
+	"github.com/probitas-test/echo-servers/shared/version"
+)
+
+// Paths of the admin API
+const (
+	Path        = "/recordings"
+	HARPath     = Path + "/har"
+	ArchivePath = Path + "/archive"
+)
+
+// Handler serves the admin API of r, to mount at Path and Path + "/":
+//
+//	GET    /recordings          recording status and files
+//	PUT    /recordings          turn recording on or off: {"enabled": true}
+//	DELETE /recordings          delete the recordings
+//	GET    /recordings/har      HAR document of the HTTP exchanges
+//	GET    /recordings/archive  tar.gz archive of the HAR document and RPCs
+func Handler(r *Recorder) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(Path, func(w http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodGet, http.MethodHead:
+		case http.MethodPut:
+			var body struct {
+				Enabled bool `json:"enabled"`
+			}
+			if err := jsonhttp.Decode(req, &body); err != nil {
+				jsonhttp.Error(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			r.SetEnabled(body.Enabled)
+		case http.MethodDelete:
+			if err := r.Clear(); err != nil {
+				jsonhttp.Error(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+		default:
+			w.Header().Set("Allow", "GET, HEAD, PUT, DELETE")
+			jsonhttp.Error(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		jsonhttp.Write(w, http.StatusOK, r.State())
+	})
+	mux.HandleFunc("GET "+HARPath, func(w http.ResponseWriter, req *http.Request) {
+		har, err := r.HAR()
+		if err != nil {
+			jsonhttp.Error(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		w.Header().Set("Content-Disposition", `attachment; filename="`+r.server+`.har"`)
+		jsonhttp.Write(w, http.StatusOK, har)
+	})
+	mux.HandleFunc("GET "+ArchivePath, func(w http.ResponseWriter, req *http.Request) {
+		var buf bytes.Buffer
+		if err := r.WriteArchive(&buf); err != nil {
+			jsonhttp.Error(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", "application/gzip")
+		w.Header().Set("Content-Disposition", `attachment; filename="`+r.server+`-recordings.tar.gz"`)
+		_, _ = buf.WriteTo(w)
+	})
+	return mux
+}
+
+// HAR returns the HAR document of the HTTP exchanges recorded so far
+func (r *Recorder) HAR() (HAR, error) {
+	har := HAR{Log: Log{
+		Version: "1.2",
+		Creator: Creator{Name: r.server, Version: version.New(r.server, nil).Version},
+		Entries: []json.RawMessage{},
+	}}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	f, err := os.Open(r.path(kindHAR))
+	if errors.Is(err, fs.ErrNotExist) {
+		return har, nil
+	}
+	if err != nil {
+		return har, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 64<<20)
+	for scanner.Scan() {
+		har.Log.Entries = append(har.Log.Entries, bytes.Clone(scanner.Bytes()))
+	}
+	return har, scanner.Err()
+}
+
+// WriteArchive writes a tar.gz archive of the HAR document and the RPC
+// recording file to w
+func (r *Recorder) WriteArchive(w io.Writer) error {
+	har, err := r.HAR()
+	if err != nil {
+		return err
+	}
+	harJSON, err := json.MarshalIndent(har, "", "  ")
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	rpcs, err := os.ReadFile(r.path(kindGRPC))
+	r.mu.Unlock()
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	now := time.Now()
+	for _, file := range []struct {
+		name string
+		data []byte
+	}{
+		{r.server + ".har", harJSON},
+		{r.server + "." + kindGRPC, rpcs},
+	} {
+		hdr := &tar.Header{Name: file.name, Mode: 0o644, Size: int64(len(file.data)), ModTime: now}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := tw.Write(file.data); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
@@ -0,0 +1,169 @@
+// Package recording persists the traffic of a server to a directory, so
+// failing CI runs leave artifacts to investigate: HTTP exchanges as HAR
+// entries and RPCs as their serialized gRPC frames.
+//
+// A Recorder appends one JSON line per exchange to <server>.har.jsonl and
+// <server>.grpc.jsonl in the RECORDING_DIR directory, as soon as the
+// exchange completes. HTTP servers record with the HTTP middleware, RPC
+// servers with their own interceptors calling Start. The admin API
+// (Handler) turns recording on and off and downloads the HAR document or
+// an archive of the recordings.
+package recording
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	"github.com/probitas-test/echo-servers/shared/config"
+)
+
+// Config configures the recordings
+type Config struct {
+	Enabled bool
+	Dir     string
+	// MaxBodyBytes limits the body recorded per request and response, and
+	// the frames recorded per RPC
+	MaxBodyBytes int
+}
+
+// LoadConfig reads the RECORDING_* settings from src
+func LoadConfig(src *config.Source) Config {
+	return Config{
+		Enabled:      src.Bool("RECORDING_ENABLED", false),
+		Dir:          src.String("RECORDING_DIR", "recordings"),
+		MaxBodyBytes: src.Int("RECORDING_MAX_BODY_BYTES", 1<<20),
+	}
+}
+
+// Validate reports the first invalid setting
+func (c Config) Validate() error {
+	if c.Dir == "" {
+		return errors.New("recording directory is required")
+	}
+	if c.MaxBodyBytes < 0 {
+		return errors.New("max body bytes must not be negative")
+	}
+	return nil
+}
+
+// String describes the recordings for the startup log
+func (c Config) String() string {
+	if !c.Enabled {
+		return "disabled"
+	}
+	return fmt.Sprintf("to %s, bodies up to %d bytes", c.Dir, c.MaxBodyBytes)
+}
+
+// Kinds of recording files
+const (
+	kindHAR  = "har.jsonl"
+	kindGRPC = "grpc.jsonl"
+)
+
+// Recorder appends the exchanges of one server to its recording files
+type Recorder struct {
+	server  string
+	cfg     Config
+	enabled atomic.Bool
+
+	// mu serializes the writes, so every line stays whole
+	mu sync.Mutex
+}
+
+// New creates the recorder of server, creating the directory when
+// recording is enabled
+func New(server string, c Config) (*Recorder, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	r := &Recorder{server: server, cfg: c}
+	if c.Enabled {
+		if err := os.MkdirAll(c.Dir, 0o755); err != nil {
+			return nil, err
+		}
+	}
+	r.enabled.Store(c.Enabled)
+	return r, nil
+}
+
+// Enabled reports whether exchanges are recorded
+func (r *Recorder) Enabled() bool {
+	return r.enabled.Load()
+}
+
+// SetEnabled turns recording on or off
+func (r *Recorder) SetEnabled(enabled bool) {
+	r.enabled.Store(enabled)
+}
+
+func (r *Recorder) path(kind string) string {
+	return filepath.Join(r.cfg.Dir, r.server+"."+kind)
+}
+
+// append writes v as one line of the file kind
+func (r *Recorder) append(kind string, v any) error {
+	line, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := os.MkdirAll(r.cfg.Dir, 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(r.path(kind), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(line); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Clear deletes the recordings of the server
+func (r *Recorder) Clear() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, kind := range []string{kindHAR, kindGRPC} {
+		if err := os.Remove(r.path(kind)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// File is a recording file of the server
+type File struct {
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+}
+
+// State is the recording status and files, as served by the admin API
+type State struct {
+	Enabled bool   `json:"enabled"`
+	Dir     string `json:"dir"`
+	Files   []File `json:"files"`
+}
+
+// State returns the recording status and the files recorded so far
+func (r *Recorder) State() State {
+	s := State{Enabled: r.Enabled(), Dir: r.cfg.Dir, Files: []File{}}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, kind := range []string{kindHAR, kindGRPC} {
+		if info, err := os.Stat(r.path(kind)); err == nil {
+			s.Files = append(s.Files, File{Name: info.Name(), Size: info.Size()})
+		}
+	}
+	return s
+}
@@ -0,0 +1,147 @@
+package recording
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func newRecorder(t *testing.T, maxBody int) *Recorder {
+	t.Helper()
+	r, err := New("echo-test", Config{Enabled: true, Dir: t.TempDir(), MaxBodyBytes: maxBody})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestHTTP(t *testing.T) {
+	r := newRecorder(t, 8)
+	h := r.HTTP(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write(append([]byte("echo: "), body...))
+	}))
+	req := httptest.NewRequest("POST", "/anything?a=1", strings.NewReader("hello"))
+	req.Header.Set("Content-Type", "text/plain")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	r.SetEnabled(false)
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/get", nil))
+	r.SetEnabled(true)
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/bin", bytes.NewReader([]byte{0xff, 0xfe})))
+
+	har, err := r.HAR()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(har.Log.Entries) != 2 {
+		t.Fatalf("%d entries, want 2", len(har.Log.Entries))
+	}
+	var e Entry
+	if err := json.Unmarshal(har.Log.Entries[0], &e); err != nil {
+		t.Fatal(err)
+	}
+	if e.Request.URL != "http://example.com/anything?a=1" || e.Request.PostData.Text != "hello" ||
+		len(e.Request.QueryString) != 1 || e.Request.BodySize != 5 {
+		t.Errorf("request = %+v", e.Request)
+	}
+	if e.Response.Status != 201 || e.Response.Content.Text != "echo: he" || e.Response.Content.Comment != "truncated" ||
+		e.Response.Content.Size != 11 {
+		t.Errorf("response = %+v", e.Response)
+	}
+	if err := json.Unmarshal(har.Log.Entries[1], &e); err != nil {
+		t.Fatal(err)
+	}
+	if e.Request.PostData.Encoding != "base64" || e.Request.PostData.Text != "//4=" {
+		t.Errorf("binary body = %+v", e.Request.PostData)
+	}
+}
+
+func TestRPC(t *testing.T) {
+	r := newRecorder(t, 6)
+	c := r.Start("/echo.v1.Echo/Echo", "127.0.0.1:1234", map[string][]string{"x-test": {"1"}})
+	c.Frame(Received, []byte("ping"))
+	c.Frame(Sent, []byte("pong"))
+	c.End(t.Context(), "OK", "")
+
+	r.SetEnabled(false)
+	r.Start("/echo.v1.Echo/Echo", "", nil).End(t.Context(), "OK", "")
+
+	data, err := os.ReadFile(r.path(kindGRPC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("%d RPCs recorded, want 1", len(lines))
+	}
+	var rpc RPC
+	if err := json.Unmarshal([]byte(lines[0]), &rpc); err != nil {
+		t.Fatal(err)
+	}
+	if len(rpc.Frames) != 1 || !rpc.Truncated || rpc.Code != "OK" {
+		t.Fatalf("RPC = %+v", rpc)
+	}
+	if f := rpc.Frames[0]; f.Direction != Received || !bytes.Equal(f.Data, []byte{0, 0, 0, 0, 4, 'p', 'i', 'n', 'g'}) {
+		t.Errorf("frame = %+v", f)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	r := newRecorder(t, 1024)
+	r.HTTP(http.NotFoundHandler()).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/missing", nil))
+	r.Start("/echo.v1.Echo/Echo", "", nil).End(t.Context(), "OK", "")
+	h := Handler(r)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", Path, nil))
+	var s State
+	if err := json.Unmarshal(rec.Body.Bytes(), &s); err != nil {
+		t.Fatal(err)
+	}
+	if !s.Enabled || len(s.Files) != 2 {
+		t.Errorf("GET %s = %+v", Path, s)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", ArchivePath, nil))
+	gz, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for tr := tar.NewReader(gz); ; {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, hdr.Name)
+	}
+	if strings.Join(names, ",") != "echo-test.har,echo-test.grpc.jsonl" {
+		t.Errorf("archive files = %v", names)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("PUT", Path, strings.NewReader(`{"enabled": false}`)))
+	if r.Enabled() {
+		t.Errorf("PUT %s = %d, recording still enabled", Path, rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("DELETE", Path, nil))
+	if har, _ := r.HAR(); rec.Code != http.StatusOK || len(har.Log.Entries) != 0 {
+		t.Errorf("DELETE %s = %d, %d entries left", Path, rec.Code, len(har.Log.Entries))
+	}
+}
@@ -0,0 +1,108 @@
+package recording
+
+import (
+	"context"
+	"encoding/binary"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Direction tells who sent a frame
+type Direction string
+
+// Directions of a frame
+const (
+	// Received frames come from the client
+	Received Direction = "received"
+	// Sent frames go to the client
+	Sent Direction = "sent"
+)
+
+// Frame is one message of a recorded RPC
+type Frame struct {
+	Direction Direction `json:"direction"`
+	// OffsetMS is the time since the start of the RPC, in milliseconds
+	OffsetMS float64 `json:"offset_ms"`
+	// Data is the gRPC length-prefixed message: an uncompressed flag, the
+	// big-endian length and the serialized message (base64 in JSON)
+	Data []byte `json:"data"`
+}
+
+// RPC is one recorded RPC
+type RPC struct {
+	StartedAt  time.Time           `json:"started_at"`
+	DurationMS float64             `json:"duration_ms"`
+	Method     string              `json:"method"`
+	Peer       string              `json:"peer"`
+	Metadata   map[string][]string `json:"metadata"`
+	Frames     []Frame             `json:"frames"`
+	// Truncated reports frames dropped past MaxBodyBytes
+	Truncated bool   `json:"truncated,omitempty"`
+	Code      string `json:"code"`
+	Message   string `json:"message,omitempty"`
+}
+
+// Call records the frames of one RPC until End
+type Call struct {
+	r    *Recorder
+	size int
+
+	mu  sync.Mutex
+	rpc RPC
+}
+
+// Start starts recording an RPC of method from peer with the request
+// metadata md. It returns nil while recording is disabled; the methods of
+// a nil Call do nothing.
+func (r *Recorder) Start(method, peer string, md map[string][]string) *Call {
+	if !r.Enabled() {
+		return nil
+	}
+	return &Call{r: r, rpc: RPC{
+		StartedAt: time.Now(),
+		Method:    method,
+		Peer:      peer,
+		Metadata:  md,
+		Frames:    []Frame{},
+	}}
+}
+
+// Frame records the serialized message msg, sent in direction dir
+func (c *Call) Frame(dir Direction, msg []byte) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.size+len(msg) > c.r.cfg.MaxBodyBytes {
+		c.rpc.Truncated = true
+		return
+	}
+	c.size += len(msg)
+	data := make([]byte, 5+len(msg))
+	binary.BigEndian.PutUint32(data[1:5], uint32(len(msg)))
+	copy(data[5:], msg)
+	c.rpc.Frames = append(c.rpc.Frames, Frame{
+		Direction: dir,
+		OffsetMS:  float64(time.Since(c.rpc.StartedAt).Microseconds()) / 1000,
+		Data:      data,
+	})
+}
+
+// End records the status of the RPC, such as "OK" and an empty message,
+// and writes the RPC to the recording file
+func (c *Call) End(ctx context.Context, code, message string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.rpc.DurationMS = float64(time.Since(c.rpc.StartedAt).Microseconds()) / 1000
+	c.rpc.Code = code
+	c.rpc.Message = message
+	err := c.r.append(kindGRPC, c.rpc)
+	c.mu.Unlock()
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to record RPC", "error", err)
+	}
+}
@@ -0,0 +1,91 @@
+package script
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/probitas-test/echo-servers/shared/internal/jsonhttp"
+)
+
+// Path is where the admin API is served
+const Path = "/script"
+
+// Header names the scenario step applied to a response, such as "flaky#2"
+const Header = "X-Script-Step"
+
+// Client returns the client ID of a request: id when set, the IP address
+// of remoteAddr otherwise
+func Client(remoteAddr, id string) string {
+	if id != "" {
+		return id
+	}
+	if host, _, err := net.SplitHostPort(remoteAddr); err == nil {
+		return host
+	}
+	return remoteAddr
+}
+
+// HTTP is middleware playing the script of e on every request but the
+// health checks at /health and those of the admin API. Scenarios match the
+// path or the method and path of a request.
+func HTTP(e *Engine) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == Path || r.URL.Path == "/health" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			a := e.Next(Client(r.RemoteAddr, r.Header.Get(e.ClientHeader())), r.URL.Path, r.Method+" "+r.URL.Path)
+			if !a.Matched() {
+				next.ServeHTTP(w, r)
+				return
+			}
+			w.Header().Set(Header, a.Label())
+			if a.Wait(r.Context()) != nil {
+				return
+			}
+			if a.Status != 0 {
+				body := a.Body
+				if body == "" {
+					body = http.StatusText(a.Status)
+				}
+				http.Error(w, body, a.Status)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Handler serves the admin API of e:
+//
+//	GET    /script  current script and progress
+//	PUT    /script  replace the script and rewind its scenarios
+//	POST   /script  rewind the scenarios
+//	DELETE /script  restore the startup script
+func Handler(e *Engine) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+		case http.MethodPut:
+			var s Script
+			err := jsonhttp.Decode(r, &s)
+			if err == nil {
+				err = e.SetScript(s)
+			}
+			if err != nil {
+				jsonhttp.Error(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		case http.MethodPost:
+			e.Rewind()
+		case http.MethodDelete:
+			e.Reset()
+		default:
+			w.Header().Set("Allow", "GET, HEAD, PUT, POST, DELETE")
+			jsonhttp.Error(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		jsonhttp.Write(w, http.StatusOK, e.State())
+	})
+}
@@ -0,0 +1,322 @@
+// Package script plays scripted scenarios: sequences of server behaviors
+// such as "the first 3 requests fail with 500, the next 2 are delayed by
+// 30s, then requests succeed", so client retry and backoff stories can be
+// reproduced deterministically with the same script on every protocol.
+//
+// Scenarios are loaded from the YAML file of SCRIPT_FILE and replaced at
+// runtime through the admin API (Handler). Each scenario matches routes
+// with the patterns of chaos rules and counts the requests globally or per
+// client. echo-http applies it with the HTTP middleware, RPC servers with
+// their own interceptors calling Next.
+package script
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/probitas-test/echo-servers/shared/chaos"
+	"github.com/probitas-test/echo-servers/shared/config"
+)
+
+// DefaultClientHeader is the header identifying the client of a request
+const DefaultClientHeader = "X-Client-ID"
+
+// Config configures the script loaded at startup
+type Config struct {
+	File string
+	// ClientHeader identifies the clients of the scenarios counted per
+	// client, falling back to the client IP address without it
+	ClientHeader string
+}
+
+// LoadConfig reads the SCRIPT_* settings from src
+func LoadConfig(src *config.Source) Config {
+	return Config{
+		File:         src.String("SCRIPT_FILE", ""),
+		ClientHeader: src.String("SCRIPT_CLIENT_HEADER", DefaultClientHeader),
+	}
+}
+
+// String describes the script for the startup log
+func (c Config) String() string {
+	if c.File == "" {
+		return "none"
+	}
+	return fmt.Sprintf("%s (clients by %s)", c.File, c.ClientHeader)
+}
+
+// Step is the behavior of a scenario for a number of requests
+type Step struct {
+	// Times is the number of requests the step applies to (1 when 0)
+	Times int `yaml:"times" json:"times,omitempty"`
+	// DelayMS delays the requests
+	DelayMS int `yaml:"delay_ms" json:"delay_ms,omitempty"`
+	// Status fails the requests with an HTTP status (4xx or 5xx), mapped to
+	// the code of RPCs like chaos errors; 0 lets them succeed
+	Status int `yaml:"status" json:"status,omitempty"`
+	// Body is the body of the failed responses, or the message of failed
+	// RPCs
+	Body string `yaml:"body" json:"body,omitempty"`
+}
+
+func (s Step) times() int {
+	return max(s.Times, 1)
+}
+
+// Scenario is a sequence of steps played on the matching requests
+type Scenario struct {
+	Name string `yaml:"name" json:"name"`
+	// Match lists the route patterns of the requests the scenario applies
+	// to (all when empty)
+	Match []string `yaml:"match" json:"match,omitempty"`
+	// Clients limits the scenario to these client IDs (all when empty)
+	Clients []string `yaml:"clients" json:"clients,omitempty"`
+	// PerClient counts the requests of every client separately
+	PerClient bool `yaml:"per_client" json:"per_client,omitempty"`
+	// Loop restarts the steps after the last one; otherwise the last step
+	// applies to every later request
+	Loop  bool   `yaml:"loop" json:"loop,omitempty"`
+	Steps []Step `yaml:"steps" json:"steps"`
+}
+
+// Script is the list of scenarios; the first matching a request applies
+type Script struct {
+	Scenarios []Scenario `yaml:"scenarios" json:"scenarios"`
+}
+
+// Validate reports the first invalid scenario
+func (s Script) Validate() error {
+	names := map[string]bool{}
+	for i, sc := range s.Scenarios {
+		switch {
+		case sc.Name == "":
+			return fmt.Errorf("scenario %d: name is required", i)
+		case names[sc.Name]:
+			return fmt.Errorf("scenario %q: duplicate name", sc.Name)
+		case len(sc.Steps) == 0:
+			return fmt.Errorf("scenario %q: at least one step is required", sc.Name)
+		}
+		names[sc.Name] = true
+		for j, step := range sc.Steps {
+			switch {
+			case step.Times < 0:
+				return fmt.Errorf("scenario %q step %d: times must not be negative", sc.Name, j)
+			case step.DelayMS < 0:
+				return fmt.Errorf("scenario %q step %d: delay_ms must not be negative", sc.Name, j)
+			case step.Status != 0 && (step.Status < 400 || step.Status > 599):
+				return fmt.Errorf("scenario %q step %d: status must be 4xx or 5xx", sc.Name, j)
+			}
+		}
+	}
+	return nil
+}
+
+// LoadFile reads the script of the YAML file path, rejecting unknown fields
+func LoadFile(path string) (Script, error) {
+	var s Script
+	f, err := os.Open(path)
+	if err != nil {
+		return s, err
+	}
+	defer f.Close()
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	if err := dec.Decode(&s); err != nil {
+		return s, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, s.Validate()
+}
+
+// Progress is how far a scenario has played for one client (or all)
+type Progress struct {
+	Scenario string `json:"scenario"`
+	Client   string `json:"client,omitempty"`
+	Requests int    `json:"requests"`
+}
+
+// State is the script in effect and its progress, as served by the admin
+// API
+type State struct {
+	Script
+	Progress []Progress `json:"progress"`
+}
+
+type progressKey struct {
+	scenario string
+	client   string
+}
+
+// Engine plays a script under a configuration replaced at runtime
+type Engine struct {
+	clientHeader string
+	initial      Script
+
+	mu       sync.Mutex
+	script   Script
+	progress map[progressKey]int
+}
+
+// New creates an engine playing the script of c.File, if any
+func New(c Config) (*Engine, error) {
+	var s Script
+	if c.File != "" {
+		var err error
+		if s, err = LoadFile(c.File); err != nil {
+			return nil, err
+		}
+	}
+	if c.ClientHeader == "" {
+		return nil, errors.New("script client header is required")
+	}
+	return &Engine{clientHeader: c.ClientHeader, initial: s, script: s, progress: map[progressKey]int{}}, nil
+}
+
+// ClientHeader returns the header identifying the client of a request
+func (e *Engine) ClientHeader() string {
+	return e.clientHeader
+}
+
+// State returns the script in effect and its progress
+func (e *Engine) State() State {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	s := State{Script: e.script, Progress: []Progress{}}
+	if s.Scenarios == nil {
+		s.Scenarios = []Scenario{}
+	}
+	for key, n := range e.progress {
+		s.Progress = append(s.Progress, Progress{Scenario: key.scenario, Client: key.client, Requests: n})
+	}
+	slices.SortFunc(s.Progress, func(a, b Progress) int {
+		return strings.Compare(a.Scenario+"\x00"+a.Client, b.Scenario+"\x00"+b.Client)
+	})
+	return s
+}
+
+// SetScript replaces the script and rewinds every scenario
+func (e *Engine) SetScript(s Script) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.script = s
+	clear(e.progress)
+	return nil
+}
+
+// Reset restores the startup script and rewinds every scenario
+func (e *Engine) Reset() {
+	_ = e.SetScript(e.initial)
+}
+
+// Rewind restarts every scenario from its first step
+func (e *Engine) Rewind() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	clear(e.progress)
+}
+
+// Action is what a script does with one request
+type Action struct {
+	// Scenario and Step name the step applied, empty and 0 when no
+	// scenario matches; steps count from 1
+	Scenario string
+	Step     int
+	Delay    time.Duration
+	Status   int
+	Body     string
+}
+
+// Matched reports whether a scenario applies to the request
+func (a Action) Matched() bool {
+	return a.Scenario != ""
+}
+
+// Wait waits for the delay of a, or until ctx is done
+func (a Action) Wait(ctx context.Context) error {
+	if a.Delay <= 0 {
+		return nil
+	}
+	t := time.NewTimer(a.Delay)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Label names the step of a for the response header, such as "flaky#2"
+func (a Action) Label() string {
+	return fmt.Sprintf("%s#%d", a.Scenario, a.Step)
+}
+
+// Next counts one request from client, keyed by routes, and returns the
+// step of the first matching scenario
+func (e *Engine) Next(client string, routes ...string) Action {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, sc := range e.script.Scenarios {
+		if !matches(sc.Match, routes) || (len(sc.Clients) > 0 && !slices.Contains(sc.Clients, client)) {
+			continue
+		}
+		key := progressKey{scenario: sc.Name}
+		if sc.PerClient {
+			key.client = client
+		}
+		n := e.progress[key]
+		e.progress[key] = n + 1
+		i, step := sc.step(n)
+		return Action{
+			Scenario: sc.Name,
+			Step:     i + 1,
+			Delay:    time.Duration(step.DelayMS) * time.Millisecond,
+			Status:   step.Status,
+			Body:     step.Body,
+		}
+	}
+	return Action{}
+}
+
+// step returns the index and the step of the request n (from 0)
+func (sc Scenario) step(n int) (int, Step) {
+	if sc.Loop {
+		total := 0
+		for _, s := range sc.Steps {
+			total += s.times()
+		}
+		n %= total
+	}
+	for i, s := range sc.Steps {
+		if n < s.times() {
+			return i, s
+		}
+		n -= s.times()
+	}
+	last := len(sc.Steps) - 1
+	return last, sc.Steps[last]
+}
+
+func matches(patterns, routes []string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		for _, route := range routes {
+			if chaos.Match(pattern, route) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+package script
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const flaky = `
+scenarios:
+  - name: flaky
+    match: ["GET /status/*"]
+    per_client: true
+    steps:
+      - times: 3
+        status: 500
+      - times: 2
+        delay_ms: 1
+      - {}
+  - name: cycle
+    match: ["/echo.v1.Echo/*"]
+    loop: true
+    steps:
+      - status: 503
+        body: try again
+      - {}
+`
+
+func newEngine(t *testing.T, yaml string) *Engine {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "script.yaml")
+	if err := os.WriteFile(path, []byte(yaml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	e, err := New(Config{File: path, ClientHeader: DefaultClientHeader})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+func TestNext(t *testing.T) {
+	e := newEngine(t, flaky)
+
+	var steps []int
+	for range 7 {
+		a := e.Next("a", "/status/200", "GET /status/200")
+		if a.Scenario != "flaky" {
+			t.Fatalf("scenario %q, want flaky", a.Scenario)
+		}
+		steps = append(steps, a.Step)
+	}
+	if got := join(steps); got != "1,1,1,2,2,3,3" {
+		t.Errorf("steps = %s, want 1,1,1,2,2,3,3", got)
+	}
+	if a := e.Next("b", "GET /status/200"); a.Step != 1 || a.Status != 500 {
+		t.Errorf("first request of another client = %+v, want step 1", a)
+	}
+	if a := e.Next("a", "POST /status/200"); a.Matched() {
+		t.Errorf("POST matched %+v", a)
+	}
+
+	var codes []int
+	for range 4 {
+		codes = append(codes, e.Next("a", "/echo.v1.Echo/Echo").Status)
+	}
+	if join(codes) != "503,0,503,0" {
+		t.Errorf("looped statuses = %s, want 503,0,503,0", join(codes))
+	}
+
+	e.Rewind()
+	if a := e.Next("a", "GET /status/200"); a.Step != 1 {
+		t.Errorf("step after Rewind = %d, want 1", a.Step)
+	}
+}
+
+func join(values []int) string {
+	b, _ := json.Marshal(values)
+	return strings.Trim(string(b), "[]")
+}
+
+func TestHTTP(t *testing.T) {
+	e := newEngine(t, flaky)
+	h := HTTP(e)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+
+	var got []string
+	for range 6 {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/status/200", nil)
+		req.Header.Set(DefaultClientHeader, "ci")
+		h.ServeHTTP(rec, req)
+		got = append(got, rec.Header().Get(Header)+"="+http.StatusText(rec.Code))
+	}
+	want := "flaky#1=Internal Server Error,flaky#1=Internal Server Error,flaky#1=Internal Server Error,flaky#2=OK,flaky#2=OK,flaky#3=OK"
+	if strings.Join(got, ",") != want {
+		t.Errorf("responses = %v", got)
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/get", nil))
+	if rec.Header().Get(Header) != "" || rec.Code != http.StatusOK {
+		t.Errorf("unmatched request = %d %v", rec.Code, rec.Header())
+	}
+}
+
+func TestHandler(t *testing.T) {
+	e := newEngine(t, flaky)
+	e.Next("a", "GET /status/500")
+
+	rec := httptest.NewRecorder()
+	Handler(e).ServeHTTP(rec, httptest.NewRequest("PUT", Path, strings.NewReader(
+		`{"scenarios": [{"name": "down", "steps": [{"status": 503}]}]}`)))
+	var s State
+	if err := json.Unmarshal(rec.Body.Bytes(), &s); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusOK || len(s.Scenarios) != 1 || len(s.Progress) != 0 {
+		t.Errorf("PUT %s = %d %+v", Path, rec.Code, s)
+	}
+
+	rec = httptest.NewRecorder()
+	Handler(e).ServeHTTP(rec, httptest.NewRequest("PUT", Path, strings.NewReader(
+		`{"scenarios": [{"name": "down", "steps": [{"status": 200}]}]}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("PUT with status 200 = %d, want 400", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	Handler(e).ServeHTTP(rec, httptest.NewRequest("DELETE", Path, nil))
+	if err := json.Unmarshal(rec.Body.Bytes(), &s); err != nil || len(s.Scenarios) != 2 {
+		t.Errorf("DELETE %s = %+v, %v", Path, s, err)
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "script.yaml")
+	if err := os.WriteFile(path, []byte("scenarios:\n  - name: x\n    step: []\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFile(path); err == nil {
+		t.Error("unknown field accepted")
+	}
+}
@@ -0,0 +1,53 @@
+package tracing
+
+import (
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/probitas-test/echo-servers/shared/internal/httpwrap"
+)
+
+// HTTP returns middleware starting a server span per request and echoing
+// the trace context in the response headers. route returns the route that
+// handled the request, naming the span "METHOD route"; the default is the
+// http.ServeMux pattern.
+func HTTP(route func(*http.Request) string) func(http.Handler) http.Handler {
+	if route == nil {
+		route = func(r *http.Request) string { return r.Pattern }
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			carrier := propagation.HeaderCarrier(r.Header)
+			ctx, span := Start(r.Context(), carrier, r.Method, trace.SpanKindServer,
+				attribute.String("http.request.method", r.Method),
+				attribute.String("url.path", r.URL.Path),
+				attribute.String("network.protocol.version", strings.TrimPrefix(r.Proto, "HTTP/")),
+			)
+			defer span.End()
+			Echo(ctx, carrier, propagation.HeaderCarrier(w.Header()))
+
+			rw := httpwrap.WrapResponseWriter(w)
+			r = r.WithContext(ctx)
+			next.ServeHTTP(rw, r)
+
+			if name := route(r); name != "" {
+				// Patterns may include the method ("GET /health")
+				if !strings.HasPrefix(name, r.Method+" ") {
+					name = r.Method + " " + name
+				}
+				span.SetName(name)
+				span.SetAttributes(attribute.String("http.route", strings.TrimPrefix(name, r.Method+" ")))
+			}
+			status := rw.Status()
+			span.SetAttributes(attribute.Int("http.response.status_code", status))
+			if status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(status))
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/probitas-test/echo-servers/shared/tracing"
+
+// TraceHeaders are the W3C Trace Context and Baggage headers echoed back
+// to the caller
+var TraceHeaders = []string{"traceparent", "tracestate", "baggage"}
+
+// TraceResponse is the W3C Trace Context Level 2 response header naming
+// the server span (version-traceid-spanid-flags)
+const TraceResponse = "traceresponse"
+
+// Tracer returns the tracer of the echo servers
+func Tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// Start starts a span of kind, parented to the trace context in carrier
+// unless ctx already carries one (carrier may be nil)
+func Start(ctx context.Context, carrier propagation.TextMapCarrier, name string, kind trace.SpanKind, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if carrier != nil && !trace.SpanContextFromContext(ctx).IsValid() {
+		ctx = otel.GetTextMapPropagator().Extract(ctx, carrier)
+	}
+	return Tracer().Start(ctx, name, trace.WithSpanKind(kind), trace.WithAttributes(attrs...))
+}
+
+// Inject writes the trace context and baggage of ctx to carrier, such as
+// the headers of an echoed message or a proxied request
+func Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+}
+
+// Echo copies the trace headers of in to out and, when ctx carries a span
+// started by this server, sets traceresponse to it. Without an exporter
+// (OTEL_ENABLED=false) only the incoming headers are echoed.
+func Echo(ctx context.Context, in, out propagation.TextMapCarrier) {
+	for _, key := range TraceHeaders {
+		if v := in.Get(key); v != "" {
+			out.Set(key, v)
+		}
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() && !sc.IsRemote() {
+		out.Set(TraceResponse, fmt.Sprintf("00-%s-%s-%s", sc.TraceID(), sc.SpanID(), sc.TraceFlags()))
+	}
+}
+
+// SetError records err on span and marks it failed; a nil err is ignored
+func SetError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
@@ -0,0 +1,157 @@
+// Package tracing sets up OpenTelemetry tracing with OTLP export and W3C
+// Trace Context and Baggage propagation, and echoes the trace context back
+// to callers, so client instrumentation can verify propagation through
+// every echo server.
+//
+// Propagation is always installed; spans are exported only when
+// OTEL_ENABLED is set. Servers start a span per request (or message)
+// parented to the incoming trace context, and respond with the incoming
+// traceparent, tracestate and baggage plus a traceresponse naming the
+// server span.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/probitas-test/echo-servers/shared/config"
+)
+
+// OTLP exporter protocols
+const (
+	ProtocolGRPC = "grpc"
+	ProtocolHTTP = "http/protobuf"
+)
+
+// Samplers (the values of OTEL_TRACES_SAMPLER)
+const (
+	SamplerAlwaysOn                = "always_on"
+	SamplerAlwaysOff               = "always_off"
+	SamplerTraceIDRatio            = "traceidratio"
+	SamplerParentBasedAlwaysOn     = "parentbased_always_on"
+	SamplerParentBasedAlwaysOff    = "parentbased_always_off"
+	SamplerParentBasedTraceIDRatio = "parentbased_traceidratio"
+)
+
+// Config configures the tracer provider
+type Config struct {
+	Enabled          bool
+	ServiceName      string
+	ExporterEndpoint string
+	ExporterProtocol string
+	Sampler          string
+	SamplerArg       float64
+}
+
+// LoadConfig reads the OTEL_* settings from src; serviceName is the
+// default of OTEL_SERVICE_NAME
+func LoadConfig(src *config.Source, serviceName string) Config {
+	return Config{
+		Enabled:          src.Bool("OTEL_ENABLED", false),
+		ServiceName:      src.String("OTEL_SERVICE_NAME", serviceName),
+		ExporterEndpoint: src.String("OTEL_EXPORTER_OTLP_ENDPOINT", ""),
+		ExporterProtocol: src.String("OTEL_EXPORTER_OTLP_PROTOCOL", ProtocolGRPC),
+		Sampler:          src.String("OTEL_TRACES_SAMPLER", SamplerParentBasedAlwaysOn),
+		SamplerArg:       src.Float("OTEL_TRACES_SAMPLER_ARG", 1),
+	}
+}
+
+// Endpoint returns the OTLP collector base URL, defaulting to the standard
+// local collector port for the configured protocol
+func (c Config) Endpoint() string {
+	if c.ExporterEndpoint != "" {
+		return strings.TrimSuffix(c.ExporterEndpoint, "/")
+	}
+	if c.ExporterProtocol == ProtocolHTTP {
+		return "http://localhost:4318"
+	}
+	return "http://localhost:4317"
+}
+
+// String describes the exporter and sampler for the startup log
+func (c Config) String() string {
+	if !c.Enabled {
+		return "disabled"
+	}
+	return fmt.Sprintf("protocol=%s, endpoint=%s, sampler=%s, ratio=%g", c.ExporterProtocol, c.Endpoint(), c.Sampler, c.SamplerArg)
+}
+
+// Setup installs the W3C Trace Context and Baggage propagators and, when
+// tracing is enabled, a tracer provider exporting spans via OTLP. The
+// returned function flushes and shuts down the tracer provider.
+func Setup(ctx context.Context, c Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	if !c.Enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	sampler, err := newSampler(c.Sampler, c.SamplerArg)
+	if err != nil {
+		return nil, err
+	}
+
+	var exporter sdktrace.SpanExporter
+	switch c.ExporterProtocol {
+	case ProtocolGRPC:
+		exporter, err = otlptracegrpc.New(ctx, otlptracegrpc.WithEndpointURL(c.Endpoint()))
+	case ProtocolHTTP:
+		exporter, err = otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(c.Endpoint()+"/v1/traces"))
+	default:
+		return nil, fmt.Errorf("unsupported OTLP protocol %q (must be %q or %q)", c.ExporterProtocol, ProtocolGRPC, ProtocolHTTP)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+
+	res, err := resource.Merge(
+		resource.Default(),
+		resource.NewSchemaless(attribute.String("service.name", c.ServiceName)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create resource: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sampler),
+	)
+	otel.SetTracerProvider(tp)
+
+	return tp.Shutdown, nil
+}
+
+func newSampler(name string, arg float64) (sdktrace.Sampler, error) {
+	if arg < 0 || arg > 1 {
+		return nil, fmt.Errorf("sampler ratio %g is not between 0 and 1", arg)
+	}
+	switch name {
+	case SamplerAlwaysOn:
+		return sdktrace.AlwaysSample(), nil
+	case SamplerAlwaysOff:
+		return sdktrace.NeverSample(), nil
+	case SamplerTraceIDRatio:
+		return sdktrace.TraceIDRatioBased(arg), nil
+	case SamplerParentBasedAlwaysOn:
+		return sdktrace.ParentBased(sdktrace.AlwaysSample()), nil
+	case SamplerParentBasedAlwaysOff:
+		return sdktrace.ParentBased(sdktrace.NeverSample()), nil
+	case SamplerParentBasedTraceIDRatio:
+		return sdktrace.ParentBased(sdktrace.TraceIDRatioBased(arg)), nil
+	default:
+		return nil, fmt.Errorf("unsupported sampler %q", name)
+	}
+}
@@ -0,0 +1,153 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/probitas-test/echo-servers/shared/config"
+)
+
+const (
+	traceID     = "4bf92f3577b34da6a3ce929d0e0e4736"
+	parentID    = "00f067aa0ba902b7"
+	traceparent = "00-" + traceID + "-" + parentID + "-01"
+)
+
+// useRecorder installs the propagators and a tracer provider recording
+// every span for the duration of the test
+func useRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	if _, err := Setup(context.Background(), Config{}); err != nil {
+		t.Fatal(err)
+	}
+	rec := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+	return rec
+}
+
+func TestHTTP(t *testing.T) {
+	rec := useRecorder(t)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /items/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if !trace.SpanContextFromContext(r.Context()).IsValid() {
+			t.Error("handler context has no span")
+		}
+		w.WriteHeader(http.StatusTeapot)
+	})
+	h := HTTP(nil)(mux)
+
+	req := httptest.NewRequest("GET", "/items/1", nil)
+	req.Header.Set("traceparent", traceparent)
+	req.Header.Set("baggage", "user=alice")
+	resp := httptest.NewRecorder()
+	h.ServeHTTP(resp, req)
+
+	spans := rec.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("%d spans, want 1", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "GET /items/{id}" || span.SpanKind() != trace.SpanKindServer {
+		t.Errorf("span = %q (%v)", span.Name(), span.SpanKind())
+	}
+	if span.Parent().SpanID().String() != parentID || span.SpanContext().TraceID().String() != traceID {
+		t.Errorf("span parent = %v, want the traceparent", span.Parent())
+	}
+
+	if got := resp.Header().Get("traceparent"); got != traceparent {
+		t.Errorf("traceparent = %q, want the request's", got)
+	}
+	if got := resp.Header().Get("baggage"); got != "user=alice" {
+		t.Errorf("baggage = %q", got)
+	}
+	want := "00-" + traceID + "-" + span.SpanContext().SpanID().String() + "-01"
+	if got := resp.Header().Get(TraceResponse); got != want {
+		t.Errorf("traceresponse = %q, want %q", got, want)
+	}
+}
+
+func TestHTTPUnmatchedRoute(t *testing.T) {
+	rec := useRecorder(t)
+	h := HTTP(nil)(http.NewServeMux())
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/missing", nil))
+
+	if spans := rec.Ended(); len(spans) != 1 || spans[0].Name() != "POST" {
+		t.Errorf("spans = %v, want one named after the method", spans)
+	}
+}
+
+func TestEchoWithoutExporter(t *testing.T) {
+	if _, err := Setup(context.Background(), Config{}); err != nil {
+		t.Fatal(err)
+	}
+	in := propagation.MapCarrier{"traceparent": traceparent, "tracestate": "vendor=1"}
+	ctx, span := Start(context.Background(), in, "op", trace.SpanKindConsumer)
+	defer span.End()
+
+	out := propagation.MapCarrier{}
+	Echo(ctx, in, out)
+	if out["traceparent"] != traceparent || out["tracestate"] != "vendor=1" {
+		t.Errorf("echoed = %v", out)
+	}
+	if _, ok := out[TraceResponse]; ok {
+		t.Error("traceresponse set without a recording tracer provider")
+	}
+
+	// The remote context still propagates to outgoing messages
+	injected := propagation.MapCarrier{}
+	Inject(ctx, injected)
+	if injected["traceparent"] != traceparent {
+		t.Errorf("injected = %v", injected)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Setenv(config.FileEnv, "")
+	t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", ProtocolHTTP)
+	t.Setenv("OTEL_TRACES_SAMPLER", SamplerTraceIDRatio)
+	t.Setenv("OTEL_TRACES_SAMPLER_ARG", "0.25")
+
+	c := LoadConfig(config.Load(), "echo-test")
+	if c.Enabled || c.ServiceName != "echo-test" || c.Endpoint() != "http://localhost:4318" {
+		t.Errorf("config = %+v, endpoint %s", c, c.Endpoint())
+	}
+	if c.Sampler != SamplerTraceIDRatio || c.SamplerArg != 0.25 {
+		t.Errorf("sampler = %s %g", c.Sampler, c.SamplerArg)
+	}
+
+	c.ExporterEndpoint = "http://collector:4318/"
+	if c.Endpoint() != "http://collector:4318" {
+		t.Errorf("Endpoint() = %s", c.Endpoint())
+	}
+}
+
+func TestSetupErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		want string
+	}{
+		{"protocol", Config{Enabled: true, ExporterProtocol: "zipkin", Sampler: SamplerAlwaysOn}, "unsupported OTLP protocol"},
+		{"sampler", Config{Enabled: true, ExporterProtocol: ProtocolGRPC, Sampler: "sometimes"}, "unsupported sampler"},
+		{"ratio", Config{Enabled: true, ExporterProtocol: ProtocolGRPC, Sampler: SamplerTraceIDRatio, SamplerArg: 2}, "not between 0 and 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Setup(context.Background(), tt.cfg)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Setup() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
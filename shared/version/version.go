@@ -0,0 +1,99 @@
+// Package version reports the build of a server at /version, so tests can
+// assert that they run against the build they expect.
+//
+// Version, Commit and Date are set at build time by the Dockerfiles:
+//
+//	go build -ldflags "-X github.com/probitas-test/echo-servers/shared/version.Version=v1.2.3 ..."
+//
+// Builds without them fall back to the module version and the VCS
+// information embedded by the go command.
+package version
+
+import (
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"slices"
+
+	"github.com/probitas-test/echo-servers/shared/internal/jsonhttp"
+)
+
+// Build information set with -ldflags -X
+var (
+	Version string
+	Commit  string
+	Date    string
+)
+
+// Path is where the build information is served
+const Path = "/version"
+
+// Features names the optional features of a server and whether they are
+// enabled
+type Features map[string]bool
+
+// Info is the build information of a server
+type Info struct {
+	Server    string `json:"server"`
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	Date      string `json:"date"`
+	GoVersion string `json:"go_version"`
+	Platform  string `json:"platform"`
+	// Features lists the enabled features, sorted
+	Features []string `json:"features"`
+}
+
+// New returns the build information of server, with the enabled features
+// of features
+func New(server string, features Features) Info {
+	info := Info{
+		Server:    server,
+		Version:   Version,
+		Commit:    Commit,
+		Date:      Date,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+		Features:  []string{},
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		if info.Version == "" && bi.Main.Version != "(devel)" {
+			info.Version = bi.Main.Version
+		}
+		var modified bool
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = s.Value
+				}
+			case "vcs.time":
+				if info.Date == "" {
+					info.Date = s.Value
+				}
+			case "vcs.modified":
+				modified = s.Value == "true"
+			}
+		}
+		if modified && Commit == "" && info.Commit != "" {
+			info.Commit += "-dirty"
+		}
+	}
+	if info.Version == "" {
+		info.Version = "dev"
+	}
+	for name, enabled := range features {
+		if enabled {
+			info.Features = append(info.Features, name)
+		}
+	}
+	slices.Sort(info.Features)
+	return info
+}
+
+// Handler serves info as JSON
+func Handler(info Info) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		jsonhttp.Write(w, http.StatusOK, info)
+	})
+}
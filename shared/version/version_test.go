@@ -0,0 +1,51 @@
+package version
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"slices"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	Version, Commit, Date = "v1.2.3", "abc123", "2026-01-02T03:04:05Z"
+	t.Cleanup(func() { Version, Commit, Date = "", "", "" })
+
+	info := New("echo-test", Features{"tracing": true, "chaos": false, "admin": true})
+	if info.Server != "echo-test" || info.Version != "v1.2.3" || info.Commit != "abc123" || info.Date != "2026-01-02T03:04:05Z" {
+		t.Errorf("info = %+v, want the linked build", info)
+	}
+	if info.GoVersion != runtime.Version() || info.Platform != runtime.GOOS+"/"+runtime.GOARCH {
+		t.Errorf("runtime = %s %s", info.GoVersion, info.Platform)
+	}
+	if !slices.Equal(info.Features, []string{"admin", "tracing"}) {
+		t.Errorf("features = %v, want [admin tracing]", info.Features)
+	}
+}
+
+func TestNewWithoutLinkedBuild(t *testing.T) {
+	info := New("echo-test", nil)
+	if info.Version == "" {
+		t.Error("version is empty, want a fallback")
+	}
+	if info.Features == nil {
+		t.Error("features is nil, want an empty list")
+	}
+}
+
+func TestHandler(t *testing.T) {
+	rec := httptest.NewRecorder()
+	Handler(New("echo-test", Features{"admin": true})).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, Path, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	var info Info
+	if err := json.Unmarshal(rec.Body.Bytes(), &info); err != nil {
+		t.Fatal(err)
+	}
+	if info.Server != "echo-test" || !slices.Equal(info.Features, []string{"admin"}) {
+		t.Errorf("info = %+v", info)
+	}
+}